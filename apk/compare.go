@@ -0,0 +1,113 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apk
+
+import (
+	"strings"
+)
+
+// Compare compares two packages and returns -1, 0 or 1 if p1 is older, same or newer than p2
+// packages are ordered by name first and then by version
+func Compare(p1, p2 *Package) int {
+	if c := strings.Compare(p1.Name, p2.Name); c != 0 {
+		return c
+	}
+	return VersionCompare(p1.Version, p2.Version)
+}
+
+// VersionCompare compares two versions the same way apk does
+// it returns -1, 0 or 1 if v1 is older, same or newer than v2
+// invalid versions are compared as strings and are always older than valid ones
+func VersionCompare(v1, v2 string) int {
+	if v1 == v2 {
+		return 0
+	}
+
+	ver1, err1 := parseVersion(v1)
+	ver2, err2 := parseVersion(v2)
+	switch {
+	case err1 != nil && err2 != nil:
+		return strings.Compare(v1, v2)
+	case err1 != nil:
+		return -1
+	case err2 != nil:
+		return 1
+	}
+
+	// 1. numbers, the first one is always compared as an integer
+	// the rest are compared as decimal fractions if either of them starts with a 0
+	for i := 0; i < len(ver1.numbers) && i < len(ver2.numbers); i++ {
+		n1, n2 := ver1.numbers[i], ver2.numbers[i]
+		var c int
+		if i > 0 && (strings.HasPrefix(n1, "0") || strings.HasPrefix(n2, "0")) {
+			c = strings.Compare(strings.TrimRight(n1, "0"), strings.TrimRight(n2, "0"))
+		} else {
+			c = compareNumeric(n1, n2)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	// more numbers wins, 1.2.1 is newer than 1.2
+	if c := compareInts(len(ver1.numbers), len(ver2.numbers)); c != 0 {
+		return c
+	}
+
+	// 2. letter, version with a letter is newer than the one without it
+	if c := strings.Compare(ver1.letter, ver2.letter); c != 0 {
+		return c
+	}
+
+	// 3. suffixes, pre release suffixes are older than no suffix, others are newer
+	for i := 0; i < len(ver1.suffixes) || i < len(ver2.suffixes); i++ {
+		var s1, s2 suffix
+		if i < len(ver1.suffixes) {
+			s1 = ver1.suffixes[i]
+		}
+		if i < len(ver2.suffixes) {
+			s2 = ver2.suffixes[i]
+		}
+		if c := compareInts(s1.order, s2.order); c != 0 {
+			return c
+		}
+		if c := compareNumeric(s1.number, s2.number); c != 0 {
+			return c
+		}
+	}
+
+	// 4. revision, missing revision is r0
+	return compareNumeric(ver1.revision, ver2.revision)
+}
+
+// compareNumeric compares two strings of digits, empty string is treated as 0
+func compareNumeric(n1, n2 string) int {
+	n1 = strings.TrimLeft(n1, "0")
+	n2 = strings.TrimLeft(n2, "0")
+	if c := compareInts(len(n1), len(n2)); c != 0 {
+		return c
+	}
+	return strings.Compare(n1, n2)
+}
+
+func compareInts(i1, i2 int) int {
+	switch {
+	case i1 < i2:
+		return -1
+	case i1 > i2:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apk
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestVersionCompare(t *testing.T) {
+	cases := []struct {
+		v1, v2 string
+		result int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.0", "1.0-r0", 0},
+		{"1.0-r1", "1.0-r0", 1},
+		{"1.0-r10", "1.0-r9", 1},
+		{"1.0", "1.1", -1},
+		{"1.10", "1.9", 1},
+		{"2.0", "1.99", 1},
+		{"1.2.1", "1.2", 1},
+		{"1.2.1", "1.2a", 1},
+		// numbers with leading zeros are compared as fractions
+		{"1.01", "1.1", -1},
+		{"1.010", "1.01", 0},
+		{"1.05", "1.1", -1},
+		// letters
+		{"1.0a", "1.0", 1},
+		{"1.0b", "1.0a", 1},
+		// suffixes
+		{"1.0_alpha", "1.0", -1},
+		{"1.0_alpha", "1.0_beta", -1},
+		{"1.0_beta2", "1.0_beta10", -1},
+		{"1.0_rc1", "1.0", -1},
+		{"1.0_pre1", "1.0_rc1", -1},
+		{"1.0_p1", "1.0", 1},
+		{"1.0_git20200101", "1.0_p1", -1},
+		{"1.0_rc1_p1", "1.0_rc1", 1},
+		{"1.0_rc1_p1", "1.0", -1},
+		{"1.0_rc1_alpha", "1.0_rc1", -1},
+		// commit hash is ignored
+		{"1.0_git20200101~abc123", "1.0_git20200101", 0},
+		// invalid versions are older than valid ones
+		{"invalid", "1.0", -1},
+		{"a", "b", -1},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			if r := VersionCompare(c.v1, c.v2); r != c.result {
+				t.Errorf("compare(%q, %q) = %d, expecting %d", c.v1, c.v2, r, c.result)
+			}
+			if r := VersionCompare(c.v2, c.v1); r != -c.result {
+				t.Errorf("compare(%q, %q) = %d, expecting %d", c.v2, c.v1, r, -c.result)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	p1, _ := Parse("musl-1.1.24-r2")
+	p2, _ := Parse("musl-1.1.24-r10")
+	p3, _ := Parse("busybox-1.31.1-r9")
+	if Compare(p1, p2) != -1 {
+		t.Errorf("%v should be older than %v", p1, p2)
+	}
+	if Compare(p1, p3) != 1 {
+		t.Errorf("%v should be ordered after %v", p1, p3)
+	}
+}
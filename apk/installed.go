@@ -0,0 +1,81 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apk
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Installed is a package installed on alpine
+type Installed struct {
+	Package
+	// Origin is the name of the aport the package was built from, e.g. openssl for libcrypto3
+	Origin string
+	Arch   string
+}
+
+// ParseInstalled parses the apk database of installed packages, /lib/apk/db/installed
+// the database has a paragraph per package, each line is a field: one letter key, colon and value
+func ParseInstalled(r io.Reader) ([]*Installed, error) {
+	var pkgs []*Installed
+	var pkg Installed
+	flush := func() error {
+		defer func() { pkg = Installed{} }()
+		if pkg.Name == "" {
+			return nil
+		}
+		if pkg.Version == "" {
+			return fmt.Errorf("no version found for package %s", pkg.Name)
+		}
+		p := pkg
+		pkgs = append(pkgs, &p)
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if len(line) < 2 || line[1] != ':' {
+			return nil, fmt.Errorf("invalid line: %q", line)
+		}
+		switch value := line[2:]; line[0] {
+		case 'P':
+			pkg.Name = strings.ToLower(value)
+		case 'V':
+			pkg.Version = value
+		case 'A':
+			pkg.Arch = value
+		case 'o':
+			pkg.Origin = strings.ToLower(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("can't read apk database: %v", err)
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return pkgs, nil
+}
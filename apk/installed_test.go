@@ -0,0 +1,69 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apk
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testInstalled = `C:Q1Hf0mJmVYpDtZOJqvGxSL4SHsqgE=
+P:musl
+V:1.2.4-r2
+A:x86_64
+S:407278
+I:663552
+T:the musl c library (libc) implementation
+U:https://musl.libc.org/
+L:MIT
+o:musl
+m:Timo Teräs <timo.teras@iki.fi>
+t:1695219986
+c:2a3f3fd6f4f1f5b9a0d8e6b1d8c0e5f3a7f2b1c4
+F:lib
+R:libc.musl-x86_64.so.1
+
+C:Q1dRbKJvcLrSbNTrjqPSFN6BvU5Mk=
+P:libcrypto3
+V:3.1.4-r1
+A:x86_64
+o:openssl
+`
+
+func TestParseInstalled(t *testing.T) {
+	pkgs, err := ParseInstalled(strings.NewReader(testInstalled))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Installed{
+		{Package: Package{Name: "musl", Version: "1.2.4-r2"}, Origin: "musl", Arch: "x86_64"},
+		{Package: Package{Name: "libcrypto3", Version: "3.1.4-r1"}, Origin: "openssl", Arch: "x86_64"},
+	}
+	if !reflect.DeepEqual(pkgs, want) {
+		t.Fatalf("have: %+v\nwant: %+v", pkgs, want)
+	}
+}
+
+func TestParseInstalledInvalid(t *testing.T) {
+	for _, s := range []string{
+		"P:musl\n",
+		"P:musl\nV:1.2.4-r2\ninvalid\n",
+	} {
+		if _, err := ParseInstalled(strings.NewReader(s)); err == nil {
+			t.Errorf("%q was expected to fail, but succeeded", s)
+		}
+	}
+}
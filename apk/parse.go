@@ -0,0 +1,110 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apk
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// version is numbers separated by dots, optional letter, suffixes, optional commit hash and revision
+	// https://wiki.alpinelinux.org/wiki/APKBUILD_Reference#pkgver
+	versionRegex = regexp.MustCompile(`^(\d+(?:\.\d+)*)([a-z]?)((?:_(?:alpha|beta|pre|rc|cvs|svn|git|hg|p)\d*)*)(?:~[0-9a-f]+)?(?:-r(\d+))?$`)
+	suffixRegex  = regexp.MustCompile(`_(alpha|beta|pre|rc|cvs|svn|git|hg|p)(\d*)`)
+)
+
+// Package represents one alpine package
+type Package struct {
+	Name    string
+	Version string
+}
+
+// Parse parses the package in name-version-rREVISION[.apk] format
+// e.g. musl-1.1.24-r2 or py3-pip-20.1.1-r0.apk
+func Parse(pkg string) (*Package, error) {
+	pkg = strings.TrimSuffix(pkg, ".apk")
+
+	// revision
+	i := strings.LastIndexByte(pkg, '-')
+	if i < 0 || !strings.HasPrefix(pkg[i+1:], "r") {
+		return nil, fmt.Errorf("can't find revision in pkg %q", pkg)
+	}
+
+	// version
+	j := strings.LastIndexByte(pkg[:i], '-')
+	if j <= 0 {
+		return nil, fmt.Errorf("can't find version in pkg %q", pkg)
+	}
+
+	p := Package{
+		Name:    strings.ToLower(pkg[:j]),
+		Version: pkg[j+1:],
+	}
+	if !Valid(p.Version) {
+		return nil, fmt.Errorf("invalid version %q in pkg %q", p.Version, pkg)
+	}
+
+	return &p, nil
+}
+
+// Valid returns whether the given string is a valid alpine version
+func Valid(version string) bool {
+	return versionRegex.MatchString(version)
+}
+
+// version is a parsed alpine version
+type version struct {
+	numbers  []string
+	letter   string
+	suffixes []suffix
+	revision string
+}
+
+type suffix struct {
+	// order of the suffix, negative for pre release suffixes
+	order  int
+	number string
+}
+
+// suffixes in order, pre release suffixes come before a version without suffix (order 0)
+var suffixOrder = map[string]int{
+	"alpha": -4,
+	"beta":  -3,
+	"pre":   -2,
+	"rc":    -1,
+	"cvs":   1,
+	"svn":   2,
+	"git":   3,
+	"hg":    4,
+	"p":     5,
+}
+
+func parseVersion(s string) (*version, error) {
+	m := versionRegex.FindStringSubmatch(s)
+	if m == nil {
+		return nil, fmt.Errorf("invalid version %q", s)
+	}
+	v := version{
+		numbers:  strings.Split(m[1], "."),
+		letter:   m[2],
+		revision: m[4],
+	}
+	for _, sm := range suffixRegex.FindAllStringSubmatch(m[3], -1) {
+		v.suffixes = append(v.suffixes, suffix{suffixOrder[sm[1]], sm[2]})
+	}
+	return &v, nil
+}
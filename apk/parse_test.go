@@ -0,0 +1,56 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apk
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		pkgStr string
+		pkg    Package
+		fail   bool
+	}{
+		{pkgStr: "", fail: true},
+		{pkgStr: "musl", fail: true},
+		{pkgStr: "musl-1.1.24", fail: true},
+		{pkgStr: "-1.1.24-r2", fail: true},
+		{pkgStr: "musl-version-r2", fail: true},
+		{pkgStr: "musl-1.1.24-r2", pkg: Package{"musl", "1.1.24-r2"}},
+		{pkgStr: "py3-pip-20.1.1-r0.apk", pkg: Package{"py3-pip", "20.1.1-r0"}},
+		{pkgStr: "openssl-1.1.1g_rc1_p2-r10", pkg: Package{"openssl", "1.1.1g_rc1_p2-r10"}},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			pkg, err := Parse(c.pkgStr)
+			if c.fail {
+				if err == nil {
+					t.Fatalf("parsing %q should fail, got %+v", c.pkgStr, pkg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*pkg, c.pkg) {
+				t.Fatalf("expecting %+v, got %+v", c.pkg, *pkg)
+			}
+		})
+	}
+}
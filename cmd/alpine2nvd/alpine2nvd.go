@@ -0,0 +1,28 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+
+	"github.com/facebookincubator/nvdtools/providers/alpine/api"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+)
+
+func main() {
+	flag.StringVar(&api.Branches, "branches", api.Branches, "Comma separated list of alpine branches to download")
+	flag.StringVar(&api.Repos, "repos", api.Repos, "Comma separated list of alpine repositories to download")
+	runner.Main("alpine")
+}
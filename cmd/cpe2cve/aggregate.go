@@ -0,0 +1,121 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/logging"
+)
+
+// aggregator merges assets sharing the same key, which are read by the underlying reader:
+// merged asset is the first one of them with the union of their CPE names, assets are returned in the order they were read;
+// assets without the key are passed through as they are
+type aggregator struct {
+	r      assetReader
+	cfg    config
+	assets []*asset
+	loaded bool
+}
+
+func newAggregator(r assetReader, cfg config) *aggregator {
+	return &aggregator{r: r, cfg: cfg}
+}
+
+// read is a part of the assetReader interface
+// all input is read and merged on the first call, read errors are logged
+func (ag *aggregator) read() (*asset, error) {
+	if !ag.loaded {
+		ag.load()
+		ag.loaded = true
+	}
+	if len(ag.assets) == 0 {
+		return nil, io.EOF
+	}
+	a := ag.assets[0]
+	ag.assets = ag.assets[1:]
+	return a, nil
+}
+
+// group is a merged asset
+type group struct {
+	*asset
+	cpes []string
+	seen map[string]bool
+}
+
+func (g *group) add(cpes []string) {
+	for _, cpe := range cpes {
+		if cpe != "" && !g.seen[cpe] {
+			g.seen[cpe] = true
+			g.cpes = append(g.cpes, cpe)
+		}
+	}
+}
+
+func (ag *aggregator) load() {
+	var groups []*group
+	byKey := make(map[string]*group)
+	for line := 1; ; line++ {
+		a, err := ag.r.read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			logging.Recordf("read error at line %d: %v", line, err)
+		}
+		if a == nil {
+			continue
+		}
+		key, cpes, ok := ag.key(a)
+		if !ok {
+			groups = append(groups, &group{asset: a})
+			continue
+		}
+		g := byKey[key]
+		if g == nil {
+			g = &group{asset: a, seen: make(map[string]bool)}
+			byKey[key] = g
+			groups = append(groups, g)
+		}
+		g.add(cpes)
+	}
+
+	ag.assets = make([]*asset, 0, len(groups))
+	for _, g := range groups {
+		if g.seen != nil {
+			if g.record != nil {
+				g.record[ag.cfg.CPEsAt-1] = strings.Join(g.cpes, ag.cfg.InRecordSeparator)
+			} else {
+				g.asset.cpes = g.cpes
+			}
+		}
+		ag.assets = append(ag.assets, g.asset)
+	}
+}
+
+// key returns the key and CPE names of the asset, false if it doesn't have the key
+func (ag *aggregator) key(a *asset) (string, []string, bool) {
+	if a.record == nil {
+		key, ok := a.metadata[ag.cfg.AssetKey]
+		return string(key), a.cpes, ok && ag.cfg.AssetKey != ""
+	}
+	keyAt, cpesAt := ag.cfg.AssetAt-1, ag.cfg.CPEsAt-1
+	if keyAt < 0 || keyAt >= len(a.record) || cpesAt >= len(a.record) {
+		return "", nil, false
+	}
+	return a.record[keyAt], strings.Split(a.record[cpesAt], ag.cfg.InRecordSeparator), true
+}
@@ -0,0 +1,142 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+func TestProcessInputAggregate(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	cache := cvefeed.NewCache(dict)
+
+	for i, tc := range []struct {
+		in     string
+		cfg    config
+		expect []string
+	}{
+		{
+			in: "host1;cpe:/o:microsoft:windows_10:-::~~~~x64~\n" +
+				"host2;cpe:/a:adobe:flash_player:24.0.0.194\n" +
+				"host1;cpe:/a:adobe:flash_player:24.0.0.194,cpe:/o:microsoft:windows_10:-::~~~~x64~\n",
+			cfg: config{CPEsAt: 2, CVEsAt: 2, MatchesAt: 3, EraseFields: fieldsToSkip{1: true}, InFieldSeparator: ";", OutFieldSeparator: ";"},
+			expect: []string{
+				"host1;CVE-2016-0165;cpe:/o:microsoft:windows_10:-::~~~~x64~",
+				"host1;CVE-2016-0165;cpe:/o:microsoft:windows_10:-::~~~~x64~",
+				"host1;CVE-2666-1337;cpe:/o:microsoft:windows_10:-::~~~~x64~,cpe:/a:adobe:flash_player:24.0.0.194",
+			},
+		},
+		{
+			in: "host1;cpe:/o:microsoft:windows_10:-::~~~~x64~\n" +
+				"host2;cpe:/a:adobe:flash_player:24.0.0.194\n" +
+				"host1;cpe:/a:adobe:flash_player:24.0.0.194,cpe:/o:microsoft:windows_10:-::~~~~x64~\n",
+			cfg: config{CPEsAt: 2, CVEsAt: 2, MatchesAt: 3, AssetAt: 1, EraseFields: fieldsToSkip{1: true}, InFieldSeparator: ";", OutFieldSeparator: ";"},
+			expect: []string{
+				"host1;CVE-2016-0165;cpe:/o:microsoft:windows_10:-::~~~~x64~",
+				"host1;CVE-2666-1337;cpe:/o:microsoft:windows_10:-::~~~~x64~,cpe:/a:adobe:flash_player:24.0.0.194",
+			},
+		},
+		{
+			in: "host1;cpe:/o:microsoft:windows_10:-::~~~~x64~\n" +
+				"host2;cpe:/a:adobe:flash_player:24.0.0.194\n" +
+				"host1;cpe:/a:adobe:flash_player:24.0.0.194\n",
+			cfg: config{CPEsAt: 2, CVEsAt: 2, MatchesAt: 3, AssetAt: 1, EraseFields: fieldsToSkip{1: true}, InFieldSeparator: ";", OutFieldSeparator: ";"},
+			expect: []string{
+				"host1;CVE-2016-0165;cpe:/o:microsoft:windows_10:-::~~~~x64~",
+				"host1;CVE-2666-1337;cpe:/o:microsoft:windows_10:-::~~~~x64~,cpe:/a:adobe:flash_player:24.0.0.194",
+			},
+		},
+		{
+			in: `{"host": "host1", "cpes": ["cpe:/o:microsoft:windows_10:-::~~~~x64~"]}` + "\n" +
+				`{"host": "host2", "cpes": ["cpe:/a:adobe:flash_player:24.0.0.194"]}` + "\n" +
+				`{"host": "host1", "cpes": ["cpe:/a:adobe:flash_player:24.0.0.194"]}` + "\n",
+			cfg: config{AssetKey: "host", InFieldSeparator: inputNDJSON, OutputTemplate: "{{.Metadata.host}};{{.CVE}};{{.MatchedCPE}}"},
+			expect: []string{
+				"host1;CVE-2016-0165;cpe:/o:microsoft:windows_10:-::~~~~x64~",
+				"host1;CVE-2666-1337;cpe:/o:microsoft:windows_10:-::~~~~x64~,cpe:/a:adobe:flash_player:24.0.0.194",
+			},
+		},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			cfg := tc.cfg
+			cfg.NumProcessors = 1
+			cfg.InRecordSeparator, cfg.OutRecordSeparator = ",", ","
+			if cfg.OutFieldSeparator == "" {
+				cfg.OutFieldSeparator = "\t"
+			}
+			cfg.Feeds = map[string][]string{"test": {"feed.json"}}
+			if err := cfg.validate(); err != nil {
+				t.Fatal(err)
+			}
+			var w bytes.Buffer
+			done := processInput(context.Background(), strings.NewReader(tc.in), &w, singleCache(cache), cfg)
+			<-done
+			got := strings.Split(strings.TrimSpace(w.String()), "\n")
+			for i, line := range got {
+				// order of matched CPEs isn't defined
+				fields := strings.Split(line, ";")
+				matches := strings.Split(fields[len(fields)-1], ",")
+				sort.Sort(sort.Reverse(sort.StringSlice(matches)))
+				fields[len(fields)-1] = strings.Join(matches, ",")
+				got[i] = strings.Join(fields, ";")
+			}
+			sort.Strings(got)
+			if strings.Join(got, "\n") != strings.Join(tc.expect, "\n") {
+				t.Fatalf("expecting:\n%s\ngot:\n%s", strings.Join(tc.expect, "\n"), strings.Join(got, "\n"))
+			}
+		})
+	}
+}
+
+func TestValidateAsset(t *testing.T) {
+	for i, tc := range []struct {
+		assetAt  int
+		assetKey string
+		inSep    string
+		ok       bool
+	}{
+		{assetAt: 1, inSep: "\t", ok: true},
+		{assetAt: -1, inSep: "\t", ok: false},
+		{assetKey: "host", inSep: "\t", ok: false},
+		{assetKey: "host", inSep: inputNDJSON, ok: true},
+		{assetAt: 1, inSep: inputNDJSON, ok: false},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			cfg := config{
+				CPEsAt:            1,
+				AssetAt:           tc.assetAt,
+				AssetKey:          tc.assetKey,
+				InFieldSeparator:  tc.inSep,
+				OutFieldSeparator: outputNDJSON,
+				Feeds:             map[string][]string{"test": {"feed.json"}},
+			}
+			if err := cfg.validate(); (err == nil) != tc.ok {
+				t.Fatalf("unexpected validation result: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/deb"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/amazon"
+	"github.com/facebookincubator/nvdtools/providers/debian"
+	"github.com/facebookincubator/nvdtools/providers/oracle"
+	"github.com/facebookincubator/nvdtools/providers/redhat"
+	"github.com/facebookincubator/nvdtools/providers/suse"
+	"github.com/facebookincubator/nvdtools/providers/ubuntu"
+	"github.com/facebookincubator/nvdtools/rpm"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// keys of the distribution CPE and installed packages in JSON input:
+//
+//	{"cpes": ["cpe:/a:openssl:openssl:1.1.1c"], "distro": "cpe:/o:redhat:enterprise_linux:8", "packages": ["openssl-1:1.1.1c-15.el8.x86_64"]}
+const (
+	assetDistroKey   = "distro"
+	assetPackagesKey = "packages"
+)
+
+// backportLoaders load checkers of distributions from feeds downloaded by their providers,
+// the checkers know which CVEs were fixed in distribution packages, usually by backporting the patches
+var backportLoaders = map[string]func(paths []string) (packageChecker, error){
+	"redhat": loadRedHatChecker,
+	"oracle": loadRPMChecker(func(path string) (rpm.Checker, error) {
+		load := oracle.LoadFeed
+		if isXML(path) {
+			load = oracle.LoadOVAL
+		}
+		feed, err := load(path)
+		if err != nil {
+			return nil, err
+		}
+		return feed.Checker()
+	}),
+	"amazon": loadRPMChecker(func(path string) (rpm.Checker, error) {
+		feed, err := amazon.LoadFeed(path)
+		if err != nil {
+			return nil, err
+		}
+		return feed.Checker()
+	}),
+	"suse": loadRPMChecker(func(path string) (rpm.Checker, error) {
+		feed, err := suse.LoadFeed(path)
+		if err != nil {
+			return nil, err
+		}
+		return feed.Checker()
+	}),
+	"debian": loadDebChecker(func(path string) (deb.Checker, error) {
+		feed, err := debian.LoadFeed(path)
+		if err != nil {
+			return nil, err
+		}
+		return feed.Checker()
+	}),
+	"ubuntu": loadDebChecker(func(path string) (deb.Checker, error) {
+		feed, err := ubuntu.LoadFeed(path)
+		if err != nil {
+			return nil, err
+		}
+		return feed.Checker()
+	}),
+}
+
+// packageChecker knows whether an installed package on the distribution has been fixed for the CVE
+type packageChecker interface {
+	// fixed returns an error if the package can't be parsed
+	fixed(pkg string, distro *wfn.Attributes, cve string) (bool, error)
+}
+
+type rpmChecker struct {
+	rpm.Checker
+}
+
+func (c rpmChecker) fixed(pkg string, distro *wfn.Attributes, cve string) (bool, error) {
+	p, err := rpm.Parse(pkg)
+	if err != nil {
+		return false, err
+	}
+	return c.Check(p, distro, cve), nil
+}
+
+type debChecker struct {
+	deb.Checker
+}
+
+func (c debChecker) fixed(pkg string, distro *wfn.Attributes, cve string) (bool, error) {
+	p, err := deb.Parse(pkg)
+	if err != nil {
+		return false, err
+	}
+	return c.Check(p, distro, cve), nil
+}
+
+// loadRedHatChecker merges all feeds before creating the checker, so OVAL streams replace fixed versions from the API
+func loadRedHatChecker(paths []string) (packageChecker, error) {
+	feed := make(redhat.Feed)
+	for _, path := range paths {
+		load := redhat.LoadFeed
+		if isXML(path) {
+			load = func(path string) (redhat.Feed, error) { return redhat.LoadOVAL(path) }
+		}
+		f, err := load(path)
+		if err != nil {
+			return nil, err
+		}
+		feed.Merge(f)
+	}
+	chk, err := feed.Checker()
+	if err != nil {
+		return nil, err
+	}
+	return rpmChecker{chk}, nil
+}
+
+func loadRPMChecker(load func(string) (rpm.Checker, error)) func([]string) (packageChecker, error) {
+	return func(paths []string) (packageChecker, error) {
+		chks := make([]rpm.Checker, 0, len(paths))
+		for _, path := range paths {
+			chk, err := load(path)
+			if err != nil {
+				return nil, err
+			}
+			chks = append(chks, chk)
+		}
+		return rpmChecker{rpm.CheckAny(chks...)}, nil
+	}
+}
+
+func loadDebChecker(load func(string) (deb.Checker, error)) func([]string) (packageChecker, error) {
+	return func(paths []string) (packageChecker, error) {
+		chks := make([]deb.Checker, 0, len(paths))
+		for _, path := range paths {
+			chk, err := load(path)
+			if err != nil {
+				return nil, err
+			}
+			chks = append(chks, chk)
+		}
+		return debChecker{deb.CheckAny(chks...)}, nil
+	}
+}
+
+// isXML returns true for OVAL definitions, which can be compressed
+func isXML(path string) bool {
+	return strings.HasSuffix(path, ".xml") || strings.Contains(path, ".xml.")
+}
+
+// parseBackports parses provider:path values of -backports into paths by provider
+func parseBackports(values []string) (map[string][]string, error) {
+	feeds := make(map[string][]string)
+	for _, v := range values {
+		i := strings.IndexByte(v, ':')
+		if i <= 0 || i == len(v)-1 {
+			return nil, fmt.Errorf("%q should be in provider:path format", v)
+		}
+		provider, path := v[:i], v[i+1:]
+		if _, ok := backportLoaders[provider]; !ok {
+			return nil, fmt.Errorf("unknown provider %q, should be one of %s", provider, strings.Join(backportProviders(), ", "))
+		}
+		feeds[provider] = append(feeds[provider], path)
+	}
+	return feeds, nil
+}
+
+func backportProviders() []string {
+	providers := make([]string, 0, len(backportLoaders))
+	for provider := range backportLoaders {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	return providers
+}
+
+// loadBackports loads checkers from the feeds set with -backports
+func (cfg *config) loadBackports() error {
+	feeds, err := parseBackports(cfg.Backports)
+	if err != nil {
+		return err
+	}
+	for _, provider := range backportProviders() {
+		if len(feeds[provider]) == 0 {
+			continue
+		}
+		chk, err := backportLoaders[provider](feeds[provider])
+		if err != nil {
+			return fmt.Errorf("can't load %s feeds: %v", provider, err)
+		}
+		cfg.backports = append(cfg.backports, chk)
+	}
+	return nil
+}
+
+// installed returns the distribution and packages of the asset, nil distro if they're not known
+func (cfg *config) installed(a *asset) (*wfn.Attributes, []string) {
+	var distro string
+	var pkgs []string
+	if a.record != nil {
+		if cfg.DistroAt > len(a.record) || cfg.PackagesAt > len(a.record) {
+			logging.Recordf("not enough fields in input (%d) for distro and packages", len(a.record))
+			return nil, nil
+		}
+		distro = a.record[cfg.DistroAt-1]
+		if s := a.record[cfg.PackagesAt-1]; s != "" {
+			pkgs = strings.Split(s, cfg.InRecordSeparator)
+		}
+	} else {
+		if data, ok := a.metadata[assetDistroKey]; ok {
+			if err := json.Unmarshal(data, &distro); err != nil {
+				logging.Recordf("can't decode %s of asset: %v", assetDistroKey, err)
+				return nil, nil
+			}
+		}
+		if data, ok := a.metadata[assetPackagesKey]; ok {
+			if err := json.Unmarshal(data, &pkgs); err != nil {
+				logging.Recordf("can't decode %s of asset: %v", assetPackagesKey, err)
+				return nil, nil
+			}
+		}
+	}
+	if distro == "" || len(pkgs) == 0 {
+		return nil, nil
+	}
+	attrs, err := wfn.Parse(distro)
+	if err != nil {
+		logging.Recordf("couldn't parse distro %q: %v", distro, err)
+		return nil, nil
+	}
+	return attrs, pkgs
+}
+
+// backported returns true if any of the packages has been fixed for any CVE of the vulnerability on the distribution
+// packages which can't be parsed by a checker are skipped, so rpm and deb distributions can be mixed in the input
+func (cfg *config) backported(vuln cvefeed.Vuln, distro *wfn.Attributes, pkgs []string) bool {
+	if distro == nil {
+		return false
+	}
+	for _, chk := range cfg.backports {
+		for _, pkg := range pkgs {
+			for _, cve := range vuln.CVEs() {
+				fixed, err := chk.fixed(pkg, distro, cve)
+				if err != nil {
+					logging.Debugf("couldn't parse package %q: %v", pkg, err)
+					break
+				}
+				if fixed {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
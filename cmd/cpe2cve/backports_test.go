@@ -0,0 +1,164 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+const testRedHatFeed = `{
+  "CVE-2016-0165": {
+    "name": "CVE-2016-0165",
+    "affected_release": {
+      "product_name": "Red Hat Enterprise Linux 8",
+      "advisory": "RHSA-2016:0001",
+      "package": "foo-1:1.0-2.el8",
+      "cpe": "cpe:/o:redhat:enterprise_linux:8"
+    }
+  }
+}`
+
+func TestProcessInputBackports(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cpe2cve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	feed := filepath.Join(dir, "redhat.json")
+	if err := ioutil.WriteFile(feed, []byte(testRedHatFeed), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	cfg := config{
+		NumProcessors:      1,
+		CPEsAt:             1,
+		DistroAt:           2,
+		PackagesAt:         3,
+		CVEsAt:             2,
+		EraseFields:        getSkip([]int{1, 2}),
+		InFieldSeparator:   "\t",
+		OutFieldSeparator:  "\t",
+		InRecordSeparator:  ",",
+		OutRecordSeparator: ",",
+		Backports:          multiString{"redhat:" + feed},
+	}
+	if err := cfg.loadBackports(); err != nil {
+		t.Fatal(err)
+	}
+
+	cpes := "cpe:/o:microsoft:windows_10:-::~~~~x64~,cpe:/a:adobe:flash_player:24.0.0.194"
+	in := strings.Join([]string{
+		// fixed
+		cpes + "\tcpe:/o:redhat:enterprise_linux:8\tbar-2.0-1.el8.x86_64,foo-1:1.0-2.el8.x86_64",
+		// not fixed yet
+		cpes + "\tcpe:/o:redhat:enterprise_linux:8\tfoo-1:1.0-1.el8.x86_64",
+		// not fixed on this distro
+		cpes + "\tcpe:/o:redhat:enterprise_linux:7\tfoo-1:1.0-2.el7.x86_64",
+		// unknown distro
+		cpes + "\t\tfoo-1:1.0-2.el8.x86_64",
+	}, "\n")
+
+	var w bytes.Buffer
+	done := processInput(context.Background(), strings.NewReader(in), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+	<-done
+	got := strings.Split(strings.TrimSpace(w.String()), "\n")
+	sort.Strings(got)
+	expect := []string{
+		"bar-2.0-1.el8.x86_64,foo-1:1.0-2.el8.x86_64\tCVE-2666-1337",
+		"foo-1:1.0-1.el8.x86_64\tCVE-2016-0165",
+		"foo-1:1.0-1.el8.x86_64\tCVE-2666-1337",
+		"foo-1:1.0-2.el7.x86_64\tCVE-2016-0165",
+		"foo-1:1.0-2.el7.x86_64\tCVE-2666-1337",
+		"foo-1:1.0-2.el8.x86_64\tCVE-2016-0165",
+		"foo-1:1.0-2.el8.x86_64\tCVE-2666-1337",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(expect) {
+		t.Fatalf("expecting:\n%q\ngot:\n%q", expect, got)
+	}
+}
+
+func TestParseBackports(t *testing.T) {
+	for i, tc := range []struct {
+		values []string
+		expect map[string][]string
+	}{
+		{nil, map[string][]string{}},
+		{
+			[]string{"redhat:redhat.json", "redhat:/oval/rhel-8.oval.xml.bz2", "debian:debian.json"},
+			map[string][]string{
+				"redhat": {"redhat.json", "/oval/rhel-8.oval.xml.bz2"},
+				"debian": {"debian.json"},
+			},
+		},
+		{[]string{"redhat.json"}, nil},
+		{[]string{"redhat:"}, nil},
+		{[]string{"gentoo:gentoo.json"}, nil},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			got, err := parseBackports(tc.values)
+			if tc.expect == nil {
+				if err == nil {
+					t.Fatalf("expecting an error, got %v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tc.expect) {
+				t.Fatalf("expecting %v, got %v", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestValidateBackports(t *testing.T) {
+	for i, tc := range []struct {
+		cfg  config
+		fail bool
+	}{
+		{config{Backports: multiString{"redhat:redhat.json"}, DistroAt: 2, PackagesAt: 3}, false},
+		{config{Backports: multiString{"redhat:redhat.json"}, InputFormat: inputNDJSON, OutputFormat: outputNDJSON}, false},
+		{config{Backports: multiString{"redhat:redhat.json"}, DistroAt: 2}, true},
+		{config{Backports: multiString{"redhat:redhat.json"}, DistroAt: 2, PackagesAt: 3, AssetAt: 4}, true},
+		{config{Backports: multiString{"centos:centos.json"}, DistroAt: 2, PackagesAt: 3}, true},
+		{config{DistroAt: 2, PackagesAt: 3}, true},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			cfg := tc.cfg
+			cfg.CPEsAt, cfg.CVEsAt = 1, 1
+			cfg.Feeds = map[string][]string{"": {"nvd.json"}}
+			if err := cfg.validate(); (err != nil) != tc.fail {
+				t.Fatalf("expecting failure %t, got %v", tc.fail, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/logging"
+)
+
+// checkpointInterval is how often the checkpoint is saved while the input is processed,
+// it's saved when the process is interrupted as well
+const checkpointInterval = 10 * time.Second
+
+// checkpoint is the state of a run saved to the -checkpoint file: the number of input lines (or merged assets)
+// whose findings were written and the size of the output once they were
+type checkpoint struct {
+	Lines  uint64 `json:"lines"`
+	Output int64  `json:"output_bytes"`
+}
+
+// checkpointer saves the checkpoint of the run to path and resumes an interrupted one;
+// findings are written in the input order with checkpointing, so lines up to the checkpoint are done
+type checkpointer struct {
+	path string
+	// lines of the interrupted run, skipped when the input is read again
+	resumed uint64
+	// bytes written since the last line was done, by the goroutine writing findings
+	pending int64
+
+	mu    sync.Mutex
+	state checkpoint
+	saved time.Time
+}
+
+// loadCheckpoint loads the checkpoint of an interrupted run if there is one;
+// out is truncated to the size it had when the checkpoint was saved, so findings written after it aren't duplicated
+func (cfg *config) loadCheckpoint(out *os.File) error {
+	if cfg.Checkpoint == "" {
+		return nil
+	}
+	cp := &checkpointer{path: cfg.Checkpoint, saved: time.Now()}
+	data, err := ioutil.ReadFile(cfg.Checkpoint)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &cp.state); err != nil {
+			return fmt.Errorf("can't parse checkpoint %q: %v", cfg.Checkpoint, err)
+		}
+		cp.resumed = cp.state.Lines
+	}
+
+	fi, err := out.Stat()
+	if err != nil {
+		return err
+	}
+	switch {
+	case !fi.Mode().IsRegular():
+		if cp.resumed != 0 {
+			logging.Warningf("output isn't a file, findings written after the checkpoint was saved will be written again")
+		}
+	case cp.resumed == 0:
+		// output appended to a file starts at its end
+		cp.state.Output = fi.Size()
+	case fi.Size() < cp.state.Output:
+		return fmt.Errorf("output has %d bytes, less than %d when the checkpoint was saved; append it to the output of the interrupted run", fi.Size(), cp.state.Output)
+	default:
+		if err := out.Truncate(cp.state.Output); err != nil {
+			return fmt.Errorf("can't truncate output: %v", err)
+		}
+		if _, err := out.Seek(cp.state.Output, io.SeekStart); err != nil {
+			return fmt.Errorf("can't truncate output: %v", err)
+		}
+	}
+	if cp.resumed != 0 {
+		logging.Infof("resuming after %d lines processed", cp.resumed)
+	}
+	cfg.checkpoint = cp
+	return nil
+}
+
+// writer returns w which counts bytes written to it, they're part of the checkpoint once the line is done
+func (cp *checkpointer) writer(w io.Writer) io.Writer {
+	return &checkpointWriter{w: w, cp: cp}
+}
+
+// skip returns whether the n-th asset read (starting with 1) was processed by the interrupted run;
+// nothing is skipped if checkpointing is off
+func (cp *checkpointer) skip(n uint64) bool {
+	return cp != nil && n <= cp.resumed
+}
+
+// advance records that the findings of the next line were written, the checkpoint is saved once in a while
+func (cp *checkpointer) advance() {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.state.Lines++
+	cp.state.Output += cp.pending
+	cp.pending = 0
+	if time.Since(cp.saved) >= checkpointInterval {
+		if err := cp.saveLocked(); err != nil {
+			logging.Errorf("can't save checkpoint: %v", err)
+		}
+	}
+}
+
+// save saves the checkpoint
+func (cp *checkpointer) save() error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return cp.saveLocked()
+}
+
+func (cp *checkpointer) saveLocked() error {
+	data, err := json.Marshal(cp.state)
+	if err != nil {
+		return err
+	}
+	// replace the file at once, an interruption while it's written must not lose the previous checkpoint
+	tmp := cp.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, cp.path); err != nil {
+		return err
+	}
+	cp.saved = time.Now()
+	return nil
+}
+
+// remove removes the checkpoint once all input was processed, so the next run starts over
+func (cp *checkpointer) remove() error {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	if err := os.Remove(cp.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+type checkpointWriter struct {
+	w  io.Writer
+	cp *checkpointer
+}
+
+func (cw *checkpointWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.cp.pending += int64(n)
+	return n, err
+}
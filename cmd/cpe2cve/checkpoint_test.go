@@ -0,0 +1,127 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+func TestProcessInputCheckpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "checkpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	feed := `{"CVE_Items": [{"cve": {"CVE_data_meta": {"ID": "CVE-0001"}},
+		"configurations": {"nodes": [{"operator": "OR", "cpe_match": [{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:vendor:product:1.0:*:*:*:*:*:*:*"}]}]}}]}`
+	vulns, err := cvefeed.ParseJSON(strings.NewReader(feed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dict := cvefeed.Dictionary{}
+	for _, v := range vulns {
+		dict[v.ID()] = v
+	}
+	in := "host1,cpe:/a:vendor:product:1.0\nhost2,cpe:/a:vendor:other:1.0\nhost3,cpe:/a:vendor:product:1.0\n"
+	host1 := "host1|cpe:/a:vendor:product:1.0|CVE-0001\n"
+	expect := host1 + "host3|cpe:/a:vendor:product:1.0|CVE-0001\n"
+	cpPath := filepath.Join(dir, "checkpoint.json")
+
+	// run writes the output to a file which has prefix already and returns its contents
+	run := func(t *testing.T, prefix string) string {
+		outPath := filepath.Join(dir, "out.csv")
+		if err := ioutil.WriteFile(outPath, []byte(prefix), 0644); err != nil {
+			t.Fatal(err)
+		}
+		out, err := os.OpenFile(outPath, os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer out.Close()
+
+		cfg := config{
+			NumProcessors:      2,
+			Ordered:            true,
+			CPEsAt:             2,
+			CVEsAt:             3,
+			InFieldSeparator:   ",",
+			OutFieldSeparator:  "|",
+			InRecordSeparator:  "+",
+			OutRecordSeparator: "&",
+			Checkpoint:         cpPath,
+		}
+		if err := cfg.loadCheckpoint(out); err != nil {
+			t.Fatal(err)
+		}
+		done := processInput(context.Background(), strings.NewReader(in), out, singleCache(cvefeed.NewCache(dict)), cfg)
+		<-done
+		data, err := ioutil.ReadFile(outPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cfg.checkpoint.state != (checkpoint{Lines: 3, Output: int64(len(data))}) {
+			t.Fatalf("unexpected checkpoint %+v of %d bytes of output", cfg.checkpoint.state, len(data))
+		}
+		return string(data)
+	}
+
+	t.Run("start", func(t *testing.T) {
+		if got := run(t, ""); got != expect {
+			t.Fatalf("expected output\n%s\ngot\n%s", expect, got)
+		}
+	})
+
+	t.Run("append", func(t *testing.T) {
+		prefix := "previous output\n"
+		if got := run(t, prefix); got != prefix+expect {
+			t.Fatalf("expected output\n%s\ngot\n%s", prefix+expect, got)
+		}
+	})
+
+	t.Run("resume", func(t *testing.T) {
+		// the run was interrupted after the first line, while the third one was written
+		cp := &checkpointer{path: cpPath, state: checkpoint{Lines: 1, Output: int64(len(host1))}}
+		if err := cp.save(); err != nil {
+			t.Fatal(err)
+		}
+		if got := run(t, host1+"host3|cpe:/a:ven"); got != expect {
+			t.Fatalf("expected output\n%s\ngot\n%s", expect, got)
+		}
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		cp := &checkpointer{path: cpPath, state: checkpoint{Lines: 1, Output: int64(len(host1))}}
+		if err := cp.save(); err != nil {
+			t.Fatal(err)
+		}
+		out, err := os.Create(filepath.Join(dir, "out.csv"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer out.Close()
+		cfg := config{Checkpoint: cpPath}
+		if err := cfg.loadCheckpoint(out); err == nil {
+			t.Fatal("expected the output shorter than the checkpoint to fail")
+		}
+	})
+}
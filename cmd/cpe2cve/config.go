@@ -15,30 +15,95 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path"
+	"strings"
+	"text/template"
+	"time"
 
 	"github.com/BurntSushi/toml"
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/cvss3"
+	"github.com/facebookincubator/nvdtools/providers/epss"
+	"github.com/facebookincubator/nvdtools/providers/exploitdb"
+	exploitdbschema "github.com/facebookincubator/nvdtools/providers/exploitdb/schema"
+	"github.com/facebookincubator/nvdtools/providers/kev"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
 )
 
 type config struct {
 	// input fields
 	CPEsAt int
+	// merge input records sharing the asset key at this position, or the key of JSON input
+	AssetAt  int
+	AssetKey string
+	// distribution CPE and installed packages, used with Backports
+	DistroAt   int
+	PackagesAt int
+	// OS CPE at this position, or the key of JSON input: running_on conditions are matched only against it
+	OSAt  int
+	OSKey string
 	// output fields
 	CVEsAt     int
 	MatchesAt  int
 	CWEsAt     int
 	ProviderAt int
+	// output known exploited date
+	KnownExploitedAt int
+	// output EPSS fields
+	EPSSScoreAt      int
+	EPSSPercentileAt int
+	// output public exploits
+	ExploitsAt int
+	// output severity as rated by the policy
+	RiskAt int
+	// output description in this language
+	DescriptionAt int
+	Lang          string
+	// output why the CVE matched
+	ExplainAt int
 	// output score fields
 	CVSS2At int
 	CVSS3At int
+	CVSS4At int
 	CVSSAt  int
+	// skip matches with lower EPSS score
+	MinEPSS float64
+	// skip matches with lower CVSS score or other severity, scored with this CVSS version
+	MinCVSS     float64
+	Severities  string
+	CVSSVersion string
+	// temporal and environmental metrics applied to CVSS v3 vectors before filtering by score
+	CVSS3Environment string
+	// score vulnerabilities which don't have CVSS v3 vectors with their v2 vectors converted to v3
+	CVSS2ToCVSS3 bool
+	// comma separated CWEs: skip matches of vulnerabilities without any of IncludeCWEs or with any of ExcludeCWEs
+	IncludeCWEs string
+	ExcludeCWEs string
+	// comma separated vendor or vendor:product: match only vulnerabilities of AllowProducts, except DenyProducts
+	AllowProducts string
+	DenyProducts  string
+	// skip matches of vulnerabilities published or last modified before this time
+	PublishedSince string
+	ModifiedSince  string
 	// output deleted fields
 	EraseFields fieldsToSkip // []int
+	// read assets in this format (ndjson) instead of CSV records
+	InputFormat string
+	// output findings in this format (json or ndjson) instead of CSV records
+	OutputFormat string
+	// output findings with this text/template instead of CSV records
+	OutputTemplate string
+	// output a VEX document in this format instead of CSV records
+	VEXFormat string
+	VEXAuthor string
 
 	// separators
 	InFieldSeparator   string
@@ -48,22 +113,98 @@ type config struct {
 
 	// optimizations
 	NumProcessors  int
+	Ordered        bool
 	IndexDict      bool
 	CacheSize      int64
+	CacheEntries   int
+	CacheStats     bool
 	RequireVersion bool
+	Semver         bool
 
 	// profiling
 	CPUProfile    string
 	MemoryProfile string
+	// save the number of lines processed to this file and resume from it if it exists
+	Checkpoint string
+	// report progress every this duration, to ProgressFile if it's set and logs otherwise
+	Progress     string
+	ProgressFile string
+	// address to serve net/http/pprof handlers on
+	PprofAddr string
 
 	// feeds
 	FeedOverrides multiString // []string
 	Feeds         map[string][]string
+	// directory to cache feeds compiled for matching in
+	FeedCache string
+	// NVD Match Criteria API responses, to resolve CPE matches referencing match criteria by id
+	MatchCriteria multiString // []string
+	// CISA KEV catalog
+	KEVCatalog string
+	// FIRST EPSS scores
+	EPSSScores string
+	// Exploit-DB or Metasploit exploits
+	Exploits multiString // []string
+	// rules overriding scores and severities
+	Policy string
+	// rules suppressing false positives, and the file to write suppressed findings to
+	Suppress         string
+	SuppressedOutput string
+	// provider:path of distribution feeds, matches of CVEs fixed in installed packages are suppressed
+	Backports multiString // []string
+
+	// loaded from MatchCriteria
+	matchCriteria nvd.MatchCriteria
+	// cve id -> date added to the KEV catalog, loaded from KEVCatalog
+	knownExploited map[string]string
+	// loaded from EPSSScores
+	epssScores *epss.Scores
+	// loaded from Exploits
+	exploits exploitdbschema.Index
+	// loaded from Policy
+	policy *policy
+	// loaded from Suppress
+	suppressions *suppressions
+	// opened from SuppressedOutput, suppressed findings are dropped if it's nil
+	suppressedOut io.Writer
+	// loaded from Backports
+	backports []packageChecker
+	// parsed from Severities
+	severities map[string]bool
+	// parsed from IncludeCWEs and ExcludeCWEs
+	includeCWEs map[string]bool
+	excludeCWEs map[string]bool
+	// parsed from AllowProducts and DenyProducts
+	productFilter *cvefeed.ProductFilter
+	// parsed from CVSS3Environment
+	cvss3Env *cvss3.Vector
+	// parsed from PublishedSince and ModifiedSince
+	publishedSince time.Time
+	modifiedSince  time.Time
+	// parsed from OutputTemplate
+	template *template.Template
+	// parsed from Progress
+	progressEvery time.Duration
+	// tracks the progress when Progress is set
+	progress *progress
+	// loaded from Checkpoint
+	checkpoint *checkpointer
+	// collects VEX statements when VEXFormat is set
+	vex *vexCollector
+	// provider -> cache of overridden vulnerabilities as they were before the overrides,
+	// their matches which don't match anymore are reported as not affected in VEX
+	suppressed map[string]*cvefeed.Cache
 }
 
 func (cfg *config) addFlags() {
 	// input
 	flag.IntVar(&cfg.CPEsAt, "cpe", 0, "look for CPE names in input at this position (starts with 1)")
+	flag.IntVar(&cfg.AssetAt, "asset", 0, "merge input records with the same asset key at this position (starts with 1), so each CVE is reported once per asset with all CPEs of the asset it matches; requires the whole input to be read before matching")
+	flag.StringVar(&cfg.AssetKey, "asset_key", "", "merge JSON input assets with the same value of this key, as -asset does")
+	flag.IntVar(&cfg.DistroAt, "distro", 0, "look for the distribution CPE (e.g. cpe:/o:redhat:enterprise_linux:8) in input at this position (starts with 1); requires -backports")
+	flag.IntVar(&cfg.OSAt, "os", 0, "look for the operating system CPE in input at this position (starts with 1) and match running_on conditions of CVEs (operating systems which aren't vulnerable themselves) only against it, so CVEs of applications require the OS they run on")
+	flag.StringVar(&cfg.OSKey, "os_key", "", "look for the operating system CPE in this key of JSON input, as -os does")
+	flag.IntVar(&cfg.PackagesAt, "packages", 0, "look for installed packages (full rpm NEVRA or deb name_version_arch, separated with -d2) in input at this position (starts with 1); requires -backports")
 
 	// output
 	flag.IntVar(&cfg.CVEsAt, "cve", 0, "output CVEs at this position (starts with 1)")
@@ -72,27 +213,70 @@ func (cfg *config) addFlags() {
 	flag.IntVar(&cfg.ProviderAt, "provider_field", 0, "where should the provider be placed in the output (starts with 1).")
 	flag.IntVar(&cfg.CVSS2At, "cvss2", 0, "output CVSS 2.0 base score at this position (starts with 1)")
 	flag.IntVar(&cfg.CVSS3At, "cvss3", 0, "output CVSS 3.0 base score at this position (starts with 1)")
+	flag.IntVar(&cfg.CVSS4At, "cvss4", 0, "output CVSS 4.0 score at this position (starts with 1)")
 	flag.IntVar(&cfg.CVSSAt, "cvss", 0, "output CVSS base score (v3 if available, v2 otherwise) at this position (starts with 1)")
+	flag.IntVar(&cfg.KnownExploitedAt, "known_exploited", 0, "output the date when CVE was added to the CISA KEV catalog at this position, empty if it's not known to be exploited (starts with 1); requires -kev")
+	flag.IntVar(&cfg.EPSSScoreAt, "epss_score", 0, "output EPSS score of the CVE at this position, empty if it wasn't scored (starts with 1); requires -epss")
+	flag.IntVar(&cfg.EPSSPercentileAt, "epss_percentile", 0, "output EPSS percentile of the CVE at this position, empty if it wasn't scored (starts with 1); requires -epss")
+	flag.Float64Var(&cfg.MinEPSS, "min_epss", 0, "skip matches of CVEs with EPSS score lower than this; CVEs which weren't scored are skipped as well; requires -epss")
+	flag.Float64Var(&cfg.MinCVSS, "min_cvss", 0, "skip matches of CVEs with CVSS base score lower than this; CVEs which weren't scored are skipped as well")
+	flag.StringVar(&cfg.Severities, "severity", "", "comma separated list of severities (none, low, medium, high, critical) of CVSS base score, skip matches of CVEs with other severities")
+	flag.StringVar(&cfg.CVSSVersion, "cvss_version", "", "CVSS version (2, 3, 3.0, 3.1 or 4) of the score used by -min_cvss and -severity, skip matches of CVEs which weren't scored with it; v3 score is used if available, v2 otherwise, by default")
+	flag.StringVar(&cfg.CVSS3Environment, "cvss3_env", "", "slash separated CVSS v3 temporal and environmental metrics (e.g. CR:H/MAV:L/E:P) to re-score CVSS v3 vectors of CVEs with before -min_cvss and -severity are applied")
+	flag.BoolVar(&cfg.CVSS2ToCVSS3, "cvss2_to_cvss3", false, "score CVEs which weren't scored with CVSS v3 by converting their v2 vectors to v3 (heuristically, see cvss3.FromCVSS2), so -min_cvss and -severity rate all CVEs as v3 does")
+	flag.StringVar(&cfg.IncludeCWEs, "include_cwe", "", "comma separated list of CWEs (e.g. CWE-787,CWE-416 or 787,416), skip matches of CVEs without any of them")
+	flag.StringVar(&cfg.ExcludeCWEs, "exclude_cwe", "", "comma separated list of CWEs (e.g. NVD-CWE-noinfo,CWE-20), skip matches of CVEs with any of them")
+	flag.StringVar(&cfg.AllowProducts, "allow_products", "", "comma separated list of vendors or vendor:product pairs (e.g. openssl,haxx:curl), match only CVEs of these products; filtered out of the feeds before they're indexed")
+	flag.StringVar(&cfg.DenyProducts, "deny_products", "", "comma separated list of vendors or vendor:product pairs (e.g. linux:linux_kernel), skip CVEs which mention only these products; filtered out of the feeds before they're indexed")
+	flag.StringVar(&cfg.PublishedSince, "published_since", "", "skip matches of CVEs published before this time: RFC3339 time, date (2006-01-02) or duration relative to now (e.g. 90d, 2w, 36h)")
+	flag.StringVar(&cfg.ModifiedSince, "modified_since", "", "skip matches of CVEs last modified before this time: RFC3339 time, date (2006-01-02) or duration relative to now (e.g. 90d, 2w, 36h)")
+	flag.IntVar(&cfg.ExploitsAt, "exploits", 0, "output ids of public exploits of the CVE at this position, empty if there are none (starts with 1); requires -exploitdb")
+	flag.IntVar(&cfg.RiskAt, "risk", 0, "output severity of the CVE at this position, rated by -policy rules if it's set and as -severity rates it otherwise, empty if it isn't scored (starts with 1)")
+	flag.IntVar(&cfg.DescriptionAt, "description", 0, "output description of the CVE at this position (starts with 1), JSON and template outputs get the description as well; descriptions are loaded from the feeds only if it's set")
+	flag.IntVar(&cfg.ExplainAt, "explain", 0, "output why the CVE matched at this position (starts with 1): the branch of configuration nodes down to the CPE match criteria, with their version ranges, which matched; JSON and template outputs get the whole tree as explanation")
+	flag.StringVar(&cfg.Lang, "lang", cvefeed.DefaultLang, "language of descriptions output with -description, e.g. es or pt-BR; falls back to a dialect or the base language, then to "+cvefeed.DefaultLang+" and then to any available language")
+	flag.StringVar(&cfg.OutputTemplate, "template", "", "output findings with this Go template instead of CSV records, e.g. '{{.CVE}} {{.CVSS3.BaseScore}} {{.MatchedCPE}} {{.Published}}'; see README for the fields")
+	flag.StringVar(&cfg.VEXFormat, "vex", "", "output a VEX document in this format (openvex or cyclonedx) instead of CSV records; matches suppressed by override feeds (-r) are reported as not affected")
+	flag.StringVar(&cfg.VEXAuthor, "vex_author", "nvdtools", "author of the VEX document")
 	flag.Var(&cfg.EraseFields, "e", "comma separated list of fields to erase from output; starts at 1, supports ranges (e.g. 1-3); processed before the vulnerablitie field added")
 
 	// separators
-	flag.StringVar(&cfg.InFieldSeparator, "d", "\t", "input columns delimiter")
+	flag.StringVar(&cfg.InFieldSeparator, "d", "\t", "input columns delimiter, or ndjson to read an asset per line as a JSON object with cpes array instead of CSV records")
 	flag.StringVar(&cfg.InRecordSeparator, "d2", ",", "inner input columns delimiter: separates elements of list passed into a CSV columns")
-	flag.StringVar(&cfg.OutFieldSeparator, "o", "\t", "output columns delimiter")
+	flag.StringVar(&cfg.OutFieldSeparator, "o", "\t", "output columns delimiter, or json or ndjson to output findings as a JSON array or a JSON object per line instead of CSV records")
 	flag.StringVar(&cfg.OutRecordSeparator, "o2", ",", "inner output columns delimiter: separates elements of lists in output CSV columns")
 
 	// optimizations
 	flag.IntVar(&cfg.NumProcessors, "nproc", 1, "number of concurrent goroutines that perform CVE lookup")
+	flag.IntVar(&cfg.NumProcessors, "workers", 1, "same as -nproc")
+	flag.BoolVar(&cfg.Ordered, "ordered", false, "write findings in the order of the input when -nproc is greater than 1; a few assets per goroutine are matched ahead of the one written next, so a slow asset delays the output but memory stays bounded")
 	flag.BoolVar(&cfg.IndexDict, "idxd", false, "build and use an index for CVE dictionary: increases the processing speed, but might miss some matches")
 	flag.Int64Var(&cfg.CacheSize, "cache_size", 0, "limit the cache size to this amount in bytes; 0 removes the limit, -1 disables caching")
+	flag.IntVar(&cfg.CacheEntries, "cache_entries", 0, "limit the number of cached CPE lists (input lines or assets), evicting the least recently used ones; 0 removes the limit")
+	flag.BoolVar(&cfg.CacheStats, "cache_stats", false, "log hits, misses and evictions of the cache and its size when the input is processed, to tune -cache_size and -cache_entries")
 	flag.BoolVar(&cfg.RequireVersion, "require_version", false, "ignore matches of CPEs with version ANY")
+	flag.BoolVar(&cfg.Semver, "semver", false, "compare versions against version ranges of application CPEs as semantic versions, e.g. 2.0.0-rc.1 is below 2.0.0; versions which aren't semantic are compared as usual")
 
 	// profiling
 	flag.StringVar(&cfg.CPUProfile, "cpuprofile", "", "file to store CPU profile data to; empty value disables CPU profiling")
 	flag.StringVar(&cfg.MemoryProfile, "memprofile", "", "file to store memory profile data to; empty value disables memory profiling")
+	flag.StringVar(&cfg.Checkpoint, "checkpoint", "", "save the number of input lines processed and the size of the output to this file periodically and when interrupted, and resume an interrupted run from it; the output must be appended (>>) to the output of the interrupted run, it's truncated to the checkpoint so findings aren't duplicated; findings are written in the input order, as with -ordered")
+	flag.StringVar(&cfg.Progress, "progress", "", "report lines processed, findings, rate and estimated time left every this duration (e.g. 30s) while the input is processed; the time left is estimated only if the input is a regular file or merged with -asset")
+	flag.StringVar(&cfg.ProgressFile, "progress_file", "", "write the progress as a JSON object to this file, replacing it on each report, instead of logging it; requires -progress")
+	flag.StringVar(&cfg.PprofAddr, "pprof", "", "serve runtime profiling data on this address (e.g. localhost:6060) at /debug/pprof/ while the feeds are loaded and the input is processed; empty value disables it")
 
 	// feeds
 	flag.Var(&cfg.FeedOverrides, "r", "overRide: path to override feed, can be specified multiple times")
+	flag.StringVar(&cfg.FeedCache, "feed_cache", "", "directory to cache feeds compiled for matching in, keyed by checksums of the feeds; feeds which didn't change are loaded from the cache, so repeated runs start faster")
+	flag.Var(&cfg.MatchCriteria, "match_criteria", "path to NVD Match Criteria API 2.0 responses (can be gzipped), used to resolve CPE matches of 2.0 feeds which reference match criteria by id; can be specified multiple times")
+	flag.StringVar(&cfg.KEVCatalog, "kev", "", "path to the CISA Known Exploited Vulnerabilities catalog, used to annotate matched CVEs")
+	flag.Var(&cfg.Exploits, "exploitdb", "path to Exploit-DB files_exploits.csv, Metasploit modules_metadata_base.json or exploitdb2nvd output, can be specified multiple times")
+	flag.StringVar(&cfg.Policy, "policy", "", "path to a YAML (or JSON, if its extension is .json) policy file with rules overriding or adjusting scores and severities of CVEs by CVE id, vendor of matched CPEs or CWE, applied before -min_cvss and -severity; see README for the format")
+	flag.StringVar(&cfg.Suppress, "suppress", "", "path to a YAML file with rules suppressing false positives by CVE id and CPE pattern, with optional expiry and a justification; see README for the format")
+	flag.StringVar(&cfg.SuppressedOutput, "suppressed", "", "write findings suppressed by -suppress rules to this file for audit, in the output format; CSV records get the justification as the last field and JSON findings the rule as suppression; requires -suppress")
+	flag.Var(&cfg.Backports, "backports", "provider:path of a distribution feed ("+strings.Join(backportProviders(), ", ")+"), matches of CVEs which the distribution fixed in any of the installed packages are suppressed; "+
+		"redhat and oracle OVAL definitions are supported as well; can be specified multiple times; requires -distro and -packages, or distro and packages keys of JSON input")
+	flag.StringVar(&cfg.EPSSScores, "epss", "", "path or http(s) url of the FIRST EPSS scores CSV (can be gzipped), e.g. https://epss.cyentia.com/epss_scores-current.csv.gz")
 }
 
 func (cfg *config) addFeedsFromArgs(provider string, feedFiles ...string) {
@@ -103,6 +287,43 @@ func (cfg *config) addFeedsFromArgs(provider string, feedFiles ...string) {
 }
 
 func (cfg *config) validate() error {
+	if cfg.InFieldSeparator == inputNDJSON {
+		cfg.InputFormat, cfg.InFieldSeparator = cfg.InFieldSeparator, "\t"
+	}
+	switch cfg.InputFormat {
+	case "", inputNDJSON:
+	default:
+		return fmt.Errorf("input format is invalid %q, should be %s", cfg.InputFormat, inputNDJSON)
+	}
+	switch cfg.OutFieldSeparator {
+	case outputJSON, outputNDJSON:
+		cfg.OutputFormat, cfg.OutFieldSeparator = cfg.OutFieldSeparator, "\t"
+	}
+	switch cfg.OutputFormat {
+	case "", outputJSON, outputNDJSON:
+	default:
+		return fmt.Errorf("output format is invalid %q, should be %s or %s", cfg.OutputFormat, outputJSON, outputNDJSON)
+	}
+	if cfg.OutputFormat != "" && cfg.VEXFormat != "" {
+		return fmt.Errorf("-vex can't be used with %s output", cfg.OutputFormat)
+	}
+	if cfg.OutputTemplate != "" {
+		if cfg.OutputFormat != "" {
+			return fmt.Errorf("-template can't be used with %s output", cfg.OutputFormat)
+		}
+		if cfg.VEXFormat != "" {
+			return fmt.Errorf("-template can't be used with -vex")
+		}
+		tmpl, err := parseTemplate(cfg.OutputTemplate)
+		if err != nil {
+			return fmt.Errorf("can't parse output template: %v", err)
+		}
+		cfg.template = tmpl
+	}
+	if cfg.InputFormat != "" && cfg.OutputFormat == "" && cfg.OutputTemplate == "" && cfg.VEXFormat == "" {
+		return fmt.Errorf("%s input requires %s or %s output, -template or -vex", cfg.InputFormat, outputJSON, outputNDJSON)
+	}
+
 	if len(cfg.Feeds) == 0 {
 		return fmt.Errorf("feed files weren't provided")
 	}
@@ -114,10 +335,44 @@ func (cfg *config) validate() error {
 		}
 	}
 
-	if cfg.CPEsAt <= 0 {
+	if cfg.CPEsAt <= 0 && cfg.InputFormat == "" {
 		return fmt.Errorf("-cpe flag wasn't provided")
 	}
-	if cfg.CVEsAt <= 0 {
+	if cfg.AssetAt < 0 {
+		return fmt.Errorf("-asset value is invalid %d", cfg.AssetAt)
+	}
+	if cfg.AssetAt != 0 && cfg.InputFormat != "" {
+		return fmt.Errorf("-asset can't be used with %s input, use -asset_key", cfg.InputFormat)
+	}
+	if cfg.AssetKey != "" && cfg.InputFormat == "" {
+		return fmt.Errorf("-asset_key requires %s input, use -asset", inputNDJSON)
+	}
+	if cfg.OSAt < 0 {
+		return fmt.Errorf("-os value is invalid %d", cfg.OSAt)
+	}
+	if cfg.OSAt != 0 && cfg.InputFormat != "" {
+		return fmt.Errorf("-os can't be used with %s input, use -os_key", cfg.InputFormat)
+	}
+	if cfg.OSKey != "" && cfg.InputFormat == "" {
+		return fmt.Errorf("-os_key requires %s input, use -os", inputNDJSON)
+	}
+	if cfg.DistroAt < 0 || cfg.PackagesAt < 0 {
+		return fmt.Errorf("-distro and -packages values are invalid %d, %d", cfg.DistroAt, cfg.PackagesAt)
+	}
+	if len(cfg.Backports) != 0 {
+		if _, err := parseBackports(cfg.Backports); err != nil {
+			return fmt.Errorf("-backports value is invalid: %v", err)
+		}
+		if cfg.InputFormat == "" && (cfg.DistroAt == 0 || cfg.PackagesAt == 0) {
+			return fmt.Errorf("-backports requires -distro and -packages")
+		}
+		if cfg.AssetAt != 0 || cfg.AssetKey != "" {
+			return fmt.Errorf("-backports can't be used with merged assets, packages of the merged records aren't known")
+		}
+	} else if cfg.DistroAt != 0 || cfg.PackagesAt != 0 {
+		return fmt.Errorf("-distro and -packages require -backports")
+	}
+	if cfg.CVEsAt <= 0 && cfg.VEXFormat == "" && cfg.OutputFormat == "" && cfg.OutputTemplate == "" {
 		return fmt.Errorf("-cve flag wasn't provided")
 	}
 	if cfg.MatchesAt < 0 {
@@ -132,12 +387,257 @@ func (cfg *config) validate() error {
 	if cfg.CVSS3At < 0 {
 		return fmt.Errorf("-cvss2 value is invalid %d", cfg.CVSS3At)
 	}
+	if cfg.CVSS4At < 0 {
+		return fmt.Errorf("-cvss4 value is invalid %d", cfg.CVSS4At)
+	}
 	if cfg.CVSSAt < 0 {
 		return fmt.Errorf("-cvss value is invalid %d", cfg.CVSSAt)
 	}
+	if cfg.KnownExploitedAt < 0 {
+		return fmt.Errorf("-known_exploited value is invalid %d", cfg.KnownExploitedAt)
+	}
+	if cfg.KnownExploitedAt != 0 && cfg.KEVCatalog == "" {
+		return fmt.Errorf("-known_exploited requires -kev catalog")
+	}
+	if cfg.ExploitsAt < 0 {
+		return fmt.Errorf("-exploits value is invalid %d", cfg.ExploitsAt)
+	}
+	if cfg.ExploitsAt != 0 && len(cfg.Exploits) == 0 {
+		return fmt.Errorf("-exploits requires -exploitdb")
+	}
+	if cfg.RiskAt < 0 {
+		return fmt.Errorf("-risk value is invalid %d", cfg.RiskAt)
+	}
+	if cfg.Checkpoint != "" {
+		switch {
+		case cfg.OutputFormat == outputJSON:
+			return fmt.Errorf("-checkpoint can't be used with %s output, use %s", outputJSON, outputNDJSON)
+		case cfg.VEXFormat != "":
+			return fmt.Errorf("-checkpoint can't be used with -vex")
+		case cfg.SuppressedOutput != "":
+			return fmt.Errorf("-checkpoint can't be used with -suppressed")
+		}
+		// lines are done in the input order, so the checkpoint is the number of lines done
+		cfg.Ordered = true
+	}
+	if cfg.Progress != "" {
+		d, err := time.ParseDuration(cfg.Progress)
+		if err != nil || d <= 0 {
+			return fmt.Errorf("-progress value is invalid %q", cfg.Progress)
+		}
+		cfg.progressEvery = d
+	} else if cfg.ProgressFile != "" {
+		return fmt.Errorf("-progress_file requires -progress")
+	}
+	if cfg.CacheEntries < 0 {
+		return fmt.Errorf("-cache_entries value is invalid %d", cfg.CacheEntries)
+	}
+	if cfg.DescriptionAt < 0 {
+		return fmt.Errorf("-description value is invalid %d", cfg.DescriptionAt)
+	}
+	if cfg.ExplainAt < 0 {
+		return fmt.Errorf("-explain value is invalid %d", cfg.ExplainAt)
+	}
+	if cfg.EPSSScoreAt < 0 {
+		return fmt.Errorf("-epss_score value is invalid %d", cfg.EPSSScoreAt)
+	}
+	if cfg.EPSSPercentileAt < 0 {
+		return fmt.Errorf("-epss_percentile value is invalid %d", cfg.EPSSPercentileAt)
+	}
+	if cfg.MinEPSS < 0 || cfg.MinEPSS > 1 {
+		return fmt.Errorf("-min_epss value is invalid %v, should be between 0 and 1", cfg.MinEPSS)
+	}
+	if (cfg.EPSSScoreAt != 0 || cfg.EPSSPercentileAt != 0 || cfg.MinEPSS != 0) && cfg.EPSSScores == "" {
+		return fmt.Errorf("-epss_score, -epss_percentile and -min_epss require -epss scores")
+	}
+	if cfg.MinCVSS < 0 || cfg.MinCVSS > 10 {
+		return fmt.Errorf("-min_cvss value is invalid %v, should be between 0 and 10", cfg.MinCVSS)
+	}
+	severities, err := parseSeverities(cfg.Severities)
+	if err != nil {
+		return fmt.Errorf("-severity value is invalid: %v", err)
+	}
+	cfg.severities = severities
+	if cfg.includeCWEs, err = parseCWEs(cfg.IncludeCWEs); err != nil {
+		return fmt.Errorf("-include_cwe value is invalid: %v", err)
+	}
+	if cfg.excludeCWEs, err = parseCWEs(cfg.ExcludeCWEs); err != nil {
+		return fmt.Errorf("-exclude_cwe value is invalid: %v", err)
+	}
+	if cfg.AllowProducts != "" || cfg.DenyProducts != "" {
+		if cfg.productFilter, err = cvefeed.NewProductFilter(splitList(cfg.AllowProducts), splitList(cfg.DenyProducts)); err != nil {
+			return fmt.Errorf("-allow_products or -deny_products value is invalid: %v", err)
+		}
+	}
+	if cfg.cvss3Env, err = parseCVSS3Environment(cfg.CVSS3Environment); err != nil {
+		return fmt.Errorf("-cvss3_env value is invalid: %v", err)
+	}
+	switch cfg.CVSSVersion {
+	case "", "2", "3", "3.0", "3.1", "4":
+	default:
+		return fmt.Errorf("-cvss_version value is invalid %q, should be 2, 3, 3.0, 3.1 or 4", cfg.CVSSVersion)
+	}
+	now := time.Now()
+	if cfg.publishedSince, err = parseSince(cfg.PublishedSince, now); err != nil {
+		return fmt.Errorf("-published_since value is invalid: %v", err)
+	}
+	if cfg.modifiedSince, err = parseSince(cfg.ModifiedSince, now); err != nil {
+		return fmt.Errorf("-modified_since value is invalid: %v", err)
+	}
+	switch cfg.VEXFormat {
+	case "", vexOpenVEX, vexCycloneDX:
+	default:
+		return fmt.Errorf("-vex value is invalid %q, should be %s or %s", cfg.VEXFormat, vexOpenVEX, vexCycloneDX)
+	}
+	if cfg.SuppressedOutput != "" {
+		if cfg.Suppress == "" {
+			return fmt.Errorf("-suppressed requires -suppress")
+		}
+		if cfg.VEXFormat != "" {
+			return fmt.Errorf("-suppressed can't be used with -vex, suppressed findings are reported as not affected")
+		}
+	}
+	return nil
+}
+
+// loadDictionary loads dictionary from the feeds, using the feed cache if it's configured;
+// descriptions are dropped unless they're output
+func (cfg *config) loadDictionary(ctx context.Context, paths ...string) (cvefeed.Dictionary, error) {
+	compact := cfg.DescriptionAt == 0
+	if cfg.FeedCache != "" {
+		return cvefeed.LoadCachedResolvedJSONDictionaryContext(ctx, cfg.FeedCache, cfg.matchCriteria, compact, paths...)
+	}
+	return cvefeed.LoadResolvedJSONDictionaryContext(ctx, cfg.matchCriteria, compact, paths...)
+}
+
+// loadMatchCriteria loads match criteria to resolve CPE matches of the feeds with
+func (cfg *config) loadMatchCriteria(ctx context.Context) error {
+	if len(cfg.MatchCriteria) == 0 {
+		return nil
+	}
+	criteria, err := cvefeed.LoadMatchCriteriaContext(ctx, cfg.MatchCriteria...)
+	if err != nil {
+		return err
+	}
+	cfg.matchCriteria = criteria
+	return nil
+}
+
+// loadKEVCatalog loads dates when CVEs were added to the KEV catalog
+func (cfg *config) loadKEVCatalog() error {
+	if cfg.KEVCatalog == "" {
+		return nil
+	}
+	catalog, err := kev.LoadCatalog(cfg.KEVCatalog)
+	if err != nil {
+		return err
+	}
+	cfg.knownExploited = make(map[string]string, len(catalog.Vulnerabilities))
+	for _, vuln := range catalog.Vulnerabilities {
+		cfg.knownExploited[vuln.CVEID] = vuln.DateAdded
+	}
+	return nil
+}
+
+// loadPolicy loads the policy rules
+func (cfg *config) loadPolicy() error {
+	if cfg.Policy == "" {
+		return nil
+	}
+	p, err := loadPolicy(cfg.Policy)
+	if err != nil {
+		return err
+	}
+	cfg.policy = p
 	return nil
 }
 
+// loadSuppressions loads the suppression rules which haven't expired yet
+func (cfg *config) loadSuppressions() error {
+	if cfg.Suppress == "" {
+		return nil
+	}
+	s, err := loadSuppressions(cfg.Suppress, time.Now())
+	if err != nil {
+		return err
+	}
+	cfg.suppressions = s
+	return nil
+}
+
+// dateAdded returns the date when any of the CVEs of the vulnerability was added to the KEV catalog
+func (cfg *config) dateAdded(vuln cvefeed.Vuln) string {
+	for _, cve := range vuln.CVEs() {
+		if date, ok := cfg.knownExploited[cve]; ok {
+			return date
+		}
+	}
+	return ""
+}
+
+// loadEPSSScores loads EPSS scores from a file or downloads them
+func (cfg *config) loadEPSSScores(ctx context.Context) error {
+	if cfg.EPSSScores == "" {
+		return nil
+	}
+	if !strings.HasPrefix(cfg.EPSSScores, "http://") && !strings.HasPrefix(cfg.EPSSScores, "https://") {
+		scores, err := epss.LoadScores(cfg.EPSSScores)
+		cfg.epssScores = scores
+		return err
+	}
+	resp, err := client.Get(ctx, client.Default(), cfg.EPSSScores, http.Header{})
+	if err != nil {
+		return fmt.Errorf("can't download %q: %v", cfg.EPSSScores, err)
+	}
+	defer resp.Body.Close()
+	scores, err := epss.ReadScores(resp.Body)
+	cfg.epssScores = scores
+	return err
+}
+
+// epssScore returns the highest EPSS score among the CVEs of the vulnerability, nil if none was scored
+func (cfg *config) epssScore(vuln cvefeed.Vuln) *epss.Score {
+	if cfg.epssScores == nil {
+		return nil
+	}
+	var max *epss.Score
+	for _, cve := range vuln.CVEs() {
+		if score := cfg.epssScores.Get(cve); score != nil && (max == nil || score.EPSS > max.EPSS) {
+			max = score
+		}
+	}
+	return max
+}
+
+// loadExploits loads public exploits
+func (cfg *config) loadExploits() error {
+	if len(cfg.Exploits) == 0 {
+		return nil
+	}
+	idx, err := exploitdb.LoadIndex(cfg.Exploits...)
+	cfg.exploits = idx
+	return err
+}
+
+// exploitIDs returns ids of public exploits of any of the CVEs of the vulnerability
+func (cfg *config) exploitIDs(vuln cvefeed.Vuln) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, cve := range vuln.CVEs() {
+		v, ok := cfg.exploits[cve]
+		if !ok {
+			continue
+		}
+		for _, exploit := range v.Exploits {
+			if !seen[exploit.ID] {
+				seen[exploit.ID] = true
+				ids = append(ids, exploit.ID)
+			}
+		}
+	}
+	return ids
+}
+
 func readConfigFile(file string) (config, error) {
 	f, err := os.Open(file)
 	if err != nil {
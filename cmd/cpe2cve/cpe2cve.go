@@ -15,179 +15,288 @@
 package main
 
 import (
-	"encoding/csv"
+	"context"
 	"flag"
 	"fmt"
 	"io"
+	"net/http"
+	_ "net/http/pprof"
 	"os"
 	"path"
 	"runtime"
 	"runtime/pprof"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"github.com/facebookincubator/flog"
 	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/logging"
+	_ "github.com/facebookincubator/nvdtools/semver" // semver version scheme
+	"github.com/facebookincubator/nvdtools/shutdown"
 	"github.com/facebookincubator/nvdtools/stats"
 	"github.com/facebookincubator/nvdtools/wfn"
 )
 
-func processAll(in <-chan []string, out chan<- []string, caches map[string]*cvefeed.Cache, cfg config, nlines *uint64) {
+func processAll(in <-chan *asset, out chan<- *finding, caches map[string]*cvefeed.Cache, cfg config, nlines *uint64) {
+	for a := range in {
+		processAsset(a, func(f *finding) { out <- f }, caches, cfg, nlines)
+		if cfg.checkpoint != nil {
+			out <- &finding{lineDone: true}
+		}
+	}
+}
+
+// processAsset matches CPEs of the asset and calls emit for each finding
+func processAsset(a *asset, emit func(*finding), caches map[string]*cvefeed.Cache, cfg config, nlines *uint64) {
 	cpesAt := cfg.CPEsAt - 1
-	for rec := range in {
+	rec, cpeList := a.record, a.cpes
+	if rec != nil {
 		if cpesAt >= len(rec) {
-			flog.Errorf("not enough fields in input (%d)", len(rec))
-			continue
+			logging.Recordf("not enough fields in input (%d)", len(rec))
+			return
 		}
+		cpeList = strings.Split(rec[cpesAt], cfg.InRecordSeparator)
+		rec[cpesAt] = strings.Join(cpeList, cfg.OutRecordSeparator)
+	}
+	if stats.AreLogged() {
+		stats.IncrementCounter("line.total")
+	}
+	cpes := make([]*wfn.Attributes, 0, len(cpeList))
+	for _, uri := range cpeList {
 		if stats.AreLogged() {
-			stats.IncrementCounter("line.total")
+			stats.IncrementCounter("cpe.total")
+		}
+		attr, err := wfn.Parse(uri)
+		if err != nil {
+			logging.Recordf("couldn't parse uri %q: %v", uri, err)
+			continue
 		}
-		cpeList := strings.Split(rec[cpesAt], cfg.InRecordSeparator)
-		cpes := make([]*wfn.Attributes, 0, len(cpeList))
-		for _, uri := range cpeList {
+		cpes = append(cpes, attr)
+	}
+
+	var distro *wfn.Attributes
+	var pkgs []string
+	if len(cfg.backports) != 0 {
+		distro, pkgs = cfg.installed(a)
+	}
+	var platforms []*wfn.Attributes
+	if cfg.platformAware() {
+		platforms = cfg.platforms(a)
+	}
+
+	// if performance seems to be the issue, we could try to make these cache.Get's concurrent:
+	//
+	// wg := sync.WaitGroup{}
+	// for provider, cache := range caches {
+	// 	provider, cache := provider, cache
+	// 	wg.Add(1)
+	// 	go func() {
+	// 		defer wg.Done()
+	// 		for _, matches := range cache.Get(cpes) {
+	// ...
+	for provider, cache := range caches {
+		for _, matches := range cfg.lookup(cache, cpes, platforms) {
+			ml := len(matches.CPEs)
 			if stats.AreLogged() {
-				stats.IncrementCounter("cpe.total")
+				stats.IncrementCounterBy("cpe.match", int64(ml))
+				if ml != 0 {
+					stats.IncrementCounter("line.match")
+				}
+			}
+			matchingCPEs := make([]string, ml)
+			for i, attr := range matches.CPEs {
+				if attr == nil {
+					logging.Recordf("%s matches nil CPE", matches.CVE.ID())
+					continue
+				}
+				matchingCPEs[i] = (*wfn.Attributes)(attr).BindToURI()
 			}
-			attr, err := wfn.Parse(uri)
-			if err != nil {
-				flog.Errorf("couldn't parse uri %q: %v", uri, err)
+			if cfg.skipSeverity(matches.CVE, matches.CPEs) || cfg.skipDate(matches.CVE) || cfg.skipCWE(matches.CVE) {
 				continue
 			}
-			cpes = append(cpes, attr)
-		}
-		rec[cpesAt] = strings.Join(cpeList, cfg.OutRecordSeparator)
-
-		// if performance seems to be the issue, we could try to make these cache.Get's concurrent:
-		//
-		// wg := sync.WaitGroup{}
-		// for provider, cache := range caches {
-		// 	provider, cache := provider, cache
-		// 	wg.Add(1)
-		// 	go func() {
-		// 		defer wg.Done()
-		// 		for _, matches := range cache.Get(cpes) {
-		// ...
-		for provider, cache := range caches {
-			for _, matches := range cache.Get(cpes) {
-				ml := len(matches.CPEs)
+			if cfg.backported(matches.CVE, distro, pkgs) {
 				if stats.AreLogged() {
-					stats.IncrementCounterBy("cpe.match", int64(ml))
-					if ml != 0 {
-						stats.IncrementCounter("line.match")
-					}
+					stats.IncrementCounter("cve.backported")
 				}
-				matchingCPEs := make([]string, ml)
-				for i, attr := range matches.CPEs {
-					if attr == nil {
-						flog.Errorf("%s matches nil CPE", matches.CVE.ID())
-						continue
-					}
-					matchingCPEs[i] = (*wfn.Attributes)(attr).BindToURI()
+				if cfg.vex != nil {
+					cfg.vex.add(matches.CVE, matches.CPEs, vexNotAffected)
+				}
+				continue
+			}
+			score := cfg.epssScore(matches.CVE)
+			if cfg.MinEPSS != 0 && (score == nil || score.EPSS < cfg.MinEPSS) {
+				continue
+			}
+			rule := cfg.suppressions.match(matches.CVE, matches.CPEs)
+			if rule != nil {
+				if stats.AreLogged() {
+					stats.IncrementCounter("cve.suppressed")
+				}
+				if cfg.vex != nil {
+					cfg.vex.add(matches.CVE, matches.CPEs, vexNotAffected)
+					continue
 				}
-				rec2 := make([]string, len(rec))
-				copy(rec2, rec)
-				cvss := matches.CVE.CVSSv3BaseScore()
-				if cvss == 0 {
-					cvss = matches.CVE.CVSSv2BaseScore()
+				if cfg.suppressedOut == nil {
+					continue
 				}
-				rec2 = cfg.EraseFields.appendAt(
-					rec2,
-					cfg.CVEsAt-1, matches.CVE.ID(),
-					cfg.MatchesAt-1, strings.Join(matchingCPEs, cfg.OutRecordSeparator),
-					cfg.CWEsAt-1, strings.Join(matches.CVE.CWEs(), cfg.OutRecordSeparator),
-					cfg.CVSS2At-1, fmt.Sprintf("%.1f", matches.CVE.CVSSv2BaseScore()),
-					cfg.CVSS3At-1, fmt.Sprintf("%.1f", matches.CVE.CVSSv3BaseScore()),
-					cfg.CVSSAt-1, fmt.Sprintf("%.1f", cvss),
-					cfg.ProviderAt-1, provider,
-				)
-				out <- rec2
+			} else {
+				cfg.progress.found()
+			}
+			if cfg.vex != nil {
+				cfg.vex.add(matches.CVE, matches.CPEs, vexAffected)
+				continue
 			}
+			f := cfg.newFinding(a, cpeList, provider, matches.CVE, matchingCPEs, score)
+			f.Risk = cfg.risk(matches.CVE, matches.CPEs)
+			f.Explanation = cfg.explain(matches.CVE, matches.CPEs)
+			f.Suppression = rule
+			emit(f)
 		}
-
-		n := atomic.AddUint64(nlines, 1)
-		if n > 0 {
-			if n%10000 == 0 {
-				flog.V(1).Infoln(n, "lines processed")
-			} else if n%1000 == 0 {
-				flog.V(2).Infoln(n, "lines processed")
-			} else if n%100 == 0 {
-				flog.V(3).Infoln(n, "lines processed")
+		if cache := cfg.suppressed[provider]; cfg.vex != nil && cache != nil {
+			// collector keeps the products which are still affected after the overrides
+			for _, matches := range cfg.lookup(cache, cpes, platforms) {
+				cfg.vex.add(matches.CVE, matches.CPEs, vexNotAffected)
 			}
 		}
 	}
+
+	n := atomic.AddUint64(nlines, 1)
+	if n > 0 && n%10000 == 0 {
+		logging.Debugf("%d lines processed", n)
+	}
 }
 
-func processInput(in io.Reader, out io.Writer, caches map[string]*cvefeed.Cache, cfg config) chan struct{} {
+// processInput matches the assets read from in and writes findings to out in background,
+// the returned channel is closed when they're all written; it stops reading once the context is done
+func processInput(ctx context.Context, in io.Reader, out io.Writer, caches map[string]*cvefeed.Cache, cfg config) chan struct{} {
 	done := make(chan struct{})
-	procIn := make(chan []string)
-	procOut := make(chan []string)
+	procIn := make(chan *asset)
+	procOut := make(chan *finding)
+
+	var linesProcessed uint64
+	if cfg.progressEvery != 0 {
+		cfg.progress = newProgress(&linesProcessed, in)
+		in = cfg.progress.reader(in)
+	}
+
+	r := newAssetReader(in, cfg)
+	if cfg.AssetAt != 0 || cfg.AssetKey != "" {
+		r = newAggregator(r, cfg)
+	}
 
-	r := csv.NewReader(in)
-	r.Comma = rune(cfg.InFieldSeparator[0])
+	if cfg.checkpoint != nil {
+		out = cfg.checkpoint.writer(out)
+	}
+	w := newFindingWriter(out, cfg)
+	var sw findingWriter
+	if cfg.suppressedOut != nil {
+		sw = newFindingWriter(cfg.suppressedOut, cfg)
+	}
 
-	w := csv.NewWriter(out)
-	w.Comma = rune(cfg.OutFieldSeparator[0])
+	if cfg.VEXFormat != "" {
+		cfg.vex = newVEXCollector()
+	}
 
 	// spawn processing goroutines
-	var linesProcessed uint64
 	var procWG sync.WaitGroup
-	procWG.Add(cfg.NumProcessors)
-	for i := 0; i < cfg.NumProcessors; i++ {
+	if cfg.Ordered {
+		procWG.Add(1)
 		go func() {
-			processAll(procIn, procOut, caches, cfg, &linesProcessed)
+			processOrdered(procIn, procOut, caches, cfg, &linesProcessed)
 			procWG.Done()
 		}()
+	} else {
+		procWG.Add(cfg.NumProcessors)
+		for i := 0; i < cfg.NumProcessors; i++ {
+			go func() {
+				processAll(procIn, procOut, caches, cfg, &linesProcessed)
+				procWG.Done()
+			}()
+		}
 	}
 
 	// write processed results in background
 	go func() {
-		for rec := range procOut {
-			if err := w.Write(rec); err != nil {
-				flog.Errorf("write error: %v", err)
+		for f := range procOut {
+			if f.lineDone {
+				cfg.checkpoint.advance()
+				continue
+			}
+			fw := w
+			if f.Suppression != nil {
+				fw = sw
+			}
+			if err := fw.write(f); err != nil {
+				logging.Errorf("write error: %v", err)
 			}
-			w.Flush()
 		}
-		if err := w.Error(); err != nil {
-			flog.Errorf("write error: %v", err)
+		if sw != nil {
+			if err := sw.close(); err != nil {
+				logging.Errorf("write error: %v", err)
+			}
+		}
+		if cfg.vex != nil {
+			if err := cfg.vex.write(out, cfg.VEXFormat, cfg.VEXAuthor, time.Now()); err != nil {
+				logging.Errorf("write error: %v", err)
+			}
+		} else if err := w.close(); err != nil {
+			logging.Errorf("write error: %v", err)
 		}
 		close(done)
 	}()
 
 	start := time.Now()
+	if cfg.progress != nil {
+		cfg.progress.run(cfg.progressEvery, cfg.ProgressFile)
+	}
 	// main goroutine reads input and sends it to processors
-	for line := 1; ; line++ {
-		rec, err := r.Read()
+	var assets uint64
+	for line := 1; ctx.Err() == nil; line++ {
+		a, err := r.read()
 		if err != nil {
 			if err == io.EOF {
 				break
 			}
-			flog.Errorf("read error at line %d: %v", line, err)
+			logging.Recordf("read error at line %d: %v", line, err)
 		}
-		procIn <- rec
+		if ag, ok := r.(*aggregator); ok && line == 1 && a != nil && cfg.progress != nil {
+			// all input is merged on the first read, so the number of assets is known
+			cfg.progress.setAssets(len(ag.assets) + 1)
+		}
+		if a == nil {
+			continue
+		}
+		assets++
+		if cfg.checkpoint.skip(assets) {
+			continue
+		}
+		procIn <- a
 	}
 
 	close(procIn)
 	procWG.Wait()
 	close(procOut)
-	flog.V(1).Infof("processed %d lines in %v", linesProcessed, time.Since(start))
+	if cfg.progress != nil {
+		cfg.progress.close()
+	}
+	logging.Debugf("processed %d lines in %v", linesProcessed, time.Since(start))
 	return done
 }
 
 func init() {
-	flog.AddFlags(flag.CommandLine, nil)
+	logging.AddFlags()
 	stats.AddFlags()
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "usage: %s [flags] nvd_feed.xml.gz...\n", path.Base(os.Args[0]))
 		fmt.Fprintf(os.Stderr, "flags:\n")
 		flag.PrintDefaults()
-		if flog.V(1) {
+		if logging.Default().Level() <= logging.DebugLevel {
 			writeConfigFileDefinition(os.Stderr)
 		}
 		os.Exit(1)
 	}
-	flag.Set("logtostderr", "true")
 }
 
 func main() {
@@ -199,7 +308,7 @@ func Main() int {
 	var cfg config
 	cfg.addFlags()
 	provider := flag.String("provider", "", "feed provider. used as a provider name for the feeds passed in through the command line")
-	cfgFile := flag.String("config", "", "path to a config file (JSON or TOML); see usage to see how it's configured (pass -v=1 flag for verbose help). Mutually exclusive with command line flags => when used, other flags are ignored")
+	cfgFile := flag.String("config", "", "path to a config file (JSON or TOML); see usage to see how it's configured (pass -log_level=debug for verbose help). Mutually exclusive with command line flags => when used, other flags are ignored")
 	flag.Parse()
 
 	var err error
@@ -213,10 +322,20 @@ func Main() int {
 		err = cfg.validate()
 	}
 	if err != nil {
-		flog.Error(err)
+		logging.Errorf("%v", err)
 		flag.Usage()
 	}
 
+	if cfg.Semver {
+		wfn.SetPartVersionScheme("a", "semver")
+	}
+
+	if cfg.PprofAddr != "" {
+		go func() {
+			logging.Errorf("pprof server failed: %v", http.ListenAndServe(cfg.PprofAddr, nil))
+		}()
+	}
+
 	start := time.Now()
 
 	if stats.AreLogged() {
@@ -226,14 +345,22 @@ func Main() int {
 		}(start)
 	}
 
-	flog.V(1).Info("loading NVD feeds...")
+	ctx, stop := shutdown.Context(context.Background())
+	defer stop()
+
+	if err := cfg.loadMatchCriteria(ctx); err != nil {
+		logging.Errorf("failed to load match criteria: %v", err)
+		return -1
+	}
+
+	logging.Debugf("loading NVD feeds...")
 
 	var overrides cvefeed.Dictionary
 	dicts := map[string]cvefeed.Dictionary{} // provider -> dictionary
 	for provider, files := range cfg.Feeds {
-		dict, err := cvefeed.LoadJSONDictionary(files...)
+		dict, err := cfg.loadDictionary(ctx, files...)
 		if err != nil {
-			flog.Errorf("failed to load dictionary for provider %s: %v", provider, err)
+			logging.Errorf("failed to load dictionary for provider %s: %v", provider, err)
 		}
 		dicts[provider] = dict
 	}
@@ -246,38 +373,97 @@ func Main() int {
 		}
 	}
 	if allEmpty {
-		flog.Error(fmt.Errorf("all dictionaries are empty"))
+		logging.Errorf("all dictionaries are empty")
 		return -1
 	}
 
-	overrides, err = cvefeed.LoadJSONDictionary(cfg.FeedOverrides...)
+	if cfg.productFilter != nil {
+		start := time.Now()
+		logging.Debugf("filtering vendors and products...")
+		for provider, dict := range dicts {
+			dicts[provider] = dict.Filter(cfg.productFilter)
+			logging.Debugf("%d out of %d vulnerabilities of provider %q are kept", len(dicts[provider]), len(dict), provider)
+		}
+		logging.Debugf("...done in %v", time.Since(start))
+	}
+
+	overrides, err = cfg.loadDictionary(ctx, cfg.FeedOverrides...)
 	if err != nil {
-		flog.Error(err)
+		logging.Errorf("%v", err)
 		return -1
 	}
 
-	flog.V(1).Infof("...done in %v", time.Since(start))
+	if err := logging.Err(); err != nil {
+		logging.Errorf("%v", err)
+		return -1
+	}
+
+	if err := cfg.loadKEVCatalog(); err != nil {
+		logging.Errorf("failed to load KEV catalog: %v", err)
+		return -1
+	}
+
+	if err := cfg.loadExploits(); err != nil {
+		logging.Errorf("failed to load exploits: %v", err)
+		return -1
+	}
+
+	if err := cfg.loadEPSSScores(ctx); err != nil {
+		logging.Errorf("failed to load EPSS scores: %v", err)
+		return -1
+	}
+
+	if err := cfg.loadPolicy(); err != nil {
+		logging.Errorf("failed to load policy: %v", err)
+		return -1
+	}
+
+	if err := cfg.loadBackports(); err != nil {
+		logging.Errorf("failed to load backports: %v", err)
+		return -1
+	}
+
+	if err := cfg.loadSuppressions(); err != nil {
+		logging.Errorf("failed to load suppressions: %v", err)
+		return -1
+	}
+
+	logging.Debugf("...done in %v", time.Since(start))
+
+	if len(overrides) != 0 && cfg.VEXFormat != "" {
+		// keep the overridden vulnerabilities as they were, so suppressed matches can be reported
+		cfg.suppressed = make(map[string]*cvefeed.Cache, len(dicts))
+		for provider, dict := range dicts {
+			orig := make(cvefeed.Dictionary)
+			for id := range overrides {
+				if vuln, ok := dict[id]; ok {
+					orig[id] = vuln
+				}
+			}
+			cfg.suppressed[provider] = cvefeed.NewCache(orig).SetRequireVersion(cfg.RequireVersion).SetMaxSize(cfg.CacheSize).SetMaxEntries(cfg.CacheEntries).SetInvertedIndex()
+		}
+	}
 
 	if len(overrides) != 0 {
 		start = time.Now()
-		flog.V(1).Info("applying overrides...")
+		logging.Debugf("applying overrides...")
 		for _, dict := range dicts {
 			dict.Override(overrides)
 		}
-		flog.V(1).Infof("...done in %v", time.Since(start))
+		logging.Debugf("...done in %v", time.Since(start))
 	}
 
 	caches := map[string]*cvefeed.Cache{}
 	for provider, dict := range dicts {
-		caches[provider] = cvefeed.NewCache(dict).SetRequireVersion(cfg.RequireVersion).SetMaxSize(cfg.CacheSize)
+		caches[provider] = cvefeed.NewCache(dict).SetRequireVersion(cfg.RequireVersion).SetMaxSize(cfg.CacheSize).SetMaxEntries(cfg.CacheEntries).SetInvertedIndex()
 	}
 
 	if cfg.IndexDict {
 		start = time.Now()
-		flog.V(1).Info("indexing dictionaries...")
+		logging.Debugf("indexing dictionaries...")
 		for provider, cache := range caches {
 			cache.Idx = cvefeed.NewIndex(dicts[provider])
-			if flog.V(2) {
+			if logging.Default().Level() <= logging.DebugLevel {
 				var named, total int
 				for k, v := range cache.Idx {
 					if k != wfn.Any {
@@ -285,37 +471,88 @@ func Main() int {
 					}
 					total += len(v)
 				}
-				flog.Infof("%d out of %d records are named", named, total)
+				logging.Debugf("%d out of %d records are named", named, total)
 			}
 		}
-		flog.V(1).Infof("...done in %v", time.Since(start))
+		logging.Debugf("...done in %v", time.Since(start))
 	}
 
 	if cfg.CPUProfile != "" {
 		f, err := os.Create(cfg.CPUProfile)
 		if err != nil {
-			flog.Error(err)
+			logging.Errorf("%v", err)
 			return 1
 		}
 		pprof.StartCPUProfile(f)
 		defer pprof.StopCPUProfile()
 	}
 
-	done := processInput(os.Stdin, os.Stdout, caches, cfg)
+	if cfg.SuppressedOutput != "" {
+		f, err := os.Create(cfg.SuppressedOutput)
+		if err != nil {
+			logging.Errorf("%v", err)
+			return 1
+		}
+		defer f.Close()
+		cfg.suppressedOut = f
+	}
+
+	if err := cfg.loadCheckpoint(os.Stdout); err != nil {
+		logging.Errorf("failed to load checkpoint: %v", err)
+		return -1
+	}
+
+	done := processInput(ctx, os.Stdin, os.Stdout, caches, cfg)
 
 	if cfg.MemoryProfile != "" {
 		f, err := os.Create(cfg.MemoryProfile)
 		if err != nil {
-			flog.Error(err)
+			logging.Errorf("%v", err)
 			return 1
 		}
 		runtime.GC()
 		if err = pprof.WriteHeapProfile(f); err != nil {
-			flog.Errorf("couldn't write heap profile: %v", err)
+			logging.Errorf("couldn't write heap profile: %v", err)
 		}
 		f.Close()
 	}
 
 	<-done
+
+	if ctx.Err() != nil {
+		if cfg.checkpoint == nil {
+			logging.Errorf("interrupted, the output is incomplete")
+			return 1
+		}
+		if err := cfg.checkpoint.save(); err != nil {
+			logging.Errorf("can't save checkpoint: %v", err)
+			return 1
+		}
+		logging.Errorf("interrupted, the run can be resumed from checkpoint %q", cfg.Checkpoint)
+		return 1
+	}
+
+	if cfg.checkpoint != nil {
+		if err := cfg.checkpoint.remove(); err != nil {
+			logging.Errorf("can't remove checkpoint: %v", err)
+		}
+	}
+
+	// findings are streamed, so in strict mode bad records can only fail the run once it's done
+	if err := logging.Err(); err != nil {
+		logging.Errorf("%v", err)
+		return 1
+	}
+
+	if cfg.CacheStats {
+		providers := make([]string, 0, len(caches))
+		for provider := range caches {
+			providers = append(providers, provider)
+		}
+		sort.Strings(providers)
+		for _, provider := range providers {
+			logging.Infof("cache stats of provider %q: %v", provider, caches[provider].Stats())
+		}
+	}
 	return 0
 }
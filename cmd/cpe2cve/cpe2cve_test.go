@@ -16,11 +16,19 @@ package main
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"os"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
 
 	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/epss"
+	exploitdbschema "github.com/facebookincubator/nvdtools/providers/exploitdb/schema"
 )
 
 func TestAppendAt(t *testing.T) {
@@ -96,7 +104,7 @@ func TestProcessInput(t *testing.T) {
 			t.Run(fmt.Sprintf("cache#%d case #%d", cacheID+1, i+1), func(t *testing.T) {
 				var w bytes.Buffer
 				r := strings.NewReader(c.in)
-				done := processInput(r, &w, singleCache(cache), cfg)
+				done := processInput(context.Background(), r, &w, singleCache(cache), cfg)
 				<-done
 				got := strings.Split(strings.TrimSpace(w.String()), "\n")
 				if len(got) != len(c.out) {
@@ -118,6 +126,69 @@ func TestProcessInput(t *testing.T) {
 	}
 }
 
+func TestProcessInputCancelled(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	cfg := config{
+		NumProcessors:      1,
+		CPEsAt:             1,
+		InFieldSeparator:   ",",
+		OutFieldSeparator:  ",",
+		InRecordSeparator:  "+",
+		OutRecordSeparator: "+",
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var w bytes.Buffer
+	<-processInput(ctx, strings.NewReader("cpe:/a:adobe:flash_player:24.0.0.194\n"), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+	if w.Len() != 0 {
+		t.Fatalf("input was processed after the context was cancelled:\n%s", w.String())
+	}
+}
+
+func TestProcessInputStrict(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	cfg := config{
+		NumProcessors:      1,
+		CPEsAt:             1,
+		InFieldSeparator:   ",",
+		OutFieldSeparator:  ",",
+		InRecordSeparator:  "+",
+		OutRecordSeparator: "+",
+	}
+
+	// records which can't be parsed are reported through logging, so -strict makes the run fail
+	l := logging.Default()
+	var logged bytes.Buffer
+	l.SetOutput(&logged)
+	l.SetStrict(true)
+	defer func() {
+		l.SetOutput(os.Stderr)
+		l.SetStrict(false)
+	}()
+	if err := l.Err(); err != nil {
+		t.Fatalf("unexpected error before processing: %v", err)
+	}
+
+	var w bytes.Buffer
+	<-processInput(context.Background(), strings.NewReader("cpe:/a:adobe:flash_player:24.0.0.194+cpe:%zz\n"), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+	if !strings.Contains(logged.String(), "ERROR couldn't parse uri") {
+		t.Fatalf("expecting an error about the uri, got %q", logged.String())
+	}
+	if err := l.Err(); err == nil {
+		t.Fatal("expecting an error in strict mode")
+	}
+}
+
 // This used to cause false postives, added this test during the debug session
 func TestProcessInputFalsePositives(t *testing.T) {
 	in := "cpe:/a::glibc:2.27-1"
@@ -140,7 +211,7 @@ func TestProcessInputFalsePositives(t *testing.T) {
 	}
 	var w bytes.Buffer
 	r := strings.NewReader(in)
-	done := processInput(r, &w, singleCache(cache), cfg)
+	done := processInput(context.Background(), r, &w, singleCache(cache), cfg)
 	<-done
 	out := strings.TrimSpace(w.String())
 	if out != "" {
@@ -148,6 +219,86 @@ func TestProcessInputFalsePositives(t *testing.T) {
 	}
 }
 
+func TestProcessInputExplain(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	cfg := config{
+		NumProcessors:      1,
+		CPEsAt:             1,
+		CVEsAt:             2,
+		ExplainAt:          3,
+		InFieldSeparator:   ",",
+		OutFieldSeparator:  "|",
+		InRecordSeparator:  "+",
+		OutRecordSeparator: "&",
+	}
+	var w bytes.Buffer
+	done := processInput(context.Background(), strings.NewReader("cpe:/o:microsoft:windows_10:-::~~~~x64~+cpe:/a:adobe:flash_player:24.0.0.194"), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+	<-done
+	got := strings.Split(strings.TrimSpace(w.String()), "\n")
+	sort.Strings(got)
+	expect := []string{
+		"cpe:/o:microsoft:windows_10:-::~~~~x64~&cpe:/a:adobe:flash_player:24.0.0.194|CVE-2016-0165|" +
+			"OR(OR(cpe:2.3:o:microsoft:windows_10:-:*:*:*:*:*:*:*))",
+		"cpe:/o:microsoft:windows_10:-::~~~~x64~&cpe:/a:adobe:flash_player:24.0.0.194|CVE-2666-1337|" +
+			"OR(AND(OR(cpe:2.3:o:microsoft:windows_10:*:*:*:*:*:*:*:*); OR(cpe:2.3:a:adobe:flash_player:24.0.0.194:*:*:*:*:*:*:*)))",
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Fatalf("expected\n%s\ngot\n%s", strings.Join(expect, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+const testPlatformFeed = `{"CVE_Items": [
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0001"}}, "configurations": {"nodes": [{"operator": "AND", "children": [
+    {"operator": "OR", "cpe_match": [{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:vendor:app:1.0:*:*:*:*:*:*:*"}]},
+    {"operator": "OR", "cpe_match": [{"vulnerable": false, "cpe23Uri": "cpe:2.3:o:vendor:os:*:*:*:*:*:*:*:*"}]}]}]}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0002"}}, "configurations": {"nodes": [{"operator": "OR", "cpe_match": [
+    {"vulnerable": true, "cpe23Uri": "cpe:2.3:o:vendor:os:*:*:*:*:*:*:*:*", "versionEndExcluding": "10"}]}]}}
+]}`
+
+func TestProcessInputPlatform(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testPlatformFeed))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	in := strings.Join([]string{
+		"host1,cpe:/a:vendor:app:1.0,cpe:/o:vendor:os:9",
+		// the OS isn't known, so the application doesn't run on it
+		"host2,cpe:/a:vendor:app:1.0+cpe:/o:vendor:os:9,",
+		"host3,cpe:/a:vendor:app:1.0,cpe:/o:other:os:9",
+	}, "\n")
+	cfg := config{
+		NumProcessors:      1,
+		CPEsAt:             2,
+		OSAt:               3,
+		CVEsAt:             4,
+		InFieldSeparator:   ",",
+		OutFieldSeparator:  "|",
+		InRecordSeparator:  "+",
+		OutRecordSeparator: "&",
+	}
+	var w bytes.Buffer
+	// running_on conditions match all versions of the OS, but they match it even if the version is required
+	done := processInput(context.Background(), strings.NewReader(in), &w, singleCache(cvefeed.NewCache(dict).SetRequireVersion(true)), cfg)
+	<-done
+	got := strings.Split(strings.TrimSpace(w.String()), "\n")
+	sort.Strings(got)
+	expect := []string{
+		"host1|cpe:/a:vendor:app:1.0|cpe:/o:vendor:os:9|CVE-0001",
+		"host1|cpe:/a:vendor:app:1.0|cpe:/o:vendor:os:9|CVE-0002",
+		"host2|cpe:/a:vendor:app:1.0&cpe:/o:vendor:os:9||CVE-0002",
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Fatalf("expected\n%s\ngot\n%s", strings.Join(expect, "\n"), strings.Join(got, "\n"))
+	}
+}
+
 func TestProcessInputRequireVersion(t *testing.T) {
 	in := "cpe:/h:huaweidevice:d100:1.33.7"
 	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
@@ -170,7 +321,7 @@ func TestProcessInputRequireVersion(t *testing.T) {
 	}
 	var w bytes.Buffer
 	r := strings.NewReader(in)
-	done := processInput(r, &w, singleCache(cache), cfg)
+	done := processInput(context.Background(), r, &w, singleCache(cache), cfg)
 	<-done
 	out := strings.TrimSpace(w.String())
 	if out != "" {
@@ -178,6 +329,399 @@ func TestProcessInputRequireVersion(t *testing.T) {
 	}
 }
 
+func TestProcessInputKnownExploited(t *testing.T) {
+	in := "cpe:/o:microsoft:windows_10:-::~~~~x64~,cpe:/a:adobe:flash_player:24.0.0.194"
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	cache := cvefeed.NewCache(dict)
+	cfg := config{
+		NumProcessors:      1,
+		CPEsAt:             1,
+		CVEsAt:             2,
+		KnownExploitedAt:   3,
+		InFieldSeparator:   "\t",
+		OutFieldSeparator:  ";",
+		InRecordSeparator:  ",",
+		OutRecordSeparator: "&",
+		knownExploited:     map[string]string{"CVE-2016-0165": "2022-04-15"},
+	}
+	var w bytes.Buffer
+	r := strings.NewReader(in)
+	done := processInput(context.Background(), r, &w, singleCache(cache), cfg)
+	<-done
+	got := strings.Split(strings.TrimSpace(w.String()), "\n")
+	expect := []string{
+		"cpe:/o:microsoft:windows_10:-::~~~~x64~&cpe:/a:adobe:flash_player:24.0.0.194;CVE-2016-0165;2022-04-15",
+		"cpe:/o:microsoft:windows_10:-::~~~~x64~&cpe:/a:adobe:flash_player:24.0.0.194;CVE-2666-1337;",
+	}
+	if len(got) != len(expect) {
+		t.Fatalf("got %d lines but %d were expected:\n%s", len(got), len(expect), strings.Join(got, "\n"))
+	}
+	for _, s := range got {
+		if !contains(expect, s) {
+			t.Fatalf("got:\n%q\nexpected one of:\n%#v", s, expect)
+		}
+	}
+}
+
+func TestProcessInputDescription(t *testing.T) {
+	feed := `{"CVE_Items": [{"cve": {"CVE_data_meta": {"ID": "CVE-0001"}, "description": {"description_data": [
+		{"lang": "en", "value": "Buffer overflow."}, {"lang": "es", "value": "Desbordamiento de búfer."}]}},
+		"configurations": {"nodes": [{"operator": "OR", "cpe_match": [{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:vendor:product:1.0:*:*:*:*:*:*:*"}]}]}}]}`
+	vulns, err := cvefeed.ParseJSON(strings.NewReader(feed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dict := cvefeed.Dictionary{"CVE-0001": vulns[0]}
+
+	for i, tc := range []struct {
+		lang   string
+		expect string
+	}{
+		{"en", "cpe:/a:vendor:product:1.0;CVE-0001;Buffer overflow."},
+		{"es-ES", "cpe:/a:vendor:product:1.0;CVE-0001;Desbordamiento de búfer."},
+		{"de", "cpe:/a:vendor:product:1.0;CVE-0001;Buffer overflow."},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			cfg := config{
+				NumProcessors:      1,
+				CPEsAt:             1,
+				CVEsAt:             2,
+				DescriptionAt:      3,
+				Lang:               tc.lang,
+				InFieldSeparator:   "\t",
+				OutFieldSeparator:  ";",
+				InRecordSeparator:  ",",
+				OutRecordSeparator: "&",
+			}
+			var w bytes.Buffer
+			done := processInput(context.Background(), strings.NewReader("cpe:/a:vendor:product:1.0"), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+			<-done
+			if got := strings.TrimSpace(w.String()); got != tc.expect {
+				t.Fatalf("expecting %q, got %q", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestProcessInputJSON(t *testing.T) {
+	in := "host1\tcpe:/o:microsoft:windows_10:-::~~~~x64~,cpe:/a:adobe:flash_player:24.0.0.194"
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	cache := cvefeed.NewCache(dict)
+	for _, format := range []string{outputJSON, outputNDJSON} {
+		t.Run(format, func(t *testing.T) {
+			cfg := config{
+				NumProcessors:      1,
+				CPEsAt:             2,
+				InFieldSeparator:   "\t",
+				OutFieldSeparator:  format,
+				InRecordSeparator:  ",",
+				OutRecordSeparator: ",",
+				Feeds:              map[string][]string{"test": {"feed.json"}},
+				knownExploited:     map[string]string{"CVE-2016-0165": "2022-04-15"},
+			}
+			if err := cfg.validate(); err != nil {
+				t.Fatal(err)
+			}
+			var w bytes.Buffer
+			done := processInput(context.Background(), strings.NewReader(in), &w, singleCache(cache), cfg)
+			<-done
+
+			var findings []map[string]interface{}
+			if format == outputJSON {
+				if err := json.Unmarshal(w.Bytes(), &findings); err != nil {
+					t.Fatalf("can't decode output %q: %v", w.String(), err)
+				}
+			} else {
+				for _, line := range strings.Split(strings.TrimSpace(w.String()), "\n") {
+					var f map[string]interface{}
+					if err := json.Unmarshal([]byte(line), &f); err != nil {
+						t.Fatalf("can't decode line %q: %v", line, err)
+					}
+					findings = append(findings, f)
+				}
+			}
+			if len(findings) != 2 {
+				t.Fatalf("expecting 2 findings, got %d:\n%s", len(findings), w.String())
+			}
+			for _, f := range findings {
+				for _, key := range []string{"input", "cpes", "cve", "cves", "provider", "matched_cpes", "cwes"} {
+					if _, ok := f[key]; !ok {
+						t.Fatalf("key %q is missing in finding %v", key, f)
+					}
+				}
+				if got := f["input"].([]interface{}); len(got) != 2 || got[0] != "host1" {
+					t.Fatalf("unexpected input %v", got)
+				}
+				if got := f["cpes"].([]interface{}); len(got) != 2 {
+					t.Fatalf("unexpected cpes %v", got)
+				}
+				switch f["cve"] {
+				case "CVE-2016-0165":
+					if f["known_exploited"] != "2022-04-15" {
+						t.Fatalf("expecting %s to be known exploited, got %v", f["cve"], f["known_exploited"])
+					}
+					if got := f["matched_cpes"].([]interface{}); len(got) != 1 {
+						t.Fatalf("unexpected matched cpes of %s: %v", f["cve"], got)
+					}
+				case "CVE-2666-1337":
+					if _, ok := f["known_exploited"]; ok {
+						t.Fatalf("%s isn't expected to be known exploited", f["cve"])
+					}
+					if got := f["matched_cpes"].([]interface{}); len(got) != 2 {
+						t.Fatalf("unexpected matched cpes of %s: %v", f["cve"], got)
+					}
+				default:
+					t.Fatalf("unexpected finding %v", f)
+				}
+			}
+		})
+	}
+}
+
+func TestProcessInputNDJSON(t *testing.T) {
+	in := `{"cpes": ["cpe:/o:microsoft:windows_10:-::~~~~x64~", "cpe:/a:adobe:flash_player:24.0.0.194"], "host": "host1", "tags": ["prod"]}
+{"cpes": ["cpe:/a:adobe:flash_player:24.0.0.194"], "host": "host2"}
+`
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	cfg := config{
+		NumProcessors:     1,
+		InFieldSeparator:  "ndjson",
+		OutFieldSeparator: "ndjson",
+		Feeds:             map[string][]string{"test": {"feed.json"}},
+	}
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+	var w bytes.Buffer
+	done := processInput(context.Background(), strings.NewReader(in), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+	<-done
+
+	got := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(w.String()), "\n") {
+		var f struct {
+			Input    []string        `json:"input"`
+			CVE      string          `json:"cve"`
+			Metadata json.RawMessage `json:"metadata"`
+		}
+		if err := json.Unmarshal([]byte(line), &f); err != nil {
+			t.Fatalf("can't decode line %q: %v", line, err)
+		}
+		if f.Input != nil {
+			t.Fatalf("input isn't expected for JSON input, got %v", f.Input)
+		}
+		got[f.CVE] = string(f.Metadata)
+	}
+	expect := map[string]string{
+		"CVE-2016-0165": `{"host":"host1","tags":["prod"]}`,
+		"CVE-2666-1337": `{"host":"host1","tags":["prod"]}`,
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Fatalf("expecting %v, got %v", expect, got)
+	}
+}
+
+func TestValidateFormats(t *testing.T) {
+	for i, tc := range []struct {
+		in, sep, vex string
+		format       string
+		ok           bool
+	}{
+		{sep: "\t", ok: false}, // -cve is required
+		{sep: "json", format: outputJSON, ok: true},
+		{sep: "ndjson", format: outputNDJSON, ok: true},
+		{sep: "json", vex: "openvex", ok: false},
+		{in: "ndjson", sep: "json", format: outputJSON, ok: true},
+		{in: "ndjson", sep: "\t", vex: "openvex", ok: true},
+		{in: "ndjson", sep: "\t", ok: false},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			cfg := config{
+				CPEsAt:            1,
+				InFieldSeparator:  tc.in,
+				OutFieldSeparator: tc.sep,
+				VEXFormat:         tc.vex,
+				Feeds:             map[string][]string{"test": {"feed.json"}},
+			}
+			err := cfg.validate()
+			if (err == nil) != tc.ok {
+				t.Fatalf("unexpected validation result: %v", err)
+			}
+			if err == nil && cfg.OutputFormat != tc.format {
+				t.Fatalf("expecting output format %q, got %q", tc.format, cfg.OutputFormat)
+			}
+		})
+	}
+}
+
+func TestProcessInputEPSS(t *testing.T) {
+	in := "cpe:/o:microsoft:windows_10:-::~~~~x64~,cpe:/a:adobe:flash_player:24.0.0.194"
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	cache := cvefeed.NewCache(dict)
+	scores, err := epss.ReadScores(strings.NewReader("cve,epss,percentile\nCVE-2016-0165,0.0421,0.91\nCVE-2666-1337,0.0009,0.3\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config{
+		NumProcessors:      1,
+		CPEsAt:             1,
+		CVEsAt:             2,
+		EPSSScoreAt:        3,
+		EPSSPercentileAt:   4,
+		MinEPSS:            0.01,
+		InFieldSeparator:   "\t",
+		OutFieldSeparator:  ";",
+		InRecordSeparator:  ",",
+		OutRecordSeparator: "&",
+		epssScores:         scores,
+	}
+	var w bytes.Buffer
+	r := strings.NewReader(in)
+	done := processInput(context.Background(), r, &w, singleCache(cache), cfg)
+	<-done
+	expect := "cpe:/o:microsoft:windows_10:-::~~~~x64~&cpe:/a:adobe:flash_player:24.0.0.194;CVE-2016-0165;0.0421;0.91"
+	if got := strings.TrimSpace(w.String()); got != expect {
+		t.Fatalf("got:\n%q\nexpected:\n%q", got, expect)
+	}
+}
+
+func TestProcessInputExploits(t *testing.T) {
+	in := "cpe:/o:microsoft:windows_10:-::~~~~x64~,cpe:/a:adobe:flash_player:24.0.0.194"
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	cache := cvefeed.NewCache(dict)
+	exploits := make(exploitdbschema.Index)
+	exploits.Add(
+		&exploitdbschema.Exploit{ID: "EDB-39960", CVEs: []string{"CVE-2016-0165"}},
+		&exploitdbschema.Exploit{ID: "exploit/windows/local/ms16_039", CVEs: []string{"CVE-2016-0165", "CVE-2016-0167"}},
+	)
+	cfg := config{
+		NumProcessors:      1,
+		CPEsAt:             1,
+		CVEsAt:             2,
+		ExploitsAt:         3,
+		InFieldSeparator:   "\t",
+		OutFieldSeparator:  ";",
+		InRecordSeparator:  ",",
+		OutRecordSeparator: "&",
+		exploits:           exploits,
+	}
+	var w bytes.Buffer
+	r := strings.NewReader(in)
+	done := processInput(context.Background(), r, &w, singleCache(cache), cfg)
+	<-done
+	got := strings.Split(strings.TrimSpace(w.String()), "\n")
+	expect := []string{
+		"cpe:/o:microsoft:windows_10:-::~~~~x64~&cpe:/a:adobe:flash_player:24.0.0.194;CVE-2016-0165;EDB-39960&exploit/windows/local/ms16_039",
+		"cpe:/o:microsoft:windows_10:-::~~~~x64~&cpe:/a:adobe:flash_player:24.0.0.194;CVE-2666-1337;",
+	}
+	if len(got) != len(expect) {
+		t.Fatalf("got %d lines but %d were expected:\n%s", len(got), len(expect), strings.Join(got, "\n"))
+	}
+	for _, s := range got {
+		if !contains(expect, s) {
+			t.Fatalf("got:\n%q\nexpected one of:\n%#v", s, expect)
+		}
+	}
+}
+
+func TestProcessInputVEX(t *testing.T) {
+	in := "cpe:/o:microsoft:windows_10:-::~~~~x64~,cpe:/a:adobe:flash_player:24.0.0.194"
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	overrides, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testOverrideJSONStr))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON overrides: %v", err)
+	}
+	orig := cvefeed.Dictionary{"CVE-2016-0165": dict["CVE-2016-0165"]}
+	dict.Override(overrides)
+
+	for _, format := range []string{vexOpenVEX, vexCycloneDX} {
+		t.Run(format, func(t *testing.T) {
+			cfg := config{
+				NumProcessors:      1,
+				CPEsAt:             1,
+				InFieldSeparator:   "\t",
+				OutFieldSeparator:  "\t",
+				InRecordSeparator:  ",",
+				OutRecordSeparator: ",",
+				VEXFormat:          format,
+				VEXAuthor:          "test",
+				suppressed:         singleCache(cvefeed.NewCache(orig)),
+			}
+			var w bytes.Buffer
+			done := processInput(context.Background(), strings.NewReader(in), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+			<-done
+
+			got := make(map[string]string)
+			switch format {
+			case vexOpenVEX:
+				var doc openVEXDocument
+				if err := json.Unmarshal(w.Bytes(), &doc); err != nil {
+					t.Fatalf("can't decode openvex document: %v\n%s", err, w.String())
+				}
+				for _, st := range doc.Statements {
+					for _, p := range st.Products {
+						got[st.Vulnerability.Name+" "+p.ID] = st.Status
+					}
+				}
+			case vexCycloneDX:
+				var doc cycloneDXDocument
+				if err := json.Unmarshal(w.Bytes(), &doc); err != nil {
+					t.Fatalf("can't decode cyclonedx document: %v\n%s", err, w.String())
+				}
+				if len(doc.Components) != 2 {
+					t.Fatalf("expecting 2 components, got %d", len(doc.Components))
+				}
+				states := map[string]string{"exploitable": vexAffected, "not_affected": vexNotAffected}
+				for _, v := range doc.Vulnerabilities {
+					for _, a := range v.Affects {
+						got[v.ID+" "+a.Ref] = states[v.Analysis.State]
+					}
+				}
+			}
+
+			expect := map[string]string{
+				"CVE-2016-0165 cpe:/o:microsoft:windows_10:-::~~~~x64~": vexNotAffected,
+				"CVE-2666-1337 cpe:/o:microsoft:windows_10:-::~~~~x64~": vexAffected,
+				"CVE-2666-1337 cpe:/a:adobe:flash_player:24.0.0.194":    vexAffected,
+			}
+			if !reflect.DeepEqual(got, expect) {
+				t.Fatalf("got statuses %v, expected %v", got, expect)
+			}
+		})
+	}
+}
+
 func BenchmarkProcessInputJSON(t *testing.B) {
 	in := `1;2;3;cpe:/o:microsoft:windows_10:-::~~~~x64~,cpe:/a:adobe:flash_player:24.0.0.194
 1;2;3;cpe:/o::centos_linux:7.5.1804,cpe:/a::chardet:2.2.1,cpe:/a::javapackages:1.0.0,cpe:/a::kitchen:1.1.1,cpe:/a::nose:1.3.7,cpe:/a::python-dateutil:1.5,cpe:/a::pytz:2016.10,cpe:/a::setuptools:0.9.8,cpe:/a::chardet:2.2.1,cpe:/a::javapackages:1.0.0,cpe:/a::kitchen:1.1.1,cpe:/a::nose:1.3.7,cpe:/a::python-dateutil:1.5,cpe:/a::pytz:2016.10,cpe:/a::setuptools:0.9.8
@@ -204,7 +748,7 @@ func BenchmarkProcessInputJSON(t *testing.B) {
 	for i := 0; i < t.N; i++ {
 		var w bytes.Buffer
 		r := strings.NewReader(in)
-		done := processInput(r, &w, singleCache(cache), cfg)
+		done := processInput(context.Background(), r, &w, singleCache(cache), cfg)
 		<-done
 	}
 }
@@ -217,15 +761,6 @@ func getSkip(ff []int) fieldsToSkip {
 	return fieldsToSkip(set)
 }
 
-func contains(in []string, s string) bool {
-	for _, t := range in {
-		if t == s {
-			return true
-		}
-	}
-	return false
-}
-
 func singleCache(cache *cvefeed.Cache) map[string]*cvefeed.Cache {
 	return map[string]*cvefeed.Cache{"test": cache}
 }
@@ -380,3 +915,5 @@ var testDictJSONStr = `{
 }`
 
 var testDictJSONStr2 = `{"CVE_data_format":"","CVE_data_type":"","CVE_data_version":"","CVE_Items":[{"cve":{"affects":{"vendor":{"vendor_data":[{"product":{"product_data":[{"product_name":"d100","version":{"version_data":[{"version_value":"*"}]}}]},"vendor_name":"huaweidevice"}]}},"CVE_data_meta":{"ASSIGNER":"cve@mitre.org","ID":"CVE-2009-2273"},"data_format":"MITRE","data_type":"CVE","data_version":"4.0","description":{"description_data":[{"lang":"en","value":"The default configuration of the Wi-Fi component on the Huawei D100 does not use encryption, which makes it easier for remote attackers to obtain sensitive information by sniffing the network."}]},"problemtype":{"problemtype_data":[{"description":[{"lang":"en","value":"CWE-310"}]}]},"references":{"reference_data":[{"name":"20090630 Multiple Flaws in Huawei D100","refsource":"BUGTRAQ","url":"http://www.securityfocus.com/archive/1/archive/1/504645/100/0/threaded"}]}},"configurations":{"CVE_data_version":"4.0","nodes":[{"cpe":[{"cpe22Uri":"cpe:/h:huaweidevice:d100","cpe23Uri":"cpe:2.3:h:huaweidevice:d100:*:*:*:*:*:*:*:*","vulnerable":true}],"operator":"AND"}]},"impact":{"baseMetricV2":{"cvssV2":{"accessComplexity":"LOW","accessVector":"NETWORK","authentication":"NONE","availabilityImpact":"NONE","baseScore":5,"confidentialityImpact":"PARTIAL","integrityImpact":"NONE","vectorString":"(AV:N/AC:L/Au:N/C:P/I:N/A:N)","version":"2.0"},"exploitabilityScore":10,"impactScore":2.9,"severity":"MEDIUM"}},"lastModifiedDate":"2009-07-01T04:00Z","publishedDate":"2009-07-01T13:00Z"}]}`
+
+var testOverrideJSONStr = `{"CVE_Items":[{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"CVE-2016-0165"}},"configurations":{"CVE_data_version":"4.0","nodes":[{"operator":"OR","cpe_match":[{"vulnerable":true,"cpe22Uri":"cpe:/o:microsoft:windows_10:-"}]}]}}]}`
@@ -0,0 +1,285 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/cvss2"
+	"github.com/facebookincubator/nvdtools/cvss3"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// qualitative severity ratings of CVSS scores
+const (
+	severityNone     = "none"
+	severityLow      = "low"
+	severityMedium   = "medium"
+	severityHigh     = "high"
+	severityCritical = "critical"
+)
+
+// parseSeverities parses comma-separated list of severity ratings
+func parseSeverities(s string) (map[string]bool, error) {
+	if s == "" {
+		return nil, nil
+	}
+	severities := make(map[string]bool)
+	for _, sev := range strings.Split(s, ",") {
+		sev = strings.ToLower(strings.TrimSpace(sev))
+		switch sev {
+		case severityNone, severityLow, severityMedium, severityHigh, severityCritical:
+			severities[sev] = true
+		default:
+			return nil, fmt.Errorf("unknown severity %q", sev)
+		}
+	}
+	return severities, nil
+}
+
+var cweRegex = regexp.MustCompile(`^(CWE-[0-9]+|NVD-CWE-OTHER|NVD-CWE-NOINFO)$`)
+
+// parseCWEs parses comma-separated list of CWEs, numbers are prefixed with CWE-
+func parseCWEs(s string) (map[string]bool, error) {
+	if s == "" {
+		return nil, nil
+	}
+	cwes := make(map[string]bool)
+	for _, cwe := range strings.Split(s, ",") {
+		cwe = normalizeCWE(cwe)
+		if !cweRegex.MatchString(cwe) {
+			return nil, fmt.Errorf("unknown CWE %q", cwe)
+		}
+		cwes[cwe] = true
+	}
+	return cwes, nil
+}
+
+// splitList splits comma-separated list, it returns nil for an empty string
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, ",")
+}
+
+// normalizeCWE returns the CWE in upper case, with CWE- prefix if it's a number
+func normalizeCWE(cwe string) string {
+	cwe = strings.ToUpper(strings.TrimSpace(cwe))
+	if _, err := strconv.Atoi(cwe); err == nil {
+		cwe = "CWE-" + cwe
+	}
+	return cwe
+}
+
+// skipCWE returns true if matches of the vulnerability should be skipped as per -include_cwe and -exclude_cwe;
+// vulnerabilities without CWEs are skipped if -include_cwe is set
+func (cfg *config) skipCWE(vuln cvefeed.Vuln) bool {
+	if cfg.includeCWEs == nil && cfg.excludeCWEs == nil {
+		return false
+	}
+	included := cfg.includeCWEs == nil
+	for _, cwe := range vuln.CWEs() {
+		cwe = normalizeCWE(cwe)
+		if cfg.excludeCWEs[cwe] {
+			return true
+		}
+		included = included || cfg.includeCWEs[cwe]
+	}
+	return !included
+}
+
+// parseCVSS3Environment parses slash separated CVSS v3 temporal and environmental metrics, e.g. CR:H/MAV:L,
+// into a vector without base metrics, which can be absorbed by vectors of vulnerabilities to re-score them
+func parseCVSS3Environment(s string) (*cvss3.Vector, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var env cvss3.Vector
+	for _, part := range strings.Split(strings.ToUpper(s), "/") {
+		mv := strings.Split(part, ":")
+		if len(mv) != 2 {
+			return nil, fmt.Errorf("need metric and value separated by :, got %q", part)
+		}
+		if err := env.Set(mv[0], mv[1]); err != nil {
+			return nil, err
+		}
+		if env.BaseMetrics != (cvss3.BaseMetrics{}) {
+			return nil, fmt.Errorf("%s is a base metric, only temporal and environmental metrics can be set", mv[0])
+		}
+	}
+	return &env, nil
+}
+
+// cvss3Score returns CVSS v3 base score of the vulnerability, or its score in the environment set by -cvss3_env
+// if the vulnerability has a valid v3 vector; if -cvss2_to_cvss3 is set, vulnerabilities which are scored only
+// with v2 are scored with their v2 vector converted to v3; returns false if the vulnerability isn't scored with v3
+func (cfg *config) cvss3Score(vuln cvefeed.Vuln) (float64, bool) {
+	score, vector := vuln.CVSSv3BaseScore(), vuln.CVSSv3Vector()
+	if score == 0 && vector == "" {
+		if !cfg.CVSS2ToCVSS3 || vuln.CVSSv2Vector() == "" {
+			return 0, false
+		}
+		v2, err := cvss2.VectorFromString(vuln.CVSSv2Vector())
+		if err != nil {
+			return 0, false
+		}
+		v, err := cvss3.FromCVSS2(v2)
+		if err != nil {
+			return 0, false
+		}
+		return cfg.rescore(v), true
+	}
+	if cfg.cvss3Env == nil || vector == "" {
+		return score, true
+	}
+	v, err := cvss3.VectorFromString(vector)
+	if err == nil {
+		err = v.Validate()
+	}
+	if err != nil {
+		return score, true
+	}
+	return cfg.rescore(v), true
+}
+
+// rescore returns score of the valid vector in the environment set by -cvss3_env
+func (cfg *config) rescore(v cvss3.Vector) float64 {
+	if cfg.cvss3Env == nil {
+		return v.BaseScore()
+	}
+	v.Absorb(*cfg.cvss3Env)
+	return v.Score()
+}
+
+// severity returns qualitative rating of the score of given CVSS major version
+// CVSS v2 scores are rated as NVD does, v2 doesn't have none and critical ratings; v3 and v4 are rated the same
+func severity(score float64, version int) string {
+	switch {
+	case version == 2 && score < 4.0:
+		return severityLow
+	case version == 2 && score < 7.0:
+		return severityMedium
+	case version == 2:
+		return severityHigh
+	case score == 0:
+		return severityNone
+	case score < 4.0:
+		return severityLow
+	case score < 7.0:
+		return severityMedium
+	case score < 9.0:
+		return severityHigh
+	default:
+		return severityCritical
+	}
+}
+
+// cvssScore returns base score of the vulnerability and major version of CVSS it's scored with, as per -cvss_version;
+// v3 score is preferred to v2 if the version wasn't set; returns false if the vulnerability isn't scored with the version
+// v3 scores are re-scored in the environment set by -cvss3_env, and converted from v2 if -cvss2_to_cvss3 is set
+func (cfg *config) cvssScore(vuln cvefeed.Vuln) (float64, int, bool) {
+	v2 := vuln.CVSSv2BaseScore()
+	hasV2 := v2 != 0 || vuln.CVSSv2Vector() != ""
+	v3, hasV3 := cfg.cvss3Score(vuln)
+	switch cfg.CVSSVersion {
+	case "2":
+		return v2, 2, hasV2
+	case "3":
+		return v3, 3, hasV3
+	case "3.0", "3.1":
+		return v3, 3, strings.HasPrefix(vuln.CVSSv3Vector(), "CVSS:"+cfg.CVSSVersion+"/")
+	case "4":
+		v4 := vuln.CVSSv4BaseScore()
+		return v4, 4, v4 != 0 || vuln.CVSSv4Vector() != ""
+	}
+	if hasV3 {
+		return v3, 3, true
+	}
+	return v2, 2, hasV2
+}
+
+// rate returns score and severity of the vulnerability matching the CPEs: its CVSS score as per -cvss_version,
+// overridden or adjusted by the -policy rules; returns false if the vulnerability isn't scored
+func (cfg *config) rate(vuln cvefeed.Vuln, matched []*wfn.Attributes) (float64, string, bool) {
+	score, version, ok := cfg.cvssScore(vuln)
+	if cfg.policy != nil {
+		return cfg.policy.apply(vuln, matched, score, version, ok)
+	}
+	if !ok {
+		return 0, "", false
+	}
+	return score, severity(score, version), true
+}
+
+// skipSeverity returns true if matches of the vulnerability should be skipped as per -min_cvss, -severity and -cvss_version,
+// and the -policy rules; vulnerabilities which aren't scored are skipped if any of the flags is set
+func (cfg *config) skipSeverity(vuln cvefeed.Vuln, matched []*wfn.Attributes) bool {
+	if cfg.MinCVSS == 0 && cfg.severities == nil && cfg.CVSSVersion == "" {
+		return false
+	}
+	score, sev, ok := cfg.rate(vuln, matched)
+	if !ok || score < cfg.MinCVSS {
+		return true
+	}
+	return cfg.severities != nil && !cfg.severities[sev]
+}
+
+// parseSince parses time in RFC3339 format or as a date (2006-01-02),
+// or a duration relative to now, with d (days) and w (weeks) units besides the time.ParseDuration ones, e.g. 90d
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	var unit time.Duration
+	switch {
+	case strings.HasSuffix(s, "d"):
+		unit = 24 * time.Hour
+	case strings.HasSuffix(s, "w"):
+		unit = 7 * 24 * time.Hour
+	}
+	if unit != 0 {
+		n, err := strconv.Atoi(s[:len(s)-1])
+		if err != nil || n < 0 {
+			return time.Time{}, fmt.Errorf("can't parse %q as RFC3339 time, date or relative duration", s)
+		}
+		return now.Add(-time.Duration(n) * unit), nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return time.Time{}, fmt.Errorf("can't parse %q as RFC3339 time, date or relative duration", s)
+	}
+	return now.Add(-d), nil
+}
+
+// skipDate returns true if matches of the vulnerability should be skipped as per -published_since and -modified_since;
+// vulnerabilities with unknown dates are skipped if any of them is set
+func (cfg *config) skipDate(vuln cvefeed.Vuln) bool {
+	if !cfg.publishedSince.IsZero() && vuln.Published().Before(cfg.publishedSince) {
+		return true
+	}
+	return !cfg.modifiedSince.IsZero() && vuln.LastModified().Before(cfg.modifiedSince)
+}
@@ -0,0 +1,287 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+const testScoredFeed = `{"CVE_Items": [
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0001"}}, "configurations": {"nodes": []},
+    "publishedDate": "2023-01-10T15:00Z", "lastModifiedDate": "2024-02-01T10:00Z", "impact": {
+    "baseMetricV2": {"cvssV2": {"baseScore": 7.5, "vectorString": "AV:N/AC:L/Au:N/C:P/I:P/A:P"}},
+    "baseMetricV3": {"cvssV3": {"baseScore": 9.8, "vectorString": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}}}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0002"}}, "configurations": {"nodes": []},
+    "publishedDate": "2024-03-01T15:00Z", "lastModifiedDate": "2024-03-05T10:00Z", "impact": {
+    "baseMetricV3": {"cvssV3": {"baseScore": 5.3, "vectorString": "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:L/I:N/A:N"}},
+    "baseMetricV4": {"cvssV4": {"vectorString": "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:L/VI:N/VA:N/SC:N/SI:N/SA:N"}}}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0003"}}, "configurations": {"nodes": []},
+    "publishedDate": "2010-06-01T15:00Z", "impact": {
+    "baseMetricV2": {"cvssV2": {"baseScore": 7.5, "vectorString": "AV:N/AC:L/Au:N/C:P/I:P/A:P"}}}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0004"}}, "configurations": {"nodes": []}}
+]}`
+
+func TestSeverity(t *testing.T) {
+	for i, tc := range []struct {
+		score   float64
+		version int
+		expect  string
+	}{
+		{0, 3, severityNone},
+		{3.9, 3, severityLow},
+		{4.0, 3, severityMedium},
+		{7.0, 3, severityHigh},
+		{9.0, 3, severityCritical},
+		{0, 2, severityLow},
+		{6.9, 2, severityMedium},
+		{10, 2, severityHigh},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			if got := severity(tc.score, tc.version); got != tc.expect {
+				t.Fatalf("expecting %s for %v (v%d), got %s", tc.expect, tc.score, tc.version, got)
+			}
+		})
+	}
+}
+
+func TestSkipSeverity(t *testing.T) {
+	vulns, err := cvefeed.ParseJSON(bytes.NewBufferString(testScoredFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tc := range []struct {
+		minCVSS    float64
+		severities string
+		version    string
+		env        string
+		convert    bool
+		expect     []string
+	}{
+		{expect: []string{"CVE-0001", "CVE-0002", "CVE-0003", "CVE-0004"}},
+		{minCVSS: 7.0, expect: []string{"CVE-0001", "CVE-0003"}},
+		{minCVSS: 7.0, version: "2", expect: []string{"CVE-0001", "CVE-0003"}},
+		{minCVSS: 7.0, version: "3", expect: []string{"CVE-0001"}},
+		{version: "3.0", expect: []string{"CVE-0002"}},
+		{version: "3.1", expect: []string{"CVE-0001"}},
+		{severities: "high, Critical", expect: []string{"CVE-0001", "CVE-0003"}},
+		{severities: "medium", expect: []string{"CVE-0002"}},
+		{severities: "high", version: "3", expect: nil},
+		{severities: "none,low", expect: nil},
+		{version: "4", expect: []string{"CVE-0002"}},
+		{minCVSS: 6.9, version: "4", expect: []string{"CVE-0002"}},
+		{minCVSS: 7.0, version: "4", expect: nil},
+		{severities: "high", version: "4", expect: nil},
+		{minCVSS: 7.0, version: "3", env: "MAV:P", expect: nil},
+		{minCVSS: 6.8, version: "3", env: "mav:p", expect: []string{"CVE-0001"}},
+		{severities: "high", version: "3", env: "E:U/RL:O/RC:U", expect: []string{"CVE-0001"}},
+		{severities: "high", env: "E:U/RL:O/RC:U", expect: []string{"CVE-0001", "CVE-0003"}},
+		{version: "3", convert: true, expect: []string{"CVE-0001", "CVE-0002", "CVE-0003"}},
+		{minCVSS: 7.3, convert: true, expect: []string{"CVE-0001", "CVE-0003"}},
+		{minCVSS: 7.4, convert: true, expect: []string{"CVE-0001"}},
+		{severities: "high", version: "3", convert: true, expect: []string{"CVE-0003"}},
+		{severities: "high", version: "3", env: "MAV:L", convert: true, expect: []string{"CVE-0001"}},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			cfg := config{
+				CPEsAt:      1,
+				CVEsAt:      1,
+				MinCVSS:     tc.minCVSS,
+				Severities:  tc.severities,
+				CVSSVersion: tc.version,
+				Feeds:       map[string][]string{"test": {"feed.json"}},
+
+				CVSS3Environment: tc.env,
+				CVSS2ToCVSS3:     tc.convert,
+			}
+			if err := cfg.validate(); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, vuln := range vulns {
+				if !cfg.skipSeverity(vuln, nil) {
+					got = append(got, vuln.ID())
+				}
+			}
+			if !reflect.DeepEqual(got, tc.expect) {
+				t.Fatalf("expecting %v, got %v", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestValidateSeverity(t *testing.T) {
+	for i, tc := range []struct {
+		minCVSS    float64
+		severities string
+		version    string
+		env        string
+	}{
+		{minCVSS: -1},
+		{minCVSS: 10.1},
+		{severities: "high,severe"},
+		{version: "4.0"},
+		{env: "AV:L"},
+		{env: "CR:H/MAV"},
+		{env: "CR:Q"},
+		{env: "XX:H"},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			cfg := config{
+				CPEsAt:      1,
+				CVEsAt:      1,
+				MinCVSS:     tc.minCVSS,
+				Severities:  tc.severities,
+				CVSSVersion: tc.version,
+				Feeds:       map[string][]string{"test": {"feed.json"}},
+
+				CVSS3Environment: tc.env,
+			}
+			if err := cfg.validate(); err == nil {
+				t.Fatal("expecting validation to fail")
+			}
+		})
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+	for i, tc := range []struct {
+		in     string
+		expect time.Time
+		fail   bool
+	}{
+		{in: ""},
+		{in: "2024-01-02T03:04:05Z", expect: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{in: "2024-01-02", expect: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{in: "90d", expect: time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{in: "2w", expect: time.Date(2024, 3, 17, 12, 0, 0, 0, time.UTC)},
+		{in: "36h", expect: time.Date(2024, 3, 30, 0, 0, 0, 0, time.UTC)},
+		{in: "-3d", fail: true},
+		{in: "xd", fail: true},
+		{in: "yesterday", fail: true},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			got, err := parseSince(tc.in, now)
+			if (err != nil) != tc.fail {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if !got.Equal(tc.expect) {
+				t.Fatalf("expecting %v, got %v", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestSkipDate(t *testing.T) {
+	vulns, err := cvefeed.ParseJSON(bytes.NewBufferString(testScoredFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tc := range []struct {
+		published, modified string
+		expect              []string
+	}{
+		{expect: []string{"CVE-0001", "CVE-0002", "CVE-0003", "CVE-0004"}},
+		{published: "2023-01-01", expect: []string{"CVE-0001", "CVE-0002"}},
+		{published: "2024-03-01T15:00:00Z", expect: []string{"CVE-0002"}},
+		{modified: "2024-01-01", expect: []string{"CVE-0001", "CVE-0002"}},
+		{published: "2020-01-01", modified: "2024-03-01", expect: []string{"CVE-0002"}},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			cfg := config{
+				CPEsAt:         1,
+				CVEsAt:         1,
+				PublishedSince: tc.published,
+				ModifiedSince:  tc.modified,
+				Feeds:          map[string][]string{"test": {"feed.json"}},
+			}
+			if err := cfg.validate(); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, vuln := range vulns {
+				if !cfg.skipDate(vuln) {
+					got = append(got, vuln.ID())
+				}
+			}
+			if !reflect.DeepEqual(got, tc.expect) {
+				t.Fatalf("expecting %v, got %v", tc.expect, got)
+			}
+		})
+	}
+}
+
+const testCWEFeed = `{"CVE_Items": [
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0001"}, "problemtype": {"problemtype_data": [{"description": [{"lang": "en", "value": "CWE-787"}, {"lang": "en", "value": "CWE-20"}]}]}}, "configurations": {"nodes": []}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0002"}, "problemtype": {"problemtype_data": [{"description": [{"lang": "en", "value": "CWE-79"}]}]}}, "configurations": {"nodes": []}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0003"}, "problemtype": {"problemtype_data": [{"description": [{"lang": "en", "value": "NVD-CWE-noinfo"}]}]}}, "configurations": {"nodes": []}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0004"}}, "configurations": {"nodes": []}}
+]}`
+
+func TestSkipCWE(t *testing.T) {
+	vulns, err := cvefeed.ParseJSON(bytes.NewBufferString(testCWEFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tc := range []struct {
+		include, exclude string
+		expect           []string
+	}{
+		{expect: []string{"CVE-0001", "CVE-0002", "CVE-0003", "CVE-0004"}},
+		{include: "CWE-787,cwe-416", expect: []string{"CVE-0001"}},
+		{include: "79, 787", expect: []string{"CVE-0001", "CVE-0002"}},
+		{exclude: "nvd-cwe-noinfo", expect: []string{"CVE-0001", "CVE-0002", "CVE-0004"}},
+		{exclude: "20", expect: []string{"CVE-0002", "CVE-0003", "CVE-0004"}},
+		{include: "787,79", exclude: "20", expect: []string{"CVE-0002"}},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			cfg := config{
+				CPEsAt:      1,
+				CVEsAt:      1,
+				IncludeCWEs: tc.include,
+				ExcludeCWEs: tc.exclude,
+				Feeds:       map[string][]string{"test": {"feed.json"}},
+			}
+			if err := cfg.validate(); err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, vuln := range vulns {
+				if !cfg.skipCWE(vuln) {
+					got = append(got, vuln.ID())
+				}
+			}
+			if !reflect.DeepEqual(got, tc.expect) {
+				t.Fatalf("expecting %v, got %v", tc.expect, got)
+			}
+		})
+	}
+
+	for _, s := range []string{"CWE-", "XSS", "CWE-79,"} {
+		cfg := config{CPEsAt: 1, CVEsAt: 1, IncludeCWEs: s, Feeds: map[string][]string{"test": {"feed.json"}}}
+		if err := cfg.validate(); err == nil {
+			t.Fatalf("expecting -include_cwe %q to be invalid", s)
+		}
+	}
+}
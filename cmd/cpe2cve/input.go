@@ -0,0 +1,142 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// input formats, besides delimiter-separated records
+const (
+	inputNDJSON = "ndjson"
+)
+
+// assetCPEsKey is the key of the CPE names array in JSON input
+const assetCPEsKey = "cpes"
+
+// asset is an input record, either delimiter-separated fields or an object of JSON input:
+//
+//	{"cpes": ["cpe:/a:gnu:glibc:2.28", "cpe:/a:haxx:curl:7.55.0"], "host": "host2.foo.bar", "owner": {"team": "infra"}}
+//
+// other keys of the object are passed through to the output as they are
+type asset struct {
+	record   []string
+	cpes     []string
+	metadata map[string]json.RawMessage
+}
+
+// assetReader reads assets in the configured input format
+type assetReader interface {
+	// read returns io.EOF at the end of input, nil asset should be skipped
+	read() (*asset, error)
+}
+
+func newAssetReader(r io.Reader, cfg config) assetReader {
+	if cfg.InputFormat == inputNDJSON {
+		return &jsonReader{r: bufio.NewReader(r)}
+	}
+	cr := csv.NewReader(r)
+	cr.Comma = rune(cfg.InFieldSeparator[0])
+	return &csvReader{r: cr}
+}
+
+type csvReader struct {
+	r *csv.Reader
+}
+
+func (r *csvReader) read() (*asset, error) {
+	rec, err := r.r.Read()
+	if rec == nil {
+		return nil, err
+	}
+	return &asset{record: rec}, err
+}
+
+// jsonReader reads an asset per line
+type jsonReader struct {
+	r    *bufio.Reader
+	line int
+}
+
+func (r *jsonReader) read() (*asset, error) {
+	data, err := r.r.ReadBytes('\n')
+	if err != nil && (err != io.EOF || len(data) == 0) {
+		return nil, err
+	}
+	r.line++
+	if data = bytes.TrimSpace(data); len(data) == 0 {
+		return nil, nil
+	}
+	var a asset
+	if err := json.Unmarshal(data, &a.metadata); err != nil {
+		return nil, fmt.Errorf("can't decode asset at line %d: %v", r.line, err)
+	}
+	if cpes, ok := a.metadata[assetCPEsKey]; ok {
+		if err := json.Unmarshal(cpes, &a.cpes); err != nil {
+			return nil, fmt.Errorf("can't decode %s of asset at line %d: %v", assetCPEsKey, r.line, err)
+		}
+		delete(a.metadata, assetCPEsKey)
+	}
+	return &a, nil
+}
+
+// platformAware returns true if running_on conditions are matched against the OS CPE of assets
+func (cfg *config) platformAware() bool {
+	return cfg.OSAt != 0 || cfg.OSKey != ""
+}
+
+// platforms returns the OS CPE of the asset, if it has one
+func (cfg *config) platforms(a *asset) []*wfn.Attributes {
+	var uri string
+	if a.record != nil {
+		if cfg.OSAt > len(a.record) {
+			logging.Recordf("not enough fields in input (%d) for os", len(a.record))
+			return nil
+		}
+		uri = a.record[cfg.OSAt-1]
+	} else if data, ok := a.metadata[cfg.OSKey]; ok {
+		if err := json.Unmarshal(data, &uri); err != nil {
+			logging.Recordf("can't decode %s of asset: %v", cfg.OSKey, err)
+			return nil
+		}
+	}
+	if uri == "" {
+		return nil
+	}
+	attrs, err := wfn.Parse(uri)
+	if err != nil {
+		logging.Recordf("couldn't parse os %q: %v", uri, err)
+		return nil
+	}
+	return []*wfn.Attributes{attrs}
+}
+
+// lookup returns vulnerabilities matching the CPEs in the cache, running_on conditions of them
+// are matched only against the platforms if matching is platform-aware
+func (cfg *config) lookup(cache *cvefeed.Cache, cpes, platforms []*wfn.Attributes) []cvefeed.MatchResult {
+	if cfg.platformAware() {
+		return cache.GetWithPlatform(cpes, platforms)
+	}
+	return cache.Get(cpes)
+}
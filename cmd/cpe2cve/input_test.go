@@ -0,0 +1,79 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONReader(t *testing.T) {
+	in := `{"cpes": ["cpe:/a:gnu:glibc:2.28", "cpe:/a:haxx:curl:7.55.0"], "host": "host1", "owner": {"team": "infra"}}
+
+{"host": "host2"}
+not json
+{"cpes": "cpe:/a:gnu:glibc:2.28"}
+{"cpes": []}`
+	r := newAssetReader(strings.NewReader(in), config{InputFormat: inputNDJSON})
+
+	type result struct {
+		cpes     []string
+		metadata map[string]string
+		err      bool
+	}
+	var got []result
+	for {
+		a, err := r.read()
+		if err == io.EOF {
+			break
+		}
+		if a == nil && err == nil {
+			continue
+		}
+		res := result{err: err != nil}
+		if a != nil {
+			res.cpes = a.cpes
+			res.metadata = make(map[string]string)
+			for k, v := range a.metadata {
+				res.metadata[k] = string(v)
+			}
+		}
+		got = append(got, res)
+	}
+
+	expect := []result{
+		{
+			cpes:     []string{"cpe:/a:gnu:glibc:2.28", "cpe:/a:haxx:curl:7.55.0"},
+			metadata: map[string]string{"host": `"host1"`, "owner": `{"team": "infra"}`},
+		},
+		{metadata: map[string]string{"host": `"host2"`}},
+		{err: true},
+		{err: true},
+		{cpes: []string{}, metadata: map[string]string{}},
+	}
+	if len(got) != len(expect) {
+		t.Fatalf("expecting %d assets, got %d: %v", len(expect), len(got), got)
+	}
+	for i, tc := range expect {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			if !reflect.DeepEqual(got[i], tc) {
+				t.Fatalf("expecting %v, got %v", tc, got[i])
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+// orderedPendingPerWorker is the number of assets per processor which can be matched ahead of the asset
+// which is written next, when the output is ordered; it bounds the number of findings kept in memory
+const orderedPendingPerWorker = 4
+
+// orderedJob is an asset being matched, its findings are written once the findings of previous assets are
+type orderedJob struct {
+	asset    *asset
+	findings []*finding
+	done     chan struct{}
+}
+
+// processOrdered is processAll with cfg.NumProcessors concurrent processors,
+// which sends findings to out in the order assets were received from in
+func processOrdered(in <-chan *asset, out chan<- *finding, caches map[string]*cvefeed.Cache, cfg config, nlines *uint64) {
+	jobs := make(chan *orderedJob)
+	// jobs are queued in the input order, the queue blocks reading more input if matching the oldest asset takes long
+	queue := make(chan *orderedJob, cfg.NumProcessors*orderedPendingPerWorker)
+
+	for i := 0; i < cfg.NumProcessors; i++ {
+		go func() {
+			for job := range jobs {
+				processAsset(job.asset, func(f *finding) { job.findings = append(job.findings, f) }, caches, cfg, nlines)
+				if cfg.checkpoint != nil {
+					job.findings = append(job.findings, &finding{lineDone: true})
+				}
+				close(job.done)
+			}
+		}()
+	}
+
+	written := make(chan struct{})
+	go func() {
+		for job := range queue {
+			<-job.done
+			for _, f := range job.findings {
+				out <- f
+			}
+		}
+		close(written)
+	}()
+
+	for a := range in {
+		job := &orderedJob{asset: a, done: make(chan struct{})}
+		queue <- job
+		jobs <- job
+	}
+	close(jobs)
+	close(queue)
+	<-written
+}
@@ -0,0 +1,78 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+func TestProcessInputOrdered(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+
+	const lines = 500
+	var in strings.Builder
+	for i := 0; i < lines; i++ {
+		// every other line doesn't match anything, others match 2 CVEs
+		cpe := "cpe:/o:microsoft:windows_10:-::~~~~x64~,cpe:/a:adobe:flash_player:24.0.0.194"
+		if i%2 == 1 {
+			cpe = "cpe:/a:vendor:product:1.0"
+		}
+		fmt.Fprintf(&in, "%d\t%s\n", i, cpe)
+	}
+
+	for _, nproc := range []int{1, 8} {
+		t.Run(fmt.Sprintf("nproc-%d", nproc), func(t *testing.T) {
+			cfg := config{
+				NumProcessors:      nproc,
+				Ordered:            true,
+				CPEsAt:             2,
+				CVEsAt:             3,
+				InFieldSeparator:   "\t",
+				OutFieldSeparator:  ";",
+				InRecordSeparator:  ",",
+				OutRecordSeparator: "&",
+			}
+			var w bytes.Buffer
+			done := processInput(context.Background(), strings.NewReader(in.String()), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+			<-done
+			got := strings.Split(strings.TrimSpace(w.String()), "\n")
+			if len(got) != lines {
+				t.Fatalf("expecting %d findings, got %d", lines, len(got))
+			}
+			for i, rec := range got {
+				n, err := strconv.Atoi(strings.SplitN(rec, ";", 2)[0])
+				if err != nil {
+					t.Fatal(err)
+				}
+				// findings of line 2k are at 2k and 2k+1
+				if n != i-i%2 {
+					t.Fatalf("expecting finding of line %d at %d, got %q", i-i%2, i, rec)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,304 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/providers/epss"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// output formats, besides delimiter-separated records
+const (
+	outputJSON   = "json"
+	outputNDJSON = "ndjson"
+)
+
+// finding is a vulnerability matching some of the CPEs of an input record
+// it's the record of JSON and NDJSON output, key names are part of the output format:
+//
+//	{
+//	  "input": ["host1", "cpe:/a:haxx:curl:7.55.0"],
+//	  "cpes": ["cpe:/a:haxx:curl:7.55.0"],
+//	  "cve": "CVE-2017-8817",
+//	  "cves": ["CVE-2017-8817"],
+//	  "provider": "nvd",
+//	  "matched_cpes": ["cpe:/a:haxx:curl:7.55.0"],
+//	  "cwes": ["CWE-119"],
+//	  "cvss2": {"base_score": 7.5, "vector": "AV:N/AC:L/Au:N/C:P/I:P/A:P"},
+//	  "cvss3": {"base_score": 9.8, "vector": "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"},
+//	  "cvss4": {"base_score": 9.3, "vector": "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N"},
+//	  "known_exploited": "2022-04-15",
+//	  "epss": {"score": 0.0123, "percentile": 0.851},
+//	  "exploits": ["EDB-43365"],
+//	  "risk": {"score": 9.8, "severity": "critical"}
+//	}
+//
+// findings written for audit by -suppressed have the suppression rule as well:
+//
+//	"suppression": {"cve": "CVE-2017-8817", "expires": "2024-12-31", "justification": "FTP isn't used"}
+//
+// input is the input record without the erased fields and cpes are the CPE names found in it;
+// assets of JSON input have metadata instead of input, it's the asset object without the cpes key;
+// provider is set if it was given, the rest of keys is omitted if there's nothing to annotate the CVE with
+type finding struct {
+	Input          []string                   `json:"input,omitempty"`
+	Metadata       map[string]json.RawMessage `json:"metadata,omitempty"`
+	CPEs           []string                   `json:"cpes"`
+	CVE            string                     `json:"cve"`
+	CVEs           []string                   `json:"cves"`
+	Provider       string                     `json:"provider,omitempty"`
+	MatchedCPEs    []string                   `json:"matched_cpes"`
+	CWEs           []string                   `json:"cwes"`
+	Description    string                     `json:"description,omitempty"`
+	CVSS2          *cvssMetric                `json:"cvss2,omitempty"`
+	CVSS3          *cvssMetric                `json:"cvss3,omitempty"`
+	CVSS4          *cvssMetric                `json:"cvss4,omitempty"`
+	KnownExploited string                     `json:"known_exploited,omitempty"`
+	EPSS           *epssMetric                `json:"epss,omitempty"`
+	Exploits       []string                   `json:"exploits,omitempty"`
+	Risk           *riskRating                `json:"risk,omitempty"`
+	Explanation    *wfn.Explanation           `json:"explanation,omitempty"`
+	Suppression    *suppressRule              `json:"suppression,omitempty"`
+
+	vuln cvefeed.Vuln
+	// lineDone isn't a finding, but marks that all findings of the next input line were sent, for checkpointing
+	lineDone bool
+}
+
+type cvssMetric struct {
+	BaseScore float64 `json:"base_score"`
+	Vector    string  `json:"vector"`
+}
+
+// riskRating is the score and severity of the vulnerability as rated by the policy
+type riskRating struct {
+	Score    float64 `json:"score"`
+	Severity string  `json:"severity"`
+}
+
+type epssMetric struct {
+	Score      float64 `json:"score"`
+	Percentile float64 `json:"percentile"`
+}
+
+// risk returns rating of the vulnerability matching the CPEs if it's scored and the policy or the -risk output is set
+func (cfg *config) risk(vuln cvefeed.Vuln, matched []*wfn.Attributes) *riskRating {
+	if cfg.policy == nil && cfg.RiskAt == 0 {
+		return nil
+	}
+	score, sev, ok := cfg.rate(vuln, matched)
+	if !ok {
+		return nil
+	}
+	return &riskRating{Score: score, Severity: sev}
+}
+
+// explain returns why the vulnerability matched the CPEs if the -explain output is set
+func (cfg *config) explain(vuln cvefeed.Vuln, matched []*wfn.Attributes) *wfn.Explanation {
+	if cfg.ExplainAt == 0 {
+		return nil
+	}
+	return wfn.Explain(vuln, matched, cfg.RequireVersion)
+}
+
+// newFinding creates a finding of the vulnerability matching matched CPEs out of the CPEs of the input record
+func (cfg *config) newFinding(a *asset, cpes []string, provider string, vuln cvefeed.Vuln, matched []string, score *epss.Score) *finding {
+	var input []string
+	if a.record != nil {
+		input = make([]string, len(a.record))
+		copy(input, a.record)
+		input = cfg.EraseFields.skipFields(input)
+	}
+	f := finding{
+		Input:          input,
+		Metadata:       a.metadata,
+		CPEs:           nonNil(cpes),
+		CVE:            vuln.ID(),
+		CVEs:           nonNil(vuln.CVEs()),
+		Provider:       provider,
+		MatchedCPEs:    matched,
+		CWEs:           nonNil(vuln.CWEs()),
+		KnownExploited: cfg.dateAdded(vuln),
+		Exploits:       cfg.exploitIDs(vuln),
+		vuln:           vuln,
+	}
+	if cfg.DescriptionAt != 0 {
+		f.Description = cvefeed.Description(vuln, cfg.Lang)
+	}
+	if score := vuln.CVSSv2BaseScore(); score != 0 || vuln.CVSSv2Vector() != "" {
+		f.CVSS2 = &cvssMetric{BaseScore: score, Vector: vuln.CVSSv2Vector()}
+	}
+	if score := vuln.CVSSv3BaseScore(); score != 0 || vuln.CVSSv3Vector() != "" {
+		f.CVSS3 = &cvssMetric{BaseScore: score, Vector: vuln.CVSSv3Vector()}
+	}
+	if score := vuln.CVSSv4BaseScore(); score != 0 || vuln.CVSSv4Vector() != "" {
+		f.CVSS4 = &cvssMetric{BaseScore: score, Vector: vuln.CVSSv4Vector()}
+	}
+	if score != nil {
+		f.EPSS = &epssMetric{Score: score.EPSS, Percentile: score.Percentile}
+	}
+	return &f
+}
+
+func nonNil(ss []string) []string {
+	if ss == nil {
+		return []string{}
+	}
+	return ss
+}
+
+// record returns delimiter-separated output record of the finding, with the configured fields added to the input
+func (f *finding) record(cfg config) []string {
+	var cvss2, cvss3, cvss4 float64
+	if f.CVSS2 != nil {
+		cvss2 = f.CVSS2.BaseScore
+	}
+	if f.CVSS3 != nil {
+		cvss3 = f.CVSS3.BaseScore
+	}
+	if f.CVSS4 != nil {
+		cvss4 = f.CVSS4.BaseScore
+	}
+	cvss := cvss3
+	if cvss == 0 {
+		cvss = cvss2
+	}
+	var risk string
+	if f.Risk != nil {
+		risk = f.Risk.Severity
+	}
+	var epssScore, epssPercentile string
+	if f.EPSS != nil {
+		epssScore = strconv.FormatFloat(f.EPSS.Score, 'f', -1, 64)
+		epssPercentile = strconv.FormatFloat(f.EPSS.Percentile, 'f', -1, 64)
+	}
+
+	rec := make([]string, len(f.Input))
+	copy(rec, f.Input)
+	// input fields were erased already
+	return fieldsToSkip(nil).appendAt(
+		rec,
+		cfg.CVEsAt-1, f.CVE,
+		cfg.MatchesAt-1, strings.Join(f.MatchedCPEs, cfg.OutRecordSeparator),
+		cfg.CWEsAt-1, strings.Join(f.CWEs, cfg.OutRecordSeparator),
+		cfg.CVSS2At-1, fmt.Sprintf("%.1f", cvss2),
+		cfg.CVSS3At-1, fmt.Sprintf("%.1f", cvss3),
+		cfg.CVSS4At-1, fmt.Sprintf("%.1f", cvss4),
+		cfg.CVSSAt-1, fmt.Sprintf("%.1f", cvss),
+		cfg.ProviderAt-1, f.Provider,
+		cfg.KnownExploitedAt-1, f.KnownExploited,
+		cfg.EPSSScoreAt-1, epssScore,
+		cfg.EPSSPercentileAt-1, epssPercentile,
+		cfg.ExploitsAt-1, strings.Join(f.Exploits, cfg.OutRecordSeparator),
+		cfg.RiskAt-1, risk,
+		cfg.DescriptionAt-1, f.Description,
+		cfg.ExplainAt-1, f.Explanation.String(),
+	)
+}
+
+// findingWriter writes findings in the configured output format
+type findingWriter interface {
+	write(*finding) error
+	// close finishes the output, it doesn't close the underlying writer
+	close() error
+}
+
+func newFindingWriter(w io.Writer, cfg config) findingWriter {
+	if cfg.template != nil {
+		return &templateWriter{w: w, tmpl: cfg.template, cfg: cfg}
+	}
+	switch cfg.OutputFormat {
+	case outputJSON:
+		return &jsonWriter{w: w}
+	case outputNDJSON:
+		return &jsonWriter{w: w, ndjson: true}
+	}
+	cw := csv.NewWriter(w)
+	cw.Comma = rune(cfg.OutFieldSeparator[0])
+	return &csvWriter{w: cw, cfg: cfg}
+}
+
+type csvWriter struct {
+	w   *csv.Writer
+	cfg config
+}
+
+func (w *csvWriter) write(f *finding) error {
+	rec := f.record(w.cfg)
+	if f.Suppression != nil {
+		rec = append(rec, f.Suppression.Justification)
+	}
+	if err := w.w.Write(rec); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *csvWriter) close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// jsonWriter writes findings as a JSON array, or a finding per line if ndjson is set
+type jsonWriter struct {
+	w      io.Writer
+	ndjson bool
+	n      int
+}
+
+func (w *jsonWriter) write(f *finding) error {
+	data, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	var sep string
+	switch {
+	case w.ndjson:
+	case w.n == 0:
+		sep = "[\n"
+	default:
+		sep = ",\n"
+	}
+	w.n++
+	if w.ndjson {
+		data = append(data, '\n')
+	}
+	if _, err := io.WriteString(w.w, sep); err != nil {
+		return err
+	}
+	_, err = w.w.Write(data)
+	return err
+}
+
+func (w *jsonWriter) close() error {
+	if w.ndjson {
+		return nil
+	}
+	end := "\n]\n"
+	if w.n == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(w.w, end)
+	return err
+}
@@ -0,0 +1,211 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/wfn"
+	"github.com/facebookincubator/nvdtools/yaml"
+)
+
+// policy encodes internal risk ratings: its rules override or adjust scores and severities of
+// the vulnerabilities they match before the matches are filtered and written, e.g.:
+//
+//	rules:
+//	  - cve: CVE-2021-44228
+//	    severity: critical
+//	  - vendor: haxx
+//	    adjust: -1.5
+//	  - cwe: CWE-79
+//	    vendor: wordpress
+//	    score: 4.0
+//
+// rules are applied in order, so later rules adjust the score set by earlier ones
+type policy struct {
+	Rules []policyRule `json:"rules"`
+}
+
+// policyRule matches vulnerabilities which satisfy all of its conditions (CVE, Vendor and CWE),
+// at least one of them needs to be set
+type policyRule struct {
+	// CVE matches vulnerabilities with this ID or referencing this CVE
+	CVE string `json:"cve,omitempty"`
+	// Vendor matches vulnerabilities matching CPEs of this vendor
+	Vendor string `json:"vendor,omitempty"`
+	// CWE matches vulnerabilities with this problem type
+	CWE string `json:"cwe,omitempty"`
+
+	// Score overrides the score of matched vulnerabilities, it's rated as CVSS v3 score if the vulnerability isn't scored
+	Score *float64 `json:"score,omitempty"`
+	// Adjust is added to the score of matched vulnerabilities which are scored, the result is kept between 0 and 10
+	Adjust float64 `json:"adjust,omitempty"`
+	// Severity overrides severity of matched vulnerabilities, regardless of their score
+	Severity string `json:"severity,omitempty"`
+}
+
+// loadPolicy loads the policy file, written in YAML or, if its extension is .json, JSON
+func loadPolicy(path string) (*policy, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p policy
+	if filepath.Ext(path) == ".json" {
+		err = json.Unmarshal(data, &p)
+	} else {
+		err = yaml.Unmarshal(data, &p)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't decode policy %q: %v", path, err)
+	}
+	for i := range p.Rules {
+		if err := p.Rules[i].validate(); err != nil {
+			return nil, fmt.Errorf("rule %d of policy %q is invalid: %v", i+1, path, err)
+		}
+	}
+	return &p, nil
+}
+
+// UnmarshalJSON decodes the rule, accepting scores written as strings, which is how YAML scalars are decoded
+func (r *policyRule) UnmarshalJSON(data []byte) error {
+	type rule policyRule
+	var v struct {
+		*rule
+		Score  *json.Number `json:"score,omitempty"`
+		Adjust json.Number  `json:"adjust,omitempty"`
+	}
+	v.rule = (*rule)(r)
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	if v.Score != nil {
+		score, err := v.Score.Float64()
+		if err != nil {
+			return fmt.Errorf("can't parse score: %v", err)
+		}
+		r.Score = &score
+	}
+	if v.Adjust != "" {
+		adjust, err := v.Adjust.Float64()
+		if err != nil {
+			return fmt.Errorf("can't parse adjust: %v", err)
+		}
+		r.Adjust = adjust
+	}
+	return nil
+}
+
+// validate checks the rule and normalizes its severity
+func (r *policyRule) validate() error {
+	if r.CVE == "" && r.Vendor == "" && r.CWE == "" {
+		return fmt.Errorf("cve, vendor or cwe should be set")
+	}
+	if r.Score == nil && r.Adjust == 0 && r.Severity == "" {
+		return fmt.Errorf("score, adjust or severity should be set")
+	}
+	if r.Score != nil && (*r.Score < 0 || *r.Score > 10) {
+		return fmt.Errorf("score %v should be between 0 and 10", *r.Score)
+	}
+	if r.Severity != "" {
+		severities, err := parseSeverities(r.Severity)
+		if err != nil {
+			return err
+		}
+		if len(severities) != 1 {
+			return fmt.Errorf("a single severity should be set, got %q", r.Severity)
+		}
+		for sev := range severities {
+			r.Severity = sev
+		}
+	}
+	return nil
+}
+
+// matches returns true if the vulnerability matching the CPEs satisfies all conditions of the rule
+func (r *policyRule) matches(vuln cvefeed.Vuln, matched []*wfn.Attributes) bool {
+	if r.CVE != "" && r.CVE != vuln.ID() && !contains(vuln.CVEs(), r.CVE) {
+		return false
+	}
+	if r.CWE != "" && !contains(vuln.CWEs(), r.CWE) {
+		return false
+	}
+	if r.Vendor != "" {
+		for _, attr := range matched {
+			if attr != nil && strings.EqualFold(attr.Vendor, r.Vendor) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// apply applies matching rules to the score of the vulnerability, rated as the given CVSS major version,
+// and returns the resulting score, its severity and whether the vulnerability is scored
+func (p *policy) apply(vuln cvefeed.Vuln, matched []*wfn.Attributes, score float64, version int, ok bool) (float64, string, bool) {
+	var override string
+	for _, r := range p.Rules {
+		if !r.matches(vuln, matched) {
+			continue
+		}
+		if r.Score != nil {
+			if !ok {
+				version = 3
+			}
+			score, ok = *r.Score, true
+		}
+		if r.Adjust != 0 && ok {
+			score = clampScore(score + r.Adjust)
+		}
+		if r.Severity != "" {
+			override = r.Severity
+		}
+	}
+	switch {
+	case override != "":
+		return score, override, true
+	case ok:
+		return score, severity(score, version), true
+	default:
+		return 0, "", false
+	}
+}
+
+// clampScore keeps the score between 0 and 10, rounded to one decimal
+func clampScore(score float64) float64 {
+	switch {
+	case score < 0:
+		return 0
+	case score > 10:
+		return 10
+	}
+	return float64(int(score*10+0.5)) / 10
+}
+
+// contains returns true if s is one of the strings
+func contains(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
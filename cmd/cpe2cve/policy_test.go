@@ -0,0 +1,159 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const testPolicyFeed = `{"CVE_Items": [
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0001"},
+    "problemtype": {"problemtype_data": [{"description": [{"lang": "en", "value": "CWE-79"}]}]}},
+    "configurations": {"nodes": []}, "impact": {
+    "baseMetricV3": {"cvssV3": {"baseScore": 6.1, "vectorString": "CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:C/C:L/I:L/A:N"}}}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0002"}}, "configurations": {"nodes": []}, "impact": {
+    "baseMetricV3": {"cvssV3": {"baseScore": 9.8, "vectorString": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}}}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0003"}}, "configurations": {"nodes": []}}
+]}`
+
+func TestPolicy(t *testing.T) {
+	vulns, err := cvefeed.ParseJSON(bytes.NewBufferString(testPolicyFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	matched := []*wfn.Attributes{{Part: "a", Vendor: "haxx", Product: "curl"}}
+
+	score := func(f float64) *float64 { return &f }
+	for i, tc := range []struct {
+		rules  []policyRule
+		expect map[string]riskRating
+	}{
+		{
+			expect: map[string]riskRating{"CVE-0001": {6.1, severityMedium}, "CVE-0002": {9.8, severityCritical}},
+		},
+		{
+			rules:  []policyRule{{CWE: "CWE-79", Score: score(8)}, {CVE: "CVE-0003", Score: score(2)}},
+			expect: map[string]riskRating{"CVE-0001": {8, severityHigh}, "CVE-0002": {9.8, severityCritical}, "CVE-0003": {2, severityLow}},
+		},
+		{
+			rules:  []policyRule{{Vendor: "HAXX", Adjust: -1.5}, {CVE: "CVE-0002", Severity: severityLow}},
+			expect: map[string]riskRating{"CVE-0001": {4.6, severityMedium}, "CVE-0002": {8.3, severityLow}},
+		},
+		{
+			rules:  []policyRule{{Vendor: "apache", Adjust: 5}, {CVE: "CVE-0002", CWE: "CWE-79", Score: score(0)}},
+			expect: map[string]riskRating{"CVE-0001": {6.1, severityMedium}, "CVE-0002": {9.8, severityCritical}},
+		},
+		{
+			rules:  []policyRule{{Vendor: "haxx", Adjust: 5}},
+			expect: map[string]riskRating{"CVE-0001": {10, severityCritical}, "CVE-0002": {10, severityCritical}},
+		},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			cfg := config{RiskAt: 1}
+			if tc.rules != nil {
+				cfg.policy = &policy{Rules: tc.rules}
+			}
+			got := make(map[string]riskRating)
+			for _, vuln := range vulns {
+				if r := cfg.risk(vuln, matched); r != nil {
+					got[vuln.ID()] = *r
+				}
+			}
+			if !reflect.DeepEqual(got, tc.expect) {
+				t.Fatalf("expecting %v, got %v", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestPolicySkipSeverity(t *testing.T) {
+	vulns, err := cvefeed.ParseJSON(bytes.NewBufferString(testPolicyFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := config{
+		Severities: "critical",
+		policy:     &policy{Rules: []policyRule{{CVE: "CVE-0002", Severity: severityHigh}, {CWE: "CWE-79", Severity: severityCritical}}},
+	}
+	if cfg.severities, err = parseSeverities(cfg.Severities); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, vuln := range vulns {
+		if !cfg.skipSeverity(vuln, nil) {
+			got = append(got, vuln.ID())
+		}
+	}
+	if expect := []string{"CVE-0001"}; !reflect.DeepEqual(got, expect) {
+		t.Fatalf("expecting %v, got %v", expect, got)
+	}
+}
+
+func TestLoadPolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cpe2cve")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	for i, tc := range []struct {
+		policy string
+		yaml   bool
+		fail   bool
+	}{
+		{policy: `{"rules": [{"cve": "CVE-0001", "severity": "High"}, {"vendor": "haxx", "cwe": "CWE-79", "adjust": -1}]}`},
+		{policy: `{"rules": [{"severity": "high"}]}`, fail: true},
+		{policy: `{"rules": [{"cve": "CVE-0001"}]}`, fail: true},
+		{policy: `{"rules": [{"cve": "CVE-0001", "score": 11}]}`, fail: true},
+		{policy: `{"rules": [{"cve": "CVE-0001", "severity": "severe"}]}`, fail: true},
+		{policy: `{"rules": [{"cve": "CVE-0001", "severity": "high,low"}]}`, fail: true},
+		{policy: `{"rules": [`, fail: true},
+		{policy: "rules:\n  - cve: CVE-0001\n    severity: High\n  - vendor: haxx\n    adjust: -1.5\n", yaml: true},
+		{policy: "rules:\n  - cve: CVE-0001\n    score: eleven\n", yaml: true, fail: true},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			ext := ".json"
+			if tc.yaml {
+				ext = ".yaml"
+			}
+			path := filepath.Join(dir, fmt.Sprintf("policy%d%s", i+1, ext))
+			if err := ioutil.WriteFile(path, []byte(tc.policy), 0644); err != nil {
+				t.Fatal(err)
+			}
+			p, err := loadPolicy(path)
+			if tc.fail {
+				if err == nil {
+					t.Fatal("expecting policy to be invalid")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Rules[0].Severity != severityHigh {
+				t.Fatalf("expecting severity to be normalized, got %q", p.Rules[0].Severity)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/logging"
+)
+
+// progress tracks how far the processing of the input got, to report it periodically during long scans
+type progress struct {
+	// lines processed, shared with processors which count them anyway
+	lines *uint64
+	// findings which weren't suppressed
+	findings uint64
+	// bytes read from the input
+	read uint64
+	// size of the input in bytes, or the number of assets to process, 0 if unknown;
+	// estimated time left is known only if one of them is
+	size   int64
+	assets uint64
+	start  time.Time
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// newProgress returns progress counting lines processed in lines, the size of the input is known if it's a regular file
+func newProgress(lines *uint64, in io.Reader) *progress {
+	p := &progress{lines: lines}
+	if f, ok := in.(*os.File); ok {
+		if fi, err := f.Stat(); err == nil && fi.Mode().IsRegular() {
+			p.size = fi.Size()
+		}
+	}
+	return p
+}
+
+// reader returns in which counts bytes read from it
+func (p *progress) reader(in io.Reader) io.Reader {
+	return &countingReader{r: in, n: &p.read}
+}
+
+// setAssets sets the number of assets to process, the estimate is based on it instead of bytes read
+func (p *progress) setAssets(n int) {
+	atomic.StoreUint64(&p.assets, uint64(n))
+}
+
+// found counts a finding, it's a noop if progress isn't tracked
+func (p *progress) found() {
+	if p != nil {
+		atomic.AddUint64(&p.findings, 1)
+	}
+}
+
+// progressStatus is a progress report, written to the status file as JSON
+type progressStatus struct {
+	Lines    uint64        `json:"lines"`
+	Findings uint64        `json:"findings"`
+	Rate     float64       `json:"lines_per_second"`
+	Percent  float64       `json:"percent,omitempty"`
+	Elapsed  time.Duration `json:"-"`
+	ETA      time.Duration `json:"-"`
+	Updated  time.Time     `json:"updated"`
+}
+
+// MarshalJSON is a part of json.Marshaler interface, durations are formatted as strings
+func (s progressStatus) MarshalJSON() ([]byte, error) {
+	type status progressStatus
+	var eta string
+	if s.ETA != 0 {
+		eta = s.ETA.String()
+	}
+	return json.Marshal(struct {
+		status
+		Elapsed string `json:"elapsed"`
+		ETA     string `json:"eta,omitempty"`
+	}{status(s), s.Elapsed.String(), eta})
+}
+
+func (s progressStatus) String() string {
+	str := fmt.Sprintf("processed %d lines, %d findings, %.1f lines/s", s.Lines, s.Findings, s.Rate)
+	if s.Percent != 0 {
+		str += fmt.Sprintf(", %.1f%% done", s.Percent)
+	}
+	if s.ETA != 0 {
+		str += fmt.Sprintf(", ETA %v", s.ETA)
+	}
+	return str
+}
+
+// status reports the progress at time now
+func (p *progress) status(now time.Time) progressStatus {
+	s := progressStatus{
+		Lines:    atomic.LoadUint64(p.lines),
+		Findings: atomic.LoadUint64(&p.findings),
+		Elapsed:  now.Sub(p.start).Round(time.Second),
+		Updated:  now,
+	}
+	elapsed := now.Sub(p.start).Seconds()
+	if elapsed > 0 {
+		s.Rate = float64(s.Lines) / elapsed
+	}
+
+	var done float64
+	if assets := atomic.LoadUint64(&p.assets); assets != 0 {
+		done = float64(s.Lines) / float64(assets)
+	} else if p.size != 0 {
+		done = float64(atomic.LoadUint64(&p.read)) / float64(p.size)
+	}
+	if done > 1 {
+		done = 1
+	}
+	if done > 0 {
+		s.Percent = done * 100
+		s.ETA = time.Duration(elapsed * (1 - done) / done * float64(time.Second)).Round(time.Second)
+	}
+	return s
+}
+
+// run reports the progress every interval until it's stopped, to the file if it's set and logs otherwise
+func (p *progress) run(interval time.Duration, file string) {
+	p.start = time.Now()
+	p.stop, p.done = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(p.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case now := <-ticker.C:
+				p.report(p.status(now), file)
+			case <-p.stop:
+				p.report(p.status(time.Now()), file)
+				return
+			}
+		}
+	}()
+}
+
+// close stops reporting after the final report
+func (p *progress) close() {
+	close(p.stop)
+	<-p.done
+}
+
+func (p *progress) report(s progressStatus, file string) {
+	if file == "" {
+		logging.Infof("%v", s)
+		return
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		logging.Errorf("can't marshal progress: %v", err)
+		return
+	}
+	// replace the file at once, so whoever is watching it doesn't read a partial status
+	tmp := file + ".tmp"
+	if err := ioutil.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		logging.Errorf("can't write progress: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, file); err != nil {
+		logging.Errorf("can't write progress: %v", err)
+	}
+}
+
+type countingReader struct {
+	r io.Reader
+	n *uint64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	atomic.AddUint64(cr.n, uint64(n))
+	return n, err
+}
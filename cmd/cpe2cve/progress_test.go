@@ -0,0 +1,138 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+func TestProgressStatus(t *testing.T) {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	now := start.Add(100 * time.Second)
+	lines := uint64(500)
+
+	for i, tc := range []struct {
+		p       progress
+		percent float64
+		eta     time.Duration
+		str     string
+	}{
+		{
+			p:   progress{findings: 7},
+			str: "processed 500 lines, 7 findings, 5.0 lines/s",
+		},
+		{
+			p:       progress{read: 250, size: 1000},
+			percent: 25,
+			eta:     300 * time.Second,
+			str:     "processed 500 lines, 0 findings, 5.0 lines/s, 25.0% done, ETA 5m0s",
+		},
+		{
+			// assets are counted after all input was read
+			p:       progress{read: 1000, size: 1000, assets: 2000},
+			percent: 25,
+			eta:     300 * time.Second,
+			str:     "processed 500 lines, 0 findings, 5.0 lines/s, 25.0% done, ETA 5m0s",
+		},
+		{
+			p:       progress{read: 1000, size: 1000},
+			percent: 100,
+			str:     "processed 500 lines, 0 findings, 5.0 lines/s, 100.0% done",
+		},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			tc.p.lines, tc.p.start = &lines, start
+			s := tc.p.status(now)
+			if s.Lines != lines || s.Rate != 5 || s.Elapsed != 100*time.Second {
+				t.Fatalf("unexpected status %+v", s)
+			}
+			if s.Percent != tc.percent || s.ETA != tc.eta {
+				t.Fatalf("expected %v%% done and ETA %v, got %v%% and %v", tc.percent, tc.eta, s.Percent, s.ETA)
+			}
+			if s.String() != tc.str {
+				t.Fatalf("expected %q, got %q", tc.str, s.String())
+			}
+		})
+	}
+}
+
+func TestProcessInputProgress(t *testing.T) {
+	dir, err := ioutil.TempDir("", "progress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	inPath := filepath.Join(dir, "inventory.csv")
+	in := "cpe:/o:microsoft:windows_10:-::~~~~x64~+cpe:/a:adobe:flash_player:24.0.0.194\ncpe:/a:haxx:curl:7.0\n"
+	if err := ioutil.WriteFile(inPath, []byte(in), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.Open(inPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	cfg := config{
+		NumProcessors:      1,
+		CPEsAt:             1,
+		CVEsAt:             2,
+		InFieldSeparator:   ",",
+		OutFieldSeparator:  "|",
+		InRecordSeparator:  "+",
+		OutRecordSeparator: "&",
+		// only the final report is written
+		progressEvery: time.Hour,
+		ProgressFile:  filepath.Join(dir, "progress.json"),
+	}
+	var w bytes.Buffer
+	done := processInput(context.Background(), f, &w, singleCache(cvefeed.NewCache(dict)), cfg)
+	<-done
+
+	data, err := ioutil.ReadFile(cfg.ProgressFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var s struct {
+		Lines    uint64
+		Findings uint64
+		Percent  float64
+		Elapsed  string
+		ETA      *string
+	}
+	if err := json.Unmarshal(data, &s); err != nil {
+		t.Fatalf("can't parse progress %s: %v", data, err)
+	}
+	if s.Lines != 2 || s.Findings != 2 || s.Percent != 100 || s.Elapsed == "" || s.ETA != nil {
+		t.Fatalf("unexpected progress %s", data)
+	}
+}
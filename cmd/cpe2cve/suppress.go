@@ -0,0 +1,141 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/wfn"
+	"github.com/facebookincubator/nvdtools/yaml"
+)
+
+// suppressions are accepted false positives: findings matching any of the rules aren't reported, e.g.:
+//
+//	rules:
+//	  - cve: CVE-2021-44228
+//	    cpe: cpe:2.3:a:apache:log4j:2.14.1:*:*:*:*:*:*:*
+//	    expires: 2024-12-31
+//	    justification: JndiLookup class is removed from the classpath
+//	  - cpe: cpe:/a:example:internal_tool
+//	    justification: not deployed to production
+//
+// rules which expired are ignored, so the suppressed findings are reported again and can be reviewed
+type suppressions struct {
+	Rules []*suppressRule `json:"rules"`
+}
+
+// suppressRule suppresses findings which satisfy all of its conditions (CVE and CPE), at least one of them needs to be set;
+// it's output with the suppressed findings, so they can be audited
+type suppressRule struct {
+	// CVE matches findings of vulnerabilities with this ID or referencing this CVE
+	CVE string `json:"cve,omitempty"`
+	// CPE is the pattern matching all CPEs of the finding the vulnerability matched
+	CPE string `json:"cpe,omitempty"`
+	// Expires is RFC3339 time or date (2006-01-02) when the rule stops applying
+	Expires string `json:"expires,omitempty"`
+	// Justification explains why the findings are false positives
+	Justification string `json:"justification"`
+
+	// parsed from CPE and Expires
+	cpe     *wfn.Attributes
+	expires time.Time
+}
+
+// loadSuppressions loads the suppression file, it's a YAML document; rules which expired by now are dropped
+func loadSuppressions(path string, now time.Time) (*suppressions, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s suppressions
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("can't decode suppressions %q: %v", path, err)
+	}
+	rules := s.Rules[:0]
+	for i, r := range s.Rules {
+		if r == nil {
+			return nil, fmt.Errorf("rule %d of suppressions %q is empty", i+1, path)
+		}
+		if err := r.validate(); err != nil {
+			return nil, fmt.Errorf("rule %d of suppressions %q is invalid: %v", i+1, path, err)
+		}
+		if !r.expires.IsZero() && !now.Before(r.expires) {
+			logging.Warningf("rule %d of suppressions %q expired on %s, its findings are reported", i+1, path, r.Expires)
+			continue
+		}
+		rules = append(rules, r)
+	}
+	s.Rules = rules
+	return &s, nil
+}
+
+// validate checks the rule and parses its CPE pattern and expiry time
+func (r *suppressRule) validate() error {
+	if r.CVE == "" && r.CPE == "" {
+		return fmt.Errorf("cve or cpe should be set")
+	}
+	if r.Justification == "" {
+		return fmt.Errorf("justification should be set")
+	}
+	if r.CPE != "" {
+		attrs, err := wfn.Parse(r.CPE)
+		if err != nil {
+			return fmt.Errorf("can't parse cpe %q: %v", r.CPE, err)
+		}
+		r.cpe = attrs
+	}
+	if r.Expires != "" {
+		t, err := time.Parse(time.RFC3339, r.Expires)
+		if err != nil {
+			if t, err = time.Parse("2006-01-02", r.Expires); err != nil {
+				return fmt.Errorf("can't parse expires %q as RFC3339 time or date", r.Expires)
+			}
+		}
+		r.expires = t
+	}
+	return nil
+}
+
+// matches returns true if the vulnerability matching the CPEs satisfies all conditions of the rule
+func (r *suppressRule) matches(vuln cvefeed.Vuln, matched []*wfn.Attributes) bool {
+	if r.CVE != "" && r.CVE != vuln.ID() && !contains(vuln.CVEs(), r.CVE) {
+		return false
+	}
+	if r.cpe != nil {
+		for _, attr := range matched {
+			if attr != nil && !wfn.Match(r.cpe, attr) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// match returns the first rule suppressing the vulnerability matching the CPEs, or nil if there's none
+func (s *suppressions) match(vuln cvefeed.Vuln, matched []*wfn.Attributes) *suppressRule {
+	if s == nil {
+		return nil
+	}
+	for _, r := range s.Rules {
+		if r.matches(vuln, matched) {
+			return r
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+func TestLoadSuppressions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "suppress")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	for i, tc := range []struct {
+		doc   string
+		rules []string // CVEs of the loaded rules
+		fail  bool
+	}{
+		{doc: "rules: []", rules: []string{}},
+		{
+			doc: `
+rules:
+  - cve: CVE-0001
+    cpe: cpe:2.3:a:haxx:curl:*:*:*:*:*:*:*:*
+    expires: 2024-12-31
+    justification: FTP isn't used
+  - cve: CVE-0002
+    expires: 2024-06-01
+    justification: expires now
+  - cve: CVE-0003
+    expires: "2024-06-01T00:00:01Z"
+    justification: 'expires in a second'
+`,
+			rules: []string{"CVE-0001", "CVE-0003"},
+		},
+		{doc: "rules:\n  - justification: matches everything", fail: true},
+		{doc: "rules:\n  - cve: CVE-0001", fail: true},
+		{doc: "rules:\n  - cpe: haxx curl\n    justification: x", fail: true},
+		{doc: "rules:\n  - cve: CVE-0001\n    expires: next year\n    justification: x", fail: true},
+		{doc: "rules:\n  -\n  - cve: CVE-0001", fail: true},
+		{doc: "rules: {}", fail: true},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			path := filepath.Join(dir, fmt.Sprintf("suppress-%d.yaml", i+1))
+			if err := ioutil.WriteFile(path, []byte(tc.doc), 0644); err != nil {
+				t.Fatal(err)
+			}
+			s, err := loadSuppressions(path, now)
+			if tc.fail {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", s)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, r := range s.Rules {
+				got = append(got, r.CVE)
+			}
+			if strings.Join(got, ",") != strings.Join(tc.rules, ",") {
+				t.Fatalf("expected rules %v, got %v", tc.rules, got)
+			}
+		})
+	}
+}
+
+func TestProcessInputSuppress(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testDictJSONStr))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	rules := []*suppressRule{
+		// flash player matches CVE-2666-1337 as well, so the rule doesn't cover the finding
+		{CVE: "CVE-2666-1337", CPE: "cpe:/o:microsoft:windows_10", Justification: "not reachable"},
+		{CPE: "cpe:/o:microsoft:windows_10", Justification: "patched"},
+	}
+	for _, r := range rules {
+		if err := r.validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	in := "cpe:/o:microsoft:windows_10:-::~~~~x64~+cpe:/a:adobe:flash_player:24.0.0.194"
+
+	for _, audit := range []bool{false, true} {
+		t.Run(fmt.Sprintf("audit-%t", audit), func(t *testing.T) {
+			cfg := config{
+				NumProcessors:      1,
+				CPEsAt:             1,
+				CVEsAt:             2,
+				InFieldSeparator:   ",",
+				OutFieldSeparator:  "|",
+				InRecordSeparator:  "+",
+				OutRecordSeparator: "&",
+				suppressions:       &suppressions{Rules: rules},
+			}
+			var w, sw bytes.Buffer
+			if audit {
+				cfg.suppressedOut = &sw
+			}
+			done := processInput(context.Background(), strings.NewReader(in), &w, singleCache(cvefeed.NewCache(dict)), cfg)
+			<-done
+			expect := "cpe:/o:microsoft:windows_10:-::~~~~x64~&cpe:/a:adobe:flash_player:24.0.0.194|CVE-2666-1337\n"
+			if w.String() != expect {
+				t.Fatalf("expected output\n%s\ngot\n%s", expect, w.String())
+			}
+			var expectSuppressed string
+			if audit {
+				expectSuppressed = "cpe:/o:microsoft:windows_10:-::~~~~x64~&cpe:/a:adobe:flash_player:24.0.0.194|CVE-2016-0165|patched\n"
+			}
+			if sw.String() != expectSuppressed {
+				t.Fatalf("expected suppressed output\n%s\ngot\n%s", expectSuppressed, sw.String())
+			}
+		})
+	}
+}
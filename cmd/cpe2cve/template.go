@@ -0,0 +1,130 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateFuncs are available in output templates besides the text/template builtins
+var templateFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+// parseTemplate parses output template, a newline is added to the template if it doesn't end with one
+func parseTemplate(text string) (*template.Template, error) {
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return template.New("output").Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+}
+
+// templateData is the data of output templates, e.g.:
+//
+//	{{.CVE}} {{.CVSS31.BaseScore}} {{.MatchedCPE}} {{.Published.Format "2006-01-02"}}
+//
+// it has all fields of finding, with scores which weren't set being zero and decoded metadata,
+// MatchedCPE with matched CPE names joined by the inner output delimiter and dates of the vulnerability
+type templateData struct {
+	*finding
+	Metadata     map[string]interface{}
+	MatchedCPE   string
+	CVSS2        cvssMetric
+	CVSS3        cvssMetric
+	CVSS4        cvssMetric
+	EPSS         epssMetric
+	Risk         riskRating
+	Published    time.Time
+	LastModified time.Time
+}
+
+func newTemplateData(f *finding, cfg config) *templateData {
+	d := templateData{
+		finding:    f,
+		MatchedCPE: strings.Join(f.MatchedCPEs, cfg.OutRecordSeparator),
+	}
+	if f.CVSS2 != nil {
+		d.CVSS2 = *f.CVSS2
+	}
+	if f.CVSS3 != nil {
+		d.CVSS3 = *f.CVSS3
+	}
+	if f.CVSS4 != nil {
+		d.CVSS4 = *f.CVSS4
+	}
+	if f.EPSS != nil {
+		d.EPSS = *f.EPSS
+	}
+	if f.Risk != nil {
+		d.Risk = *f.Risk
+	}
+	if f.Metadata != nil {
+		d.Metadata = make(map[string]interface{}, len(f.Metadata))
+		for k, v := range f.Metadata {
+			var value interface{}
+			if err := json.Unmarshal(v, &value); err == nil {
+				d.Metadata[k] = value
+			}
+		}
+	}
+	if f.vuln != nil {
+		d.Published, d.LastModified = f.vuln.Published(), f.vuln.LastModified()
+	}
+	return &d
+}
+
+// CVSS30 returns CVSS v3 metric if it's CVSS 3.0, zero metric otherwise
+func (d *templateData) CVSS30() cvssMetric {
+	return d.cvss3Version("3.0")
+}
+
+// CVSS31 returns CVSS v3 metric if it's CVSS 3.1, zero metric otherwise
+func (d *templateData) CVSS31() cvssMetric {
+	return d.cvss3Version("3.1")
+}
+
+func (d *templateData) cvss3Version(version string) cvssMetric {
+	if !strings.HasPrefix(d.CVSS3.Vector, "CVSS:"+version+"/") {
+		return cvssMetric{}
+	}
+	return d.CVSS3
+}
+
+// templateWriter writes findings with a template
+type templateWriter struct {
+	w    io.Writer
+	tmpl *template.Template
+	cfg  config
+	buf  bytes.Buffer
+}
+
+func (w *templateWriter) write(f *finding) error {
+	w.buf.Reset()
+	if err := w.tmpl.Execute(&w.buf, newTemplateData(f, w.cfg)); err != nil {
+		return fmt.Errorf("can't execute output template: %v", err)
+	}
+	_, err := w.w.Write(w.buf.Bytes())
+	return err
+}
+
+func (w *templateWriter) close() error {
+	return nil
+}
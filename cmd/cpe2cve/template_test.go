@@ -0,0 +1,114 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+const testTemplateFeed = `{"CVE_Items": [{
+  "cve": {
+    "CVE_data_meta": {"ID": "CVE-2009-2273"},
+    "problemtype": {"problemtype_data": [{"description": [{"lang": "en", "value": "CWE-310"}]}]}
+  },
+  "configurations": {"nodes": [{"operator": "OR", "cpe_match": [{"vulnerable": true, "cpe23Uri": "cpe:2.3:h:huaweidevice:d100:*:*:*:*:*:*:*:*"}]}]},
+  "impact": {
+    "baseMetricV2": {"cvssV2": {"baseScore": 5, "vectorString": "AV:N/AC:L/Au:N/C:P/I:N/A:N", "version": "2.0"}},
+    "baseMetricV3": {"cvssV3": {"baseScore": 7.5, "vectorString": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N", "version": "3.1"}}
+  },
+  "lastModifiedDate": "2009-07-02T04:00Z",
+  "publishedDate": "2009-07-01T13:00Z"
+}]}`
+
+func TestProcessInputTemplate(t *testing.T) {
+	dict, err := cvefeed.LoadFeed(func(_ string) ([]cvefeed.Vuln, error) {
+		return cvefeed.ParseJSON(bytes.NewBufferString(testTemplateFeed))
+	}, "")
+	if err != nil {
+		t.Fatalf("couldn't parse JSON dictionary: %v", err)
+	}
+	cache := cvefeed.NewCache(dict)
+
+	for i, tc := range []struct {
+		in, inSep, tmpl string
+		expect          string
+	}{
+		{
+			in:     "host1\tcpe:/h:huaweidevice:d100",
+			inSep:  "\t",
+			tmpl:   `{{.CVE}} {{.CVSS2.BaseScore}} {{.CVSS30.BaseScore}} {{.CVSS31.BaseScore}} {{.MatchedCPE}} {{.Published.Format "2006-01-02"}} {{join .CWEs ";"}} {{index .Input 0}}`,
+			expect: "CVE-2009-2273 5 0 7.5 cpe:/h:huaweidevice:d100 2009-07-01 CWE-310 host1\n",
+		},
+		{
+			in:     `{"cpes": ["cpe:/h:huaweidevice:d100"], "host": "host2", "owner": {"team": "infra"}}`,
+			inSep:  "ndjson",
+			tmpl:   "{{.Metadata.host}},{{.Metadata.owner.team}},{{.Metadata.missing}},{{.CVE}},{{.LastModified.Format \"2006-01-02\"}}\n",
+			expect: "host2,infra,<no value>,CVE-2009-2273,2009-07-02\n",
+		},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			cfg := config{
+				NumProcessors:      1,
+				CPEsAt:             2,
+				InFieldSeparator:   tc.inSep,
+				InRecordSeparator:  ",",
+				OutFieldSeparator:  "\t",
+				OutRecordSeparator: ",",
+				OutputTemplate:     tc.tmpl,
+				Feeds:              map[string][]string{"test": {"feed.json"}},
+			}
+			if err := cfg.validate(); err != nil {
+				t.Fatal(err)
+			}
+			var w bytes.Buffer
+			done := processInput(context.Background(), strings.NewReader(tc.in), &w, singleCache(cache), cfg)
+			<-done
+			if got := w.String(); got != tc.expect {
+				t.Fatalf("expecting %q, got %q", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	for i, tc := range []struct {
+		tmpl, sep, vex string
+		ok             bool
+	}{
+		{tmpl: "{{.CVE}}", sep: "\t", ok: true},
+		{tmpl: "{{.CVE", sep: "\t", ok: false},
+		{tmpl: "{{.CVE}}", sep: "json", ok: false},
+		{tmpl: "{{.CVE}}", sep: "\t", vex: "openvex", ok: false},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			cfg := config{
+				CPEsAt:            1,
+				OutFieldSeparator: tc.sep,
+				OutputTemplate:    tc.tmpl,
+				VEXFormat:         tc.vex,
+				Feeds:             map[string][]string{"test": {"feed.json"}},
+			}
+			if err := cfg.validate(); (err == nil) != tc.ok {
+				t.Fatalf("unexpected validation result: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,376 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// supported VEX formats
+const (
+	vexOpenVEX   = "openvex"
+	vexCycloneDX = "cyclonedx"
+)
+
+// VEX statuses, OpenVEX names are used internally
+const (
+	vexAffected    = "affected"
+	vexNotAffected = "not_affected"
+)
+
+const vexSuppressedImpact = "the vulnerable version was matched, but an override feed or the distribution feed marks it as fixed, e.g. by a patch backported by the distribution"
+
+// vexCollector collects statuses of matched products for all CVEs, so they can be written as a single VEX document
+type vexCollector struct {
+	mu       sync.Mutex
+	vulns    map[string]cvefeed.Vuln
+	products map[string]*wfn.Attributes
+	statuses map[vexKey]string
+}
+
+type vexKey struct {
+	vuln, product string
+}
+
+func newVEXCollector() *vexCollector {
+	return &vexCollector{
+		vulns:    make(map[string]cvefeed.Vuln),
+		products: make(map[string]*wfn.Attributes),
+		statuses: make(map[vexKey]string),
+	}
+}
+
+// add records the status of products for the vulnerability
+// a product which is affected through any feed is affected, regardless of what the others say
+func (vc *vexCollector) add(vuln cvefeed.Vuln, products []*wfn.Attributes, status string) {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+	if _, ok := vc.vulns[vuln.ID()]; !ok {
+		vc.vulns[vuln.ID()] = vuln
+	}
+	for _, attrs := range products {
+		if attrs == nil {
+			continue
+		}
+		uri := attrs.BindToURI()
+		vc.products[uri] = attrs
+		key := vexKey{vuln.ID(), uri}
+		if vc.statuses[key] != vexAffected {
+			vc.statuses[key] = status
+		}
+	}
+}
+
+// vexGroup is a vulnerability together with products which have the same status
+type vexGroup struct {
+	vuln     cvefeed.Vuln
+	status   string
+	products []string
+}
+
+// groups returns collected statuses grouped by vulnerability and status, sorted
+func (vc *vexCollector) groups() []*vexGroup {
+	vc.mu.Lock()
+	defer vc.mu.Unlock()
+	byKey := make(map[[2]string]*vexGroup)
+	var groups []*vexGroup
+	for key, status := range vc.statuses {
+		k := [2]string{key.vuln, status}
+		g, ok := byKey[k]
+		if !ok {
+			g = &vexGroup{vuln: vc.vulns[key.vuln], status: status}
+			byKey[k] = g
+			groups = append(groups, g)
+		}
+		g.products = append(g.products, key.product)
+	}
+	for _, g := range groups {
+		sort.Strings(g.products)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if id1, id2 := groups[i].vuln.ID(), groups[j].vuln.ID(); id1 != id2 {
+			return id1 < id2
+		}
+		return groups[i].status < groups[j].status
+	})
+	return groups
+}
+
+// write writes the VEX document in the given format
+func (vc *vexCollector) write(w io.Writer, format, author string, timestamp time.Time) error {
+	var doc interface{}
+	switch format {
+	case vexOpenVEX:
+		doc = vc.openVEX(author, timestamp)
+	case vexCycloneDX:
+		doc = vc.cycloneDX(author, timestamp)
+	default:
+		return fmt.Errorf("unsupported vex format %q", format)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// https://github.com/openvex/spec/blob/main/OPENVEX-SPEC.md
+
+type openVEXDocument struct {
+	Context    string              `json:"@context"`
+	ID         string              `json:"@id"`
+	Author     string              `json:"author"`
+	Timestamp  string              `json:"timestamp"`
+	Version    int                 `json:"version"`
+	Tooling    string              `json:"tooling,omitempty"`
+	Statements []*openVEXStatement `json:"statements"`
+}
+
+type openVEXStatement struct {
+	Vulnerability   openVEXVulnerability `json:"vulnerability"`
+	Products        []*openVEXProduct    `json:"products"`
+	Status          string               `json:"status"`
+	Justification   string               `json:"justification,omitempty"`
+	ImpactStatement string               `json:"impact_statement,omitempty"`
+	ActionStatement string               `json:"action_statement,omitempty"`
+}
+
+type openVEXVulnerability struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+type openVEXProduct struct {
+	ID          string            `json:"@id"`
+	Identifiers map[string]string `json:"identifiers,omitempty"`
+}
+
+func (vc *vexCollector) openVEX(author string, timestamp time.Time) *openVEXDocument {
+	doc := openVEXDocument{
+		Context:    "https://openvex.dev/ns/v0.2.0",
+		ID:         "urn:uuid:" + newUUID(),
+		Author:     author,
+		Timestamp:  timestamp.UTC().Format(time.RFC3339),
+		Version:    1,
+		Tooling:    "nvdtools cpe2cve",
+		Statements: []*openVEXStatement{},
+	}
+	for _, g := range vc.groups() {
+		st := openVEXStatement{
+			Vulnerability: openVEXVulnerability{Name: g.vuln.ID()},
+			Status:        g.status,
+		}
+		for _, cve := range g.vuln.CVEs() {
+			if cve != g.vuln.ID() {
+				st.Vulnerability.Aliases = append(st.Vulnerability.Aliases, cve)
+			}
+		}
+		for _, uri := range g.products {
+			st.Products = append(st.Products, &openVEXProduct{
+				ID: uri,
+				Identifiers: map[string]string{
+					"cpe22": uri,
+					"cpe23": vc.products[uri].BindToFmtString(),
+				},
+			})
+		}
+		switch g.status {
+		case vexAffected:
+			st.ActionStatement = "Update the product to a version which isn't affected"
+		case vexNotAffected:
+			st.Justification = "vulnerable_code_not_present"
+			st.ImpactStatement = vexSuppressedImpact
+		}
+		doc.Statements = append(doc.Statements, &st)
+	}
+	return &doc
+}
+
+// https://cyclonedx.org/capabilities/vex/
+
+type cycloneDXDocument struct {
+	BOMFormat       string                    `json:"bomFormat"`
+	SpecVersion     string                    `json:"specVersion"`
+	SerialNumber    string                    `json:"serialNumber"`
+	Version         int                       `json:"version"`
+	Metadata        cycloneDXMetadata         `json:"metadata"`
+	Components      []*cycloneDXComponent     `json:"components"`
+	Vulnerabilities []*cycloneDXVulnerability `json:"vulnerabilities"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp string `json:"timestamp"`
+	Authors   []struct {
+		Name string `json:"name"`
+	} `json:"authors,omitempty"`
+	Tools []struct {
+		Vendor string `json:"vendor"`
+		Name   string `json:"name"`
+	} `json:"tools"`
+}
+
+type cycloneDXComponent struct {
+	BOMRef  string `json:"bom-ref"`
+	Type    string `json:"type"`
+	Group   string `json:"group,omitempty"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	CPE     string `json:"cpe"`
+}
+
+type cycloneDXVulnerability struct {
+	BOMRef   string             `json:"bom-ref"`
+	ID       string             `json:"id"`
+	Source   *cycloneDXSource   `json:"source,omitempty"`
+	Ratings  []*cycloneDXRating `json:"ratings,omitempty"`
+	CWEs     []int              `json:"cwes,omitempty"`
+	Analysis cycloneDXAnalysis  `json:"analysis"`
+	Affects  []*cycloneDXAffect `json:"affects"`
+}
+
+type cycloneDXSource struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+type cycloneDXRating struct {
+	Score  float64 `json:"score"`
+	Method string  `json:"method"`
+	Vector string  `json:"vector,omitempty"`
+}
+
+type cycloneDXAnalysis struct {
+	State         string `json:"state"`
+	Justification string `json:"justification,omitempty"`
+	Detail        string `json:"detail,omitempty"`
+}
+
+type cycloneDXAffect struct {
+	Ref string `json:"ref"`
+}
+
+func (vc *vexCollector) cycloneDX(author string, timestamp time.Time) *cycloneDXDocument {
+	doc := cycloneDXDocument{
+		BOMFormat:       "CycloneDX",
+		SpecVersion:     "1.5",
+		SerialNumber:    "urn:uuid:" + newUUID(),
+		Version:         1,
+		Components:      []*cycloneDXComponent{},
+		Vulnerabilities: []*cycloneDXVulnerability{},
+	}
+	doc.Metadata.Timestamp = timestamp.UTC().Format(time.RFC3339)
+	if author != "" {
+		doc.Metadata.Authors = append(doc.Metadata.Authors, struct {
+			Name string `json:"name"`
+		}{author})
+	}
+	doc.Metadata.Tools = append(doc.Metadata.Tools, struct {
+		Vendor string `json:"vendor"`
+		Name   string `json:"name"`
+	}{"facebookincubator", "nvdtools cpe2cve"})
+
+	groups := vc.groups()
+	referenced := make(map[string]bool)
+	for i, g := range groups {
+		v := cycloneDXVulnerability{
+			BOMRef:  fmt.Sprintf("vulnerability-%d", i+1),
+			ID:      g.vuln.ID(),
+			Ratings: cycloneDXRatings(g.vuln),
+		}
+		if strings.HasPrefix(v.ID, "CVE-") {
+			v.Source = &cycloneDXSource{Name: "NVD", URL: "https://nvd.nist.gov/vuln/detail/" + v.ID}
+		}
+		for _, cwe := range g.vuln.CWEs() {
+			if n, err := strconv.Atoi(strings.TrimPrefix(cwe, "CWE-")); err == nil {
+				v.CWEs = append(v.CWEs, n)
+			}
+		}
+		switch g.status {
+		case vexAffected:
+			v.Analysis.State = "exploitable"
+		case vexNotAffected:
+			v.Analysis.State = "not_affected"
+			v.Analysis.Justification = "code_not_present"
+			v.Analysis.Detail = vexSuppressedImpact
+		}
+		for _, uri := range g.products {
+			v.Affects = append(v.Affects, &cycloneDXAffect{Ref: uri})
+			referenced[uri] = true
+		}
+		doc.Vulnerabilities = append(doc.Vulnerabilities, &v)
+	}
+
+	uris := make([]string, 0, len(referenced))
+	for uri := range referenced {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	for _, uri := range uris {
+		attrs := vc.products[uri]
+		c := cycloneDXComponent{
+			BOMRef:  uri,
+			Type:    "application",
+			Group:   wfn.StripSlashes(attrs.Vendor),
+			Name:    wfn.StripSlashes(attrs.Product),
+			Version: wfn.StripSlashes(attrs.Version),
+			CPE:     attrs.BindToFmtString(),
+		}
+		switch attrs.Part {
+		case "o":
+			c.Type = "operating-system"
+		case "h":
+			c.Type = "device"
+		}
+		if c.Version == wfn.NA {
+			c.Version = ""
+		}
+		doc.Components = append(doc.Components, &c)
+	}
+
+	return &doc
+}
+
+func cycloneDXRatings(vuln cvefeed.Vuln) []*cycloneDXRating {
+	var ratings []*cycloneDXRating
+	if score := vuln.CVSSv3BaseScore(); score != 0 {
+		method, vector := "CVSSv3", vuln.CVSSv3Vector()
+		if strings.HasPrefix(vector, "CVSS:3.1/") {
+			method = "CVSSv31"
+		}
+		ratings = append(ratings, &cycloneDXRating{Score: score, Method: method, Vector: vector})
+	}
+	if score := vuln.CVSSv2BaseScore(); score != 0 {
+		ratings = append(ratings, &cycloneDXRating{Score: score, Method: "CVSSv2", Vector: vuln.CVSSv2Vector()})
+	}
+	return ratings
+}
+
+// newUUID returns a random (version 4) uuid
+func newUUID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
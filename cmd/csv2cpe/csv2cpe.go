@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"encoding/csv"
 	"flag"
 	"fmt"
@@ -24,6 +25,8 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/facebookincubator/nvdtools/cpedict"
+	"github.com/facebookincubator/nvdtools/shutdown"
 	"github.com/facebookincubator/nvdtools/wfn"
 )
 
@@ -38,6 +41,10 @@ func main() {
 	unmap := flag.Bool("x", false, "erase all columns mapped from -cpe_{field}, optional")
 	lower := flag.Bool("lower", false, "force cpe output to be lower case, optional")
 	defaultNA := flag.Bool("na", false, "if set, unknown CPE attributes are set to N/A, otherwise to ANY")
+	dictPath := flag.String("cpe_dict", "", "CPE dictionary (XML feed or CPE API 2.0 mirror, optionally compressed); if set, the closest official CPE is added after the generated one")
+	validate := flag.Bool("validate", false, "replace the generated CPE with the closest official one from -cpe_dict instead of adding it, optional")
+	infer := flag.Bool("infer", false, "infer vendor, product and version from raw inventory data using default normalization rules, optional")
+	rulesPath := flag.String("rules", "", "file with normalization rules applied to attributes before generating CPE, optional")
 
 	flag.Parse()
 
@@ -52,6 +59,11 @@ func main() {
 		*odelim = *idelim
 	}
 
+	if *validate && *dictPath == "" {
+		fmt.Fprintln(os.Stderr, "-validate requires -cpe_dict")
+		os.Exit(1)
+	}
+
 	var err error
 	eraseCols := make(IntSet)
 
@@ -73,9 +85,35 @@ func main() {
 		DefaultNA:         *defaultNA,
 		CPEOutputColumn:   *idx,
 		EraseInputColumns: eraseCols,
+		Validate:          *validate,
 	}
 
-	err = p.Process(acm, os.Stdin, os.Stdout)
+	if *infer {
+		p.Rules = DefaultRules()
+	}
+
+	if *rulesPath != "" {
+		rules, err := LoadRulesFile(*rulesPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		p.Rules = append(p.Rules, rules...)
+	}
+
+	ctx, stop := shutdown.Context(context.Background())
+	defer stop()
+
+	if *dictPath != "" {
+		dict, err := cpedict.LoadContext(ctx, *dictPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		p.Dictionary = cpedict.NewIndex(dict)
+	}
+
+	err = p.ProcessContext(ctx, acm, os.Stdin, os.Stdout)
 	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
@@ -90,10 +128,20 @@ type Processor struct {
 	DefaultNA         bool   // true -> default attribute value is NA, false -> ANY
 	CPEOutputColumn   int    // index to add cpe column in the output, after erases
 	EraseInputColumns IntSet // input columns to erase before output
+	// if set, the closest official CPE from the dictionary is added after the generated one
+	Dictionary *cpedict.Index
+	Validate   bool  // replace the generated CPE with the closest official one instead of adding it
+	Rules      Rules // rules applied to attribute values before generating CPE
 }
 
 // Process reads CSV from r and writes CSV + CPE to w.
 func (p *Processor) Process(acm *AttributeColumnMap, r io.Reader, w io.Writer) error {
+	return p.ProcessContext(context.Background(), acm, r, w)
+}
+
+// ProcessContext is Process which stops when the context is done, after writing the records processed so far,
+// and returns the error of the context.
+func (p *Processor) ProcessContext(ctx context.Context, acm *AttributeColumnMap, r io.Reader, w io.Writer) error {
 	reader := csv.NewReader(r)
 	reader.Comma = p.InputComma
 
@@ -105,6 +153,9 @@ func (p *Processor) Process(acm *AttributeColumnMap, r io.Reader, w io.Writer) e
 	line := 0
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		line++
 
 		cols, err := reader.Read()
@@ -115,13 +166,31 @@ func (p *Processor) Process(acm *AttributeColumnMap, r io.Reader, w io.Writer) e
 			return fmt.Errorf("error parsing line %d: %v", line, err)
 		}
 
-		cpe, err := acm.CPE(cols, p.CPEToLower, p.DefaultNA)
+		rec := acm.Record(cols, p.CPEToLower)
+		p.Rules.Apply(rec)
+
+		attr, err := rec.Attributes(p.DefaultNA)
 		if err != nil {
 			return fmt.Errorf("error parsing columns in line %d: %v", line, err)
 		}
 
 		cols = RemoveColumns(cols, p.EraseInputColumns)
-		cols = InsertColumn(cols, cpe, p.CPEOutputColumn)
+
+		if p.Validate {
+			cols = InsertColumn(cols, p.suggest(attr), p.CPEOutputColumn)
+			writer.Write(cols)
+			continue
+		}
+
+		cols = InsertColumn(cols, attr.BindToURI(), p.CPEOutputColumn)
+
+		if p.Dictionary != nil {
+			idx := p.CPEOutputColumn
+			if idx > 0 {
+				idx++
+			}
+			cols = InsertColumn(cols, p.suggest(attr), idx)
+		}
 
 		writer.Write(cols)
 	}
@@ -129,6 +198,26 @@ func (p *Processor) Process(acm *AttributeColumnMap, r io.Reader, w io.Writer) e
 	return nil
 }
 
+// minSuggestionScore is the minimum score of dictionary search results used as suggestions
+const minSuggestionScore = 0.5
+
+// suggest returns the generated CPE with vendor and product replaced by the closest ones from the dictionary.
+// Empty string is returned if nothing similar was found.
+func (p *Processor) suggest(attr *wfn.Attributes) string {
+	query := wfn.StripSlashes(attr.Vendor) + " " + wfn.StripSlashes(attr.Product)
+	suggestions := p.Dictionary.Search(query, 1)
+	if len(suggestions) == 0 || suggestions[0].Score < minSuggestionScore {
+		return ""
+	}
+	name := suggestions[0].Item.Name
+	suggested := *attr
+	suggested.Vendor, suggested.Product = name.Vendor, name.Product
+	if suggested.Part == wfn.Any || suggested.Part == wfn.NA {
+		suggested.Part = name.Part
+	}
+	return suggested.BindToURI()
+}
+
 // AttributeColumnMap maps CSV columns to WFN Attribute fields.
 type AttributeColumnMap struct {
 	Part      int
@@ -161,34 +250,25 @@ func (acm *AttributeColumnMap) AddFlags(fs *flag.FlagSet) {
 
 // CPE returns a CPE by mapping cols to the configured column indices.
 func (acm *AttributeColumnMap) CPE(cols []string, lower, na bool) (string, error) {
-	var err error
-	var attr *wfn.Attributes
-	if na {
-		attr = wfn.NewAttributesWithNA()
-	} else {
-		attr = wfn.NewAttributesWithAny()
-	}
-
-	m := map[int]*string{
-		acm.Part:      &attr.Part,
-		acm.Vendor:    &attr.Vendor,
-		acm.Product:   &attr.Product,
-		acm.Version:   &attr.Version,
-		acm.Update:    &attr.Update,
-		acm.Edition:   &attr.Edition,
-		acm.SWEdition: &attr.SWEdition,
-		acm.TargetSW:  &attr.TargetSW,
-		acm.TargetHW:  &attr.TargetHW,
-		acm.Other:     &attr.Other,
-		acm.Language:  &attr.Language,
+	attr, err := acm.Attributes(cols, lower, na)
+	if err != nil {
+		return "", err
 	}
+	return attr.BindToURI(), nil
+}
 
-	delete(m, 0)
+// Attributes returns WFN attributes by mapping cols to the configured column indices.
+func (acm *AttributeColumnMap) Attributes(cols []string, lower, na bool) (*wfn.Attributes, error) {
+	return acm.Record(cols, lower).Attributes(na)
+}
 
-	for i, v := range m {
+// Record returns values of the configured columns keyed by attribute name.
+func (acm *AttributeColumnMap) Record(cols []string, lower bool) Record {
+	rec := make(Record)
+	for field, i := range acm.fields() {
 		j := i - 1
 
-		if j >= len(cols) {
+		if j < 0 || j >= len(cols) {
 			continue
 		}
 
@@ -198,19 +278,32 @@ func (acm *AttributeColumnMap) CPE(cols []string, lower, na bool) (string, error
 			col = strings.ToLower(col)
 		}
 
-		if i == acm.Version {
+		if field == "version" {
 			for strings.HasSuffix(col, ".") {
 				col = strings.TrimSuffix(col, ".")
 			}
 		}
 
-		*v, err = wfn.WFNize(col)
-		if err != nil {
-			return "", err
-		}
+		rec[field] = col
 	}
+	return rec
+}
 
-	return attr.BindToURI(), nil
+// fields maps attribute names, as used in -cpe_{field} flags, to column indices.
+func (acm *AttributeColumnMap) fields() map[string]int {
+	return map[string]int{
+		"part":      acm.Part,
+		"vendor":    acm.Vendor,
+		"product":   acm.Product,
+		"version":   acm.Version,
+		"update":    acm.Update,
+		"edition":   acm.Edition,
+		"swedition": acm.SWEdition,
+		"targetsw":  acm.TargetSW,
+		"targethw":  acm.TargetHW,
+		"other":     acm.Other,
+		"language":  acm.Language,
+	}
 }
 
 // Columns returns a list of columns configured in the map, sorted descending.
@@ -19,7 +19,10 @@ import (
 	"flag"
 	"fmt"
 	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/facebookincubator/nvdtools/cpedict"
 )
 
 func TestIntSet(t *testing.T) {
@@ -87,21 +90,21 @@ func TestProcessor(t *testing.T) {
 			NewIntSet(1, 2, 3),
 			true,
 			"Foo\t1.0...\tdelet\ta\nbar\t2.0\tdelet\tb",
-			"a,cpe:/-:-:foo:1.0:-:-:-\nb,cpe:/-:-:bar:2.0:-:-:-\n",
+			"a,cpe:/-:-:foo:1.0:-:~-~-~-~-~-:-\nb,cpe:/-:-:bar:2.0:-:~-~-~-~-~-:-\n",
 		},
 		{
 			[]string{"-cpe_part=1", "-cpe_product=2", "-cpe_product=4"},
 			NewIntSet(1, 2, 3),
 			true,
 			"a\tb\tc\n",
-			"cpe:/a:-:-:-:-:-:-\n",
+			"cpe:/a:-:-:-:-:~-~-~-~-~-:-\n",
 		},
 		{
 			[]string{"-cpe_part=1", "-cpe_product=2", "-cpe_version=3"},
 			NewIntSet(1, 2, 3),
 			true,
 			"a\tbash\t4.4\n",
-			"cpe:/a:-:bash:4.4:-:-:-\n",
+			"cpe:/a:-:bash:4.4:-:~-~-~-~-~-:-\n",
 		},
 		{
 			[]string{"-cpe_part=1", "-cpe_product=2", "-cpe_version=3"},
@@ -149,3 +152,45 @@ func TestProcessor(t *testing.T) {
 	}
 
 }
+
+func TestProcessorDictionary(t *testing.T) {
+	dict, err := cpedict.Decode(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<cpe-list>
+  <cpe-item name="cpe:/a:gnu:bash:4.4">
+    <title xml:lang="en-US">GNU Bash 4.4</title>
+  </cpe-item>
+  <cpe-item name="cpe:/a:openbsd:openssh:9.0">
+    <title xml:lang="en-US">OpenBSD OpenSSH 9.0</title>
+  </cpe-item>
+</cpe-list>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	acm := &AttributeColumnMap{}
+	acm.AddFlags(fs)
+	if err := fs.Parse([]string{"-cpe_vendor=1", "-cpe_product=2", "-cpe_version=3"}); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Processor{
+		InputComma:  rune(','),
+		OutputComma: rune(','),
+		CPEToLower:  true,
+		Dictionary:  cpedict.NewIndex(dict),
+	}
+
+	var stdout bytes.Buffer
+	in := "GNU,Bash,5.1\n,open ssh,9.3\nfoo,bar,1.0\n"
+	if err := p.Process(acm, strings.NewReader(in), &stdout); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "GNU,Bash,5.1,cpe:/:gnu:bash:5.1,cpe:/a:gnu:bash:5.1\n" +
+		",open ssh,9.3,cpe:/::open_ssh:9.3,cpe:/a:openbsd:openssh:9.3\n" +
+		"foo,bar,1.0,cpe:/:foo:bar:1.0,\n"
+	if out := stdout.String(); out != want {
+		t.Fatalf("unexpected output:\nwant: %q\nhave: %q\n", want, out)
+	}
+}
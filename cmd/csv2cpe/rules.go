@@ -0,0 +1,329 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// Record holds raw attribute values of a CSV line keyed by attribute name,
+// as used in -cpe_{field} flags, e.g. "vendor" or "targetsw".
+type Record map[string]string
+
+// Attributes returns WFN attributes with values of the record.
+// Attributes missing from the record are set to NA if na is true, to ANY otherwise.
+func (rec Record) Attributes(na bool) (*wfn.Attributes, error) {
+	var attr *wfn.Attributes
+	if na {
+		attr = wfn.NewAttributesWithNA()
+	} else {
+		attr = wfn.NewAttributesWithAny()
+	}
+
+	m := map[string]*string{
+		"part":      &attr.Part,
+		"vendor":    &attr.Vendor,
+		"product":   &attr.Product,
+		"version":   &attr.Version,
+		"update":    &attr.Update,
+		"edition":   &attr.Edition,
+		"swedition": &attr.SWEdition,
+		"targetsw":  &attr.TargetSW,
+		"targethw":  &attr.TargetHW,
+		"other":     &attr.Other,
+		"language":  &attr.Language,
+	}
+
+	for field, value := range rec {
+		v, ok := m[field]
+		if !ok {
+			continue
+		}
+		var err error
+		if *v, err = wfn.WFNize(value); err != nil {
+			return nil, err
+		}
+	}
+
+	return attr, nil
+}
+
+// Rule normalizes values of a record before they're bound to a CPE.
+type Rule interface {
+	Apply(rec Record)
+}
+
+// RuleFunc is an adapter to allow the use of ordinary functions as rules.
+type RuleFunc func(rec Record)
+
+// Apply is a part of the Rule interface.
+func (f RuleFunc) Apply(rec Record) {
+	f(rec)
+}
+
+// Rules apply all rules in order.
+type Rules []Rule
+
+// Apply is a part of the Rule interface.
+func (rs Rules) Apply(rec Record) {
+	for _, r := range rs {
+		r.Apply(rec)
+	}
+}
+
+// fieldRule applies fn to the value of the field, or to all values if field is "*".
+func fieldRule(field string, fn func(string) string) Rule {
+	return RuleFunc(func(rec Record) {
+		for f, v := range rec {
+			if field == "*" || field == f {
+				rec[f] = fn(v)
+			}
+		}
+	})
+}
+
+// Lower converts the value of the field to lower case.
+func Lower(field string) Rule {
+	return fieldRule(field, strings.ToLower)
+}
+
+// Trim removes leading and trailing spaces and collapses inner spaces in the value of the field.
+func Trim(field string) Rule {
+	return fieldRule(field, func(v string) string {
+		return strings.Join(strings.Fields(v), " ")
+	})
+}
+
+// StripSuffix removes any of the given words from the end of the value of the field,
+// e.g. company suffixes: "Oracle Corp." -> "Oracle". Words are compared case insensitively.
+func StripSuffix(field string, suffixes ...string) Rule {
+	return fieldRule(field, func(v string) string {
+		for stripped := true; stripped; {
+			stripped = false
+			words := strings.Fields(v)
+			if len(words) < 2 {
+				break
+			}
+			last := strings.TrimRight(words[len(words)-1], ",")
+			for _, s := range suffixes {
+				if strings.EqualFold(last, s) {
+					v = strings.TrimRight(strings.Join(words[:len(words)-1], " "), ",")
+					stripped = true
+					break
+				}
+			}
+		}
+		return v
+	})
+}
+
+// Alias replaces the value of the field with to if it's equal to from, compared case insensitively.
+func Alias(field, from, to string) Rule {
+	return Aliases(field, map[string]string{from: to})
+}
+
+// Aliases replaces the value of the field with the one it's mapped to, keys are compared case insensitively.
+func Aliases(field string, aliases map[string]string) Rule {
+	lower := make(map[string]string, len(aliases))
+	for from, to := range aliases {
+		lower[strings.ToLower(from)] = to
+	}
+	return fieldRule(field, func(v string) string {
+		if to, ok := lower[strings.ToLower(v)]; ok {
+			return to
+		}
+		return v
+	})
+}
+
+// Replace replaces matches of the regular expression in the value of the field with repl,
+// which can refer to submatches, see regexp.Regexp.ReplaceAllString.
+func Replace(field string, re *regexp.Regexp, repl string) Rule {
+	return fieldRule(field, func(v string) string {
+		return re.ReplaceAllString(v, repl)
+	})
+}
+
+// InferVendor sets the vendor to the first word of the product if the vendor is unknown,
+// e.g. product "Microsoft Office" becomes vendor "Microsoft" and product "Office".
+func InferVendor() Rule {
+	return RuleFunc(func(rec Record) {
+		if rec["vendor"] != "" {
+			return
+		}
+		if words := strings.SplitN(rec["product"], " ", 2); len(words) == 2 {
+			rec["vendor"], rec["product"] = words[0], words[1]
+		}
+	})
+}
+
+// StripVendor removes the vendor from the beginning of the product,
+// e.g. vendor "Adobe" and product "Adobe Reader" become "Adobe" and "Reader".
+func StripVendor() Rule {
+	return RuleFunc(func(rec Record) {
+		vendor, product := rec["vendor"], rec["product"]
+		if vendor == "" || len(product) <= len(vendor)+1 || product[len(vendor)] != ' ' {
+			return
+		}
+		if strings.EqualFold(product[:len(vendor)], vendor) {
+			rec["product"] = product[len(vendor)+1:]
+		}
+	})
+}
+
+// InferVersion moves the version from the end of the product if the version is unknown,
+// e.g. product "Office 2016" becomes "Office" with version "2016".
+func InferVersion() Rule {
+	return RuleFunc(func(rec Record) {
+		if rec["version"] != "" {
+			return
+		}
+		product := rec["product"]
+		i := strings.LastIndexByte(product, ' ')
+		if i == -1 {
+			return
+		}
+		version := strings.TrimPrefix(strings.ToLower(product[i+1:]), "v")
+		if version == "" || !unicode.IsDigit(rune(version[0])) {
+			return
+		}
+		rec["product"], rec["version"] = product[:i], version
+	})
+}
+
+// companySuffixes are stripped from vendor names by default rules
+var companySuffixes = []string{
+	"inc", "inc.", "incorporated", "corp", "corp.", "corporation", "co", "co.", "company",
+	"ltd", "ltd.", "limited", "llc", "l.l.c.", "gmbh", "ag", "sa", "s.a.", "plc", "oy", "ab", "bv", "b.v.",
+}
+
+// vendorAliases map common vendor names to NVD vendors
+var vendorAliases = map[string]string{
+	"apache software foundation":      "apache",
+	"the apache software foundation":  "apache",
+	"mozilla foundation":              "mozilla",
+	"python software foundation":      "python",
+	"the document foundation":         "libreoffice",
+	"free software foundation":        "gnu",
+	"hewlett-packard":                 "hp",
+	"hewlett packard":                 "hp",
+	"hewlett packard enterprise":      "hpe",
+	"international business machines": "ibm",
+	"micro$oft":                       "microsoft",
+	"oracle america":                  "oracle",
+	"vmware by broadcom":              "vmware",
+}
+
+// DefaultRules returns rules used to infer CPE attributes from raw inventory data:
+// vendors are inferred from products if unknown, company suffixes are stripped from vendors,
+// vendors are stripped from products, common vendor aliases are resolved, versions are inferred
+// from products if unknown, and everything is converted to lower case.
+func DefaultRules() Rules {
+	return Rules{
+		Trim("*"),
+		InferVendor(),
+		StripSuffix("vendor", companySuffixes...),
+		StripVendor(),
+		Aliases("vendor", vendorAliases),
+		InferVersion(),
+		Lower("*"),
+	}
+}
+
+// LoadRules reads rules from CSV, one rule per line: action,field[,arguments...]
+// field is an attribute name as used in -cpe_{field} flags or * for all attributes.
+// Supported actions:
+//
+//	lower,field
+//	trim,field
+//	strip_suffix,field,suffix1[,suffix2...]
+//	alias,field,from,to
+//	replace,field,regexp,replacement
+//
+// Lines starting with # are ignored.
+func LoadRules(r io.Reader) (Rules, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var rules Rules
+	for line := 1; ; line++ {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			return rules, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("can't read rules: %v", err)
+		}
+		rule, err := newRule(rec)
+		if err != nil {
+			return nil, fmt.Errorf("bad rule %q: %v", strings.Join(rec, ","), err)
+		}
+		rules = append(rules, rule)
+	}
+}
+
+// LoadRulesFile reads rules from the file, see LoadRules for the format.
+func LoadRulesFile(path string) (Rules, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open rules file: %v", err)
+	}
+	defer f.Close()
+	return LoadRules(f)
+}
+
+func newRule(rec []string) (Rule, error) {
+	if len(rec) < 2 {
+		return nil, fmt.Errorf("rule must have action and field")
+	}
+	action, field, args := rec[0], rec[1], rec[2:]
+	switch action {
+	case "lower":
+		return Lower(field), nil
+	case "trim":
+		return Trim(field), nil
+	case "strip_suffix":
+		if len(args) == 0 {
+			return nil, fmt.Errorf("no suffixes given")
+		}
+		return StripSuffix(field, args...), nil
+	case "alias":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("alias needs from and to values")
+		}
+		return Alias(field, args[0], args[1]), nil
+	case "replace":
+		if len(args) != 2 {
+			return nil, fmt.Errorf("replace needs regexp and replacement")
+		}
+		re, err := regexp.Compile(args[0])
+		if err != nil {
+			return nil, fmt.Errorf("can't compile regexp: %v", err)
+		}
+		return Replace(field, re, args[1]), nil
+	default:
+		return nil, fmt.Errorf("unknown action %q", action)
+	}
+}
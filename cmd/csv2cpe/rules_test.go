@@ -0,0 +1,150 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cpedict"
+)
+
+func TestDefaultRules(t *testing.T) {
+	cases := []struct {
+		in, out Record
+	}{
+		{
+			Record{"vendor": "Microsoft Corporation", "product": "Microsoft  Office 2016"},
+			Record{"vendor": "microsoft", "product": "office", "version": "2016"},
+		},
+		{
+			Record{"vendor": "Oracle Corp.", "product": "MySQL", "version": "8.0"},
+			Record{"vendor": "oracle", "product": "mysql", "version": "8.0"},
+		},
+		{
+			Record{"product": "Mozilla Firefox v115.0"},
+			Record{"vendor": "mozilla", "product": "firefox", "version": "115.0"},
+		},
+		{
+			Record{"vendor": "The Apache Software Foundation", "product": "Tomcat"},
+			Record{"vendor": "apache", "product": "tomcat"},
+		},
+		{
+			Record{"vendor": "Micro$oft", "product": "Internet Explorer", "version": "11"},
+			Record{"vendor": "microsoft", "product": "internet explorer", "version": "11"},
+		},
+		{
+			Record{"vendor": "Acme, Inc.", "product": "Rocket Skates"},
+			Record{"vendor": "acme", "product": "rocket skates"},
+		},
+	}
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			DefaultRules().Apply(c.in)
+			if !reflect.DeepEqual(c.in, c.out) {
+				t.Fatalf("unexpected record:\nwant: %v\nhave: %v", c.out, c.in)
+			}
+		})
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	rules, err := LoadRules(strings.NewReader(`# normalization rules
+trim,*
+strip_suffix,vendor,gmbh,ag
+alias,vendor,"SAP SE",sap
+replace,product,"(?i)^(.+) for windows$",$1
+lower,product
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := Record{"vendor": " SAP SE ", "product": "GUI for Windows"}
+	rules.Apply(rec)
+	if want := (Record{"vendor": "sap", "product": "gui"}); !reflect.DeepEqual(rec, want) {
+		t.Fatalf("unexpected record:\nwant: %v\nhave: %v", want, rec)
+	}
+
+	for _, bad := range []string{"lower", "unknown,vendor", "alias,vendor,foo", "replace,product,(,x", "strip_suffix,vendor"} {
+		if _, err := LoadRules(strings.NewReader(bad)); err == nil {
+			t.Fatalf("loading rule %q was expected to fail", bad)
+		}
+	}
+}
+
+func TestProcessorInfer(t *testing.T) {
+	dict, err := cpedict.Decode(strings.NewReader(`<?xml version="1.0" encoding="UTF-8"?>
+<cpe-list>
+  <cpe-item name="cpe:/a:microsoft:office:2016">
+    <title xml:lang="en-US">Microsoft Office 2016</title>
+  </cpe-item>
+  <cpe-item name="cpe:/a:mozilla:firefox:115.0">
+    <title xml:lang="en-US">Mozilla Firefox 115.0</title>
+  </cpe-item>
+</cpe-list>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	acm := &AttributeColumnMap{}
+	acm.AddFlags(fs)
+	if err := fs.Parse([]string{"-cpe_vendor=1", "-cpe_product=2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	in := "Microsoft Corporation,Microsoft Office 2016\n,Mozila Firefox 115.0\nAcme Inc.,Rocket Skates 2\n"
+	cases := []struct {
+		validate bool
+		out      string
+	}{
+		{
+			false,
+			"Microsoft Corporation,Microsoft Office 2016,cpe:/:microsoft:office:2016\n" +
+				",Mozila Firefox 115.0,cpe:/:mozila:firefox:115.0\n" +
+				"Acme Inc.,Rocket Skates 2,cpe:/:acme:rocket_skates:2\n",
+		},
+		{
+			true,
+			"Microsoft Corporation,Microsoft Office 2016,cpe:/a:microsoft:office:2016\n" +
+				",Mozila Firefox 115.0,cpe:/a:mozilla:firefox:115.0\n" +
+				"Acme Inc.,Rocket Skates 2,\n",
+		},
+	}
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			p := &Processor{
+				InputComma:  rune(','),
+				OutputComma: rune(','),
+				Rules:       DefaultRules(),
+				Validate:    c.validate,
+			}
+			if c.validate {
+				p.Dictionary = cpedict.NewIndex(dict)
+			}
+			var stdout bytes.Buffer
+			if err := p.Process(acm, strings.NewReader(in), &stdout); err != nil {
+				t.Fatal(err)
+			}
+			if out := stdout.String(); out != c.out {
+				t.Fatalf("unexpected output:\nwant: %q\nhave: %q\n", c.out, out)
+			}
+		})
+	}
+}
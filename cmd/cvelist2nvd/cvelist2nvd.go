@@ -0,0 +1,76 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/cvelist"
+	"github.com/facebookincubator/nvdtools/shutdown"
+)
+
+var (
+	sync = flag.Bool("git", false, "clone the cvelistV5 git repository into the given directory, or pull it if it's already there, before converting")
+	repo = flag.String("repo", cvelist.CVEListURL, "url of the cvelistV5 git repository used with -git")
+)
+
+func init() {
+	logging.AddFlags()
+}
+
+func main() {
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Println("Usage: cvelist2nvd [-git] <cvelist-dir>")
+		fmt.Println("Example:")
+		fmt.Println("git clone --depth 1 https://github.com/CVEProject/cvelistV5")
+		fmt.Println("cvelist2nvd cvelistV5/cves/2023 > cvelist-2023.cve.json")
+		fmt.Println("or, to clone or update the repository directly:")
+		fmt.Println("cvelist2nvd -git cvelistV5 > cvelist.cve.json")
+		os.Exit(1)
+	}
+
+	dir := flag.Arg(0)
+	if *sync {
+		ctx, stop := shutdown.Context(context.Background())
+		err := cvelist.Sync(ctx, dir, *repo)
+		stop()
+		if err != nil {
+			logging.Errorf("%v", err)
+			os.Exit(1)
+		}
+	}
+
+	feed, err := cvelist.Convert(dir)
+	if err != nil {
+		logging.Errorf("%v", err)
+		os.Exit(1)
+	}
+	if err := logging.Err(); err != nil {
+		logging.Errorf("%v", err)
+		os.Exit(1)
+	}
+
+	err = json.NewEncoder(os.Stdout).Encode(feed)
+	if err != nil {
+		logging.Errorf("%v", err)
+		os.Exit(1)
+	}
+}
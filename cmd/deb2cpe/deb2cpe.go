@@ -0,0 +1,297 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/deb"
+	"github.com/facebookincubator/nvdtools/shutdown"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+var progname = path.Base(os.Args[0])
+
+// custom type to be recognized by flag.Parse()
+type fieldsToSkip map[int]struct{}
+
+// remove elements from  fields slice as per config
+// NB!: this modifies the underlying array of fields slice
+func (fs fieldsToSkip) skipFields(fields []string) []string {
+	j := 0
+	for i := 0; i < len(fields); i++ {
+		if _, ok := fs[i]; ok {
+			continue
+		}
+		fields[j] = fields[i]
+		j++
+	}
+	return fields[:j]
+}
+
+// part of flag.Value interface implementation
+func (fs fieldsToSkip) String() string {
+	fss := make([]string, 0, len(fs))
+	for i := range fs {
+		fss = append(fss, fmt.Sprintf("%d", i+1))
+	}
+	return strings.Join(fss, ",")
+}
+
+// part of flag.Value interface implementation
+func (fs *fieldsToSkip) Set(val string) error {
+	if *fs == nil {
+		*fs = fieldsToSkip{}
+	}
+	for _, v := range strings.Split(val, ",") {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return err
+		}
+		if n < 1 {
+			return fmt.Errorf("illegal field index %d", n)
+		}
+		(*fs)[n-1] = struct{}{}
+	}
+	return nil
+}
+
+type config struct {
+	pkgField     int
+	nameField    int
+	versionField int
+	archField    int
+	cpeField     int
+	inFieldSep   string
+	outFieldSep  string
+	skip         fieldsToSkip
+	defaultNA    bool
+	dpkgList     bool
+	targetHW     bool
+	targetSW     string
+}
+
+func (c *config) addFlags() {
+	flag.IntVar(&c.pkgField, "pkg", 0, "position of the field in DSV input that contains the package in name_version[_arch] format, as in .deb file names (starts at 1)")
+	flag.IntVar(&c.nameField, "name", 0, "position of the field in DSV input that contains the package name (starts at 1)")
+	flag.IntVar(&c.versionField, "version", 0, "position of the field in DSV input that contains the package version (starts at 1)")
+	flag.IntVar(&c.archField, "arch", 0, "optional position of the field in DSV input that contains the package architecture (starts at 1)")
+	flag.IntVar(&c.cpeField, "cpe", 0, "position of the field in the output to put generated CPE at (starts at 1)")
+	flag.StringVar(&c.inFieldSep, "d", "\t", "input column delimiter")
+	flag.StringVar(&c.outFieldSep, "o", "\t", "output column delimiter")
+	flag.Var(&c.skip, "e", "optional comma-separated list of input fields that should be dropped from output (starts with 1) "+
+		"package is extracted before dropping fields, CPE is added after that")
+	flag.BoolVar(&c.defaultNA, "na", false, "if set, unknown CPE attributes are set to N/A, otherwise to ANY")
+	flag.BoolVar(&c.dpkgList, "l", false, "if set, input is the output of dpkg -l; only installed packages are processed "+
+		"and input fields are name, version and architecture")
+	flag.BoolVar(&c.targetHW, "target_hw", false, "if set, debian architecture is mapped to CPE target_hw names, e.g. amd64 to x64")
+	flag.StringVar(&c.targetSW, "target_sw", "", "optional CPE target_sw of all packages, e.g. debian_bookworm or ubuntu_jammy, as used by distribution feeds")
+}
+
+func sayErr(status int, msg string, args ...interface{}) {
+	var statusStr string
+	if status != 0 {
+		statusStr = "fatal"
+	} else {
+		statusStr = "error"
+	}
+	fmt.Fprintf(os.Stderr, "%s: %s: %s\n", progname, statusStr, fmt.Sprintf(msg, args...))
+	if status != 0 {
+		os.Exit(status)
+	}
+}
+
+func init() {
+	var indent bytes.Buffer
+	for i := 0; i < len(progname); i++ {
+		indent.WriteByte(' ')
+	}
+	flag.Usage = func() {
+		usageStr := "%[1]s takes a delimiter-separated input with fields containing debian package name, version and\n" +
+			"%[2]s architecture (e.g. output of dpkg-query) or the output of dpkg -l and produces delimiter-separated\n" +
+			"%[2]s output consisting of the same fields plus CPE name of the package.\n" +
+			"usage: %[1]s [flags]\n" +
+			"flags:\n"
+		fmt.Fprintf(os.Stderr, usageStr, progname, indent.String())
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+}
+
+// field returns the field at the given position (starts at 1) or empty string if position is 0
+func field(fields []string, pos int) (string, error) {
+	if pos == 0 {
+		return "", nil
+	}
+	if pos > len(fields) {
+		return "", fmt.Errorf("not enough fields (%d)", len(fields))
+	}
+	return fields[pos-1], nil
+}
+
+// pkgFromFields returns the package found in fields as per config
+func pkgFromFields(fields []string, cfg config) (*deb.Package, error) {
+	if cfg.pkgField != 0 {
+		s, err := field(fields, cfg.pkgField)
+		if err != nil {
+			return nil, err
+		}
+		return deb.Parse(s)
+	}
+
+	name, err := field(fields, cfg.nameField)
+	if err != nil {
+		return nil, err
+	}
+	version, err := field(fields, cfg.versionField)
+	if err != nil {
+		return nil, err
+	}
+	arch, err := field(fields, cfg.archField)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := deb.ParseVersion(version)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse version %q: %v", version, err)
+	}
+	if arch == "all" {
+		arch = ""
+	}
+	// multi-arch packages have architecture qualified names, e.g. libssl1.1:amd64
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		name = name[:i]
+	}
+	return &deb.Package{Name: strings.ToLower(name), Version: *v, Arch: arch}, nil
+}
+
+// NB!: modifies underlying array of fields slice
+func processRecord(fields []string, cfg config) ([]string, error) {
+	pkg, err := pkgFromFields(fields, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get package from fields: %v", err)
+	}
+	var attr *wfn.Attributes
+	if cfg.defaultNA {
+		attr = wfn.NewAttributesWithNA()
+	} else {
+		attr = wfn.NewAttributesWithAny()
+	}
+	attr.Vendor = wfn.Any
+	if cfg.targetHW {
+		pkg.Arch = deb.ArchToTargetHW(pkg.Arch)
+	}
+	if err := deb.PackageToWFN(attr, pkg); err != nil {
+		return nil, fmt.Errorf("couldn't create CPE of package %q: %v", pkg.Name, err)
+	}
+	if cfg.targetSW != "" {
+		if attr.TargetSW, err = wfn.WFNize(cfg.targetSW); err != nil {
+			return nil, fmt.Errorf("couldn't wfnize target software %q: %v", cfg.targetSW, err)
+		}
+	}
+	cpe := attr.BindToURI()
+	fields = cfg.skip.skipFields(fields)
+	if cfg.cpeField > len(fields) {
+		// if cfg.cpeField > len(fields)+1 we ignore that silently and just add CPE as the last field
+		return append(fields, cpe), nil
+	}
+	outFields := make([]string, 0, len(fields)+1)
+	outFields = append(outFields, fields[:cfg.cpeField-1]...)
+	outFields = append(outFields, cpe)
+	outFields = append(outFields, fields[cfg.cpeField-1:]...)
+	return outFields, nil
+}
+
+// records returns a function which returns input records one by one, it returns io.EOF at the end of input
+func records(in io.Reader, cfg config) (func() ([]string, error), error) {
+	if !cfg.dpkgList {
+		r := csv.NewReader(in)
+		r.Comma = rune(cfg.inFieldSep[0])
+		r.FieldsPerRecord = -1
+		return r.Read, nil
+	}
+	pkgs, err := deb.ParseDpkgList(in)
+	if err != nil {
+		return nil, err
+	}
+	return func() ([]string, error) {
+		if len(pkgs) == 0 {
+			return nil, io.EOF
+		}
+		pkg := pkgs[0]
+		pkgs = pkgs[1:]
+		return []string{pkg.Name, pkg.Version.String(), pkg.Arch}, nil
+	}, nil
+}
+
+// deb2cpe adds CPE names to the records from in and writes them to out;
+// when the context is done, it stops after writing the records processed so far
+func deb2cpe(ctx context.Context, in io.Reader, out io.Writer, cfg config) {
+	if cfg.dpkgList {
+		cfg.pkgField, cfg.nameField, cfg.versionField, cfg.archField = 0, 1, 2, 3
+	}
+	read, err := records(in, cfg)
+	if err != nil {
+		sayErr(-1, "read error: %v", err)
+	}
+	w := csv.NewWriter(out)
+	w.Comma = rune(cfg.outFieldSep[0])
+	for ctx.Err() == nil {
+		inRec, err := read()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			sayErr(-1, "read error: %v", err)
+		}
+		outRec, err := processRecord(inRec, cfg)
+		if err != nil {
+			sayErr(0, "couldn't process record %v: %v", inRec, err)
+			continue
+		}
+		if err = w.Write(outRec); err != nil {
+			sayErr(-1, "write error: %v", err)
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		sayErr(-1, "write error: %v", err)
+	}
+	if err := ctx.Err(); err != nil {
+		sayErr(-1, "%v", err)
+	}
+}
+
+func main() {
+	var cfg config
+	cfg.addFlags()
+	flag.Parse()
+	if cfg.cpeField == 0 || (!cfg.dpkgList && cfg.pkgField == 0 && (cfg.nameField == 0 || cfg.versionField == 0)) {
+		flag.Usage()
+	}
+	ctx, stop := shutdown.Context(context.Background())
+	defer stop()
+	deb2cpe(ctx, os.Stdin, os.Stdout, cfg)
+}
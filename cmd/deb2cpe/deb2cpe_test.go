@@ -0,0 +1,106 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestProcessRecord(t *testing.T) {
+	cases := []struct {
+		in, out string
+		cfg     config
+		fail    bool
+	}{
+		{
+			in:   "",
+			cfg:  config{pkgField: 1, cpeField: 1},
+			fail: true,
+		},
+		{
+			in:  "0,openssl_1.1.1f-1ubuntu2_amd64.deb",
+			out: "0;openssl_1.1.1f-1ubuntu2_amd64.deb;cpe:/a::openssl:1.1.1f:1ubuntu2:~~~~amd64~",
+			cfg: config{pkgField: 2, cpeField: 3},
+		},
+		{
+			in:  "openssl,1.1.1f-1ubuntu2,amd64",
+			out: "cpe:/a::openssl:1.1.1f:1ubuntu2:~~~ubuntu_focal~x64~;openssl;1.1.1f-1ubuntu2",
+			cfg: config{
+				nameField: 1, versionField: 2, archField: 3, cpeField: 1,
+				skip: fieldsToSkip{2: {}}, targetHW: true, targetSW: "ubuntu_focal",
+			},
+		},
+		{
+			in:  "libc6:amd64,2.28-10,all",
+			out: "libc6:amd64;2.28-10;all;cpe:/a::libc6:2.28:10:~-~-~-~~-:-",
+			cfg: config{
+				nameField: 1, versionField: 2, archField: 3, cpeField: 4,
+				defaultNA: true,
+			},
+		},
+		{
+			in:   "foo,a:1.0",
+			cfg:  config{nameField: 1, versionField: 2, cpeField: 1},
+			fail: true,
+		},
+		{
+			in:   "foo",
+			cfg:  config{nameField: 1, versionField: 2, cpeField: 1},
+			fail: true,
+		},
+	}
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			c.cfg.inFieldSep, c.cfg.outFieldSep = ",", ";"
+			record, err := processRecord(strings.Split(c.in, c.cfg.inFieldSep), c.cfg)
+			if err != nil {
+				if !c.fail {
+					t.Fatalf("line %q was expected to succeed, but failed: %v", c.in, err)
+				}
+				return
+			}
+			if c.fail {
+				t.Fatalf("line %q was expected to fail, but succeeded", c.in)
+			}
+			if out := strings.Join(record, c.cfg.outFieldSep); out != c.out {
+				t.Fatalf("line %q:\nhave: %q\nwant: %q", c.in, out, c.out)
+			}
+		})
+	}
+}
+
+func TestDeb2CPEDpkgList(t *testing.T) {
+	in := "||/ Name  Version  Architecture Description\n" +
+		"+++-=====-========-============-===========\n" +
+		"ii  bash  5.0-6ubuntu1.2  amd64  GNU Bourne Again SHell\n" +
+		"rc  vim   2:8.1.2269-1ubuntu5  amd64  Vi IMproved\n"
+	cfg := config{
+		cpeField:    4,
+		inFieldSep:  "\t",
+		outFieldSep: "\t",
+		dpkgList:    true,
+		targetSW:    "ubuntu_focal",
+	}
+	var out bytes.Buffer
+	deb2cpe(context.Background(), strings.NewReader(in), &out, cfg)
+	want := "bash\t5.0-6ubuntu1.2\tamd64\tcpe:/a::bash:5.0:6ubuntu1.2:~~~ubuntu_focal~amd64~\n"
+	if out.String() != want {
+		t.Fatalf("have: %q\nwant: %q", out.String(), want)
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+
+	"github.com/facebookincubator/nvdtools/providers/exploitdb/api"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+)
+
+func main() {
+	flag.StringVar(&api.Metasploit, "metasploit", api.Metasploit, "Metasploit modules metadata to download along with Exploit-DB; empty value disables it")
+	runner.Main("exploitdb")
+}
@@ -0,0 +1,232 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+)
+
+// snapshot is the part of a vulnerability which is compared
+type snapshot struct {
+	lastModified string
+	descriptions map[string]string
+	cwes         []string
+	references   []string
+	scores       map[string]score
+	cpes         []string
+}
+
+// score is a CVSS score of a vulnerability
+type score struct {
+	Vector string  `json:"vector"`
+	Score  float64 `json:"score"`
+}
+
+func newSnapshot(item *schema.NVDCVEFeedJSON10DefCVEItem) *snapshot {
+	v := nvd.ToVuln(item)
+	s := &snapshot{
+		lastModified: item.LastModifiedDate,
+		descriptions: v.Descriptions(),
+		cwes:         v.CWEs(),
+		scores:       make(map[string]score),
+	}
+	for _, ref := range v.References() {
+		if ref.URL != "" {
+			s.references = append(s.references, ref.URL)
+		}
+	}
+	if vector := v.CVSSv2Vector(); vector != "" {
+		s.scores["v2"] = score{vector, v.CVSSv2BaseScore()}
+	}
+	if vector := v.CVSSv3Vector(); vector != "" {
+		s.scores["v3"] = score{vector, v.CVSSv3BaseScore()}
+	}
+	if vector := v.CVSSv4Vector(); vector != "" {
+		s.scores["v4"] = score{vector, v.CVSSv4BaseScore()}
+	}
+	if item.Configurations != nil {
+		s.cpes = cpeMatches(nil, item.Configurations.Nodes)
+	}
+	return s
+}
+
+// cpeMatches appends CPE matches of the nodes and their children, formatted with their version ranges
+func cpeMatches(matches []string, nodes []*schema.NVDCVEFeedJSON10DefNode) []string {
+	for _, node := range nodes {
+		for _, m := range node.CPEMatch {
+			matches = append(matches, formatMatch(m))
+		}
+		matches = cpeMatches(matches, node.Children)
+	}
+	return matches
+}
+
+// formatMatch formats the CPE match as CPE name followed by its version range, e.g.
+// cpe:2.3:a:vendor:product:*:*:*:*:*:*:*:* >=1.0 <1.2
+func formatMatch(m *schema.NVDCVEFeedJSON10DefCPEMatch) string {
+	parts := []string{m.Cpe23Uri}
+	if m.Cpe23Uri == "" {
+		parts[0] = m.Cpe22Uri
+	}
+	for _, r := range []struct{ op, version string }{
+		{">=", m.VersionStartIncluding},
+		{">", m.VersionStartExcluding},
+		{"<=", m.VersionEndIncluding},
+		{"<", m.VersionEndExcluding},
+	} {
+		if r.version != "" {
+			parts = append(parts, r.op+r.version)
+		}
+	}
+	if !m.Vulnerable {
+		parts = append(parts, "(not vulnerable)")
+	}
+	return strings.Join(parts, " ")
+}
+
+// report lists the differences between two snapshots of feeds
+type report struct {
+	Added    []string `json:"added"`
+	Removed  []string `json:"removed"`
+	Modified []change `json:"modified"`
+}
+
+// change lists what changed in a vulnerability
+type change struct {
+	ID                 string        `json:"id"`
+	LastModified       *valueChange  `json:"last_modified,omitempty"`
+	DescriptionChanged bool          `json:"description_changed,omitempty"`
+	CVSS               []scoreChange `json:"cvss,omitempty"`
+	CWEs               *setChange    `json:"cwes,omitempty"`
+	References         *setChange    `json:"references,omitempty"`
+	CPEs               *setChange    `json:"cpes,omitempty"`
+}
+
+type valueChange struct {
+	Old string `json:"old"`
+	New string `json:"new"`
+}
+
+// scoreChange is a changed CVSS score, old or new is nil if the score was added or removed
+type scoreChange struct {
+	Version string `json:"version"`
+	Old     *score `json:"old"`
+	New     *score `json:"new"`
+}
+
+type setChange struct {
+	Added   []string `json:"added,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// diff compares the snapshots of feeds, IDs in the report are sorted
+func diff(before, after map[string]*snapshot) *report {
+	r := &report{Added: []string{}, Removed: []string{}, Modified: []change{}}
+	for id, n := range after {
+		o, ok := before[id]
+		if !ok {
+			r.Added = append(r.Added, id)
+			continue
+		}
+		if c, changed := compare(id, o, n); changed {
+			r.Modified = append(r.Modified, c)
+		}
+	}
+	for id := range before {
+		if _, ok := after[id]; !ok {
+			r.Removed = append(r.Removed, id)
+		}
+	}
+	sort.Strings(r.Added)
+	sort.Strings(r.Removed)
+	sort.Slice(r.Modified, func(i, j int) bool {
+		return r.Modified[i].ID < r.Modified[j].ID
+	})
+	return r
+}
+
+// compare returns the changes of the vulnerability and whether there are any
+func compare(id string, before, after *snapshot) (change, bool) {
+	c := change{ID: id}
+	changed := false
+	if before.lastModified != after.lastModified {
+		c.LastModified = &valueChange{before.lastModified, after.lastModified}
+		changed = true
+	}
+	if !reflect.DeepEqual(before.descriptions, after.descriptions) {
+		c.DescriptionChanged = true
+		changed = true
+	}
+	for _, version := range []string{"v2", "v3", "v4"} {
+		o, hadOld := before.scores[version]
+		n, hasNew := after.scores[version]
+		if hadOld == hasNew && o == n {
+			continue
+		}
+		sc := scoreChange{Version: version}
+		if hadOld {
+			sc.Old = &o
+		}
+		if hasNew {
+			sc.New = &n
+		}
+		c.CVSS = append(c.CVSS, sc)
+		changed = true
+	}
+	if c.CWEs = compareSets(before.cwes, after.cwes); c.CWEs != nil {
+		changed = true
+	}
+	if c.References = compareSets(before.references, after.references); c.References != nil {
+		changed = true
+	}
+	if c.CPEs = compareSets(before.cpes, after.cpes); c.CPEs != nil {
+		changed = true
+	}
+	return c, changed
+}
+
+// compareSets returns values which were added and removed, or nil if the sets are equal
+func compareSets(before, after []string) *setChange {
+	inOld := make(map[string]bool, len(before))
+	for _, v := range before {
+		inOld[v] = true
+	}
+	inNew := make(map[string]bool, len(after))
+	for _, v := range after {
+		inNew[v] = true
+	}
+	var sc setChange
+	for v := range inNew {
+		if !inOld[v] {
+			sc.Added = append(sc.Added, v)
+		}
+	}
+	for v := range inOld {
+		if !inNew[v] {
+			sc.Removed = append(sc.Removed, v)
+		}
+	}
+	if len(sc.Added) == 0 && len(sc.Removed) == 0 {
+		return nil
+	}
+	sort.Strings(sc.Added)
+	sort.Strings(sc.Removed)
+	return &sc
+}
@@ -0,0 +1,103 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+)
+
+func testItem(id, modified string, v3 float64, matches ...*schema.NVDCVEFeedJSON10DefCPEMatch) *schema.NVDCVEFeedJSON10DefCVEItem {
+	return &schema.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &schema.CVEJSON40{
+			CVEDataMeta: &schema.CVEJSON40CVEDataMeta{ID: id},
+			Description: &schema.CVEJSON40Description{
+				DescriptionData: []*schema.CVEJSON40LangString{{Lang: "en", Value: id}},
+			},
+		},
+		Configurations: &schema.NVDCVEFeedJSON10DefConfigurations{
+			Nodes: []*schema.NVDCVEFeedJSON10DefNode{{
+				Operator: "AND",
+				Children: []*schema.NVDCVEFeedJSON10DefNode{{Operator: "OR", CPEMatch: matches}},
+			}},
+		},
+		Impact: &schema.NVDCVEFeedJSON10DefImpact{
+			BaseMetricV3: &schema.NVDCVEFeedJSON10DefImpactBaseMetricV3{
+				CVSSV3: &schema.CVSSV30{VectorString: "CVSS:3.1/AV:N", BaseScore: v3},
+			},
+		},
+		LastModifiedDate: modified,
+	}
+}
+
+func snapshots(items ...*schema.NVDCVEFeedJSON10DefCVEItem) map[string]*snapshot {
+	m := make(map[string]*snapshot)
+	for _, item := range items {
+		m[item.CVE.CVEDataMeta.ID] = newSnapshot(item)
+	}
+	return m
+}
+
+func TestDiff(t *testing.T) {
+	cpe := "cpe:2.3:a:vendor:product:*:*:*:*:*:*:*:*"
+	before := snapshots(
+		testItem("CVE-2020-0001", "2020-01-01T00:00Z", 5.0, &schema.NVDCVEFeedJSON10DefCPEMatch{Cpe23Uri: cpe, VersionEndExcluding: "1.2", Vulnerable: true}),
+		testItem("CVE-2020-0002", "2020-01-01T00:00Z", 5.0),
+		testItem("CVE-2020-0003", "2020-01-01T00:00Z", 5.0),
+	)
+	after := snapshots(
+		testItem("CVE-2020-0001", "2020-02-01T00:00Z", 9.8, &schema.NVDCVEFeedJSON10DefCPEMatch{Cpe23Uri: cpe, VersionStartIncluding: "1.0", VersionEndExcluding: "1.3", Vulnerable: true}),
+		testItem("CVE-2020-0002", "2020-01-01T00:00Z", 5.0),
+		testItem("CVE-2020-0004", "2020-02-01T00:00Z", 5.0),
+	)
+
+	expected := &report{
+		Added:   []string{"CVE-2020-0004"},
+		Removed: []string{"CVE-2020-0003"},
+		Modified: []change{{
+			ID:           "CVE-2020-0001",
+			LastModified: &valueChange{"2020-01-01T00:00Z", "2020-02-01T00:00Z"},
+			CVSS: []scoreChange{{
+				Version: "v3",
+				Old:     &score{"CVSS:3.1/AV:N", 5.0},
+				New:     &score{"CVSS:3.1/AV:N", 9.8},
+			}},
+			CPEs: &setChange{
+				Added:   []string{cpe + " >=1.0 <1.3"},
+				Removed: []string{cpe + " <1.2"},
+			},
+		}},
+	}
+	if got := diff(before, after); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected\n%+v\ngot\n%+v", expected, got)
+	}
+
+	if got := diff(after, after); len(got.Added)+len(got.Removed)+len(got.Modified) != 0 {
+		t.Fatalf("expected no differences, got %+v", got)
+	}
+}
+
+func TestFormatMatch(t *testing.T) {
+	m := &schema.NVDCVEFeedJSON10DefCPEMatch{
+		Cpe23Uri:              "cpe:2.3:o:vendor:os:*:*:*:*:*:*:*:*",
+		VersionStartExcluding: "1",
+		VersionEndIncluding:   "2",
+	}
+	if got, want := formatMatch(m), "cpe:2.3:o:vendor:os:*:*:*:*:*:*:*:* >1 <=2 (not vulnerable)"; got != want {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
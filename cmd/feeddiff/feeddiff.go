@@ -0,0 +1,148 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// feeddiff compares two snapshots of NVD JSON feeds and reports added, removed and modified vulnerabilities
+// as JSON, including changed CVSS scores and CPE ranges, e.g. for reviewing feed updates or alerting on rescored vulnerabilities
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/shutdown"
+)
+
+type config struct {
+	Output   string
+	ExitCode bool
+}
+
+func (cfg *config) addFlags() {
+	flag.StringVar(&cfg.Output, "o", "", "write the report to this file instead of stdout")
+	flag.BoolVar(&cfg.ExitCode, "exit_code", false, "exit with status 1 if the snapshots differ, like diff")
+}
+
+func init() {
+	logging.AddFlags()
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] old_feed new_feed > report.json\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "feeds can be glob patterns to compare snapshots split into several files, e.g. 'old/nvdcve-1.1-*.json.gz'\n")
+		fmt.Fprintf(os.Stderr, "flags:\n")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+}
+
+func main() {
+	var cfg config
+	cfg.addFlags()
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+	}
+
+	ctx, stop := shutdown.Context(context.Background())
+	defer stop()
+	changes, err := run(ctx, &cfg, flag.Arg(0), flag.Arg(1))
+	if err != nil {
+		logging.Errorf("%v", err)
+		os.Exit(1)
+	}
+	if cfg.ExitCode && changes > 0 {
+		os.Exit(1)
+	}
+}
+
+// run writes the report of differences between the snapshots and returns the number of changes,
+// the output file is only created if the report is written
+func run(ctx context.Context, cfg *config, beforePattern, afterPattern string) (int, error) {
+	before, err := loadSnapshot(ctx, beforePattern)
+	if err != nil {
+		return 0, err
+	}
+	after, err := loadSnapshot(ctx, afterPattern)
+	if err != nil {
+		return 0, err
+	}
+	if err := logging.Err(); err != nil {
+		return 0, err
+	}
+
+	r := diff(before, after)
+	logging.Infof("%d added, %d removed, %d modified", len(r.Added), len(r.Removed), len(r.Modified))
+
+	var out io.Writer = os.Stdout
+	if cfg.Output != "" {
+		f, err := shutdown.CreateFile(cfg.Output)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Remove()
+		out = f
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(r); err != nil {
+		return 0, fmt.Errorf("can't write report: %v", err)
+	}
+	if f, ok := out.(*shutdown.File); ok {
+		if err := f.Commit(); err != nil {
+			return 0, fmt.Errorf("can't write report: %v", err)
+		}
+	}
+	return len(r.Added) + len(r.Removed) + len(r.Modified), nil
+}
+
+// loadSnapshot loads vulnerabilities from feed files matching the pattern
+func loadSnapshot(ctx context.Context, pattern string) (map[string]*snapshot, error) {
+	paths, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("can't expand %q: %v", pattern, err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no feeds match %q", pattern)
+	}
+	snapshots := make(map[string]*snapshot)
+	for _, p := range paths {
+		if err := loadFeed(ctx, snapshots, p); err != nil {
+			return nil, fmt.Errorf("can't load feed %q: %v", p, err)
+		}
+	}
+	logging.Infof("%s: %d vulnerabilities", pattern, len(snapshots))
+	return snapshots, nil
+}
+
+func loadFeed(ctx context.Context, snapshots map[string]*snapshot, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return cvefeed.DecodeJSONItemsContext(ctx, f, func(item *schema.NVDCVEFeedJSON10DefCVEItem) {
+		if item.CVE == nil || item.CVE.CVEDataMeta == nil || item.CVE.CVEDataMeta.ID == "" {
+			logging.Recordf("%s: skipping vulnerability without ID", path)
+			return
+		}
+		snapshots[item.CVE.CVEDataMeta.ID] = newSnapshot(item)
+	})
+}
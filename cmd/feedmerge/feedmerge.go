@@ -0,0 +1,149 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// feedmerge merges NVD JSON feeds from several sources (e.g. NVD and vendor feeds converted by *2nvd tools)
+// into a single feed, merging vulnerabilities with the same CVE ID field by field
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/shutdown"
+)
+
+type config struct {
+	Output string
+	Policy policy
+}
+
+func (cfg *config) addFlags() {
+	var names []string
+	for field := range fields {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+	cfg.Policy = defaultPolicy()
+	flag.StringVar(&cfg.Output, "o", "", "write the merged feed to this file instead of stdout")
+	flag.Var(cfg.Policy, "policy", fmt.Sprintf("field=rule, can be repeated; rule is either union or a comma separated list of preferred sources. Fields: %s", strings.Join(names, ", ")))
+}
+
+func init() {
+	logging.AddFlags()
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] [source=]feed.json.gz... > merged.json\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "sources are named after feed files unless named explicitly, several feeds can have the same name;\n")
+		fmt.Fprintf(os.Stderr, "fields which aren't in the policy are taken from the first source which has them, in order of arguments\n")
+		fmt.Fprintf(os.Stderr, "flags:\n")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+}
+
+func main() {
+	var cfg config
+	cfg.addFlags()
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+	}
+
+	ctx, stop := shutdown.Context(context.Background())
+	defer stop()
+	if err := run(ctx, &cfg, flag.Args()); err != nil {
+		logging.Errorf("%v", err)
+		os.Exit(1)
+	}
+}
+
+// run merges the feeds, the output file is only created if all of them are merged
+func run(ctx context.Context, cfg *config, args []string) error {
+	m := newMerger(cfg.Policy)
+	for _, arg := range args {
+		source, feed := parseSource(arg)
+		n, err := addFeed(ctx, m, source, feed)
+		if err != nil {
+			return fmt.Errorf("can't load feed %q: %v", feed, err)
+		}
+		logging.Infof("%s: %d vulnerabilities from %s", source, n, feed)
+	}
+	if err := m.validate(); err != nil {
+		return err
+	}
+	if err := logging.Err(); err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if cfg.Output != "" {
+		f, err := shutdown.CreateFile(cfg.Output)
+		if err != nil {
+			return err
+		}
+		defer f.Remove()
+		out = f
+	}
+	feed := schema.NVDCVEFeedJSON10{CVEItems: m.merged()}
+	if err := json.NewEncoder(out).Encode(feed); err != nil {
+		return fmt.Errorf("can't write merged feed: %v", err)
+	}
+	if f, ok := out.(*shutdown.File); ok {
+		if err := f.Commit(); err != nil {
+			return fmt.Errorf("can't write merged feed: %v", err)
+		}
+	}
+	logging.Infof("merged %d vulnerabilities", len(feed.CVEItems))
+	return nil
+}
+
+// parseSource splits source=path argument, sources without a name are named after the file
+func parseSource(arg string) (source, feed string) {
+	if i := strings.Index(arg, "="); i > 0 {
+		return arg[:i], arg[i+1:]
+	}
+	source = filepath.Base(arg)
+	for _, ext := range []string{".gz", ".json"} {
+		source = strings.TrimSuffix(source, ext)
+	}
+	return source, arg
+}
+
+// addFeed adds vulnerabilities from the feed file and returns their number
+func addFeed(ctx context.Context, m *merger, source, path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	var n int
+	err = cvefeed.DecodeJSONItemsContext(ctx, f, func(item *schema.NVDCVEFeedJSON10DefCVEItem) {
+		if err := m.add(source, item); err != nil {
+			logging.Recordf("%s: skipping item: %v", path, err)
+			return
+		}
+		n++
+	})
+	return n, err
+}
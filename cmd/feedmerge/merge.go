@@ -0,0 +1,362 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+)
+
+// fields lists the fields which can be merged and whether they can be merged with the union rule
+var fields = map[string]bool{
+	"description":    false,
+	"cwe":            true,
+	"references":     true,
+	"cvss":           false,
+	"configurations": true,
+	"kev":            false,
+	"epss":           false,
+	"exploits":       true,
+}
+
+// rule decides which sources a field is taken from:
+// either all of them (union) or the first one which has it, in order of preference
+type rule struct {
+	union bool
+	// prefer lists sources which take precedence, sources not listed follow in order of arguments
+	prefer []string
+}
+
+func (r rule) String() string {
+	if r.union {
+		return "union"
+	}
+	return strings.Join(r.prefer, ",")
+}
+
+// policy maps fields to their rules, fields which aren't in the policy are taken from sources in order of arguments
+type policy map[string]rule
+
+// defaultPolicy unions lists which are additive, everything else comes from the first source
+func defaultPolicy() policy {
+	return policy{
+		"cwe":        {union: true},
+		"references": {union: true},
+		"exploits":   {union: true},
+	}
+}
+
+// String is a part of flag.Value interface
+func (p policy) String() string {
+	var rules []string
+	for field, r := range p {
+		rules = append(rules, field+"="+r.String())
+	}
+	sort.Strings(rules)
+	return strings.Join(rules, " ")
+}
+
+// Set is a part of flag.Value interface, it parses a field=rule pair
+func (p policy) Set(val string) error {
+	i := strings.Index(val, "=")
+	if i < 0 {
+		return fmt.Errorf("policy %q isn't in field=rule format", val)
+	}
+	field, spec := val[:i], val[i+1:]
+	canUnion, ok := fields[field]
+	if !ok {
+		return fmt.Errorf("unknown field %q", field)
+	}
+	if spec == "union" {
+		if !canUnion {
+			return fmt.Errorf("field %q can't be merged with union", field)
+		}
+		p[field] = rule{union: true}
+		return nil
+	}
+	var r rule
+	for _, source := range strings.Split(spec, ",") {
+		if source = strings.TrimSpace(source); source != "" {
+			r.prefer = append(r.prefer, source)
+		}
+	}
+	if len(r.prefer) == 0 {
+		return fmt.Errorf("no sources in policy %q", val)
+	}
+	p[field] = r
+	return nil
+}
+
+// sourcedItem is a vulnerability read from a source
+type sourcedItem struct {
+	source string
+	item   *schema.NVDCVEFeedJSON10DefCVEItem
+}
+
+// merger collects vulnerabilities from all sources and merges the ones with the same CVE ID
+type merger struct {
+	policy  policy
+	sources []string
+	items   map[string][]sourcedItem
+	ids     []string
+}
+
+func newMerger(p policy) *merger {
+	return &merger{policy: p, items: make(map[string][]sourcedItem)}
+}
+
+// add adds the vulnerability read from the source
+// if the source has the vulnerability already, the last modified one is kept
+func (m *merger) add(source string, item *schema.NVDCVEFeedJSON10DefCVEItem) error {
+	id := itemID(item)
+	if id == "" {
+		return fmt.Errorf("vulnerability without ID")
+	}
+	known := false
+	for _, s := range m.sources {
+		if s == source {
+			known = true
+			break
+		}
+	}
+	if !known {
+		m.sources = append(m.sources, source)
+	}
+
+	items, ok := m.items[id]
+	if !ok {
+		m.ids = append(m.ids, id)
+	}
+	for i, si := range items {
+		if si.source == source {
+			if item.LastModifiedDate >= si.item.LastModifiedDate {
+				items[i].item = item
+			}
+			return nil
+		}
+	}
+	m.items[id] = append(items, sourcedItem{source, item})
+	return nil
+}
+
+// validate checks that the policy only refers to sources which were read
+func (m *merger) validate() error {
+	for field, r := range m.policy {
+		for _, source := range r.prefer {
+			if m.rank(source) < 0 {
+				return fmt.Errorf("policy for %s refers to unknown source %q", field, source)
+			}
+		}
+	}
+	return nil
+}
+
+// merged returns the merged vulnerabilities, in order in which they were first read
+func (m *merger) merged() []*schema.NVDCVEFeedJSON10DefCVEItem {
+	merged := make([]*schema.NVDCVEFeedJSON10DefCVEItem, len(m.ids))
+	for i, id := range m.ids {
+		merged[i] = m.merge(m.items[id])
+	}
+	return merged
+}
+
+// rank returns the position of the source in order of arguments, or -1 if it's unknown
+func (m *merger) rank(source string) int {
+	for i, s := range m.sources {
+		if s == source {
+			return i
+		}
+	}
+	return -1
+}
+
+// order sorts the items by the rule of the field: preferred sources first, then the rest in order of arguments
+func (m *merger) order(field string, items []sourcedItem) []sourcedItem {
+	prefer := m.policy[field].prefer
+	priority := func(source string) int {
+		for i, s := range prefer {
+			if s == source {
+				return i
+			}
+		}
+		return len(prefer) + m.rank(source)
+	}
+	ordered := append([]sourcedItem(nil), items...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return priority(ordered[i].source) < priority(ordered[j].source)
+	})
+	return ordered
+}
+
+func (m *merger) union(field string) bool {
+	return m.policy[field].union
+}
+
+// merge merges items of the same vulnerability field by field
+func (m *merger) merge(items []sourcedItem) *schema.NVDCVEFeedJSON10DefCVEItem {
+	items = m.order("", items)
+	first := items[0].item
+	cve := *first.CVE
+	merged := &schema.NVDCVEFeedJSON10DefCVEItem{
+		CVE:              &cve,
+		PublishedDate:    first.PublishedDate,
+		LastModifiedDate: first.LastModifiedDate,
+	}
+
+	// the vulnerability was published when the first source published it and modified when the last one modified it
+	for _, si := range items[1:] {
+		if d := si.item.PublishedDate; d != "" && (merged.PublishedDate == "" || d < merged.PublishedDate) {
+			merged.PublishedDate = d
+		}
+		if d := si.item.LastModifiedDate; d > merged.LastModifiedDate {
+			merged.LastModifiedDate = d
+		}
+	}
+
+	cve.Description = nil
+	for _, si := range m.order("description", items) {
+		if d := si.item.CVE.Description; d != nil && len(d.DescriptionData) > 0 {
+			cve.Description = d
+			break
+		}
+	}
+
+	cve.Problemtype = nil
+	var cwes []*schema.CVEJSON40LangString
+	seenCWE := make(map[string]bool)
+	for _, si := range m.order("cwe", items) {
+		if !m.union("cwe") && len(cwes) > 0 {
+			break
+		}
+		if si.item.CVE.Problemtype == nil {
+			continue
+		}
+		for _, pt := range si.item.CVE.Problemtype.ProblemtypeData {
+			for _, d := range pt.Description {
+				if !seenCWE[d.Value] {
+					seenCWE[d.Value] = true
+					cwes = append(cwes, d)
+				}
+			}
+		}
+	}
+	if len(cwes) > 0 {
+		cve.Problemtype = &schema.CVEJSON40Problemtype{
+			ProblemtypeData: []*schema.CVEJSON40ProblemtypeProblemtypeData{{Description: cwes}},
+		}
+	}
+
+	cve.References = nil
+	var refs []*schema.CVEJSON40Reference
+	seenRef := make(map[string]bool)
+	for _, si := range m.order("references", items) {
+		if !m.union("references") && len(refs) > 0 {
+			break
+		}
+		if si.item.CVE.References == nil {
+			continue
+		}
+		for _, ref := range si.item.CVE.References.ReferenceData {
+			if !seenRef[ref.URL] {
+				seenRef[ref.URL] = true
+				refs = append(refs, ref)
+			}
+		}
+	}
+	if len(refs) > 0 {
+		cve.References = &schema.CVEJSON40References{ReferenceData: refs}
+	}
+
+	// scores of each CVSS version are taken separately, so a source which only has v3 doesn't hide v2 from the others
+	var impact schema.NVDCVEFeedJSON10DefImpact
+	for _, si := range m.order("cvss", items) {
+		if si.item.Impact == nil {
+			continue
+		}
+		if impact.BaseMetricV2 == nil {
+			impact.BaseMetricV2 = si.item.Impact.BaseMetricV2
+		}
+		if impact.BaseMetricV3 == nil {
+			impact.BaseMetricV3 = si.item.Impact.BaseMetricV3
+		}
+		if impact.BaseMetricV4 == nil {
+			impact.BaseMetricV4 = si.item.Impact.BaseMetricV4
+		}
+	}
+	if impact.BaseMetricV2 != nil || impact.BaseMetricV3 != nil || impact.BaseMetricV4 != nil {
+		merged.Impact = &impact
+	}
+
+	// top level nodes are alternatives, so the union is vulnerable if any of the sources says it is
+	var nodes []*schema.NVDCVEFeedJSON10DefNode
+	seenNode := make(map[string]bool)
+	for _, si := range m.order("configurations", items) {
+		if !m.union("configurations") && len(nodes) > 0 {
+			break
+		}
+		if si.item.Configurations == nil {
+			continue
+		}
+		for _, node := range si.item.Configurations.Nodes {
+			key, _ := json.Marshal(node)
+			if !seenNode[string(key)] {
+				seenNode[string(key)] = true
+				nodes = append(nodes, node)
+			}
+		}
+	}
+	if len(nodes) > 0 {
+		merged.Configurations = &schema.NVDCVEFeedJSON10DefConfigurations{CVEDataVersion: "4.0", Nodes: nodes}
+	}
+
+	for _, si := range m.order("kev", items) {
+		if si.item.KnownExploited != nil {
+			merged.KnownExploited = si.item.KnownExploited
+			break
+		}
+	}
+	for _, si := range m.order("epss", items) {
+		if si.item.EPSS != nil {
+			merged.EPSS = si.item.EPSS
+			break
+		}
+	}
+
+	seenExploit := make(map[string]bool)
+	for _, si := range m.order("exploits", items) {
+		if !m.union("exploits") && len(merged.Exploits) > 0 {
+			break
+		}
+		for _, e := range si.item.Exploits {
+			if key := e.Source + "/" + e.ID; !seenExploit[key] {
+				seenExploit[key] = true
+				merged.Exploits = append(merged.Exploits, e)
+			}
+		}
+	}
+
+	return merged
+}
+
+func itemID(item *schema.NVDCVEFeedJSON10DefCVEItem) string {
+	if item.CVE == nil || item.CVE.CVEDataMeta == nil {
+		return ""
+	}
+	return item.CVE.CVEDataMeta.ID
+}
@@ -0,0 +1,196 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+)
+
+type testItem struct {
+	id, published, modified, description string
+	cwes, refs, cpes                     []string
+	v2, v3                               string
+}
+
+func (ti testItem) item() *schema.NVDCVEFeedJSON10DefCVEItem {
+	item := &schema.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &schema.CVEJSON40{
+			CVEDataMeta: &schema.CVEJSON40CVEDataMeta{ID: ti.id},
+			Description: &schema.CVEJSON40Description{},
+			Problemtype: &schema.CVEJSON40Problemtype{
+				ProblemtypeData: []*schema.CVEJSON40ProblemtypeProblemtypeData{{}},
+			},
+			References: &schema.CVEJSON40References{},
+		},
+		Configurations:   &schema.NVDCVEFeedJSON10DefConfigurations{},
+		Impact:           &schema.NVDCVEFeedJSON10DefImpact{},
+		PublishedDate:    ti.published,
+		LastModifiedDate: ti.modified,
+	}
+	if ti.description != "" {
+		item.CVE.Description.DescriptionData = []*schema.CVEJSON40LangString{{Lang: "en", Value: ti.description}}
+	}
+	for _, cwe := range ti.cwes {
+		pt := item.CVE.Problemtype.ProblemtypeData[0]
+		pt.Description = append(pt.Description, &schema.CVEJSON40LangString{Lang: "en", Value: cwe})
+	}
+	for _, ref := range ti.refs {
+		item.CVE.References.ReferenceData = append(item.CVE.References.ReferenceData, &schema.CVEJSON40Reference{URL: ref})
+	}
+	for _, cpe := range ti.cpes {
+		item.Configurations.Nodes = append(item.Configurations.Nodes, &schema.NVDCVEFeedJSON10DefNode{
+			Operator: "OR",
+			CPEMatch: []*schema.NVDCVEFeedJSON10DefCPEMatch{{Cpe23Uri: cpe, Vulnerable: true}},
+		})
+	}
+	if ti.v2 != "" {
+		item.Impact.BaseMetricV2 = &schema.NVDCVEFeedJSON10DefImpactBaseMetricV2{CVSSV2: &schema.CVSSV20{VectorString: ti.v2}}
+	}
+	if ti.v3 != "" {
+		item.Impact.BaseMetricV3 = &schema.NVDCVEFeedJSON10DefImpactBaseMetricV3{CVSSV3: &schema.CVSSV30{VectorString: ti.v3}}
+	}
+	return item
+}
+
+// summary flattens the merged item back to testItem
+func summary(item *schema.NVDCVEFeedJSON10DefCVEItem) testItem {
+	ti := testItem{
+		id:        itemID(item),
+		published: item.PublishedDate,
+		modified:  item.LastModifiedDate,
+	}
+	if d := item.CVE.Description; d != nil {
+		ti.description = d.DescriptionData[0].Value
+	}
+	if pt := item.CVE.Problemtype; pt != nil {
+		for _, d := range pt.ProblemtypeData[0].Description {
+			ti.cwes = append(ti.cwes, d.Value)
+		}
+	}
+	if refs := item.CVE.References; refs != nil {
+		for _, ref := range refs.ReferenceData {
+			ti.refs = append(ti.refs, ref.URL)
+		}
+	}
+	if c := item.Configurations; c != nil {
+		for _, node := range c.Nodes {
+			ti.cpes = append(ti.cpes, node.CPEMatch[0].Cpe23Uri)
+		}
+	}
+	if impact := item.Impact; impact != nil {
+		if impact.BaseMetricV2 != nil {
+			ti.v2 = impact.BaseMetricV2.CVSSV2.VectorString
+		}
+		if impact.BaseMetricV3 != nil {
+			ti.v3 = impact.BaseMetricV3.CVSSV3.VectorString
+		}
+	}
+	return ti
+}
+
+func TestMerge(t *testing.T) {
+	nvd := testItem{
+		id: "CVE-2020-0001", published: "2020-01-02T00:00Z", modified: "2020-03-01T00:00Z",
+		description: "nvd", cwes: []string{"CWE-79"}, refs: []string{"https://a", "https://b"},
+		cpes: []string{"cpe:2.3:a:x:y:1:*:*:*:*:*:*:*"}, v2: "AV:N/AC:L/Au:N/C:P/I:P/A:P", v3: "nvd-v3",
+	}
+	vendor := testItem{
+		id: "CVE-2020-0001", published: "2020-01-01T00:00Z", modified: "2020-02-01T00:00Z",
+		cwes: []string{"CWE-79", "CWE-80"}, refs: []string{"https://b", "https://c"},
+		cpes: []string{"cpe:2.3:o:vendor:os:1:*:*:*:*:*:*:*"}, v3: "vendor-v3",
+	}
+	other := testItem{id: "CVE-2020-0002", description: "only vendor"}
+
+	cases := []struct {
+		policies []string
+		expected testItem
+	}{
+		{
+			// defaults
+			expected: testItem{
+				id: "CVE-2020-0001", published: "2020-01-01T00:00Z", modified: "2020-03-01T00:00Z",
+				description: "nvd", cwes: []string{"CWE-79", "CWE-80"}, refs: []string{"https://a", "https://b", "https://c"},
+				cpes: nvd.cpes, v2: nvd.v2, v3: "nvd-v3",
+			},
+		},
+		{
+			policies: []string{"cvss=vendor", "references=nvd", "configurations=union", "cwe=vendor"},
+			expected: testItem{
+				id: "CVE-2020-0001", published: "2020-01-01T00:00Z", modified: "2020-03-01T00:00Z",
+				description: "nvd", cwes: vendor.cwes, refs: nvd.refs,
+				cpes: append(append([]string{}, nvd.cpes...), vendor.cpes...), v2: nvd.v2, v3: "vendor-v3",
+			},
+		},
+	}
+
+	for _, c := range cases {
+		p := defaultPolicy()
+		for _, s := range c.policies {
+			if err := p.Set(s); err != nil {
+				t.Fatal(err)
+			}
+		}
+		m := newMerger(p)
+		m.add("nvd", nvd.item())
+		m.add("vendor", vendor.item())
+		m.add("vendor", other.item())
+		if err := m.validate(); err != nil {
+			t.Fatal(err)
+		}
+		merged := m.merged()
+		if len(merged) != 2 {
+			t.Fatalf("expected 2 vulnerabilities, got %d", len(merged))
+		}
+		if got := summary(merged[0]); !reflect.DeepEqual(got, c.expected) {
+			t.Errorf("policies %v:\nexpected %+v\ngot      %+v", c.policies, c.expected, got)
+		}
+		if got := summary(merged[1]); got.description != "only vendor" {
+			t.Errorf("unexpected vulnerability %+v", got)
+		}
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	for _, s := range []string{"cvss", "unknown=nvd", "cvss=union", "cvss=", "description=,"} {
+		if err := defaultPolicy().Set(s); err == nil {
+			t.Errorf("expected an error for policy %q", s)
+		}
+	}
+
+	p := defaultPolicy()
+	if err := p.Set("cvss=redhat,nvd"); err != nil {
+		t.Fatal(err)
+	}
+	m := newMerger(p)
+	m.add("nvd", testItem{id: "CVE-2020-0001"}.item())
+	if err := m.validate(); err == nil {
+		t.Fatal("expected an error for policy with unknown source")
+	}
+}
+
+func TestParseSource(t *testing.T) {
+	cases := map[string][2]string{
+		"nvd=feeds/nvdcve-1.1-2020.json.gz": {"nvd", "feeds/nvdcve-1.1-2020.json.gz"},
+		"feeds/redhat.json":                 {"redhat", "feeds/redhat.json"},
+	}
+	for arg, expected := range cases {
+		if source, feed := parseSource(arg); source != expected[0] || feed != expected[1] {
+			t.Errorf("%q: expected %v, got %s %s", arg, expected, source, feed)
+		}
+	}
+}
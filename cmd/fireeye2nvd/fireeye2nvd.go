@@ -15,60 +15,10 @@
 package main
 
 import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"log"
-	"os"
-
-	"github.com/facebookincubator/nvdtools/providers/fireeye/api"
-	"github.com/facebookincubator/nvdtools/providers/fireeye/schema"
-	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	_ "github.com/facebookincubator/nvdtools/providers/fireeye/api"
 	"github.com/facebookincubator/nvdtools/providers/lib/runner"
 )
 
-func Read(r io.Reader, c chan runner.Convertible) error {
-	var vulns map[string]*schema.Vulnerability
-	if err := json.NewDecoder(r).Decode(&vulns); err != nil {
-		return fmt.Errorf("can't decode into vulns: %v", err)
-	}
-
-	for _, vuln := range vulns {
-		c <- vuln
-	}
-
-	return nil
-}
-
-func FetchSince(ctx context.Context, c client.Client, baseURL string, since int64) (<-chan runner.Convertible, error) {
-	publicKey := os.Getenv("FIREEYE_PUBLIC_KEY")
-	if publicKey == "" {
-		return nil, fmt.Errorf("please set FIREEYE_PUBLIC_KEY in environment")
-	}
-	privateKey := os.Getenv("FIREEYE_PRIVATE_KEY")
-	if privateKey == "" {
-		return nil, fmt.Errorf("please set FIREEYE_PRIVATE_KEY in environment")
-	}
-
-	client := api.NewClient(c, baseURL, publicKey, privateKey)
-	return client.FetchAllVulnerabilities(ctx, since)
-}
-
 func main() {
-	r := runner.Runner{
-		Config: runner.Config{
-			BaseURL: "https://api.isightpartners.com",
-			ClientConfig: client.Config{
-				UserAgent: "fireeye2nvd",
-			},
-		},
-		FetchSince: FetchSince,
-		Read:       Read,
-	}
-
-	if err := r.Run(); err != nil {
-		log.Println(err)
-		os.Exit(1)
-	}
+	runner.Main("fireeye")
 }
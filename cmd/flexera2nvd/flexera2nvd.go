@@ -15,56 +15,10 @@
 package main
 
 import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"log"
-	"os"
-
-	"github.com/facebookincubator/nvdtools/providers/flexera/api"
-	"github.com/facebookincubator/nvdtools/providers/flexera/schema"
-	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	_ "github.com/facebookincubator/nvdtools/providers/flexera/api"
 	"github.com/facebookincubator/nvdtools/providers/lib/runner"
 )
 
-func Read(r io.Reader, c chan runner.Convertible) error {
-	var vulns map[string]*schema.Advisory
-	if err := json.NewDecoder(r).Decode(&vulns); err != nil {
-		return fmt.Errorf("can't decode into vulns: %v", err)
-	}
-
-	for _, vuln := range vulns {
-		c <- vuln
-	}
-
-	return nil
-}
-
-func FetchSince(ctx context.Context, c client.Client, baseURL string, since int64) (<-chan runner.Convertible, error) {
-	apiKey := os.Getenv("FLEXERA_TOKEN")
-	if apiKey == "" {
-		return nil, fmt.Errorf("please set FLEXERA_TOKEN in environment")
-	}
-
-	client := api.NewClient(c, baseURL, apiKey)
-	return client.FetchAllVulnerabilities(ctx, since)
-}
-
 func main() {
-	r := runner.Runner{
-		Config: runner.Config{
-			BaseURL: "https://api.app.secunia.com",
-			ClientConfig: client.Config{
-				UserAgent: "flexera2nvd",
-			},
-		},
-		FetchSince: FetchSince,
-		Read:       Read,
-	}
-
-	if err := r.Run(); err != nil {
-		log.Println(err)
-		os.Exit(1)
-	}
+	runner.Main("flexera")
 }
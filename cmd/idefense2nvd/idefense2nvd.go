@@ -15,55 +15,42 @@
 package main
 
 import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"log"
+	"flag"
 	"os"
 
-	"github.com/facebookincubator/nvdtools/providers/idefense/api"
+	"github.com/facebookincubator/nvdtools/logging"
+	_ "github.com/facebookincubator/nvdtools/providers/idefense/api"
 	"github.com/facebookincubator/nvdtools/providers/idefense/schema"
-	"github.com/facebookincubator/nvdtools/providers/lib/client"
 	"github.com/facebookincubator/nvdtools/providers/lib/runner"
 )
 
-func Read(r io.Reader, c chan runner.Convertible) error {
-	var vulns map[string]*schema.Vulnerability
-	if err := json.NewDecoder(r).Decode(&vulns); err != nil {
-		return fmt.Errorf("can't decode into vulns: %v", err)
-	}
-
-	for _, vuln := range vulns {
-		c <- vuln
-	}
+// mappingFile is a flag.Value which loads the conversion mapping when it's set
+type mappingFile string
 
-	return nil
+// String is a part of flag.Value interface implementation.
+func (f *mappingFile) String() string {
+	return string(*f)
 }
 
-func FetchSince(ctx context.Context, c client.Client, baseURL string, since int64) (<-chan runner.Convertible, error) {
-	apiKey := os.Getenv("IDEFENSE_TOKEN")
-	if apiKey == "" {
-		return nil, fmt.Errorf("please set IDEFENSE_TOKEN in environment")
+// Set is a part of flag.Value interface implementation.
+func (f *mappingFile) Set(path string) error {
+	m, err := schema.LoadMapping(path)
+	if err != nil {
+		return err
 	}
-	client := api.NewClient(c, baseURL, apiKey)
-	return client.FetchAllVulnerabilities(ctx, since)
+	schema.SetMapping(m)
+	*f = mappingFile(path)
+	return nil
 }
 
 func main() {
-	r := runner.Runner{
-		Config: runner.Config{
-			BaseURL: "https://api.intelgraph.idefense.com",
-			ClientConfig: client.Config{
-				UserAgent: "idefense2nvd",
-			},
-		},
-		FetchSince: FetchSince,
-		Read:       Read,
-	}
-
-	if err := r.Run(); err != nil {
-		log.Println(err)
-		os.Exit(1)
+	var mapping mappingFile
+	if path := os.Getenv("IDEFENSE_MAPPING"); path != "" {
+		if err := mapping.Set(path); err != nil {
+			logging.Errorf("can't load mapping: %v", err)
+			os.Exit(1)
+		}
 	}
+	flag.Var(&mapping, "mapping", "JSON file which controls which fields are converted into description, references and CVSS\nenv: IDEFENSE_MAPPING")
+	runner.Main("idefense")
 }
@@ -0,0 +1,195 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// image2cve matches packages installed in a container image against vulnerability feeds
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/oci"
+	"github.com/facebookincubator/nvdtools/shutdown"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+type config struct {
+	Platform           string
+	OutFieldSeparator  string
+	OutRecordSeparator string
+	RequireVersion     bool
+	List               bool
+	Username           string
+	PlainHTTP          bool
+}
+
+func (cfg *config) addFlags() {
+	flag.StringVar(&cfg.Platform, "platform", oci.DefaultPlatform, "platform of the image to select from multi-platform images, e.g. linux/arm64")
+	flag.StringVar(&cfg.OutFieldSeparator, "o", "\t", "output columns delimiter")
+	flag.StringVar(&cfg.OutRecordSeparator, "o2", ",", "inner output columns delimiter: separates elements of lists in output CSV columns")
+	flag.BoolVar(&cfg.RequireVersion, "require_version", false, "ignore matches of packages without version")
+	flag.BoolVar(&cfg.List, "list", false, "list installed packages and their CPEs instead of matching them, no feeds are needed")
+	flag.StringVar(&cfg.Username, "username", "", "username to pull the image from the registry with, the password is read from REGISTRY_PASSWORD environment variable")
+	flag.BoolVar(&cfg.PlainHTTP, "plain_http", false, "pull the image from the registry over http instead of https")
+}
+
+// openImage opens the image saved in the file or the directory, or pulls it from the registry if there's no such file
+func (cfg *config) openImage(ctx context.Context, name string) (*oci.Image, error) {
+	if _, err := os.Stat(name); err == nil {
+		return oci.Open(name, cfg.Platform)
+	}
+	reg := oci.Registry{
+		Username:  cfg.Username,
+		Password:  os.Getenv("REGISTRY_PASSWORD"),
+		PlainHTTP: cfg.PlainHTTP,
+	}
+	return reg.Pull(ctx, name, cfg.Platform)
+}
+
+// list writes a record for every package: type, name, version and CPE
+func list(pkgs []*oci.Package, w io.Writer, cfg config) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = rune(cfg.OutFieldSeparator[0])
+	for _, pkg := range pkgs {
+		attrs, err := pkg.Attributes()
+		if err != nil {
+			logging.Errorf("skipping package %s: %v", pkg.Name, err)
+			continue
+		}
+		if err := cw.Write([]string{pkg.Type, pkg.Name, version(pkg), attrs.BindToURI()}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// process writes a record for every vulnerability matching some of the packages:
+// package type, name and version, CVE, CVSS base score (v3 if available, v2 otherwise) and matched CPEs
+func process(pkgs []*oci.Package, cache *cvefeed.Cache, w io.Writer, cfg config) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = rune(cfg.OutFieldSeparator[0])
+	for _, pkg := range pkgs {
+		attrs, err := pkg.Attributes()
+		if err != nil {
+			logging.Errorf("skipping package %s: %v", pkg.Name, err)
+			continue
+		}
+		for _, matches := range cache.Get([]*wfn.Attributes{attrs}) {
+			cpes := make([]string, 0, len(matches.CPEs))
+			for _, attr := range matches.CPEs {
+				if attr != nil {
+					cpes = append(cpes, attr.BindToURI())
+				}
+			}
+			cvss := matches.CVE.CVSSv3BaseScore()
+			if cvss == 0 {
+				cvss = matches.CVE.CVSSv2BaseScore()
+			}
+			rec := []string{
+				pkg.Type,
+				pkg.Name,
+				version(pkg),
+				matches.CVE.ID(),
+				fmt.Sprintf("%.1f", cvss),
+				strings.Join(cpes, cfg.OutRecordSeparator),
+			}
+			if err := cw.Write(rec); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// version returns the version of the package as its package manager shows it
+func version(pkg *oci.Package) string {
+	if pkg.Release == "" {
+		return pkg.Version
+	}
+	return pkg.Version + "-" + pkg.Release
+}
+
+func init() {
+	logging.AddFlags()
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] image nvd_feed.json.gz...\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "image is a docker save or OCI layout archive or directory, or a reference of the image in a registry\n")
+		fmt.Fprintf(os.Stderr, "output: package type, name, version, CVE, CVSS score, matching CPEs\n")
+		fmt.Fprintf(os.Stderr, "flags:\n")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+}
+
+func main() {
+	var cfg config
+	cfg.addFlags()
+	flag.Parse()
+	if flag.NArg() == 0 || (flag.NArg() == 1 && !cfg.List) {
+		flag.Usage()
+	}
+
+	ctx, stop := shutdown.Context(context.Background())
+	defer stop()
+
+	img, err := cfg.openImage(ctx, flag.Arg(0))
+	if err != nil {
+		logging.Errorf("failed to open image: %v", err)
+		os.Exit(1)
+	}
+	defer img.Close()
+	pkgs, err := img.Packages()
+	if err != nil {
+		logging.Errorf("failed to read packages: %v", err)
+		os.Exit(1)
+	}
+	if len(pkgs) == 0 {
+		logging.Warningf("no packages found in %s", flag.Arg(0))
+	}
+
+	if cfg.List {
+		if err := list(pkgs, os.Stdout, cfg); err != nil {
+			logging.Errorf("write error: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	dict, err := cvefeed.LoadCompactJSONDictionaryContext(ctx, flag.Args()[1:]...)
+	if err != nil {
+		logging.Errorf("failed to load feeds: %v", err)
+		os.Exit(1)
+	}
+	if err := logging.Err(); err != nil {
+		logging.Errorf("%v", err)
+		os.Exit(1)
+	}
+	// matching doesn't need cleaning up, signals can kill it
+	stop()
+	cache := cvefeed.NewCache(dict).SetRequireVersion(cfg.RequireVersion).SetInvertedIndex()
+	if err := process(pkgs, cache, os.Stdout, cfg); err != nil {
+		logging.Errorf("write error: %v", err)
+		os.Exit(1)
+	}
+}
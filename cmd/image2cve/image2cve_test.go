@@ -0,0 +1,73 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/oci"
+)
+
+const testFeed = `{"CVE_Items":[
+{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"CVE-2023-5363"}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[{"operator":"OR","cpe_match":[{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:*:openssl:*:*:*:*:*:debian_bookworm:*:*","versionEndExcluding":"3.0.11-1~deb12u2"}]}]},
+ "impact":{"baseMetricV3":{"cvssV3":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N","baseScore":7.5}}}},
+{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"CVE-2022-3715"}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[{"operator":"OR","cpe_match":[{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:*:bash:*:*:*:*:*:debian_bullseye:*:*","versionEndExcluding":"5.1-2+deb11u1"}]}]}}
+]}`
+
+var testPackages = []*oci.Package{
+	{Type: "dpkg", Name: "libssl3", Source: "openssl", Version: "3.0.9-1", Arch: "amd64", Distro: "debian_bookworm"},
+	{Type: "dpkg", Name: "bash", Version: "5.2.15-2+b2", Arch: "amd64", Distro: "debian_bookworm"},
+	{Type: "rpm", Name: "openssl", Version: "3.0.7", Release: "24.el9", Arch: "x86_64", Distro: "rhel_9"},
+}
+
+func TestProcess(t *testing.T) {
+	vulns, err := cvefeed.ParseJSON(strings.NewReader(testFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dict := make(cvefeed.Dictionary)
+	for _, v := range vulns {
+		dict[v.ID()] = v
+	}
+
+	cfg := config{OutFieldSeparator: "\t", OutRecordSeparator: ","}
+	var w bytes.Buffer
+	if err := process(testPackages, cvefeed.NewCache(dict), &w, cfg); err != nil {
+		t.Fatal(err)
+	}
+	expect := "dpkg\tlibssl3\t3.0.9-1\tCVE-2023-5363\t7.5\tcpe:/a::openssl:3.0.9-1::~~~debian_bookworm~x64~\n"
+	if w.String() != expect {
+		t.Fatalf("got:\n%q\nexpected:\n%q", w.String(), expect)
+	}
+}
+
+func TestList(t *testing.T) {
+	cfg := config{OutFieldSeparator: ";"}
+	var w bytes.Buffer
+	if err := list(testPackages, &w, cfg); err != nil {
+		t.Fatal(err)
+	}
+	expect := "dpkg;libssl3;3.0.9-1;cpe:/a::openssl:3.0.9-1::~~~debian_bookworm~x64~\n" +
+		"dpkg;bash;5.2.15-2+b2;cpe:/a::bash:5.2.15-2%2bb2::~~~debian_bookworm~x64~\n" +
+		"rpm;openssl;3.0.7-24.el9;cpe:/a::openssl:3.0.7:24.el9:~~~rhel_9~x64~\n"
+	if w.String() != expect {
+		t.Fatalf("got:\n%q\nexpected:\n%q", w.String(), expect)
+	}
+}
@@ -0,0 +1,179 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// nvd2parquet loads NVD JSON feeds and writes them as Parquet files for data lakes:
+// cve.parquet with a row per vulnerability and cpe_match.parquet with a row per CPE match criterion
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/parquet"
+	"github.com/facebookincubator/nvdtools/shutdown"
+)
+
+type config struct {
+	OutDir       string
+	Codec        string
+	RowGroupSize int
+	Lang         string
+}
+
+func (cfg *config) addFlags() {
+	flag.StringVar(&cfg.OutDir, "o", ".", "directory to write cve.parquet and cpe_match.parquet to")
+	flag.StringVar(&cfg.Codec, "codec", "gzip", "compression of pages: gzip or none")
+	flag.IntVar(&cfg.RowGroupSize, "row_group_size", parquet.DefaultRowGroupSize, "number of rows in a row group")
+	flag.StringVar(&cfg.Lang, "lang", cvefeed.DefaultLang, "language of descriptions")
+}
+
+func init() {
+	logging.AddFlags()
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] nvd_feed.json.gz...\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "flags:\n")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+}
+
+// output is a parquet file being written, it's renamed to its name once it's closed
+type output struct {
+	f *shutdown.File
+	*parquet.Writer
+}
+
+func create(cfg *config, name string, columns []parquet.Column) (*output, error) {
+	f, err := shutdown.CreateFile(filepath.Join(cfg.OutDir, name))
+	if err != nil {
+		return nil, err
+	}
+	w := parquet.NewWriter(f, columns)
+	w.RowGroupSize = cfg.RowGroupSize
+	if cfg.Codec == "none" {
+		w.Codec = parquet.Uncompressed
+	}
+	w.Metadata = map[string]string{"nvdtools.schema_version": schemaVersion}
+	return &output{f, w}, nil
+}
+
+// Close writes the footer and commits the file
+func (o *output) Close() error {
+	if err := o.Writer.Close(); err != nil {
+		return err
+	}
+	return o.f.Commit()
+}
+
+func main() {
+	var cfg config
+	cfg.addFlags()
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+	}
+	if cfg.Codec != "gzip" && cfg.Codec != "none" {
+		logging.Errorf("unsupported codec %q", cfg.Codec)
+		os.Exit(1)
+	}
+	if cfg.RowGroupSize < 1 {
+		logging.Errorf("row group size must be positive, got %d", cfg.RowGroupSize)
+		os.Exit(1)
+	}
+
+	ctx, stop := shutdown.Context(context.Background())
+	defer stop()
+	if err := run(ctx, &cfg, flag.Args()); err != nil {
+		logging.Errorf("%v", err)
+		os.Exit(1)
+	}
+}
+
+// run writes the feeds to parquet files, which are only created if all feeds are written
+func run(ctx context.Context, cfg *config, feeds []string) error {
+	cves, err := create(cfg, "cve.parquet", cveColumns())
+	if err != nil {
+		return err
+	}
+	defer cves.f.Remove()
+	versions := make([]string, len(cvssVersions))
+	for i, v := range cvssVersions {
+		versions[i] = v.name
+	}
+	cves.Metadata["nvdtools.cvss_versions"] = strings.Join(versions, ",")
+	matches, err := create(cfg, "cpe_match.parquet", cpeMatchColumns)
+	if err != nil {
+		return err
+	}
+	defer matches.f.Remove()
+
+	for _, feed := range feeds {
+		n, err := writeFeed(ctx, cves, matches, feed, cfg.Lang)
+		if err != nil {
+			return fmt.Errorf("can't load feed %q: %v", feed, err)
+		}
+		logging.Infof("%s: %d vulnerabilities", feed, n)
+	}
+	if err := logging.Err(); err != nil {
+		return err
+	}
+	for _, o := range []*output{cves, matches} {
+		if err := o.Close(); err != nil {
+			return fmt.Errorf("can't write %s: %v", o.f.Name(), err)
+		}
+	}
+	return nil
+}
+
+// writeFeed writes vulnerabilities from the feed file and returns their number
+func writeFeed(ctx context.Context, cves, matches *output, path, lang string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	var n int
+	var werr error
+	err = cvefeed.DecodeJSONItemsContext(ctx, f, func(item *schema.NVDCVEFeedJSON10DefCVEItem) {
+		if werr != nil {
+			return
+		}
+		row := cveRow(item, lang)
+		if row[0] == "" {
+			logging.Recordf("%s: skipping item without ID", path)
+			return
+		}
+		if werr = cves.Write(row); werr != nil {
+			return
+		}
+		for _, m := range cpeMatchRows(item) {
+			if werr = matches.Write(m); werr != nil {
+				return
+			}
+		}
+		n++
+	})
+	if err == nil {
+		err = werr
+	}
+	return n, err
+}
@@ -0,0 +1,182 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/parquet"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// schemaVersion is written in the metadata of the files, it's incremented when columns are added
+const schemaVersion = "1"
+
+// cvssVersion describes the columns of a CVSS version in the cve file.
+// Columns of new versions must be appended at the end of cvssVersions, so the schemas of files written
+// by older and newer versions differ only in optional columns at the end, which readers can merge
+// (e.g. Spark with mergeSchema, BigQuery with ALLOW_FIELD_ADDITION)
+type cvssVersion struct {
+	name   string
+	vector func(cvefeed.Vuln) string
+	score  func(cvefeed.Vuln) float64
+}
+
+var cvssVersions = []cvssVersion{
+	{"cvss2", cvefeed.Vuln.CVSSv2Vector, cvefeed.Vuln.CVSSv2BaseScore},
+	{"cvss3", cvefeed.Vuln.CVSSv3Vector, cvefeed.Vuln.CVSSv3BaseScore},
+	{"cvss4", cvefeed.Vuln.CVSSv4Vector, cvefeed.Vuln.CVSSv4BaseScore},
+}
+
+// cveColumns returns the columns of the cve file, one row per vulnerability
+func cveColumns() []parquet.Column {
+	columns := []parquet.Column{
+		{Name: "id", Type: parquet.String},
+		{Name: "assigner", Type: parquet.String, Optional: true},
+		{Name: "published", Type: parquet.Timestamp, Optional: true},
+		{Name: "last_modified", Type: parquet.Timestamp, Optional: true},
+		{Name: "description", Type: parquet.String, Optional: true},
+		{Name: "cwes", Type: parquet.String, Optional: true},
+		{Name: "references", Type: parquet.String, Optional: true},
+	}
+	for _, v := range cvssVersions {
+		columns = append(columns,
+			parquet.Column{Name: v.name + "_vector", Type: parquet.String, Optional: true},
+			parquet.Column{Name: v.name + "_score", Type: parquet.Double, Optional: true},
+		)
+	}
+	return columns
+}
+
+// cpeMatchColumns are the columns of the cpe_match file, one row per match criterion
+var cpeMatchColumns = []parquet.Column{
+	{Name: "cve_id", Type: parquet.String},
+	{Name: "node", Type: parquet.String},
+	{Name: "operator", Type: parquet.String, Optional: true},
+	{Name: "negate", Type: parquet.Boolean},
+	{Name: "vulnerable", Type: parquet.Boolean},
+	{Name: "criteria", Type: parquet.String},
+	{Name: "part", Type: parquet.String, Optional: true},
+	{Name: "vendor", Type: parquet.String, Optional: true},
+	{Name: "product", Type: parquet.String, Optional: true},
+	{Name: "version", Type: parquet.String, Optional: true},
+	{Name: "version_start_including", Type: parquet.String, Optional: true},
+	{Name: "version_start_excluding", Type: parquet.String, Optional: true},
+	{Name: "version_end_including", Type: parquet.String, Optional: true},
+	{Name: "version_end_excluding", Type: parquet.String, Optional: true},
+	{Name: "match_criteria_id", Type: parquet.String, Optional: true},
+}
+
+// cveRow returns the row of the vulnerability in the cve file
+func cveRow(item *schema.NVDCVEFeedJSON10DefCVEItem, lang string) []interface{} {
+	v := nvd.ToVuln(item)
+	var assigner string
+	if item.CVE != nil && item.CVE.CVEDataMeta != nil {
+		assigner = item.CVE.CVEDataMeta.ASSIGNER
+	}
+	refs := make([]string, 0, len(v.References()))
+	for _, ref := range v.References() {
+		refs = append(refs, ref.URL)
+	}
+	row := []interface{}{
+		v.ID(),
+		optional(assigner),
+		optionalTime(v.Published()),
+		optionalTime(v.LastModified()),
+		optional(cvefeed.Description(v, lang)),
+		optional(strings.Join(v.CWEs(), ",")),
+		optional(strings.Join(refs, "\n")),
+	}
+	for _, cv := range cvssVersions {
+		if vector := cv.vector(v); vector != "" {
+			row = append(row, vector, cv.score(v))
+		} else {
+			row = append(row, nil, nil)
+		}
+	}
+	return row
+}
+
+// cpeMatchRows returns the rows of the CPE match criteria of the vulnerability in the cpe_match file
+func cpeMatchRows(item *schema.NVDCVEFeedJSON10DefCVEItem) [][]interface{} {
+	if item.Configurations == nil || item.CVE == nil || item.CVE.CVEDataMeta == nil {
+		return nil
+	}
+	var rows [][]interface{}
+	var walk func(path string, node *schema.NVDCVEFeedJSON10DefNode)
+	walk = func(path string, node *schema.NVDCVEFeedJSON10DefNode) {
+		if node == nil {
+			return
+		}
+		for _, m := range node.CPEMatch {
+			if m == nil || m.Cpe23Uri == "" {
+				continue
+			}
+			row := []interface{}{
+				item.CVE.CVEDataMeta.ID,
+				path,
+				optional(strings.ToUpper(node.Operator)),
+				node.Negate,
+				m.Vulnerable,
+				m.Cpe23Uri,
+			}
+			if attrs, err := wfn.UnbindFmtString(m.Cpe23Uri); err == nil {
+				row = append(row, optional(attrs.Part), optionalAttr(attrs.Vendor), optionalAttr(attrs.Product), optionalAttr(attrs.Version))
+			} else {
+				row = append(row, nil, nil, nil, nil)
+			}
+			row = append(row,
+				optional(m.VersionStartIncluding),
+				optional(m.VersionStartExcluding),
+				optional(m.VersionEndIncluding),
+				optional(m.VersionEndExcluding),
+				optional(m.MatchCriteriaID),
+			)
+			rows = append(rows, row)
+		}
+		for i, child := range node.Children {
+			walk(path+"."+strconv.Itoa(i), child)
+		}
+	}
+	for i, node := range item.Configurations.Nodes {
+		walk(strconv.Itoa(i), node)
+	}
+	return rows
+}
+
+// optional returns nil for empty strings, which are stored as nulls
+func optional(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// optionalAttr returns the unescaped value of a WFN attribute, nil if it's ANY
+func optionalAttr(s string) interface{} {
+	return optional(wfn.StripSlashes(s))
+}
+
+func optionalTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
@@ -0,0 +1,96 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/parquet"
+)
+
+const testFeed = `{"CVE_Items": [{
+	"cve": {
+		"CVE_data_meta": {"ID": "CVE-2020-0001", "ASSIGNER": "cve@mitre.org"},
+		"problemtype": {"problemtype_data": [{"description": [{"lang": "en", "value": "CWE-79"}]}]},
+		"references": {"reference_data": [{"url": "https://example.com/a"}, {"url": "https://example.com/b"}]},
+		"description": {"description_data": [{"lang": "en", "value": "bad"}]}
+	},
+	"configurations": {"nodes": [{
+		"operator": "AND",
+		"children": [
+			{"operator": "OR", "cpe_match": [{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:vendor:some\\.product:*:*:*:*:*:*:*:*", "versionEndExcluding": "1.2"}]},
+			{"operator": "OR", "cpe_match": [{"vulnerable": false, "cpe23Uri": "cpe:2.3:o:vendor:os:-:*:*:*:*:*:*:*"}]}
+		]
+	}]},
+	"impact": {"baseMetricV3": {"cvssV3": {"vectorString": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "baseScore": 9.8}}},
+	"publishedDate": "2020-01-02T03:04Z"
+}]}`
+
+func testItem(t *testing.T) *schema.NVDCVEFeedJSON10DefCVEItem {
+	var items []*schema.NVDCVEFeedJSON10DefCVEItem
+	err := cvefeed.DecodeJSONItems(strings.NewReader(testFeed), func(item *schema.NVDCVEFeedJSON10DefCVEItem) {
+		items = append(items, item)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return items[0]
+}
+
+func TestCVERow(t *testing.T) {
+	row := cveRow(testItem(t), "en")
+	expected := []interface{}{
+		"CVE-2020-0001",
+		"cve@mitre.org",
+		time.Date(2020, 1, 2, 3, 4, 0, 0, time.UTC),
+		nil,
+		"bad",
+		"CWE-79",
+		"https://example.com/a\nhttps://example.com/b",
+		nil, nil,
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", 9.8,
+		nil, nil,
+	}
+	if !reflect.DeepEqual(row, expected) {
+		t.Fatalf("expected %v, got %v", expected, row)
+	}
+	w := parquet.NewWriter(ioutil.Discard, cveColumns())
+	if err := w.Write(row); err != nil {
+		t.Fatalf("row doesn't fit the columns: %v", err)
+	}
+}
+
+func TestCPEMatchRows(t *testing.T) {
+	rows := cpeMatchRows(testItem(t))
+	expected := [][]interface{}{
+		{"CVE-2020-0001", "0.0", "OR", false, true, "cpe:2.3:a:vendor:some\\.product:*:*:*:*:*:*:*:*", "a", "vendor", "some.product", nil, nil, nil, nil, "1.2", nil},
+		{"CVE-2020-0001", "0.1", "OR", false, false, "cpe:2.3:o:vendor:os:-:*:*:*:*:*:*:*", "o", "vendor", "os", "-", nil, nil, nil, nil, nil},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Fatalf("expected %v, got %v", expected, rows)
+	}
+	w := parquet.NewWriter(ioutil.Discard, cpeMatchColumns)
+	for _, row := range rows {
+		if err := w.Write(row); err != nil {
+			t.Fatalf("row doesn't fit the columns: %v", err)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// nvd2sql loads NVD JSON feeds and writes them as SQL statements which create and fill
+// a normalized relational schema in SQLite or PostgreSQL
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/shutdown"
+)
+
+type config struct {
+	Dialect  string
+	Output   string
+	Batch    int
+	NoSchema bool
+	Lang     string
+}
+
+func (cfg *config) addFlags() {
+	flag.StringVar(&cfg.Dialect, "dialect", "sqlite", "SQL dialect of the output: sqlite or postgres")
+	flag.StringVar(&cfg.Output, "o", "", "write SQL to this file instead of stdout")
+	flag.IntVar(&cfg.Batch, "batch", 500, "number of vulnerabilities per INSERT statement")
+	flag.BoolVar(&cfg.NoSchema, "no_schema", false, "don't create the tables and indexes, they must exist already")
+	flag.StringVar(&cfg.Lang, "lang", cvefeed.DefaultLang, "language of descriptions")
+}
+
+func init() {
+	logging.AddFlags()
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] nvd_feed.json.gz... | sqlite3 nvd.db\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "       %s -dialect postgres [flags] nvd_feed.json.gz... | psql dbname\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "flags:\n")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+}
+
+func main() {
+	var cfg config
+	cfg.addFlags()
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+	}
+	d, ok := dialects[cfg.Dialect]
+	if !ok {
+		logging.Errorf("unsupported dialect %q", cfg.Dialect)
+		os.Exit(1)
+	}
+	if cfg.Batch < 1 {
+		logging.Errorf("batch must be positive, got %d", cfg.Batch)
+		os.Exit(1)
+	}
+
+	ctx, stop := shutdown.Context(context.Background())
+	defer stop()
+	if err := run(ctx, &cfg, d, flag.Args()); err != nil {
+		logging.Errorf("%v", err)
+		os.Exit(1)
+	}
+}
+
+// run writes SQL for the feeds, the output file is only created if all of them are written
+func run(ctx context.Context, cfg *config, d *dialect, feeds []string) error {
+	var out io.Writer = os.Stdout
+	if cfg.Output != "" {
+		f, err := shutdown.CreateFile(cfg.Output)
+		if err != nil {
+			return err
+		}
+		defer f.Remove()
+		out = f
+	}
+
+	sw := newSQLWriter(out, d, cfg.Batch, cfg.Lang)
+	sw.begin(!cfg.NoSchema)
+	for _, feed := range feeds {
+		n, err := writeFeed(ctx, sw, feed)
+		if err != nil {
+			return fmt.Errorf("can't load feed %q: %v", feed, err)
+		}
+		logging.Infof("%s: %d vulnerabilities", feed, n)
+	}
+	if err := logging.Err(); err != nil {
+		return err
+	}
+	if err := sw.end(); err != nil {
+		return fmt.Errorf("can't write SQL: %v", err)
+	}
+	if f, ok := out.(*shutdown.File); ok {
+		return f.Commit()
+	}
+	return nil
+}
+
+// writeFeed writes vulnerabilities from the feed file and returns their number
+func writeFeed(ctx context.Context, sw *sqlWriter, path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	var n int
+	err = cvefeed.DecodeJSONItemsContext(ctx, f, func(item *schema.NVDCVEFeedJSON10DefCVEItem) {
+		if err := sw.add(item); err != nil {
+			logging.Recordf("%s: skipping item: %v", path, err)
+			return
+		}
+		n++
+	})
+	return n, err
+}
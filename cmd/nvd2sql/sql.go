@@ -0,0 +1,310 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+)
+
+// column types, mapped to SQL types by dialects
+const (
+	typeText = iota
+	typeReal
+	typeBool
+	typeTime
+)
+
+// dialect describes the differences between the supported databases
+type dialect struct {
+	types [4]string
+	bools [2]string // false, true
+}
+
+var dialects = map[string]*dialect{
+	"sqlite": {
+		types: [4]string{"TEXT", "REAL", "INTEGER", "TEXT"},
+		bools: [2]string{"0", "1"},
+	},
+	"postgres": {
+		types: [4]string{"TEXT", "DOUBLE PRECISION", "BOOLEAN", "TIMESTAMPTZ"},
+		bools: [2]string{"FALSE", "TRUE"},
+	},
+}
+
+type column struct {
+	name string
+	typ  int
+}
+
+type table struct {
+	name    string
+	columns []column
+}
+
+// cveTable has one row per vulnerability, the other tables reference it by cve_id
+var cveTable = table{"cve", []column{
+	{"id", typeText},
+	{"assigner", typeText},
+	{"published", typeTime},
+	{"last_modified", typeTime},
+	{"description", typeText},
+}}
+
+// childTables are the tables with rows of a vulnerability, they're replaced when the vulnerability is
+var childTables = []table{
+	{"cwe", []column{
+		{"cve_id", typeText},
+		{"cwe", typeText},
+	}},
+	{"cvss", []column{
+		{"cve_id", typeText},
+		{"version", typeText},
+		{"vector", typeText},
+		{"base_score", typeReal},
+	}},
+	{"reference", []column{
+		{"cve_id", typeText},
+		{"url", typeText},
+		{"name", typeText},
+		{"source", typeText},
+		{"tags", typeText},
+	}},
+	{"cpe_match", []column{
+		{"cve_id", typeText},
+		{"node", typeText},
+		{"operator", typeText},
+		{"negate", typeBool},
+		{"vulnerable", typeBool},
+		{"cpe23_uri", typeText},
+		{"version_start_including", typeText},
+		{"version_start_excluding", typeText},
+		{"version_end_including", typeText},
+		{"version_end_excluding", typeText},
+		{"match_criteria_id", typeText},
+	}},
+}
+
+// sqlWriter writes vulnerabilities as SQL statements, in batches of rows, inside a single transaction;
+// vulnerabilities which are already in the database are replaced
+type sqlWriter struct {
+	w     *bufio.Writer
+	d     *dialect
+	batch int
+	lang  string
+
+	ids     []string
+	pending map[string]bool
+	rows    map[string][]string // table -> formatted rows
+}
+
+func newSQLWriter(w io.Writer, d *dialect, batch int, lang string) *sqlWriter {
+	if batch < 1 {
+		batch = 1
+	}
+	sw := sqlWriter{w: bufio.NewWriter(w), d: d, batch: batch, lang: lang}
+	sw.reset()
+	return &sw
+}
+
+func (sw *sqlWriter) reset() {
+	sw.ids = nil
+	sw.pending = make(map[string]bool)
+	sw.rows = make(map[string][]string)
+}
+
+// begin starts the transaction, creating the tables and indexes if they don't exist if schema is true
+func (sw *sqlWriter) begin(schema bool) {
+	fmt.Fprintln(sw.w, "BEGIN;")
+	if !schema {
+		return
+	}
+	sw.createTable(cveTable, "PRIMARY KEY (id)")
+	for _, t := range childTables {
+		sw.createTable(t, "")
+		fmt.Fprintf(sw.w, "CREATE INDEX IF NOT EXISTS %s_cve_id ON %s (cve_id);\n", t.name, t.name)
+	}
+}
+
+func (sw *sqlWriter) createTable(t table, constraint string) {
+	defs := make([]string, 0, len(t.columns)+1)
+	for _, c := range t.columns {
+		defs = append(defs, c.name+" "+sw.d.types[c.typ])
+	}
+	if constraint != "" {
+		defs = append(defs, constraint)
+	}
+	fmt.Fprintf(sw.w, "CREATE TABLE IF NOT EXISTS %s (\n\t%s\n);\n", t.name, strings.Join(defs, ",\n\t"))
+}
+
+// add adds the rows of the vulnerability to the current batch, writing the batch if it's full
+func (sw *sqlWriter) add(item *schema.NVDCVEFeedJSON10DefCVEItem) error {
+	v := nvd.ToVuln(item)
+	id := v.ID()
+	if id == "" {
+		return fmt.Errorf("vulnerability without ID")
+	}
+	if sw.pending[id] {
+		// the same row can't be upserted twice in one statement
+		sw.flush()
+	}
+	sw.ids = append(sw.ids, id)
+	sw.pending[id] = true
+
+	var assigner string
+	if item.CVE != nil && item.CVE.CVEDataMeta != nil {
+		assigner = item.CVE.CVEDataMeta.ASSIGNER
+	}
+	sw.addRow(cveTable.name, sw.text(id), sw.text(assigner), sw.time(v.Published()), sw.time(v.LastModified()), sw.text(cvefeed.Description(v, sw.lang)))
+	for _, cwe := range v.CWEs() {
+		sw.addRow("cwe", sw.text(id), sw.text(cwe))
+	}
+	if vector := v.CVSSv2Vector(); vector != "" {
+		sw.addRow("cvss", sw.text(id), sw.text("2.0"), sw.text(vector), sw.real(v.CVSSv2BaseScore()))
+	}
+	if vector := v.CVSSv3Vector(); vector != "" {
+		sw.addRow("cvss", sw.text(id), sw.text(cvss3Version(vector)), sw.text(vector), sw.real(v.CVSSv3BaseScore()))
+	}
+	if vector := v.CVSSv4Vector(); vector != "" {
+		sw.addRow("cvss", sw.text(id), sw.text("4.0"), sw.text(vector), sw.real(v.CVSSv4BaseScore()))
+	}
+	for _, ref := range v.References() {
+		sw.addRow("reference", sw.text(id), sw.text(ref.URL), sw.text(ref.Name), sw.text(ref.Source), sw.text(strings.Join(ref.Tags, ",")))
+	}
+	if item.Configurations != nil {
+		for i, node := range item.Configurations.Nodes {
+			sw.addNode(id, strconv.Itoa(i), node)
+		}
+	}
+
+	if len(sw.ids) >= sw.batch {
+		sw.flush()
+	}
+	return nil
+}
+
+// addNode adds the CPE matches of the node and its children, path identifies the node in the configuration,
+// e.g. 0.1 is the second child of the first node
+func (sw *sqlWriter) addNode(id, path string, node *schema.NVDCVEFeedJSON10DefNode) {
+	if node == nil {
+		return
+	}
+	for _, m := range node.CPEMatch {
+		if m == nil {
+			continue
+		}
+		sw.addRow("cpe_match", sw.text(id), sw.text(path), sw.text(strings.ToUpper(node.Operator)), sw.bool(node.Negate), sw.bool(m.Vulnerable),
+			sw.text(m.Cpe23Uri), sw.text(m.VersionStartIncluding), sw.text(m.VersionStartExcluding),
+			sw.text(m.VersionEndIncluding), sw.text(m.VersionEndExcluding), sw.text(m.MatchCriteriaID))
+	}
+	for i, child := range node.Children {
+		sw.addNode(id, path+"."+strconv.Itoa(i), child)
+	}
+}
+
+func (sw *sqlWriter) addRow(table string, values ...string) {
+	sw.rows[table] = append(sw.rows[table], "("+strings.Join(values, ", ")+")")
+}
+
+// flush writes the current batch: rows of the vulnerabilities in child tables are deleted,
+// the vulnerabilities are upserted and their new rows are inserted
+func (sw *sqlWriter) flush() {
+	if len(sw.ids) == 0 {
+		return
+	}
+	ids := make([]string, len(sw.ids))
+	for i, id := range sw.ids {
+		ids[i] = sw.text(id)
+	}
+	in := strings.Join(ids, ", ")
+	for _, t := range childTables {
+		fmt.Fprintf(sw.w, "DELETE FROM %s WHERE cve_id IN (%s);\n", t.name, in)
+	}
+
+	var updates []string
+	for _, c := range cveTable.columns[1:] {
+		updates = append(updates, fmt.Sprintf("%s = excluded.%s", c.name, c.name))
+	}
+	sw.insert(cveTable, fmt.Sprintf(" ON CONFLICT (id) DO UPDATE SET %s", strings.Join(updates, ", ")))
+	for _, t := range childTables {
+		sw.insert(t, "")
+	}
+	sw.reset()
+}
+
+func (sw *sqlWriter) insert(t table, suffix string) {
+	rows := sw.rows[t.name]
+	if len(rows) == 0 {
+		return
+	}
+	names := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		names[i] = c.name
+	}
+	fmt.Fprintf(sw.w, "INSERT INTO %s (%s) VALUES\n%s%s;\n", t.name, strings.Join(names, ", "), strings.Join(rows, ",\n"), suffix)
+}
+
+// end writes the last batch and commits the transaction
+func (sw *sqlWriter) end() error {
+	sw.flush()
+	fmt.Fprintln(sw.w, "COMMIT;")
+	return sw.w.Flush()
+}
+
+// text returns the SQL literal of s, NULL if it's empty
+func (sw *sqlWriter) text(s string) string {
+	if s == "" {
+		return "NULL"
+	}
+	// postgres doesn't allow NUL in text
+	s = strings.Replace(s, "\x00", "", -1)
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+func (sw *sqlWriter) real(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func (sw *sqlWriter) bool(b bool) string {
+	if b {
+		return sw.d.bools[1]
+	}
+	return sw.d.bools[0]
+}
+
+func (sw *sqlWriter) time(t time.Time) string {
+	if t.IsZero() {
+		return "NULL"
+	}
+	return sw.text(t.UTC().Format(time.RFC3339))
+}
+
+// cvss3Version returns the version from the prefix of CVSS v3 vector, e.g. CVSS:3.1/AV:N/...
+func cvss3Version(vector string) string {
+	if strings.HasPrefix(vector, "CVSS:") {
+		if i := strings.IndexByte(vector, '/'); i > 0 {
+			return vector[len("CVSS:"):i]
+		}
+	}
+	return "3.0"
+}
@@ -0,0 +1,125 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+)
+
+const testFeed = `{"CVE_Items": [{
+	"cve": {
+		"CVE_data_meta": {"ID": "CVE-2020-0001", "ASSIGNER": "cve@mitre.org"},
+		"problemtype": {"problemtype_data": [{"description": [{"lang": "en", "value": "CWE-79"}]}]},
+		"references": {"reference_data": [{"url": "https://example.com/a", "name": "a", "refsource": "MISC", "tags": ["Patch", "Vendor Advisory"]}]},
+		"description": {"description_data": [{"lang": "en", "value": "It's bad"}]}
+	},
+	"configurations": {"nodes": [{
+		"operator": "AND",
+		"children": [
+			{"operator": "OR", "cpe_match": [{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:vendor:product:*:*:*:*:*:*:*:*", "versionEndExcluding": "1.2"}]},
+			{"operator": "OR", "cpe_match": [{"vulnerable": false, "cpe23Uri": "cpe:2.3:o:vendor:os:-:*:*:*:*:*:*:*"}]}
+		]
+	}]},
+	"impact": {"baseMetricV3": {"cvssV3": {"vectorString": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "baseScore": 9.8}}},
+	"publishedDate": "2020-01-02T03:04Z",
+	"lastModifiedDate": "2020-02-03T04:05Z"
+}]}`
+
+func testItems(t *testing.T) []*schema.NVDCVEFeedJSON10DefCVEItem {
+	var items []*schema.NVDCVEFeedJSON10DefCVEItem
+	err := cvefeed.DecodeJSONItems(strings.NewReader(testFeed), func(item *schema.NVDCVEFeedJSON10DefCVEItem) {
+		items = append(items, item)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return items
+}
+
+func TestSQLWriter(t *testing.T) {
+	var buf bytes.Buffer
+	sw := newSQLWriter(&buf, dialects["postgres"], 10, "en")
+	sw.begin(true)
+	for _, item := range testItems(t) {
+		if err := sw.add(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sw.end(); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, expected := range []string{
+		"BEGIN;\n",
+		"CREATE TABLE IF NOT EXISTS cve (\n\tid TEXT,\n\tassigner TEXT,\n\tpublished TIMESTAMPTZ,",
+		"CREATE INDEX IF NOT EXISTS cpe_match_cve_id ON cpe_match (cve_id);",
+		"DELETE FROM cvss WHERE cve_id IN ('CVE-2020-0001');",
+		"('CVE-2020-0001', 'cve@mitre.org', '2020-01-02T03:04:00Z', '2020-02-03T04:05:00Z', 'It''s bad') ON CONFLICT (id) DO UPDATE SET assigner = excluded.assigner,",
+		"('CVE-2020-0001', 'CWE-79')",
+		"('CVE-2020-0001', '3.1', 'CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H', 9.8)",
+		"('CVE-2020-0001', 'https://example.com/a', 'a', 'MISC', 'Patch,Vendor Advisory')",
+		"('CVE-2020-0001', '0.0', 'OR', FALSE, TRUE, 'cpe:2.3:a:vendor:product:*:*:*:*:*:*:*:*', NULL, NULL, NULL, '1.2', NULL)",
+		"('CVE-2020-0001', '0.1', 'OR', FALSE, FALSE, 'cpe:2.3:o:vendor:os:-:*:*:*:*:*:*:*', NULL, NULL, NULL, NULL, NULL)",
+		"COMMIT;\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("output doesn't contain %q:\n%s", expected, out)
+		}
+	}
+}
+
+func TestSQLWriterBatches(t *testing.T) {
+	item := testItems(t)[0]
+	var buf bytes.Buffer
+	sw := newSQLWriter(&buf, dialects["sqlite"], 10, "en")
+	sw.begin(false)
+	// the same vulnerability twice must end up in separate statements
+	for i := 0; i < 2; i++ {
+		if err := sw.add(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sw.end(); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if strings.Contains(out, "CREATE TABLE") {
+		t.Errorf("schema shouldn't be created")
+	}
+	if n := strings.Count(out, "INSERT INTO cve "); n != 2 {
+		t.Errorf("expected 2 inserts into cve, got %d:\n%s", n, out)
+	}
+	if !strings.Contains(out, "'0.0', 'OR', 0, 1,") {
+		t.Errorf("sqlite booleans should be integers:\n%s", out)
+	}
+}
+
+func TestCVSS3Version(t *testing.T) {
+	cases := map[string]string{
+		"CVSS:3.1/AV:N": "3.1",
+		"CVSS:3.0/AV:N": "3.0",
+		"AV:N/AC:L":     "3.0",
+	}
+	for vector, expected := range cases {
+		if version := cvss3Version(vector); version != expected {
+			t.Errorf("%s: expected %q, got %q", vector, expected, version)
+		}
+	}
+}
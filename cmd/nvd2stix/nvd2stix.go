@@ -0,0 +1,82 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// nvd2stix converts NVD JSON feeds to a STIX 2.1 bundle of vulnerability objects
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/shutdown"
+	"github.com/facebookincubator/nvdtools/stix"
+)
+
+func init() {
+	logging.AddFlags()
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] nvd_feed.json.gz... > bundle.json\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "flags:\n")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+}
+
+func main() {
+	lang := flag.String("lang", cvefeed.DefaultLang, "language of descriptions")
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+	}
+
+	ctx, stop := shutdown.Context(context.Background())
+	defer stop()
+
+	out := bufio.NewWriter(os.Stdout)
+	bw := stix.NewBundleWriter(out)
+	now := time.Now()
+	for _, feed := range flag.Args() {
+		vulns, err := cvefeed.LoadJSONDictionaryContext(ctx, feed)
+		if err != nil {
+			logging.Errorf("can't load feed %q: %v", feed, err)
+			os.Exit(1)
+		}
+		for _, v := range vulns {
+			if err := bw.Write(stix.FromVuln(v, *lang, now)); err != nil {
+				logging.Errorf("can't write bundle: %v", err)
+				os.Exit(1)
+			}
+		}
+		logging.Infof("%s: %d vulnerabilities", feed, len(vulns))
+	}
+	if err := logging.Err(); err != nil {
+		logging.Errorf("%v", err)
+		os.Exit(1)
+	}
+	if err := bw.Close(); err != nil {
+		logging.Errorf("can't write bundle: %v", err)
+		os.Exit(1)
+	}
+	if err := out.Flush(); err != nil {
+		logging.Errorf("can't write bundle: %v", err)
+		os.Exit(1)
+	}
+}
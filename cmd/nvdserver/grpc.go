@@ -0,0 +1,121 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build grpc
+// +build grpc
+
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "github.com/facebookincubator/nvdtools/cmd/nvdserver/matcherpb"
+)
+
+func init() {
+	serveGRPC = func(s *server, addr string) error {
+		lis, err := net.Listen("tcp", addr)
+		if err != nil {
+			return err
+		}
+		gs := grpc.NewServer()
+		pb.RegisterMatcherServer(gs, &grpcServer{s: s})
+		return gs.Serve(lis)
+	}
+}
+
+// grpcServer implements the Matcher service from matcherpb/matcher.proto on top of server
+type grpcServer struct {
+	pb.UnimplementedMatcherServer
+	s *server
+}
+
+// Match is a part of the pb.MatcherServer interface
+func (g *grpcServer) Match(ctx context.Context, req *pb.MatchRequest) (*pb.MatchResponse, error) {
+	return g.match(req)
+}
+
+// GetCVE is a part of the pb.MatcherServer interface
+func (g *grpcServer) GetCVE(ctx context.Context, req *pb.GetCVERequest) (*pb.CVEDetails, error) {
+	details, ok := g.s.cve(req.Id)
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "vulnerability %s not found", req.Id)
+	}
+	return &pb.CVEDetails{
+		Vulnerability: toPB(details.vulnerability),
+		Configuration: details.Configuration,
+	}, nil
+}
+
+// StreamMatch is a part of the pb.MatcherServer interface;
+// the stream is aborted at the first request which can't be matched
+func (g *grpcServer) StreamMatch(stream pb.Matcher_StreamMatchServer) error {
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		resp, err := g.match(req)
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+func (g *grpcServer) match(req *pb.MatchRequest) (*pb.MatchResponse, error) {
+	matches, err := g.s.match(req.Cpes)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "%s: %v", req.Key, err)
+	}
+	resp := &pb.MatchResponse{Key: req.Key, Matches: make([]*pb.Match, len(matches))}
+	for i, m := range matches {
+		resp.Matches[i] = &pb.Match{
+			Vulnerability: toPB(m.vulnerability),
+			MatchedCpes:   m.MatchedCPEs,
+		}
+	}
+	return resp, nil
+}
+
+func toPB(v vulnerability) *pb.Vulnerability {
+	pv := &pb.Vulnerability{
+		Id:           v.ID,
+		Cves:         v.CVEs,
+		Cwes:         v.CWEs,
+		Published:    v.Published,
+		LastModified: v.LastModified,
+	}
+	if v.CVSS2 != nil {
+		pv.Cvss2 = &pb.CVSS{Score: v.CVSS2.Score, Vector: v.CVSS2.Vector}
+	}
+	if v.CVSS3 != nil {
+		pv.Cvss3 = &pb.CVSS{Score: v.CVSS3.Score, Vector: v.CVSS3.Vector}
+	}
+	if v.CVSS4 != nil {
+		pv.Cvss4 = &pb.CVSS{Score: v.CVSS4.Score, Vector: v.CVSS4.Vector}
+	}
+	return pv
+}
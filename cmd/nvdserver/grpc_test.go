@@ -0,0 +1,153 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build grpc
+// +build grpc
+
+package main
+
+import (
+	"context"
+	"io"
+	"net"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "github.com/facebookincubator/nvdtools/cmd/nvdserver/matcherpb"
+)
+
+func testGRPCClient(t *testing.T) pb.MatcherClient {
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	pb.RegisterMatcherServer(gs, &grpcServer{s: newTestServer(t)})
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewMatcherClient(conn)
+}
+
+func TestGRPCMatch(t *testing.T) {
+	client := testGRPCClient(t)
+	ctx := context.Background()
+
+	resp, err := client.Match(ctx, &pb.MatchRequest{
+		Cpes: []string{"cpe:2.3:a:djangoproject:django:3.2.0:*:*:*:*:*:*:*"},
+		Key:  "asset",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Key != "asset" || len(resp.Matches) != 2 {
+		t.Fatalf("expecting 2 matches for asset, got %v", resp)
+	}
+	v := resp.Matches[1].Vulnerability
+	if v.Id != "CVE-2021-33203" {
+		t.Fatalf("matches aren't sorted: %v", resp.Matches)
+	}
+	want := []string{"cpe:2.3:a:djangoproject:django:3.2.0:*:*:*:*:*:*:*"}
+	if !reflect.DeepEqual(resp.Matches[1].MatchedCpes, want) {
+		t.Fatalf("expecting matched CPEs %v, got %v", want, resp.Matches[1].MatchedCpes)
+	}
+	if v.Cvss3 == nil || v.Cvss3.Score != 4.9 {
+		t.Fatalf("unexpected CVSS v3: %v", v.Cvss3)
+	}
+	if v.Published != "2021-06-08T18:15:00Z" {
+		t.Fatalf("unexpected published date %v", v.Published)
+	}
+
+	_, err = client.Match(ctx, &pb.MatchRequest{Cpes: []string{"django 3.2"}})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("expecting InvalidArgument, got %v", err)
+	}
+}
+
+func TestGRPCGetCVE(t *testing.T) {
+	client := testGRPCClient(t)
+	ctx := context.Background()
+
+	details, err := client.GetCVE(ctx, &pb.GetCVERequest{Id: "CVE-2021-33203"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details.Vulnerability.Id != "CVE-2021-33203" {
+		t.Fatalf("unexpected vulnerability %v", details.Vulnerability.Id)
+	}
+	if !reflect.DeepEqual(details.Vulnerability.Cwes, []string{"CWE-22"}) {
+		t.Fatalf("unexpected CWEs %v", details.Vulnerability.Cwes)
+	}
+	want := []string{"cpe:2.3:a:djangoproject:django:*:*:*:*:*:*:*:*"}
+	if !reflect.DeepEqual(details.Configuration, want) {
+		t.Fatalf("expecting configuration %v, got %v", want, details.Configuration)
+	}
+
+	_, err = client.GetCVE(ctx, &pb.GetCVERequest{Id: "CVE-2000-0001"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("expecting NotFound, got %v", err)
+	}
+}
+
+func TestGRPCStreamMatch(t *testing.T) {
+	client := testGRPCClient(t)
+
+	stream, err := client.StreamMatch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	reqs := []*pb.MatchRequest{
+		{Key: "a", Cpes: []string{"cpe:2.3:a:djangoproject:django:3.2.0:*:*:*:*:*:*:*"}},
+		{Key: "b", Cpes: []string{"cpe:2.3:a:djangoproject:django:3.2.2:*:*:*:*:*:*:*"}},
+		{Key: "c", Cpes: []string{"cpe:2.3:a:python:python:3.9:*:*:*:*:*:*:*"}},
+	}
+	for _, req := range reqs {
+		if err := stream.Send(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	var counts []int
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, resp.Key)
+		counts = append(counts, len(resp.Matches))
+	}
+	if !reflect.DeepEqual(got, []string{"a", "b", "c"}) || !reflect.DeepEqual(counts, []int{2, 1, 0}) {
+		t.Fatalf("unexpected responses %v with %v matches", got, counts)
+	}
+}
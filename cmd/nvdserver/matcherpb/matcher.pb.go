@@ -0,0 +1,551 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: matcher.proto
+
+// Package matcher is the gRPC API of nvdserver: CPE to CVE matching against the loaded feeds.
+
+package matcherpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type MatchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// CPEs as URIs (cpe:/a:...) or formatted strings (cpe:2.3:a:...).
+	Cpes []string `protobuf:"bytes,1,rep,name=cpes,proto3" json:"cpes,omitempty"`
+	// Key is copied to the response to correlate responses of StreamMatch with requests.
+	Key           string `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MatchRequest) Reset() {
+	*x = MatchRequest{}
+	mi := &file_matcher_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MatchRequest) ProtoMessage() {}
+
+func (x *MatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_matcher_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MatchRequest.ProtoReflect.Descriptor instead.
+func (*MatchRequest) Descriptor() ([]byte, []int) {
+	return file_matcher_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *MatchRequest) GetCpes() []string {
+	if x != nil {
+		return x.Cpes
+	}
+	return nil
+}
+
+func (x *MatchRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+type MatchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Key   string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	// Matches are sorted by vulnerability ID.
+	Matches       []*Match `protobuf:"bytes,2,rep,name=matches,proto3" json:"matches,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MatchResponse) Reset() {
+	*x = MatchResponse{}
+	mi := &file_matcher_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MatchResponse) ProtoMessage() {}
+
+func (x *MatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_matcher_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MatchResponse.ProtoReflect.Descriptor instead.
+func (*MatchResponse) Descriptor() ([]byte, []int) {
+	return file_matcher_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *MatchResponse) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *MatchResponse) GetMatches() []*Match {
+	if x != nil {
+		return x.Matches
+	}
+	return nil
+}
+
+type Match struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Vulnerability *Vulnerability         `protobuf:"bytes,1,opt,name=vulnerability,proto3" json:"vulnerability,omitempty"`
+	// CPEs from the request which matched the vulnerability, as formatted strings.
+	MatchedCpes   []string `protobuf:"bytes,2,rep,name=matched_cpes,json=matchedCpes,proto3" json:"matched_cpes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Match) Reset() {
+	*x = Match{}
+	mi := &file_matcher_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Match) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Match) ProtoMessage() {}
+
+func (x *Match) ProtoReflect() protoreflect.Message {
+	mi := &file_matcher_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Match.ProtoReflect.Descriptor instead.
+func (*Match) Descriptor() ([]byte, []int) {
+	return file_matcher_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Match) GetVulnerability() *Vulnerability {
+	if x != nil {
+		return x.Vulnerability
+	}
+	return nil
+}
+
+func (x *Match) GetMatchedCpes() []string {
+	if x != nil {
+		return x.MatchedCpes
+	}
+	return nil
+}
+
+type GetCVERequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCVERequest) Reset() {
+	*x = GetCVERequest{}
+	mi := &file_matcher_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCVERequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCVERequest) ProtoMessage() {}
+
+func (x *GetCVERequest) ProtoReflect() protoreflect.Message {
+	mi := &file_matcher_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCVERequest.ProtoReflect.Descriptor instead.
+func (*GetCVERequest) Descriptor() ([]byte, []int) {
+	return file_matcher_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetCVERequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type CVEDetails struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Vulnerability *Vulnerability         `protobuf:"bytes,1,opt,name=vulnerability,proto3" json:"vulnerability,omitempty"`
+	// CPEs from the vulnerability configuration, as formatted strings.
+	Configuration []string `protobuf:"bytes,2,rep,name=configuration,proto3" json:"configuration,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CVEDetails) Reset() {
+	*x = CVEDetails{}
+	mi := &file_matcher_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CVEDetails) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CVEDetails) ProtoMessage() {}
+
+func (x *CVEDetails) ProtoReflect() protoreflect.Message {
+	mi := &file_matcher_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CVEDetails.ProtoReflect.Descriptor instead.
+func (*CVEDetails) Descriptor() ([]byte, []int) {
+	return file_matcher_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CVEDetails) GetVulnerability() *Vulnerability {
+	if x != nil {
+		return x.Vulnerability
+	}
+	return nil
+}
+
+func (x *CVEDetails) GetConfiguration() []string {
+	if x != nil {
+		return x.Configuration
+	}
+	return nil
+}
+
+type Vulnerability struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Cves  []string               `protobuf:"bytes,2,rep,name=cves,proto3" json:"cves,omitempty"`
+	Cwes  []string               `protobuf:"bytes,3,rep,name=cwes,proto3" json:"cwes,omitempty"`
+	Cvss2 *CVSS                  `protobuf:"bytes,4,opt,name=cvss2,proto3" json:"cvss2,omitempty"`
+	Cvss3 *CVSS                  `protobuf:"bytes,5,opt,name=cvss3,proto3" json:"cvss3,omitempty"`
+	// RFC 3339 timestamps, empty if unknown.
+	Published     string `protobuf:"bytes,6,opt,name=published,proto3" json:"published,omitempty"`
+	LastModified  string `protobuf:"bytes,7,opt,name=last_modified,json=lastModified,proto3" json:"last_modified,omitempty"`
+	Cvss4         *CVSS  `protobuf:"bytes,8,opt,name=cvss4,proto3" json:"cvss4,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Vulnerability) Reset() {
+	*x = Vulnerability{}
+	mi := &file_matcher_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Vulnerability) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Vulnerability) ProtoMessage() {}
+
+func (x *Vulnerability) ProtoReflect() protoreflect.Message {
+	mi := &file_matcher_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Vulnerability.ProtoReflect.Descriptor instead.
+func (*Vulnerability) Descriptor() ([]byte, []int) {
+	return file_matcher_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Vulnerability) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Vulnerability) GetCves() []string {
+	if x != nil {
+		return x.Cves
+	}
+	return nil
+}
+
+func (x *Vulnerability) GetCwes() []string {
+	if x != nil {
+		return x.Cwes
+	}
+	return nil
+}
+
+func (x *Vulnerability) GetCvss2() *CVSS {
+	if x != nil {
+		return x.Cvss2
+	}
+	return nil
+}
+
+func (x *Vulnerability) GetCvss3() *CVSS {
+	if x != nil {
+		return x.Cvss3
+	}
+	return nil
+}
+
+func (x *Vulnerability) GetPublished() string {
+	if x != nil {
+		return x.Published
+	}
+	return ""
+}
+
+func (x *Vulnerability) GetLastModified() string {
+	if x != nil {
+		return x.LastModified
+	}
+	return ""
+}
+
+func (x *Vulnerability) GetCvss4() *CVSS {
+	if x != nil {
+		return x.Cvss4
+	}
+	return nil
+}
+
+type CVSS struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Score         float64                `protobuf:"fixed64,1,opt,name=score,proto3" json:"score,omitempty"`
+	Vector        string                 `protobuf:"bytes,2,opt,name=vector,proto3" json:"vector,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CVSS) Reset() {
+	*x = CVSS{}
+	mi := &file_matcher_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CVSS) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CVSS) ProtoMessage() {}
+
+func (x *CVSS) ProtoReflect() protoreflect.Message {
+	mi := &file_matcher_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CVSS.ProtoReflect.Descriptor instead.
+func (*CVSS) Descriptor() ([]byte, []int) {
+	return file_matcher_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CVSS) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *CVSS) GetVector() string {
+	if x != nil {
+		return x.Vector
+	}
+	return ""
+}
+
+var File_matcher_proto protoreflect.FileDescriptor
+
+const file_matcher_proto_rawDesc = "" +
+	"\n" +
+	"\rmatcher.proto\x12\x10nvdtools.matcher\"4\n" +
+	"\fMatchRequest\x12\x12\n" +
+	"\x04cpes\x18\x01 \x03(\tR\x04cpes\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\"T\n" +
+	"\rMatchResponse\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x121\n" +
+	"\amatches\x18\x02 \x03(\v2\x17.nvdtools.matcher.MatchR\amatches\"q\n" +
+	"\x05Match\x12E\n" +
+	"\rvulnerability\x18\x01 \x01(\v2\x1f.nvdtools.matcher.VulnerabilityR\rvulnerability\x12!\n" +
+	"\fmatched_cpes\x18\x02 \x03(\tR\vmatchedCpes\"\x1f\n" +
+	"\rGetCVERequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"y\n" +
+	"\n" +
+	"CVEDetails\x12E\n" +
+	"\rvulnerability\x18\x01 \x01(\v2\x1f.nvdtools.matcher.VulnerabilityR\rvulnerability\x12$\n" +
+	"\rconfiguration\x18\x02 \x03(\tR\rconfiguration\"\x94\x02\n" +
+	"\rVulnerability\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04cves\x18\x02 \x03(\tR\x04cves\x12\x12\n" +
+	"\x04cwes\x18\x03 \x03(\tR\x04cwes\x12,\n" +
+	"\x05cvss2\x18\x04 \x01(\v2\x16.nvdtools.matcher.CVSSR\x05cvss2\x12,\n" +
+	"\x05cvss3\x18\x05 \x01(\v2\x16.nvdtools.matcher.CVSSR\x05cvss3\x12\x1c\n" +
+	"\tpublished\x18\x06 \x01(\tR\tpublished\x12#\n" +
+	"\rlast_modified\x18\a \x01(\tR\flastModified\x12,\n" +
+	"\x05cvss4\x18\b \x01(\v2\x16.nvdtools.matcher.CVSSR\x05cvss4\"4\n" +
+	"\x04CVSS\x12\x14\n" +
+	"\x05score\x18\x01 \x01(\x01R\x05score\x12\x16\n" +
+	"\x06vector\x18\x02 \x01(\tR\x06vector2\xf0\x01\n" +
+	"\aMatcher\x12H\n" +
+	"\x05Match\x12\x1e.nvdtools.matcher.MatchRequest\x1a\x1f.nvdtools.matcher.MatchResponse\x12G\n" +
+	"\x06GetCVE\x12\x1f.nvdtools.matcher.GetCVERequest\x1a\x1c.nvdtools.matcher.CVEDetails\x12R\n" +
+	"\vStreamMatch\x12\x1e.nvdtools.matcher.MatchRequest\x1a\x1f.nvdtools.matcher.MatchResponse(\x010\x01B?Z=github.com/facebookincubator/nvdtools/cmd/nvdserver/matcherpbb\x06proto3"
+
+var (
+	file_matcher_proto_rawDescOnce sync.Once
+	file_matcher_proto_rawDescData []byte
+)
+
+func file_matcher_proto_rawDescGZIP() []byte {
+	file_matcher_proto_rawDescOnce.Do(func() {
+		file_matcher_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_matcher_proto_rawDesc), len(file_matcher_proto_rawDesc)))
+	})
+	return file_matcher_proto_rawDescData
+}
+
+var file_matcher_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_matcher_proto_goTypes = []any{
+	(*MatchRequest)(nil),  // 0: nvdtools.matcher.MatchRequest
+	(*MatchResponse)(nil), // 1: nvdtools.matcher.MatchResponse
+	(*Match)(nil),         // 2: nvdtools.matcher.Match
+	(*GetCVERequest)(nil), // 3: nvdtools.matcher.GetCVERequest
+	(*CVEDetails)(nil),    // 4: nvdtools.matcher.CVEDetails
+	(*Vulnerability)(nil), // 5: nvdtools.matcher.Vulnerability
+	(*CVSS)(nil),          // 6: nvdtools.matcher.CVSS
+}
+var file_matcher_proto_depIdxs = []int32{
+	2, // 0: nvdtools.matcher.MatchResponse.matches:type_name -> nvdtools.matcher.Match
+	5, // 1: nvdtools.matcher.Match.vulnerability:type_name -> nvdtools.matcher.Vulnerability
+	5, // 2: nvdtools.matcher.CVEDetails.vulnerability:type_name -> nvdtools.matcher.Vulnerability
+	6, // 3: nvdtools.matcher.Vulnerability.cvss2:type_name -> nvdtools.matcher.CVSS
+	6, // 4: nvdtools.matcher.Vulnerability.cvss3:type_name -> nvdtools.matcher.CVSS
+	6, // 5: nvdtools.matcher.Vulnerability.cvss4:type_name -> nvdtools.matcher.CVSS
+	0, // 6: nvdtools.matcher.Matcher.Match:input_type -> nvdtools.matcher.MatchRequest
+	3, // 7: nvdtools.matcher.Matcher.GetCVE:input_type -> nvdtools.matcher.GetCVERequest
+	0, // 8: nvdtools.matcher.Matcher.StreamMatch:input_type -> nvdtools.matcher.MatchRequest
+	1, // 9: nvdtools.matcher.Matcher.Match:output_type -> nvdtools.matcher.MatchResponse
+	4, // 10: nvdtools.matcher.Matcher.GetCVE:output_type -> nvdtools.matcher.CVEDetails
+	1, // 11: nvdtools.matcher.Matcher.StreamMatch:output_type -> nvdtools.matcher.MatchResponse
+	9, // [9:12] is the sub-list for method output_type
+	6, // [6:9] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_matcher_proto_init() }
+func file_matcher_proto_init() {
+	if File_matcher_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_matcher_proto_rawDesc), len(file_matcher_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_matcher_proto_goTypes,
+		DependencyIndexes: file_matcher_proto_depIdxs,
+		MessageInfos:      file_matcher_proto_msgTypes,
+	}.Build()
+	File_matcher_proto = out.File
+	file_matcher_proto_goTypes = nil
+	file_matcher_proto_depIdxs = nil
+}
@@ -0,0 +1,214 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: matcher.proto
+
+// Package matcher is the gRPC API of nvdserver: CPE to CVE matching against the loaded feeds.
+
+package matcherpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Matcher_Match_FullMethodName       = "/nvdtools.matcher.Matcher/Match"
+	Matcher_GetCVE_FullMethodName      = "/nvdtools.matcher.Matcher/GetCVE"
+	Matcher_StreamMatch_FullMethodName = "/nvdtools.matcher.Matcher/StreamMatch"
+)
+
+// MatcherClient is the client API for Matcher service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MatcherClient interface {
+	// Match returns vulnerabilities matching the CPEs, which are treated as a single asset.
+	Match(ctx context.Context, in *MatchRequest, opts ...grpc.CallOption) (*MatchResponse, error)
+	// GetCVE returns the vulnerability with the ID, NOT_FOUND if it isn't in the feeds.
+	GetCVE(ctx context.Context, in *GetCVERequest, opts ...grpc.CallOption) (*CVEDetails, error)
+	// StreamMatch matches a stream of assets, there's a response per request, in the same order.
+	StreamMatch(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[MatchRequest, MatchResponse], error)
+}
+
+type matcherClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMatcherClient(cc grpc.ClientConnInterface) MatcherClient {
+	return &matcherClient{cc}
+}
+
+func (c *matcherClient) Match(ctx context.Context, in *MatchRequest, opts ...grpc.CallOption) (*MatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MatchResponse)
+	err := c.cc.Invoke(ctx, Matcher_Match_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *matcherClient) GetCVE(ctx context.Context, in *GetCVERequest, opts ...grpc.CallOption) (*CVEDetails, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CVEDetails)
+	err := c.cc.Invoke(ctx, Matcher_GetCVE_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *matcherClient) StreamMatch(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[MatchRequest, MatchResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Matcher_ServiceDesc.Streams[0], Matcher_StreamMatch_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[MatchRequest, MatchResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Matcher_StreamMatchClient = grpc.BidiStreamingClient[MatchRequest, MatchResponse]
+
+// MatcherServer is the server API for Matcher service.
+// All implementations must embed UnimplementedMatcherServer
+// for forward compatibility.
+type MatcherServer interface {
+	// Match returns vulnerabilities matching the CPEs, which are treated as a single asset.
+	Match(context.Context, *MatchRequest) (*MatchResponse, error)
+	// GetCVE returns the vulnerability with the ID, NOT_FOUND if it isn't in the feeds.
+	GetCVE(context.Context, *GetCVERequest) (*CVEDetails, error)
+	// StreamMatch matches a stream of assets, there's a response per request, in the same order.
+	StreamMatch(grpc.BidiStreamingServer[MatchRequest, MatchResponse]) error
+	mustEmbedUnimplementedMatcherServer()
+}
+
+// UnimplementedMatcherServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedMatcherServer struct{}
+
+func (UnimplementedMatcherServer) Match(context.Context, *MatchRequest) (*MatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Match not implemented")
+}
+func (UnimplementedMatcherServer) GetCVE(context.Context, *GetCVERequest) (*CVEDetails, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCVE not implemented")
+}
+func (UnimplementedMatcherServer) StreamMatch(grpc.BidiStreamingServer[MatchRequest, MatchResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamMatch not implemented")
+}
+func (UnimplementedMatcherServer) mustEmbedUnimplementedMatcherServer() {}
+func (UnimplementedMatcherServer) testEmbeddedByValue()                 {}
+
+// UnsafeMatcherServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MatcherServer will
+// result in compilation errors.
+type UnsafeMatcherServer interface {
+	mustEmbedUnimplementedMatcherServer()
+}
+
+func RegisterMatcherServer(s grpc.ServiceRegistrar, srv MatcherServer) {
+	// If the following call pancis, it indicates UnimplementedMatcherServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Matcher_ServiceDesc, srv)
+}
+
+func _Matcher_Match_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MatcherServer).Match(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Matcher_Match_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MatcherServer).Match(ctx, req.(*MatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Matcher_GetCVE_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCVERequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MatcherServer).GetCVE(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Matcher_GetCVE_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MatcherServer).GetCVE(ctx, req.(*GetCVERequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Matcher_StreamMatch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MatcherServer).StreamMatch(&grpc.GenericServerStream[MatchRequest, MatchResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Matcher_StreamMatchServer = grpc.BidiStreamingServer[MatchRequest, MatchResponse]
+
+// Matcher_ServiceDesc is the grpc.ServiceDesc for Matcher service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Matcher_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "nvdtools.matcher.Matcher",
+	HandlerType: (*MatcherServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Match",
+			Handler:    _Matcher_Match_Handler,
+		},
+		{
+			MethodName: "GetCVE",
+			Handler:    _Matcher_GetCVE_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamMatch",
+			Handler:       _Matcher_StreamMatch_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "matcher.proto",
+}
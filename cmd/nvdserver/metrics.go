@@ -0,0 +1,61 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/metrics"
+)
+
+var (
+	matchDuration = metrics.NewHistogram(
+		"nvdtools_server_match_duration_seconds",
+		"Latency of matching CPEs against the feeds, for HTTP and gRPC requests.",
+		nil,
+	)
+	loadedVulns = metrics.NewGauge(
+		"nvdtools_server_vulnerabilities",
+		"Number of vulnerabilities in the loaded feeds.",
+	)
+	loadTime = metrics.NewGauge(
+		"nvdtools_server_feeds_load_timestamp_seconds",
+		"Unix time when the feeds were last loaded.",
+	)
+	feedsModified = metrics.NewGauge(
+		"nvdtools_server_feeds_last_modified_timestamp_seconds",
+		"Unix time of the most recently modified vulnerability in the loaded feeds, to alert on stale feeds.",
+	)
+	reloadErrors = metrics.NewCounter(
+		"nvdtools_server_reload_errors_total",
+		"Number of failed feed reloads.",
+	)
+)
+
+// recordFeedMetrics updates the metrics describing the loaded feeds
+func recordFeedMetrics(dict cvefeed.Dictionary) {
+	var latest time.Time
+	for _, vuln := range dict {
+		if modified := vuln.LastModified(); modified.After(latest) {
+			latest = modified
+		}
+	}
+	loadedVulns.Set(float64(len(dict)))
+	loadTime.Set(float64(time.Now().Unix()))
+	if !latest.IsZero() {
+		feedsModified.Set(float64(latest.Unix()))
+	}
+}
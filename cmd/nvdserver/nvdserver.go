@@ -0,0 +1,153 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// nvdserver loads vulnerability feeds once and serves CPE to CVE matching over an HTTP API
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cpedict"
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/metrics"
+	"github.com/facebookincubator/nvdtools/shutdown"
+)
+
+// shutdownTimeout is how long requests in flight are waited for when shutting down
+const shutdownTimeout = 10 * time.Second
+
+// serveGRPC serves the gRPC API on the address, it's set only if nvdserver is built with the grpc tag
+var serveGRPC func(s *server, addr string) error
+
+type config struct {
+	Addr           string
+	GRPCAddr       string
+	CPEDictionary  string
+	RequireVersion bool
+	CacheSize      int64
+	MaxCPEs        int
+	Reload         time.Duration
+}
+
+func (cfg *config) addFlags() {
+	flag.StringVar(&cfg.Addr, "addr", ":8080", "address to listen on")
+	flag.StringVar(&cfg.GRPCAddr, "grpc_addr", "", "address to serve the gRPC API on, requires nvdserver built with -tags grpc; the API is disabled if it's empty")
+	flag.StringVar(&cfg.CPEDictionary, "cpe_dict", "", "CPE dictionary (XML feed or NVD CPE API response) for /cpe/search; the endpoint is disabled without it")
+	flag.BoolVar(&cfg.RequireVersion, "require_version", false, "ignore matches of CPEs with version ANY")
+	flag.Int64Var(&cfg.CacheSize, "cache_size", 0, "limit the cache size to this amount in bytes; 0 means no limit, -1 disables caching")
+	flag.IntVar(&cfg.MaxCPEs, "max_cpes", 1000, "maximum number of CPEs in a match request; 0 means no limit")
+	flag.DurationVar(&cfg.Reload, "reload", 0, "check feeds for changes with this interval and reload them if they changed, e.g. after nvdsync; 0 disables reloading")
+}
+
+func init() {
+	logging.AddFlags()
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] nvd_feed.json.gz...\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "endpoints: POST /match, GET /cve/{id}, GET /cpe/search?q=query&limit=N\n")
+		fmt.Fprintf(os.Stderr, "flags:\n")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+}
+
+func main() {
+	var cfg config
+	cfg.addFlags()
+	metrics.AddFlags()
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+	}
+	metrics.Start()
+
+	ctx, stop := shutdown.Context(context.Background())
+	defer stop()
+
+	feeds := flag.Args()
+	watcher, err := cvefeed.NewWatcher(func() (*cvefeed.Cache, error) {
+		dict, err := cvefeed.LoadCompactJSONDictionaryContext(ctx, feeds...)
+		if err != nil {
+			return nil, err
+		}
+		logging.Infof("loaded %d vulnerabilities", len(dict))
+		recordFeedMetrics(dict)
+		return cvefeed.NewCache(dict).SetRequireVersion(cfg.RequireVersion).SetMaxSize(cfg.CacheSize).SetInvertedIndex(), nil
+	}, feeds...)
+	if err != nil {
+		logging.Errorf("failed to load feeds: %v", err)
+		os.Exit(1)
+	}
+	if err := logging.Err(); err != nil {
+		logging.Errorf("%v", err)
+		os.Exit(1)
+	}
+	if cfg.Reload > 0 {
+		go watcher.Watch(ctx, cfg.Reload, func(err error) {
+			logging.Errorf("failed to reload feeds: %v", err)
+			reloadErrors.Inc()
+		})
+	}
+
+	s := server{cache: watcher.Cache, maxCPEs: cfg.MaxCPEs}
+	if cfg.CPEDictionary != "" {
+		dict, err := cpedict.LoadContext(ctx, cfg.CPEDictionary)
+		if err != nil {
+			logging.Errorf("failed to load CPE dictionary: %v", err)
+			os.Exit(1)
+		}
+		s.cpes = cpedict.NewIndex(dict)
+	}
+
+	if cfg.GRPCAddr != "" {
+		if serveGRPC == nil {
+			logging.Errorf("nvdserver is built without gRPC support, rebuild it with -tags grpc")
+			os.Exit(1)
+		}
+		go func() {
+			logging.Infof("serving gRPC on %s", cfg.GRPCAddr)
+			if err := serveGRPC(&s, cfg.GRPCAddr); err != nil {
+				logging.Errorf("%v", err)
+				os.Exit(1)
+			}
+		}()
+	}
+
+	srv := &http.Server{Addr: cfg.Addr, Handler: s.handler()}
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		// let requests in flight finish
+		sctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		if err := srv.Shutdown(sctx); err != nil {
+			logging.Errorf("failed to shut down: %v", err)
+		}
+	}()
+
+	logging.Infof("listening on %s", cfg.Addr)
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		logging.Errorf("%v", err)
+		os.Exit(1)
+	}
+	<-stopped
+	logging.Infof("stopped serving")
+}
@@ -0,0 +1,268 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cpedict"
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// defaultSearchLimit is the number of CPE search results returned if the limit isn't set
+const defaultSearchLimit = 10
+
+// server serves the HTTP API
+type server struct {
+	// cache returns the current cache of the feeds, it may change between calls if feeds are reloaded
+	cache func() *cvefeed.Cache
+	// cpes is the CPE dictionary index for search, nil if the dictionary isn't loaded
+	cpes *cpedict.Index
+	// maxCPEs limits the number of CPEs in a match request, 0 -- unlimited
+	maxCPEs int
+}
+
+// matchRequest is the body of POST /match
+type matchRequest struct {
+	CPEs []string `json:"cpes"`
+}
+
+// vulnerability describes a vulnerability from the feeds
+type vulnerability struct {
+	ID           string   `json:"id"`
+	CVEs         []string `json:"cves"`
+	CWEs         []string `json:"cwes"`
+	CVSS2        *cvss    `json:"cvss2,omitempty"`
+	CVSS3        *cvss    `json:"cvss3,omitempty"`
+	CVSS4        *cvss    `json:"cvss4,omitempty"`
+	Published    string   `json:"published,omitempty"`
+	LastModified string   `json:"last_modified,omitempty"`
+}
+
+type cvss struct {
+	Score  float64 `json:"score"`
+	Vector string  `json:"vector"`
+}
+
+// match is a vulnerability matching some of the requested CPEs
+type match struct {
+	vulnerability
+	MatchedCPEs []string `json:"matched_cpes"`
+}
+
+// cveDetails is the response of GET /cve/{id}
+type cveDetails struct {
+	vulnerability
+	// Configuration lists CPEs from the vulnerability configuration
+	Configuration []string `json:"configuration"`
+}
+
+// cpeSuggestion is a CPE found by GET /cpe/search
+type cpeSuggestion struct {
+	CPE   string  `json:"cpe"`
+	Title string  `json:"title,omitempty"`
+	Score float64 `json:"score"`
+}
+
+// handler returns the handler of all API endpoints
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/match", s.handleMatch)
+	mux.HandleFunc("/cve/", s.handleCVE)
+	mux.HandleFunc("/cpe/search", s.handleCPESearch)
+	return mux
+}
+
+// handleMatch matches the CPEs from the request body against the feeds
+func (s *server) handleMatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	var req matchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("can't decode request: %v", err))
+		return
+	}
+	matches, err := s.match(req.CPEs)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Matches []match `json:"matches"`
+	}{matches})
+}
+
+// handleCVE returns the vulnerability with ID given in the path
+func (s *server) handleCVE(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	id := strings.TrimPrefix(r.URL.Path, "/cve/")
+	if id == "" || strings.Contains(id, "/") {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no vulnerability ID in path %q", r.URL.Path))
+		return
+	}
+	details, ok := s.cve(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("vulnerability %s not found", id))
+		return
+	}
+	writeJSON(w, http.StatusOK, details)
+}
+
+// handleCPESearch searches the CPE dictionary for the free-text query q, returning at most limit results
+func (s *server) handleCPESearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	if s.cpes == nil {
+		writeError(w, http.StatusNotImplemented, fmt.Errorf("CPE dictionary isn't loaded"))
+		return
+	}
+	query := r.URL.Query().Get("q")
+	if query == "" {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("query is empty"))
+		return
+	}
+	limit := defaultSearchLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("bad limit %q", l))
+			return
+		}
+		limit = n
+	}
+
+	results := []cpeSuggestion{}
+	for _, sug := range s.cpes.Search(query, limit) {
+		results = append(results, cpeSuggestion{
+			CPE:   wfn.Attributes(sug.Item.Name).BindToFmtString(),
+			Title: sug.Item.Title["en"],
+			Score: sug.Score,
+		})
+	}
+	writeJSON(w, http.StatusOK, struct {
+		Results []cpeSuggestion `json:"results"`
+	}{results})
+}
+
+// match matches the CPEs, which are treated as a single asset, against the feeds
+// and returns the matching vulnerabilities sorted by ID
+func (s *server) match(cpes []string) ([]match, error) {
+	defer func(start time.Time) {
+		matchDuration.Observe(time.Since(start).Seconds())
+	}(time.Now())
+	if len(cpes) == 0 {
+		return nil, fmt.Errorf("no CPEs to match")
+	}
+	if s.maxCPEs > 0 && len(cpes) > s.maxCPEs {
+		return nil, fmt.Errorf("too many CPEs: %d, at most %d are allowed", len(cpes), s.maxCPEs)
+	}
+	attrs := make([]*wfn.Attributes, len(cpes))
+	for i, uri := range cpes {
+		attr, err := wfn.Parse(uri)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse CPE %q: %v", uri, err)
+		}
+		attrs[i] = attr
+	}
+
+	matches := []match{}
+	for _, result := range s.cache().Get(attrs) {
+		m := match{vulnerability: newVulnerability(result.CVE), MatchedCPEs: []string{}}
+		for _, attr := range result.CPEs {
+			if attr != nil {
+				m.MatchedCPEs = append(m.MatchedCPEs, attr.BindToFmtString())
+			}
+		}
+		sort.Strings(m.MatchedCPEs)
+		matches = append(matches, m)
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].ID < matches[j].ID })
+	return matches, nil
+}
+
+// cve returns the vulnerability with the ID, false if there's no such vulnerability in the feeds
+func (s *server) cve(id string) (*cveDetails, bool) {
+	vuln, ok := s.cache().Dict[id]
+	if !ok {
+		return nil, false
+	}
+	details := cveDetails{vulnerability: newVulnerability(vuln), Configuration: []string{}}
+	for _, attr := range vuln.Config() {
+		if attr != nil {
+			details.Configuration = append(details.Configuration, attr.BindToFmtString())
+		}
+	}
+	return &details, true
+}
+
+func newVulnerability(vuln cvefeed.Vuln) vulnerability {
+	v := vulnerability{
+		ID:           vuln.ID(),
+		CVEs:         nonNil(vuln.CVEs()),
+		CWEs:         nonNil(vuln.CWEs()),
+		Published:    formatTime(vuln.Published()),
+		LastModified: formatTime(vuln.LastModified()),
+	}
+	if vector := vuln.CVSSv2Vector(); vector != "" {
+		v.CVSS2 = &cvss{Score: vuln.CVSSv2BaseScore(), Vector: vector}
+	}
+	if vector := vuln.CVSSv3Vector(); vector != "" {
+		v.CVSS3 = &cvss{Score: vuln.CVSSv3BaseScore(), Vector: vector}
+	}
+	if vector := vuln.CVSSv4Vector(); vector != "" {
+		v.CVSS4 = &cvss{Score: vuln.CVSSv4BaseScore(), Vector: vector}
+	}
+	return v
+}
+
+func nonNil(ss []string) []string {
+	if ss == nil {
+		return []string{}
+	}
+	return ss
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, struct {
+		Error string `json:"error"`
+	}{err.Error()})
+}
@@ -0,0 +1,204 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cpedict"
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const testFeed = `{"CVE_Items":[
+{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"CVE-2021-33203"},
+  "problemtype":{"problemtype_data":[{"description":[{"lang":"en","value":"CWE-22"}]}]}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[{"operator":"OR","cpe_match":[{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:djangoproject:django:*:*:*:*:*:*:*:*","versionStartIncluding":"3.2","versionEndExcluding":"3.2.4"}]}]},
+ "impact":{"baseMetricV3":{"cvssV3":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:N/A:N","baseScore":4.9}}},
+ "publishedDate":"2021-06-08T18:15Z","lastModifiedDate":"2021-06-15T12:49Z"},
+{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"CVE-2021-31542"}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[{"operator":"OR","cpe_match":[{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:djangoproject:django:*:*:*:*:*:*:*:*","versionStartIncluding":"3.2","versionEndExcluding":"3.2.1"}]}]}}
+]}`
+
+func testServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(newTestServer(t).handler())
+}
+
+// newTestServer creates a server with the test feed and a dictionary containing django 3.2
+func newTestServer(t *testing.T) *server {
+	vulns, err := cvefeed.ParseCompactJSON(strings.NewReader(testFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dict := make(cvefeed.Dictionary)
+	for _, v := range vulns {
+		dict[v.ID()] = v
+	}
+	cache := cvefeed.NewCache(dict).SetInvertedIndex()
+
+	name, err := wfn.Parse("cpe:2.3:a:djangoproject:django:3.2:*:*:*:*:*:*:*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cpes := &cpedict.CPEList{Items: []cpedict.CPEItem{
+		{Name: cpedict.NamePattern(*name), Title: cpedict.TextType{"en": "Django Project Django 3.2"}},
+	}}
+
+	s := server{
+		cache:   func() *cvefeed.Cache { return cache },
+		cpes:    cpedict.NewIndex(cpes),
+		maxCPEs: 2,
+	}
+	return &s
+}
+
+func TestServer(t *testing.T) {
+	ts := testServer(t)
+	defer ts.Close()
+
+	cases := []struct {
+		method, path, body string
+		status             int
+		check              func(t *testing.T, resp map[string]interface{})
+	}{
+		{
+			method: "POST", path: "/match", body: `{"cpes": ["cpe:2.3:a:djangoproject:django:3.2.0:*:*:*:*:*:*:*"]}`,
+			status: http.StatusOK,
+			check: func(t *testing.T, resp map[string]interface{}) {
+				matches := resp["matches"].([]interface{})
+				if len(matches) != 2 {
+					t.Fatalf("expecting 2 matches, got %v", matches)
+				}
+				m := matches[1].(map[string]interface{})
+				if m["id"] != "CVE-2021-33203" {
+					t.Fatalf("matches aren't sorted: %v", matches)
+				}
+				want := []interface{}{"cpe:2.3:a:djangoproject:django:3.2.0:*:*:*:*:*:*:*"}
+				if !reflect.DeepEqual(m["matched_cpes"], want) {
+					t.Fatalf("expecting matched CPEs %v, got %v", want, m["matched_cpes"])
+				}
+				if cvss3 := m["cvss3"].(map[string]interface{}); cvss3["score"] != 4.9 {
+					t.Fatalf("unexpected CVSS v3: %v", cvss3)
+				}
+				if m["published"] != "2021-06-08T18:15:00Z" {
+					t.Fatalf("unexpected published date %v", m["published"])
+				}
+			},
+		},
+		{
+			method: "POST", path: "/match", body: `{"cpes": ["cpe:2.3:a:djangoproject:django:3.2.2:*:*:*:*:*:*:*"]}`,
+			status: http.StatusOK,
+			check: func(t *testing.T, resp map[string]interface{}) {
+				if matches := resp["matches"].([]interface{}); len(matches) != 1 {
+					t.Fatalf("expecting 1 match, got %v", matches)
+				}
+			},
+		},
+		{
+			method: "POST", path: "/match", body: `{"cpes": ["cpe:2.3:a:python:python:3.9:*:*:*:*:*:*:*"]}`,
+			status: http.StatusOK,
+			check: func(t *testing.T, resp map[string]interface{}) {
+				if matches := resp["matches"].([]interface{}); len(matches) != 0 {
+					t.Fatalf("expecting no matches, got %v", matches)
+				}
+			},
+		},
+		{method: "POST", path: "/match", body: `{"cpes": []}`, status: http.StatusBadRequest},
+		{method: "POST", path: "/match", body: `{"cpes": ["a", "b", "c"]}`, status: http.StatusBadRequest},
+		{method: "POST", path: "/match", body: `{"cpes": ["django 3.2"]}`, status: http.StatusBadRequest},
+		{method: "POST", path: "/match", body: `not json`, status: http.StatusBadRequest},
+		{method: "GET", path: "/match", status: http.StatusMethodNotAllowed},
+		{
+			method: "GET", path: "/cve/CVE-2021-33203",
+			status: http.StatusOK,
+			check: func(t *testing.T, resp map[string]interface{}) {
+				if resp["id"] != "CVE-2021-33203" {
+					t.Fatalf("unexpected vulnerability %v", resp["id"])
+				}
+				if want := []interface{}{"CWE-22"}; !reflect.DeepEqual(resp["cwes"], want) {
+					t.Fatalf("expecting CWEs %v, got %v", want, resp["cwes"])
+				}
+				want := []interface{}{"cpe:2.3:a:djangoproject:django:*:*:*:*:*:*:*:*"}
+				if !reflect.DeepEqual(resp["configuration"], want) {
+					t.Fatalf("expecting configuration %v, got %v", want, resp["configuration"])
+				}
+			},
+		},
+		{method: "GET", path: "/cve/CVE-2000-0001", status: http.StatusNotFound},
+		{method: "GET", path: "/cve/", status: http.StatusNotFound},
+		{
+			method: "GET", path: "/cpe/search?q=django+3.2",
+			status: http.StatusOK,
+			check: func(t *testing.T, resp map[string]interface{}) {
+				results := resp["results"].([]interface{})
+				if len(results) != 1 {
+					t.Fatalf("expecting 1 result, got %v", results)
+				}
+				r := results[0].(map[string]interface{})
+				if r["cpe"] != "cpe:2.3:a:djangoproject:django:3.2:*:*:*:*:*:*:*" || r["title"] != "Django Project Django 3.2" {
+					t.Fatalf("unexpected result %v", r)
+				}
+			},
+		},
+		{method: "GET", path: "/cpe/search", status: http.StatusBadRequest},
+		{method: "GET", path: "/cpe/search?q=django&limit=x", status: http.StatusBadRequest},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			req, err := http.NewRequest(c.method, ts.URL+c.path, strings.NewReader(c.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer res.Body.Close()
+			if res.StatusCode != c.status {
+				t.Fatalf("expecting status %d, got %d", c.status, res.StatusCode)
+			}
+			if ct := res.Header.Get("Content-Type"); ct != "application/json" {
+				t.Fatalf("unexpected content type %q", ct)
+			}
+			var resp map[string]interface{}
+			if err := json.NewDecoder(res.Body).Decode(&resp); err != nil {
+				t.Fatal(err)
+			}
+			if c.status != http.StatusOK {
+				if resp["error"] == nil {
+					t.Fatalf("expecting an error, got %v", resp)
+				}
+				return
+			}
+			c.check(t, resp)
+		})
+	}
+}
+
+func TestServerWithoutDictionary(t *testing.T) {
+	s := server{cache: func() *cvefeed.Cache { return cvefeed.NewCache(cvefeed.Dictionary{}) }}
+	w := httptest.NewRecorder()
+	s.handler().ServeHTTP(w, httptest.NewRequest("GET", "/cpe/search?q=django", nil))
+	if w.Code != http.StatusNotImplemented {
+		t.Fatalf("expecting status %d, got %d", http.StatusNotImplemented, w.Code)
+	}
+}
@@ -19,37 +19,55 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"time"
 
-	"github.com/facebookincubator/flog"
+	"github.com/facebookincubator/nvdtools/compress"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/metrics"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
 	"github.com/facebookincubator/nvdtools/providers/nvd"
+	"github.com/facebookincubator/nvdtools/shutdown"
+	"github.com/facebookincubator/nvdtools/storage"
 )
 
 func init() {
-	flog.AddFlags(flag.CommandLine, nil)
-	flag.Set("logtostderr", "true")
+	logging.AddFlags()
 }
 
 func main() {
 	var (
 		cvefeed   nvd.CVE
 		cpefeed   nvd.CPE
+		cpematch  nvd.CPEMatch
+		cveAPI    bool
+		cpeAPI    bool
+		delta     bool
 		timeout   time.Duration
 		userAgent string
+		transport client.Transport
 		source    = nvd.NewSourceConfig()
+		format    = compress.Gzip
 	)
 
 	flag.Var(&cvefeed, "cve_feed", cvefeed.Help())
 	flag.Var(&cpefeed, "cpe_feed", cpefeed.Help())
+	flag.Var(&cpematch, "cpematch_feed", cpematch.Help())
+	flag.BoolVar(&cveAPI, "cve_api", false, "mirror CVEs from NVD CVE API 2.0 instead of syncing the CVE feed; needs a longer -timeout for the first sync")
+	flag.BoolVar(&cpeAPI, "cpe_api", false, "mirror CPEs from NVD CPE API 2.0 instead of syncing the CPE feed; needs a longer -timeout for the first sync")
+	flag.BoolVar(&delta, "delta", false, "after syncing, write CVEs added or modified since the previous sync to nvdcve-{version}-delta-{time}.json.gz; JSON CVE feeds and -cve_api only")
+	flag.Var(&format, "compress", "compression of files written with -cve_api, -cpe_api and -delta: gzip, zstd or xz; mirror files compressed differently are recompressed. zstd and xz need the zstd and xz commands")
 	flag.DurationVar(&timeout, "timeout", 5*time.Minute, "sync timeout")
 	flag.StringVar(&userAgent, "user_agent", nvd.UserAgent(), "HTTP request User-Agent header")
 	source.AddFlags(flag.CommandLine)
+	transport.AddFlags(flag.CommandLine)
+	metrics.AddFlags()
 
 	flag.Usage = func() {
 		fmt.Printf("nvdsync %s\n\n", nvd.Version)
-		fmt.Printf("use: %s [flags] dir\n", os.Args[0])
-		fmt.Printf("Synchronizes NVD data feeds to local directory.\n\n")
+		fmt.Printf("use: %s [flags] dir|url\n", os.Args[0])
+		fmt.Printf("Synchronizes NVD data feeds or APIs to local directory, or object store bucket (s3://, gs:// or azblob:// url).\n\n")
 		fmt.Printf("Flags:\n\n")
 		flag.PrintDefaults()
 		os.Exit(1)
@@ -61,24 +79,66 @@ func main() {
 	if localdir == "" {
 		flag.Usage()
 	}
+	metrics.Start()
 
 	// determine User-Agent header
 	// check if it's only ascii characters
 	if err := nvd.SetUserAgent(userAgent); err != nil {
-		flog.Warningf("could not set User-Agent HTTP header, using default: %v", err)
+		logging.Warningf("could not set User-Agent HTTP header, using default: %v", err)
+	}
+	logging.Infof("Using http User-Agent: %s", nvd.UserAgent())
+	if err := transport.Install(); err != nil {
+		logging.Errorf("can't configure http client: %v", err)
+		os.Exit(1)
+	}
+
+	feeds := []nvd.Syncer{cvefeed, cpefeed}
+	if cveAPI {
+		feeds[0] = nvd.CVEAPI{Compression: format}
+	}
+	if cpeAPI {
+		feeds[1] = nvd.CPEAPI{Compression: format}
+	}
+	feeds = append(feeds, cpematch)
+	if delta {
+		// after the CVE feed was synced
+		feeds = append(feeds, nvd.Delta{Feed: cvefeed, API: cveAPI, Compression: format})
 	}
-	flog.Infof("Using http User-Agent: %s", nvd.UserAgent())
 
 	dfs := nvd.Sync{
-		Feeds:    []nvd.Syncer{cvefeed, cpefeed},
+		Feeds:    feeds,
 		Source:   source,
 		LocalDir: localdir,
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	if storage.IsURL(localdir) {
+		store, err := storage.New(localdir)
+		if err != nil {
+			logging.Errorf("%v", err)
+			os.Exit(1)
+		}
+		// feeds are staged in a temporary directory
+		if dfs.LocalDir, err = ioutil.TempDir("", "nvdsync"); err != nil {
+			logging.Errorf("%v", err)
+			os.Exit(1)
+		}
+		dfs.Store = store
+	}
+
+	ctx, stop := shutdown.Context(context.Background())
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	if err := dfs.Do(ctx); err != nil {
-		flog.Fatal(err)
+	err := dfs.Do(ctx)
+	if dfs.Store != nil {
+		os.RemoveAll(dfs.LocalDir)
+	}
+	if err := metrics.Flush(); err != nil {
+		logging.Errorf("can't write metrics: %v", err)
+	}
+	if err != nil {
+		logging.Errorf("%v", err)
+		os.Exit(1)
 	}
 }
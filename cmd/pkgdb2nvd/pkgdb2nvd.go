@@ -0,0 +1,27 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"flag"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+	"github.com/facebookincubator/nvdtools/providers/pkgdb/api"
+)
+
+func main() {
+	flag.StringVar(&api.Ecosystems, "ecosystems", api.Ecosystems, "Comma separated list of OSV ecosystems to download")
+	runner.Main("pkgdb")
+}
@@ -15,41 +15,127 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
-	"log"
 	"os"
+	"strings"
 
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/csaf"
 	"github.com/facebookincubator/nvdtools/providers/lib/client"
 	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+	"github.com/facebookincubator/nvdtools/providers/redhat"
 	"github.com/facebookincubator/nvdtools/providers/redhat/api"
 	"github.com/facebookincubator/nvdtools/providers/redhat/schema"
 )
 
+const defaultBaseURL = "https://access.redhat.com/labs/securitydataapi"
+
+var (
+	useCSAF     = flag.Bool("csaf", false, "Download CSAF VEX documents instead of the legacy CVE format")
+	workers     = flag.Int("workers", api.DefaultWorkers, "How many documents to download concurrently. Use -requests-per-period to limit the request rate")
+	cacheDir    = flag.String("cache_dir", "", "Directory to store downloaded documents in. Documents already in it aren't downloaded again, so an interrupted download can be resumed. They are removed once a download finishes without errors")
+	ovalStreams = flag.String("oval", "", "Comma separated list of OVAL v2 streams to fetch, e.g. RHEL8/rhel-8.oval.xml.bz2. "+
+		"Streams are urls or paths relative to "+api.DefaultOVALBaseURL+". Their fixed versions replace the ones from the API")
+	ovalOnly = flag.Bool("oval-only", false, "Only use OVAL streams set with -oval, don't fetch anything from the API")
+)
+
 func Read(r io.Reader, c chan runner.Convertible) error {
-	var vulns map[string]*schema.CVE
+	var vulns map[string]json.RawMessage
 	if err := json.NewDecoder(r).Decode(&vulns); err != nil {
 		return fmt.Errorf("can't decode into vulns: %v", err)
 	}
 
-	for _, vuln := range vulns {
-		c <- vuln
+	for id, data := range vulns {
+		if csaf.IsDocument(data) {
+			vex, err := schema.ReadVEX(bytes.NewReader(data))
+			if err != nil {
+				return fmt.Errorf("can't decode vuln %q: %v", id, err)
+			}
+			c <- vex
+			continue
+		}
+		var cve schema.CVE
+		if err := json.Unmarshal(data, &cve); err != nil {
+			return fmt.Errorf("can't decode vuln %q: %v", id, err)
+		}
+		c <- &cve
 	}
 
 	return nil
 }
 
 func FetchSince(ctx context.Context, c client.Client, baseURL string, since int64) (<-chan runner.Convertible, error) {
+	if *useCSAF && baseURL == defaultBaseURL {
+		baseURL = api.DefaultVEXBaseURL
+	}
+
 	client := api.NewClient(c, baseURL)
-	return client.FetchAllCVEs(ctx, since)
+	client.SetWorkers(*workers)
+	if *cacheDir != "" {
+		if err := os.MkdirAll(*cacheDir, 0755); err != nil {
+			return nil, fmt.Errorf("can't create cache dir: %v", err)
+		}
+		client.SetCacheDir(*cacheDir)
+	}
+
+	if *ovalStreams == "" {
+		if *ovalOnly {
+			return nil, fmt.Errorf("-oval-only requires OVAL streams to be set with -oval")
+		}
+		if *useCSAF {
+			return client.FetchAllVEX(ctx, since)
+		}
+		return client.FetchAllCVEs(ctx, since)
+	}
+
+	if *useCSAF && !*ovalOnly {
+		return nil, fmt.Errorf("OVAL streams can't be merged with CSAF VEX documents, use -oval-only to skip the API")
+	}
+	oval, err := client.FetchOVAL(ctx, strings.Split(*ovalStreams, ","), since)
+	if err != nil {
+		return nil, err
+	}
+	if *ovalOnly {
+		return mergeOVAL(nil, oval), nil
+	}
+	vulns, err := client.FetchAllCVEs(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	return mergeOVAL(vulns, oval), nil
+}
+
+// mergeOVAL waits for all CVEs fetched from the API and merges CVEs from OVAL streams into them
+// CVEs which are only found in OVAL streams are added as they are
+func mergeOVAL(vulns <-chan runner.Convertible, oval map[string]*schema.CVE) <-chan runner.Convertible {
+	output := make(chan runner.Convertible)
+	go func() {
+		defer close(output)
+		feed := make(redhat.Feed)
+		if vulns != nil {
+			for vuln := range vulns {
+				if cve, ok := vuln.(*schema.CVE); ok {
+					feed[cve.Name] = cve
+				}
+			}
+		}
+		feed.Merge(oval)
+		for _, cve := range feed {
+			output <- cve
+		}
+	}()
+	return output
 }
 
 func main() {
 	r := runner.Runner{
 		Config: runner.Config{
-			BaseURL: "https://access.redhat.com/labs/securitydataapi",
+			BaseURL: defaultBaseURL,
 			ClientConfig: client.Config{
 				UserAgent: "redhat2nvd",
 			},
@@ -59,7 +145,7 @@ func main() {
 	}
 
 	if err := r.Run(); err != nil {
-		log.Println(err)
+		logging.Errorf("%v", err)
 		os.Exit(1)
 	}
 }
@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"encoding/csv"
 	"flag"
 	"fmt"
@@ -22,31 +23,33 @@ import (
 	"os"
 	"strings"
 
-	"github.com/facebookincubator/flog"
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/providers/redhat"
 	"github.com/facebookincubator/nvdtools/rpm"
+	"github.com/facebookincubator/nvdtools/shutdown"
 )
 
 func main() {
 	var cfg config
 	cfg.addFlags()
-	flog.AddFlags(flag.CommandLine, nil)
+	logging.AddFlags()
 	flag.Parse()
 
 	if err := cfg.validate(); err != nil {
-		flog.Fatal(err)
+		logging.Errorf("%v", err)
+		os.Exit(1)
 	}
-	if flag.NArg() != 1 {
-		flog.Fatalf("expecting one argument: feed path. got %d", flag.NArg())
-	}
-
-	feed, err := redhat.LoadFeed(flag.Arg(0))
+	ctx, stop := shutdown.Context(context.Background())
+	defer stop()
+	feed, err := loadFeed(ctx, &cfg, flag.Args())
 	if err != nil {
-		flog.Fatal(err)
+		logging.Errorf("%v", err)
+		os.Exit(1)
 	}
 	chk, err := feed.Checker()
 	if err != nil {
-		flog.Fatal(err)
+		logging.Errorf("%v", err)
+		os.Exit(1)
 	}
 
 	// adjust indexes
@@ -54,16 +57,52 @@ func main() {
 	cfg.distro--
 	cfg.cve--
 
-	if err := filter(chk, &cfg, os.Stdin, os.Stdout); err != nil {
-		flog.Fatal(err)
+	if err := filter(ctx, chk, &cfg, os.Stdin, os.Stdout); err != nil {
+		logging.Errorf("%v", err)
+		os.Exit(1)
 	}
 }
 
-func filter(chk rpm.Checker, cfg *config, r io.Reader, w io.Writer) error {
+// loadFeed loads the feed from the given path and merges OVAL streams into it
+// feed path can be omitted if OVAL streams are set, they're used instead
+// loading stops between the files when the context is done
+func loadFeed(ctx context.Context, cfg *config, args []string) (redhat.Feed, error) {
+	if len(args) > 1 || (len(args) == 0 && cfg.oval == "") {
+		return nil, fmt.Errorf("expecting one argument: feed path. got %d", len(args))
+	}
+	feed := make(redhat.Feed)
+	if len(args) == 1 {
+		var err error
+		if feed, err = redhat.LoadFeed(args[0]); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.oval == "" {
+		return feed, nil
+	}
+	for _, path := range strings.Split(cfg.oval, ",") {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		oval, err := redhat.LoadOVAL(path)
+		if err != nil {
+			return nil, err
+		}
+		feed.Merge(oval)
+	}
+	return feed, nil
+}
+
+// filter writes rows as they're filtered, it stops when the context is done
+func filter(ctx context.Context, chk rpm.Checker, cfg *config, r io.Reader, w io.Writer) error {
 	cr := csv.NewReader(r)
 	cw := csv.NewWriter(w)
 
 	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		// read
 		row, err := cr.Read()
 		if err == io.EOF {
@@ -100,6 +139,7 @@ func filter(chk rpm.Checker, cfg *config, r io.Reader, w io.Writer) error {
 type config struct {
 	pkgs, distro, cve int
 	pkgsSep           string
+	oval              string
 }
 
 func (cfg *config) addFlags() {
@@ -107,11 +147,13 @@ func (cfg *config) addFlags() {
 	flag.IntVar(&cfg.distro, "distro", 0, "csv field which holds the distribution CPE. starts with 1")
 	flag.IntVar(&cfg.cve, "cve", 0, "csv field which holds the CVE. starts with 1")
 	flag.StringVar(&cfg.pkgsSep, "pkgs-sep", "\x02", "separator to use for the packages field")
+	flag.StringVar(&cfg.oval, "oval", "", "comma separated list of OVAL v2 stream files, their fixed versions replace the ones from the feed. feed path is optional if it's set")
 }
 
 func (cfg *config) validate() error {
 	if cfg.pkgs <= 0 || cfg.distro <= 0 || cfg.cve <= 0 {
-		flog.Fatalf("indexes must be postive: distro=%d pkgs=%d cve=%d", cfg.distro, cfg.pkgs, cfg.cve)
+		logging.Errorf("indexes must be postive: distro=%d pkgs=%d cve=%d", cfg.distro, cfg.pkgs, cfg.cve)
+		os.Exit(1)
 	}
 	return nil
 }
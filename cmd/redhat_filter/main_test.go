@@ -15,6 +15,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"strings"
@@ -77,7 +78,7 @@ func TestFilter(t *testing.T) {
 			r := strings.NewReader(strings.Join(tc.in, "\n"))
 			var w strings.Builder
 
-			if err := filter(chk, &cfg, r, &w); err != nil {
+			if err := filter(context.Background(), chk, &cfg, r, &w); err != nil {
 				if !tc.fail {
 					t.Fatalf("shouldn't have failed for %v, but did: %v", tc.in, err)
 				}
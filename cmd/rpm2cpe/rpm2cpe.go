@@ -16,6 +16,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/csv"
 	"flag"
 	"fmt"
@@ -28,6 +29,7 @@ import (
 	"github.com/facebookincubator/nvdtools/wfn"
 
 	"github.com/facebookincubator/nvdtools/rpm"
+	"github.com/facebookincubator/nvdtools/shutdown"
 )
 
 var progname = path.Base(os.Args[0])
@@ -83,6 +85,9 @@ type config struct {
 	outFieldSep string
 	skip        fieldsToSkip
 	defaultNA   bool
+	targetHW    bool
+	targetSW    bool
+	distField   int
 }
 
 func (c *config) addFlags() {
@@ -93,6 +98,10 @@ func (c *config) addFlags() {
 	flag.Var(&c.skip, "e", "optional comma-separated list of input fields that should be dropped from output (starts with 1) "+
 		"rpm name is extracted before dropping fields, CPE is added after that")
 	flag.BoolVar(&c.defaultNA, "na", false, "if set, unknown CPE attributes are set to N/A, otherwise to ANY")
+	flag.BoolVar(&c.targetHW, "target_hw", false, "if set, RPM arch is mapped to CPE target_hw names, e.g. x86_64 to x64 and aarch64 to arm64")
+	flag.BoolVar(&c.targetSW, "target_sw", false, "if set, CPE target_sw is set to the distribution found in the dist tag of the release, e.g. rhel_8 for el8")
+	flag.IntVar(&c.distField, "dist", 0, "optional position of the field in the output to put the distribution found in the dist tag at (starts at 1), "+
+		"it's added after CPE")
 }
 
 func sayErr(status int, msg string, args ...interface{}) {
@@ -137,28 +146,57 @@ func processRecord(fields []string, cfg config) ([]string, error) {
 		attr = wfn.NewAttributesWithAny()
 	}
 	attr.Vendor = wfn.Any
-	if err := rpm.ToWFN(attr, fields[cfg.rpmField-1]); err != nil {
-		return nil, fmt.Errorf("couldn't parse RPM name from field %q: %v", fields[cfg.rpmField-1], err)
+	name := fields[cfg.rpmField-1]
+	if err := rpm.ToWFN(attr, name); err != nil {
+		return nil, fmt.Errorf("couldn't parse RPM name from field %q: %v", name, err)
+	}
+	pkg, err := rpm.Parse(name)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse RPM name from field %q: %v", name, err)
+	}
+	if cfg.targetHW && pkg.Arch != "" {
+		if attr.TargetHW, err = wfn.WFNize(rpm.ArchToTargetHW(pkg.Arch)); err != nil {
+			return nil, fmt.Errorf("couldn't wfnize arch %q: %v", pkg.Arch, err)
+		}
+	}
+	var distro string
+	if dist := rpm.ParseDist(pkg.Label.Release); dist != nil {
+		distro = dist.TargetSW()
+	}
+	if cfg.targetSW && distro != "" {
+		if attr.TargetSW, err = wfn.WFNize(distro); err != nil {
+			return nil, fmt.Errorf("couldn't wfnize distribution %q: %v", distro, err)
+		}
 	}
-	cpe := attr.BindToURI()
 	fields = cfg.skip.skipFields(fields)
-	if cfg.cpeField > len(fields) {
-		// if cfg.cpeField > len(fields)+1 we ignore that silently and just add CPE as the last field
-		return append(fields, cpe), nil
+	fields = insertField(fields, cfg.cpeField, attr.BindToURI())
+	if cfg.distField != 0 {
+		fields = insertField(fields, cfg.distField, distro)
+	}
+	return fields, nil
+}
+
+// insertField inserts value into fields at the given position (starts at 1)
+// if pos > len(fields)+1 we ignore that silently and just add value as the last field
+func insertField(fields []string, pos int, value string) []string {
+	if pos > len(fields) {
+		return append(fields, value)
 	}
 	outFields := make([]string, 0, len(fields)+1)
-	outFields = append(outFields, fields[:cfg.cpeField-1]...)
-	outFields = append(outFields, cpe)
-	outFields = append(outFields, fields[cfg.cpeField-1:]...)
-	return outFields, nil
+	outFields = append(outFields, fields[:pos-1]...)
+	outFields = append(outFields, value)
+	outFields = append(outFields, fields[pos-1:]...)
+	return outFields
 }
 
-func rpmname2cpe(in io.Reader, out io.Writer, cfg config) {
+// rpmname2cpe adds CPE names to the records from in and writes them to out;
+// when the context is done, it stops after writing the records processed so far
+func rpmname2cpe(ctx context.Context, in io.Reader, out io.Writer, cfg config) {
 	r := csv.NewReader(in)
 	r.Comma = rune(cfg.inFieldSep[0])
 	w := csv.NewWriter(out)
 	w.Comma = rune(cfg.outFieldSep[0])
-	for {
+	for ctx.Err() == nil {
 		inRec, err := r.Read()
 		if err != nil {
 			if err == io.EOF {
@@ -179,6 +217,9 @@ func rpmname2cpe(in io.Reader, out io.Writer, cfg config) {
 	if err := w.Error(); err != nil {
 		sayErr(-1, "write error: %v", err)
 	}
+	if err := ctx.Err(); err != nil {
+		sayErr(-1, "%v", err)
+	}
 }
 
 func main() {
@@ -188,5 +229,7 @@ func main() {
 	if cfg.rpmField == 0 || cfg.cpeField == 0 {
 		flag.Usage()
 	}
-	rpmname2cpe(os.Stdin, os.Stdout, cfg)
+	ctx, stop := shutdown.Context(context.Background())
+	defer stop()
+	rpmname2cpe(ctx, os.Stdin, os.Stdout, cfg)
 }
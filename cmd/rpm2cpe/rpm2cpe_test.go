@@ -15,6 +15,7 @@
 package main
 
 import (
+	"fmt"
 	"reflect"
 	"strings"
 	"testing"
@@ -92,3 +93,55 @@ func TestProcessRecord(t *testing.T) {
 		}
 	}
 }
+
+func TestProcessRecordDist(t *testing.T) {
+	cases := []struct {
+		in, out  string
+		targetHW bool
+		targetSW bool
+	}{
+		{
+			in:  "openssl-1.1.1k-9.el8_7.x86_64.rpm,a",
+			out: "cpe:/a::openssl:1.1.1k:9.el8_7:~~~~x86_64~;rhel_8;a",
+		},
+		{
+			in:       "openssl-1.1.1k-9.el8_7.x86_64.rpm,a",
+			out:      "cpe:/a::openssl:1.1.1k:9.el8_7:~~~rhel_8~x64~;rhel_8;a",
+			targetHW: true,
+			targetSW: true,
+		},
+		{
+			in:       "bash-5.2.15-3.fc38.aarch64.rpm,a",
+			out:      "cpe:/a::bash:5.2.15:3.fc38:~~~fedora_38~arm64~;fedora_38;a",
+			targetHW: true,
+			targetSW: true,
+		},
+		{
+			in:       "bash-4.4-1.noarch.rpm,a",
+			out:      "cpe:/a::bash:4.4:1;;a",
+			targetHW: true,
+			targetSW: true,
+		},
+	}
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			cfg := config{
+				rpmField:    1,
+				cpeField:    1,
+				distField:   2,
+				inFieldSep:  ",",
+				outFieldSep: ";",
+				skip:        fieldsToSkip(map[int]struct{}{0: {}}),
+				targetHW:    c.targetHW,
+				targetSW:    c.targetSW,
+			}
+			record, err := processRecord(strings.Split(c.in, cfg.inFieldSep), cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out := strings.Join(record, cfg.outFieldSep); out != c.out {
+				t.Fatalf("line %q:\nhave: %q\nwant: %q", c.in, out, c.out)
+			}
+		})
+	}
+}
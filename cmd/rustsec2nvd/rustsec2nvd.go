@@ -15,35 +15,62 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 
-	"github.com/facebookincubator/flog"
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/providers/rustsec"
+	"github.com/facebookincubator/nvdtools/shutdown"
+)
+
+var (
+	sync = flag.Bool("git", false, "clone the advisory-db git repository into the given directory, or pull it if it's already there, before converting")
+	repo = flag.String("repo", rustsec.AdvisoryDBURL, "url of the advisory-db git repository used with -git")
 )
 
 func init() {
-	flog.AddFlags(flag.CommandLine, nil)
+	logging.AddFlags()
 }
 
 func main() {
-	if len(os.Args) != 2 {
-		fmt.Println("Usage: rustsec2nvd <rustsec-crates-dir>")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Println("Usage: rustsec2nvd [-git] <rustsec-crates-dir>")
 		fmt.Println("Example:")
 		fmt.Println("git clone https://github.com/RustSec/advisory-db")
 		fmt.Println("rustsec2nvd advisory-db/crates > rustsec.cve.json")
+		fmt.Println("or, to clone or update the repository directly:")
+		fmt.Println("rustsec2nvd -git advisory-db > rustsec.cve.json")
 		os.Exit(1)
 	}
 
-	feed, err := rustsec.Convert(os.Args[1])
+	dir := flag.Arg(0)
+	if *sync {
+		ctx, stop := shutdown.Context(context.Background())
+		err := rustsec.Sync(ctx, dir, *repo)
+		stop()
+		if err != nil {
+			logging.Errorf("%v", err)
+			os.Exit(1)
+		}
+	}
+
+	feed, err := rustsec.Convert(dir)
 	if err != nil {
-		flog.Fatal(err)
+		logging.Errorf("%v", err)
+		os.Exit(1)
+	}
+	if err := logging.Err(); err != nil {
+		logging.Errorf("%v", err)
+		os.Exit(1)
 	}
 
 	err = json.NewEncoder(os.Stdout).Encode(feed)
 	if err != nil {
-		flog.Fatal(err)
+		logging.Errorf("%v", err)
+		os.Exit(1)
 	}
 }
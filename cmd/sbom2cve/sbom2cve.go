@@ -0,0 +1,156 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// sbom2cve matches components or packages of an SBOM against vulnerability feeds
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/purl"
+	"github.com/facebookincubator/nvdtools/sbom"
+	"github.com/facebookincubator/nvdtools/shutdown"
+)
+
+type config struct {
+	Format             string
+	OutFieldSeparator  string
+	OutRecordSeparator string
+	RequireVersion     bool
+	PURLMapping        string
+
+	// built-in purl mapping extended with PURLMapping
+	mapping *purl.Mapping
+}
+
+func (cfg *config) addFlags() {
+	flag.StringVar(&cfg.Format, "format", "auto", "format of the SBOM: cyclonedx (JSON or XML), spdx (2.3 JSON or tag-value, 3.0 JSON-LD) or auto to detect it")
+	flag.StringVar(&cfg.OutFieldSeparator, "o", "\t", "output columns delimiter")
+	flag.StringVar(&cfg.OutRecordSeparator, "o2", ",", "inner output columns delimiter: separates elements of lists in output CSV columns")
+	flag.BoolVar(&cfg.RequireVersion, "require_version", false, "ignore matches of components without version")
+	flag.StringVar(&cfg.PURLMapping, "purl_map", "", "file with additional package url to CPE translations, a package url and a CPE per line, e.g. pkg:pypi/django cpe:/a:djangoproject:django")
+}
+
+// readComponents reads components from the SBOM in the configured format
+func (cfg *config) readComponents(r io.Reader) ([]*sbom.Component, error) {
+	switch cfg.Format {
+	case "auto":
+		return sbom.Read(r)
+	case "cyclonedx":
+		return sbom.ReadCycloneDX(r)
+	case "spdx":
+		return sbom.ReadSPDX(r)
+	default:
+		return nil, fmt.Errorf("unsupported sbom format %q", cfg.Format)
+	}
+}
+
+// process writes a record for every vulnerability matching some of the components:
+// component ref, name and version, CVE, CVSS base score (v3 if available, v2 otherwise) and matched CPEs
+func process(components []*sbom.Component, cache *cvefeed.Cache, w io.Writer, cfg config) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = rune(cfg.OutFieldSeparator[0])
+	for _, c := range components {
+		attrs, err := c.AttributesWith(cfg.mapping)
+		if err != nil {
+			logging.Errorf("skipping component: %v", err)
+			continue
+		}
+		for _, matches := range cache.Get(attrs) {
+			cpes := make([]string, 0, len(matches.CPEs))
+			for _, attr := range matches.CPEs {
+				if attr != nil {
+					cpes = append(cpes, attr.BindToURI())
+				}
+			}
+			cvss := matches.CVE.CVSSv3BaseScore()
+			if cvss == 0 {
+				cvss = matches.CVE.CVSSv2BaseScore()
+			}
+			rec := []string{
+				c.Ref,
+				c.Name,
+				c.Version,
+				matches.CVE.ID(),
+				fmt.Sprintf("%.1f", cvss),
+				strings.Join(cpes, cfg.OutRecordSeparator),
+			}
+			if err := cw.Write(rec); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func init() {
+	logging.AddFlags()
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] nvd_feed.json.gz... < sbom.json\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "output: component ref, name, version, CVE, CVSS score, matching CPEs\n")
+		fmt.Fprintf(os.Stderr, "flags:\n")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+}
+
+func main() {
+	var cfg config
+	cfg.addFlags()
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+	}
+
+	cfg.mapping = purl.DefaultMapping()
+	if cfg.PURLMapping != "" {
+		if err := cfg.mapping.LoadFile(cfg.PURLMapping); err != nil {
+			logging.Errorf("failed to load purl mapping: %v", err)
+			os.Exit(1)
+		}
+	}
+
+	ctx, stop := shutdown.Context(context.Background())
+	dict, err := cvefeed.LoadCompactJSONDictionaryContext(ctx, flag.Args()...)
+	// matching doesn't need cleaning up, signals can kill it
+	stop()
+	if err != nil {
+		logging.Errorf("failed to load feeds: %v", err)
+		os.Exit(1)
+	}
+	if err := logging.Err(); err != nil {
+		logging.Errorf("%v", err)
+		os.Exit(1)
+	}
+	components, err := cfg.readComponents(os.Stdin)
+	if err != nil {
+		logging.Errorf("%v", err)
+		os.Exit(1)
+	}
+	cache := cvefeed.NewCache(dict).SetRequireVersion(cfg.RequireVersion).SetInvertedIndex()
+	if err := process(components, cache, os.Stdout, cfg); err != nil {
+		logging.Errorf("write error: %v", err)
+		os.Exit(1)
+	}
+}
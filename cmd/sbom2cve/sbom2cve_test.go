@@ -0,0 +1,68 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/purl"
+)
+
+const testFeed = `{"CVE_Items":[
+{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"CVE-2021-33203"}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[{"operator":"OR","cpe_match":[{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:djangoproject:django:*:*:*:*:*:*:*:*","versionStartIncluding":"3.2","versionEndExcluding":"3.2.4"}]}]},
+ "impact":{"baseMetricV3":{"cvssV3":{"version":"3.1","vectorString":"CVSS:3.1/AV:N/AC:L/PR:H/UI:N/S:U/C:H/I:N/A:N","baseScore":4.9}}}},
+{"cve":{"data_type":"CVE","data_format":"MITRE","data_version":"4.0","CVE_data_meta":{"ID":"CVE-2020-8203"}},
+ "configurations":{"CVE_data_version":"4.0","nodes":[{"operator":"OR","cpe_match":[{"vulnerable":true,"cpe23Uri":"cpe:2.3:a:lodash:lodash:*:*:*:*:*:node.js:*:*","versionEndExcluding":"4.17.19"}]}]}}
+]}`
+
+const testBOM = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "components": [
+    {"bom-ref": "django", "type": "library", "name": "Django", "version": "3.2.1", "cpe": "cpe:2.3:a:djangoproject:django:3.2.1:*:*:*:*:*:*:*"},
+    {"bom-ref": "lodash", "type": "library", "name": "lodash", "version": "4.17.15", "purl": "pkg:npm/lodash@4.17.15"}
+  ]
+}`
+
+func TestProcess(t *testing.T) {
+	vulns, err := cvefeed.ParseJSON(strings.NewReader(testFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	dict := make(cvefeed.Dictionary)
+	for _, v := range vulns {
+		dict[v.ID()] = v
+	}
+
+	cfg := config{Format: "cyclonedx", OutFieldSeparator: "\t", OutRecordSeparator: ",", mapping: purl.DefaultMapping()}
+	components, err := cfg.readComponents(strings.NewReader(testBOM))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var w bytes.Buffer
+	if err := process(components, cvefeed.NewCache(dict), &w, cfg); err != nil {
+		t.Fatal(err)
+	}
+	expect := "django\tDjango\t3.2.1\tCVE-2021-33203\t4.9\tcpe:/a:djangoproject:django:3.2.1\n" +
+		"lodash\tlodash\t4.17.15\tCVE-2020-8203\t0.0\tcpe:/a:lodash:lodash:4.17.15::~~~node.js~~\n"
+	if w.String() != expect {
+		t.Fatalf("got:\n%q\nexpected:\n%q", w.String(), expect)
+	}
+}
@@ -15,25 +15,33 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
-	"log"
 	"os"
 
 	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
 
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/providers/vfeed/api"
+	"github.com/facebookincubator/nvdtools/shutdown"
 )
 
 const pathVar = "VFEED_REPO_PATH"
 
 func main() {
-	if err := run(); err != nil {
-		log.Fatal(err)
+	logging.AddFlags()
+	flag.Parse()
+	ctx, stop := shutdown.Context(context.Background())
+	defer stop()
+	if err := run(ctx); err != nil {
+		logging.Errorf("%v", err)
+		os.Exit(1)
 	}
 }
 
-func run() error {
+func run(ctx context.Context) error {
 	path := os.Getenv(pathVar)
 	if path == "" {
 		return fmt.Errorf("variable %s is not set", pathVar)
@@ -42,7 +50,7 @@ func run() error {
 	client := api.NewClient(path)
 
 	// For now, FetchAllVulnerabilities disregards the "since" argument.
-	items, err := client.FetchAllVulnerabilities(0)
+	items, err := client.FetchAllVulnerabilities(ctx, 0)
 	if err != nil {
 		return fmt.Errorf("client failed to fetch vulnerabilities: %v", err)
 	}
@@ -57,6 +65,12 @@ func run() error {
 
 		feed.CVEItems = append(feed.CVEItems, nvdItem)
 	}
+	if err := logging.Err(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("interrupted: %v", err)
+	}
 
 	if err := json.NewEncoder(os.Stdout).Encode(feed); err != nil {
 		return fmt.Errorf("failed to encode nvd item: %v", err)
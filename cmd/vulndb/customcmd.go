@@ -15,7 +15,6 @@
 package main
 
 import (
-	"context"
 	"log"
 	"os"
 
@@ -69,7 +68,7 @@ File schema: https://csrc.nist.gov/schema/nvd/feed/1.0/nvd_cve_feed_json_1.0.sch
 			Provider: gFlagProvider,
 		}
 
-		ctx := context.Background()
+		ctx := cmd.Context()
 		err = imp.ImportFile(ctx, args[0])
 		if err != nil {
 			log.Fatal(err)
@@ -107,7 +106,7 @@ for JSON output, e.g. JSON_INDENT=$'\t' or use jq.
 			FilterCVEs: args,
 		}
 
-		ctx := context.Background()
+		ctx := cmd.Context()
 		switch gFlagFormat {
 		case "csv":
 			err = exp.CSV(ctx, os.Stdout, !gFlagCSVNoHeader)
@@ -153,7 +152,7 @@ for specific providers. Requires a list of CVE ID to delete, or
 			FilterCVEs: args,
 		}
 
-		ctx := context.Background()
+		ctx := cmd.Context()
 		err = del.Delete(ctx)
 		if err != nil {
 			log.Fatalln(err)
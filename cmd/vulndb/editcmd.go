@@ -16,7 +16,6 @@ package main
 
 import (
 	"bytes"
-	"context"
 	"crypto/md5"
 	"fmt"
 	"io"
@@ -94,7 +93,7 @@ for JSON output, e.g. JSON_INDENT=$'\t' or use jq.
 			indent = "  " // node-js style indentation
 		}
 
-		ctx := context.Background()
+		ctx := cmd.Context()
 		err = exp.JSON(ctx, mw, indent)
 		if err != nil {
 			log.Fatalln(err)
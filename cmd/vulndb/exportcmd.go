@@ -15,7 +15,6 @@
 package main
 
 import (
-	"context"
 	"log"
 	"os"
 	"strings"
@@ -63,7 +62,7 @@ for JSON output, e.g. JSON_INDENT=$'\t' or use jq.
 			FilterCVEs:      args,
 		}
 
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		switch gFlagFormat {
 		case "csv":
@@ -16,16 +16,29 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
+	"os"
 
 	"github.com/spf13/cobra"
+
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/shutdown"
 )
 
 func main() {
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
+	logging.AddFlags()
+	// subcommands report problems with the log package, send them through logging as well
+	log.SetFlags(0)
+	log.SetOutput(logging.Default())
+	RootCmd.PersistentFlags().AddGoFlagSet(flag.CommandLine)
 
-	if err := RootCmd.Execute(); err != nil {
-		log.Fatal(err)
+	ctx, stop := shutdown.Context(context.Background())
+	defer stop()
+	if err := RootCmd.ExecuteContext(ctx); err != nil {
+		logging.Errorf("%v", err)
+		os.Exit(1)
 	}
 }
 
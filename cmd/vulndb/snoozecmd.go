@@ -15,7 +15,6 @@
 package main
 
 import (
-	"context"
 	"log"
 	"os"
 
@@ -76,7 +75,7 @@ The deadline and metadata flags are optional.
 			sc.Metadata = []byte(gFlagMetadata)
 		}
 
-		ctx := context.Background()
+		ctx := cmd.Context()
 		err = sc.Create(ctx, args...)
 		if err != nil {
 			log.Fatalln(err)
@@ -112,7 +111,7 @@ The --collector and --provider flags, and list of CVEs are optional filters.
 			FilterCVEs: args,
 		}
 
-		ctx := context.Background()
+		ctx := cmd.Context()
 		err = sg.CSV(ctx, os.Stdout, !gFlagCSVNoHeader)
 		if err != nil {
 			log.Fatalln(err)
@@ -150,7 +149,7 @@ The delete command deletes snoozes from the database for specific providers.
 			FilterCVEs: args,
 		}
 
-		ctx := context.Background()
+		ctx := cmd.Context()
 		err = del.Delete(ctx)
 		if err != nil {
 			log.Fatalln(err)
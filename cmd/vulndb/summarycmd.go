@@ -15,7 +15,6 @@
 package main
 
 import (
-	"context"
 	"log"
 	"os"
 
@@ -45,7 +44,7 @@ var summaryCmd = &cobra.Command{
 			DB: db,
 		}
 
-		ctx := context.Background()
+		ctx := cmd.Context()
 		err = exp.CSV(ctx, os.Stdout, !gFlagCSVNoHeader)
 		if err != nil {
 			log.Fatalln(err)
@@ -15,7 +15,6 @@
 package main
 
 import (
-	"context"
 	"log"
 	"os"
 	"strings"
@@ -74,7 +73,7 @@ File schema: https://csrc.nist.gov/schema/nvd/feed/1.0/nvd_cve_feed_json_1.0.sch
 			},
 		}
 
-		ctx := context.Background()
+		ctx := cmd.Context()
 		vendor, err := imp.ImportFiles(ctx, args...)
 		if err != nil {
 			log.Fatal(err)
@@ -114,7 +113,7 @@ for JSON output, e.g. JSON_INDENT=$'\t' or use jq.
 			FilterCVEs: args,
 		}
 
-		ctx := context.Background()
+		ctx := cmd.Context()
 
 		switch gFlagFormat {
 		case "csv":
@@ -165,7 +164,7 @@ To force deleting all data use --all, optionally combined with --provider.
 			DeleteLatestVersion: gFlagDeleteAll,
 		}
 
-		ctx := context.Background()
+		ctx := cmd.Context()
 		err = del.Trim(ctx)
 		if err != nil {
 			log.Fatalln(err)
@@ -0,0 +1,180 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+	"github.com/facebookincubator/nvdtools/yaml"
+)
+
+// unregistered are the providers vulnsync can run which aren't registered with the runner,
+// with the environment variables their commands need
+var unregistered = map[string][]string{
+	"redhat": nil,
+}
+
+// requiredEnv returns the environment variables the command of the provider needs,
+// registered providers list them in their registration
+func requiredEnv(name string) ([]string, bool) {
+	if reg, ok := runner.Lookup(name); ok {
+		return reg.Env, true
+	}
+	env, ok := unregistered[name]
+	return env, ok
+}
+
+// providerNames returns sorted names of the providers
+func providerNames() []string {
+	names := runner.Registered()
+	for name := range unregistered {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// config is the vulnsync config file
+type config struct {
+	// OutputDir is the directory the converted feeds are written to
+	OutputDir string `json:"output_dir"`
+	// Schedule is how often providers run, unless they set their own
+	Schedule string `json:"schedule"`
+	// Timeout of a provider run, unless they set their own
+	Timeout   string            `json:"timeout"`
+	Providers []*providerConfig `json:"providers"`
+}
+
+// providerConfig configures a provider run
+type providerConfig struct {
+	Name string `json:"name"`
+	// Command is the provider command, {name}2nvd found in PATH by default
+	Command string `json:"command"`
+	BaseURL string `json:"base_url"`
+	// Env is added to the environment of the command, it's where credentials are passed;
+	// values can refer to variables of vulnsync's environment, e.g. ${SNYK_TOKEN}
+	Env map[string]string `json:"env"`
+	// Args are additional arguments of the command, e.g. provider specific flags
+	Args []string `json:"args"`
+	// Output is the name of the converted feed in the output directory, {name}.json by default
+	Output   string `json:"output"`
+	Schedule string `json:"schedule"`
+	Timeout  string `json:"timeout"`
+
+	schedule time.Duration
+	timeout  time.Duration
+}
+
+// loadConfig reads the config file, written in YAML or, if its extension is .json, JSON
+func loadConfig(path string) (*config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read config: %v", err)
+	}
+	if filepath.Ext(path) != ".json" {
+		doc, err := yaml.Parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("can't parse config %q: %v", path, err)
+		}
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, err
+		}
+	}
+	var cfg config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("can't decode config %q: %v", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("config %q is invalid: %v", path, err)
+	}
+	return &cfg, nil
+}
+
+// validate checks the config and sets the defaults
+func (cfg *config) validate() error {
+	if cfg.OutputDir == "" {
+		return fmt.Errorf("output_dir isn't set")
+	}
+	if len(cfg.Providers) == 0 {
+		return fmt.Errorf("no providers are configured")
+	}
+	if cfg.Schedule == "" {
+		cfg.Schedule = "24h"
+	}
+	if cfg.Timeout == "" {
+		cfg.Timeout = "1h"
+	}
+	outputs := make(map[string]bool)
+	for i, p := range cfg.Providers {
+		if p == nil {
+			return fmt.Errorf("provider %d is empty", i+1)
+		}
+		if err := p.validate(cfg); err != nil {
+			return fmt.Errorf("provider %d (%s): %v", i+1, p.Name, err)
+		}
+		if outputs[p.Output] {
+			return fmt.Errorf("provider %d (%s): output %q is written by another provider", i+1, p.Name, p.Output)
+		}
+		outputs[p.Output] = true
+	}
+	return nil
+}
+
+func (p *providerConfig) validate(cfg *config) error {
+	required, ok := requiredEnv(p.Name)
+	if !ok {
+		return fmt.Errorf("unknown provider, should be one of %s", strings.Join(providerNames(), ", "))
+	}
+	if p.Command == "" {
+		p.Command = p.Name + "2nvd"
+	}
+	if p.Output == "" {
+		p.Output = p.Name + ".json"
+	}
+	if filepath.Base(p.Output) != p.Output {
+		return fmt.Errorf("output %q should be a file name", p.Output)
+	}
+	for key, value := range p.Env {
+		p.Env[key] = os.ExpandEnv(value)
+	}
+	for _, key := range required {
+		if p.Env[key] == "" && os.Getenv(key) == "" {
+			return fmt.Errorf("%s isn't set in env of the provider nor in the environment", key)
+		}
+	}
+
+	var err error
+	if p.Schedule == "" {
+		p.Schedule = cfg.Schedule
+	}
+	if p.schedule, err = time.ParseDuration(p.Schedule); err != nil || p.schedule <= 0 {
+		return fmt.Errorf("bad schedule %q, should be a positive duration", p.Schedule)
+	}
+	if p.Timeout == "" {
+		p.Timeout = cfg.Timeout
+	}
+	if p.timeout, err = time.ParseDuration(p.Timeout); err != nil || p.timeout <= 0 {
+		return fmt.Errorf("bad timeout %q, should be a positive duration", p.Timeout)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadConfig(t *testing.T) {
+	td, err := ioutil.TempDir("", "vulnsync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(td)
+	os.Setenv("VULNSYNC_TEST_SECRET", "secret")
+	defer os.Unsetenv("VULNSYNC_TEST_SECRET")
+
+	for i, tc := range []struct {
+		filename string
+		config   string
+		check    func(*config) error
+		fail     string
+	}{
+		{
+			filename: "vulnsync.yaml",
+			config: `
+output_dir: /feeds/vendors
+schedule: 12h
+providers:
+  - name: snyk
+    env:
+      SNYK_ID: id
+      SNYK_READONLY_KEY: ${VULNSYNC_TEST_SECRET}
+    schedule: 6h
+  - name: kev
+    base_url: https://mirror.example.com/kev
+    timeout: 5m
+`,
+			check: func(cfg *config) error {
+				snyk, kev := cfg.Providers[0], cfg.Providers[1]
+				switch {
+				case snyk.Command != "snyk2nvd" || snyk.Output != "snyk.json" || snyk.schedule != 6*time.Hour || snyk.timeout != time.Hour:
+					return fmt.Errorf("unexpected snyk config %+v", snyk)
+				case snyk.Env["SNYK_READONLY_KEY"] != "secret":
+					return fmt.Errorf("environment variable wasn't expanded: %v", snyk.Env)
+				case kev.BaseURL != "https://mirror.example.com/kev" || kev.schedule != 12*time.Hour || kev.timeout != 5*time.Minute:
+					return fmt.Errorf("unexpected kev config %+v", kev)
+				}
+				return nil
+			},
+		},
+		{
+			filename: "vulnsync.json",
+			config:   `{"output_dir": "/feeds", "providers": [{"name": "debian", "args": ["-x"]}]}`,
+			check: func(cfg *config) error {
+				if p := cfg.Providers[0]; p.Name != "debian" || len(p.Args) != 1 || p.schedule != 24*time.Hour {
+					return fmt.Errorf("unexpected debian config %+v", p)
+				}
+				return nil
+			},
+		},
+		{
+			filename: "snyk.yaml",
+			config:   "output_dir: /feeds\nproviders:\n  - name: snyk\n    env:\n      SNYK_TOKEN: token\n    args: [-org, org]",
+			check: func(cfg *config) error {
+				if p := cfg.Providers[0]; p.Env["SNYK_TOKEN"] != "token" {
+					return fmt.Errorf("unexpected snyk config %+v", p)
+				}
+				return nil
+			},
+		},
+		{filename: "missing.yaml", fail: "can't read"},
+		{filename: "a.yaml", config: "providers:\n  - name: kev", fail: "output_dir"},
+		{filename: "b.yaml", config: "output_dir: /feeds", fail: "no providers"},
+		{filename: "c.yaml", config: "output_dir: /feeds\nproviders:\n  - name: nope", fail: "unknown provider"},
+		{filename: "d.yaml", config: "output_dir: /feeds\nproviders:\n  - name: flexera", fail: "FLEXERA_TOKEN"},
+		{filename: "e.yaml", config: "output_dir: /feeds\nproviders:\n  - name: kev\n    schedule: daily", fail: "bad schedule"},
+		{filename: "f.yaml", config: "output_dir: /feeds\nproviders:\n  - name: kev\n  - name: kev", fail: "written by another provider"},
+		{filename: "g.yaml", config: "output_dir: /feeds\nproviders:\n  - name: kev\n    output: ../kev.json", fail: "file name"},
+		{filename: "h.yaml", config: "output_dir: [/feeds]", fail: "can't decode"},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			path := filepath.Join(td, tc.filename)
+			if tc.config != "" {
+				if err := ioutil.WriteFile(path, []byte(tc.config), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			cfg, err := loadConfig(path)
+			if tc.fail != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.fail) {
+					t.Fatalf("expected error containing %q, got %v", tc.fail, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := tc.check(cfg); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
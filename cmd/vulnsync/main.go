@@ -0,0 +1,88 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/shutdown"
+)
+
+func init() {
+	logging.AddFlags()
+}
+
+func main() {
+	var (
+		configPath string
+		once       bool
+		only       string
+	)
+	flag.StringVar(&configPath, "config", "", "config file, in YAML (or JSON if its extension is .json)")
+	flag.BoolVar(&once, "once", false, "run all providers once and exit, instead of running them on their schedules")
+	flag.StringVar(&only, "only", "", "comma separated list of providers to run, all configured providers run if it's not set")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "use: %s -config vulnsync.yaml [flags]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "Runs vulnerability providers (%s) as configured, converting their feeds to NVD format.\n\n", strings.Join(providerNames(), ", "))
+		fmt.Fprintf(os.Stderr, "Flags:\n\n")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+	flag.Parse()
+	if configPath == "" {
+		flag.Usage()
+	}
+
+	cfg, err := loadConfig(configPath)
+	if err != nil {
+		logging.Errorf("%v", err)
+		os.Exit(1)
+	}
+	if only != "" {
+		selected := make(map[string]bool)
+		for _, name := range strings.Split(only, ",") {
+			selected[name] = true
+		}
+		var ps []*providerConfig
+		for _, p := range cfg.Providers {
+			if selected[p.Name] {
+				ps = append(ps, p)
+			}
+		}
+		if len(ps) == 0 {
+			logging.Errorf("none of providers %q are configured", only)
+			os.Exit(1)
+		}
+		cfg.Providers = ps
+	}
+
+	ctx, stop := shutdown.Context(context.Background())
+	defer stop()
+
+	if once {
+		if failed := runOnce(ctx, cfg); failed != 0 {
+			logging.Errorf("%d of %d providers failed", failed, len(cfg.Providers))
+			os.Exit(1)
+		}
+		return
+	}
+	schedule(ctx, cfg)
+}
@@ -0,0 +1,37 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+// providers register themselves with the runner, so vulnsync knows their names and the environment they need
+import (
+	_ "github.com/facebookincubator/nvdtools/providers/alpine/api"
+	_ "github.com/facebookincubator/nvdtools/providers/amazon/api"
+	_ "github.com/facebookincubator/nvdtools/providers/debian/api"
+	_ "github.com/facebookincubator/nvdtools/providers/exploitdb/api"
+	_ "github.com/facebookincubator/nvdtools/providers/fireeye/api"
+	_ "github.com/facebookincubator/nvdtools/providers/flexera/api"
+	_ "github.com/facebookincubator/nvdtools/providers/ghsa/api"
+	_ "github.com/facebookincubator/nvdtools/providers/gitlab/api"
+	_ "github.com/facebookincubator/nvdtools/providers/idefense/api"
+	_ "github.com/facebookincubator/nvdtools/providers/kev/api"
+	_ "github.com/facebookincubator/nvdtools/providers/msrc/api"
+	_ "github.com/facebookincubator/nvdtools/providers/oracle/api"
+	_ "github.com/facebookincubator/nvdtools/providers/osv/api"
+	_ "github.com/facebookincubator/nvdtools/providers/pkgdb/api"
+	_ "github.com/facebookincubator/nvdtools/providers/rbs/api"
+	_ "github.com/facebookincubator/nvdtools/providers/snyk/api"
+	_ "github.com/facebookincubator/nvdtools/providers/suse/api"
+	_ "github.com/facebookincubator/nvdtools/providers/ubuntu/api"
+)
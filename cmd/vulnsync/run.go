@@ -0,0 +1,107 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/logging"
+)
+
+// run runs the provider command, which downloads and converts vulnerabilities, and writes its output to the
+// output directory; the previous output is kept if the command fails
+func (p *providerConfig) run(ctx context.Context, outputDir string) error {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	args := []string{"-download", "-convert"}
+	if p.BaseURL != "" {
+		args = append(args, "-base_url", p.BaseURL)
+	}
+	args = append(args, p.Args...)
+	cmd := exec.CommandContext(ctx, p.Command, args...)
+	cmd.Env = os.Environ()
+	for key, value := range p.Env {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	cmd.Stderr = os.Stderr
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(outputDir, "."+p.Output+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	cmd.Stdout = tmp
+
+	logging.Debugf("running %s: %s %v", p.Name, p.Command, args)
+	err = cmd.Run()
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("%s failed: %v", p.Name, err)
+	}
+	return os.Rename(tmp.Name(), filepath.Join(outputDir, p.Output))
+}
+
+// runOnce runs all providers one after another, it returns the number of providers which failed
+func runOnce(ctx context.Context, cfg *config) int {
+	var failed int
+	for _, p := range cfg.Providers {
+		if err := p.run(ctx, cfg.OutputDir); err != nil {
+			logging.Errorf("%v", err)
+			failed++
+		}
+	}
+	return failed
+}
+
+// schedule runs each provider every time its schedule elapses, starting with all of them, until ctx is done.
+// Providers run one after another, a provider which is due while another one runs waits for it.
+func schedule(ctx context.Context, cfg *config) {
+	next := make([]time.Time, len(cfg.Providers))
+	for {
+		// the provider which is due first
+		due := 0
+		for i := range next {
+			if next[i].Before(next[due]) {
+				due = i
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(next[due])):
+		}
+
+		p := cfg.Providers[due]
+		start := time.Now()
+		if err := p.run(ctx, cfg.OutputDir); err != nil {
+			logging.Errorf("%v", err)
+		} else {
+			logging.Infof("%s synced in %v", p.Name, time.Since(start).Round(time.Second))
+		}
+		next[due] = start.Add(p.schedule)
+	}
+}
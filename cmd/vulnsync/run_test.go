@@ -0,0 +1,70 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+	td, err := ioutil.TempDir("", "vulnsync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(td)
+
+	// the provider command prints its arguments and credentials
+	command := filepath.Join(td, "kev2nvd")
+	script := "#!/bin/sh\nif [ \"$FAIL\" = 1 ]; then exit 1; fi\necho \"$TOKEN $@\"\n"
+	if err := ioutil.WriteFile(command, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	outputDir := filepath.Join(td, "feeds")
+	p := &providerConfig{
+		Name:    "kev",
+		Command: command,
+		BaseURL: "http://localhost",
+		Env:     map[string]string{"TOKEN": "secret"},
+		Args:    []string{"-x"},
+		Output:  "kev.json",
+		timeout: time.Minute,
+	}
+	if err := p.run(context.Background(), outputDir); err != nil {
+		t.Fatal(err)
+	}
+	want := "secret -download -convert -base_url http://localhost -x\n"
+	data, err := ioutil.ReadFile(filepath.Join(outputDir, "kev.json"))
+	if err != nil || string(data) != want {
+		t.Fatalf("unexpected output %q (%v), want %q", data, err, want)
+	}
+
+	// the previous output is kept if the command fails
+	p.Env["FAIL"] = "1"
+	if err := p.run(context.Background(), outputDir); err == nil {
+		t.Fatal("expected failure")
+	}
+	data, err = ioutil.ReadFile(filepath.Join(outputDir, "kev.json"))
+	if err != nil || string(data) != want {
+		t.Fatalf("unexpected output %q (%v), want %q", data, err, want)
+	}
+	if files, _ := ioutil.ReadDir(outputDir); len(files) != 1 {
+		t.Fatalf("temporary files weren't removed: %d files", len(files))
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"io"
 	"os"
 
+	"github.com/facebookincubator/nvdtools/shutdown"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -41,17 +42,20 @@ func main() {
 		fmt.Fprintf(os.Stderr, "wfnconvert: %v\n\n", err)
 		flag.Usage()
 	}
-	if err := wfnconvert(os.Stdin, os.Stdout, &o); err != nil {
+	ctx, stop := shutdown.Context(context.Background())
+	defer stop()
+	if err := wfnconvert(ctx, os.Stdin, os.Stdout, &o); err != nil {
 		fmt.Fprintf(os.Stderr, "wfnconvert: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-func wfnconvert(in io.Reader, out io.Writer, o *options) error {
+// wfnconvert converts the lines or CSV records from in and writes them to out until the context is done
+func wfnconvert(ctx context.Context, in io.Reader, out io.Writer, o *options) error {
 	inCh := make(chan []string)
 	outCh := make(chan []string)
 
-	g, ctx := errgroup.WithContext(context.Background())
+	g, ctx := errgroup.WithContext(ctx)
 	var inFunc, procFunc, outFunc func() error
 	if len(o.csvFields) == 0 {
 		inFunc = readLines(ctx, in, inCh)
@@ -16,6 +16,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"strings"
 	"testing"
@@ -51,7 +52,7 @@ func TestWfnconvert(t *testing.T) {
 				any2na:           true,
 			},
 			in:  "cpe:/a::bar:1.1\n",
-			out: "cpe:/a::bar:1.1:-:-:-\n",
+			out: "cpe:/a::bar:1.1:-:~-~-~-~-~-:-\n",
 		},
 		{
 			opts: &options{
@@ -64,8 +65,8 @@ func TestWfnconvert(t *testing.T) {
 			},
 			in: "field1.1,cpe:/a::bar:1.1,cpe:/o::linux_kernel:2.6.11,field1.4,field1.5\n" +
 				"field2.1,cpe:/a::baz:1:-,cpe:/o:microsoft:windows:10:very_expensive,field2.4,field2.5\n",
-			out: "field1.1,cpe:/a::bar:1.1:-:-:-,cpe:/o::linux_kernel:2.6.11:-:-:-,field1.4,field1.5\n" +
-				"field2.1,cpe:/a::baz:1:-:-:-,cpe:/o:microsoft:windows:10:very_expensive:-:-,field2.4,field2.5\n",
+			out: "field1.1,cpe:/a::bar:1.1:-:~-~-~-~-~-:-,cpe:/o::linux_kernel:2.6.11:-:~-~-~-~-~-:-,field1.4,field1.5\n" +
+				"field2.1,cpe:/a::baz:1:-:~-~-~-~-~-:-,cpe:/o:microsoft:windows:10:very_expensive:~-~-~-~-~-:-,field2.4,field2.5\n",
 		},
 	}
 	for n, c := range cases {
@@ -77,7 +78,7 @@ func TestWfnconvert(t *testing.T) {
 			if opts == nil {
 				opts = &defaultOptions
 			}
-			if err := wfnconvert(in, &out, opts); err != nil {
+			if err := wfnconvert(context.Background(), in, &out, opts); err != nil {
 				t.Fatal(err)
 			}
 			if out.String() != c.out {
@@ -0,0 +1,155 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// win2cpe converts exports of software installed on Windows hosts to CPE names
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/facebookincubator/nvdtools/logging"
+	msrc "github.com/facebookincubator/nvdtools/providers/msrc/schema"
+	"github.com/facebookincubator/nvdtools/shutdown"
+	"github.com/facebookincubator/nvdtools/wfn"
+	"github.com/facebookincubator/nvdtools/windows"
+)
+
+type config struct {
+	OutFieldSeparator string
+	Mapping           string
+	OS                string
+	OSBuild           string
+
+	// built-in mapping extended with Mapping
+	mapping *windows.Mapping
+	// CPE of the operating system parsed from OS and OSBuild
+	os string
+}
+
+func (cfg *config) addFlags() {
+	flag.StringVar(&cfg.OutFieldSeparator, "o", "\t", "output columns delimiter")
+	flag.StringVar(&cfg.Mapping, "map", "", "file with additional publisher to vendor and product to CPE translations, e.g. vendor 7-zip Igor Pavlov or product cpe:/a:videolan:vlc_media_player VLC media player")
+	flag.StringVar(&cfg.OS, "os", "", "Windows version as named by MSRC, e.g. \"Windows 10 Version 22H2 for x64-based Systems\"; its CPE is added as a record and as the last column of all records, for cpe2cve -os")
+	flag.StringVar(&cfg.OSBuild, "os_build", "", "Windows build, e.g. 10.0.19045.3448, which is the version of the -os CPE")
+}
+
+// validate checks the flags and loads the mapping and the operating system CPE
+func (cfg *config) validate() error {
+	if cfg.OutFieldSeparator == "" {
+		return fmt.Errorf("output columns delimiter can't be empty")
+	}
+	if cfg.OSBuild != "" && cfg.OS == "" {
+		return fmt.Errorf("-os_build requires -os")
+	}
+	cfg.mapping = windows.DefaultMapping()
+	if cfg.Mapping != "" {
+		if err := cfg.mapping.LoadFile(cfg.Mapping); err != nil {
+			return fmt.Errorf("can't load mapping: %v", err)
+		}
+	}
+	if cfg.OS != "" {
+		attrs, err := msrc.ProductToCPE(cfg.OS)
+		if err != nil {
+			return fmt.Errorf("can't create cpe of %q: %v", cfg.OS, err)
+		}
+		if attrs.Version, err = wfn.WFNize(cfg.OSBuild); err != nil {
+			return fmt.Errorf("can't wfnize build %q: %v", cfg.OSBuild, err)
+		}
+		cfg.os = attrs.BindToURI()
+	}
+	return nil
+}
+
+// process writes a record for every installed software: name, version, publisher and CPE,
+// followed by the operating system CPE if it's set; the operating system gets a record as well.
+// When the context is done, it stops after writing the records processed so far.
+func process(ctx context.Context, sw []*windows.Software, w io.Writer, cfg config) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = rune(cfg.OutFieldSeparator[0])
+	write := func(rec ...string) error {
+		if cfg.os != "" {
+			rec = append(rec, cfg.os)
+		}
+		return cw.Write(rec)
+	}
+	if cfg.os != "" {
+		if err := write(cfg.OS, cfg.OSBuild, "Microsoft Corporation", cfg.os); err != nil {
+			return err
+		}
+	}
+	for _, s := range sw {
+		if ctx.Err() != nil {
+			break
+		}
+		attrs, err := cfg.mapping.ToCPE(s)
+		if err != nil {
+			logging.Errorf("skipping %q: %v", s.Name, err)
+			continue
+		}
+		if err := write(s.Name, s.Version, s.Publisher, attrs.BindToURI()); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+func init() {
+	logging.AddFlags()
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s [flags] < software.csv\n", path.Base(os.Args[0]))
+		fmt.Fprintf(os.Stderr, "input: CSV export of registry Uninstall keys, Get-Package or wmic product\n")
+		fmt.Fprintf(os.Stderr, "output: name, version, publisher, CPE[, operating system CPE]\n")
+		fmt.Fprintf(os.Stderr, "flags:\n")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+}
+
+func main() {
+	var cfg config
+	cfg.addFlags()
+	flag.Parse()
+	if flag.NArg() != 0 {
+		flag.Usage()
+	}
+	if err := cfg.validate(); err != nil {
+		logging.Errorf("%v", err)
+		flag.Usage()
+	}
+
+	ctx, stop := shutdown.Context(context.Background())
+	defer stop()
+	sw, err := windows.ParseCSV(os.Stdin)
+	if err != nil {
+		logging.Errorf("failed to read software: %v", err)
+		os.Exit(1)
+	}
+	if len(sw) == 0 {
+		logging.Warningf("no software found")
+	}
+	if err := process(ctx, sw, os.Stdout, cfg); err != nil {
+		logging.Errorf("write error: %v", err)
+		os.Exit(1)
+	}
+}
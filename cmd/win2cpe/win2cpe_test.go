@@ -0,0 +1,77 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/windows"
+)
+
+const testExport = `"DisplayName","DisplayVersion","Publisher","PSPath"
+"Mozilla Firefox (x64 en-US)","118.0.1","Mozilla","Microsoft.PowerShell.Core\Registry::HKEY_LOCAL_MACHINE\Software\Microsoft\Windows\CurrentVersion\Uninstall\Mozilla Firefox 118.0.1 (x64 en-US)"
+"Microsoft Office Professional Plus 2019 - en-us","16.0.10827.20138","Microsoft Corporation","Microsoft.PowerShell.Core\Registry::HKEY_LOCAL_MACHINE\Software\WOW6432Node\Microsoft\Windows\CurrentVersion\Uninstall\ProPlus2019Volume - en-us"
+`
+
+func TestProcess(t *testing.T) {
+	sw, err := windows.ParseCSV(strings.NewReader(testExport))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config{OutFieldSeparator: "\t"}
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+	var w bytes.Buffer
+	if err := process(context.Background(), sw, &w, cfg); err != nil {
+		t.Fatal(err)
+	}
+	expect := "Mozilla Firefox (x64 en-US)\t118.0.1\tMozilla\tcpe:/a:mozilla:firefox:118.0.1::~~~~x64~\n" +
+		"Microsoft Office Professional Plus 2019 - en-us\t16.0.10827.20138\tMicrosoft Corporation\tcpe:/a:microsoft:office_2019:16.0.10827.20138::~~~~x86~\n"
+	if w.String() != expect {
+		t.Fatalf("got:\n%q\nexpected:\n%q", w.String(), expect)
+	}
+
+	cfg = config{OutFieldSeparator: "\t", OS: "Windows 10 Version 22H2 for x64-based Systems", OSBuild: "10.0.19045.3448"}
+	if err := cfg.validate(); err != nil {
+		t.Fatal(err)
+	}
+	w.Reset()
+	if err := process(context.Background(), sw[:1], &w, cfg); err != nil {
+		t.Fatal(err)
+	}
+	osCPE := "cpe:/o:microsoft:windows_10_22h2:10.0.19045.3448::~~~~x64~"
+	expect = "Windows 10 Version 22H2 for x64-based Systems\t10.0.19045.3448\tMicrosoft Corporation\t" + osCPE + "\t" + osCPE + "\n" +
+		"Mozilla Firefox (x64 en-US)\t118.0.1\tMozilla\tcpe:/a:mozilla:firefox:118.0.1::~~~~x64~\t" + osCPE + "\n"
+	if w.String() != expect {
+		t.Fatalf("got:\n%q\nexpected:\n%q", w.String(), expect)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, cfg := range []config{
+		{OutFieldSeparator: ""},
+		{OutFieldSeparator: "\t", OSBuild: "10.0.19045.3448"},
+		{OutFieldSeparator: "\t", Mapping: "/nonexistent"},
+	} {
+		if err := cfg.validate(); err == nil {
+			t.Fatalf("expecting %+v to be invalid", cfg)
+		}
+	}
+}
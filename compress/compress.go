@@ -0,0 +1,232 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package compress reads and writes compressed feed files.
+// Readers detect the compression by the magic number of the stream, so files can be renamed freely.
+// gzip is handled natively, zstd and xz streams are piped through the zstd and xz commands,
+// which need to be in the PATH.
+package compress
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+)
+
+// Format is a compression format
+type Format string
+
+// Supported formats
+const (
+	None Format = "none"
+	Gzip Format = "gzip"
+	Zstd Format = "zstd"
+	XZ   Format = "xz"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	xzMagic   = []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}
+)
+
+// extensions of files in each format
+var extensions = map[Format]string{
+	None: "",
+	Gzip: ".gz",
+	Zstd: ".zst",
+	XZ:   ".xz",
+}
+
+// ParseFormat parses the name of the format
+func ParseFormat(name string) (Format, error) {
+	f := Format(strings.ToLower(name))
+	if _, ok := extensions[f]; !ok {
+		return "", fmt.Errorf("unknown compression %q, expected none, gzip, zstd or xz", name)
+	}
+	return f, nil
+}
+
+// String implements fmt.Stringer interface
+func (f Format) String() string {
+	return string(f)
+}
+
+// Set implements flag.Value interface
+func (f *Format) Set(name string) error {
+	parsed, err := ParseFormat(name)
+	if err != nil {
+		return err
+	}
+	*f = parsed
+	return nil
+}
+
+// Ext returns the file extension of the format, including the dot
+func (f Format) Ext() string {
+	return extensions[f]
+}
+
+// TrimExt removes the extension of any supported format from the file name
+func TrimExt(name string) string {
+	for _, f := range []Format{Gzip, Zstd, XZ} {
+		if strings.HasSuffix(name, f.Ext()) {
+			return strings.TrimSuffix(name, f.Ext())
+		}
+	}
+	return name
+}
+
+// Detect returns the format of the stream which starts with header, None if it's not compressed
+func Detect(header []byte) Format {
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		return Gzip
+	case bytes.HasPrefix(header, zstdMagic):
+		return Zstd
+	case bytes.HasPrefix(header, xzMagic):
+		return XZ
+	}
+	return None
+}
+
+// NewReader returns a reader which decompresses r, if it's compressed in one of the supported formats
+func NewReader(r io.Reader) (io.ReadCloser, error) {
+	br := bufio.NewReader(r)
+	// shorter streams can't be compressed, so a failed peek is fine
+	header, _ := br.Peek(len(xzMagic))
+	switch f := Detect(header); f {
+	case Gzip:
+		return gzip.NewReader(br)
+	case Zstd, XZ:
+		return newCmdReader(br, string(f), "-d", "-c", "-q")
+	}
+	return ioutil.NopCloser(br), nil
+}
+
+// NewWriter returns a writer which compresses data written to it to w in the format
+// it needs to be closed to flush the data
+func NewWriter(w io.Writer, f Format) (io.WriteCloser, error) {
+	switch f {
+	case None:
+		return nopWriteCloser{w}, nil
+	case Gzip:
+		return gzip.NewWriter(w), nil
+	case Zstd, XZ:
+		return newCmdWriter(w, string(f), "-c", "-q")
+	}
+	return nil, fmt.Errorf("unknown compression %q", f)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// cmdReader reads the output of a command which decompresses its input
+type cmdReader struct {
+	cmd    *exec.Cmd
+	out    io.ReadCloser
+	stderr bytes.Buffer
+	eof    bool
+}
+
+func newCmdReader(in io.Reader, name string, args ...string) (*cmdReader, error) {
+	r := &cmdReader{cmd: exec.Command(name, args...)}
+	r.cmd.Stdin = in
+	r.cmd.Stderr = &r.stderr
+	out, err := r.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	r.out = out
+	if err := r.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("can't decompress %s: %v", name, err)
+	}
+	return r, nil
+}
+
+// Read implements io.Reader interface, errors of the command are returned instead of EOF
+func (r *cmdReader) Read(p []byte) (int, error) {
+	n, err := r.out.Read(p)
+	if err == io.EOF && !r.eof {
+		r.eof = true
+		if werr := r.wait(); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+// Close implements io.Closer interface, the command is killed if the output wasn't read till the end
+func (r *cmdReader) Close() error {
+	if r.eof {
+		return nil
+	}
+	r.eof = true
+	r.cmd.Process.Kill()
+	r.cmd.Wait()
+	return nil
+}
+
+func (r *cmdReader) wait() error {
+	if err := r.cmd.Wait(); err != nil {
+		return fmt.Errorf("can't decompress %s: %v: %s", r.cmd.Path, err, strings.TrimSpace(r.stderr.String()))
+	}
+	return nil
+}
+
+// cmdWriter writes to a command which compresses its input
+type cmdWriter struct {
+	cmd    *exec.Cmd
+	in     io.WriteCloser
+	stderr bytes.Buffer
+}
+
+func newCmdWriter(out io.Writer, name string, args ...string) (*cmdWriter, error) {
+	w := &cmdWriter{cmd: exec.Command(name, args...)}
+	w.cmd.Stdout = out
+	w.cmd.Stderr = &w.stderr
+	in, err := w.cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	w.in = in
+	if err := w.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("can't compress %s: %v", name, err)
+	}
+	return w, nil
+}
+
+// Write implements io.Writer interface
+func (w *cmdWriter) Write(p []byte) (int, error) {
+	return w.in.Write(p)
+}
+
+// Close implements io.Closer interface, it waits for the command to write everything
+func (w *cmdWriter) Close() error {
+	w.in.Close()
+	if err := w.cmd.Wait(); err != nil {
+		return fmt.Errorf("can't compress %s: %v: %s", w.cmd.Path, err, strings.TrimSpace(w.stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package compress
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	data := []byte(strings.Repeat(`{"CVE_Items": []}`, 100))
+	for _, f := range []Format{None, Gzip, Zstd, XZ} {
+		t.Run(f.String(), func(t *testing.T) {
+			if f == Zstd || f == XZ {
+				if _, err := exec.LookPath(string(f)); err != nil {
+					t.Skipf("%s isn't installed", f)
+				}
+			}
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := w.Write(data); err != nil {
+				t.Fatal(err)
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+			if detected := Detect(buf.Bytes()); detected != f {
+				t.Fatalf("detected %s", detected)
+			}
+
+			r, err := NewReader(&buf)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer r.Close()
+			got, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Fatalf("expected %d bytes, got %d", len(data), len(got))
+			}
+		})
+	}
+}
+
+func TestCorrupted(t *testing.T) {
+	if _, err := exec.LookPath("zstd"); err != nil {
+		t.Skip("zstd isn't installed")
+	}
+	r, err := NewReader(bytes.NewReader(append(zstdMagic, "garbage"...)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if _, err := ioutil.ReadAll(r); err == nil {
+		t.Fatal("expected an error")
+	}
+}
+
+func TestShortStream(t *testing.T) {
+	r, err := NewReader(strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := ioutil.ReadAll(r); string(got) != "{}" {
+		t.Fatalf("unexpected %q", got)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	if _, err := ParseFormat("lz4"); err == nil {
+		t.Fatal("expected an error for unknown format")
+	}
+	if f, err := ParseFormat("ZSTD"); err != nil || f != Zstd {
+		t.Fatalf("unexpected format %q: %v", f, err)
+	}
+	cases := map[string]string{
+		"nvdcve-2.0-2020.json.gz":  "nvdcve-2.0-2020.json",
+		"nvdcve-2.0-2020.json.zst": "nvdcve-2.0-2020.json",
+		"nvdcve-2.0-2020.json.xz":  "nvdcve-2.0-2020.json",
+		"nvdcve-2.0-2020.json":     "nvdcve-2.0-2020.json",
+	}
+	for name, expected := range cases {
+		if got := TrimExt(name); got != expected {
+			t.Errorf("TrimExt(%q): expected %q, got %q", name, expected, got)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpedict
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/compress"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// apiResponse is the format of NVD CPE API 2.0 responses, also used by the nvdsync mirror (nvdcpe-2.0.json.gz)
+type apiResponse struct {
+	Timestamp string `json:"timestamp"`
+	Version   string `json:"version"`
+	Products  []struct {
+		CPE apiCPE `json:"cpe"`
+	} `json:"products"`
+}
+
+type apiCPE struct {
+	Deprecated   bool   `json:"deprecated"`
+	CPEName      string `json:"cpeName"`
+	LastModified string `json:"lastModified"`
+	Titles       []struct {
+		Title string `json:"title"`
+		Lang  string `json:"lang"`
+	} `json:"titles"`
+	Refs []struct {
+		Ref  string `json:"ref"`
+		Type string `json:"type"`
+	} `json:"refs"`
+	DeprecatedBy []struct {
+		CPEName string `json:"cpeName"`
+	} `json:"deprecatedBy"`
+}
+
+// apiTimeLayout is the layout of timestamps returned by the API
+const apiTimeLayout = "2006-01-02T15:04:05.000"
+
+// DecodeAPI decodes dictionary from the NVD CPE API 2.0 response
+func DecodeAPI(r io.Reader) (*CPEList, error) {
+	var resp apiResponse
+	if err := json.NewDecoder(r).Decode(&resp); err != nil {
+		return nil, err
+	}
+
+	list := CPEList{
+		Generator: Generator{
+			ProductName:    "NVD CPE API",
+			ProductVersion: resp.Version,
+		},
+		Items: make([]CPEItem, 0, len(resp.Products)),
+	}
+	list.Generator.TimeStamp, _ = time.Parse(apiTimeLayout, resp.Timestamp)
+
+	for _, product := range resp.Products {
+		item, err := product.CPE.item()
+		if err != nil {
+			return nil, err
+		}
+		list.Items = append(list.Items, *item)
+	}
+	return &list, nil
+}
+
+func (c *apiCPE) item() (*CPEItem, error) {
+	name, err := parseName(c.CPEName)
+	if err != nil {
+		return nil, err
+	}
+	item := CPEItem{
+		Name:       name,
+		Deprecated: c.Deprecated,
+		CPE23:      CPE23Item{Name: name},
+		Title:      TextType{},
+	}
+	for _, t := range c.Titles {
+		item.Title[t.Lang] = t.Title
+	}
+	for _, ref := range c.Refs {
+		item.References = append(item.References, Reference{URL: ref.Ref, Desc: ref.Type})
+	}
+	if c.Deprecated && len(c.DeprecatedBy) != 0 {
+		dep := &Deprecation{}
+		dep.Date, _ = time.Parse(apiTimeLayout, c.LastModified)
+		for _, by := range c.DeprecatedBy {
+			byName, err := parseName(by.CPEName)
+			if err != nil {
+				return nil, err
+			}
+			dep.DeprecatedBy = append(dep.DeprecatedBy, DeprecatedInfo{Name: byName})
+		}
+		item.DeprecationDate = dep.Date
+		item.CPE23.Deprecation = dep
+	}
+	return &item, nil
+}
+
+func parseName(s string) (NamePattern, error) {
+	attrs, err := wfn.Parse(s)
+	if err != nil {
+		return NamePattern{}, fmt.Errorf("can't parse cpe name %q: %v", s, err)
+	}
+	return NamePattern(*attrs), nil
+}
+
+// Load loads dictionary from the XML feed or the NVD CPE API 2.0 response (e.g. one mirrored by nvdsync).
+// Files compressed with gzip, zstd, xz or zip are decompressed.
+func Load(path string) (*CPEList, error) {
+	return LoadContext(context.Background(), path)
+}
+
+// LoadContext is Load which stops loading when the context is done and returns the error of the context
+func LoadContext(ctx context.Context, path string) (*CPEList, error) {
+	list, err := load(ctx, path)
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+		return nil, ctxErr
+	}
+	return list, err
+}
+
+func load(ctx context.Context, path string) (*CPEList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open dictionary: %v", err)
+	}
+	defer f.Close()
+
+	var r io.Reader
+	if strings.HasSuffix(path, ".zip") {
+		data, err := ioutil.ReadAll(&contextReader{ctx: ctx, r: f})
+		if err != nil {
+			return nil, fmt.Errorf("can't read dictionary %q: %v", path, err)
+		}
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("can't decompress dictionary %q: %v", path, err)
+		}
+		if len(zr.File) == 0 {
+			return nil, fmt.Errorf("dictionary archive %q is empty", path)
+		}
+		zf, err := zr.File[0].Open()
+		if err != nil {
+			return nil, fmt.Errorf("can't decompress dictionary %q: %v", path, err)
+		}
+		defer zf.Close()
+		r = zf
+	} else {
+		cr, err := compress.NewReader(&contextReader{ctx: ctx, r: f})
+		if err != nil {
+			return nil, fmt.Errorf("can't decompress dictionary %q: %v", path, err)
+		}
+		defer cr.Close()
+		r = cr
+	}
+
+	list, err := decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("can't decode dictionary %q: %v", path, err)
+	}
+	return list, nil
+}
+
+// contextReader fails reading once the context is done, so decoding of large dictionaries stops
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+// decode detects the format of the dictionary by its first character and decodes it
+func decode(r io.Reader) (*CPEList, error) {
+	br := bufio.NewReader(r)
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			br.ReadByte()
+			continue
+		case '{':
+			return DecodeAPI(br)
+		default:
+			return Decode(br)
+		}
+	}
+}
@@ -15,6 +15,10 @@
 package cpedict
 
 import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -99,3 +103,30 @@ func TestDecode(t *testing.T) {
 		t.Errorf("item was expected to be deprecated because of NAME_CORRECTION, got %v", dep.DeprecatedBy[0].Type)
 	}
 }
+
+func TestLoadContext(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cpedict")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "nvdcpe-2.0.json")
+	data := `{"products": [{"cpe": {"cpeName": "cpe:2.3:a:vendor:product:1.0:*:*:*:*:*:*:*", "titles": [{"title": "Product 1.0", "lang": "en"}]}}]}`
+	if err := ioutil.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := LoadContext(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Items) != 1 {
+		t.Fatalf("expecting 1 item, got %d", len(list.Items))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := LoadContext(ctx, path); err != context.Canceled {
+		t.Fatalf("expecting %v, got %v", context.Canceled, err)
+	}
+}
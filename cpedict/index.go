@@ -0,0 +1,298 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpedict
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// weights of tokens found in different parts of dictionary item
+const (
+	nameWeight  = 3.0
+	titleWeight = 2.0
+	refWeight   = 1.0
+)
+
+// indexed tokens less similar to a query token than this are ignored
+const minSimilarity = 0.75
+
+// stopWords are ignored when tokenizing titles, references and queries
+var stopWords = map[string]bool{
+	"a": true, "aka": true, "an": true, "and": true, "for": true, "of": true, "on": true, "the": true,
+	"com": true, "html": true, "http": true, "https": true, "index": true, "net": true, "org": true, "www": true,
+}
+
+// Index is the dictionary indexed for free-text search.
+// Vendor and product names, titles and references of items are split into tokens,
+// query tokens are matched against them either exactly or, for tokens which aren't numbers,
+// by edit distance, which makes search tolerant to typos and different spellings.
+// Deprecated items aren't indexed.
+type Index struct {
+	items    []CPEItem
+	postings map[string][]posting
+	// trigram -> tokens containing it
+	trigrams map[string][]string
+}
+
+type posting struct {
+	item   int
+	weight float64
+}
+
+// Suggestion is a dictionary item found by Index.Search
+type Suggestion struct {
+	Item CPEItem
+	// Score is between 0 and 1; 1 means that every query token matched the vendor or product name exactly
+	Score float64
+}
+
+// NewIndex indexes the dictionary
+func NewIndex(dict *CPEList) *Index {
+	idx := &Index{
+		postings: make(map[string][]posting),
+		trigrams: make(map[string][]string),
+	}
+	for _, item := range dict.Items {
+		if item.Deprecated {
+			continue
+		}
+		n := len(idx.items)
+		idx.items = append(idx.items, item)
+
+		weights := make(map[string]float64)
+		add := func(weight float64, tokens []string) {
+			for _, t := range tokens {
+				if weights[t] < weight {
+					weights[t] = weight
+				}
+			}
+		}
+		add(nameWeight, tokenize(wfn.StripSlashes(item.Name.Vendor)))
+		add(nameWeight, tokenize(wfn.StripSlashes(item.Name.Product)))
+		if v := item.Name.Version; v != wfn.Any && v != wfn.NA {
+			add(refWeight, []string{wfn.StripSlashes(v)})
+		}
+		for lang, title := range item.Title {
+			if strings.HasPrefix(lang, "en") {
+				add(titleWeight, tokenize(title))
+			}
+		}
+		for _, ref := range item.References {
+			add(refWeight, tokenizeURL(ref.URL))
+		}
+
+		for t, w := range weights {
+			if _, ok := idx.postings[t]; !ok && !isNumber(t) {
+				for _, tri := range trigrams(t) {
+					idx.trigrams[tri] = append(idx.trigrams[tri], t)
+				}
+			}
+			idx.postings[t] = append(idx.postings[t], posting{item: n, weight: w})
+		}
+	}
+	return idx
+}
+
+// Search returns at most limit dictionary items closest to the free-text query, e.g. "apache http server 2.4".
+// Only the best matching item of each vendor and product is returned; items whose version
+// appears in the query are preferred. Suggestions are sorted by score, best first.
+func (idx *Index) Search(query string, limit int) []Suggestion {
+	qtokens := idx.join(tokenize(query))
+	if len(qtokens) == 0 {
+		return nil
+	}
+
+	scores := make(map[int]float64)
+	for _, qt := range qtokens {
+		// the best score of this query token for every item
+		best := make(map[int]float64)
+		for t, sim := range idx.similar(qt) {
+			for _, p := range idx.postings[t] {
+				if s := sim * p.weight; s > best[p.item] {
+					best[p.item] = s
+				}
+			}
+		}
+		for item, s := range best {
+			scores[item] += s
+		}
+	}
+
+	items := make([]int, 0, len(scores))
+	for item := range scores {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if si, sj := scores[items[i]], scores[items[j]]; si != sj {
+			return si > sj
+		}
+		return items[i] < items[j]
+	})
+
+	var suggestions []Suggestion
+	seen := make(map[string]bool)
+	maxScore := nameWeight * float64(len(qtokens))
+	for _, i := range items {
+		if len(suggestions) == limit {
+			break
+		}
+		item := idx.items[i]
+		key := item.Name.Vendor + ":" + item.Name.Product
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		score := scores[i] / maxScore
+		if score > 1 {
+			score = 1
+		}
+		suggestions = append(suggestions, Suggestion{Item: item, Score: score})
+	}
+	return suggestions
+}
+
+// join merges adjacent tokens if the result is an indexed token, e.g. "open ssh" -> "openssh"
+func (idx *Index) join(tokens []string) []string {
+	joined := make([]string, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		if i+1 < len(tokens) {
+			if t := tokens[i] + tokens[i+1]; idx.postings[t] != nil {
+				joined = append(joined, t)
+				i++
+				continue
+			}
+		}
+		joined = append(joined, tokens[i])
+	}
+	return joined
+}
+
+// similar returns indexed tokens similar to the given one along with their similarity
+func (idx *Index) similar(token string) map[string]float64 {
+	result := make(map[string]float64)
+	if _, ok := idx.postings[token]; ok {
+		result[token] = 1
+	}
+	if isNumber(token) {
+		return result
+	}
+	// tokens sharing a trigram with the given one are candidates
+	candidates := make(map[string]bool)
+	for _, tri := range trigrams(token) {
+		for _, t := range idx.trigrams[tri] {
+			candidates[t] = true
+		}
+	}
+	for t := range candidates {
+		if t == token {
+			continue
+		}
+		if sim := similarity(token, t); sim >= minSimilarity {
+			result[t] = sim
+		}
+	}
+	return result
+}
+
+// similarity is 1 - edit distance / length of the longer string
+func similarity(a, b string) float64 {
+	n := len(a)
+	if len(b) > n {
+		n = len(b)
+	}
+	return 1 - float64(editDistance(a, b))/float64(n)
+}
+
+// editDistance is the optimal string alignment distance,
+// i.e. the number of insertions, deletions, substitutions and transpositions of adjacent characters
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = d[i-1][j-1] + cost
+			if d[i-1][j]+1 < d[i][j] {
+				d[i][j] = d[i-1][j] + 1
+			}
+			if d[i][j-1]+1 < d[i][j] {
+				d[i][j] = d[i][j-1] + 1
+			}
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && d[i-2][j-2]+1 < d[i][j] {
+				d[i][j] = d[i-2][j-2] + 1
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+// tokenize splits the text into lower case tokens; dots are kept inside of tokens, so that versions aren't split
+func tokenize(s string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.'
+	})
+	tokens := fields[:0]
+	for _, f := range fields {
+		if f = strings.Trim(f, "."); f != "" && !stopWords[f] {
+			tokens = append(tokens, f)
+		}
+	}
+	return tokens
+}
+
+// tokenizeURL returns tokens of host and path of the url, e.g. github.com/apache/httpd -> github, apache, httpd
+func tokenizeURL(s string) []string {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil
+	}
+	return tokenize(strings.Replace(u.Hostname()+" "+u.Path, ".", " ", -1))
+}
+
+func trigrams(token string) []string {
+	padded := " " + token + " "
+	set := make(map[string]bool)
+	tris := make([]string, 0, len(padded))
+	for i := 0; i+3 <= len(padded); i++ {
+		if tri := padded[i : i+3]; !set[tri] {
+			set[tri] = true
+			tris = append(tris, tri)
+		}
+	}
+	return tris
+}
+
+func isNumber(token string) bool {
+	for _, r := range token {
+		if !unicode.IsDigit(r) && r != '.' {
+			return false
+		}
+	}
+	return true
+}
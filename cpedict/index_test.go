@@ -0,0 +1,143 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cpedict
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const testAPIDict = `{
+  "resultsPerPage": 4,
+  "startIndex": 0,
+  "totalResults": 4,
+  "format": "NVD_CPE",
+  "version": "2.0",
+  "timestamp": "2024-03-01T10:00:00.000",
+  "products": [
+    {"cpe": {
+      "deprecated": false,
+      "cpeName": "cpe:2.3:a:apache:http_server:2.4.58:*:*:*:*:*:*:*",
+      "cpeNameId": "1",
+      "lastModified": "2023-10-20T12:00:00.000",
+      "titles": [{"title": "Apache Software Foundation Apache HTTP Server 2.4.58", "lang": "en"}],
+      "refs": [{"ref": "https://httpd.apache.org/", "type": "Vendor"}]
+    }},
+    {"cpe": {
+      "deprecated": false,
+      "cpeName": "cpe:2.3:a:apache:http_server:2.4.57:*:*:*:*:*:*:*",
+      "cpeNameId": "2",
+      "lastModified": "2023-04-20T12:00:00.000",
+      "titles": [{"title": "Apache Software Foundation Apache HTTP Server 2.4.57", "lang": "en"}]
+    }},
+    {"cpe": {
+      "deprecated": false,
+      "cpeName": "cpe:2.3:a:apache:tomcat:9.0.0:*:*:*:*:*:*:*",
+      "cpeNameId": "3",
+      "lastModified": "2023-04-20T12:00:00.000",
+      "titles": [{"title": "Apache Software Foundation Tomcat 9.0.0", "lang": "en"}],
+      "refs": [{"ref": "https://tomcat.apache.org/", "type": "Product"}]
+    }},
+    {"cpe": {
+      "deprecated": true,
+      "cpeName": "cpe:2.3:a:apache:httpd:2.4.57:*:*:*:*:*:*:*",
+      "cpeNameId": "4",
+      "lastModified": "2023-04-21T12:00:00.000",
+      "titles": [{"title": "Apache httpd 2.4.57", "lang": "en"}],
+      "deprecatedBy": [{"cpeName": "cpe:2.3:a:apache:http_server:2.4.57:*:*:*:*:*:*:*", "cpeNameId": "2"}]
+    }},
+    {"cpe": {
+      "deprecated": false,
+      "cpeName": "cpe:2.3:a:mozilla:firefox:115.0:*:*:*:esr:*:*:*",
+      "cpeNameId": "5",
+      "lastModified": "2023-07-04T12:00:00.000",
+      "titles": [{"title": "Mozilla Firefox ESR 115.0", "lang": "en"}]
+    }}
+  ]
+}`
+
+func TestDecodeAPI(t *testing.T) {
+	dict, err := DecodeAPI(strings.NewReader(testAPIDict))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(dict.Items); n != 5 {
+		t.Fatalf("expecting 5 items, got %d", n)
+	}
+	item := dict.Items[0]
+	if item.Name.Vendor != "apache" || item.Name.Product != "http_server" || item.Name != item.CPE23.Name {
+		t.Fatalf("bad name: %v", item.Name)
+	}
+	if item.Title["en"] != "Apache Software Foundation Apache HTTP Server 2.4.58" {
+		t.Fatalf("bad title: %v", item.Title)
+	}
+	if len(item.References) != 1 || item.References[0].URL != "https://httpd.apache.org/" {
+		t.Fatalf("bad references: %v", item.References)
+	}
+	item = dict.Items[3]
+	if !item.Deprecated || item.CPE23.Deprecation == nil || len(item.CPE23.Deprecation.DeprecatedBy) != 1 {
+		t.Fatalf("item was expected to be deprecated: %+v", item)
+	}
+	if by := item.CPE23.Deprecation.DeprecatedBy[0].Name; by != dict.Items[1].Name {
+		t.Fatalf("item was expected to be deprecated by %v, got %v", dict.Items[1].Name, by)
+	}
+}
+
+func TestIndexSearch(t *testing.T) {
+	dict, err := DecodeAPI(strings.NewReader(testAPIDict))
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx := NewIndex(dict)
+
+	cases := []struct {
+		query  string
+		expect []string
+	}{
+		{"apache http server", []string{"apache:http_server", "apache:tomcat"}},
+		{"Apache HTTP Server 2.4.57", []string{"apache:http_server:2\\.4\\.57", "apache:tomcat"}},
+		{"apache tomcat", []string{"apache:tomcat", "apache:http_server"}},
+		{"tomcta", []string{"apache:tomcat"}},
+		{"firefx esr", []string{"mozilla:firefox"}},
+		{"httpd", []string{"apache:http_server"}},
+		{"microsoft windows", nil},
+		{"", nil},
+	}
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			suggestions := idx.Search(c.query, 10)
+			if len(suggestions) != len(c.expect) {
+				t.Fatalf("expecting %d suggestions for %q, got %d: %v", len(c.expect), c.query, len(suggestions), suggestions)
+			}
+			for j, s := range suggestions {
+				name := s.Item.Name.Vendor + ":" + s.Item.Name.Product
+				if strings.Count(c.expect[j], ":") == 2 {
+					name += ":" + s.Item.Name.Version
+				}
+				if name != c.expect[j] {
+					t.Fatalf("expecting suggestion %d for %q to be %s, got %s", j+1, c.query, c.expect[j], name)
+				}
+				if s.Score <= 0 || s.Score > 1 {
+					t.Fatalf("score out of range: %v", s.Score)
+				}
+			}
+		})
+	}
+
+	if s := idx.Search("apache http server", 1); len(s) != 1 || s[0].Score != 1 {
+		t.Fatalf("expecting one exact suggestion, got %v", s)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// sizes of the synthetic feed and inventory used by benchmarks
+const (
+	benchVendors           = 200
+	benchProductsPerVendor = 10
+	benchVulns             = 5000
+	benchAssets            = 100
+	benchCPEsPerAsset      = 20
+)
+
+// syntheticFeed returns a JSON 1.x feed of n vulnerabilities of random products,
+// with exact versions, version ranges and some AND configurations, as NVD feeds have
+func syntheticFeed(n int) []byte {
+	rnd := rand.New(rand.NewSource(1))
+	var buf bytes.Buffer
+	buf.WriteString(`{"CVE_Items": [`)
+	for i := 0; i < n; i++ {
+		if i != 0 {
+			buf.WriteString(",\n")
+		}
+		fmt.Fprintf(&buf, `{"cve": {"CVE_data_meta": {"ID": "CVE-2020-%05d"}}, "configurations": {"nodes": [`, i)
+		if rnd.Intn(10) == 0 {
+			buf.WriteString(`{"operator": "AND", "children": [`)
+			writeSyntheticNode(&buf, rnd)
+			fmt.Fprintf(&buf, `, {"operator": "OR", "cpe_match": [{"vulnerable": false, "cpe23Uri": "cpe:2.3:o:os%d:os:%d:*:*:*:*:*:*:*"}]}]}`, rnd.Intn(10), rnd.Intn(5))
+		} else {
+			writeSyntheticNode(&buf, rnd)
+		}
+		buf.WriteString(`]}}`)
+	}
+	buf.WriteString(`]}`)
+	return buf.Bytes()
+}
+
+func writeSyntheticNode(buf *bytes.Buffer, rnd *rand.Rand) {
+	buf.WriteString(`{"operator": "OR", "cpe_match": [`)
+	for j, n := 0, 1+rnd.Intn(3); j < n; j++ {
+		if j != 0 {
+			buf.WriteString(", ")
+		}
+		vendor, product := rnd.Intn(benchVendors), rnd.Intn(benchProductsPerVendor)
+		if rnd.Intn(2) == 0 {
+			fmt.Fprintf(buf, `{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:vendor%d:product%d:%d.%d.%d:*:*:*:*:*:*:*"}`,
+				vendor, product, rnd.Intn(5), rnd.Intn(10), rnd.Intn(10))
+		} else {
+			fmt.Fprintf(buf, `{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:vendor%d:product%d:*:*:*:*:*:*:*:*", "versionStartIncluding": "%d.0", "versionEndExcluding": "%d.%d.%d"}`,
+				vendor, product, rnd.Intn(3), 3+rnd.Intn(2), rnd.Intn(10), rnd.Intn(10))
+		}
+	}
+	buf.WriteString(`]}`)
+}
+
+// syntheticInventory returns n assets with random products of the synthetic feed and an operating system
+func syntheticInventory(n int) [][]*wfn.Attributes {
+	rnd := rand.New(rand.NewSource(2))
+	assets := make([][]*wfn.Attributes, n)
+	for i := range assets {
+		cpes := []*wfn.Attributes{{Part: "o", Vendor: fmt.Sprintf("os%d", rnd.Intn(10)), Product: "os", Version: fmt.Sprint(rnd.Intn(5))}}
+		for j := 0; j < benchCPEsPerAsset; j++ {
+			cpes = append(cpes, &wfn.Attributes{
+				Part:    "a",
+				Vendor:  fmt.Sprintf("vendor%d", rnd.Intn(benchVendors)),
+				Product: fmt.Sprintf("product%d", rnd.Intn(benchProductsPerVendor)),
+				Version: fmt.Sprintf("%d\\.%d\\.%d", rnd.Intn(5), rnd.Intn(10), rnd.Intn(10)),
+			})
+		}
+		assets[i] = cpes
+	}
+	return assets
+}
+
+func loadSyntheticDictionary(b *testing.B) Dictionary {
+	feed := syntheticFeed(benchVulns)
+	dict, err := LoadFeed(func(_ string) ([]Vuln, error) {
+		return ParseJSON(bytes.NewReader(feed))
+	}, "")
+	if err != nil {
+		b.Fatal(err)
+	}
+	return dict
+}
+
+func BenchmarkParseJSON(b *testing.B) {
+	feed := syntheticFeed(benchVulns)
+	b.SetBytes(int64(len(feed)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseJSON(bytes.NewReader(feed)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkParseCompactJSON(b *testing.B) {
+	feed := syntheticFeed(benchVulns)
+	b.SetBytes(int64(len(feed)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseCompactJSON(bytes.NewReader(feed)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNewInvertedIndex(b *testing.B) {
+	dict := loadSyntheticDictionary(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		NewInvertedIndex(dict)
+	}
+}
+
+// benchmarkCacheGet matches the synthetic inventory, asset per iteration, with caching disabled
+func benchmarkCacheGet(b *testing.B, cache *Cache) {
+	assets := syntheticInventory(benchAssets)
+	matched := 0
+	for _, cpes := range assets {
+		matched += len(cache.Get(cpes))
+	}
+	if matched == 0 {
+		b.Fatal("synthetic inventory doesn't match any vulnerability")
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		cache.Get(assets[i%len(assets)])
+	}
+}
+
+func BenchmarkCacheGet(b *testing.B) {
+	benchmarkCacheGet(b, NewCache(loadSyntheticDictionary(b)).SetMaxSize(-1))
+}
+
+func BenchmarkCacheGetInvertedIndex(b *testing.B) {
+	benchmarkCacheGet(b, NewCache(loadSyntheticDictionary(b)).SetMaxSize(-1).SetInvertedIndex())
+}
+
+func BenchmarkCacheGetIndex(b *testing.B) {
+	dict := loadSyntheticDictionary(b)
+	cache := NewCache(dict).SetMaxSize(-1)
+	cache.Idx = NewIndex(dict)
+	benchmarkCacheGet(b, cache)
+}
@@ -0,0 +1,95 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+)
+
+// LoadMatchCriteria loads match criteria from NVD Match Criteria API 2.0 responses (can be compressed with gzip, zstd or xz);
+// criteria of later files replace criteria with the same id of earlier ones.
+// Use them with LoadResolvedJSONDictionary to match configurations which reference criteria by id.
+func LoadMatchCriteria(paths ...string) (nvd.MatchCriteria, error) {
+	return LoadMatchCriteriaContext(context.Background(), paths...)
+}
+
+// LoadMatchCriteriaContext is LoadMatchCriteria which stops loading when the context is done
+func LoadMatchCriteriaContext(ctx context.Context, paths ...string) (nvd.MatchCriteria, error) {
+	criteria := make(nvd.MatchCriteria)
+	for _, path := range paths {
+		if err := loadMatchCriteriaFile(ctx, criteria, path); err != nil {
+			return nil, err
+		}
+	}
+	return criteria, nil
+}
+
+func loadMatchCriteriaFile(ctx context.Context, criteria nvd.MatchCriteria, path string) error {
+	f, err := openFeed(ctx, path)
+	if err != nil {
+		return fmt.Errorf("can't load match criteria %q: %v", path, err)
+	}
+	defer f.Close()
+	if err := ParseMatchCriteria(f, criteria); err != nil {
+		return fmt.Errorf("can't load match criteria %q: %v", path, err)
+	}
+	return nil
+}
+
+// ParseMatchCriteria decodes NVD Match Criteria API 2.0 response and adds match criteria from it to the index;
+// criteria are decoded one by one, so the response isn't kept in memory
+func ParseMatchCriteria(in io.Reader, criteria nvd.MatchCriteria) error {
+	reader, err := setupReader(in)
+	if err != nil {
+		return fmt.Errorf("can't setup reader: %v", err)
+	}
+	defer reader.Close()
+
+	dec := json.NewDecoder(reader)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case "matchStrings":
+			err = decodeArray(dec, func() error {
+				var ms schema.CPEMatchAPIJSON20DefMatchString
+				if err := dec.Decode(&ms); err != nil {
+					return err
+				}
+				criteria.Add(ms.MatchString)
+				return nil
+			})
+		default:
+			// other keys are response metadata
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, '}')
+}
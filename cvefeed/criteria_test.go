@@ -0,0 +1,198 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const testCriteriaFeed = `{
+  "format": "NVD_CVE",
+  "version": "2.0",
+  "vulnerabilities": [
+    {
+      "cve": {
+        "id": "CVE-2024-0001",
+        "published": "2024-01-01T00:00:00.000",
+        "lastModified": "2024-01-01T00:00:00.000",
+        "configurations": [
+          {
+            "nodes": [
+              {"operator": "OR", "cpeMatch": [
+                {"vulnerable": true, "criteria": "", "matchCriteriaId": "A1B2C3D4-0000-0000-0000-000000000001"},
+                {"vulnerable": true, "criteria": "cpe:2.3:a:vendor:other:*:*:*:*:*:*:*:*", "versionEndExcluding": "2.0", "matchCriteriaId": "A1B2C3D4-0000-0000-0000-000000000002"}
+              ]}
+            ]
+          }
+        ]
+      }
+    }
+  ]
+}`
+
+const testMatchCriteria = `{
+  "resultsPerPage": 3,
+  "startIndex": 0,
+  "totalResults": 3,
+  "format": "NVD_CPEMatchString",
+  "version": "2.0",
+  "timestamp": "2024-01-02T00:00:00.000",
+  "matchStrings": [
+    {"matchString": {
+      "matchCriteriaId": "A1B2C3D4-0000-0000-0000-000000000001",
+      "criteria": "cpe:2.3:a:vendor:product:*:*:*:*:*:*:*:*",
+      "versionStartIncluding": "1.0",
+      "versionEndExcluding": "1.5",
+      "status": "Active",
+      "matches": [
+        {"cpeName": "cpe:2.3:a:vendor:product:1.0:*:*:*:*:*:*:*", "cpeNameId": "00000000-0000-0000-0000-000000000001"},
+        {"cpeName": "cpe:2.3:a:vendor:product:1.4:*:*:*:*:*:*:*", "cpeNameId": "00000000-0000-0000-0000-000000000002"}
+      ]
+    }},
+    {"matchString": {
+      "matchCriteriaId": "A1B2C3D4-0000-0000-0000-000000000002",
+      "criteria": "cpe:2.3:a:vendor:other:*:*:*:*:*:*:*:*",
+      "versionEndExcluding": "3.0",
+      "status": "Active"
+    }},
+    {"matchString": {"criteria": "cpe:2.3:a:vendor:noid:*:*:*:*:*:*:*:*"}}
+  ]
+}`
+
+func TestParseMatchCriteria(t *testing.T) {
+	criteria := make(nvd.MatchCriteria)
+	if err := ParseMatchCriteria(bytes.NewBufferString(testMatchCriteria), criteria); err != nil {
+		t.Fatal(err)
+	}
+	if len(criteria) != 2 {
+		t.Fatalf("expecting 2 match criteria, got %d", len(criteria))
+	}
+	c := criteria["A1B2C3D4-0000-0000-0000-000000000001"]
+	if c == nil {
+		t.Fatal("match criteria is missing")
+	}
+	if c.Criteria != "cpe:2.3:a:vendor:product:*:*:*:*:*:*:*:*" || c.VersionStartIncluding != "1.0" || len(c.Matches) != 2 {
+		t.Fatalf("wrong match criteria %+v", c)
+	}
+}
+
+func TestParseResolvedJSON(t *testing.T) {
+	criteria := make(nvd.MatchCriteria)
+	if err := ParseMatchCriteria(bytes.NewBufferString(testMatchCriteria), criteria); err != nil {
+		t.Fatal(err)
+	}
+
+	unresolved, err := ParseJSON(bytes.NewBufferString(testCriteriaFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolved, err := ParseResolvedJSON(bytes.NewBufferString(testCriteriaFeed), criteria, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unresolved) != 1 || len(resolved) != 1 {
+		t.Fatalf("expecting 1 vulnerability, got %d and %d", len(unresolved), len(resolved))
+	}
+
+	for i, tc := range []struct {
+		cpe                 string
+		unresolved, matched bool
+	}{
+		{"cpe:/a:vendor:product:1.2", false, true},
+		{"cpe:/a:vendor:product:1.5", false, false},
+		{"cpe:/a:vendor:product:0.9", false, false},
+		// ranges embedded in configurations are kept
+		{"cpe:/a:vendor:other:1.9", true, true},
+		{"cpe:/a:vendor:other:2.5", false, false},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			attrs, err := wfn.Parse(tc.cpe)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if matched := len(unresolved[0].Match([]*wfn.Attributes{attrs}, false)) != 0; matched != tc.unresolved {
+				t.Fatalf("expecting unresolved match of %s to be %v, got %v", tc.cpe, tc.unresolved, matched)
+			}
+			if matched := len(resolved[0].Match([]*wfn.Attributes{attrs}, false)) != 0; matched != tc.matched {
+				t.Fatalf("expecting resolved match of %s to be %v, got %v", tc.cpe, tc.matched, matched)
+			}
+		})
+	}
+}
+
+func TestLoadCachedResolvedJSONDictionary(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cvefeed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	feed := filepath.Join(dir, "feed.json")
+	if err := ioutil.WriteFile(feed, []byte(testCriteriaFeed), 0644); err != nil {
+		t.Fatal(err)
+	}
+	criteriaFile := filepath.Join(dir, "criteria.json")
+	if err := ioutil.WriteFile(criteriaFile, []byte(testMatchCriteria), 0644); err != nil {
+		t.Fatal(err)
+	}
+	criteria, err := LoadMatchCriteria(criteriaFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cacheDir := filepath.Join(dir, "cache")
+	attrs := []*wfn.Attributes{{Part: "a", Vendor: "vendor", Product: "product", Version: "1\\.2"}}
+
+	for i, tc := range []struct {
+		criteria nvd.MatchCriteria
+		matched  bool
+		cached   int
+	}{
+		{nil, false, 1},
+		{criteria, true, 2},
+		{criteria, true, 2},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			dict, err := LoadCachedResolvedJSONDictionary(cacheDir, tc.criteria, true, feed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v := dict["CVE-2024-0001"]
+			if v == nil {
+				t.Fatal("CVE-2024-0001 is missing")
+			}
+			if matched := len(v.Match(attrs, false)) != 0; matched != tc.matched {
+				t.Fatalf("expecting match to be %v, got %v", tc.matched, matched)
+			}
+			files, err := filepath.Glob(filepath.Join(cacheDir, "*.gob"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(files) != tc.cached {
+				t.Fatalf("expecting %d cache files, got %d", tc.cached, len(files))
+			}
+		})
+	}
+
+	if _, err := LoadMatchCriteria(filepath.Join(dir, "missing.json")); err == nil {
+		t.Fatal("expecting an error loading missing match criteria")
+	}
+}
@@ -16,10 +16,12 @@ package cvefeed
 
 import (
 	"bytes"
+	"container/list"
+	"fmt"
 	"sync"
 	"unsafe"
 
-	"github.com/facebookincubator/flog"
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/wfn"
 )
 
@@ -60,10 +62,10 @@ type MatchResult struct {
 
 // cachedCVEs stores cached CVEs, a channel to signal if the value is ready
 type cachedCVEs struct {
-	res           []MatchResult
-	ready         chan struct{}
-	size          int64
-	evictionIndex int // position in eviction queue
+	res          []MatchResult
+	ready        chan struct{}
+	size         int64
+	evictionElem *list.Element // position in eviction queue
 }
 
 // updateResSize calculates the size of cached MatchResult and assigns it to cves.size
@@ -97,9 +99,44 @@ type Cache struct {
 	mu             sync.Mutex
 	Dict           Dictionary
 	Idx            Index
-	RequireVersion bool  // ignore matching specifications that have Version == ANY
-	MaxSize        int64 // maximum size of the cache, 0 -- unlimited, -1 -- no caching
-	size           int64 // current size of the cache
+	InvertedIdx    *InvertedIndex // used instead of Dict for candidate lookup, if Idx isn't set
+	RequireVersion bool           // ignore matching specifications that have Version == ANY
+	MaxSize        int64          // maximum size of the cache, 0 -- unlimited, -1 -- no caching
+	MaxEntries     int            // maximum number of cached CPE lists, 0 -- unlimited
+	size           int64          // current size of the cache
+	hits           int64
+	misses         int64
+	evictions      int64
+}
+
+// CacheStats are statistics of the cache, to tune its size with
+type CacheStats struct {
+	// Hits is the number of lookups of CPE lists which were cached, or being matched by another goroutine
+	Hits int64
+	// Misses is the number of lookups of CPE lists which had to be matched
+	Misses int64
+	// Evictions is the number of cached CPE lists evicted to keep the cache within its limits
+	Evictions int64
+	// Entries and Size are the number of cached CPE lists and their approximate size in bytes
+	Entries int
+	Size    int64
+	// MaxEntries and MaxSize are the limits of the cache, 0 if there's no limit
+	MaxEntries int
+	MaxSize    int64
+}
+
+// HitRatio returns the share of lookups which were cached, 0 if there were none
+func (s CacheStats) HitRatio() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// String returns the statistics in a single line, to log them
+func (s CacheStats) String() string {
+	return fmt.Sprintf("hits=%d misses=%d hit_ratio=%.3f evictions=%d entries=%d/%d size=%d/%d",
+		s.Hits, s.Misses, s.HitRatio(), s.Evictions, s.Entries, s.MaxEntries, s.Size, s.MaxSize)
 }
 
 // NewCache creates new Cache instance with dictionary dict.
@@ -115,6 +152,14 @@ func (c *Cache) SetRequireVersion(requireVersion bool) *Cache {
 	return c
 }
 
+// SetInvertedIndex builds an InvertedIndex of the dictionary, which is used to look up vulnerabilities
+// which could match CPE names instead of matching them against the whole dictionary.
+// Returns a pointer to the instance of Cache, for easy chaining.
+func (c *Cache) SetInvertedIndex() *Cache {
+	c.InvertedIdx = NewInvertedIndex(c.Dict)
+	return c
+}
+
 // SetMaxSize sets maximum size of the cache to some pre-defined value,
 // size of 0 disables eviction (makes the cache grow indefinitely),
 // negative size disables caching.
@@ -124,55 +169,134 @@ func (c *Cache) SetMaxSize(size int64) *Cache {
 	return c
 }
 
+// SetMaxEntries sets maximum number of CPE lists whose matches are cached,
+// least recently used ones are evicted once there are more of them; 0 removes the limit.
+// Returns a pointer to the instance of Cache, for easy chaining.
+func (c *Cache) SetMaxEntries(n int) *Cache {
+	c.MaxEntries = n
+	return c
+}
+
+// Stats returns statistics of the cache
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{
+		Hits:       c.hits,
+		Misses:     c.misses,
+		Evictions:  c.evictions,
+		Entries:    c.evictionQ.len(),
+		Size:       c.size,
+		MaxEntries: c.MaxEntries,
+		MaxSize:    c.MaxSize,
+	}
+}
+
 // Get returns slice of CVEs for CPE names from cpes parameter;
 // if CVEs aren't cached (and the feature is enabled) it finds them in cveDict and caches the results
 func (c *Cache) Get(cpes []*wfn.Attributes) []MatchResult {
+	return c.get(matchQuery{cpes: cpes})
+}
+
+// GetWithPlatform is Get which matches running_on conditions of vulnerabilities, operating systems which
+// aren't vulnerable themselves, only against the platforms, e.g. the operating system the CPEs are installed on;
+// other conditions match both the CPEs and the platforms, so vulnerable platforms are matched too, see wfn.MatchPlatform
+func (c *Cache) GetWithPlatform(cpes, platforms []*wfn.Attributes) []MatchResult {
+	return c.get(matchQuery{cpes: cpes, platforms: platforms, platformAware: true})
+}
+
+// matchQuery is the CPE names to match and, in platform-aware matching, their platforms
+type matchQuery struct {
+	cpes, platforms []*wfn.Attributes
+	platformAware   bool
+}
+
+// all returns all CPE names of the query
+func (q matchQuery) all() []*wfn.Attributes {
+	if len(q.platforms) == 0 {
+		return q.cpes
+	}
+	return append(q.cpes[:len(q.cpes):len(q.cpes)], q.platforms...)
+}
+
+// key returns the cache key of the query
+func (q matchQuery) key() string {
+	if !q.platformAware {
+		return cacheKey(q.cpes)
+	}
+	return cacheKey(q.cpes) + "@" + cacheKey(q.platforms)
+}
+
+// match returns CPE names of the query matching the vulnerability
+func (q matchQuery) match(v Vuln, requireVersion bool) []*wfn.Attributes {
+	if q.platformAware {
+		return wfn.MatchPlatform(v, q.cpes, q.platforms, requireVersion)
+	}
+	return v.Match(q.cpes, requireVersion)
+}
+
+// get returns the cached results of the query, matching and caching them if they aren't cached
+func (c *Cache) get(q matchQuery) []MatchResult {
 	// negative max size of the cache disables caching
 	if c.MaxSize < 0 {
-		return c.match(cpes)
+		c.mu.Lock()
+		c.misses++
+		c.mu.Unlock()
+		return c.match(q)
 	}
 
 	// otherwise, let's get to the business
-	key := cacheKey(cpes)
+	key := q.key()
 	c.mu.Lock()
 	if c.data == nil {
 		c.data = make(map[string]*cachedCVEs)
 	}
 	cves := c.data[key]
 	if cves != nil {
+		c.hits++
 		// value is being computed, wait till ready
 		c.mu.Unlock()
 		<-cves.ready
 		c.mu.Lock() // TODO: XXX: ugly, consider using atomic.Value instead
-		cves.evictionIndex = c.evictionQ.touch(cves.evictionIndex)
+		// the value could have been evicted meanwhile
+		if c.data[key] == cves {
+			c.evictionQ.touch(cves.evictionElem)
+		}
 		c.mu.Unlock()
 		return cves.res
 	}
+	c.misses++
 	// first request; the goroutine that sent it computes the value
 	cves = &cachedCVEs{ready: make(chan struct{})}
 	c.data[key] = cves
 	c.mu.Unlock()
 	// now other requests for same key wait on the channel, and the requests for the different keys aren't blocked
-	cves.res = c.match(cpes)
+	cves.res = c.match(q)
 	cves.updateResSize(key)
 	c.mu.Lock()
 	c.size += cves.size
+	cves.evictionElem = c.evictionQ.push(key)
 	if c.MaxSize != 0 && c.size > c.MaxSize {
 		c.evict(int64(cacheEvictPercentage * float64(c.MaxSize)))
 	}
-	cves.evictionIndex = c.evictionQ.push(key)
+	if c.MaxEntries != 0 && c.evictionQ.len() > c.MaxEntries {
+		c.evictEntries(c.MaxEntries)
+	}
 	c.mu.Unlock()
 	close(cves.ready)
 	return cves.res
 }
 
-// match will return all match results based on the given cpes
-func (c *Cache) match(cpes []*wfn.Attributes) []MatchResult {
+// match will return all match results based on the given query
+func (c *Cache) match(q matchQuery) []MatchResult {
+	if c.Idx == nil && c.InvertedIdx != nil {
+		return c.matchVulns(q, c.InvertedIdx.Candidates(q.all()))
+	}
 	d := c.Dict
 	if c.Idx != nil {
-		d = c.dictFromIndex(cpes)
+		d = c.dictFromIndex(q.all())
 	}
-	return c.matchDict(cpes, d)
+	return c.matchDict(q, d)
 }
 
 // dictFromIndex creates CVE dictionary from entries indexed by CPE names
@@ -194,7 +318,7 @@ func (c *Cache) dictFromIndex(cpes []*wfn.Attributes) Dictionary {
 
 	for _, cpe := range cpes {
 		if cpe == nil { // should never happen
-			flog.Warning("nil CPE in list")
+			logging.Warningf("nil CPE in list")
 			continue
 		}
 		if cpe.Product != wfn.Any {
@@ -207,9 +331,19 @@ func (c *Cache) dictFromIndex(cpes []*wfn.Attributes) Dictionary {
 }
 
 // match matches the CPE names against internal vulnerability dictionary and returns a slice of matching resutls
-func (c *Cache) matchDict(cpes []*wfn.Attributes, dict Dictionary) (results []MatchResult) {
+func (c *Cache) matchDict(q matchQuery, dict Dictionary) (results []MatchResult) {
 	for _, v := range dict {
-		if matches := v.Match(cpes, c.RequireVersion); len(matches) > 0 {
+		if matches := q.match(v, c.RequireVersion); len(matches) > 0 {
+			results = append(results, MatchResult{v, matches})
+		}
+	}
+	return results
+}
+
+// matchVulns matches the CPE names against the vulnerabilities and returns a slice of matching results
+func (c *Cache) matchVulns(q matchQuery, vulns []Vuln) (results []MatchResult) {
+	for _, v := range vulns {
+		if matches := q.match(v, c.RequireVersion); len(matches) > 0 {
 			results = append(results, MatchResult{v, matches})
 		}
 	}
@@ -219,15 +353,29 @@ func (c *Cache) matchDict(cpes []*wfn.Attributes, dict Dictionary) (results []Ma
 // evict the least recently used records untile nbytes of capacity is achieved or no more records left.
 // It is not concurrency-safe, c.mu should be locked before calling it.
 func (c *Cache) evict(nbytes int64) {
-	for c.size+nbytes > c.MaxSize {
-		key := c.evictionQ.pop()
-		cd, ok := c.data[key]
-		if !ok { // should not happen
-			panic("attempted to evict non-existent record")
-		}
-		c.size -= cd.size
-		delete(c.data, key)
+	for c.size+nbytes > c.MaxSize && c.evictionQ.len() > 0 {
+		c.evictOldest()
+	}
+}
+
+// evictEntries evicts the least recently used records until there are at most n of them.
+// It is not concurrency-safe, c.mu should be locked before calling it.
+func (c *Cache) evictEntries(n int) {
+	for c.evictionQ.len() > n {
+		c.evictOldest()
+	}
+}
+
+// evictOldest evicts the least recently used record, c.mu should be locked before calling it
+func (c *Cache) evictOldest() {
+	key := c.evictionQ.pop()
+	cd, ok := c.data[key]
+	if !ok { // should not happen
+		panic("attempted to evict non-existent record")
 	}
+	c.size -= cd.size
+	c.evictions++
+	delete(c.data, key)
 }
 
 func cacheKey(cpes []*wfn.Attributes) string {
@@ -15,11 +15,16 @@
 package cvefeed
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"sync"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/storage"
 )
 
 // Dictionary is a slice of entries
@@ -43,11 +48,56 @@ func (d *Dictionary) Override(d2 Dictionary) {
 
 // LoadJSONDictionary parses dictionary from multiple NVD vulenrability feed JSON files
 func LoadJSONDictionary(paths ...string) (Dictionary, error) {
-	return LoadFeed(loadJSONFile, paths...)
+	return LoadJSONDictionaryContext(context.Background(), paths...)
+}
+
+// LoadJSONDictionaryContext is LoadJSONDictionary which stops loading when the context is done
+func LoadJSONDictionaryContext(ctx context.Context, paths ...string) (Dictionary, error) {
+	return LoadFeedContext(ctx, loadJSONFile, paths...)
+}
+
+// LoadCompactJSONDictionary is LoadJSONDictionary which drops descriptions and references of vulnerabilities,
+// see ParseCompactJSON
+func LoadCompactJSONDictionary(paths ...string) (Dictionary, error) {
+	return LoadCompactJSONDictionaryContext(context.Background(), paths...)
+}
+
+// LoadCompactJSONDictionaryContext is LoadCompactJSONDictionary which stops loading when the context is done
+func LoadCompactJSONDictionaryContext(ctx context.Context, paths ...string) (Dictionary, error) {
+	return LoadFeedContext(ctx, loadCompactJSONFile, paths...)
+}
+
+// LoadResolvedJSONDictionary is LoadJSONDictionary, or LoadCompactJSONDictionary if compact is set,
+// which resolves CPE matches referencing match criteria by id, see LoadMatchCriteria
+func LoadResolvedJSONDictionary(criteria nvd.MatchCriteria, compact bool, paths ...string) (Dictionary, error) {
+	return LoadResolvedJSONDictionaryContext(context.Background(), criteria, compact, paths...)
+}
+
+// LoadResolvedJSONDictionaryContext is LoadResolvedJSONDictionary which stops loading when the context is done
+func LoadResolvedJSONDictionaryContext(ctx context.Context, criteria nvd.MatchCriteria, compact bool, paths ...string) (Dictionary, error) {
+	return LoadFeedContext(ctx, func(ctx context.Context, path string) ([]Vuln, error) {
+		f, err := openFeed(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("dictionary: failed to load feed %q: %v", path, err)
+		}
+		defer f.Close()
+		return ParseResolvedJSON(f, criteria, compact)
+	}, paths...)
 }
 
 // LoadFeed calls loadFunc for each file in paths and returns the combined outputs in a Dictionary.
 func LoadFeed(loadFunc func(string) ([]Vuln, error), paths ...string) (Dictionary, error) {
+	return LoadFeedContext(context.Background(), func(_ context.Context, path string) ([]Vuln, error) {
+		return loadFunc(path)
+	}, paths...)
+}
+
+// LoadFeedContext is LoadFeed which passes the context to loadFunc;
+// it returns the error of the context if it's done before all feeds are loaded
+func LoadFeedContext(ctx context.Context, loadFunc func(context.Context, string) ([]Vuln, error), paths ...string) (Dictionary, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	dict := make(Dictionary)
 	var wg sync.WaitGroup
 	done := make(chan struct{})
@@ -58,7 +108,7 @@ func LoadFeed(loadFunc func(string) ([]Vuln, error), paths ...string) (Dictionar
 		wg.Add(1)
 		go func(path string) {
 			defer wg.Done()
-			feed, err := loadFunc(path)
+			feed, err := loadFunc(ctx, path)
 			if err != nil {
 				errChan <- fmt.Errorf("dictionary: failed to load feed %q: %v", path, err)
 				return
@@ -88,18 +138,60 @@ func LoadFeed(loadFunc func(string) ([]Vuln, error), paths ...string) (Dictionar
 	close(errChan)
 	<-done
 	<-errDone
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	if len(errs) > 0 {
 		return dict, errors.New(strings.Join(errs, "\n"))
 	}
 	return dict, nil
 }
 
+// openFeed opens the feed file, or object if path is an object store url (see storage package);
+// reading it fails once the context is done
+func openFeed(ctx context.Context, path string) (io.ReadCloser, error) {
+	var f io.ReadCloser
+	var err error
+	if storage.IsURL(path) {
+		f, err = storage.Open(ctx, path)
+	} else {
+		f, err = os.Open(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &contextReader{ctx: ctx, ReadCloser: f}, nil
+}
+
+// contextReader fails reading once the context is done, so parsing of large feeds stops
+type contextReader struct {
+	ctx context.Context
+	io.ReadCloser
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.ReadCloser.Read(p)
+}
+
 // loadJSONFile parses dictionary from NVD vulnerability feed JSON file
-func loadJSONFile(path string) ([]Vuln, error) {
-	f, err := os.Open(path)
+func loadJSONFile(ctx context.Context, path string) ([]Vuln, error) {
+	f, err := openFeed(ctx, path)
 	if err != nil {
 		return nil, fmt.Errorf("dictionary: failed to load feed %q: %v", path, err)
 	}
 	defer f.Close()
 	return ParseJSON(f)
 }
+
+// loadCompactJSONFile parses compact dictionary from NVD vulnerability feed JSON file
+func loadCompactJSONFile(ctx context.Context, path string) ([]Vuln, error) {
+	f, err := openFeed(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("dictionary: failed to load feed %q: %v", path, err)
+	}
+	defer f.Close()
+	return ParseCompactJSON(f)
+}
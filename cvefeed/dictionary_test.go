@@ -0,0 +1,75 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadJSONDictionaryContext(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cvefeed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	feed := filepath.Join(dir, "feed.json")
+	if err := ioutil.WriteFile(feed, []byte(testStreamFeed), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dict, err := LoadJSONDictionaryContext(context.Background(), feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dict) != 2 {
+		t.Fatalf("expecting 2 vulnerabilities, got %d", len(dict))
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := LoadJSONDictionaryContext(ctx, feed); err != context.Canceled {
+		t.Fatalf("expecting %v, got %v", context.Canceled, err)
+	}
+	if _, err := LoadCachedResolvedJSONDictionaryContext(ctx, filepath.Join(dir, "cache"), nil, true, feed); err != context.Canceled {
+		t.Fatalf("expecting %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestOpenFeedCancelled(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cvefeed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	feed := filepath.Join(dir, "feed.json")
+	if err := ioutil.WriteFile(feed, []byte(testStreamFeed), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	f, err := openFeed(ctx, feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	cancel()
+	if _, err := ParseJSON(f); err == nil {
+		t.Fatal("expecting an error reading the feed after the context was cancelled")
+	}
+}
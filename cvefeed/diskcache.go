@@ -0,0 +1,171 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
+)
+
+// cacheFormatVersion is a part of the cache keys, it should be changed when nvd.Compiled changes
+const cacheFormatVersion = "1"
+
+// LoadCachedJSONDictionary is LoadJSONDictionary, or LoadCompactJSONDictionary if compact is set,
+// which caches vulnerabilities compiled from each of the feeds in cacheDir, keyed by checksum of the feed;
+// feeds which didn't change since they were cached are loaded without parsing JSON and CPE names again.
+// Cache files which can't be read or written are ignored.
+func LoadCachedJSONDictionary(cacheDir string, compact bool, paths ...string) (Dictionary, error) {
+	return LoadCachedResolvedJSONDictionary(cacheDir, nil, compact, paths...)
+}
+
+// LoadCachedResolvedJSONDictionary is LoadCachedJSONDictionary which resolves CPE matches referencing match criteria by id,
+// see LoadResolvedJSONDictionary; cache keys depend on the match criteria as well
+func LoadCachedResolvedJSONDictionary(cacheDir string, criteria nvd.MatchCriteria, compact bool, paths ...string) (Dictionary, error) {
+	return LoadCachedResolvedJSONDictionaryContext(context.Background(), cacheDir, criteria, compact, paths...)
+}
+
+// LoadCachedResolvedJSONDictionaryContext is LoadCachedResolvedJSONDictionary which stops loading when the context is done
+func LoadCachedResolvedJSONDictionaryContext(ctx context.Context, cacheDir string, criteria nvd.MatchCriteria, compact bool, paths ...string) (Dictionary, error) {
+	criteriaKey, err := criteriaKeyOf(criteria)
+	if err != nil {
+		return nil, fmt.Errorf("dictionary: can't compute checksum of match criteria: %v", err)
+	}
+	return LoadFeedContext(ctx, func(ctx context.Context, path string) ([]Vuln, error) {
+		return loadCachedJSONFile(ctx, cacheDir, criteria, criteriaKey, compact, path)
+	}, paths...)
+}
+
+func loadCachedJSONFile(ctx context.Context, cacheDir string, criteria nvd.MatchCriteria, criteriaKey string, compact bool, path string) ([]Vuln, error) {
+	key, err := cacheKeyOf(ctx, path, criteriaKey, compact)
+	if err != nil {
+		return nil, fmt.Errorf("dictionary: failed to load feed %q: %v", path, err)
+	}
+	cachePath := filepath.Join(cacheDir, key+".gob")
+
+	compiled, err := readCompiled(cachePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logging.Warningf("can't read cached feed %q: %v", cachePath, err)
+		}
+		if compiled, err = compileJSONFile(ctx, path, criteria, compact); err != nil {
+			return nil, err
+		}
+		if err := writeCompiled(cachePath, compiled); err != nil {
+			logging.Warningf("can't cache feed %q: %v", path, err)
+		}
+	}
+
+	vulns := make([]Vuln, len(compiled))
+	for i, c := range compiled {
+		vulns[i] = c.Vuln()
+	}
+	return vulns, nil
+}
+
+// cacheKeyOf returns the cache key of the feed file
+func cacheKeyOf(ctx context.Context, path, criteriaKey string, compact bool) (string, error) {
+	f, err := openFeed(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	fmt.Fprintf(h, "%s:%t:", cacheFormatVersion, compact)
+	if criteriaKey != "" {
+		fmt.Fprintf(h, "%s:", criteriaKey)
+	}
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// criteriaKeyOf returns the checksum of match criteria, empty if there are none
+func criteriaKeyOf(criteria nvd.MatchCriteria) (string, error) {
+	if len(criteria) == 0 {
+		return "", nil
+	}
+	h := sha256.New()
+	// map keys are sorted, so the checksum doesn't depend on the order criteria were loaded in
+	if err := json.NewEncoder(h).Encode(criteria); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func compileJSONFile(ctx context.Context, path string, criteria nvd.MatchCriteria, compact bool) ([]*nvd.Compiled, error) {
+	f, err := openFeed(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("dictionary: failed to load feed %q: %v", path, err)
+	}
+	defer f.Close()
+
+	var compiled []*nvd.Compiled
+	err = decodeFeed(f, func(cve *schema.NVDCVEFeedJSON10DefCVEItem) {
+		if cve.Configurations != nil {
+			criteria.Resolve(cve)
+			compiled = append(compiled, nvd.Compile(cve, compact))
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cvefeed.ParseJSON: %v", err)
+	}
+	return compiled, nil
+}
+
+func readCompiled(path string) ([]*nvd.Compiled, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var compiled []*nvd.Compiled
+	if err := gob.NewDecoder(f).Decode(&compiled); err != nil {
+		return nil, err
+	}
+	return compiled, nil
+}
+
+// writeCompiled writes the cache file atomically, so concurrent runs don't read partially written files
+func writeCompiled(path string, compiled []*nvd.Compiled) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if err := gob.NewEncoder(tmp).Encode(compiled); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
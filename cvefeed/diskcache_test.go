@@ -0,0 +1,102 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestLoadCachedJSONDictionary(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cvefeed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	feed := filepath.Join(dir, "feed.json")
+	if err := ioutil.WriteFile(feed, []byte(testStreamFeed), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cacheDir := filepath.Join(dir, "cache")
+
+	check := func(t *testing.T, compact bool) {
+		dict, err := LoadCachedJSONDictionary(cacheDir, compact, feed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(dict) != 2 {
+			t.Fatalf("expecting 2 vulnerabilities, got %d", len(dict))
+		}
+		v := dict["CVE-2020-0001"]
+		if v == nil {
+			t.Fatal("CVE-2020-0001 is missing")
+		}
+		if cves := v.CVEs(); len(cves) != 2 {
+			t.Fatalf("expecting 2 cves, got %v", cves)
+		}
+		attrs := []*wfn.Attributes{{Part: "a", Vendor: "vendor", Product: "product", Version: "1\\.0"}}
+		if len(v.Match(attrs, false)) != 1 {
+			t.Fatal("expecting a match")
+		}
+	}
+	cached := func() []string {
+		files, err := filepath.Glob(filepath.Join(cacheDir, "*.gob"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return files
+	}
+
+	t.Run("compile", func(t *testing.T) {
+		check(t, true)
+		if n := len(cached()); n != 1 {
+			t.Fatalf("expecting the feed to be cached, got %d cache files", n)
+		}
+	})
+	t.Run("cached", func(t *testing.T) {
+		check(t, true)
+		if n := len(cached()); n != 1 {
+			t.Fatalf("expecting the cache to be used, got %d cache files", n)
+		}
+	})
+	t.Run("not compact", func(t *testing.T) {
+		check(t, false)
+		if n := len(cached()); n != 2 {
+			t.Fatalf("expecting another cache file, got %d cache files", n)
+		}
+	})
+	t.Run("corrupted", func(t *testing.T) {
+		for _, file := range cached() {
+			if err := ioutil.WriteFile(file, []byte("corrupted"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		check(t, true)
+		check(t, true)
+	})
+	t.Run("changed", func(t *testing.T) {
+		if err := ioutil.WriteFile(feed, []byte(testStreamFeed+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		check(t, true)
+		if n := len(cached()); n != 3 {
+			t.Fatalf("expecting changed feed to be cached again, got %d cache files", n)
+		}
+	})
+}
@@ -121,3 +121,50 @@ func TestCacheEviction(t *testing.T) {
 	}
 	t.Logf("sequentual run #2: cache size %d/%d; %d records cached", cache.size, cache.MaxSize, len(cache.data))
 }
+
+func TestCacheStats(t *testing.T) {
+	items, err := LoadFeed(func(_ string) ([]Vuln, error) {
+		return ParseJSON(bytes.NewBufferString(testJSONdict))
+	}, "")
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	inventory := func(product string) []*wfn.Attributes {
+		return []*wfn.Attributes{{Part: "a", Vendor: "microsoft", Product: product, Version: "5\\.4"}}
+	}
+
+	cache := NewCache(items).SetMaxEntries(2)
+	for _, product := range []string{"ie", "edge", "ie", "word", "edge", "ie"} {
+		cache.Get(inventory(product))
+	}
+	// ie hits once, word evicts edge which is then matched again and evicts ie
+	expect := CacheStats{Hits: 1, Misses: 5, Evictions: 3, Entries: 2, MaxEntries: 2}
+	stats := cache.Stats()
+	expect.Size = stats.Size
+	if stats != expect {
+		t.Fatalf("expecting %+v, got %+v", expect, stats)
+	}
+	if ratio := stats.HitRatio(); ratio != 1.0/6 {
+		t.Fatalf("wrong hit ratio %v", ratio)
+	}
+	if len(cache.data) != 2 {
+		t.Fatalf("expecting 2 cached records, got %d", len(cache.data))
+	}
+
+	// records bigger than the cache aren't kept
+	cache = NewCache(items).SetMaxSize(1)
+	if matches := cache.Get(inventory("ie")); len(matches) != 1 {
+		t.Fatalf("expecting 1 match, got %d", len(matches))
+	}
+	if stats := cache.Stats(); stats.Entries != 0 || stats.Size != 0 || stats.Evictions != 1 {
+		t.Fatalf("expecting the record to be evicted, got %+v", stats)
+	}
+
+	// lookups aren't cached if caching is disabled
+	cache = NewCache(items).SetMaxSize(-1)
+	cache.Get(inventory("ie"))
+	cache.Get(inventory("ie"))
+	if stats := cache.Stats(); stats.Hits != 0 || stats.Misses != 2 || stats.Entries != 0 {
+		t.Fatalf("expecting lookups to miss, got %+v", stats)
+	}
+}
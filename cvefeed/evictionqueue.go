@@ -15,70 +15,34 @@
 package cvefeed
 
 import (
-	"container/heap"
-	"time"
+	"container/list"
 )
 
-type evictionData struct {
-	key    string    // which key in Cache.data refers to it
-	index  int       // the index of item on the heap
-	access time.Time // last access time
-}
-
-// evictionQueue is a priority queue for LRU cache
+// evictionQueue is a queue of keys of the LRU cache, from the least to the most recently used one
 type evictionQueue struct {
-	q evictionHeap
+	l list.List
 }
 
-// pop pops next key to evict
+// pop removes the least recently used key from the queue and returns it, empty string if the queue is empty
 func (eq *evictionQueue) pop() string {
-	if eq.q.Len() > 0 {
-		return heap.Pop(&eq.q).(*evictionData).key
-	}
-	return ""
-}
-
-// push pushes a key onto heap, returns the index item ended up at.
-func (eq *evictionQueue) push(key string) int {
-	index := eq.q.Len()
-	ed := &evictionData{
-		key:    key,
-		index:  index,
-		access: time.Now(),
+	e := eq.l.Front()
+	if e == nil {
+		return ""
 	}
-	heap.Push(&eq.q, ed)
-	return ed.index
+	return eq.l.Remove(e).(string)
 }
 
-// touch updates the access time of the item at index, returns the new index of that item.
-func (eq *evictionQueue) touch(index int) int {
-	ed := eq.q[index]
-	ed.access = time.Now()
-	heap.Fix(&eq.q, index)
-	return ed.index
-}
-
-// evictionHeap is a slice of evictionData that implements heap.Interface
-type evictionHeap []*evictionData
-
-func (eh evictionHeap) Len() int { return len(eh) }
-
-func (eh evictionHeap) Less(i, j int) bool { return eh[i].access.Before(eh[j].access) }
-
-func (eh evictionHeap) Swap(i, j int) {
-	eh[i], eh[j] = eh[j], eh[i]
-	eh[i].index, eh[j].index = i, j
+// push adds the key to the queue as the most recently used one, returns its element to touch it with
+func (eq *evictionQueue) push(key string) *list.Element {
+	return eq.l.PushBack(key)
 }
 
-func (eh *evictionHeap) Push(x interface{}) {
-	ed := x.(*evictionData)
-	ed.index = len(*eh)
-	*eh = append(*eh, ed)
+// touch marks the key of the element as the most recently used one
+func (eq *evictionQueue) touch(e *list.Element) {
+	eq.l.MoveToBack(e)
 }
 
-func (eh *evictionHeap) Pop() interface{} {
-	old := *eh
-	ed := old[len(old)-1]
-	*eh = old[:len(old)-1]
-	return ed
+// len returns the number of keys in the queue
+func (eq *evictionQueue) len() int {
+	return eq.l.Len()
 }
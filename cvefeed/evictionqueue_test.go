@@ -15,70 +15,58 @@
 package cvefeed
 
 import (
+	"container/list"
+	"reflect"
 	"testing"
-	"time"
 )
 
 func TestEvictionQueue(t *testing.T) {
 	var q evictionQueue
 	cases := []string{"hello", "world", "quux", "baz", "foo"}
+	elems := make([]*list.Element, len(cases))
 	for i, c := range cases {
-		idx := q.push(c)
-		if idx != i {
-			t.Errorf("push() returned wrong index %d (%d was expected)", idx, i)
-		}
-		time.Sleep(1 * time.Millisecond)
+		elems[i] = q.push(c)
+	}
+	if q.len() != len(cases) {
+		t.Fatalf("expecting %d keys in the queue, got %d", len(cases), q.len())
 	}
 
 	// first, it should appear in order
-	for i := range cases {
-		if cases[i] != q.q[i].key {
-			t.Errorf("unexpected queue order (before touch-ing):\nexpected %v\ngot      %v", cases, listKeys(q.q))
-			break
-		}
+	if keys := queueKeys(&q); !reflect.DeepEqual(keys, cases) {
+		t.Errorf("unexpected queue order (before touch-ing):\nexpected %v\ngot      %v", cases, keys)
 	}
 
 	// touch it in reverse order
 	for i := len(cases) - 1; i >= 0; i-- {
-		q.touch(i)
-		time.Sleep(1 * time.Millisecond)
+		q.touch(elems[i])
+	}
+	expect := []string{"foo", "baz", "quux", "world", "hello"}
+	if keys := queueKeys(&q); !reflect.DeepEqual(keys, expect) {
+		t.Errorf("unexpected queue order (after touch-ing):\nexpected %v\ngot      %v", expect, keys)
 	}
 
-	// now baz and quux should be after foo and hello and world should be the last ones
-	// but the exact order is non-deterministic
-	for i, item := range q.q {
-		switch i {
-		case 0:
-			if item.key != "foo" {
-				t.Errorf("unexpected queue order (after touch-ing): %q at position %d", item.key, i)
-			}
-		case 1, 2:
-			if item.key != "baz" && item.key != "quux" {
-				t.Errorf("unexpected queue order (after touch-ing): %q at position %d", item.key, i)
-			}
-		case 3, 4:
-			if item.key != "hello" && item.key != "world" {
-				t.Errorf("unexpected queue order (after touch-ing): %q at position %d", item.key, i)
-			}
-		default:
-			t.Fatal("unreacheable code reached o_O")
-		}
+	// touching the least recently used key makes it the most recently used one
+	q.touch(elems[4])
+	expect = []string{"baz", "quux", "world", "hello", "foo"}
+	if keys := queueKeys(&q); !reflect.DeepEqual(keys, expect) {
+		t.Errorf("unexpected queue order (after touch-ing foo):\nexpected %v\ngot      %v", expect, keys)
 	}
 
-	// but when pop-ing the values from heap, it should come in order reverse to the one we started with
-	for i := len(cases) - 1; i >= 0; i-- {
-		item := q.pop()
-		if item != cases[i] {
-			t.Errorf("unexpected queue order (while pop-ing):\nexpected %v\ngot      %v", cases, listKeys(q.q))
-			break
+	// pop-ing returns the least recently used keys first
+	for _, key := range expect {
+		if item := q.pop(); item != key {
+			t.Errorf("unexpected queue order (while pop-ing): expected %q, got %q", key, item)
 		}
 	}
+	if item := q.pop(); item != "" || q.len() != 0 {
+		t.Errorf("expecting the queue to be empty, popped %q", item)
+	}
 }
 
-func listKeys(in []*evictionData) []string {
-	out := make([]string, len(in))
-	for i, item := range in {
-		out[i] = item.key
+func queueKeys(q *evictionQueue) []string {
+	var keys []string
+	for e := q.l.Front(); e != nil; e = e.Next() {
+		keys = append(keys, e.Value.(string))
 	}
-	return out
+	return keys
 }
@@ -19,63 +19,152 @@
 package cvefeed
 
 import (
-	"bufio"
-	"compress/gzip"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 
+	"github.com/facebookincubator/nvdtools/compress"
 	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
 	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
 )
 
 // ParseJSON parses JSON dictionary from NVD vulnerability feed
+// both 1.x feeds and 2.0 feeds or CVE API responses are supported
+// the feed is decoded item by item, so only the vulnerabilities are kept in memory
 func ParseJSON(in io.Reader) ([]Vuln, error) {
-	feed, err := getFeed(in)
-	if err != nil {
-		return nil, fmt.Errorf("cvefeed.ParseJSON: %v", err)
+	return parseJSON(in, nil, nvd.ToVuln)
+}
+
+// ParseCompactJSON is ParseJSON which drops descriptions and references of vulnerabilities,
+// to use when they aren't needed besides matching; see nvd.ToCompactVuln
+func ParseCompactJSON(in io.Reader) ([]Vuln, error) {
+	return parseJSON(in, nil, nvd.ToCompactVuln)
+}
+
+// ParseResolvedJSON is ParseJSON, or ParseCompactJSON if compact is set,
+// which resolves CPE matches referencing match criteria by id, see nvd.MatchCriteria.Resolve
+func ParseResolvedJSON(in io.Reader, criteria nvd.MatchCriteria, compact bool) ([]Vuln, error) {
+	if compact {
+		return parseJSON(in, criteria, nvd.ToCompactVuln)
 	}
+	return parseJSON(in, criteria, nvd.ToVuln)
+}
 
-	vulns := make([]Vuln, 0, len(feed.CVEItems))
-	for _, cve := range feed.CVEItems {
-		if cve != nil && cve.Configurations != nil {
-			vulns = append(vulns, nvd.ToVuln(cve))
+func parseJSON(in io.Reader, criteria nvd.MatchCriteria, toVuln func(*schema.NVDCVEFeedJSON10DefCVEItem) *nvd.Vuln) ([]Vuln, error) {
+	var vulns []Vuln
+	err := decodeFeed(in, func(cve *schema.NVDCVEFeedJSON10DefCVEItem) {
+		if cve.Configurations != nil {
+			criteria.Resolve(cve)
+			vulns = append(vulns, toVuln(cve))
 		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cvefeed.ParseJSON: %v", err)
 	}
 	return vulns, nil
 }
 
-func getFeed(in io.Reader) (*schema.NVDCVEFeedJSON10, error) {
+// DecodeJSONItems decodes items of a 1.x or 2.0 feed, which may be compressed with gzip, zstd or xz, one by one
+// and calls fn for each of them, for tools which need the items themselves rather than Vulns
+func DecodeJSONItems(in io.Reader, fn func(*schema.NVDCVEFeedJSON10DefCVEItem)) error {
+	return decodeFeed(in, fn)
+}
+
+// DecodeJSONItemsContext is DecodeJSONItems which stops decoding when the context is done
+// and returns the error of the context
+func DecodeJSONItemsContext(ctx context.Context, in io.Reader, fn func(*schema.NVDCVEFeedJSON10DefCVEItem)) error {
+	err := decodeFeed(&contextReader{ctx: ctx, ReadCloser: ioutil.NopCloser(in)}, fn)
+	if ctxErr := ctx.Err(); err != nil && ctxErr != nil {
+		return ctxErr
+	}
+	return err
+}
+
+// decodeFeed decodes items of the feed one by one, calling fn for each of them;
+// 2.0 records are in the vulnerabilities array, they're converted to 1.x items
+func decodeFeed(in io.Reader, fn func(*schema.NVDCVEFeedJSON10DefCVEItem)) error {
 	reader, err := setupReader(in)
 	if err != nil {
-		return nil, fmt.Errorf("can't setup reader: %v", err)
+		return fmt.Errorf("can't setup reader: %v", err)
 	}
 	defer reader.Close()
 
-	var feed schema.NVDCVEFeedJSON10
-	if err := json.NewDecoder(reader).Decode(&feed); err != nil {
-		return nil, err
+	dec := json.NewDecoder(reader)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case "CVE_Items":
+			err = decodeArray(dec, func() error {
+				var cve schema.NVDCVEFeedJSON10DefCVEItem
+				if err := dec.Decode(&cve); err != nil {
+					return err
+				}
+				fn(&cve)
+				return nil
+			})
+		case "vulnerabilities":
+			err = decodeArray(dec, func() error {
+				var vuln schema.CVEAPIJSON20DefVulnerability
+				if err := dec.Decode(&vuln); err != nil {
+					return err
+				}
+				if vuln.CVE != nil {
+					fn(nvd.FromJSON20(vuln.CVE))
+				}
+				return nil
+			})
+		default:
+			// other keys are feed metadata
+			var skip json.RawMessage
+			err = dec.Decode(&skip)
+		}
+		if err != nil {
+			return err
+		}
 	}
-	return &feed, nil
+	return expectDelim(dec, '}')
 }
 
-func setupReader(in io.Reader) (src io.ReadCloser, err error) {
-	r := bufio.NewReader(in)
-	header, err := r.Peek(2)
+// decodeArray calls decodeElem for each element of JSON array, null is decoded as an empty array
+func decodeArray(dec *json.Decoder, decodeElem func() error) error {
+	tok, err := dec.Token()
 	if err != nil {
-		return nil, err
+		return err
 	}
-	// assume plain text first
-	src = ioutil.NopCloser(r)
-	// replace with gzip.Reader if gzip'ed
-	if header[0] == 0x1f && header[1] == 0x8b { // file is gzip'ed
-		zr, err := gzip.NewReader(r)
-		if err != nil {
-			return nil, err
+	if tok == nil {
+		return nil
+	}
+	if tok != json.Delim('[') {
+		return fmt.Errorf("expecting array, got %v", tok)
+	}
+	for dec.More() {
+		if err := decodeElem(); err != nil {
+			return err
 		}
-		src = zr
 	}
-	// TODO: maybe support .zip
-	return src, nil
+	return expectDelim(dec, ']')
+}
+
+func expectDelim(dec *json.Decoder, delim json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok != delim {
+		return fmt.Errorf("expecting %v, got %v", delim, tok)
+	}
+	return nil
+}
+
+// setupReader decompresses the feed if it's compressed with gzip, zstd or xz
+func setupReader(in io.Reader) (io.ReadCloser, error) {
+	return compress.NewReader(in)
 }
@@ -0,0 +1,135 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const testStreamFeed = `{
+  "CVE_data_type": "CVE",
+  "CVE_data_numberOfCVEs": "3",
+  "CVE_Items": [
+    {
+      "cve": {
+        "CVE_data_meta": {"ID": "CVE-2020-0001"},
+        "description": {"description_data": [{"lang": "en", "value": "description"}]},
+        "references": {"reference_data": [
+          {"name": "https://example.com/advisory", "url": "https://example.com/advisory"},
+          {"name": "CVE-2020-0002", "url": "https://cve.mitre.org/cgi-bin/cvename.cgi?name=CVE-2020-0002"}
+        ]}
+      },
+      "configurations": {"nodes": [{"operator": "OR", "cpe_match": [{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:vendor:product:1.0:*:*:*:*:*:*:*"}]}]},
+      "publishedDate": "2020-01-01T00:00Z"
+    },
+    null,
+    {"cve": {"CVE_data_meta": {"ID": "CVE-2020-0003"}}}
+  ],
+  "CVE_data_timestamp": "2020-01-02T00:00Z",
+  "vulnerabilities": [
+    {"cve": {"id": "CVE-2021-0001", "published": "2021-01-01T00:00:00.000", "configurations": []}}
+  ]
+}`
+
+func TestParseJSONStream(t *testing.T) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte(testStreamFeed))
+	zw.Close()
+
+	for i, tc := range []struct {
+		data  []byte
+		parse func([]byte) ([]Vuln, error)
+	}{
+		{[]byte(testStreamFeed), func(b []byte) ([]Vuln, error) { return ParseJSON(bytes.NewReader(b)) }},
+		{gz.Bytes(), func(b []byte) ([]Vuln, error) { return ParseJSON(bytes.NewReader(b)) }},
+		{[]byte(testStreamFeed), func(b []byte) ([]Vuln, error) { return ParseCompactJSON(bytes.NewReader(b)) }},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			vulns, err := tc.parse(tc.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, v := range vulns {
+				ids = append(ids, v.ID())
+			}
+			// items without configurations are skipped
+			if expect := []string{"CVE-2020-0001", "CVE-2021-0001"}; !reflect.DeepEqual(ids, expect) {
+				t.Fatalf("expecting %v, got %v", expect, ids)
+			}
+
+			v := vulns[0]
+			if expect := []string{"CVE-2020-0001", "CVE-2020-0002"}; !reflect.DeepEqual(v.CVEs(), expect) {
+				t.Fatalf("expecting cves %v, got %v", expect, v.CVEs())
+			}
+			if v.Published().IsZero() {
+				t.Fatal("published date is missing")
+			}
+			attrs := []*wfn.Attributes{{Part: "a", Vendor: "vendor", Product: "product", Version: "1\\.0"}}
+			if len(v.Match(attrs, false)) != 1 {
+				t.Fatal("expecting a match")
+			}
+		})
+	}
+}
+
+func TestParseJSONStreamErrors(t *testing.T) {
+	for i, in := range []string{
+		``,
+		`[]`,
+		`{"CVE_Items": {}}`,
+		`{"CVE_Items": [{"cve": 1}]}`,
+		`{"CVE_Items": [`,
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			if _, err := ParseJSON(bytes.NewBufferString(in)); err == nil {
+				t.Fatalf("expecting %q to fail", in)
+			}
+		})
+	}
+}
+
+func TestDecodeJSONItemsContext(t *testing.T) {
+	var ids []string
+	err := DecodeJSONItemsContext(context.Background(), bytes.NewBufferString(testStreamFeed), func(item *schema.NVDCVEFeedJSON10DefCVEItem) {
+		if item.CVE != nil {
+			ids = append(ids, item.CVE.CVEDataMeta.ID)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := []string{"CVE-2020-0001", "CVE-2020-0003", "CVE-2021-0001"}; !reflect.DeepEqual(ids, expect) {
+		t.Fatalf("expecting %v, got %v", expect, ids)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = DecodeJSONItemsContext(ctx, bytes.NewBufferString(testStreamFeed), func(*schema.NVDCVEFeedJSON10DefCVEItem) {
+		t.Fatal("no items should be decoded after the context was cancelled")
+	})
+	if err != context.Canceled {
+		t.Fatalf("expecting %v, got %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// ProductFilter restricts matching to vulnerabilities of some vendors and products, or excludes the noisy ones,
+// e.g. linux:linux_kernel when kernels are tracked elsewhere; it's applied to a dictionary before it's indexed,
+// so the filtered out vulnerabilities cost nothing when matching
+type ProductFilter struct {
+	allow, deny map[indexKey]bool
+}
+
+// NewProductFilter creates a filter which allows vendors and products of the allow list, or all of them if it's empty,
+// except those of the deny list; entries are a vendor, matching all of its products, or vendor:product
+func NewProductFilter(allow, deny []string) (*ProductFilter, error) {
+	var f ProductFilter
+	var err error
+	if f.allow, err = parseProductList(allow); err != nil {
+		return nil, fmt.Errorf("bad allow list: %v", err)
+	}
+	if f.deny, err = parseProductList(deny); err != nil {
+		return nil, fmt.Errorf("bad deny list: %v", err)
+	}
+	return &f, nil
+}
+
+// parseProductList parses the list of vendor or vendor:product entries, vendor-only entries have ANY product
+func parseProductList(entries []string) (map[indexKey]bool, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+	keys := make(map[indexKey]bool, len(entries))
+	for _, entry := range entries {
+		parts := strings.Split(strings.ToLower(strings.TrimSpace(entry)), ":")
+		if len(parts) > 2 || parts[0] == "" || parts[len(parts)-1] == "" {
+			return nil, fmt.Errorf("%q should be vendor or vendor:product", entry)
+		}
+		key := indexKey{vendor: parts[0], product: wfn.Any}
+		if len(parts) == 2 {
+			key.product = parts[1]
+		}
+		keys[key] = true
+	}
+	return keys, nil
+}
+
+// Allows returns true if the filter allows the vendor and product of the CPE name; they're compared literally,
+// so ANY or wildcard products are listed by vendor entries only and ANY or wildcard vendors aren't listed at all
+func (f *ProductFilter) Allows(attrs *wfn.Attributes) bool {
+	if f == nil {
+		return true
+	}
+	if attrs == nil {
+		return false
+	}
+	key := indexKey{vendor: strings.ToLower(wfn.StripSlashes(attrs.Vendor)), product: strings.ToLower(wfn.StripSlashes(attrs.Product))}
+	if f.allow != nil && !listed(f.allow, key) {
+		return false
+	}
+	return !listed(f.deny, key)
+}
+
+// listed returns true if the vendor, or the vendor and product are in the list
+func listed(list map[indexKey]bool, key indexKey) bool {
+	return list[key] || list[indexKey{vendor: key.vendor, product: wfn.Any}]
+}
+
+// Filter returns the dictionary of vulnerabilities which mention any CPE name the filter allows,
+// vulnerabilities mentioning denied products together with allowed ones are kept; d isn't modified
+func (d Dictionary) Filter(f *ProductFilter) Dictionary {
+	if f == nil {
+		return d
+	}
+	filtered := make(Dictionary, len(d))
+	for id, vuln := range d {
+		for _, attrs := range vuln.Config() {
+			if f.Allows(attrs) {
+				filtered[id] = vuln
+				break
+			}
+		}
+	}
+	return filtered
+}
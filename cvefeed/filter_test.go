@@ -0,0 +1,86 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]Vuln, error) {
+		return ParseJSON(bytes.NewBufferString(testIndexFeed))
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tc := range []struct {
+		allow, deny []string
+		expect      []string
+	}{
+		{
+			expect: []string{"CVE-0001", "CVE-0002", "CVE-0003", "CVE-0004", "CVE-0005", "CVE-0006"},
+		},
+		{
+			allow:  []string{"vendor"},
+			expect: []string{"CVE-0001", "CVE-0003", "CVE-0004"},
+		},
+		{
+			allow:  []string{"vendor:product"},
+			expect: []string{"CVE-0001"},
+		},
+		{
+			// CVE-0005 mentions other:app as well
+			deny:   []string{"other:os"},
+			expect: []string{"CVE-0001", "CVE-0002", "CVE-0003", "CVE-0004", "CVE-0005", "CVE-0006"},
+		},
+		{
+			deny:   []string{"VENDOR:Product", "other", "unrelated"},
+			expect: []string{"CVE-0002", "CVE-0003", "CVE-0004"},
+		},
+		{
+			allow:  []string{"vendor"},
+			deny:   []string{"vendor:product"},
+			expect: []string{"CVE-0003", "CVE-0004"},
+		},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			var f *ProductFilter
+			if tc.allow != nil || tc.deny != nil {
+				if f, err = NewProductFilter(tc.allow, tc.deny); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var got []string
+			for id := range dict.Filter(f) {
+				got = append(got, id)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tc.expect) {
+				t.Fatalf("expected %v, got %v", tc.expect, got)
+			}
+		})
+	}
+
+	for _, entry := range []string{"", ":product", "vendor:", "vendor:product:version"} {
+		if _, err := NewProductFilter(nil, []string{entry}); err == nil {
+			t.Errorf("expected an error for entry %q", entry)
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// indexKey is vendor and product of CPE names in the configurations of vulnerabilities,
+// wfn.Any if the attribute is ANY or has wildcards
+type indexKey struct {
+	vendor, product string
+}
+
+// InvertedIndex maps vendors and products of CPE names to the vulnerabilities which mention them,
+// vulnerabilities which mention CPE names with ANY vendor or product are kept in fallback buckets;
+// unlike Index, lookups don't miss any vulnerability which could match
+type InvertedIndex struct {
+	buckets map[indexKey][]Vuln
+	all     []Vuln
+}
+
+// NewInvertedIndex creates new InvertedIndex of the dictionary
+func NewInvertedIndex(d Dictionary) *InvertedIndex {
+	idx := InvertedIndex{
+		buckets: make(map[indexKey][]Vuln),
+		all:     make([]Vuln, 0, len(d)),
+	}
+	for _, vuln := range d {
+		idx.all = append(idx.all, vuln)
+		seen := make(map[indexKey]bool)
+		for _, cpe := range vuln.Config() {
+			if cpe == nil {
+				continue
+			}
+			key := indexKey{vendor: cpe.Vendor, product: cpe.Product}
+			if wfn.HasWildcard(key.vendor) {
+				key.vendor = wfn.Any
+			}
+			if wfn.HasWildcard(key.product) {
+				key.product = wfn.Any
+			}
+			if !seen[key] {
+				seen[key] = true
+				idx.buckets[key] = append(idx.buckets[key], vuln)
+			}
+		}
+	}
+	return &idx
+}
+
+// Candidates returns vulnerabilities which could match any of the CPE names:
+// the ones in the buckets of their vendor and product and in the fallback buckets;
+// all vulnerabilities are returned if vendor or product of any CPE name is ANY
+func (idx *InvertedIndex) Candidates(cpes []*wfn.Attributes) []Vuln {
+	seen := make(map[Vuln]bool)
+	var vulns []Vuln
+	add := func(key indexKey) {
+		for _, vuln := range idx.buckets[key] {
+			if !seen[vuln] {
+				seen[vuln] = true
+				vulns = append(vulns, vuln)
+			}
+		}
+	}
+	for _, cpe := range cpes {
+		if cpe == nil {
+			continue
+		}
+		if cpe.Vendor == wfn.Any || cpe.Product == wfn.Any {
+			return idx.all
+		}
+		add(indexKey{vendor: cpe.Vendor, product: cpe.Product})
+		add(indexKey{vendor: wfn.Any, product: cpe.Product})
+		add(indexKey{vendor: cpe.Vendor, product: wfn.Any})
+	}
+	add(indexKey{vendor: wfn.Any, product: wfn.Any})
+	return vulns
+}
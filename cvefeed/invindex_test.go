@@ -0,0 +1,100 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const testIndexFeed = `{"CVE_Items": [
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0001"}}, "configurations": {"nodes": [{"operator": "OR", "cpe_match": [
+    {"vulnerable": true, "cpe23Uri": "cpe:2.3:a:vendor:product:1.0:*:*:*:*:*:*:*"}]}]}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0002"}}, "configurations": {"nodes": [{"operator": "OR", "cpe_match": [
+    {"vulnerable": true, "cpe23Uri": "cpe:2.3:a:*:product:*:*:*:*:*:*:*:*"}]}]}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0003"}}, "configurations": {"nodes": [{"operator": "OR", "cpe_match": [
+    {"vulnerable": true, "cpe23Uri": "cpe:2.3:a:vendor:*:*:*:*:*:*:*:*:*"}]}]}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0004"}}, "configurations": {"nodes": [{"operator": "OR", "cpe_match": [
+    {"vulnerable": true, "cpe23Uri": "cpe:2.3:a:vendor:prod*:*:*:*:*:*:*:*:*"}]}]}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0005"}}, "configurations": {"nodes": [{"operator": "AND", "children": [
+    {"operator": "OR", "cpe_match": [{"vulnerable": true, "cpe23Uri": "cpe:2.3:o:other:os:-:*:*:*:*:*:*:*"}]},
+    {"operator": "OR", "cpe_match": [{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:other:app:2.0:*:*:*:*:*:*:*"}]}]}]}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0006"}}, "configurations": {"nodes": [{"operator": "OR", "cpe_match": [
+    {"vulnerable": true, "cpe23Uri": "cpe:2.3:a:unrelated:thing:1.0:*:*:*:*:*:*:*"}]}]}}
+]}`
+
+func TestInvertedIndex(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]Vuln, error) {
+		return ParseJSON(bytes.NewBufferString(testIndexFeed))
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx := NewInvertedIndex(dict)
+	full := NewCache(dict).SetMaxSize(-1)
+	indexed := NewCache(dict).SetMaxSize(-1).SetInvertedIndex()
+
+	ids := func(vulns []Vuln) []string {
+		var ids []string
+		for _, v := range vulns {
+			ids = append(ids, v.ID())
+		}
+		sort.Strings(ids)
+		return ids
+	}
+	matched := func(results []MatchResult) []string {
+		var vulns []Vuln
+		for _, r := range results {
+			vulns = append(vulns, r.CVE)
+		}
+		return ids(vulns)
+	}
+
+	for i, tc := range []struct {
+		cpes       []string
+		candidates int
+	}{
+		{[]string{"cpe:/a:vendor:product:1.0"}, 4},
+		{[]string{"cpe:/a:other:product:1.0"}, 1},
+		{[]string{"cpe:/a:vendor:thing:1.0"}, 2},
+		{[]string{"cpe:/o:other:os:-", "cpe:/a:other:app:2.0"}, 1},
+		{[]string{"cpe:/a:other:app:2.0"}, 1},
+		{[]string{"cpe:/a:nobody:nothing:1.0"}, 0},
+		{[]string{"cpe:/a::product:1.0"}, 6},
+		{[]string{"cpe:/a:vendor"}, 6},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			var attrs []*wfn.Attributes
+			for _, cpe := range tc.cpes {
+				a, err := wfn.Parse(cpe)
+				if err != nil {
+					t.Fatal(err)
+				}
+				attrs = append(attrs, a)
+			}
+			if n := len(idx.Candidates(attrs)); n != tc.candidates {
+				t.Fatalf("expecting %d candidates, got %d: %v", tc.candidates, n, ids(idx.Candidates(attrs)))
+			}
+			expect, got := matched(full.Get(attrs)), matched(indexed.Get(attrs))
+			if fmt.Sprint(expect) != fmt.Sprint(got) {
+				t.Fatalf("expecting matches %v, got %v", expect, got)
+			}
+		})
+	}
+}
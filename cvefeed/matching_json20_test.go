@@ -0,0 +1,183 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const testJSON20 = `{
+  "resultsPerPage": 2,
+  "startIndex": 0,
+  "totalResults": 2,
+  "format": "NVD_CVE",
+  "version": "2.0",
+  "timestamp": "2023-10-12T10:00:00.000",
+  "vulnerabilities": [
+    {
+      "cve": {
+        "id": "CVE-2021-44228",
+        "sourceIdentifier": "security@apache.org",
+        "published": "2021-12-10T10:15:09.143",
+        "lastModified": "2023-04-03T20:15:07.983",
+        "vulnStatus": "Analyzed",
+        "cisaExploitAdd": "2021-12-10",
+        "cisaActionDue": "2021-12-24",
+        "cisaRequiredAction": "Apply updates per vendor instructions.",
+        "descriptions": [{"lang": "en", "value": "Apache Log4j2 JNDI features do not protect against attacker controlled LDAP."}],
+        "metrics": {
+          "cvssMetricV40": [
+            {"source": "cna@example.com", "type": "Secondary", "cvssData": {"version": "4.0", "vectorString": "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:H/SI:H/SA:H", "baseScore": 10.0, "baseSeverity": "CRITICAL"}}
+          ],
+          "cvssMetricV31": [
+            {"source": "cna@example.com", "type": "Secondary", "cvssData": {"version": "3.1", "vectorString": "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:H/A:H", "baseScore": 8.1, "baseSeverity": "HIGH"}},
+            {"source": "nvd@nist.gov", "type": "Primary", "cvssData": {"version": "3.1", "vectorString": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H", "baseScore": 10.0, "baseSeverity": "CRITICAL"}, "exploitabilityScore": 3.9, "impactScore": 6.0}
+          ],
+          "cvssMetricV2": [
+            {"source": "nvd@nist.gov", "type": "Primary", "cvssData": {"version": "2.0", "vectorString": "AV:N/AC:M/Au:N/C:C/I:C/A:C", "baseScore": 9.3}, "baseSeverity": "HIGH"}
+          ]
+        },
+        "weaknesses": [
+          {"source": "nvd@nist.gov", "type": "Primary", "description": [{"lang": "en", "value": "CWE-917"}]},
+          {"source": "security@apache.org", "type": "Secondary", "description": [{"lang": "en", "value": "CWE-20"}, {"lang": "en", "value": "CWE-917"}]}
+        ],
+        "configurations": [
+          {
+            "nodes": [
+              {"operator": "OR", "negate": false, "cpeMatch": [
+                {"vulnerable": true, "criteria": "cpe:2.3:a:apache:log4j:*:*:*:*:*:*:*:*", "versionStartIncluding": "2.0.1", "versionEndExcluding": "2.12.2", "matchCriteriaId": "03FA5E81-F9C0-403E-8A3C-4A1E34B1A02E"}
+              ]}
+            ]
+          },
+          {
+            "operator": "AND",
+            "nodes": [
+              {"operator": "OR", "negate": false, "cpeMatch": [
+                {"vulnerable": true, "criteria": "cpe:2.3:a:siemens:sppa-t3000_ses3000_firmware:*:*:*:*:*:*:*:*", "matchCriteriaId": "B8A6F8B4-9C74-4C5E-B2A7-DDB3B8C7D0A5"}
+              ]},
+              {"operator": "OR", "negate": false, "cpeMatch": [
+                {"vulnerable": false, "criteria": "cpe:2.3:h:siemens:sppa-t3000_ses3000:-:*:*:*:*:*:*:*", "matchCriteriaId": "F7B3E0B4-0F5E-4B51-9F3A-5B4E2C7D6A11"}
+              ]}
+            ]
+          }
+        ],
+        "references": [{"url": "https://logging.apache.org/log4j/2.x/security.html", "source": "security@apache.org", "tags": ["Vendor Advisory"]}]
+      }
+    },
+    {
+      "cve": {
+        "id": "CVE-2023-0001",
+        "sourceIdentifier": "psirt@example.com",
+        "published": "2023-01-01T00:00:00.000",
+        "lastModified": "2023-01-01T00:00:00.000",
+        "vulnStatus": "Awaiting Analysis",
+        "descriptions": [{"lang": "en", "value": "Not analyzed yet."}],
+        "references": []
+      }
+    }
+  ]
+}`
+
+func TestParseJSON20(t *testing.T) {
+	vulns, err := ParseJSON(bytes.NewBufferString(testJSON20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vulns) != 2 {
+		t.Fatalf("expecting 2 vulnerabilities, got %d", len(vulns))
+	}
+
+	v := vulns[0]
+	if v.ID() != "CVE-2021-44228" {
+		t.Fatalf("wrong id %q", v.ID())
+	}
+	if v.CVSSv3BaseScore() != 10.0 || v.CVSSv3Vector() != "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H" {
+		t.Fatalf("primary cvss v3 metric should be used, got %v %q", v.CVSSv3BaseScore(), v.CVSSv3Vector())
+	}
+	if v.CVSSv4BaseScore() != 10.0 || v.CVSSv4Vector() != "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:H/SI:H/SA:H" {
+		t.Fatalf("wrong cvss v4 metric %v %q", v.CVSSv4BaseScore(), v.CVSSv4Vector())
+	}
+	if vulns[1].CVSSv4BaseScore() != 0 || vulns[1].CVSSv4Vector() != "" {
+		t.Fatalf("unexpected cvss v4 metric %v %q", vulns[1].CVSSv4BaseScore(), vulns[1].CVSSv4Vector())
+	}
+	if v.CVSSv2BaseScore() != 9.3 {
+		t.Fatalf("wrong cvss v2 score %v", v.CVSSv2BaseScore())
+	}
+	if cwes := v.CWEs(); len(cwes) != 2 {
+		t.Fatalf("expecting 2 cwes, got %v", cwes)
+	}
+	if published := v.Published(); !published.Equal(time.Date(2021, 12, 10, 10, 15, 0, 0, time.UTC)) {
+		t.Fatalf("wrong published time %v", published)
+	}
+	if modified := v.LastModified(); !modified.Equal(time.Date(2023, 4, 3, 20, 15, 0, 0, time.UTC)) {
+		t.Fatalf("wrong last modified time %v", modified)
+	}
+
+	refs := v.References()
+	if len(refs) != 1 {
+		t.Fatalf("expecting 1 reference, got %+v", refs)
+	}
+	if ref := refs[0]; ref.URL != "https://logging.apache.org/log4j/2.x/security.html" || ref.Source != "security@apache.org" || len(ref.Tags) != 1 || ref.Tags[0] != "Vendor Advisory" {
+		t.Fatalf("wrong reference %+v", ref)
+	}
+	if descs := v.Descriptions(); len(descs) != 1 || descs["en"] != "Apache Log4j2 JNDI features do not protect against attacker controlled LDAP." {
+		t.Fatalf("wrong descriptions %v", descs)
+	}
+	if refs := vulns[1].References(); len(refs) != 0 {
+		t.Fatalf("unexpected references %+v", refs)
+	}
+
+	compact, err := ParseCompactJSON(bytes.NewBufferString(testJSON20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if descs := compact[0].Descriptions(); descs != nil {
+		t.Fatalf("compact vulnerability shouldn't have descriptions, got %v", descs)
+	}
+
+	for i, tc := range []struct {
+		cpes  []string
+		match bool
+	}{
+		{[]string{"cpe:/a:apache:log4j:2.12.1"}, true},
+		{[]string{"cpe:/a:apache:log4j:2.12.2"}, false},
+		{[]string{"cpe:/a:siemens:sppa-t3000_ses3000_firmware:1.0"}, false},
+		{[]string{"cpe:/a:siemens:sppa-t3000_ses3000_firmware:1.0", "cpe:/h:siemens:sppa-t3000_ses3000:-"}, true},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			var attrs []*wfn.Attributes
+			for _, cpe := range tc.cpes {
+				a, err := wfn.Parse(cpe)
+				if err != nil {
+					t.Fatal(err)
+				}
+				attrs = append(attrs, a)
+			}
+			if matched := len(v.Match(attrs, false)) != 0; matched != tc.match {
+				t.Fatalf("expecting match of %v to be %v, got %v", tc.cpes, tc.match, matched)
+			}
+		})
+	}
+
+	// records without configurations don't match anything
+	if m := vulns[1].Match([]*wfn.Attributes{{Part: "a"}}, false); len(m) != 0 {
+		t.Fatalf("unexpected match %v", m)
+	}
+}
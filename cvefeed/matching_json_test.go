@@ -17,6 +17,8 @@ package cvefeed
 import (
 	"bytes"
 	"fmt"
+	"sort"
+	"strings"
 	"testing"
 
 	"github.com/facebookincubator/nvdtools/wfn"
@@ -123,6 +125,140 @@ func TestMatchJSONsmartVersionMatching(t *testing.T) {
 	}
 }
 
+const testConfigurationsFeed = `{"CVE_Items": [
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0001"}}, "configurations": {"nodes": [{"operator": "AND", "children": [
+    {"operator": "OR", "cpe_match": [{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:vendor:app:*:*:*:*:*:*:*:*", "versionEndExcluding": "2.0"}]},
+    {"operator": "OR", "cpe_match": [{"vulnerable": false, "cpe23Uri": "cpe:2.3:o:vendor:os:*:*:*:*:*:*:*:*"}]},
+    {"operator": "OR", "negate": true, "cpe_match": [{"vulnerable": false, "cpe23Uri": "cpe:2.3:a:vendor:hardening:*:*:*:*:*:*:*:*"}]}]}]}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0002"}}, "configurations": {"nodes": [{"operator": "OR", "children": [
+    {"operator": "AND", "children": [
+      {"operator": "OR", "cpe_match": [{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:vendor:lib:1.0:*:*:*:*:*:*:*"}]},
+      {"operator": "OR", "children": [
+        {"operator": "OR", "cpe_match": [{"vulnerable": false, "cpe23Uri": "cpe:2.3:a:vendor:runtime:*:*:*:*:*:*:*:*"}]},
+        {"operator": "AND", "children": [
+          {"operator": "OR", "cpe_match": [{"vulnerable": false, "cpe23Uri": "cpe:2.3:a:other:runtime:*:*:*:*:*:*:*:*"}]},
+          {"operator": "OR", "negate": true, "cpe_match": [{"vulnerable": false, "cpe23Uri": "cpe:2.3:o:vendor:os:*:*:*:*:*:*:*:*"}]}]}]}]},
+    {"operator": "OR", "cpe_match": [{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:vendor:lib2:*:*:*:*:*:*:*:*"}]}]}]}}
+]}`
+
+func TestMatchJSONConfigurations(t *testing.T) {
+	items, err := ParseJSON(bytes.NewBufferString(testConfigurationsFeed))
+	if err != nil {
+		t.Fatalf("failed to parse the dictionary: %v", err)
+	}
+	attrs := map[string]*wfn.Attributes{
+		"app":           {Part: "a", Vendor: "vendor", Product: "app", Version: "1\\.5"},
+		"app2":          {Part: "a", Vendor: "vendor", Product: "app", Version: "2\\.0"},
+		"os":            {Part: "o", Vendor: "vendor", Product: "os"},
+		"hardening":     {Part: "a", Vendor: "vendor", Product: "hardening"},
+		"lib":           {Part: "a", Vendor: "vendor", Product: "lib", Version: "1\\.0"},
+		"lib2":          {Part: "a", Vendor: "vendor", Product: "lib2"},
+		"runtime":       {Part: "a", Vendor: "vendor", Product: "runtime"},
+		"other_runtime": {Part: "a", Vendor: "other", Product: "runtime"},
+	}
+	names := func(matches []*wfn.Attributes) string {
+		var names []string
+		for name, a := range attrs {
+			for _, m := range matches {
+				if m == a {
+					names = append(names, name)
+				}
+			}
+		}
+		sort.Strings(names)
+		return strings.Join(names, ",")
+	}
+
+	for i, tc := range []struct {
+		rule                int
+		inventory           []string
+		vulnerable, context string
+	}{
+		{rule: 0, inventory: []string{"app", "os"}, vulnerable: "app", context: "os"},
+		{rule: 0, inventory: []string{"app2", "os"}},
+		{rule: 0, inventory: []string{"app"}},
+		{rule: 0, inventory: []string{"app", "os", "hardening"}},
+		{rule: 0, inventory: []string{"app", "os", "lib"}, vulnerable: "app", context: "os"},
+		{rule: 1, inventory: []string{"lib"}},
+		{rule: 1, inventory: []string{"lib", "runtime"}, vulnerable: "lib", context: "runtime"},
+		{rule: 1, inventory: []string{"lib", "other_runtime"}, vulnerable: "lib", context: "other_runtime"},
+		{rule: 1, inventory: []string{"lib", "other_runtime", "os"}},
+		{rule: 1, inventory: []string{"lib", "runtime", "other_runtime", "os"}, vulnerable: "lib", context: "runtime"},
+		{rule: 1, inventory: []string{"lib2", "os"}, vulnerable: "lib2"},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			var inventory []*wfn.Attributes
+			for _, name := range tc.inventory {
+				inventory = append(inventory, attrs[name])
+			}
+			vulnerable, context := wfn.MatchContext(items[tc.rule], inventory, false)
+			if got := names(vulnerable); got != tc.vulnerable {
+				t.Errorf("expected vulnerable %q, got %q", tc.vulnerable, got)
+			}
+			if got := names(context); got != tc.context {
+				t.Errorf("expected context %q, got %q", tc.context, got)
+			}
+			if n := len(items[tc.rule].Match(inventory, false)); n != len(vulnerable)+len(context) {
+				t.Errorf("expected %d matches, got %d", len(vulnerable)+len(context), n)
+			}
+		})
+	}
+}
+
+func TestCacheGetWithPlatform(t *testing.T) {
+	dict, err := LoadFeed(func(_ string) ([]Vuln, error) {
+		return ParseJSON(bytes.NewBufferString(testConfigurationsFeed))
+	}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	app := &wfn.Attributes{Part: "a", Vendor: "vendor", Product: "app", Version: "1\\.5"}
+	os := &wfn.Attributes{Part: "o", Vendor: "vendor", Product: "os", Version: "11"}
+	runtime := &wfn.Attributes{Part: "a", Vendor: "other", Product: "runtime"}
+	lib := &wfn.Attributes{Part: "a", Vendor: "vendor", Product: "lib", Version: "1\\.0"}
+
+	cache := NewCache(dict).SetInvertedIndex()
+	ids := func(results []MatchResult) string {
+		var ids []string
+		for _, r := range results {
+			ids = append(ids, fmt.Sprintf("%s:%d", r.CVE.ID(), len(r.CPEs)))
+		}
+		sort.Strings(ids)
+		return strings.Join(ids, ",")
+	}
+	for i, tc := range []struct {
+		cpes, platforms []*wfn.Attributes
+		expect          string
+	}{
+		{cpes: []*wfn.Attributes{app}, platforms: []*wfn.Attributes{os}, expect: "CVE-0001:2"},
+		// the OS in the CPEs doesn't satisfy running_on conditions
+		{cpes: []*wfn.Attributes{app, os}},
+		{cpes: []*wfn.Attributes{app}},
+		// running with conditions are satisfied by the CPEs, negated running_on conditions by the platforms
+		{cpes: []*wfn.Attributes{lib, runtime}, expect: "CVE-0002:2"},
+		{cpes: []*wfn.Attributes{lib, runtime}, platforms: []*wfn.Attributes{os}},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			if got := ids(cache.GetWithPlatform(tc.cpes, tc.platforms)); got != tc.expect {
+				t.Fatalf("expected %q, got %q", tc.expect, got)
+			}
+		})
+	}
+	// Get matches the CPEs as usual, regardless of the cached platform-aware results
+	if got := ids(cache.Get([]*wfn.Attributes{app, os})); got != "CVE-0001:2" {
+		t.Fatalf("expected CVE-0001 to match, got %q", got)
+	}
+
+	// running_on conditions match all versions of the OS, but they match it even if the version is required
+	cache = NewCache(dict).SetRequireVersion(true)
+	if got := ids(cache.GetWithPlatform([]*wfn.Attributes{app}, []*wfn.Attributes{os})); got != "CVE-0001:2" {
+		t.Fatalf("expected CVE-0001 to match, got %q", got)
+	}
+	if got := ids(cache.Get([]*wfn.Attributes{app, os})); got != "" {
+		t.Fatalf("expected no matches, got %q", got)
+	}
+}
+
 func BenchmarkMatchJSON(b *testing.B) {
 	inventory := []*wfn.Attributes{
 		{Part: "o", Vendor: "microsoft", Product: "windows_xp", Update: "sp3"},
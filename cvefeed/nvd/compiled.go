@@ -0,0 +1,212 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// Compiled is a vulnerability with its configurations compiled for matching;
+// it can be serialized (e.g. with encoding/gob) and converted to Vuln without parsing CPE names again
+type Compiled struct {
+	Item  *schema.NVDCVEFeedJSON10DefCVEItem
+	Nodes []*CompiledNode
+}
+
+// CompiledNode is a compiled configuration node
+type CompiledNode struct {
+	// All is set for AND operator, OR is used otherwise
+	All      bool
+	Negate   bool
+	Matches  []*CompiledMatch
+	Children []*CompiledNode
+}
+
+// CompiledMatch is a compiled CPE match of a configuration node
+type CompiledMatch struct {
+	CPE                   wfn.Attributes
+	Vulnerable            bool
+	VersionEndExcluding   string
+	VersionEndIncluding   string
+	VersionStartExcluding string
+	VersionStartIncluding string
+}
+
+// Compile compiles configurations of the item
+// if compact is set, configurations, descriptions and references which don't name CVEs are dropped from the item,
+// as they aren't needed besides matching; the item itself isn't modified
+func Compile(cve *schema.NVDCVEFeedJSON10DefCVEItem, compact bool) *Compiled {
+	c := Compiled{Item: cve}
+	if cve.Configurations != nil {
+		for _, node := range cve.Configurations.Nodes {
+			if node != nil {
+				if n, err := compileNode(node); err == nil {
+					c.Nodes = append(c.Nodes, n)
+				}
+			}
+		}
+	}
+	if compact {
+		c.Item = compactItem(cve)
+	}
+	return &c
+}
+
+// Vuln returns the vulnerability
+func (c *Compiled) Vuln() *Vuln {
+	ms := make([]wfn.Matcher, len(c.Nodes))
+	for i, node := range c.Nodes {
+		ms[i] = node.matcher()
+	}
+	return &Vuln{
+		cveItem: c.Item,
+		Matcher: wfn.MatchAny(ms...),
+	}
+}
+
+func compileNode(node *schema.NVDCVEFeedJSON10DefNode) (*CompiledNode, error) {
+	var n CompiledNode
+	for _, match := range node.CPEMatch {
+		if match != nil {
+			if m, err := compileMatch(match); err == nil {
+				n.Matches = append(n.Matches, m)
+			}
+		}
+	}
+	for _, child := range node.Children {
+		if child != nil {
+			if c, err := compileNode(child); err == nil {
+				n.Children = append(n.Children, c)
+			}
+		}
+	}
+
+	if len(n.Matches) == 0 && len(n.Children) == 0 {
+		return nil, fmt.Errorf("empty configuration for node")
+	}
+
+	switch strings.ToUpper(node.Operator) {
+	default:
+		logging.Recordf("unknown operator, defaulting to OR: got %q", node.Operator)
+	case "OR":
+	case "AND":
+		n.All = true
+	}
+	n.Negate = node.Negate
+
+	return &n, nil
+}
+
+// matcher returns an object which knows how to match attributes
+func (n *CompiledNode) matcher() wfn.Matcher {
+	ms := make([]wfn.Matcher, 0, len(n.Matches)+len(n.Children))
+	for _, match := range n.Matches {
+		ms = append(ms, match.matcher())
+	}
+	for _, child := range n.Children {
+		ms = append(ms, child.matcher())
+	}
+
+	var m wfn.Matcher
+	if n.All {
+		m = wfn.MatchAll(ms...)
+	} else {
+		m = wfn.MatchAny(ms...)
+	}
+	if n.Negate {
+		m = wfn.Negate(m)
+	}
+	return m
+}
+
+func compileMatch(nvdMatch *schema.NVDCVEFeedJSON10DefCPEMatch) (*CompiledMatch, error) {
+	parse := func(uri string) (*wfn.Attributes, error) {
+		if uri == "" {
+			return nil, fmt.Errorf("can't parse empty uri")
+		}
+		return wfn.Parse(uri)
+	}
+
+	attrs, err := parse(nvdMatch.Cpe23Uri)
+	if err != nil {
+		if attrs, err = parse(nvdMatch.Cpe22Uri); err != nil {
+			return nil, fmt.Errorf("unable to parse both cpe2.2 and cpe2.3")
+		}
+	}
+
+	return &CompiledMatch{
+		CPE:                   *attrs,
+		Vulnerable:            nvdMatch.Vulnerable,
+		VersionEndExcluding:   nvdMatch.VersionEndExcluding,
+		VersionEndIncluding:   nvdMatch.VersionEndIncluding,
+		VersionStartExcluding: nvdMatch.VersionStartExcluding,
+		VersionStartIncluding: nvdMatch.VersionStartIncluding,
+	}, nil
+}
+
+// matcher returns an object which knows how to match attributes,
+// CPEs which aren't vulnerable are matched as the platform of the vulnerable ones
+func (m *CompiledMatch) matcher() wfn.Matcher {
+	attrs := m.CPE
+	matcher := m.VersionRange().Matcher(&attrs)
+	if !m.Vulnerable {
+		return wfn.Platform(matcher)
+	}
+	return matcher
+}
+
+// VersionRange returns the version range of the match
+func (m *CompiledMatch) VersionRange() wfn.VersionRange {
+	return wfn.VersionRange{
+		StartIncluding: m.VersionStartIncluding,
+		StartExcluding: m.VersionStartExcluding,
+		EndIncluding:   m.VersionEndIncluding,
+		EndExcluding:   m.VersionEndExcluding,
+	}
+}
+
+// compactItem returns a copy of the item without configurations, descriptions and references which don't name CVEs
+func compactItem(cve *schema.NVDCVEFeedJSON10DefCVEItem) *schema.NVDCVEFeedJSON10DefCVEItem {
+	item := *cve
+	item.Configurations = nil
+	if item.CVE != nil {
+		c := *item.CVE
+		c.Affects, c.Description, c.References = nil, nil, compactReferences(c.References)
+		item.CVE = &c
+	}
+	return &item
+}
+
+// compactReferences returns references which name CVEs, with names only
+func compactReferences(refs *schema.CVEJSON40References) *schema.CVEJSON40References {
+	if refs == nil {
+		return nil
+	}
+	var compact []*schema.CVEJSON40Reference
+	for _, ref := range refs.ReferenceData {
+		if ref != nil && cveRegex.MatchString(ref.Name) {
+			compact = append(compact, &schema.CVEJSON40Reference{Name: ref.Name})
+		}
+	}
+	if len(compact) == 0 {
+		return nil
+	}
+	return &schema.CVEJSON40References{ReferenceData: compact}
+}
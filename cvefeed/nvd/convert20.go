@@ -0,0 +1,233 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvd
+
+import (
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+)
+
+// FromJSON20 converts a CVE record of NVD CVE API 2.0 into a 1.x feed item,
+// so it can be matched, overridden and annotated as any other item.
+func FromJSON20(cve *schema.CVEJSON20) *schema.NVDCVEFeedJSON10DefCVEItem {
+	item := &schema.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &schema.CVEJSON40{
+			CVEDataMeta: &schema.CVEJSON40CVEDataMeta{
+				ID:       cve.ID,
+				ASSIGNER: cve.SourceIdentifier,
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: "4.0",
+			Description: &schema.CVEJSON40Description{
+				DescriptionData: langStrings20(cve.Descriptions),
+			},
+			Problemtype: problemtype20(cve.Weaknesses),
+			References:  references20(cve.References),
+		},
+		Configurations: &schema.NVDCVEFeedJSON10DefConfigurations{
+			CVEDataVersion: "4.0",
+		},
+		Impact:           impact20(cve.Metrics),
+		LastModifiedDate: time20(cve.LastModified),
+		PublishedDate:    time20(cve.Published),
+	}
+	if cve.VulnStatus == "Rejected" {
+		item.CVE.CVEDataMeta.STATE = "REJECT"
+	}
+
+	for _, config := range cve.Configurations {
+		if node := node20(config); node != nil {
+			item.Configurations.Nodes = append(item.Configurations.Nodes, node)
+		}
+	}
+
+	if cve.CISAExploitAdd != "" {
+		item.KnownExploited = &schema.NVDCVEFeedJSON10DefKnownExploited{
+			DateAdded:      cve.CISAExploitAdd,
+			DueDate:        cve.CISAActionDue,
+			RequiredAction: cve.CISARequiredAction,
+		}
+	}
+
+	return item
+}
+
+// node20 converts a configuration into a node
+// configurations with a single node are converted directly, otherwise the nodes become children
+func node20(config *schema.CVEJSON20DefConfiguration) *schema.NVDCVEFeedJSON10DefNode {
+	var nodes []*schema.NVDCVEFeedJSON10DefNode
+	for _, n := range config.Nodes {
+		if n == nil {
+			continue
+		}
+		node := &schema.NVDCVEFeedJSON10DefNode{
+			Operator: n.Operator,
+			Negate:   n.Negate,
+		}
+		for _, m := range n.CPEMatch {
+			if m == nil {
+				continue
+			}
+			node.CPEMatch = append(node.CPEMatch, &schema.NVDCVEFeedJSON10DefCPEMatch{
+				Cpe23Uri:              m.Criteria,
+				MatchCriteriaID:       m.MatchCriteriaID,
+				Vulnerable:            m.Vulnerable,
+				VersionStartExcluding: m.VersionStartExcluding,
+				VersionStartIncluding: m.VersionStartIncluding,
+				VersionEndExcluding:   m.VersionEndExcluding,
+				VersionEndIncluding:   m.VersionEndIncluding,
+			})
+		}
+		nodes = append(nodes, node)
+	}
+
+	switch {
+	case len(nodes) == 0:
+		return nil
+	case len(nodes) == 1 && !config.Negate:
+		return nodes[0]
+	}
+
+	operator := config.Operator
+	if operator == "" {
+		operator = "OR"
+	}
+	return &schema.NVDCVEFeedJSON10DefNode{
+		Operator: operator,
+		Negate:   config.Negate,
+		Children: nodes,
+	}
+}
+
+func langStrings20(ss []*schema.CVEJSON20DefLangString) []*schema.CVEJSON40LangString {
+	var out []*schema.CVEJSON40LangString
+	for _, s := range ss {
+		if s != nil {
+			out = append(out, &schema.CVEJSON40LangString{Lang: s.Lang, Value: s.Value})
+		}
+	}
+	return out
+}
+
+func problemtype20(weaknesses []*schema.CVEJSON20DefWeakness) *schema.CVEJSON40Problemtype {
+	data := &schema.CVEJSON40ProblemtypeProblemtypeData{}
+	seen := make(map[string]bool)
+	for _, w := range weaknesses {
+		if w == nil {
+			continue
+		}
+		for _, desc := range langStrings20(w.Description) {
+			if !seen[desc.Value] {
+				seen[desc.Value] = true
+				data.Description = append(data.Description, desc)
+			}
+		}
+	}
+	return &schema.CVEJSON40Problemtype{
+		ProblemtypeData: []*schema.CVEJSON40ProblemtypeProblemtypeData{data},
+	}
+}
+
+func references20(refs []*schema.CVEJSON20DefReference) *schema.CVEJSON40References {
+	out := &schema.CVEJSON40References{}
+	for _, ref := range refs {
+		if ref != nil {
+			out.ReferenceData = append(out.ReferenceData, &schema.CVEJSON40Reference{
+				Name:      ref.URL,
+				Refsource: ref.Source,
+				Tags:      ref.Tags,
+				URL:       ref.URL,
+			})
+		}
+	}
+	return out
+}
+
+// impact20 picks the primary metrics, falling back to the first secondary ones
+// CVSS 3.1 is preferred over 3.0
+func impact20(metrics *schema.CVEJSON20DefMetrics) *schema.NVDCVEFeedJSON10DefImpact {
+	if metrics == nil {
+		return nil
+	}
+	impact := &schema.NVDCVEFeedJSON10DefImpact{}
+
+	var v4 *schema.CVEJSON20DefCVSSMetricV4
+	for _, m := range metrics.CVSSMetricV40 {
+		if m == nil || m.CVSSData == nil {
+			continue
+		}
+		if v4 == nil || (m.Type == "Primary" && v4.Type != "Primary") {
+			v4 = m
+		}
+	}
+	if v4 != nil {
+		impact.BaseMetricV4 = &schema.NVDCVEFeedJSON10DefImpactBaseMetricV4{CVSSV4: v4.CVSSData}
+	}
+
+	var v3 *schema.CVEJSON20DefCVSSMetricV3
+	for _, ms := range [][]*schema.CVEJSON20DefCVSSMetricV3{metrics.CVSSMetricV31, metrics.CVSSMetricV30} {
+		for _, m := range ms {
+			if m == nil || m.CVSSData == nil {
+				continue
+			}
+			if v3 == nil || (m.Type == "Primary" && v3.Type != "Primary") {
+				v3 = m
+			}
+		}
+	}
+	if v3 != nil {
+		impact.BaseMetricV3 = &schema.NVDCVEFeedJSON10DefImpactBaseMetricV3{
+			CVSSV3:              v3.CVSSData,
+			ExploitabilityScore: v3.ExploitabilityScore,
+			ImpactScore:         v3.ImpactScore,
+		}
+	}
+
+	var v2 *schema.CVEJSON20DefCVSSMetricV2
+	for _, m := range metrics.CVSSMetricV2 {
+		if m == nil || m.CVSSData == nil {
+			continue
+		}
+		if v2 == nil || (m.Type == "Primary" && v2.Type != "Primary") {
+			v2 = m
+		}
+	}
+	if v2 != nil {
+		impact.BaseMetricV2 = &schema.NVDCVEFeedJSON10DefImpactBaseMetricV2{
+			AcInsufInfo:             v2.AcInsufInfo,
+			CVSSV2:                  v2.CVSSData,
+			ExploitabilityScore:     v2.ExploitabilityScore,
+			ImpactScore:             v2.ImpactScore,
+			ObtainAllPrivilege:      v2.ObtainAllPrivilege,
+			ObtainOtherPrivilege:    v2.ObtainOtherPrivilege,
+			ObtainUserPrivilege:     v2.ObtainUserPrivilege,
+			Severity:                v2.BaseSeverity,
+			UserInteractionRequired: v2.UserInteractionRequired,
+		}
+	}
+
+	return impact
+}
+
+// time20 converts API 2.0 timestamp into the 1.x layout
+func time20(s string) string {
+	t, err := time.Parse(schema.TimeLayout20, s)
+	if err != nil {
+		return s
+	}
+	return t.Format(schema.TimeLayout)
+}
@@ -0,0 +1,54 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvd
+
+import (
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// NewCPEMatch creates a cpe match of the attributes within the version range, to be used by converters.
+// The version of attrs is used only if the range doesn't have any bounds.
+func NewCPEMatch(attrs *wfn.Attributes, r wfn.VersionRange, vulnerable bool) *schema.NVDCVEFeedJSON10DefCPEMatch {
+	a := *attrs
+	if r.HasBounds() {
+		a.Version = wfn.Any
+	}
+	cpe23URI := a.BindToFmtString()
+	return &schema.NVDCVEFeedJSON10DefCPEMatch{
+		CPEName: []*schema.NVDCVEFeedJSON10DefCPEName{
+			{
+				Cpe22Uri: a.BindToURI(),
+				Cpe23Uri: cpe23URI,
+			},
+		},
+		Cpe23Uri:              cpe23URI,
+		VersionStartIncluding: r.StartIncluding,
+		VersionStartExcluding: r.StartExcluding,
+		VersionEndIncluding:   r.EndIncluding,
+		VersionEndExcluding:   r.EndExcluding,
+		Vulnerable:            vulnerable,
+	}
+}
+
+// CPEMatchVersionRange returns the version range of the cpe match
+func CPEMatchVersionRange(m *schema.NVDCVEFeedJSON10DefCPEMatch) wfn.VersionRange {
+	return wfn.VersionRange{
+		StartIncluding: m.VersionStartIncluding,
+		StartExcluding: m.VersionStartExcluding,
+		EndIncluding:   m.VersionEndIncluding,
+		EndExcluding:   m.VersionEndExcluding,
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvd
+
+import (
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+)
+
+// MatchCriteria indexes match criteria of NVD Match Criteria API 2.0 by their ids,
+// so CPE matches of configurations referencing criteria by id can be resolved into CPE ranges
+type MatchCriteria map[string]*schema.CPEMatchJSON20
+
+// Add adds the match criteria to the index, replacing criteria with the same id
+func (mc MatchCriteria) Add(criteria *schema.CPEMatchJSON20) {
+	if criteria != nil && criteria.MatchCriteriaID != "" {
+		mc[criteria.MatchCriteriaID] = criteria
+	}
+}
+
+// Resolve fills CPE matches of the item configurations from the match criteria they reference:
+// the CPE match string and the version range are set if the match doesn't have them,
+// and CPE names matched by the criteria are added as cpe_name; matches with unknown ids are left as they are
+func (mc MatchCriteria) Resolve(item *schema.NVDCVEFeedJSON10DefCVEItem) {
+	if len(mc) == 0 || item == nil || item.Configurations == nil {
+		return
+	}
+	for _, node := range item.Configurations.Nodes {
+		mc.resolveNode(node)
+	}
+}
+
+func (mc MatchCriteria) resolveNode(node *schema.NVDCVEFeedJSON10DefNode) {
+	if node == nil {
+		return
+	}
+	for _, match := range node.CPEMatch {
+		if match != nil {
+			mc.resolveMatch(match)
+		}
+	}
+	for _, child := range node.Children {
+		mc.resolveNode(child)
+	}
+}
+
+func (mc MatchCriteria) resolveMatch(match *schema.NVDCVEFeedJSON10DefCPEMatch) {
+	criteria, ok := mc[match.MatchCriteriaID]
+	if !ok {
+		return
+	}
+	if match.Cpe23Uri == "" && match.Cpe22Uri == "" {
+		match.Cpe23Uri = criteria.Criteria
+	}
+	if !hasVersionRange(match) {
+		match.VersionStartExcluding = criteria.VersionStartExcluding
+		match.VersionStartIncluding = criteria.VersionStartIncluding
+		match.VersionEndExcluding = criteria.VersionEndExcluding
+		match.VersionEndIncluding = criteria.VersionEndIncluding
+	}
+	if len(match.CPEName) == 0 {
+		for _, name := range criteria.Matches {
+			if name != nil && name.CPEName != "" {
+				match.CPEName = append(match.CPEName, &schema.NVDCVEFeedJSON10DefCPEName{Cpe23Uri: name.CPEName})
+			}
+		}
+	}
+}
+
+func hasVersionRange(match *schema.NVDCVEFeedJSON10DefCPEMatch) bool {
+	return match.VersionStartExcluding != "" || match.VersionStartIncluding != "" ||
+		match.VersionEndExcluding != "" || match.VersionEndIncluding != ""
+}
@@ -16,27 +16,32 @@ package nvd
 
 import (
 	"regexp"
+	"time"
 
 	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/cvss4"
 	"github.com/facebookincubator/nvdtools/wfn"
 )
 
 var cveRegex = regexp.MustCompile("CVE-[0-9]{4}-[0-9]{4,}")
 
+// ToVuln converts the feed item to Vuln
 func ToVuln(cve *schema.NVDCVEFeedJSON10DefCVEItem) *Vuln {
-	var ms []wfn.Matcher
-	for _, node := range cve.Configurations.Nodes {
-		if node != nil {
-			if m, err := nodeMatcher(node); err == nil {
-				ms = append(ms, m)
-			}
-		}
-	}
+	return Compile(cve, false).Vuln()
+}
 
-	return &Vuln{
-		cveItem: cve,
-		Matcher: wfn.MatchAny(ms...),
-	}
+// ToCompactVuln is ToVuln which keeps only the parts of the item the Vuln needs besides matching:
+// configurations, descriptions and references which don't name CVEs are dropped, the item isn't modified
+func ToCompactVuln(cve *schema.NVDCVEFeedJSON10DefCVEItem) *Vuln {
+	return Compile(cve, true).Vuln()
+}
+
+// Reference is a reference of a vulnerability, e.g. an advisory or a patch
+type Reference struct {
+	URL    string
+	Name   string
+	Source string
+	Tags   []string
 }
 
 // Vuln implements the cvefeed.Vuln interface
@@ -45,6 +50,23 @@ type Vuln struct {
 	wfn.Matcher
 }
 
+// Explain returns why the vulnerability matched some of attrs, nil if it didn't match any
+func (v *Vuln) Explain(attrs []*wfn.Attributes, requireVersion bool) *wfn.Explanation {
+	return wfn.Explain(v.Matcher, attrs, requireVersion)
+}
+
+// MatchContext returns attributes which match the vulnerability, split into the ones matched by vulnerable CPE matches
+// and the ones matched as their platform by CPE matches which aren't vulnerable, e.g. the OS a vulnerable application runs on
+func (v *Vuln) MatchContext(attrs []*wfn.Attributes, requireVersion bool) (vulnerable, platform []*wfn.Attributes) {
+	return wfn.MatchContext(v.Matcher, attrs, requireVersion)
+}
+
+// MatchPlatform returns attributes and platforms which match the vulnerability, running_on conditions,
+// operating systems which aren't vulnerable themselves, match only the platforms, see wfn.MatchPlatform
+func (v *Vuln) MatchPlatform(attrs, platforms []*wfn.Attributes, requireVersion bool) []*wfn.Attributes {
+	return wfn.MatchPlatform(v.Matcher, attrs, platforms, requireVersion)
+}
+
 // ID is a part of the cvefeed.Vuln Interface
 func (v *Vuln) ID() string {
 	if v == nil || v.cveItem == nil || v.cveItem.CVE == nil || v.cveItem.CVE.CVEDataMeta == nil {
@@ -139,6 +161,95 @@ func (v *Vuln) CVSSv3Vector() string {
 	return ""
 }
 
+// CVSSv4BaseScore is a part of the cvefeed.Vuln Interface
+// The score is calculated from the vector if the feed doesn't have it
+func (v *Vuln) CVSSv4BaseScore() float64 {
+	c := v.cvssv4()
+	if c == nil {
+		return 0.0
+	}
+	if c.BaseScore == 0 && c.VectorString != "" {
+		if vec, err := cvss4.VectorFromString(c.VectorString); err == nil && vec.Validate() == nil {
+			return vec.Score()
+		}
+	}
+	return c.BaseScore
+}
+
+// CVSSv4Vector is a part of the cvefeed.Vuln Interface
+func (v *Vuln) CVSSv4Vector() string {
+	if c := v.cvssv4(); c != nil {
+		return c.VectorString
+	}
+	return ""
+}
+
+// Published is a part of the cvefeed.Vuln Interface
+func (v *Vuln) Published() time.Time {
+	if v == nil || v.cveItem == nil {
+		return time.Time{}
+	}
+	return parseTime(v.cveItem.PublishedDate)
+}
+
+// LastModified is a part of the cvefeed.Vuln Interface
+func (v *Vuln) LastModified() time.Time {
+	if v == nil || v.cveItem == nil {
+		return time.Time{}
+	}
+	return parseTime(v.cveItem.LastModifiedDate)
+}
+
+// References is a part of the cvefeed.Vuln Interface
+func (v *Vuln) References() []Reference {
+	if v == nil || v.cveItem == nil || v.cveItem.CVE == nil || v.cveItem.CVE.References == nil {
+		return nil
+	}
+	var refs []Reference
+	for _, refd := range v.cveItem.CVE.References.ReferenceData {
+		if refd != nil {
+			refs = append(refs, Reference{
+				URL:    refd.URL,
+				Name:   refd.Name,
+				Source: refd.Refsource,
+				Tags:   refd.Tags,
+			})
+		}
+	}
+	return refs
+}
+
+// Descriptions is a part of the cvefeed.Vuln Interface
+func (v *Vuln) Descriptions() map[string]string {
+	if v == nil || v.cveItem == nil || v.cveItem.CVE == nil || v.cveItem.CVE.Description == nil {
+		return nil
+	}
+	descs := make(map[string]string)
+	for _, desc := range v.cveItem.CVE.Description.DescriptionData {
+		if desc == nil || desc.Value == "" {
+			continue
+		}
+		if d, ok := descs[desc.Lang]; ok {
+			descs[desc.Lang] = d + "\n" + desc.Value
+		} else {
+			descs[desc.Lang] = desc.Value
+		}
+	}
+	if len(descs) == 0 {
+		return nil
+	}
+	return descs
+}
+
+// parseTime parses NVD timestamp, returns zero time if it can't be parsed
+func parseTime(s string) time.Time {
+	t, err := time.Parse(schema.TimeLayout, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
 // unique returns unique strings from input
 func unique(ss []string) []string {
 	var us []string
@@ -167,3 +278,11 @@ func (v *Vuln) cvssv3() *schema.CVSSV30 {
 	}
 	return v.cveItem.Impact.BaseMetricV3.CVSSV3
 }
+
+// just a helper to return the cvssv4 data
+func (v *Vuln) cvssv4() *schema.CVSSV40 {
+	if v == nil || v.cveItem == nil || v.cveItem.Impact == nil || v.cveItem.Impact.BaseMetricV4 == nil {
+		return nil
+	}
+	return v.cveItem.Impact.BaseMetricV4.CVSSV4
+}
@@ -0,0 +1,55 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+// types of NVD Match Criteria API 2.0 records, based on
+// https://csrc.nist.gov/schema/nvd/api/2.0/cpematch_api_json_2.0.schema
+
+// CPEMatchAPIJSON20 is a response of the Match Criteria API.
+type CPEMatchAPIJSON20 struct {
+	ResultsPerPage int                                `json:"resultsPerPage"`
+	StartIndex     int                                `json:"startIndex"`
+	TotalResults   int                                `json:"totalResults"`
+	Format         string                             `json:"format"`
+	Version        string                             `json:"version"`
+	Timestamp      string                             `json:"timestamp"`
+	MatchStrings   []*CPEMatchAPIJSON20DefMatchString `json:"matchStrings"`
+}
+
+// CPEMatchAPIJSON20DefMatchString is a single result of the Match Criteria API.
+type CPEMatchAPIJSON20DefMatchString struct {
+	MatchString *CPEMatchJSON20 `json:"matchString"`
+}
+
+// CPEMatchJSON20 is a match criteria: a CPE match string or range and CPE names it matches.
+type CPEMatchJSON20 struct {
+	MatchCriteriaID       string                   `json:"matchCriteriaId"`
+	Criteria              string                   `json:"criteria"`
+	VersionStartExcluding string                   `json:"versionStartExcluding,omitempty"`
+	VersionStartIncluding string                   `json:"versionStartIncluding,omitempty"`
+	VersionEndExcluding   string                   `json:"versionEndExcluding,omitempty"`
+	VersionEndIncluding   string                   `json:"versionEndIncluding,omitempty"`
+	Created               string                   `json:"created,omitempty"`
+	LastModified          string                   `json:"lastModified,omitempty"`
+	CPELastModified       string                   `json:"cpeLastModified,omitempty"`
+	Status                string                   `json:"status,omitempty"`
+	Matches               []*CPEMatchJSON20DefName `json:"matches,omitempty"`
+}
+
+// CPEMatchJSON20DefName is a CPE name matched by a match criteria.
+type CPEMatchJSON20DefName struct {
+	CPEName   string `json:"cpeName"`
+	CPENameID string `json:"cpeNameId,omitempty"`
+}
@@ -30,6 +30,7 @@ type NVDCVEFeedJSON10DefCPEMatch struct {
 	CPEName               []*NVDCVEFeedJSON10DefCPEName `json:"cpe_name,omitempty"`
 	Cpe22Uri              string                        `json:"cpe22Uri,omitempty"`
 	Cpe23Uri              string                        `json:"cpe23Uri"`
+	MatchCriteriaID       string                        `json:"matchCriteriaId,omitempty"`
 	VersionEndExcluding   string                        `json:"versionEndExcluding,omitempty"`
 	VersionEndIncluding   string                        `json:"versionEndIncluding,omitempty"`
 	VersionStartExcluding string                        `json:"versionStartExcluding,omitempty"`
@@ -226,11 +227,30 @@ type NVDCVEFeedJSON10DefImpactBaseMetricV3 struct {
 	ImpactScore         float64  `json:"impactScore,omitempty"`
 }
 
+// CVSSV40 is CVSS v4.0 data as published by NVD API 2.0.
+// The 1.x feeds don't have it, it's kept when API 2.0 responses are converted.
+type CVSSV40 struct {
+	Version               string  `json:"version"`
+	VectorString          string  `json:"vectorString"`
+	BaseScore             float64 `json:"baseScore"`
+	BaseSeverity          string  `json:"baseSeverity"`
+	ThreatScore           float64 `json:"threatScore,omitempty"`
+	ThreatSeverity        string  `json:"threatSeverity,omitempty"`
+	EnvironmentalScore    float64 `json:"environmentalScore,omitempty"`
+	EnvironmentalSeverity string  `json:"environmentalSeverity,omitempty"`
+}
+
+// NVDCVEFeedJSON10DefImpactBaseMetricV4 is CVSS V4.0 score, an extension of the 1.x feed schema.
+type NVDCVEFeedJSON10DefImpactBaseMetricV4 struct {
+	CVSSV4 *CVSSV40 `json:"cvssV4,omitempty"`
+}
+
 // NVDCVEFeedJSON10DefImpact was auto-generated.
 // Impact scores for a vulnerability as found on NVD.
 type NVDCVEFeedJSON10DefImpact struct {
 	BaseMetricV2 *NVDCVEFeedJSON10DefImpactBaseMetricV2 `json:"baseMetricV2,omitempty"`
 	BaseMetricV3 *NVDCVEFeedJSON10DefImpactBaseMetricV3 `json:"baseMetricV3,omitempty"`
+	BaseMetricV4 *NVDCVEFeedJSON10DefImpactBaseMetricV4 `json:"baseMetricV4,omitempty"`
 }
 
 // NVDCVEFeedJSON10DefCVEItem was auto-generated.
@@ -241,6 +261,38 @@ type NVDCVEFeedJSON10DefCVEItem struct {
 	Impact           *NVDCVEFeedJSON10DefImpact         `json:"impact,omitempty"`
 	LastModifiedDate string                             `json:"lastModifiedDate,omitempty"`
 	PublishedDate    string                             `json:"publishedDate,omitempty"`
+	// KnownExploited isn't a part of the NVD schema, it's set on vulnerabilities
+	// which are listed in the CISA Known Exploited Vulnerabilities catalog
+	KnownExploited *NVDCVEFeedJSON10DefKnownExploited `json:"knownExploited,omitempty"`
+	// EPSS isn't a part of the NVD schema, it's the FIRST Exploit Prediction Scoring System score of the vulnerability
+	EPSS *NVDCVEFeedJSON10DefEPSS `json:"epss,omitempty"`
+	// Exploits isn't a part of the NVD schema, it lists public exploits of the vulnerability
+	Exploits []*NVDCVEFeedJSON10DefExploit `json:"exploits,omitempty"`
+}
+
+// NVDCVEFeedJSON10DefExploit is a public exploit of a vulnerability, e.g. from Exploit-DB or Metasploit.
+type NVDCVEFeedJSON10DefExploit struct {
+	Source        string `json:"source"`
+	ID            string `json:"id"`
+	Description   string `json:"description,omitempty"`
+	URL           string `json:"url,omitempty"`
+	DatePublished string `json:"datePublished,omitempty"`
+	Verified      bool   `json:"verified,omitempty"`
+}
+
+// NVDCVEFeedJSON10DefEPSS is the EPSS score of a vulnerability on the given date.
+type NVDCVEFeedJSON10DefEPSS struct {
+	Score      float64 `json:"score"`
+	Percentile float64 `json:"percentile"`
+	Date       string  `json:"date,omitempty"`
+}
+
+// NVDCVEFeedJSON10DefKnownExploited describes a vulnerability from the CISA KEV catalog.
+type NVDCVEFeedJSON10DefKnownExploited struct {
+	DateAdded                  string `json:"dateAdded"`
+	DueDate                    string `json:"dueDate,omitempty"`
+	RequiredAction             string `json:"requiredAction,omitempty"`
+	KnownRansomwareCampaignUse string `json:"knownRansomwareCampaignUse,omitempty"`
 }
 
 // NVDCVEFeedJSON10 was auto-generated.
@@ -0,0 +1,142 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+// types of NVD CVE API 2.0 records, based on
+// https://csrc.nist.gov/schema/nvd/api/2.0/cve_api_json_2.0.schema
+
+// TimeLayout20 is the layout of NVD CVE API 2.0 timestamps.
+const TimeLayout20 = "2006-01-02T15:04:05.000"
+
+// CVEAPIJSON20 is a response of the CVE API, also used by 2.0 feed files.
+type CVEAPIJSON20 struct {
+	ResultsPerPage  int                             `json:"resultsPerPage"`
+	StartIndex      int                             `json:"startIndex"`
+	TotalResults    int                             `json:"totalResults"`
+	Format          string                          `json:"format"`
+	Version         string                          `json:"version"`
+	Timestamp       string                          `json:"timestamp"`
+	Vulnerabilities []*CVEAPIJSON20DefVulnerability `json:"vulnerabilities"`
+}
+
+// CVEAPIJSON20DefVulnerability is a single result of the CVE API.
+type CVEAPIJSON20DefVulnerability struct {
+	CVE *CVEJSON20 `json:"cve"`
+}
+
+// CVEJSON20 is a CVE record.
+type CVEJSON20 struct {
+	ID                    string                       `json:"id"`
+	SourceIdentifier      string                       `json:"sourceIdentifier,omitempty"`
+	Published             string                       `json:"published"`
+	LastModified          string                       `json:"lastModified"`
+	VulnStatus            string                       `json:"vulnStatus,omitempty"`
+	EvaluatorComment      string                       `json:"evaluatorComment,omitempty"`
+	EvaluatorSolution     string                       `json:"evaluatorSolution,omitempty"`
+	EvaluatorImpact       string                       `json:"evaluatorImpact,omitempty"`
+	CISAExploitAdd        string                       `json:"cisaExploitAdd,omitempty"`
+	CISAActionDue         string                       `json:"cisaActionDue,omitempty"`
+	CISARequiredAction    string                       `json:"cisaRequiredAction,omitempty"`
+	CISAVulnerabilityName string                       `json:"cisaVulnerabilityName,omitempty"`
+	Descriptions          []*CVEJSON20DefLangString    `json:"descriptions"`
+	References            []*CVEJSON20DefReference     `json:"references"`
+	Metrics               *CVEJSON20DefMetrics         `json:"metrics,omitempty"`
+	Weaknesses            []*CVEJSON20DefWeakness      `json:"weaknesses,omitempty"`
+	Configurations        []*CVEJSON20DefConfiguration `json:"configurations,omitempty"`
+}
+
+// CVEJSON20DefLangString is a text in the given language.
+type CVEJSON20DefLangString struct {
+	Lang  string `json:"lang"`
+	Value string `json:"value"`
+}
+
+// CVEJSON20DefReference is a reference of the CVE.
+type CVEJSON20DefReference struct {
+	URL    string   `json:"url"`
+	Source string   `json:"source,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+// CVEJSON20DefWeakness lists CWEs of the CVE assigned by the source.
+type CVEJSON20DefWeakness struct {
+	Source      string                    `json:"source"`
+	Type        string                    `json:"type"`
+	Description []*CVEJSON20DefLangString `json:"description"`
+}
+
+// CVEJSON20DefConfiguration is a configuration of vulnerable products.
+type CVEJSON20DefConfiguration struct {
+	Operator string              `json:"operator,omitempty"`
+	Negate   bool                `json:"negate,omitempty"`
+	Nodes    []*CVEJSON20DefNode `json:"nodes"`
+}
+
+// CVEJSON20DefNode is a node of a configuration.
+type CVEJSON20DefNode struct {
+	Operator string                  `json:"operator"`
+	Negate   bool                    `json:"negate,omitempty"`
+	CPEMatch []*CVEJSON20DefCPEMatch `json:"cpeMatch"`
+}
+
+// CVEJSON20DefCPEMatch is a CPE match criteria.
+type CVEJSON20DefCPEMatch struct {
+	Vulnerable            bool   `json:"vulnerable"`
+	Criteria              string `json:"criteria"`
+	MatchCriteriaID       string `json:"matchCriteriaId"`
+	VersionStartExcluding string `json:"versionStartExcluding,omitempty"`
+	VersionStartIncluding string `json:"versionStartIncluding,omitempty"`
+	VersionEndExcluding   string `json:"versionEndExcluding,omitempty"`
+	VersionEndIncluding   string `json:"versionEndIncluding,omitempty"`
+}
+
+// CVEJSON20DefMetrics holds CVSS metrics provided by different sources.
+type CVEJSON20DefMetrics struct {
+	CVSSMetricV40 []*CVEJSON20DefCVSSMetricV4 `json:"cvssMetricV40,omitempty"`
+	CVSSMetricV31 []*CVEJSON20DefCVSSMetricV3 `json:"cvssMetricV31,omitempty"`
+	CVSSMetricV30 []*CVEJSON20DefCVSSMetricV3 `json:"cvssMetricV30,omitempty"`
+	CVSSMetricV2  []*CVEJSON20DefCVSSMetricV2 `json:"cvssMetricV2,omitempty"`
+}
+
+// CVEJSON20DefCVSSMetricV4 is a CVSS v4.0 metric.
+type CVEJSON20DefCVSSMetricV4 struct {
+	Source   string   `json:"source"`
+	Type     string   `json:"type"`
+	CVSSData *CVSSV40 `json:"cvssData"`
+}
+
+// CVEJSON20DefCVSSMetricV3 is a CVSS v3.x metric.
+type CVEJSON20DefCVSSMetricV3 struct {
+	Source              string   `json:"source"`
+	Type                string   `json:"type"`
+	CVSSData            *CVSSV30 `json:"cvssData"`
+	ExploitabilityScore float64  `json:"exploitabilityScore,omitempty"`
+	ImpactScore         float64  `json:"impactScore,omitempty"`
+}
+
+// CVEJSON20DefCVSSMetricV2 is a CVSS v2 metric.
+type CVEJSON20DefCVSSMetricV2 struct {
+	Source                  string   `json:"source"`
+	Type                    string   `json:"type"`
+	CVSSData                *CVSSV20 `json:"cvssData"`
+	BaseSeverity            string   `json:"baseSeverity,omitempty"`
+	ExploitabilityScore     float64  `json:"exploitabilityScore,omitempty"`
+	ImpactScore             float64  `json:"impactScore,omitempty"`
+	AcInsufInfo             bool     `json:"acInsufInfo,omitempty"`
+	ObtainAllPrivilege      bool     `json:"obtainAllPrivilege,omitempty"`
+	ObtainUserPrivilege     bool     `json:"obtainUserPrivilege,omitempty"`
+	ObtainOtherPrivilege    bool     `json:"obtainOtherPrivilege,omitempty"`
+	UserInteractionRequired bool     `json:"userInteractionRequired,omitempty"`
+}
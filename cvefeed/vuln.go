@@ -15,6 +15,11 @@
 package cvefeed
 
 import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
 	"github.com/facebookincubator/nvdtools/wfn"
 )
 
@@ -36,6 +41,68 @@ type Vuln interface {
 	CVSSv3BaseScore() float64
 	// CVSSv2BaseScore returns CVSS v3 vector
 	CVSSv3Vector() string
+	// CVSSv4BaseScore returns CVSS v4 score
+	CVSSv4BaseScore() float64
+	// CVSSv4Vector returns CVSS v4 vector
+	CVSSv4Vector() string
+	// Published returns when the vulnerability was published, zero time if it's unknown
+	Published() time.Time
+	// LastModified returns when the vulnerability was last modified, zero time if it's unknown
+	LastModified() time.Time
+	// References returns references of the vulnerability, with their tags;
+	// compact vulnerabilities have only references which name CVEs, without URLs
+	References() []Reference
+	// Descriptions returns descriptions of the vulnerability by language, multiple descriptions in the same language are joined with newlines;
+	// compact vulnerabilities don't have descriptions
+	Descriptions() map[string]string
+}
+
+// Reference is a reference of a vulnerability
+type Reference = nvd.Reference
+
+// DefaultLang is the language of descriptions used if the requested one isn't available
+const DefaultLang = "en"
+
+// Description returns description of the vulnerability in the language, e.g. es or pt-BR, falling back to:
+// a description in a dialect of the language or in its base language (pt-BR and pt match each other),
+// then to DefaultLang and then to the first language in alphabetical order; languages are compared case-insensitively
+func Description(v Vuln, lang string) string {
+	descs := v.Descriptions()
+	if len(descs) == 0 {
+		return ""
+	}
+	langs := make([]string, 0, len(descs))
+	for l := range descs {
+		langs = append(langs, l)
+	}
+	sort.Strings(langs)
+
+	find := func(lang string, sameBase bool) (string, bool) {
+		for _, l := range langs {
+			if strings.EqualFold(l, lang) || sameBase && strings.EqualFold(baseLang(l), baseLang(lang)) {
+				return descs[l], true
+			}
+		}
+		return "", false
+	}
+	if d, ok := find(lang, false); ok {
+		return d
+	}
+	if d, ok := find(lang, true); ok {
+		return d
+	}
+	if d, ok := find(DefaultLang, true); ok {
+		return d
+	}
+	return descs[langs[0]]
+}
+
+// baseLang returns the primary language subtag of the language tag, e.g. pt of pt-BR
+func baseLang(lang string) string {
+	if i := strings.IndexAny(lang, "-_"); i != -1 {
+		return lang[:i]
+	}
+	return lang
 }
 
 // MergeVuln combines two Vulns:
@@ -59,11 +126,26 @@ func (v *overriden) Match(attrs []*wfn.Attributes, requireVersion bool) []*wfn.A
 	return v.matcher.Match(attrs, requireVersion)
 }
 
+// MatchContext is a part of the wfn.ContextMatcher interface
+func (v *overriden) MatchContext(attrs []*wfn.Attributes, requireVersion bool) (vulnerable, context []*wfn.Attributes) {
+	return wfn.MatchContext(v.matcher, attrs, requireVersion)
+}
+
+// MatchPlatform is a part of the wfn.PlatformMatcher interface
+func (v *overriden) MatchPlatform(attrs, platforms []*wfn.Attributes, requireVersion bool) []*wfn.Attributes {
+	return wfn.MatchPlatform(v.matcher, attrs, platforms, requireVersion)
+}
+
 // Attrs is a part of the wfn.Matcher interface
 func (v *overriden) Config() []*wfn.Attributes {
 	return v.matcher.Config()
 }
 
+// Explain is a part of the wfn.Explainer interface
+func (v *overriden) Explain(attrs []*wfn.Attributes, requireVersion bool) *wfn.Explanation {
+	return wfn.Explain(v.matcher, attrs, requireVersion)
+}
+
 // matches are the ones matched by both
 type andMatcher struct {
 	m1, m2 wfn.Matcher
@@ -74,7 +156,49 @@ func (m *andMatcher) Match(attrs []*wfn.Attributes, requireVersion bool) []*wfn.
 	return m.m2.Match(m.m1.Match(attrs, requireVersion), requireVersion)
 }
 
+// MatchContext is a part of the wfn.ContextMatcher interface
+func (m *andMatcher) MatchContext(attrs []*wfn.Attributes, requireVersion bool) (vulnerable, context []*wfn.Attributes) {
+	v1, c1 := wfn.MatchContext(m.m1, attrs, requireVersion)
+	isContext := make(map[*wfn.Attributes]bool, len(c1))
+	for _, a := range c1 {
+		isContext[a] = true
+	}
+	for _, a := range m.m2.Match(append(v1, c1...), requireVersion) {
+		if isContext[a] {
+			context = append(context, a)
+		} else {
+			vulnerable = append(vulnerable, a)
+		}
+	}
+	return vulnerable, context
+}
+
+// MatchPlatform is a part of the wfn.PlatformMatcher interface
+func (m *andMatcher) MatchPlatform(attrs, platforms []*wfn.Attributes, requireVersion bool) []*wfn.Attributes {
+	return m.m2.Match(wfn.MatchPlatform(m.m1, attrs, platforms, requireVersion), requireVersion)
+}
+
 // Attrs is a part of the wfn.Matcher interface
 func (m *andMatcher) Config() []*wfn.Attributes {
 	return append(m.m1.Config(), m.m2.Config()...)
 }
+
+// Explain is a part of the wfn.Explainer interface
+func (m *andMatcher) Explain(attrs []*wfn.Attributes, requireVersion bool) *wfn.Explanation {
+	matches := m.Match(attrs, requireVersion)
+	if len(matches) == 0 {
+		return nil
+	}
+	e := wfn.Explanation{Operator: "AND"}
+	for _, a := range matches {
+		e.Matched = append(e.Matched, a.BindToFmtString())
+	}
+	sort.Strings(e.Matched)
+	if c := wfn.Explain(m.m1, matches, requireVersion); c != nil {
+		e.Children = append(e.Children, c)
+	}
+	if c := wfn.Explain(m.m2, matches, requireVersion); c != nil {
+		e.Children = append(e.Children, c)
+	}
+	return &e
+}
@@ -0,0 +1,68 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+)
+
+const testDescriptionsFeed = `{"CVE_Items": [
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0001"}, "description": {"description_data": [
+    {"lang": "es", "value": "Desbordamiento de búfer."},
+    {"lang": "en", "value": "Buffer overflow."},
+    {"lang": "pt-BR", "value": "Estouro de buffer."}
+  ]}}, "configurations": {"nodes": []}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0002"}, "description": {"description_data": [
+    {"lang": "fr", "value": "Débordement de tampon."},
+    {"lang": "de", "value": "Pufferüberlauf."},
+    {"lang": "de", "value": "Zweite Beschreibung."}
+  ]}}, "configurations": {"nodes": []}},
+  {"cve": {"CVE_data_meta": {"ID": "CVE-0003"}}, "configurations": {"nodes": []}}
+]}`
+
+func TestDescription(t *testing.T) {
+	vulns, err := ParseJSON(bytes.NewBufferString(testDescriptionsFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vulns) != 3 {
+		t.Fatalf("expecting 3 vulnerabilities, got %d", len(vulns))
+	}
+
+	for i, tc := range []struct {
+		vuln   int
+		lang   string
+		expect string
+	}{
+		{0, "en", "Buffer overflow."},
+		{0, "ES", "Desbordamiento de búfer."},
+		{0, "es-MX", "Desbordamiento de búfer."},
+		{0, "pt", "Estouro de buffer."},
+		{0, "pt_br", "Estouro de buffer."},
+		{0, "ja", "Buffer overflow."},
+		{0, "", "Buffer overflow."},
+		{1, "fr", "Débordement de tampon."},
+		{1, "en", "Pufferüberlauf.\nZweite Beschreibung."},
+		{2, "en", ""},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			if d := Description(vulns[tc.vuln], tc.lang); d != tc.expect {
+				t.Fatalf("expecting %q, got %q", tc.expect, d)
+			}
+		})
+	}
+}
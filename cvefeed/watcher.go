@@ -0,0 +1,139 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Watcher keeps a Cache built from feed files and rebuilds it when any of the files changes,
+// so long-running processes pick up updated feeds (e.g. by nvdsync) without restarting;
+// the new Cache is built while the current one keeps serving lookups and replaces it atomically
+type Watcher struct {
+	paths []string
+	build func() (*Cache, error)
+
+	cache atomic.Value // *Cache
+	mu    sync.Mutex   // serializes reloads
+	state map[string]fileState
+}
+
+// fileState is used to detect changes of the files
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewWatcher builds the Cache with the build function and returns a Watcher of the files it's built from
+func NewWatcher(build func() (*Cache, error), paths ...string) (*Watcher, error) {
+	w := Watcher{paths: paths, build: build}
+	state, err := w.stat()
+	if err != nil {
+		return nil, err
+	}
+	cache, err := build()
+	if err != nil {
+		return nil, err
+	}
+	w.cache.Store(cache)
+	w.state = state
+	return &w, nil
+}
+
+// NewDictionaryWatcher returns a Watcher of the feeds with a Cache of the JSON dictionary loaded from them,
+// setup can configure the Cache (e.g. SetRequireVersion), it can be nil
+func NewDictionaryWatcher(setup func(*Cache) *Cache, paths ...string) (*Watcher, error) {
+	return NewWatcher(func() (*Cache, error) {
+		dict, err := LoadJSONDictionary(paths...)
+		if err != nil {
+			return nil, err
+		}
+		cache := NewCache(dict)
+		if setup != nil {
+			cache = setup(cache)
+		}
+		return cache, nil
+	}, paths...)
+}
+
+// Cache returns the current Cache
+func (w *Watcher) Cache() *Cache {
+	return w.cache.Load().(*Cache)
+}
+
+// Reload rebuilds the Cache if any of the files was modified since it was built, returns true if it was rebuilt;
+// the current Cache is kept if it can't be rebuilt
+func (w *Watcher) Reload() (bool, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	state, err := w.stat()
+	if err != nil {
+		return false, err
+	}
+	if !w.changed(state) {
+		return false, nil
+	}
+	cache, err := w.build()
+	if err != nil {
+		return false, err
+	}
+	w.cache.Store(cache)
+	w.state = state
+	return true, nil
+}
+
+// Watch checks the files every interval and reloads the Cache if they changed, until the context is done;
+// errors are passed to onError if it's not nil
+func (w *Watcher) Watch(ctx context.Context, interval time.Duration, onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := w.Reload(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+func (w *Watcher) stat() (map[string]fileState, error) {
+	state := make(map[string]fileState, len(w.paths))
+	for _, path := range w.paths {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("can't stat feed %q: %v", path, err)
+		}
+		state[path] = fileState{modTime: fi.ModTime(), size: fi.Size()}
+	}
+	return state, nil
+}
+
+func (w *Watcher) changed(state map[string]fileState) bool {
+	for path, s := range state {
+		if w.state[path] != s {
+			return true
+		}
+	}
+	return false
+}
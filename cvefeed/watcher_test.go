@@ -0,0 +1,81 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvefeed
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cvefeed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	feed := filepath.Join(dir, "feed.json")
+	if err := ioutil.WriteFile(feed, []byte(testStreamFeed), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := NewDictionaryWatcher((*Cache).SetInvertedIndex, feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := w.Cache()
+	if n := len(cache.Dict); n != 2 {
+		t.Fatalf("expecting 2 vulnerabilities, got %d", n)
+	}
+	if cache.InvertedIdx == nil {
+		t.Fatal("expecting the cache to be set up")
+	}
+
+	reloaded, err := w.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reloaded || w.Cache() != cache {
+		t.Fatal("unmodified feed was reloaded")
+	}
+
+	if err := ioutil.WriteFile(feed, []byte(`{"CVE_Items": []}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err = w.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reloaded {
+		t.Fatal("modified feed wasn't reloaded")
+	}
+	if n := len(w.Cache().Dict); n != 0 {
+		t.Fatalf("expecting 0 vulnerabilities after reload, got %d", n)
+	}
+	if n := len(cache.Dict); n != 2 {
+		t.Fatalf("previous cache was modified, got %d vulnerabilities", n)
+	}
+
+	if err := ioutil.WriteFile(feed, []byte("not a feed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Reload(); err == nil {
+		t.Fatal("expecting an error for a broken feed")
+	}
+	if w.Cache() == nil {
+		t.Fatal("expecting the previous cache to be kept")
+	}
+}
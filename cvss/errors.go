@@ -0,0 +1,90 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cvss holds what the cvss2, cvss3 and cvss4 packages have in common
+package cvss
+
+import "fmt"
+
+// ErrorKind tells what's wrong with a vector
+type ErrorKind int
+
+const (
+	// Malformed vector doesn't have the expected structure, e.g. a part isn't metric:value
+	Malformed ErrorKind = iota + 1
+	// UnknownMetric isn't defined by the specification
+	UnknownMetric
+	// IllegalValue isn't defined for the metric
+	IllegalValue
+	// DuplicateMetric is given more than once
+	DuplicateMetric
+	// MisplacedMetric is out of the order defined by the specification, only reported in strict mode
+	MisplacedMetric
+	// MissingMetric is required but not given
+	MissingMetric
+)
+
+func (k ErrorKind) String() string {
+	switch k {
+	case Malformed:
+		return "malformed vector"
+	case UnknownMetric:
+		return "unknown metric"
+	case IllegalValue:
+		return "illegal value"
+	case DuplicateMetric:
+		return "duplicate metric"
+	case MisplacedMetric:
+		return "misplaced metric"
+	case MissingMetric:
+		return "missing metric"
+	default:
+		return fmt.Sprintf("ErrorKind(%d)", int(k))
+	}
+}
+
+// VectorError is returned when a vector can't be parsed or is invalid, it tells exactly what's wrong and where
+type VectorError struct {
+	// Package is the name of the package which parsed the vector, it's used as the prefix of the message
+	Package string
+	Kind    ErrorKind
+	// Metric is the name of the metric the error is about, empty if it's not about a single metric
+	Metric string
+	// Value is the illegal value of the metric
+	Value string
+	// Pos is the byte offset of the erroneous part in the vector string, -1 if it's not in the string (e.g. missing metric)
+	Pos int
+	// Reason gives more details, if any
+	Reason string
+}
+
+func (e *VectorError) Error() string {
+	msg := e.Kind.String()
+	switch {
+	case e.Kind == IllegalValue:
+		msg = fmt.Sprintf("illegal value %q of metric %s", e.Value, e.Metric)
+	case e.Metric != "":
+		msg += " " + e.Metric
+	}
+	if e.Pos >= 0 {
+		msg += fmt.Sprintf(" at position %d", e.Pos)
+	}
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	if e.Package == "" {
+		return "cvss: " + msg
+	}
+	return e.Package + ": " + msg
+}
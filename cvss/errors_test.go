@@ -0,0 +1,34 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss
+
+import "testing"
+
+func TestVectorError(t *testing.T) {
+	cases := []struct {
+		err  *VectorError
+		want string
+	}{
+		{&VectorError{Package: "cvss3", Kind: Malformed, Pos: 0, Reason: `missing "CVSS:3.1/" prefix`}, `cvss3: malformed vector at position 0: missing "CVSS:3.1/" prefix`},
+		{&VectorError{Package: "cvss2", Kind: IllegalValue, Metric: "AV", Value: "X", Pos: 3}, `cvss2: illegal value "X" of metric AV at position 3`},
+		{&VectorError{Package: "cvss4", Kind: MissingMetric, Metric: "VC", Pos: -1}, "cvss4: missing metric VC"},
+		{&VectorError{Kind: ErrorKind(42), Pos: -1}, "cvss: ErrorKind(42)"},
+	}
+	for _, c := range cases {
+		if got := c.err.Error(); got != c.want {
+			t.Errorf("expecting %q, got %q", c.want, got)
+		}
+	}
+}
@@ -14,10 +14,7 @@
 
 package cvss2
 
-import (
-	"fmt"
-	"math"
-)
+import "math"
 
 func roundTo1Decimal(x float64) float64 {
 	return math.Round(x*10) / 10
@@ -25,23 +22,15 @@ func roundTo1Decimal(x float64) float64 {
 
 // Validate should be called before calculating any scores on vector
 // If there's an error, there's no guarantee that a call to *Score() won't panic
+// The error is of type *VectorError naming the first missing base metric
 func (v Vector) Validate() error {
-	switch {
-	case !v.BaseMetrics.AccessVector.defined():
-		return fmt.Errorf("base metric access vector not defined")
-	case !v.BaseMetrics.AccessComplexity.defined():
-		return fmt.Errorf("base metric access complexity not defined")
-	case !v.BaseMetrics.Authentication.defined():
-		return fmt.Errorf("base metric authentication not defined")
-	case !v.BaseMetrics.ConfidentialityImpact.defined():
-		return fmt.Errorf("base metric confidentiality impact not defined")
-	case !v.BaseMetrics.IntegrityImpact.defined():
-		return fmt.Errorf("base metric integrity impact not defined")
-	case !v.BaseMetrics.AvailabilityImpact.defined():
-		return fmt.Errorf("base metric availability impact not defined")
-	default:
-		return nil
+	definables := v.definables()
+	for _, metric := range baseMetrics {
+		if !definables[metric].defined() {
+			return &VectorError{Package: pkg, Kind: MissingMetric, Metric: metric, Pos: -1, Reason: "base metrics are required"}
+		}
 	}
+	return nil
 }
 
 // Score = combined score for the whole Vector
@@ -60,25 +60,68 @@ func (v Vector) String() string {
 	return sb.String()
 }
 
+// ParseMode controls how strictly vectors are parsed
+type ParseMode int
+
+const (
+	// Strict mode accepts vectors as defined by the specification: metrics named and valued as in the specification
+	// (e.g. Au, not AU), in the specified order
+	Strict ParseMode = iota
+	// Lenient mode also accepts vectors with metrics in arbitrary order or in any case
+	Lenient
+)
+
 // VectorFromString will parse a string into a Vector, or return an error if it can't be parsed
+// Enclosing parentheses are optional. It parses in lenient mode, see ParseVector
 func VectorFromString(str string) (Vector, error) {
+	return ParseVector(str, Lenient)
+}
+
+// ParseVector parses a string into a Vector in the given mode, metrics can't be given more than once in any mode
+// Returned errors are of type *VectorError telling which part of the string is wrong
+func ParseVector(str string, mode ParseMode) (Vector, error) {
 	var v Vector
-	str = strings.TrimPrefix(str, prefix)
+	pos := 0
+	if strings.HasPrefix(str, prefix) {
+		str = str[len(prefix):]
+		pos = len(prefix)
+	}
 	str = strings.TrimSuffix(str, suffix)
 	parseables := v.parseables()
+	seen := make(map[string]bool)
+	last := -1 // position in order of the previous metric
 
 	for _, part := range strings.Split(str, partSeparator) {
 		tmp := strings.Split(part, metricSeparator)
 		if len(tmp) != 2 {
-			return v, fmt.Errorf("need two values separated by %s, got %q", metricSeparator, part)
+			return v, &VectorError{Package: pkg, Kind: Malformed, Pos: pos, Reason: fmt.Sprintf("need two values separated by %s, got %q", metricSeparator, part)}
 		}
 
 		metric, value := tmp[0], tmp[1]
-		if p, ok := parseables[metric]; !ok {
-			return v, fmt.Errorf("undefined metric %s with value %s", metric, value)
-		} else if err := p.parse(value); err != nil {
-			return v, fmt.Errorf("error occurred while parsing metric %s: %v", metric, err)
+		name := metric
+		if mode == Lenient {
+			name = canonicalMetrics[strings.ToUpper(metric)]
+			value = strings.ToUpper(value)
+		}
+		p, ok := parseables[name]
+		switch {
+		case !ok:
+			return v, &VectorError{Package: pkg, Kind: UnknownMetric, Metric: metric, Pos: pos}
+		case seen[name]:
+			return v, &VectorError{Package: pkg, Kind: DuplicateMetric, Metric: metric, Pos: pos}
+		}
+		seen[name] = true
+		if mode == Strict {
+			idx, _ := findIndex(name, order)
+			if idx < last {
+				return v, &VectorError{Package: pkg, Kind: MisplacedMetric, Metric: metric, Pos: pos, Reason: fmt.Sprintf("must precede %s", order[last])}
+			}
+			last = idx
 		}
+		if err := p.parse(value); err != nil {
+			return v, &VectorError{Package: pkg, Kind: IllegalValue, Metric: metric, Value: tmp[1], Pos: pos + len(metric) + len(metricSeparator)}
+		}
+		pos += len(part) + len(partSeparator)
 	}
 
 	return v, nil
@@ -96,10 +139,44 @@ func (v *Vector) Absorb(other Vector) {
 	}
 }
 
+// Get returns value of the metric with the given abbreviation as it appears in the vector string,
+// "ND" for undefined temporal and environmental metrics and an empty string for undefined base metrics
+func (v Vector) Get(metric string) (string, error) {
+	def, ok := v.definables()[metric]
+	if !ok {
+		return "", &VectorError{Package: pkg, Kind: UnknownMetric, Metric: metric, Pos: -1}
+	}
+	return def.String(), nil
+}
+
+// Set sets value of the metric with the given abbreviation, e.g. Set("Au", "S");
+// temporal and environmental metrics can be reset to undefined with "ND"
+func (v *Vector) Set(metric, value string) error {
+	p, ok := v.parseables()[metric]
+	if !ok {
+		return &VectorError{Package: pkg, Kind: UnknownMetric, Metric: metric, Pos: -1}
+	}
+	if err := p.parse(value); err != nil {
+		return &VectorError{Package: pkg, Kind: IllegalValue, Metric: metric, Value: value, Pos: -1}
+	}
+	return nil
+}
+
 // helpers
 
+var baseMetrics = []string{"AV", "AC", "Au", "C", "I", "A"}
+
 var order = []string{"AV", "AC", "Au", "C", "I", "A", "E", "RL", "RC", "CDP", "TD", "CR", "IR", "AR", "ME", "MRL", "MRC"}
 
+// canonicalMetrics maps upper case metric names to the ones defined by the specification
+var canonicalMetrics = func() map[string]string {
+	m := make(map[string]string, len(order))
+	for _, metric := range order {
+		m[strings.ToUpper(metric)] = metric
+	}
+	return m
+}()
+
 type defineable interface {
 	defined() bool
 	String() string
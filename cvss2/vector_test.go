@@ -78,3 +78,95 @@ func TestAbsorb(t *testing.T) {
 		t.Errorf("when absorbing only defined values from another vector, it shouldn't override undefined ones")
 	}
 }
+
+func TestParseVectorErrors(t *testing.T) {
+	for i, c := range []struct {
+		str    string
+		mode   ParseMode
+		kind   ErrorKind
+		metric string
+		pos    int
+	}{
+		{"AV:N/AC", Strict, Malformed, "", 5},
+		{"(AV:N/XX:L)", Strict, UnknownMetric, "XX", 6},
+		{"AV:N/AC:Q", Strict, IllegalValue, "AC", 8},
+		{"AV:N/AC:L/Au:N/AV:L", Lenient, DuplicateMetric, "AV", 15},
+		{"AC:L/AV:N", Strict, MisplacedMetric, "AV", 5},
+		{"AV:N/AU:N", Strict, UnknownMetric, "AU", 5},
+		{"AV:n", Strict, IllegalValue, "AV", 3},
+	} {
+		t.Run(fmt.Sprintf("case %2d", i+1), func(t *testing.T) {
+			_, err := ParseVector(c.str, c.mode)
+			verr, ok := err.(*VectorError)
+			if !ok {
+				t.Fatalf("expecting *VectorError, got %v", err)
+			}
+			if verr.Kind != c.kind || verr.Metric != c.metric || verr.Pos != c.pos {
+				t.Fatalf("expecting %v of %q at %d, got %v", c.kind, c.metric, c.pos, verr)
+			}
+		})
+	}
+}
+
+func TestParseVectorLenient(t *testing.T) {
+	const want = "(AV:N/AC:L/Au:N/C:P/I:P/A:P/E:POC)"
+	for i, str := range []string{
+		"AV:N/AC:L/Au:N/C:P/I:P/A:P/E:POC",
+		"(E:POC/A:P/I:P/C:P/Au:N/AC:L/AV:N)",
+		"av:n/ac:l/au:n/c:p/i:p/a:p/e:poc",
+	} {
+		t.Run(fmt.Sprintf("case %2d", i+1), func(t *testing.T) {
+			v, err := ParseVector(str, Lenient)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.String() != want {
+				t.Fatalf("expecting %s, got %s", want, v)
+			}
+			if _, err := ParseVector(str, Strict); (err != nil) != (i > 0) {
+				t.Fatalf("unexpected result of strict parsing: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	v, err := VectorFromString("AV:N/AC:L/C:P/I:P/A:P")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = v.Validate()
+	verr, ok := err.(*VectorError)
+	if !ok || verr.Kind != MissingMetric || verr.Metric != "Au" || verr.Pos != -1 {
+		t.Fatalf("expecting missing metric Au, got %v", err)
+	}
+}
+
+func TestSetGet(t *testing.T) {
+	v, err := VectorFromString("(AV:N/AC:L/Au:N/C:P/I:P/A:P/E:F)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mv := range [][2]string{{"Au", "S"}, {"CR", "H"}, {"E", "ND"}} {
+		if err := v.Set(mv[0], mv[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := "(AV:N/AC:L/Au:S/C:P/I:P/A:P/CR:H)"; v.String() != want {
+		t.Fatalf("expecting %s, got %s", want, v)
+	}
+	if got, err := v.Get("Au"); err != nil || got != "S" {
+		t.Fatalf("expecting S, got %q (%v)", got, err)
+	}
+	if got, err := v.Get("E"); err != nil || got != "ND" {
+		t.Fatalf("expecting ND, got %q (%v)", got, err)
+	}
+
+	err = v.Set("Au", "X")
+	if verr, ok := err.(*VectorError); !ok || verr.Kind != IllegalValue || verr.Metric != "Au" {
+		t.Fatalf("expecting illegal value of Au, got %v", err)
+	}
+	if _, err = v.Get("MAV"); err == nil {
+		t.Fatal("expecting error getting unknown metric")
+	}
+}
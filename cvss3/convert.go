@@ -0,0 +1,125 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss3
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/cvss2"
+)
+
+// The conversions between CVSS v2 and v3 are heuristic: the versions don't measure the same things,
+// so converted vectors only approximate how the vulnerability would be scored with the other version.
+// They're meant to let vulnerabilities which were scored with a single version be compared with the
+// others, e.g. old CVEs which NVD scored only with v2, never to replace scores assigned by analysts.
+//
+// Metrics are mapped one to one where the versions agree, otherwise:
+//   - v2 access complexity M is v3 attack complexity L with user interaction R, since v2 rated
+//     vulnerabilities requiring user interaction (e.g. opening a file) with medium complexity;
+//     L and H map to AC:L and AC:H with UI:N, and back
+//   - v2 authentication N, S and M maps to v3 privileges required N, L and H
+//   - v2 impacts N, P and C map to v3 impacts N, L and H
+//   - scope is always unchanged, v2 has no notion of it
+//   - v3 physical attack vector is v2 local access vector
+//   - v2 collateral damage potential and target distribution, and v3 modified base metrics are dropped
+
+// v2ToV3 maps values of v2 metrics to v3 metrics, undefined values and metrics which aren't mapped are dropped
+var v2ToV3 = map[string]map[string]string{
+	"AV": {"L": "AV:L", "A": "AV:A", "N": "AV:N"},
+	"AC": {"H": "AC:H/UI:N", "M": "AC:L/UI:R", "L": "AC:L/UI:N"},
+	"Au": {"M": "PR:H", "S": "PR:L", "N": "PR:N"},
+	"C":  {"N": "C:N", "P": "C:L", "C": "C:H"},
+	"I":  {"N": "I:N", "P": "I:L", "C": "I:H"},
+	"A":  {"N": "A:N", "P": "A:L", "C": "A:H"},
+	"E":  {"U": "E:U", "POC": "E:P", "F": "E:F", "H": "E:H"},
+	"RL": {"OF": "RL:O", "TF": "RL:T", "W": "RL:W", "U": "RL:U"},
+	"RC": {"UC": "RC:U", "UR": "RC:R", "C": "RC:C"},
+	"CR": {"L": "CR:L", "M": "CR:M", "H": "CR:H"},
+	"IR": {"L": "IR:L", "M": "IR:M", "H": "IR:H"},
+	"AR": {"L": "AR:L", "M": "AR:M", "H": "AR:H"},
+}
+
+// v3ToV2 maps values of v3 metrics to v2 metrics, the same way
+var v3ToV2 = map[string]map[string]string{
+	"AV": {"N": "AV:N", "A": "AV:A", "L": "AV:L", "P": "AV:L"},
+	"AC": {"L": "AC:L", "H": "AC:H"},
+	"PR": {"N": "Au:N", "L": "Au:S", "H": "Au:M"},
+	"C":  {"N": "C:N", "L": "C:P", "H": "C:C"},
+	"I":  {"N": "I:N", "L": "I:P", "H": "I:C"},
+	"A":  {"N": "A:N", "L": "A:P", "H": "A:C"},
+	"E":  {"U": "E:U", "P": "E:POC", "F": "E:F", "H": "E:H"},
+	"RL": {"O": "RL:OF", "T": "RL:TF", "W": "RL:W", "U": "RL:U"},
+	"RC": {"U": "RC:UC", "R": "RC:UR", "C": "RC:C"},
+	"CR": {"L": "CR:L", "M": "CR:M", "H": "CR:H"},
+	"IR": {"L": "IR:L", "M": "IR:M", "H": "IR:H"},
+	"AR": {"L": "AR:L", "M": "AR:M", "H": "AR:H"},
+}
+
+// FromCVSS2 converts a valid CVSS v2 vector to a CVSS v3.1 vector, see above how the metrics are mapped
+func FromCVSS2(v2 cvss2.Vector) (Vector, error) {
+	v := Vector{version: version(1)}
+	if err := v2.Validate(); err != nil {
+		return v, fmt.Errorf("can't convert invalid vector: %v", err)
+	}
+	if err := v.Set("S", "U"); err != nil {
+		return v, err
+	}
+	for metric, values := range v2ToV3 {
+		value, err := v2.Get(metric)
+		if err != nil {
+			return v, err
+		}
+		if err := setAll(values[value], v.Set); err != nil {
+			return v, err
+		}
+	}
+	return v, nil
+}
+
+// ToCVSS2 converts a valid vector to a CVSS v2 vector, see above how the metrics are mapped
+func (v Vector) ToCVSS2() (cvss2.Vector, error) {
+	var v2 cvss2.Vector
+	if err := v.Validate(); err != nil {
+		return v2, fmt.Errorf("can't convert invalid vector: %v", err)
+	}
+	for metric, values := range v3ToV2 {
+		value, err := v.Get(metric)
+		if err != nil {
+			return v2, err
+		}
+		if err := setAll(values[value], v2.Set); err != nil {
+			return v2, err
+		}
+	}
+	if v.AttackComplexity == AttackComplexityLow && v.UserInteraction == UserInteractionRequired {
+		v2.AccessComplexity = cvss2.AccessComplexityMedium
+	}
+	return v2, nil
+}
+
+// setAll sets all metrics of a slash separated list of metric:value pairs
+func setAll(metrics string, set func(metric, value string) error) error {
+	if metrics == "" {
+		return nil
+	}
+	for _, part := range strings.Split(metrics, partSeparator) {
+		mv := strings.Split(part, metricSeparator)
+		if err := set(mv[0], mv[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss3
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvss2"
+)
+
+func TestFromCVSS2(t *testing.T) {
+	cases := []struct {
+		v2, v3 string
+		score  float64
+	}{
+		{"AV:N/AC:L/Au:N/C:P/I:P/A:P", "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:L/I:L/A:L", 7.3},
+		{"AV:N/AC:M/Au:N/C:C/I:C/A:C", "CVSS:3.1/AV:N/AC:L/PR:N/UI:R/S:U/C:H/I:H/A:H", 8.8},
+		{"AV:L/AC:H/Au:S/C:N/I:N/A:C/E:POC/RL:OF/RC:UR/CDP:H/CR:H", "CVSS:3.1/AV:L/AC:H/PR:L/UI:N/S:U/C:N/I:N/A:H/E:P/RL:O/RC:R/CR:H", 4.7},
+	}
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case %2d", i+1), func(t *testing.T) {
+			v2, err := cvss2.VectorFromString(c.v2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v3, err := FromCVSS2(v2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v3.String() != c.v3 {
+				t.Fatalf("expecting %s, got %s", c.v3, v3)
+			}
+			if s := v3.BaseScore(); s != c.score {
+				t.Fatalf("expecting base score %.1f, got %.1f", c.score, s)
+			}
+		})
+	}
+
+	if _, err := FromCVSS2(cvss2.Vector{}); err == nil {
+		t.Fatal("expecting error converting invalid vector")
+	}
+}
+
+func TestToCVSS2(t *testing.T) {
+	cases := []struct {
+		v3, v2 string
+	}{
+		{"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:L/I:L/A:L", "(AV:N/AC:L/Au:N/C:P/I:P/A:P)"},
+		{"CVSS:3.0/AV:N/AC:L/PR:N/UI:R/S:U/C:H/I:H/A:H", "(AV:N/AC:M/Au:N/C:C/I:C/A:C)"},
+		{"CVSS:3.1/AV:P/AC:H/PR:H/UI:R/S:C/C:H/I:N/A:N/E:U/RL:T/RC:C/AR:L/MAV:N", "(AV:L/AC:H/Au:M/C:C/I:N/A:N/E:U/RL:TF/RC:C/AR:L)"},
+	}
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case %2d", i+1), func(t *testing.T) {
+			v3, err := VectorFromString(c.v3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			v2, err := v3.ToCVSS2()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v2.String() != c.v2 {
+				t.Fatalf("expecting %s, got %s", c.v2, v2)
+			}
+		})
+	}
+
+	if _, err := (Vector{}).ToCVSS2(); err == nil {
+		t.Fatal("expecting error converting invalid vector")
+	}
+}
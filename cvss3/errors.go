@@ -0,0 +1,41 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss3
+
+import "github.com/facebookincubator/nvdtools/cvss"
+
+// ErrorKind tells what's wrong with a vector
+type ErrorKind = cvss.ErrorKind
+
+const (
+	// Malformed vector doesn't have the expected structure, e.g. a part isn't metric:value
+	Malformed = cvss.Malformed
+	// UnknownMetric isn't defined by the specification
+	UnknownMetric = cvss.UnknownMetric
+	// IllegalValue isn't defined for the metric
+	IllegalValue = cvss.IllegalValue
+	// DuplicateMetric is given more than once
+	DuplicateMetric = cvss.DuplicateMetric
+	// MisplacedMetric is out of the order defined by the specification, only reported in strict mode
+	MisplacedMetric = cvss.MisplacedMetric
+	// MissingMetric is required but not given
+	MissingMetric = cvss.MissingMetric
+)
+
+// VectorError is returned when a vector can't be parsed or is invalid, it tells exactly what's wrong and where
+type VectorError = cvss.VectorError
+
+// pkg is the prefix of messages of errors returned by this package
+const pkg = "cvss3"
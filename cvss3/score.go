@@ -14,10 +14,7 @@
 
 package cvss3
 
-import (
-	"fmt"
-	"math"
-)
+import "math"
 
 func roundUp(x float64) float64 {
 	// round up to one decimal
@@ -26,27 +23,15 @@ func roundUp(x float64) float64 {
 
 // Validate should be called before calculating any scores on vector
 // If there's an error, there's no guarantee that a call to *Score() won't panic
+// The error is of type *VectorError naming the first missing base metric
 func (v Vector) Validate() error {
-	switch {
-	case !v.BaseMetrics.AttackVector.defined():
-		return fmt.Errorf("base metric attack vector not defined")
-	case !v.BaseMetrics.AttackComplexity.defined():
-		return fmt.Errorf("base metric attack complexity not defined")
-	case !v.BaseMetrics.PrivilegesRequired.defined():
-		return fmt.Errorf("base metric privileges required not defined")
-	case !v.BaseMetrics.UserInteraction.defined():
-		return fmt.Errorf("base metric user interaction not defined")
-	case !v.BaseMetrics.Scope.defined():
-		return fmt.Errorf("base metric scope not defined")
-	case !v.BaseMetrics.Confidentiality.defined():
-		return fmt.Errorf("base metric confidentiality not defined")
-	case !v.BaseMetrics.Integrity.defined():
-		return fmt.Errorf("base metric integrity not defined")
-	case !v.BaseMetrics.Availability.defined():
-		return fmt.Errorf("base metric availability not defined")
-	default:
-		return nil
+	definables := v.definables()
+	for _, metric := range baseMetrics {
+		if !definables[metric].defined() {
+			return &VectorError{Package: pkg, Kind: MissingMetric, Metric: metric, Pos: -1, Reason: "base metrics are required"}
+		}
 	}
+	return nil
 }
 
 // Score = combined score for the whole Vector
@@ -160,3 +160,22 @@ func TestScoresV30V31(t *testing.T) {
 		}
 	}
 }
+
+func TestRescore(t *testing.T) {
+	v, err := VectorFromString("CVSS:3.0/AV:L/AC:H/PR:H/UI:R/S:C/C:L/I:H/A:L/E:P/RL:W/RC:R/CR:M/IR:H/AR:L/MAV:N/MAC:H/MPR:L/MUI:R/MS:C/MC:L/MA:N")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := v.EnvironmentalScore(); s != 7.1 {
+		t.Fatalf("expected 7.1, got %.1f", s)
+	}
+	if err := v.Set("MS", "U"); err != nil {
+		t.Fatal(err)
+	}
+	if s := v.EnvironmentalScore(); s != 6.1 {
+		t.Fatalf("expected 6.1 after setting MS:U, got %.1f", s)
+	}
+	if s := v.BaseScore(); s != 6.4 {
+		t.Fatalf("base score shouldn't change, expected 6.4, got %.1f", s)
+	}
+}
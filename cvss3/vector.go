@@ -77,40 +77,82 @@ func (v Vector) String() string {
 	return sb.String()
 }
 
+// ParseMode controls how strictly vectors are parsed
+type ParseMode int
+
+const (
+	// Strict mode accepts vectors as defined by the specification: upper case, with metrics in the specified order
+	Strict ParseMode = iota
+	// Lenient mode also accepts vectors with metrics in arbitrary order or in lower case
+	Lenient
+)
+
 // VectorFromString will parse a string into a Vector, or return an error if it can't be parsed
+// It parses in lenient mode, see ParseVector
 func VectorFromString(str string) (Vector, error) {
+	return ParseVector(str, Lenient)
+}
+
+// ParseVector parses a string into a Vector in the given mode, metrics can't be given more than once in any mode
+// Returned errors are of type *VectorError telling which part of the string is wrong
+func ParseVector(str string, mode ParseMode) (Vector, error) {
 	var v Vector
 
+	if mode == Lenient {
+		str = strings.ToUpper(str)
+	}
+
 	// check for prefix and trim it
-	str = strings.ToUpper(str)
 	if !strings.HasPrefix(str, prefix) {
-		return v, fmt.Errorf("vector missing %q prefix: %q", prefix, str)
+		return v, &VectorError{Package: pkg, Kind: Malformed, Pos: 0, Reason: fmt.Sprintf("missing %q prefix", prefix)}
 	}
-	str = strings.TrimPrefix(str, prefix)
+	pos := len(prefix)
+	str = str[pos:]
 
 	// extract version
-	slashIdx := strings.IndexByte(str, '/')
+	ver := str
+	if slashIdx := strings.IndexByte(str, '/'); slashIdx >= 0 {
+		ver, str = str[:slashIdx], str[slashIdx+1:]
+	} else {
+		str = ""
+	}
 	var err error
-	if v.version, err = versionFromString(str[:slashIdx]); err != nil {
-		return v, err
+	if v.version, err = versionFromString(ver); err != nil {
+		return v, &VectorError{Package: pkg, Kind: Malformed, Pos: pos, Reason: err.Error()}
 	}
-	str = str[slashIdx+1:]
+	pos += len(ver) + len(partSeparator)
 
 	// parse all metrics
 	parseables := v.parseables()
+	seen := make(map[string]bool)
+	last := -1 // position in order of the previous metric
 
 	for _, part := range strings.Split(str, partSeparator) {
 		tmp := strings.Split(part, metricSeparator)
 		if len(tmp) != 2 {
-			return v, fmt.Errorf("need two values separated by %s, got %q", metricSeparator, part)
+			return v, &VectorError{Package: pkg, Kind: Malformed, Pos: pos, Reason: fmt.Sprintf("need two values separated by %s, got %q", metricSeparator, part)}
 		}
 
 		metric, value := tmp[0], tmp[1]
-		if p, ok := parseables[metric]; !ok {
-			return v, fmt.Errorf("undefined metric %s with value %s", metric, value)
-		} else if err := p.parse(value); err != nil {
-			return v, fmt.Errorf("error occurred while parsing metric %s: %v", metric, err)
+		p, ok := parseables[metric]
+		switch {
+		case !ok:
+			return v, &VectorError{Package: pkg, Kind: UnknownMetric, Metric: metric, Pos: pos}
+		case seen[metric]:
+			return v, &VectorError{Package: pkg, Kind: DuplicateMetric, Metric: metric, Pos: pos}
+		}
+		seen[metric] = true
+		if mode == Strict {
+			idx, _ := findIndex(metric, order)
+			if idx < last {
+				return v, &VectorError{Package: pkg, Kind: MisplacedMetric, Metric: metric, Pos: pos, Reason: fmt.Sprintf("must precede %s", order[last])}
+			}
+			last = idx
 		}
+		if err := p.parse(value); err != nil {
+			return v, &VectorError{Package: pkg, Kind: IllegalValue, Metric: metric, Value: value, Pos: pos + len(metric) + len(metricSeparator)}
+		}
+		pos += len(part) + len(partSeparator)
 	}
 
 	return v, nil
@@ -128,8 +170,34 @@ func (v *Vector) Absorb(other Vector) {
 	}
 }
 
+// Get returns value of the metric with the given abbreviation as it appears in the vector string,
+// "X" for undefined temporal and environmental metrics and an empty string for undefined base metrics
+func (v Vector) Get(metric string) (string, error) {
+	def, ok := v.definables()[metric]
+	if !ok {
+		return "", &VectorError{Package: pkg, Kind: UnknownMetric, Metric: metric, Pos: -1}
+	}
+	return def.String(), nil
+}
+
+// Set sets value of the metric with the given abbreviation, e.g. Set("MAV", "L");
+// temporal and environmental metrics can be reset to undefined with "X"
+// Scores of the vector reflect the change, so it can be re-scored in a different environment
+func (v *Vector) Set(metric, value string) error {
+	p, ok := v.parseables()[metric]
+	if !ok {
+		return &VectorError{Package: pkg, Kind: UnknownMetric, Metric: metric, Pos: -1}
+	}
+	if err := p.parse(value); err != nil {
+		return &VectorError{Package: pkg, Kind: IllegalValue, Metric: metric, Value: value, Pos: -1}
+	}
+	return nil
+}
+
 // helpers
 
+var baseMetrics = []string{"AV", "AC", "PR", "UI", "S", "C", "I", "A"}
+
 var order = []string{"AV", "AC", "PR", "UI", "S", "C", "I", "A", "E", "RL", "RC", "CR", "IR", "AR", "MAV", "MAC", "MPR", "MUI", "MS", "MC", "MI", "MA", "ME", "MRL", "MRC"}
 
 type defineable interface {
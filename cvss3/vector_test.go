@@ -141,3 +141,105 @@ func TestAbsorb(t *testing.T) {
 		t.Errorf("when absorbing only defined values from another vector, it shouldn't override undefined ones")
 	}
 }
+
+func TestParseVectorErrors(t *testing.T) {
+	for i, c := range []struct {
+		str    string
+		mode   ParseMode
+		kind   ErrorKind
+		metric string
+		pos    int
+	}{
+		{"AV:N/AC:L", Strict, Malformed, "", 0},
+		{"CVSS:3.2/AV:N", Strict, Malformed, "", 5},
+		{"CVSS:3.1", Strict, Malformed, "", 9},
+		{"CVSS:3.1/AV:N/AC", Strict, Malformed, "", 14},
+		{"CVSS:3.1/AV:N/XX:L", Strict, UnknownMetric, "XX", 14},
+		{"CVSS:3.1/AV:N/AC:Q", Strict, IllegalValue, "AC", 17},
+		{"CVSS:3.1/AV:N/AC:L/AV:L", Lenient, DuplicateMetric, "AV", 19},
+		{"CVSS:3.1/AC:L/AV:N", Strict, MisplacedMetric, "AV", 14},
+		{"cvss:3.1/AV:N", Strict, Malformed, "", 0},
+		{"CVSS:3.1/av:n", Strict, UnknownMetric, "av", 9},
+	} {
+		t.Run(fmt.Sprintf("case %2d", i+1), func(t *testing.T) {
+			_, err := ParseVector(c.str, c.mode)
+			verr, ok := err.(*VectorError)
+			if !ok {
+				t.Fatalf("expecting *VectorError, got %v", err)
+			}
+			if verr.Kind != c.kind || verr.Metric != c.metric || verr.Pos != c.pos {
+				t.Fatalf("expecting %v of %q at %d, got %v", c.kind, c.metric, c.pos, verr)
+			}
+		})
+	}
+}
+
+func TestParseVectorLenient(t *testing.T) {
+	const want = "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/E:P"
+	for i, str := range []string{
+		"CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/E:P",
+		"CVSS:3.1/E:P/A:H/I:H/C:H/S:U/UI:N/PR:N/AC:L/AV:N",
+		"cvss:3.1/av:n/ac:l/pr:n/ui:n/s:u/c:h/i:h/a:h/e:p",
+	} {
+		t.Run(fmt.Sprintf("case %2d", i+1), func(t *testing.T) {
+			v, err := ParseVector(str, Lenient)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.String() != want {
+				t.Fatalf("expecting %s, got %s", want, v)
+			}
+			if _, err := ParseVector(str, Strict); (err != nil) != (i > 0) {
+				t.Fatalf("unexpected result of strict parsing: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	v, err := VectorFromString("CVSS:3.1/AV:N/AC:L/PR:N/UI:N/C:H/I:H/A:H")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = v.Validate()
+	verr, ok := err.(*VectorError)
+	if !ok || verr.Kind != MissingMetric || verr.Metric != "S" || verr.Pos != -1 {
+		t.Fatalf("expecting missing metric S, got %v", err)
+	}
+	if want := "cvss3: missing metric S: base metrics are required"; err.Error() != want {
+		t.Fatalf("expecting error %q, got %q", want, err)
+	}
+}
+
+func TestSetGet(t *testing.T) {
+	v, err := VectorFromString("CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H/E:P")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mv := range [][2]string{{"CR", "L"}, {"MAV", "L"}, {"E", "X"}, {"AC", "H"}} {
+		if err := v.Set(mv[0], mv[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:H/A:H/CR:L/MAV:L"; v.String() != want {
+		t.Fatalf("expecting %s, got %s", want, v)
+	}
+	if got, err := v.Get("MAV"); err != nil || got != "L" {
+		t.Fatalf("expecting L, got %q (%v)", got, err)
+	}
+	if got, err := v.Get("E"); err != nil || got != "X" {
+		t.Fatalf("expecting X, got %q (%v)", got, err)
+	}
+
+	err = v.Set("MAV", "Q")
+	if verr, ok := err.(*VectorError); !ok || verr.Kind != IllegalValue || verr.Metric != "MAV" {
+		t.Fatalf("expecting illegal value of MAV, got %v", err)
+	}
+	err = v.Set("XX", "L")
+	if verr, ok := err.(*VectorError); !ok || verr.Kind != UnknownMetric || verr.Metric != "XX" {
+		t.Fatalf("expecting unknown metric XX, got %v", err)
+	}
+	if _, err = v.Get("XX"); err == nil {
+		t.Fatal("expecting error getting unknown metric")
+	}
+}
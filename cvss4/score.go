@@ -0,0 +1,270 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss4
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// qualitative severity ratings
+const (
+	SeverityNone     = "NONE"
+	SeverityLow      = "LOW"
+	SeverityMedium   = "MEDIUM"
+	SeverityHigh     = "HIGH"
+	SeverityCritical = "CRITICAL"
+)
+
+// Severity returns the qualitative severity rating of the score
+func Severity(score float64) string {
+	switch {
+	case score == 0:
+		return SeverityNone
+	case score < 4.0:
+		return SeverityLow
+	case score < 7.0:
+		return SeverityMedium
+	case score < 9.0:
+		return SeverityHigh
+	default:
+		return SeverityCritical
+	}
+}
+
+// Severity returns the qualitative severity rating of the vector score
+func (v Vector) Severity() string {
+	return Severity(v.Score())
+}
+
+// Score returns the score of the vector; threat and environmental metrics are taken into account if defined,
+// see Nomenclature. Validate should be called before, the score of an invalid vector is meaningless
+//
+// The vector belongs to a MacroVector, a group of vectors of similar severity, and is scored
+// by interpolating between the score of the highest severity vector of the MacroVector
+// and the scores of the next lower MacroVectors by the distance of the vector from the highest one
+func (v Vector) Score() float64 {
+	// no impact on the system
+	none := true
+	for _, metric := range []string{"VC", "VI", "VA", "SC", "SI", "SA"} {
+		none = none && v.effective(metric) == "N"
+	}
+	if none {
+		return 0
+	}
+
+	eq := v.macroVector()
+	value := macroVectorScores[macroVectorKey(eq)]
+
+	// scores of the next lower MacroVectors, some of them don't exist
+	lower := func(levels ...int) (float64, bool) {
+		next := eq
+		for _, i := range levels {
+			next[i]++
+		}
+		score, ok := macroVectorScores[macroVectorKey(next)]
+		return score, ok
+	}
+	var lowerScores [5]float64
+	var lowerExists [5]bool
+	lowerScores[0], lowerExists[0] = lower(0)
+	lowerScores[1], lowerExists[1] = lower(1)
+	// EQ3 and EQ6 are related
+	switch eq3, eq6 := eq[2], eq[5]; {
+	case eq3 == 0 && eq6 == 0:
+		// there are two next lower MacroVectors, take the one with the higher score
+		left, _ := lower(5)
+		right, _ := lower(2)
+		lowerScores[2], lowerExists[2] = math.Max(left, right), true
+	case eq3 == 1 && eq6 == 0:
+		lowerScores[2], lowerExists[2] = lower(5)
+	default:
+		lowerScores[2], lowerExists[2] = lower(2)
+	}
+	lowerScores[3], lowerExists[3] = lower(3)
+	lowerScores[4], lowerExists[4] = lower(4)
+
+	// severity distances of the vector from the highest severity vector of its MacroVector,
+	// the first one which isn't of lower severity than the vector in any metric
+	var distances map[string]float64
+	for _, highest := range v.maxVectors(eq) {
+		distances = make(map[string]float64, len(severityLevels))
+		lowerThanHighest := false
+		for metric, levels := range severityLevels {
+			distances[metric] = levels[v.effective(metric)] - levels[highest[metric]]
+			lowerThanHighest = lowerThanHighest || distances[metric] < 0
+		}
+		if !lowerThanHighest {
+			break
+		}
+	}
+	sum := func(metrics ...string) float64 {
+		var s float64
+		for _, metric := range metrics {
+			s += distances[metric]
+		}
+		return s
+	}
+	current := [5]float64{
+		sum("AV", "PR", "UI"),
+		sum("AC", "AT"),
+		sum("VC", "VI", "VA", "CR", "IR", "AR"),
+		sum("SC", "SI", "SA"),
+		0,
+	}
+
+	const step = 0.1
+	depths := [4]float64{
+		maxSeverity[0][eq[0]] * step,
+		maxSeverity[1][eq[1]] * step,
+		maxSeverity[2][eq[2]*10+eq[5]] * step,
+		maxSeverity[3][eq[3]] * step,
+	}
+
+	// mean of the proportional distances to the next lower MacroVectors which exist
+	var n int
+	var meanDistance float64
+	for i := range lowerScores {
+		if !lowerExists[i] {
+			continue
+		}
+		n++
+		// the proportion is always 0 for EQ5
+		if i < len(depths) {
+			meanDistance += (value - lowerScores[i]) * current[i] / depths[i]
+		}
+	}
+	if n > 0 {
+		meanDistance /= float64(n)
+	}
+
+	value -= meanDistance
+	switch {
+	case value < 0:
+		value = 0
+	case value > 10:
+		value = 10
+	}
+	return roundTo1Decimal(value)
+}
+
+// roundTo1Decimal rounds half up, the small epsilon compensates for floating point errors as the calculator does
+func roundTo1Decimal(x float64) float64 {
+	const epsilon = 1e-6
+	return math.Round((x+epsilon)*10) / 10
+}
+
+// effective returns the value of the metric used for scoring: modified metrics override base ones,
+// not defined threat and security requirement metrics are assumed to be of the highest severity
+func (v Vector) effective(metric string) string {
+	value := v.Get(metric)
+	switch {
+	case metric == "E" && value == notDefined:
+		return "A"
+	case (metric == "CR" || metric == "IR" || metric == "AR") && value == notDefined:
+		return "H"
+	}
+	if modified := v.Get("M" + metric); modified != notDefined {
+		return modified
+	}
+	return value
+}
+
+// macroVector returns the levels of equivalence classes EQ1..EQ6 the vector belongs to
+func (v Vector) macroVector() [6]int {
+	m := v.effective
+	var eq [6]int
+
+	switch {
+	case m("AV") == "N" && m("PR") == "N" && m("UI") == "N":
+		eq[0] = 0
+	case (m("AV") == "N" || m("PR") == "N" || m("UI") == "N") && m("AV") != "P":
+		eq[0] = 1
+	default:
+		eq[0] = 2
+	}
+
+	if m("AC") != "L" || m("AT") != "N" {
+		eq[1] = 1
+	}
+
+	switch {
+	case m("VC") == "H" && m("VI") == "H":
+		eq[2] = 0
+	case m("VC") == "H" || m("VI") == "H" || m("VA") == "H":
+		eq[2] = 1
+	default:
+		eq[2] = 2
+	}
+
+	switch {
+	case m("MSI") == "S" || m("MSA") == "S":
+		eq[3] = 0
+	case m("SC") == "H" || m("SI") == "H" || m("SA") == "H":
+		eq[3] = 1
+	default:
+		eq[3] = 2
+	}
+
+	switch m("E") {
+	case "A":
+		eq[4] = 0
+	case "P":
+		eq[4] = 1
+	default:
+		eq[4] = 2
+	}
+
+	if !(m("CR") == "H" && m("VC") == "H" || m("IR") == "H" && m("VI") == "H" || m("AR") == "H" && m("VA") == "H") {
+		eq[5] = 1
+	}
+
+	return eq
+}
+
+func macroVectorKey(eq [6]int) string {
+	return fmt.Sprintf("%d%d%d%d%d%d", eq[0], eq[1], eq[2], eq[3], eq[4], eq[5])
+}
+
+// maxVectors returns all highest severity vectors of the MacroVector, as metric values
+func (v Vector) maxVectors(eq [6]int) []map[string]string {
+	parts := [5][]string{
+		maxComposed[0][eq[0]],
+		maxComposed[1][eq[1]],
+		maxComposed[2][eq[2]*10+eq[5]],
+		maxComposed[3][eq[3]],
+		maxComposed[4][eq[4]],
+	}
+	vectors := []map[string]string{{}}
+	for _, alternatives := range parts {
+		var next []map[string]string
+		for _, vec := range vectors {
+			for _, alt := range alternatives {
+				combined := make(map[string]string, len(vec)+6)
+				for metric, value := range vec {
+					combined[metric] = value
+				}
+				for _, part := range strings.Split(alt, partSeparator) {
+					tmp := strings.Split(part, metricSeparator)
+					combined[tmp[0]] = tmp[1]
+				}
+				next = append(next, combined)
+			}
+		}
+		vectors = next
+	}
+	return vectors
+}
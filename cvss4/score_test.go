@@ -0,0 +1,109 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss4
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+	for i, c := range []struct {
+		vec          string
+		score        float64
+		severity     string
+		nomenclature string
+	}{
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", 9.3, SeverityCritical, "CVSS-B"},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:H/SI:H/SA:H", 10, SeverityCritical, "CVSS-B"},
+		{"CVSS:4.0/AV:L/AC:L/AT:N/PR:L/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N", 8.5, SeverityHigh, "CVSS-B"},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:L/VI:N/VA:N/SC:N/SI:N/SA:N", 6.9, SeverityMedium, "CVSS-B"},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:P/VC:N/VI:N/VA:N/SC:L/SI:L/SA:N", 5.3, SeverityMedium, "CVSS-B"},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:L/UI:P/VC:N/VI:N/VA:N/SC:L/SI:L/SA:N", 5.1, SeverityMedium, "CVSS-B"},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:N/VI:N/VA:N/SC:N/SI:N/SA:N", 0, SeverityNone, "CVSS-B"},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/E:U", 8.1, SeverityHigh, "CVSS-BT"},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/MSI:S", 10, SeverityCritical, "CVSS-BE"},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:N/VI:N/VA:N/SC:N/SI:N/SA:N/E:A/MVC:H/MVI:H/MVA:H", 9.3, SeverityCritical, "CVSS-BTE"},
+		{"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/S:P/U:Red", 9.3, SeverityCritical, "CVSS-B"},
+	} {
+		t.Run(fmt.Sprintf("case %2d", i+1), func(t *testing.T) {
+			v, err := VectorFromString(c.vec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := v.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			if score := v.Score(); score != c.score {
+				t.Errorf("expected score %.1f, got %.1f", c.score, score)
+			}
+			if severity := v.Severity(); severity != c.severity {
+				t.Errorf("expected severity %s, got %s", c.severity, severity)
+			}
+			if n := v.Nomenclature(); n != c.nomenclature {
+				t.Errorf("expected nomenclature %s, got %s", c.nomenclature, n)
+			}
+		})
+	}
+}
+
+func TestScoreAllMacroVectors(t *testing.T) {
+	// every combination of base metrics must fall into an existing MacroVector and be scored within bounds
+	var walk func(v Vector, i int)
+	n := 0
+	walk = func(v Vector, i int) {
+		if !metrics[i].base {
+			n++
+			if _, ok := macroVectorScores[macroVectorKey(v.macroVector())]; !ok {
+				t.Fatalf("%s: no MacroVector %s", v, macroVectorKey(v.macroVector()))
+			}
+			if score := v.Score(); score < 0 || score > 10 {
+				t.Fatalf("%s: score %.1f out of bounds", v, score)
+			}
+			return
+		}
+		for _, value := range metrics[i].values {
+			v.set(metrics[i].name, value)
+			walk(v, i+1)
+		}
+	}
+	walk(Vector{}, 0)
+	if n != 4*2*2*3*3*3*3*3*3*3*3 {
+		t.Fatalf("unexpected number of vectors %d", n)
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	for i, c := range []struct {
+		score    float64
+		severity string
+	}{
+		{0, SeverityNone},
+		{0.1, SeverityLow},
+		{3.9, SeverityLow},
+		{4.0, SeverityMedium},
+		{6.9, SeverityMedium},
+		{7.0, SeverityHigh},
+		{8.9, SeverityHigh},
+		{9.0, SeverityCritical},
+		{10, SeverityCritical},
+	} {
+		t.Run(fmt.Sprintf("case %2d", i+1), func(t *testing.T) {
+			if severity := Severity(c.score); severity != c.severity {
+				t.Fatalf("expected %s, got %s", c.severity, severity)
+			}
+		})
+	}
+}
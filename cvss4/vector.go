@@ -0,0 +1,263 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cvss4 implements CVSS v4.0 vectors as defined by https://www.first.org/cvss/v4.0/specification-document:
+// parsing and serialization, MacroVector based scoring and qualitative severity ratings
+package cvss4
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	prefix          = "CVSS:4.0/"
+	partSeparator   = "/"
+	metricSeparator = ":"
+	notDefined      = "X"
+)
+
+// metric is a metric defined by the specification
+type metric struct {
+	name string
+	// values are the legal values of the metric, ordered as in the specification; "X" means the metric isn't defined
+	values []string
+	// base metrics are required
+	base bool
+}
+
+// metrics in the order defined by the specification
+var metrics = []metric{
+	// base metrics
+	{"AV", []string{"N", "A", "L", "P"}, true},
+	{"AC", []string{"L", "H"}, true},
+	{"AT", []string{"N", "P"}, true},
+	{"PR", []string{"N", "L", "H"}, true},
+	{"UI", []string{"N", "P", "A"}, true},
+	{"VC", []string{"H", "L", "N"}, true},
+	{"VI", []string{"H", "L", "N"}, true},
+	{"VA", []string{"H", "L", "N"}, true},
+	{"SC", []string{"H", "L", "N"}, true},
+	{"SI", []string{"H", "L", "N"}, true},
+	{"SA", []string{"H", "L", "N"}, true},
+	// threat metrics
+	{"E", []string{"X", "A", "P", "U"}, false},
+	// environmental metrics
+	{"CR", []string{"X", "H", "M", "L"}, false},
+	{"IR", []string{"X", "H", "M", "L"}, false},
+	{"AR", []string{"X", "H", "M", "L"}, false},
+	{"MAV", []string{"X", "N", "A", "L", "P"}, false},
+	{"MAC", []string{"X", "L", "H"}, false},
+	{"MAT", []string{"X", "N", "P"}, false},
+	{"MPR", []string{"X", "N", "L", "H"}, false},
+	{"MUI", []string{"X", "N", "P", "A"}, false},
+	{"MVC", []string{"X", "H", "L", "N"}, false},
+	{"MVI", []string{"X", "H", "L", "N"}, false},
+	{"MVA", []string{"X", "H", "L", "N"}, false},
+	{"MSC", []string{"X", "H", "L", "N"}, false},
+	{"MSI", []string{"X", "S", "H", "L", "N"}, false},
+	{"MSA", []string{"X", "S", "H", "L", "N"}, false},
+	// supplemental metrics, they don't affect the score
+	{"S", []string{"X", "N", "P"}, false},
+	{"AU", []string{"X", "N", "Y"}, false},
+	{"R", []string{"X", "A", "U", "I"}, false},
+	{"V", []string{"X", "D", "C"}, false},
+	{"RE", []string{"X", "L", "M", "H"}, false},
+	{"U", []string{"X", "Clear", "Green", "Amber", "Red"}, false},
+}
+
+// metricIndex maps metric names to their position in metrics
+var metricIndex = func() map[string]int {
+	m := make(map[string]int, len(metrics))
+	for i, metric := range metrics {
+		m[metric.name] = i
+	}
+	return m
+}()
+
+// Vector represents a CVSS v4.0 vector, holds all metrics inside (base, threat, environmental and supplemental)
+type Vector struct {
+	// defined metrics with their values
+	values map[string]string
+}
+
+// ParseMode controls how strictly vectors are parsed
+type ParseMode int
+
+const (
+	// Strict mode accepts vectors as defined by the specification: with metric names and values
+	// in the case of the specification (e.g. U:Red), with metrics in the specified order
+	Strict ParseMode = iota
+	// Lenient mode also accepts vectors with metrics in arbitrary order or in any case
+	Lenient
+)
+
+// VectorFromString will parse a string into a Vector, or return an error if it can't be parsed
+// It parses in lenient mode, see ParseVector
+func VectorFromString(str string) (Vector, error) {
+	return ParseVector(str, Lenient)
+}
+
+// ParseVector parses a string into a Vector in the given mode, metrics can't be given more than once in any mode
+// Returned errors are of type *VectorError telling which part of the string is wrong
+func ParseVector(str string, mode ParseMode) (Vector, error) {
+	var v Vector
+
+	if !strings.HasPrefix(str, prefix) && (mode == Strict || !strings.HasPrefix(strings.ToUpper(str), prefix)) {
+		return v, &VectorError{Package: pkg, Kind: Malformed, Pos: 0, Reason: fmt.Sprintf("missing %q prefix", prefix)}
+	}
+	pos := len(prefix)
+	str = str[pos:]
+
+	seen := make(map[string]bool)
+	last := -1 // position in metrics of the previous metric
+
+	for _, part := range strings.Split(str, partSeparator) {
+		tmp := strings.Split(part, metricSeparator)
+		if len(tmp) != 2 {
+			return v, &VectorError{Package: pkg, Kind: Malformed, Pos: pos, Reason: fmt.Sprintf("need two values separated by %s, got %q", metricSeparator, part)}
+		}
+
+		name, value := tmp[0], tmp[1]
+		if mode == Lenient {
+			name = strings.ToUpper(name)
+		}
+		idx, ok := metricIndex[name]
+		switch {
+		case !ok:
+			return v, &VectorError{Package: pkg, Kind: UnknownMetric, Metric: tmp[0], Pos: pos}
+		case seen[name]:
+			return v, &VectorError{Package: pkg, Kind: DuplicateMetric, Metric: tmp[0], Pos: pos}
+		case mode == Strict && idx < last:
+			return v, &VectorError{Package: pkg, Kind: MisplacedMetric, Metric: tmp[0], Pos: pos, Reason: fmt.Sprintf("must precede %s", metrics[last].name)}
+		}
+		seen[name] = true
+		last = idx
+
+		if value, ok = metrics[idx].value(value, mode == Lenient); !ok {
+			return v, &VectorError{Package: pkg, Kind: IllegalValue, Metric: tmp[0], Value: tmp[1], Pos: pos + len(tmp[0]) + len(metricSeparator)}
+		}
+		v.set(name, value)
+		pos += len(part) + len(partSeparator)
+	}
+
+	return v, nil
+}
+
+// value returns the legal value of the metric matching s, case insensitively if ignoreCase is set
+func (m metric) value(s string, ignoreCase bool) (string, bool) {
+	for _, value := range m.values {
+		if value == s || (ignoreCase && strings.EqualFold(value, s)) {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+// String returns this vectors representation as a string, metrics which aren't defined are omitted
+// it shouldn't depend on the order of metrics
+func (v Vector) String() string {
+	var sb strings.Builder
+	fmt.Fprint(&sb, prefix)
+
+	first := true
+	for _, metric := range metrics {
+		value, ok := v.values[metric.name]
+		if !ok {
+			continue
+		}
+		if !first {
+			fmt.Fprint(&sb, partSeparator)
+		} else {
+			first = false
+		}
+		fmt.Fprintf(&sb, "%s%s%s", metric.name, metricSeparator, value)
+	}
+
+	return sb.String()
+}
+
+// Get returns the value of the metric, "X" if it isn't defined
+func (v Vector) Get(metric string) string {
+	if value, ok := v.values[metric]; ok {
+		return value
+	}
+	return notDefined
+}
+
+// Set sets the value of the metric, "X" makes it not defined
+func (v *Vector) Set(metric, value string) error {
+	idx, ok := metricIndex[metric]
+	if !ok {
+		return &VectorError{Package: pkg, Kind: UnknownMetric, Metric: metric, Pos: -1}
+	}
+	if _, ok := metrics[idx].value(value, false); !ok {
+		return &VectorError{Package: pkg, Kind: IllegalValue, Metric: metric, Value: value, Pos: -1}
+	}
+	v.set(metric, value)
+	return nil
+}
+
+func (v *Vector) set(metric, value string) {
+	if value == notDefined {
+		delete(v.values, metric)
+		return
+	}
+	if v.values == nil {
+		v.values = make(map[string]string)
+	}
+	v.values[metric] = value
+}
+
+// Absorb will override only metrics in the current vector from the one given which are defined
+func (v *Vector) Absorb(other Vector) {
+	for metric, value := range other.values {
+		v.set(metric, value)
+	}
+}
+
+// Validate should be called before calculating the score of the vector
+// The error is of type *VectorError naming the first missing base metric
+func (v Vector) Validate() error {
+	for _, metric := range metrics {
+		if _, ok := v.values[metric.name]; metric.base && !ok {
+			return &VectorError{Package: pkg, Kind: MissingMetric, Metric: metric.name, Pos: -1, Reason: "base metrics are required"}
+		}
+	}
+	return nil
+}
+
+// Nomenclature tells which metrics affect the score of the vector:
+// CVSS-B (base metrics only), CVSS-BT (with threat metrics), CVSS-BE (with environmental metrics) or CVSS-BTE
+func (v Vector) Nomenclature() string {
+	var threat, env bool
+	for metric := range v.values {
+		switch idx := metricIndex[metric]; {
+		case metrics[idx].name == "E":
+			threat = true
+		case idx > metricIndex["E"] && idx < metricIndex["S"]:
+			env = true
+		}
+	}
+	switch {
+	case threat && env:
+		return "CVSS-BTE"
+	case threat:
+		return "CVSS-BT"
+	case env:
+		return "CVSS-BE"
+	default:
+		return "CVSS-B"
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvss4
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFromString(t *testing.T) {
+	cases := []string{
+		"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N",
+		"CVSS:4.0/AV:P/AC:H/AT:P/PR:H/UI:A/VC:L/VI:L/VA:L/SC:L/SI:L/SA:L/E:P",
+		"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/CR:L/MAV:A/MSI:S/MSA:S",
+		"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/S:P/AU:Y/R:A/V:C/RE:H/U:Amber",
+	}
+	for i, str := range cases {
+		t.Run(fmt.Sprintf("case %2d", i+1), func(t *testing.T) {
+			v, err := ParseVector(str, Strict)
+			if err != nil {
+				t.Fatalf("unable to parse vector: %v", err)
+			}
+			if v.String() != str {
+				t.Fatalf("vector.String() should be the same thing it was parsed from.\nGot:\t%s\nExpect:\t%s", v, str)
+			}
+		})
+	}
+}
+
+func TestParseVectorLenient(t *testing.T) {
+	const want = "CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/U:Red"
+	for i, str := range []string{
+		"CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/U:Red",
+		"CVSS:4.0/U:Red/SA:N/SI:N/SC:N/VA:H/VI:H/VC:H/UI:N/PR:N/AT:N/AC:L/AV:N",
+		"cvss:4.0/av:n/ac:l/at:n/pr:n/ui:n/vc:h/vi:h/va:h/sc:n/si:n/sa:n/u:red",
+	} {
+		t.Run(fmt.Sprintf("case %2d", i+1), func(t *testing.T) {
+			v, err := ParseVector(str, Lenient)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.String() != want {
+				t.Fatalf("expecting %s, got %s", want, v)
+			}
+			if _, err := ParseVector(str, Strict); (err != nil) != (i > 0) {
+				t.Fatalf("unexpected result of strict parsing: %v", err)
+			}
+		})
+	}
+}
+
+func TestParseVectorErrors(t *testing.T) {
+	for i, c := range []struct {
+		str    string
+		mode   ParseMode
+		kind   ErrorKind
+		metric string
+		pos    int
+	}{
+		{"CVSS:3.1/AV:N", Strict, Malformed, "", 0},
+		{"CVSS:4.0/AV:N/AC", Strict, Malformed, "", 14},
+		{"CVSS:4.0/AV:N/XX:L", Strict, UnknownMetric, "XX", 14},
+		{"CVSS:4.0/AV:N/AC:Q", Strict, IllegalValue, "AC", 17},
+		{"CVSS:4.0/AV:N/AC:L/AV:L", Lenient, DuplicateMetric, "AV", 19},
+		{"CVSS:4.0/AC:L/AV:N", Strict, MisplacedMetric, "AV", 14},
+		{"CVSS:4.0/AV:N/U:red", Strict, IllegalValue, "U", 16},
+	} {
+		t.Run(fmt.Sprintf("case %2d", i+1), func(t *testing.T) {
+			_, err := ParseVector(c.str, c.mode)
+			verr, ok := err.(*VectorError)
+			if !ok {
+				t.Fatalf("expecting *VectorError, got %v", err)
+			}
+			if verr.Kind != c.kind || verr.Metric != c.metric || verr.Pos != c.pos {
+				t.Fatalf("expecting %v of %q at %d, got %v", c.kind, c.metric, c.pos, verr)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	v, err := VectorFromString("CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N")
+	if err != nil {
+		t.Fatal(err)
+	}
+	verr, ok := v.Validate().(*VectorError)
+	if !ok || verr.Kind != MissingMetric || verr.Metric != "SA" {
+		t.Fatalf("expecting missing metric SA, got %v", verr)
+	}
+}
+
+func TestSetAndAbsorb(t *testing.T) {
+	v, err := VectorFromString("CVSS:4.0/AV:N/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/E:U")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set("E", "X"); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Set("E", "Q"); err == nil {
+		t.Fatal("expecting an error for an illegal value")
+	}
+	other, err := VectorFromString("CVSS:4.0/AV:L/MAV:N")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v.Absorb(other)
+	if want := "CVSS:4.0/AV:L/AC:L/AT:N/PR:N/UI:N/VC:H/VI:H/VA:H/SC:N/SI:N/SA:N/MAV:N"; v.String() != want {
+		t.Fatalf("expecting %s, got %s", want, v)
+	}
+	if v.Get("E") != "X" || v.Get("MAV") != "N" {
+		t.Fatalf("unexpected metric values of %s", v)
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func init() {
+	wfn.RegisterVersionScheme("deb", func(v1, v2 string) int {
+		c, err := CompareVersions(v1, v2)
+		if err != nil {
+			// not a debian version, do the best we can
+			return wfn.SmartVerCmp(v1, v2)
+		}
+		return c
+	})
+}
+
+// Compare compares two packages and returns -1, 0 or 1 if p1 is older, same or newer than p2
+// packages are ordered by name first, then by version and finally by arch
+func Compare(p1, p2 *Package) int {
+	if c := strings.Compare(p1.Name, p2.Name); c != 0 {
+		return c
+	}
+	if c := VersionCompare(p1.Version, p2.Version); c != 0 {
+		return c
+	}
+	return strings.Compare(p1.Arch, p2.Arch)
+}
+
+// CompareVersions parses and compares two versions in [epoch:]upstream[-revision] format
+func CompareVersions(v1, v2 string) (int, error) {
+	ver1, err := ParseVersion(v1)
+	if err != nil {
+		return 0, fmt.Errorf("can't parse version %q: %v", v1, err)
+	}
+	ver2, err := ParseVersion(v2)
+	if err != nil {
+		return 0, fmt.Errorf("can't parse version %q: %v", v2, err)
+	}
+	return VersionCompare(*ver1, *ver2), nil
+}
+
+// VersionCompare compares two versions the same way dpkg does
+// it returns -1, 0 or 1 if v1 is older, same or newer than v2
+// https://www.debian.org/doc/debian-policy/ch-controlfields.html#version
+func VersionCompare(v1, v2 Version) int {
+	// 1. epochs are compared numerically, missing epoch is 0
+	if c := compareNumeric(v1.Epoch, v2.Epoch); c != 0 {
+		return c
+	}
+
+	// 2. upstream versions are compared using the dpkg algorithm
+	if c := comparePart(v1.Upstream, v2.Upstream); c != 0 {
+		return c
+	}
+
+	// 3. revisions are compared the same way, missing revision is the same as "0"
+	return comparePart(v1.Revision, v2.Revision)
+}
+
+// comparePart compares two version parts (upstream version or revision) using the dpkg algorithm
+// it returns -1, 0 or 1 if s1 is older, same or newer than s2
+func comparePart(s1, s2 string) int {
+	for len(s1) > 0 || len(s2) > 0 {
+		// 1. compare the non digit prefixes, character by character
+		// letters sort before non letters, and tilde sorts before anything, even the end of the string
+		var p1, p2 string
+		p1, s1 = takeWhile(s1, isntDigit)
+		p2, s2 = takeWhile(s2, isntDigit)
+		if c := compareLexical(p1, p2); c != 0 {
+			return c
+		}
+
+		// 2. compare the digit prefixes numerically
+		var n1, n2 string
+		n1, s1 = takeWhile(s1, isDigit)
+		n2, s2 = takeWhile(s2, isDigit)
+		if c := compareNumeric(n1, n2); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// compareLexical compares two strings without digits using dpkg rules
+func compareLexical(s1, s2 string) int {
+	for i := 0; i < len(s1) || i < len(s2); i++ {
+		var c1, c2 int
+		if i < len(s1) {
+			c1 = order(s1[i])
+		}
+		if i < len(s2) {
+			c2 = order(s2[i])
+		}
+		switch {
+		case c1 < c2:
+			return -1
+		case c1 > c2:
+			return 1
+		}
+	}
+	return 0
+}
+
+// order returns the weight of a character, end of string has weight 0
+func order(c byte) int {
+	switch {
+	case c == '~':
+		return -1
+	case isLetter(c):
+		return int(c)
+	default:
+		return int(c) + 256
+	}
+}
+
+// compareNumeric compares two strings of digits, empty string is treated as 0
+func compareNumeric(n1, n2 string) int {
+	n1 = strings.TrimLeft(n1, "0")
+	n2 = strings.TrimLeft(n2, "0")
+	if c := len(n1) - len(n2); c != 0 {
+		if c < 0 {
+			return -1
+		}
+		return 1
+	}
+	return strings.Compare(n1, n2)
+}
+
+func isDigit(r rune) bool {
+	return '0' <= r && r <= '9'
+}
+
+func isntDigit(r rune) bool {
+	return !isDigit(r)
+}
+
+func isLetter(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+func takeWhile(s string, f func(rune) bool) (matched, rest string) {
+	var i int
+	for i = 0; i < len(s) && f(rune(s[i])); i++ {
+	}
+	return s[:i], s[i:]
+}
@@ -0,0 +1,113 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deb
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		v1, v2 string
+		result int
+	}{
+		{"1.0", "1.0", 0},
+		{"1.0", "1.0-0", 0},
+		{"0:1.0", "1.0", 0},
+		{"1.0", "1.1", -1},
+		{"1.10", "1.9", 1},
+		{"1.001", "1.1", 0},
+		{"1:1.0", "2.0", 1},
+		{"10:1.0", "9:2.0", 1},
+		// tilde sorts before everything, even the end
+		{"1.0~rc1", "1.0", -1},
+		{"1.0~~", "1.0~", -1},
+		{"1.0~~a", "1.0~~", 1},
+		{"1.0~rc1", "1.0~rc2", -1},
+		// letters sort before non letters
+		{"1.0a", "1.0+", -1},
+		{"1.0a", "1.0", 1},
+		{"1.0+dfsg", "1.0", 1},
+		{"1.0-1", "1.0-1ubuntu1", -1},
+		{"1.0-1ubuntu1", "1.0-1ubuntu1.1", -1},
+		{"1.0-1ubuntu1.1", "1.0-2", -1},
+		{"2.28-10", "2.28-10+deb10u1", -1},
+		{"1.1.1f-1ubuntu2", "1.1.1f-1ubuntu2.1", -1},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			r, err := CompareVersions(c.v1, c.v2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r != c.result {
+				t.Errorf("compare(%q, %q) = %d, expecting %d", c.v1, c.v2, r, c.result)
+			}
+			// and the other way around
+			if r, _ := CompareVersions(c.v2, c.v1); r != -c.result {
+				t.Errorf("compare(%q, %q) = %d, expecting %d", c.v2, c.v1, r, -c.result)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	cases := []struct {
+		p1, p2 string
+		result int
+	}{
+		{"bash_5.0-4_amd64", "bash_5.0-4_amd64", 0},
+		{"bash_5.0-4_amd64", "bash_5.0-5_amd64", -1},
+		{"bash_5.0-4_amd64", "bash_5.0-4_i386", -1},
+		{"zsh_1.0-1_amd64", "bash_5.0-4_amd64", 1},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			p1, err := Parse(c.p1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p2, err := Parse(c.p2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r := Compare(p1, p2); r != c.result {
+				t.Errorf("compare(%q, %q) = %d, expecting %d", c.p1, c.p2, r, c.result)
+			}
+		})
+	}
+}
+
+func BenchmarkCompareVersions(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		CompareVersions("1:1.1.1f-1ubuntu2.1", "1:1.1.1f-1ubuntu2.10")
+	}
+}
+
+func TestVersionScheme(t *testing.T) {
+	// tilde sorts before anything, even the end of the version
+	r := wfn.VersionRange{StartIncluding: "1.0~rc1", EndExcluding: "1.0", Scheme: "deb"}
+	if !r.Contains("1.0~rc2") {
+		t.Errorf("1.0~rc2 should be in %v", r)
+	}
+	if r.Contains("1.0~beta1") {
+		t.Errorf("1.0~beta1 shouldn't be in %v", r)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// ToWFN parses CPE name from debian package in name_version[_arch] format
+func ToWFN(attr *wfn.Attributes, s string) error {
+	pkg, err := Parse(s)
+	if err != nil {
+		return fmt.Errorf("can't get fields from %q: %v", s, err)
+	}
+	return PackageToWFN(attr, pkg)
+}
+
+// PackageToWFN sets CPE attributes of the package: name is the product,
+// upstream version is the version, revision is the update and architecture is the target hardware
+func PackageToWFN(attr *wfn.Attributes, pkg *Package) error {
+	if pkg.Name == "" {
+		return fmt.Errorf("no name found in package")
+	}
+	if pkg.Upstream == "" {
+		return fmt.Errorf("no version found in package %q", pkg.Name)
+	}
+
+	for _, f := range []struct {
+		name  string
+		value string
+		addr  *string
+	}{
+		{"name", pkg.Name, &attr.Product},
+		{"version", pkg.Upstream, &attr.Version},
+		{"revision", pkg.Revision, &attr.Update},
+		{"arch", pkg.Arch, &attr.TargetHW},
+	} {
+		var err error
+		if *f.addr, err = wfn.WFNize(f.value); err != nil {
+			return fmt.Errorf("couldn't wfnize %s %q: %v", f.name, f.value, err)
+		}
+	}
+	attr.Part = "a"
+	return nil
+}
+
+// archs maps debian architectures to CPE target_hw names used by NVD
+var archs = map[string]string{
+	"amd64": "x64",
+	"i386":  "x86",
+	"arm64": "arm64",
+	"armhf": "arm",
+	"armel": "arm",
+}
+
+// ArchToTargetHW maps debian architecture to CPE target_hw, e.g. amd64 to x64 and armhf to arm.
+// Architectures without a common CPE name are returned unchanged.
+func ArchToTargetHW(arch string) string {
+	if hw, ok := archs[strings.ToLower(arch)]; ok {
+		return hw
+	}
+	return arch
+}
@@ -0,0 +1,69 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deb
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestToWFN(t *testing.T) {
+	cases := []struct {
+		pkg  string
+		cpe  string
+		fail bool
+	}{
+		{pkg: "name", fail: true},
+		{pkg: "openssl_1.1.1f-1ubuntu2_amd64.deb", cpe: "cpe:/a::openssl:1.1.1f:1ubuntu2:~~~~amd64~"},
+		{pkg: "tzdata_2019c-0+deb10u1_all.deb", cpe: "cpe:/a::tzdata:2019c:0%2bdeb10u1"},
+		{pkg: "libc6_2:2.28-10_amd64", cpe: "cpe:/a::libc6:2.28:10:~~~~amd64~"},
+		{pkg: "zlib1g_1.2.11.dfsg", cpe: "cpe:/a::zlib1g:1.2.11.dfsg"},
+	}
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			attr := wfn.NewAttributesWithAny()
+			err := ToWFN(attr, c.pkg)
+			if c.fail {
+				if err == nil {
+					t.Fatalf("%q was expected to fail, but succeeded", c.pkg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cpe := attr.BindToURI(); cpe != c.cpe {
+				t.Fatalf("%q:\nhave: %q\nwant: %q", c.pkg, cpe, c.cpe)
+			}
+		})
+	}
+}
+
+func TestArchToTargetHW(t *testing.T) {
+	for arch, hw := range map[string]string{
+		"amd64":   "x64",
+		"i386":    "x86",
+		"arm64":   "arm64",
+		"armhf":   "arm",
+		"ppc64el": "ppc64el",
+		"":        "",
+	} {
+		if have := ArchToTargetHW(arch); have != hw {
+			t.Errorf("%q: expected %q, got %q", arch, hw, have)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deb
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseDpkgList parses the output of dpkg -l and returns installed packages
+// packages which aren't installed, e.g. removed ones with only config files left (rc), are skipped
+func ParseDpkgList(r io.Reader) ([]*Package, error) {
+	var pkgs []*Package
+	scanner := bufio.NewScanner(r)
+	inHeader := true
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if inHeader {
+			// header ends with +++-====...
+			if len(fields) != 0 && strings.HasPrefix(fields[0], "+++") {
+				inHeader = false
+			}
+			continue
+		}
+		if len(fields) < 4 {
+			continue
+		}
+		// desired action and package status, e.g. ii or hi
+		if status := fields[0]; len(status) < 2 || status[1] != 'i' {
+			continue
+		}
+		name := fields[1]
+		// multi-arch packages have architecture qualified names, e.g. libssl1.1:amd64
+		if i := strings.IndexByte(name, ':'); i >= 0 {
+			name = name[:i]
+		}
+		v, err := ParseVersion(fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("can't parse version of %s at line %d: %v", name, line, err)
+		}
+		pkg := &Package{
+			Name:    strings.ToLower(name),
+			Version: *v,
+			Arch:    fields[3],
+		}
+		if pkg.Arch == "all" {
+			pkg.Arch = ""
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("can't read dpkg list: %v", err)
+	}
+	return pkgs, nil
+}
@@ -0,0 +1,55 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deb
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testDpkgList = `Desired=Unknown/Install/Remove/Purge/Hold
+| Status=Not/Inst/Conf-files/Unpacked/halF-conf/Half-inst/trig-aWait/Trig-pend
+|/ Err?=(none)/Reinst-required (Status,Err: uppercase=bad)
+||/ Name               Version              Architecture Description
++++-==================-====================-============-=================================
+ii  adduser            3.118ubuntu2         all          add and remove users and groups
+ii  libssl1.1:amd64    1.1.1f-1ubuntu2.19   amd64        Secure Sockets Layer toolkit - shared libraries
+rc  linux-image-5.4.0  5.4.0-42.46          amd64        Signed kernel image generic
+hi  libc6:amd64        2.31-0ubuntu9.9      amd64        GNU C Library: Shared libraries
+un  python             <none>               <none>       (no description available)
+`
+
+func TestParseDpkgList(t *testing.T) {
+	pkgs, err := ParseDpkgList(strings.NewReader(testDpkgList))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Package{
+		{Name: "adduser", Version: Version{Upstream: "3.118ubuntu2"}},
+		{Name: "libssl1.1", Version: Version{Upstream: "1.1.1f", Revision: "1ubuntu2.19"}, Arch: "amd64"},
+		{Name: "libc6", Version: Version{Upstream: "2.31", Revision: "0ubuntu9.9"}, Arch: "amd64"},
+	}
+	if !reflect.DeepEqual(pkgs, want) {
+		t.Fatalf("have: %+v\nwant: %+v", pkgs, want)
+	}
+}
+
+func TestParseDpkgListBadVersion(t *testing.T) {
+	in := "+++-====\nii  foo  a:1.0  amd64  description\n"
+	if _, err := ParseDpkgList(strings.NewReader(in)); err == nil {
+		t.Fatal("expected invalid version to fail")
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Package represents one debian package
+type Package struct {
+	Name string
+	Version
+	Arch string
+	// Source is the name of the source package the package was built from, if known
+	Source string
+}
+
+// Version is part of the package and allows us to compare two debian packages
+// https://www.debian.org/doc/debian-policy/ch-controlfields.html#version
+type Version struct {
+	Epoch    string
+	Upstream string
+	Revision string
+}
+
+// Parse parses the debian package in name_version[_arch][.deb] format, as used in .deb file names
+// e.g. openssl_1.1.1f-1ubuntu2_amd64.deb
+func Parse(pkg string) (*Package, error) {
+	pkg = strings.TrimSuffix(pkg, ".deb")
+	// epoch is encoded in file names
+	pkg = strings.Replace(pkg, "%3a", ":", -1)
+	pkg = strings.Replace(pkg, "%3A", ":", -1)
+
+	parts := strings.Split(pkg, "_")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("can't parse pkg %q, expecting name_version[_arch]", pkg)
+	}
+
+	var p Package
+	p.Name = strings.ToLower(parts[0])
+	if p.Name == "" {
+		return nil, fmt.Errorf("can't find name in pkg %q", pkg)
+	}
+
+	v, err := ParseVersion(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("can't parse version in pkg %q: %v", pkg, err)
+	}
+	p.Version = *v
+
+	if len(parts) == 3 {
+		p.Arch = parts[2]
+		if p.Arch == "all" {
+			p.Arch = ""
+		}
+	}
+
+	return &p, nil
+}
+
+// ParseVersion parses the version in [epoch:]upstream[-revision] format
+func ParseVersion(version string) (*Version, error) {
+	var v Version
+
+	// epoch
+	if i := strings.IndexByte(version, ':'); i >= 0 {
+		v.Epoch, version = version[:i], version[i+1:]
+		if v.Epoch == "" || strings.TrimLeftFunc(v.Epoch, isDigit) != "" {
+			return nil, fmt.Errorf("epoch %q should be a number", v.Epoch)
+		}
+	}
+
+	// revision
+	if i := strings.LastIndexByte(version, '-'); i >= 0 {
+		version, v.Revision = version[:i], version[i+1:]
+		if v.Revision == "" {
+			return nil, fmt.Errorf("empty revision")
+		}
+	}
+
+	if version == "" {
+		return nil, fmt.Errorf("empty upstream version")
+	}
+	if !isDigit(rune(version[0])) {
+		return nil, fmt.Errorf("upstream version %q should start with a digit", version)
+	}
+	v.Upstream = version
+
+	return &v, nil
+}
+
+// String returns the version in [epoch:]upstream[-revision] format
+func (v Version) String() string {
+	s := v.Upstream
+	if v.Epoch != "" {
+		s = v.Epoch + ":" + s
+	}
+	if v.Revision != "" {
+		s += "-" + v.Revision
+	}
+	return s
+}
@@ -0,0 +1,111 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deb
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		pkgStr string
+		pkg    Package
+		fail   bool
+	}{
+		{pkgStr: "", fail: true},
+		{pkgStr: "name", fail: true},
+		{pkgStr: "name_", fail: true},
+		{pkgStr: "name_version", fail: true},
+		{pkgStr: "name_1.0_amd64_extra", fail: true},
+		{pkgStr: "name_a:1.0", fail: true},
+		{
+			pkgStr: "name_1.0",
+			pkg:    Package{Name: "name", Version: Version{Upstream: "1.0"}},
+		},
+		{
+			pkgStr: "openssl_1.1.1f-1ubuntu2_amd64.deb",
+			pkg: Package{
+				Name:    "openssl",
+				Version: Version{Upstream: "1.1.1f", Revision: "1ubuntu2"},
+				Arch:    "amd64",
+			},
+		},
+		{
+			pkgStr: "tzdata_2019c-0+deb10u1_all.deb",
+			pkg: Package{
+				Name:    "tzdata",
+				Version: Version{Upstream: "2019c", Revision: "0+deb10u1"},
+			},
+		},
+		{
+			pkgStr: "libc6_2:2.28-10_amd64",
+			pkg: Package{
+				Name:    "libc6",
+				Version: Version{Epoch: "2", Upstream: "2.28", Revision: "10"},
+				Arch:    "amd64",
+			},
+		},
+		{
+			pkgStr: "vim_2%3a8.1.0875-5_amd64.deb",
+			pkg: Package{
+				Name:    "vim",
+				Version: Version{Epoch: "2", Upstream: "8.1.0875", Revision: "5"},
+				Arch:    "amd64",
+			},
+		},
+		{
+			// only the last dash separates the revision
+			pkgStr: "foo_1.0-beta-2",
+			pkg: Package{
+				Name:    "foo",
+				Version: Version{Upstream: "1.0-beta", Revision: "2"},
+			},
+		},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			pkg, err := Parse(c.pkgStr)
+			if c.fail {
+				if err == nil {
+					t.Fatalf("parsing %q should fail, got %+v", c.pkgStr, pkg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*pkg, c.pkg) {
+				t.Fatalf("expecting %+v, got %+v", c.pkg, *pkg)
+			}
+		})
+	}
+}
+
+func TestVersionString(t *testing.T) {
+	for i, s := range []string{"1.0", "1:1.0", "1.0-1", "2:8.1.0875-5", "1.0-beta-2"} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			v, err := ParseVersion(s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if v.String() != s {
+				t.Fatalf("expecting %q, got %q", s, v.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deb
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ParseStatus parses the dpkg status database, /var/lib/dpkg/status, and returns installed packages
+// packages without status, as listed in /var/lib/dpkg/status.d of distroless images, are considered installed
+func ParseStatus(r io.Reader) ([]*Package, error) {
+	var pkgs []*Package
+	fields := map[string]string{}
+	flush := func() error {
+		defer func() { fields = map[string]string{} }()
+		if fields["Package"] == "" {
+			return nil
+		}
+		// status is desired action, error flag and status, e.g. install ok installed
+		if status := strings.Fields(fields["Status"]); len(status) == 3 && status[2] != "installed" {
+			return nil
+		}
+		pkg, err := statusPackage(fields)
+		if err != nil {
+			return err
+		}
+		pkgs = append(pkgs, pkg)
+		return nil
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	var key string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.TrimSpace(line) == "":
+			// paragraphs are separated by empty lines
+			if err := flush(); err != nil {
+				return nil, err
+			}
+			key = ""
+		case line[0] == ' ' || line[0] == '\t':
+			// continuation of multiline field, e.g. description
+			if key == "" {
+				return nil, fmt.Errorf("continuation line without a field: %q", line)
+			}
+		default:
+			i := strings.IndexByte(line, ':')
+			if i < 0 {
+				return nil, fmt.Errorf("invalid line: %q", line)
+			}
+			key = line[:i]
+			fields[key] = strings.TrimSpace(line[i+1:])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("can't read dpkg status: %v", err)
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return pkgs, nil
+}
+
+// statusPackage creates the package from fields of its paragraph in dpkg status
+func statusPackage(fields map[string]string) (*Package, error) {
+	name := fields["Package"]
+	v, err := ParseVersion(fields["Version"])
+	if err != nil {
+		return nil, fmt.Errorf("can't parse version of %s: %v", name, err)
+	}
+	pkg := &Package{
+		Name:    strings.ToLower(name),
+		Version: *v,
+		Arch:    fields["Architecture"],
+	}
+	if pkg.Arch == "all" {
+		pkg.Arch = ""
+	}
+	// source can contain the version if it's different from the binary one, e.g. openssl (3.0.11-1)
+	if source := strings.Fields(fields["Source"]); len(source) != 0 {
+		pkg.Source = strings.ToLower(source[0])
+	}
+	return pkg, nil
+}
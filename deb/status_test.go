@@ -0,0 +1,82 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deb
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testStatus = `Package: libssl3
+Status: install ok installed
+Priority: optional
+Section: libs
+Installed-Size: 6204
+Maintainer: Debian OpenSSL Team <pkg-openssl-devel@alioth-lists.debian.net>
+Architecture: amd64
+Multi-Arch: same
+Source: openssl
+Version: 3.0.11-1~deb12u2
+Depends: libc6 (>= 2.34)
+Description: Secure Sockets Layer toolkit - shared libraries
+ This package is part of the OpenSSL project's implementation of the SSL and
+ TLS cryptographic protocols for secure communication over the Internet.
+ .
+ It provides the libssl and libcrypto shared libraries.
+
+Package: vim
+Status: deinstall ok config-files
+Architecture: amd64
+Version: 2:9.0.1378-2
+
+Package: tzdata
+Status: install ok installed
+Architecture: all
+Version: 2024a-0+deb12u1
+Description: time zone and daylight-saving time data
+
+Package: libgcc-s1
+Architecture: amd64
+Source: gcc-12 (12.2.0-14)
+Version: 12.2.0-14
+`
+
+func TestParseStatus(t *testing.T) {
+	pkgs, err := ParseStatus(strings.NewReader(testStatus))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Package{
+		{Name: "libssl3", Version: Version{Upstream: "3.0.11", Revision: "1~deb12u2"}, Arch: "amd64", Source: "openssl"},
+		{Name: "tzdata", Version: Version{Upstream: "2024a", Revision: "0+deb12u1"}},
+		{Name: "libgcc-s1", Version: Version{Upstream: "12.2.0", Revision: "14"}, Arch: "amd64", Source: "gcc-12"},
+	}
+	if !reflect.DeepEqual(pkgs, want) {
+		t.Fatalf("have: %+v\nwant: %+v", pkgs, want)
+	}
+}
+
+func TestParseStatusInvalid(t *testing.T) {
+	for _, s := range []string{
+		"Package: foo\nVersion: a:1.0\n",
+		"Package: foo\ninvalid line\n",
+		" continuation\n",
+	} {
+		if _, err := ParseStatus(strings.NewReader(s)); err == nil {
+			t.Errorf("%q was expected to fail, but succeeded", s)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nvdtools is the high-level API of nvdtools, for services which embed it instead of running its commands:
+// it loads vulnerability feeds, matches CPE names against them, and fetches and converts vulnerabilities
+// of the providers to NVD format, as cpe2cve and {provider}2nvd commands do.
+//
+// Matching an inventory against NVD feeds:
+//
+//	dict, err := nvdtools.LoadFeeds("nvdcve-1.1-2023.json.gz", "nvdcve-1.1-2024.json.gz")
+//	if err != nil {
+//		return err
+//	}
+//	m := nvdtools.NewMatcher(dict).SetRequireVersion(true)
+//	matches, err := m.Match("cpe:/a:haxx:curl:7.55.0", "cpe:/o:linux:linux_kernel:5.4")
+//	for _, match := range matches {
+//		fmt.Println(match.CVE, match.CPEs)
+//	}
+//
+// Fetching vulnerabilities of a provider, converted to NVD CVE items:
+//
+//	f := nvdtools.Fetcher{Provider: "ghsa", Env: map[string]string{"GITHUB_TOKEN": token}}
+//	err := f.Fetch(ctx, since, func(item *schema.NVDCVEFeedJSON10DefCVEItem) error {
+//		return store(item)
+//	})
+//
+// The packages this one is built on (cvefeed, wfn, providers/...) have lower-level APIs for what it doesn't cover.
+package nvdtools
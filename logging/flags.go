@@ -0,0 +1,130 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"flag"
+	"log"
+	"os"
+	"strconv"
+)
+
+// Environment variables providing defaults for the flags
+const (
+	EnvLevel  = "NVDTOOLS_LOG_LEVEL"
+	EnvFormat = "NVDTOOLS_LOG_FORMAT"
+	EnvQuiet  = "NVDTOOLS_LOG_QUIET"
+	EnvStrict = "NVDTOOLS_LOG_STRICT"
+)
+
+// AddFlags configures the default logger from the environment and adds flags to override it:
+// -log_level, -log_format, -quiet and -strict. It also redirects the standard log package
+// to the default logger, so everything ends up in the same format.
+func AddFlags() {
+	if err := configureFromEnv(std, os.Getenv); err != nil {
+		std.Warningf("%v", err)
+	}
+	flag.Var(levelFlag{std}, "log_level", "minimum level of logged messages: debug, info, warning or error (env "+EnvLevel+")")
+	flag.Var(formatFlag{std}, "log_format", "format of logged messages: text or json (env "+EnvFormat+")")
+	flag.Var(boolFlag{std.Quiet, std.SetQuiet}, "quiet", "don't log problems with individual records while parsing (env "+EnvQuiet+")")
+	flag.Var(boolFlag{std.Strict, std.SetStrict}, "strict", "treat problems with individual records as errors and fail (env "+EnvStrict+")")
+	log.SetFlags(0)
+	log.SetOutput(std)
+}
+
+// configureFromEnv applies the settings found in the environment to the logger
+func configureFromEnv(l *Logger, getenv func(string) string) error {
+	if v := getenv(EnvLevel); v != "" {
+		level, err := ParseLevel(v)
+		if err != nil {
+			return err
+		}
+		l.SetLevel(level)
+	}
+	if v := getenv(EnvFormat); v != "" {
+		if err := l.SetFormat(v); err != nil {
+			return err
+		}
+	}
+	for name, set := range map[string]func(bool){EnvQuiet: l.SetQuiet, EnvStrict: l.SetStrict} {
+		if v := getenv(name); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return err
+			}
+			set(b)
+		}
+	}
+	return nil
+}
+
+// levelFlag is a flag.Value setting the level of a logger
+type levelFlag struct{ l *Logger }
+
+func (f levelFlag) String() string {
+	if f.l == nil {
+		return InfoLevel.String()
+	}
+	return f.l.Level().String()
+}
+
+func (f levelFlag) Set(s string) error {
+	level, err := ParseLevel(s)
+	if err != nil {
+		return err
+	}
+	f.l.SetLevel(level)
+	return nil
+}
+
+// formatFlag is a flag.Value setting the format of a logger
+type formatFlag struct{ l *Logger }
+
+func (f formatFlag) String() string {
+	if f.l == nil {
+		return TextFormat
+	}
+	return f.l.Format()
+}
+
+func (f formatFlag) Set(s string) error {
+	return f.l.SetFormat(s)
+}
+
+// boolFlag is a boolean flag.Value backed by a getter and a setter
+type boolFlag struct {
+	get func() bool
+	set func(bool)
+}
+
+func (f boolFlag) String() string {
+	if f.get == nil {
+		return "false"
+	}
+	return strconv.FormatBool(f.get())
+}
+
+func (f boolFlag) Set(s string) error {
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return err
+	}
+	f.set(b)
+	return nil
+}
+
+func (f boolFlag) IsBoolFlag() bool {
+	return true
+}
@@ -0,0 +1,338 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logging implements a leveled, structured logger shared by nvdtools libraries and commands.
+//
+// Entries are written either as text or as JSON objects, one per line. Problems with individual
+// records found while parsing feeds are reported with Recordf: they are logged as warnings by
+// default, dropped in quiet mode and turned into errors in strict mode, in which case Err returns
+// a non-nil error so commands can exit with a failure.
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is the severity of a log entry
+type Level int
+
+// Supported levels, from the most to the least verbose
+const (
+	DebugLevel Level = iota
+	InfoLevel
+	WarningLevel
+	ErrorLevel
+)
+
+var levelNames = [...]string{"debug", "info", "warning", "error"}
+
+// String returns the name of the level
+func (l Level) String() string {
+	if l < DebugLevel || l > ErrorLevel {
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+	return levelNames[l]
+}
+
+// ParseLevel returns the level with the given name
+func ParseLevel(s string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return DebugLevel, nil
+	case "info":
+		return InfoLevel, nil
+	case "warning", "warn":
+		return WarningLevel, nil
+	case "error":
+		return ErrorLevel, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q", s)
+}
+
+// Output formats
+const (
+	TextFormat = "text"
+	JSONFormat = "json"
+)
+
+// Logger writes leveled entries to an io.Writer; it's safe for concurrent use
+type Logger struct {
+	mu      sync.Mutex
+	out     io.Writer
+	level   Level
+	json    bool
+	quiet   bool
+	strict  bool
+	records int
+	errors  int
+	now     func() time.Time
+}
+
+// New creates a new text logger writing entries of info level and above to out
+func New(out io.Writer) *Logger {
+	return &Logger{out: out, level: InfoLevel, now: time.Now}
+}
+
+// SetOutput sets the destination of the log entries
+func (l *Logger) SetOutput(out io.Writer) {
+	l.mu.Lock()
+	l.out = out
+	l.mu.Unlock()
+}
+
+// SetLevel sets the minimum level of the entries to write
+func (l *Logger) SetLevel(level Level) {
+	l.mu.Lock()
+	l.level = level
+	l.mu.Unlock()
+}
+
+// Level returns the minimum level of the entries to write
+func (l *Logger) Level() Level {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.level
+}
+
+// SetFormat sets the output format, TextFormat or JSONFormat
+func (l *Logger) SetFormat(format string) error {
+	var isJSON bool
+	switch strings.ToLower(format) {
+	case TextFormat:
+	case JSONFormat:
+		isJSON = true
+	default:
+		return fmt.Errorf("unknown log format %q", format)
+	}
+	l.mu.Lock()
+	l.json = isJSON
+	l.mu.Unlock()
+	return nil
+}
+
+// Format returns the output format
+func (l *Logger) Format() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.json {
+		return JSONFormat
+	}
+	return TextFormat
+}
+
+// SetQuiet enables or disables quiet mode, which drops problems reported with Recordf
+func (l *Logger) SetQuiet(quiet bool) {
+	l.mu.Lock()
+	l.quiet = quiet
+	l.mu.Unlock()
+}
+
+// Quiet returns whether quiet mode is enabled
+func (l *Logger) Quiet() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.quiet
+}
+
+// SetStrict enables or disables strict mode, which turns problems reported with Recordf into errors
+func (l *Logger) SetStrict(strict bool) {
+	l.mu.Lock()
+	l.strict = strict
+	l.mu.Unlock()
+}
+
+// Strict returns whether strict mode is enabled
+func (l *Logger) Strict() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.strict
+}
+
+// Log writes an entry with the given level and message, and fields given as alternating keys and values
+func (l *Logger) Log(level Level, msg string, keysAndValues ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.output(level, msg, keysAndValues)
+}
+
+// Debugf logs a formatted message at debug level
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.Log(DebugLevel, fmt.Sprintf(format, args...))
+}
+
+// Infof logs a formatted message at info level
+func (l *Logger) Infof(format string, args ...interface{}) {
+	l.Log(InfoLevel, fmt.Sprintf(format, args...))
+}
+
+// Warningf logs a formatted message at warning level
+func (l *Logger) Warningf(format string, args ...interface{}) {
+	l.Log(WarningLevel, fmt.Sprintf(format, args...))
+}
+
+// Errorf logs a formatted message at error level
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.Log(ErrorLevel, fmt.Sprintf(format, args...))
+}
+
+// Recordf reports a problem with a single record, e.g. a field which couldn't be parsed
+// while the rest of the record (and feed) can still be used
+func (l *Logger) Recordf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	switch {
+	case l.strict:
+		l.records++
+		l.output(ErrorLevel, fmt.Sprintf(format, args...), nil)
+	case !l.quiet:
+		l.output(WarningLevel, fmt.Sprintf(format, args...), nil)
+	}
+}
+
+// Err returns an error if problems with records were reported in strict mode
+func (l *Logger) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.records == 0 {
+		return nil
+	}
+	return fmt.Errorf("strict mode: %d record(s) couldn't be parsed", l.records)
+}
+
+// Errors returns how many entries were logged at error level, including records reported in strict mode
+func (l *Logger) Errors() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.errors
+}
+
+// Write logs p at info level; it allows using the logger as output of the standard log package
+func (l *Logger) Write(p []byte) (int, error) {
+	l.Log(InfoLevel, string(bytes.TrimRight(p, "\n")))
+	return len(p), nil
+}
+
+// output writes a single entry, l.mu must be held
+func (l *Logger) output(level Level, msg string, kvs []interface{}) {
+	if level >= ErrorLevel {
+		l.errors++
+	}
+	if level < l.level {
+		return
+	}
+	if len(kvs)%2 != 0 {
+		kvs = append(kvs, "(MISSING)")
+	}
+	var buf bytes.Buffer
+	ts := l.now().UTC().Format(time.RFC3339)
+	if l.json {
+		buf.WriteByte('{')
+		writeJSON(&buf, "time", ts)
+		buf.WriteByte(',')
+		writeJSON(&buf, "level", level.String())
+		buf.WriteByte(',')
+		writeJSON(&buf, "msg", msg)
+		for i := 0; i < len(kvs); i += 2 {
+			buf.WriteByte(',')
+			writeJSON(&buf, fmt.Sprint(kvs[i]), kvs[i+1])
+		}
+		buf.WriteString("}\n")
+	} else {
+		fmt.Fprintf(&buf, "%s %s %s", ts, strings.ToUpper(level.String()), msg)
+		for i := 0; i < len(kvs); i += 2 {
+			fmt.Fprintf(&buf, " %v=%s", kvs[i], textValue(kvs[i+1]))
+		}
+		buf.WriteByte('\n')
+	}
+	l.out.Write(buf.Bytes())
+}
+
+// writeJSON writes "key":value to buf
+func writeJSON(buf *bytes.Buffer, key string, value interface{}) {
+	if err, ok := value.(error); ok {
+		value = err.Error()
+	}
+	k, _ := json.Marshal(key)
+	v, err := json.Marshal(value)
+	if err != nil {
+		v, _ = json.Marshal(fmt.Sprint(value))
+	}
+	buf.Write(k)
+	buf.WriteByte(':')
+	buf.Write(v)
+}
+
+// textValue formats value for the text format, quoting it if needed
+func textValue(value interface{}) string {
+	s := fmt.Sprint(value)
+	if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+var std = New(os.Stderr)
+
+// Default returns the logger used by the package level functions
+func Default() *Logger {
+	return std
+}
+
+// Log writes an entry to the default logger
+func Log(level Level, msg string, keysAndValues ...interface{}) {
+	std.Log(level, msg, keysAndValues...)
+}
+
+// Debugf logs a formatted message at debug level to the default logger
+func Debugf(format string, args ...interface{}) {
+	std.Debugf(format, args...)
+}
+
+// Infof logs a formatted message at info level to the default logger
+func Infof(format string, args ...interface{}) {
+	std.Infof(format, args...)
+}
+
+// Warningf logs a formatted message at warning level to the default logger
+func Warningf(format string, args ...interface{}) {
+	std.Warningf(format, args...)
+}
+
+// Errorf logs a formatted message at error level to the default logger
+func Errorf(format string, args ...interface{}) {
+	std.Errorf(format, args...)
+}
+
+// Recordf reports a problem with a single record to the default logger
+func Recordf(format string, args ...interface{}) {
+	std.Recordf(format, args...)
+}
+
+// Err returns an error if problems with records were reported to the default logger in strict mode
+func Err() error {
+	return std.Err()
+}
+
+// Errors returns how many entries were logged at error level to the default logger
+func Errors() int {
+	return std.Errors()
+}
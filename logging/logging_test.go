@@ -0,0 +1,177 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestLogger() (*Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	l := New(&buf)
+	l.now = func() time.Time { return time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC) }
+	return l, &buf
+}
+
+func TestParseLevel(t *testing.T) {
+	cases := []struct {
+		in    string
+		level Level
+		fail  bool
+	}{
+		{in: "debug", level: DebugLevel},
+		{in: "INFO", level: InfoLevel},
+		{in: "warn", level: WarningLevel},
+		{in: " warning ", level: WarningLevel},
+		{in: "error", level: ErrorLevel},
+		{in: "fatal", fail: true},
+	}
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			level, err := ParseLevel(c.in)
+			if c.fail {
+				if err == nil {
+					t.Fatalf("expected an error, got level %v", level)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if level != c.level {
+				t.Fatalf("expected %v, got %v", c.level, level)
+			}
+		})
+	}
+}
+
+func TestText(t *testing.T) {
+	l, buf := newTestLogger()
+	l.Debugf("not logged")
+	l.Infof("hello %s", "world")
+	l.Log(WarningLevel, "fields", "id", "CVE-2020-0001", "err", "can't parse", "odd")
+	expected := "2020-01-02T03:04:05Z INFO hello world\n" +
+		"2020-01-02T03:04:05Z WARNING fields id=CVE-2020-0001 err=\"can't parse\" odd=(MISSING)\n"
+	if buf.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestJSON(t *testing.T) {
+	l, buf := newTestLogger()
+	l.SetLevel(DebugLevel)
+	if err := l.SetFormat("json"); err != nil {
+		t.Fatal(err)
+	}
+	l.Log(DebugLevel, "msg", "n", 3, "err", fmt.Errorf("boom"))
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("can't decode %q: %v", buf.String(), err)
+	}
+	expected := map[string]interface{}{
+		"time":  "2020-01-02T03:04:05Z",
+		"level": "debug",
+		"msg":   "msg",
+		"n":     float64(3),
+		"err":   "boom",
+	}
+	if fmt.Sprint(entry) != fmt.Sprint(expected) {
+		t.Fatalf("expected %v, got %v", expected, entry)
+	}
+	if !strings.HasPrefix(buf.String(), `{"time":`) {
+		t.Fatalf("fields should keep their order, got %q", buf.String())
+	}
+	if err := l.SetFormat("xml"); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
+
+func TestRecordf(t *testing.T) {
+	l, buf := newTestLogger()
+	l.Recordf("bad record %d", 1)
+	if !strings.Contains(buf.String(), "WARNING bad record 1") {
+		t.Fatalf("expected a warning, got %q", buf.String())
+	}
+	if err := l.Err(); err != nil {
+		t.Fatalf("unexpected error outside of strict mode: %v", err)
+	}
+
+	buf.Reset()
+	l.SetQuiet(true)
+	l.Recordf("bad record %d", 2)
+	l.Warningf("still logged")
+	if strings.Contains(buf.String(), "bad record") || !strings.Contains(buf.String(), "still logged") {
+		t.Fatalf("quiet mode should only drop records, got %q", buf.String())
+	}
+
+	buf.Reset()
+	l.SetStrict(true)
+	l.Recordf("bad record %d", 3)
+	l.Recordf("bad record %d", 4)
+	if !strings.Contains(buf.String(), "ERROR bad record 3") {
+		t.Fatalf("expected an error in strict mode, got %q", buf.String())
+	}
+	if err := l.Err(); err == nil || !strings.Contains(err.Error(), "2 record(s)") {
+		t.Fatalf("expected an error about 2 records, got %v", err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	l, _ := newTestLogger()
+	l.Warningf("not an error")
+	l.Recordf("not an error outside of strict mode")
+	l.Errorf("error %d", 1)
+	l.Log(ErrorLevel, "error 2")
+	l.SetStrict(true)
+	l.Recordf("error 3")
+	if n := l.Errors(); n != 3 {
+		t.Fatalf("expected 3 errors, got %d", n)
+	}
+}
+
+func TestConfigureFromEnv(t *testing.T) {
+	env := map[string]string{
+		EnvLevel:  "error",
+		EnvFormat: "json",
+		EnvQuiet:  "1",
+		EnvStrict: "false",
+	}
+	l, _ := newTestLogger()
+	if err := configureFromEnv(l, func(k string) string { return env[k] }); err != nil {
+		t.Fatal(err)
+	}
+	if l.Level() != ErrorLevel || l.Format() != JSONFormat || !l.Quiet() || l.Strict() {
+		t.Fatalf("logger wasn't configured from env: %v %v %v %v", l.Level(), l.Format(), l.Quiet(), l.Strict())
+	}
+
+	env[EnvLevel] = "loud"
+	if err := configureFromEnv(l, func(k string) string { return env[k] }); err == nil {
+		t.Fatal("expected an error for an invalid level")
+	}
+}
+
+func TestWrite(t *testing.T) {
+	l, buf := newTestLogger()
+	fmt.Fprintln(l, "from the log package")
+	expected := "2020-01-02T03:04:05Z INFO from the log package\n"
+	if buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+}
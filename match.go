@@ -0,0 +1,120 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdtools
+
+import (
+	"context"
+	"sort"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// LoadFeeds loads vulnerabilities from NVD JSON 1.1 or 2.0 feeds, or feeds converted to NVD format
+// by the providers; feeds can be compressed. Vulnerabilities are keyed by their ids.
+func LoadFeeds(paths ...string) (cvefeed.Dictionary, error) {
+	return cvefeed.LoadJSONDictionary(paths...)
+}
+
+// LoadFeedsContext is LoadFeeds which stops loading and returns the error of the context once it's done
+func LoadFeedsContext(ctx context.Context, paths ...string) (cvefeed.Dictionary, error) {
+	return cvefeed.LoadJSONDictionaryContext(ctx, paths...)
+}
+
+// Match is a vulnerability matching some of the CPE names passed to Matcher
+type Match struct {
+	// CVE is the id of the vulnerability
+	CVE string
+	// Vuln is the matching vulnerability, with its scores, CWEs, etc.
+	Vuln cvefeed.Vuln
+	// CPEs are the CPE names which matched, bound to URI
+	CPEs []string
+}
+
+// Matcher matches CPE names against vulnerabilities of a dictionary.
+// Matches of each list of CPE names are cached, so matching the same list again is cheap.
+// It's safe for concurrent use.
+type Matcher struct {
+	cache *cvefeed.Cache
+}
+
+// NewMatcher returns a matcher of vulnerabilities in the dictionary
+func NewMatcher(dict cvefeed.Dictionary) *Matcher {
+	return &Matcher{cache: cvefeed.NewCache(dict).SetInvertedIndex()}
+}
+
+// SetRequireVersion sets whether CPE match criteria of vulnerabilities without a version or a version range
+// are ignored, rather than matching any version of the product.
+// Returns the matcher, for easy chaining.
+func (m *Matcher) SetRequireVersion(requireVersion bool) *Matcher {
+	m.cache.SetRequireVersion(requireVersion)
+	return m
+}
+
+// SetMaxEntries limits the number of cached lists of CPE names, 0 removes the limit.
+// Returns the matcher, for easy chaining.
+func (m *Matcher) SetMaxEntries(n int) *Matcher {
+	m.cache.SetMaxEntries(n)
+	return m
+}
+
+// Match returns vulnerabilities matching the CPE names (bound to URI or formatted string), sorted by CVE id
+func (m *Matcher) Match(cpes ...string) ([]Match, error) {
+	attrs, err := parseCPEs(cpes)
+	if err != nil {
+		return nil, err
+	}
+	return matches(m.cache.Get(attrs)), nil
+}
+
+// MatchOnPlatform is Match which matches the running_on conditions of vulnerabilities (operating systems
+// which aren't vulnerable themselves) only against the platforms, e.g. the OS of the host the CPEs are installed on
+func (m *Matcher) MatchOnPlatform(cpes []string, platforms ...string) ([]Match, error) {
+	attrs, err := parseCPEs(cpes)
+	if err != nil {
+		return nil, err
+	}
+	pattrs, err := parseCPEs(platforms)
+	if err != nil {
+		return nil, err
+	}
+	return matches(m.cache.GetWithPlatform(attrs, pattrs)), nil
+}
+
+func parseCPEs(cpes []string) ([]*wfn.Attributes, error) {
+	attrs := make([]*wfn.Attributes, len(cpes))
+	for i, cpe := range cpes {
+		var err error
+		if attrs[i], err = wfn.Parse(cpe); err != nil {
+			return nil, err
+		}
+	}
+	return attrs, nil
+}
+
+func matches(results []cvefeed.MatchResult) []Match {
+	ms := make([]Match, 0, len(results))
+	for _, res := range results {
+		m := Match{CVE: res.CVE.ID(), Vuln: res.CVE, CPEs: make([]string, len(res.CPEs))}
+		for i, attrs := range res.CPEs {
+			m.CPEs[i] = attrs.BindToURI()
+		}
+		ms = append(ms, m)
+	}
+	sort.Slice(ms, func(i, j int) bool {
+		return ms[i].CVE < ms[j].CVE
+	})
+	return ms
+}
@@ -0,0 +1,96 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdtools
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testFeed = `{"CVE_Items": [
+{"cve": {"CVE_data_meta": {"ID": "CVE-0002"}}, "configurations": {"nodes": [{"operator": "OR", "cpe_match": [
+	{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:vendor:product:*:*:*:*:*:*:*:*", "versionEndExcluding": "2.0"}]}]}},
+{"cve": {"CVE_data_meta": {"ID": "CVE-0001"}}, "configurations": {"nodes": [{"operator": "AND", "children": [
+	{"operator": "OR", "cpe_match": [{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:vendor:product:1.0:*:*:*:*:*:*:*"}]},
+	{"operator": "OR", "cpe_match": [{"vulnerable": false, "cpe23Uri": "cpe:2.3:o:vendor:os:*:*:*:*:*:*:*:*"}]}]}]}},
+{"cve": {"CVE_data_meta": {"ID": "CVE-0003"}}, "configurations": {"nodes": [{"operator": "OR", "cpe_match": [
+	{"vulnerable": true, "cpe23Uri": "cpe:2.3:a:vendor:other:*:*:*:*:*:*:*:*"}]}]}}
+]}`
+
+func TestMatcher(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nvdtools")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "feed.json")
+	if err := ioutil.WriteFile(path, []byte(testFeed), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dict, err := LoadFeeds(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := NewMatcher(dict)
+
+	ids := func(matches []Match) []string {
+		ids := []string{}
+		for _, match := range matches {
+			ids = append(ids, match.CVE)
+		}
+		return ids
+	}
+
+	matches, err := m.Match("cpe:/a:vendor:product:1.0", "cpe:2.3:o:vendor:os:10:*:*:*:*:*:*:*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(matches); !reflect.DeepEqual(got, []string{"CVE-0001", "CVE-0002"}) {
+		t.Fatalf("unexpected matches %v", got)
+	}
+	if matches[1].Vuln.ID() != "CVE-0002" || !reflect.DeepEqual(matches[1].CPEs, []string{"cpe:/a:vendor:product:1.0"}) {
+		t.Fatalf("unexpected match %+v", matches[1])
+	}
+
+	// the OS matches as the platform only
+	matches, err = m.MatchOnPlatform([]string{"cpe:/a:vendor:product:1.0", "cpe:/o:vendor:os:10"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(matches); !reflect.DeepEqual(got, []string{"CVE-0002"}) {
+		t.Fatalf("unexpected matches on no platform %v", got)
+	}
+	matches, err = m.MatchOnPlatform([]string{"cpe:/a:vendor:product:1.0"}, "cpe:/o:vendor:os:10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ids(matches); !reflect.DeepEqual(got, []string{"CVE-0001", "CVE-0002"}) {
+		t.Fatalf("unexpected matches on the platform %v", got)
+	}
+
+	if _, err := m.Match("vendor:product:1.0"); err == nil {
+		t.Fatal("expected invalid CPE name to fail")
+	}
+	if matches, err := m.Match("cpe:/a:vendor:other:1.0"); err != nil || len(matches) != 1 {
+		t.Fatalf("expected a match of any version, got %v, %v", matches, err)
+	}
+	m = NewMatcher(dict).SetRequireVersion(true)
+	if matches, err := m.Match("cpe:/a:vendor:other:1.0"); err != nil || len(matches) != 0 {
+		t.Fatalf("expected no matches of any version, got %v, %v", matches, err)
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/facebookincubator/nvdtools/logging"
+)
+
+var (
+	flagAddr string
+	flagFile string
+)
+
+// AddFlags adds the -metrics_addr and -metrics_file flags, used by Start and Flush
+func AddFlags() {
+	flag.StringVar(&flagAddr, "metrics_addr", "", "serve Prometheus metrics on this address, at /metrics")
+	flag.StringVar(&flagFile, "metrics_file", "", "write Prometheus metrics to this file when done, e.g. for the node exporter textfile collector")
+}
+
+// Start serves the default registry on the address from -metrics_addr in background, if it's set
+func Start() {
+	if flagAddr == "" {
+		return
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Default.Handler())
+	go func() {
+		logging.Infof("serving metrics on %s", flagAddr)
+		if err := http.ListenAndServe(flagAddr, mux); err != nil {
+			logging.Errorf("can't serve metrics: %v", err)
+		}
+	}()
+}
+
+// Flush writes the default registry to the file from -metrics_file, if it's set
+func Flush() error {
+	if flagFile == "" {
+		return nil
+	}
+	return Default.WriteFile(flagFile)
+}
+
+// WriteFile writes the metrics to a temporary file in the same directory and renames it,
+// so the textfile collector never reads a partially written file
+func (r *Registry) WriteFile(path string) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = r.WriteTo(tmp)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
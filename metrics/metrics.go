@@ -0,0 +1,295 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics implements counters, gauges and histograms which can be exposed
+// in the Prometheus text format, either over HTTP or written to a file for the node exporter
+// textfile collector (for commands which run as batch jobs).
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefBuckets are the default histogram buckets, in seconds
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300}
+
+// Registry holds metrics, which are created on the first use and written in the order of creation
+type Registry struct {
+	mu      sync.Mutex
+	metrics map[string]*family
+	order   []*family
+}
+
+// NewRegistry creates a new empty registry
+func NewRegistry() *Registry {
+	return &Registry{metrics: make(map[string]*family)}
+}
+
+// Default is the registry used by package level functions
+var Default = NewRegistry()
+
+// family is a metric with all its labeled series
+type family struct {
+	name    string
+	help    string
+	typ     string
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+// series is a single labeled series of a metric
+type series struct {
+	labelValues []string
+	value       float64
+	// histograms only
+	counts []uint64
+	count  uint64
+}
+
+func (r *Registry) family(name, help, typ string, buckets []float64, labels []string) *family {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if f, ok := r.metrics[name]; ok {
+		if f.typ != typ || len(f.labels) != len(labels) {
+			panic(fmt.Sprintf("metrics: %s is already registered as %s with labels %v", name, f.typ, f.labels))
+		}
+		return f
+	}
+	f := &family{
+		name:    name,
+		help:    help,
+		typ:     typ,
+		labels:  labels,
+		buckets: buckets,
+		series:  make(map[string]*series),
+	}
+	r.metrics[name] = f
+	r.order = append(r.order, f)
+	return f
+}
+
+// with calls fn with the series with given label values, creating it if needed
+func (f *family) with(labelValues []string, fn func(s *series)) {
+	if len(labelValues) != len(f.labels) {
+		panic(fmt.Sprintf("metrics: %s needs %d label values, got %d", f.name, len(f.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		if f.typ == "histogram" {
+			s.counts = make([]uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+	fn(s)
+}
+
+// Counter is a value which only goes up, e.g. the number of processed records
+type Counter struct{ f *family }
+
+// Counter returns the counter with the given name, registering it if needed
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	return &Counter{r.family(name, help, "counter", nil, labels)}
+}
+
+// Inc increments the counter by 1
+func (c *Counter) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v, which mustn't be negative, to the counter
+func (c *Counter) Add(v float64, labelValues ...string) {
+	if v < 0 {
+		panic(fmt.Sprintf("metrics: counter %s can't decrease", c.f.name))
+	}
+	c.f.with(labelValues, func(s *series) { s.value += v })
+}
+
+// Gauge is a value which can go up and down, e.g. the time of the last successful run
+type Gauge struct{ f *family }
+
+// Gauge returns the gauge with the given name, registering it if needed
+func (r *Registry) Gauge(name, help string, labels ...string) *Gauge {
+	return &Gauge{r.family(name, help, "gauge", nil, labels)}
+}
+
+// Set sets the gauge to v
+func (g *Gauge) Set(v float64, labelValues ...string) {
+	g.f.with(labelValues, func(s *series) { s.value = v })
+}
+
+// Add adds v to the gauge
+func (g *Gauge) Add(v float64, labelValues ...string) {
+	g.f.with(labelValues, func(s *series) { s.value += v })
+}
+
+// Histogram counts observations, e.g. latencies, in buckets
+type Histogram struct{ f *family }
+
+// Histogram returns the histogram with the given name, registering it if needed;
+// DefBuckets are used if buckets is nil
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	if buckets == nil {
+		buckets = DefBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &Histogram{r.family(name, help, "histogram", buckets, labels)}
+}
+
+// Observe adds a single observation to the histogram
+func (h *Histogram) Observe(v float64, labelValues ...string) {
+	h.f.with(labelValues, func(s *series) {
+		for i, b := range h.f.buckets {
+			if v <= b {
+				s.counts[i]++
+			}
+		}
+		s.count++
+		s.value += v
+	})
+}
+
+// WriteTo writes all metrics to w in the Prometheus text format
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	families := append([]*family(nil), r.order...)
+	r.mu.Unlock()
+
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+	for _, f := range families {
+		f.write(cw)
+	}
+	if cw.err == nil {
+		cw.err = cw.w.(*bufio.Writer).Flush()
+	}
+	return cw.n, cw.err
+}
+
+// Handler returns an http.Handler serving the metrics
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteTo(w)
+	})
+}
+
+func (f *family) write(w io.Writer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.series) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", f.name, f.typ)
+
+	keys := make([]string, 0, len(f.series))
+	for k := range f.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := f.series[k]
+		if f.typ != "histogram" {
+			fmt.Fprintf(w, "%s%s %s\n", f.name, f.labelString(s.labelValues, "", 0), formatFloat(s.value))
+			continue
+		}
+		for i, b := range f.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, f.labelString(s.labelValues, "le", b), s.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", f.name, f.labelString(s.labelValues, "le", math.Inf(1)), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", f.name, f.labelString(s.labelValues, "", 0), formatFloat(s.value))
+		fmt.Fprintf(w, "%s_count%s %d\n", f.name, f.labelString(s.labelValues, "", 0), s.count)
+	}
+}
+
+// labelString formats the labels of a series, with an extra label if its name isn't empty
+func (f *family) labelString(values []string, extra string, extraValue float64) string {
+	var pairs []string
+	for i, name := range f.labels {
+		pairs = append(pairs, name+`="`+escapeLabel(values[i])+`"`)
+	}
+	if extra != "" {
+		pairs = append(pairs, extra+`="`+formatFloat(extraValue)+`"`)
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+type countingWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	if cw.err != nil {
+		return 0, cw.err
+	}
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	cw.err = err
+	return n, err
+}
+
+// NewCounter returns the counter with the given name from the default registry
+func NewCounter(name, help string, labels ...string) *Counter {
+	return Default.Counter(name, help, labels...)
+}
+
+// NewGauge returns the gauge with the given name from the default registry
+func NewGauge(name, help string, labels ...string) *Gauge {
+	return Default.Gauge(name, help, labels...)
+}
+
+// NewHistogram returns the histogram with the given name from the default registry
+func NewHistogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	return Default.Histogram(name, help, buckets, labels...)
+}
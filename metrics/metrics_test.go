@@ -0,0 +1,119 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteTo(t *testing.T) {
+	r := NewRegistry()
+	c := r.Counter("records_total", "Number of records.", "provider")
+	c.Inc("b")
+	c.Add(2, "a")
+	c.Inc("a")
+	r.Gauge("last_success_timestamp_seconds", "Time of the last success.").Set(1.5e9)
+	r.Gauge("unused", "Not written without series.")
+	h := r.Histogram("duration_seconds", "Duration.", []float64{1, 0.1}, "op")
+	h.Observe(0.05, `say "hi"`)
+	h.Observe(0.5, `say "hi"`)
+	h.Observe(5, `say "hi"`)
+
+	var buf bytes.Buffer
+	if _, err := r.WriteTo(&buf); err != nil {
+		t.Fatal(err)
+	}
+	expected := `# HELP records_total Number of records.
+# TYPE records_total counter
+records_total{provider="a"} 3
+records_total{provider="b"} 1
+# HELP last_success_timestamp_seconds Time of the last success.
+# TYPE last_success_timestamp_seconds gauge
+last_success_timestamp_seconds 1.5e+09
+# HELP duration_seconds Duration.
+# TYPE duration_seconds histogram
+duration_seconds_bucket{op="say \"hi\"",le="0.1"} 1
+duration_seconds_bucket{op="say \"hi\"",le="1"} 2
+duration_seconds_bucket{op="say \"hi\"",le="+Inf"} 3
+duration_seconds_sum{op="say \"hi\""} 5.55
+duration_seconds_count{op="say \"hi\""} 3
+`
+	if buf.String() != expected {
+		t.Fatalf("expected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestRegistryReuse(t *testing.T) {
+	r := NewRegistry()
+	r.Counter("c", "help").Inc()
+	r.Counter("c", "help").Inc()
+	var buf bytes.Buffer
+	r.WriteTo(&buf)
+	if !strings.Contains(buf.String(), "c 2\n") {
+		t.Fatalf("counter wasn't reused:\n%s", buf.String())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected a panic when registering a gauge with the name of a counter")
+		}
+	}()
+	r.Gauge("c", "help")
+}
+
+func TestHandler(t *testing.T) {
+	r := NewRegistry()
+	r.Gauge("g", "help").Set(1)
+	w := httptest.NewRecorder()
+	r.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	if !strings.Contains(w.Body.String(), "g 1\n") {
+		t.Fatalf("unexpected body:\n%s", w.Body.String())
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := NewRegistry()
+	r.Gauge("g", "help").Set(2)
+	path := filepath.Join(dir, "nvdsync.prom")
+	if err := r.WriteFile(path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "g 2\n") {
+		t.Fatalf("unexpected file content:\n%s", data)
+	}
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Fatalf("temporary file wasn't removed: %d files in dir", len(files))
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// descriptor points to a manifest or a layer
+type descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Platform    *platform         `json:"platform,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+type platform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+func (p *platform) String() string {
+	s := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		s += "/" + p.Variant
+	}
+	return s
+}
+
+// manifest is either an image index, which lists manifests of images for different platforms,
+// or an image manifest, which lists layers of the image; docker manifests have the same structure
+type manifest struct {
+	MediaType string       `json:"mediaType"`
+	Manifests []descriptor `json:"manifests"`
+	Layers    []descriptor `json:"layers"`
+}
+
+// maximum depth of nested indexes
+const maxIndexDepth = 4
+
+// resolve returns layers of the image for the platform, following indexes from the given manifest
+// fetch returns manifests by their digest
+func resolve(data []byte, platform string, fetch func(digest string) ([]byte, error)) ([]descriptor, error) {
+	for depth := 0; depth < maxIndexDepth; depth++ {
+		var m manifest
+		if err := json.Unmarshal(data, &m); err != nil {
+			return nil, fmt.Errorf("can't decode manifest: %v", err)
+		}
+		if m.Manifests == nil {
+			return m.Layers, nil
+		}
+		desc, err := selectManifest(m.Manifests, platform)
+		if err != nil {
+			return nil, err
+		}
+		if data, err = fetch(desc.Digest); err != nil {
+			return nil, fmt.Errorf("can't fetch manifest %s: %v", desc.Digest, err)
+		}
+	}
+	return nil, fmt.Errorf("too many nested image indexes")
+}
+
+// selectManifest selects the manifest for the platform, e.g. linux/arm64 or linux/arm/v7
+// manifests without platform are selected if there's only one of them, as in OCI image layout index
+func selectManifest(manifests []descriptor, want string) (*descriptor, error) {
+	var candidates, available []string
+	var found *descriptor
+	for i := range manifests {
+		desc := &manifests[i]
+		if desc.Annotations["vnd.docker.reference.type"] == "attestation-manifest" {
+			continue
+		}
+		if desc.Platform == nil {
+			candidates = append(candidates, desc.Digest)
+			found = desc
+			continue
+		}
+		p := desc.Platform.String()
+		available = append(available, p)
+		// variant doesn't need to be given, e.g. linux/arm64 selects linux/arm64/v8
+		if p == want || desc.Platform.OS+"/"+desc.Platform.Architecture == want {
+			return desc, nil
+		}
+	}
+	if len(available) == 0 && len(candidates) == 1 {
+		return found, nil
+	}
+	return nil, fmt.Errorf("no image found for platform %s, available: %s", want, strings.Join(available, ", "))
+}
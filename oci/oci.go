@@ -0,0 +1,350 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package oci reads container images, from docker save and OCI layout archives or from registries,
+// and lists the packages installed in them, so they can be matched against vulnerability feeds.
+package oci
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultPlatform is the platform which is selected from multi-platform images by default
+const DefaultPlatform = "linux/amd64"
+
+// Image is a container image
+type Image struct {
+	// layers open layers of the image in order, from the base one
+	layers []func() (io.ReadCloser, error)
+	close  func() error
+}
+
+// Close releases resources used by the image
+func (img *Image) Close() error {
+	if img.close == nil {
+		return nil
+	}
+	return img.close()
+}
+
+// Open opens an image saved by docker save or in OCI image layout, either as a directory or as an archive,
+// which can be gzip compressed; platform selects the image from multi-platform images, e.g. linux/arm64
+func Open(name, platform string) (*Image, error) {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return nil, err
+	}
+	var store blobStore
+	if fi.IsDir() {
+		store = dirStore(name)
+	} else if store, err = openArchive(name); err != nil {
+		return nil, fmt.Errorf("can't open archive %s: %v", name, err)
+	}
+
+	img, err := openImage(store, platform)
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+	img.close = store.Close
+	return img, nil
+}
+
+// blobStore gives access to files of a saved image
+type blobStore interface {
+	Open(name string) (io.ReadCloser, error)
+	Close() error
+}
+
+// openImage finds layers of the image in docker save manifest or in the OCI image layout index
+func openImage(store blobStore, platform string) (*Image, error) {
+	var layers []string
+	if data, err := readBlob(store, "manifest.json"); err == nil {
+		var manifests []struct {
+			Layers []string `json:"Layers"`
+		}
+		if err := json.Unmarshal(data, &manifests); err != nil {
+			return nil, fmt.Errorf("can't decode manifest.json: %v", err)
+		}
+		if len(manifests) == 0 {
+			return nil, fmt.Errorf("no images found in manifest.json")
+		}
+		layers = manifests[0].Layers
+	} else {
+		data, err := readBlob(store, "index.json")
+		if err != nil {
+			return nil, fmt.Errorf("neither manifest.json nor index.json found, not a docker save or OCI layout image")
+		}
+		descs, err := resolve(data, platform, func(digest string) ([]byte, error) {
+			name, err := blobPath(digest)
+			if err != nil {
+				return nil, err
+			}
+			return readBlob(store, name)
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, desc := range descs {
+			name, err := blobPath(desc.Digest)
+			if err != nil {
+				return nil, err
+			}
+			layers = append(layers, name)
+		}
+	}
+
+	img := Image{layers: make([]func() (io.ReadCloser, error), 0, len(layers))}
+	for _, name := range layers {
+		name := name
+		img.layers = append(img.layers, func() (io.ReadCloser, error) {
+			return store.Open(name)
+		})
+	}
+	return &img, nil
+}
+
+func readBlob(store blobStore, name string) ([]byte, error) {
+	rc, err := store.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// blobPath returns path of the blob in OCI image layout, e.g. blobs/sha256/abc... for sha256:abc...
+func blobPath(digest string) (string, error) {
+	parts := strings.SplitN(digest, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" || strings.ContainsAny(digest, "/\\.") {
+		return "", fmt.Errorf("invalid digest %q", digest)
+	}
+	return "blobs/" + parts[0] + "/" + parts[1], nil
+}
+
+// dirStore is an image saved in a directory
+type dirStore string
+
+func (d dirStore) Open(name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(string(d), filepath.FromSlash(name)))
+}
+
+func (d dirStore) Close() error {
+	return nil
+}
+
+// archive is a tar archive of an image; docker save stores the manifest after layers,
+// so offsets of all files are found first and files are read from the archive as needed
+type archive struct {
+	f       *os.File
+	entries map[string]archiveEntry
+}
+
+type archiveEntry struct {
+	offset, size int64
+	// link is the target of symbolic links
+	link string
+}
+
+func openArchive(name string) (*archive, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if f, err = decompressArchive(f); err != nil {
+		return nil, err
+	}
+
+	a := archive{f: f, entries: map[string]archiveEntry{}}
+	// tar reader seeks over contents of files, so the file offset is at the start of each entry's content
+	tr := tar.NewReader(f)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		name := cleanPath(hdr.Name)
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+			offset, err := f.Seek(0, io.SeekCurrent)
+			if err != nil {
+				f.Close()
+				return nil, err
+			}
+			a.entries[name] = archiveEntry{offset: offset, size: hdr.Size}
+		case tar.TypeSymlink:
+			a.entries[name] = archiveEntry{link: cleanPath(path.Join(path.Dir(name), hdr.Linkname))}
+		}
+	}
+	return &a, nil
+}
+
+// decompressArchive decompresses gzip compressed archive into a temporary file, so it can be read at random
+func decompressArchive(f *os.File) (*os.File, error) {
+	magic := make([]byte, 2)
+	if _, err := io.ReadFull(f, magic); err != nil || !bytes.Equal(magic, gzipMagic) {
+		_, err = f.Seek(0, io.SeekStart)
+		return f, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp("", "image-*.tar")
+	if err != nil {
+		return nil, err
+	}
+	// the file is removed right away, it stays available until it's closed
+	os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, gz); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("can't decompress archive: %v", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		tmp.Close()
+		return nil, err
+	}
+	return tmp, nil
+}
+
+func (a *archive) Open(name string) (io.ReadCloser, error) {
+	// follow symbolic links, docker save links legacy layer paths to blobs
+	for i := 0; i < 8; i++ {
+		e, ok := a.entries[name]
+		if !ok {
+			return nil, fmt.Errorf("%s not found in archive", name)
+		}
+		if e.link == "" {
+			return io.NopCloser(io.NewSectionReader(a.f, e.offset, e.size)), nil
+		}
+		name = e.link
+	}
+	return nil, fmt.Errorf("too many levels of symbolic links: %s", name)
+}
+
+func (a *archive) Close() error {
+	return a.f.Close()
+}
+
+// cleanPath returns the path relative to the root, e.g. var/lib/dpkg/status for ./var/lib/dpkg/status
+func cleanPath(name string) string {
+	return strings.TrimPrefix(path.Clean("/"+name), "/")
+}
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+)
+
+// decompress returns reader of the uncompressed layer, layers can be uncompressed or gzip compressed tar archives
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, _ := br.Peek(4)
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return gzip.NewReader(br)
+	case bytes.HasPrefix(magic, zstdMagic):
+		return nil, fmt.Errorf("zstd compressed layers aren't supported")
+	}
+	return br, nil
+}
+
+// files returns contents of the files in the image which are needed to find installed packages
+// layers are applied in order, files deleted by whiteouts of upper layers are removed
+func (img *Image) files() (map[string][]byte, error) {
+	files := map[string][]byte{}
+	for i, open := range img.layers {
+		if err := applyLayer(files, open); err != nil {
+			return nil, fmt.Errorf("can't read layer %d: %v", i+1, err)
+		}
+	}
+	return files, nil
+}
+
+// applyLayer applies changes of the layer to the files
+// https://github.com/opencontainers/image-spec/blob/main/layer.md#whiteouts
+func applyLayer(files map[string][]byte, open func() (io.ReadCloser, error)) error {
+	rc, err := open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	r, err := decompress(rc)
+	if err != nil {
+		return err
+	}
+
+	added := map[string][]byte{}
+	// deleted paths, with everything under them
+	var deleted []string
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name := cleanPath(hdr.Name)
+		dir, base := path.Split(name)
+		switch {
+		case base == ".wh..wh..opq":
+			// opaque directory, contents of lower layers are hidden
+			deleted = append(deleted, strings.TrimSuffix(dir, "/"))
+		case strings.HasPrefix(base, ".wh."):
+			deleted = append(deleted, dir+strings.TrimPrefix(base, ".wh."))
+		case !wanted(name):
+		case hdr.Typeflag == tar.TypeReg:
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return fmt.Errorf("can't read %s: %v", name, err)
+			}
+			added[name] = data
+		default:
+			// file replaced by a link or a directory
+			deleted = append(deleted, name)
+		}
+	}
+
+	for _, d := range deleted {
+		for name := range files {
+			if name == d || strings.HasPrefix(name, d+"/") {
+				delete(files, name)
+			}
+		}
+	}
+	for name, data := range added {
+		files[name] = data
+	}
+	return nil
+}
@@ -0,0 +1,251 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// testEntry is a file in a tar archive, files without content are directories
+type testEntry struct {
+	name string
+	data string
+	link string
+}
+
+func testTar(t *testing.T, entries ...testEntry) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0644, Size: int64(len(e.data)), Typeflag: tar.TypeReg}
+		switch {
+		case e.link != "":
+			hdr.Typeflag, hdr.Linkname, hdr.Size = tar.TypeSymlink, e.link, 0
+		case e.data == "":
+			hdr.Typeflag = tar.TypeDir
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func testGzip(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+const (
+	testOSRelease = `PRETTY_NAME="Debian GNU/Linux 12 (bookworm)"
+NAME="Debian GNU/Linux"
+VERSION_ID="12"
+VERSION="12 (bookworm)"
+VERSION_CODENAME=bookworm
+ID=debian
+`
+	testStatus1 = "Package: libssl3\nStatus: install ok installed\nArchitecture: amd64\nSource: openssl\nVersion: 3.0.9-1\n"
+	testStatus2 = testStatus1 + "\nPackage: bash\nStatus: install ok installed\nArchitecture: amd64\nVersion: 5.2.15-2+b2\n"
+)
+
+// testLayers are layers of a debian image:
+// os-release, dpkg status and a distroless status file in the base layer,
+// updated status in the second and removed status directory in the third
+func testLayers(t *testing.T) [][]byte {
+	return [][]byte{
+		testTar(t,
+			testEntry{name: "etc/"},
+			testEntry{name: "etc/os-release", link: "../usr/lib/os-release"},
+			testEntry{name: "usr/lib/os-release", data: testOSRelease},
+			testEntry{name: "var/lib/dpkg/status", data: testStatus1},
+			testEntry{name: "var/lib/dpkg/status.d/tzdata", data: "Package: tzdata\nVersion: 2024a-0+deb12u1\nArchitecture: all\n"},
+			testEntry{name: "var/lib/dpkg/status.d/tzdata.md5sums", data: "abc  usr/share/zoneinfo/UTC\n"},
+		),
+		testGzip(t, testTar(t,
+			testEntry{name: "./var/lib/dpkg/status", data: testStatus2},
+			testEntry{name: "./usr/bin/bash", data: "ELF"},
+		)),
+		testTar(t,
+			testEntry{name: "var/lib/dpkg/.wh.status.d", data: "-"},
+		),
+	}
+}
+
+var testImagePackages = []*Package{
+	{Type: "dpkg", Name: "libssl3", Source: "openssl", Version: "3.0.9-1", Arch: "amd64", Distro: "debian_bookworm"},
+	{Type: "dpkg", Name: "bash", Version: "5.2.15-2+b2", Arch: "amd64", Distro: "debian_bookworm"},
+}
+
+func digest(data []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(data))
+}
+
+func mustJSON(t *testing.T, v interface{}) []byte {
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// testOCILayout returns files of the image in OCI image layout, with an index of images for two platforms
+func testOCILayout(t *testing.T, layers [][]byte) []testEntry {
+	var files []testEntry
+	blob := func(data []byte) descriptor {
+		d := digest(data)
+		files = append(files, testEntry{name: "blobs/sha256/" + d[len("sha256:"):], data: string(data)})
+		return descriptor{Digest: d, Size: int64(len(data))}
+	}
+
+	var descs []descriptor
+	for _, layer := range layers {
+		descs = append(descs, blob(layer))
+	}
+	amd64 := blob(mustJSON(t, manifest{Layers: descs}))
+	amd64.Platform = &platform{OS: "linux", Architecture: "amd64"}
+	arm64 := blob(mustJSON(t, manifest{Layers: descs[:1]}))
+	arm64.Platform = &platform{OS: "linux", Architecture: "arm64", Variant: "v8"}
+	index := blob(mustJSON(t, manifest{Manifests: []descriptor{arm64, amd64}}))
+	files = append(files, testEntry{name: "index.json", data: string(mustJSON(t, manifest{Manifests: []descriptor{index}}))})
+	return files
+}
+
+// testDockerSave returns files of the image saved by docker save, the last layer is linked to a blob
+func testDockerSave(t *testing.T, layers [][]byte) []testEntry {
+	var files []testEntry
+	var names []string
+	for i, layer := range layers {
+		name := fmt.Sprintf("layer%d/layer.tar", i+1)
+		if i == len(layers)-1 {
+			files = append(files, testEntry{name: "blobs/sha256/abc", data: string(layer)})
+			files = append(files, testEntry{name: name, link: "../blobs/sha256/abc"})
+		} else {
+			files = append(files, testEntry{name: name, data: string(layer)})
+		}
+		names = append(names, name)
+	}
+	manifest := []map[string]interface{}{{"Config": "config.json", "RepoTags": []string{"test:latest"}, "Layers": names}}
+	return append(files, testEntry{name: "manifest.json", data: string(mustJSON(t, manifest))})
+}
+
+func TestOpen(t *testing.T) {
+	layers := testLayers(t)
+	dir := t.TempDir()
+	write := func(name string, data []byte) string {
+		p := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	for _, e := range testOCILayout(t, layers) {
+		write(filepath.Join("layout", e.name), []byte(e.data))
+	}
+
+	cases := []struct {
+		name     string
+		path     string
+		platform string
+		want     []*Package
+	}{
+		{"docker save", write("docker.tar", testTar(t, testDockerSave(t, layers)...)), DefaultPlatform, testImagePackages},
+		{"compressed", write("docker.tar.gz", testGzip(t, testTar(t, testDockerSave(t, layers)...))), DefaultPlatform, testImagePackages},
+		{"oci layout", write("oci.tar", testTar(t, testOCILayout(t, layers)...)), DefaultPlatform, testImagePackages},
+		{"oci layout directory", filepath.Join(dir, "layout"), DefaultPlatform, testImagePackages},
+		{
+			name:     "platform",
+			path:     filepath.Join(dir, "layout"),
+			platform: "linux/arm64",
+			want: []*Package{
+				{Type: "dpkg", Name: "libssl3", Source: "openssl", Version: "3.0.9-1", Arch: "amd64", Distro: "debian_bookworm"},
+				{Type: "dpkg", Name: "tzdata", Version: "2024a-0+deb12u1", Distro: "debian_bookworm"},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			img, err := Open(c.path, c.platform)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer img.Close()
+			pkgs, err := img.Packages()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(pkgs, c.want) {
+				t.Fatalf("have: %+v\nwant: %+v", pkgs, c.want)
+			}
+		})
+	}
+
+	t.Run("unknown platform", func(t *testing.T) {
+		if _, err := Open(filepath.Join(dir, "layout"), "linux/s390x"); err == nil {
+			t.Fatal("expected unknown platform to fail")
+		}
+	})
+	t.Run("not an image", func(t *testing.T) {
+		if _, err := Open(write("empty.tar", testTar(t, testEntry{name: "foo", data: "bar"})), DefaultPlatform); err == nil {
+			t.Fatal("expected archive without image to fail")
+		}
+	})
+}
+
+func TestOpaqueWhiteout(t *testing.T) {
+	files := map[string][]byte{
+		"lib/apk/db/installed": []byte("P:musl\nV:1.2.4-r2\n"),
+		"etc/os-release":       []byte(testOSRelease),
+	}
+	layer := testTar(t,
+		testEntry{name: "lib/apk/db/.wh..wh..opq", data: "-"},
+		testEntry{name: "etc/os-release", data: "ID=alpine\n"},
+	)
+	err := applyLayer(files, func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(layer)), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string][]byte{"etc/os-release": []byte("ID=alpine\n")}
+	if !reflect.DeepEqual(files, want) {
+		t.Fatalf("have: %q\nwant: %q", files, want)
+	}
+}
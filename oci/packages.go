@@ -0,0 +1,260 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/apk"
+	"github.com/facebookincubator/nvdtools/deb"
+	"github.com/facebookincubator/nvdtools/rpm"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// paths of package databases and os-release, relative to the root
+const (
+	apkInstalled = "lib/apk/db/installed"
+	dpkgStatus   = "var/lib/dpkg/status"
+	// distroless images have a status file per package
+	dpkgStatusDir = "var/lib/dpkg/status.d/"
+)
+
+// rpm database can be in any of these, depending on the version of rpm and the distribution
+var rpmDBs = []string{
+	"var/lib/rpm/rpmdb.sqlite",
+	"var/lib/rpm/Packages.db",
+	"var/lib/rpm/Packages",
+	"usr/lib/sysimage/rpm/rpmdb.sqlite",
+	"usr/lib/sysimage/rpm/Packages.db",
+	"usr/lib/sysimage/rpm/Packages",
+}
+
+var osReleases = []string{
+	"etc/os-release",
+	"usr/lib/os-release",
+}
+
+// wanted returns whether the file is needed to find installed packages
+func wanted(name string) bool {
+	if strings.HasPrefix(name, dpkgStatusDir) {
+		return !strings.HasSuffix(name, ".md5sums")
+	}
+	switch name {
+	case apkInstalled, dpkgStatus:
+		return true
+	}
+	for _, n := range rpmDBs {
+		if name == n {
+			return true
+		}
+	}
+	for _, n := range osReleases {
+		if name == n {
+			return true
+		}
+	}
+	return false
+}
+
+// Package is a package installed in an image
+type Package struct {
+	// Type is the package manager which installed the package: apk, dpkg or rpm
+	Type string
+	Name string
+	// Source is the name of the source package, if it's different from the name
+	Source string
+	// Version is the full version of apk and dpkg packages, and version without release of rpm packages
+	Version string
+	Release string
+	Arch    string
+	// Distro is the distribution the package was built for, as used in target_sw of distribution feeds,
+	// e.g. debian_bookworm, alpine_3.18 or rhel_9
+	Distro string
+}
+
+// Attributes returns CPE attributes of the package to match vulnerabilities against
+// distribution feeds are keyed by source packages, so the source package is the product if it's known
+func (p *Package) Attributes() (*wfn.Attributes, error) {
+	product := p.Source
+	if product == "" {
+		product = p.Name
+	}
+	targetHW := rpm.ArchToTargetHW(p.Arch)
+	if p.Type == "dpkg" {
+		targetHW = deb.ArchToTargetHW(p.Arch)
+	}
+
+	attrs := wfn.NewAttributesWithAny()
+	attrs.Part = "a"
+	for _, f := range []struct {
+		name  string
+		value string
+		addr  *string
+	}{
+		{"name", product, &attrs.Product},
+		{"version", p.Version, &attrs.Version},
+		{"release", p.Release, &attrs.Update},
+		{"distro", p.Distro, &attrs.TargetSW},
+		{"arch", targetHW, &attrs.TargetHW},
+	} {
+		var err error
+		if *f.addr, err = wfn.WFNize(f.value); err != nil {
+			return nil, fmt.Errorf("couldn't wfnize %s %q: %v", f.name, f.value, err)
+		}
+	}
+	return attrs, nil
+}
+
+// Packages returns the packages installed in the image by apk, dpkg and rpm
+func (img *Image) Packages() ([]*Package, error) {
+	files, err := img.files()
+	if err != nil {
+		return nil, err
+	}
+	return packages(files)
+}
+
+// packages returns the packages listed in package databases found in the files
+func packages(files map[string][]byte) ([]*Package, error) {
+	var osRelease map[string]string
+	for _, name := range osReleases {
+		if data, ok := files[name]; ok {
+			osRelease = parseOSRelease(data)
+			break
+		}
+	}
+	distro := osDistro(osRelease)
+
+	var pkgs []*Package
+	if data, ok := files[apkInstalled]; ok {
+		installed, err := apk.ParseInstalled(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("can't parse %s: %v", apkInstalled, err)
+		}
+		for _, p := range installed {
+			pkg := &Package{Type: "apk", Name: p.Name, Version: p.Version, Arch: p.Arch, Distro: distro}
+			if p.Origin != p.Name {
+				pkg.Source = p.Origin
+			}
+			pkgs = append(pkgs, pkg)
+		}
+	}
+
+	statuses := []string{dpkgStatus}
+	for name := range files {
+		if strings.HasPrefix(name, dpkgStatusDir) {
+			statuses = append(statuses, name)
+		}
+	}
+	sort.Strings(statuses[1:])
+	for _, name := range statuses {
+		data, ok := files[name]
+		if !ok {
+			continue
+		}
+		installed, err := deb.ParseStatus(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("can't parse %s: %v", name, err)
+		}
+		for _, p := range installed {
+			pkgs = append(pkgs, &Package{
+				Type:    "dpkg",
+				Name:    p.Name,
+				Source:  p.Source,
+				Version: p.Version.String(),
+				Arch:    p.Arch,
+				Distro:  distro,
+			})
+		}
+	}
+
+	for _, name := range rpmDBs {
+		data, ok := files[name]
+		if !ok {
+			continue
+		}
+		installed, err := rpm.ParseDB(data)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse %s: %v", name, err)
+		}
+		for _, p := range installed {
+			pkg := &Package{Type: "rpm", Name: p.Name, Version: p.Label.Version, Release: p.Label.Release, Arch: p.Arch}
+			// the dist tag says more than os-release, e.g. packages of centos are built for rhel
+			if dist := rpm.ParseDist(p.Label.Release); dist != nil {
+				pkg.Distro = dist.TargetSW()
+			}
+			pkgs = append(pkgs, pkg)
+		}
+		break
+	}
+
+	return pkgs, nil
+}
+
+// parseOSRelease parses os-release file: lines of shell variable assignments
+// https://www.freedesktop.org/software/systemd/man/os-release.html
+func parseOSRelease(data []byte) map[string]string {
+	vars := map[string]string{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		i := strings.IndexByte(line, '=')
+		if i < 0 {
+			continue
+		}
+		key, value := line[:i], line[i+1:]
+		if s, err := strconv.Unquote(value); err == nil {
+			value = s
+		} else {
+			value = strings.Trim(value, `"'`)
+		}
+		vars[key] = value
+	}
+	return vars
+}
+
+// osDistro returns the distribution from os-release as used in target_sw of distribution feeds:
+// release codename for debian and ubuntu, e.g. debian_bookworm, and branch for alpine, e.g. alpine_3.18
+func osDistro(osRelease map[string]string) string {
+	switch id := osRelease["ID"]; id {
+	case "debian", "ubuntu":
+		codename := osRelease["VERSION_CODENAME"]
+		if codename == "" {
+			// older releases only have it in the version, e.g. 9 (stretch) or 16.04.7 LTS (Xenial Xerus)
+			version := osRelease["VERSION"]
+			if i := strings.IndexByte(version, '('); i >= 0 {
+				if fields := strings.Fields(version[i+1:]); len(fields) != 0 {
+					codename = strings.TrimSuffix(fields[0], ")")
+				}
+			}
+		}
+		if codename != "" {
+			return id + "_" + strings.ToLower(codename)
+		}
+	case "alpine":
+		if v := strings.SplitN(osRelease["VERSION_ID"], ".", 3); len(v) >= 2 {
+			return id + "_" + v[0] + "." + v[1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestPackages(t *testing.T) {
+	rpmdb, err := os.ReadFile("../rpm/testdata/rpmdb.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("apk", func(t *testing.T) {
+		pkgs, err := packages(map[string][]byte{
+			"etc/os-release":       []byte("NAME=\"Alpine Linux\"\nID=alpine\nVERSION_ID=3.18.4\n"),
+			"lib/apk/db/installed": []byte("P:musl\nV:1.2.4-r2\nA:x86_64\no:musl\n\nP:libcrypto3\nV:3.1.4-r1\nA:x86_64\no:openssl\n"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := []*Package{
+			{Type: "apk", Name: "musl", Version: "1.2.4-r2", Arch: "x86_64", Distro: "alpine_3.18"},
+			{Type: "apk", Name: "libcrypto3", Source: "openssl", Version: "3.1.4-r1", Arch: "x86_64", Distro: "alpine_3.18"},
+		}
+		if !reflect.DeepEqual(pkgs, want) {
+			t.Fatalf("have: %+v\nwant: %+v", pkgs, want)
+		}
+	})
+
+	t.Run("rpm", func(t *testing.T) {
+		pkgs, err := packages(map[string][]byte{
+			"etc/os-release":           []byte("ID=\"rocky\"\nVERSION_ID=\"9.2\"\n"),
+			"var/lib/rpm/rpmdb.sqlite": rpmdb,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(pkgs) != 43 {
+			t.Fatalf("expecting 43 packages, got %d", len(pkgs))
+		}
+		want := &Package{Type: "rpm", Name: "openssl", Version: "3.0.7", Release: "24.el9", Arch: "x86_64", Distro: "rhel_9"}
+		if !reflect.DeepEqual(pkgs[1], want) {
+			t.Fatalf("have: %+v\nwant: %+v", pkgs[1], want)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := packages(map[string][]byte{"var/lib/rpm/Packages": []byte("garbage")}); err == nil {
+			t.Fatal("expected invalid database to fail")
+		}
+	})
+}
+
+func TestPackageAttributes(t *testing.T) {
+	cases := []struct {
+		pkg Package
+		cpe string
+	}{
+		{
+			Package{Type: "dpkg", Name: "libssl3", Source: "openssl", Version: "3.0.11-1~deb12u2", Arch: "amd64", Distro: "debian_bookworm"},
+			"cpe:/a::openssl:3.0.11-1%7edeb12u2::~~~debian_bookworm~x64~",
+		},
+		{
+			Package{Type: "apk", Name: "musl", Version: "1.2.4-r2", Arch: "x86_64", Distro: "alpine_3.18"},
+			"cpe:/a::musl:1.2.4-r2::~~~alpine_3.18~x64~",
+		},
+		{
+			Package{Type: "rpm", Name: "openssl", Version: "3.0.7", Release: "24.el9", Distro: "rhel_9"},
+			"cpe:/a::openssl:3.0.7:24.el9:~~~rhel_9~~",
+		},
+	}
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			attrs, err := c.pkg.Attributes()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cpe := attrs.BindToURI(); cpe != c.cpe {
+				t.Fatalf("have: %q\nwant: %q", cpe, c.cpe)
+			}
+		})
+	}
+}
+
+func TestOSDistro(t *testing.T) {
+	for osRelease, distro := range map[string]string{
+		"ID=debian\nVERSION_CODENAME=bookworm\n":              "debian_bookworm",
+		"ID=debian\nVERSION=\"9 (stretch)\"\n":                "debian_stretch",
+		"ID=ubuntu\nVERSION=\"16.04.7 LTS (Xenial Xerus)\"\n": "ubuntu_xenial",
+		"ID=ubuntu\nVERSION_CODENAME=jammy\n":                 "ubuntu_jammy",
+		"# comment\nID=alpine\nVERSION_ID=3.19.1\n":           "alpine_3.19",
+		"ID=alpine\nVERSION_ID=3\n":                           "",
+		"ID=\"centos\"\nVERSION_ID=\"7\"\n":                   "",
+		"":                                                    "",
+	} {
+		if have := osDistro(parseOSRelease([]byte(osRelease))); have != distro {
+			t.Errorf("%q: expected %q, got %q", osRelease, distro, have)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// manifestMediaTypes are accepted when fetching manifests
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// Registry pulls images from registries implementing the OCI distribution API
+// registries are accessed anonymously unless username is set
+type Registry struct {
+	Client   *http.Client
+	Username string
+	Password string
+	// PlainHTTP makes requests over http instead of https, e.g. for local registries
+	PlainHTTP bool
+
+	// bearer tokens by registry and repository
+	tokens map[string]string
+}
+
+// Pull opens the image from the registry, e.g. alpine:3.18, ghcr.io/org/image@sha256:...
+// platform selects the image from multi-platform images, e.g. linux/arm64
+// layers are downloaded when packages are read
+func (r *Registry) Pull(ctx context.Context, ref, platform string) (*Image, error) {
+	registry, repo, tag, err := ParseReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	scheme := "https"
+	if r.PlainHTTP {
+		scheme = "http"
+	}
+	base := fmt.Sprintf("%s://%s/v2/%s", scheme, registry, repo)
+
+	fetch := func(kind, ref string) (io.ReadCloser, error) {
+		return r.get(ctx, registry, repo, fmt.Sprintf("%s/%s/%s", base, kind, ref))
+	}
+	fetchManifest := func(ref string) ([]byte, error) {
+		body, err := fetch("manifests", ref)
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+
+	data, err := fetchManifest(tag)
+	if err != nil {
+		return nil, fmt.Errorf("can't fetch manifest of %s: %v", ref, err)
+	}
+	layers, err := resolve(data, platform, fetchManifest)
+	if err != nil {
+		return nil, err
+	}
+
+	img := Image{layers: make([]func() (io.ReadCloser, error), 0, len(layers))}
+	for _, layer := range layers {
+		digest := layer.Digest
+		img.layers = append(img.layers, func() (io.ReadCloser, error) {
+			return fetch("blobs", digest)
+		})
+	}
+	return &img, nil
+}
+
+// get returns the body of the response, authenticating if the registry requires it
+func (r *Registry) get(ctx context.Context, registry, repo, u string) (io.ReadCloser, error) {
+	key := registry + "/" + repo
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+		if token := r.tokens[key]; token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		} else if r.Username != "" {
+			req.SetBasicAuth(r.Username, r.Password)
+		}
+
+		resp, err := r.client().Do(req)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusOK {
+			return resp.Body, nil
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized || attempt > 0 {
+			return nil, fmt.Errorf("unexpected status %s from %s", resp.Status, u)
+		}
+
+		challenge := resp.Header.Get("WWW-Authenticate")
+		if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+			return nil, fmt.Errorf("unauthorized, unsupported authentication %q", challenge)
+		}
+		token, err := r.token(ctx, challenge)
+		if err != nil {
+			return nil, fmt.Errorf("can't get token: %v", err)
+		}
+		if r.tokens == nil {
+			r.tokens = map[string]string{}
+		}
+		r.tokens[key] = token
+	}
+}
+
+var challengeParamRE = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// token gets bearer token from the auth server in the challenge, e.g.
+// Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:library/alpine:pull"
+// https://distribution.github.io/distribution/spec/auth/token/
+func (r *Registry) token(ctx context.Context, challenge string) (string, error) {
+	params := map[string]string{}
+	for _, m := range challengeParamRE.FindAllStringSubmatch(challenge, -1) {
+		params[m[1]] = m[2]
+	}
+	if params["realm"] == "" {
+		return "", fmt.Errorf("no realm in challenge %q", challenge)
+	}
+	u, err := url.Parse(params["realm"])
+	if err != nil {
+		return "", fmt.Errorf("invalid realm: %v", err)
+	}
+	q := u.Query()
+	for _, p := range []string{"service", "scope"} {
+		if params[p] != "" {
+			q.Set(p, params[p])
+		}
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if r.Username != "" {
+		req.SetBasicAuth(r.Username, r.Password)
+	}
+	resp, err := r.client().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %s from %s", resp.Status, params["realm"])
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("can't decode token: %v", err)
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	if token.AccessToken != "" {
+		return token.AccessToken, nil
+	}
+	return "", fmt.Errorf("no token in response")
+}
+
+func (r *Registry) client() *http.Client {
+	if r.Client == nil {
+		return http.DefaultClient
+	}
+	return r.Client
+}
+
+// ParseReference splits image reference into registry, repository and tag or digest
+// e.g. alpine:3.18 is registry-1.docker.io, library/alpine and 3.18; tag defaults to latest
+func ParseReference(ref string) (registry, repo, tag string, err error) {
+	name := ref
+	if i := strings.IndexByte(name, '@'); i >= 0 {
+		name, tag = name[:i], name[i+1:]
+	}
+	if i := strings.LastIndexByte(name, ':'); i > strings.LastIndexByte(name, '/') {
+		if tag == "" {
+			tag = name[i+1:]
+		}
+		name = name[:i]
+	}
+	if tag == "" {
+		tag = "latest"
+	}
+
+	registry = "docker.io"
+	if i := strings.IndexByte(name, '/'); i >= 0 && (strings.ContainsAny(name[:i], ".:") || name[:i] == "localhost") {
+		registry, name = name[:i], name[i+1:]
+	}
+	if registry == "docker.io" {
+		registry = "registry-1.docker.io"
+		if !strings.Contains(name, "/") {
+			name = "library/" + name
+		}
+	}
+	if name == "" || name != strings.ToLower(name) || strings.ContainsAny(tag, "/ ") {
+		return "", "", "", fmt.Errorf("invalid image reference %q", ref)
+	}
+	return registry, name, tag, nil
+}
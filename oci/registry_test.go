@@ -0,0 +1,130 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oci
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseReference(t *testing.T) {
+	cases := []struct {
+		ref                 string
+		registry, repo, tag string
+		fail                bool
+	}{
+		{ref: "alpine", registry: "registry-1.docker.io", repo: "library/alpine", tag: "latest"},
+		{ref: "alpine:3.18", registry: "registry-1.docker.io", repo: "library/alpine", tag: "3.18"},
+		{ref: "docker.io/grafana/grafana:10.2.0", registry: "registry-1.docker.io", repo: "grafana/grafana", tag: "10.2.0"},
+		{ref: "ghcr.io/org/image@sha256:abc", registry: "ghcr.io", repo: "org/image", tag: "sha256:abc"},
+		{ref: "quay.io/org/image:1.0@sha256:abc", registry: "quay.io", repo: "org/image", tag: "sha256:abc"},
+		{ref: "localhost:5000/image", registry: "localhost:5000", repo: "image", tag: "latest"},
+		{ref: "localhost/image:1", registry: "localhost", repo: "image", tag: "1"},
+		{ref: "Alpine", fail: true},
+		{ref: "ghcr.io/", fail: true},
+	}
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			registry, repo, tag, err := ParseReference(c.ref)
+			if err != nil {
+				if !c.fail {
+					t.Fatalf("%q was expected to succeed, but failed: %v", c.ref, err)
+				}
+				return
+			}
+			if c.fail {
+				t.Fatalf("%q was expected to fail, but succeeded", c.ref)
+			}
+			if registry != c.registry || repo != c.repo || tag != c.tag {
+				t.Fatalf("%q: have %s %s %s, want %s %s %s", c.ref, registry, repo, tag, c.registry, c.repo, c.tag)
+			}
+		})
+	}
+}
+
+func TestRegistryPull(t *testing.T) {
+	// serve the OCI layout with token authentication
+	blobs := map[string]string{}
+	var index string
+	for _, e := range testOCILayout(t, testLayers(t)) {
+		if e.name == "index.json" {
+			index = e.data
+			continue
+		}
+		blobs["sha256:"+strings.TrimPrefix(e.name, "blobs/sha256/")] = e.data
+	}
+	var m manifest
+	if err := json.Unmarshal([]byte(index), &m); err != nil {
+		t.Fatal(err)
+	}
+	tag := m.Manifests[0].Digest
+
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if r.URL.Query().Get("scope") != "repository:org/image:pull" {
+				http.Error(w, "invalid scope", http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `{"token": "secret"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test",scope="repository:org/image:pull"`, srv.URL))
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		ref := r.URL.Path[strings.LastIndexByte(r.URL.Path, '/')+1:]
+		switch {
+		case r.URL.Path == "/v2/org/image/manifests/1.0":
+			ref = tag
+		case strings.HasPrefix(r.URL.Path, "/v2/org/image/manifests/"), strings.HasPrefix(r.URL.Path, "/v2/org/image/blobs/"):
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		data, ok := blobs[ref]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, data)
+	}))
+	defer srv.Close()
+
+	reg := Registry{Client: srv.Client()}
+	host := strings.TrimPrefix(srv.URL, "https://")
+	img, err := reg.Pull(context.Background(), host+"/org/image:1.0", DefaultPlatform)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgs, err := img.Packages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(pkgs, testImagePackages) {
+		t.Fatalf("have: %+v\nwant: %+v", pkgs, testImagePackages)
+	}
+
+	if _, err := reg.Pull(context.Background(), host+"/org/image:2.0", DefaultPlatform); err == nil {
+		t.Fatal("expected unknown tag to fail")
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// thrift compact protocol types
+const (
+	ctStop   = 0
+	ctI32    = 5
+	ctI64    = 6
+	ctBinary = 8
+	ctList   = 9
+	ctStruct = 12
+)
+
+// compactWriter encodes thrift structs with the compact protocol, which is used for parquet metadata
+type compactWriter struct {
+	buf    []byte
+	lastID []int16 // id of the last written field, per nested struct
+}
+
+func (w *compactWriter) varint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(b[:], v)
+	w.buf = append(w.buf, b[:n]...)
+}
+
+func zigzag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
+
+func (w *compactWriter) fieldHeader(id int16, typ byte) {
+	last := int16(0)
+	if n := len(w.lastID); n > 0 {
+		last = w.lastID[n-1]
+		w.lastID[n-1] = id
+	}
+	if delta := id - last; delta > 0 && delta <= 15 {
+		w.buf = append(w.buf, byte(delta)<<4|typ)
+		return
+	}
+	w.buf = append(w.buf, typ)
+	w.varint(zigzag(int64(id)))
+}
+
+// structBegin starts a struct, the top level one or a field value
+func (w *compactWriter) structBegin() {
+	w.lastID = append(w.lastID, 0)
+}
+
+func (w *compactWriter) structEnd() {
+	w.buf = append(w.buf, ctStop)
+	w.lastID = w.lastID[:len(w.lastID)-1]
+}
+
+func (w *compactWriter) fieldStruct(id int16) {
+	w.fieldHeader(id, ctStruct)
+	w.structBegin()
+}
+
+func (w *compactWriter) fieldI32(id int16, v int32) {
+	w.fieldHeader(id, ctI32)
+	w.varint(zigzag(int64(v)))
+}
+
+func (w *compactWriter) fieldI64(id int16, v int64) {
+	w.fieldHeader(id, ctI64)
+	w.varint(zigzag(v))
+}
+
+func (w *compactWriter) fieldString(id int16, v string) {
+	w.fieldHeader(id, ctBinary)
+	w.varint(uint64(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+// fieldList starts a list field of n elements of the given type; elements are written
+// with the value methods, or structBegin/structEnd for structs
+func (w *compactWriter) fieldList(id int16, typ byte, n int) {
+	w.fieldHeader(id, ctList)
+	if n < 15 {
+		w.buf = append(w.buf, byte(n)<<4|typ)
+		return
+	}
+	w.buf = append(w.buf, 0xf0|typ)
+	w.varint(uint64(n))
+}
+
+func (w *compactWriter) i32(v int32) {
+	w.varint(zigzag(int64(v)))
+}
+
+func (w *compactWriter) string(v string) {
+	w.varint(uint64(len(v)))
+	w.buf = append(w.buf, v...)
+}
+
+// plainEncoder encodes values with the parquet PLAIN encoding
+type plainEncoder struct {
+	buf   []byte
+	bits  byte // booleans are bit packed
+	nbits uint
+}
+
+func (e *plainEncoder) bool(v bool) {
+	if v {
+		e.bits |= 1 << e.nbits
+	}
+	e.nbits++
+	if e.nbits == 8 {
+		e.flushBits()
+	}
+}
+
+func (e *plainEncoder) flushBits() {
+	if e.nbits > 0 {
+		e.buf = append(e.buf, e.bits)
+		e.bits, e.nbits = 0, 0
+	}
+}
+
+func (e *plainEncoder) int64(v int64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], uint64(v))
+	e.buf = append(e.buf, b[:]...)
+}
+
+func (e *plainEncoder) double(v float64) {
+	e.int64(int64(math.Float64bits(v)))
+}
+
+func (e *plainEncoder) byteArray(v string) {
+	var b [4]byte
+	binary.LittleEndian.PutUint32(b[:], uint32(len(v)))
+	e.buf = append(e.buf, b[:]...)
+	e.buf = append(e.buf, v...)
+}
+
+// definitionLevels encodes levels of an optional column (0 -- null, 1 -- defined) with the RLE/bit-packed hybrid
+// encoding, using RLE runs only, prefixed by the length as data pages v1 require
+func definitionLevels(defined []bool) []byte {
+	var runs []byte
+	var b [binary.MaxVarintLen64]byte
+	for i := 0; i < len(defined); {
+		j := i
+		for j < len(defined) && defined[j] == defined[i] {
+			j++
+		}
+		n := binary.PutUvarint(b[:], uint64(j-i)<<1)
+		runs = append(runs, b[:n]...)
+		if defined[i] {
+			runs = append(runs, 1)
+		} else {
+			runs = append(runs, 0)
+		}
+		i = j
+	}
+	out := make([]byte, 4, 4+len(runs))
+	binary.LittleEndian.PutUint32(out, uint32(len(runs)))
+	return append(out, runs...)
+}
+
+// countingWriter counts the bytes written, to know the offsets of pages in the file
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
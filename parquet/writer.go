@@ -0,0 +1,364 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package parquet implements a minimal writer of Apache Parquet files with flat schemas,
+// enough to export feeds for data lakes (Spark, Trino, BigQuery, ...) without external dependencies.
+//
+// Each row group has a single PLAIN encoded data page (v1) per column, optionally gzip compressed;
+// dictionary encoding, statistics and nested columns aren't supported.
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Type is the type of a column
+type Type int
+
+// Supported column types
+const (
+	String Type = iota
+	Int64
+	Double
+	Boolean
+	// Timestamp is stored as INT64 milliseconds since the Unix epoch, in UTC
+	Timestamp
+)
+
+// parquet physical types
+const (
+	physicalBoolean   = 0
+	physicalInt64     = 2
+	physicalDouble    = 5
+	physicalByteArray = 6
+)
+
+// parquet converted types
+const (
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+)
+
+func (t Type) physical() int32 {
+	switch t {
+	case Int64, Timestamp:
+		return physicalInt64
+	case Double:
+		return physicalDouble
+	case Boolean:
+		return physicalBoolean
+	}
+	return physicalByteArray
+}
+
+// Column describes a column of the file
+type Column struct {
+	Name     string
+	Type     Type
+	Optional bool
+}
+
+// Codec is the compression codec of pages
+type Codec int
+
+// Supported codecs, the values are the ones used in parquet metadata
+const (
+	Uncompressed Codec = 0
+	Gzip         Codec = 2
+)
+
+// DefaultRowGroupSize is the default number of rows in a row group
+const DefaultRowGroupSize = 100000
+
+// Writer writes rows to a parquet file; Close must be called to write the footer
+type Writer struct {
+	// RowGroupSize is the number of rows buffered in memory before they're written as a row group
+	RowGroupSize int
+	// Codec compresses the pages
+	Codec Codec
+	// Metadata is written in the footer as key-value metadata
+	Metadata map[string]string
+
+	w         *countingWriter
+	columns   []Column
+	rows      [][]interface{}
+	numRows   int64
+	rowGroups []rowGroup
+	started   bool
+}
+
+type rowGroup struct {
+	chunks    []columnChunk
+	numRows   int64
+	totalSize int64
+}
+
+type columnChunk struct {
+	offset           int64
+	numValues        int64
+	uncompressedSize int64
+	compressedSize   int64
+}
+
+// NewWriter creates a new writer of a file with the given columns
+func NewWriter(w io.Writer, columns []Column) *Writer {
+	return &Writer{
+		RowGroupSize: DefaultRowGroupSize,
+		Codec:        Gzip,
+		w:            &countingWriter{w: w},
+		columns:      columns,
+	}
+}
+
+// Write adds a row, which must have a value for each column: string, int64, float64, bool or time.Time
+// depending on the type of the column, or nil for null values of optional columns
+func (pw *Writer) Write(row []interface{}) error {
+	if len(row) != len(pw.columns) {
+		return fmt.Errorf("parquet: row has %d values, expected %d", len(row), len(pw.columns))
+	}
+	for i, v := range row {
+		if err := pw.columns[i].check(v); err != nil {
+			return err
+		}
+	}
+	pw.rows = append(pw.rows, append([]interface{}(nil), row...))
+	if pw.RowGroupSize > 0 && len(pw.rows) >= pw.RowGroupSize {
+		return pw.flush()
+	}
+	return nil
+}
+
+func (c Column) check(v interface{}) error {
+	var ok bool
+	switch v.(type) {
+	case nil:
+		ok = c.Optional
+	case string:
+		ok = c.Type == String
+	case int64:
+		ok = c.Type == Int64
+	case float64:
+		ok = c.Type == Double
+	case bool:
+		ok = c.Type == Boolean
+	case time.Time:
+		ok = c.Type == Timestamp
+	}
+	if !ok {
+		return fmt.Errorf("parquet: bad value %#v for column %s", v, c.Name)
+	}
+	return nil
+}
+
+// flush writes the buffered rows as a row group
+func (pw *Writer) flush() error {
+	if len(pw.rows) == 0 {
+		return nil
+	}
+	if !pw.started {
+		if _, err := pw.w.Write([]byte("PAR1")); err != nil {
+			return err
+		}
+		pw.started = true
+	}
+	rg := rowGroup{numRows: int64(len(pw.rows))}
+	for i, c := range pw.columns {
+		chunk, err := pw.writeColumn(i, c)
+		if err != nil {
+			return err
+		}
+		rg.chunks = append(rg.chunks, chunk)
+		rg.totalSize += chunk.uncompressedSize
+	}
+	pw.rowGroups = append(pw.rowGroups, rg)
+	pw.numRows += rg.numRows
+	pw.rows = pw.rows[:0]
+	return nil
+}
+
+// writeColumn writes values of the buffered rows in the column as a single data page
+func (pw *Writer) writeColumn(i int, c Column) (columnChunk, error) {
+	var enc plainEncoder
+	defined := make([]bool, len(pw.rows))
+	for j, row := range pw.rows {
+		v := row[i]
+		if v == nil {
+			continue
+		}
+		defined[j] = true
+		switch c.Type {
+		case String:
+			enc.byteArray(v.(string))
+		case Int64:
+			enc.int64(v.(int64))
+		case Double:
+			enc.double(v.(float64))
+		case Boolean:
+			enc.bool(v.(bool))
+		case Timestamp:
+			enc.int64(v.(time.Time).UnixNano() / int64(time.Millisecond))
+		}
+	}
+	enc.flushBits()
+
+	var page []byte
+	if c.Optional {
+		page = definitionLevels(defined)
+	}
+	page = append(page, enc.buf...)
+	data := page
+	if pw.Codec == Gzip {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(page)
+		if err := zw.Close(); err != nil {
+			return columnChunk{}, err
+		}
+		data = buf.Bytes()
+	}
+
+	var hdr compactWriter
+	hdr.structBegin()
+	hdr.fieldI32(1, 0) // DATA_PAGE
+	hdr.fieldI32(2, int32(len(page)))
+	hdr.fieldI32(3, int32(len(data)))
+	hdr.fieldStruct(5)
+	hdr.fieldI32(1, int32(len(pw.rows)))
+	hdr.fieldI32(2, 0) // PLAIN
+	hdr.fieldI32(3, 3) // RLE
+	hdr.fieldI32(4, 3) // RLE
+	hdr.structEnd()
+	hdr.structEnd()
+
+	chunk := columnChunk{
+		offset:           pw.w.n,
+		numValues:        int64(len(pw.rows)),
+		uncompressedSize: int64(len(hdr.buf) + len(page)),
+		compressedSize:   int64(len(hdr.buf) + len(data)),
+	}
+	if _, err := pw.w.Write(hdr.buf); err != nil {
+		return chunk, err
+	}
+	if _, err := pw.w.Write(data); err != nil {
+		return chunk, err
+	}
+	return chunk, nil
+}
+
+// Close writes the remaining rows and the footer; it doesn't close the underlying writer
+func (pw *Writer) Close() error {
+	if err := pw.flush(); err != nil {
+		return err
+	}
+	if !pw.started {
+		// a file without rows still needs the magic number
+		if _, err := pw.w.Write([]byte("PAR1")); err != nil {
+			return err
+		}
+		pw.started = true
+	}
+	meta := pw.footer()
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(len(meta)))
+	for _, b := range [][]byte{meta, size[:], []byte("PAR1")} {
+		if _, err := pw.w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// footer returns the FileMetaData struct
+func (pw *Writer) footer() []byte {
+	var w compactWriter
+	w.structBegin()
+	w.fieldI32(1, 1) // version
+
+	w.fieldList(2, ctStruct, len(pw.columns)+1)
+	w.structBegin()
+	w.fieldString(4, "schema")
+	w.fieldI32(5, int32(len(pw.columns)))
+	w.structEnd()
+	for _, c := range pw.columns {
+		w.structBegin()
+		w.fieldI32(1, c.Type.physical())
+		if c.Optional {
+			w.fieldI32(3, 1) // OPTIONAL
+		} else {
+			w.fieldI32(3, 0) // REQUIRED
+		}
+		w.fieldString(4, c.Name)
+		switch c.Type {
+		case String:
+			w.fieldI32(6, convertedUTF8)
+		case Timestamp:
+			w.fieldI32(6, convertedTimestampMillis)
+		}
+		w.structEnd()
+	}
+
+	w.fieldI64(3, pw.numRows)
+
+	w.fieldList(4, ctStruct, len(pw.rowGroups))
+	for _, rg := range pw.rowGroups {
+		w.structBegin()
+		w.fieldList(1, ctStruct, len(rg.chunks))
+		for i, chunk := range rg.chunks {
+			c := pw.columns[i]
+			w.structBegin()
+			w.fieldI64(2, chunk.offset)
+			w.fieldStruct(3)
+			w.fieldI32(1, c.Type.physical())
+			w.fieldList(2, ctI32, 2)
+			w.i32(0) // PLAIN
+			w.i32(3) // RLE
+			w.fieldList(3, ctBinary, 1)
+			w.string(c.Name)
+			w.fieldI32(4, int32(pw.Codec))
+			w.fieldI64(5, chunk.numValues)
+			w.fieldI64(6, chunk.uncompressedSize)
+			w.fieldI64(7, chunk.compressedSize)
+			w.fieldI64(9, chunk.offset)
+			w.structEnd()
+			w.structEnd()
+		}
+		w.fieldI64(2, rg.totalSize)
+		w.fieldI64(3, rg.numRows)
+		w.structEnd()
+	}
+
+	if len(pw.Metadata) > 0 {
+		keys := make([]string, 0, len(pw.Metadata))
+		for k := range pw.Metadata {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		w.fieldList(5, ctStruct, len(keys))
+		for _, k := range keys {
+			w.structBegin()
+			w.fieldString(1, k)
+			w.fieldString(2, pw.Metadata[k])
+			w.structEnd()
+		}
+	}
+	w.fieldString(6, "nvdtools parquet writer")
+	w.structEnd()
+	return w.buf
+}
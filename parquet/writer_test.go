@@ -0,0 +1,270 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// compactReader decodes thrift compact structs into maps of field id to value, independently of the writer
+type compactReader struct {
+	b   []byte
+	pos int
+}
+
+func (r *compactReader) varint() uint64 {
+	v, n := binary.Uvarint(r.b[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *compactReader) zigzag() int64 {
+	v := r.varint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *compactReader) value(typ byte) interface{} {
+	switch typ {
+	case 1:
+		return true
+	case 2:
+		return false
+	case 5, 6:
+		return r.zigzag()
+	case 8:
+		n := int(r.varint())
+		s := string(r.b[r.pos : r.pos+n])
+		r.pos += n
+		return s
+	case 9:
+		hdr := r.b[r.pos]
+		r.pos++
+		n, elem := int(hdr>>4), hdr&0x0f
+		if n == 15 {
+			n = int(r.varint())
+		}
+		list := make([]interface{}, n)
+		for i := range list {
+			list[i] = r.value(elem)
+		}
+		return list
+	case 12:
+		return r.structure()
+	}
+	panic(fmt.Sprintf("unexpected type %d", typ))
+}
+
+func (r *compactReader) structure() map[int16]interface{} {
+	fields := make(map[int16]interface{})
+	var id int16
+	for {
+		hdr := r.b[r.pos]
+		r.pos++
+		if hdr == 0 {
+			return fields
+		}
+		if delta := int16(hdr >> 4); delta != 0 {
+			id += delta
+		} else {
+			id = int16(r.zigzag())
+		}
+		fields[id] = r.value(hdr & 0x0f)
+	}
+}
+
+// readColumn decodes values of a column chunk with a single data page
+func readColumn(t *testing.T, file []byte, chunk map[int16]interface{}, c Column) []interface{} {
+	meta := chunk[3].(map[int16]interface{})
+	r := compactReader{b: file, pos: int(meta[9].(int64))}
+	hdr := r.structure()
+	data := file[r.pos : r.pos+int(hdr[3].(int64))]
+	if meta[4].(int64) == int64(Gzip) {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if data, err = ioutil.ReadAll(zr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(data) != int(hdr[2].(int64)) {
+		t.Fatalf("uncompressed page size is %d, header says %d", len(data), hdr[2])
+	}
+	n := int(hdr[5].(map[int16]interface{})[1].(int64))
+
+	defined := make([]bool, n)
+	for i := range defined {
+		defined[i] = true
+	}
+	if c.Optional {
+		size := int(binary.LittleEndian.Uint32(data))
+		lr := compactReader{b: data[4 : 4+size]}
+		for i := 0; lr.pos < size; {
+			run := int(lr.varint() >> 1)
+			v := lr.b[lr.pos] == 1
+			lr.pos++
+			for j := 0; j < run; j++ {
+				defined[i] = v
+				i++
+			}
+		}
+		data = data[4+size:]
+	}
+
+	values := make([]interface{}, n)
+	var bit uint
+	for i := range values {
+		if !defined[i] {
+			continue
+		}
+		switch c.Type {
+		case String:
+			size := int(binary.LittleEndian.Uint32(data))
+			values[i] = string(data[4 : 4+size])
+			data = data[4+size:]
+		case Int64:
+			values[i] = int64(binary.LittleEndian.Uint64(data))
+			data = data[8:]
+		case Timestamp:
+			ms := int64(binary.LittleEndian.Uint64(data))
+			values[i] = time.Unix(0, ms*int64(time.Millisecond)).UTC()
+			data = data[8:]
+		case Double:
+			values[i] = math.Float64frombits(binary.LittleEndian.Uint64(data))
+			data = data[8:]
+		case Boolean:
+			values[i] = data[0]&(1<<bit) != 0
+			if bit++; bit == 8 {
+				data, bit = data[1:], 0
+			}
+		}
+	}
+	return values
+}
+
+func TestWriter(t *testing.T) {
+	columns := []Column{
+		{Name: "id", Type: String},
+		{Name: "score", Type: Double, Optional: true},
+		{Name: "count", Type: Int64},
+		{Name: "flag", Type: Boolean, Optional: true},
+		{Name: "published", Type: Timestamp, Optional: true},
+	}
+	ts := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	rows := [][]interface{}{
+		{"CVE-2020-0001", 9.8, int64(1), true, ts},
+		{"CVE-2020-0002", nil, int64(2), nil, nil},
+		{"CVE-2020-0003", 5.0, int64(3), false, ts.Add(time.Hour)},
+	}
+
+	for _, codec := range []Codec{Uncompressed, Gzip} {
+		t.Run(fmt.Sprintf("codec-%d", codec), func(t *testing.T) {
+			var buf bytes.Buffer
+			w := NewWriter(&buf, columns)
+			w.RowGroupSize = 2
+			w.Codec = codec
+			w.Metadata = map[string]string{"version": "1"}
+			for _, row := range rows {
+				if err := w.Write(row); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := w.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			file := buf.Bytes()
+			if string(file[:4]) != "PAR1" || string(file[len(file)-4:]) != "PAR1" {
+				t.Fatal("missing magic number")
+			}
+			size := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+			r := compactReader{b: file[len(file)-8-size : len(file)-8]}
+			meta := r.structure()
+			if r.pos != size {
+				t.Fatalf("footer has %d bytes, decoded %d", size, r.pos)
+			}
+			if meta[3].(int64) != 3 {
+				t.Fatalf("expected 3 rows, got %v", meta[3])
+			}
+			schema := meta[2].([]interface{})
+			if len(schema) != len(columns)+1 || schema[0].(map[int16]interface{})[5].(int64) != int64(len(columns)) {
+				t.Fatalf("bad schema: %v", schema)
+			}
+			for i, c := range columns {
+				if name := schema[i+1].(map[int16]interface{})[4]; name != c.Name {
+					t.Fatalf("expected column %s, got %v", c.Name, name)
+				}
+			}
+			kv := meta[5].([]interface{})[0].(map[int16]interface{})
+			if kv[1] != "version" || kv[2] != "1" {
+				t.Fatalf("bad key-value metadata: %v", kv)
+			}
+
+			groups := meta[4].([]interface{})
+			if len(groups) != 2 {
+				t.Fatalf("expected 2 row groups, got %d", len(groups))
+			}
+			var got [][]interface{}
+			for _, g := range groups {
+				g := g.(map[int16]interface{})
+				chunks := g[1].([]interface{})
+				n := int(g[3].(int64))
+				cols := make([][]interface{}, len(columns))
+				for i, c := range columns {
+					cols[i] = readColumn(t, file, chunks[i].(map[int16]interface{}), c)
+				}
+				for j := 0; j < n; j++ {
+					row := make([]interface{}, len(columns))
+					for i := range columns {
+						row[i] = cols[i][j]
+					}
+					got = append(got, row)
+				}
+			}
+			if !reflect.DeepEqual(got, rows) {
+				t.Fatalf("expected %v, got %v", rows, got)
+			}
+		})
+	}
+}
+
+func TestWriterErrors(t *testing.T) {
+	w := NewWriter(ioutil.Discard, []Column{{Name: "id", Type: String}})
+	if err := w.Write([]interface{}{"a", "b"}); err == nil {
+		t.Error("expected an error for too many values")
+	}
+	if err := w.Write([]interface{}{nil}); err == nil {
+		t.Error("expected an error for null in a required column")
+	}
+	if err := w.Write([]interface{}{1.0}); err == nil {
+		t.Error("expected an error for a value of wrong type")
+	}
+}
+
+func TestDefinitionLevels(t *testing.T) {
+	got := definitionLevels([]bool{true, true, false, true})
+	expected := []byte{6, 0, 0, 0, 2 << 1, 1, 1 << 1, 0, 1 << 1, 1}
+	if !bytes.Equal(got, expected) {
+		t.Fatalf("expected %v, got %v", expected, got)
+	}
+}
@@ -0,0 +1,196 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdtools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+
+	// providers which can be fetched and converted
+	_ "github.com/facebookincubator/nvdtools/providers/debian/api"
+	_ "github.com/facebookincubator/nvdtools/providers/fireeye/api"
+	_ "github.com/facebookincubator/nvdtools/providers/flexera/api"
+	_ "github.com/facebookincubator/nvdtools/providers/ghsa/api"
+	_ "github.com/facebookincubator/nvdtools/providers/gitlab/api"
+	_ "github.com/facebookincubator/nvdtools/providers/idefense/api"
+	_ "github.com/facebookincubator/nvdtools/providers/kev/api"
+	_ "github.com/facebookincubator/nvdtools/providers/msrc/api"
+	_ "github.com/facebookincubator/nvdtools/providers/oracle/api"
+	_ "github.com/facebookincubator/nvdtools/providers/ubuntu/api"
+)
+
+// Providers returns sorted names of the providers which can be fetched and converted
+func Providers() []string {
+	return runner.Registered()
+}
+
+// Fetcher fetches vulnerabilities of a provider and converts them to NVD format,
+// as {provider}2nvd -download -convert does
+type Fetcher struct {
+	// Provider is the name of the provider, one of Providers
+	Provider string
+	// BaseURL of the provider API, its default one is used if it's empty
+	BaseURL string
+	// Env has the environment variables the provider needs, e.g. credentials;
+	// those which aren't set in it are read from the environment of the process
+	Env map[string]string
+	// Client makes the requests, the default client is used if it's nil
+	Client client.Client
+}
+
+func (f *Fetcher) getenv(key string) string {
+	if value, ok := f.Env[key]; ok {
+		return value
+	}
+	return os.Getenv(key)
+}
+
+// Fetch fetches vulnerabilities changed since the given time, or all of them if it's zero,
+// and calls fn with each of them converted to a CVE item. Fetching stops at the first error,
+// including one returned by fn; vulnerabilities which can't be converted are skipped and reported
+// by the error returned once all others were fetched.
+func (f *Fetcher) Fetch(ctx context.Context, since time.Time, fn func(*schema.NVDCVEFeedJSON10DefCVEItem) error) error {
+	reg, err := lookup(f.Provider)
+	if err != nil {
+		return err
+	}
+	c := f.Client
+	if c == nil {
+		c = client.WithUserAgent(client.Default(), "nvdtools")
+	}
+	baseURL := f.BaseURL
+	if baseURL == "" {
+		baseURL = reg.BaseURL
+	}
+	if since.IsZero() {
+		since = time.Unix(0, 0)
+	}
+
+	p, err := reg.NewProvider(c, baseURL, f.getenv)
+	if err != nil {
+		return fmt.Errorf("can't create provider %s: %v", f.Provider, err)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	it, err := p.Fetch(ctx, since)
+	if err != nil {
+		return fmt.Errorf("can't fetch %s vulnerabilities: %v", f.Provider, err)
+	}
+	if ch, ok := it.(runner.ChanIterator); ok {
+		// providers sending records to a channel don't stop when fetching does
+		defer func() {
+			go func() {
+				for range ch {
+				}
+			}()
+		}()
+	}
+
+	conv := conversion{provider: f.Provider}
+	for {
+		record, err := it.Next(ctx)
+		if err == io.EOF {
+			return conv.err()
+		}
+		if err != nil {
+			return fmt.Errorf("can't fetch %s vulnerabilities: %v", f.Provider, err)
+		}
+		item, ok := conv.convert(record.ID(), func() (*schema.NVDCVEFeedJSON10DefCVEItem, error) {
+			return p.Convert(record)
+		})
+		if !ok {
+			continue
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+}
+
+// Convert reads vulnerabilities of the provider, as they were downloaded by {provider}2nvd -download
+// or from the provider directly if its command reads that as well, and calls fn with each of them converted
+// to a CVE item. Vulnerabilities which can't be converted are skipped and reported by the error returned
+// once all others were read.
+func Convert(provider string, r io.Reader, fn func(*schema.NVDCVEFeedJSON10DefCVEItem) error) error {
+	reg, err := lookup(provider)
+	if err != nil {
+		return err
+	}
+	vulns := make(chan runner.Convertible)
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(vulns)
+		readErr <- reg.Read(r, vulns)
+	}()
+
+	conv := conversion{provider: provider}
+	var fnErr error
+	for vuln := range vulns {
+		if fnErr != nil {
+			// the provider blocks until all vulnerabilities it read are received
+			continue
+		}
+		if item, ok := conv.convert(vuln.ID(), vuln.Convert); ok {
+			fnErr = fn(item)
+		}
+	}
+	if fnErr != nil {
+		return fnErr
+	}
+	if err := <-readErr; err != nil {
+		return fmt.Errorf("can't read %s vulnerabilities: %v", provider, err)
+	}
+	return conv.err()
+}
+
+func lookup(provider string) (runner.Registration, error) {
+	reg, ok := runner.Lookup(provider)
+	if !ok {
+		return reg, fmt.Errorf("unknown provider %q", provider)
+	}
+	return reg, nil
+}
+
+// conversion converts vulnerabilities of the provider, counting those which can't be converted
+type conversion struct {
+	provider string
+	failed   int
+	first    error
+}
+
+func (c *conversion) convert(id string, convert func() (*schema.NVDCVEFeedJSON10DefCVEItem, error)) (*schema.NVDCVEFeedJSON10DefCVEItem, bool) {
+	item, err := convert()
+	if err != nil {
+		if c.failed++; c.first == nil {
+			c.first = fmt.Errorf("%s: %v", id, err)
+		}
+		return nil, false
+	}
+	return item, true
+}
+
+func (c *conversion) err() error {
+	if c.failed == 0 {
+		return nil
+	}
+	return fmt.Errorf("can't convert %d %s vulnerabilities, e.g. %v", c.failed, c.provider, c.first)
+}
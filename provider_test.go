@@ -0,0 +1,122 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvdtools
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+)
+
+const testCatalog = `{
+  "title": "CISA Catalog of Known Exploited Vulnerabilities",
+  "vulnerabilities": [
+    {"cveID": "CVE-2021-44228", "vendorProject": "Apache", "product": "Log4j2", "dateAdded": "2021-12-10"},
+    {"cveID": "CVE-2016-0165", "vendorProject": "Microsoft", "product": "Win32k", "dateAdded": "2022-04-15"}
+  ]
+}`
+
+// collect returns fn collecting ids of the items, which fails after n items if n isn't 0
+func collect(ids *[]string, n int) func(*schema.NVDCVEFeedJSON10DefCVEItem) error {
+	return func(item *schema.NVDCVEFeedJSON10DefCVEItem) error {
+		if n != 0 && len(*ids) == n {
+			return errors.New("enough")
+		}
+		*ids = append(*ids, item.CVE.CVEDataMeta.ID)
+		return nil
+	}
+}
+
+func TestProviders(t *testing.T) {
+	providers := Providers()
+	if len(providers) == 0 || !contains(providers, "kev") {
+		t.Fatalf("kev isn't registered: %v", providers)
+	}
+}
+
+func contains(ss []string, s string) bool {
+	for _, x := range ss {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+func TestConvert(t *testing.T) {
+	var ids []string
+	if err := Convert("kev", strings.NewReader(testCatalog), collect(&ids, 0)); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []string{"CVE-2021-44228", "CVE-2016-0165"}) {
+		t.Fatalf("unexpected items %v", ids)
+	}
+
+	ids = nil
+	if err := Convert("kev", strings.NewReader(testCatalog), collect(&ids, 1)); err == nil || err.Error() != "enough" {
+		t.Fatalf("expected the error of fn, got %v", err)
+	}
+	if err := Convert("kev", strings.NewReader("{"), collect(&ids, 0)); err == nil {
+		t.Fatal("expected invalid catalog to fail")
+	}
+	if err := Convert("unknown", strings.NewReader(testCatalog), collect(&ids, 0)); err == nil {
+		t.Fatal("expected unknown provider to fail")
+	}
+}
+
+func TestFetcher(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/known_exploited_vulnerabilities.json" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, testCatalog)
+	}))
+	defer srv.Close()
+
+	f := Fetcher{Provider: "kev", BaseURL: srv.URL}
+	for _, tc := range []struct {
+		since time.Time
+		n     int
+		ids   []string
+		fail  bool
+	}{
+		{ids: []string{"CVE-2021-44228", "CVE-2016-0165"}},
+		{since: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), ids: []string{"CVE-2016-0165"}},
+		{n: 1, ids: []string{"CVE-2021-44228"}, fail: true},
+	} {
+		var ids []string
+		err := f.Fetch(context.Background(), tc.since, collect(&ids, tc.n))
+		if (err != nil) != tc.fail {
+			t.Fatalf("unexpected error %v", err)
+		}
+		if !reflect.DeepEqual(ids, tc.ids) {
+			t.Fatalf("expected items %v since %v, got %v", tc.ids, tc.since, ids)
+		}
+	}
+
+	f.BaseURL = srv.URL + "/missing"
+	if err := f.Fetch(context.Background(), time.Time{}, collect(new([]string), 0)); err == nil {
+		t.Fatal("expected fetching from a wrong url to fail")
+	}
+}
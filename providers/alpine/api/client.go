@@ -0,0 +1,89 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/alpine/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+)
+
+// Client downloads alpine secdb
+type Client struct {
+	client.Client
+	baseURL string
+}
+
+// NewClient creates an object which is used to download alpine secdb
+// base url should point to the secdb, e.g. https://secdb.alpinelinux.org
+func NewClient(c client.Client, baseURL string) *Client {
+	return &Client{
+		Client:  c,
+		baseURL: baseURL,
+	}
+}
+
+// FetchAllVulnerabilities downloads secdb for all given branches and repositories and returns all vulnerabilities
+// secdb doesn't say when the data was modified, so everything is returned every time
+func (c *Client) FetchAllVulnerabilities(ctx context.Context, branches, repos []string) (<-chan runner.Convertible, error) {
+	var dbs []*schema.SecDB
+	for _, branch := range branches {
+		for _, repo := range repos {
+			db, err := c.fetchSecDB(ctx, branch, repo)
+			if err != nil {
+				// not all repositories exist for all branches
+				logging.Errorf("can't fetch secdb for %s/%s: %v", branch, repo, err)
+				continue
+			}
+			dbs = append(dbs, db)
+		}
+	}
+	if len(dbs) == 0 {
+		return nil, fmt.Errorf("couldn't fetch any secdb")
+	}
+
+	output := make(chan runner.Convertible)
+	go func() {
+		defer close(output)
+		for _, vuln := range schema.Vulnerabilities(dbs...) {
+			output <- vuln
+		}
+	}()
+
+	return output, nil
+}
+
+func (c *Client) fetchSecDB(ctx context.Context, branch, repo string) (*schema.SecDB, error) {
+	resp, err := client.Get(ctx, c, fmt.Sprintf("%s/%s/%s.json", c.baseURL, branch, repo), http.Header{})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var db schema.SecDB
+	if err := json.NewDecoder(resp.Body).Decode(&db); err != nil {
+		return nil, fmt.Errorf("can't decode secdb: %v", err)
+	}
+	if db.DistroVersion == "" {
+		db.DistroVersion = branch
+	}
+	return &db, nil
+}
@@ -0,0 +1,77 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/alpine/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+)
+
+var (
+	// Branches is a comma separated list of alpine branches the registered provider downloads
+	Branches = "v3.16,v3.17,v3.18,v3.19,v3.20,edge"
+	// Repos is a comma separated list of alpine repositories the registered provider downloads
+	Repos = "main,community"
+)
+
+func init() {
+	runner.Register(runner.Registration{
+		Name:    "alpine",
+		BaseURL: "https://secdb.alpinelinux.org",
+		New: func(c client.Client, baseURL string, _ map[string]string) (runner.Provider, error) {
+			client := NewClient(c, baseURL)
+			// the whole secdb is downloaded, it doesn't support incremental downloads
+			return runner.ConvertibleProvider(func(ctx context.Context, _ time.Time) (<-chan runner.Convertible, error) {
+				return client.FetchAllVulnerabilities(ctx, strings.Split(Branches, ","), strings.Split(Repos, ","))
+			}), nil
+		},
+		Read: Read,
+	})
+}
+
+// Read reads vulnerabilities either from a file created by downloading, or directly from a secdb json or yaml file
+func Read(r io.Reader, c chan runner.Convertible) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("can't read input: %v", err)
+	}
+
+	if db, err := schema.ParseSecDB(data); err == nil && len(db.Packages) != 0 {
+		for _, vuln := range schema.Vulnerabilities(db) {
+			c <- vuln
+		}
+		return nil
+	}
+
+	var vulns map[string]*schema.Vulnerability
+	if err := json.Unmarshal(data, &vulns); err != nil {
+		return fmt.Errorf("can't decode into vulns: %v", err)
+	}
+
+	for _, vuln := range vulns {
+		c <- vuln
+	}
+
+	return nil
+}
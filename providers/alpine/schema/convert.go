@@ -0,0 +1,106 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const (
+	cveDataVersion     = "4.0"
+	notAffectedVersion = "0"
+)
+
+// ID is a part of the runner.Convertible interface
+func (vuln *Vulnerability) ID() string {
+	return vuln.CVEID
+}
+
+// Convert is a part of the runner.Convertible interface
+func (vuln *Vulnerability) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	configurations, err := vuln.newConfigurations()
+	if err != nil {
+		return nil, fmt.Errorf("can't create configurations: %v", err)
+	}
+
+	item := nvd.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &nvd.CVEJSON40{
+			CVEDataMeta: &nvd.CVEJSON40CVEDataMeta{
+				ID:       vuln.ID(),
+				ASSIGNER: "alpinelinux.org",
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: cveDataVersion,
+			Description: &nvd.CVEJSON40Description{},
+			References:  &nvd.CVEJSON40References{},
+		},
+		Configurations: configurations,
+	}
+
+	return &item, nil
+}
+
+func (vuln *Vulnerability) newConfigurations() (*nvd.NVDCVEFeedJSON10DefConfigurations, error) {
+	// sort fixes to get a stable output
+	fixes := make([]*Fix, len(vuln.Fixes))
+	copy(fixes, vuln.Fixes)
+	sort.Slice(fixes, func(i, j int) bool {
+		if fixes[i].Branch != fixes[j].Branch {
+			return fixes[i].Branch < fixes[j].Branch
+		}
+		return fixes[i].Package < fixes[j].Package
+	})
+
+	node := &nvd.NVDCVEFeedJSON10DefNode{Operator: "OR"}
+	for _, fix := range fixes {
+		if fix.Version == notAffectedVersion {
+			continue
+		}
+		attrs, err := PackageToCPE(fix.Branch, fix.Package)
+		if err != nil {
+			return nil, err
+		}
+		node.CPEMatch = append(node.CPEMatch, &nvd.NVDCVEFeedJSON10DefCPEMatch{
+			Cpe23Uri:            attrs.BindToFmtString(),
+			VersionEndExcluding: fix.Version,
+			Vulnerable:          true,
+		})
+	}
+
+	return &nvd.NVDCVEFeedJSON10DefConfigurations{
+		CVEDataVersion: cveDataVersion,
+		Nodes:          []*nvd.NVDCVEFeedJSON10DefNode{node},
+	}, nil
+}
+
+// PackageToCPE creates a CPE for the package on the given alpine branch
+// branch is stored as target software, e.g. alpine_3.18 or alpine_edge
+func PackageToCPE(branch, name string) (*wfn.Attributes, error) {
+	attrs := wfn.Attributes{Part: "a"}
+	var err error
+	if attrs.Product, err = wfn.WFNize(name); err != nil {
+		return nil, fmt.Errorf("can't wfnize package name %q: %v", name, err)
+	}
+	if attrs.TargetSW, err = wfn.WFNize("alpine_" + strings.TrimPrefix(branch, "v")); err != nil {
+		return nil, fmt.Errorf("can't wfnize branch %q: %v", branch, err)
+	}
+	return &attrs, nil
+}
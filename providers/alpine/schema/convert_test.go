@@ -0,0 +1,133 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const testSecDB = `{
+  "apkurl": "{{urlprefix}}/{{distroversion}}/{{reponame}}/{{arch}}/{{pkg.name}}-{{pkg.ver}}.apk",
+  "archs": ["x86_64", "aarch64"],
+  "reponame": "main",
+  "urlprefix": "https://dl-cdn.alpinelinux.org/alpine",
+  "distroversion": "v3.18",
+  "packages": [
+    {"pkg": {"name": "openssl", "secfixes": {
+      "3.1.0-r1": ["CVE-2023-0464"],
+      "3.1.0-r4": ["CVE-2023-1255 CVE-2023-2650"],
+      "0": ["CVE-2022-1343"]
+    }}},
+    {"pkg": {"name": "busybox", "secfixes": {"1.36.1-r1": ["CVE-2022-48174"]}}}
+  ]
+}`
+
+func TestVulnerabilities(t *testing.T) {
+	var db SecDB
+	if err := json.Unmarshal([]byte(testSecDB), &db); err != nil {
+		t.Fatal(err)
+	}
+
+	vulns := Vulnerabilities(&db)
+	if len(vulns) != 5 {
+		t.Fatalf("expecting 5 vulnerabilities, got %d", len(vulns))
+	}
+
+	// never affected
+	item, err := vulns["CVE-2022-1343"].Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(item.Configurations.Nodes[0].CPEMatch); n != 0 {
+		t.Fatalf("expecting no cpe matches, got %d", n)
+	}
+
+	item, err = vulns["CVE-2023-2650"].Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v := nvd.ToVuln(item)
+	for i, tc := range []struct {
+		cpe   string
+		match bool
+	}{
+		{"cpe:/a::openssl:3.1.0-r3::~~~alpine_3.18~~", true},
+		{"cpe:/a::openssl:3.1.0-r4::~~~alpine_3.18~~", false},
+		{"cpe:/a::openssl:3.1.0-r3::~~~alpine_3.17~~", false},
+		{"cpe:/a::busybox:1.36.0-r0::~~~alpine_3.18~~", false},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			attrs, err := wfn.Parse(tc.cpe)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if matched := len(v.Match([]*wfn.Attributes{attrs}, true)) != 0; matched != tc.match {
+				t.Fatalf("expecting match of %s to be %v, got %v", tc.cpe, tc.match, matched)
+			}
+		})
+	}
+}
+
+const testSecDBYAML = `---
+distroversion: v3.18
+reponame: main
+archs:
+  - x86_64
+  - aarch64
+urlprefix: https://dl-cdn.alpinelinux.org/alpine
+apkurl: "{{urlprefix}}/{{distroversion}}/{{reponame}}/{{arch}}/{{pkg.name}}-{{pkg.ver}}.apk"
+packages:
+  - pkg:
+      name: openssl
+      secfixes:
+        3.1.0-r1:
+          - CVE-2023-0464
+        3.1.0-r4:
+          - CVE-2023-1255 CVE-2023-2650
+        0:
+          - CVE-2022-1343
+  - pkg:
+      name: busybox
+      secfixes:
+        1.36.1-r1:
+          - CVE-2022-48174
+`
+
+func TestParseSecDB(t *testing.T) {
+	fromJSON, err := ParseSecDB([]byte(testSecDB))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromYAML, err := ParseSecDB([]byte(testSecDBYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(fromJSON, fromYAML) {
+		t.Fatalf("yaml secdb differs from json:\n%+v\n%+v", fromYAML, fromJSON)
+	}
+	if fix := Vulnerabilities(fromYAML)["CVE-2023-2650"].Fixes[0]; fix.Branch != "v3.18" || fix.Package != "openssl" || fix.Version != "3.1.0-r4" {
+		t.Fatalf("unexpected fix %+v", fix)
+	}
+
+	if _, err := ParseSecDB([]byte("packages:\n  - pkg: {name: openssl}\n")); err == nil {
+		t.Fatal("expecting an error for unsupported yaml")
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/yaml"
+)
+
+// based on the Alpine secdb, published per branch and repository
+// https://secdb.alpinelinux.org/v3.18/main.json
+
+// SecDB lists security fixes for all packages in a single repository of some branch
+type SecDB struct {
+	DistroVersion string   `json:"distroversion"`
+	RepoName      string   `json:"reponame"`
+	URLPrefix     string   `json:"urlprefix,omitempty"`
+	APKURL        string   `json:"apkurl,omitempty"`
+	Archs         []string `json:"archs,omitempty"`
+	Packages      []struct {
+		Pkg *Package `json:"pkg"`
+	} `json:"packages"`
+}
+
+// Package lists security fixes for a single package
+// secfixes maps the fixed version to the list of fixed vulnerabilities
+type Package struct {
+	Name     string              `json:"name"`
+	SecFixes map[string][]string `json:"secfixes"`
+}
+
+// Vulnerability is a single cve with all the packages it was fixed in
+type Vulnerability struct {
+	CVEID string `json:"id"`
+	Fixes []*Fix `json:"fixes"`
+}
+
+// Fix is the version in which a package was fixed on some branch
+// version "0" means that the package was never affected
+type Fix struct {
+	Branch  string `json:"branch"`
+	Repo    string `json:"repo"`
+	Package string `json:"package"`
+	Version string `json:"version"`
+}
+
+// ParseSecDB parses the secdb of a repository, which is published both in json and yaml
+// https://secdb.alpinelinux.org/v3.18/main.yaml
+func ParseSecDB(data []byte) (*SecDB, error) {
+	var db SecDB
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		if err := json.Unmarshal(data, &db); err != nil {
+			return nil, fmt.Errorf("can't decode json secdb: %v", err)
+		}
+		return &db, nil
+	}
+	if err := yaml.Unmarshal(data, &db); err != nil {
+		return nil, fmt.Errorf("can't decode yaml secdb: %v", err)
+	}
+	return &db, nil
+}
+
+// Vulnerabilities regroups fixes from given databases by cve id
+func Vulnerabilities(dbs ...*SecDB) map[string]*Vulnerability {
+	vulns := make(map[string]*Vulnerability)
+	for _, db := range dbs {
+		for _, p := range db.Packages {
+			if p.Pkg == nil {
+				continue
+			}
+			for version, ids := range p.Pkg.SecFixes {
+				for _, id := range ids {
+					// sometimes there are multiple ids or a comment on the same line
+					// e.g. "CVE-2021-3449 CVE-2021-3450"
+					for _, field := range strings.Fields(id) {
+						if !strings.Contains(field, "-") {
+							continue
+						}
+						vuln, ok := vulns[field]
+						if !ok {
+							vuln = &Vulnerability{CVEID: field}
+							vulns[field] = vuln
+						}
+						vuln.Fixes = append(vuln.Fixes, &Fix{
+							Branch:  db.DistroVersion,
+							Repo:    db.RepoName,
+							Package: p.Pkg.Name,
+							Version: version,
+						})
+					}
+				}
+			}
+		}
+	}
+	return vulns
+}
@@ -0,0 +1,244 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/amazon/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+)
+
+// Release knows where the data for an amazon linux release is published
+type Release struct {
+	// MirrorList lists repository mirrors, updateinfo.xml is taken from the first one
+	MirrorList string
+	// RSS is the path of the ALAS RSS feed, relative to the base url
+	RSS string
+}
+
+// Releases are all supported amazon linux releases
+var Releases = map[string]*Release{
+	"1": {
+		MirrorList: "http://repo.us-east-1.amazonaws.com/latest/updates/x86_64/mirror.list",
+		RSS:        "/alas.rss",
+	},
+	"2": {
+		MirrorList: "https://cdn.amazonlinux.com/2/core/latest/x86_64/mirror.list",
+		RSS:        "/AL2/alas.rss",
+	},
+	"2023": {
+		MirrorList: "https://cdn.amazonlinux.com/al2023/core/mirrors/latest/x86_64/mirror.list",
+		RSS:        "/AL2023/alas.rss",
+	},
+}
+
+// Client downloads amazon linux security advisories
+type Client struct {
+	client.Client
+	baseURL string
+}
+
+// NewClient creates an object which is used to download amazon linux security advisories
+// base url should point to ALAS, e.g. https://alas.aws.amazon.com
+func NewClient(c client.Client, baseURL string) *Client {
+	return &Client{
+		Client:  c,
+		baseURL: baseURL,
+	}
+}
+
+// FetchAllAdvisories fetches all security advisories for the given releases updated since the given time
+func (c *Client) FetchAllAdvisories(ctx context.Context, since int64, releases []string) (<-chan runner.Convertible, error) {
+	for _, release := range releases {
+		if _, ok := Releases[release]; !ok {
+			return nil, fmt.Errorf("unknown amazon linux release %q", release)
+		}
+	}
+
+	output := make(chan runner.Convertible)
+	go func() {
+		defer close(output)
+		for _, release := range releases {
+			logging.Infof("fetching amazon linux %s", release)
+			if err := c.fetchRelease(ctx, release, since, output); err != nil {
+				logging.Errorf("can't fetch amazon linux %s: %v", release, err)
+			}
+		}
+	}()
+	return output, nil
+}
+
+func (c *Client) fetchRelease(ctx context.Context, release string, since int64, output chan<- runner.Convertible) error {
+	r := Releases[release]
+
+	if since > 0 {
+		// updateinfo is big, check the rss feed first to see if there's anything new
+		latest, err := c.latestPublished(ctx, r.RSS)
+		if err != nil {
+			logging.Warningf("can't check rss feed, fetching updateinfo anyway: %v", err)
+		} else if latest.Unix() < since {
+			logging.Infof("no new advisories since %s", time.Unix(since, 0).UTC())
+			return nil
+		}
+	}
+
+	info, err := c.fetchUpdateInfo(ctx, r.MirrorList)
+	if err != nil {
+		return err
+	}
+
+	for _, u := range info.Updates {
+		if u.Type != "security" || !updatedSince(u, since) {
+			continue
+		}
+		u.Release = release
+		output <- u
+	}
+	return nil
+}
+
+// latestPublished returns the time when the latest advisory in rss feed was published
+func (c *Client) latestPublished(ctx context.Context, path string) (time.Time, error) {
+	var latest time.Time
+	resp, err := client.Get(ctx, c, c.baseURL+path, http.Header{})
+	if err != nil {
+		return latest, err
+	}
+	defer resp.Body.Close()
+
+	var rss schema.RSS
+	if err := xml.NewDecoder(resp.Body).Decode(&rss); err != nil {
+		return latest, fmt.Errorf("can't decode rss: %v", err)
+	}
+	for _, item := range rss.Items {
+		published, err := time.Parse(time.RFC1123, item.PubDate)
+		if err != nil {
+			if published, err = time.Parse(time.RFC1123Z, item.PubDate); err != nil {
+				return latest, fmt.Errorf("can't parse time of %q: %v", item.Title, err)
+			}
+		}
+		if published.After(latest) {
+			latest = published
+		}
+	}
+	return latest, nil
+}
+
+// fetchUpdateInfo finds updateinfo.xml through repomd.xml of the first mirror in the list
+func (c *Client) fetchUpdateInfo(ctx context.Context, mirrorList string) (*schema.UpdateInfo, error) {
+	mirror, err := c.firstMirror(ctx, mirrorList)
+	if err != nil {
+		return nil, fmt.Errorf("can't find mirror: %v", err)
+	}
+
+	href, err := c.updateInfoLocation(ctx, mirror)
+	if err != nil {
+		return nil, fmt.Errorf("can't find updateinfo location: %v", err)
+	}
+
+	resp, err := client.Get(ctx, c, mirror+"/"+href, http.Header{})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var r io.Reader = resp.Body
+	if strings.HasSuffix(href, ".gz") {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("can't decompress updateinfo: %v", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var info schema.UpdateInfo
+	if err := xml.NewDecoder(r).Decode(&info); err != nil {
+		return nil, fmt.Errorf("can't decode updateinfo: %v", err)
+	}
+	return &info, nil
+}
+
+func (c *Client) firstMirror(ctx context.Context, mirrorList string) (string, error) {
+	resp, err := client.Get(ctx, c, mirrorList, http.Header{})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			return strings.TrimSuffix(line, "/"), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("mirror list is empty")
+}
+
+func (c *Client) updateInfoLocation(ctx context.Context, mirror string) (string, error) {
+	resp, err := client.Get(ctx, c, mirror+"/repodata/repomd.xml", http.Header{})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var repomd struct {
+		Data []struct {
+			Type     string `xml:"type,attr"`
+			Location struct {
+				Href string `xml:"href,attr"`
+			} `xml:"location"`
+		} `xml:"data"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&repomd); err != nil {
+		return "", fmt.Errorf("can't decode repomd: %v", err)
+	}
+	for _, data := range repomd.Data {
+		if data.Type == "updateinfo" {
+			return data.Location.Href, nil
+		}
+	}
+	return "", fmt.Errorf("no updateinfo in repomd")
+}
+
+func updatedSince(u *schema.Update, since int64) bool {
+	if since <= 0 {
+		return true
+	}
+	date := u.Updated.Date
+	if date == "" {
+		date = u.Issued.Date
+	}
+	t, err := schema.ParseTime(date)
+	if err != nil {
+		// can't know, better to include it
+		return true
+	}
+	return t.Unix() >= since
+}
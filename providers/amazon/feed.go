@@ -0,0 +1,89 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package amazon
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/providers/amazon/schema"
+	"github.com/facebookincubator/nvdtools/rpm"
+)
+
+// Feed is a collection of advisories, keyed by the advisory id
+type Feed map[string]*schema.Update
+
+// LoadFeed loads a feed downloaded by amazon2nvd
+func LoadFeed(path string) (Feed, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open file %q: %v", path, err)
+	}
+	defer f.Close()
+
+	var feed Feed
+	if err := json.NewDecoder(f).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("can't decode feed: %v", err)
+	}
+	return feed, nil
+}
+
+// LoadUpdateInfo loads security advisories from updateinfo.xml of the given amazon linux release
+// gzipped files are supported as well
+func LoadUpdateInfo(release, path string) (Feed, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open file %q: %v", path, err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("can't decompress file %q: %v", path, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	return loadUpdateInfo(release, r)
+}
+
+func loadUpdateInfo(release string, r io.Reader) (Feed, error) {
+	var info schema.UpdateInfo
+	if err := xml.NewDecoder(r).Decode(&info); err != nil {
+		return nil, fmt.Errorf("can't decode updateinfo: %v", err)
+	}
+	feed := make(Feed, len(info.Updates))
+	for _, u := range info.Updates {
+		if u.Type != "security" {
+			continue
+		}
+		u.Release = release
+		feed[u.ID()] = u
+	}
+	return feed, nil
+}
+
+// Checker returns a checker which knows whether a package on some amazon linux release has been fixed
+// distro should be created with schema.DistroCPE, e.g. cpe:/o:amazon:amazon_linux:2
+func (feed Feed) Checker() (rpm.Checker, error) {
+	return NewPackageFeed(feed)
+}
@@ -0,0 +1,130 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package amazon
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/providers/amazon/schema"
+	"github.com/facebookincubator/nvdtools/rpm"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestPackageFeedListFixedCVEs(t *testing.T) {
+	feed, err := loadUpdateInfo("2", strings.NewReader(testUpdateInfo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feed) != 1 {
+		t.Fatalf("expecting only security updates, got %d", len(feed))
+	}
+	pf, err := NewPackageFeed(feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	al2, err := schema.DistroCPE("2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	al2023, err := schema.DistroCPE("2023")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tc := range []struct {
+		distro *wfn.Attributes
+		pkg    string
+		expect []string
+	}{
+		{al2, "openssl-1:1.0.2k-24.amzn2.0.6.x86_64", []string{"CVE-2023-0215", "CVE-2023-0286"}},
+		{al2, "openssl-libs-1:1.0.2k-24.amzn2.0.7.aarch64", []string{"CVE-2023-0215", "CVE-2023-0286"}},
+		{al2, "openssl-1:1.0.2k-24.amzn2.0.4.x86_64", []string{}},
+		{al2023, "openssl-1:1.0.2k-24.amzn2.0.6.x86_64", []string{}},
+		{nil, "openssl-1:1.0.2k-24.amzn2.0.6.x86_64", []string{"CVE-2023-0215", "CVE-2023-0286"}},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			pkg, err := rpm.Parse(tc.pkg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := pf.ListFixedCVEs(tc.distro, pkg); !reflect.DeepEqual(got, tc.expect) {
+				t.Fatalf("expecting %v, got %v", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	feed, err := loadUpdateInfo("2", strings.NewReader(testUpdateInfo))
+	if err != nil {
+		t.Fatal(err)
+	}
+	item, err := feed["ALAS2-2023-1934"].Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.PublishedDate != "2023-02-07T22:41Z" || item.LastModifiedDate != "2023-02-09T00:05Z" {
+		t.Fatalf("wrong dates %q, %q", item.PublishedDate, item.LastModifiedDate)
+	}
+	if url := item.CVE.References.ReferenceData[0].URL; url != "https://alas.aws.amazon.com/AL2/ALAS2-2023-1934.html" {
+		t.Fatalf("wrong url %q", url)
+	}
+	// packages for different archs are merged
+	if n := len(item.Configurations.Nodes); n != 2 {
+		t.Fatalf("expecting 2 nodes, got %d", n)
+	}
+	if cpe := item.Configurations.Nodes[0].CPEMatch[0].Cpe22Uri; cpe != "cpe:/o:amazon:amazon_linux:2" {
+		t.Fatalf("wrong distro cpe %q", cpe)
+	}
+}
+
+const testUpdateInfo = `<?xml version="1.0" ?>
+<updates>
+  <update author="linux-security@amazon.com" from="linux-security@amazon.com" status="final" type="security" version="1.4">
+    <id>ALAS2-2023-1934</id>
+    <title>Amazon Linux 2 - ALAS2-2023-1934: important priority package update for openssl</title>
+    <issued date="2023-02-07 22:41" />
+    <updated date="2023-02-09 00:05" />
+    <severity>important</severity>
+    <description>Package updates are available for Amazon Linux 2 that fix the following vulnerabilities</description>
+    <references>
+      <reference href="https://access.redhat.com/security/cve/CVE-2023-0215" id="CVE-2023-0215" title="" type="cve" />
+      <reference href="https://access.redhat.com/security/cve/CVE-2023-0286" id="CVE-2023-0286" title="" type="cve" />
+    </references>
+    <pkglist>
+      <collection short="amazon-linux-2">
+        <name>Amazon Linux 2</name>
+        <package arch="x86_64" epoch="1" name="openssl" release="24.amzn2.0.6" version="1.0.2k">
+          <filename>Packages/openssl-1.0.2k-24.amzn2.0.6.x86_64.rpm</filename>
+        </package>
+        <package arch="aarch64" epoch="1" name="openssl" release="24.amzn2.0.6" version="1.0.2k">
+          <filename>Packages/openssl-1.0.2k-24.amzn2.0.6.aarch64.rpm</filename>
+        </package>
+        <package arch="aarch64" epoch="1" name="openssl-libs" release="24.amzn2.0.6" version="1.0.2k">
+          <filename>Packages/openssl-libs-1.0.2k-24.amzn2.0.6.aarch64.rpm</filename>
+        </package>
+      </collection>
+    </pkglist>
+  </update>
+  <update author="linux-security@amazon.com" from="linux-security@amazon.com" status="final" type="bugfix" version="1.4">
+    <id>ALAS2-2023-1935</id>
+    <title>Amazon Linux 2 - ALAS2-2023-1935: bugfix</title>
+    <issued date="2023-02-08 00:00" />
+  </update>
+</updates>`
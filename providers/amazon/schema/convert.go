@@ -0,0 +1,230 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/rpm"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const (
+	cveDataVersion = "4.0"
+	alasURL        = "https://alas.aws.amazon.com/"
+)
+
+// updateinfo dates come in different formats
+var timeLayouts = []string{
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// ID is a part of the runner.Convertible interface
+func (u *Update) ID() string {
+	return u.UpdateID
+}
+
+// Convert is a part of the runner.Convertible interface
+func (u *Update) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	distro, err := DistroCPE(u.Release)
+	if err != nil {
+		return nil, err
+	}
+
+	item := nvd.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &nvd.CVEJSON40{
+			CVEDataMeta: &nvd.CVEJSON40CVEDataMeta{
+				ID:       u.ID(),
+				ASSIGNER: "amazon.com",
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: cveDataVersion,
+			Description: &nvd.CVEJSON40Description{
+				DescriptionData: []*nvd.CVEJSON40LangString{
+					{
+						Lang:  "en",
+						Value: u.Description,
+					},
+				},
+			},
+			References: u.newReferences(),
+		},
+		Configurations:   u.newConfigurations(distro),
+		LastModifiedDate: convertTime(u.Updated.Date),
+		PublishedDate:    convertTime(u.Issued.Date),
+	}
+
+	return &item, nil
+}
+
+// CVEs returns CVEs fixed by the update
+func (u *Update) CVEs() []string {
+	var cves []string
+	for _, ref := range u.References {
+		if ref.Type == "cve" {
+			cves = append(cves, ref.RefID)
+		}
+	}
+	return cves
+}
+
+// Fixed returns all packages fixed by the update, sorted by name, version and arch
+func (u *Update) Fixed() []*rpm.Package {
+	pkgs := make([]*rpm.Package, 0, len(u.Packages))
+	for _, p := range u.Packages {
+		pkg := rpm.Package{
+			Name: p.Name,
+			Label: rpm.Label{
+				Epoch:   p.Epoch,
+				Version: p.Version,
+				Release: p.Release,
+			},
+			Arch: p.Arch,
+		}
+		if pkg.Label.Epoch == "0" {
+			pkg.Label.Epoch = ""
+		}
+		if pkg.Arch == "src" || pkg.Arch == "noarch" {
+			pkg.Arch = ""
+		}
+		pkgs = append(pkgs, &pkg)
+	}
+	sort.Slice(pkgs, func(i, j int) bool {
+		return rpm.Compare(pkgs[i], pkgs[j]) < 0
+	})
+	return pkgs
+}
+
+func (u *Update) newReferences() *nvd.CVEJSON40References {
+	refs := &nvd.CVEJSON40References{
+		ReferenceData: []*nvd.CVEJSON40Reference{
+			{
+				Name: u.Title,
+				URL:  u.url(),
+			},
+		},
+	}
+	for _, ref := range u.References {
+		refs.ReferenceData = append(refs.ReferenceData, &nvd.CVEJSON40Reference{
+			Name: ref.RefID,
+			URL:  ref.Href,
+		})
+	}
+	return refs
+}
+
+// url returns the link to the advisory, e.g. https://alas.aws.amazon.com/AL2/ALAS-2023-1934.html
+func (u *Update) url() string {
+	switch u.Release {
+	case "2":
+		return fmt.Sprintf("%sAL2/%s.html", alasURL, u.ID())
+	case "2023":
+		return fmt.Sprintf("%sAL2023/%s.html", alasURL, u.ID())
+	default:
+		return fmt.Sprintf("%s%s.html", alasURL, u.ID())
+	}
+}
+
+// newConfigurations creates a node for each fixed package, the same way redhat does
+func (u *Update) newConfigurations(distro *wfn.Attributes) *nvd.NVDCVEFeedJSON10DefConfigurations {
+	var nodes []*nvd.NVDCVEFeedJSON10DefNode
+	seen := make(map[string]bool)
+	for _, pkg := range u.Fixed() {
+		pkgAttrs, err := package2wfn(pkg)
+		if err != nil {
+			logging.Recordf("%s: can't create wfn from package: %v", u.ID(), err)
+			continue
+		}
+		cpe := pkgAttrs.BindToURI()
+		if seen[cpe] {
+			continue
+		}
+		seen[cpe] = true
+
+		nodes = append(nodes, &nvd.NVDCVEFeedJSON10DefNode{
+			Operator: "AND",
+			CPEMatch: []*nvd.NVDCVEFeedJSON10DefCPEMatch{
+				{
+					Cpe22Uri:   distro.BindToURI(),
+					Vulnerable: false,
+				},
+				{
+					Cpe22Uri:   cpe,
+					Cpe23Uri:   pkgAttrs.BindToFmtString(),
+					Vulnerable: false,
+				},
+			},
+		})
+	}
+
+	return &nvd.NVDCVEFeedJSON10DefConfigurations{
+		CVEDataVersion: cveDataVersion,
+		Nodes:          nodes,
+	}
+}
+
+// DistroCPE returns the CPE of the amazon linux release, e.g. cpe:/o:amazon:amazon_linux:2
+func DistroCPE(release string) (*wfn.Attributes, error) {
+	switch release {
+	case "1", "2", "2023":
+		return wfn.Parse("cpe:/o:amazon:amazon_linux:" + release)
+	default:
+		return nil, fmt.Errorf("unknown amazon linux release %q", release)
+	}
+}
+
+func package2wfn(pkg *rpm.Package) (*wfn.Attributes, error) {
+	attrs := wfn.NewAttributesWithAny()
+	attrs.Part = "a"
+	var err error
+	if attrs.Product, err = wfn.WFNize(pkg.Name); err != nil {
+		return nil, fmt.Errorf("couldn't wfnize name %q: %v", pkg.Name, err)
+	}
+	if attrs.Version, err = wfn.WFNize(pkg.Label.Version); err != nil {
+		return nil, fmt.Errorf("couldn't wfnize version %q: %v", pkg.Label.Version, err)
+	}
+	if attrs.Update, err = wfn.WFNize(pkg.Label.Release); err != nil {
+		return nil, fmt.Errorf("couldn't wfnize release %q: %v", pkg.Label.Release, err)
+	}
+	return attrs, nil
+}
+
+func convertTime(amazonTime string) string {
+	t, err := ParseTime(amazonTime)
+	if err != nil {
+		if amazonTime != "" {
+			logging.Recordf("%v", err)
+		}
+		return amazonTime
+	}
+	return t.Format(nvd.TimeLayout)
+}
+
+// ParseTime parses the time of the update
+func ParseTime(amazonTime string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, amazonTime); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unable to parse time %q", amazonTime)
+}
@@ -0,0 +1,75 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import "encoding/xml"
+
+// based on the yum updateinfo.xml which is published in amazon linux repositories
+// and the ALAS RSS feeds https://alas.aws.amazon.com/
+
+// UpdateInfo is the list of all advisories in a repository
+type UpdateInfo struct {
+	XMLName xml.Name  `xml:"updates"`
+	Updates []*Update `xml:"update"`
+}
+
+// Update is a single ALAS advisory, e.g. ALAS2-2023-1934
+type Update struct {
+	// Release is the amazon linux release the update is for (1, 2 or 2023), it's not part of updateinfo.xml
+	Release     string `xml:"-" json:"release"`
+	Type        string `xml:"type,attr" json:"type"`
+	Status      string `xml:"status,attr" json:"status"`
+	UpdateID    string `xml:"id" json:"id"`
+	Title       string `xml:"title" json:"title"`
+	Severity    string `xml:"severity" json:"severity"`
+	Description string `xml:"description" json:"description"`
+	Issued      struct {
+		Date string `xml:"date,attr" json:"date"`
+	} `xml:"issued" json:"issued"`
+	Updated struct {
+		Date string `xml:"date,attr" json:"date"`
+	} `xml:"updated" json:"updated"`
+	References []*Reference `xml:"references>reference" json:"references"`
+	Packages   []*Package   `xml:"pkglist>collection>package" json:"packages"`
+}
+
+type Reference struct {
+	Href  string `xml:"href,attr" json:"href"`
+	RefID string `xml:"id,attr" json:"id"`
+	Title string `xml:"title,attr" json:"title,omitempty"`
+	Type  string `xml:"type,attr" json:"type"`
+}
+
+// Package is a package which fixes the advisory
+type Package struct {
+	Name     string `xml:"name,attr" json:"name"`
+	Epoch    string `xml:"epoch,attr" json:"epoch,omitempty"`
+	Version  string `xml:"version,attr" json:"version"`
+	Release  string `xml:"release,attr" json:"release"`
+	Arch     string `xml:"arch,attr" json:"arch"`
+	Filename string `xml:"filename" json:"filename,omitempty"`
+}
+
+// RSS is the ALAS RSS feed, it only lists the latest advisories without packages
+type RSS struct {
+	Items []*RSSItem `xml:"channel>item"`
+}
+
+type RSSItem struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	PubDate     string `xml:"pubDate"`
+	Description string `xml:"description"`
+}
@@ -0,0 +1,91 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package csaf provides a reader and a generic converter for CSAF 2.0 documents.
+// Vendor specific providers can use it directly, or customize the conversion of products into CPEs.
+package csaf
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/csaf/schema"
+)
+
+// IsDocument returns whether the given json object is a CSAF 2.0 document
+func IsDocument(data []byte) bool {
+	var doc struct {
+		Document *struct {
+			CSAFVersion string `json:"csaf_version"`
+		} `json:"document"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil || doc.Document == nil {
+		return false
+	}
+	return strings.HasPrefix(doc.Document.CSAFVersion, "2.")
+}
+
+// ReadDocument reads a CSAF 2.0 document from r
+func ReadDocument(r io.Reader) (*schema.Document, error) {
+	var doc schema.Document
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("can't decode csaf document: %v", err)
+	}
+	if !strings.HasPrefix(doc.Document.CSAFVersion, "2.") {
+		return nil, fmt.Errorf("unsupported csaf version %q", doc.Document.CSAFVersion)
+	}
+	if doc.Document.Tracking.ID == "" {
+		return nil, fmt.Errorf("csaf document doesn't have a tracking id")
+	}
+	return &doc, nil
+}
+
+// LoadDocument reads a CSAF 2.0 document from the file at path
+func LoadDocument(path string) (*schema.Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	doc, err := ReadDocument(f)
+	if err != nil {
+		return nil, fmt.Errorf("can't read %s: %v", path, err)
+	}
+	return doc, nil
+}
+
+// Convert converts all vulnerabilities of the given documents to NVD CVE JSON 1.0 format using toCPE,
+// or schema.ProductCPE if it's nil; vulnerabilities which can't be converted are logged and skipped
+func Convert(toCPE schema.ProductToCPE, docs ...*schema.Document) *nvd.NVDCVEFeedJSON10 {
+	if toCPE == nil {
+		toCPE = schema.ProductCPE
+	}
+	feed := &nvd.NVDCVEFeedJSON10{}
+	for _, doc := range docs {
+		for _, item := range doc.Items() {
+			cve, err := item.ConvertWith(toCPE)
+			if err != nil {
+				logging.Recordf("can't convert %s from %s: %v", item.ID(), doc.Document.Tracking.ID, err)
+				continue
+			}
+			feed.CVEItems = append(feed.CVEItems, cve)
+		}
+	}
+	return feed
+}
@@ -0,0 +1,150 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package csaf
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const testDocument = `{
+  "document": {
+    "category": "csaf_security_advisory",
+    "csaf_version": "2.0",
+    "title": "Example Server vulnerabilities",
+    "lang": "en",
+    "publisher": {"category": "vendor", "name": "Example Corp", "namespace": "https://example.com"},
+    "references": [{"category": "self", "summary": "Advisory", "url": "https://example.com/csaf/ex-2023-01.json"}],
+    "tracking": {
+      "id": "EX-2023-01",
+      "status": "final",
+      "version": "1",
+      "initial_release_date": "2023-05-02T10:00:00Z",
+      "current_release_date": "2023-05-09T10:00:00+02:00"
+    }
+  },
+  "product_tree": {
+    "branches": [
+      {"category": "vendor", "name": "Example Corp", "branches": [
+        {"category": "product_name", "name": "Example Server", "branches": [
+          {"category": "product_version", "name": "2.0", "product": {"name": "Example Server 2.0", "product_id": "ES-2.0"}},
+          {"category": "product_version_range", "name": "vers:generic/>=3.0|<3.2", "product": {"name": "Example Server 3.x", "product_id": "ES-3"}},
+          {"category": "product_version", "name": "1.0", "product": {"name": "Example Server 1.0", "product_id": "ES-1.0"}},
+          {"category": "product_version", "name": "1.5", "product": {"name": "Example Server 1.5", "product_id": "ES-1.5"}}
+        ]}
+      ]}
+    ],
+    "full_product_names": [
+      {"name": "Example Agent 4.1", "product_id": "EA-4.1", "product_identification_helper": {"cpe": "cpe:2.3:a:example:agent:4.1:*:*:*:*:*:*:*"}},
+      {"name": "Example OS 9", "product_id": "EOS-9", "product_identification_helper": {"cpe": "cpe:/o:example:os:9"}}
+    ],
+    "relationships": [
+      {"category": "installed_on", "product_reference": "EA-4.1", "relates_to_product_reference": "EOS-9",
+       "full_product_name": {"name": "Example Agent 4.1 on Example OS 9", "product_id": "EOS-9:EA-4.1"}}
+    ],
+    "product_groups": [{"group_id": "agents", "product_ids": ["EOS-9:EA-4.1"]}]
+  },
+  "vulnerabilities": [
+    {
+      "cve": "CVE-2023-1111",
+      "cwe": {"id": "CWE-787", "name": "Out-of-bounds Write"},
+      "notes": [{"category": "summary", "text": "Short summary."}, {"category": "description", "text": "Heap overflow in Example Server."}],
+      "product_status": {
+        "known_affected": ["ES-2.0", "ES-3", "EOS-9:EA-4.1"],
+        "first_affected": ["ES-1.0"],
+        "first_fixed": ["ES-1.5"],
+        "known_not_affected": ["EOS-9"]
+      },
+      "references": [{"summary": "CVE-2023-1111", "url": "https://www.cve.org/CVERecord?id=CVE-2023-1111"}],
+      "scores": [{"products": ["ES-2.0"], "cvss_v3": {"version": "3.1", "vectorString": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "baseScore": 9.8, "baseSeverity": "CRITICAL"}}],
+      "threats": [{"category": "impact", "details": "Critical", "group_ids": ["agents"]}]
+    },
+    {
+      "ids": [{"system_name": "Example Bug ID", "text": "EX-BUG-42"}],
+      "title": "Information disclosure",
+      "product_status": {"fixed": ["ES-2.0"]}
+    }
+  ]
+}`
+
+func TestConvert(t *testing.T) {
+	doc, err := ReadDocument(strings.NewReader(testDocument))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsDocument([]byte(testDocument)) {
+		t.Fatal("expecting a csaf document")
+	}
+
+	items := doc.Items()
+	if len(items) != 2 || items[1].ID() != "EX-BUG-42" {
+		t.Fatalf("unexpected items %v", items)
+	}
+	if ids := items[0].Tree().ProductIDs(nil, []string{"agents"}); len(ids) != 1 || ids[0] != "EOS-9:EA-4.1" {
+		t.Fatalf("wrong product ids of group: %v", ids)
+	}
+
+	feed := Convert(nil, doc)
+	if len(feed.CVEItems) != 2 {
+		t.Fatalf("expecting 2 items, got %d", len(feed.CVEItems))
+	}
+	item := feed.CVEItems[0]
+	if item.PublishedDate != "2023-05-02T10:00Z" || item.LastModifiedDate != "2023-05-09T08:00Z" {
+		t.Fatalf("wrong dates %q %q", item.PublishedDate, item.LastModifiedDate)
+	}
+	if desc := item.CVE.Description.DescriptionData[0].Value; desc != "Heap overflow in Example Server." {
+		t.Fatalf("wrong description %q", desc)
+	}
+	if item.Impact == nil || item.Impact.BaseMetricV3.CVSSV3.BaseScore != 9.8 {
+		t.Fatalf("wrong impact %+v", item.Impact)
+	}
+	if refs := item.CVE.References.ReferenceData; len(refs) != 2 {
+		t.Fatalf("expecting 2 references, got %d", len(refs))
+	}
+	if n := len(feed.CVEItems[1].Configurations.Nodes[0].CPEMatch); n != 0 {
+		t.Fatalf("fixed products shouldn't be matched, got %d cpe matches", n)
+	}
+
+	v := nvd.ToVuln(item)
+	for i, tc := range []struct {
+		cpe   string
+		match bool
+	}{
+		{"cpe:/a:example_corp:example_server:2.0", true},
+		{"cpe:/a:example_corp:example_server:2.1", false},
+		{"cpe:/a:example_corp:example_server:3.1.5", true},
+		{"cpe:/a:example_corp:example_server:3.2", false},
+		{"cpe:/a:example_corp:example_server:1.2", true},
+		{"cpe:/a:example_corp:example_server:1.5", false},
+		{"cpe:/a:example_corp:example_server:0.9", false},
+		{"cpe:/a:example:agent:4.1", true},
+		{"cpe:/a:example:agent:4.2", false},
+		{"cpe:/o:example:os:9", false},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			attrs, err := wfn.Parse(tc.cpe)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if matched := len(v.Match([]*wfn.Attributes{attrs}, true)) != 0; matched != tc.match {
+				t.Fatalf("expecting match of %s to be %v, got %v", tc.cpe, tc.match, matched)
+			}
+		})
+	}
+}
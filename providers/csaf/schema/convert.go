@@ -0,0 +1,420 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	nvdmatch "github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const (
+	cveDataVersion = "4.0"
+)
+
+// ProductToCPE creates a CPE for the product, nil is returned for products which can't be matched
+type ProductToCPE func(p *Product) (*wfn.Attributes, error)
+
+// Item is a single vulnerability of a CSAF document
+type Item struct {
+	Document      *Document
+	Vulnerability *Vulnerability
+	index         int
+	tree          *Tree
+}
+
+// Items returns all vulnerabilities of the document, so they can be converted one by one
+func (doc *Document) Items() []*Item {
+	tree := NewTree(doc.ProductTree)
+	items := make([]*Item, 0, len(doc.Vulnerabilities))
+	for i, v := range doc.Vulnerabilities {
+		items = append(items, &Item{
+			Document:      doc,
+			Vulnerability: v,
+			index:         i,
+			tree:          tree,
+		})
+	}
+	return items
+}
+
+// Tree returns the index of the document's product tree
+func (item *Item) Tree() *Tree {
+	return item.tree
+}
+
+// ID is a part of the runner.Convertible interface
+// vulnerabilities without a cve use the first of their ids, or document's tracking id if they don't have any
+func (item *Item) ID() string {
+	v := item.Vulnerability
+	switch {
+	case v.CVE != "":
+		return v.CVE
+	case len(v.IDs) != 0:
+		return v.IDs[0].Text
+	case len(item.Document.Vulnerabilities) > 1:
+		return fmt.Sprintf("%s#%d", item.Document.Document.Tracking.ID, item.index+1)
+	default:
+		return item.Document.Document.Tracking.ID
+	}
+}
+
+// Convert is a part of the runner.Convertible interface
+func (item *Item) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	return item.ConvertWith(ProductCPE)
+}
+
+// ConvertWith converts the vulnerability using the given function to create CPEs for affected products
+func (item *Item) ConvertWith(toCPE ProductToCPE) (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	doc, v := &item.Document.Document, item.Vulnerability
+
+	published := v.ReleaseDate
+	if published == "" {
+		published = doc.Tracking.InitialReleaseDate
+	}
+
+	nvdItem := nvd.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &nvd.CVEJSON40{
+			CVEDataMeta: &nvd.CVEJSON40CVEDataMeta{
+				ID:       item.ID(),
+				ASSIGNER: doc.Publisher.Name,
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: cveDataVersion,
+			Description: item.newDescription(),
+			Problemtype: item.newProblemType(),
+			References:  item.newReferences(),
+		},
+		Configurations:   item.newConfigurations(toCPE),
+		Impact:           item.newImpact(),
+		LastModifiedDate: csafTimeToNVD(doc.Tracking.CurrentReleaseDate),
+		PublishedDate:    csafTimeToNVD(published),
+	}
+
+	return &nvdItem, nil
+}
+
+func (item *Item) newDescription() *nvd.CVEJSON40Description {
+	lang := item.Document.Document.Lang
+	if lang == "" {
+		lang = "en"
+	}
+	v := item.Vulnerability
+	text := v.Title
+	for _, category := range []string{"description", "summary"} {
+		if note := findNote(v.Notes, category); note != nil {
+			text = note.Text
+			break
+		}
+	}
+	return &nvd.CVEJSON40Description{
+		DescriptionData: []*nvd.CVEJSON40LangString{
+			{
+				Lang:  lang,
+				Value: text,
+			},
+		},
+	}
+}
+
+func findNote(notes []*Note, category string) *Note {
+	for _, note := range notes {
+		if note.Category == category {
+			return note
+		}
+	}
+	return nil
+}
+
+func (item *Item) newProblemType() *nvd.CVEJSON40Problemtype {
+	cwe := item.Vulnerability.CWE
+	if cwe == nil || cwe.ID == "" {
+		return nil
+	}
+	return &nvd.CVEJSON40Problemtype{
+		ProblemtypeData: []*nvd.CVEJSON40ProblemtypeProblemtypeData{
+			{
+				Description: []*nvd.CVEJSON40LangString{
+					{
+						Lang:  "en",
+						Value: cwe.ID,
+					},
+				},
+			},
+		},
+	}
+}
+
+// newReferences returns the references of the vulnerability followed by the document's self reference
+func (item *Item) newReferences() *nvd.CVEJSON40References {
+	refs := &nvd.CVEJSON40References{}
+	seen := make(map[string]bool)
+	addRef := func(ref *Reference) {
+		if ref.URL == "" || seen[ref.URL] {
+			return
+		}
+		seen[ref.URL] = true
+		refs.ReferenceData = append(refs.ReferenceData, &nvd.CVEJSON40Reference{
+			Name: ref.Summary,
+			URL:  ref.URL,
+		})
+	}
+	for _, ref := range item.Vulnerability.References {
+		addRef(ref)
+	}
+	for _, ref := range item.Document.Document.References {
+		if ref.Category == "self" {
+			addRef(ref)
+		}
+	}
+	if len(refs.ReferenceData) == 0 {
+		return nil
+	}
+	return refs
+}
+
+// newImpact uses the first cvss v3 and v2 scores, regardless of the products they apply to
+func (item *Item) newImpact() *nvd.NVDCVEFeedJSON10DefImpact {
+	var impact nvd.NVDCVEFeedJSON10DefImpact
+	for _, score := range item.Vulnerability.Scores {
+		if score.CVSSV3 != nil && impact.BaseMetricV3 == nil {
+			impact.BaseMetricV3 = &nvd.NVDCVEFeedJSON10DefImpactBaseMetricV3{CVSSV3: score.CVSSV3}
+		}
+		if score.CVSSV2 != nil && impact.BaseMetricV2 == nil {
+			impact.BaseMetricV2 = &nvd.NVDCVEFeedJSON10DefImpactBaseMetricV2{CVSSV2: score.CVSSV2}
+		}
+	}
+	if impact.BaseMetricV2 == nil && impact.BaseMetricV3 == nil {
+		return nil
+	}
+	return &impact
+}
+
+// newConfigurations creates a cpe match for every affected product
+// products which are known to be affected match either their exact version or their version range,
+// first affected, last affected and first fixed products become the start or the end of a range;
+// when a product has just one start and one end they are merged into a single range
+func (item *Item) newConfigurations(toCPE ProductToCPE) *nvd.NVDCVEFeedJSON10DefConfigurations {
+	node := &nvd.NVDCVEFeedJSON10DefNode{Operator: "OR"}
+	status := item.Vulnerability.ProductStatus
+	if status == nil {
+		status = &ProductStatus{}
+	}
+
+	type bounds struct {
+		cpe    *wfn.Attributes
+		starts []wfn.VersionRange
+		ends   []wfn.VersionRange
+	}
+	var order []string
+	byProduct := make(map[string]*bounds)
+
+	resolve := func(id string) *wfn.Attributes {
+		p := item.tree.lookup(id)
+		cpe, err := toCPE(p)
+		if err != nil {
+			logging.Recordf("can't create cpe for %s, product %q: %v", item.ID(), id, err)
+			return nil
+		}
+		return cpe
+	}
+
+	for _, id := range status.KnownAffected {
+		cpe := resolve(id)
+		if cpe == nil {
+			continue
+		}
+		for _, r := range productRanges(item.tree.lookup(id)) {
+			node.CPEMatch = append(node.CPEMatch, nvdmatch.NewCPEMatch(cpe, r, true))
+		}
+	}
+
+	addBound := func(ids []string, bound func(version string) wfn.VersionRange, start bool) {
+		for _, id := range ids {
+			cpe := resolve(id)
+			if cpe == nil || !hasValue(cpe.Version) {
+				// a bound without a version doesn't mean anything
+				continue
+			}
+			version := wfn.StripSlashes(cpe.Version)
+			base := *cpe
+			base.Version = wfn.Any
+			key := base.BindToFmtString()
+			b, ok := byProduct[key]
+			if !ok {
+				b = &bounds{cpe: &base}
+				byProduct[key] = b
+				order = append(order, key)
+			}
+			if start {
+				b.starts = append(b.starts, bound(version))
+			} else {
+				b.ends = append(b.ends, bound(version))
+			}
+		}
+	}
+	addBound(status.FirstAffected, func(v string) wfn.VersionRange { return wfn.VersionRange{StartIncluding: v} }, true)
+	addBound(status.LastAffected, func(v string) wfn.VersionRange { return wfn.VersionRange{EndIncluding: v} }, false)
+	addBound(status.FirstFixed, func(v string) wfn.VersionRange { return wfn.VersionRange{EndExcluding: v} }, false)
+
+	for _, key := range order {
+		b := byProduct[key]
+		if len(b.starts) == 1 && len(b.ends) == 1 {
+			r := b.starts[0]
+			r.EndIncluding, r.EndExcluding = b.ends[0].EndIncluding, b.ends[0].EndExcluding
+			node.CPEMatch = append(node.CPEMatch, nvdmatch.NewCPEMatch(b.cpe, r, true))
+			continue
+		}
+		for _, r := range append(b.starts, b.ends...) {
+			node.CPEMatch = append(node.CPEMatch, nvdmatch.NewCPEMatch(b.cpe, r, true))
+		}
+	}
+
+	return &nvd.NVDCVEFeedJSON10DefConfigurations{
+		CVEDataVersion: cveDataVersion,
+		Nodes:          []*nvd.NVDCVEFeedJSON10DefNode{node},
+	}
+}
+
+// ProductCPE is the default ProductToCPE function
+// it uses the cpe from the product identification helper if there is one, and the vendor, product name and
+// product version branches otherwise; products defined by relationships use the cpe of their component
+func ProductCPE(p *Product) (*wfn.Attributes, error) {
+	if p.Component != nil {
+		return ProductCPE(p.Component)
+	}
+
+	version := p.Branches[BranchProductVersion]
+
+	if p.CPE != "" {
+		attrs, err := wfn.Parse(p.CPE)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse cpe %q: %v", p.CPE, err)
+		}
+		if !hasValue(attrs.Version) && version != "" {
+			if attrs.Version, err = wfn.WFNize(version); err != nil {
+				return nil, fmt.Errorf("can't wfnize version %q: %v", version, err)
+			}
+		}
+		return attrs, nil
+	}
+
+	name := p.Branches[BranchProductName]
+	if name == "" {
+		return nil, nil
+	}
+	attrs := wfn.Attributes{Part: "a"}
+	var err error
+	if attrs.Vendor, err = wfn.WFNize(strings.ToLower(p.Branches[BranchVendor])); err != nil {
+		return nil, fmt.Errorf("can't wfnize vendor %q: %v", p.Branches[BranchVendor], err)
+	}
+	if attrs.Product, err = wfn.WFNize(strings.ToLower(name)); err != nil {
+		return nil, fmt.Errorf("can't wfnize product name %q: %v", name, err)
+	}
+	if attrs.Version, err = wfn.WFNize(version); err != nil {
+		return nil, fmt.Errorf("can't wfnize version %q: %v", version, err)
+	}
+	return &attrs, nil
+}
+
+func hasValue(s string) bool {
+	return s != "" && s != wfn.Any && s != wfn.NA
+}
+
+// productRanges returns the version ranges of products under a product version range branch
+// and a single range without bounds, meaning the version of the product's cpe, for all other products
+func productRanges(p *Product) []wfn.VersionRange {
+	for p.Component != nil {
+		p = p.Component
+	}
+	vers, ok := p.Branches[BranchProductVersionRange]
+	if !ok {
+		return []wfn.VersionRange{{}}
+	}
+	ranges, err := parseVers(vers)
+	if err != nil {
+		logging.Recordf("can't parse version range of product %q: %v", p.ID, err)
+		return nil
+	}
+	return ranges
+}
+
+// parseVers parses the version range specifier, e.g. vers:generic/>=1.0|<1.4.2|>=2.0|<2.1.1
+// constraints are sorted by version, so every lower bound starts a new range and an upper bound ends it
+// https://github.com/package-url/purl-spec/blob/master/VERSION-RANGE-SPEC.rst
+func parseVers(s string) ([]wfn.VersionRange, error) {
+	if !strings.HasPrefix(s, "vers:") {
+		return nil, fmt.Errorf("unsupported version range %q, only vers is supported", s)
+	}
+	i := strings.IndexByte(s, '/')
+	if i < 0 {
+		return nil, fmt.Errorf("invalid version range %q", s)
+	}
+
+	var ranges []wfn.VersionRange
+	var cur *wfn.VersionRange
+	for _, c := range strings.Split(s[i+1:], "|") {
+		c = strings.TrimSpace(c)
+		switch {
+		case c == "*":
+			return []wfn.VersionRange{{StartIncluding: "0"}}, nil
+		case strings.HasPrefix(c, "!="):
+			// excluded versions can't be expressed with cpe matches
+		case strings.HasPrefix(c, ">="):
+			cur = &wfn.VersionRange{StartIncluding: c[2:]}
+		case strings.HasPrefix(c, ">"):
+			cur = &wfn.VersionRange{StartExcluding: c[1:]}
+		case strings.HasPrefix(c, "<=") || strings.HasPrefix(c, "<"):
+			if cur == nil {
+				cur = &wfn.VersionRange{}
+			}
+			if strings.HasPrefix(c, "<=") {
+				cur.EndIncluding = c[2:]
+			} else {
+				cur.EndExcluding = c[1:]
+			}
+			ranges, cur = append(ranges, *cur), nil
+		default:
+			version := strings.TrimPrefix(c, "=")
+			ranges = append(ranges, wfn.VersionRange{StartIncluding: version, EndIncluding: version})
+		}
+	}
+	if cur != nil {
+		// lower bound without the upper one
+		ranges = append(ranges, *cur)
+	}
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("version range %q doesn't contain any versions", s)
+	}
+	return ranges, nil
+}
+
+func csafTimeToNVD(s string) string {
+	if s == "" {
+		return ""
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		logging.Recordf("cannot parse csaf time: %v", err)
+		return s
+	}
+	return t.UTC().Format(nvd.TimeLayout)
+}
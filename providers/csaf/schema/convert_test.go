@@ -0,0 +1,45 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseVers(t *testing.T) {
+	for i, tc := range []struct {
+		vers   string
+		ranges int
+		fail   bool
+	}{
+		{"vers:generic/>=1.0|<1.4.2|>=2.0|<2.1.1", 2, false},
+		{"vers:semver/<1.2.3", 1, false},
+		{"vers:generic/1.0|1.1|>=2.0", 3, false},
+		{"vers:generic/*", 1, false},
+		{"vers:generic/!=1.0", 0, true},
+		{"< 1.2", 0, true},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			ranges, err := parseVers(tc.vers)
+			if (err != nil) != tc.fail {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(ranges) != tc.ranges {
+				t.Fatalf("expecting %d ranges, got %d", tc.ranges, len(ranges))
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+)
+
+// based on the CSAF 2.0 specification
+// https://docs.oasis-open.org/csaf/csaf/v2.0/csaf-v2.0.html
+// vendor specific fields, as well as the parts which don't matter for vulnerability matching, are left out
+
+// document categories
+const (
+	CategoryBase             = "csaf_base"
+	CategorySecurityAdvisory = "csaf_security_advisory"
+	CategoryVEX              = "csaf_vex"
+)
+
+// branch categories
+const (
+	BranchArchitecture        = "architecture"
+	BranchHostName            = "host_name"
+	BranchLanguage            = "language"
+	BranchLegacy              = "legacy"
+	BranchPatchLevel          = "patch_level"
+	BranchProductFamily       = "product_family"
+	BranchProductName         = "product_name"
+	BranchProductVersion      = "product_version"
+	BranchProductVersionRange = "product_version_range"
+	BranchServicePack         = "service_pack"
+	BranchSpecification       = "specification"
+	BranchVendor              = "vendor"
+)
+
+// relationship categories
+const (
+	RelationshipDefaultComponentOf  = "default_component_of"
+	RelationshipExternalComponentOf = "external_component_of"
+	RelationshipInstalledOn         = "installed_on"
+	RelationshipInstalledWith       = "installed_with"
+	RelationshipOptionalComponentOf = "optional_component_of"
+)
+
+// Document is a CSAF 2.0 document
+type Document struct {
+	Document        Meta             `json:"document"`
+	ProductTree     *ProductTree     `json:"product_tree,omitempty"`
+	Vulnerabilities []*Vulnerability `json:"vulnerabilities,omitempty"`
+}
+
+// Meta contains the document level meta data
+type Meta struct {
+	Category          string             `json:"category"`
+	CSAFVersion       string             `json:"csaf_version"`
+	Title             string             `json:"title"`
+	Lang              string             `json:"lang,omitempty"`
+	AggregateSeverity *AggregateSeverity `json:"aggregate_severity,omitempty"`
+	Publisher         Publisher          `json:"publisher"`
+	Notes             []*Note            `json:"notes,omitempty"`
+	References        []*Reference       `json:"references,omitempty"`
+	Tracking          Tracking           `json:"tracking"`
+}
+
+type AggregateSeverity struct {
+	Namespace string `json:"namespace,omitempty"`
+	Text      string `json:"text"`
+}
+
+type Publisher struct {
+	Category  string `json:"category"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+type Tracking struct {
+	ID                 string      `json:"id"`
+	Status             string      `json:"status"`
+	Version            string      `json:"version"`
+	InitialReleaseDate string      `json:"initial_release_date"`
+	CurrentReleaseDate string      `json:"current_release_date"`
+	RevisionHistory    []*Revision `json:"revision_history,omitempty"`
+}
+
+type Revision struct {
+	Date    string `json:"date"`
+	Number  string `json:"number"`
+	Summary string `json:"summary"`
+}
+
+type Note struct {
+	Category string `json:"category"`
+	Text     string `json:"text"`
+	Title    string `json:"title,omitempty"`
+}
+
+type Reference struct {
+	Category string `json:"category,omitempty"`
+	Summary  string `json:"summary"`
+	URL      string `json:"url"`
+}
+
+// ProductTree lists all products referenced in the document
+type ProductTree struct {
+	Branches         []*Branch          `json:"branches,omitempty"`
+	FullProductNames []*FullProductName `json:"full_product_names,omitempty"`
+	ProductGroups    []*ProductGroup    `json:"product_groups,omitempty"`
+	Relationships    []*Relationship    `json:"relationships,omitempty"`
+}
+
+// Branch is a node in the product tree, every leaf defines a product
+type Branch struct {
+	Category string           `json:"category"`
+	Name     string           `json:"name"`
+	Product  *FullProductName `json:"product,omitempty"`
+	Branches []*Branch        `json:"branches,omitempty"`
+}
+
+type FullProductName struct {
+	Name                        string                       `json:"name"`
+	ProductID                   string                       `json:"product_id"`
+	ProductIdentificationHelper *ProductIdentificationHelper `json:"product_identification_helper,omitempty"`
+}
+
+type ProductIdentificationHelper struct {
+	CPE           string   `json:"cpe,omitempty"`
+	PURL          string   `json:"purl,omitempty"`
+	ModelNumbers  []string `json:"model_numbers,omitempty"`
+	SBOMURLs      []string `json:"sbom_urls,omitempty"`
+	SerialNumbers []string `json:"serial_numbers,omitempty"`
+	SKUs          []string `json:"skus,omitempty"`
+}
+
+type ProductGroup struct {
+	GroupID    string   `json:"group_id"`
+	ProductIDs []string `json:"product_ids"`
+	Summary    string   `json:"summary,omitempty"`
+}
+
+// Relationship defines a new product by combining two others, e.g. a package installed on some distribution
+type Relationship struct {
+	Category                  string          `json:"category"`
+	FullProductName           FullProductName `json:"full_product_name"`
+	ProductReference          string          `json:"product_reference"`
+	RelatesToProductReference string          `json:"relates_to_product_reference"`
+}
+
+type Vulnerability struct {
+	CVE           string         `json:"cve,omitempty"`
+	CWE           *CWE           `json:"cwe,omitempty"`
+	Title         string         `json:"title,omitempty"`
+	DiscoveryDate string         `json:"discovery_date,omitempty"`
+	ReleaseDate   string         `json:"release_date,omitempty"`
+	IDs           []*ID          `json:"ids,omitempty"`
+	Notes         []*Note        `json:"notes,omitempty"`
+	ProductStatus *ProductStatus `json:"product_status,omitempty"`
+	References    []*Reference   `json:"references,omitempty"`
+	Remediations  []*Remediation `json:"remediations,omitempty"`
+	Scores        []*Score       `json:"scores,omitempty"`
+	Threats       []*Threat      `json:"threats,omitempty"`
+	Flags         []*Flag        `json:"flags,omitempty"`
+}
+
+type CWE struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+type ID struct {
+	SystemName string `json:"system_name"`
+	Text       string `json:"text"`
+}
+
+// ProductStatus lists product ids by their status for the vulnerability
+type ProductStatus struct {
+	FirstAffected      []string `json:"first_affected,omitempty"`
+	FirstFixed         []string `json:"first_fixed,omitempty"`
+	Fixed              []string `json:"fixed,omitempty"`
+	KnownAffected      []string `json:"known_affected,omitempty"`
+	KnownNotAffected   []string `json:"known_not_affected,omitempty"`
+	LastAffected       []string `json:"last_affected,omitempty"`
+	Recommended        []string `json:"recommended,omitempty"`
+	UnderInvestigation []string `json:"under_investigation,omitempty"`
+}
+
+type Remediation struct {
+	Category   string   `json:"category"`
+	Details    string   `json:"details"`
+	Date       string   `json:"date,omitempty"`
+	URL        string   `json:"url,omitempty"`
+	GroupIDs   []string `json:"group_ids,omitempty"`
+	ProductIDs []string `json:"product_ids,omitempty"`
+}
+
+// Score uses the same cvss json schema as nvd
+type Score struct {
+	CVSSV2   *nvd.CVSSV20 `json:"cvss_v2,omitempty"`
+	CVSSV3   *nvd.CVSSV30 `json:"cvss_v3,omitempty"`
+	Products []string     `json:"products"`
+}
+
+type Threat struct {
+	Category   string   `json:"category"`
+	Details    string   `json:"details"`
+	Date       string   `json:"date,omitempty"`
+	GroupIDs   []string `json:"group_ids,omitempty"`
+	ProductIDs []string `json:"product_ids,omitempty"`
+}
+
+type Flag struct {
+	Label      string   `json:"label"`
+	Date       string   `json:"date,omitempty"`
+	GroupIDs   []string `json:"group_ids,omitempty"`
+	ProductIDs []string `json:"product_ids,omitempty"`
+}
@@ -0,0 +1,130 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+// Product is a product from the product tree together with what is known about it from the tree
+type Product struct {
+	ID   string
+	Name string
+	// from the product identification helper
+	CPE  string
+	PURL string
+	// names of the branches on the path to the product by their category, e.g. vendor, product_name or product_version
+	Branches map[string]string
+	// products defined by relationships reference the component and the platform it relates to
+	Relationship string
+	Component    *Product
+	Platform     *Product
+}
+
+// Tree indexes the product tree so product and group ids can be resolved
+type Tree struct {
+	products map[string]*Product
+	groups   map[string][]string
+}
+
+// NewTree creates an index of the given product tree, which can be nil
+func NewTree(pt *ProductTree) *Tree {
+	tree := Tree{
+		products: make(map[string]*Product),
+		groups:   make(map[string][]string),
+	}
+	if pt == nil {
+		return &tree
+	}
+
+	var walk func([]*Branch, map[string]string)
+	walk = func(branches []*Branch, path map[string]string) {
+		for _, b := range branches {
+			p := make(map[string]string, len(path)+1)
+			for k, v := range path {
+				p[k] = v
+			}
+			p[b.Category] = b.Name
+			if b.Product != nil {
+				tree.add(b.Product, p)
+			}
+			walk(b.Branches, p)
+		}
+	}
+	walk(pt.Branches, nil)
+
+	for _, fpn := range pt.FullProductNames {
+		tree.add(fpn, nil)
+	}
+
+	// relationships can reference each other, so all of them are added before they're linked
+	for _, rel := range pt.Relationships {
+		tree.add(&rel.FullProductName, nil).Relationship = rel.Category
+	}
+	for _, rel := range pt.Relationships {
+		p := tree.products[rel.FullProductName.ProductID]
+		p.Component = tree.lookup(rel.ProductReference)
+		p.Platform = tree.lookup(rel.RelatesToProductReference)
+	}
+
+	for _, g := range pt.ProductGroups {
+		tree.groups[g.GroupID] = append(tree.groups[g.GroupID], g.ProductIDs...)
+	}
+
+	return &tree
+}
+
+func (tree *Tree) add(fpn *FullProductName, branches map[string]string) *Product {
+	p := Product{
+		ID:       fpn.ProductID,
+		Name:     fpn.Name,
+		Branches: branches,
+	}
+	if h := fpn.ProductIdentificationHelper; h != nil {
+		p.CPE, p.PURL = h.CPE, h.PURL
+	}
+	tree.products[p.ID] = &p
+	return &p
+}
+
+// lookup returns the product with the given id, products missing from the tree only have the id
+func (tree *Tree) lookup(id string) *Product {
+	if p, ok := tree.products[id]; ok {
+		return p
+	}
+	return &Product{ID: id, Name: id}
+}
+
+// Product returns the product with the given id, or nil if it's not in the tree
+func (tree *Tree) Product(id string) *Product {
+	return tree.products[id]
+}
+
+// ProductIDs returns the given product ids and the ids of products in the given groups, without duplicates
+func (tree *Tree) ProductIDs(productIDs, groupIDs []string) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	add := func(id string) {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	for _, id := range productIDs {
+		add(id)
+	}
+	for _, gid := range groupIDs {
+		for _, id := range tree.groups[gid] {
+			add(id)
+		}
+	}
+	return ids
+}
@@ -0,0 +1,102 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cvelist provides a converter for CVE JSON 5 records from the cvelistV5 repository to nvd.
+package cvelist
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/providers/cvelist/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/git"
+
+	"github.com/pkg/errors"
+)
+
+// CVEListURL is the git repository of CVE records published by the CVE Program.
+const CVEListURL = "https://github.com/CVEProject/cvelistV5"
+
+// Sync clones the cvelistV5 git repository from url into dir, or pulls the latest
+// changes if it has already been cloned there, so records can be converted from dir.
+func Sync(ctx context.Context, dir, url string) error {
+	return git.Sync(ctx, dir, url)
+}
+
+// Convert scans a directory recursively for CVE records and converts them to NVD CVE JSON 1.0 format.
+// Rejected records are skipped.
+func Convert(dir string) (*nvd.NVDCVEFeedJSON10, error) {
+	feed := &nvd.NVDCVEFeedJSON10{}
+
+	walker := func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if info.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if fn := info.Name(); !strings.HasPrefix(fn, "CVE-") || filepath.Ext(fn) != ".json" {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		item, err := ConvertRecord(f)
+		if err == schema.ErrRejected {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "error parsing file: %s", path)
+		}
+		feed.CVEItems = append(feed.CVEItems, item)
+		return nil
+	}
+
+	if err := filepath.Walk(dir, walker); err != nil {
+		return nil, err
+	}
+
+	return feed, nil
+}
+
+// ReadRecord reads a CVE JSON 5 record from r.
+func ReadRecord(r io.Reader) (*schema.Record, error) {
+	var record schema.Record
+	if err := json.NewDecoder(r).Decode(&record); err != nil {
+		return nil, errors.Wrap(err, "cannot decode cve record")
+	}
+	if record.CVEMetadata.CVEID == "" {
+		return nil, errors.New("cve record doesn't have an id")
+	}
+	return &record, nil
+}
+
+// ConvertRecord converts the CVE JSON 5 record from r to NVD CVE JSON 1.0 format.
+func ConvertRecord(r io.Reader) (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	record, err := ReadRecord(r)
+	if err != nil {
+		return nil, err
+	}
+	return record.Convert()
+}
@@ -0,0 +1,154 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cvelist
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const testRecord = `{
+  "dataType": "CVE_RECORD",
+  "dataVersion": "5.1",
+  "cveMetadata": {
+    "cveId": "CVE-2023-1234",
+    "assignerOrgId": "00000000-0000-4000-8000-000000000000",
+    "assignerShortName": "example",
+    "state": "PUBLISHED",
+    "datePublished": "2023-10-10T12:00:00.000Z",
+    "dateUpdated": "2023-10-12T08:30:00.000Z"
+  },
+  "containers": {
+    "cna": {
+      "providerMetadata": {"orgId": "00000000-0000-4000-8000-000000000000", "shortName": "example"},
+      "title": "Path traversal in Example Server",
+      "descriptions": [{"lang": "en", "value": "Example Server before 2.4.1 allows path traversal."}],
+      "affected": [
+        {
+          "vendor": "Example Corp",
+          "product": "Example Server",
+          "defaultStatus": "unaffected",
+          "versions": [
+            {"version": "2.0", "status": "affected", "lessThan": "2.4.1", "versionType": "semver"},
+            {"version": "1.9.9", "status": "affected"},
+            {"version": "3.0", "status": "affected", "versionType": "semver", "changes": [{"at": "3.0.2", "status": "unaffected"}]},
+            {"version": "0123abc", "status": "affected", "versionType": "git"}
+          ]
+        },
+        {"vendor": "n/a", "product": "n/a", "versions": [{"version": "n/a", "status": "affected"}]}
+      ],
+      "problemTypes": [{"descriptions": [{"lang": "en", "description": "CWE-22 Path Traversal", "cweId": "CWE-22", "type": "CWE"}]}],
+      "references": [{"url": "https://example.com/advisory"}]
+    },
+    "adp": [
+      {
+        "providerMetadata": {"orgId": "134c704f-9b21-4f2e-91b3-4a467353bcc0", "shortName": "CISA-ADP"},
+        "affected": [
+          {"vendor": "example", "product": "agent", "cpes": ["cpe:2.3:a:example:agent:*:*:*:*:*:*:*:*"], "defaultStatus": "unknown",
+           "versions": [{"version": "0", "status": "affected", "lessThanOrEqual": "1.2", "versionType": "custom"}]}
+        ],
+        "metrics": [{"cvssV3_1": {"version": "3.1", "vectorString": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N", "baseScore": 7.5, "baseSeverity": "HIGH"}}],
+        "references": [{"url": "https://example.com/advisory"}]
+      }
+    ]
+  }
+}`
+
+const testRejected = `{
+  "dataType": "CVE_RECORD",
+  "dataVersion": "5.1",
+  "cveMetadata": {"cveId": "CVE-2023-0001", "assignerOrgId": "00000000-0000-4000-8000-000000000000", "state": "REJECTED"},
+  "containers": {"cna": {"providerMetadata": {"orgId": "00000000-0000-4000-8000-000000000000"}, "rejectedReasons": [{"lang": "en", "value": "Duplicate."}]}}
+}`
+
+func TestConvertRecord(t *testing.T) {
+	item, err := ConvertRecord(strings.NewReader(testRecord))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.PublishedDate != "2023-10-10T12:00Z" || item.LastModifiedDate != "2023-10-12T08:30Z" {
+		t.Fatalf("wrong dates %q %q", item.PublishedDate, item.LastModifiedDate)
+	}
+	if item.Impact == nil || item.Impact.BaseMetricV3 == nil || item.Impact.BaseMetricV3.CVSSV3.BaseScore != 7.5 {
+		t.Fatalf("adp metrics should be used when cna has none: %+v", item.Impact)
+	}
+	if refs := item.CVE.References.ReferenceData; len(refs) != 1 {
+		t.Fatalf("expecting 1 unique reference, got %d", len(refs))
+	}
+
+	v := nvd.ToVuln(item)
+	if cwes := v.CWEs(); len(cwes) != 1 || cwes[0] != "CWE-22" {
+		t.Fatalf("wrong cwes %v", cwes)
+	}
+	for i, tc := range []struct {
+		cpe   string
+		match bool
+	}{
+		{"cpe:/a:example_corp:example_server:2.4.0", true},
+		{"cpe:/a:example_corp:example_server:2.4.1", false},
+		{"cpe:/a:example_corp:example_server:1.9.9", true},
+		{"cpe:/a:example_corp:example_server:1.9.8", false},
+		{"cpe:/a:example_corp:example_server:3.0.1", true},
+		{"cpe:/a:example_corp:example_server:3.0.2", false},
+		{"cpe:/a:example:agent:1.2", true},
+		{"cpe:/a:example:agent:1.3", false},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			attrs, err := wfn.Parse(tc.cpe)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if matched := len(v.Match([]*wfn.Attributes{attrs}, true)) != 0; matched != tc.match {
+				t.Fatalf("expecting match of %s to be %v, got %v", tc.cpe, tc.match, matched)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cvelist-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.MkdirAll(filepath.Join(dir, "cves", "2023", "1xxx"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "cves", "2023", "1xxx", "CVE-2023-1234.json"), []byte(testRecord), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "cves", "2023", "0xxx"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "cves", "2023", "0xxx", "CVE-2023-0001.json"), []byte(testRejected), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	feed, err := Convert(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feed.CVEItems) != 1 || feed.CVEItems[0].CVE.CVEDataMeta.ID != "CVE-2023-1234" {
+		t.Fatalf("expecting only the published record, got %d items", len(feed.CVEItems))
+	}
+}
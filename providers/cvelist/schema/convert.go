@@ -0,0 +1,348 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const (
+	cveDataVersion = "4.0"
+)
+
+// ErrRejected is returned when converting records which have been rejected
+var ErrRejected = errors.New("record has been rejected")
+
+// ID is a part of the runner.Convertible interface
+func (r *Record) ID() string {
+	return r.CVEMetadata.CVEID
+}
+
+// Convert is a part of the runner.Convertible interface
+// affected products are converted into configurations where possible, using CPEs if the CNA or ADP listed them
+// and vendor and product names otherwise
+func (r *Record) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	if r.CVEMetadata.State == StateRejected {
+		return nil, ErrRejected
+	}
+	cna := r.Containers.CNA
+	if cna == nil {
+		return nil, fmt.Errorf("record %s doesn't have the cna container", r.ID())
+	}
+
+	item := nvd.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &nvd.CVEJSON40{
+			CVEDataMeta: &nvd.CVEJSON40CVEDataMeta{
+				ID:       r.ID(),
+				ASSIGNER: r.CVEMetadata.AssignerShortName,
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: cveDataVersion,
+			Description: cna.newDescription(),
+			Problemtype: r.newProblemType(),
+			References:  r.newReferences(),
+		},
+		Configurations:   r.newConfigurations(),
+		Impact:           r.newImpact(),
+		LastModifiedDate: cveTimeToNVD(r.CVEMetadata.DateUpdated),
+		PublishedDate:    cveTimeToNVD(r.CVEMetadata.DatePublished),
+	}
+
+	return &item, nil
+}
+
+func (c *Container) newDescription() *nvd.CVEJSON40Description {
+	desc := &nvd.CVEJSON40Description{}
+	for _, d := range c.Descriptions {
+		if d != nil {
+			desc.DescriptionData = append(desc.DescriptionData, &nvd.CVEJSON40LangString{
+				Lang:  d.Lang,
+				Value: d.Value,
+			})
+		}
+	}
+	if len(desc.DescriptionData) == 0 && c.Title != "" {
+		desc.DescriptionData = append(desc.DescriptionData, &nvd.CVEJSON40LangString{
+			Lang:  "en",
+			Value: c.Title,
+		})
+	}
+	return desc
+}
+
+// newProblemType returns CWEs from all containers
+func (r *Record) newProblemType() *nvd.CVEJSON40Problemtype {
+	data := &nvd.CVEJSON40ProblemtypeProblemtypeData{}
+	seen := make(map[string]bool)
+	for _, c := range r.AllContainers() {
+		for _, pt := range c.ProblemTypes {
+			if pt == nil {
+				continue
+			}
+			for _, d := range pt.Descriptions {
+				if d == nil {
+					continue
+				}
+				cwe := d.CWEID
+				if cwe == "" && strings.HasPrefix(d.Description, "CWE-") {
+					cwe = strings.Fields(d.Description)[0]
+				}
+				if cwe == "" || seen[cwe] {
+					continue
+				}
+				seen[cwe] = true
+				data.Description = append(data.Description, &nvd.CVEJSON40LangString{
+					Lang:  "en",
+					Value: cwe,
+				})
+			}
+		}
+	}
+	return &nvd.CVEJSON40Problemtype{
+		ProblemtypeData: []*nvd.CVEJSON40ProblemtypeProblemtypeData{data},
+	}
+}
+
+// newReferences returns references from all containers
+func (r *Record) newReferences() *nvd.CVEJSON40References {
+	refs := &nvd.CVEJSON40References{}
+	seen := make(map[string]bool)
+	for _, c := range r.AllContainers() {
+		for _, ref := range c.References {
+			if ref == nil || seen[ref.URL] {
+				continue
+			}
+			seen[ref.URL] = true
+			name := ref.Name
+			if name == "" {
+				name = ref.URL
+			}
+			refs.ReferenceData = append(refs.ReferenceData, &nvd.CVEJSON40Reference{
+				Name:      name,
+				Refsource: c.ProviderMetadata.ShortName,
+				Tags:      ref.Tags,
+				URL:       ref.URL,
+			})
+		}
+	}
+	return refs
+}
+
+// newImpact uses the first CVSS metrics found, CNA's metrics are preferred over ADP's
+func (r *Record) newImpact() *nvd.NVDCVEFeedJSON10DefImpact {
+	var impact nvd.NVDCVEFeedJSON10DefImpact
+	for _, c := range r.AllContainers() {
+		for _, m := range c.Metrics {
+			if m == nil {
+				continue
+			}
+			if impact.BaseMetricV3 == nil {
+				if cvss := m.CVSSV31; cvss != nil {
+					impact.BaseMetricV3 = &nvd.NVDCVEFeedJSON10DefImpactBaseMetricV3{CVSSV3: cvss}
+				} else if cvss := m.CVSSV30; cvss != nil {
+					impact.BaseMetricV3 = &nvd.NVDCVEFeedJSON10DefImpactBaseMetricV3{CVSSV3: cvss}
+				}
+			}
+			if impact.BaseMetricV2 == nil && m.CVSSV20 != nil {
+				impact.BaseMetricV2 = &nvd.NVDCVEFeedJSON10DefImpactBaseMetricV2{CVSSV2: m.CVSSV20}
+			}
+		}
+	}
+	if impact.BaseMetricV2 == nil && impact.BaseMetricV3 == nil {
+		return nil
+	}
+	return &impact
+}
+
+// newConfigurations creates configurations from affected products of the CNA
+// and of ADPs which listed CPEs, e.g. CISA vulnrichment
+func (r *Record) newConfigurations() *nvd.NVDCVEFeedJSON10DefConfigurations {
+	node := &nvd.NVDCVEFeedJSON10DefNode{Operator: "OR"}
+	for _, c := range r.AllContainers() {
+		for _, affected := range c.Affected {
+			if affected == nil || (c != r.Containers.CNA && len(affected.CPEs) == 0) {
+				continue
+			}
+			matches, err := affected.cpeMatches()
+			if err != nil {
+				logging.Recordf("can't create configuration for %s, product %q: %v", r.ID(), affected.Product, err)
+				continue
+			}
+			node.CPEMatch = append(node.CPEMatch, matches...)
+		}
+	}
+	return &nvd.NVDCVEFeedJSON10DefConfigurations{
+		CVEDataVersion: cveDataVersion,
+		Nodes:          []*nvd.NVDCVEFeedJSON10DefNode{node},
+	}
+}
+
+// versionRange is a range of affected versions, empty range means all versions
+type versionRange struct {
+	exact          string
+	startIncluding string
+	endExcluding   string
+	endIncluding   string
+}
+
+func (a *Affected) cpeMatches() ([]*nvd.NVDCVEFeedJSON10DefCPEMatch, error) {
+	cpes, err := a.cpes()
+	if err != nil {
+		return nil, err
+	}
+	ranges := a.ranges()
+
+	var matches []*nvd.NVDCVEFeedJSON10DefCPEMatch
+	for _, cpe := range cpes {
+		for _, vr := range ranges {
+			attrs := *cpe
+			if vr.exact != "" {
+				if attrs.Version, err = wfn.WFNize(vr.exact); err != nil {
+					return nil, fmt.Errorf("can't wfnize version %q: %v", vr.exact, err)
+				}
+			} else if vr != (versionRange{}) {
+				attrs.Version = wfn.Any
+			}
+			cpe23URI := attrs.BindToFmtString()
+			matches = append(matches, &nvd.NVDCVEFeedJSON10DefCPEMatch{
+				CPEName: []*nvd.NVDCVEFeedJSON10DefCPEName{
+					{
+						Cpe22Uri: attrs.BindToURI(),
+						Cpe23Uri: cpe23URI,
+					},
+				},
+				Cpe23Uri:              cpe23URI,
+				VersionStartIncluding: vr.startIncluding,
+				VersionEndExcluding:   vr.endExcluding,
+				VersionEndIncluding:   vr.endIncluding,
+				Vulnerable:            true,
+			})
+		}
+	}
+	return matches, nil
+}
+
+// cpes returns the listed CPEs, or creates one from vendor and product or package name
+func (a *Affected) cpes() ([]*wfn.Attributes, error) {
+	var cpes []*wfn.Attributes
+	for _, s := range a.CPEs {
+		attrs, err := wfn.Parse(s)
+		if err != nil {
+			logging.Recordf("can't parse cpe %q: %v", s, err)
+			continue
+		}
+		cpes = append(cpes, attrs)
+	}
+	if len(cpes) != 0 {
+		return cpes, nil
+	}
+
+	product := a.Product
+	if unspecified(product) {
+		product = a.PackageName
+	}
+	if unspecified(product) {
+		return nil, fmt.Errorf("neither product nor package name is specified")
+	}
+
+	attrs := wfn.Attributes{Part: "a"}
+	var err error
+	if attrs.Product, err = wfn.WFNize(strings.ToLower(product)); err != nil {
+		return nil, fmt.Errorf("can't wfnize product %q: %v", product, err)
+	}
+	if !unspecified(a.Vendor) {
+		if attrs.Vendor, err = wfn.WFNize(strings.ToLower(a.Vendor)); err != nil {
+			return nil, fmt.Errorf("can't wfnize vendor %q: %v", a.Vendor, err)
+		}
+	}
+	return []*wfn.Attributes{&attrs}, nil
+}
+
+// ranges returns ranges of affected versions
+// versions of git commits can't be matched against CPEs and are ignored
+func (a *Affected) ranges() []versionRange {
+	var ranges []versionRange
+	for _, v := range a.Versions {
+		if v == nil || v.Status != StatusAffected || v.VersionType == "git" {
+			continue
+		}
+		var vr versionRange
+		start := v.Version
+		if unspecified(start) {
+			start = ""
+		}
+		switch {
+		case v.LessThan != "":
+			vr.startIncluding = start
+			if !unspecified(v.LessThan) {
+				vr.endExcluding = v.LessThan
+			}
+		case v.LessThanOrEqual != "":
+			vr.startIncluding = start
+			if !unspecified(v.LessThanOrEqual) {
+				vr.endIncluding = v.LessThanOrEqual
+			}
+		case len(v.Changes) != 0:
+			vr.startIncluding = start
+		default:
+			vr.exact = start
+		}
+		// the range ends at the first version which isn't affected anymore
+		if vr.endExcluding == "" && vr.endIncluding == "" {
+			for _, c := range v.Changes {
+				if c != nil && c.Status == StatusUnaffected {
+					vr.endExcluding = c.At
+					break
+				}
+			}
+		}
+		ranges = append(ranges, vr)
+	}
+	if len(ranges) == 0 && a.DefaultStatus == StatusAffected {
+		// all versions
+		ranges = append(ranges, versionRange{})
+	}
+	return ranges
+}
+
+// unspecified returns true for values CNAs use when they don't know the value
+func unspecified(s string) bool {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "*", "0", "n/a", "unspecified", "unknown":
+		return true
+	}
+	return false
+}
+
+func cveTimeToNVD(s string) string {
+	if s == "" {
+		return ""
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC().Format(nvd.TimeLayout)
+		}
+	}
+	logging.Recordf("can't parse cve time %q", s)
+	return s
+}
@@ -0,0 +1,153 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+)
+
+// based on CVE JSON 5 record format
+// https://github.com/CVEProject/cve-schema/blob/main/schema/CVE_Record_Format.json
+
+// states of CVE records
+const (
+	StatePublished = "PUBLISHED"
+	StateRejected  = "REJECTED"
+)
+
+// Record is a CVE record
+type Record struct {
+	DataType    string      `json:"dataType"`
+	DataVersion string      `json:"dataVersion"`
+	CVEMetadata CVEMetadata `json:"cveMetadata"`
+	Containers  Containers  `json:"containers"`
+}
+
+type CVEMetadata struct {
+	CVEID             string `json:"cveId"`
+	AssignerOrgID     string `json:"assignerOrgId"`
+	AssignerShortName string `json:"assignerShortName,omitempty"`
+	State             string `json:"state"`
+	DateReserved      string `json:"dateReserved,omitempty"`
+	DatePublished     string `json:"datePublished,omitempty"`
+	DateUpdated       string `json:"dateUpdated,omitempty"`
+	DateRejected      string `json:"dateRejected,omitempty"`
+}
+
+// Containers holds the data provided by the CNA which assigned the CVE
+// and by authorized data publishers, like CISA vulnrichment
+type Containers struct {
+	CNA *Container   `json:"cna"`
+	ADP []*Container `json:"adp,omitempty"`
+}
+
+type Container struct {
+	ProviderMetadata ProviderMetadata `json:"providerMetadata"`
+	Title            string           `json:"title,omitempty"`
+	DatePublic       string           `json:"datePublic,omitempty"`
+	Descriptions     []*Description   `json:"descriptions,omitempty"`
+	Affected         []*Affected      `json:"affected,omitempty"`
+	ProblemTypes     []*ProblemType   `json:"problemTypes,omitempty"`
+	References       []*Reference     `json:"references,omitempty"`
+	Metrics          []*Metric        `json:"metrics,omitempty"`
+	// rejected records only have the reasons
+	RejectedReasons []*Description `json:"rejectedReasons,omitempty"`
+}
+
+type ProviderMetadata struct {
+	OrgID       string `json:"orgId"`
+	ShortName   string `json:"shortName,omitempty"`
+	DateUpdated string `json:"dateUpdated,omitempty"`
+}
+
+type Description struct {
+	Lang  string `json:"lang"`
+	Value string `json:"value"`
+}
+
+// Affected is an affected product
+// it's identified either by vendor and product, or collection url and package name
+type Affected struct {
+	Vendor        string     `json:"vendor,omitempty"`
+	Product       string     `json:"product,omitempty"`
+	CollectionURL string     `json:"collectionURL,omitempty"`
+	PackageName   string     `json:"packageName,omitempty"`
+	CPEs          []string   `json:"cpes,omitempty"`
+	Platforms     []string   `json:"platforms,omitempty"`
+	DefaultStatus string     `json:"defaultStatus,omitempty"`
+	Versions      []*Version `json:"versions,omitempty"`
+}
+
+// statuses of versions
+const (
+	StatusAffected   = "affected"
+	StatusUnaffected = "unaffected"
+	StatusUnknown    = "unknown"
+)
+
+// Version is a single version or a range of versions with the same status
+type Version struct {
+	Version         string    `json:"version"`
+	Status          string    `json:"status"`
+	VersionType     string    `json:"versionType,omitempty"`
+	LessThan        string    `json:"lessThan,omitempty"`
+	LessThanOrEqual string    `json:"lessThanOrEqual,omitempty"`
+	Changes         []*Change `json:"changes,omitempty"`
+}
+
+// Change is a change of the status within a range of versions
+type Change struct {
+	At     string `json:"at"`
+	Status string `json:"status"`
+}
+
+type ProblemType struct {
+	Descriptions []*ProblemTypeDescription `json:"descriptions"`
+}
+
+type ProblemTypeDescription struct {
+	Lang        string `json:"lang"`
+	Description string `json:"description"`
+	CWEID       string `json:"cweId,omitempty"`
+	Type        string `json:"type,omitempty"`
+}
+
+type Reference struct {
+	URL  string   `json:"url"`
+	Name string   `json:"name,omitempty"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+// Metric holds scores in one of the formats, only CVSS is used
+type Metric struct {
+	Format  string       `json:"format,omitempty"`
+	CVSSV31 *nvd.CVSSV30 `json:"cvssV3_1,omitempty"`
+	CVSSV30 *nvd.CVSSV30 `json:"cvssV3_0,omitempty"`
+	CVSSV20 *nvd.CVSSV20 `json:"cvssV2_0,omitempty"`
+}
+
+// AllContainers returns the CNA container followed by ADP containers
+func (r *Record) AllContainers() []*Container {
+	var cs []*Container
+	if r.Containers.CNA != nil {
+		cs = append(cs, r.Containers.CNA)
+	}
+	for _, c := range r.Containers.ADP {
+		if c != nil {
+			cs = append(cs, c)
+		}
+	}
+	return cs
+}
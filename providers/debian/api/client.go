@@ -0,0 +1,67 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/facebookincubator/nvdtools/providers/debian/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+)
+
+const trackerPath = "/tracker/data/json"
+
+// Client downloads the debian security tracker data
+type Client struct {
+	client.Client
+	baseURL string
+}
+
+// NewClient creates an object which is used to download the debian security tracker data
+func NewClient(c client.Client, baseURL string) *Client {
+	return &Client{
+		Client:  c,
+		baseURL: baseURL,
+	}
+}
+
+// FetchAllVulnerabilities downloads the tracker data and returns all vulnerabilities
+// the tracker doesn't say when the data was modified, so everything is returned every time
+func (c *Client) FetchAllVulnerabilities(ctx context.Context) (<-chan runner.Convertible, error) {
+	resp, err := client.Get(ctx, c, c.baseURL+trackerPath, http.Header{})
+	if err != nil {
+		return nil, fmt.Errorf("can't download tracker data: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var tracker schema.Tracker
+	if err := json.NewDecoder(resp.Body).Decode(&tracker); err != nil {
+		return nil, fmt.Errorf("can't decode tracker data: %v", err)
+	}
+
+	output := make(chan runner.Convertible)
+	go func() {
+		defer close(output)
+		for _, vuln := range tracker.Vulnerabilities() {
+			output <- vuln
+		}
+	}()
+
+	return output, nil
+}
@@ -0,0 +1,106 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debian
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/deb"
+	"github.com/facebookincubator/nvdtools/providers/debian/schema"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// Releases maps debian release codenames to their versions
+var Releases = map[string]string{
+	"jessie":   "8",
+	"stretch":  "9",
+	"buster":   "10",
+	"bullseye": "11",
+	"bookworm": "12",
+	"trixie":   "13",
+	"forky":    "14",
+	"sid":      "unstable",
+}
+
+// Feed is a collection of vulnerabilities, keyed by the cve id
+type Feed map[string]*schema.Vulnerability
+
+// LoadFeed loads a feed downloaded by debian2nvd
+func LoadFeed(path string) (Feed, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open file %q: %v", path, err)
+	}
+	defer f.Close()
+
+	var feed Feed
+	if err := json.NewDecoder(f).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("can't decode feed: %v", err)
+	}
+	return feed, nil
+}
+
+// LoadTracker loads the security tracker data as published by debian
+func LoadTracker(path string) (Feed, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open file %q: %v", path, err)
+	}
+	defer f.Close()
+	return loadTracker(f)
+}
+
+func loadTracker(r io.Reader) (Feed, error) {
+	var tracker schema.Tracker
+	if err := json.NewDecoder(r).Decode(&tracker); err != nil {
+		return nil, fmt.Errorf("can't decode tracker data: %v", err)
+	}
+	return Feed(tracker.Vulnerabilities()), nil
+}
+
+// Checker returns a checker which knows whether a package on some debian release has been fixed
+// distro should be debian cpe, e.g. cpe:/o:debian:debian_linux:12
+func (feed Feed) Checker() (deb.Checker, error) {
+	return NewPackageFeed(feed)
+}
+
+// ReleaseName returns the codename of the debian release, which can be given either as a codename or a version
+func ReleaseName(release string) (string, bool) {
+	if _, ok := Releases[release]; ok {
+		return release, true
+	}
+	// point releases, e.g. 12.4
+	if i := strings.IndexByte(release, '.'); i >= 0 {
+		release = release[:i]
+	}
+	for name, version := range Releases {
+		if version == release {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// distroRelease returns the codename of the release from the debian cpe
+func distroRelease(distro *wfn.Attributes) (string, bool) {
+	if distro == nil || distro.Product != "debian_linux" {
+		return "", false
+	}
+	return ReleaseName(wfn.StripSlashes(distro.Version))
+}
@@ -0,0 +1,101 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debian
+
+import (
+	"github.com/facebookincubator/nvdtools/deb"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/pkgfeed"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// packageKey identifies a source package on some debian release
+type packageKey struct {
+	release string
+	name    string
+}
+
+// PackageFeed indexes the feed by release and source package
+// it can be used to list CVEs which are fixed or which still affect some installed package
+type PackageFeed struct {
+	feed *pkgfeed.Feed
+}
+
+// NewPackageFeed creates a new package feed from the given feed
+func NewPackageFeed(feed Feed) (*PackageFeed, error) {
+	pf, err := pkgfeed.New(func(add func(interface{}, pkgfeed.Fix)) error {
+		for cveid, vuln := range feed {
+			for name, cve := range vuln.Packages {
+				for release, r := range cve.Releases {
+					fix := pkgfeed.DebFix{ID: cveid, NotAffected: r.NotAffected()}
+					if r.Fixed() && !fix.NotAffected {
+						v, err := deb.ParseVersion(r.FixedVersion)
+						if err != nil {
+							logging.Recordf("can't parse fixed version %q of %s for %s: %v", r.FixedVersion, name, cveid, err)
+							continue
+						}
+						fix.Version = v
+					}
+					add(packageKey{release: release, name: name}, &fix)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &PackageFeed{feed: pf}, nil
+}
+
+// LoadPackageFeed loads the feed from the given path and indexes it by packages
+func LoadPackageFeed(path string) (*PackageFeed, error) {
+	feed, err := LoadFeed(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewPackageFeed(feed)
+}
+
+// ListFixedCVEs returns sorted list of CVEs which are fixed for the given source package on the given release
+// release can be either a codename (e.g. bookworm) or a version (e.g. 12)
+// CVEs which never affected the package on the release are included
+func (pf *PackageFeed) ListFixedCVEs(release string, pkg *deb.Package) []string {
+	name, ok := ReleaseName(release)
+	if !ok {
+		return []string{}
+	}
+	return pf.feed.ListFixedCVEs(pkg, packageKey{release: name, name: pkg.Name})
+}
+
+// ListVulnerableCVEs returns sorted list of CVEs which affect the given source package on the given release
+// these are all CVEs which aren't fixed, including undetermined and no-dsa ones
+func (pf *PackageFeed) ListVulnerableCVEs(release string, pkg *deb.Package) []string {
+	name, ok := ReleaseName(release)
+	if !ok {
+		return []string{}
+	}
+	return pf.feed.ListVulnerableCVEs(pkg, packageKey{release: name, name: pkg.Name})
+}
+
+// Check is part of the deb.Checker interface
+// distro should be debian cpe, e.g. cpe:/o:debian:debian_linux:12
+func (pf *PackageFeed) Check(pkg *deb.Package, distro *wfn.Attributes, cve string) bool {
+	release, ok := distroRelease(distro)
+	if !ok {
+		return false
+	}
+	return pf.feed.Check(pkg, cve, packageKey{release: release, name: pkg.Name})
+}
@@ -0,0 +1,155 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package debian
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/deb"
+)
+
+func TestPackageFeed(t *testing.T) {
+	feed, err := loadTracker(strings.NewReader(testTracker))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pf, err := NewPackageFeed(feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tc := range []struct {
+		release    string
+		pkg        string
+		fixed      []string
+		vulnerable []string
+	}{
+		{"bullseye", "openssl_1.1.1n-0+deb11u4", []string{"CVE-2023-0286"}, []string{"CVE-2023-0464"}},
+		{"11", "openssl_1.1.1n-0+deb11u3", []string{}, []string{"CVE-2023-0286", "CVE-2023-0464"}},
+		{"12.4", "openssl_3.0.8-1", []string{"CVE-2023-0286", "CVE-2023-0464"}, []string{}},
+		{"bookworm", "openssl_3.0.7-1", []string{"CVE-2023-0286"}, []string{"CVE-2023-0464"}},
+		{"buster", "openssl_1.1.1n-0+deb10u3", []string{}, []string{"CVE-2023-0286"}},
+		{"unknown", "openssl_1.1.1n-0+deb11u4", []string{}, []string{}},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			pkg, err := deb.Parse(tc.pkg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := pf.ListFixedCVEs(tc.release, pkg); !reflect.DeepEqual(got, tc.fixed) {
+				t.Fatalf("expecting fixed %v, got %v", tc.fixed, got)
+			}
+			if got := pf.ListVulnerableCVEs(tc.release, pkg); !reflect.DeepEqual(got, tc.vulnerable) {
+				t.Fatalf("expecting vulnerable %v, got %v", tc.vulnerable, got)
+			}
+		})
+	}
+}
+
+func TestChecker(t *testing.T) {
+	feed, err := loadTracker(strings.NewReader(testTracker))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chk, err := feed.Checker()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tc := range []struct {
+		pkg    string
+		distro string
+		cve    string
+		fixed  bool
+	}{
+		{"openssl_1.1.1n-0+deb11u4", "cpe:/o:debian:debian_linux:11.0", "CVE-2023-0286", true},
+		{"openssl_1.1.1n-0+deb11u3", "cpe:/o:debian:debian_linux:11.0", "CVE-2023-0286", false},
+		{"openssl_1.1.1n-0+deb11u4", "cpe:/o:debian:debian_linux:11.0", "CVE-2023-0464", false},
+		{"openssl_3.0.8-1", "cpe:/o:debian:debian_linux:12", "CVE-2023-0464", true},
+		{"openssl_3.0.8-1", "cpe:/o:canonical:ubuntu_linux:22.04", "CVE-2023-0464", false},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			fixed, err := deb.Check(chk, tc.pkg, tc.distro, tc.cve)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fixed != tc.fixed {
+				t.Fatalf("expecting %s to be fixed: %v, got %v", tc.pkg, tc.fixed, fixed)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	feed, err := loadTracker(strings.NewReader(testTracker))
+	if err != nil {
+		t.Fatal(err)
+	}
+	item, err := feed["CVE-2023-0286"].Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// buster won't be fixed in a DSA, which is told in the description
+	want := "X.400 address type confusion\n\nDebian: openssl on buster won't get a DSA: Minor issue (postponed)."
+	if got := item.CVE.Description.DescriptionData[0].Value; got != want {
+		t.Fatalf("expecting description %q, got %q", want, got)
+	}
+	matches := item.Configurations.Nodes[0].CPEMatch
+	// bookworm isn't affected
+	if len(matches) != 2 {
+		t.Fatalf("expecting 2 cpe matches, got %d", len(matches))
+	}
+	if matches[0].VersionEndExcluding != "1.1.1n-0+deb11u4" || matches[1].VersionEndExcluding != "" {
+		t.Fatalf("wrong versions in cpe matches: %q, %q", matches[0].VersionEndExcluding, matches[1].VersionEndExcluding)
+	}
+
+	// undetermined issues match all versions, the description tells why
+	if item, err = feed["CVE-2023-0464"].Convert(); err != nil {
+		t.Fatal(err)
+	}
+	want = "Excessive Resource Usage Verifying X.509 Policy Constraints\n\nDebian: openssl on bullseye is undetermined, all versions are considered vulnerable."
+	if got := item.CVE.Description.DescriptionData[0].Value; got != want {
+		t.Fatalf("expecting description %q, got %q", want, got)
+	}
+	if matches := item.Configurations.Nodes[0].CPEMatch; len(matches) != 2 || matches[1].VersionEndExcluding != "" {
+		t.Fatalf("expecting an unbounded match for bullseye, got %d matches", len(matches))
+	}
+}
+
+var testTracker = `{
+  "openssl": {
+    "CVE-2023-0286": {
+      "description": "X.400 address type confusion",
+      "scope": "remote",
+      "debianbug": 1031327,
+      "releases": {
+        "bookworm": {"status": "resolved", "repositories": {"bookworm": "3.0.9-1"}, "fixed_version": "0", "urgency": "not yet assigned"},
+        "bullseye": {"status": "resolved", "repositories": {"bullseye": "1.1.1n-0+deb11u4"}, "fixed_version": "1.1.1n-0+deb11u4", "urgency": "not yet assigned"},
+        "buster": {"status": "open", "repositories": {"buster": "1.1.1n-0+deb10u3"}, "urgency": "not yet assigned", "nodsa": "Minor issue", "nodsa_reason": "postponed"}
+      }
+    },
+    "CVE-2023-0464": {
+      "description": "Excessive Resource Usage Verifying X.509 Policy Constraints",
+      "scope": "remote",
+      "releases": {
+        "bookworm": {"status": "resolved", "repositories": {"bookworm": "3.0.9-1"}, "fixed_version": "3.0.8-1", "urgency": "not yet assigned"},
+        "bullseye": {"status": "undetermined", "repositories": {"bullseye": "1.1.1n-0+deb11u4"}, "urgency": "not yet assigned"}
+      }
+    }
+  }
+}`
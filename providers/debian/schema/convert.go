@@ -0,0 +1,188 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const (
+	cveDataVersion = "4.0"
+	trackerURL     = "https://security-tracker.debian.org/tracker/"
+	bugURL         = "https://bugs.debian.org/"
+)
+
+// ID is a part of the runner.Convertible interface
+func (vuln *Vulnerability) ID() string {
+	return vuln.CVEID
+}
+
+// Convert is a part of the runner.Convertible interface
+func (vuln *Vulnerability) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	pkgs := vuln.packages()
+
+	configurations, err := vuln.newConfigurations(pkgs)
+	if err != nil {
+		return nil, fmt.Errorf("can't create configurations: %v", err)
+	}
+
+	var description string
+	for _, pkg := range pkgs {
+		if description = vuln.Packages[pkg].Description; description != "" {
+			break
+		}
+	}
+	if notes := vuln.statusNotes(pkgs); len(notes) != 0 {
+		description = strings.TrimSpace(description + "\n\n" + strings.Join(notes, "\n"))
+	}
+
+	item := nvd.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &nvd.CVEJSON40{
+			CVEDataMeta: &nvd.CVEJSON40CVEDataMeta{
+				ID:       vuln.ID(),
+				ASSIGNER: "debian.org",
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: cveDataVersion,
+			Description: &nvd.CVEJSON40Description{
+				DescriptionData: []*nvd.CVEJSON40LangString{
+					{
+						Lang:  "en",
+						Value: description,
+					},
+				},
+			},
+			References: vuln.newReferences(pkgs),
+		},
+		Configurations: configurations,
+	}
+
+	return &item, nil
+}
+
+// packages returns sorted package names to get a stable output
+func (vuln *Vulnerability) packages() []string {
+	pkgs := make([]string, 0, len(vuln.Packages))
+	for pkg := range vuln.Packages {
+		pkgs = append(pkgs, pkg)
+	}
+	sort.Strings(pkgs)
+	return pkgs
+}
+
+// statusNotes explains statuses which can't be told from the configurations: releases on which
+// the issue wasn't triaged yet, so all versions are considered vulnerable, and releases which
+// won't get a security advisory (DSA), fixed or not
+func (vuln *Vulnerability) statusNotes(pkgs []string) []string {
+	var notes []string
+	for _, pkg := range pkgs {
+		cve := vuln.Packages[pkg]
+		for _, release := range sortedReleases(cve) {
+			r := cve.Releases[release]
+			if r.Status == StatusUndetermined {
+				notes = append(notes, fmt.Sprintf("Debian: %s on %s is undetermined, all versions are considered vulnerable.", pkg, release))
+			}
+			if r.NoDSA != "" {
+				note := fmt.Sprintf("Debian: %s on %s won't get a DSA: %s", pkg, release, r.NoDSA)
+				if r.NoDSAReason != "" {
+					note += fmt.Sprintf(" (%s)", r.NoDSAReason)
+				}
+				notes = append(notes, note+".")
+			}
+		}
+	}
+	return notes
+}
+
+// sortedReleases returns sorted release names of the cve to get a stable output
+func sortedReleases(cve *CVE) []string {
+	releases := make([]string, 0, len(cve.Releases))
+	for release := range cve.Releases {
+		releases = append(releases, release)
+	}
+	sort.Strings(releases)
+	return releases
+}
+
+func (vuln *Vulnerability) newReferences(pkgs []string) *nvd.CVEJSON40References {
+	refs := &nvd.CVEJSON40References{
+		ReferenceData: []*nvd.CVEJSON40Reference{
+			{
+				Name: vuln.ID(),
+				URL:  trackerURL + vuln.ID(),
+			},
+		},
+	}
+	for _, pkg := range pkgs {
+		if bug := vuln.Packages[pkg].DebianBug; bug != 0 {
+			refs.ReferenceData = append(refs.ReferenceData, &nvd.CVEJSON40Reference{
+				Name: fmt.Sprintf("Debian bug #%d", bug),
+				URL:  fmt.Sprintf("%s%d", bugURL, bug),
+			})
+		}
+	}
+	return refs
+}
+
+func (vuln *Vulnerability) newConfigurations(pkgs []string) (*nvd.NVDCVEFeedJSON10DefConfigurations, error) {
+	node := &nvd.NVDCVEFeedJSON10DefNode{Operator: "OR"}
+	for _, pkg := range pkgs {
+		cve := vuln.Packages[pkg]
+		for _, release := range sortedReleases(cve) {
+			r := cve.Releases[release]
+			if r.NotAffected() {
+				continue
+			}
+			attrs, err := PackageToCPE(release, pkg)
+			if err != nil {
+				return nil, err
+			}
+			match := nvd.NVDCVEFeedJSON10DefCPEMatch{
+				Cpe23Uri:   attrs.BindToFmtString(),
+				Vulnerable: true,
+			}
+			// open and undetermined issues affect all versions
+			if r.Fixed() {
+				match.VersionEndExcluding = r.FixedVersion
+			}
+			node.CPEMatch = append(node.CPEMatch, &match)
+		}
+	}
+
+	return &nvd.NVDCVEFeedJSON10DefConfigurations{
+		CVEDataVersion: cveDataVersion,
+		Nodes:          []*nvd.NVDCVEFeedJSON10DefNode{node},
+	}, nil
+}
+
+// PackageToCPE creates a CPE for the source package on the given debian release
+// release codename is stored as target software, e.g. debian_bookworm
+func PackageToCPE(release, name string) (*wfn.Attributes, error) {
+	attrs := wfn.Attributes{Part: "a"}
+	var err error
+	if attrs.Product, err = wfn.WFNize(name); err != nil {
+		return nil, fmt.Errorf("can't wfnize package name %q: %v", name, err)
+	}
+	if attrs.TargetSW, err = wfn.WFNize("debian_" + release); err != nil {
+		return nil, fmt.Errorf("can't wfnize release %q: %v", release, err)
+	}
+	return &attrs, nil
+}
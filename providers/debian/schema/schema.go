@@ -0,0 +1,81 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+// based on the Debian security tracker data
+// https://security-tracker.debian.org/tracker/data/json
+
+// possible statuses of a package on some release
+const (
+	StatusResolved     = "resolved"
+	StatusOpen         = "open"
+	StatusUndetermined = "undetermined"
+)
+
+// notAffectedVersion is used as a fixed version when the package was never affected
+const notAffectedVersion = "0"
+
+// Tracker is the whole security tracker data: source package -> cve id -> cve
+type Tracker map[string]map[string]*CVE
+
+// CVE is the status of a cve for a single source package
+type CVE struct {
+	Description string              `json:"description,omitempty"`
+	Scope       string              `json:"scope,omitempty"`
+	DebianBug   int                 `json:"debianbug,omitempty"`
+	Releases    map[string]*Release `json:"releases"`
+}
+
+// Release is the status of a cve for a single source package on some release (e.g. bookworm)
+type Release struct {
+	Status       string            `json:"status"`
+	Repositories map[string]string `json:"repositories,omitempty"`
+	FixedVersion string            `json:"fixed_version,omitempty"`
+	Urgency      string            `json:"urgency,omitempty"`
+	NoDSA        string            `json:"nodsa,omitempty"`
+	NoDSAReason  string            `json:"nodsa_reason,omitempty"`
+}
+
+// Fixed returns whether the package has been fixed on the release
+func (r *Release) Fixed() bool {
+	return r.Status == StatusResolved && r.FixedVersion != ""
+}
+
+// NotAffected returns whether the package was never affected on the release
+func (r *Release) NotAffected() bool {
+	return r.Fixed() && r.FixedVersion == notAffectedVersion
+}
+
+// Vulnerability is a single cve with its status in all affected source packages
+type Vulnerability struct {
+	CVEID    string          `json:"id"`
+	Packages map[string]*CVE `json:"packages"`
+}
+
+// Vulnerabilities regroups tracker data by cve id
+func (t Tracker) Vulnerabilities() map[string]*Vulnerability {
+	vulns := make(map[string]*Vulnerability)
+	for pkg, cves := range t {
+		for id, cve := range cves {
+			vuln, ok := vulns[id]
+			if !ok {
+				vuln = &Vulnerability{CVEID: id, Packages: make(map[string]*CVE)}
+				vulns[id] = vuln
+			}
+			vuln.Packages[pkg] = cve
+		}
+	}
+	return vulns
+}
@@ -0,0 +1,51 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/facebookincubator/nvdtools/providers/epss"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+)
+
+const scoresPath = "/epss_scores-current.csv.gz"
+
+// Client downloads EPSS scores
+type Client struct {
+	client.Client
+	baseURL string
+}
+
+// NewClient creates an object which is used to download EPSS scores
+// base url should point to the location of the scores, e.g. https://epss.cyentia.com
+func NewClient(c client.Client, baseURL string) *Client {
+	return &Client{
+		Client:  c,
+		baseURL: baseURL,
+	}
+}
+
+// FetchScores downloads the latest scores of all CVEs
+func (c *Client) FetchScores(ctx context.Context) (*epss.Scores, error) {
+	resp, err := client.Get(ctx, c, c.baseURL+scoresPath, http.Header{})
+	if err != nil {
+		return nil, fmt.Errorf("can't download scores: %v", err)
+	}
+	defer resp.Body.Close()
+	return epss.ReadScores(resp.Body)
+}
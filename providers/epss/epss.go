@@ -0,0 +1,171 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package epss
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+)
+
+// Score is the EPSS score of a single CVE
+type Score struct {
+	// probability of exploitation in the next 30 days
+	EPSS float64
+	// percentile of the score among all scored CVEs
+	Percentile float64
+}
+
+// Scores holds EPSS scores published by FIRST on a single day
+type Scores struct {
+	ModelVersion string
+	// date the scores were computed at, YYYY-MM-DD
+	Date   string
+	Scores map[string]*Score
+}
+
+// LoadScores loads scores from the given file
+// file is the CSV published by FIRST, it can be gzipped
+func LoadScores(path string) (*Scores, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open %q: %v", path, err)
+	}
+	defer f.Close()
+	return ReadScores(f)
+}
+
+// ReadScores reads scores in the format supported by LoadScores
+//
+// Example of the file:
+//
+//	#model_version:v2023.03.01,score_date:2023-10-12T00:00:00+0000
+//	cve,epss,percentile
+//	CVE-1999-0001,0.0109,0.83093
+func ReadScores(r io.Reader) (*Scores, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, fmt.Errorf("can't create gzip reader: %v", err)
+		}
+		defer gr.Close()
+		br = bufio.NewReader(gr)
+	}
+
+	scores := Scores{Scores: make(map[string]*Score)}
+
+	// optional comment with the model version and the score date
+	if c, err := br.Peek(1); err == nil && c[0] == '#' {
+		line, err := br.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("can't read header: %v", err)
+		}
+		scores.parseComment(line)
+	}
+
+	cr := csv.NewReader(br)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err == io.EOF {
+		return &scores, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't read header: %v", err)
+	}
+	cols := map[string]int{"cve": -1, "epss": -1, "percentile": -1}
+	for i, name := range header {
+		if _, ok := cols[name]; ok {
+			cols[name] = i
+		}
+	}
+	if cols["cve"] < 0 || cols["epss"] < 0 {
+		return nil, fmt.Errorf("header %q doesn't contain cve and epss columns", strings.Join(header, ","))
+	}
+
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("can't read scores: %v", err)
+		}
+		line, _ := cr.FieldPos(0)
+		if len(rec) < len(header) {
+			return nil, fmt.Errorf("line %d: expecting %d fields, got %d", line, len(header), len(rec))
+		}
+		var score Score
+		if score.EPSS, err = strconv.ParseFloat(rec[cols["epss"]], 64); err != nil {
+			return nil, fmt.Errorf("line %d: can't parse score: %v", line, err)
+		}
+		if i := cols["percentile"]; i >= 0 {
+			if score.Percentile, err = strconv.ParseFloat(rec[i], 64); err != nil {
+				return nil, fmt.Errorf("line %d: can't parse percentile: %v", line, err)
+			}
+		}
+		scores.Scores[rec[cols["cve"]]] = &score
+	}
+
+	return &scores, nil
+}
+
+func (s *Scores) parseComment(line string) {
+	for _, field := range strings.Split(strings.TrimSpace(strings.TrimPrefix(line, "#")), ",") {
+		kv := strings.SplitN(field, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "model_version":
+			s.ModelVersion = kv[1]
+		case "score_date":
+			// only the date is interesting, scores are published daily
+			s.Date = strings.SplitN(kv[1], "T", 2)[0]
+		}
+	}
+}
+
+// Get returns the score of the given CVE, nil if it's not scored
+func (s *Scores) Get(cve string) *Score {
+	return s.Scores[cve]
+}
+
+// Annotate sets the EPSS score on items which were scored
+// returns the number of annotated items
+func (s *Scores) Annotate(items []*nvd.NVDCVEFeedJSON10DefCVEItem) int {
+	var n int
+	for _, item := range items {
+		if item == nil || item.CVE == nil || item.CVE.CVEDataMeta == nil {
+			continue
+		}
+		if score := s.Get(item.CVE.CVEDataMeta.ID); score != nil {
+			item.EPSS = &nvd.NVDCVEFeedJSON10DefEPSS{
+				Score:      score.EPSS,
+				Percentile: score.Percentile,
+				Date:       s.Date,
+			}
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,82 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package epss
+
+import (
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+)
+
+const testScores = `#model_version:v2023.03.01,score_date:2023-10-12T00:00:00+0000
+cve,epss,percentile
+CVE-1999-0001,0.0109,0.83093
+CVE-2021-44228,0.97565,0.99996
+`
+
+func TestReadScores(t *testing.T) {
+	var gzipped bytes.Buffer
+	gw := gzip.NewWriter(&gzipped)
+	gw.Write([]byte(testScores))
+	gw.Close()
+
+	for name, data := range map[string]string{"plain": testScores, "gzip": gzipped.String()} {
+		t.Run(name, func(t *testing.T) {
+			scores, err := ReadScores(strings.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if scores.ModelVersion != "v2023.03.01" || scores.Date != "2023-10-12" {
+				t.Fatalf("wrong header %q %q", scores.ModelVersion, scores.Date)
+			}
+			if len(scores.Scores) != 2 {
+				t.Fatalf("expecting 2 scores, got %d", len(scores.Scores))
+			}
+			if s := scores.Get("CVE-2021-44228"); s == nil || s.EPSS != 0.97565 || s.Percentile != 0.99996 {
+				t.Fatalf("wrong score %+v", s)
+			}
+		})
+	}
+
+	if _, err := ReadScores(strings.NewReader("cve,epss\nCVE-1999-0001,high\n")); err == nil {
+		t.Fatal("expecting an error for a malformed score")
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	scores, err := ReadScores(strings.NewReader(testScores))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newItem := func(id string) *nvd.NVDCVEFeedJSON10DefCVEItem {
+		return &nvd.NVDCVEFeedJSON10DefCVEItem{
+			CVE: &nvd.CVEJSON40{CVEDataMeta: &nvd.CVEJSON40CVEDataMeta{ID: id}},
+		}
+	}
+	items := []*nvd.NVDCVEFeedJSON10DefCVEItem{newItem("CVE-1999-0001"), newItem("CVE-2020-0001")}
+	if n := scores.Annotate(items); n != 1 {
+		t.Fatalf("expecting 1 annotated item, got %d", n)
+	}
+	if e := items[0].EPSS; e == nil || e.Score != 0.0109 || e.Percentile != 0.83093 || e.Date != "2023-10-12" {
+		t.Fatalf("wrong annotation %+v", e)
+	}
+	if items[1].EPSS != nil {
+		t.Fatalf("item shouldn't be annotated")
+	}
+}
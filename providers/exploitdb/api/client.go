@@ -0,0 +1,93 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/exploitdb/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+)
+
+const exploitsPath = "/files_exploits.csv"
+
+// MetasploitURL is the location of Metasploit modules metadata
+const MetasploitURL = "https://raw.githubusercontent.com/rapid7/metasploit-framework/master/db/modules_metadata_base.json"
+
+// Client downloads public exploits
+type Client struct {
+	client.Client
+	baseURL string
+}
+
+// NewClient creates an object which is used to download public exploits
+// base url should point to the root of Exploit-DB repository, e.g. https://gitlab.com/exploit-database/exploitdb/-/raw/main
+func NewClient(c client.Client, baseURL string) *Client {
+	return &Client{
+		Client:  c,
+		baseURL: baseURL,
+	}
+}
+
+// FetchAllVulnerabilities returns CVEs with any exploit published or updated since the given time
+// Metasploit modules are downloaded from the given url as well, unless it's empty
+// all exploits of such CVEs are returned, not only the updated ones
+func (c *Client) FetchAllVulnerabilities(ctx context.Context, since int64, metasploitURL string) (<-chan runner.Convertible, error) {
+	idx := make(schema.Index)
+
+	exploits, err := c.fetch(ctx, c.baseURL+exploitsPath, schema.ReadExploitDB)
+	if err != nil {
+		return nil, fmt.Errorf("can't fetch exploit-db exploits: %v", err)
+	}
+	idx.Add(exploits...)
+
+	if metasploitURL != "" {
+		exploits, err := c.fetch(ctx, metasploitURL, schema.ReadMetasploit)
+		if err != nil {
+			return nil, fmt.Errorf("can't fetch metasploit modules: %v", err)
+		}
+		idx.Add(exploits...)
+	}
+
+	var sinceDate string
+	if since > 0 {
+		sinceDate = time.Unix(since, 0).UTC().Format("2006-01-02")
+	}
+
+	output := make(chan runner.Convertible)
+	go func() {
+		defer close(output)
+		for _, vuln := range idx.Vulnerabilities() {
+			if vuln.Modified() >= sinceDate {
+				output <- vuln
+			}
+		}
+	}()
+	return output, nil
+}
+
+func (c *Client) fetch(ctx context.Context, url string, read func(r io.Reader) ([]*schema.Exploit, error)) ([]*schema.Exploit, error) {
+	resp, err := client.Get(ctx, c, url, http.Header{})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return read(resp.Body)
+}
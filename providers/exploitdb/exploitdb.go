@@ -0,0 +1,105 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exploitdb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/facebookincubator/nvdtools/providers/exploitdb/schema"
+)
+
+// LoadIndex loads exploits from the given files and indexes them by CVE
+// file can be either Exploit-DB files_exploits.csv, Metasploit modules_metadata_base.json
+// or the output of exploitdb2nvd before conversion
+func LoadIndex(paths ...string) (schema.Index, error) {
+	idx := make(schema.Index)
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("can't open %q: %v", path, err)
+		}
+		err = ReadIndex(f, idx)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("can't read %q: %v", path, err)
+		}
+	}
+	return idx, nil
+}
+
+// ReadIndex reads exploits in any of the formats supported by LoadIndex and adds them to the index
+func ReadIndex(r io.Reader, idx schema.Index) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("can't read exploits: %v", err)
+	}
+
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		exploits, err := schema.ReadExploitDB(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		idx.Add(exploits...)
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("can't decode exploits: %v", err)
+	}
+
+	var modules map[string]*schema.MetasploitModule
+	var vulns map[string]*schema.Vulnerability
+	for _, raw := range fields {
+		var probe struct {
+			Fullname string `json:"fullname"`
+		}
+		if err := json.Unmarshal(raw, &probe); err != nil {
+			return fmt.Errorf("can't decode exploits: %v", err)
+		}
+		if probe.Fullname != "" {
+			modules = make(map[string]*schema.MetasploitModule, len(fields))
+		} else {
+			vulns = make(map[string]*schema.Vulnerability, len(fields))
+		}
+		break
+	}
+
+	switch {
+	case modules != nil:
+		if err := json.Unmarshal(data, &modules); err != nil {
+			return fmt.Errorf("can't decode modules: %v", err)
+		}
+		idx.Add(schema.MetasploitExploits(modules)...)
+	case vulns != nil:
+		if err := json.Unmarshal(data, &vulns); err != nil {
+			return fmt.Errorf("can't decode vulnerabilities: %v", err)
+		}
+		for cve, vuln := range vulns {
+			// an exploit is listed under each cve it exploits, add it only under this one
+			for _, exploit := range vuln.Exploits {
+				e := *exploit
+				e.CVEs = []string{cve}
+				idx.Add(&e)
+			}
+		}
+	}
+	return nil
+}
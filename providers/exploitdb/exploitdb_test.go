@@ -0,0 +1,114 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exploitdb
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/providers/exploitdb/schema"
+)
+
+const testExploitDB = `id,file,description,date_published,author,type,platform,port,date_added,date_updated,verified,codes,tags,aliases,screenshot_url,application_url,source_url
+50592,exploits/java/remote/50592.py,"Apache Log4j 2 - Remote Code Execution (RCE)",2021-12-14,kozmer,remote,java,,2021-12-14,2021-12-14,0,CVE-2021-44228,,,,,
+16,exploits/linux/local/16.c,"Linux Kernel - Local Root",2000-11-16,anonymous,local,linux,,2000-11-16,2000-11-16,1,,,,,,
+`
+
+const testMetasploit = `{
+  "exploit_multi/http/log4shell_header_injection": {
+    "name": "Log4Shell HTTP Header Injection",
+    "fullname": "exploit/multi/http/log4shell_header_injection",
+    "type": "exploit",
+    "platform": "Java,Linux,Unix",
+    "disclosure_date": "2021-12-09",
+    "mod_time": "2023-08-01 16:59:48 +0000",
+    "path": "/modules/exploits/multi/http/log4shell_header_injection.rb",
+    "rank": 600,
+    "references": ["CVE-2021-44228", "CVE-2021-45046", "URL-https://logging.apache.org/log4j/2.x/security.html"]
+  }
+}`
+
+func TestReadIndex(t *testing.T) {
+	idx := make(schema.Index)
+	if err := ReadIndex(strings.NewReader(testExploitDB), idx); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReadIndex(strings.NewReader(testMetasploit), idx); err != nil {
+		t.Fatal(err)
+	}
+	if len(idx) != 2 {
+		t.Fatalf("expecting 2 vulnerabilities, got %d", len(idx))
+	}
+	vuln := idx["CVE-2021-44228"]
+	if vuln == nil || len(vuln.Exploits) != 2 {
+		t.Fatalf("expecting 2 exploits of CVE-2021-44228, got %+v", vuln)
+	}
+	if e := vuln.Exploits[0]; e.ID != "EDB-50592" || e.URL != "https://www.exploit-db.com/exploits/50592" || e.Verified {
+		t.Fatalf("wrong exploit-db exploit %+v", e)
+	}
+	if e := vuln.Exploits[1]; e.ID != "exploit/multi/http/log4shell_header_injection" || e.DateUpdated != "2023-08-01" {
+		t.Fatalf("wrong metasploit exploit %+v", e)
+	}
+
+	// the output of exploitdb2nvd
+	data, err := json.Marshal(idx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idx2 := make(schema.Index)
+	if err := ReadIndex(strings.NewReader(string(data)), idx2); err != nil {
+		t.Fatal(err)
+	}
+	if len(idx2) != 2 || len(idx2["CVE-2021-44228"].Exploits) != 2 || len(idx2["CVE-2021-45046"].Exploits) != 1 {
+		t.Fatalf("wrong index after reading it back: %s", data)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	idx := make(schema.Index)
+	if err := ReadIndex(strings.NewReader(testExploitDB), idx); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReadIndex(strings.NewReader(testMetasploit), idx); err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := idx["CVE-2021-44228"].Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.PublishedDate != "2021-12-09T00:00Z" || item.LastModifiedDate != "2023-08-01T00:00Z" {
+		t.Fatalf("wrong dates %q %q", item.PublishedDate, item.LastModifiedDate)
+	}
+	if len(item.Exploits) != 2 || len(item.CVE.References.ReferenceData) != 2 {
+		t.Fatalf("expecting 2 exploits and references, got %d and %d", len(item.Exploits), len(item.CVE.References.ReferenceData))
+	}
+
+	items := []*nvd.NVDCVEFeedJSON10DefCVEItem{
+		{CVE: &nvd.CVEJSON40{CVEDataMeta: &nvd.CVEJSON40CVEDataMeta{ID: "CVE-2021-45046"}}},
+		{CVE: &nvd.CVEJSON40{CVEDataMeta: &nvd.CVEJSON40CVEDataMeta{ID: "CVE-2020-0001"}}},
+	}
+	if n := idx.Annotate(items); n != 1 {
+		t.Fatalf("expecting 1 annotated item, got %d", n)
+	}
+	if e := items[0].Exploits; len(e) != 1 || e[0].Source != schema.SourceMetasploit {
+		t.Fatalf("wrong annotation %+v", e)
+	}
+	if items[1].Exploits != nil {
+		t.Fatalf("item shouldn't be annotated")
+	}
+}
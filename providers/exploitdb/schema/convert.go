@@ -0,0 +1,119 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"strings"
+	"time"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
+)
+
+const (
+	cveDataVersion = "4.0"
+	dateLayout     = "2006-01-02"
+)
+
+// ID is a part of the runner.Convertible interface
+func (vuln *Vulnerability) ID() string {
+	return vuln.CVE
+}
+
+// Convert is a part of the runner.Convertible interface
+// exploits don't say which products are affected, so the item has no configurations
+// and is useful mostly to annotate other feeds
+func (vuln *Vulnerability) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	descriptions := make([]string, 0, len(vuln.Exploits))
+	published := ""
+	for _, exploit := range vuln.Exploits {
+		descriptions = append(descriptions, exploit.Description)
+		if published == "" || (exploit.DatePublished != "" && exploit.DatePublished < published) {
+			published = exploit.DatePublished
+		}
+	}
+
+	item := nvd.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &nvd.CVEJSON40{
+			CVEDataMeta: &nvd.CVEJSON40CVEDataMeta{
+				ID:       vuln.ID(),
+				ASSIGNER: "exploit-db.com",
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: cveDataVersion,
+			Description: &nvd.CVEJSON40Description{
+				DescriptionData: []*nvd.CVEJSON40LangString{
+					{
+						Lang:  "en",
+						Value: "Public exploits: " + strings.Join(descriptions, "; "),
+					},
+				},
+			},
+			References: vuln.newReferences(),
+		},
+		Configurations: &nvd.NVDCVEFeedJSON10DefConfigurations{
+			CVEDataVersion: cveDataVersion,
+		},
+		Exploits:         vuln.NVDExploits(),
+		LastModifiedDate: exploitDateToNVD(vuln.Modified()),
+		PublishedDate:    exploitDateToNVD(published),
+	}
+
+	return &item, nil
+}
+
+// NVDExploits returns exploits which are set on NVD items of the vulnerability
+func (vuln *Vulnerability) NVDExploits() []*nvd.NVDCVEFeedJSON10DefExploit {
+	exploits := make([]*nvd.NVDCVEFeedJSON10DefExploit, 0, len(vuln.Exploits))
+	for _, exploit := range vuln.Exploits {
+		exploits = append(exploits, &nvd.NVDCVEFeedJSON10DefExploit{
+			Source:        exploit.Source,
+			ID:            exploit.ID,
+			Description:   exploit.Description,
+			URL:           exploit.URL,
+			DatePublished: exploit.DatePublished,
+			Verified:      exploit.Verified,
+		})
+	}
+	return exploits
+}
+
+func (vuln *Vulnerability) newReferences() *nvd.CVEJSON40References {
+	refs := &nvd.CVEJSON40References{
+		ReferenceData: make([]*nvd.CVEJSON40Reference, 0, len(vuln.Exploits)),
+	}
+	for _, exploit := range vuln.Exploits {
+		refs.ReferenceData = append(refs.ReferenceData, &nvd.CVEJSON40Reference{
+			Name:      exploit.ID,
+			Refsource: exploit.Source,
+			Tags:      []string{"Exploit"},
+			URL:       exploit.URL,
+		})
+	}
+	return refs
+}
+
+func exploitDateToNVD(s string) string {
+	if s == "" {
+		return ""
+	}
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		logging.Recordf("can't parse exploit date: %v", err)
+		return s
+	}
+	return t.Format(nvd.TimeLayout)
+}
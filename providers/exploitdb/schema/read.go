@@ -0,0 +1,145 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	exploitDBURL  = "https://www.exploit-db.com/exploits/"
+	metasploitURL = "https://github.com/rapid7/metasploit-framework/blob/master"
+)
+
+// ReadExploitDB reads exploits from Exploit-DB files_exploits.csv
+// exploits which don't reference any CVE are skipped
+func ReadExploitDB(r io.Reader) ([]*Exploit, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("can't read header: %v", err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, name := range header {
+		cols[name] = i
+	}
+	for _, name := range []string{"id", "description", "codes"} {
+		if _, ok := cols[name]; !ok {
+			return nil, fmt.Errorf("header doesn't contain %s column", name)
+		}
+	}
+
+	var exploits []*Exploit
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("can't read exploits: %v", err)
+		}
+		get := func(name string) string {
+			if i, ok := cols[name]; ok && i < len(rec) {
+				return rec[i]
+			}
+			return ""
+		}
+		cves := cveIDs(strings.Split(get("codes"), ";"))
+		if len(cves) == 0 {
+			continue
+		}
+		exploits = append(exploits, &Exploit{
+			Source:        SourceExploitDB,
+			ID:            "EDB-" + get("id"),
+			Description:   get("description"),
+			URL:           exploitDBURL + get("id"),
+			Type:          get("type"),
+			Platform:      get("platform"),
+			DatePublished: get("date_published"),
+			DateUpdated:   get("date_updated"),
+			Verified:      get("verified") == "1",
+			CVEs:          cves,
+		})
+	}
+	return exploits, nil
+}
+
+// MetasploitModule is an entry of Metasploit db/modules_metadata_base.json
+type MetasploitModule struct {
+	Name           string   `json:"name"`
+	Fullname       string   `json:"fullname"`
+	Type           string   `json:"type"`
+	Platform       string   `json:"platform"`
+	DisclosureDate string   `json:"disclosure_date"`
+	ModTime        string   `json:"mod_time"`
+	Path           string   `json:"path"`
+	Rank           int      `json:"rank"`
+	References     []string `json:"references"`
+}
+
+// ReadMetasploit reads exploits from Metasploit modules metadata
+// modules which don't reference any CVE are skipped
+func ReadMetasploit(r io.Reader) ([]*Exploit, error) {
+	var modules map[string]*MetasploitModule
+	if err := json.NewDecoder(r).Decode(&modules); err != nil {
+		return nil, fmt.Errorf("can't decode modules: %v", err)
+	}
+	return MetasploitExploits(modules), nil
+}
+
+// MetasploitExploits converts modules into exploits, sorted by their names
+func MetasploitExploits(modules map[string]*MetasploitModule) []*Exploit {
+	var exploits []*Exploit
+	for _, module := range modules {
+		if exploit := module.Exploit(); exploit != nil {
+			exploits = append(exploits, exploit)
+		}
+	}
+	sort.Slice(exploits, func(i, j int) bool {
+		return exploits[i].ID < exploits[j].ID
+	})
+	return exploits
+}
+
+// Exploit returns the exploit of the module, nil if it doesn't reference any CVE
+func (module *MetasploitModule) Exploit() *Exploit {
+	cves := cveIDs(module.References)
+	if len(cves) == 0 {
+		return nil
+	}
+	exploit := Exploit{
+		Source:        SourceMetasploit,
+		ID:            module.Fullname,
+		Description:   module.Name,
+		Type:          module.Type,
+		Platform:      module.Platform,
+		DatePublished: module.DisclosureDate,
+		// mod time is like 2023-08-01 16:59:48 +0000
+		DateUpdated: strings.SplitN(module.ModTime, " ", 2)[0],
+		// modules are reviewed before they are merged
+		Verified: true,
+		CVEs:     cves,
+	}
+	if module.Path != "" {
+		exploit.URL = metasploitURL + module.Path
+	}
+	return &exploit
+}
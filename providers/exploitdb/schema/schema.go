@@ -0,0 +1,118 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"sort"
+	"strings"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+)
+
+// sources of exploits
+const (
+	SourceExploitDB  = "exploit-db"
+	SourceMetasploit = "metasploit"
+)
+
+// Exploit is a public exploit of one or more CVEs
+type Exploit struct {
+	Source        string   `json:"source"`
+	ID            string   `json:"id"`
+	Description   string   `json:"description,omitempty"`
+	URL           string   `json:"url,omitempty"`
+	Type          string   `json:"type,omitempty"`
+	Platform      string   `json:"platform,omitempty"`
+	DatePublished string   `json:"date_published,omitempty"`
+	DateUpdated   string   `json:"date_updated,omitempty"`
+	Verified      bool     `json:"verified,omitempty"`
+	CVEs          []string `json:"cves,omitempty"`
+}
+
+// Vulnerability is a CVE with all of its known exploits
+type Vulnerability struct {
+	CVE      string     `json:"cve"`
+	Exploits []*Exploit `json:"exploits"`
+}
+
+// Index holds vulnerabilities by their CVE ids
+type Index map[string]*Vulnerability
+
+// Add adds exploits to vulnerabilities of all CVEs they exploit
+func (idx Index) Add(exploits ...*Exploit) {
+	for _, exploit := range exploits {
+		for _, cve := range exploit.CVEs {
+			vuln, ok := idx[cve]
+			if !ok {
+				vuln = &Vulnerability{CVE: cve}
+				idx[cve] = vuln
+			}
+			vuln.Exploits = append(vuln.Exploits, exploit)
+		}
+	}
+}
+
+// Vulnerabilities returns all vulnerabilities sorted by CVE
+func (idx Index) Vulnerabilities() []*Vulnerability {
+	vulns := make([]*Vulnerability, 0, len(idx))
+	for _, vuln := range idx {
+		vulns = append(vulns, vuln)
+	}
+	sort.Slice(vulns, func(i, j int) bool {
+		return vulns[i].CVE < vulns[j].CVE
+	})
+	return vulns
+}
+
+// Annotate sets exploits on items which have any
+// returns the number of annotated items
+func (idx Index) Annotate(items []*nvd.NVDCVEFeedJSON10DefCVEItem) int {
+	var n int
+	for _, item := range items {
+		if item == nil || item.CVE == nil || item.CVE.CVEDataMeta == nil {
+			continue
+		}
+		if vuln, ok := idx[item.CVE.CVEDataMeta.ID]; ok {
+			item.Exploits = vuln.NVDExploits()
+			n++
+		}
+	}
+	return n
+}
+
+// Modified returns the latest date when any of the exploits was published or updated
+func (vuln *Vulnerability) Modified() string {
+	var modified string
+	for _, exploit := range vuln.Exploits {
+		for _, date := range []string{exploit.DatePublished, exploit.DateUpdated} {
+			if date > modified {
+				modified = date
+			}
+		}
+	}
+	return modified
+}
+
+// cveIDs extracts CVE ids from a list of references, like CVE-2021-44228 or OSVDB-1234
+func cveIDs(refs []string) []string {
+	var cves []string
+	for _, ref := range refs {
+		ref = strings.TrimSpace(ref)
+		if strings.HasPrefix(ref, "CVE-") {
+			cves = append(cves, ref)
+		}
+	}
+	return cves
+}
@@ -0,0 +1,53 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/fireeye/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+)
+
+// LegacyBaseURL is the base url of the legacy iSIGHT API, which is used if it's set as base url
+const LegacyBaseURL = "https://api.isightpartners.com"
+
+func init() {
+	runner.Register(runner.Registration{
+		Name:        "fireeye",
+		BaseURL:     AdvantageBaseURL,
+		Env:         []string{"FIREEYE_PUBLIC_KEY", "FIREEYE_PRIVATE_KEY"},
+		OptionalEnv: []string{"FIREEYE_APP_NAME"},
+		New: func(c client.Client, baseURL string, env map[string]string) (runner.Provider, error) {
+			if baseURL == LegacyBaseURL {
+				client := NewClient(c, baseURL, env["FIREEYE_PUBLIC_KEY"], env["FIREEYE_PRIVATE_KEY"])
+				return runner.ConvertibleProvider(func(ctx context.Context, since time.Time) (<-chan runner.Convertible, error) {
+					return client.FetchAllVulnerabilities(ctx, since.Unix())
+				}), nil
+			}
+			appName := env["FIREEYE_APP_NAME"]
+			if appName == "" {
+				appName = "nvdtools"
+			}
+			client := NewClientV4(c, baseURL, env["FIREEYE_PUBLIC_KEY"], env["FIREEYE_PRIVATE_KEY"], appName)
+			return runner.ConvertibleProvider(func(ctx context.Context, since time.Time) (<-chan runner.Convertible, error) {
+				return client.FetchAllVulnerabilities(ctx, since.Unix())
+			}), nil
+		},
+		Read: runner.ReadMap(func() runner.Convertible { return new(schema.Vulnerability) }),
+	})
+}
@@ -0,0 +1,187 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/fireeye/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+	"github.com/facebookincubator/nvdtools/stats"
+)
+
+const (
+	// AdvantageBaseURL is the base url of the Mandiant Advantage threat intelligence API
+	AdvantageBaseURL = "https://api.intelligence.mandiant.com"
+
+	v4PageSize = 1000
+	// maxRateLimitWaits is the number of times a rate limited request is retried
+	maxRateLimitWaits = 5
+)
+
+// defaultRetryAfter is how long to wait after being rate limited if the API doesn't say
+var defaultRetryAfter = time.Minute
+
+// ClientV4 queries the Mandiant Advantage v4 API
+type ClientV4 struct {
+	client.Client
+	baseURL string
+	appName string
+	tokens  oauth2.TokenSource
+}
+
+// NewClientV4 creates an object which is used to query the Mandiant Advantage v4 API
+// requests are authorized with bearer tokens obtained with the API key and secret as OAuth client credentials;
+// appName is sent in the X-App-Name header, as required by the API
+func NewClientV4(c client.Client, baseURL, apiKey, secret, appName string) *ClientV4 {
+	conf := &clientcredentials.Config{
+		ClientID:     apiKey,
+		ClientSecret: secret,
+		TokenURL:     baseURL + "/token",
+		AuthStyle:    oauth2.AuthStyleInHeader,
+	}
+	ctx := context.Background()
+	if hc, ok := client.Default().(*http.Client); ok {
+		// fetch tokens through the configured transport (proxy, certificates, ...)
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, hc)
+	}
+	return &ClientV4{
+		Client:  c,
+		baseURL: baseURL,
+		appName: appName,
+		tokens:  conf.TokenSource(ctx),
+	}
+}
+
+// FetchAllVulnerabilities will fetch all vulnerabilities modified since the given time
+// vulnerabilities are returned in the legacy format, so they're converted the same way
+func (c *ClientV4) FetchAllVulnerabilities(ctx context.Context, since int64) (<-chan runner.Convertible, error) {
+	parameters := newParametersSince(since)
+	if err := parameters.validate(); err != nil {
+		return nil, err
+	}
+	windows := parameters.batchBy(ninetyDays)
+
+	// fetch the first page synchronously so we can fail early on wrong credentials etc.
+	page, err := c.fetchVulnerabilities(ctx, windows[0], "")
+	if err != nil {
+		return nil, err
+	}
+
+	output := make(chan runner.Convertible)
+	go func() {
+		defer close(output)
+		for i := 0; i < len(windows); {
+			stats.IncrementCounterBy("vulnerabilities", int64(len(page.Vulnerabilities)))
+			for _, v := range page.Vulnerabilities {
+				output <- v.Legacy()
+			}
+			next := page.Next
+			if next == "" {
+				// done with this window
+				if i++; i == len(windows) {
+					return
+				}
+			}
+			if page, err = c.fetchVulnerabilities(ctx, windows[i], next); err != nil {
+				logging.Errorf("error while fetching %s: %v", windows[i], err)
+				return
+			}
+		}
+	}()
+
+	return output, nil
+}
+
+// fetchVulnerabilities fetches a page of vulnerabilities modified in the time window
+func (c *ClientV4) fetchVulnerabilities(ctx context.Context, params timeRangeParameters, next string) (*schema.VulnerabilitiesV4, error) {
+	query := url.Values{
+		"start_epoch": {strconv.FormatInt(params.StartDate, 10)},
+		"end_epoch":   {strconv.FormatInt(params.EndDate, 10)},
+		"limit":       {strconv.Itoa(v4PageSize)},
+	}
+	if next != "" {
+		query.Set("next", next)
+	}
+
+	var page schema.VulnerabilitiesV4
+	if err := c.get(ctx, "/v4/vulnerability?"+query.Encode(), &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// get fetches the endpoint and decodes the response into v, waiting and retrying if it's rate limited
+func (c *ClientV4) get(ctx context.Context, endpoint string, v interface{}) error {
+	for waits := 0; ; waits++ {
+		tok, err := c.tokens.Token()
+		if err != nil {
+			return errors.Wrap(err, "can't get oauth token")
+		}
+		req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+endpoint, nil)
+		if err != nil {
+			return errors.Wrap(err, "cannot create http get request")
+		}
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Authorization", tok.Type()+" "+tok.AccessToken)
+		req.Header.Set("X-App-Name", c.appName)
+
+		stats.IncrementCounter("request")
+		resp, err := c.Do(req)
+		if err != nil {
+			stats.IncrementCounter("request.error")
+			return errors.Wrap(err, "cannot get url")
+		}
+		stats.IncrementCounter(fmt.Sprintf("request.code.%d", resp.StatusCode))
+
+		if resp.StatusCode == http.StatusOK {
+			defer resp.Body.Close()
+			if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+				stats.IncrementCounter("request.feed.error")
+				return errors.Wrap(err, "couldn't decode result")
+			}
+			stats.IncrementCounter("request.success")
+			return nil
+		}
+
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusTooManyRequests || waits == maxRateLimitWaits {
+			return &client.Err{Code: resp.StatusCode, Status: resp.Status, Body: string(body)}
+		}
+
+		wait := client.RetryAfter(resp.Header, defaultRetryAfter)
+		logging.Infof("rate limited by mandiant, retrying in %v", wait)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/fireeye/schema"
+)
+
+func TestClientV4(t *testing.T) {
+	var tokens, limited int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if key, secret, _ := r.BasicAuth(); key != "key" || secret != "secret" {
+				http.Error(w, "bad credentials", http.StatusUnauthorized)
+				return
+			}
+			tokens++
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"access_token": "tok", "token_type": "Bearer", "expires_in": 3600}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer tok" || r.Header.Get("X-App-Name") != "test" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		q := r.URL.Query()
+		switch q.Get("next") {
+		case "":
+			fmt.Fprintf(w, `{"vulnerabilities": [{"id": "vulnerability--%s-1"}], "next": "p2"}`, q.Get("start_epoch"))
+		case "p2":
+			if limited == 0 {
+				limited++
+				w.Header().Set("Retry-After", "0")
+				http.Error(w, "slow down", http.StatusTooManyRequests)
+				return
+			}
+			fmt.Fprintf(w, `{"vulnerabilities": [{"id": "vulnerability--%s-2"}]}`, q.Get("start_epoch"))
+		}
+	}))
+	defer ts.Close()
+
+	c := NewClientV4(http.DefaultClient, ts.URL, "key", "secret", "test")
+	// two 90 days windows
+	since := time.Now().Add(-100 * 24 * time.Hour).Unix()
+	vulns, err := c.FetchAllVulnerabilities(context.Background(), since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for v := range vulns {
+		ids = append(ids, v.(*schema.Vulnerability).ReportID)
+	}
+	sort.Strings(ids)
+	second := since + ninetyDays + 1
+	want := []string{
+		fmt.Sprintf("vulnerability--%d-1", since),
+		fmt.Sprintf("vulnerability--%d-2", since),
+		fmt.Sprintf("vulnerability--%d-1", second),
+		fmt.Sprintf("vulnerability--%d-2", second),
+	}
+	if !reflect.DeepEqual(ids, want) {
+		t.Fatalf("expected %v, got %v", want, ids)
+	}
+	if tokens != 1 || limited != 1 {
+		t.Fatalf("expected one token and one rate limited request, got %d and %d", tokens, limited)
+	}
+
+	c = NewClientV4(http.DefaultClient, ts.URL, "key", "wrong", "test")
+	if _, err := c.FetchAllVulnerabilities(context.Background(), since); err == nil {
+		t.Fatal("expected an error with wrong credentials")
+	}
+}
@@ -18,8 +18,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/providers/fireeye/schema"
 	"github.com/facebookincubator/nvdtools/providers/lib/client"
 	"github.com/facebookincubator/nvdtools/providers/lib/runner"
@@ -40,13 +40,13 @@ func (c *Client) FetchAllVulnerabilities(ctx context.Context, since int64) (<-ch
 	for _, params := range parameters.batchBy(ninetyDays) {
 		params := params
 		eg.Go(func() error {
-			log.Printf("Fetching: %s\n", params)
+			logging.Infof("Fetching: %s", params)
 			vs, err := c.fetchVulnerabilities(ctx, params)
 			if err != nil {
 				return client.StopOrContinue(fmt.Errorf("error while fetching %s: %v", params, err))
 			}
 			numVulns := len(vs)
-			log.Printf("Adding %d vulns\n", numVulns)
+			logging.Infof("Adding %d vulns", numVulns)
 			stats.IncrementCounterBy("vulnerabilities", int64(numVulns))
 			for _, v := range vs {
 				output <- v
@@ -57,7 +57,7 @@ func (c *Client) FetchAllVulnerabilities(ctx context.Context, since int64) (<-ch
 
 	go func() {
 		if err := eg.Wait(); err != nil {
-			log.Println(err)
+			logging.Errorf("%v", err)
 		}
 		close(output)
 	}()
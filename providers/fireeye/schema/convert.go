@@ -54,6 +54,15 @@ func (item *Vulnerability) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
 		LastModifiedDate: convertTime(item.PublishDate),
 		PublishedDate:    convertTime(item.Version1PublishDate),
 	}
+	if item.CvssV3BaseVector != "" {
+		nvdItem.Impact.BaseMetricV3 = &nvd.NVDCVEFeedJSON10DefImpactBaseMetricV3{
+			CVSSV3: &nvd.CVSSV30{
+				BaseScore:     strToFloat(item.CvssV3BaseScore),
+				TemporalScore: strToFloat(item.CvssV3TemporalScore),
+				VectorString:  item.CvssV3BaseVector,
+			},
+		}
+	}
 
 	return &nvdItem, nil
 }
@@ -71,8 +80,12 @@ func (item *Vulnerability) makeReferences() *nvd.CVEJSON40References {
 		})
 	}
 
-	addRef("FireEye report API link", item.ReportLink)
-	addRef("FireEye web link", item.WebLink)
+	if item.ReportLink != "" {
+		addRef("FireEye report API link", item.ReportLink)
+	}
+	if item.WebLink != "" {
+		addRef("FireEye web link", item.WebLink)
+	}
 	for _, cve := range item.CVEIds {
 		for _, cveid := range strings.Split(cve, ",") {
 			addRef(cveid, "")
@@ -15,12 +15,12 @@
 package schema
 
 import (
-	"log"
 	"strconv"
 	"strings"
 	"time"
 
 	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
 )
 
 func extractCVSSBaseScore(item *Vulnerability) float64 {
@@ -44,9 +44,12 @@ func convertTime(fireeyeTime int64) string {
 }
 
 func strToFloat(str string) float64 {
+	if str == "" {
+		return 0
+	}
 	f, err := strconv.ParseFloat(str, 64)
 	if err != nil {
-		log.Println(err)
+		logging.Recordf("%v", err)
 		f = float64(0)
 	}
 	return f
@@ -0,0 +1,143 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// AdvantageWebURL is where vulnerabilities are shown in Mandiant Advantage
+const AdvantageWebURL = "https://advantage.mandiant.com/vulnerabilities/"
+
+// VulnerabilitiesV4 is a page of vulnerabilities returned by the Mandiant Advantage v4 API
+type VulnerabilitiesV4 struct {
+	Vulnerabilities []*VulnerabilityV4 `json:"vulnerabilities"`
+	// Next is the token of the next page, it's empty on the last one
+	Next string `json:"next,omitempty"`
+}
+
+// VulnerabilityV4 is a vulnerability returned by the Mandiant Advantage v4 API
+type VulnerabilityV4 struct {
+	ID                        string                  `json:"id"`
+	Name                      string                  `json:"name"`
+	Title                     string                  `json:"title"`
+	Description               string                  `json:"description"`
+	CVEID                     string                  `json:"cve_id"`
+	PublishDate               string                  `json:"publish_date"`
+	LastModifiedDate          string                  `json:"last_modified_date"`
+	RiskRating                string                  `json:"risk_rating"`
+	ExploitationState         string                  `json:"exploitation_state"`
+	Audience                  []string                `json:"audience"`
+	CommonVulnerabilityScores map[string]*CVSSScoreV4 `json:"common_vulnerability_scores"`
+	VulnerableCPEs            []*VulnerableCPEV4      `json:"vulnerable_cpes"`
+	Workarounds               string                  `json:"workarounds"`
+}
+
+// CVSSScoreV4 is a CVSS score of a v4 vulnerability
+type CVSSScoreV4 struct {
+	BaseScore     float64 `json:"base_score"`
+	TemporalScore float64 `json:"temporal_score"`
+	VectorString  string  `json:"vector_string"`
+}
+
+// VulnerableCPEV4 is a CPE affected by a v4 vulnerability
+type VulnerableCPEV4 struct {
+	CPE            string `json:"cpe"`
+	CPETitle       string `json:"cpe_title"`
+	TechnologyName string `json:"technology_name"`
+	VendorName     string `json:"vendor_name"`
+}
+
+// Legacy returns the vulnerability in the legacy API format, so it's downloaded and converted the same way
+func (v *VulnerabilityV4) Legacy() *Vulnerability {
+	legacy := &Vulnerability{
+		Audience:            v.Audience,
+		ExploitInTheWild:    strings.EqualFold(v.ExploitationState, "wild"),
+		ExploitRating:       v.ExploitationState,
+		IntelligenceType:    "vulnerability",
+		PublishDate:         parseTimeV4(v.LastModifiedDate),
+		ReportID:            v.ID,
+		RiskRating:          v.RiskRating,
+		Title:               v.Title,
+		Version1PublishDate: parseTimeV4(v.PublishDate),
+		WebLink:             AdvantageWebURL + v.ID,
+	}
+	if legacy.Title == "" {
+		legacy.Title = v.Name
+	}
+	if legacy.PublishDate == 0 {
+		legacy.PublishDate = legacy.Version1PublishDate
+	}
+	if v.CVEID != "" {
+		legacy.CVEIds = []string{v.CVEID}
+	}
+	if v.Workarounds != "" {
+		legacy.Mitigations = []string{v.Workarounds}
+	}
+
+	cpes := make([]string, 0, len(v.VulnerableCPEs))
+	for _, cpe := range v.VulnerableCPEs {
+		if cpe.CPE != "" {
+			cpes = append(cpes, cpe.CPE)
+		}
+	}
+	legacy.CPE = strings.Join(cpes, ",")
+
+	if score := v.latestScore("v2"); score != nil {
+		legacy.CvssBaseScore = formatScore(score.BaseScore)
+		legacy.CvssBaseVector = score.VectorString
+		legacy.CvssTemporalScore = formatScore(score.TemporalScore)
+	}
+	if score := v.latestScore("v3"); score != nil {
+		legacy.CvssV3BaseScore = formatScore(score.BaseScore)
+		legacy.CvssV3BaseVector = score.VectorString
+		legacy.CvssV3TemporalScore = formatScore(score.TemporalScore)
+	}
+	return legacy
+}
+
+// latestScore returns the score of the latest CVSS version with the given major version, e.g. v3.1 for v3
+func (v *VulnerabilityV4) latestScore(major string) *CVSSScoreV4 {
+	var versions []string
+	for version := range v.CommonVulnerabilityScores {
+		if strings.HasPrefix(version, major+".") || version == major {
+			versions = append(versions, version)
+		}
+	}
+	if len(versions) == 0 {
+		return nil
+	}
+	sort.Strings(versions)
+	return v.CommonVulnerabilityScores[versions[len(versions)-1]]
+}
+
+func formatScore(score float64) string {
+	if score == 0 {
+		return ""
+	}
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
+
+// parseTimeV4 returns the unix time of v4 API timestamp, or 0 if it can't be parsed
+func parseTimeV4(s string) int64 {
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return 0
+	}
+	return t.Unix()
+}
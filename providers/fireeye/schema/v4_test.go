@@ -0,0 +1,75 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestVulnerabilityV4Legacy(t *testing.T) {
+	var v VulnerabilityV4
+	err := json.Unmarshal([]byte(`{
+		"id": "vulnerability--8ac6b7a8-2d5b-5f3c-9f1e-0d4c36e5e36a",
+		"name": "CVE-2021-44228",
+		"title": "Apache Log4j2 Remote Code Execution Vulnerability",
+		"cve_id": "CVE-2021-44228",
+		"publish_date": "2021-12-10T02:20:00.000Z",
+		"last_modified_date": "2022-01-05T10:00:00Z",
+		"risk_rating": "CRITICAL",
+		"exploitation_state": "Wild",
+		"common_vulnerability_scores": {
+			"v2.0": {"base_score": 9.3, "temporal_score": 8.1, "vector_string": "AV:N/AC:M/Au:N/C:C/I:C/A:C"},
+			"v3.0": {"base_score": 9.8, "vector_string": "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"},
+			"v3.1": {"base_score": 10, "temporal_score": 9.5, "vector_string": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H"}
+		},
+		"vulnerable_cpes": [
+			{"cpe": "cpe:2.3:a:apache:log4j:2.0:*:*:*:*:*:*:*"},
+			{"cpe": "cpe:2.3:a:apache:log4j:2.14.1:*:*:*:*:*:*:*"}
+		]
+	}`), &v)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := v.Legacy().Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := item.CVE.CVEDataMeta.ID; id != "fireeye-"+v.ID {
+		t.Fatalf("wrong id %q", id)
+	}
+	if item.PublishedDate != "2021-12-10T02:20Z" || item.LastModifiedDate != "2022-01-05T10:00Z" {
+		t.Fatalf("wrong dates %q, %q", item.PublishedDate, item.LastModifiedDate)
+	}
+	if v2 := item.Impact.BaseMetricV2.CVSSV2; v2.BaseScore != 9.3 || v2.TemporalScore != 8.1 {
+		t.Fatalf("wrong cvss v2 %+v", v2)
+	}
+	if v3 := item.Impact.BaseMetricV3.CVSSV3; v3.BaseScore != 10 || v3.VectorString[:8] != "CVSS:3.1" {
+		t.Fatalf("the latest cvss v3 should be used, got %+v", v3)
+	}
+	if matches := item.Configurations.Nodes[0].CPEMatch; len(matches) != 2 || matches[1].Cpe23Uri != "cpe:2.3:a:apache:log4j:2.14.1:*:*:*:*:*:*:*" {
+		t.Fatalf("wrong matches %+v", matches)
+	}
+	var cves []string
+	for _, ref := range item.CVE.References.ReferenceData {
+		if ref.URL == "" {
+			cves = append(cves, ref.Name)
+		}
+	}
+	if len(cves) != 1 || cves[0] != "CVE-2021-44228" {
+		t.Fatalf("wrong cve references %v", cves)
+	}
+}
@@ -28,6 +28,9 @@ type Vulnerability struct {
 	CvssTemporalScore      string   `json:"cvssTemporalScore"`
 	CvssTemporalScoreLink  string   `json:"cvssTemporalScoreLink"`
 	CvssTemporalVector     string   `json:"cvssTemporalVector"`
+	CvssV3BaseScore        string   `json:"cvssV3BaseScore,omitempty"`
+	CvssV3BaseVector       string   `json:"cvssV3BaseVector,omitempty"`
+	CvssV3TemporalScore    string   `json:"cvssV3TemporalScore,omitempty"`
 	ExploitInTheWild       bool     `json:"exploitInTheWild"`
 	ExploitRating          string   `json:"exploitRating"`
 	IntelligenceType       string   `json:"intelligenceType"`
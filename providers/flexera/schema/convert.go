@@ -98,6 +98,8 @@ func (item *Advisory) makeReferences() *nvd.CVEJSON40References {
 		}
 	}
 
+	refsData = append(refsData, item.makeRevisionReferences()...)
+
 	return &nvd.CVEJSON40References{
 		ReferenceData: refsData,
 	}
@@ -0,0 +1,105 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+)
+
+// revisionRefsource is the refsource of references which record the revision history of an advisory
+const revisionRefsource = "FLEXERA-REVISION"
+
+// LatestRevision returns the latest revision of the advisory, or nil if it has none
+func (item *Advisory) LatestRevision() *Revision {
+	var latest *Revision
+	for _, rev := range item.Revisions {
+		if latest == nil || compareRevisions(rev.Number, latest.Number) > 0 {
+			latest = rev
+		}
+	}
+	return latest
+}
+
+// Supersedes returns true if the advisory is a later release of the other one: it has a later revision,
+// or was modified later if revisions are the same
+// it's a part of the runner.Superseder interface, used to convert re-released advisories only once
+func (item *Advisory) Supersedes(other runner.Convertible) bool {
+	o, ok := other.(*Advisory)
+	if !ok {
+		return false
+	}
+	var rev, otherRev string
+	if latest := item.LatestRevision(); latest != nil {
+		rev = latest.Number
+	}
+	if latest := o.LatestRevision(); latest != nil {
+		otherRev = latest.Number
+	}
+	if c := compareRevisions(rev, otherRev); c != 0 {
+		return c > 0
+	}
+	return item.ModifiedDate > o.ModifiedDate
+}
+
+// compareRevisions compares dot separated revision numbers, e.g. 1.0 and 2.1
+func compareRevisions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		var x, y int
+		if i < len(as) {
+			x, _ = strconv.Atoi(as[i])
+		}
+		if i < len(bs) {
+			y, _ = strconv.Atoi(bs[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// makeRevisionReferences records the revision history of the advisory, oldest first
+func (item *Advisory) makeRevisionReferences() []*nvd.CVEJSON40Reference {
+	revs := make([]*Revision, len(item.Revisions))
+	copy(revs, item.Revisions)
+	// insertion sort, there's only a handful of revisions
+	for i := 1; i < len(revs); i++ {
+		for j := i; j > 0 && compareRevisions(revs[j].Number, revs[j-1].Number) < 0; j-- {
+			revs[j], revs[j-1] = revs[j-1], revs[j]
+		}
+	}
+
+	refs := make([]*nvd.CVEJSON40Reference, len(revs))
+	for i, rev := range revs {
+		name := fmt.Sprintf("Revision %s: %s", rev.Number, rev.Description)
+		if date, err := convertTime(rev.ReleaseDate); err == nil {
+			name = fmt.Sprintf("Revision %s (%s): %s", rev.Number, date, rev.Description)
+		}
+		refs[i] = &nvd.CVEJSON40Reference{
+			Name:      name,
+			Refsource: revisionRefsource,
+		}
+	}
+	return refs
+}
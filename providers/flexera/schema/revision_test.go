@@ -0,0 +1,78 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"testing"
+)
+
+func TestRevisions(t *testing.T) {
+	data, err := ioutil.ReadFile("advisory_details_example.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var first Advisory
+	if err := json.Unmarshal(data, &first); err != nil {
+		t.Fatal(err)
+	}
+	second := first
+	second.ModifiedDate = "2019-04-10T08:00:00Z"
+	second.Revisions = []*Revision{
+		{Number: "10.0", Description: "Updated solution", ReleaseDate: "2019-04-10T08:00:00Z"},
+		first.Revisions[0],
+		{Number: "2.0", Description: "Added CVE", ReleaseDate: "2019-03-05T10:00:00Z"},
+	}
+
+	if rev := second.LatestRevision(); rev.Number != "10.0" {
+		t.Fatalf("wrong latest revision %q", rev.Number)
+	}
+	if !second.Supersedes(&first) || first.Supersedes(&second) {
+		t.Fatal("re-released advisory should supersede the initial release")
+	}
+	modified := first
+	modified.ModifiedDate = "2019-03-02T00:00:00Z"
+	if !modified.Supersedes(&first) || first.Supersedes(&modified) {
+		t.Fatal("advisory modified later should supersede the same revision")
+	}
+
+	item, err := second.Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var history []string
+	for _, ref := range item.CVE.References.ReferenceData {
+		if ref.Refsource == revisionRefsource {
+			history = append(history, ref.Name)
+		}
+	}
+	want := []string{
+		"Revision 1.0 (2019-03-01T13:35Z): Initial release",
+		"Revision 2.0 (2019-03-05T10:00Z): Added CVE",
+		"Revision 10.0 (2019-04-10T08:00Z): Updated solution",
+	}
+	if len(history) != len(want) {
+		t.Fatalf("expected revision history %q, got %q", want, history)
+	}
+	for i := range want {
+		if history[i] != want[i] {
+			t.Fatalf("expected revision history %q, got %q", want, history)
+		}
+	}
+	if item.LastModifiedDate != "2019-04-10T08:00Z" {
+		t.Fatalf("wrong last modified date %q", item.LastModifiedDate)
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/ghsa/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+)
+
+const (
+	pageSize = 100
+
+	advisoriesQuery = `query($first: Int!, $after: String, $updatedSince: DateTime) {
+  securityAdvisories(first: $first, after: $after, updatedSince: $updatedSince, orderBy: {field: UPDATED_AT, direction: ASC}) {
+    pageInfo { hasNextPage endCursor }
+    nodes {
+      ghsaId summary description severity publishedAt updatedAt withdrawnAt permalink
+      identifiers { type value }
+      references { url }
+      cvss { score vectorString }
+      cwes(first: 20) { nodes { cweId } }
+      vulnerabilities(first: 100) {
+        nodes {
+          package { ecosystem name }
+          vulnerableVersionRange
+          firstPatchedVersion { identifier }
+        }
+      }
+    }
+  }
+}`
+)
+
+// Client queries the GitHub GraphQL API for security advisories
+type Client struct {
+	client.Client
+	baseURL string
+	token   string
+}
+
+// NewClient creates an object which is used to query the GitHub GraphQL API
+// base url should point to the graphql endpoint, e.g. https://api.github.com/graphql
+func NewClient(c client.Client, baseURL, token string) *Client {
+	return &Client{
+		Client:  c,
+		baseURL: baseURL,
+		token:   token,
+	}
+}
+
+// FetchAllAdvisories will fetch all advisories updated since the given time
+func (c *Client) FetchAllAdvisories(ctx context.Context, since int64) (<-chan runner.Convertible, error) {
+	// fetch the first page synchronously so we can fail early on wrong token etc.
+	page, err := c.queryAdvisories(ctx, since, "")
+	if err != nil {
+		return nil, err
+	}
+
+	output := make(chan runner.Convertible)
+	go func() {
+		defer close(output)
+		for {
+			for _, adv := range page.Nodes {
+				if adv.WithdrawnAt != "" {
+					continue
+				}
+				output <- adv
+			}
+			if !page.PageInfo.HasNextPage {
+				return
+			}
+			cursor := page.PageInfo.EndCursor
+			if page, err = c.queryAdvisories(ctx, since, cursor); err != nil {
+				logging.Errorf("can't fetch advisories after %q: %v", cursor, err)
+				return
+			}
+		}
+	}()
+
+	return output, nil
+}
+
+type query struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// queryAdvisories returns a page of advisories after the given cursor
+func (c *Client) queryAdvisories(ctx context.Context, since int64, after string) (*schema.AdvisoryConnection, error) {
+	q := query{
+		Query: advisoriesQuery,
+		Variables: map[string]interface{}{
+			"first": pageSize,
+		},
+	}
+	if after != "" {
+		q.Variables["after"] = after
+	}
+	if since > 0 {
+		q.Variables["updatedSince"] = time.Unix(since, 0).UTC().Format(time.RFC3339)
+	}
+
+	body, err := json.Marshal(q)
+	if err != nil {
+		return nil, fmt.Errorf("can't encode query: %v", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("can't create request: %v", err)
+	}
+	req.Header.Set("Authorization", "bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("can't query advisories: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("can't query advisories: %s", resp.Status)
+	}
+
+	var r schema.Response
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		return nil, fmt.Errorf("can't decode response: %v", err)
+	}
+	if len(r.Errors) != 0 {
+		msgs := make([]string, len(r.Errors))
+		for i, e := range r.Errors {
+			msgs[i] = e.Message
+		}
+		return nil, fmt.Errorf("graphql errors: %s", strings.Join(msgs, "; "))
+	}
+	if r.Data == nil || r.Data.SecurityAdvisories == nil {
+		return nil, fmt.Errorf("no advisories in response")
+	}
+	return r.Data.SecurityAdvisories, nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+)
+
+func TestFetchAllAdvisories(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "bearer token" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		var q query
+		if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if q.Variables["updatedSince"] != "2021-01-01T00:00:00Z" {
+			http.Error(w, "wrong updatedSince", http.StatusBadRequest)
+			return
+		}
+		switch q.Variables["after"] {
+		case nil:
+			fmt.Fprint(w, `{"data": {"securityAdvisories": {
+				"pageInfo": {"hasNextPage": true, "endCursor": "c1"},
+				"nodes": [{"ghsaId": "GHSA-0001"}, {"ghsaId": "GHSA-0002", "withdrawnAt": "2021-02-01T00:00:00Z"}]}}}`)
+		case "c1":
+			fmt.Fprint(w, `{"data": {"securityAdvisories": {
+				"pageInfo": {"hasNextPage": false, "endCursor": "c2"},
+				"nodes": [{"ghsaId": "GHSA-0003"}]}}}`)
+		default:
+			fmt.Fprint(w, `{"errors": [{"message": "unknown cursor"}]}`)
+		}
+	}))
+	defer srv.Close()
+
+	since := int64(1609459200) // 2021-01-01
+	advs, err := NewClient(client.Default(), srv.URL, "token").FetchAllAdvisories(context.Background(), since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for adv := range advs {
+		ids = append(ids, adv.ID())
+	}
+	if expected := fmt.Sprint([]string{"GHSA-0001", "GHSA-0003"}); fmt.Sprint(ids) != expected {
+		t.Fatalf("expecting %s, got %v", expected, ids)
+	}
+
+	if _, err := NewClient(client.Default(), srv.URL, "wrong").FetchAllAdvisories(context.Background(), since); err == nil {
+		t.Fatal("expecting an error with wrong token")
+	}
+}
@@ -0,0 +1,289 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/semver"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const (
+	cveDataVersion = "4.0"
+)
+
+// github ecosystems mapped to target software, same as used by the osv provider
+var ecosystems = map[string]string{
+	"ACTIONS":  "github_actions",
+	"COMPOSER": "packagist",
+	"ERLANG":   "hex",
+	"GO":       "go",
+	"MAVEN":    "maven",
+	"NPM":      "npm",
+	"NUGET":    "nuget",
+	"PIP":      "pypi",
+	"PUB":      "pub",
+	"RUBYGEMS": "rubygems",
+	"RUST":     "crates.io",
+	"SWIFT":    "swifturl",
+}
+
+// semverEcosystems are github ecosystems whose packages use semantic versioning
+var semverEcosystems = map[string]bool{
+	"ERLANG": true,
+	"GO":     true,
+	"NPM":    true,
+	"PUB":    true,
+	"RUST":   true,
+}
+
+// ID is a part of the runner.Convertible interface
+func (adv *Advisory) ID() string {
+	return adv.GHSAID
+}
+
+// Convert is a part of the runner.Convertible interface
+func (adv *Advisory) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	if adv.WithdrawnAt != "" {
+		return nil, fmt.Errorf("advisory %s has been withdrawn", adv.GHSAID)
+	}
+
+	item := nvd.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &nvd.CVEJSON40{
+			CVEDataMeta: &nvd.CVEJSON40CVEDataMeta{
+				ID:       adv.ID(),
+				ASSIGNER: "github.com",
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: cveDataVersion,
+			Description: &nvd.CVEJSON40Description{
+				DescriptionData: []*nvd.CVEJSON40LangString{
+					{
+						Lang:  "en",
+						Value: adv.Description,
+					},
+				},
+			},
+			Problemtype: adv.newProblemType(),
+			References:  adv.newReferences(),
+		},
+		Configurations:   adv.newConfigurations(),
+		Impact:           adv.newImpact(),
+		LastModifiedDate: githubTimeToNVD(adv.UpdatedAt),
+		PublishedDate:    githubTimeToNVD(adv.PublishedAt),
+	}
+
+	return &item, nil
+}
+
+// CVEs returns CVE ids the advisory is an alias of
+func (adv *Advisory) CVEs() []string {
+	var cves []string
+	for _, id := range adv.Identifiers {
+		if id.Type == "CVE" {
+			cves = append(cves, id.Value)
+		}
+	}
+	return cves
+}
+
+func (adv *Advisory) newProblemType() *nvd.CVEJSON40Problemtype {
+	if len(adv.CWEs.Nodes) == 0 {
+		return nil
+	}
+	data := &nvd.CVEJSON40ProblemtypeProblemtypeData{}
+	for _, cwe := range adv.CWEs.Nodes {
+		data.Description = append(data.Description, &nvd.CVEJSON40LangString{
+			Lang:  "en",
+			Value: cwe.CWEID,
+		})
+	}
+	return &nvd.CVEJSON40Problemtype{
+		ProblemtypeData: []*nvd.CVEJSON40ProblemtypeProblemtypeData{data},
+	}
+}
+
+func (adv *Advisory) newReferences() *nvd.CVEJSON40References {
+	cves := adv.CVEs()
+	refs := &nvd.CVEJSON40References{
+		ReferenceData: make([]*nvd.CVEJSON40Reference, 0, 1+len(cves)+len(adv.References)),
+	}
+	addRef := func(name, url string) {
+		refs.ReferenceData = append(refs.ReferenceData, &nvd.CVEJSON40Reference{
+			Name: name,
+			URL:  url,
+		})
+	}
+	if adv.Summary != "" || adv.Permalink != "" {
+		addRef(adv.Summary, adv.Permalink)
+	}
+	for _, cve := range cves {
+		addRef(cve, "")
+	}
+	for _, ref := range adv.References {
+		addRef(ref.URL, ref.URL)
+	}
+	if len(refs.ReferenceData) == 0 {
+		return nil
+	}
+	return refs
+}
+
+func (adv *Advisory) newImpact() *nvd.NVDCVEFeedJSON10DefImpact {
+	if adv.CVSS.VectorString == "" {
+		return nil
+	}
+	return &nvd.NVDCVEFeedJSON10DefImpact{
+		BaseMetricV3: &nvd.NVDCVEFeedJSON10DefImpactBaseMetricV3{
+			CVSSV3: &nvd.CVSSV30{
+				BaseScore:    adv.CVSS.Score,
+				BaseSeverity: adv.Severity,
+				VectorString: adv.CVSS.VectorString,
+			},
+		},
+	}
+}
+
+func (adv *Advisory) newConfigurations() *nvd.NVDCVEFeedJSON10DefConfigurations {
+	node := &nvd.NVDCVEFeedJSON10DefNode{Operator: "OR"}
+	for _, vuln := range adv.Vulnerabilities.Nodes {
+		match, err := vuln.cpeMatch()
+		if err != nil {
+			logging.Recordf("can't create configuration for %s, package %q: %v", adv.GHSAID, vuln.Package.Name, err)
+			continue
+		}
+		node.CPEMatch = append(node.CPEMatch, match)
+	}
+	return &nvd.NVDCVEFeedJSON10DefConfigurations{
+		CVEDataVersion: cveDataVersion,
+		Nodes:          []*nvd.NVDCVEFeedJSON10DefNode{node},
+	}
+}
+
+func (vuln *Vulnerability) cpeMatch() (*nvd.NVDCVEFeedJSON10DefCPEMatch, error) {
+	attrs, err := PackageToCPE(vuln.Package.Ecosystem, vuln.Package.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	match := nvd.NVDCVEFeedJSON10DefCPEMatch{Vulnerable: true}
+
+	// constraints are separated by a comma, e.g. ">= 1.0, < 1.2.3"
+	for _, constraint := range strings.Split(vuln.VulnerableVersionRange, ",") {
+		constraint = strings.TrimSpace(constraint)
+		var op, version string
+		if i := strings.IndexByte(constraint, ' '); i >= 0 {
+			op, version = constraint[:i], strings.TrimSpace(constraint[i+1:])
+		} else {
+			return nil, fmt.Errorf("can't parse version range %q", vuln.VulnerableVersionRange)
+		}
+		switch op {
+		case "=":
+			if attrs.Version, err = wfn.WFNize(version); err != nil {
+				return nil, fmt.Errorf("can't wfnize version %q: %v", version, err)
+			}
+		case ">=":
+			match.VersionStartIncluding = version
+		case ">":
+			match.VersionStartExcluding = version
+		case "<=":
+			match.VersionEndIncluding = version
+		case "<":
+			match.VersionEndExcluding = version
+		default:
+			return nil, fmt.Errorf("unknown operator %q in version range %q", op, vuln.VulnerableVersionRange)
+		}
+	}
+
+	if semverEcosystems[strings.ToUpper(vuln.Package.Ecosystem)] && isEmptyRange(&match) {
+		return nil, fmt.Errorf("version range %q doesn't contain any versions", vuln.VulnerableVersionRange)
+	}
+
+	match.Cpe23Uri = attrs.BindToFmtString()
+	match.CPEName = []*nvd.NVDCVEFeedJSON10DefCPEName{
+		{
+			Cpe22Uri: attrs.BindToURI(),
+			Cpe23Uri: match.Cpe23Uri,
+		},
+	}
+	return &match, nil
+}
+
+// isEmptyRange returns true if lower bound of the match is above its upper bound, comparing them as semantic versions
+// bounds which aren't semantic versions aren't checked
+func isEmptyRange(match *nvd.NVDCVEFeedJSON10DefCPEMatch) bool {
+	start, end := match.VersionStartIncluding, match.VersionEndIncluding
+	if start == "" {
+		start = match.VersionStartExcluding
+	}
+	if end == "" {
+		end = match.VersionEndExcluding
+	}
+	if start == "" || end == "" {
+		return false
+	}
+	c, err := semver.CompareVersions(start, end)
+	if err != nil {
+		return false
+	}
+	return c > 0 || c == 0 && (match.VersionStartExcluding != "" || match.VersionEndExcluding != "")
+}
+
+// PackageToCPE creates a CPE for the package in the given github ecosystem
+// ecosystem is stored as target software and maven group id as the vendor
+func PackageToCPE(ecosystem, name string) (*wfn.Attributes, error) {
+	targetSW, ok := ecosystems[strings.ToUpper(ecosystem)]
+	if !ok {
+		targetSW = strings.ToLower(ecosystem)
+	}
+
+	attrs := wfn.Attributes{Part: "a"}
+	if targetSW == "maven" {
+		if parts := strings.SplitN(name, ":", 2); len(parts) == 2 {
+			vendor, err := wfn.WFNize(parts[0])
+			if err != nil {
+				return nil, fmt.Errorf("can't wfnize group id %q: %v", parts[0], err)
+			}
+			attrs.Vendor, name = vendor, parts[1]
+		}
+	}
+
+	var err error
+	if attrs.Product, err = wfn.WFNize(name); err != nil {
+		return nil, fmt.Errorf("can't wfnize package name %q: %v", name, err)
+	}
+	if attrs.TargetSW, err = wfn.WFNize(targetSW); err != nil {
+		return nil, fmt.Errorf("can't wfnize ecosystem %q: %v", targetSW, err)
+	}
+	return &attrs, nil
+}
+
+func githubTimeToNVD(s string) string {
+	if s == "" {
+		return ""
+	}
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		logging.Recordf("cannot parse github time: %v", err)
+		return s
+	}
+	return t.UTC().Format(nvd.TimeLayout)
+}
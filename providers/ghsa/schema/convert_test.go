@@ -0,0 +1,137 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const testAdvisory = `{
+  "ghsaId": "GHSA-jfh8-c2jp-5v3q",
+  "summary": "Remote code injection in Log4j",
+  "description": "Log4j2 JNDI features do not protect against attacker controlled LDAP endpoints.",
+  "severity": "CRITICAL",
+  "publishedAt": "2021-12-10T00:40:56Z",
+  "updatedAt": "2021-12-20T17:50:11Z",
+  "withdrawnAt": null,
+  "permalink": "https://github.com/advisories/GHSA-jfh8-c2jp-5v3q",
+  "identifiers": [{"type": "GHSA", "value": "GHSA-jfh8-c2jp-5v3q"}, {"type": "CVE", "value": "CVE-2021-44228"}],
+  "references": [{"url": "https://nvd.nist.gov/vuln/detail/CVE-2021-44228"}],
+  "cvss": {"score": 10, "vectorString": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H"},
+  "cwes": {"nodes": [{"cweId": "CWE-502"}, {"cweId": "CWE-917"}]},
+  "vulnerabilities": {"nodes": [
+    {"package": {"ecosystem": "MAVEN", "name": "org.apache.logging.log4j:log4j-core"},
+     "vulnerableVersionRange": ">= 2.13.0, < 2.15.0", "firstPatchedVersion": {"identifier": "2.15.0"}},
+    {"package": {"ecosystem": "PIP", "name": "some-package"}, "vulnerableVersionRange": "= 1.1"},
+    {"package": {"ecosystem": "NPM", "name": "other-package"}, "vulnerableVersionRange": "<= 0.9.0"}
+  ]}
+}`
+
+func TestConvert(t *testing.T) {
+	var adv Advisory
+	if err := json.Unmarshal([]byte(testAdvisory), &adv); err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := adv.Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if item.CVE.CVEDataMeta.ID != "GHSA-jfh8-c2jp-5v3q" {
+		t.Fatalf("wrong id %q", item.CVE.CVEDataMeta.ID)
+	}
+	if item.PublishedDate != "2021-12-10T00:40Z" || item.LastModifiedDate != "2021-12-20T17:50Z" {
+		t.Fatalf("wrong dates %q, %q", item.PublishedDate, item.LastModifiedDate)
+	}
+	if cves := adv.CVEs(); !reflect.DeepEqual(cves, []string{"CVE-2021-44228"}) {
+		t.Fatalf("wrong aliases %v", cves)
+	}
+	var aliased bool
+	for _, ref := range item.CVE.References.ReferenceData {
+		aliased = aliased || ref.Name == "CVE-2021-44228"
+	}
+	if !aliased {
+		t.Fatal("cve alias should be in references")
+	}
+	if score := item.Impact.BaseMetricV3.CVSSV3.BaseScore; score != 10 {
+		t.Fatalf("wrong base score %.1f", score)
+	}
+	if n := len(item.CVE.Problemtype.ProblemtypeData[0].Description); n != 2 {
+		t.Fatalf("expecting 2 cwes, got %d", n)
+	}
+
+	v := nvd.ToVuln(item)
+	for i, tc := range []struct {
+		cpe   string
+		match bool
+	}{
+		{"cpe:/a:org.apache.logging.log4j:log4j-core:2.14.1::~~~maven~~", true},
+		{"cpe:/a:org.apache.logging.log4j:log4j-core:2.13.0::~~~maven~~", true},
+		{"cpe:/a:org.apache.logging.log4j:log4j-core:2.15.0::~~~maven~~", false},
+		{"cpe:/a:org.apache.logging.log4j:log4j-core:2.14.1::~~~npm~~", false},
+		{"cpe:/a::some-package:1.1::~~~pypi~~", true},
+		{"cpe:/a::some-package:1.2::~~~pypi~~", false},
+		{"cpe:/a::other-package:0.9.0::~~~npm~~", true},
+		{"cpe:/a::other-package:0.9.1::~~~npm~~", false},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			attrs, err := wfn.Parse(tc.cpe)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if matched := len(v.Match([]*wfn.Attributes{attrs}, true)) != 0; matched != tc.match {
+				t.Fatalf("expecting match of %s to be %v, got %v", tc.cpe, tc.match, matched)
+			}
+		})
+	}
+}
+
+func TestEmptySemverRange(t *testing.T) {
+	for i, tc := range []struct {
+		ecosystem, versions string
+		fail                bool
+	}{
+		{"NPM", ">= 2.0.0, < 1.0.0", true},
+		{"NPM", "> 1.0.0, < 1.0.0", true},
+		{"NPM", ">= 1.0.0, <= 1.0.0", false},
+		{"NPM", ">= 1.0.0-rc.2, < 1.0.0", false},
+		{"GO", ">= v1.2.0, < v1.10.0", false},
+		// not a semver ecosystem, so the range isn't checked
+		{"PIP", ">= 2.0, < 1.0", false},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			var vuln Vulnerability
+			vuln.Package.Ecosystem, vuln.Package.Name = tc.ecosystem, "pkg"
+			vuln.VulnerableVersionRange = tc.versions
+			if _, err := vuln.cpeMatch(); (err != nil) != tc.fail {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestConvertWithdrawn(t *testing.T) {
+	adv := Advisory{GHSAID: "GHSA-xxxx-xxxx-xxxx", WithdrawnAt: "2022-01-01T00:00:00Z"}
+	if _, err := adv.Convert(); err == nil {
+		t.Fatal("withdrawn advisory shouldn't be converted")
+	}
+}
@@ -0,0 +1,85 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+// based on the GitHub GraphQL API
+// https://docs.github.com/en/graphql/reference/objects#securityadvisory
+
+// Response is the response to the security advisories query
+type Response struct {
+	Data *struct {
+		SecurityAdvisories *AdvisoryConnection `json:"securityAdvisories"`
+	} `json:"data"`
+	Errors []*Error `json:"errors,omitempty"`
+}
+
+// Error is an error returned by the GraphQL API
+type Error struct {
+	Message string `json:"message"`
+}
+
+// AdvisoryConnection is a single page of advisories
+type AdvisoryConnection struct {
+	PageInfo struct {
+		HasNextPage bool   `json:"hasNextPage"`
+		EndCursor   string `json:"endCursor"`
+	} `json:"pageInfo"`
+	Nodes []*Advisory `json:"nodes"`
+}
+
+type Advisory struct {
+	GHSAID      string        `json:"ghsaId"`
+	Summary     string        `json:"summary"`
+	Description string        `json:"description"`
+	Severity    string        `json:"severity"`
+	PublishedAt string        `json:"publishedAt"`
+	UpdatedAt   string        `json:"updatedAt"`
+	WithdrawnAt string        `json:"withdrawnAt,omitempty"`
+	Permalink   string        `json:"permalink"`
+	Identifiers []*Identifier `json:"identifiers"`
+	References  []struct {
+		URL string `json:"url"`
+	} `json:"references"`
+	CVSS struct {
+		Score        float64 `json:"score"`
+		VectorString string  `json:"vectorString"`
+	} `json:"cvss"`
+	CWEs struct {
+		Nodes []struct {
+			CWEID string `json:"cweId"`
+		} `json:"nodes"`
+	} `json:"cwes"`
+	Vulnerabilities struct {
+		Nodes []*Vulnerability `json:"nodes"`
+	} `json:"vulnerabilities"`
+}
+
+// Identifier is either GHSA or CVE id
+type Identifier struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type Vulnerability struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	// e.g. "< 1.2.3" or ">= 1.0, < 1.2.3"
+	VulnerableVersionRange string `json:"vulnerableVersionRange"`
+	FirstPatchedVersion    *struct {
+		Identifier string `json:"identifier"`
+	} `json:"firstPatchedVersion,omitempty"`
+}
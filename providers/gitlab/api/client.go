@@ -0,0 +1,130 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/gitlab/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+)
+
+const archivePath = "/-/archive/main/advisories-community-main.tar.gz"
+
+// Client downloads the GitLab advisory database
+type Client struct {
+	client.Client
+	baseURL string
+}
+
+// NewClient creates an object which is used to download the GitLab advisory database
+// base url should point to the repository, e.g. https://gitlab.com/gitlab-org/advisories-community
+func NewClient(c client.Client, baseURL string) *Client {
+	return &Client{
+		Client:  c,
+		baseURL: baseURL,
+	}
+}
+
+// FetchAllVulnerabilities downloads the archive of the repository and returns vulnerabilities
+// which were modified since the given time
+func (c *Client) FetchAllVulnerabilities(ctx context.Context, since int64) (<-chan runner.Convertible, error) {
+	resp, err := client.Get(ctx, c, c.baseURL+archivePath, http.Header{})
+	if err != nil {
+		return nil, fmt.Errorf("can't download advisories: %v", err)
+	}
+	defer resp.Body.Close()
+
+	advs, err := ReadArchive(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var sinceDate string
+	if since > 0 {
+		sinceDate = time.Unix(since, 0).UTC().Format("2006-01-02")
+	}
+
+	output := make(chan runner.Convertible)
+	go func() {
+		defer close(output)
+		for _, vuln := range schema.Vulnerabilities(advs) {
+			if vuln.Modified() >= sinceDate {
+				output <- vuln
+			}
+		}
+	}()
+	return output, nil
+}
+
+// ReadArchive reads all advisories from the gzipped tar archive of the advisory database
+func ReadArchive(r io.Reader) ([]*schema.Advisory, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("can't open gzip: %v", err)
+	}
+	defer gr.Close()
+
+	var advs []*schema.Advisory
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("can't read archive: %v", err)
+		}
+		if hdr.Typeflag != tar.TypeReg || !isAdvisory(hdr.Name) {
+			continue
+		}
+		data, err := ioutil.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("can't read %s: %v", hdr.Name, err)
+		}
+		adv, err := schema.ParseAdvisory(data)
+		if err != nil {
+			logging.Recordf("can't parse %s: %v", hdr.Name, err)
+			continue
+		}
+		advs = append(advs, adv)
+	}
+
+	return advs, nil
+}
+
+// isAdvisory returns whether the file is an advisory, they're stored as <type>/<package>/<id>.yml
+func isAdvisory(name string) bool {
+	if path.Ext(name) != ".yml" && path.Ext(name) != ".yaml" {
+		return false
+	}
+	// skip the top level directory of the archive
+	if i := strings.IndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	// there are some yaml files which aren't advisories, like the ci configuration
+	return !strings.HasPrefix(path.Base(name), ".") && strings.Count(name, "/") >= 2
+}
@@ -0,0 +1,74 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/gitlab/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+)
+
+func init() {
+	runner.Register(runner.Registration{
+		Name:    "gitlab",
+		BaseURL: "https://gitlab.com/gitlab-org/advisories-community",
+		New: func(c client.Client, baseURL string, _ map[string]string) (runner.Provider, error) {
+			client := NewClient(c, baseURL)
+			return runner.ConvertibleProvider(func(ctx context.Context, since time.Time) (<-chan runner.Convertible, error) {
+				return client.FetchAllVulnerabilities(ctx, since.Unix())
+			}), nil
+		},
+		Read: Read,
+	})
+}
+
+// Read reads vulnerabilities either from a file created by downloading, or directly from the archive of the repository
+func Read(r io.Reader, c chan runner.Convertible) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("can't read input: %v", err)
+	}
+
+	// gzip magic
+	if bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		advs, err := ReadArchive(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+		for _, vuln := range schema.Vulnerabilities(advs) {
+			c <- vuln
+		}
+		return nil
+	}
+
+	var vulns map[string]*schema.Vulnerability
+	if err := json.Unmarshal(data, &vulns); err != nil {
+		return fmt.Errorf("can't decode into vulns: %v", err)
+	}
+
+	for _, vuln := range vulns {
+		c <- vuln
+	}
+
+	return nil
+}
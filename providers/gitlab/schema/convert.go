@@ -0,0 +1,275 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/cvss2"
+	"github.com/facebookincubator/nvdtools/cvss3"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const (
+	cveDataVersion = "4.0"
+	dateLayout     = "2006-01-02"
+)
+
+// gitlab package types mapped to target software, same as used by the osv provider
+var packageTypes = map[string]string{
+	"conan":     "conan",
+	"gem":       "rubygems",
+	"go":        "go",
+	"maven":     "maven",
+	"npm":       "npm",
+	"nuget":     "nuget",
+	"packagist": "packagist",
+	"pypi":      "pypi",
+	"swift":     "swifturl",
+}
+
+// ID is a part of the runner.Convertible interface
+func (vuln *Vulnerability) ID() string {
+	return vuln.Identifier
+}
+
+// Convert is a part of the runner.Convertible interface
+func (vuln *Vulnerability) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	if len(vuln.Advisories) == 0 {
+		return nil, fmt.Errorf("vulnerability %s doesn't have any advisories", vuln.Identifier)
+	}
+	// all advisories share the description and scores of the vulnerability
+	adv := vuln.Advisories[0]
+
+	impact, err := adv.newImpact()
+	if err != nil {
+		return nil, fmt.Errorf("can't create impact for %s: %v", vuln.Identifier, err)
+	}
+
+	item := nvd.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &nvd.CVEJSON40{
+			CVEDataMeta: &nvd.CVEJSON40CVEDataMeta{
+				ID:       vuln.ID(),
+				ASSIGNER: "gitlab.com",
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: cveDataVersion,
+			Description: &nvd.CVEJSON40Description{
+				DescriptionData: []*nvd.CVEJSON40LangString{
+					{
+						Lang:  "en",
+						Value: adv.Description,
+					},
+				},
+			},
+			Problemtype: adv.newProblemType(),
+			References:  vuln.newReferences(),
+		},
+		Configurations:   vuln.newConfigurations(),
+		Impact:           impact,
+		LastModifiedDate: gitlabDateToNVD(vuln.Modified()),
+		PublishedDate:    gitlabDateToNVD(vuln.published()),
+	}
+
+	return &item, nil
+}
+
+func (vuln *Vulnerability) published() string {
+	var published string
+	for _, adv := range vuln.Advisories {
+		if adv.PubDate != "" && (published == "" || adv.PubDate < published) {
+			published = adv.PubDate
+		}
+	}
+	return published
+}
+
+func (adv *Advisory) newProblemType() *nvd.CVEJSON40Problemtype {
+	if len(adv.CWEIDs) == 0 {
+		return nil
+	}
+	data := &nvd.CVEJSON40ProblemtypeProblemtypeData{}
+	for _, cwe := range adv.CWEIDs {
+		data.Description = append(data.Description, &nvd.CVEJSON40LangString{
+			Lang:  "en",
+			Value: cwe,
+		})
+	}
+	return &nvd.CVEJSON40Problemtype{
+		ProblemtypeData: []*nvd.CVEJSON40ProblemtypeProblemtypeData{data},
+	}
+}
+
+func (vuln *Vulnerability) newReferences() *nvd.CVEJSON40References {
+	refs := &nvd.CVEJSON40References{}
+	seen := make(map[string]bool)
+	addRef := func(name, url string) {
+		if seen[name+url] {
+			return
+		}
+		seen[name+url] = true
+		refs.ReferenceData = append(refs.ReferenceData, &nvd.CVEJSON40Reference{
+			Name: name,
+			URL:  url,
+		})
+	}
+	for _, adv := range vuln.Advisories {
+		if adv.Title != "" {
+			addRef(adv.Title, "")
+		}
+		for _, id := range adv.Identifiers {
+			if id != vuln.Identifier {
+				addRef(id, "")
+			}
+		}
+		for _, url := range adv.URLs {
+			addRef(url, url)
+		}
+	}
+	if len(refs.ReferenceData) == 0 {
+		return nil
+	}
+	return refs
+}
+
+func (adv *Advisory) newImpact() (*nvd.NVDCVEFeedJSON10DefImpact, error) {
+	var impact nvd.NVDCVEFeedJSON10DefImpact
+	if adv.CVSSv2 != "" {
+		v, err := cvss2.VectorFromString(adv.CVSSv2)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse cvss v2 vector %q: %v", adv.CVSSv2, err)
+		}
+		impact.BaseMetricV2 = &nvd.NVDCVEFeedJSON10DefImpactBaseMetricV2{
+			CVSSV2: &nvd.CVSSV20{
+				BaseScore:    v.BaseScore(),
+				VectorString: v.String(),
+				Version:      "2.0",
+			},
+		}
+	}
+	if adv.CVSSv3 != "" {
+		v, err := cvss3.VectorFromString(adv.CVSSv3)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse cvss v3 vector %q: %v", adv.CVSSv3, err)
+		}
+		impact.BaseMetricV3 = &nvd.NVDCVEFeedJSON10DefImpactBaseMetricV3{
+			CVSSV3: &nvd.CVSSV30{
+				BaseScore:    v.BaseScore(),
+				VectorString: adv.CVSSv3,
+				Version:      strings.TrimPrefix(strings.SplitN(v.String(), "/", 2)[0], "CVSS:"),
+			},
+		}
+	}
+
+	if impact.BaseMetricV2 == nil && impact.BaseMetricV3 == nil {
+		return nil, nil
+	}
+	return &impact, nil
+}
+
+func (vuln *Vulnerability) newConfigurations() *nvd.NVDCVEFeedJSON10DefConfigurations {
+	node := &nvd.NVDCVEFeedJSON10DefNode{Operator: "OR"}
+	for _, adv := range vuln.Advisories {
+		matches, err := adv.cpeMatches()
+		if err != nil {
+			logging.Recordf("can't create configuration for %s, package %q: %v", vuln.Identifier, adv.PackageSlug, err)
+			continue
+		}
+		node.CPEMatch = append(node.CPEMatch, matches...)
+	}
+	return &nvd.NVDCVEFeedJSON10DefConfigurations{
+		CVEDataVersion: cveDataVersion,
+		Nodes:          []*nvd.NVDCVEFeedJSON10DefNode{node},
+	}
+}
+
+// cpeMatches creates a cpe match for each affected version range
+func (adv *Advisory) cpeMatches() ([]*nvd.NVDCVEFeedJSON10DefCPEMatch, error) {
+	typ, name := adv.Package()
+	attrs, err := PackageToCPE(typ, name)
+	if err != nil {
+		return nil, err
+	}
+	ranges, err := ParseRange(typ, adv.AffectedRange)
+	if err != nil {
+		return nil, err
+	}
+
+	cpe23URI := attrs.BindToFmtString()
+	matches := make([]*nvd.NVDCVEFeedJSON10DefCPEMatch, 0, len(ranges))
+	for _, r := range ranges {
+		matches = append(matches, &nvd.NVDCVEFeedJSON10DefCPEMatch{
+			CPEName: []*nvd.NVDCVEFeedJSON10DefCPEName{
+				{
+					Cpe22Uri: attrs.BindToURI(),
+					Cpe23Uri: cpe23URI,
+				},
+			},
+			Cpe23Uri:              cpe23URI,
+			VersionStartIncluding: r.StartIncluding,
+			VersionStartExcluding: r.StartExcluding,
+			VersionEndIncluding:   r.EndIncluding,
+			VersionEndExcluding:   r.EndExcluding,
+			Vulnerable:            true,
+		})
+	}
+	return matches, nil
+}
+
+// PackageToCPE creates a CPE for the package of the given gitlab package type
+// package type is stored as target software and maven group id as the vendor
+func PackageToCPE(typ, name string) (*wfn.Attributes, error) {
+	targetSW, ok := packageTypes[typ]
+	if !ok {
+		targetSW = typ
+	}
+
+	attrs := wfn.Attributes{Part: "a"}
+	if typ == "maven" {
+		if i := strings.LastIndexByte(name, '/'); i >= 0 {
+			vendor, err := wfn.WFNize(name[:i])
+			if err != nil {
+				return nil, fmt.Errorf("can't wfnize group id %q: %v", name[:i], err)
+			}
+			attrs.Vendor, name = vendor, name[i+1:]
+		}
+	}
+
+	var err error
+	if attrs.Product, err = wfn.WFNize(name); err != nil {
+		return nil, fmt.Errorf("can't wfnize package name %q: %v", name, err)
+	}
+	if attrs.TargetSW, err = wfn.WFNize(targetSW); err != nil {
+		return nil, fmt.Errorf("can't wfnize package type %q: %v", targetSW, err)
+	}
+	return &attrs, nil
+}
+
+func gitlabDateToNVD(s string) string {
+	if s == "" {
+		return ""
+	}
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		logging.Recordf("cannot parse gitlab date: %v", err)
+		return s
+	}
+	return t.Format(nvd.TimeLayout)
+}
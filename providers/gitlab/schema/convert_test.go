@@ -0,0 +1,191 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const testMavenAdvisory = `---
+identifier: "CVE-2021-44228"
+identifiers:
+- "GHSA-jfh8-c2jp-5v3q"
+- "CVE-2021-44228"
+package_slug: "maven/org.apache.logging.log4j/log4j-core"
+title: "Improper Input Validation"
+description: "Apache Log4j2 JNDI features do not protect against attacker controlled LDAP
+  and other JNDI related endpoints.\n\nFrom log4j 2.15.0, this behavior has been disabled
+  by default."
+date: "2022-04-06"
+pubdate: "2021-12-10"
+affected_range: "[2.0-beta9,2.3.1),[2.4,2.12.2),[2.13.0,2.15.0)"
+fixed_versions:
+- "2.3.1"
+- "2.12.2"
+- "2.15.0"
+solution: |
+  Upgrade to versions 2.3.1, 2.12.2, 2.15.0 or above.
+  Or remove the JndiLookup class.
+urls:
+- "https://nvd.nist.gov/vuln/detail/CVE-2021-44228"
+cvss_v3: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H"
+uuid: "3d5b1c3f-6e6a-4f0b-8f8b-6f2c3d0e2b1a"
+cwe_ids:
+- "CWE-1035"
+- "CWE-20"
+versions:
+- number: "2.3.1"
+  commit:
+    tags:
+    - "v2.3.1"
+`
+
+const testNPMAdvisory = `identifier: CVE-2021-44228
+package_slug: npm/@example/log4js
+title: 'Log4j''s port'
+date: "2021-12-12"
+pubdate: "2021-12-11"
+affected_range: ">=1.0.0 <1.2.0||>= 2.0.0, <2.0.5"
+`
+
+func TestParseAdvisory(t *testing.T) {
+	adv, err := ParseAdvisory([]byte(testMavenAdvisory))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := &Advisory{
+		Identifier:    "CVE-2021-44228",
+		Identifiers:   []string{"GHSA-jfh8-c2jp-5v3q", "CVE-2021-44228"},
+		PackageSlug:   "maven/org.apache.logging.log4j/log4j-core",
+		Title:         "Improper Input Validation",
+		Description:   "Apache Log4j2 JNDI features do not protect against attacker controlled LDAP and other JNDI related endpoints.\n\nFrom log4j 2.15.0, this behavior has been disabled by default.",
+		Date:          "2022-04-06",
+		PubDate:       "2021-12-10",
+		AffectedRange: "[2.0-beta9,2.3.1),[2.4,2.12.2),[2.13.0,2.15.0)",
+		FixedVersions: []string{"2.3.1", "2.12.2", "2.15.0"},
+		Solution:      "Upgrade to versions 2.3.1, 2.12.2, 2.15.0 or above.\nOr remove the JndiLookup class.",
+		URLs:          []string{"https://nvd.nist.gov/vuln/detail/CVE-2021-44228"},
+		CVSSv3:        "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H",
+		UUID:          "3d5b1c3f-6e6a-4f0b-8f8b-6f2c3d0e2b1a",
+		CWEIDs:        []string{"CWE-1035", "CWE-20"},
+	}
+	if !reflect.DeepEqual(adv, expect) {
+		t.Fatalf("expecting\n%+v\ngot\n%+v", expect, adv)
+	}
+
+	if adv, err = ParseAdvisory([]byte(testNPMAdvisory)); err != nil {
+		t.Fatal(err)
+	}
+	if adv.Title != "Log4j's port" || adv.Identifier != "CVE-2021-44228" {
+		t.Fatalf("wrong scalars in %+v", adv)
+	}
+
+	if _, err := ParseAdvisory([]byte("title: no identifier\n")); err == nil {
+		t.Fatal("expecting an error")
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	for i, tc := range []struct {
+		typ    string
+		expr   string
+		expect []VersionRange
+	}{
+		{"maven", "[1.0,1.2),(2.0,2.1]", []VersionRange{{StartIncluding: "1.0", EndExcluding: "1.2"}, {StartExcluding: "2.0", EndIncluding: "2.1"}}},
+		{"nuget", "(,1.0.5)", []VersionRange{{EndExcluding: "1.0.5"}}},
+		{"maven", "[1.3]", []VersionRange{{StartIncluding: "1.3", EndIncluding: "1.3"}}},
+		{"npm", ">=1.0.0 <1.2.0||=2.0.0", []VersionRange{{StartIncluding: "1.0.0", EndExcluding: "1.2.0"}, {StartIncluding: "2.0.0", EndIncluding: "2.0.0"}}},
+		{"pypi", ">=2.0,<2.1.3||==1.0", []VersionRange{{StartIncluding: "2.0", EndExcluding: "2.1.3"}, {StartIncluding: "1.0", EndIncluding: "1.0"}}},
+		{"packagist", "> 1.0 <= 1.5", []VersionRange{{StartExcluding: "1.0", EndIncluding: "1.5"}}},
+		{"go", ">=v1.2.0 <v1.4.1", []VersionRange{{StartIncluding: "1.2.0", EndExcluding: "1.4.1"}}},
+		{"npm", "*", []VersionRange{{}}},
+		{"maven", "1.0", nil},
+		{"npm", "!=1.0", nil},
+		{"npm", "", nil},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			ranges, err := ParseRange(tc.typ, tc.expr)
+			if tc.expect == nil {
+				if err == nil {
+					t.Fatalf("expecting an error, got %v", ranges)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]VersionRange, len(ranges))
+			for i, r := range ranges {
+				got[i] = *r
+			}
+			if !reflect.DeepEqual(got, tc.expect) {
+				t.Fatalf("expecting %+v, got %+v", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	var advs []*Advisory
+	for _, data := range []string{testMavenAdvisory, testNPMAdvisory} {
+		adv, err := ParseAdvisory([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		advs = append(advs, adv)
+	}
+
+	vulns := Vulnerabilities(advs)
+	if len(vulns) != 1 {
+		t.Fatalf("expecting 1 vulnerability, got %d", len(vulns))
+	}
+	item, err := vulns["CVE-2021-44228"].Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.PublishedDate != "2021-12-10T00:00Z" || item.LastModifiedDate != "2022-04-06T00:00Z" {
+		t.Fatalf("wrong dates %q, %q", item.PublishedDate, item.LastModifiedDate)
+	}
+	if score := item.Impact.BaseMetricV3.CVSSV3.BaseScore; score != 10 {
+		t.Fatalf("wrong base score %.1f", score)
+	}
+
+	v := nvd.ToVuln(item)
+	for i, tc := range []struct {
+		cpe   string
+		match bool
+	}{
+		{"cpe:/a:org.apache.logging.log4j:log4j-core:2.14.1::~~~maven~~", true},
+		{"cpe:/a:org.apache.logging.log4j:log4j-core:2.12.2::~~~maven~~", false},
+		{"cpe:/a:org.apache.logging.log4j:log4j-core:2.15.0::~~~maven~~", false},
+		{"cpe:/a::%40example%2flog4js:1.1.0::~~~npm~~", true},
+		{"cpe:/a::%40example%2flog4js:2.0.5::~~~npm~~", false},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			attrs, err := wfn.Parse(tc.cpe)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if matched := len(v.Match([]*wfn.Attributes{attrs}, true)) != 0; matched != tc.match {
+				t.Fatalf("expecting match of %s to be %v, got %v", tc.cpe, tc.match, matched)
+			}
+		})
+	}
+}
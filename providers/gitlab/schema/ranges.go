@@ -0,0 +1,158 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// VersionRange is a range of affected versions, empty bounds are unlimited
+type VersionRange struct {
+	StartIncluding string
+	StartExcluding string
+	EndIncluding   string
+	EndExcluding   string
+}
+
+// ParseRange parses the affected range of the package of the given type
+// maven and nuget use interval notation, e.g. "[1.0,1.2),[2.0,2.1.3)"
+// other package types use comparators joined by "||", e.g. ">=1.0 <1.2||>=2.0 <2.1.3"
+func ParseRange(typ, expr string) ([]*VersionRange, error) {
+	expr = strings.TrimSpace(expr)
+	if expr == "" {
+		return nil, fmt.Errorf("empty range")
+	}
+
+	var ranges []*VersionRange
+	var err error
+	switch typ {
+	case "maven", "nuget":
+		ranges, err = parseIntervals(expr)
+	default:
+		ranges, err = parseComparators(expr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("can't parse range %q: %v", expr, err)
+	}
+
+	if typ == "go" {
+		// go versions have a v prefix, which isn't used in CPEs
+		for _, r := range ranges {
+			for _, v := range []*string{&r.StartIncluding, &r.StartExcluding, &r.EndIncluding, &r.EndExcluding} {
+				*v = strings.TrimPrefix(*v, "v")
+			}
+		}
+	}
+	return ranges, nil
+}
+
+// parseIntervals parses ranges in interval notation
+// [1.0] is exactly 1.0, (,1.0] is 1.0 or less, [1.2,) is 1.2 or more
+func parseIntervals(expr string) ([]*VersionRange, error) {
+	var ranges []*VersionRange
+	for expr != "" {
+		expr = strings.TrimLeft(expr, ", ")
+		if expr == "" {
+			break
+		}
+		if expr[0] != '[' && expr[0] != '(' {
+			return nil, fmt.Errorf("expecting an interval at %q", expr)
+		}
+		end := strings.IndexAny(expr, "])")
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated interval %q", expr)
+		}
+		interval := expr[1:end]
+		startIncluding, endIncluding := expr[0] == '[', expr[end] == ']'
+		expr = expr[end+1:]
+
+		var r VersionRange
+		bounds := strings.Split(interval, ",")
+		switch len(bounds) {
+		case 1:
+			v := strings.TrimSpace(bounds[0])
+			if v == "" || !startIncluding || !endIncluding {
+				return nil, fmt.Errorf("invalid interval %q", interval)
+			}
+			r.StartIncluding, r.EndIncluding = v, v
+		case 2:
+			if v := strings.TrimSpace(bounds[0]); v != "" {
+				if startIncluding {
+					r.StartIncluding = v
+				} else {
+					r.StartExcluding = v
+				}
+			}
+			if v := strings.TrimSpace(bounds[1]); v != "" {
+				if endIncluding {
+					r.EndIncluding = v
+				} else {
+					r.EndExcluding = v
+				}
+			}
+		default:
+			return nil, fmt.Errorf("invalid interval %q", interval)
+		}
+		ranges = append(ranges, &r)
+	}
+	return ranges, nil
+}
+
+// parseComparators parses ranges as comparators, comparators in the same range are separated by spaces or commas
+func parseComparators(expr string) ([]*VersionRange, error) {
+	var ranges []*VersionRange
+	for _, conj := range strings.Split(expr, "||") {
+		fields := strings.Fields(strings.Replace(conj, ",", " ", -1))
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("empty range")
+		}
+
+		var r VersionRange
+		for i := 0; i < len(fields); i++ {
+			comparator := fields[i]
+			op := comparator[:len(comparator)-len(strings.TrimLeft(comparator, "<>=!~^"))]
+			version := comparator[len(op):]
+			if version == "" && i+1 < len(fields) {
+				// operator separated from the version
+				i++
+				version = fields[i]
+			}
+			if version == "" {
+				return nil, fmt.Errorf("missing version in %q", conj)
+			}
+			switch op {
+			case "":
+				if version != "*" {
+					r.StartIncluding, r.EndIncluding = version, version
+				}
+			case ">=":
+				r.StartIncluding = version
+			case ">":
+				r.StartExcluding = version
+			case "<=":
+				r.EndIncluding = version
+			case "<":
+				r.EndExcluding = version
+			case "=", "==":
+				r.StartIncluding, r.EndIncluding = version, version
+			default:
+				return nil, fmt.Errorf("unsupported operator %q", op)
+			}
+		}
+		ranges = append(ranges, &r)
+	}
+	return ranges, nil
+}
@@ -0,0 +1,113 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/yaml"
+)
+
+// based on the GitLab advisory database, only the parts which are needed for conversion
+// https://gitlab.com/gitlab-org/security-products/gemnasium-db
+
+// Advisory is a single advisory file, it describes how one package is affected by one vulnerability
+type Advisory struct {
+	Identifier       string   `json:"identifier"`
+	Identifiers      []string `json:"identifiers,omitempty"`
+	PackageSlug      string   `json:"package_slug"`
+	Title            string   `json:"title,omitempty"`
+	Description      string   `json:"description,omitempty"`
+	Date             string   `json:"date,omitempty"`
+	PubDate          string   `json:"pubdate,omitempty"`
+	AffectedRange    string   `json:"affected_range"`
+	FixedVersions    []string `json:"fixed_versions,omitempty"`
+	AffectedVersions string   `json:"affected_versions,omitempty"`
+	NotImpacted      string   `json:"not_impacted,omitempty"`
+	Solution         string   `json:"solution,omitempty"`
+	URLs             []string `json:"urls,omitempty"`
+	CVSSv2           string   `json:"cvss_v2,omitempty"`
+	CVSSv3           string   `json:"cvss_v3,omitempty"`
+	UUID             string   `json:"uuid,omitempty"`
+	CWEIDs           []string `json:"cwe_ids,omitempty"`
+}
+
+// Package returns the package type and name from the package slug
+// e.g. maven/org.apache.logging.log4j/log4j-core is (maven, org.apache.logging.log4j/log4j-core)
+func (adv *Advisory) Package() (typ, name string) {
+	parts := strings.SplitN(adv.PackageSlug, "/", 2)
+	if len(parts) != 2 {
+		return "", adv.PackageSlug
+	}
+	return parts[0], parts[1]
+}
+
+// ParseAdvisory parses the advisory yaml file
+func ParseAdvisory(data []byte) (*Advisory, error) {
+	var adv Advisory
+	if err := yaml.Unmarshal(data, &adv); err != nil {
+		return nil, err
+	}
+	// block scalars keep their final line break
+	adv.Title = strings.TrimSpace(adv.Title)
+	adv.Description = strings.TrimSpace(adv.Description)
+	adv.Solution = strings.TrimSpace(adv.Solution)
+
+	if adv.Identifier == "" {
+		return nil, fmt.Errorf("advisory doesn't have an identifier")
+	}
+	if adv.PackageSlug == "" {
+		return nil, fmt.Errorf("advisory %s doesn't have a package slug", adv.Identifier)
+	}
+	return &adv, nil
+}
+
+// Vulnerability contains advisories for all packages affected by the same vulnerability
+type Vulnerability struct {
+	Identifier string      `json:"identifier"`
+	Advisories []*Advisory `json:"advisories"`
+}
+
+// Vulnerabilities groups advisories by their identifiers
+func Vulnerabilities(advs []*Advisory) map[string]*Vulnerability {
+	vulns := make(map[string]*Vulnerability)
+	for _, adv := range advs {
+		vuln, ok := vulns[adv.Identifier]
+		if !ok {
+			vuln = &Vulnerability{Identifier: adv.Identifier}
+			vulns[adv.Identifier] = vuln
+		}
+		vuln.Advisories = append(vuln.Advisories, adv)
+	}
+	for _, vuln := range vulns {
+		sort.Slice(vuln.Advisories, func(i, j int) bool {
+			return vuln.Advisories[i].PackageSlug < vuln.Advisories[j].PackageSlug
+		})
+	}
+	return vulns
+}
+
+// Modified returns the latest date any of the advisories was modified, in YYYY-MM-DD format
+func (vuln *Vulnerability) Modified() string {
+	var modified string
+	for _, adv := range vuln.Advisories {
+		if adv.Date > modified {
+			modified = adv.Date
+		}
+	}
+	return modified
+}
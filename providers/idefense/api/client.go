@@ -18,15 +18,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"net/url"
 	"time"
 
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/providers/idefense/schema"
 	"github.com/facebookincubator/nvdtools/providers/lib/client"
 	"github.com/facebookincubator/nvdtools/providers/lib/runner"
 	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -34,20 +36,54 @@ import (
 type Client struct {
 	client.Client
 	baseUrl string
-	apiKey  string
+	// header returns the headers authorizing a request
+	header func() (http.Header, error)
 }
 
 const (
 	pageSize              = 200
 	vulnerabilityEndpoint = "/rest/vulnerability/v0"
+	// TokenEndpoint is where OAuth tokens are issued, relative to the base url
+	TokenEndpoint = "/oauth2/token"
 )
 
 // NewClient creates an object which is used to query the iDefense API
+// the api key is sent in the Auth-Token header
 func NewClient(c client.Client, baseUrl, apiKey string) *Client {
 	return &Client{
 		Client:  c,
 		baseUrl: baseUrl,
-		apiKey:  apiKey,
+		header: func() (http.Header, error) {
+			return http.Header{"Auth-Token": {apiKey}}, nil
+		},
+	}
+}
+
+// NewOAuthClient creates an object which is used to query the Accenture threat intelligence API, which
+// authorizes requests with bearer tokens obtained using OAuth client credentials
+// tokens are fetched from tokenURL and cached until they expire
+func NewOAuthClient(c client.Client, baseUrl, tokenURL, clientID, clientSecret string) *Client {
+	conf := &clientcredentials.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		TokenURL:     tokenURL,
+	}
+	ctx := context.Background()
+	if hc, ok := client.Default().(*http.Client); ok {
+		// fetch tokens through the configured transport (proxy, certificates, ...)
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, hc)
+	}
+	tokens := conf.TokenSource(ctx)
+	return &Client{
+		Client:  c,
+		baseUrl: baseUrl,
+		header: func() (http.Header, error) {
+			tok, err := tokens.Token()
+			if err != nil {
+				return nil, errors.Wrap(err, "can't get oauth token")
+			}
+			return http.Header{"Authorization": {tok.Type() + " " + tok.AccessToken}}, nil
+		},
 	}
 }
 
@@ -73,7 +109,7 @@ func (c *Client) FetchAllVulnerabilities(ctx context.Context, since int64) (<-ch
 	numPages := (totalVulns-1)/pageSize + 1
 
 	// fetch pages concurrently
-	log.Printf("starting sync for %d vulnerabilities over %d pages\n", totalVulns, numPages)
+	logging.Infof("starting sync for %d vulnerabilities over %d pages", totalVulns, numPages)
 	eg, ctx := errgroup.WithContext(ctx)
 	for page := 1; page <= numPages; page++ {
 		page := page
@@ -98,7 +134,7 @@ func (c *Client) FetchAllVulnerabilities(ctx context.Context, since int64) (<-ch
 
 	go func() {
 		if err := eg.Wait(); err != nil {
-			log.Println(err)
+			logging.Errorf("%v", err)
 		}
 		close(output)
 	}()
@@ -117,14 +153,19 @@ func (c *Client) queryVulnerabilities(ctx context.Context, params map[string]int
 	}
 	u.RawQuery = query.Encode()
 
-	resp, err := client.Get(ctx, c, u.String(), http.Header{
-		"Auth-Token": {c.apiKey},
-	})
+	header, err := c.header()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(ctx, c, u.String(), header)
 	if err != nil {
 		return nil, err
 	}
 
 	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected response: %s", resp.Status)
+	}
 
 	// decode into json
 	var result schema.VulnerabilitySearchResults
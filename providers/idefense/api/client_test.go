@@ -0,0 +1,69 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientAuth(t *testing.T) {
+	var tokens int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == TokenEndpoint {
+			if id, secret, _ := r.BasicAuth(); id != "id" || secret != "secret" {
+				http.Error(w, "bad credentials", http.StatusUnauthorized)
+				return
+			}
+			tokens++
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"access_token": "tok", "token_type": "bearer", "expires_in": 3600}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer tok" && r.Header.Get("Auth-Token") != "key" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"total_size": 1, "results": [{"key": "1"}]}`)
+	}))
+	defer ts.Close()
+
+	cases := []struct {
+		client *Client
+		ok     bool
+	}{
+		{NewClient(http.DefaultClient, ts.URL, "key"), true},
+		{NewClient(http.DefaultClient, ts.URL, "wrong"), false},
+		{NewOAuthClient(http.DefaultClient, ts.URL, ts.URL+TokenEndpoint, "id", "secret"), true},
+		{NewOAuthClient(http.DefaultClient, ts.URL, ts.URL+TokenEndpoint, "id", "wrong"), false},
+	}
+	for i, c := range cases {
+		for j := 0; j < 2; j++ {
+			result, err := c.client.queryVulnerabilities(context.Background(), nil)
+			if c.ok && (err != nil || result.TotalSize != 1) {
+				t.Fatalf("case %d: unexpected result %+v, %v", i+1, result, err)
+			}
+			if !c.ok && err == nil {
+				t.Fatalf("case %d: expected an error", i+1)
+			}
+		}
+	}
+	if tokens != 1 {
+		t.Fatalf("token should be fetched once and reused, fetched %d times", tokens)
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/idefense/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+)
+
+func init() {
+	runner.Register(runner.Registration{
+		Name:    "idefense",
+		BaseURL: "https://api.intelgraph.idefense.com",
+		OptionalEnv: []string{
+			"IDEFENSE_TOKEN",
+			"IDEFENSE_CLIENT_ID",
+			"IDEFENSE_CLIENT_SECRET",
+			"IDEFENSE_TOKEN_URL",
+		},
+		New: func(c client.Client, baseURL string, env map[string]string) (runner.Provider, error) {
+			var client *Client
+			switch {
+			case env["IDEFENSE_CLIENT_ID"] != "" && env["IDEFENSE_CLIENT_SECRET"] != "":
+				tokenURL := env["IDEFENSE_TOKEN_URL"]
+				if tokenURL == "" {
+					tokenURL = baseURL + TokenEndpoint
+				}
+				client = NewOAuthClient(c, baseURL, tokenURL, env["IDEFENSE_CLIENT_ID"], env["IDEFENSE_CLIENT_SECRET"])
+			case env["IDEFENSE_TOKEN"] != "":
+				client = NewClient(c, baseURL, env["IDEFENSE_TOKEN"])
+			default:
+				return nil, fmt.Errorf("please set IDEFENSE_TOKEN, or IDEFENSE_CLIENT_ID and IDEFENSE_CLIENT_SECRET in environment")
+			}
+			return runner.ConvertibleProvider(func(ctx context.Context, since time.Time) (<-chan runner.Convertible, error) {
+				return client.FetchAllVulnerabilities(ctx, since.Unix())
+			}), nil
+		},
+		Read: runner.ReadMap(func() runner.Convertible { return new(schema.Vulnerability) }),
+	})
+}
@@ -40,6 +40,7 @@ func (item *Vulnerability) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
 		return nil, errors.Wrap(err, "can't create configurations")
 	}
 
+	m := currentMapping()
 	return &nvd.NVDCVEFeedJSON10DefCVEItem{
 		CVE: &nvd.CVEJSON40{
 			CVEDataMeta: &nvd.CVEJSON40CVEDataMeta{
@@ -51,7 +52,7 @@ func (item *Vulnerability) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
 			DataVersion: cveDataVersion,
 			Description: &nvd.CVEJSON40Description{
 				DescriptionData: []*nvd.CVEJSON40LangString{
-					{Lang: "en", Value: item.Description},
+					{Lang: "en", Value: m.description(item)},
 				},
 			},
 			Problemtype: &nvd.CVEJSON40Problemtype{
@@ -63,25 +64,10 @@ func (item *Vulnerability) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
 					},
 				},
 			},
-			References: item.makeReferences(),
-		},
-		Configurations: configurations,
-		Impact: &nvd.NVDCVEFeedJSON10DefImpact{
-			BaseMetricV2: &nvd.NVDCVEFeedJSON10DefImpactBaseMetricV2{
-				CVSSV2: &nvd.CVSSV20{
-					BaseScore:     item.Cvss2BaseScore,
-					TemporalScore: item.Cvss2TemporalScore,
-					VectorString:  item.Cvss2,
-				},
-			},
-			BaseMetricV3: &nvd.NVDCVEFeedJSON10DefImpactBaseMetricV3{
-				CVSSV3: &nvd.CVSSV30{
-					BaseScore:     item.Cvss3BaseScore,
-					TemporalScore: item.Cvss3TemporalScore,
-					VectorString:  item.Cvss3,
-				},
-			},
+			References: m.references(item),
 		},
+		Configurations:   configurations,
+		Impact:           m.impact(item),
 		LastModifiedDate: lastModifiedDate,
 		PublishedDate:    publishedDate,
 	}, nil
@@ -91,39 +77,6 @@ func (item *Vulnerability) ID() string {
 	return "idefense-" + item.Key
 }
 
-func (item *Vulnerability) makeReferences() *nvd.CVEJSON40References {
-	if len(item.SourcesExternal) == 0 {
-		return nil
-	}
-
-	var refsData []*nvd.CVEJSON40Reference
-	addRef := func(name, url string) {
-		refsData = append(refsData, &nvd.CVEJSON40Reference{
-			Name: name,
-			URL:  url,
-		})
-	}
-
-	for _, source := range item.SourcesExternal {
-		addRef(source.Name, source.URL)
-	}
-	if item.AlsoIdentifies != nil {
-		for _, vuln := range item.AlsoIdentifies.Vulnerability {
-			addRef(vuln.Key, "")
-		}
-	}
-	for _, poc := range item.Pocs {
-		addRef(poc.PocName, poc.URL)
-	}
-	for _, fix := range item.VendorFixExternal {
-		addRef(fix.ID, fix.URL)
-	}
-
-	return &nvd.CVEJSON40References{
-		ReferenceData: refsData,
-	}
-}
-
 func (item *Vulnerability) makeConfigurations() (*nvd.NVDCVEFeedJSON10DefConfigurations, error) {
 	configs := item.findConfigurations()
 	if len(configs) == 0 {
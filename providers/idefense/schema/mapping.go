@@ -0,0 +1,228 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+)
+
+// Mapping controls which fields of iDefense vulnerabilities end up in the converted CVE items
+type Mapping struct {
+	// Description lists fields which are joined into the description, in order; empty fields are skipped
+	// one of: title, description, analysis, mitigation
+	Description []string `json:"description"`
+	// References lists sources of references, in order
+	// one of: sources_external, also_identifies, alias, pocs, vendor_fix_external, fixed_by_patches, workarounds
+	References []string `json:"references"`
+	// CVSS2 and CVSS3 select which score is used as base score: base, temporal (falls back to base if it's not
+	// set) or none, to leave the metric out
+	CVSS2 string `json:"cvss2"`
+	CVSS3 string `json:"cvss3"`
+}
+
+// DefaultMapping returns the mapping used if no other is set
+func DefaultMapping() *Mapping {
+	return &Mapping{
+		Description: []string{"description"},
+		References:  []string{"sources_external", "also_identifies", "pocs", "vendor_fix_external"},
+		CVSS2:       "base",
+		CVSS3:       "base",
+	}
+}
+
+var (
+	descriptionFields = map[string]func(*Vulnerability) string{
+		"title":       func(v *Vulnerability) string { return v.Title },
+		"description": func(v *Vulnerability) string { return v.Description },
+		"analysis":    func(v *Vulnerability) string { return v.Analysis },
+		"mitigation":  func(v *Vulnerability) string { return v.Mitigation },
+	}
+
+	referenceFields = map[string]func(*Vulnerability) []*nvd.CVEJSON40Reference{
+		"sources_external": func(v *Vulnerability) (refs []*nvd.CVEJSON40Reference) {
+			for _, source := range v.SourcesExternal {
+				refs = append(refs, &nvd.CVEJSON40Reference{Name: source.Name, URL: source.URL})
+			}
+			return refs
+		},
+		"also_identifies": func(v *Vulnerability) (refs []*nvd.CVEJSON40Reference) {
+			if v.AlsoIdentifies == nil {
+				return nil
+			}
+			for _, vuln := range v.AlsoIdentifies.Vulnerability {
+				refs = append(refs, &nvd.CVEJSON40Reference{Name: vuln.Key})
+			}
+			return refs
+		},
+		"alias": func(v *Vulnerability) (refs []*nvd.CVEJSON40Reference) {
+			for _, alias := range v.Alias {
+				refs = append(refs, &nvd.CVEJSON40Reference{Name: alias})
+			}
+			return refs
+		},
+		"pocs": func(v *Vulnerability) (refs []*nvd.CVEJSON40Reference) {
+			for _, poc := range v.Pocs {
+				refs = append(refs, &nvd.CVEJSON40Reference{Name: poc.PocName, URL: poc.URL})
+			}
+			return refs
+		},
+		"vendor_fix_external": func(v *Vulnerability) (refs []*nvd.CVEJSON40Reference) {
+			for _, fix := range v.VendorFixExternal {
+				refs = append(refs, &nvd.CVEJSON40Reference{Name: fix.ID, URL: fix.URL})
+			}
+			return refs
+		},
+		"fixed_by_patches": func(v *Vulnerability) (refs []*nvd.CVEJSON40Reference) {
+			if v.FixedBy == nil {
+				return nil
+			}
+			for _, tech := range v.FixedBy.VulnTechs {
+				for _, patch := range tech.Patches {
+					refs = append(refs, &nvd.CVEJSON40Reference{Name: patch.ID, URL: patch.URL})
+				}
+			}
+			for _, pkg := range v.FixedBy.Packages {
+				for _, patch := range pkg.Patches {
+					refs = append(refs, &nvd.CVEJSON40Reference{Name: patch.ID, URL: patch.URL})
+				}
+			}
+			return refs
+		},
+		"workarounds": func(v *Vulnerability) (refs []*nvd.CVEJSON40Reference) {
+			for _, w := range v.Workarounds {
+				refs = append(refs, &nvd.CVEJSON40Reference{Name: w.Comment, URL: w.URLReference})
+			}
+			return refs
+		},
+	}
+
+	scoreChoices = map[string]bool{"base": true, "temporal": true, "none": true}
+)
+
+// Validate returns an error if the mapping refers to unknown fields
+func (m *Mapping) Validate() error {
+	for _, f := range m.Description {
+		if descriptionFields[f] == nil {
+			return fmt.Errorf("unknown description field %q, should be one of title, description, analysis, mitigation", f)
+		}
+	}
+	for _, f := range m.References {
+		if referenceFields[f] == nil {
+			return fmt.Errorf("unknown references field %q, should be one of sources_external, also_identifies, alias, pocs, vendor_fix_external, fixed_by_patches, workarounds", f)
+		}
+	}
+	for _, s := range []string{m.CVSS2, m.CVSS3} {
+		if !scoreChoices[s] {
+			return fmt.Errorf("unknown cvss score %q, should be one of base, temporal, none", s)
+		}
+	}
+	return nil
+}
+
+// LoadMapping reads the mapping from a JSON file; fields which aren't set in the file are taken from
+// the default mapping
+func LoadMapping(path string) (*Mapping, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open mapping file: %v", err)
+	}
+	defer f.Close()
+	m := DefaultMapping()
+	dec := json.NewDecoder(f)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(m); err != nil {
+		return nil, fmt.Errorf("can't decode mapping file %q: %v", path, err)
+	}
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid mapping file %q: %v", path, err)
+	}
+	return m, nil
+}
+
+var (
+	mappingMu sync.RWMutex
+	mapping   = DefaultMapping()
+)
+
+// SetMapping sets the mapping used when converting vulnerabilities
+func SetMapping(m *Mapping) {
+	mappingMu.Lock()
+	defer mappingMu.Unlock()
+	mapping = m
+}
+
+func currentMapping() *Mapping {
+	mappingMu.RLock()
+	defer mappingMu.RUnlock()
+	return mapping
+}
+
+func (m *Mapping) description(v *Vulnerability) string {
+	var parts []string
+	for _, f := range m.Description {
+		if s := strings.TrimSpace(descriptionFields[f](v)); s != "" {
+			parts = append(parts, s)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+func (m *Mapping) references(v *Vulnerability) *nvd.CVEJSON40References {
+	var refs []*nvd.CVEJSON40Reference
+	for _, f := range m.References {
+		refs = append(refs, referenceFields[f](v)...)
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	return &nvd.CVEJSON40References{ReferenceData: refs}
+}
+
+// score returns the base score to use according to the choice
+func score(choice string, base, temporal float64) float64 {
+	if choice == "temporal" && temporal != 0 {
+		return temporal
+	}
+	return base
+}
+
+func (m *Mapping) impact(v *Vulnerability) *nvd.NVDCVEFeedJSON10DefImpact {
+	var impact nvd.NVDCVEFeedJSON10DefImpact
+	if m.CVSS2 != "none" {
+		impact.BaseMetricV2 = &nvd.NVDCVEFeedJSON10DefImpactBaseMetricV2{
+			CVSSV2: &nvd.CVSSV20{
+				BaseScore:     score(m.CVSS2, v.Cvss2BaseScore, v.Cvss2TemporalScore),
+				TemporalScore: v.Cvss2TemporalScore,
+				VectorString:  v.Cvss2,
+			},
+		}
+	}
+	if m.CVSS3 != "none" {
+		impact.BaseMetricV3 = &nvd.NVDCVEFeedJSON10DefImpactBaseMetricV3{
+			CVSSV3: &nvd.CVSSV30{
+				BaseScore:     score(m.CVSS3, v.Cvss3BaseScore, v.Cvss3TemporalScore),
+				TemporalScore: v.Cvss3TemporalScore,
+				VectorString:  v.Cvss3,
+			},
+		}
+	}
+	return &impact
+}
@@ -0,0 +1,113 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var testVuln = &Vulnerability{
+	Key:                "12345",
+	Title:              "Example Buffer Overflow",
+	Description:        "A buffer overflow.",
+	Analysis:           " ",
+	Mitigation:         "Upgrade.",
+	Alias:              []string{"CVE-2020-0001"},
+	Cvss2BaseScore:     7.5,
+	Cvss2TemporalScore: 6.1,
+	Cvss3BaseScore:     9.8,
+	Cvss3TemporalScore: 8.5,
+	Cvss3:              "CVSS:3.0/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H",
+	SourcesExternal:    []*VulnerabilitySource{{Name: "vendor", URL: "https://example.com/advisory"}},
+	Workarounds:        []*VulnerabilityWorkaround{{Comment: "disable it", URLReference: "https://example.com/kb"}},
+}
+
+func TestDefaultMapping(t *testing.T) {
+	m := DefaultMapping()
+	if err := m.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if d := m.description(testVuln); d != "A buffer overflow." {
+		t.Fatalf("wrong description %q", d)
+	}
+	refs := m.references(testVuln).ReferenceData
+	if len(refs) != 1 || refs[0].URL != "https://example.com/advisory" {
+		t.Fatalf("wrong references %+v", refs)
+	}
+	impact := m.impact(testVuln)
+	if impact.BaseMetricV2.CVSSV2.BaseScore != 7.5 || impact.BaseMetricV3.CVSSV3.BaseScore != 9.8 {
+		t.Fatalf("wrong impact %+v", impact)
+	}
+}
+
+func TestLoadMapping(t *testing.T) {
+	dir, err := ioutil.TempDir("", "idefense")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	write := func(content string) string {
+		path := filepath.Join(dir, "mapping.json")
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	m, err := LoadMapping(write(`{
+		"description": ["title", "description", "analysis", "mitigation"],
+		"references": ["alias", "workarounds"],
+		"cvss2": "none",
+		"cvss3": "temporal"
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := m.description(testVuln); d != "Example Buffer Overflow\n\nA buffer overflow.\n\nUpgrade." {
+		t.Fatalf("wrong description %q", d)
+	}
+	refs := m.references(testVuln).ReferenceData
+	if len(refs) != 2 || refs[0].Name != "CVE-2020-0001" || refs[1].URL != "https://example.com/kb" {
+		t.Fatalf("wrong references %+v", refs)
+	}
+	impact := m.impact(testVuln)
+	if impact.BaseMetricV2 != nil || impact.BaseMetricV3.CVSSV3.BaseScore != 8.5 {
+		t.Fatalf("wrong impact %+v", impact)
+	}
+
+	// fields which aren't set are taken from the default mapping
+	if m, err = LoadMapping(write(`{"cvss3": "temporal"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Description) != 1 || m.CVSS2 != "base" {
+		t.Fatalf("default values not used: %+v", m)
+	}
+
+	for _, bad := range []string{
+		`{"description": ["summary"]}`,
+		`{"references": ["urls"]}`,
+		`{"cvss2": "max"}`,
+		`{"cvs2": "base"}`,
+		`not json`,
+	} {
+		if _, err := LoadMapping(write(bad)); err == nil {
+			t.Errorf("expected an error for mapping %s", bad)
+		}
+	}
+}
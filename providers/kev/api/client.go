@@ -0,0 +1,83 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/kev/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+)
+
+const catalogPath = "/known_exploited_vulnerabilities.json"
+
+// Client downloads the CISA KEV catalog
+type Client struct {
+	client.Client
+	baseURL string
+}
+
+// NewClient creates an object which is used to download the CISA KEV catalog
+// base url should point to the feeds directory, e.g. https://www.cisa.gov/sites/default/files/feeds
+func NewClient(c client.Client, baseURL string) *Client {
+	return &Client{
+		Client:  c,
+		baseURL: baseURL,
+	}
+}
+
+// FetchCatalog downloads the whole catalog
+func (c *Client) FetchCatalog(ctx context.Context) (*schema.Catalog, error) {
+	resp, err := client.Get(ctx, c, c.baseURL+catalogPath, http.Header{})
+	if err != nil {
+		return nil, fmt.Errorf("can't download catalog: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var catalog schema.Catalog
+	if err := json.NewDecoder(resp.Body).Decode(&catalog); err != nil {
+		return nil, fmt.Errorf("can't decode catalog: %v", err)
+	}
+	return &catalog, nil
+}
+
+// FetchAllVulnerabilities returns vulnerabilities which were added to the catalog since the given time
+func (c *Client) FetchAllVulnerabilities(ctx context.Context, since int64) (<-chan runner.Convertible, error) {
+	catalog, err := c.FetchCatalog(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var sinceDate string
+	if since > 0 {
+		sinceDate = time.Unix(since, 0).UTC().Format("2006-01-02")
+	}
+
+	output := make(chan runner.Convertible)
+	go func() {
+		defer close(output)
+		for _, vuln := range catalog.Vulnerabilities {
+			if vuln.DateAdded >= sinceDate {
+				output <- vuln
+			}
+		}
+	}()
+	return output, nil
+}
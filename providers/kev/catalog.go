@@ -0,0 +1,73 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kev
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+
+	"github.com/facebookincubator/nvdtools/providers/kev/schema"
+)
+
+// LoadCatalog loads the catalog from the given file
+// file can be either the catalog published by CISA, or the output of kev2nvd before conversion
+func LoadCatalog(path string) (*schema.Catalog, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open %q: %v", path, err)
+	}
+	defer f.Close()
+	return ReadCatalog(f)
+}
+
+// ReadCatalog reads the catalog in any of the formats supported by LoadCatalog
+func ReadCatalog(r io.Reader) (*schema.Catalog, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("can't read catalog: %v", err)
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("can't decode catalog: %v", err)
+	}
+
+	var catalog schema.Catalog
+	if _, ok := fields["vulnerabilities"]; ok {
+		if err := json.Unmarshal(data, &catalog); err != nil {
+			return nil, fmt.Errorf("can't decode catalog: %v", err)
+		}
+		return &catalog, nil
+	}
+
+	// map of cve id to vulnerability
+	vulns := make(map[string]*schema.Vulnerability, len(fields))
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&vulns); err != nil {
+		return nil, fmt.Errorf("can't decode vulnerabilities: %v", err)
+	}
+	for _, vuln := range vulns {
+		catalog.Vulnerabilities = append(catalog.Vulnerabilities, vuln)
+	}
+	sort.Slice(catalog.Vulnerabilities, func(i, j int) bool {
+		return catalog.Vulnerabilities[i].CVEID < catalog.Vulnerabilities[j].CVEID
+	})
+	catalog.Count = len(catalog.Vulnerabilities)
+	return &catalog, nil
+}
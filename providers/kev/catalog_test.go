@@ -0,0 +1,142 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kev
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	nvdschema "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const testCatalog = `{
+  "title": "CISA Catalog of Known Exploited Vulnerabilities",
+  "catalogVersion": "2023.10.12",
+  "dateReleased": "2023-10-12T15:04:29.6523Z",
+  "count": 2,
+  "vulnerabilities": [
+    {
+      "cveID": "CVE-2021-44228",
+      "vendorProject": "Apache",
+      "product": "Log4j2",
+      "vulnerabilityName": "Apache Log4j2 Remote Code Execution Vulnerability",
+      "dateAdded": "2021-12-10",
+      "shortDescription": "Apache Log4j2 contains a vulnerability where JNDI features do not protect against attacker-controlled JNDI-related endpoints.",
+      "requiredAction": "Apply updates per vendor instructions.",
+      "dueDate": "2021-12-24",
+      "knownRansomwareCampaignUse": "Known",
+      "notes": "https://nvd.nist.gov/vuln/detail/CVE-2021-44228 ; https://logging.apache.org/log4j/2.x/security.html",
+      "cwes": ["CWE-20", "CWE-917"]
+    },
+    {
+      "cveID": "CVE-2016-0165",
+      "vendorProject": "Microsoft",
+      "product": "Win32k",
+      "vulnerabilityName": "Microsoft Win32k Privilege Escalation Vulnerability",
+      "dateAdded": "2022-04-15",
+      "shortDescription": "Microsoft Win32k contains an unspecified vulnerability due to the Win32k component failing to properly handle objects in memory.",
+      "requiredAction": "Apply updates per vendor instructions.",
+      "dueDate": "2022-05-06",
+      "knownRansomwareCampaignUse": "Unknown",
+      "notes": ""
+    }
+  ]
+}`
+
+func TestReadCatalog(t *testing.T) {
+	catalog, err := ReadCatalog(strings.NewReader(testCatalog))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(catalog.Vulnerabilities) != 2 {
+		t.Fatalf("expecting 2 vulnerabilities, got %d", len(catalog.Vulnerabilities))
+	}
+
+	// the output of kev2nvd
+	catalog, err = ReadCatalog(strings.NewReader(`{"CVE-2021-44228": {"cveID": "CVE-2021-44228", "dateAdded": "2021-12-10"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(catalog.Vulnerabilities) != 1 || catalog.Vulnerabilities[0].DateAdded != "2021-12-10" {
+		t.Fatalf("wrong catalog %+v", catalog)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	catalog, err := ReadCatalog(strings.NewReader(testCatalog))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := catalog.Vulnerabilities[0].Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.KnownExploited == nil || item.KnownExploited.DateAdded != "2021-12-10" {
+		t.Fatalf("item isn't annotated: %+v", item.KnownExploited)
+	}
+	if item.PublishedDate != "2021-12-10T00:00Z" {
+		t.Fatalf("wrong published date %q", item.PublishedDate)
+	}
+	// name and two urls from notes
+	if n := len(item.CVE.References.ReferenceData); n != 3 {
+		t.Fatalf("expecting 3 references, got %d", n)
+	}
+
+	v := nvd.ToVuln(item)
+	for i, tc := range []struct {
+		cpe   string
+		match bool
+	}{
+		{"cpe:/a:apache:log4j2:2.14.1", true},
+		{"cpe:/a:apache:log4j:2.14.1", false},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			attrs, err := wfn.Parse(tc.cpe)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if matched := len(v.Match([]*wfn.Attributes{attrs}, false)) != 0; matched != tc.match {
+				t.Fatalf("expecting match of %s to be %v, got %v", tc.cpe, tc.match, matched)
+			}
+		})
+	}
+}
+
+func TestAnnotate(t *testing.T) {
+	catalog, err := ReadCatalog(strings.NewReader(testCatalog))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newItem := func(id string) *nvdschema.NVDCVEFeedJSON10DefCVEItem {
+		return &nvdschema.NVDCVEFeedJSON10DefCVEItem{
+			CVE: &nvdschema.CVEJSON40{CVEDataMeta: &nvdschema.CVEJSON40CVEDataMeta{ID: id}},
+		}
+	}
+	items := []*nvdschema.NVDCVEFeedJSON10DefCVEItem{newItem("CVE-2016-0165"), newItem("CVE-2020-0001")}
+	if n := catalog.Annotate(items); n != 1 {
+		t.Fatalf("expecting 1 annotated item, got %d", n)
+	}
+	if ke := items[0].KnownExploited; ke == nil || ke.DateAdded != "2022-04-15" || ke.DueDate != "2022-05-06" {
+		t.Fatalf("wrong annotation %+v", ke)
+	}
+	if items[1].KnownExploited != nil {
+		t.Fatalf("item shouldn't be annotated")
+	}
+}
@@ -0,0 +1,177 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const (
+	cveDataVersion = "4.0"
+	dateLayout     = "2006-01-02"
+)
+
+// ID is a part of the runner.Convertible interface
+func (vuln *Vulnerability) ID() string {
+	return vuln.CVEID
+}
+
+// Convert is a part of the runner.Convertible interface
+// the catalog doesn't list affected versions, so all versions of the product are matched
+func (vuln *Vulnerability) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	configurations, err := vuln.newConfigurations()
+	if err != nil {
+		return nil, fmt.Errorf("can't create configurations for %s: %v", vuln.CVEID, err)
+	}
+
+	item := nvd.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &nvd.CVEJSON40{
+			CVEDataMeta: &nvd.CVEJSON40CVEDataMeta{
+				ID:       vuln.ID(),
+				ASSIGNER: "cisa.gov",
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: cveDataVersion,
+			Description: &nvd.CVEJSON40Description{
+				DescriptionData: []*nvd.CVEJSON40LangString{
+					{
+						Lang:  "en",
+						Value: vuln.ShortDescription,
+					},
+				},
+			},
+			Problemtype: vuln.newProblemType(),
+			References:  vuln.newReferences(),
+		},
+		Configurations:   configurations,
+		KnownExploited:   vuln.KnownExploited(),
+		LastModifiedDate: kevDateToNVD(vuln.DateAdded),
+		PublishedDate:    kevDateToNVD(vuln.DateAdded),
+	}
+
+	return &item, nil
+}
+
+// KnownExploited returns the annotation which is set on NVD items of the vulnerability
+func (vuln *Vulnerability) KnownExploited() *nvd.NVDCVEFeedJSON10DefKnownExploited {
+	return &nvd.NVDCVEFeedJSON10DefKnownExploited{
+		DateAdded:                  vuln.DateAdded,
+		DueDate:                    vuln.DueDate,
+		RequiredAction:             vuln.RequiredAction,
+		KnownRansomwareCampaignUse: vuln.KnownRansomwareCampaignUse,
+	}
+}
+
+// Annotate sets the known exploited annotation on items which are in the catalog
+// returns the number of annotated items
+func (c *Catalog) Annotate(items []*nvd.NVDCVEFeedJSON10DefCVEItem) int {
+	idx := c.Index()
+	var n int
+	for _, item := range items {
+		if item == nil || item.CVE == nil || item.CVE.CVEDataMeta == nil {
+			continue
+		}
+		if vuln, ok := idx[item.CVE.CVEDataMeta.ID]; ok {
+			item.KnownExploited = vuln.KnownExploited()
+			n++
+		}
+	}
+	return n
+}
+
+func (vuln *Vulnerability) newProblemType() *nvd.CVEJSON40Problemtype {
+	if len(vuln.CWEs) == 0 {
+		return nil
+	}
+	data := &nvd.CVEJSON40ProblemtypeProblemtypeData{}
+	for _, cwe := range vuln.CWEs {
+		data.Description = append(data.Description, &nvd.CVEJSON40LangString{
+			Lang:  "en",
+			Value: cwe,
+		})
+	}
+	return &nvd.CVEJSON40Problemtype{
+		ProblemtypeData: []*nvd.CVEJSON40ProblemtypeProblemtypeData{data},
+	}
+}
+
+// newReferences adds the name of the vulnerability and urls from notes, which are separated by semicolons
+func (vuln *Vulnerability) newReferences() *nvd.CVEJSON40References {
+	refs := &nvd.CVEJSON40References{
+		ReferenceData: []*nvd.CVEJSON40Reference{
+			{Name: vuln.VulnerabilityName},
+		},
+	}
+	for _, note := range strings.Split(vuln.Notes, ";") {
+		if note = strings.TrimSpace(note); strings.HasPrefix(note, "http") {
+			refs.ReferenceData = append(refs.ReferenceData, &nvd.CVEJSON40Reference{
+				Name: note,
+				URL:  note,
+			})
+		}
+	}
+	return refs
+}
+
+func (vuln *Vulnerability) newConfigurations() (*nvd.NVDCVEFeedJSON10DefConfigurations, error) {
+	attrs, err := wfn.NewAttributesBuilder().
+		Vendor(strings.ToLower(vuln.VendorProject)).
+		Product(strings.ToLower(vuln.Product)).
+		Build()
+	if err != nil {
+		return nil, err
+	}
+	cpe23URI := attrs.BindToFmtString()
+
+	return &nvd.NVDCVEFeedJSON10DefConfigurations{
+		CVEDataVersion: cveDataVersion,
+		Nodes: []*nvd.NVDCVEFeedJSON10DefNode{
+			{
+				Operator: "OR",
+				CPEMatch: []*nvd.NVDCVEFeedJSON10DefCPEMatch{
+					{
+						CPEName: []*nvd.NVDCVEFeedJSON10DefCPEName{
+							{
+								Cpe22Uri: attrs.BindToURI(),
+								Cpe23Uri: cpe23URI,
+							},
+						},
+						Cpe23Uri:   cpe23URI,
+						Vulnerable: true,
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func kevDateToNVD(s string) string {
+	if s == "" {
+		return ""
+	}
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		logging.Recordf("cannot parse kev date: %v", err)
+		return s
+	}
+	return t.Format(nvd.TimeLayout)
+}
@@ -0,0 +1,50 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+// based on the CISA Known Exploited Vulnerabilities catalog
+// https://www.cisa.gov/sites/default/files/feeds/known_exploited_vulnerabilities_schema.json
+
+// Catalog is the list of all known exploited vulnerabilities
+type Catalog struct {
+	Title           string           `json:"title"`
+	CatalogVersion  string           `json:"catalogVersion"`
+	DateReleased    string           `json:"dateReleased"`
+	Count           int              `json:"count"`
+	Vulnerabilities []*Vulnerability `json:"vulnerabilities"`
+}
+
+type Vulnerability struct {
+	CVEID                      string   `json:"cveID"`
+	VendorProject              string   `json:"vendorProject"`
+	Product                    string   `json:"product"`
+	VulnerabilityName          string   `json:"vulnerabilityName"`
+	DateAdded                  string   `json:"dateAdded"`
+	ShortDescription           string   `json:"shortDescription"`
+	RequiredAction             string   `json:"requiredAction"`
+	DueDate                    string   `json:"dueDate"`
+	KnownRansomwareCampaignUse string   `json:"knownRansomwareCampaignUse,omitempty"`
+	Notes                      string   `json:"notes,omitempty"`
+	CWEs                       []string `json:"cwes,omitempty"`
+}
+
+// Index returns vulnerabilities from the catalog by their CVE ids
+func (c *Catalog) Index() map[string]*Vulnerability {
+	idx := make(map[string]*Vulnerability, len(c.Vulnerabilities))
+	for _, vuln := range c.Vulnerabilities {
+		idx[vuln.CVEID] = vuln
+	}
+	return idx
+}
@@ -26,11 +26,6 @@ type Client interface {
 	Get(url string) (*http.Response, error)
 }
 
-// Default returns the default http client to use
-func Default() Client {
-	return http.DefaultClient
-}
-
 // Get will create a GET request with given headers and call Do on the client
 func Get(ctx context.Context, c Client, url string, header http.Header) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
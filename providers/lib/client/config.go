@@ -26,6 +26,7 @@ var userAgentRe = regexp.MustCompile("^[[:ascii:]]+$")
 // Config is used to configure a client
 type Config struct {
 	UserAgent         string
+	Transport         Transport
 	numRetries        int
 	retryDelay        time.Duration
 	retryPolicy       RetryPolicy
@@ -37,11 +38,11 @@ type Config struct {
 func (conf *Config) AddFlags() {
 	flag.StringVar(&conf.UserAgent, "user-agent", conf.UserAgent, "which user agent to use when making requests")
 	flag.IntVar(&conf.numRetries, "num-retries", 0, "how many times will specified statuses get retried. 0 means no retries")
-	// TODO implement exponential backoff (for some statuses?)
-	flag.DurationVar(&conf.retryDelay, "retry-delay", time.Second, "delay between each retry")
+	flag.DurationVar(&conf.retryDelay, "retry-delay", time.Second, "delay before the first retry, it's doubled after each retry")
 	flag.Var(&conf.retryPolicy, "retry", "which http statuses to retry. empty string means no retries, all means retry all, or provide a comma separated list of status codes")
 	flag.IntVar(&conf.requestsPerPeriod, "requests-per-period", 0, "how many requests per period to make. 0 means no throttling")
 	flag.DurationVar(&conf.period, "period", time.Second, "period in which requests are capped by the requests-per-period flag")
+	conf.Transport.AddFlags(flag.CommandLine)
 }
 
 func (conf *Config) Validate() error {
@@ -15,11 +15,13 @@
 package client
 
 import (
+	"context"
 	"net/http"
 )
 
+// executor runs the request function, ctx is the context of the request
 type executor interface {
-	execute(func() (*http.Response, error)) (*http.Response, error)
+	execute(ctx context.Context, f func() (*http.Response, error)) (*http.Response, error)
 }
 
 type executorClient struct {
@@ -29,14 +31,14 @@ type executorClient struct {
 
 // Do is a part of the Client interface
 func (c *executorClient) Do(req *http.Request) (*http.Response, error) {
-	return c.execute(func() (*http.Response, error) {
+	return c.execute(req.Context(), func() (*http.Response, error) {
 		return c.Client.Do(req)
 	})
 }
 
 // Get is a part of the Client interface
 func (c *executorClient) Get(url string) (*http.Response, error) {
-	return c.execute(func() (*http.Response, error) {
+	return c.execute(context.Background(), func() (*http.Response, error) {
 		return c.Client.Get(url)
 	})
 }
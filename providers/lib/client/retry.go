@@ -15,10 +15,12 @@
 package client
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"time"
@@ -82,7 +84,10 @@ func Retry(statuses ...int) RetryPolicy {
 // WithRetries will retry all given requests for the specified number of times
 //	- if status is 200, returns
 //	- if status is covered by the retry policy and hasn't been retried the total number of times, retry
+//	- if the request failed before getting a response (e.g. connection reset), retry as well,
+//	  unless the context of the request is done
 //	- otherwise, fail the request
+// delay between retries is doubled after each retry, waiting stops when the context of the request is done
 func WithRetries(c Client, retries int, delay time.Duration, rp RetryPolicy) Client {
 	if retries <= 0 {
 		// if no retries, return the normal client
@@ -97,12 +102,19 @@ type retryExecutor struct {
 	shouldRetry RetryPolicy
 }
 
-func (c *retryExecutor) execute(f func() (*http.Response, error)) (*http.Response, error) {
+func (c *retryExecutor) execute(ctx context.Context, f func() (*http.Response, error)) (*http.Response, error) {
 	delay := c.delay
 	for retry := 0; retry <= c.retries; retry++ {
 		resp, err := f()
 		if err != nil {
-			return resp, err
+			if retry == c.retries || ctx.Err() != nil || isContextErr(err) {
+				return resp, err
+			}
+			if err := sleep(ctx, delay); err != nil {
+				return nil, err
+			}
+			delay *= backoff
+			continue
 		}
 
 		if resp.StatusCode == http.StatusOK {
@@ -121,11 +133,51 @@ func (c *retryExecutor) execute(f func() (*http.Response, error)) (*http.Respons
 
 		// retry if have more retries left
 
+		resp.Body.Close()
 		if retry != c.retries {
-			time.Sleep(delay)
+			if err := sleep(ctx, delay); err != nil {
+				return nil, err
+			}
 		}
 		delay *= backoff
 	}
 	// no more retries left
 	return nil, FailedRetries(c.retries)
 }
+
+// isContextErr returns whether the request failed because its context was cancelled or its deadline exceeded
+func isContextErr(err error) bool {
+	if uerr, ok := err.(*url.Error); ok {
+		err = uerr.Err
+	}
+	return err == context.Canceled || err == context.DeadlineExceeded
+}
+
+// sleep waits for the delay, it returns the error of the context if it's done before that
+func sleep(ctx context.Context, delay time.Duration) error {
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RetryAfter returns how long to wait before retrying a rate limited request, according to the Retry-After
+// header of the response; fallback is returned if the header isn't set or can't be parsed
+func RetryAfter(h http.Header, fallback time.Duration) time.Duration {
+	if s := h.Get("Retry-After"); s != "" {
+		if secs, err := strconv.Atoi(s); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second
+		}
+		if t, err := http.ParseTime(s); err == nil {
+			if d := time.Until(t); d > 0 {
+				return d
+			}
+			return 0
+		}
+	}
+	return fallback
+}
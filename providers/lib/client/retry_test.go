@@ -0,0 +1,77 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryCancelled(t *testing.T) {
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	c := WithRetries(http.DefaultClient, 5, time.Minute, RetryAll)
+
+	// waiting between retries stops when the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := Get(ctx, c, srv.URL, http.Header{}); err != context.DeadlineExceeded {
+		t.Fatalf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+	if d := time.Since(start); d > 10*time.Second {
+		t.Fatalf("retries weren't stopped, took %v", d)
+	}
+
+	// requests failing because the context is done aren't retried
+	atomic.StoreInt32(&requests, 0)
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	if _, err := Get(ctx, c, srv.URL, http.Header{}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Fatalf("expected no requests, got %d", n)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	cases := []struct {
+		header string
+		want   time.Duration
+	}{
+		{"", time.Minute},
+		{"5", 5 * time.Second},
+		{"nonsense", time.Minute},
+		{"Wed, 21 Oct 2015 07:28:00 GMT", 0},
+	}
+	for _, c := range cases {
+		h := http.Header{}
+		if c.header != "" {
+			h.Set("Retry-After", c.header)
+		}
+		if got := RetryAfter(h, time.Minute); got != c.want {
+			t.Errorf("Retry-After %q: expected %v, got %v", c.header, c.want, got)
+		}
+	}
+}
@@ -15,6 +15,7 @@
 package client
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -31,7 +32,7 @@ type rateLimitedExecutor struct {
 	rate.Limiter
 }
 
-func (e *rateLimitedExecutor) execute(f func() (*http.Response, error)) (*http.Response, error) {
+func (e *rateLimitedExecutor) execute(_ context.Context, f func() (*http.Response, error)) (*http.Response, error) {
 	e.Limiter.Allow() // block until we can make another request
 	return f()
 }
@@ -0,0 +1,134 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+var (
+	defaultMu     sync.RWMutex
+	defaultClient Client = http.DefaultClient
+)
+
+// Default returns the default http client to use, configured by Transport.Install
+func Default() Client {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultClient
+}
+
+// Transport configures how clients connect to servers: through a proxy, with a client certificate,
+// trusting a private CA, and with timeouts. Its flags default to environment variables,
+// so all providers and nvdsync can be configured at once.
+type Transport struct {
+	// ProxyURL is the url of the proxy all requests go through; proxies are taken from
+	// HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables if it's not set
+	ProxyURL string
+	// CertFile and KeyFile are PEM encoded client certificate and its key, used for mutual TLS
+	CertFile string
+	KeyFile  string
+	// CAFile is a PEM encoded bundle of CA certificates trusted in addition to the system ones
+	CAFile string
+	// Timeout limits the time of a whole request, including reading the response body
+	Timeout time.Duration
+	// ConnectTimeout limits the time of establishing a connection
+	ConnectTimeout time.Duration
+}
+
+// AddFlags adds flags used to configure the transport to the given FlagSet.
+func (t *Transport) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&t.ProxyURL, "proxy", os.Getenv("NVDTOOLS_PROXY"), "url of the proxy to send requests through, HTTPS_PROXY, HTTP_PROXY and NO_PROXY are used if it's not set\nenv: NVDTOOLS_PROXY")
+	fs.StringVar(&t.CertFile, "tls-cert", os.Getenv("NVDTOOLS_TLS_CERT"), "PEM encoded client certificate for mutual TLS\nenv: NVDTOOLS_TLS_CERT")
+	fs.StringVar(&t.KeyFile, "tls-key", os.Getenv("NVDTOOLS_TLS_KEY"), "PEM encoded key of the client certificate\nenv: NVDTOOLS_TLS_KEY")
+	fs.StringVar(&t.CAFile, "ca-file", os.Getenv("NVDTOOLS_CA_FILE"), "PEM encoded CA certificates to trust in addition to the system ones\nenv: NVDTOOLS_CA_FILE")
+	fs.DurationVar(&t.Timeout, "http-timeout", envDuration("NVDTOOLS_HTTP_TIMEOUT"), "timeout of a whole http request, including reading the response; 0 means no timeout\nenv: NVDTOOLS_HTTP_TIMEOUT")
+	fs.DurationVar(&t.ConnectTimeout, "connect-timeout", envDuration("NVDTOOLS_CONNECT_TIMEOUT"), "timeout of establishing a connection; 0 means the default of 30s\nenv: NVDTOOLS_CONNECT_TIMEOUT")
+}
+
+// envDuration returns the duration in the environment variable, 0 if it's not set or invalid
+func envDuration(key string) time.Duration {
+	d, _ := time.ParseDuration(os.Getenv(key))
+	return d
+}
+
+// Client returns a new http client using the transport.
+func (t *Transport) Client() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if t.ProxyURL != "" {
+		u, err := url.Parse(t.ProxyURL)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("bad proxy url %q", t.ProxyURL)
+		}
+		transport.Proxy = http.ProxyURL(u)
+	}
+	if t.ConnectTimeout > 0 {
+		dialer := &net.Dialer{Timeout: t.ConnectTimeout, KeepAlive: 30 * time.Second}
+		transport.DialContext = dialer.DialContext
+		transport.TLSHandshakeTimeout = t.ConnectTimeout
+	}
+
+	if t.CertFile != "" || t.KeyFile != "" || t.CAFile != "" {
+		transport.TLSClientConfig = &tls.Config{}
+	}
+	if t.CertFile != "" || t.KeyFile != "" {
+		if t.CertFile == "" || t.KeyFile == "" {
+			return nil, fmt.Errorf("both client certificate and its key need to be set")
+		}
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("can't load client certificate: %v", err)
+		}
+		transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	}
+	if t.CAFile != "" {
+		pem, err := ioutil.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("can't read CA file: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA file %q", t.CAFile)
+		}
+		transport.TLSClientConfig.RootCAs = pool
+	}
+
+	return &http.Client{Transport: transport, Timeout: t.Timeout}, nil
+}
+
+// Install makes the client using the transport the one returned by Default.
+func (t *Transport) Install() error {
+	c, err := t.Client()
+	if err != nil {
+		return err
+	}
+	defaultMu.Lock()
+	defaultClient = c
+	defaultMu.Unlock()
+	return nil
+}
@@ -0,0 +1,45 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package git keeps local clones of git repositories used by providers up to date.
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Sync clones the git repository from url into dir, or pulls the latest
+// changes if it has already been cloned there; git is killed if the context is done before it's finished.
+func Sync(ctx context.Context, dir, url string) error {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		return git(ctx, "-C", dir, "pull", "--ff-only", "--quiet")
+	} else if !os.IsNotExist(err) {
+		return errors.Wrapf(err, "cannot check git repository in %s", dir)
+	}
+	return git(ctx, "clone", "--depth", "1", "--quiet", url, dir)
+}
+
+func git(ctx context.Context, args ...string) error {
+	out, err := exec.CommandContext(ctx, "git", args...).CombinedOutput()
+	if err != nil {
+		return errors.Wrapf(err, "git %s: %s", strings.Join(args, " "), strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,387 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kafka implements a minimal Kafka producer, which is enough to publish
+// vulnerabilities to a topic without depending on a full client library.
+// Messages are sent uncompressed and acknowledged by all in-sync replicas.
+package kafka
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// Defaults for new producers
+const (
+	DefaultClientID  = "nvdtools"
+	DefaultBatchSize = 100
+	DefaultTimeout   = 30 * time.Second
+)
+
+// Error is an error code returned by a broker
+type Error int16
+
+var errorNames = map[Error]string{
+	3:  "unknown topic or partition",
+	5:  "leader not available",
+	6:  "not leader for partition",
+	7:  "request timed out",
+	10: "message too large",
+	19: "not enough replicas",
+	29: "topic authorization failed",
+}
+
+// Error implements error interface
+func (e Error) Error() string {
+	if name, ok := errorNames[e]; ok {
+		return fmt.Sprintf("kafka: %s (%d)", name, int16(e))
+	}
+	return fmt.Sprintf("kafka: error code %d", int16(e))
+}
+
+// Producer publishes messages to a single topic
+// Messages are buffered and sent in batches, Flush or Close need to be called to send the rest
+type Producer struct {
+	// ClientID identifies the producer in broker logs
+	ClientID string
+	// BatchSize is the number of buffered messages which triggers sending them
+	BatchSize int
+	// Timeout applies to connecting to brokers and to every request
+	Timeout time.Duration
+
+	topic     string
+	bootstrap []string
+
+	brokers  map[int32]string
+	leaders  []int32
+	conns    map[int32]*conn
+	pending  [][]message
+	buffered int
+	next     int
+	corrID   int32
+}
+
+// NewProducer creates a producer which publishes messages to the given topic
+// brokers are host:port addresses used to discover the rest of the cluster
+func NewProducer(brokers []string, topic string) (*Producer, error) {
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("no kafka brokers given")
+	}
+	if topic == "" {
+		return nil, fmt.Errorf("no kafka topic given")
+	}
+	p := &Producer{
+		ClientID:  DefaultClientID,
+		BatchSize: DefaultBatchSize,
+		Timeout:   DefaultTimeout,
+		topic:     topic,
+		bootstrap: brokers,
+		conns:     make(map[int32]*conn),
+	}
+	if err := p.refreshMetadata(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Send buffers the message, which is sent once there are enough buffered messages
+// Messages with the same key are sent to the same partition, nil keys are spread over all partitions
+func (p *Producer) Send(key, value []byte) error {
+	var i int
+	if key == nil {
+		i = p.next % len(p.leaders)
+		p.next++
+	} else {
+		i = partition(key, len(p.leaders))
+	}
+	p.pending[i] = append(p.pending[i], message{key: key, value: value, time: time.Now()})
+	p.buffered++
+	if p.buffered < p.BatchSize {
+		return nil
+	}
+	return p.Flush()
+}
+
+// Flush sends all buffered messages
+// If it fails, the metadata is refreshed and messages which weren't sent are retried once
+func (p *Producer) Flush() error {
+	if p.buffered == 0 {
+		return nil
+	}
+	if err := p.produce(); err == nil {
+		return nil
+	}
+	p.closeConns()
+	if err := p.refreshMetadata(); err != nil {
+		return err
+	}
+	return p.produce()
+}
+
+// Close flushes the buffered messages and closes connections to brokers
+func (p *Producer) Close() error {
+	err := p.Flush()
+	p.closeConns()
+	return err
+}
+
+// produce sends pending messages to leaders of their partitions
+// messages are removed from pending once they are acknowledged
+func (p *Producer) produce() error {
+	byLeader := make(map[int32][]int)
+	for i, msgs := range p.pending {
+		if len(msgs) > 0 {
+			byLeader[p.leaders[i]] = append(byLeader[p.leaders[i]], i)
+		}
+	}
+
+	var firstErr error
+	for leader, partitions := range byLeader {
+		if err := p.produceTo(leader, partitions); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (p *Producer) produceTo(leader int32, partitions []int) error {
+	var e encoder
+	e.int16(-1) // transactional id
+	e.int16(-1) // acks from all in-sync replicas
+	e.int32(int32(p.Timeout / time.Millisecond))
+	e.int32(1)
+	e.string(p.topic)
+	e.int32(int32(len(partitions)))
+	for _, i := range partitions {
+		e.int32(int32(i))
+		e.bytes(recordBatch(p.pending[i]))
+	}
+
+	c, err := p.conn(leader)
+	if err != nil {
+		return err
+	}
+	d, err := c.request(apiProduce, apiProduceVersion, e.b)
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for n := d.arrayLen(); n > 0; n-- {
+		d.string() // topic
+		for m := d.arrayLen(); m > 0; m-- {
+			i := int(d.int32())
+			code := Error(d.int16())
+			d.int64() // base offset
+			d.int64() // log append time
+			if d.err != nil {
+				break
+			}
+			if code != errNone {
+				if firstErr == nil {
+					firstErr = fmt.Errorf("can't produce to partition %d: %v", i, code)
+				}
+				continue
+			}
+			if i >= 0 && i < len(p.pending) {
+				p.buffered -= len(p.pending[i])
+				p.pending[i] = nil
+			}
+		}
+	}
+	if d.err != nil {
+		return fmt.Errorf("can't decode produce response: %v", d.err)
+	}
+	return firstErr
+}
+
+// refreshMetadata finds the partitions of the topic and their leaders
+func (p *Producer) refreshMetadata() error {
+	var e encoder
+	e.int32(1)
+	e.string(p.topic)
+	e.bool(false) // don't create the topic
+
+	var err error
+	for _, addr := range p.addrs() {
+		var c *conn
+		if c, err = p.dial(addr); err != nil {
+			continue
+		}
+		var d *decoder
+		d, err = c.request(apiMetadata, apiMetadataVersion, e.b)
+		c.Close()
+		if err != nil {
+			continue
+		}
+		if err = p.parseMetadata(d); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("can't get metadata of topic %q: %v", p.topic, err)
+}
+
+func (p *Producer) parseMetadata(d *decoder) error {
+	d.int32() // throttle time
+	brokers := make(map[int32]string)
+	for n := d.arrayLen(); n > 0; n-- {
+		id := d.int32()
+		host := d.string()
+		port := d.int32()
+		d.string() // rack
+		brokers[id] = net.JoinHostPort(host, strconv.Itoa(int(port)))
+	}
+	d.string() // cluster id
+	d.int32()  // controller id
+
+	var leaders []int32
+	var topicErr, partitionErr Error
+	for n := d.arrayLen(); n > 0; n-- {
+		code := Error(d.int16())
+		name := d.string()
+		d.int8() // is internal
+		for m := d.arrayLen(); m > 0; m-- {
+			pcode := Error(d.int16())
+			i := d.int32()
+			leader := d.int32()
+			for r := d.arrayLen(); r > 0; r-- {
+				d.int32() // replicas
+			}
+			for r := d.arrayLen(); r > 0; r-- {
+				d.int32() // in-sync replicas
+			}
+			if name != p.topic || d.err != nil {
+				continue
+			}
+			if leader < 0 && pcode == errNone {
+				pcode = errLeaderNotAvailable
+			}
+			if pcode != errNone && partitionErr == errNone {
+				partitionErr = pcode
+			}
+			for int(i) >= len(leaders) {
+				leaders = append(leaders, -1)
+			}
+			leaders[i] = leader
+		}
+		if name == p.topic {
+			topicErr = code
+		}
+	}
+	switch {
+	case d.err != nil:
+		return fmt.Errorf("can't decode metadata response: %v", d.err)
+	case topicErr != errNone:
+		return topicErr
+	case partitionErr != errNone:
+		return partitionErr
+	case len(leaders) == 0:
+		return Error(3)
+	}
+
+	p.brokers = brokers
+	p.leaders = leaders
+	for len(p.pending) < len(leaders) {
+		p.pending = append(p.pending, nil)
+	}
+	return nil
+}
+
+// addrs returns addresses of known brokers, followed by the bootstrap ones
+func (p *Producer) addrs() []string {
+	var addrs []string
+	for _, addr := range p.brokers {
+		addrs = append(addrs, addr)
+	}
+	return append(addrs, p.bootstrap...)
+}
+
+func (p *Producer) conn(id int32) (*conn, error) {
+	if c, ok := p.conns[id]; ok {
+		return c, nil
+	}
+	addr, ok := p.brokers[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown kafka broker %d", id)
+	}
+	c, err := p.dial(addr)
+	if err != nil {
+		return nil, err
+	}
+	p.conns[id] = c
+	return c, nil
+}
+
+func (p *Producer) closeConns() {
+	for id, c := range p.conns {
+		c.Close()
+		delete(p.conns, id)
+	}
+}
+
+func (p *Producer) dial(addr string) (*conn, error) {
+	nc, err := net.DialTimeout("tcp", addr, p.Timeout)
+	if err != nil {
+		return nil, fmt.Errorf("can't connect to kafka broker %s: %v", addr, err)
+	}
+	return &conn{Conn: nc, r: bufio.NewReader(nc), p: p}, nil
+}
+
+// conn is a connection to a broker
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+	p *Producer
+}
+
+// request sends a request and returns a decoder positioned after the response header
+func (c *conn) request(apiKey, version int16, body []byte) (*decoder, error) {
+	c.p.corrID++
+	corrID := c.p.corrID
+
+	var e encoder
+	e.int32(0) // size, set below
+	e.int16(apiKey)
+	e.int16(version)
+	e.int32(corrID)
+	e.string(c.p.ClientID)
+	e.b = append(e.b, body...)
+	binary.BigEndian.PutUint32(e.b, uint32(len(e.b)-4))
+
+	if err := c.SetDeadline(time.Now().Add(c.p.Timeout)); err != nil {
+		return nil, err
+	}
+	if _, err := c.Write(e.b); err != nil {
+		return nil, fmt.Errorf("can't send request to kafka broker %s: %v", c.RemoteAddr(), err)
+	}
+
+	var size [4]byte
+	if _, err := io.ReadFull(c.r, size[:]); err != nil {
+		return nil, fmt.Errorf("can't read response from kafka broker %s: %v", c.RemoteAddr(), err)
+	}
+	resp := make([]byte, binary.BigEndian.Uint32(size[:]))
+	if _, err := io.ReadFull(c.r, resp); err != nil {
+		return nil, fmt.Errorf("can't read response from kafka broker %s: %v", c.RemoteAddr(), err)
+	}
+	d := &decoder{b: resp}
+	if id := d.int32(); d.err == nil && id != corrID {
+		return nil, fmt.Errorf("unexpected correlation id %d, expected %d", id, corrID)
+	}
+	return d, d.err
+}
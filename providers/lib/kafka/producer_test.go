@@ -0,0 +1,271 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestMurmur2(t *testing.T) {
+	// values from the tests of the Java client
+	cases := map[string]int32{
+		"21":                         -973932308,
+		"foobar":                     -790332482,
+		"a-little-bit-long-string":   -985981536,
+		"a-little-bit-longer-string": -1486304829,
+		"lkjh234lh9fiuh90y23oiuhsafujhadof229phr9h19h89h8": -58897971,
+		"abc": 479470107,
+	}
+	for in, expected := range cases {
+		if h := int32(murmur2([]byte(in))); h != expected {
+			t.Errorf("murmur2(%q): expected %d, got %d", in, expected, h)
+		}
+	}
+}
+
+// fakeBroker is a single broker cluster which leads all partitions of a topic
+type fakeBroker struct {
+	t          *testing.T
+	ln         net.Listener
+	topic      string
+	partitions int
+
+	mu       sync.Mutex
+	messages map[int32][]string // partition -> key=value
+	failures int                // number of produce requests to fail
+}
+
+func newFakeBroker(t *testing.T, topic string, partitions int) *fakeBroker {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &fakeBroker{t: t, ln: ln, topic: topic, partitions: partitions, messages: make(map[int32][]string)}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go b.serve(c)
+		}
+	}()
+	return b
+}
+
+func (b *fakeBroker) serve(c net.Conn) {
+	defer c.Close()
+	r := bufio.NewReader(c)
+	for {
+		var size [4]byte
+		if _, err := io.ReadFull(r, size[:]); err != nil {
+			return
+		}
+		req := make([]byte, binary.BigEndian.Uint32(size[:]))
+		if _, err := io.ReadFull(r, req); err != nil {
+			return
+		}
+		d := &decoder{b: req}
+		apiKey, version, corrID := d.int16(), d.int16(), d.int32()
+		d.string() // client id
+
+		var e encoder
+		e.int32(0)
+		e.int32(corrID)
+		switch {
+		case apiKey == apiMetadata && version == 4:
+			b.metadata(d, &e)
+		case apiKey == apiProduce && version == 3:
+			b.produce(d, &e)
+		default:
+			b.t.Errorf("unexpected request %d version %d", apiKey, version)
+			return
+		}
+		if d.err != nil {
+			b.t.Errorf("can't decode request: %v", d.err)
+			return
+		}
+		binary.BigEndian.PutUint32(e.b, uint32(len(e.b)-4))
+		if _, err := c.Write(e.b); err != nil {
+			return
+		}
+	}
+}
+
+func (b *fakeBroker) metadata(d *decoder, e *encoder) {
+	for n := d.arrayLen(); n > 0; n-- {
+		d.string()
+	}
+	d.int8()
+
+	host, port, _ := net.SplitHostPort(b.ln.Addr().String())
+	p, _ := strconv.Atoi(port)
+	e.int32(0) // throttle
+	e.int32(1)
+	e.int32(7) // node id
+	e.string(host)
+	e.int32(int32(p))
+	e.int16(-1) // rack
+	e.int16(-1) // cluster id
+	e.int32(7)  // controller
+	e.int32(1)
+	e.int16(0)
+	e.string(b.topic)
+	e.int8(0)
+	e.int32(int32(b.partitions))
+	for i := 0; i < b.partitions; i++ {
+		e.int16(0)
+		e.int32(int32(i))
+		e.int32(7) // leader
+		e.int32(1)
+		e.int32(7)
+		e.int32(1)
+		e.int32(7)
+	}
+}
+
+func (b *fakeBroker) produce(d *decoder, e *encoder) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	fail := b.failures > 0
+	b.failures--
+
+	d.int16() // transactional id
+	if acks := d.int16(); acks != -1 {
+		b.t.Errorf("unexpected acks %d", acks)
+	}
+	d.int32() // timeout
+	n := d.arrayLen()
+	e.int32(int32(n))
+	for ; n > 0; n-- {
+		topic := d.string()
+		e.string(topic)
+		m := d.arrayLen()
+		e.int32(int32(m))
+		for ; m > 0; m-- {
+			i := d.int32()
+			batch := d.next(int(d.int32()))
+			e.int32(i)
+			if fail {
+				e.int16(errNotLeaderForPartition)
+			} else {
+				e.int16(0)
+				b.messages[i] = append(b.messages[i], decodeBatch(b.t, batch)...)
+			}
+			e.int64(0)
+			e.int64(-1)
+		}
+	}
+	e.int32(0) // throttle
+}
+
+func decodeBatch(t *testing.T, batch []byte) []string {
+	d := &decoder{b: batch}
+	d.int64() // base offset
+	if n := int(d.int32()); n != len(d.b) {
+		t.Errorf("batch length %d, expected %d", n, len(d.b))
+	}
+	d.int32() // leader epoch
+	if magic := d.int8(); magic != 2 {
+		t.Errorf("unexpected magic %d", magic)
+	}
+	crc := uint32(d.int32())
+	if sum := crc32.Checksum(d.b, crc32.MakeTable(crc32.Castagnoli)); sum != crc {
+		t.Errorf("crc %x, expected %x", crc, sum)
+	}
+	d.next(2 + 4 + 8 + 8 + 8 + 2 + 4)
+	n := int(d.int32())
+	var msgs []string
+	rest := d.b
+	varint := func() int64 {
+		v, k := binary.Varint(rest)
+		rest = rest[k:]
+		return v
+	}
+	for i := 0; i < n; i++ {
+		varint()        // length
+		rest = rest[1:] // attributes
+		varint()        // timestamp delta
+		if delta := varint(); delta != int64(i) {
+			t.Errorf("offset delta %d, expected %d", delta, i)
+		}
+		key := rest[:varint()]
+		rest = rest[len(key):]
+		value := rest[:varint()]
+		rest = rest[len(value):]
+		varint() // headers
+		msgs = append(msgs, string(key)+"="+string(value))
+	}
+	return msgs
+}
+
+func TestProducer(t *testing.T) {
+	b := newFakeBroker(t, "cves", 3)
+	defer b.ln.Close()
+	b.failures = 1
+
+	p, err := NewProducer([]string{b.ln.Addr().String()}, "cves")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.BatchSize = 4
+	for i := 0; i < 10; i++ {
+		key := fmt.Sprintf("CVE-2020-%04d", i%5)
+		if err := p.Send([]byte(key), []byte(strconv.Itoa(i))); err != nil {
+			t.Fatalf("can't send message %d: %v", i, err)
+		}
+	}
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	total := 0
+	for i, msgs := range b.messages {
+		for j, m := range msgs {
+			key := m[:len("CVE-2020-0000")]
+			if part := partition([]byte(key), 3); int32(part) != i {
+				t.Errorf("message %q sent to partition %d, expected %d", m, i, part)
+			}
+			// messages with the same key need to keep their order
+			for _, prev := range msgs[:j] {
+				if prev[:len(key)] == key && prev > m {
+					t.Errorf("message %q sent after %q", m, prev)
+				}
+			}
+		}
+		total += len(msgs)
+	}
+	if total != 10 {
+		t.Fatalf("expected 10 messages, got %d: %v", total, b.messages)
+	}
+}
+
+func TestProducerUnknownTopic(t *testing.T) {
+	b := newFakeBroker(t, "cves", 1)
+	defer b.ln.Close()
+	if _, err := NewProducer([]string{b.ln.Addr().String()}, "other"); err == nil {
+		t.Fatal("expected an error for unknown topic")
+	}
+}
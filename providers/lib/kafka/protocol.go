@@ -0,0 +1,241 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kafka
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"time"
+)
+
+// API keys and versions of the requests the producer sends
+// both are the oldest versions supported by Kafka 4.0, which aren't flexible (tagged fields) versions yet
+const (
+	apiProduce         = 0
+	apiMetadata        = 3
+	apiProduceVersion  = 3
+	apiMetadataVersion = 4
+)
+
+// error codes which are worth refreshing the metadata and retrying for
+const (
+	errNone                  = 0
+	errLeaderNotAvailable    = 5
+	errNotLeaderForPartition = 6
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// encoder appends values in Kafka protocol encoding
+type encoder struct {
+	b []byte
+}
+
+func (e *encoder) int8(v int8)    { e.b = append(e.b, byte(v)) }
+func (e *encoder) int16(v int16)  { e.b = binary.BigEndian.AppendUint16(e.b, uint16(v)) }
+func (e *encoder) int32(v int32)  { e.b = binary.BigEndian.AppendUint32(e.b, uint32(v)) }
+func (e *encoder) int64(v int64)  { e.b = binary.BigEndian.AppendUint64(e.b, uint64(v)) }
+func (e *encoder) varint(v int64) { e.b = binary.AppendVarint(e.b, v) }
+
+func (e *encoder) bool(v bool) {
+	if v {
+		e.int8(1)
+	} else {
+		e.int8(0)
+	}
+}
+
+func (e *encoder) string(s string) {
+	e.int16(int16(len(s)))
+	e.b = append(e.b, s...)
+}
+
+func (e *encoder) bytes(b []byte) {
+	e.int32(int32(len(b)))
+	e.b = append(e.b, b...)
+}
+
+// varbytes encodes b with a varint length, nil is encoded as null
+func (e *encoder) varbytes(b []byte) {
+	if b == nil {
+		e.varint(-1)
+		return
+	}
+	e.varint(int64(len(b)))
+	e.b = append(e.b, b...)
+}
+
+// decoder reads values in Kafka protocol encoding, the first error is kept and stops decoding
+type decoder struct {
+	b   []byte
+	err error
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || len(d.b) < n {
+		d.err = fmt.Errorf("response is too short")
+		return nil
+	}
+	b := d.b[:n]
+	d.b = d.b[n:]
+	return b
+}
+
+func (d *decoder) int8() int8 {
+	if b := d.next(1); b != nil {
+		return int8(b[0])
+	}
+	return 0
+}
+
+func (d *decoder) int16() int16 {
+	if b := d.next(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (d *decoder) int32() int32 {
+	if b := d.next(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (d *decoder) int64() int64 {
+	if b := d.next(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+// string decodes a nullable string, null is decoded as empty string
+func (d *decoder) string() string {
+	n := d.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(d.next(int(n)))
+}
+
+// arrayLen returns the length of the following array, null arrays are empty
+func (d *decoder) arrayLen() int {
+	n := d.int32()
+	if n < 0 {
+		return 0
+	}
+	if int(n) > len(d.b) {
+		// every element takes at least a byte
+		d.err = fmt.Errorf("invalid array length %d", n)
+		return 0
+	}
+	return int(n)
+}
+
+// message is a key-value pair sent to a topic
+type message struct {
+	key, value []byte
+	time       time.Time
+}
+
+// recordBatch encodes messages as a record batch (magic 2), without compression
+func recordBatch(msgs []message) []byte {
+	first := msgs[0].time
+	max := first
+	var records encoder
+	for i, m := range msgs {
+		if m.time.After(max) {
+			max = m.time
+		}
+		var r encoder
+		r.int8(0) // attributes
+		r.varint(int64(m.time.Sub(first) / time.Millisecond))
+		r.varint(int64(i))
+		r.varbytes(m.key)
+		r.varbytes(m.value)
+		r.varint(0) // headers
+		records.varint(int64(len(r.b)))
+		records.b = append(records.b, r.b...)
+	}
+
+	// everything after the crc is covered by it
+	var body encoder
+	body.int16(0) // attributes
+	body.int32(int32(len(msgs) - 1))
+	body.int64(millis(first))
+	body.int64(millis(max))
+	body.int64(-1) // producer id
+	body.int16(-1) // producer epoch
+	body.int32(-1) // base sequence
+	body.int32(int32(len(msgs)))
+	body.b = append(body.b, records.b...)
+
+	var e encoder
+	e.int64(0)                              // base offset
+	e.int32(int32(4 + 1 + 4 + len(body.b))) // length of the rest of the batch
+	e.int32(-1)                             // partition leader epoch
+	e.int8(2)                               // magic
+	e.int32(int32(crc32.Checksum(body.b, castagnoli)))
+	e.b = append(e.b, body.b...)
+	return e.b
+}
+
+func millis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+// partition returns the partition of the key, compatible with the default partitioner of the Java client
+func partition(key []byte, partitions int) int {
+	return int(murmur2(key)&0x7fffffff) % partitions
+}
+
+// murmur2 is the hash function used by the Java client to partition keys
+func murmur2(data []byte) uint32 {
+	const (
+		seed = 0x9747b28c
+		m    = 0x5bd1e995
+		r    = 24
+	)
+	length := len(data)
+	h := uint32(seed ^ length)
+	for i := 0; i+4 <= length; i += 4 {
+		k := binary.LittleEndian.Uint32(data[i:])
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+	tail := data[length&^3:]
+	switch len(tail) {
+	case 3:
+		h ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(tail[0])
+		h *= m
+	}
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+	return h
+}
@@ -0,0 +1,47 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkgfeed
+
+import (
+	"github.com/facebookincubator/nvdtools/deb"
+)
+
+// DebFix is the version in which a deb package has been fixed for some cve
+// it's up to the key it's added with to tell the release, e.g. codename and source package name
+type DebFix struct {
+	ID string
+	// Version is the fixed version, nil if the package isn't fixed
+	Version *deb.Version
+	// NotAffected is set if the package was never affected
+	NotAffected bool
+}
+
+// CVE is part of the Fix interface
+func (f *DebFix) CVE() string {
+	return f.ID
+}
+
+// Affects is part of the Fix interface
+func (f *DebFix) Affects(installed interface{}) bool {
+	return true
+}
+
+// Fixed is part of the Fix interface, installed should be *deb.Package
+func (f *DebFix) Fixed(installed interface{}) bool {
+	if f.NotAffected {
+		return true
+	}
+	return f.Version != nil && deb.VersionCompare(installed.(*deb.Package).Version, *f.Version) >= 0
+}
@@ -0,0 +1,109 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pkgfeed indexes feeds of linux distributions by installed packages, to list CVEs which are fixed
+// for some package or which still affect it. Distributions provide an indexer which adds fixes found in
+// their feed under keys of their choice (e.g. release and package name), and look packages up under them.
+package pkgfeed
+
+import (
+	"sort"
+)
+
+// Fix is a fix of a single cve for packages looked up under the key it was added with
+type Fix interface {
+	// CVE returns id of the fixed cve
+	CVE() string
+	// Fixed returns true if the installed package is fixed
+	// installed is passed as it was given to the feed, e.g. a package together with its distribution
+	Fixed(installed interface{}) bool
+	// Affects returns true if the fix is about the installed package, so the package is vulnerable
+	// unless it, or some other fix of the cve, is fixed
+	Affects(installed interface{}) bool
+}
+
+// Indexer adds all fixes of a distribution feed, keys have to be comparable
+type Indexer func(add func(key interface{}, fix Fix)) error
+
+// Feed is the index of fixes by keys
+type Feed struct {
+	fixes map[interface{}][]Fix
+}
+
+// New creates a new feed with fixes added by the indexer
+func New(index Indexer) (*Feed, error) {
+	f := Feed{
+		fixes: make(map[interface{}][]Fix),
+	}
+	err := index(func(key interface{}, fix Fix) {
+		f.fixes[key] = append(f.fixes[key], fix)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+// ListFixedCVEs returns sorted list of CVEs which are fixed for the installed package by fixes under any of the keys
+func (f *Feed) ListFixedCVEs(installed interface{}, keys ...interface{}) []string {
+	return setToSortedList(f.fixedCVEs(installed, keys))
+}
+
+// ListVulnerableCVEs returns sorted list of CVEs which have fixes under any of the keys affecting the installed
+// package, but none of them is fixed
+func (f *Feed) ListVulnerableCVEs(installed interface{}, keys ...interface{}) []string {
+	fixed := f.fixedCVEs(installed, keys)
+	vulnerable := make(map[string]bool)
+	for _, key := range keys {
+		for _, fix := range f.fixes[key] {
+			if !fixed[fix.CVE()] && fix.Affects(installed) {
+				vulnerable[fix.CVE()] = true
+			}
+		}
+	}
+	return setToSortedList(vulnerable)
+}
+
+// Check returns true if the cve is fixed for the installed package by any fix under the keys
+func (f *Feed) Check(installed interface{}, cve string, keys ...interface{}) bool {
+	for _, key := range keys {
+		for _, fix := range f.fixes[key] {
+			if fix.CVE() == cve && fix.Fixed(installed) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (f *Feed) fixedCVEs(installed interface{}, keys []interface{}) map[string]bool {
+	set := make(map[string]bool)
+	for _, key := range keys {
+		for _, fix := range f.fixes[key] {
+			if !set[fix.CVE()] && fix.Fixed(installed) {
+				set[fix.CVE()] = true
+			}
+		}
+	}
+	return set
+}
+
+func setToSortedList(set map[string]bool) []string {
+	list := make([]string, 0, len(set))
+	for s := range set {
+		list = append(list, s)
+	}
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,120 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkgfeed
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/deb"
+	"github.com/facebookincubator/nvdtools/rpm"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func mustDebVersion(t *testing.T, s string) *deb.Version {
+	v, err := deb.ParseVersion(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestFeedDeb(t *testing.T) {
+	type key struct{ release, name string }
+	f, err := New(func(add func(interface{}, Fix)) error {
+		add(key{"bookworm", "openssl"}, &DebFix{ID: "CVE-1", Version: mustDebVersion(t, "3.0.11-1")})
+		add(key{"bookworm", "openssl"}, &DebFix{ID: "CVE-2", Version: mustDebVersion(t, "3.0.13-1")})
+		add(key{"bookworm", "openssl"}, &DebFix{ID: "CVE-3", NotAffected: true})
+		add(key{"bookworm", "openssl"}, &DebFix{ID: "CVE-4"})
+		add(key{"bullseye", "openssl"}, &DebFix{ID: "CVE-5", Version: mustDebVersion(t, "1.1.1w-0")})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkg, err := deb.Parse("openssl_3.0.11-1_amd64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	k := key{"bookworm", "openssl"}
+
+	if got, expect := f.ListFixedCVEs(pkg, k), []string{"CVE-1", "CVE-3"}; !reflect.DeepEqual(got, expect) {
+		t.Fatalf("fixed: expecting %v, got %v", expect, got)
+	}
+	if got, expect := f.ListVulnerableCVEs(pkg, k), []string{"CVE-2", "CVE-4"}; !reflect.DeepEqual(got, expect) {
+		t.Fatalf("vulnerable: expecting %v, got %v", expect, got)
+	}
+	if !f.Check(pkg, "CVE-1", k) || f.Check(pkg, "CVE-2", k) || f.Check(pkg, "CVE-5", k) {
+		t.Fatal("unexpected check results")
+	}
+	if got := f.ListFixedCVEs(pkg, key{"buster", "openssl"}); len(got) != 0 {
+		t.Fatalf("expecting no CVEs for unknown key, got %v", got)
+	}
+}
+
+func TestFeedRPM(t *testing.T) {
+	el8, err := wfn.Parse("cpe:/o:redhat:enterprise_linux:8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	el9, err := wfn.Parse("cpe:/o:redhat:enterprise_linux:9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixed, err := rpm.Parse("openssl-1:1.1.1k-9.el8.src")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := New(func(add func(interface{}, Fix)) error {
+		add("openssl", &RPMFix{ID: "CVE-1", Distro: el8, Label: fixed.Label})
+		add("openssl", &RPMFix{ID: "CVE-2", Distro: el9, Label: fixed.Label})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		pkg        string
+		distro     *wfn.Attributes
+		fixed      []string
+		vulnerable []string
+	}{
+		{"openssl-1:1.1.1k-9.el8.x86_64", el8, []string{"CVE-1"}, []string{}},
+		{"openssl-1:1.1.1k-7.el8.x86_64", el8, []string{}, []string{"CVE-1"}},
+		{"openssl-1:1.1.1k-9.el8.x86_64", nil, []string{"CVE-1", "CVE-2"}, []string{}},
+	} {
+		p, err := rpm.Parse(tc.pkg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		installed := RPMPackage{Package: p, Distro: tc.distro}
+		if got := f.ListFixedCVEs(installed, p.Name); !reflect.DeepEqual(got, tc.fixed) {
+			t.Errorf("%s: fixed: expecting %v, got %v", tc.pkg, tc.fixed, got)
+		}
+		if got := f.ListVulnerableCVEs(installed, p.Name); !reflect.DeepEqual(got, tc.vulnerable) {
+			t.Errorf("%s: vulnerable: expecting %v, got %v", tc.pkg, tc.vulnerable, got)
+		}
+	}
+}
+
+func TestNewError(t *testing.T) {
+	errTest := errors.New("test")
+	if _, err := New(func(func(interface{}, Fix)) error { return errTest }); err != errTest {
+		t.Fatalf("expecting %v, got %v", errTest, err)
+	}
+}
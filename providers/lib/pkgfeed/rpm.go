@@ -0,0 +1,50 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkgfeed
+
+import (
+	"github.com/facebookincubator/nvdtools/rpm"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// RPMPackage is an installed rpm package looked up in the feed
+type RPMPackage struct {
+	*rpm.Package
+	// Distro the package is installed on, nil matches all distros
+	Distro *wfn.Attributes
+}
+
+// RPMFix is the version in which an rpm package has been fixed for some cve on some distro
+type RPMFix struct {
+	ID     string
+	Distro *wfn.Attributes
+	Label  rpm.Label
+}
+
+// CVE is part of the Fix interface
+func (f *RPMFix) CVE() string {
+	return f.ID
+}
+
+// Affects is part of the Fix interface, installed should be RPMPackage
+func (f *RPMFix) Affects(installed interface{}) bool {
+	distro := installed.(RPMPackage).Distro
+	return distro == nil || wfn.Match(distro, f.Distro)
+}
+
+// Fixed is part of the Fix interface, installed should be RPMPackage
+func (f *RPMFix) Fixed(installed interface{}) bool {
+	return f.Affects(installed) && rpm.LabelCompare(installed.(RPMPackage).Label, f.Label) >= 0
+}
@@ -31,6 +31,8 @@ type Config struct {
 	download      bool
 	convert       bool
 	downloadSince sinceTS
+	sinceFile     string
+	sink          string
 }
 
 func (c *Config) addFlags() {
@@ -39,6 +41,8 @@ func (c *Config) addFlags() {
 	flag.BoolVar(&c.download, "download", false, "Should the data be downloaded or read from stdin/files")
 	flag.BoolVar(&c.convert, "convert", false, "Should the feed be converted to NVD format or not")
 	flag.Var(&c.downloadSince, "since", fmt.Sprintf("Since when to download. It can be a timestamp, golang duration or time in %q format. Default is timestamp=0", nvd.TimeLayout))
+	flag.StringVar(&c.sinceFile, "since_file", "", "File which stores the time of the last download. If set, only vulnerabilities changed since then are downloaded and the file is updated once the output is written, unless errors were logged during the run")
+	flag.StringVar(&c.sink, "sink", "", "Publish vulnerabilities in NVD format one by one as they're converted, instead of writing a feed once all of them are fetched. It can be - for NDJSON on stdout, a file to write NDJSON to, or kafka://host:port[,host:port...]/topic")
 }
 
 func (c *Config) validate() error {
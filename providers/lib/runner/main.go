@@ -20,13 +20,18 @@ import (
 	"flag"
 	"fmt"
 	"io"
-	"log"
+	"io/ioutil"
 	"os"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/metrics"
 	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/shutdown"
 	"github.com/facebookincubator/nvdtools/stats"
 )
 
@@ -38,6 +43,12 @@ type Convertible interface {
 	Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error)
 }
 
+// Superseder is implemented by vulnerabilities which can be released more than once under the same ID,
+// e.g. revised advisories; Supersedes should return true if the vulnerability is newer than the other one
+type Superseder interface {
+	Supersedes(other Convertible) bool
+}
+
 // Read should read the vulnerabilities from the given reader and push them into the channel
 // The contents of the reader should be a slice of structs which are convertibles
 // channel will be created and mustn't be closed
@@ -58,10 +69,12 @@ type Runner struct {
 // Run should be called in main function of the converter
 // It will run the fetchers/runners (and convert vulnerabilities)
 // Finally, it will output it as json to stdout
+// On SIGINT or SIGTERM it stops fetching and returns an error without writing the output
 func (r *Runner) Run() error {
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
 	r.Config.addFlags()
 	stats.AddFlags()
+	logging.AddFlags()
+	metrics.AddFlags()
 	flag.Usage = func() {
 		fmt.Fprintf(os.Stderr, "Usage: %s [flags]\n", os.Args[0])
 		flag.PrintDefaults()
@@ -74,43 +87,179 @@ func (r *Runner) Run() error {
 		stats.WriteAndLogError()
 	}(time.Now())
 
+	metrics.Start()
+	defer func() {
+		if err := metrics.Flush(); err != nil {
+			logging.Errorf("can't write metrics: %v", err)
+		}
+	}()
+
 	if err := r.Config.validate(); err != nil {
 		return fmt.Errorf("config is invalid: %v", err)
 	}
+	if err := r.Config.ClientConfig.Transport.Install(); err != nil {
+		return fmt.Errorf("can't configure http client: %v", err)
+	}
+
+	ctx, stop := shutdown.Context(context.Background())
+	defer stop()
 
 	var vulns <-chan Convertible
+	var startTime time.Time
 	var err error
+	errorsBefore := logging.Errors()
 	if r.Config.download {
-		vulns, err = r.downloadVulnerabilities(context.Background())
+		vulns, startTime, err = r.downloadVulnerabilities(ctx)
 	} else {
 		vulns, err = r.readVulnerabilities()
 	}
 	if err != nil {
 		return fmt.Errorf("couldn't get vulnerabilities: %v", err)
 	}
+	vulns = countRecords(ctx, vulns)
+
+	if r.Config.sink != "" {
+		sink, err := OpenSink(r.Config.sink)
+		if err != nil {
+			return err
+		}
+		if err := stream(vulns, sink); err != nil {
+			return fmt.Errorf("failed to stream vulns: %v", err)
+		}
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("interrupted, streamed vulns are incomplete: %v", err)
+		}
+		r.updateSinceFile(startTime, errorsBefore)
+		lastSuccess.Set(float64(time.Now().Unix()), provider)
+		return nil
+	}
 
 	if r.Config.convert {
-		if err := convert(vulns); err != nil {
+		if err := convert(ctx, vulns); err != nil {
 			return fmt.Errorf("failed to convert vulns: %v", err)
 		}
+		r.updateSinceFile(startTime, errorsBefore)
+		lastSuccess.Set(float64(time.Now().Unix()), provider)
 		return nil
 	}
 
 	m := make(map[string]Convertible)
 	for v := range vulns {
+		if prev, ok := m[v.ID()]; ok {
+			if s, ok := v.(Superseder); ok && !s.Supersedes(prev) {
+				continue
+			}
+		}
 		m[v.ID()] = v
 	}
+	if err := logging.Err(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("interrupted: %v", err)
+	}
 	if err := json.NewEncoder(os.Stdout).Encode(m); err != nil {
 		return fmt.Errorf("couldn't write vulnerabilities: %v", err)
 	}
+	r.updateSinceFile(startTime, errorsBefore)
+	lastSuccess.Set(float64(time.Now().Unix()), provider)
 
 	return nil
 }
 
-func (r *Runner) downloadVulnerabilities(ctx context.Context) (<-chan Convertible, error) {
+// countRecords counts the vulnerabilities passing through the channel
+// and tracks how long it took to fetch all of them; it stops passing them once the context is done
+func countRecords(ctx context.Context, vulns <-chan Convertible) <-chan Convertible {
+	start := time.Now()
+	output := make(chan Convertible)
+	go func() {
+		defer close(output)
+		for {
+			select {
+			case vuln, ok := <-vulns:
+				if !ok {
+					fetchDuration.Set(time.Since(start).Seconds(), provider)
+					return
+				}
+				fetchedRecords.Inc(provider)
+				select {
+				case output <- vuln:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return output
+}
+
+// downloadVulnerabilities starts fetching the vulnerabilities and returns the time it started,
+// which should be stored in the since file once the output is written
+func (r *Runner) downloadVulnerabilities(ctx context.Context) (<-chan Convertible, time.Time, error) {
 	c := client.Default()
 	c = r.Config.ClientConfig.Configure(c)
-	return r.FetchSince(ctx, c, r.Config.BaseURL, int64(r.Config.downloadSince))
+
+	since := int64(r.Config.downloadSince)
+	if r.Config.sinceFile != "" {
+		last, err := readSinceFile(r.Config.sinceFile)
+		if err != nil {
+			return nil, time.Time{}, err
+		}
+		if last > since {
+			logging.Infof("downloading vulnerabilities changed since last run at %s", time.Unix(last, 0).UTC().Format(nvd.TimeLayout))
+			since = last
+		}
+	}
+
+	startTime := time.Now()
+	vulns, err := r.FetchSince(ctx, c, r.Config.BaseURL, since)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return vulns, startTime, nil
+}
+
+// updateSinceFile stores the time the download started in the since file
+// it should be called once the output was written; the file isn't updated if errors were logged since errorsBefore,
+// as fetchers only log documents they couldn't fetch, and the next run needs to fetch them again
+func (r *Runner) updateSinceFile(startTime time.Time, errorsBefore int) {
+	if r.Config.sinceFile == "" || startTime.IsZero() {
+		return
+	}
+	if n := logging.Errors() - errorsBefore; n > 0 {
+		logging.Warningf("%d error(s) were logged during the run, not updating since file", n)
+		return
+	}
+	if err := writeSinceFile(r.Config.sinceFile, startTime.Unix()); err != nil {
+		logging.Warningf("couldn't update since file: %v", err)
+	}
+}
+
+// readSinceFile returns the timestamp stored in the given file, or 0 if the file doesn't exist
+func readSinceFile(path string) (int64, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("couldn't read since file %q: %v", path, err)
+	}
+	ts, err := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("couldn't parse since file %q: %v", path, err)
+	}
+	return ts, nil
+}
+
+func writeSinceFile(path string, ts int64) error {
+	// write to a temporary file first, so the file is never left half written
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, []byte(strconv.FormatInt(ts, 10)+"\n"), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
 }
 
 func (r *Runner) readVulnerabilities() (<-chan Convertible, error) {
@@ -121,7 +270,7 @@ func (r *Runner) readVulnerabilities() (<-chan Convertible, error) {
 		go func() {
 			defer close(vulns)
 			if err := r.Read(os.Stdin, vulns); err != nil {
-				log.Printf("error while reading from stdin: %v", err)
+				logging.Errorf("error while reading from stdin: %v", err)
 			}
 		}()
 		return vulns, nil
@@ -135,12 +284,12 @@ func (r *Runner) readVulnerabilities() (<-chan Convertible, error) {
 			defer wg.Done()
 			file, err := os.Open(filename)
 			if err != nil {
-				log.Printf("couldn't open file %q: %v", filename, err)
+				logging.Errorf("couldn't open file %q: %v", filename, err)
 				return
 			}
 			defer file.Close()
 			if err := r.Read(file, vulns); err != nil {
-				log.Printf("error while reading from file %q: %v", filename, err)
+				logging.Errorf("error while reading from file %q: %v", filename, err)
 			}
 		}(filename)
 	}
@@ -153,16 +302,16 @@ func (r *Runner) readVulnerabilities() (<-chan Convertible, error) {
 }
 
 // getNVDFeed will convert the vulns in channel to NVD Feed
-func convert(vulns <-chan Convertible) error {
+func convert(ctx context.Context, vulns <-chan Convertible) error {
 	defer stats.TrackTime("convert.time", time.Now(), time.Second)
-	var feed nvd.NVDCVEFeedJSON10
-	for vuln := range vulns {
-		converted, err := vuln.Convert()
-		if err != nil {
-			log.Printf("error while converting vuln: %v", err)
-			continue
-		}
-		feed.CVEItems = append(feed.CVEItems, converted)
+	feed := nvd.NVDCVEFeedJSON10{
+		CVEItems: convertLatest(vulns),
+	}
+	if err := logging.Err(); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("interrupted: %v", err)
 	}
 
 	if err := json.NewEncoder(os.Stdout).Encode(feed); err != nil {
@@ -170,3 +319,42 @@ func convert(vulns <-chan Convertible) error {
 	}
 	return nil
 }
+
+// convertLatest converts the vulns, keeping only the latest one if the same ID is read more than once
+// (e.g. when converting several incremental downloads together): the one which supersedes the others
+// if vulns implement Superseder, or the last modified one otherwise
+func convertLatest(vulns <-chan Convertible) []*nvd.NVDCVEFeedJSON10DefCVEItem {
+	type entry struct {
+		vuln Convertible
+		item *nvd.NVDCVEFeedJSON10DefCVEItem
+	}
+	var items []*nvd.NVDCVEFeedJSON10DefCVEItem
+	seen := make(map[string]int)
+	entries := make(map[string]entry)
+	for vuln := range vulns {
+		converted, err := vuln.Convert()
+		if err != nil {
+			logging.Recordf("error while converting vuln: %v", err)
+			conversionErrors.Inc(provider)
+			continue
+		}
+		id := vuln.ID()
+		i, ok := seen[id]
+		if !ok {
+			seen[id] = len(items)
+			entries[id] = entry{vuln, converted}
+			items = append(items, converted)
+			continue
+		}
+		prev := entries[id]
+		newer := converted.LastModifiedDate > prev.item.LastModifiedDate
+		if s, ok := vuln.(Superseder); ok {
+			newer = s.Supersedes(prev.vuln)
+		}
+		if newer {
+			entries[id] = entry{vuln, converted}
+			items[i] = converted
+		}
+	}
+	return items
+}
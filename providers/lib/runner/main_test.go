@@ -0,0 +1,145 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
+)
+
+func TestSinceFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "runner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "since")
+
+	// first run, file doesn't exist
+	ts, err := readSinceFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ts != 0 {
+		t.Fatalf("expecting 0 when file doesn't exist, got %d", ts)
+	}
+
+	if err := writeSinceFile(path, 1572566400); err != nil {
+		t.Fatal(err)
+	}
+	if ts, err = readSinceFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if ts != 1572566400 {
+		t.Fatalf("expecting 1572566400, got %d", ts)
+	}
+
+	if err := ioutil.WriteFile(path, []byte("garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readSinceFile(path); err == nil {
+		t.Fatal("expecting an error when file can't be parsed")
+	}
+}
+
+func TestUpdateSinceFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "runner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "since")
+	r := Runner{Config: Config{sinceFile: path}}
+	start := time.Unix(1572566400, 0)
+
+	// a fetch error was logged, the next run should download everything again
+	logging.Default().SetOutput(ioutil.Discard)
+	defer logging.Default().SetOutput(os.Stderr)
+	errorsBefore := logging.Errors()
+	logging.Errorf("can't fetch page 2")
+	r.updateSinceFile(start, errorsBefore)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("since file shouldn't be written after errors, got %v", err)
+	}
+
+	r.updateSinceFile(start, logging.Errors())
+	if ts, err := readSinceFile(path); err != nil || ts != start.Unix() {
+		t.Fatalf("expecting %d, got %d, %v", start.Unix(), ts, err)
+	}
+}
+
+type revisedVuln struct {
+	name, modified string
+	revision       int
+}
+
+func (v *revisedVuln) ID() string {
+	return v.name
+}
+
+func (v *revisedVuln) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	return &nvd.NVDCVEFeedJSON10DefCVEItem{
+		CVE:              &nvd.CVEJSON40{CVEDataMeta: &nvd.CVEJSON40CVEDataMeta{ID: v.name}},
+		LastModifiedDate: v.modified,
+	}, nil
+}
+
+type supersedingVuln struct {
+	revisedVuln
+}
+
+func (v *supersedingVuln) Supersedes(other Convertible) bool {
+	return v.revision > other.(*supersedingVuln).revision
+}
+
+func TestConvertLatest(t *testing.T) {
+	convertAll := func(vulns ...Convertible) []string {
+		ch := make(chan Convertible, len(vulns))
+		for _, v := range vulns {
+			ch <- v
+		}
+		close(ch)
+		var out []string
+		for _, item := range convertLatest(ch) {
+			out = append(out, item.CVE.CVEDataMeta.ID+"@"+item.LastModifiedDate)
+		}
+		return out
+	}
+
+	got := convertAll(
+		&revisedVuln{name: "a", modified: "2020-01-01T00:00Z"},
+		&revisedVuln{name: "b", modified: "2020-01-01T00:00Z"},
+		&revisedVuln{name: "a", modified: "2020-02-01T00:00Z"},
+		&revisedVuln{name: "b", modified: "2019-12-01T00:00Z"},
+	)
+	if want := []string{"a@2020-02-01T00:00Z", "b@2020-01-01T00:00Z"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected the last modified vulns %v, got %v", want, got)
+	}
+
+	got = convertAll(
+		&supersedingVuln{revisedVuln{name: "a", modified: "2020-02-01T00:00Z", revision: 2}},
+		&supersedingVuln{revisedVuln{name: "a", modified: "2020-03-01T00:00Z", revision: 1}},
+	)
+	if want := []string{"a@2020-02-01T00:00Z"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected the latest revision %v, got %v", want, got)
+	}
+}
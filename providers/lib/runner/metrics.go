@@ -0,0 +1,49 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/metrics"
+)
+
+// provider labels the metrics, it's the name of the command without the 2nvd suffix
+var provider = strings.TrimSuffix(filepath.Base(os.Args[0]), "2nvd")
+
+var (
+	fetchDuration = metrics.NewGauge(
+		"nvdtools_provider_fetch_duration_seconds",
+		"How long it took to fetch or read all vulnerabilities in the last run.",
+		"provider",
+	)
+	fetchedRecords = metrics.NewCounter(
+		"nvdtools_provider_records_total",
+		"Number of vulnerabilities fetched or read.",
+		"provider",
+	)
+	conversionErrors = metrics.NewCounter(
+		"nvdtools_provider_conversion_errors_total",
+		"Number of vulnerabilities which couldn't be converted to NVD format.",
+		"provider",
+	)
+	lastSuccess = metrics.NewGauge(
+		"nvdtools_provider_last_success_timestamp_seconds",
+		"Unix time of the last successful run.",
+		"provider",
+	)
+)
@@ -0,0 +1,270 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package runner runs vulnerability providers: it downloads vulnerabilities, converts them to NVD format
+// and writes them to stdout, as the main function of {provider}2nvd commands.
+//
+// Providers implement the Provider interface and register themselves in init function of their package,
+// so their command is just:
+//
+//	import _ "github.com/facebookincubator/nvdtools/providers/kev/api"
+//
+//	func main() {
+//		runner.Main("kev")
+//	}
+//
+// Providers whose API clients return records which convert themselves can be registered with ConvertibleProvider.
+// Options of a provider are package variables of its api package, which its command binds to flags.
+//
+// Commands which don't download through a single provider don't use the registry: redhat2nvd merges
+// the Red Hat API, CSAF VEX documents and OVAL streams depending on its flags, while vfeed2nvd,
+// cvelist2nvd and rustsec2nvd convert local repositories instead of downloading from an API.
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+)
+
+// Record is a vulnerability in the format of its provider
+type Record interface {
+	// ID should return vulnerabilities ID
+	ID() string
+}
+
+// RecordIterator iterates over records fetched by a provider
+type RecordIterator interface {
+	// Next returns the next record, or io.EOF after the last one
+	Next(ctx context.Context) (Record, error)
+}
+
+// Provider is a source of vulnerabilities
+type Provider interface {
+	// Fetch fetches vulnerabilities changed since the given time
+	Fetch(ctx context.Context, since time.Time) (RecordIterator, error)
+	// Convert converts a record returned by Fetch to NVD CVE Item
+	Convert(record Record) (*nvd.NVDCVEFeedJSON10DefCVEItem, error)
+}
+
+// Registration describes a provider to the registry
+type Registration struct {
+	// Name of the provider, its command is {name}2nvd
+	Name string
+	// BaseURL is the default API base URL
+	BaseURL string
+	// Env lists environment variables the provider needs, e.g. credentials
+	Env []string
+	// OptionalEnv lists environment variables the provider uses if they're set, e.g. alternative credentials
+	OptionalEnv []string
+	// New returns the provider which fetches vulnerabilities from baseURL using the client.
+	// Values of environment variables in Env are passed in env, they're set; values of those in
+	// OptionalEnv are passed only if they're set
+	New func(c client.Client, baseURL string, env map[string]string) (Provider, error)
+	// Read reads vulnerabilities downloaded by the runner without converting them
+	Read Read
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Registration)
+)
+
+// Register registers the provider, it should be called in init function of the provider package.
+// It panics if a provider with the same name is registered already.
+func Register(reg Registration) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if reg.Name == "" || reg.New == nil || reg.Read == nil {
+		panic("runner: incomplete registration of provider " + reg.Name)
+	}
+	if _, ok := registry[reg.Name]; ok {
+		panic("runner: provider " + reg.Name + " is registered twice")
+	}
+	registry[reg.Name] = reg
+}
+
+// Lookup returns the registered provider
+func Lookup(name string) (Registration, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	reg, ok := registry[name]
+	return reg, ok
+}
+
+// Registered returns sorted names of registered providers
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Main is the main function of the command of a registered provider
+func Main(name string) {
+	reg, ok := Lookup(name)
+	if !ok {
+		logging.Errorf("provider %q isn't registered", name)
+		os.Exit(1)
+	}
+	r := Runner{
+		Config: Config{
+			BaseURL: reg.BaseURL,
+			ClientConfig: client.Config{
+				UserAgent: name + "2nvd",
+			},
+		},
+		FetchSince: reg.FetchSince,
+		Read:       reg.Read,
+	}
+	if err := r.Run(); err != nil {
+		logging.Errorf("%v", err)
+		os.Exit(1)
+	}
+}
+
+// NewProvider returns the registered provider which fetches vulnerabilities from baseURL using the client,
+// getenv returns values of its environment variables, e.g. os.Getenv
+func (reg Registration) NewProvider(c client.Client, baseURL string, getenv func(string) string) (Provider, error) {
+	env := make(map[string]string, len(reg.Env))
+	for _, key := range reg.Env {
+		if env[key] = getenv(key); env[key] == "" {
+			return nil, fmt.Errorf("please set %s in environment", key)
+		}
+	}
+	for _, key := range reg.OptionalEnv {
+		if value := getenv(key); value != "" {
+			env[key] = value
+		}
+	}
+	return reg.New(c, baseURL, env)
+}
+
+// FetchSince returns the FetchSince function of the runner, which fetches from the registered provider
+func (reg Registration) FetchSince(ctx context.Context, c client.Client, baseURL string, since int64) (<-chan Convertible, error) {
+	p, err := reg.NewProvider(c, baseURL, os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+	it, err := p.Fetch(ctx, time.Unix(since, 0))
+	if err != nil {
+		return nil, err
+	}
+	output := make(chan Convertible)
+	go func() {
+		defer close(output)
+		for {
+			record, err := it.Next(ctx)
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				logging.Errorf("error while fetching %s vulnerabilities: %v", reg.Name, err)
+				return
+			}
+			select {
+			case output <- providerRecord{Record: record, provider: p}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return output, nil
+}
+
+// providerRecord is a record which is converted by its provider
+type providerRecord struct {
+	Record
+	provider Provider
+}
+
+// Convert implements the Convertible interface
+func (r providerRecord) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	return r.provider.Convert(r.Record)
+}
+
+// MarshalJSON writes the record as it is, so it can be read back by Read of the provider
+func (r providerRecord) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.Record)
+}
+
+// ChanIterator iterates over records sent to the channel, until it's closed
+type ChanIterator <-chan Convertible
+
+// Next implements the RecordIterator interface
+func (it ChanIterator) Next(ctx context.Context) (Record, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case record, ok := <-it:
+		if !ok {
+			return nil, io.EOF
+		}
+		return record, nil
+	}
+}
+
+// ConvertibleProvider is a provider fetching records which convert themselves, with an API client
+// returning them in a channel; most providers can be registered with it.
+type ConvertibleProvider func(ctx context.Context, since time.Time) (<-chan Convertible, error)
+
+// Fetch implements the Provider interface
+func (p ConvertibleProvider) Fetch(ctx context.Context, since time.Time) (RecordIterator, error) {
+	vulns, err := p(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	return ChanIterator(vulns), nil
+}
+
+// Convert implements the Provider interface
+func (p ConvertibleProvider) Convert(record Record) (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	c, ok := record.(Convertible)
+	if !ok {
+		return nil, fmt.Errorf("record %s of type %T can't be converted", record.ID(), record)
+	}
+	return c.Convert()
+}
+
+// ReadMap returns Read function for vulnerabilities downloaded by the runner, a JSON object
+// of vulnerabilities by their ids; newVuln returns a new vulnerability of the provider to decode into
+func ReadMap(newVuln func() Convertible) Read {
+	return func(r io.Reader, c chan Convertible) error {
+		var vulns map[string]json.RawMessage
+		if err := json.NewDecoder(r).Decode(&vulns); err != nil {
+			return fmt.Errorf("can't decode into vulns: %v", err)
+		}
+		for id, data := range vulns {
+			vuln := newVuln()
+			if err := json.Unmarshal(data, vuln); err != nil {
+				return fmt.Errorf("can't decode vuln %q: %v", id, err)
+			}
+			c <- vuln
+		}
+		return nil
+	}
+}
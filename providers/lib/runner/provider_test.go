@@ -0,0 +1,143 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+)
+
+type testVuln struct {
+	Name string `json:"name"`
+}
+
+func (v *testVuln) ID() string {
+	return v.Name
+}
+
+func (v *testVuln) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	return &nvd.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &nvd.CVEJSON40{CVEDataMeta: &nvd.CVEJSON40CVEDataMeta{ID: v.Name}},
+	}, nil
+}
+
+var testRegistration = Registration{
+	Name: "test",
+	Env:  []string{"RUNNER_TEST_TOKEN"},
+	New: func(c client.Client, baseURL string, env map[string]string) (Provider, error) {
+		return ConvertibleProvider(func(ctx context.Context, since time.Time) (<-chan Convertible, error) {
+			vulns := make(chan Convertible)
+			go func() {
+				defer close(vulns)
+				for i := since.Unix(); i < 3; i++ {
+					vulns <- &testVuln{Name: fmt.Sprintf("%s-%s-%d", baseURL, env["RUNNER_TEST_TOKEN"], i)}
+				}
+			}()
+			return vulns, nil
+		}), nil
+	},
+	Read: ReadMap(func() Convertible { return new(testVuln) }),
+}
+
+func TestRegistry(t *testing.T) {
+	Register(testRegistration)
+	defer func() {
+		registryMu.Lock()
+		delete(registry, testRegistration.Name)
+		registryMu.Unlock()
+	}()
+
+	if _, ok := Lookup("test"); !ok {
+		t.Fatal("provider isn't registered")
+	}
+	if names := Registered(); len(names) != 1 || names[0] != "test" {
+		t.Fatalf("unexpected registered providers %v", names)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("registering a provider twice should panic")
+			}
+		}()
+		Register(testRegistration)
+	}()
+}
+
+func TestRegistrationFetchSince(t *testing.T) {
+	ctx := context.Background()
+	if _, err := testRegistration.FetchSince(ctx, nil, "url", 0); err == nil || !strings.Contains(err.Error(), "RUNNER_TEST_TOKEN") {
+		t.Fatalf("expected an error about missing environment variable, got %v", err)
+	}
+
+	os.Setenv("RUNNER_TEST_TOKEN", "token")
+	defer os.Unsetenv("RUNNER_TEST_TOKEN")
+	vulns, err := testRegistration.FetchSince(ctx, nil, "url", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	downloaded := make(map[string]Convertible)
+	for vuln := range vulns {
+		item, err := vuln.Convert()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if item.CVE.CVEDataMeta.ID != vuln.ID() {
+			t.Fatalf("vulnerability %s was converted to %s", vuln.ID(), item.CVE.CVEDataMeta.ID)
+		}
+		ids = append(ids, vuln.ID())
+		downloaded[vuln.ID()] = vuln
+	}
+	if strings.Join(ids, ",") != "url-token-1,url-token-2" {
+		t.Fatalf("unexpected vulnerabilities %v", ids)
+	}
+
+	// downloaded vulnerabilities can be read back
+	data, err := json.Marshal(downloaded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	read := make(chan Convertible, len(downloaded))
+	if err := testRegistration.Read(strings.NewReader(string(data)), read); err != nil {
+		t.Fatal(err)
+	}
+	close(read)
+	for vuln := range read {
+		if downloaded[vuln.ID()] == nil {
+			t.Fatalf("unexpected vulnerability %s read", vuln.ID())
+		}
+		delete(downloaded, vuln.ID())
+	}
+	if len(downloaded) != 0 {
+		t.Fatalf("vulnerabilities weren't read back: %v", downloaded)
+	}
+}
+
+func TestChanIterator(t *testing.T) {
+	vulns := make(chan Convertible)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ChanIterator(vulns).Next(ctx); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/kafka"
+	"github.com/facebookincubator/nvdtools/stats"
+)
+
+// Sink receives vulnerabilities as soon as they're converted to NVD format,
+// instead of waiting for all of them to be fetched
+type Sink interface {
+	// Write should publish the vulnerability
+	Write(*nvd.NVDCVEFeedJSON10DefCVEItem) error
+	// Close should publish everything which is buffered and release resources
+	Close() error
+}
+
+// OpenSink opens the sink described by spec, which is one of:
+// "-" to write NDJSON to stdout, a path of the file to write NDJSON to,
+// or kafka://host:port[,host:port...]/topic to publish to a Kafka topic
+func OpenSink(spec string) (Sink, error) {
+	switch {
+	case spec == "-":
+		return NewWriterSink(os.Stdout), nil
+	case strings.HasPrefix(spec, "kafka://"):
+		addr := strings.TrimPrefix(spec, "kafka://")
+		i := strings.Index(addr, "/")
+		if i < 0 {
+			return nil, fmt.Errorf("no topic in kafka sink %q", spec)
+		}
+		return NewKafkaSink(strings.Split(addr[:i], ","), addr[i+1:])
+	}
+	f, err := os.Create(spec)
+	if err != nil {
+		return nil, fmt.Errorf("can't create sink: %v", err)
+	}
+	s := NewWriterSink(f).(*writerSink)
+	s.closer = f
+	return s, nil
+}
+
+// writerSink writes every vulnerability as a line of JSON
+type writerSink struct {
+	w      *bufio.Writer
+	enc    *json.Encoder
+	closer io.Closer
+}
+
+// NewWriterSink returns a sink which writes vulnerabilities to w as newline delimited JSON (NDJSON)
+func NewWriterSink(w io.Writer) Sink {
+	bw := bufio.NewWriter(w)
+	return &writerSink{w: bw, enc: json.NewEncoder(bw)}
+}
+
+// Write is a part of Sink interface
+func (s *writerSink) Write(item *nvd.NVDCVEFeedJSON10DefCVEItem) error {
+	return s.enc.Encode(item)
+}
+
+// Close is a part of Sink interface
+func (s *writerSink) Close() error {
+	err := s.w.Flush()
+	if s.closer != nil {
+		if cerr := s.closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// kafkaSink publishes every vulnerability as a JSON message keyed by CVE ID
+type kafkaSink struct {
+	p *kafka.Producer
+}
+
+// NewKafkaSink returns a sink which publishes vulnerabilities to the Kafka topic
+// Messages are keyed by CVE ID, so all versions of a vulnerability land in the same partition
+func NewKafkaSink(brokers []string, topic string) (Sink, error) {
+	p, err := kafka.NewProducer(brokers, topic)
+	if err != nil {
+		return nil, fmt.Errorf("can't create kafka producer: %v", err)
+	}
+	return &kafkaSink{p: p}, nil
+}
+
+// Write is a part of Sink interface
+func (s *kafkaSink) Write(item *nvd.NVDCVEFeedJSON10DefCVEItem) error {
+	value, err := json.Marshal(item)
+	if err != nil {
+		return err
+	}
+	var key []byte
+	if item.CVE != nil && item.CVE.CVEDataMeta != nil {
+		key = []byte(item.CVE.CVEDataMeta.ID)
+	}
+	return s.p.Send(key, value)
+}
+
+// Close is a part of Sink interface
+func (s *kafkaSink) Close() error {
+	return s.p.Close()
+}
+
+// stream converts the vulns and writes them to the sink one by one
+// unlike convert, it doesn't deduplicate vulnerabilities read more than once
+func stream(vulns <-chan Convertible, sink Sink) error {
+	defer stats.TrackTime("convert.time", time.Now(), time.Second)
+	for vuln := range vulns {
+		converted, err := vuln.Convert()
+		if err != nil {
+			logging.Recordf("error while converting vuln: %v", err)
+			conversionErrors.Inc(provider)
+			continue
+		}
+		if err := sink.Write(converted); err != nil {
+			sink.Close()
+			return fmt.Errorf("couldn't write vulnerability %s: %v", vuln.ID(), err)
+		}
+	}
+	if err := sink.Close(); err != nil {
+		return fmt.Errorf("couldn't close sink: %v", err)
+	}
+	return logging.Err()
+}
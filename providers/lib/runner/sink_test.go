@@ -0,0 +1,88 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+)
+
+func TestStream(t *testing.T) {
+	vulns := make(chan Convertible, 3)
+	vulns <- &revisedVuln{name: "a", modified: "2020-01-01T00:00Z"}
+	vulns <- &revisedVuln{name: "b", modified: "2020-01-01T00:00Z"}
+	vulns <- &revisedVuln{name: "a", modified: "2020-02-01T00:00Z"}
+	close(vulns)
+
+	var buf bytes.Buffer
+	if err := stream(vulns, NewWriterSink(&buf)); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var item nvd.NVDCVEFeedJSON10DefCVEItem
+		if err := json.Unmarshal(scanner.Bytes(), &item); err != nil {
+			t.Fatalf("can't decode line %q: %v", scanner.Text(), err)
+		}
+		got = append(got, item.CVE.CVEDataMeta.ID+"@"+item.LastModifiedDate)
+	}
+	// vulnerabilities are written as they come, without deduplication
+	want := []string{"a@2020-01-01T00:00Z", "b@2020-01-01T00:00Z", "a@2020-02-01T00:00Z"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+}
+
+func TestOpenSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "runner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "out.ndjson")
+
+	sink, err := OpenSink(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item, _ := (&revisedVuln{name: "a"}).Convert()
+	if err := sink.Write(item); err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Count(data, []byte("\n")) != 1 {
+		t.Fatalf("expected a single line, got %q", data)
+	}
+
+	if _, err := OpenSink("kafka://localhost:9092"); err == nil {
+		t.Fatal("expected an error for kafka sink without topic")
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+	"github.com/facebookincubator/nvdtools/providers/msrc/schema"
+)
+
+// Client downloads CVRF documents from the MSRC API
+type Client struct {
+	client.Client
+	baseURL string
+}
+
+// NewClient creates an object which is used to query the MSRC API
+// base url should point to the CVRF API, e.g. https://api.msrc.microsoft.com/cvrf/v3.0
+func NewClient(c client.Client, baseURL string) *Client {
+	return &Client{
+		Client:  c,
+		baseURL: baseURL,
+	}
+}
+
+// FetchAllVulnerabilities fetches all monthly documents released since the given time
+// vulnerabilities are often revised in later documents, only the latest revision is returned
+func (c *Client) FetchAllVulnerabilities(ctx context.Context, since int64) (<-chan runner.Convertible, error) {
+	updates, err := c.fetchUpdates(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("can't fetch list of updates: %v", err)
+	}
+
+	vulns := make(map[string]*schema.Vulnerability)
+	for _, update := range updates {
+		logging.Infof("fetching %s", update.ID)
+		doc, err := c.fetchCVRF(ctx, update.ID)
+		if err != nil {
+			logging.Errorf("can't fetch %s: %v", update.ID, err)
+			continue
+		}
+		for _, vuln := range doc.Vulnerabilities() {
+			vulns[vuln.CVE] = vuln
+		}
+	}
+
+	output := make(chan runner.Convertible)
+	go func() {
+		defer close(output)
+		for _, vuln := range vulns {
+			output <- vuln
+		}
+	}()
+
+	return output, nil
+}
+
+// fetchUpdates returns updates released after since, the oldest first
+func (c *Client) fetchUpdates(ctx context.Context, since int64) ([]*schema.Update, error) {
+	var updates schema.Updates
+	if err := c.query(ctx, "/updates", &updates); err != nil {
+		return nil, err
+	}
+
+	var result []*schema.Update
+	released := make(map[*schema.Update]time.Time)
+	for _, update := range updates.Value {
+		t, err := time.Parse(time.RFC3339, update.CurrentReleaseDate)
+		if err != nil {
+			logging.Recordf("can't parse release date of %s: %v", update.ID, err)
+			continue
+		}
+		if t.Unix() < since {
+			continue
+		}
+		released[update] = t
+		result = append(result, update)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return released[result[i]].Before(released[result[j]])
+	})
+	return result, nil
+}
+
+func (c *Client) fetchCVRF(ctx context.Context, id string) (*schema.CVRF, error) {
+	var doc schema.CVRF
+	if err := c.query(ctx, "/cvrf/"+id, &doc); err != nil {
+		return nil, err
+	}
+	return &doc, nil
+}
+
+func (c *Client) query(ctx context.Context, path string, v interface{}) error {
+	headers := http.Header{}
+	headers.Set("Accept", "application/json")
+	resp, err := client.Get(ctx, c, c.baseURL+path, headers)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("can't decode %s: %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,304 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const (
+	cveDataVersion = "4.0"
+	updateGuideURL = "https://msrc.microsoft.com/update-guide/vulnerability/"
+)
+
+var (
+	htmlTagRegex     = regexp.MustCompile(`<[^>]*>`)
+	archRegex        = regexp.MustCompile(`(?i)\s+for\s+(32-bit|64-bit|x64-based|arm64-based|arm-based|itanium-based)\s+(systems|editions)`)
+	servicePackRegex = regexp.MustCompile(`(?i)\s+service\s+pack\s+(\d+)`)
+	windowsOSRegex   = regexp.MustCompile(`^windows (\d|server|rt)`)
+	fixedBuildRegex  = regexp.MustCompile(`^\d+(\.\d+)+$`)
+)
+
+// product architectures mapped to target hardware
+var architectures = map[string]string{
+	"32-bit":        "x86",
+	"64-bit":        "x64",
+	"x64-based":     "x64",
+	"arm64-based":   "arm64",
+	"arm-based":     "arm",
+	"itanium-based": "itanium",
+}
+
+// ID is a part of the runner.Convertible interface
+func (vuln *Vulnerability) ID() string {
+	return vuln.CVE
+}
+
+// Convert is a part of the runner.Convertible interface
+func (vuln *Vulnerability) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	if vuln.CVE == "" {
+		return nil, fmt.Errorf("vulnerability %q doesn't have a cve id", vuln.Title.Value)
+	}
+
+	published, modified := vuln.dates()
+	item := nvd.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &nvd.CVEJSON40{
+			CVEDataMeta: &nvd.CVEJSON40CVEDataMeta{
+				ID:       vuln.ID(),
+				ASSIGNER: "microsoft.com",
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: cveDataVersion,
+			Description: &nvd.CVEJSON40Description{
+				DescriptionData: []*nvd.CVEJSON40LangString{
+					{
+						Lang:  "en",
+						Value: vuln.description(),
+					},
+				},
+			},
+			Problemtype: vuln.newProblemType(),
+			References:  vuln.newReferences(),
+		},
+		Configurations:   vuln.newConfigurations(),
+		Impact:           vuln.newImpact(),
+		LastModifiedDate: modified,
+		PublishedDate:    published,
+	}
+
+	return &item, nil
+}
+
+// AffectedProducts returns ids of products which are known to be affected
+func (vuln *Vulnerability) AffectedProducts() []string {
+	var ids []string
+	for _, ps := range vuln.ProductStatuses {
+		if ps.Type == StatusKnownAffected {
+			ids = append(ids, ps.ProductID...)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// FixedBuild returns the build in which the vulnerability was fixed for the given product, if known
+func (vuln *Vulnerability) FixedBuild(productID string) string {
+	for _, rem := range vuln.Remediations {
+		if rem.FixedBuild == "" || !fixedBuildRegex.MatchString(rem.FixedBuild) {
+			continue
+		}
+		for _, id := range rem.ProductID {
+			if id == productID {
+				return rem.FixedBuild
+			}
+		}
+	}
+	return ""
+}
+
+func (vuln *Vulnerability) description() string {
+	for _, note := range vuln.Notes {
+		if note.Title == "Description" && note.Value != "" {
+			return strings.TrimSpace(htmlTagRegex.ReplaceAllString(note.Value, ""))
+		}
+	}
+	return vuln.Title.Value
+}
+
+func (vuln *Vulnerability) dates() (published, modified string) {
+	for _, rev := range vuln.RevisionHistory {
+		t := msrcTimeToNVD(rev.Date)
+		if t == "" {
+			continue
+		}
+		if published == "" || t < published {
+			published = t
+		}
+		if t > modified {
+			modified = t
+		}
+	}
+	return published, modified
+}
+
+func (vuln *Vulnerability) newProblemType() *nvd.CVEJSON40Problemtype {
+	if len(vuln.CWE) == 0 {
+		return nil
+	}
+	data := &nvd.CVEJSON40ProblemtypeProblemtypeData{}
+	for _, cwe := range vuln.CWE {
+		data.Description = append(data.Description, &nvd.CVEJSON40LangString{
+			Lang:  "en",
+			Value: cwe.ID,
+		})
+	}
+	return &nvd.CVEJSON40Problemtype{
+		ProblemtypeData: []*nvd.CVEJSON40ProblemtypeProblemtypeData{data},
+	}
+}
+
+func (vuln *Vulnerability) newReferences() *nvd.CVEJSON40References {
+	refs := &nvd.CVEJSON40References{
+		ReferenceData: []*nvd.CVEJSON40Reference{
+			{
+				Name: vuln.Title.Value,
+				URL:  updateGuideURL + vuln.CVE,
+			},
+		},
+	}
+	seen := make(map[string]bool)
+	for _, rem := range vuln.Remediations {
+		if rem.URL == "" || seen[rem.URL] {
+			continue
+		}
+		seen[rem.URL] = true
+		refs.ReferenceData = append(refs.ReferenceData, &nvd.CVEJSON40Reference{
+			Name: rem.Description.Value,
+			URL:  rem.URL,
+		})
+	}
+	return refs
+}
+
+// newImpact uses the highest score of all affected products
+func (vuln *Vulnerability) newImpact() *nvd.NVDCVEFeedJSON10DefImpact {
+	var cvss *nvd.CVSSV30
+	for _, set := range vuln.CVSSScoreSets {
+		if set.Vector == "" || (cvss != nil && cvss.BaseScore >= set.BaseScore) {
+			continue
+		}
+		cvss = &nvd.CVSSV30{
+			BaseScore:    set.BaseScore,
+			BaseSeverity: severity(set.BaseScore),
+			VectorString: set.Vector,
+		}
+	}
+	if cvss == nil {
+		return nil
+	}
+	return &nvd.NVDCVEFeedJSON10DefImpact{
+		BaseMetricV3: &nvd.NVDCVEFeedJSON10DefImpactBaseMetricV3{CVSSV3: cvss},
+	}
+}
+
+// newConfigurations creates a match for each known affected product
+// if the fixed build is known, all builds before it are vulnerable
+func (vuln *Vulnerability) newConfigurations() *nvd.NVDCVEFeedJSON10DefConfigurations {
+	node := &nvd.NVDCVEFeedJSON10DefNode{Operator: "OR"}
+	seen := make(map[string]bool)
+	for _, id := range vuln.AffectedProducts() {
+		name, ok := vuln.ProductNames[id]
+		if !ok {
+			logging.Recordf("%s: unknown product id %s", vuln.CVE, id)
+			continue
+		}
+		attrs, err := ProductToCPE(name)
+		if err != nil {
+			logging.Recordf("%s: can't create cpe for product %q: %v", vuln.CVE, name, err)
+			continue
+		}
+		match := &nvd.NVDCVEFeedJSON10DefCPEMatch{
+			Cpe23Uri:            attrs.BindToFmtString(),
+			VersionEndExcluding: vuln.FixedBuild(id),
+			Vulnerable:          true,
+		}
+		key := match.Cpe23Uri + match.VersionEndExcluding
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		match.CPEName = []*nvd.NVDCVEFeedJSON10DefCPEName{
+			{
+				Cpe22Uri: attrs.BindToURI(),
+				Cpe23Uri: match.Cpe23Uri,
+			},
+		}
+		node.CPEMatch = append(node.CPEMatch, match)
+	}
+	return &nvd.NVDCVEFeedJSON10DefConfigurations{
+		CVEDataVersion: cveDataVersion,
+		Nodes:          []*nvd.NVDCVEFeedJSON10DefNode{node},
+	}
+}
+
+// ProductToCPE creates a CPE from the MSRC product name, following the naming used by recent NVD entries
+// e.g. "Windows 10 Version 1809 for x64-based Systems" becomes cpe:2.3:o:microsoft:windows_10_1809:*:*:*:*:*:*:x64:*
+// version is left as ANY, builds are matched using the fixed build from remediations
+func ProductToCPE(name string) (*wfn.Attributes, error) {
+	attrs := wfn.Attributes{Part: "a", Vendor: "microsoft"}
+
+	name = strings.Replace(name, "(Server Core installation)", "", -1)
+	if m := servicePackRegex.FindStringSubmatch(name); m != nil {
+		attrs.Update = "sp" + m[1]
+		name = servicePackRegex.ReplaceAllString(name, "")
+	}
+	if m := archRegex.FindStringSubmatch(name); m != nil {
+		attrs.TargetHW = architectures[strings.ToLower(m[1])]
+		name = archRegex.ReplaceAllString(name, "")
+	}
+
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.TrimPrefix(name, "microsoft ")
+	name = strings.Replace(name, " version ", " ", -1)
+	if name == "" {
+		return nil, fmt.Errorf("empty product name")
+	}
+	if windowsOSRegex.MatchString(name) {
+		attrs.Part = "o"
+	}
+
+	var err error
+	if attrs.Product, err = wfn.WFNize(strings.Join(strings.Fields(name), "_")); err != nil {
+		return nil, fmt.Errorf("can't wfnize product name %q: %v", name, err)
+	}
+	return &attrs, nil
+}
+
+// severity returns the qualitative rating of the cvss v3 score
+func severity(score float64) string {
+	switch {
+	case score >= 9.0:
+		return "CRITICAL"
+	case score >= 7.0:
+		return "HIGH"
+	case score >= 4.0:
+		return "MEDIUM"
+	case score > 0:
+		return "LOW"
+	}
+	return "NONE"
+}
+
+func msrcTimeToNVD(s string) string {
+	if s == "" {
+		return ""
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t.UTC().Format(nvd.TimeLayout)
+		}
+	}
+	logging.Recordf("cannot parse msrc time %q", s)
+	return ""
+}
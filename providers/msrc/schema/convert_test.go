@@ -0,0 +1,123 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const testCVRF = `{
+  "DocumentTitle": {"Value": "January 2023 Security Updates"},
+  "DocumentTracking": {"Identification": {"ID": {"Value": "2023-Jan"}}, "CurrentReleaseDate": "2023-01-10T08:00:00Z"},
+  "ProductTree": {"FullProductName": [
+    {"ProductID": "11568", "Value": "Windows 10 Version 1809 for 32-bit Systems"},
+    {"ProductID": "11569", "Value": "Windows 10 Version 1809 for x64-based Systems"},
+    {"ProductID": "11923", "Value": "Windows Server 2022"},
+    {"ProductID": "11924", "Value": "Windows Server 2022 (Server Core installation)"},
+    {"ProductID": "11762", "Value": "Microsoft Office 2019 for 32-bit editions"}
+  ]},
+  "Vulnerability": [{
+    "Title": {"Value": "Windows ALPC Elevation of Privilege Vulnerability"},
+    "CVE": "CVE-2023-21674",
+    "Notes": [{"Title": "Description", "Type": 2, "Value": "<p>Windows ALPC Elevation of Privilege Vulnerability</p>"}],
+    "ProductStatuses": [{"ProductID": ["11568", "11569", "11923", "11924"], "Type": 3}],
+    "CVSSScoreSets": [{"BaseScore": 8.8, "Vector": "CVSS:3.1/AV:L/AC:L/PR:L/UI:N/S:C/C:H/I:H/A:H", "ProductID": ["11568"]}],
+    "Remediations": [
+      {"Description": {"Value": "5022286"}, "URL": "https://catalog.update.microsoft.com/v7/site/Search.aspx?q=KB5022286", "ProductID": ["11568", "11569"], "Type": 2, "FixedBuild": "10.0.17763.3887"},
+      {"Description": {"Value": "5022291"}, "ProductID": ["11923", "11924"], "Type": 2, "FixedBuild": "10.0.20348.1487"}
+    ],
+    "RevisionHistory": [{"Date": "2023-01-10T08:00:00"}, {"Date": "2023-01-19T08:00:00"}]
+  }]
+}`
+
+func TestConvert(t *testing.T) {
+	var doc CVRF
+	if err := json.Unmarshal([]byte(testCVRF), &doc); err != nil {
+		t.Fatal(err)
+	}
+	vulns := doc.Vulnerabilities()
+	if len(vulns) != 1 {
+		t.Fatalf("expecting 1 vulnerability, got %d", len(vulns))
+	}
+
+	item, err := vulns[0].Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.PublishedDate != "2023-01-10T08:00Z" || item.LastModifiedDate != "2023-01-19T08:00Z" {
+		t.Fatalf("wrong dates: %s, %s", item.PublishedDate, item.LastModifiedDate)
+	}
+	if desc := item.CVE.Description.DescriptionData[0].Value; desc != "Windows ALPC Elevation of Privilege Vulnerability" {
+		t.Fatalf("wrong description %q", desc)
+	}
+	if sev := item.Impact.BaseMetricV3.CVSSV3.BaseSeverity; sev != "HIGH" {
+		t.Fatalf("expecting HIGH severity, got %s", sev)
+	}
+	// server core installation is the same product
+	if n := len(item.Configurations.Nodes[0].CPEMatch); n != 3 {
+		t.Fatalf("expecting 3 cpe matches, got %d", n)
+	}
+
+	v := nvd.ToVuln(item)
+	for i, tc := range []struct {
+		cpe   string
+		match bool
+	}{
+		{"cpe:/o:microsoft:windows_10_1809:10.0.17763.3886::~~~~x64~", true},
+		{"cpe:/o:microsoft:windows_10_1809:10.0.17763.3887::~~~~x64~", false},
+		{"cpe:/o:microsoft:windows_10_1809:10.0.17763.3886::~~~~arm64~", false},
+		{"cpe:/o:microsoft:windows_server_2022:10.0.20348.1400", true},
+		{"cpe:/a:microsoft:office_2019:16.0", false},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			attrs, err := wfn.Parse(tc.cpe)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if matched := len(v.Match([]*wfn.Attributes{attrs}, true)) != 0; matched != tc.match {
+				t.Fatalf("expecting match of %s to be %v, got %v", tc.cpe, tc.match, matched)
+			}
+		})
+	}
+}
+
+func TestProductToCPE(t *testing.T) {
+	for i, tc := range []struct {
+		name   string
+		expect string
+	}{
+		{"Windows 10 Version 22H2 for ARM64-based Systems", "cpe:2.3:o:microsoft:windows_10_22h2:*:*:*:*:*:*:arm64:*"},
+		{"Windows 11 version 21H2 for x64-based Systems", "cpe:2.3:o:microsoft:windows_11_21h2:*:*:*:*:*:*:x64:*"},
+		{"Windows Server 2012 R2 (Server Core installation)", "cpe:2.3:o:microsoft:windows_server_2012_r2:*:*:*:*:*:*:*:*"},
+		{"Windows Server 2008 for 32-bit Systems Service Pack 2", "cpe:2.3:o:microsoft:windows_server_2008:*:sp2:*:*:*:*:x86:*"},
+		{"Microsoft Office 2019 for 64-bit editions", "cpe:2.3:a:microsoft:office_2019:*:*:*:*:*:*:x64:*"},
+		{"Microsoft Edge (Chromium-based)", `cpe:2.3:a:microsoft:edge_\(chromium-based\):*:*:*:*:*:*:*:*`},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			attrs, err := ProductToCPE(tc.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := attrs.BindToFmtString(); got != tc.expect {
+				t.Fatalf("expecting %s, got %s", tc.expect, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+// based on the CVRF documents returned by the MSRC API, only the parts which are needed for conversion
+// https://api.msrc.microsoft.com/cvrf/v3.0/swagger/v3/swagger.json
+
+// product statuses
+const (
+	StatusKnownAffected = 3
+)
+
+// Updates is the list of monthly CVRF documents
+type Updates struct {
+	Value []*Update `json:"value"`
+}
+
+type Update struct {
+	ID                 string `json:"ID"`
+	Alias              string `json:"Alias"`
+	DocumentTitle      string `json:"DocumentTitle"`
+	InitialReleaseDate string `json:"InitialReleaseDate"`
+	CurrentReleaseDate string `json:"CurrentReleaseDate"`
+	CvrfURL            string `json:"CvrfUrl"`
+}
+
+// CVRF is a monthly document, e.g. 2023-Jan
+type CVRF struct {
+	DocumentTitle    Value `json:"DocumentTitle"`
+	DocumentTracking struct {
+		Identification struct {
+			ID Value `json:"ID"`
+		} `json:"Identification"`
+		InitialReleaseDate string `json:"InitialReleaseDate"`
+		CurrentReleaseDate string `json:"CurrentReleaseDate"`
+	} `json:"DocumentTracking"`
+	ProductTree struct {
+		FullProductName []*FullProductName `json:"FullProductName"`
+	} `json:"ProductTree"`
+	Vulnerability []*Vulnerability `json:"Vulnerability"`
+}
+
+type Value struct {
+	Value string `json:"Value"`
+}
+
+type FullProductName struct {
+	ProductID string `json:"ProductID"`
+	Value     string `json:"Value"`
+}
+
+// Vulnerability is a single CVE from the CVRF document
+type Vulnerability struct {
+	Title Value  `json:"Title"`
+	CVE   string `json:"CVE"`
+	Notes []struct {
+		Title string `json:"Title"`
+		Type  int    `json:"Type"`
+		Value string `json:"Value"`
+	} `json:"Notes"`
+	ProductStatuses []struct {
+		ProductID []string `json:"ProductID"`
+		Type      int      `json:"Type"`
+	} `json:"ProductStatuses"`
+	CVSSScoreSets []struct {
+		BaseScore     float64  `json:"BaseScore"`
+		TemporalScore float64  `json:"TemporalScore"`
+		Vector        string   `json:"Vector"`
+		ProductID     []string `json:"ProductID"`
+	} `json:"CVSSScoreSets"`
+	Remediations []struct {
+		Description Value    `json:"Description"`
+		URL         string   `json:"URL"`
+		ProductID   []string `json:"ProductID"`
+		Type        int      `json:"Type"`
+		SubType     string   `json:"SubType"`
+		FixedBuild  string   `json:"FixedBuild"`
+	} `json:"Remediations"`
+	CWE []struct {
+		ID    string `json:"ID"`
+		Value string `json:"Value"`
+	} `json:"CWE"`
+	RevisionHistory []struct {
+		Date string `json:"Date"`
+	} `json:"RevisionHistory"`
+
+	// ProductNames maps product ids used in the vulnerability to product names
+	// it's not part of the vulnerability in the CVRF document, but it's needed for conversion
+	ProductNames map[string]string `json:"ProductNames,omitempty"`
+}
+
+// Vulnerabilities returns all vulnerabilities from the document, with product names filled in
+func (doc *CVRF) Vulnerabilities() []*Vulnerability {
+	names := make(map[string]string, len(doc.ProductTree.FullProductName))
+	for _, p := range doc.ProductTree.FullProductName {
+		names[p.ProductID] = p.Value
+	}
+	for _, vuln := range doc.Vulnerability {
+		vuln.ProductNames = make(map[string]string)
+		for _, ps := range vuln.ProductStatuses {
+			for _, id := range ps.ProductID {
+				if name, ok := names[id]; ok {
+					vuln.ProductNames[id] = name
+				}
+			}
+		}
+	}
+	return doc.Vulnerability
+}
@@ -0,0 +1,166 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+)
+
+// DefaultBaseURL is the location of NVD APIs
+const DefaultBaseURL = "https://services.nvd.nist.gov/rest/json"
+
+const (
+	cvesPath = "/cves/2.0"
+	cpesPath = "/cpes/2.0"
+
+	// maximum number of results per page allowed by the APIs
+	maxCVEsPerPage = 2000
+	maxCPEsPerPage = 10000
+
+	// the APIs don't allow last modified ranges longer than 120 days
+	maxWindow = 120 * 24 * time.Hour
+
+	timeLayout = "2006-01-02T15:04:05.000-07:00"
+)
+
+// Client talks to NVD CVE and CPE APIs 2.0
+type Client struct {
+	client.Client
+	baseURL     string
+	apiKey      string
+	cvesPerPage int
+	cpesPerPage int
+}
+
+// NewClient creates an object which is used to talk to NVD APIs
+// base url should point to the APIs root, e.g. https://services.nvd.nist.gov/rest/json
+// api key is optional, requests are much more rate limited without it
+func NewClient(c client.Client, baseURL, apiKey string) *Client {
+	return &Client{
+		Client:      c,
+		baseURL:     baseURL,
+		apiKey:      apiKey,
+		cvesPerPage: maxCVEsPerPage,
+		cpesPerPage: maxCPEsPerPage,
+	}
+}
+
+// RequestsPerPeriod returns how many requests can be made in the given period of 30 seconds
+func RequestsPerPeriod(apiKey string) (int, time.Duration) {
+	if apiKey != "" {
+		return 50, 30 * time.Second
+	}
+	return 5, 30 * time.Second
+}
+
+// Configure wraps the client so that it's throttled according to NVD rate limits
+// and requests rejected by NVD are retried with backoff; NVD returns 403 when the limit is exceeded
+// and 503 when the service is overloaded
+func Configure(c client.Client, apiKey string) client.Client {
+	requests, period := RequestsPerPeriod(apiKey)
+	c = client.WithThrottling(c, period, requests)
+	return client.WithRetries(c, 5, 6*time.Second, client.Retry(http.StatusForbidden, http.StatusServiceUnavailable))
+}
+
+// FetchCVEs calls fn with every page of CVEs modified between since and until
+// all CVEs are fetched if since is zero
+func (c *Client) FetchCVEs(ctx context.Context, since, until time.Time, fn func(*Response) error) error {
+	return c.fetchModified(ctx, cvesPath, c.cvesPerPage, since, until, fn)
+}
+
+// FetchCPEs calls fn with every page of CPEs modified between since and until
+// all CPEs are fetched if since is zero
+func (c *Client) FetchCPEs(ctx context.Context, since, until time.Time, fn func(*Response) error) error {
+	return c.fetchModified(ctx, cpesPath, c.cpesPerPage, since, until, fn)
+}
+
+func (c *Client) fetchModified(ctx context.Context, path string, perPage int, since, until time.Time, fn func(*Response) error) error {
+	if since.IsZero() {
+		return c.fetchPages(ctx, path, perPage, url.Values{}, fn)
+	}
+	for _, w := range windows(since, until) {
+		query := url.Values{}
+		query.Set("lastModStartDate", w[0].UTC().Format(timeLayout))
+		query.Set("lastModEndDate", w[1].UTC().Format(timeLayout))
+		if err := c.fetchPages(ctx, path, perPage, query, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// windows splits the given time range into ranges accepted by the APIs
+func windows(since, until time.Time) [][2]time.Time {
+	var ws [][2]time.Time
+	for start := since; start.Before(until); start = start.Add(maxWindow) {
+		end := start.Add(maxWindow)
+		if end.After(until) {
+			end = until
+		}
+		ws = append(ws, [2]time.Time{start, end})
+	}
+	return ws
+}
+
+func (c *Client) fetchPages(ctx context.Context, path string, perPage int, query url.Values, fn func(*Response) error) error {
+	for start := 0; ; {
+		query.Set("startIndex", strconv.Itoa(start))
+		query.Set("resultsPerPage", strconv.Itoa(perPage))
+		page, err := c.fetchPage(ctx, c.baseURL+path+"?"+query.Encode())
+		if err != nil {
+			return err
+		}
+		if err := fn(page); err != nil {
+			return err
+		}
+		n := len(page.Records())
+		start += n
+		if n == 0 || start >= page.TotalResults {
+			return nil
+		}
+	}
+}
+
+func (c *Client) fetchPage(ctx context.Context, u string) (*Response, error) {
+	header := http.Header{}
+	if c.apiKey != "" {
+		header.Set("apiKey", c.apiKey)
+	}
+	resp, err := client.Get(ctx, c, u, header)
+	if err != nil {
+		return nil, fmt.Errorf("can't get %q: %v", u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4*1024))
+		return nil, fmt.Errorf("unexpected http response from %q (%q): %q", u, resp.Status, body)
+	}
+
+	var page Response
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("can't decode response from %q: %v", u, err)
+	}
+	return &page, nil
+}
@@ -0,0 +1,94 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestFetchCVEs(t *testing.T) {
+	const total = 5
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != cvesPath {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("apiKey") != "secret" {
+			http.Error(w, "missing api key", http.StatusForbidden)
+			return
+		}
+		start, _ := strconv.Atoi(r.URL.Query().Get("startIndex"))
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("resultsPerPage"))
+		fmt.Fprintf(w, `{"resultsPerPage":%d,"startIndex":%d,"totalResults":%d,"format":"NVD_CVE","version":"2.0","vulnerabilities":[`, perPage, start, total)
+		for i := start; i < start+perPage && i < total; i++ {
+			if i != start {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"cve":{"id":"CVE-2023-%04d","lastModified":"2023-10-12T00:00:00.000","vulnStatus":"Analyzed"}}`, i)
+		}
+		fmt.Fprint(w, "]}")
+	}))
+	defer srv.Close()
+
+	c := NewClient(http.DefaultClient, srv.URL, "secret")
+	c.cvesPerPage = 2
+
+	var ids []string
+	err := c.FetchCVEs(context.Background(), time.Time{}, time.Now(), func(page *Response) error {
+		for _, r := range page.Records() {
+			ids = append(ids, r.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 3 {
+		t.Fatalf("expecting 3 requests, got %d", requests)
+	}
+	if len(ids) != total || ids[4] != "CVE-2023-0004" {
+		t.Fatalf("wrong cves %v", ids)
+	}
+}
+
+func TestWindows(t *testing.T) {
+	until := time.Date(2023, 10, 12, 0, 0, 0, 0, time.UTC)
+	ws := windows(until.Add(-300*24*time.Hour), until)
+	if len(ws) != 3 {
+		t.Fatalf("expecting 3 windows, got %d", len(ws))
+	}
+	for i, w := range ws {
+		if w[1].Sub(w[0]) > maxWindow {
+			t.Fatalf("window %d is too long: %v", i, w[1].Sub(w[0]))
+		}
+		if i > 0 && !w[0].Equal(ws[i-1][1]) {
+			t.Fatalf("window %d doesn't start where the previous one ended", i)
+		}
+	}
+	if !ws[2][1].Equal(until) {
+		t.Fatalf("last window should end at %v, got %v", until, ws[2][1])
+	}
+	if ws := windows(until, until); len(ws) != 0 {
+		t.Fatalf("expecting no windows for an empty range, got %d", len(ws))
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Response is a page of results returned by the CVE or the CPE API
+// it's also used as the format of the local mirror files
+type Response struct {
+	ResultsPerPage  int              `json:"resultsPerPage"`
+	StartIndex      int              `json:"startIndex"`
+	TotalResults    int              `json:"totalResults"`
+	Format          string           `json:"format"`
+	Version         string           `json:"version"`
+	Timestamp       string           `json:"timestamp"`
+	Vulnerabilities []*Vulnerability `json:"vulnerabilities,omitempty"`
+	Products        []*Product       `json:"products,omitempty"`
+}
+
+// formats of responses
+const (
+	FormatCVE = "NVD_CVE"
+	FormatCPE = "NVD_CPE"
+)
+
+// Vulnerability is a single result of the CVE API
+type Vulnerability struct {
+	CVE *Record `json:"cve"`
+}
+
+// Product is a single result of the CPE API
+type Product struct {
+	CPE *Record `json:"cpe"`
+}
+
+// Record is a CVE or a CPE returned by the API
+// only fields needed to keep the mirror up to date are decoded, the record is kept as it was returned
+type Record struct {
+	// CVE id or CPE name id
+	ID           string
+	LastModified string
+	Raw          json.RawMessage
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface
+func (r *Record) UnmarshalJSON(data []byte) error {
+	var fields struct {
+		ID           string `json:"id"`
+		CPENameID    string `json:"cpeNameId"`
+		LastModified string `json:"lastModified"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	r.ID = fields.ID
+	if r.ID == "" {
+		r.ID = fields.CPENameID
+	}
+	if r.ID == "" {
+		return fmt.Errorf("record doesn't have an id")
+	}
+	r.LastModified = fields.LastModified
+	r.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (r *Record) MarshalJSON() ([]byte, error) {
+	return r.Raw, nil
+}
+
+// Records returns all records in the response
+func (resp *Response) Records() []*Record {
+	records := make([]*Record, 0, len(resp.Vulnerabilities)+len(resp.Products))
+	for _, vuln := range resp.Vulnerabilities {
+		if vuln.CVE != nil {
+			records = append(records, vuln.CVE)
+		}
+	}
+	for _, product := range resp.Products {
+		if product.CPE != nil {
+			records = append(records, product.CPE)
+		}
+	}
+	return records
+}
+
+// SetRecords replaces results in the response with the given records, according to the response format
+func (resp *Response) SetRecords(records []*Record) {
+	resp.Vulnerabilities, resp.Products = nil, nil
+	for _, r := range records {
+		switch resp.Format {
+		case FormatCPE:
+			resp.Products = append(resp.Products, &Product{CPE: r})
+		default:
+			resp.Vulnerabilities = append(resp.Vulnerabilities, &Vulnerability{CVE: r})
+		}
+	}
+	resp.StartIndex = 0
+	resp.ResultsPerPage = len(records)
+	resp.TotalResults = len(records)
+}
@@ -26,7 +26,8 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/facebookincubator/flog"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
 )
 
 // CPE defines the CPE data feed for synchronization.
@@ -146,10 +147,11 @@ func (cf cpeFile) Sync(ctx context.Context, src SourceConfig, localdir string) e
 	}
 	defer os.Remove(tempDataFilename)
 
-	// write etag file
-	etagFilename := filepath.Join(localdir, cf.EtagFile)
-	err = ioutil.WriteFile(etagFilename, []byte(etag), 0644)
-	if err != nil {
+	// CPE feeds don't have hashes published, so only keep downloads which decompress
+	if err = verifyCompressed(tempDataFilename, cf.compression()); err != nil {
+		return fmt.Errorf("can't sync %q: %v", sourceURL, err)
+	}
+	if err = verifySignature(ctx, src, sourceURL, tempDataFilename); err != nil {
 		return err
 	}
 
@@ -162,16 +164,19 @@ func (cf cpeFile) Sync(ctx context.Context, src SourceConfig, localdir string) e
 		return err
 	}
 	os.Remove(bakDataFilename)
-	return nil
+
+	// write etag file once the data file is replaced, so a failed sync is retried
+	etagFilename := filepath.Join(localdir, cf.EtagFile)
+	return ioutil.WriteFile(etagFilename, []byte(etag), 0644)
 }
 
 func (cf cpeFile) needsUpdate(ctx context.Context, targetURL, localdir string) (bool, error) {
-	flog.V(1).Infof("checking etag for %q", targetURL)
+	logging.Debugf("checking etag for %q", targetURL)
 	req, err := httpNewRequestContext(ctx, "HEAD", targetURL)
 	if err != nil {
 		return false, err
 	}
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := client.Default().Do(req)
 	if err != nil {
 		return false, err
 	}
@@ -185,12 +190,12 @@ func (cf cpeFile) needsUpdate(ctx context.Context, targetURL, localdir string) (
 	}
 	etagBytes, err := ioutil.ReadFile(filepath.Join(localdir, cf.EtagFile))
 	if err != nil {
-		flog.V(1).Infof("etag file %q for not exist in %q, needs sync", cf.EtagFile, localdir)
+		logging.Debugf("etag file %q for not exist in %q, needs sync", cf.EtagFile, localdir)
 		return true, nil
 	}
 	localEtag := string(etagBytes)
 	if localEtag != remoteEtag {
-		flog.V(1).Infof("data file %q needs update in %q: hash mismatch %q != %q", cf.DataFile, localdir, localEtag, remoteEtag)
+		logging.Debugf("data file %q needs update in %q: hash mismatch %q != %q", cf.DataFile, localdir, localEtag, remoteEtag)
 		return true, nil
 	}
 	return false, nil
@@ -198,12 +203,12 @@ func (cf cpeFile) needsUpdate(ctx context.Context, targetURL, localdir string) (
 
 // download file from targetURL, returns etag and path to local file.
 func (cf cpeFile) download(ctx context.Context, targetURL string) (string, string, error) {
-	flog.V(1).Infof("downloading data file %q", targetURL)
+	logging.Debugf("downloading data file %q", targetURL)
 	req, err := http.NewRequest("GET", targetURL, nil)
 	if err != nil {
 		return "", "", err
 	}
-	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	resp, err := client.Default().Do(req.WithContext(ctx))
 	if err != nil {
 		return "", "", err
 	}
@@ -216,6 +221,7 @@ func (cf cpeFile) download(ctx context.Context, targetURL string) (string, strin
 		return "", "", err
 	}
 	_, err = io.Copy(dataFile, resp.Body)
+	dataFile.Close()
 	if err != nil {
 		return "", "", err
 	}
@@ -15,11 +15,14 @@
 package nvd
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -53,9 +56,65 @@ func TestCPE(t *testing.T) {
 	}
 }
 
-type cpeTestServer struct{}
+type cpeTestServer struct {
+	etag    string
+	corrupt bool
+}
 
 func (ts cpeTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Etag", "foobar")
-	fmt.Fprintf(w, "hello, world")
+	etag := ts.etag
+	if etag == "" {
+		etag = "foobar"
+	}
+	w.Header().Set("Etag", etag)
+	data := cveGoldenDataFileGz
+	if strings.HasSuffix(r.URL.Path, ".zip") {
+		data = cveGoldenDataFileZip
+	}
+	if ts.corrupt {
+		data = data[:len(data)-8]
+	}
+	w.Write(data)
+}
+
+func TestCPECorruptDownload(t *testing.T) {
+	handler := &cpeTestServer{}
+	ts, src := httptestNewServer(handler)
+	defer ts.Close()
+
+	for _, cpe := range []CPE{cpe23xmlGz, cpe23xmlZip} {
+		t.Run(cpe.String(), func(t *testing.T) {
+			td, err := ioutil.TempDir("", "nvdsync-")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(td)
+
+			*handler = cpeTestServer{}
+			if err := cpe.Sync(context.Background(), src, td); err != nil {
+				t.Fatal(err)
+			}
+			dataFile := filepath.Join(td, "official-cpe-dictionary_v2.3.xml."+cpe.compression())
+			good, err := ioutil.ReadFile(dataFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			*handler = cpeTestServer{etag: "changed", corrupt: true}
+			if err := cpe.Sync(context.Background(), src, td); err == nil {
+				t.Fatal("expecting corrupt download to fail")
+			}
+			data, err := ioutil.ReadFile(dataFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, good) {
+				t.Fatal("good local copy was overwritten")
+			}
+			etag, _ := ioutil.ReadFile(filepath.Join(td, "official-cpe-dictionary_v2.3.etag"))
+			if string(etag) != "foobar" {
+				t.Fatalf("etag of the good copy was overwritten with %q", etag)
+			}
+		})
+	}
 }
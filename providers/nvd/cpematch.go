@@ -0,0 +1,109 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvd
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// CPEMatch defines the CPE match data feed for synchronization.
+// The feed resolves CPE match criteria of CVE configurations (e.g. version ranges) to the CPE names they match.
+type CPEMatch int
+
+// Supported CPE match feeds.
+const (
+	cpeMatchNone      CPEMatch = iota // CPE match feed isn't synchronized.
+	cpeMatch10jsonGz                  // CPE match data in JSON 1.0 format, gzip compressed.
+	cpeMatch10jsonZip                 // CPE match data in JSON 1.0 format, zip compressed.
+)
+
+// SupportedCPEMatch contains all supported CPE match data feeds indexed by name.
+var SupportedCPEMatch = map[string]CPEMatch{
+	"":                      cpeMatchNone,
+	"cpematch-1.0.json.gz":  cpeMatch10jsonGz,
+	"cpematch-1.0.json.zip": cpeMatch10jsonZip,
+}
+
+// Set implements the flag.Value interface.
+func (c *CPEMatch) Set(v string) error {
+	feed, exists := SupportedCPEMatch[v]
+	if !exists {
+		return fmt.Errorf("unsupported CPE match feed: %q", v)
+	}
+	*c = feed
+	return nil
+}
+
+// String implements the fmt.Stringer interface.
+func (c CPEMatch) String() string {
+	if c == cpeMatchNone {
+		return ""
+	}
+	return "cpematch-1.0.json." + c.compression()
+}
+
+// Help returns the CPE match flag help.
+func (c CPEMatch) Help() string {
+	opts := make([]string, 0, len(SupportedCPEMatch))
+	for k := range SupportedCPEMatch {
+		if k != "" {
+			opts = append(opts, k)
+		}
+	}
+	sort.Strings(opts)
+	return fmt.Sprintf(
+		"CPE match feed to sync (default: none)\navailable:\n%s",
+		strings.Join(opts, "\n"),
+	)
+}
+
+// compression returns the data feed compression: gz or zip.
+func (c CPEMatch) compression() string {
+	switch c {
+	case cpeMatch10jsonGz:
+		return "gz"
+	case cpeMatch10jsonZip:
+		return "zip"
+	default:
+		panic("unsupported CPE match compression")
+	}
+}
+
+// Sync synchronizes the CPE match feed to a local directory, it does nothing if the feed isn't set.
+// Like CVE feeds, the CPE match feed is published with a .meta file which tells whether it changed.
+func (c CPEMatch) Sync(ctx context.Context, src SourceConfig, localdir string) error {
+	if c == cpeMatchNone {
+		return nil
+	}
+	ff := feedFile{
+		MetaFile:    "nvdcpematch-1.0.meta",
+		DataFile:    "nvdcpematch-1.0.json." + c.compression(),
+		Compression: c.compression(),
+	}
+	u := url.URL{
+		Scheme: src.Scheme,
+		Host:   src.Host,
+		Path:   src.CPEMatchFeedPath,
+	}
+	baseURL := u.String()
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return ff.sync(ctx, src, baseURL, localdir)
+}
@@ -0,0 +1,74 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCPEMatch(t *testing.T) {
+	td, err := ioutil.TempDir("", "nvdsync-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(td)
+
+	handler := &cveTestServer{}
+	ts, src := httptestNewServer(handler)
+	defer ts.Close()
+
+	if err := cpeMatchNone.Sync(context.Background(), src, td); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := ioutil.ReadDir(td); len(files) != 0 {
+		t.Fatalf("expecting nothing to be synced, got %d files", len(files))
+	}
+
+	for _, cpeMatch := range []CPEMatch{cpeMatch10jsonGz, cpeMatch10jsonZip} {
+		label := []string{"CreateSync", "UseExistingSync"}
+		for i := 0; i < 2; i++ {
+			info := fmt.Sprintf("%s/%s", label[i], cpeMatch)
+			t.Run(info, func(t *testing.T) {
+				handler.compression = cpeMatch.compression()
+				if err := cpeMatch.Sync(context.Background(), src, td); err != nil {
+					t.Fatal(err)
+				}
+				for _, name := range []string{"nvdcpematch-1.0.meta", "nvdcpematch-1.0.json." + cpeMatch.compression()} {
+					if _, err := os.Stat(filepath.Join(td, name)); err != nil {
+						t.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestCPEMatchFlag(t *testing.T) {
+	var c CPEMatch
+	if err := c.Set("cpematch-1.0.json.gz"); err != nil || c != cpeMatch10jsonGz {
+		t.Fatalf("unexpected feed %v: %v", c, err)
+	}
+	if c.String() != "cpematch-1.0.json.gz" {
+		t.Fatalf("unexpected feed name %q", c)
+	}
+	if err := c.Set("cpematch-2.0.json.gz"); err == nil {
+		t.Fatal("expecting unsupported feed to fail")
+	}
+}
@@ -33,7 +33,7 @@ import (
 	"text/template"
 	"time"
 
-	"github.com/facebookincubator/flog"
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/providers/lib/client"
 )
 
@@ -171,24 +171,33 @@ func cveFileList(c CVE) []cveFile {
 		year := startingYear + i
 		suffix := strconv.Itoa(year)
 		f[i] = cveFile{
-			CVE:      c,
-			MetaFile: filefmt(version, suffix, "meta", ""),
-			DataFile: filefmt(version, suffix, encoding, compression),
+			CVE: c,
+			feedFile: feedFile{
+				MetaFile:    filefmt(version, suffix, "meta", ""),
+				DataFile:    filefmt(version, suffix, encoding, compression),
+				Compression: compression,
+			},
 		}
 	}
 
 	// recent
 	f[entries] = cveFile{
-		CVE:      c,
-		MetaFile: filefmt(version, "recent", "meta", ""),
-		DataFile: filefmt(version, "recent", encoding, compression),
+		CVE: c,
+		feedFile: feedFile{
+			MetaFile:    filefmt(version, "recent", "meta", ""),
+			DataFile:    filefmt(version, "recent", encoding, compression),
+			Compression: compression,
+		},
 	}
 
 	// modified
 	f[entries+1] = cveFile{
-		CVE:      c,
-		MetaFile: filefmt(version, "modified", "meta", ""),
-		DataFile: filefmt(version, "modified", encoding, compression),
+		CVE: c,
+		feedFile: feedFile{
+			MetaFile:    filefmt(version, "modified", "meta", ""),
+			DataFile:    filefmt(version, "modified", encoding, compression),
+			Compression: compression,
+		},
 	}
 
 	return f
@@ -196,8 +205,7 @@ func cveFileList(c CVE) []cveFile {
 
 type cveFile struct {
 	CVE
-	MetaFile string
-	DataFile string
+	feedFile
 }
 
 func (cf cveFile) baseURL(src SourceConfig) (string, error) {
@@ -233,8 +241,21 @@ func (cf cveFile) Sync(ctx context.Context, src SourceConfig, localdir string) e
 	if err != nil {
 		return err
 	}
+	return cf.feedFile.sync(ctx, src, baseURL, localdir)
+}
+
+// feedFile is a data file published along with a .meta file, which has its size and SHA256 hash.
+type feedFile struct {
+	MetaFile    string
+	DataFile    string
+	Compression string // gz or zip
+}
+
+// sync synchronizes the data file from baseURL to a local directory, if its .meta file changed.
+// The local copy is only replaced by a download matching the .meta file and, if it's configured, its signature.
+func (cf feedFile) sync(ctx context.Context, src SourceConfig, baseURL, localdir string) error {
 	remoteMetaURL := baseURL + cf.MetaFile
-	flog.V(1).Infof("checking meta file %q for updates to %q", cf.MetaFile, cf.DataFile)
+	logging.Debugf("checking meta file %q for updates to %q", cf.MetaFile, cf.DataFile)
 	remoteMeta, needsUpdate, err := cf.needsUpdate(ctx, remoteMetaURL, localdir)
 	if err != nil {
 		return err
@@ -248,6 +269,9 @@ func (cf cveFile) Sync(ctx context.Context, src SourceConfig, localdir string) e
 		return err
 	}
 	defer os.Remove(tempDataFilename)
+	if err = verifySignature(ctx, src, remoteFileURL, tempDataFilename); err != nil {
+		return err
+	}
 
 	// write metadata file
 	metaFilename := filepath.Join(localdir, cf.MetaFile)
@@ -268,15 +292,15 @@ func (cf cveFile) Sync(ctx context.Context, src SourceConfig, localdir string) e
 	return nil
 }
 
-func (cf cveFile) needsUpdate(ctx context.Context, remoteMetaURL, localdir string) (*metaFile, bool, error) {
-	flog.V(1).Infof("downloading meta file %q", remoteMetaURL)
+func (cf feedFile) needsUpdate(ctx context.Context, remoteMetaURL, localdir string) (*metaFile, bool, error) {
+	logging.Debugf("downloading meta file %q", remoteMetaURL)
 	remoteMeta, err := newMetaFromURL(ctx, remoteMetaURL)
 	if err != nil {
 		return nil, false, err
 	}
 	metaFilename := filepath.Join(localdir, cf.MetaFile)
 	if _, err := os.Stat(metaFilename); os.IsNotExist(err) {
-		flog.V(1).Infof("meta file %q does not exist in %q, needs sync", cf.MetaFile, localdir)
+		logging.Debugf("meta file %q does not exist in %q, needs sync", cf.MetaFile, localdir)
 		return &remoteMeta, true, nil
 	}
 	localMeta, err := newMetaFromFile(metaFilename)
@@ -284,21 +308,21 @@ func (cf cveFile) needsUpdate(ctx context.Context, remoteMetaURL, localdir strin
 		return nil, false, err
 	}
 	if !localMeta.Equal(remoteMeta) {
-		flog.V(1).Infof("data file %q needs update in %q: local%+v != remote%+v", cf.DataFile, localdir, localMeta, remoteMeta)
+		logging.Debugf("data file %q needs update in %q: local%+v != remote%+v", cf.DataFile, localdir, localMeta, remoteMeta)
 		return &remoteMeta, true, nil
 	}
 	dataFilename := filepath.Join(localdir, cf.DataFile)
 	fi, err := os.Stat(dataFilename)
 	if err != nil {
 		if os.IsNotExist(err) {
-			flog.V(1).Infof("data file %q does not exist in %q, needs sync", cf.DataFile, localdir)
+			logging.Debugf("data file %q does not exist in %q, needs sync", cf.DataFile, localdir)
 			return &remoteMeta, true, nil
 		}
 		return nil, false, err
 	}
 	var sizeOK bool
 	var hashFunc func(filename string) (string, error)
-	switch cf.compression() {
+	switch cf.Compression {
 	case "gz":
 		sizeOK = fi.Size() == int64(localMeta.GzSize)
 		hashFunc = gunzipFileAndComputeSHA256
@@ -307,7 +331,7 @@ func (cf cveFile) needsUpdate(ctx context.Context, remoteMetaURL, localdir strin
 		hashFunc = unzipFileAndComputeSHA256
 	}
 	if !sizeOK {
-		flog.V(1).Infof("data file %q needs update in %q: size mismatch", cf.DataFile, localdir)
+		logging.Debugf("data file %q needs update in %q: size mismatch", cf.DataFile, localdir)
 		return &remoteMeta, true, nil
 	}
 	hash, err := hashFunc(dataFilename)
@@ -315,7 +339,7 @@ func (cf cveFile) needsUpdate(ctx context.Context, remoteMetaURL, localdir strin
 		return nil, false, err
 	}
 	if hash != localMeta.SHA256 {
-		flog.V(1).Infof("data file %q needs update in %q: hash mismatch %q != %q", cf.DataFile, localdir, hash, localMeta.SHA256)
+		logging.Debugf("data file %q needs update in %q: hash mismatch %q != %q", cf.DataFile, localdir, hash, localMeta.SHA256)
 		return &remoteMeta, true, nil
 	}
 	return &remoteMeta, false, nil
@@ -323,12 +347,12 @@ func (cf cveFile) needsUpdate(ctx context.Context, remoteMetaURL, localdir strin
 
 // downloadAndVerify downloads a remote file into a temporary local file, and performs checksum using size and hash from m.
 // Returns the path to the local file.
-func (cf cveFile) downloadAndVerify(ctx context.Context, m *metaFile, remoteFileURL string) (string, error) {
+func (cf feedFile) downloadAndVerify(ctx context.Context, m *metaFile, remoteFileURL string) (string, error) {
 	req, err := httpNewRequestContext(ctx, "GET", remoteFileURL)
 	if err != nil {
 		return "", err
 	}
-	flog.V(1).Infof("downloading data file %q", remoteFileURL)
+	logging.Debugf("downloading data file %q", remoteFileURL)
 	resp, err := client.Default().Do(req)
 	if err != nil {
 		return "", err
@@ -339,7 +363,7 @@ func (cf cveFile) downloadAndVerify(ctx context.Context, m *metaFile, remoteFile
 	}
 	var wantSize int64
 	var hashFunc func(filename string) (string, error)
-	switch cf.compression() {
+	switch cf.Compression {
 	case "gz":
 		wantSize = int64(m.GzSize)
 		hashFunc = gunzipFileAndComputeSHA256
@@ -0,0 +1,269 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvd
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/compress"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/nvd/api"
+)
+
+// Delta writes delta feeds. It runs after the CVE feed, or the mirror of CVE API 2.0, was synchronized
+// and writes the CVEs added or modified since the previous synchronization, by comparing their last modified dates,
+// to nvdcve-{version}-delta-{time}.json.gz, in the format of the yearly files; so consumers can process increments
+// instead of whole yearly files. Last modified dates of all CVEs are kept in nvdcve-{version}-delta.state.gz,
+// the first synchronization only creates it.
+type Delta struct {
+	// Feed is the synchronized CVE feed, only JSON feeds are supported
+	Feed CVE
+	// API is set if the mirror of CVE API 2.0 was synchronized instead of the feed
+	API bool
+	// Compression of delta files, gzip if it's not set
+	Compression compress.Format
+}
+
+// Sync writes the delta feed to a local directory, it doesn't if no CVEs changed.
+func (d Delta) Sync(ctx context.Context, src SourceConfig, localdir string) error {
+	if !d.API && d.Feed.encoding() != "json" {
+		return fmt.Errorf("delta of %s feed isn't supported, only of JSON feeds", d.Feed)
+	}
+	name := "nvdcve-" + d.version()
+	stateFilename := filepath.Join(localdir, name+"-delta.state.gz")
+	previous, err := readDeltaState(stateFilename)
+	if err != nil {
+		return err
+	}
+
+	fis, err := ioutil.ReadDir(localdir)
+	if err != nil {
+		return err
+	}
+	yearly := regexp.MustCompile(`^` + regexp.QuoteMeta(name) + `-(\d{4}|other)\.json\.(gz|zst|xz|zip)$`)
+	current := make(map[string]string)
+	var header *deltaFeed
+	var changed []*api.Record
+	for _, fi := range fis {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !yearly.MatchString(fi.Name()) {
+			continue
+		}
+		feed, err := d.readFeed(filepath.Join(localdir, fi.Name()))
+		if err != nil {
+			return fmt.Errorf("can't read %q: %v", fi.Name(), err)
+		}
+		for _, r := range feed.records {
+			current[r.ID] = r.LastModified
+			if previous != nil && previous[r.ID] != r.LastModified {
+				changed = append(changed, r)
+			}
+		}
+		header = feed
+	}
+
+	switch {
+	case previous == nil:
+		logging.Debugf("delta state %q does not exist in %q, creating it", filepath.Base(stateFilename), localdir)
+	case len(changed) == 0:
+		logging.Debugf("no CVEs changed since the previous sync, not writing delta")
+	default:
+		sort.Slice(changed, func(i, j int) bool {
+			return changed[i].ID < changed[j].ID
+		})
+		header.records = changed
+		format := d.Compression
+		if format == "" {
+			format = compress.Gzip
+		}
+		deltaFilename := filepath.Join(localdir, name+"-delta-"+time.Now().UTC().Format("20060102T150405Z")+".json"+format.Ext())
+		logging.Debugf("writing %d changed CVEs to %q", len(changed), deltaFilename)
+		if err := writeJSON(deltaFilename, header.encode(), format); err != nil {
+			return fmt.Errorf("can't write delta %q: %v", deltaFilename, err)
+		}
+	}
+
+	// the state is written last, so changes are written to a delta again if writing this one failed
+	if err := writeJSON(stateFilename, current, compress.Gzip); err != nil {
+		return fmt.Errorf("can't write delta state %q: %v", stateFilename, err)
+	}
+	return nil
+}
+
+// version returns version of the feed files
+func (d Delta) version() string {
+	if d.API {
+		return "2.0"
+	}
+	return d.Feed.version()
+}
+
+// deltaFeed is a feed file: a JSON 1.x feed or an API 2.0 response
+type deltaFeed struct {
+	legacy  *legacyFeed
+	api     *api.Response
+	records []*api.Record
+}
+
+// legacyFeed is a JSON 1.x CVE feed, CVEs are kept as they are
+type legacyFeed struct {
+	DataType     string            `json:"CVE_data_type"`
+	DataFormat   string            `json:"CVE_data_format"`
+	DataVersion  string            `json:"CVE_data_version"`
+	NumberOfCVEs string            `json:"CVE_data_numberOfCVEs"`
+	Timestamp    string            `json:"CVE_data_timestamp"`
+	Items        []json.RawMessage `json:"CVE_Items"`
+}
+
+// readFeed reads a feed file compressed with zip, or gzip, zstd or xz
+func (d Delta) readFeed(filename string) (*deltaFeed, error) {
+	var r io.Reader
+	if filepath.Ext(filename) == ".zip" {
+		zr, err := zip.OpenReader(filename)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		if len(zr.File) != 1 {
+			return nil, fmt.Errorf("unexpected number of files in zip: want 1, have %d", len(zr.File))
+		}
+		f, err := zr.File[0].Open()
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	} else {
+		f, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		cr, err := compress.NewReader(f)
+		if err != nil {
+			return nil, err
+		}
+		defer cr.Close()
+		r = cr
+	}
+
+	if d.API {
+		var resp api.Response
+		if err := json.NewDecoder(r).Decode(&resp); err != nil {
+			return nil, err
+		}
+		return &deltaFeed{api: &resp, records: resp.Records()}, nil
+	}
+
+	var feed legacyFeed
+	if err := json.NewDecoder(r).Decode(&feed); err != nil {
+		return nil, err
+	}
+	records := make([]*api.Record, len(feed.Items))
+	for i, item := range feed.Items {
+		var fields struct {
+			CVE struct {
+				Meta struct {
+					ID string
+				} `json:"CVE_data_meta"`
+			} `json:"cve"`
+			LastModifiedDate string `json:"lastModifiedDate"`
+		}
+		if err := json.Unmarshal(item, &fields); err != nil {
+			return nil, err
+		}
+		if fields.CVE.Meta.ID == "" {
+			return nil, fmt.Errorf("CVE item %d doesn't have an id", i)
+		}
+		records[i] = &api.Record{ID: fields.CVE.Meta.ID, LastModified: fields.LastModifiedDate, Raw: item}
+	}
+	feed.Items = nil
+	return &deltaFeed{legacy: &feed, records: records}, nil
+}
+
+// encode returns the feed with its records, in the format it was read in
+func (f *deltaFeed) encode() interface{} {
+	if f.api != nil {
+		f.api.SetRecords(f.records)
+		f.api.Timestamp = time.Now().UTC().Format("2006-01-02T15:04:05.000")
+		return f.api
+	}
+	f.legacy.Items = make([]json.RawMessage, len(f.records))
+	for i, r := range f.records {
+		f.legacy.Items[i] = r.Raw
+	}
+	f.legacy.NumberOfCVEs = strconv.Itoa(len(f.records))
+	f.legacy.Timestamp = time.Now().UTC().Format("2006-01-02T15:04Z")
+	return f.legacy
+}
+
+// readDeltaState reads last modified dates of CVEs, it returns nil if the state doesn't exist
+func readDeltaState(filename string) (map[string]string, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("can't read delta state %q: %v", filename, err)
+	}
+	defer r.Close()
+	state := make(map[string]string)
+	if err := json.NewDecoder(r).Decode(&state); err != nil {
+		return nil, fmt.Errorf("can't read delta state %q: %v", filename, err)
+	}
+	return state, nil
+}
+
+// writeJSON writes v to filename as JSON compressed in the format, replacing the file atomically
+func writeJSON(filename string, v interface{}, format compress.Format) error {
+	tmp, err := ioutil.TempFile("", "nvdsync-data-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	w, err := compress.NewWriter(tmp, format)
+	if err == nil {
+		err = json.NewEncoder(w).Encode(v)
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	return replaceFile(tmp.Name(), filename)
+}
@@ -0,0 +1,159 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvd
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/providers/nvd/api"
+)
+
+// writeTestFeed writes a gzip compressed JSON file
+func writeTestFeed(t *testing.T, filename, data string) {
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	w := gzip.NewWriter(f)
+	if _, err := w.Write([]byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func legacyTestFeed(items ...string) string {
+	var cves []string
+	for _, item := range items {
+		parts := strings.Split(item, "@")
+		cves = append(cves, fmt.Sprintf(`{"cve": {"CVE_data_meta": {"ID": %q}}, "lastModifiedDate": %q}`, parts[0], parts[1]))
+	}
+	return `{"CVE_data_type": "CVE", "CVE_data_format": "MITRE", "CVE_data_version": "4.0", "CVE_Items": [` + strings.Join(cves, ",") + `]}`
+}
+
+func apiTestFeed(items ...string) string {
+	var cves []string
+	for _, item := range items {
+		parts := strings.Split(item, "@")
+		cves = append(cves, fmt.Sprintf(`{"cve": {"id": %q, "lastModified": %q}}`, parts[0], parts[1]))
+	}
+	return `{"format": "NVD_CVE", "version": "2.0", "vulnerabilities": [` + strings.Join(cves, ",") + `]}`
+}
+
+// readTestDelta returns ids of CVEs in the delta files in the directory
+func readTestDelta(t *testing.T, dir string, d Delta) []string {
+	matches, err := filepath.Glob(filepath.Join(dir, "nvdcve-"+d.version()+"-delta-*.json.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, filename := range matches {
+		feed, err := d.readFeed(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, r := range feed.records {
+			ids = append(ids, r.ID)
+		}
+		os.Remove(filename)
+	}
+	return ids
+}
+
+func TestDelta(t *testing.T) {
+	for _, tc := range []struct {
+		delta Delta
+		feed  func(items ...string) string
+	}{
+		{Delta{Feed: cve11jsonGz}, legacyTestFeed},
+		{Delta{API: true}, apiTestFeed},
+	} {
+		t.Run(tc.delta.version(), func(t *testing.T) {
+			td, err := ioutil.TempDir("", "nvdsync-")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(td)
+			yearly := func(year string) string {
+				return filepath.Join(td, "nvdcve-"+tc.delta.version()+"-"+year+".json.gz")
+			}
+			sync := func() []string {
+				if err := tc.delta.Sync(context.Background(), SourceConfig{}, td); err != nil {
+					t.Fatal(err)
+				}
+				return readTestDelta(t, td, tc.delta)
+			}
+
+			writeTestFeed(t, yearly("2019"), tc.feed("CVE-2019-0001@2019-01-01T00:00Z", "CVE-2019-0002@2019-01-01T00:00Z"))
+			writeTestFeed(t, yearly("2020"), tc.feed("CVE-2020-0001@2020-01-01T00:00Z"))
+			writeTestFeed(t, filepath.Join(td, "nvdcve-"+tc.delta.version()+"-recent.json.gz"), tc.feed("CVE-2020-0002@2020-01-02T00:00Z"))
+			if ids := sync(); len(ids) != 0 {
+				t.Fatalf("first sync shouldn't write delta, got %v", ids)
+			}
+			if ids := sync(); len(ids) != 0 {
+				t.Fatalf("unchanged feed shouldn't write delta, got %v", ids)
+			}
+
+			writeTestFeed(t, yearly("2019"), tc.feed("CVE-2019-0001@2019-01-01T00:00Z", "CVE-2019-0002@2020-02-01T00:00Z"))
+			writeTestFeed(t, yearly("2020"), tc.feed("CVE-2020-0001@2020-01-01T00:00Z", "CVE-2020-0003@2020-02-01T00:00Z"))
+			if ids := sync(); strings.Join(ids, ",") != "CVE-2019-0002,CVE-2020-0003" {
+				t.Fatalf("expected delta of modified and added CVEs, got %v", ids)
+			}
+			if ids := sync(); len(ids) != 0 {
+				t.Fatalf("unchanged feed shouldn't write delta, got %v", ids)
+			}
+		})
+	}
+}
+
+func TestDeltaFormat(t *testing.T) {
+	td, err := ioutil.TempDir("", "nvdsync-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(td)
+	d := Delta{API: true}
+	filename := filepath.Join(td, "nvdcve-2.0-2020.json.gz")
+	writeTestFeed(t, filename, apiTestFeed("CVE-2020-0001@2020-01-01T00:00:00.000"))
+	feed, err := d.readFeed(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(feed.encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var resp api.Response
+	if err := json.Unmarshal(data, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Format != api.FormatCVE || resp.TotalResults != 1 || resp.Records()[0].ID != "CVE-2020-0001" {
+		t.Fatalf("unexpected delta %s", data)
+	}
+
+	if err := (Delta{}).Sync(context.Background(), SourceConfig{}, td); err == nil {
+		t.Fatal("delta of XML feed should fail")
+	}
+}
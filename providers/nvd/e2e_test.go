@@ -31,7 +31,7 @@ var (
 	e2eCPE     = cpe23xmlGz
 )
 
-// test: go test -v -args -v=1 -logtostderr -e2e_enabled
+// test: go test -v -args -log_level=debug -e2e_enabled
 func init() {
 	flag.BoolVar(&e2eEnabled, "e2e_enabled", e2eEnabled, "enable end-to-end test")
 	flag.DurationVar(&e2eTimeout, "e2e_timeout", e2eTimeout, "timeout for end-to-end test")
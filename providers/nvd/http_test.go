@@ -30,10 +30,11 @@ func httptestNewServer(f http.Handler) (*httptest.Server, SourceConfig) {
 
 	tsurl, _ := url.Parse(ts.URL)
 	src := SourceConfig{
-		Scheme:      tsurl.Scheme,
-		Host:        tsurl.Host,
-		CVEFeedPath: "/",
-		CPEFeedPath: "/",
+		Scheme:           tsurl.Scheme,
+		Host:             tsurl.Host,
+		CVEFeedPath:      "/",
+		CPEFeedPath:      "/",
+		CPEMatchFeedPath: "/",
 	}
 	return ts, src
 }
@@ -0,0 +1,305 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/compress"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/nvd/api"
+)
+
+// records are merged into mirror files after this many were downloaded, to limit the memory usage
+const mirrorFlushSize = 50000
+
+// CVEAPI maintains a local mirror of NVD CVE API 2.0.
+// CVEs are stored in yearly files, nvdcve-2.0-{year}.json.gz, in the format of the API response.
+// Only CVEs modified since the previous synchronization are downloaded.
+type CVEAPI struct {
+	// Compression of mirror files, gzip if it's not set; files in other formats are recompressed
+	Compression compress.Format
+}
+
+// String returns the name of the mirror.
+func (CVEAPI) String() string {
+	return "nvdcve-2.0"
+}
+
+// Sync synchronizes the CVE mirror in a local directory.
+func (a CVEAPI) Sync(ctx context.Context, src SourceConfig, localdir string) error {
+	m := apiMirror{
+		name:        CVEAPI{}.String(),
+		format:      api.FormatCVE,
+		fetch:       (*api.Client).FetchCVEs,
+		compression: a.Compression,
+		file: func(r *api.Record) string {
+			// CVE-YYYY-NNNN
+			if parts := strings.SplitN(r.ID, "-", 3); len(parts) == 3 {
+				return "nvdcve-2.0-" + parts[1] + ".json"
+			}
+			return "nvdcve-2.0-other.json"
+		},
+		files: regexp.MustCompile(`^nvdcve-2\.0-(\d{4}|other)\.json$`),
+	}
+	return m.sync(ctx, src, localdir)
+}
+
+// CPEAPI maintains a local mirror of NVD CPE API 2.0.
+// CPEs are stored in nvdcpe-2.0.json.gz, in the format of the API response.
+// Only CPEs modified since the previous synchronization are downloaded.
+type CPEAPI struct {
+	// Compression of the mirror file, gzip if it's not set; files in other formats are recompressed
+	Compression compress.Format
+}
+
+// String returns the name of the mirror.
+func (CPEAPI) String() string {
+	return "nvdcpe-2.0"
+}
+
+// Sync synchronizes the CPE mirror in a local directory.
+func (a CPEAPI) Sync(ctx context.Context, src SourceConfig, localdir string) error {
+	m := apiMirror{
+		name:        CPEAPI{}.String(),
+		format:      api.FormatCPE,
+		fetch:       (*api.Client).FetchCPEs,
+		compression: a.Compression,
+		file: func(_ *api.Record) string {
+			return "nvdcpe-2.0.json"
+		},
+		files: regexp.MustCompile(`^nvdcpe-2\.0\.json$`),
+	}
+	return m.sync(ctx, src, localdir)
+}
+
+type apiMirror struct {
+	name   string
+	format string
+	fetch  func(c *api.Client, ctx context.Context, since, until time.Time, fn func(*api.Response) error) error
+	// file returns the name of the mirror file in which the record is stored, without the compression extension
+	file func(r *api.Record) string
+	// files matches names of all mirror files, without the compression extension
+	files       *regexp.Regexp
+	compression compress.Format
+}
+
+func (m apiMirror) sync(ctx context.Context, src SourceConfig, localdir string) error {
+	// the time of the last synchronization is stored in the meta file
+	metaFilename := filepath.Join(localdir, m.name+".meta")
+	var since time.Time
+	if data, err := ioutil.ReadFile(metaFilename); err == nil {
+		if since, err = time.Parse(time.RFC3339, strings.TrimPrefix(strings.TrimSpace(string(data)), "lastModifiedDate:")); err != nil {
+			return fmt.Errorf("malformed data in local metadata %q: %v", metaFilename, err)
+		}
+		logging.Debugf("fetching %s records modified since %v", m.name, since)
+	} else {
+		logging.Debugf("meta file %q does not exist in %q, fetching all %s records", m.name+".meta", localdir, m.name)
+	}
+	until := time.Now().UTC()
+
+	if err := m.recompress(localdir); err != nil {
+		return err
+	}
+
+	c := client.WithUserAgent(client.Default(), UserAgent())
+	apiClient := api.NewClient(api.Configure(c, src.APIKey), src.APIURL, src.APIKey)
+
+	updates := make(map[string][]*api.Record)
+	var pending int
+	flush := func() error {
+		for file, records := range updates {
+			if err := m.merge(localdir, file, records); err != nil {
+				return err
+			}
+		}
+		updates, pending = make(map[string][]*api.Record), 0
+		return nil
+	}
+
+	err := m.fetch(apiClient, ctx, since, until, func(page *api.Response) error {
+		logging.Debugf("got %d %s records at %d of %d", len(page.Records()), m.name, page.StartIndex, page.TotalResults)
+		syncRecords.Add(float64(len(page.Records())), m.name)
+		for _, r := range page.Records() {
+			file := m.file(r)
+			updates[file] = append(updates[file], r)
+			pending++
+		}
+		if pending >= mirrorFlushSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := flush(); err != nil {
+		return err
+	}
+
+	// merging is idempotent, so the time is stored only after everything was merged
+	return writeFileAtomic(metaFilename, []byte("lastModifiedDate:"+until.Format(time.RFC3339)+"\r\n"))
+}
+
+// compressionFormat returns the compression of mirror files
+func (m apiMirror) compressionFormat() compress.Format {
+	if m.compression == "" {
+		return compress.Gzip
+	}
+	return m.compression
+}
+
+// recompress rewrites mirror files which are compressed in another format, e.g. after the compression was changed
+func (m apiMirror) recompress(localdir string) error {
+	fis, err := ioutil.ReadDir(localdir)
+	if err != nil {
+		return err
+	}
+	for _, fi := range fis {
+		file := compress.TrimExt(fi.Name())
+		if m.files.MatchString(file) && fi.Name() != file+m.compressionFormat().Ext() {
+			logging.Debugf("recompressing %q with %s", fi.Name(), m.compressionFormat())
+			if err := m.merge(localdir, file, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// merge merges records into the mirror file, replacing records with the same id
+// the file is read in any compression and written in the compression of the mirror
+func (m apiMirror) merge(localdir, file string, records []*api.Record) error {
+	filename := filepath.Join(localdir, file+m.compressionFormat().Ext())
+	resp := api.Response{Format: m.format, Version: "2.0"}
+	// existing copies of the file, the one in the compression of the mirror first
+	var previous []string
+	for i, f := range []compress.Format{m.compressionFormat(), compress.Gzip, compress.Zstd, compress.XZ, compress.None} {
+		if i > 0 && f == m.compressionFormat() {
+			continue
+		}
+		name := filepath.Join(localdir, file+f.Ext())
+		if _, err := os.Stat(name); err == nil {
+			previous = append(previous, name)
+		}
+	}
+	if len(previous) > 0 {
+		if err := readMirrorFile(previous[0], &resp); err != nil {
+			return fmt.Errorf("can't read mirror file %q: %v", previous[0], err)
+		}
+	}
+
+	byID := make(map[string]*api.Record)
+	for _, r := range resp.Records() {
+		byID[r.ID] = r
+	}
+	for _, r := range records {
+		if old, ok := byID[r.ID]; ok && old.LastModified > r.LastModified {
+			continue
+		}
+		byID[r.ID] = r
+	}
+	merged := make([]*api.Record, 0, len(byID))
+	for _, r := range byID {
+		merged = append(merged, r)
+	}
+	sort.Slice(merged, func(i, j int) bool {
+		return merged[i].ID < merged[j].ID
+	})
+	resp.SetRecords(merged)
+	resp.Timestamp = time.Now().UTC().Format("2006-01-02T15:04:05.000")
+
+	logging.Debugf("writing %d records to %q", len(merged), filename)
+	tmp, err := ioutil.TempFile("", "nvdsync-data-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	w, err := compress.NewWriter(tmp, m.compressionFormat())
+	if err == nil {
+		err = json.NewEncoder(w).Encode(resp)
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return fmt.Errorf("can't write mirror file %q: %v", filename, err)
+	}
+	if err := replaceFile(tmp.Name(), filename); err != nil {
+		return err
+	}
+	// the file was read from the first one, the rest are stale copies in other formats
+	for _, name := range previous {
+		if name != filename {
+			os.Remove(name)
+		}
+	}
+	return nil
+}
+
+// readMirrorFile decodes the mirror file, which may be compressed
+func readMirrorFile(filename string, resp *api.Response) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r, err := compress.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	return json.NewDecoder(r).Decode(resp)
+}
+
+// writeFileAtomic writes data to a temporary file and moves it to filename
+func writeFileAtomic(filename string, data []byte) error {
+	tmp, err := ioutil.TempFile("", "nvdsync-meta-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	tmp.Close()
+	if err != nil {
+		return err
+	}
+	return replaceFile(tmp.Name(), filename)
+}
+
+// replaceFile moves tmp to filename, keeping the old file if it fails
+func replaceFile(tmp, filename string) error {
+	bak := filename + ".bak"
+	xRename(filename, bak)
+	if err := xRename(tmp, filename); err != nil {
+		xRename(bak, filename)
+		return err
+	}
+	os.Remove(bak)
+	return nil
+}
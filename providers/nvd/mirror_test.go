@@ -0,0 +1,126 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/compress"
+	"github.com/facebookincubator/nvdtools/providers/nvd/api"
+)
+
+func TestCVEAPISync(t *testing.T) {
+	// all cves on the first sync, a modified one afterwards
+	full := `{"totalResults":3,"format":"NVD_CVE","version":"2.0","vulnerabilities":[
+		{"cve":{"id":"CVE-2021-44228","lastModified":"2023-04-03T20:15:07.000","vulnStatus":"Analyzed"}},
+		{"cve":{"id":"CVE-2023-0001","lastModified":"2023-01-01T00:00:00.000","vulnStatus":"Analyzed"}},
+		{"cve":{"id":"CVE-2023-0002","lastModified":"2023-01-01T00:00:00.000","vulnStatus":"Analyzed"}}]}`
+	modified := `{"totalResults":1,"format":"NVD_CVE","version":"2.0","vulnerabilities":[
+		{"cve":{"id":"CVE-2023-0002","lastModified":"2023-10-12T00:00:00.000","vulnStatus":"Modified"}}]}`
+
+	var incremental bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if got := q.Get("lastModStartDate") != ""; got != incremental {
+			t.Errorf("expecting incremental request to be %v, got %v: %s", incremental, got, r.URL)
+		}
+		if incremental {
+			fmt.Fprint(w, modified)
+		} else {
+			fmt.Fprint(w, full)
+		}
+	}))
+	defer srv.Close()
+
+	d, err := ioutil.TempDir("", "nvdsync-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(d)
+
+	src := *NewSourceConfig()
+	src.APIURL = srv.URL
+	src.APIKey = "secret"
+	if err := (CVEAPI{}).Sync(context.Background(), src, d); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(d, "nvdcve-2.0.meta")); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(readMirror(t, filepath.Join(d, "nvdcve-2.0-2021.json.gz"))); n != 1 {
+		t.Fatalf("expecting 1 cve from 2021, got %d", n)
+	}
+
+	incremental = true
+	if err := (CVEAPI{}).Sync(context.Background(), src, d); err != nil {
+		t.Fatal(err)
+	}
+	records := readMirror(t, filepath.Join(d, "nvdcve-2.0-2023.json.gz"))
+	if len(records) != 2 {
+		t.Fatalf("expecting 2 cves from 2023, got %d", len(records))
+	}
+	if r := records[1]; r.ID != "CVE-2023-0002" || r.LastModified != "2023-10-12T00:00:00.000" {
+		t.Fatalf("cve wasn't updated: %s", r.Raw)
+	}
+
+	// changing the compression recompresses all files
+	if _, err := exec.LookPath("xz"); err != nil {
+		t.Skip("xz isn't installed")
+	}
+	if err := (CVEAPI{Compression: compress.XZ}).Sync(context.Background(), src, d); err != nil {
+		t.Fatal(err)
+	}
+	for _, year := range []string{"2021", "2023"} {
+		if _, err := os.Stat(filepath.Join(d, "nvdcve-2.0-"+year+".json.gz")); !os.IsNotExist(err) {
+			t.Fatalf("expecting gzip file from %s to be removed: %v", year, err)
+		}
+	}
+	if n := len(readMirror(t, filepath.Join(d, "nvdcve-2.0-2021.json.xz"))); n != 1 {
+		t.Fatalf("expecting 1 cve from 2021, got %d", n)
+	}
+	if n := len(readMirror(t, filepath.Join(d, "nvdcve-2.0-2023.json.xz"))); n != 2 {
+		t.Fatalf("expecting 2 cves from 2023, got %d", n)
+	}
+}
+
+func readMirror(t *testing.T, filename string) []*api.Record {
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := compress.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	var resp api.Response
+	if err := json.NewDecoder(r).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.TotalResults != len(resp.Vulnerabilities) {
+		t.Fatalf("total results %d don't match %d vulnerabilities", resp.TotalResults, len(resp.Vulnerabilities))
+	}
+	return resp.Records()
+}
@@ -22,10 +22,15 @@ import (
 
 // SourceConfig is the configuration of the NVD data feed source.
 type SourceConfig struct {
-	Scheme      string `envconfig:"NVDSYNC_SCHEME" default:"https"`
-	Host        string `envconfig:"NVDSYNC_HOST" default:"nvd.nist.gov"`
-	CVEFeedPath string `envconfig:"NVDSYNC_CVE_FEED_PATH" default:"/feeds/{{.Encoding}}/cve/{{.Version}}/"`
-	CPEFeedPath string `envconfig:"NVDSYNC_CPE_FEED_PATH" default:"/feeds/xml/cpe/dictionary/"`
+	Scheme           string `envconfig:"NVDSYNC_SCHEME" default:"https"`
+	Host             string `envconfig:"NVDSYNC_HOST" default:"nvd.nist.gov"`
+	CVEFeedPath      string `envconfig:"NVDSYNC_CVE_FEED_PATH" default:"/feeds/{{.Encoding}}/cve/{{.Version}}/"`
+	CPEFeedPath      string `envconfig:"NVDSYNC_CPE_FEED_PATH" default:"/feeds/xml/cpe/dictionary/"`
+	CPEMatchFeedPath string `envconfig:"NVDSYNC_CPEMATCH_FEED_PATH" default:"/feeds/json/cpematch/1.0/"`
+	GPGKeyring       string `envconfig:"NVDSYNC_GPG_KEYRING" default:""`
+	SignatureSuffix  string `envconfig:"NVDSYNC_SIGNATURE_SUFFIX" default:".asc"`
+	APIURL           string `envconfig:"NVDSYNC_API_URL" default:"https://services.nvd.nist.gov/rest/json"`
+	APIKey           string `envconfig:"NVDSYNC_API_KEY" default:""`
 }
 
 // NewSourceConfig creates and initializes a new SourceConfig with values from envconfig.
@@ -57,4 +62,9 @@ func (src *SourceConfig) AddFlags(fs *flag.FlagSet) {
 	flag.StringVar(&src.Host, "src_host", src.Host, "source host\nenv: NVDSYNC_HOST")
 	flag.StringVar(&src.CVEFeedPath, "src_cve_feed_path", src.CVEFeedPath, "source path for CVE feeds\nenv: NVDSYNC_CVE_FEED_PATH")
 	flag.StringVar(&src.CPEFeedPath, "src_cpe_feed_path", src.CPEFeedPath, "source path for CPE feeds\nenv: NVDSYNC_CPE_FEED_PATH")
+	flag.StringVar(&src.CPEMatchFeedPath, "src_cpematch_feed_path", src.CPEMatchFeedPath, "source path for CPE match feeds\nenv: NVDSYNC_CPEMATCH_FEED_PATH")
+	flag.StringVar(&src.GPGKeyring, "gpg_keyring", src.GPGKeyring, "keyring with OpenPGP keys of the source; if set, detached signatures of downloaded feeds are verified with gpgv and feeds with bad or missing signatures aren't stored\nenv: NVDSYNC_GPG_KEYRING")
+	flag.StringVar(&src.SignatureSuffix, "signature_suffix", src.SignatureSuffix, "suffix of the detached signature of a feed, appended to its url\nenv: NVDSYNC_SIGNATURE_SUFFIX")
+	flag.StringVar(&src.APIURL, "src_api_url", src.APIURL, "source url of NVD CVE and CPE APIs 2.0\nenv: NVDSYNC_API_URL")
+	flag.StringVar(&src.APIKey, "api_key", src.APIKey, "NVD API key, requests are rate limited much more without it\nenv: NVDSYNC_API_KEY")
 }
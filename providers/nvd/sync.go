@@ -19,6 +19,33 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/metrics"
+	"github.com/facebookincubator/nvdtools/storage"
+)
+
+var (
+	syncDuration = metrics.NewGauge(
+		"nvdtools_sync_duration_seconds",
+		"How long the last synchronization of the feed took.",
+		"feed",
+	)
+	syncErrors = metrics.NewCounter(
+		"nvdtools_sync_errors_total",
+		"Number of failed synchronizations of the feed.",
+		"feed",
+	)
+	syncLastSuccess = metrics.NewGauge(
+		"nvdtools_sync_last_success_timestamp_seconds",
+		"Unix time of the last successful synchronization of the feed.",
+		"feed",
+	)
+	syncRecords = metrics.NewCounter(
+		"nvdtools_sync_records_total",
+		"Number of records fetched from NVD APIs.",
+		"feed",
+	)
 )
 
 // Syncer is an abstract interface for data feed synchronizers.
@@ -46,6 +73,10 @@ type Sync struct {
 	Feeds    []Syncer
 	Source   *SourceConfig
 	LocalDir string
+	// Store is the target of the synchronization if it's set, e.g. an object store bucket.
+	// LocalDir is used as the staging directory then: objects are pulled to it before syncing the feeds,
+	// and the files which changed are pushed back after, on condition nobody changed them in the meantime.
+	Store storage.Store
 }
 
 // Do executes the synchronization.
@@ -59,10 +90,29 @@ func (s Sync) Do(ctx context.Context) error {
 		src = NewSourceConfig()
 	}
 	vsrc := *src
+	var snap storage.Snapshot
+	if s.Store != nil {
+		if snap, err = storage.Pull(ctx, s.Store, s.LocalDir); err != nil {
+			return fmt.Errorf("can't pull feeds from the store: %v", err)
+		}
+	}
 	var errors SyncError
 	for _, feed := range s.Feeds {
-		if err = feed.Sync(ctx, vsrc, s.LocalDir); err != nil {
+		name := syncerName(feed)
+		start := time.Now()
+		err = feed.Sync(ctx, vsrc, s.LocalDir)
+		syncDuration.Set(time.Since(start).Seconds(), name)
+		if err != nil {
+			syncErrors.Inc(name)
 			errors = append(errors, err.Error())
+			continue
+		}
+		syncLastSuccess.Set(float64(time.Now().Unix()), name)
+	}
+	if s.Store != nil {
+		// feeds which synced are pushed even if others failed, each file is replaced atomically
+		if err = storage.Push(ctx, s.Store, s.LocalDir, snap); err != nil {
+			errors = append(errors, fmt.Sprintf("can't push feeds to the store: %v", err))
 		}
 	}
 	if len(errors) == 0 {
@@ -70,3 +120,11 @@ func (s Sync) Do(ctx context.Context) error {
 	}
 	return errors
 }
+
+// syncerName returns the name of the feed used in metrics
+func syncerName(feed Syncer) string {
+	if s, ok := feed.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return strings.ToLower(strings.TrimPrefix(fmt.Sprintf("%T", feed), "nvd."))
+}
@@ -0,0 +1,31 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvd
+
+import "testing"
+
+func TestSyncerName(t *testing.T) {
+	cases := map[string]Syncer{
+		"cve-1.1.json.gz": cve11jsonGz,
+		"nvdcve-2.0":      CVEAPI{},
+		"nvdcpe-2.0":      CPEAPI{},
+		"delta":           Delta{},
+	}
+	for expected, feed := range cases {
+		if name := syncerName(feed); name != expected {
+			t.Errorf("expected %q, got %q", expected, name)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+)
+
+// gpgvCommand is the command verifying detached OpenPGP signatures.
+var gpgvCommand = "gpgv"
+
+// verifySignature verifies the detached signature of the data file downloaded from dataURL, if a keyring is
+// configured in src; the signature is expected next to the data file, with the configured suffix.
+func verifySignature(ctx context.Context, src SourceConfig, dataURL, dataFilename string) error {
+	if src.GPGKeyring == "" {
+		return nil
+	}
+	sigURL := dataURL + src.SignatureSuffix
+	logging.Debugf("downloading signature %q", sigURL)
+	sigFilename, err := downloadToTempFile(ctx, sigURL)
+	if err != nil {
+		return fmt.Errorf("can't download signature of %q: %v", dataURL, err)
+	}
+	defer os.Remove(sigFilename)
+	if err := gpgVerify(src.GPGKeyring, sigFilename, dataFilename); err != nil {
+		return fmt.Errorf("bad signature of %q: %v", dataURL, err)
+	}
+	return nil
+}
+
+// gpgVerify verifies the detached signature of the file with keys from the keyring.
+func gpgVerify(keyring, sigFilename, dataFilename string) error {
+	var stderr bytes.Buffer
+	cmd := exec.Command(gpgvCommand, "--keyring", keyring, sigFilename, dataFilename)
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return fmt.Errorf("%v: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// verifyCompressed checks the integrity of a gzip or zip compressed file by decompressing it.
+func verifyCompressed(filename, compression string) error {
+	var err error
+	switch compression {
+	case "gz":
+		_, err = gunzipFileAndComputeSHA256(filename)
+	case "zip":
+		_, err = unzipFileAndComputeSHA256(filename)
+	}
+	if err != nil {
+		return fmt.Errorf("corrupt data file: %v", err)
+	}
+	return nil
+}
+
+// downloadToTempFile downloads a remote file into a temporary local file, returns the path to the local file.
+func downloadToTempFile(ctx context.Context, remoteURL string) (string, error) {
+	req, err := httpNewRequestContext(ctx, "GET", remoteURL)
+	if err != nil {
+		return "", err
+	}
+	resp, err := client.Default().Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err = httpResponseNotOK(resp); err != nil {
+		return "", err
+	}
+	f, err := ioutil.TempFile("", "nvdsync-sig-")
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(f, io.LimitReader(resp.Body, 1024*1024))
+	f.Close()
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,82 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvd
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// signedTestServer serves CVE feed files along with their signatures
+type signedTestServer struct {
+	cveTestServer
+	signed bool
+}
+
+func (ts signedTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if strings.HasSuffix(r.URL.Path, ".asc") {
+		if !ts.signed {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("signature"))
+		return
+	}
+	ts.cveTestServer.ServeHTTP(w, r)
+}
+
+func TestVerifySignature(t *testing.T) {
+	defer func(cmd string) { gpgvCommand = cmd }(gpgvCommand)
+
+	handler := &signedTestServer{cveTestServer: cveTestServer{compression: "gz"}}
+	ts, src := httptestNewServer(handler)
+	defer ts.Close()
+	src.SignatureSuffix = ".asc"
+
+	for i, tc := range []struct {
+		keyring string
+		signed  bool
+		command string
+		fail    bool
+	}{
+		{command: "false"},
+		{keyring: "keyring.gpg", signed: true, command: "true"},
+		{keyring: "keyring.gpg", signed: true, command: "false", fail: true},
+		{keyring: "keyring.gpg", signed: false, command: "true", fail: true},
+	} {
+		td, err := ioutil.TempDir("", "nvdsync-")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(td)
+
+		handler.signed = tc.signed
+		gpgvCommand = tc.command
+		src.GPGKeyring = tc.keyring
+		err = cpeMatch10jsonGz.Sync(context.Background(), src, td)
+		if tc.fail != (err != nil) {
+			t.Fatalf("case %d: unexpected result of sync: %v", i+1, err)
+		}
+		_, err = os.Stat(filepath.Join(td, "nvdcpematch-1.0.json.gz"))
+		if tc.fail != os.IsNotExist(err) {
+			t.Fatalf("case %d: data file should be stored only if its signature is good: %v", i+1, err)
+		}
+	}
+}
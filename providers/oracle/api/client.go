@@ -0,0 +1,71 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"compress/bzip2"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+	"github.com/facebookincubator/nvdtools/providers/oracle/schema"
+)
+
+// all advisories are in this file
+const ovalPath = "/com.oracle.elsa-all.xml.bz2"
+
+// Client downloads oracle linux OVAL definitions
+type Client struct {
+	client.Client
+	baseURL string
+}
+
+// NewClient creates an object which is used to download oracle linux OVAL definitions
+// base url should point to the OVAL directory, e.g. https://linux.oracle.com/security/oval
+func NewClient(c client.Client, baseURL string) *Client {
+	return &Client{
+		Client:  c,
+		baseURL: baseURL,
+	}
+}
+
+// FetchAllAdvisories downloads all OVAL definitions and returns those issued since the given time
+func (c *Client) FetchAllAdvisories(ctx context.Context, since int64) (<-chan runner.Convertible, error) {
+	resp, err := client.Get(ctx, c, c.baseURL+ovalPath, http.Header{})
+	if err != nil {
+		return nil, fmt.Errorf("can't download oval definitions: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var oval schema.OVAL
+	if err := xml.NewDecoder(bzip2.NewReader(resp.Body)).Decode(&oval); err != nil {
+		return nil, fmt.Errorf("can't decode oval definitions: %v", err)
+	}
+
+	output := make(chan runner.Convertible)
+	go func() {
+		defer close(output)
+		for _, def := range oval.Definitions {
+			if def.IssuedSince(since) {
+				output <- def
+			}
+		}
+	}()
+
+	return output, nil
+}
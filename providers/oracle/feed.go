@@ -0,0 +1,80 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oracle
+
+import (
+	"compress/bzip2"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/providers/oracle/schema"
+	"github.com/facebookincubator/nvdtools/rpm"
+)
+
+// Feed is a collection of advisories, keyed by the advisory id
+type Feed map[string]*schema.Definition
+
+// LoadFeed loads a feed downloaded by oracle2nvd
+func LoadFeed(path string) (Feed, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open file %q: %v", path, err)
+	}
+	defer f.Close()
+
+	var feed Feed
+	if err := json.NewDecoder(f).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("can't decode feed: %v", err)
+	}
+	return feed, nil
+}
+
+// LoadOVAL loads advisories from the OVAL definitions file published by oracle
+// bzip2 compressed files are supported as well
+func LoadOVAL(path string) (Feed, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open file %q: %v", path, err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".bz2") {
+		r = bzip2.NewReader(f)
+	}
+	return loadOVAL(r)
+}
+
+func loadOVAL(r io.Reader) (Feed, error) {
+	var oval schema.OVAL
+	if err := xml.NewDecoder(r).Decode(&oval); err != nil {
+		return nil, fmt.Errorf("can't decode oval definitions: %v", err)
+	}
+	feed := make(Feed, len(oval.Definitions))
+	for _, def := range oval.Definitions {
+		feed[def.ID()] = def
+	}
+	return feed, nil
+}
+
+// Checker returns a checker which knows whether a package on some oracle linux release has been fixed
+// distro should be created with schema.DistroCPE, e.g. cpe:/o:oracle:linux:8
+func (feed Feed) Checker() (rpm.Checker, error) {
+	return NewPackageFeed(feed)
+}
@@ -0,0 +1,142 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oracle
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/providers/oracle/schema"
+	"github.com/facebookincubator/nvdtools/rpm"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestPackageFeedListFixedCVEs(t *testing.T) {
+	feed, err := loadOVAL(strings.NewReader(testOVAL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pf, err := NewPackageFeed(feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ol8, err := schema.DistroCPE("8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ol9, err := schema.DistroCPE("9")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tc := range []struct {
+		distro *wfn.Attributes
+		pkg    string
+		expect []string
+	}{
+		{ol8, "openssl-1:1.1.1k-9.el8_7.x86_64", []string{"CVE-2023-0215", "CVE-2023-0286"}},
+		{ol8, "openssl-libs-1:1.1.1k-9.el8_7.x86_64", []string{"CVE-2023-0215", "CVE-2023-0286"}},
+		{ol8, "openssl-1:1.1.1k-7.el8_6.x86_64", []string{}},
+		{ol9, "openssl-1:1.1.1k-9.el8_7.x86_64", []string{}},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			pkg, err := rpm.Parse(tc.pkg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := pf.ListFixedCVEs(tc.distro, pkg); !reflect.DeepEqual(got, tc.expect) {
+				t.Fatalf("expecting %v, got %v", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	feed, err := loadOVAL(strings.NewReader(testOVAL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	def, ok := feed["ELSA-2023-0946"]
+	if !ok {
+		t.Fatalf("advisory should be keyed by elsa id, got %v", feed)
+	}
+	item, err := def.Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.PublishedDate != "2023-02-28T00:00Z" {
+		t.Fatalf("wrong published date %q", item.PublishedDate)
+	}
+	if score := item.Impact.BaseMetricV3.CVSSV3.BaseScore; score != 7.4 {
+		t.Fatalf("wrong base score %.1f", score)
+	}
+	if n := len(item.Configurations.Nodes); n != 2 {
+		t.Fatalf("expecting 2 nodes, got %d", n)
+	}
+	if cpe := item.Configurations.Nodes[0].CPEMatch[0].Cpe22Uri; cpe != "cpe:/o:oracle:linux:8" {
+		t.Fatalf("wrong distro cpe %q", cpe)
+	}
+}
+
+const testOVAL = `<?xml version="1.0" encoding="UTF-8"?>
+<oval_definitions xmlns="http://oval.mitre.org/XMLSchema/oval-definitions-5">
+  <definitions>
+    <definition id="oval:com.oracle.elsa:def:20230946" version="501" class="patch">
+      <metadata>
+        <title>ELSA-2023-0946:  openssl security update (IMPORTANT)</title>
+        <affected family="unix"><platform>Oracle Linux 8</platform></affected>
+        <reference source="elsa" ref_id="ELSA-2023-0946" ref_url="https://linux.oracle.com/errata/ELSA-2023-0946.html"/>
+        <reference source="CVE" ref_id="CVE-2023-0215" ref_url="https://linux.oracle.com/cve/CVE-2023-0215.html"/>
+        <reference source="CVE" ref_id="CVE-2023-0286" ref_url="https://linux.oracle.com/cve/CVE-2023-0286.html"/>
+        <description>[1:1.1.1k-9] - Fixed X.400 address type confusion in X.509 GeneralName</description>
+        <advisory>
+          <severity>IMPORTANT</severity>
+          <rights>Copyright 2023 Oracle, Inc.</rights>
+          <issued date="2023-02-28"/>
+          <cve cvss3="5.9/CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:N/I:N/A:H" href="https://linux.oracle.com/cve/CVE-2023-0215.html" public="20230207">CVE-2023-0215</cve>
+          <cve cvss3="7.4/CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:N/A:H" href="https://linux.oracle.com/cve/CVE-2023-0286.html" public="20230207">CVE-2023-0286</cve>
+        </advisory>
+      </metadata>
+      <criteria operator="AND">
+        <criterion test_ref="oval:com.oracle.elsa:tst:20230946001" comment="Oracle Linux 8 is installed"/>
+        <criteria operator="OR">
+          <criteria operator="AND">
+            <criterion test_ref="oval:com.oracle.elsa:tst:20230946002" comment="Oracle Linux arch is x86_64"/>
+            <criteria operator="OR">
+              <criteria operator="AND">
+                <criterion test_ref="oval:com.oracle.elsa:tst:20230946003" comment="openssl is earlier than 1:1.1.1k-9.el8_7"/>
+                <criterion test_ref="oval:com.oracle.elsa:tst:20230946004" comment="openssl is signed with the Oracle Linux 8 key"/>
+              </criteria>
+              <criteria operator="AND">
+                <criterion test_ref="oval:com.oracle.elsa:tst:20230946005" comment="openssl-libs is earlier than 1:1.1.1k-9.el8_7"/>
+                <criterion test_ref="oval:com.oracle.elsa:tst:20230946006" comment="openssl-libs is signed with the Oracle Linux 8 key"/>
+              </criteria>
+            </criteria>
+          </criteria>
+          <criteria operator="AND">
+            <criterion test_ref="oval:com.oracle.elsa:tst:20230946007" comment="Oracle Linux arch is aarch64"/>
+            <criteria operator="AND">
+              <criterion test_ref="oval:com.oracle.elsa:tst:20230946003" comment="openssl is earlier than 1:1.1.1k-9.el8_7"/>
+              <criterion test_ref="oval:com.oracle.elsa:tst:20230946004" comment="openssl is signed with the Oracle Linux 8 key"/>
+            </criteria>
+          </criteria>
+        </criteria>
+      </criteria>
+    </definition>
+  </definitions>
+</oval_definitions>`
@@ -0,0 +1,267 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/rpm"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const (
+	cveDataVersion = "4.0"
+	issuedLayout   = "2006-01-02"
+)
+
+var (
+	// e.g. "openssl is earlier than 1:1.1.1k-9.el8_7"
+	earlierThanRegex = regexp.MustCompile(`^(\S+) is earlier than (\S+)$`)
+	// e.g. "Oracle Linux 8"
+	platformRegex = regexp.MustCompile(`^Oracle Linux (\d+)$`)
+)
+
+// ID is a part of the runner.Convertible interface
+func (def *Definition) ID() string {
+	for _, ref := range def.Metadata.References {
+		if ref.Source == "elsa" {
+			return ref.RefID
+		}
+	}
+	return def.DefID
+}
+
+// Convert is a part of the runner.Convertible interface
+func (def *Definition) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	distros, err := def.Distros()
+	if err != nil {
+		return nil, err
+	}
+
+	item := nvd.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &nvd.CVEJSON40{
+			CVEDataMeta: &nvd.CVEJSON40CVEDataMeta{
+				ID:       def.ID(),
+				ASSIGNER: "oracle.com",
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: cveDataVersion,
+			Description: &nvd.CVEJSON40Description{
+				DescriptionData: []*nvd.CVEJSON40LangString{
+					{
+						Lang:  "en",
+						Value: def.Metadata.Description,
+					},
+				},
+			},
+			References: def.newReferences(),
+		},
+		Configurations:   def.newConfigurations(distros),
+		Impact:           def.newImpact(),
+		LastModifiedDate: convertTime(def.Metadata.Advisory.Issued.Date),
+		PublishedDate:    convertTime(def.Metadata.Advisory.Issued.Date),
+	}
+
+	return &item, nil
+}
+
+// CVEs returns CVEs fixed by the advisory
+func (def *Definition) CVEs() []string {
+	var cves []string
+	for _, ref := range def.Metadata.References {
+		if ref.Source == "CVE" {
+			cves = append(cves, ref.RefID)
+		}
+	}
+	return cves
+}
+
+// Distros returns CPEs of all oracle linux releases the advisory applies to
+func (def *Definition) Distros() ([]*wfn.Attributes, error) {
+	var distros []*wfn.Attributes
+	for _, platform := range def.Metadata.Platforms {
+		m := platformRegex.FindStringSubmatch(platform)
+		if m == nil {
+			return nil, fmt.Errorf("unknown platform %q", platform)
+		}
+		distro, err := DistroCPE(m[1])
+		if err != nil {
+			return nil, err
+		}
+		distros = append(distros, distro)
+	}
+	return distros, nil
+}
+
+// Fixed returns all packages fixed by the advisory, sorted by name and version
+// packages are found in criterion comments, arch is not known
+func (def *Definition) Fixed() []*rpm.Package {
+	var pkgs []*rpm.Package
+	seen := make(map[string]bool)
+	var walk func(*Criteria)
+	walk = func(c *Criteria) {
+		for _, crit := range c.Criterions {
+			m := earlierThanRegex.FindStringSubmatch(crit.Comment)
+			if m == nil || seen[crit.Comment] {
+				continue
+			}
+			seen[crit.Comment] = true
+			// add .src to parse it correctly
+			pkg, err := rpm.Parse(m[1] + "-" + m[2] + ".src")
+			if err != nil {
+				logging.Recordf("%s: can't parse package in %q: %v", def.ID(), crit.Comment, err)
+				continue
+			}
+			pkgs = append(pkgs, pkg)
+		}
+		for _, sub := range c.Criterias {
+			walk(sub)
+		}
+	}
+	walk(&def.Criteria)
+
+	sort.Slice(pkgs, func(i, j int) bool {
+		return rpm.Compare(pkgs[i], pkgs[j]) < 0
+	})
+	return pkgs
+}
+
+func (def *Definition) newReferences() *nvd.CVEJSON40References {
+	refs := &nvd.CVEJSON40References{}
+	for _, ref := range def.Metadata.References {
+		refs.ReferenceData = append(refs.ReferenceData, &nvd.CVEJSON40Reference{
+			Name: ref.RefID,
+			URL:  ref.RefURL,
+		})
+	}
+	return refs
+}
+
+// newImpact uses the highest score of all fixed CVEs
+func (def *Definition) newImpact() *nvd.NVDCVEFeedJSON10DefImpact {
+	var cvss *nvd.CVSSV30
+	for _, cve := range def.Metadata.Advisory.CVEs {
+		parts := strings.SplitN(cve.CVSS3, "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		score, err := strconv.ParseFloat(parts[0], 64)
+		if err != nil {
+			logging.Recordf("%s: can't parse cvss3 score of %s: %v", def.ID(), cve.CVEID, err)
+			continue
+		}
+		if cvss == nil || score > cvss.BaseScore {
+			cvss = &nvd.CVSSV30{
+				BaseScore:    score,
+				VectorString: parts[1],
+			}
+		}
+	}
+	if cvss == nil {
+		return nil
+	}
+	return &nvd.NVDCVEFeedJSON10DefImpact{
+		BaseMetricV3: &nvd.NVDCVEFeedJSON10DefImpactBaseMetricV3{CVSSV3: cvss},
+	}
+}
+
+// newConfigurations creates a node for each fixed package on each distro, the same way redhat does
+func (def *Definition) newConfigurations(distros []*wfn.Attributes) *nvd.NVDCVEFeedJSON10DefConfigurations {
+	var nodes []*nvd.NVDCVEFeedJSON10DefNode
+	for _, pkg := range def.Fixed() {
+		pkgAttrs, err := package2wfn(pkg)
+		if err != nil {
+			logging.Recordf("%s: can't create wfn from package: %v", def.ID(), err)
+			continue
+		}
+		for _, distro := range distros {
+			nodes = append(nodes, &nvd.NVDCVEFeedJSON10DefNode{
+				Operator: "AND",
+				CPEMatch: []*nvd.NVDCVEFeedJSON10DefCPEMatch{
+					{
+						Cpe22Uri:   distro.BindToURI(),
+						Vulnerable: false,
+					},
+					{
+						Cpe22Uri:   pkgAttrs.BindToURI(),
+						Cpe23Uri:   pkgAttrs.BindToFmtString(),
+						Vulnerable: false,
+					},
+				},
+			})
+		}
+	}
+
+	return &nvd.NVDCVEFeedJSON10DefConfigurations{
+		CVEDataVersion: cveDataVersion,
+		Nodes:          nodes,
+	}
+}
+
+// DistroCPE returns the CPE of the oracle linux release, e.g. cpe:/o:oracle:linux:8
+func DistroCPE(release string) (*wfn.Attributes, error) {
+	if _, err := strconv.Atoi(release); err != nil {
+		return nil, fmt.Errorf("unknown oracle linux release %q", release)
+	}
+	return wfn.NewAttributesBuilder().Part("o").Vendor("oracle").Product("linux").Version(release).Build()
+}
+
+func package2wfn(pkg *rpm.Package) (*wfn.Attributes, error) {
+	attrs := wfn.NewAttributesWithAny()
+	attrs.Part = "a"
+	var err error
+	if attrs.Product, err = wfn.WFNize(pkg.Name); err != nil {
+		return nil, fmt.Errorf("couldn't wfnize name %q: %v", pkg.Name, err)
+	}
+	if attrs.Version, err = wfn.WFNize(pkg.Label.Version); err != nil {
+		return nil, fmt.Errorf("couldn't wfnize version %q: %v", pkg.Label.Version, err)
+	}
+	if attrs.Update, err = wfn.WFNize(pkg.Label.Release); err != nil {
+		return nil, fmt.Errorf("couldn't wfnize release %q: %v", pkg.Label.Release, err)
+	}
+	return attrs, nil
+}
+
+// IssuedSince returns whether the advisory was issued since the given time
+func (def *Definition) IssuedSince(since int64) bool {
+	if since <= 0 {
+		return true
+	}
+	t, err := time.Parse(issuedLayout, def.Metadata.Advisory.Issued.Date)
+	if err != nil {
+		// can't know, better to include it
+		return true
+	}
+	return t.Unix() >= since
+}
+
+func convertTime(oracleTime string) string {
+	t, err := time.Parse(issuedLayout, oracleTime)
+	if err != nil {
+		if oracleTime != "" {
+			logging.Recordf("unable to parse time: %v", err)
+		}
+		return oracleTime
+	}
+	return t.Format(nvd.TimeLayout)
+}
@@ -0,0 +1,73 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+// based on the Oracle Linux OVAL definitions, only the parts which are needed to get fixed packages
+// https://linux.oracle.com/security/oval/
+
+// OVAL is the root of the OVAL definitions file
+type OVAL struct {
+	Definitions []*Definition `xml:"definitions>definition" json:"definitions"`
+}
+
+// Definition describes a single ELSA advisory
+type Definition struct {
+	DefID    string   `xml:"id,attr" json:"id"`
+	Class    string   `xml:"class,attr" json:"class"`
+	Metadata Metadata `xml:"metadata" json:"metadata"`
+	Criteria Criteria `xml:"criteria" json:"criteria"`
+}
+
+type Metadata struct {
+	Title       string       `xml:"title" json:"title"`
+	Platforms   []string     `xml:"affected>platform" json:"platforms"`
+	References  []*Reference `xml:"reference" json:"references"`
+	Description string       `xml:"description" json:"description"`
+	Advisory    struct {
+		Severity string `xml:"severity" json:"severity"`
+		Issued   struct {
+			Date string `xml:"date,attr" json:"date"`
+		} `xml:"issued" json:"issued"`
+		CVEs []*AdvisoryCVE `xml:"cve" json:"cves"`
+	} `xml:"advisory" json:"advisory"`
+}
+
+type Reference struct {
+	Source string `xml:"source,attr" json:"source"`
+	RefID  string `xml:"ref_id,attr" json:"ref_id"`
+	RefURL string `xml:"ref_url,attr" json:"ref_url"`
+}
+
+// AdvisoryCVE has the score of a CVE fixed by the advisory
+// cvss3 is in score/vector format, e.g. 7.4/CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:N/A:H
+type AdvisoryCVE struct {
+	CVEID  string `xml:",chardata" json:"id"`
+	CVSS2  string `xml:"cvss2,attr" json:"cvss2,omitempty"`
+	CVSS3  string `xml:"cvss3,attr" json:"cvss3,omitempty"`
+	Href   string `xml:"href,attr" json:"href,omitempty"`
+	Public string `xml:"public,attr" json:"public,omitempty"`
+}
+
+// Criteria is a tree of criterions, fixed packages are found in criterion comments
+type Criteria struct {
+	Operator   string       `xml:"operator,attr" json:"operator"`
+	Criterions []*Criterion `xml:"criterion" json:"criterions,omitempty"`
+	Criterias  []*Criteria  `xml:"criteria" json:"criterias,omitempty"`
+}
+
+type Criterion struct {
+	TestRef string `xml:"test_ref,attr" json:"test_ref"`
+	Comment string `xml:"comment,attr" json:"comment"`
+}
@@ -0,0 +1,142 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+	"github.com/facebookincubator/nvdtools/providers/osv/schema"
+)
+
+// Client downloads OSV dumps
+type Client struct {
+	client.Client
+	baseURL string
+}
+
+// NewClient creates an object which is used to download OSV dumps
+// base url should point to the bucket with dumps, e.g. https://osv-vulnerabilities.storage.googleapis.com
+func NewClient(c client.Client, baseURL string) *Client {
+	return &Client{
+		Client:  c,
+		baseURL: baseURL,
+	}
+}
+
+// FetchAllVulnerabilities downloads all.zip dumps for given ecosystems and returns all vulnerabilities
+// which were modified since the given time. Withdrawn vulnerabilities are skipped
+func (c *Client) FetchAllVulnerabilities(ctx context.Context, since int64, ecosystems []string) (<-chan runner.Convertible, error) {
+	output := make(chan runner.Convertible)
+	go func() {
+		defer close(output)
+		for _, ecosystem := range ecosystems {
+			logging.Infof("fetching ecosystem %s", ecosystem)
+			if err := c.fetchEcosystem(ctx, ecosystem, since, output); err != nil {
+				logging.Errorf("can't fetch ecosystem %s: %v", ecosystem, err)
+			}
+		}
+	}()
+	return output, nil
+}
+
+func (c *Client) fetchEcosystem(ctx context.Context, ecosystem string, since int64, output chan<- runner.Convertible) error {
+	resp, err := client.Get(ctx, c, fmt.Sprintf("%s/%s/all.zip", c.baseURL, ecosystem), http.Header{})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// zip needs random access, store it in a temporary file
+	f, err := ioutil.TempFile("", "osv")
+	if err != nil {
+		return fmt.Errorf("can't create temporary file: %v", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	size, err := io.Copy(f, resp.Body)
+	if err != nil {
+		return fmt.Errorf("can't download dump: %v", err)
+	}
+
+	return ReadZip(f, size, since, output)
+}
+
+// ReadZip reads all vulnerabilities from an OSV zip dump which were modified since the given time
+func ReadZip(r io.ReaderAt, size int64, since int64, output chan<- runner.Convertible) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return fmt.Errorf("can't open zip: %v", err)
+	}
+
+	for _, zf := range zr.File {
+		if !strings.HasSuffix(zf.Name, ".json") {
+			continue
+		}
+		vuln, err := readVulnerability(zf)
+		if err != nil {
+			logging.Errorf("can't read %s: %v", zf.Name, err)
+			continue
+		}
+		if Accept(vuln, since) {
+			output <- vuln
+		}
+	}
+
+	return nil
+}
+
+func readVulnerability(zf *zip.File) (*schema.Vulnerability, error) {
+	rc, err := zf.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var vuln schema.Vulnerability
+	if err := json.NewDecoder(rc).Decode(&vuln); err != nil {
+		return nil, fmt.Errorf("can't decode vulnerability: %v", err)
+	}
+	return &vuln, nil
+}
+
+// Accept returns whether the vulnerability should be used
+// withdrawn vulnerabilities and those not modified since the given time are skipped
+func Accept(vuln *schema.Vulnerability, since int64) bool {
+	if vuln.Withdrawn != "" {
+		return false
+	}
+	if since <= 0 {
+		return true
+	}
+	modified, err := time.Parse(time.RFC3339Nano, vuln.Modified)
+	if err != nil {
+		// can't know, better to include it
+		return true
+	}
+	return modified.Unix() >= since
+}
@@ -0,0 +1,341 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/cvss2"
+	"github.com/facebookincubator/nvdtools/cvss3"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/semver"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const (
+	cveDataVersion = "4.0"
+)
+
+// ID is a part of the runner.Convertible interface
+func (vuln *Vulnerability) ID() string {
+	return vuln.OSVID
+}
+
+// PackageToCPE creates a CPE for the affected package
+type PackageToCPE func(pkg *Package) (*wfn.Attributes, error)
+
+// Convert is a part of the runner.Convertible interface
+func (vuln *Vulnerability) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	return vuln.ConvertWith(packageToCPE)
+}
+
+// ConvertWith converts the vulnerability using the given function to create CPEs for affected packages
+func (vuln *Vulnerability) ConvertWith(toCPE PackageToCPE) (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	if vuln.Withdrawn != "" {
+		return nil, fmt.Errorf("vulnerability %s has been withdrawn", vuln.OSVID)
+	}
+
+	description := vuln.Details
+	if description == "" {
+		description = vuln.Summary
+	}
+
+	impact, err := vuln.newImpact()
+	if err != nil {
+		return nil, fmt.Errorf("can't create impact for %s: %v", vuln.OSVID, err)
+	}
+
+	item := nvd.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &nvd.CVEJSON40{
+			CVEDataMeta: &nvd.CVEJSON40CVEDataMeta{
+				ID:       vuln.ID(),
+				ASSIGNER: "osv.dev",
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: cveDataVersion,
+			Description: &nvd.CVEJSON40Description{
+				DescriptionData: []*nvd.CVEJSON40LangString{
+					{
+						Lang:  "en",
+						Value: description,
+					},
+				},
+			},
+			Problemtype: vuln.newProblemType(),
+			References:  vuln.newReferences(),
+		},
+		Configurations:   vuln.newConfigurations(toCPE),
+		Impact:           impact,
+		LastModifiedDate: osvTimeToNVD(vuln.Modified),
+		PublishedDate:    osvTimeToNVD(vuln.Published),
+	}
+
+	return &item, nil
+}
+
+func (vuln *Vulnerability) newProblemType() *nvd.CVEJSON40Problemtype {
+	// cwe ids are stored in database specific part by some databases (e.g. github)
+	cwes, _ := vuln.DatabaseSpecific["cwe_ids"].([]interface{})
+	if len(cwes) == 0 {
+		return nil
+	}
+	data := &nvd.CVEJSON40ProblemtypeProblemtypeData{}
+	for _, cwe := range cwes {
+		if s, ok := cwe.(string); ok {
+			data.Description = append(data.Description, &nvd.CVEJSON40LangString{
+				Lang:  "en",
+				Value: s,
+			})
+		}
+	}
+	return &nvd.CVEJSON40Problemtype{
+		ProblemtypeData: []*nvd.CVEJSON40ProblemtypeProblemtypeData{data},
+	}
+}
+
+func (vuln *Vulnerability) newReferences() *nvd.CVEJSON40References {
+	if len(vuln.References) == 0 && len(vuln.Aliases) == 0 {
+		return nil
+	}
+	refs := &nvd.CVEJSON40References{
+		ReferenceData: make([]*nvd.CVEJSON40Reference, 0, len(vuln.Aliases)+len(vuln.References)),
+	}
+	addRef := func(name, url string) {
+		refs.ReferenceData = append(refs.ReferenceData, &nvd.CVEJSON40Reference{
+			Name: name,
+			URL:  url,
+		})
+	}
+	for _, alias := range vuln.Aliases {
+		addRef(alias, "")
+	}
+	for _, ref := range vuln.References {
+		addRef(ref.URL, ref.URL)
+	}
+	return refs
+}
+
+func (vuln *Vulnerability) newImpact() (*nvd.NVDCVEFeedJSON10DefImpact, error) {
+	severities := vuln.Severity
+	if len(severities) == 0 {
+		// use the severity of affected packages if there's no global one
+		for _, affected := range vuln.Affected {
+			severities = append(severities, affected.Severity...)
+		}
+	}
+
+	var impact nvd.NVDCVEFeedJSON10DefImpact
+	for _, severity := range severities {
+		switch severity.Type {
+		case "CVSS_V2":
+			if impact.BaseMetricV2 != nil {
+				continue
+			}
+			v, err := cvss2.VectorFromString(severity.Score)
+			if err != nil {
+				return nil, fmt.Errorf("can't parse cvss v2 vector %q: %v", severity.Score, err)
+			}
+			impact.BaseMetricV2 = &nvd.NVDCVEFeedJSON10DefImpactBaseMetricV2{
+				CVSSV2: &nvd.CVSSV20{
+					BaseScore:    v.BaseScore(),
+					VectorString: v.String(),
+					Version:      "2.0",
+				},
+			}
+		case "CVSS_V3":
+			if impact.BaseMetricV3 != nil {
+				continue
+			}
+			v, err := cvss3.VectorFromString(severity.Score)
+			if err != nil {
+				return nil, fmt.Errorf("can't parse cvss v3 vector %q: %v", severity.Score, err)
+			}
+			impact.BaseMetricV3 = &nvd.NVDCVEFeedJSON10DefImpactBaseMetricV3{
+				CVSSV3: &nvd.CVSSV30{
+					BaseScore:    v.BaseScore(),
+					VectorString: severity.Score,
+					Version:      strings.TrimPrefix(strings.SplitN(v.String(), "/", 2)[0], "CVSS:"),
+				},
+			}
+		}
+	}
+
+	if impact.BaseMetricV2 == nil && impact.BaseMetricV3 == nil {
+		return nil, nil
+	}
+	return &impact, nil
+}
+
+func (vuln *Vulnerability) newConfigurations(toCPE PackageToCPE) *nvd.NVDCVEFeedJSON10DefConfigurations {
+	node := &nvd.NVDCVEFeedJSON10DefNode{Operator: "OR"}
+	for _, affected := range vuln.Affected {
+		matches, err := affected.cpeMatches(toCPE)
+		if err != nil {
+			logging.Recordf("can't create configuration for %s, package %q: %v", vuln.OSVID, affected.Package.Name, err)
+			continue
+		}
+		node.CPEMatch = append(node.CPEMatch, matches...)
+	}
+	return &nvd.NVDCVEFeedJSON10DefConfigurations{
+		CVEDataVersion: cveDataVersion,
+		Nodes:          []*nvd.NVDCVEFeedJSON10DefNode{node},
+	}
+}
+
+// cpeMatches creates a cpe match for each affected version range
+// ranges are created only from SEMVER and ECOSYSTEM ranges, git commits can't be matched against CPEs
+// if there are no such ranges, explicitly listed versions are used
+func (affected *Affected) cpeMatches(toCPE PackageToCPE) ([]*nvd.NVDCVEFeedJSON10DefCPEMatch, error) {
+	cpe, err := toCPE(&affected.Package)
+	if err != nil {
+		return nil, err
+	}
+
+	newMatch := func(attrs *wfn.Attributes) *nvd.NVDCVEFeedJSON10DefCPEMatch {
+		cpe23URI := attrs.BindToFmtString()
+		return &nvd.NVDCVEFeedJSON10DefCPEMatch{
+			CPEName: []*nvd.NVDCVEFeedJSON10DefCPEName{
+				{
+					Cpe22Uri: attrs.BindToURI(),
+					Cpe23Uri: cpe23URI,
+				},
+			},
+			Cpe23Uri:   cpe23URI,
+			Vulnerable: true,
+		}
+	}
+
+	var matches []*nvd.NVDCVEFeedJSON10DefCPEMatch
+	for _, r := range affected.Ranges {
+		if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+			continue
+		}
+		// once sorted, every introduced event is followed by either fixed or last affected event
+		var start *nvd.NVDCVEFeedJSON10DefCPEMatch
+		for _, event := range r.sortedEvents() {
+			switch {
+			case event.Introduced != "":
+				start = newMatch(cpe)
+				if event.Introduced != "0" {
+					start.VersionStartIncluding = event.Introduced
+				}
+			case start == nil:
+				// fixed or last affected without introduced, ignore it
+			case event.Fixed != "":
+				start.VersionEndExcluding = event.Fixed
+				matches, start = append(matches, start), nil
+			case event.LastAffected != "":
+				start.VersionEndIncluding = event.LastAffected
+				matches, start = append(matches, start), nil
+			case event.Limit != "":
+				start.VersionEndExcluding = event.Limit
+				matches, start = append(matches, start), nil
+			}
+		}
+		if start != nil {
+			// introduced but never fixed
+			matches = append(matches, start)
+		}
+	}
+
+	if len(matches) != 0 {
+		return matches, nil
+	}
+
+	for _, version := range affected.Versions {
+		attrs := *cpe
+		if attrs.Version, err = wfn.WFNize(version); err != nil {
+			return nil, fmt.Errorf("can't wfnize version %q: %v", version, err)
+		}
+		matches = append(matches, newMatch(&attrs))
+	}
+
+	return matches, nil
+}
+
+// sortedEvents returns events of the range in the order they should be evaluated
+// events of SEMVER ranges are sorted by version, other ranges are expected to be sorted already
+func (r *Range) sortedEvents() []*Event {
+	if r.Type != "SEMVER" {
+		return r.Events
+	}
+	events := append([]*Event(nil), r.Events...)
+	sort.SliceStable(events, func(i, j int) bool {
+		// versions which can't be compared keep their order
+		c, err := semver.CompareVersions(events[i].version(), events[j].version())
+		return err == nil && c < 0
+	})
+	return events
+}
+
+// version returns the version of whichever event is set
+func (e *Event) version() string {
+	switch {
+	case e.Introduced != "":
+		return e.Introduced
+	case e.Fixed != "":
+		return e.Fixed
+	case e.LastAffected != "":
+		return e.LastAffected
+	default:
+		return e.Limit
+	}
+}
+
+// packageToCPE creates a CPE for the given package
+// ecosystem is stored as target software and maven group id as the vendor
+func packageToCPE(pkg *Package) (*wfn.Attributes, error) {
+	attrs := wfn.Attributes{Part: "a"}
+	name := pkg.Name
+
+	// ecosystem can have a suffix, like Debian:10 or Alpine:v3.12
+	ecosystem := strings.ToLower(strings.SplitN(pkg.Ecosystem, ":", 2)[0])
+	if ecosystem == "maven" {
+		if parts := strings.SplitN(name, ":", 2); len(parts) == 2 {
+			vendor, err := wfn.WFNize(parts[0])
+			if err != nil {
+				return nil, fmt.Errorf("can't wfnize group id %q: %v", parts[0], err)
+			}
+			attrs.Vendor, name = vendor, parts[1]
+		}
+	}
+
+	var err error
+	if attrs.Product, err = wfn.WFNize(name); err != nil {
+		return nil, fmt.Errorf("can't wfnize package name %q: %v", name, err)
+	}
+	if attrs.TargetSW, err = wfn.WFNize(ecosystem); err != nil {
+		return nil, fmt.Errorf("can't wfnize ecosystem %q: %v", ecosystem, err)
+	}
+
+	return &attrs, nil
+}
+
+func osvTimeToNVD(s string) string {
+	if s == "" {
+		return ""
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		logging.Recordf("cannot parse osv time: %v", err)
+		return s
+	}
+	return t.UTC().Format(nvd.TimeLayout)
+}
@@ -0,0 +1,128 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const testVuln = `{
+  "id": "GHSA-jfh8-c2jp-5v3q",
+  "modified": "2021-12-20T17:50:11.581542Z",
+  "published": "2021-12-10T00:40:56Z",
+  "aliases": ["CVE-2021-44228"],
+  "summary": "Remote code injection in Log4j",
+  "details": "Log4j2 JNDI features do not protect against attacker controlled LDAP endpoints.",
+  "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:C/C:H/I:H/A:H"}],
+  "affected": [{
+    "package": {"ecosystem": "Maven", "name": "org.apache.logging.log4j:log4j-core"},
+    "ranges": [
+      {"type": "ECOSYSTEM", "events": [{"introduced": "2.13.0"}, {"fixed": "2.15.0"}]},
+      {"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"last_affected": "2.3.0"}]},
+      {"type": "GIT", "repo": "https://github.com/apache/logging-log4j2", "events": [{"introduced": "0"}, {"fixed": "c77b3cb"}]}
+    ]
+  }, {
+    "package": {"ecosystem": "PyPI", "name": "some-package"},
+    "versions": ["1.0", "1.1"]
+  }],
+  "references": [{"type": "ADVISORY", "url": "https://nvd.nist.gov/vuln/detail/CVE-2021-44228"}],
+  "database_specific": {"cwe_ids": ["CWE-502", "CWE-917"]}
+}`
+
+func TestConvert(t *testing.T) {
+	var vuln Vulnerability
+	if err := json.Unmarshal([]byte(testVuln), &vuln); err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := vuln.Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if item.CVE.CVEDataMeta.ID != "GHSA-jfh8-c2jp-5v3q" {
+		t.Fatalf("wrong id %q", item.CVE.CVEDataMeta.ID)
+	}
+	if item.PublishedDate != "2021-12-10T00:40Z" || item.LastModifiedDate != "2021-12-20T17:50Z" {
+		t.Fatalf("wrong dates %q, %q", item.PublishedDate, item.LastModifiedDate)
+	}
+	if score := item.Impact.BaseMetricV3.CVSSV3.BaseScore; score != 10 {
+		t.Fatalf("wrong base score %.1f", score)
+	}
+	if n := len(item.CVE.Problemtype.ProblemtypeData[0].Description); n != 2 {
+		t.Fatalf("expecting 2 cwes, got %d", n)
+	}
+	// two ecosystem ranges and two versions
+	if n := len(item.Configurations.Nodes[0].CPEMatch); n != 4 {
+		t.Fatalf("expecting 4 cpe matches, got %d", n)
+	}
+
+	v := nvd.ToVuln(item)
+	for i, tc := range []struct {
+		cpe   string
+		match bool
+	}{
+		{"cpe:/a:org.apache.logging.log4j:log4j-core:2.14.1", true},
+		{"cpe:/a:org.apache.logging.log4j:log4j-core:2.13.0", true},
+		{"cpe:/a:org.apache.logging.log4j:log4j-core:2.15.0", false},
+		{"cpe:/a:org.apache.logging.log4j:log4j-core:2.2", true},
+		{"cpe:/a:org.apache.logging.log4j:log4j-core:2.5", false},
+		{"cpe:/a::some-package:1.1", true},
+		{"cpe:/a::some-package:1.2", false},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			attrs, err := wfn.Parse(tc.cpe)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if matched := len(v.Match([]*wfn.Attributes{attrs}, true)) != 0; matched != tc.match {
+				t.Fatalf("expecting match of %s to be %v, got %v", tc.cpe, tc.match, matched)
+			}
+		})
+	}
+}
+
+func TestSortedEvents(t *testing.T) {
+	r := Range{
+		Type: "SEMVER",
+		Events: []*Event{
+			{Fixed: "1.10.0"},
+			{Introduced: "1.2.0"},
+			{Fixed: "1.10.0-rc.1"},
+			{Introduced: "0"},
+			{Introduced: "1.9.0"},
+		},
+	}
+	var got []string
+	for _, e := range r.sortedEvents() {
+		got = append(got, e.version())
+	}
+	if s := strings.Join(got, " "); s != "0 1.2.0 1.9.0 1.10.0-rc.1 1.10.0" {
+		t.Fatalf("wrong order of events: %s", s)
+	}
+}
+
+func TestConvertWithdrawn(t *testing.T) {
+	vuln := Vulnerability{OSVID: "OSV-1", Withdrawn: "2021-01-01T00:00:00Z"}
+	if _, err := vuln.Convert(); err == nil {
+		t.Fatal("withdrawn vulnerabilities shouldn't be converted")
+	}
+}
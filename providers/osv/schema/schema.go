@@ -0,0 +1,75 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+// based on the OSV schema
+// https://ossf.github.io/osv-schema/
+
+type Vulnerability struct {
+	SchemaVersion    string                 `json:"schema_version,omitempty"`
+	OSVID            string                 `json:"id"`
+	Modified         string                 `json:"modified"`
+	Published        string                 `json:"published,omitempty"`
+	Withdrawn        string                 `json:"withdrawn,omitempty"`
+	Aliases          []string               `json:"aliases,omitempty"`
+	Related          []string               `json:"related,omitempty"`
+	Summary          string                 `json:"summary,omitempty"`
+	Details          string                 `json:"details,omitempty"`
+	Severity         []*Severity            `json:"severity,omitempty"`
+	Affected         []*Affected            `json:"affected,omitempty"`
+	References       []*Reference           `json:"references,omitempty"`
+	DatabaseSpecific map[string]interface{} `json:"database_specific,omitempty"`
+}
+
+type Severity struct {
+	// CVSS_V2, CVSS_V3 or CVSS_V4
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+type Affected struct {
+	Package           Package                `json:"package"`
+	Severity          []*Severity            `json:"severity,omitempty"`
+	Ranges            []*Range               `json:"ranges,omitempty"`
+	Versions          []string               `json:"versions,omitempty"`
+	EcosystemSpecific map[string]interface{} `json:"ecosystem_specific,omitempty"`
+	DatabaseSpecific  map[string]interface{} `json:"database_specific,omitempty"`
+}
+
+type Package struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	PURL      string `json:"purl,omitempty"`
+}
+
+type Range struct {
+	// SEMVER, ECOSYSTEM or GIT
+	Type   string   `json:"type"`
+	Repo   string   `json:"repo,omitempty"`
+	Events []*Event `json:"events"`
+}
+
+// Event has only one of the fields set
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+type Reference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
@@ -0,0 +1,65 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+	osvapi "github.com/facebookincubator/nvdtools/providers/osv/api"
+	osv "github.com/facebookincubator/nvdtools/providers/osv/schema"
+	"github.com/facebookincubator/nvdtools/providers/pkgdb/schema"
+)
+
+// DefaultEcosystems are the package databases which are fetched by default
+// PyPI export contains the PyPA advisory database, npm export contains npm advisories migrated to GitHub
+var DefaultEcosystems = []string{schema.EcosystemPyPI, schema.EcosystemNPM}
+
+// Client downloads package databases from OSV exports
+type Client struct {
+	*osvapi.Client
+}
+
+// NewClient creates an object which is used to download package databases
+// base url should point to the bucket with OSV dumps, e.g. https://osv-vulnerabilities.storage.googleapis.com
+func NewClient(c client.Client, baseURL string) *Client {
+	return &Client{osvapi.NewClient(c, baseURL)}
+}
+
+// FetchAllVulnerabilities downloads given ecosystems and returns all vulnerabilities modified since the given time
+func (c *Client) FetchAllVulnerabilities(ctx context.Context, since int64, ecosystems []string) (<-chan runner.Convertible, error) {
+	vulns, err := c.Client.FetchAllVulnerabilities(ctx, since, ecosystems)
+	if err != nil {
+		return nil, err
+	}
+	return Wrap(vulns), nil
+}
+
+// Wrap wraps OSV vulnerabilities so they're converted with project as the vendor
+func Wrap(vulns <-chan runner.Convertible) <-chan runner.Convertible {
+	output := make(chan runner.Convertible)
+	go func() {
+		defer close(output)
+		for vuln := range vulns {
+			if v, ok := vuln.(*osv.Vulnerability); ok {
+				output <- &schema.Vulnerability{Vulnerability: v}
+			} else {
+				output <- vuln
+			}
+		}
+	}()
+	return output
+}
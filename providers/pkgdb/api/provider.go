@@ -0,0 +1,84 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+	osvapi "github.com/facebookincubator/nvdtools/providers/osv/api"
+	"github.com/facebookincubator/nvdtools/providers/pkgdb/schema"
+)
+
+// Ecosystems is a comma separated list of OSV ecosystems the registered provider downloads
+var Ecosystems = strings.Join(DefaultEcosystems, ",")
+
+func init() {
+	runner.Register(runner.Registration{
+		Name:    "pkgdb",
+		BaseURL: "https://osv-vulnerabilities.storage.googleapis.com",
+		New: func(c client.Client, baseURL string, _ map[string]string) (runner.Provider, error) {
+			client := NewClient(c, baseURL)
+			return runner.ConvertibleProvider(func(ctx context.Context, since time.Time) (<-chan runner.Convertible, error) {
+				return client.FetchAllVulnerabilities(ctx, since.Unix(), strings.Split(Ecosystems, ","))
+			}), nil
+		},
+		Read: Read,
+	})
+}
+
+// Read reads vulnerabilities either from a file created by downloading, or directly from an OSV all.zip dump
+func Read(r io.Reader, c chan runner.Convertible) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("can't read input: %v", err)
+	}
+
+	if bytes.HasPrefix(data, []byte("PK")) {
+		out := make(chan runner.Convertible)
+		go func() {
+			defer close(out)
+			if err := osvapi.ReadZip(bytes.NewReader(data), int64(len(data)), 0, out); err != nil {
+				logging.Errorf("can't read zip: %v", err)
+			}
+		}()
+		for vuln := range Wrap(out) {
+			c <- vuln
+		}
+		return nil
+	}
+
+	var vulns map[string]*schema.Vulnerability
+	if err := json.Unmarshal(data, &vulns); err != nil {
+		return fmt.Errorf("can't decode into vulns: %v", err)
+	}
+
+	for _, vuln := range vulns {
+		if osvapi.Accept(vuln.Vulnerability, 0) {
+			c <- vuln
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	osv "github.com/facebookincubator/nvdtools/providers/osv/schema"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// OSV ecosystems of supported package databases
+const (
+	EcosystemPyPI = "PyPI"
+	EcosystemNPM  = "npm"
+)
+
+// runs of -, _ and . are equivalent in python project names
+// https://peps.python.org/pep-0503/#normalized-names
+var pypiSeparatorRegex = regexp.MustCompile(`[-_.]+`)
+
+// Vulnerability is an OSV vulnerability from one of the package databases
+// it's converted the same way OSV vulnerabilities are, except that the vendor is set to the project
+type Vulnerability struct {
+	*osv.Vulnerability
+}
+
+// Convert is a part of the runner.Convertible interface
+func (vuln *Vulnerability) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	return vuln.ConvertWith(PackageToCPE)
+}
+
+// PackageToCPE creates a CPE for the package with vendor set to the project the package belongs to.
+// For PyPI both vendor and product are the normalized project name, e.g. cpe:/a:pyyaml:pyyaml:::~~~pypi~~
+// For npm the vendor is the scope if there is one, e.g. cpe:/a:babel:core:::~~~npm~~ for @babel/core,
+// otherwise it's the package name. Packages from other ecosystems don't have a project, so the vendor is left empty like in OSV
+func PackageToCPE(pkg *osv.Package) (*wfn.Attributes, error) {
+	vendor, product := "", pkg.Name
+	switch pkg.Ecosystem {
+	case EcosystemPyPI:
+		product = pypiSeparatorRegex.ReplaceAllString(strings.ToLower(product), "-")
+		vendor = product
+	case EcosystemNPM:
+		vendor = product
+		if strings.HasPrefix(product, "@") {
+			if parts := strings.SplitN(product[1:], "/", 2); len(parts) == 2 {
+				vendor, product = parts[0], parts[1]
+			}
+		}
+	}
+
+	attrs := wfn.Attributes{Part: "a"}
+	var err error
+	if vendor != "" {
+		if attrs.Vendor, err = wfn.WFNize(vendor); err != nil {
+			return nil, fmt.Errorf("can't wfnize vendor %q: %v", vendor, err)
+		}
+	}
+	if attrs.Product, err = wfn.WFNize(product); err != nil {
+		return nil, fmt.Errorf("can't wfnize package name %q: %v", product, err)
+	}
+	ecosystem := strings.ToLower(pkg.Ecosystem)
+	if attrs.TargetSW, err = wfn.WFNize(ecosystem); err != nil {
+		return nil, fmt.Errorf("can't wfnize ecosystem %q: %v", ecosystem, err)
+	}
+	return &attrs, nil
+}
@@ -0,0 +1,101 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	osv "github.com/facebookincubator/nvdtools/providers/osv/schema"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const testVuln = `{
+  "id": "PYSEC-2021-142",
+  "modified": "2021-08-27T03:22:22.149453Z",
+  "published": "2021-06-08T18:15:00Z",
+  "aliases": ["CVE-2020-14343"],
+  "details": "A vulnerability was discovered in the PyYAML library.",
+  "affected": [{
+    "package": {"ecosystem": "PyPI", "name": "PyYAML"},
+    "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "5.4"}]}]
+  }, {
+    "package": {"ecosystem": "npm", "name": "@babel/core"},
+    "ranges": [{"type": "SEMVER", "events": [{"introduced": "7.0.0"}, {"fixed": "7.23.2"}]}]
+  }]
+}`
+
+func TestConvert(t *testing.T) {
+	vuln := Vulnerability{new(osv.Vulnerability)}
+	if err := json.Unmarshal([]byte(testVuln), &vuln); err != nil {
+		t.Fatal(err)
+	}
+
+	item, err := vuln.Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.CVE.CVEDataMeta.ID != "PYSEC-2021-142" {
+		t.Fatalf("wrong id %q", item.CVE.CVEDataMeta.ID)
+	}
+
+	v := nvd.ToVuln(item)
+	for i, tc := range []struct {
+		cpe   string
+		match bool
+	}{
+		{"cpe:/a:pyyaml:pyyaml:5.3.1::~~~pypi~~", true},
+		{"cpe:/a:pyyaml:pyyaml:5.4::~~~pypi~~", false},
+		{"cpe:/a:babel:core:7.22.0::~~~npm~~", true},
+		{"cpe:/a:babel:core:7.23.2::~~~npm~~", false},
+		{"cpe:/a:other:core:7.22.0::~~~npm~~", false},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			attrs, err := wfn.Parse(tc.cpe)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if matched := len(v.Match([]*wfn.Attributes{attrs}, true)) != 0; matched != tc.match {
+				t.Fatalf("expecting match of %s to be %v, got %v", tc.cpe, tc.match, matched)
+			}
+		})
+	}
+}
+
+func TestPackageToCPE(t *testing.T) {
+	for i, tc := range []struct {
+		pkg    osv.Package
+		expect string
+	}{
+		{osv.Package{Ecosystem: "PyPI", Name: "Django"}, "cpe:2.3:a:django:django:*:*:*:*:*:pypi:*:*"},
+		{osv.Package{Ecosystem: "PyPI", Name: "zope.interface"}, "cpe:2.3:a:zope-interface:zope-interface:*:*:*:*:*:pypi:*:*"},
+		{osv.Package{Ecosystem: "PyPI", Name: "typing__extensions"}, "cpe:2.3:a:typing-extensions:typing-extensions:*:*:*:*:*:pypi:*:*"},
+		{osv.Package{Ecosystem: "npm", Name: "lodash"}, "cpe:2.3:a:lodash:lodash:*:*:*:*:*:npm:*:*"},
+		{osv.Package{Ecosystem: "npm", Name: "@angular/core"}, "cpe:2.3:a:angular:core:*:*:*:*:*:npm:*:*"},
+		{osv.Package{Ecosystem: "Go", Name: "golang.org/x/net"}, `cpe:2.3:a:*:golang.org\/x\/net:*:*:*:*:*:go:*:*`},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			attrs, err := PackageToCPE(&tc.pkg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := attrs.BindToFmtString(); got != tc.expect {
+				t.Fatalf("expecting %s, got %s", tc.expect, got)
+			}
+		})
+	}
+}
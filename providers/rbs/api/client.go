@@ -18,7 +18,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"net/http"
 	"net/url"
 	"sync"
 	"time"
@@ -26,6 +26,7 @@ import (
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/clientcredentials"
 
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/providers/lib/client"
 	"github.com/facebookincubator/nvdtools/providers/lib/runner"
 	"github.com/facebookincubator/nvdtools/providers/rbs/schema"
@@ -57,21 +58,21 @@ func NewClient(c client.Client, baseURL, tokenURL, clientID, clientSecret string
 	}
 }
 
-func (c *Client) FetchAllVulnerabilitiesAfterVulndbID(vulndbID int) (<-chan runner.Convertible, error) {
+func (c *Client) FetchAllVulnerabilitiesAfterVulndbID(ctx context.Context, vulndbID int) (<-chan runner.Convertible, error) {
 	u := fmt.Sprintf("%d/find_next_to_vulndb_id_full", vulndbID)
 
-	return c.fetchAllVulnerabilities(func() string { return u })
+	return c.fetchAllVulnerabilities(ctx, func() string { return u })
 }
 
-func (c *Client) FetchAllVulnerabilities(since int64) (<-chan runner.Convertible, error) {
+func (c *Client) FetchAllVulnerabilities(ctx context.Context, since int64) (<-chan runner.Convertible, error) {
 	from := time.Unix(since, 0)
-	return c.fetchAllVulnerabilities(func() string {
+	return c.fetchAllVulnerabilities(ctx, func() string {
 		// we need to recalculate hours ago on each request, if the fetching takes more than an hour
 		return fmt.Sprintf("find_by_time_full?hours_ago=%d", int(time.Since(from).Hours()))
 	})
 }
 
-func (c *Client) fetchAllVulnerabilities(getEndpoint func() string) (<-chan runner.Convertible, error) {
+func (c *Client) fetchAllVulnerabilities(ctx context.Context, getEndpoint func() string) (<-chan runner.Convertible, error) {
 
 	fetch := func(page, size int) (*schema.VulnerabilityResult, error) {
 		u, err := url.Parse(fmt.Sprintf("%s/api/v1/vulnerabilities/%s", c.baseURL, getEndpoint()))
@@ -82,7 +83,7 @@ func (c *Client) fetchAllVulnerabilities(getEndpoint func() string) (<-chan runn
 		values.Set("page", fmt.Sprintf("%d", page))
 		values.Set("size", fmt.Sprintf("%d", size))
 		u.RawQuery = values.Encode()
-		return c.getResult(u.String())
+		return c.getResult(ctx, u.String())
 	}
 
 	result, err := fetch(1, 1)
@@ -99,7 +100,7 @@ func (c *Client) fetchAllVulnerabilities(getEndpoint func() string) (<-chan runn
 	numPages := (totalVulns-1)/pageSize + 1
 
 	// fetch pages concurrently
-	log.Printf("starting sync for %d vulnerabilities over %d pages\n", totalVulns, numPages)
+	logging.Infof("starting sync for %d vulnerabilities over %d pages", totalVulns, numPages)
 	wg := sync.WaitGroup{}
 	for page := 1; page <= numPages; page++ {
 		page := page
@@ -108,12 +109,17 @@ func (c *Client) fetchAllVulnerabilities(getEndpoint func() string) (<-chan runn
 			defer wg.Done()
 			result, err := fetch(page, pageSize)
 			if err != nil {
-				log.Printf("failed to get page %d: %v", page, err)
+				logging.Errorf("failed to get page %d: %v", page, err)
 				return
 			}
 			for _, vuln := range result.Vulnerabilities {
-				if vuln != nil {
-					output <- vuln
+				if vuln == nil {
+					continue
+				}
+				select {
+				case output <- vuln:
+				case <-ctx.Done():
+					return
 				}
 			}
 		}()
@@ -127,8 +133,8 @@ func (c *Client) fetchAllVulnerabilities(getEndpoint func() string) (<-chan runn
 	return output, nil
 }
 
-func (c *Client) getResult(u string) (*schema.VulnerabilityResult, error) {
-	resp, err := c.Get(u)
+func (c *Client) getResult(ctx context.Context, u string) (*schema.VulnerabilityResult, error) {
+	resp, err := client.Get(ctx, c, u, http.Header{})
 	if err != nil {
 		return nil, fmt.Errorf("can't get response: %v", err)
 	}
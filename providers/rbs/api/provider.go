@@ -0,0 +1,45 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+	"github.com/facebookincubator/nvdtools/providers/rbs/schema"
+)
+
+// DefaultBaseURL is the default url of the VulnDB API
+const DefaultBaseURL = "https://vulndb.cyberriskanalytics.com"
+
+// TokenURL is the OAuth2 access token URL used by the registered provider
+var TokenURL = DefaultBaseURL + "/oauth/token"
+
+func init() {
+	runner.Register(runner.Registration{
+		Name:    "rbs",
+		BaseURL: DefaultBaseURL,
+		Env:     []string{"RBS_CLIENT_ID", "RBS_CLIENT_SECRET"},
+		New: func(c client.Client, baseURL string, env map[string]string) (runner.Provider, error) {
+			client := NewClient(c, baseURL, TokenURL, env["RBS_CLIENT_ID"], env["RBS_CLIENT_SECRET"])
+			return runner.ConvertibleProvider(func(ctx context.Context, since time.Time) (<-chan runner.Convertible, error) {
+				return client.FetchAllVulnerabilities(ctx, since.Unix())
+			}), nil
+		},
+		Read: runner.ReadMap(func() runner.Convertible { return new(schema.Vulnerability) }),
+	})
+}
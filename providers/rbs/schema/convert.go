@@ -16,10 +16,10 @@ package schema
 
 import (
 	"fmt"
-	"log"
 	"time"
 
 	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/wfn"
 )
 
@@ -106,7 +106,7 @@ func (item *Vulnerability) makeConfigurations() *nvd.NVDCVEFeedJSON10DefConfigur
 				for _, cpe := range version.CPEs {
 					c, err := normalizeCPE(cpe.CPE)
 					if err != nil {
-						log.Printf("couldn't normalize cpe %q: %v", cpe.CPE, err)
+						logging.Recordf("couldn't normalize cpe %q: %v", cpe.CPE, err)
 						continue
 					}
 					match := &nvd.NVDCVEFeedJSON10DefCPEMatch{
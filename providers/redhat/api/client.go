@@ -18,13 +18,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/providers/lib/client"
 	"github.com/facebookincubator/nvdtools/providers/lib/runner"
 	"github.com/facebookincubator/nvdtools/providers/redhat/schema"
@@ -32,12 +36,20 @@ import (
 
 const (
 	perPage = 50
+	// DefaultWorkers is the default number of documents fetched concurrently
+	DefaultWorkers = 10
 )
 
 // Client struct
 type Client struct {
 	client.Client
-	baseURL string
+	baseURL  string
+	workers  int
+	cacheDir string
+
+	// cached holds names of documents stored in the cache dir
+	cachedMu sync.Mutex
+	cached   map[string]bool
 }
 
 // NewClient creates an object which is used to query the RedHat API
@@ -45,66 +57,170 @@ func NewClient(c client.Client, baseURL string) *Client {
 	return &Client{
 		Client:  c,
 		baseURL: baseURL,
+		workers: DefaultWorkers,
 	}
 }
 
+// SetWorkers sets the number of documents which are fetched concurrently
+func (c *Client) SetWorkers(workers int) {
+	if workers > 0 {
+		c.workers = workers
+	}
+}
+
+// SetCacheDir sets the directory in which fetched documents are stored
+// documents found in the directory aren't fetched again, so an interrupted download can be resumed
+// they are removed from it once a download finishes without errors
+func (c *Client) SetCacheDir(dir string) {
+	c.cacheDir = dir
+}
+
 // FetchAll will fetch all vulnerabilities
 func (c *Client) FetchAllCVEs(ctx context.Context, since int64) (<-chan runner.Convertible, error) {
+	var failed int32
+	cveids := make(chan string)
+	go func() {
+		defer close(cveids)
+		for page := range c.fetchAllPages(ctx, since, &failed) {
+			for _, cveItem := range *page {
+				select {
+				case cveids <- cveItem.CVE:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return c.fetchAll(ctx, cveids, &failed, func(ctx context.Context, cveid string) (runner.Convertible, error) {
+		var cve schema.CVE
+		if err := c.fetchDocument(ctx, fmt.Sprintf("/cve/%s.json", cveid), &cve); err != nil {
+			return nil, err
+		}
+		return &cve, nil
+	}), nil
+}
+
+// fetchAll fetches all documents with the given ids using a pool of workers
+// failed counts errors which happened while listing the ids, if there are none and all documents were fetched,
+// the cache dir is cleared so that the next download doesn't reuse stale documents
+func (c *Client) fetchAll(ctx context.Context, ids <-chan string, failed *int32, fetch func(context.Context, string) (runner.Convertible, error)) <-chan runner.Convertible {
 	output := make(chan runner.Convertible)
 	wg := sync.WaitGroup{}
 
-	for page := range c.fetchAllPages(ctx, since) {
-		for _, cveItem := range *page {
-			wg.Add(1)
-			go func(cveid string) {
-				defer wg.Done()
-				log.Printf("\tfetching cve %s", cveid)
-				cve, err := c.fetchCVE(ctx, cveid)
+	for i := 0; i < c.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range ids {
+				logging.Infof("fetching %s", id)
+				vuln, err := fetch(ctx, id)
 				if err != nil {
-					log.Printf("error while fetching cve %s: %v", cveid, err)
+					logging.Errorf("error while fetching %s: %v", id, err)
+					atomic.AddInt32(failed, 1)
+					continue
+				}
+				select {
+				case output <- vuln:
+				case <-ctx.Done():
 					return
 				}
-				output <- cve
-			}(cveItem.CVE)
-		}
+			}
+		}()
 	}
 
 	go func() {
 		wg.Wait()
+		if ctx.Err() == nil && atomic.LoadInt32(failed) == 0 {
+			c.clearCache()
+		}
 		close(output)
 	}()
 
-	return output, nil
+	return output
 }
 
-func (c *Client) fetchCVE(ctx context.Context, cveid string) (*schema.CVE, error) {
-	resp, err := c.queryPath(ctx, fmt.Sprintf("/cve/%s.json", cveid))
+// fetchDocument decodes the json document at the given path into v
+// if cache dir is set, the document is read from it if possible, otherwise it's stored there after fetching
+func (c *Client) fetchDocument(ctx context.Context, path string, v interface{}) error {
+	var cached string
+	if c.cacheDir != "" {
+		cached = filepath.Join(c.cacheDir, filepath.Base(path))
+		if data, err := ioutil.ReadFile(cached); err == nil {
+			if err := json.Unmarshal(data, v); err == nil {
+				c.addCached(cached)
+				return nil
+			}
+			// cached file is broken, fetch it again
+		}
+	}
+
+	resp, err := c.queryPath(ctx, path)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch from feed: %v", err)
+		return fmt.Errorf("failed to fetch from feed: %v", err)
 	}
 	defer resp.Body.Close()
 
-	var cve schema.CVE
-	if err := json.NewDecoder(resp.Body).Decode(&cve); err != nil {
-		return nil, fmt.Errorf("failed to decode cve response into a cve: %v", err)
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %v", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("failed to decode response: %v", err)
 	}
 
-	return &cve, nil
+	if cached != "" {
+		if err := ioutil.WriteFile(cached, data, 0644); err != nil {
+			logging.Warningf("can't store %s in cache dir: %v", path, err)
+			return nil
+		}
+		c.addCached(cached)
+	}
+	return nil
 }
 
-func (c *Client) fetchAllPages(ctx context.Context, since int64) <-chan *schema.CVEList {
+func (c *Client) addCached(name string) {
+	c.cachedMu.Lock()
+	defer c.cachedMu.Unlock()
+	if c.cached == nil {
+		c.cached = make(map[string]bool)
+	}
+	c.cached[name] = true
+}
+
+// clearCache removes all documents which were fetched or read from the cache dir
+func (c *Client) clearCache() {
+	if c.cacheDir == "" {
+		return
+	}
+	c.cachedMu.Lock()
+	defer c.cachedMu.Unlock()
+	for name := range c.cached {
+		if err := os.Remove(name); err != nil && !os.IsNotExist(err) {
+			logging.Warningf("can't remove %s from cache dir: %v", name, err)
+		}
+	}
+	c.cached = nil
+}
+
+func (c *Client) fetchAllPages(ctx context.Context, since int64, failed *int32) <-chan *schema.CVEList {
 	output := make(chan *schema.CVEList)
 	go func() {
 		defer close(output)
 		for page := 1; ; page++ {
-			log.Printf("fetching page %d", page)
+			logging.Infof("fetching page %d", page)
 			if list, err := c.fetchListPage(ctx, since, page); err == nil {
-				output <- list
+				select {
+				case output <- list:
+				case <-ctx.Done():
+					return
+				}
 				if len(*list) < perPage {
 					break
 				}
 			} else {
-				log.Printf("can't fetch page %d: %v", page, err)
+				logging.Errorf("can't fetch page %d: %v", page, err)
+				atomic.AddInt32(failed, 1)
 				break
 			}
 		}
@@ -0,0 +1,154 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+	"github.com/facebookincubator/nvdtools/providers/redhat/schema"
+)
+
+const testChanges = `"2019/cve-2019-0001.json","2019-01-01T00:00:00+00:00"
+"2020/cve-2020-0001.json","2020-01-01T00:00:00+00:00"
+"2020/cve-2020-0002.json","2020-06-01T00:00:00+00:00"
+`
+
+func TestFetchAllVEX(t *testing.T) {
+	var documentRequests int32
+	var failing atomic.Value
+	failing.Store("/2020/cve-2020-0002.json")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == vexChangesPath {
+			fmt.Fprint(w, testChanges)
+			return
+		}
+		atomic.AddInt32(&documentRequests, 1)
+		if r.URL.Path == failing.Load().(string) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		var year, num int
+		if _, err := fmt.Sscanf(r.URL.Path, "/%d/cve-%d-%d.json", &year, &year, &num); err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprintf(w, `{"document": {"category": "csaf_vex", "csaf_version": "2.0", "tracking": {"id": "CVE-%d-%04d"}}}`, year, num)
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "redhat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	since := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC).Unix()
+	fetch := func() []string {
+		c := NewClient(client.Default(), srv.URL)
+		c.SetWorkers(2)
+		c.SetCacheDir(dir)
+		vulns, err := c.FetchAllVEX(context.Background(), since)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for vuln := range vulns {
+			ids = append(ids, vuln.ID())
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	// an interrupted download keeps fetched documents in the cache dir
+	if ids := fetch(); fmt.Sprint(ids) != fmt.Sprint([]string{"CVE-2020-0001"}) {
+		t.Fatalf("expecting only CVE-2020-0001, got %v", ids)
+	}
+	if documentRequests != 2 {
+		t.Fatalf("expecting 2 document requests, got %d", documentRequests)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		t.Fatalf("expecting 1 cached document, got %d", len(files))
+	}
+
+	// only the missing document is fetched, the cache dir is cleared after the download succeeds
+	failing.Store("")
+	expected := fmt.Sprint([]string{"CVE-2020-0001", "CVE-2020-0002"})
+	if ids := fetch(); fmt.Sprint(ids) != expected {
+		t.Fatalf("expecting %s, got %v", expected, ids)
+	}
+	if documentRequests != 3 {
+		t.Fatalf("expecting 3 document requests, got %d", documentRequests)
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+		t.Fatalf("expecting cache dir to be cleared, got %d documents", len(files))
+	}
+
+	// documents aren't reused by the next download
+	if ids := fetch(); fmt.Sprint(ids) != expected {
+		t.Fatalf("expecting %s, got %v", expected, ids)
+	}
+	if documentRequests != 5 {
+		t.Fatalf("expecting 5 document requests, got %d", documentRequests)
+	}
+}
+
+func TestFetchAllCancelled(t *testing.T) {
+	const total = 100
+	ids := make(chan string)
+	go func() {
+		defer close(ids)
+		for i := 0; i < total; i++ {
+			ids <- fmt.Sprintf("CVE-2020-%04d", i)
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var failed int32
+	c := NewClient(client.Default(), "")
+	vulns := c.fetchAll(ctx, ids, &failed, func(ctx context.Context, id string) (runner.Convertible, error) {
+		return &schema.CVE{Name: id}, nil
+	})
+	<-vulns
+	cancel()
+
+	// workers shouldn't keep sending once the context is cancelled
+	received := 1
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range vulns {
+			received++
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("fetching didn't stop after the context was cancelled")
+	}
+	if received == total {
+		t.Fatalf("expecting fetching to stop early, got all %d documents", total)
+	}
+}
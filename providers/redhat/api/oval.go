@@ -0,0 +1,81 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/redhat/schema"
+)
+
+// DefaultOVALBaseURL is where red hat publishes OVAL v2 streams
+const DefaultOVALBaseURL = "https://access.redhat.com/security/data/oval/v2"
+
+// FetchOVAL fetches the given OVAL v2 streams and converts advisories updated since the given time
+// streams are either urls or paths relative to DefaultOVALBaseURL, e.g. RHEL8/rhel-8.oval.xml.bz2
+// CVEs found in multiple streams are merged, see schema.CVE.Merge
+func (c *Client) FetchOVAL(ctx context.Context, streams []string, since int64) (map[string]*schema.CVE, error) {
+	cves := make(map[string]*schema.CVE)
+	for _, stream := range streams {
+		u := stream
+		if !strings.Contains(u, "://") {
+			u = DefaultOVALBaseURL + "/" + strings.TrimPrefix(u, "/")
+		}
+		logging.Infof("fetching oval stream %s", u)
+		oval, err := c.fetchOVALStream(ctx, u)
+		if err != nil {
+			return nil, fmt.Errorf("can't fetch oval stream %s: %v", stream, err)
+		}
+
+		defs := oval.Definitions[:0]
+		for _, def := range oval.Definitions {
+			updated, err := def.Updated()
+			if err != nil {
+				logging.Errorf("%s: %v", stream, err)
+				continue
+			}
+			if updated.Unix() >= since {
+				defs = append(defs, def)
+			}
+		}
+		oval.Definitions = defs
+
+		for id, cve := range oval.CVEs() {
+			if existing, ok := cves[id]; ok {
+				existing.Merge(cve)
+			} else {
+				cves[id] = cve
+			}
+		}
+	}
+	return cves, nil
+}
+
+func (c *Client) fetchOVALStream(ctx context.Context, u string) (*schema.OVAL, error) {
+	resp, err := client.Get(ctx, c, u, http.Header{})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %s", resp.Status)
+	}
+	return schema.DecodeOVAL(resp.Body, u)
+}
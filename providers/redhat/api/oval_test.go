@@ -0,0 +1,95 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+)
+
+const testOVALDefinition = `
+    <definition class="patch" id="oval:com.redhat.rhsa:def:%[1]d" version="1">
+      <metadata>
+        <reference ref_id="RHSA-%[2]s" ref_url="https://access.redhat.com/errata/RHSA-%[2]s" source="RHSA"/>
+        <advisory from="secalert@redhat.com">
+          <issued date="%[3]s"/>
+          <cve impact="important">%[4]s</cve>
+          <affected_cpe_list><cpe>cpe:/o:redhat:enterprise_linux:%[5]d</cpe></affected_cpe_list>
+        </advisory>
+      </metadata>
+      <criteria operator="AND">
+        <criterion comment="openssl is earlier than %[6]s" test_ref="oval:com.redhat.rhsa:tst:1"/>
+      </criteria>
+    </definition>`
+
+func TestFetchOVAL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/RHEL7/rhel-7.oval.xml.gz":
+			gz := gzip.NewWriter(w)
+			defer gz.Close()
+			fmt.Fprint(gz, "<oval_definitions><definitions>")
+			fmt.Fprintf(gz, testOVALDefinition, 1, "2019:0001", "2019-01-01", "CVE-2019-0001", 7, "1:1.0.2k-16.el7")
+			fmt.Fprintf(gz, testOVALDefinition, 2, "2020:0001", "2020-01-01", "CVE-2020-0001", 7, "1:1.0.2k-19.el7")
+			fmt.Fprint(gz, "</definitions></oval_definitions>")
+		case "/RHEL8/rhel-8.oval.xml":
+			fmt.Fprint(w, "<oval_definitions><definitions>")
+			fmt.Fprintf(w, testOVALDefinition, 3, "2020:0002", "2020-02-01", "CVE-2020-0001", 8, "1:1.1.1c-15.el8")
+			fmt.Fprint(w, "</definitions></oval_definitions>")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewClient(client.Default(), srv.URL)
+	since := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC).Unix()
+
+	cves, err := c.FetchOVAL(context.Background(), []string{srv.URL + "/RHEL7/rhel-7.oval.xml.gz", srv.URL + "/RHEL8/rhel-8.oval.xml"}, since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cves) != 1 {
+		t.Fatalf("expecting only CVE-2020-0001 to be updated since %d, got %d cves", since, len(cves))
+	}
+	cve, ok := cves["CVE-2020-0001"]
+	if !ok {
+		t.Fatal("CVE-2020-0001 is missing")
+	}
+	var pkgs []string
+	for _, ar := range cve.AffectedRelease {
+		pkgs = append(pkgs, ar.CPE+" "+ar.Package+" "+ar.Advisory)
+	}
+	sort.Strings(pkgs)
+	expect := []string{
+		"cpe:/o:redhat:enterprise_linux:7 openssl-1:1.0.2k-19.el7 RHSA-2020:0001",
+		"cpe:/o:redhat:enterprise_linux:8 openssl-1:1.1.1c-15.el8 RHSA-2020:0002",
+	}
+	if fmt.Sprint(pkgs) != fmt.Sprint(expect) {
+		t.Fatalf("expecting %v, got %v", expect, pkgs)
+	}
+
+	if _, err := c.FetchOVAL(context.Background(), []string{srv.URL + "/RHEL9/rhel-9.oval.xml"}, since); err == nil {
+		t.Fatal("expecting an error for a missing stream")
+	}
+}
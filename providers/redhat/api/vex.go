@@ -0,0 +1,103 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+	"github.com/facebookincubator/nvdtools/providers/redhat/schema"
+)
+
+const (
+	// DefaultVEXBaseURL is where red hat publishes CSAF VEX documents
+	DefaultVEXBaseURL = "https://security.access.redhat.com/data/csaf/v2/vex"
+	// changes.csv lists all VEX files with the time of the last change
+	vexChangesPath = "/changes.csv"
+)
+
+// FetchAllVEX will fetch all CSAF VEX documents changed since the given time
+// base url of the client should point to the VEX directory
+func (c *Client) FetchAllVEX(ctx context.Context, since int64) (<-chan runner.Convertible, error) {
+	paths, err := c.fetchVEXChanges(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("can't fetch list of changed documents: %v", err)
+	}
+
+	ids := make(chan string)
+	go func() {
+		defer close(ids)
+		for _, path := range paths {
+			select {
+			case ids <- path:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var failed int32
+	return c.fetchAll(ctx, ids, &failed, func(ctx context.Context, path string) (runner.Convertible, error) {
+		var data json.RawMessage
+		if err := c.fetchDocument(ctx, "/"+path, &data); err != nil {
+			return nil, err
+		}
+		return schema.ReadVEX(bytes.NewReader(data))
+	}), nil
+}
+
+// fetchVEXChanges returns paths of all documents which changed after since
+func (c *Client) fetchVEXChanges(ctx context.Context, since int64) ([]string, error) {
+	resp, err := c.queryPath(ctx, vexChangesPath)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return parseVEXChanges(resp.Body, since)
+}
+
+// parseVEXChanges parses lines in the following format
+// "2023/cve-2023-0286.json","2023-11-07T10:51:38+00:00"
+func parseVEXChanges(r io.Reader, since int64) ([]string, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 2
+
+	var paths []string
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("can't read changes: %v", err)
+		}
+		changed, err := time.Parse(time.RFC3339, record[1])
+		if err != nil {
+			return nil, fmt.Errorf("can't parse time of change for %q: %v", record[0], err)
+		}
+		if changed.Unix() < since {
+			continue
+		}
+		paths = append(paths, record[0])
+	}
+
+	return paths, nil
+}
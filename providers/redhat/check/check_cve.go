@@ -70,7 +70,7 @@ func affectedReleaseCheckers(cve *schema.CVE) ([]rpm.Checker, error) {
 			}
 		}
 
-		chks = append(chks, &singleChecker{d, pc})
+		chks = append(chks, &singleChecker{distro: d, pkgChecker: pc, advisory: ar.Advisory})
 	}
 
 	return chks, nil
@@ -101,7 +101,7 @@ func packageStateCheckers(cve *schema.CVE) ([]rpm.Checker, error) {
 			pc = packageStatePkgChecker(ps.PackageName)
 		}
 
-		chks = append(chks, &singleChecker{d, pc})
+		chks = append(chks, &singleChecker{distro: d, pkgChecker: pc})
 	}
 
 	return chks, nil
@@ -125,3 +125,11 @@ func (c *cveChecker) Check(pkg *rpm.Package, distro *wfn.Attributes, cve string)
 	}
 	return false
 }
+
+// CheckResult is part of the rpm.ResultChecker interface
+func (c *cveChecker) CheckResult(pkg *rpm.Package, distro *wfn.Attributes, cve string) rpm.Result {
+	if cve != c.cve {
+		return rpm.Result{}
+	}
+	return rpm.AsResultChecker(rpm.CheckAny(c.chks...)).CheckResult(pkg, distro, cve)
+}
@@ -65,6 +65,58 @@ func TestCVEChecker(t *testing.T) {
 	}
 }
 
+func TestCVECheckerResult(t *testing.T) {
+	var cve schema.CVE
+	if err := json.NewDecoder(strings.NewReader(cveStr)).Decode(&cve); err != nil {
+		t.Fatal(err)
+	}
+
+	chk, err := CVEChecker(&cve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rc, ok := chk.(rpm.ResultChecker)
+	if !ok {
+		t.Fatal("cve checker should be a result checker")
+	}
+
+	fixed := rpm.Result{Fixed: true, FixedIn: "68.1.0-1.el8_0", Advisory: "RHSA-2019:2663"}
+	for i, tc := range []struct {
+		pkg           string
+		distroVersion string
+		cve           string
+		expect        rpm.Result
+	}{
+		{"firefox-68.1.0-1.el8_0.x86_64", "8", "CVE-2019-11735", fixed},
+		{"firefox-68.2.0-4.el8_1.x86_64", "8", "CVE-2019-11735", fixed},
+		// not fixed yet, should know what to upgrade to
+		{"firefox-60.8.0-1.el8.x86_64", "8", "CVE-2019-11735", rpm.Result{FixedIn: "68.1.0-1.el8_0", Advisory: "RHSA-2019:2663"}},
+		// not affected, no fixed version
+		{"firefox-60.8.0-1.el7.x86_64", "7", "CVE-2019-11735", rpm.Result{Fixed: true}},
+		// out of support scope
+		{"firefox-60.8.0-1.el5.x86_64", "5", "CVE-2019-11735", rpm.Result{}},
+		// the fix doesn't apply to other packages
+		{"thunderbird-60.8.0-1.el8.x86_64", "8", "CVE-2019-11735", rpm.Result{}},
+		{"firefox-60.8.0-1.el8.x86_64", "8", "CVE-some-other", rpm.Result{}},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			pkg, err := rpm.Parse(tc.pkg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			distro := wfn.Attributes{
+				Part:    "o",
+				Vendor:  "redhat",
+				Product: "enterprise_linux",
+				Version: tc.distroVersion,
+			}
+			if got := rc.CheckResult(pkg, &distro, tc.cve); got != tc.expect {
+				t.Fatalf("expecting %+v, got %+v", tc.expect, got)
+			}
+		})
+	}
+}
+
 var cveStr = `
   {
     "name": "CVE-2019-11735",
@@ -40,6 +40,11 @@ func (c packageStatePkgChecker) checkPkg(pkg *rpm.Package) bool {
 type affectedReleasePkgChecker rpm.Package
 
 func (c affectedReleasePkgChecker) checkPkg(pkg *rpm.Package) bool {
+	return c.samePkg(pkg) && rpm.LabelCompare(pkg.Label, c.Label) >= 0
+}
+
+// samePkg returns whether the fix applies to the given package, regardless of its version
+func (c affectedReleasePkgChecker) samePkg(pkg *rpm.Package) bool {
 	// if both have names and they're not the same, false
 	if c.Name != "" && pkg.Name != "" && c.Name != pkg.Name {
 		return false
@@ -48,16 +53,14 @@ func (c affectedReleasePkgChecker) checkPkg(pkg *rpm.Package) bool {
 	if c.Arch != "" && pkg.Arch != "" && c.Arch != pkg.Arch {
 		return false
 	}
-	if rpm.LabelCompare(pkg.Label, c.Label) < 0 {
-		return false
-	}
-
 	return true
 }
 
 type singleChecker struct {
 	distro     *wfn.Attributes
 	pkgChecker pkgCheck
+	// advisory which fixed the package, only known for affected releases
+	advisory string
 }
 
 func (c *singleChecker) Check(pkg *rpm.Package, distro *wfn.Attributes, cve string) bool {
@@ -79,3 +82,18 @@ func (c *singleChecker) Check(pkg *rpm.Package, distro *wfn.Attributes, cve stri
 
 	return true
 }
+
+// CheckResult is part of the rpm.ResultChecker interface
+// fixed version is known only for affected releases, it's set if the same package on the same distro isn't fixed yet
+func (c *singleChecker) CheckResult(pkg *rpm.Package, distro *wfn.Attributes, cve string) rpm.Result {
+	res := rpm.Result{Fixed: c.Check(pkg, distro, cve)}
+	ar, ok := c.pkgChecker.(affectedReleasePkgChecker)
+	if !ok || pkg == nil {
+		return res
+	}
+	if res.Fixed || (ar.samePkg(pkg) && (distro == nil || c.distro == nil || wfn.Match(distro, c.distro))) {
+		res.FixedIn = ar.Label.String()
+		res.Advisory = c.advisory
+	}
+	return res
+}
@@ -102,7 +102,7 @@ func TestSingleChecker(t *testing.T) {
 		Product: "product",
 		Version: "4",
 	}
-	chk := &singleChecker{&cfgDistro, pc}
+	chk := &singleChecker{distro: &cfgDistro, pkgChecker: pc}
 
 	for i, tc := range []struct {
 		distro wfn.Attributes
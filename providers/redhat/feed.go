@@ -15,11 +15,14 @@
 package redhat
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
 
+	"github.com/facebookincubator/nvdtools/providers/csaf"
 	"github.com/facebookincubator/nvdtools/providers/redhat/check"
 	"github.com/facebookincubator/nvdtools/providers/redhat/schema"
 	"github.com/facebookincubator/nvdtools/rpm"
@@ -28,6 +31,8 @@ import (
 
 type Feed map[string]*schema.CVE
 
+// LoadFeed loads a feed downloaded by redhat2nvd
+// both the legacy CVE format and CSAF VEX documents are supported
 func LoadFeed(path string) (Feed, error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -38,13 +43,57 @@ func LoadFeed(path string) (Feed, error) {
 }
 
 func loadFeed(r io.Reader) (Feed, error) {
-	var feed Feed
-	if err := json.NewDecoder(r).Decode(&feed); err != nil {
+	var raw map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
 		return nil, fmt.Errorf("can't decode feed: %v", err)
 	}
+	feed := make(Feed, len(raw))
+	for id, data := range raw {
+		cve, err := decodeCVE(data)
+		if err != nil {
+			return nil, fmt.Errorf("can't decode %q: %v", id, err)
+		}
+		feed[id] = cve
+	}
+	return feed, nil
+}
+
+// LoadVEX creates a feed from CSAF VEX documents, one document per file
+func LoadVEX(paths ...string) (Feed, error) {
+	feed := make(Feed, len(paths))
+	for _, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("can't read file %q: %v", path, err)
+		}
+		cve, err := decodeVEX(data)
+		if err != nil {
+			return nil, fmt.Errorf("can't decode file %q: %v", path, err)
+		}
+		feed[cve.Name] = cve
+	}
 	return feed, nil
 }
 
+func decodeCVE(data []byte) (*schema.CVE, error) {
+	if csaf.IsDocument(data) {
+		return decodeVEX(data)
+	}
+	var cve schema.CVE
+	if err := json.Unmarshal(data, &cve); err != nil {
+		return nil, err
+	}
+	return &cve, nil
+}
+
+func decodeVEX(data []byte) (*schema.CVE, error) {
+	vex, err := schema.ReadVEX(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return vex.CVE()
+}
+
 func (feed Feed) Checker() (rpm.Checker, error) {
 	mc := make(mapChecker, len(feed))
 	var err error
@@ -71,3 +120,11 @@ func (c mapChecker) Check(pkg *rpm.Package, distro *wfn.Attributes, cve string)
 	}
 	return false
 }
+
+// CheckResult is part of the rpm.ResultChecker interface
+func (c mapChecker) CheckResult(pkg *rpm.Package, distro *wfn.Attributes, cve string) rpm.Result {
+	if chk, ok := c[cve]; ok {
+		return rpm.AsResultChecker(chk).CheckResult(pkg, distro, cve)
+	}
+	return rpm.Result{}
+}
@@ -0,0 +1,64 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redhat
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/facebookincubator/nvdtools/providers/redhat/schema"
+)
+
+// LoadOVAL creates a feed from red hat OVAL v2 streams, e.g. rhel-8.oval.xml.bz2
+// bzip2 and gzip compressed files are supported as well
+// CVEs found in multiple streams are merged
+func LoadOVAL(paths ...string) (Feed, error) {
+	feed := make(Feed)
+	for _, path := range paths {
+		f, err := loadOVALFile(path)
+		if err != nil {
+			return nil, err
+		}
+		feed.Merge(f)
+	}
+	return feed, nil
+}
+
+func loadOVALFile(path string) (Feed, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open file %q: %v", path, err)
+	}
+	defer f.Close()
+
+	oval, err := schema.DecodeOVAL(f, path)
+	if err != nil {
+		return nil, fmt.Errorf("can't load %q: %v", path, err)
+	}
+	return Feed(oval.CVEs()), nil
+}
+
+// Merge merges other feed into this one
+// affected releases from other replace the ones for the same package and product, see schema.CVE.Merge
+// it's meant to be used with a feed loaded from OVAL streams, which carry more precise fixed versions
+func (feed Feed) Merge(other Feed) {
+	for id, cve := range other {
+		if existing, ok := feed[id]; ok {
+			existing.Merge(cve)
+		} else {
+			feed[id] = cve
+		}
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redhat
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/rpm"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const testOVALTemplate = `<?xml version="1.0" encoding="utf-8"?>
+<oval_definitions xmlns="http://oval.mitre.org/XMLSchema/oval-definitions-5">
+  <definitions>
+    <definition class="patch" id="oval:com.redhat.rhsa:def:%[1]d" version="1">
+      <metadata>
+        <title>%[2]s: openssl security update</title>
+        <affected family="unix"><platform>Red Hat Enterprise Linux %[3]d</platform></affected>
+        <reference ref_id="%[2]s" ref_url="https://access.redhat.com/errata/%[2]s" source="RHSA"/>
+        <advisory from="secalert@redhat.com">
+          <issued date="2020-05-01"/>
+          <cve impact="important" public="20200421">%[4]s</cve>
+          <affected_cpe_list><cpe>cpe:/o:redhat:enterprise_linux:%[3]d</cpe></affected_cpe_list>
+        </advisory>
+      </metadata>
+      <criteria operator="AND">
+        <criterion comment="Red Hat Enterprise Linux %[3]d is installed" test_ref="oval:com.redhat.rhba:tst:1"/>
+        <criterion comment="openssl is earlier than %[5]s" test_ref="oval:com.redhat.rhsa:tst:2"/>
+      </criteria>
+    </definition>
+  </definitions>
+</oval_definitions>`
+
+func TestLoadOVAL(t *testing.T) {
+	dir, err := ioutil.TempDir("", "redhat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// rhel 7 stream has a later fix for CVE-2020-0002 than the feed, rhel 8 stream fixes a CVE not in the feed
+	rhel7 := filepath.Join(dir, "rhel-7.oval.xml.gz")
+	f, err := os.Create(rhel7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(f)
+	fmt.Fprintf(gz, testOVALTemplate, 20201001, "RHSA-2020:1001", 7, "CVE-2020-0002", "1:1.0.2k-21.el7")
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	rhel8 := filepath.Join(dir, "rhel-8.oval.xml")
+	data := fmt.Sprintf(testOVALTemplate, 20201002, "RHSA-2020:1002", 8, "CVE-2020-0004", "1:1.1.1c-15.el8")
+	if err := ioutil.WriteFile(rhel8, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	oval, err := LoadOVAL(rhel7, rhel8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(oval) != 2 {
+		t.Fatalf("expecting 2 cves, got %d", len(oval))
+	}
+
+	feed, err := loadFeed(strings.NewReader(testFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	feed.Merge(oval)
+
+	pf, err := NewPackageFeed(feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chk, err := feed.Checker()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rhel7Distro := &wfn.Attributes{Part: "o", Vendor: "redhat", Product: "enterprise_linux", Version: "7"}
+	rhel8Distro := &wfn.Attributes{Part: "o", Vendor: "redhat", Product: "enterprise_linux", Version: "8"}
+
+	for i, tc := range []struct {
+		distro *wfn.Attributes
+		pkg    string
+		expect []string
+	}{
+		// fixed in the feed, but not according to OVAL
+		{rhel7Distro, "openssl-1:1.0.2k-19.el7.x86_64", []string{}},
+		{rhel7Distro, "openssl-1:1.0.2k-21.el7.x86_64", []string{"CVE-2020-0002"}},
+		{rhel8Distro, "openssl-1:1.1.1c-2.el8.x86_64", []string{"CVE-2020-0002"}},
+		{rhel8Distro, "openssl-1:1.1.1c-15.el8.x86_64", []string{"CVE-2020-0002", "CVE-2020-0004"}},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			pkg, err := rpm.Parse(tc.pkg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := pf.ListFixedCVEs(tc.distro, pkg, nil); !reflect.DeepEqual(got, tc.expect) {
+				t.Fatalf("expecting %v, got %v", tc.expect, got)
+			}
+			for _, cve := range []string{"CVE-2020-0002", "CVE-2020-0004"} {
+				fixed := false
+				for _, id := range tc.expect {
+					fixed = fixed || id == cve
+				}
+				if got := chk.Check(pkg, tc.distro, cve); got != fixed {
+					t.Fatalf("%s: checker expecting %t, got %t", cve, fixed, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redhat
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/pkgfeed"
+	"github.com/facebookincubator/nvdtools/providers/redhat/schema"
+	"github.com/facebookincubator/nvdtools/rpm"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+var (
+	// modular affected releases are in name:stream-version.context format
+	// e.g. nodejs:12-8020020200326104117.4cda2c84
+	modularPackageRegex = regexp.MustCompile(`^([^:/]+):([^:/]+)-(\d{10,})\.([0-9a-f]+)$`)
+)
+
+// Module is a module stream a package belongs to
+// e.g. nodejs:12:8020020200326104117:4cda2c84
+type Module struct {
+	Name    string
+	Stream  string
+	Version string
+	Context string
+}
+
+// ParseModule parses a modularity label in name:stream[:version[:context]] format
+// modularity label of an installed package can be obtained with `rpm -q --qf '%{MODULARITYLABEL}'`
+func ParseModule(label string) (*Module, error) {
+	parts := strings.Split(label, ":")
+	if len(parts) < 2 || len(parts) > 4 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("can't parse module %q, expecting name:stream[:version[:context]]", label)
+	}
+	var m Module
+	m.Name, m.Stream = parts[0], parts[1]
+	if len(parts) > 2 {
+		m.Version = parts[2]
+	}
+	if len(parts) > 3 {
+		m.Context = parts[3]
+	}
+	return &m, nil
+}
+
+func (m *Module) String() string {
+	s := m.Name + ":" + m.Stream
+	if m.Version != "" {
+		s += ":" + m.Version
+		if m.Context != "" {
+			s += ":" + m.Context
+		}
+	}
+	return s
+}
+
+// packageKey identifies a package in the package feed
+// module and stream are empty for non modular packages, name is empty if all packages match
+type packageKey struct {
+	module string
+	stream string
+	name   string
+}
+
+// packageFix knows if a package has been fixed for some cve on some distro
+type packageFix struct {
+	cve    string
+	distro *wfn.Attributes
+	// package state says the package isn't fixed (e.g. affected or will not fix)
+	unfixed bool
+	// minimal package label which is fixed, nil if all versions are fixed
+	label *rpm.Label
+	// minimal module version which is fixed, empty if not known
+	moduleVersion string
+	// fix applies to all packages, so it doesn't say a package is affected
+	generic bool
+}
+
+// installedPackage is what the package feed looks fixes up for
+type installedPackage struct {
+	pkg    *rpm.Package
+	distro *wfn.Attributes
+	mod    *Module
+}
+
+// CVE is part of the pkgfeed.Fix interface
+func (f *packageFix) CVE() string {
+	return f.cve
+}
+
+// Affects is part of the pkgfeed.Fix interface
+// fixes which apply to all packages don't say anything about the installed one
+func (f *packageFix) Affects(installed interface{}) bool {
+	return !f.generic && f.applies(installed.(installedPackage).distro)
+}
+
+// Fixed is part of the pkgfeed.Fix interface
+func (f *packageFix) Fixed(installed interface{}) bool {
+	ip := installed.(installedPackage)
+	return f.fixed(ip.pkg, ip.distro, ip.mod)
+}
+
+func (f *packageFix) applies(distro *wfn.Attributes) bool {
+	return distro == nil || f.distro == nil || wfn.Match(distro, f.distro)
+}
+
+func (f *packageFix) fixed(pkg *rpm.Package, distro *wfn.Attributes, mod *Module) bool {
+	if f.unfixed || !f.applies(distro) {
+		return false
+	}
+	if f.moduleVersion != "" {
+		if mod == nil || mod.Version == "" {
+			// can't know for sure
+			return false
+		}
+		if rpm.VersionCompare(mod.Version, f.moduleVersion) < 0 {
+			return false
+		}
+	}
+	if f.label != nil && rpm.LabelCompare(pkg.Label, *f.label) < 0 {
+		return false
+	}
+	return true
+}
+
+// PackageFeed indexes the feed by packages, taking module streams into account
+// it can be used to list CVEs which are fixed or which still affect some installed package
+type PackageFeed struct {
+	feed *pkgfeed.Feed
+}
+
+// NewPackageFeed creates a new package feed from the given feed
+func NewPackageFeed(feed Feed) (*PackageFeed, error) {
+	pf, err := pkgfeed.New(func(add func(interface{}, pkgfeed.Fix)) error {
+		for cveid, cve := range feed {
+			if err := addAffectedReleases(add, cveid, cve.AffectedRelease); err != nil {
+				return fmt.Errorf("can't index affected releases for %q: %v", cveid, err)
+			}
+			if err := addPackageStates(add, cveid, cve.PackageState); err != nil {
+				return fmt.Errorf("can't index package states for %q: %v", cveid, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &PackageFeed{feed: pf}, nil
+}
+
+// LoadPackageFeed loads the feed from the given path and indexes it by packages
+func LoadPackageFeed(path string) (*PackageFeed, error) {
+	feed, err := LoadFeed(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewPackageFeed(feed)
+}
+
+func addAffectedReleases(add func(interface{}, pkgfeed.Fix), cveid string, ars schema.AffectedReleases) error {
+	for _, ar := range ars {
+		if ar.CPE == "" {
+			continue
+		}
+		distro, err := parseDistro(ar.CPE)
+		if err != nil {
+			return err
+		}
+
+		fix := packageFix{cve: cveid, distro: distro}
+		var key packageKey
+
+		if m := modularPackageRegex.FindStringSubmatch(ar.Package); m != nil {
+			// the whole module stream was fixed in this module version
+			key = packageKey{module: m[1], stream: m[2]}
+			fix.moduleVersion = m[3]
+		} else if ar.Package != "" {
+			// add .src to parse it correctly, they're all src rpms
+			p, err := rpm.Parse(ar.Package + ".src")
+			if err != nil {
+				logging.Recordf("can't parse package %q for %s: %v", ar.Package, cveid, err)
+				continue
+			}
+			key.name, fix.label = p.Name, &p.Label
+			if ar.Module != "" {
+				m, err := ParseModule(ar.Module)
+				if err != nil {
+					logging.Recordf("can't parse module %q for %s: %v", ar.Module, cveid, err)
+					continue
+				}
+				key.module, key.stream = m.Name, m.Stream
+			}
+		}
+
+		fix.generic = key == packageKey{}
+		add(key, &fix)
+	}
+	return nil
+}
+
+func addPackageStates(add func(interface{}, pkgfeed.Fix), cveid string, pss schema.PackageStates) error {
+	for _, ps := range pss {
+		if ps.CPE == "" {
+			continue
+		}
+		distro, err := parseDistro(ps.CPE)
+		if err != nil {
+			return err
+		}
+		fix := packageFix{
+			cve:     cveid,
+			distro:  distro,
+			unfixed: ps.FixState != "" && !schema.IsFixed(ps.FixState),
+		}
+		key := packageStateKey(ps.PackageName)
+		fix.generic = key == packageKey{}
+		add(key, &fix)
+	}
+	return nil
+}
+
+// ListFixedCVEs returns sorted list of CVEs which are fixed for the given package on the given distro
+// mod should be the module the package was installed from, or nil if the package isn't modular
+func (pf *PackageFeed) ListFixedCVEs(distro *wfn.Attributes, pkg *rpm.Package, mod *Module) []string {
+	return pf.feed.ListFixedCVEs(installedPackage{pkg: pkg, distro: distro, mod: mod}, packageKeys(pkg, mod)...)
+}
+
+// ListVulnerableCVEs returns sorted list of CVEs which affect the given package on the given distro
+// these are all CVEs mentioning the package which aren't fixed, including "affected" and "will not fix" states
+// mod should be the module the package was installed from, or nil if the package isn't modular
+func (pf *PackageFeed) ListVulnerableCVEs(distro *wfn.Attributes, pkg *rpm.Package, mod *Module) []string {
+	return pf.feed.ListVulnerableCVEs(installedPackage{pkg: pkg, distro: distro, mod: mod}, packageKeys(pkg, mod)...)
+}
+
+// packageKeys returns all keys under which fixes for the given package can be found
+// the first key is always the one which matches all packages
+func packageKeys(pkg *rpm.Package, mod *Module) []interface{} {
+	keys := []interface{}{packageKey{}}
+	if mod != nil {
+		return append(keys,
+			packageKey{module: mod.Name, stream: mod.Stream},
+			packageKey{module: mod.Name, stream: mod.Stream, name: pkg.Name},
+		)
+	}
+	return append(keys, packageKey{name: pkg.Name})
+}
+
+// packageStateKey parses package name from package state
+// modular packages are in module:stream/name format, e.g. nodejs:12/nodejs
+func packageStateKey(packageName string) packageKey {
+	if i := strings.IndexByte(packageName, '/'); i >= 0 {
+		if j := strings.IndexByte(packageName[:i], ':'); j >= 0 {
+			return packageKey{
+				module: packageName[:j],
+				stream: packageName[j+1 : i],
+				name:   strings.ToLower(packageName[i+1:]),
+			}
+		}
+	}
+	return packageKey{name: strings.ToLower(packageName)}
+}
+
+func parseDistro(cpe string) (*wfn.Attributes, error) {
+	d, err := wfn.Parse(cpe)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse distro cpe %q: %v", cpe, err)
+	}
+	// XXX we need to do this because RedHat sometimes sets `a` as part for RHEL-X, when it should be `o`
+	d.Part = wfn.Any
+	return d, nil
+}
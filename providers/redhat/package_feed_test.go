@@ -0,0 +1,196 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redhat
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/rpm"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestPackageFeedListFixedCVEs(t *testing.T) {
+	feed, err := loadFeed(strings.NewReader(testFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pf, err := NewPackageFeed(feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rhel8 := &wfn.Attributes{Part: "o", Vendor: "redhat", Product: "enterprise_linux", Version: "8"}
+	rhel7 := &wfn.Attributes{Part: "o", Vendor: "redhat", Product: "enterprise_linux", Version: "7"}
+
+	for i, tc := range []struct {
+		distro *wfn.Attributes
+		pkg    string
+		module string
+		expect []string
+	}{
+		// module stream 12 has been fixed in module version 8020020200326104117
+		{rhel8, "nodejs-12.16.1-1.module+el8.2.0+5841+a3b3b5d5.x86_64", "nodejs:12:8020020200326104117:4cda2c84", []string{"CVE-2020-0001"}},
+		{rhel8, "nodejs-12.14.0-1.module+el8.1.0+5466+30f75629.x86_64", "nodejs:12:8010020191211160122:cdc1202b", []string{}},
+		// stream 10 isn't affected
+		{rhel8, "nodejs-10.19.0-1.module+el8.1.0+5726+6ed65f8c.x86_64", "nodejs:10:8010020200121102504:cdc1202b", []string{"CVE-2020-0001"}},
+		// unknown module version, can't say it's fixed
+		{rhel8, "nodejs-12.16.1-1.module+el8.2.0+5841+a3b3b5d5.x86_64", "nodejs:12", []string{}},
+		// non modular package with the same name shouldn't match module fixes
+		{rhel8, "nodejs-12.16.1-1.el8.x86_64", "", []string{}},
+		{rhel7, "openssl-1:1.0.2k-19.el7.x86_64", "", []string{"CVE-2020-0002"}},
+		{rhel7, "openssl-1:1.0.2k-16.el7.x86_64", "", []string{}},
+		{rhel8, "openssl-1.1.1c-2.el8.x86_64", "", []string{"CVE-2020-0002"}},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			pkg, err := rpm.Parse(tc.pkg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var mod *Module
+			if tc.module != "" {
+				if mod, err = ParseModule(tc.module); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := pf.ListFixedCVEs(tc.distro, pkg, mod); !reflect.DeepEqual(got, tc.expect) {
+				t.Fatalf("expecting %v, got %v", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestPackageFeedListVulnerableCVEs(t *testing.T) {
+	feed, err := loadFeed(strings.NewReader(testFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pf, err := NewPackageFeed(feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rhel8 := &wfn.Attributes{Part: "o", Vendor: "redhat", Product: "enterprise_linux", Version: "8"}
+	rhel7 := &wfn.Attributes{Part: "o", Vendor: "redhat", Product: "enterprise_linux", Version: "7"}
+
+	for i, tc := range []struct {
+		distro *wfn.Attributes
+		pkg    string
+		module string
+		expect []string
+	}{
+		{rhel8, "nodejs-12.14.0-1.module+el8.1.0+5466+30f75629.x86_64", "nodejs:12:8010020191211160122:cdc1202b", []string{"CVE-2020-0001"}},
+		{rhel8, "nodejs-12.16.1-1.module+el8.2.0+5841+a3b3b5d5.x86_64", "nodejs:12:8020020200326104117:4cda2c84", []string{}},
+		{rhel8, "nodejs-10.19.0-1.module+el8.1.0+5726+6ed65f8c.x86_64", "nodejs:10:8010020200121102504:cdc1202b", []string{}},
+		// will not fix
+		{rhel8, "nodejs-12.16.1-1.el8.x86_64", "", []string{"CVE-2020-0001"}},
+		{rhel7, "nodejs-12.16.1-1.el7.x86_64", "", []string{}},
+		{rhel7, "openssl-1:1.0.2k-16.el7.x86_64", "", []string{"CVE-2020-0002"}},
+		{rhel7, "openssl-1:1.0.2k-19.el7.x86_64", "", []string{}},
+		{rhel8, "openssl-1.1.1c-2.el8.x86_64", "", []string{}},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			pkg, err := rpm.Parse(tc.pkg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var mod *Module
+			if tc.module != "" {
+				if mod, err = ParseModule(tc.module); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := pf.ListVulnerableCVEs(tc.distro, pkg, mod); !reflect.DeepEqual(got, tc.expect) {
+				t.Fatalf("expecting %v, got %v", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestParseModule(t *testing.T) {
+	for i, tc := range []struct {
+		label  string
+		expect *Module
+	}{
+		{"nodejs:12", &Module{Name: "nodejs", Stream: "12"}},
+		{"nodejs:12:8020020200326104117:4cda2c84", &Module{"nodejs", "12", "8020020200326104117", "4cda2c84"}},
+		{"nodejs", nil},
+		{"a:b:c:d:e", nil},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			m, err := ParseModule(tc.label)
+			if tc.expect == nil {
+				if err == nil {
+					t.Fatalf("expecting an error, got %v", m)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(m, tc.expect) {
+				t.Fatalf("expecting %v, got %v", tc.expect, m)
+			}
+			if m.String() != tc.label {
+				t.Fatalf("expecting %q, got %q", tc.label, m.String())
+			}
+		})
+	}
+}
+
+var testFeed = `{
+  "CVE-2020-0001": {
+    "name": "CVE-2020-0001",
+    "public_date": "2020-01-01T00:00:00",
+    "affected_release": {
+      "product_name": "Red Hat Enterprise Linux 8",
+      "advisory": "RHSA-2020:1293",
+      "package": "nodejs:12-8020020200326104117.4cda2c84",
+      "cpe": "cpe:/a:redhat:enterprise_linux:8"
+    },
+    "package_state": [
+      {
+        "product_name": "Red Hat Enterprise Linux 8",
+        "fix_state": "Not affected",
+        "package_name": "nodejs:10/nodejs",
+        "cpe": "cpe:/o:redhat:enterprise_linux:8"
+      },
+      {
+        "product_name": "Red Hat Enterprise Linux 8",
+        "fix_state": "Will not fix",
+        "package_name": "nodejs",
+        "cpe": "cpe:/o:redhat:enterprise_linux:8"
+      }
+    ]
+  },
+  "CVE-2020-0002": {
+    "name": "CVE-2020-0002",
+    "public_date": "2020-01-01T00:00:00",
+    "affected_release": {
+      "product_name": "Red Hat Enterprise Linux 7",
+      "advisory": "RHSA-2020:1000",
+      "package": "openssl-1:1.0.2k-19.el7",
+      "cpe": "cpe:/o:redhat:enterprise_linux:7"
+    },
+    "package_state": {
+      "product_name": "Red Hat Enterprise Linux 8",
+      "fix_state": "Not affected",
+      "package_name": "openssl",
+      "cpe": "cpe:/o:redhat:enterprise_linux:8"
+    }
+  }
+}`
@@ -16,12 +16,12 @@ package schema
 
 import (
 	"fmt"
-	"log"
 	"regexp"
 	"strings"
 	"time"
 
 	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/rpm"
 	"github.com/facebookincubator/nvdtools/wfn"
 )
@@ -41,7 +41,7 @@ func convertTime(redhatTime string) (string, error) {
 		t, err = time.Parse(timeLayout, redhatTime)
 	}
 	if err != nil { // should be parsable
-		log.Printf("unable to parse time: %v", err)
+		logging.Recordf("unable to parse time: %v", err)
 		return redhatTime, err
 	}
 	return t.Format(nvd.TimeLayout), nil
@@ -67,7 +67,7 @@ func IsFixed(fixState string) bool {
 	case "not affected":
 		return true
 	default:
-		log.Printf("unknown fix state: %q", fixState)
+		logging.Recordf("unknown fix state: %q", fixState)
 		return true
 	}
 }
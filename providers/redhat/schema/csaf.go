@@ -0,0 +1,37 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"io"
+
+	"github.com/facebookincubator/nvdtools/providers/csaf"
+	csafschema "github.com/facebookincubator/nvdtools/providers/csaf/schema"
+)
+
+// VEX is a CSAF document with csaf_vex category, red hat publishes one per CVE
+// https://www.redhat.com/en/blog/vulnerability-exploitability-exchange-vex-beta-files-now-available
+type VEX struct {
+	csafschema.Document
+}
+
+// ReadVEX reads a CSAF VEX document from r
+func ReadVEX(r io.Reader) (*VEX, error) {
+	doc, err := csaf.ReadDocument(r)
+	if err != nil {
+		return nil, err
+	}
+	return &VEX{Document: *doc}, nil
+}
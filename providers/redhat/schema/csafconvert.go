@@ -0,0 +1,321 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	csafschema "github.com/facebookincubator/nvdtools/providers/csaf/schema"
+	"github.com/facebookincubator/nvdtools/rpm"
+)
+
+const (
+	bugzillaSystemName = "Red Hat Bugzilla ID"
+	bugzillaURL        = "https://bugzilla.redhat.com/show_bug.cgi?id="
+)
+
+// ID is a part of the runner.Convertible interface
+func (vex *VEX) ID() string {
+	if len(vex.Vulnerabilities) != 0 && vex.Vulnerabilities[0].CVE != "" {
+		return vex.Vulnerabilities[0].CVE
+	}
+	return vex.Document.Document.Tracking.ID
+}
+
+// Convert is a part of the runner.Convertible interface
+func (vex *VEX) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	cve, err := vex.CVE()
+	if err != nil {
+		return nil, err
+	}
+	return cve.Convert()
+}
+
+// CVE converts the VEX document into the legacy CVE format
+// this way feeds, checkers and the nvd conversion work the same for both formats
+func (vex *VEX) CVE() (*CVE, error) {
+	if len(vex.Vulnerabilities) == 0 {
+		return nil, fmt.Errorf("vex document %q doesn't contain any vulnerabilities", vex.Document.Document.Tracking.ID)
+	}
+	v := vex.Vulnerabilities[0]
+
+	cve := CVE{
+		Name:       vex.ID(),
+		PublicDate: v.ReleaseDate,
+	}
+	if cve.PublicDate == "" {
+		cve.PublicDate = vex.Document.Document.Tracking.InitialReleaseDate
+	}
+
+	for _, threat := range v.Threats {
+		if threat.Category == "impact" {
+			cve.ThreatSeverity = threat.Details
+			break
+		}
+	}
+	if cve.ThreatSeverity == "" && vex.Document.Document.AggregateSeverity != nil {
+		cve.ThreatSeverity = vex.Document.Document.AggregateSeverity.Text
+	}
+
+	for _, id := range v.IDs {
+		if id.SystemName == bugzillaSystemName {
+			cve.Bugzilla = &Bugzilla{
+				Description: v.Title,
+				ID:          id.Text,
+				URL:         bugzillaURL + id.Text,
+			}
+			break
+		}
+	}
+
+	for _, score := range v.Scores {
+		if score.CVSSV2 != nil && cve.CVSS == nil {
+			cve.CVSS = &CVSS{
+				BaseScore: strconv.FormatFloat(score.CVSSV2.BaseScore, 'f', 1, 64),
+				Vector:    score.CVSSV2.VectorString,
+			}
+		}
+		if score.CVSSV3 != nil && cve.CVSS3 == nil {
+			cve.CVSS3 = &CVSS3{
+				BaseScore: strconv.FormatFloat(score.CVSSV3.BaseScore, 'f', 1, 64),
+				Vector:    score.CVSSV3.VectorString,
+			}
+		}
+	}
+
+	if v.CWE != nil {
+		cve.CWE = v.CWE.ID
+	}
+
+	for _, note := range v.Notes {
+		switch {
+		case note.Category == "description":
+			cve.Details = append(cve.Details, note.Text)
+		case note.Category == "other" && note.Title == "Statement":
+			cve.Statement = note.Text
+		}
+	}
+
+	for _, ref := range v.References {
+		cve.References = append(cve.References, ref.URL)
+	}
+
+	for _, rem := range v.Remediations {
+		if rem.Category == "workaround" {
+			cve.Mitigation = rem.Details
+			break
+		}
+	}
+
+	tree := vexTree{csafschema.NewTree(vex.ProductTree)}
+	status := v.ProductStatus
+	if status == nil {
+		status = &csafschema.ProductStatus{}
+	}
+	cve.AffectedRelease = tree.affectedReleases(v, status)
+	cve.PackageState = tree.packageStates(v, status)
+
+	return &cve, nil
+}
+
+// vexTree resolves products of red hat's product tree
+type vexTree struct {
+	*csafschema.Tree
+}
+
+// resolve returns the platform and the component which are referenced by the given product id
+// not all components are listed in branches, in which case the component only has the product id
+func (tree vexTree) resolve(productID string) (platform, component *csafschema.Product, ok bool) {
+	p := tree.Product(productID)
+	if p == nil || p.Relationship == "" || tree.Product(p.Platform.ID) == nil {
+		return nil, nil, false
+	}
+	return p.Platform, p.Component, true
+}
+
+func (tree vexTree) affectedReleases(v *csafschema.Vulnerability, status *csafschema.ProductStatus) AffectedReleases {
+	var ars AffectedReleases
+	seen := make(map[AffectedRelease]bool)
+	for _, id := range status.Fixed {
+		platform, component, ok := tree.resolve(id)
+		if !ok {
+			continue
+		}
+		pkg, ok := componentNEVR(component)
+		if !ok {
+			// not an rpm, can't be checked
+			continue
+		}
+		ar := AffectedRelease{
+			ProductName: platform.Name,
+			Package:     pkg,
+			CPE:         platform.CPE,
+		}
+		if purl := componentPURL(component); purl != nil {
+			ar.Module = purl.Qualifiers.Get("rpmmod")
+		}
+		if rem := findRemediation(v, id, "vendor_fix"); rem != nil {
+			ar.ReleaseDate = rem.Date
+			ar.Advisory = advisoryFromURL(rem.URL)
+		}
+		if seen[ar] {
+			continue
+		}
+		seen[ar] = true
+		ars = append(ars, &ar)
+	}
+	return ars
+}
+
+func (tree vexTree) packageStates(v *csafschema.Vulnerability, status *csafschema.ProductStatus) PackageStates {
+	var pss PackageStates
+	seen := make(map[PackageState]bool)
+	add := func(ids []string, fixState func(id string) string) {
+		for _, id := range ids {
+			platform, component, ok := tree.resolve(id)
+			if !ok {
+				continue
+			}
+			ps := PackageState{
+				ProductName: platform.Name,
+				FixState:    fixState(id),
+				PackageName: componentName(component),
+				CPE:         platform.CPE,
+			}
+			if seen[ps] {
+				continue
+			}
+			seen[ps] = true
+			pss = append(pss, &ps)
+		}
+	}
+
+	add(status.KnownNotAffected, func(string) string { return "Not affected" })
+	add(status.UnderInvestigation, func(string) string { return "Under investigation" })
+	add(status.KnownAffected, func(id string) string {
+		for _, category := range []string{"no_fix_planned", "none_available"} {
+			if rem := findRemediation(v, id, category); rem != nil && isKnownFixState(rem.Details) {
+				return rem.Details
+			}
+		}
+		return "Affected"
+	})
+
+	return pss
+}
+
+func findRemediation(v *csafschema.Vulnerability, productID, category string) *csafschema.Remediation {
+	for _, rem := range v.Remediations {
+		if rem.Category != category {
+			continue
+		}
+		for _, id := range rem.ProductIDs {
+			if id == productID {
+				return rem
+			}
+		}
+	}
+	return nil
+}
+
+func isKnownFixState(fixState string) bool {
+	switch strings.TrimSpace(strings.ToLower(fixState)) {
+	case "affected", "fix deferred", "new", "not affected", "out of support scope", "will not fix", "under investigation":
+		return true
+	default:
+		return false
+	}
+}
+
+// advisoryFromURL returns advisory id from the errata url
+// https://access.redhat.com/errata/RHSA-2023:0946 -> RHSA-2023:0946
+func advisoryFromURL(u string) string {
+	if u == "" {
+		return ""
+	}
+	return path.Base(u)
+}
+
+// componentNEVR returns the package in name-[epoch:]version-release form, as in the legacy format
+func componentNEVR(component *csafschema.Product) (string, bool) {
+	if purl := componentPURL(component); purl != nil {
+		if purl.Type != "rpm" || purl.Version == "" {
+			return "", false
+		}
+		if epoch := purl.Qualifiers.Get("epoch"); epoch != "" && epoch != "0" {
+			return fmt.Sprintf("%s-%s:%s", purl.Name, epoch, purl.Version), true
+		}
+		return fmt.Sprintf("%s-%s", purl.Name, purl.Version), true
+	}
+	// no purl, try to parse product id as name-[epoch:]version-release.arch
+	pkg, err := rpm.Parse(component.ID)
+	if err != nil || pkg.Version == "" || pkg.Release == "" {
+		return "", false
+	}
+	if pkg.Epoch != "" {
+		return fmt.Sprintf("%s-%s:%s-%s", pkg.Name, pkg.Epoch, pkg.Version, pkg.Release), true
+	}
+	return fmt.Sprintf("%s-%s-%s", pkg.Name, pkg.Version, pkg.Release), true
+}
+
+// componentName returns the package name, modular packages are returned as module:stream/name, as in the legacy format
+func componentName(component *csafschema.Product) string {
+	if purl := componentPURL(component); purl != nil {
+		if mod := strings.SplitN(purl.Qualifiers.Get("rpmmod"), ":", 3); len(mod) >= 2 {
+			return fmt.Sprintf("%s:%s/%s", mod[0], mod[1], purl.Name)
+		}
+		return purl.Name
+	}
+	return component.Name
+}
+
+// vexPURL is a minimal package url, enough for red hat components
+// pkg:rpm/redhat/openssl@1.1.1k-9.el8_7?arch=src&epoch=1
+type vexPURL struct {
+	Type       string
+	Name       string
+	Version    string
+	Qualifiers url.Values
+}
+
+func componentPURL(component *csafschema.Product) *vexPURL {
+	s := component.PURL
+	if !strings.HasPrefix(s, "pkg:") {
+		return nil
+	}
+	s = strings.TrimPrefix(s, "pkg:")
+
+	var purl vexPURL
+	if i := strings.IndexByte(s, '?'); i >= 0 {
+		purl.Qualifiers, _ = url.ParseQuery(s[i+1:])
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '@'); i >= 0 {
+		purl.Version, _ = url.PathUnescape(s[i+1:])
+		s = s[:i]
+	}
+	parts := strings.Split(s, "/")
+	purl.Type = parts[0]
+	purl.Name, _ = url.PathUnescape(parts[len(parts)-1])
+	if purl.Qualifiers == nil {
+		purl.Qualifiers = url.Values{}
+	}
+	return &purl
+}
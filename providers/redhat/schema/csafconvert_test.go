@@ -0,0 +1,155 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testVEX = `{
+  "document": {
+    "category": "csaf_vex",
+    "csaf_version": "2.0",
+    "aggregate_severity": {"text": "important"},
+    "tracking": {"id": "CVE-2023-0286", "initial_release_date": "2023-02-07T00:00:00+00:00"}
+  },
+  "product_tree": {
+    "branches": [{
+      "category": "vendor", "name": "Red Hat",
+      "branches": [
+        {"category": "product_family", "name": "Red Hat Enterprise Linux", "branches": [
+          {"category": "product_name", "name": "Red Hat Enterprise Linux AppStream (v. 8)", "product": {
+            "name": "Red Hat Enterprise Linux AppStream (v. 8)", "product_id": "AppStream-8.7.0.Z.MAIN",
+            "product_identification_helper": {"cpe": "cpe:/a:redhat:enterprise_linux:8::appstream"}}},
+          {"category": "product_name", "name": "Red Hat Enterprise Linux 7", "product": {
+            "name": "Red Hat Enterprise Linux 7", "product_id": "red_hat_enterprise_linux_7",
+            "product_identification_helper": {"cpe": "cpe:/o:redhat:enterprise_linux:7"}}},
+          {"category": "product_name", "name": "Red Hat Enterprise Linux 6", "product": {
+            "name": "Red Hat Enterprise Linux 6", "product_id": "red_hat_enterprise_linux_6",
+            "product_identification_helper": {"cpe": "cpe:/o:redhat:enterprise_linux:6"}}}
+        ]},
+        {"category": "product_version", "name": "openssl-1:1.1.1k-9.el8_7.src", "product": {
+          "name": "openssl-1:1.1.1k-9.el8_7.src", "product_id": "openssl-1:1.1.1k-9.el8_7.src",
+          "product_identification_helper": {"purl": "pkg:rpm/redhat/openssl@1.1.1k-9.el8_7?arch=src&epoch=1"}}},
+        {"category": "product_version", "name": "openssl", "product": {
+          "name": "openssl", "product_id": "openssl",
+          "product_identification_helper": {"purl": "pkg:rpm/redhat/openssl?arch=src"}}}
+      ]
+    }],
+    "relationships": [
+      {"category": "default_component_of", "product_reference": "openssl-1:1.1.1k-9.el8_7.src", "relates_to_product_reference": "AppStream-8.7.0.Z.MAIN",
+       "full_product_name": {"name": "openssl as a component of RHEL 8", "product_id": "AppStream-8.7.0.Z.MAIN:openssl-1:1.1.1k-9.el8_7.src"}},
+      {"category": "default_component_of", "product_reference": "openssl-libs-1:1.1.1k-9.el8_7.x86_64", "relates_to_product_reference": "AppStream-8.7.0.Z.MAIN",
+       "full_product_name": {"name": "openssl-libs as a component of RHEL 8", "product_id": "AppStream-8.7.0.Z.MAIN:openssl-libs-1:1.1.1k-9.el8_7.x86_64"}},
+      {"category": "default_component_of", "product_reference": "openssl", "relates_to_product_reference": "red_hat_enterprise_linux_7",
+       "full_product_name": {"name": "openssl as a component of RHEL 7", "product_id": "red_hat_enterprise_linux_7:openssl"}},
+      {"category": "default_component_of", "product_reference": "openssl", "relates_to_product_reference": "red_hat_enterprise_linux_6",
+       "full_product_name": {"name": "openssl as a component of RHEL 6", "product_id": "red_hat_enterprise_linux_6:openssl"}}
+    ]
+  },
+  "vulnerabilities": [{
+    "cve": "CVE-2023-0286",
+    "cwe": {"id": "CWE-843", "name": "Access of Resource Using Incompatible Type"},
+    "title": "openssl: X.400 address type confusion in X.509 GeneralName",
+    "release_date": "2023-02-07T00:00:00+00:00",
+    "ids": [{"system_name": "Red Hat Bugzilla ID", "text": "2164440"}],
+    "notes": [
+      {"category": "description", "text": "A type confusion vulnerability was found in OpenSSL."},
+      {"category": "other", "title": "Statement", "text": "Applications are unlikely to be affected."}
+    ],
+    "product_status": {
+      "fixed": ["AppStream-8.7.0.Z.MAIN:openssl-1:1.1.1k-9.el8_7.src", "AppStream-8.7.0.Z.MAIN:openssl-libs-1:1.1.1k-9.el8_7.x86_64"],
+      "known_affected": ["red_hat_enterprise_linux_6:openssl"],
+      "known_not_affected": ["red_hat_enterprise_linux_7:openssl"]
+    },
+    "references": [{"category": "self", "url": "https://access.redhat.com/security/cve/CVE-2023-0286"}],
+    "remediations": [
+      {"category": "vendor_fix", "details": "For details on how to apply this update", "date": "2023-02-28T00:00:00+00:00",
+       "url": "https://access.redhat.com/errata/RHSA-2023:0946",
+       "product_ids": ["AppStream-8.7.0.Z.MAIN:openssl-1:1.1.1k-9.el8_7.src", "AppStream-8.7.0.Z.MAIN:openssl-libs-1:1.1.1k-9.el8_7.x86_64"]},
+      {"category": "no_fix_planned", "details": "Out of support scope", "product_ids": ["red_hat_enterprise_linux_6:openssl"]}
+    ],
+    "scores": [{"cvss_v3": {"baseScore": 7.4, "vectorString": "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:N/A:H"}}],
+    "threats": [{"category": "impact", "details": "Important"}]
+  }]
+}`
+
+func TestVEXToCVE(t *testing.T) {
+	vex, err := ReadVEX(strings.NewReader(testVEX))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cve, err := vex.CVE()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cve.Name != "CVE-2023-0286" || cve.ThreatSeverity != "Important" || cve.CWE != "CWE-843" {
+		t.Fatalf("wrong metadata: %+v", cve)
+	}
+	if cve.Bugzilla == nil || cve.Bugzilla.ID != "2164440" {
+		t.Fatalf("wrong bugzilla: %+v", cve.Bugzilla)
+	}
+	if cve.CVSS3 == nil || cve.CVSS3.BaseScore != "7.4" {
+		t.Fatalf("wrong cvss3: %+v", cve.CVSS3)
+	}
+	if cve.Statement != "Applications are unlikely to be affected." {
+		t.Fatalf("wrong statement: %q", cve.Statement)
+	}
+
+	expectedARs := AffectedReleases{
+		{
+			ProductName: "Red Hat Enterprise Linux AppStream (v. 8)",
+			ReleaseDate: "2023-02-28T00:00:00+00:00",
+			Advisory:    "RHSA-2023:0946",
+			Package:     "openssl-1:1.1.1k-9.el8_7",
+			CPE:         "cpe:/a:redhat:enterprise_linux:8::appstream",
+		},
+		{
+			ProductName: "Red Hat Enterprise Linux AppStream (v. 8)",
+			ReleaseDate: "2023-02-28T00:00:00+00:00",
+			Advisory:    "RHSA-2023:0946",
+			Package:     "openssl-libs-1:1.1.1k-9.el8_7",
+			CPE:         "cpe:/a:redhat:enterprise_linux:8::appstream",
+		},
+	}
+	if !reflect.DeepEqual(cve.AffectedRelease, expectedARs) {
+		t.Fatalf("wrong affected releases: %+v", cve.AffectedRelease)
+	}
+
+	expectedPSs := PackageStates{
+		{
+			ProductName: "Red Hat Enterprise Linux 7",
+			FixState:    "Not affected",
+			PackageName: "openssl",
+			CPE:         "cpe:/o:redhat:enterprise_linux:7",
+		},
+		{
+			ProductName: "Red Hat Enterprise Linux 6",
+			FixState:    "Out of support scope",
+			PackageName: "openssl",
+			CPE:         "cpe:/o:redhat:enterprise_linux:6",
+		},
+	}
+	if !reflect.DeepEqual(cve.PackageState, expectedPSs) {
+		t.Fatalf("wrong package states: %+v", cve.PackageState)
+	}
+
+	if _, err := vex.Convert(); err != nil {
+		t.Fatalf("can't convert vex to nvd: %v", err)
+	}
+}
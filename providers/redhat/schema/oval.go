@@ -0,0 +1,116 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// based on the red hat OVAL v2 streams, only the parts which are needed to get fixed packages
+// https://access.redhat.com/security/data/oval/v2/
+
+// OVAL is the root of an OVAL v2 stream, red hat publishes one per product stream, e.g. RHEL8/rhel-8.oval.xml.bz2
+type OVAL struct {
+	XMLName     xml.Name          `xml:"oval_definitions"`
+	Definitions []*OVALDefinition `xml:"definitions>definition"`
+}
+
+// OVALDefinition describes a single RHSA advisory when its class is patch
+type OVALDefinition struct {
+	DefID    string       `xml:"id,attr"`
+	Class    string       `xml:"class,attr"`
+	Metadata OVALMetadata `xml:"metadata"`
+	Criteria OVALCriteria `xml:"criteria"`
+}
+
+type OVALMetadata struct {
+	Title       string           `xml:"title"`
+	Platforms   []string         `xml:"affected>platform"`
+	References  []*OVALReference `xml:"reference"`
+	Description string           `xml:"description"`
+	Advisory    OVALAdvisory     `xml:"advisory"`
+}
+
+type OVALReference struct {
+	Source string `xml:"source,attr"`
+	RefID  string `xml:"ref_id,attr"`
+	RefURL string `xml:"ref_url,attr"`
+}
+
+type OVALAdvisory struct {
+	Severity string `xml:"severity"`
+	Issued   struct {
+		Date string `xml:"date,attr"`
+	} `xml:"issued"`
+	Updated struct {
+		Date string `xml:"date,attr"`
+	} `xml:"updated"`
+	CVEs []*OVALCVE `xml:"cve"`
+	// CPEs of all products the advisory applies to, e.g. cpe:/a:redhat:enterprise_linux:8::appstream
+	CPEs []string `xml:"affected_cpe_list>cpe"`
+}
+
+// OVALCVE is a CVE fixed by the advisory
+// cvss3 is in score/vector format, e.g. 7.5/CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H
+// public is in YYYYMMDD format
+type OVALCVE struct {
+	CVEID  string `xml:",chardata"`
+	CVSS2  string `xml:"cvss2,attr"`
+	CVSS3  string `xml:"cvss3,attr"`
+	CWE    string `xml:"cwe,attr"`
+	Href   string `xml:"href,attr"`
+	Impact string `xml:"impact,attr"`
+	Public string `xml:"public,attr"`
+}
+
+// OVALCriteria is a tree of criterions, fixed packages and enabled modules are found in criterion comments
+type OVALCriteria struct {
+	Operator   string           `xml:"operator,attr"`
+	Negate     bool             `xml:"negate,attr"`
+	Criterions []*OVALCriterion `xml:"criterion"`
+	Criterias  []*OVALCriteria  `xml:"criteria"`
+}
+
+type OVALCriterion struct {
+	TestRef string `xml:"test_ref,attr"`
+	Comment string `xml:"comment,attr"`
+	Negate  bool   `xml:"negate,attr"`
+}
+
+// DecodeOVAL decodes an OVAL v2 stream, name is the path or url of the stream
+// the stream is decompressed first if the name ends with .bz2 or .gz
+func DecodeOVAL(r io.Reader, name string) (*OVAL, error) {
+	switch {
+	case strings.HasSuffix(name, ".bz2"):
+		r = bzip2.NewReader(r)
+	case strings.HasSuffix(name, ".gz"):
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("can't create gzip reader: %v", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	var oval OVAL
+	if err := xml.NewDecoder(r).Decode(&oval); err != nil {
+		return nil, fmt.Errorf("can't decode oval definitions: %v", err)
+	}
+	return &oval, nil
+}
@@ -0,0 +1,230 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/rpm"
+)
+
+const (
+	ovalDateLayout   = "2006-01-02"
+	ovalPublicLayout = "20060102"
+)
+
+var (
+	// e.g. "openssl is earlier than 1:1.1.1k-9.el8_7"
+	ovalEarlierThanRegex = regexp.MustCompile(`^(\S+) is earlier than (\S+)$`)
+	// e.g. "Module nodejs:12 is enabled"
+	ovalModuleRegex = regexp.MustCompile(`^Module (\S+:\S+) is enabled$`)
+)
+
+// CVEs converts all patch definitions in the stream into the legacy CVE format, keyed by the CVE id
+// when a CVE is fixed by multiple advisories, affected releases of all of them are kept
+func (oval *OVAL) CVEs() map[string]*CVE {
+	cves := make(map[string]*CVE)
+	for _, def := range oval.Definitions {
+		if def.Class != "patch" {
+			continue
+		}
+		ars := def.AffectedReleases()
+		for _, oc := range def.Metadata.Advisory.CVEs {
+			cve, ok := cves[oc.CVEID]
+			if !ok {
+				cve = oc.cve()
+				cves[oc.CVEID] = cve
+			}
+			cve.AffectedRelease = append(cve.AffectedRelease, ars...)
+		}
+	}
+	return cves
+}
+
+// Advisory returns the id of the advisory, e.g. RHSA-2019:1145
+func (def *OVALDefinition) Advisory() string {
+	for _, ref := range def.Metadata.References {
+		if ref.Source == "RHSA" {
+			return ref.RefID
+		}
+	}
+	return ""
+}
+
+// Updated returns the time the advisory was last updated, or issued if it has never been updated
+func (def *OVALDefinition) Updated() (time.Time, error) {
+	date := def.Metadata.Advisory.Updated.Date
+	if date == "" {
+		date = def.Metadata.Advisory.Issued.Date
+	}
+	t, err := time.Parse(ovalDateLayout, date)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("can't parse update date of %s: %v", def.DefID, err)
+	}
+	return t, nil
+}
+
+// AffectedReleases returns a release for each package fixed by the advisory on each product it applies to
+// packages are in the same name-epoch:version-release format the security data API uses
+func (def *OVALDefinition) AffectedReleases() AffectedReleases {
+	var productName string
+	if len(def.Metadata.Platforms) > 0 {
+		productName = def.Metadata.Platforms[0]
+	}
+	var releaseDate string
+	if t, err := time.Parse(ovalDateLayout, def.Metadata.Advisory.Issued.Date); err == nil {
+		releaseDate = t.Format(timeLayout)
+	}
+	advisory := def.Advisory()
+
+	var ars AffectedReleases
+	for _, fix := range def.fixedPackages() {
+		for _, cpe := range def.Metadata.Advisory.CPEs {
+			ars = append(ars, &AffectedRelease{
+				ProductName: productName,
+				ReleaseDate: releaseDate,
+				Advisory:    advisory,
+				Package:     fix.pkg,
+				CPE:         cpe,
+				Module:      fix.module,
+			})
+		}
+	}
+	return ars
+}
+
+// ovalFix is a package fixed by an advisory, module is set if the fix only applies to a module stream
+type ovalFix struct {
+	pkg    string
+	module string
+}
+
+// fixedPackages walks the criteria tree and collects all fixed packages
+// a module criterion applies to all packages in the same AND criteria
+func (def *OVALDefinition) fixedPackages() []ovalFix {
+	var fixes []ovalFix
+	seen := make(map[ovalFix]bool)
+	var walk func(*OVALCriteria, string)
+	walk = func(c *OVALCriteria, module string) {
+		if c.Negate {
+			return
+		}
+		if c.Operator == "" || c.Operator == "AND" {
+			for _, crit := range c.Criterions {
+				if m := ovalModuleRegex.FindStringSubmatch(crit.Comment); m != nil && !crit.Negate {
+					module = m[1]
+				}
+			}
+		}
+		for _, crit := range c.Criterions {
+			m := ovalEarlierThanRegex.FindStringSubmatch(crit.Comment)
+			if m == nil || crit.Negate {
+				continue
+			}
+			fix := ovalFix{pkg: m[1] + "-" + m[2], module: module}
+			if !seen[fix] {
+				seen[fix] = true
+				fixes = append(fixes, fix)
+			}
+		}
+		for _, sub := range c.Criterias {
+			walk(sub, module)
+		}
+	}
+	walk(&def.Criteria, "")
+	return fixes
+}
+
+// cve creates a CVE without any affected releases
+func (oc *OVALCVE) cve() *CVE {
+	cve := CVE{
+		Name:           oc.CVEID,
+		ThreatSeverity: strings.Title(oc.Impact),
+		CWE:            oc.CWE,
+	}
+	if t, err := time.Parse(ovalPublicLayout, oc.Public); err == nil {
+		cve.PublicDate = t.Format(timeLayout)
+	}
+	if parts := strings.SplitN(oc.CVSS2, "/", 2); len(parts) == 2 {
+		cve.CVSS = &CVSS{BaseScore: parts[0], Vector: parts[1]}
+	}
+	if parts := strings.SplitN(oc.CVSS3, "/", 2); len(parts) == 2 {
+		cve.CVSS3 = &CVSS3{BaseScore: parts[0], Vector: parts[1]}
+	}
+	if oc.Href != "" {
+		cve.References = []string{oc.Href}
+	}
+	return &cve
+}
+
+// Merge merges other into cve, affected releases of other take precedence
+// an affected release of cve is replaced if other has one for the same package, module and product.
+// this is used to merge OVAL data, which has more precise fixed versions, into data from the security data API.
+// other fields are only set from other if they're missing in cve
+func (cve *CVE) Merge(other *CVE) {
+	replaced := make(map[affectedReleaseKey]bool)
+	for _, ar := range other.AffectedRelease {
+		replaced[newAffectedReleaseKey(ar)] = true
+	}
+	ars := make(AffectedReleases, 0, len(cve.AffectedRelease)+len(other.AffectedRelease))
+	for _, ar := range cve.AffectedRelease {
+		if !replaced[newAffectedReleaseKey(ar)] {
+			ars = append(ars, ar)
+		}
+	}
+	cve.AffectedRelease = append(ars, other.AffectedRelease...)
+
+	if cve.Name == "" {
+		cve.Name = other.Name
+	}
+	if cve.ThreatSeverity == "" {
+		cve.ThreatSeverity = other.ThreatSeverity
+	}
+	if cve.PublicDate == "" {
+		cve.PublicDate = other.PublicDate
+	}
+	if cve.CVSS == nil {
+		cve.CVSS = other.CVSS
+	}
+	if cve.CVSS3 == nil {
+		cve.CVSS3 = other.CVSS3
+	}
+	if cve.CWE == "" {
+		cve.CWE = other.CWE
+	}
+	if len(cve.References) == 0 {
+		cve.References = other.References
+	}
+}
+
+type affectedReleaseKey struct {
+	cpe, name, module string
+}
+
+func newAffectedReleaseKey(ar *AffectedRelease) affectedReleaseKey {
+	key := affectedReleaseKey{cpe: ar.CPE, name: ar.Package, module: ar.Module}
+	// add .src to parse it correctly, they're all src rpms
+	if pkg, err := rpm.Parse(ar.Package + ".src"); err == nil {
+		key.name = pkg.Name
+	}
+	// only name and stream are known in OVAL
+	if parts := strings.SplitN(ar.Module, ":", 3); len(parts) > 2 {
+		key.module = parts[0] + ":" + parts[1]
+	}
+	return key
+}
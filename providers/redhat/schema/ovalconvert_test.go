@@ -0,0 +1,228 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testOVAL = `<?xml version="1.0" encoding="utf-8"?>
+<oval_definitions xmlns="http://oval.mitre.org/XMLSchema/oval-definitions-5">
+  <definitions>
+    <definition class="patch" id="oval:com.redhat.rhsa:def:20201234" version="636">
+      <metadata>
+        <title>RHSA-2020:1234: openssl security update (Important)</title>
+        <affected family="unix"><platform>Red Hat Enterprise Linux 8</platform></affected>
+        <reference ref_id="RHSA-2020:1234" ref_url="https://access.redhat.com/errata/RHSA-2020:1234" source="RHSA"/>
+        <reference ref_id="CVE-2020-0002" ref_url="https://access.redhat.com/security/cve/CVE-2020-0002" source="CVE"/>
+        <description>OpenSSL is a toolkit.</description>
+        <advisory from="secalert@redhat.com">
+          <severity>Important</severity>
+          <issued date="2020-05-01"/>
+          <updated date="2020-05-02"/>
+          <cve cvss3="7.5/CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H" cwe="CWE-476" href="https://access.redhat.com/security/cve/CVE-2020-0002" impact="important" public="20200421">CVE-2020-0002</cve>
+          <affected_cpe_list>
+            <cpe>cpe:/o:redhat:enterprise_linux:8</cpe>
+            <cpe>cpe:/o:redhat:enterprise_linux:8::baseos</cpe>
+          </affected_cpe_list>
+        </advisory>
+      </metadata>
+      <criteria operator="OR">
+        <criterion comment="Red Hat Enterprise Linux must be installed" test_ref="oval:com.redhat.rhba:tst:20191992005"/>
+        <criteria operator="AND">
+          <criterion comment="Red Hat Enterprise Linux 8 is installed" test_ref="oval:com.redhat.rhba:tst:20191992003"/>
+          <criteria operator="OR">
+            <criteria operator="AND">
+              <criterion comment="openssl is earlier than 1:1.1.1c-15.el8" test_ref="oval:com.redhat.rhsa:tst:20201234001"/>
+              <criterion comment="openssl is signed with Red Hat redhatrelease2 key" test_ref="oval:com.redhat.rhsa:tst:20201234002"/>
+            </criteria>
+            <criteria operator="AND">
+              <criterion comment="openssl-libs is earlier than 1:1.1.1c-15.el8" test_ref="oval:com.redhat.rhsa:tst:20201234003"/>
+              <criterion comment="openssl-libs is signed with Red Hat redhatrelease2 key" test_ref="oval:com.redhat.rhsa:tst:20201234004"/>
+            </criteria>
+          </criteria>
+        </criteria>
+      </criteria>
+    </definition>
+    <definition class="patch" id="oval:com.redhat.rhsa:def:20205678" version="636">
+      <metadata>
+        <title>RHSA-2020:5678: nodejs:12 security update (Moderate)</title>
+        <affected family="unix"><platform>Red Hat Enterprise Linux 8</platform></affected>
+        <reference ref_id="RHSA-2020:5678" ref_url="https://access.redhat.com/errata/RHSA-2020:5678" source="RHSA"/>
+        <advisory from="secalert@redhat.com">
+          <severity>Moderate</severity>
+          <issued date="2020-06-01"/>
+          <cve impact="moderate" public="20200301">CVE-2020-0001</cve>
+          <cve impact="low" public="20200302">CVE-2020-0003</cve>
+          <affected_cpe_list>
+            <cpe>cpe:/a:redhat:enterprise_linux:8::appstream</cpe>
+          </affected_cpe_list>
+        </advisory>
+      </metadata>
+      <criteria operator="OR">
+        <criteria operator="AND">
+          <criterion comment="Module nodejs:12 is enabled" test_ref="oval:com.redhat.rhsa:tst:20205678001"/>
+          <criteria operator="OR">
+            <criterion comment="nodejs is earlier than 1:12.16.1-1.module+el8.2.0+5841+a3b3b5d5" test_ref="oval:com.redhat.rhsa:tst:20205678002"/>
+            <criterion comment="npm is earlier than 1:6.13.4-1.12.16.1.1.module+el8.2.0+5841+a3b3b5d5" test_ref="oval:com.redhat.rhsa:tst:20205678003"/>
+          </criteria>
+        </criteria>
+        <criteria operator="AND" negate="true">
+          <criterion comment="nodejs is earlier than 1:99-1.el8" test_ref="oval:com.redhat.rhsa:tst:20205678004"/>
+        </criteria>
+      </criteria>
+    </definition>
+    <definition class="inventory" id="oval:com.redhat.rhba:def:20191992" version="636">
+      <metadata><title>Red Hat Enterprise Linux 8 is installed</title></metadata>
+    </definition>
+  </definitions>
+</oval_definitions>`
+
+func TestOVALCVEs(t *testing.T) {
+	oval, err := DecodeOVAL(strings.NewReader(testOVAL), "rhel-8.oval.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cves := oval.CVEs()
+
+	openssl := func(pkg, cpe string) *AffectedRelease {
+		return &AffectedRelease{
+			ProductName: "Red Hat Enterprise Linux 8",
+			ReleaseDate: "2020-05-01T00:00:00",
+			Advisory:    "RHSA-2020:1234",
+			Package:     pkg,
+			CPE:         cpe,
+		}
+	}
+	nodejs := func(pkg string) *AffectedRelease {
+		return &AffectedRelease{
+			ProductName: "Red Hat Enterprise Linux 8",
+			ReleaseDate: "2020-06-01T00:00:00",
+			Advisory:    "RHSA-2020:5678",
+			Package:     pkg,
+			CPE:         "cpe:/a:redhat:enterprise_linux:8::appstream",
+			Module:      "nodejs:12",
+		}
+	}
+	nodejsReleases := AffectedReleases{
+		nodejs("nodejs-1:12.16.1-1.module+el8.2.0+5841+a3b3b5d5"),
+		nodejs("npm-1:6.13.4-1.12.16.1.1.module+el8.2.0+5841+a3b3b5d5"),
+	}
+
+	expect := map[string]*CVE{
+		"CVE-2020-0001": {
+			Name:            "CVE-2020-0001",
+			ThreatSeverity:  "Moderate",
+			PublicDate:      "2020-03-01T00:00:00",
+			AffectedRelease: nodejsReleases,
+		},
+		"CVE-2020-0002": {
+			Name:           "CVE-2020-0002",
+			ThreatSeverity: "Important",
+			PublicDate:     "2020-04-21T00:00:00",
+			CVSS3: &CVSS3{
+				BaseScore: "7.5",
+				Vector:    "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:N/I:N/A:H",
+			},
+			CWE:        "CWE-476",
+			References: []string{"https://access.redhat.com/security/cve/CVE-2020-0002"},
+			AffectedRelease: AffectedReleases{
+				openssl("openssl-1:1.1.1c-15.el8", "cpe:/o:redhat:enterprise_linux:8"),
+				openssl("openssl-1:1.1.1c-15.el8", "cpe:/o:redhat:enterprise_linux:8::baseos"),
+				openssl("openssl-libs-1:1.1.1c-15.el8", "cpe:/o:redhat:enterprise_linux:8"),
+				openssl("openssl-libs-1:1.1.1c-15.el8", "cpe:/o:redhat:enterprise_linux:8::baseos"),
+			},
+		},
+		"CVE-2020-0003": {
+			Name:            "CVE-2020-0003",
+			ThreatSeverity:  "Low",
+			PublicDate:      "2020-03-02T00:00:00",
+			AffectedRelease: nodejsReleases,
+		},
+	}
+	if !reflect.DeepEqual(cves, expect) {
+		for id, cve := range cves {
+			if !reflect.DeepEqual(cve, expect[id]) {
+				t.Errorf("%s: expecting %+v, got %+v", id, expect[id], cve)
+			}
+		}
+		t.Fatalf("expecting %d cves, got %d", len(expect), len(cves))
+	}
+}
+
+func TestOVALDefinitionUpdated(t *testing.T) {
+	oval, err := DecodeOVAL(strings.NewReader(testOVAL), "rhel-8.oval.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, expect := range []string{"2020-05-02", "2020-06-01"} {
+		updated, err := oval.Definitions[i].Updated()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := updated.Format(ovalDateLayout); got != expect {
+			t.Errorf("definition %d: expecting %s, got %s", i, expect, got)
+		}
+	}
+	if _, err := oval.Definitions[2].Updated(); err == nil {
+		t.Error("expecting an error for a definition without dates")
+	}
+}
+
+func TestCVEMerge(t *testing.T) {
+	cve := CVE{
+		Name:           "CVE-2020-0002",
+		ThreatSeverity: "Moderate",
+		CVSS3:          &CVSS3{BaseScore: "5.9"},
+		AffectedRelease: AffectedReleases{
+			// replaced, same package on the same product
+			{Package: "openssl-1:1.1.1c-2.el8", CPE: "cpe:/o:redhat:enterprise_linux:8"},
+			// kept, other product
+			{Package: "openssl-1:1.0.2k-19.el7", CPE: "cpe:/o:redhat:enterprise_linux:7"},
+			// replaced, the same module stream
+			{Package: "nodejs-1:12.14.0-1.module+el8.1.0+5466+30f75629", CPE: "cpe:/a:redhat:enterprise_linux:8", Module: "nodejs:12:8010020191211160122:cdc1202b"},
+			// kept, other module stream
+			{Package: "nodejs-1:10.19.0-1.module+el8.1.0+5726+6ed65f8c", CPE: "cpe:/a:redhat:enterprise_linux:8", Module: "nodejs:10:8010020200121102504:cdc1202b"},
+		},
+	}
+	oval := CVE{
+		Name:           "CVE-2020-0002",
+		ThreatSeverity: "Important",
+		CWE:            "CWE-476",
+		AffectedRelease: AffectedReleases{
+			{Package: "openssl-1:1.1.1c-15.el8", CPE: "cpe:/o:redhat:enterprise_linux:8"},
+			{Package: "nodejs-1:12.16.1-1.module+el8.2.0+5841+a3b3b5d5", CPE: "cpe:/a:redhat:enterprise_linux:8", Module: "nodejs:12"},
+		},
+	}
+	cve.Merge(&oval)
+
+	expect := CVE{
+		Name:           "CVE-2020-0002",
+		ThreatSeverity: "Moderate",
+		CVSS3:          &CVSS3{BaseScore: "5.9"},
+		CWE:            "CWE-476",
+		AffectedRelease: AffectedReleases{
+			{Package: "openssl-1:1.0.2k-19.el7", CPE: "cpe:/o:redhat:enterprise_linux:7"},
+			{Package: "nodejs-1:10.19.0-1.module+el8.1.0+5726+6ed65f8c", CPE: "cpe:/a:redhat:enterprise_linux:8", Module: "nodejs:10:8010020200121102504:cdc1202b"},
+			{Package: "openssl-1:1.1.1c-15.el8", CPE: "cpe:/o:redhat:enterprise_linux:8"},
+			{Package: "nodejs-1:12.16.1-1.module+el8.2.0+5841+a3b3b5d5", CPE: "cpe:/a:redhat:enterprise_linux:8", Module: "nodejs:12"},
+		},
+	}
+	if !reflect.DeepEqual(cve, expect) {
+		t.Fatalf("expecting %+v, got %+v", expect, cve)
+	}
+}
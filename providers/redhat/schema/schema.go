@@ -28,31 +28,19 @@ type CVEList []struct {
 }
 
 type CVE struct {
-	Name           string `json:"name,omitempty"`
-	ThreatSeverity string `json:"threat_severity,omitempty"`
-	PublicDate     string `json:"public_date,omitempty"`
-	Bugzilla       *struct {
-		Description string `json:"description,omitempty"`
-		ID          string `json:"id,omitempty"`
-		URL         string `json:"url,omitempty"`
-	} `json:"bugzilla,omitempty"`
-	CVSS *struct {
-		BaseScore string `json:"cvss_base_score,omitempty"`
-		Vector    string `json:"cvss_scoring_vector,omitempty"`
-		Status    string `json:"status,omitempty"`
-	} `json:"CVSS,omitempty"`
-	CVSS3 *struct {
-		BaseScore string `json:"cvss3_base_score,omitempty"`
-		Vector    string `json:"cvss3_scoring_vector,omitempty"`
-		Status    string `json:"status,omitempty"`
-	} `json:"CVSS3,omitempty"`
-	CWE             string   `json:"cwe,omitempty"`
-	Details         []string `json:"details,omitempty"`
-	Statement       string   `json:"statement,omitempty"`
-	References      []string `json:"references,omitempty"`
-	Acknowledgement string   `json:"acknowledgement,omitempty"`
-	Mitigation      string   `json:"mitigation,omitempty"`
-	UpstreamFix     string   `json:"upstream_fix,omitempty"`
+	Name            string    `json:"name,omitempty"`
+	ThreatSeverity  string    `json:"threat_severity,omitempty"`
+	PublicDate      string    `json:"public_date,omitempty"`
+	Bugzilla        *Bugzilla `json:"bugzilla,omitempty"`
+	CVSS            *CVSS     `json:"CVSS,omitempty"`
+	CVSS3           *CVSS3    `json:"CVSS3,omitempty"`
+	CWE             string    `json:"cwe,omitempty"`
+	Details         []string  `json:"details,omitempty"`
+	Statement       string    `json:"statement,omitempty"`
+	References      []string  `json:"references,omitempty"`
+	Acknowledgement string    `json:"acknowledgement,omitempty"`
+	Mitigation      string    `json:"mitigation,omitempty"`
+	UpstreamFix     string    `json:"upstream_fix,omitempty"`
 
 	// redhat uses a single object insted of an array when there's a single instance of that entity
 	// that's why we need to do it manually
@@ -62,12 +50,33 @@ type CVE struct {
 	PackageState    PackageStates    `json:"package_state,omitempty"`
 }
 
+type Bugzilla struct {
+	Description string `json:"description,omitempty"`
+	ID          string `json:"id,omitempty"`
+	URL         string `json:"url,omitempty"`
+}
+
+type CVSS struct {
+	BaseScore string `json:"cvss_base_score,omitempty"`
+	Vector    string `json:"cvss_scoring_vector,omitempty"`
+	Status    string `json:"status,omitempty"`
+}
+
+type CVSS3 struct {
+	BaseScore string `json:"cvss3_base_score,omitempty"`
+	Vector    string `json:"cvss3_scoring_vector,omitempty"`
+	Status    string `json:"status,omitempty"`
+}
+
 type AffectedRelease struct {
 	ProductName string `json:"product_name,omitempty"`
 	ReleaseDate string `json:"release_date,omitempty"`
 	Advisory    string `json:"advisory,omitempty"`
 	Package     string `json:"package,omitempty"`
 	CPE         string `json:"cpe,omitempty"`
+	// Module is the modularity label (name:stream:version:context) of the module the package was fixed in
+	// it's not a part of red hat's format, it's only set when converting from VEX
+	Module string `json:"module,omitempty"`
 }
 
 type PackageState struct {
@@ -0,0 +1,29 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rustsec
+
+import (
+	"context"
+	"github.com/facebookincubator/nvdtools/providers/lib/git"
+)
+
+// AdvisoryDBURL is the git repository of the rustsec advisory database.
+const AdvisoryDBURL = "https://github.com/RustSec/advisory-db"
+
+// Sync clones the advisory database git repository from url into dir, or pulls the latest
+// changes if it has already been cloned there, so advisories can be converted from dir.
+func Sync(ctx context.Context, dir, url string) error {
+	return git.Sync(ctx, dir, url)
+}
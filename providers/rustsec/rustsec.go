@@ -16,7 +16,9 @@
 package rustsec
 
 import (
+	"bytes"
 	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"sort"
@@ -39,7 +41,16 @@ func Convert(dir string) (*nvd.NVDCVEFeedJSON10, error) {
 			return err
 		}
 		_, fn := filepath.Split(path)
-		if !(strings.HasPrefix(fn, "RUSTSEC") && strings.HasSuffix(fn, ".toml")) {
+		if !strings.HasPrefix(fn, "RUSTSEC") {
+			return nil
+		}
+		var convert func(io.Reader) (*nvd.NVDCVEFeedJSON10DefCVEItem, error)
+		switch filepath.Ext(fn) {
+		case ".toml":
+			convert = ConvertAdvisory
+		case ".md":
+			convert = ConvertMarkdownAdvisory
+		default:
 			return nil
 		}
 		f, err := os.Open(path)
@@ -47,7 +58,10 @@ func Convert(dir string) (*nvd.NVDCVEFeedJSON10, error) {
 			return err
 		}
 		defer f.Close()
-		cve, err := ConvertAdvisory(f)
+		cve, err := convert(f)
+		if err == errWithdrawn {
+			return nil
+		}
 		if err != nil {
 			return errors.Wrapf(err, "error parsing file: %s", path)
 		}
@@ -63,6 +77,9 @@ func Convert(dir string) (*nvd.NVDCVEFeedJSON10, error) {
 	return feed, nil
 }
 
+// errWithdrawn is returned when converting advisories which have been withdrawn.
+var errWithdrawn = errors.New("advisory has been withdrawn")
+
 // ConvertAdvisory converts the rustsec toml advisory data from r to NVD CVE JSON 1.0 format.
 func ConvertAdvisory(r io.Reader) (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
 	var spec advisoryFile
@@ -71,13 +88,73 @@ func ConvertAdvisory(r io.Reader) (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
 		return nil, errors.Wrap(err, "cannot decode rustsec toml advisory")
 	}
 
-	return spec.Item.Convert()
+	return spec.convert()
+}
+
+// ConvertMarkdownAdvisory converts the rustsec markdown advisory data from r to NVD CVE JSON 1.0 format.
+// Advisories in this format start with the toml front matter in a code block, followed by
+// the title in a heading and the description.
+func ConvertMarkdownAdvisory(r io.Reader) (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot read rustsec markdown advisory")
+	}
+
+	data = bytes.TrimSpace(data)
+	if !bytes.HasPrefix(data, []byte(markdownFence)) {
+		return nil, errors.New("missing toml front matter in rustsec markdown advisory")
+	}
+	data = bytes.TrimPrefix(data[len(markdownFence):], []byte("toml"))
+	end := bytes.Index(data, []byte(markdownFence))
+	if end < 0 {
+		return nil, errors.New("unterminated toml front matter in rustsec markdown advisory")
+	}
+
+	var spec advisoryFile
+	if _, err := toml.Decode(string(data[:end]), &spec); err != nil {
+		return nil, errors.Wrap(err, "cannot decode rustsec toml front matter")
+	}
+
+	text := strings.TrimSpace(string(data[end+len(markdownFence):]))
+	if strings.HasPrefix(text, "# ") {
+		text = strings.TrimPrefix(text, "# ")
+		title := text
+		if i := strings.IndexByte(text, '\n'); i >= 0 {
+			title, text = text[:i], strings.TrimSpace(text[i+1:])
+		} else {
+			text = ""
+		}
+		if spec.Item.Title == "" {
+			spec.Item.Title = strings.TrimSpace(title)
+		}
+	}
+	if spec.Item.Description == "" {
+		spec.Item.Description = text
+	}
+
+	return spec.convert()
 }
 
+const markdownFence = "```"
+
 // advisoryFile is the toml spec for rustsec advisories.
+// Versions are in the advisory section in the old format, and in a separate section in the new one.
 // Ref: https://github.com/RustSec/advisory-db
 type advisoryFile struct {
-	Item advisoryItem `toml:"advisory"`
+	Item     advisoryItem `toml:"advisory"`
+	Versions struct {
+		Patched    []string `toml:"patched"`
+		Unaffected []string `toml:"unaffected"`
+	} `toml:"versions"`
+}
+
+func (spec *advisoryFile) convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	if spec.Item.Withdrawn != "" {
+		return nil, errWithdrawn
+	}
+	spec.Item.PatchedVersions = append(spec.Item.PatchedVersions, spec.Versions.Patched...)
+	spec.Item.UnaffectedVersions = append(spec.Item.UnaffectedVersions, spec.Versions.Unaffected...)
+	return spec.Item.Convert()
 }
 
 type advisoryItem struct {
@@ -95,6 +172,7 @@ type advisoryItem struct {
 	AffectedOS         []string `toml:"affected_os"`
 	AffectedFunctions  []string `toml:"affected_functions"`
 	UnaffectedVersions []string `toml:"unaffected_versions"`
+	Withdrawn          string   `toml:"withdrawn"`
 }
 
 const advisoryTimeLayout = "2006-01-02"
@@ -165,7 +243,11 @@ func (item *advisoryItem) newReferences() *nvd.CVEJSON40References {
 	}
 
 	for _, ref := range item.References {
-		addRef(ref, "")
+		if strings.HasPrefix(ref, "http") {
+			addRef(ref, ref)
+		} else {
+			addRef(ref, "")
+		}
 	}
 
 	rd := refs.ReferenceData
@@ -186,66 +268,35 @@ func (item *advisoryItem) newConfigurations() (*nvd.NVDCVEFeedJSON10DefConfigura
 	cpe23uri := cpe.BindToFmtString()
 
 	matches := []*nvd.NVDCVEFeedJSON10DefCPEMatch{}
-	unnafected := append(item.UnaffectedVersions, item.PatchedVersions...)
+	unaffected := append(item.UnaffectedVersions, item.PatchedVersions...)
 
-	for _, version := range unnafected {
-		if len(version) < 2 {
-			return nil, errors.Errorf("malformed version schema in %#v: %q", item, version)
+	for _, req := range unaffected {
+		vr, err := parseRequirement(req)
+		if err != nil {
+			return nil, errors.Wrapf(err, "malformed version schema in %s", item.ID)
 		}
 
-		var curver string
-
-		switch version[:1] {
-		case "=", "^":
-			curver = strings.TrimSpace(version[1:])
-			wfnver, err := wfn.WFNize(curver)
-			if err != nil {
-				return nil, errors.Wrapf(err, "cannot wfn-ize version: %q", curver)
-			}
-			cpe := wfn.Attributes{Part: "a", Product: pkg, Version: wfnver}
-			cpe22uri := cpe.BindToURI()
-			cpe23uri := cpe.BindToFmtString()
-			match := &nvd.NVDCVEFeedJSON10DefCPEMatch{
-				CPEName: []*nvd.NVDCVEFeedJSON10DefCPEName{
-					{
-						Cpe22Uri: cpe22uri,
-						Cpe23Uri: cpe23uri,
-					},
-				},
-				Cpe23Uri:   cpe23uri,
-				Vulnerable: version[:1] == "=",
+		cpe := wfn.Attributes{Part: "a", Product: pkg}
+		if vr.exact != "" {
+			if cpe.Version, err = wfn.WFNize(vr.exact); err != nil {
+				return nil, errors.Wrapf(err, "cannot wfn-ize version: %q", vr.exact)
 			}
-			matches = append(matches, match)
-
-		case ">", "<":
-			match := &nvd.NVDCVEFeedJSON10DefCPEMatch{
-				CPEName: []*nvd.NVDCVEFeedJSON10DefCPEName{
-					{
-						Cpe22Uri: cpe22uri,
-						Cpe23Uri: cpe23uri,
-					},
-				},
-				Cpe23Uri:   cpe23uri,
-				Vulnerable: false, // these are patched + unaffected versions
-			}
-			curver = strings.TrimSpace(version[2:])
-			switch version[:2] {
-			case "> ":
-				match.VersionStartExcluding = curver
-			case ">=":
-				match.VersionStartIncluding = curver
-			case "< ":
-				match.VersionEndExcluding = curver
-			case "<=":
-				match.VersionEndIncluding = curver
-			default:
-				return nil, errors.Errorf("malformed version schema in %#v: %q", item, version)
-			}
-			matches = append(matches, match)
-
-		default:
-			return nil, errors.Errorf("malformed version schema in %#v: %q", item, version)
 		}
+		cpe23uri := cpe.BindToFmtString()
+		matches = append(matches, &nvd.NVDCVEFeedJSON10DefCPEMatch{
+			CPEName: []*nvd.NVDCVEFeedJSON10DefCPEName{
+				{
+					Cpe22Uri: cpe.BindToURI(),
+					Cpe23Uri: cpe23uri,
+				},
+			},
+			Cpe23Uri:              cpe23uri,
+			Vulnerable:            false, // these are patched + unaffected versions
+			VersionStartIncluding: vr.startIncluding,
+			VersionStartExcluding: vr.startExcluding,
+			VersionEndIncluding:   vr.endIncluding,
+			VersionEndExcluding:   vr.endExcluding,
+		})
 	}
 
 	conf := &nvd.NVDCVEFeedJSON10DefConfigurations{
@@ -264,7 +315,7 @@ func (item *advisoryItem) newConfigurations() (*nvd.NVDCVEFeedJSON10DefConfigura
 									},
 								},
 								Cpe23Uri:              cpe23uri,
-								Vulnerable:            false,
+								Vulnerable:            true,
 								VersionStartIncluding: "0",
 							},
 						},
@@ -24,6 +24,9 @@ import (
 	"os/exec"
 	"strings"
 	"testing"
+
+	"github.com/facebookincubator/nvdtools/cvefeed/nvd"
+	"github.com/facebookincubator/nvdtools/wfn"
 )
 
 func TestConvertAdvisory(t *testing.T) {
@@ -50,6 +53,84 @@ func TestConvertAdvisory(t *testing.T) {
 	}
 }
 
+func TestConvertMarkdownAdvisory(t *testing.T) {
+	cve, err := ConvertMarkdownAdvisory(strings.NewReader(sampleMarkdownAdvisory))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if title := cve.CVE.References.ReferenceData[1].Name; title != "Use after free in mycrate" {
+		t.Fatalf("wrong title %q", title)
+	}
+	if desc := cve.CVE.Description.DescriptionData[0].Value; desc != "Affected versions of this crate did not properly X." {
+		t.Fatalf("wrong description %q", desc)
+	}
+
+	v := nvd.ToVuln(cve)
+	for i, tc := range []struct {
+		version string
+		match   bool
+	}{
+		{"0.2.9", false}, // unaffected
+		{"0.3.0", true},
+		{"0.3.4", false}, // ^0.3.4
+		{"0.4.0", true},
+		{"0.4.2", false}, // ~0.4.2
+		{"0.5.0", true},
+		{"1.0.0", false}, // >= 1.0.0
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			attrs := &wfn.Attributes{Part: "a", Product: "mycrate", Version: tc.version}
+			if matched := len(v.Match([]*wfn.Attributes{attrs}, true)) != 0; matched != tc.match {
+				t.Fatalf("expecting match of %s to be %v, got %v", tc.version, tc.match, matched)
+			}
+		})
+	}
+
+	if _, err := ConvertMarkdownAdvisory(strings.NewReader(strings.Replace(sampleMarkdownAdvisory, "[versions]", "withdrawn = \"2021-02-01\"\n\n[versions]", 1))); err != errWithdrawn {
+		t.Fatalf("expecting withdrawn advisory, got %v", err)
+	}
+}
+
+func TestParseRequirement(t *testing.T) {
+	for i, tc := range []struct {
+		req    string
+		expect versionRange
+	}{
+		{"^1.2.3", versionRange{startIncluding: "1.2.3", endExcluding: "2.0.0"}},
+		{"1.2.3", versionRange{startIncluding: "1.2.3", endExcluding: "2.0.0"}},
+		{"^0.2.3", versionRange{startIncluding: "0.2.3", endExcluding: "0.3.0"}},
+		{"^0.0.3", versionRange{startIncluding: "0.0.3", endExcluding: "0.0.4"}},
+		{"^0.0", versionRange{startIncluding: "0.0", endExcluding: "0.1.0"}},
+		{"^1.2.3-alpha.1", versionRange{startIncluding: "1.2.3-alpha.1", endExcluding: "2.0.0"}},
+		{"~1.2.3", versionRange{startIncluding: "1.2.3", endExcluding: "1.3.0"}},
+		{"~1", versionRange{startIncluding: "1", endExcluding: "2.0.0"}},
+		{"1.2.*", versionRange{startIncluding: "1.2.0", endExcluding: "1.3.0"}},
+		{"=1.0.1", versionRange{exact: "1.0.1"}},
+		{">= 1.2.0, < 1.3.5", versionRange{startIncluding: "1.2.0", endExcluding: "1.3.5"}},
+		{"> 0.1, <= 0.2", versionRange{startExcluding: "0.1", endIncluding: "0.2"}},
+		{"*", versionRange{}},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			vr, err := parseRequirement(tc.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *vr != tc.expect {
+				t.Fatalf("expecting %+v, got %+v", tc.expect, *vr)
+			}
+		})
+	}
+
+	for i, req := range []string{"^x.y", "!= 1.0", ">=", "1.2.3.4"} {
+		t.Run(fmt.Sprintf("error-%d", i+1), func(t *testing.T) {
+			if vr, err := parseRequirement(req); err == nil {
+				t.Fatalf("expecting an error, got %+v", *vr)
+			}
+		})
+	}
+}
+
 func diff(a, b string) error {
 	f1, err := ioutil.TempFile("", "rustsec2nvd")
 	if err != nil {
@@ -210,7 +291,7 @@ var goldenCVE = `{
 								],
 								"cpe23Uri": "cpe:2.3:a:*:mycrate:*:*:*:*:*:*:*:*",
 								"versionStartIncluding": "0",
-								"vulnerable": false
+								"vulnerable": true
 							}
 						]
 					},
@@ -241,11 +322,13 @@ var goldenCVE = `{
 							{
 								"cpe_name": [
 									{
-										"cpe22Uri": "cpe:/a::mycrate:1.2.1",
-										"cpe23Uri": "cpe:2.3:a:*:mycrate:1.2.1:*:*:*:*:*:*:*"
+										"cpe22Uri": "cpe:/a::mycrate",
+										"cpe23Uri": "cpe:2.3:a:*:mycrate:*:*:*:*:*:*:*:*"
 									}
 								],
-								"cpe23Uri": "cpe:2.3:a:*:mycrate:1.2.1:*:*:*:*:*:*:*",
+								"cpe23Uri": "cpe:2.3:a:*:mycrate:*:*:*:*:*:*:*:*",
+								"versionEndExcluding": "2.0.0",
+								"versionStartIncluding": "1.2.1",
 								"vulnerable": false
 							}
 						],
@@ -260,3 +343,22 @@ var goldenCVE = `{
 	"publishedDate": "2017-02-25T00:00Z"
 }
 `
+
+var sampleMarkdownAdvisory = "```toml" + `
+[advisory]
+id = "RUSTSEC-0000-0001"
+package = "mycrate"
+date = "2021-01-04"
+url = "https://github.com/mystuff/mycrate/issues/123"
+references = ["https://github.com/mystuff/mycrate/pull/124"]
+aliases = ["CVE-2021-XXXX"]
+
+[versions]
+patched = ["^0.3.4", "~0.4.2", ">= 1.0.0"]
+unaffected = ["< 0.3.0"]
+` + "```" + `
+
+# Use after free in mycrate
+
+Affected versions of this crate did not properly X.
+`
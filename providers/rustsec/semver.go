@@ -0,0 +1,134 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rustsec
+
+import (
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/semver"
+	"github.com/pkg/errors"
+)
+
+// versionRange is a range of versions, empty bounds are unlimited
+type versionRange struct {
+	startIncluding string
+	startExcluding string
+	endIncluding   string
+	endExcluding   string
+	// exact is set when the requirement matches only one version
+	exact string
+}
+
+// parseRequirement parses a cargo version requirement into a range of versions
+// requirement can consist of multiple comparators separated by a comma, e.g. ">= 1.2.0, < 1.3.0"
+// Ref: https://doc.rust-lang.org/cargo/reference/specifying-dependencies.html
+func parseRequirement(req string) (*versionRange, error) {
+	var vr versionRange
+	for _, comparator := range strings.Split(req, ",") {
+		if err := vr.add(strings.TrimSpace(comparator)); err != nil {
+			return nil, errors.Wrapf(err, "malformed version requirement %q", req)
+		}
+	}
+	return &vr, nil
+}
+
+// add narrows the range by the given comparator
+func (vr *versionRange) add(comparator string) error {
+	op, version := splitComparator(comparator)
+	if version == "" || version == "*" {
+		if op != "" {
+			return errors.Errorf("missing version in %q", comparator)
+		}
+		// any version
+		return nil
+	}
+
+	switch op {
+	case "=":
+		if strings.Contains(version, "*") {
+			return vr.addWildcard(version)
+		}
+		vr.exact = version
+	case ">=":
+		vr.startIncluding = version
+	case ">":
+		vr.startExcluding = version
+	case "<=":
+		vr.endIncluding = version
+	case "<":
+		vr.endExcluding = version
+	case "~":
+		v, err := semver.Parse(version)
+		if err != nil {
+			return err
+		}
+		vr.startIncluding = version
+		if v.Parts == 1 {
+			vr.endExcluding = bump(v, 0)
+		} else {
+			vr.endExcluding = bump(v, 1)
+		}
+	case "^", "":
+		if strings.Contains(version, "*") {
+			return vr.addWildcard(version)
+		}
+		v, err := semver.Parse(version)
+		if err != nil {
+			return err
+		}
+		// the first non zero part can't change, if all are zero, the last one can't change
+		parts := []uint64{v.Major, v.Minor, v.Patch}[:v.Parts]
+		i := 0
+		for i < len(parts)-1 && parts[i] == 0 {
+			i++
+		}
+		vr.startIncluding = version
+		vr.endExcluding = bump(v, i)
+	default:
+		return errors.Errorf("unknown operator %q", op)
+	}
+	return nil
+}
+
+// addWildcard narrows the range by a version with a wildcard, e.g. 1.2.*
+func (vr *versionRange) addWildcard(version string) error {
+	v, err := semver.Parse(strings.TrimSuffix(strings.TrimSuffix(version, "*"), "."))
+	if err != nil {
+		return err
+	}
+	start := *v
+	start.Parts = 3
+	vr.startIncluding = start.String()
+	vr.endExcluding = bump(v, v.Parts-1)
+	return nil
+}
+
+func splitComparator(comparator string) (op, version string) {
+	i := strings.IndexFunc(comparator, func(r rune) bool {
+		return !strings.ContainsRune("=<>^~", r)
+	})
+	if i < 0 {
+		return comparator, ""
+	}
+	return comparator[:i], strings.TrimSpace(comparator[i:])
+}
+
+// bump increments the i-th part of the version and zeroes the following ones
+// the result always has major, minor and patch numbers
+func bump(v *semver.Version, i int) string {
+	b := v.Bump(i)
+	b.Parts = 3
+	return b.String()
+}
@@ -0,0 +1,178 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+	"github.com/facebookincubator/nvdtools/providers/snyk/schema"
+)
+
+// LegacyBaseURL is the base url of the Snyk vulnerability feed
+const LegacyBaseURL = "https://data.snyk.io/api/v4"
+
+var (
+	// OrgID is the organization whose issues the registered provider exports using the REST API
+	// if it's empty, the vulnerability feed is downloaded
+	OrgID string
+	// Languages filters vulnerabilities the registered provider downloads and reads
+	Languages LanguageFilter
+)
+
+func init() {
+	runner.Register(runner.Registration{
+		Name:        "snyk",
+		BaseURL:     LegacyBaseURL,
+		OptionalEnv: []string{"SNYK_ID", "SNYK_READONLY_KEY", "SNYK_TOKEN"},
+		New:         newProvider,
+		Read:        Read,
+	})
+}
+
+// newProvider exports issues of OrgID if it's set, otherwise it downloads the vulnerability feed
+func newProvider(c client.Client, baseURL string, env map[string]string) (runner.Provider, error) {
+	if OrgID != "" {
+		token := env["SNYK_TOKEN"]
+		if token == "" {
+			return nil, fmt.Errorf("please set SNYK_TOKEN in environment")
+		}
+		if baseURL == LegacyBaseURL {
+			baseURL = RESTBaseURL
+		}
+		client := NewRESTClient(c, baseURL, token, OrgID)
+		return runner.ConvertibleProvider(func(ctx context.Context, since time.Time) (<-chan runner.Convertible, error) {
+			issues, err := client.FetchOrgIssues(ctx, since.Unix())
+			if err != nil {
+				return nil, err
+			}
+			vulns := make(chan runner.Convertible)
+			go func() {
+				defer close(vulns)
+				for issue := range issues {
+					vulns <- issue
+				}
+			}()
+			return Languages.filter(vulns), nil
+		}), nil
+	}
+
+	for _, key := range []string{"SNYK_ID", "SNYK_READONLY_KEY"} {
+		if env[key] == "" {
+			return nil, fmt.Errorf("please set %s in environment", key)
+		}
+	}
+	client := NewClient(c, baseURL, env["SNYK_ID"], env["SNYK_READONLY_KEY"])
+	return runner.ConvertibleProvider(func(ctx context.Context, since time.Time) (<-chan runner.Convertible, error) {
+		advs, err := client.FetchAllVulnerabilities(ctx, since.Unix())
+		if err != nil {
+			return nil, err
+		}
+		vulns := make(chan runner.Convertible)
+		go func() {
+			defer close(vulns)
+			for adv := range advs {
+				vulns <- adv
+			}
+		}()
+		return Languages.filter(vulns), nil
+	}), nil
+}
+
+// Read reads advisories and issues downloaded by the runner, keeping only the ones accepted by Languages
+func Read(r io.Reader, c chan runner.Convertible) error {
+	var vulns map[string]json.RawMessage
+	if err := json.NewDecoder(r).Decode(&vulns); err != nil {
+		return fmt.Errorf("can't decode into vulns: %v", err)
+	}
+
+	for id, data := range vulns {
+		var vuln runner.Convertible
+		if schema.IsIssue(data) {
+			vuln = new(schema.Issue)
+		} else {
+			vuln = new(schema.Advisory)
+		}
+		if err := json.Unmarshal(data, vuln); err != nil {
+			return fmt.Errorf("can't decode vuln %s: %v", id, err)
+		}
+		if Languages.accepts(vuln) {
+			c <- vuln
+		}
+	}
+
+	return nil
+}
+
+// LanguageFilter is a set of languages, it's a flag.Value which is set to a comma separated list
+type LanguageFilter map[string]bool
+
+// String is a part of flag.Value interface implementation.
+func (lf *LanguageFilter) String() string {
+	languages := make([]string, 0, len(*lf))
+	for language := range *lf {
+		languages = append(languages, language)
+	}
+	return strings.Join(languages, ",")
+}
+
+// Set is a part of flag.Value interface implementation.
+func (lf *LanguageFilter) Set(val string) error {
+	if val == "" {
+		return nil
+	}
+	if *lf == nil {
+		*lf = make(LanguageFilter)
+	}
+	for _, v := range strings.Split(val, ",") {
+		if v != "" {
+			(*lf)[v] = true
+		}
+	}
+	return nil
+}
+
+// accepts returns true if the filter is empty or it contains the language of the vulnerability
+func (lf LanguageFilter) accepts(vuln runner.Convertible) bool {
+	if len(lf) == 0 {
+		return true
+	}
+	switch v := vuln.(type) {
+	case *schema.Advisory:
+		return lf[v.Language]
+	case *schema.Issue:
+		return lf[v.Language()]
+	}
+	return false
+}
+
+func (lf LanguageFilter) filter(ch <-chan runner.Convertible) <-chan runner.Convertible {
+	output := make(chan runner.Convertible)
+	go func() {
+		defer close(output)
+		for vuln := range ch {
+			if lf.accepts(vuln) {
+				output <- vuln
+			}
+		}
+	}()
+	return output
+}
@@ -0,0 +1,78 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+)
+
+const testDownload = `{
+	"SNYK-JS-LODASH-567746": {"id": "SNYK-JS-LODASH-567746", "language": "js"},
+	"SNYK-PYTHON-DJANGO-1066259": {"id": "SNYK-PYTHON-DJANGO-1066259", "language": "python"},
+	"SNYK-GOLANG-GITHUBCOMGINGONICGIN-1041736": {"id": "ignored", "attributes": {"key": "SNYK-GOLANG-GITHUBCOMGINGONICGIN-1041736"}}
+}`
+
+func TestRead(t *testing.T) {
+	read := func(languages string) []string {
+		Languages = nil
+		defer func() { Languages = nil }()
+		if err := Languages.Set(languages); err != nil {
+			t.Fatal(err)
+		}
+		c := make(chan runner.Convertible, 3)
+		if err := Read(strings.NewReader(testDownload), c); err != nil {
+			t.Fatal(err)
+		}
+		close(c)
+		var ids []string
+		for vuln := range c {
+			ids = append(ids, vuln.ID())
+		}
+		sort.Strings(ids)
+		return ids
+	}
+
+	cases := map[string][]string{
+		"":          {"SNYK-GOLANG-GITHUBCOMGINGONICGIN-1041736", "SNYK-JS-LODASH-567746", "SNYK-PYTHON-DJANGO-1066259"},
+		"js":        {"SNYK-JS-LODASH-567746"},
+		"golang,js": {"SNYK-GOLANG-GITHUBCOMGINGONICGIN-1041736", "SNYK-JS-LODASH-567746"},
+	}
+	for languages, want := range cases {
+		if got := read(languages); fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("languages %q: expecting %v, got %v", languages, want, got)
+		}
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	reg, ok := runner.Lookup("snyk")
+	if !ok {
+		t.Fatal("snyk provider isn't registered")
+	}
+	getenv := func(string) string { return "" }
+	if _, err := reg.NewProvider(nil, reg.BaseURL, getenv); err == nil || !strings.Contains(err.Error(), "SNYK_ID") {
+		t.Fatalf("expecting an error about SNYK_ID, got %v", err)
+	}
+	OrgID = "org"
+	defer func() { OrgID = "" }()
+	if _, err := reg.NewProvider(nil, reg.BaseURL, getenv); err == nil || !strings.Contains(err.Error(), "SNYK_TOKEN") {
+		t.Fatalf("expecting an error about SNYK_TOKEN in org mode, got %v", err)
+	}
+}
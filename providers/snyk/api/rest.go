@@ -0,0 +1,173 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/snyk/schema"
+)
+
+const (
+	// RESTBaseURL is the base url of the Snyk REST API (formerly v3)
+	RESTBaseURL = "https://api.snyk.io/rest"
+	// RESTVersion is the version of the REST API requested
+	RESTVersion = "2024-01-23"
+
+	restPageSize = 100
+	// maxRateLimitWaits is the number of times a rate limited request is retried
+	maxRateLimitWaits = 5
+)
+
+// defaultRetryAfter is how long to wait after being rate limited if the API doesn't say
+var defaultRetryAfter = time.Minute
+
+// RESTClient exports issues using the Snyk REST API
+type RESTClient struct {
+	client.Client
+	baseURL string
+	token   string
+	orgID   string
+}
+
+// NewRESTClient creates a client exporting issues of the given organization
+// base url should point to the REST API root, e.g. https://api.snyk.io/rest
+func NewRESTClient(c client.Client, baseURL, token, orgID string) *RESTClient {
+	return &RESTClient{
+		Client:  c,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		token:   token,
+		orgID:   orgID,
+	}
+}
+
+// FetchOrgIssues fetches all package vulnerability issues of the organization updated since the given time
+func (c *RESTClient) FetchOrgIssues(ctx context.Context, since int64) (<-chan *schema.Issue, error) {
+	query := url.Values{
+		"version": {RESTVersion},
+		"limit":   {strconv.Itoa(restPageSize)},
+		"type":    {"package_vulnerability"},
+	}
+	if since > 0 {
+		query.Set("updated_after", time.Unix(since, 0).UTC().Format(time.RFC3339))
+	}
+	u := fmt.Sprintf("%s/orgs/%s/issues?%s", c.baseURL, url.PathEscape(c.orgID), query.Encode())
+
+	// fetch the first page synchronously so we can fail early on wrong token etc.
+	page, err := c.issues(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	output := make(chan *schema.Issue)
+	go func() {
+		defer close(output)
+		for {
+			for _, issue := range page.Data {
+				output <- issue
+			}
+			if page.Links == nil || page.Links.Next == "" {
+				return
+			}
+			next, err := c.resolve(page.Links.Next)
+			if err != nil {
+				logging.Errorf("can't follow next page link %q: %v", page.Links.Next, err)
+				return
+			}
+			if page, err = c.issues(ctx, next); err != nil {
+				logging.Errorf("can't fetch issues at %q: %v", next, err)
+				return
+			}
+		}
+	}()
+
+	return output, nil
+}
+
+// resolve returns the absolute url of a pagination link; links are either absolute, or relative to
+// the host (/rest/orgs/...) or to the API root (/orgs/...)
+func (c *RESTClient) resolve(link string) (string, error) {
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return "", err
+	}
+	ref, err := url.Parse(link)
+	if err != nil {
+		return "", err
+	}
+	if ref.IsAbs() {
+		return ref.String(), nil
+	}
+	if base.Path != "" && !strings.HasPrefix(ref.Path, base.Path+"/") {
+		ref.Path = base.Path + ref.Path
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// issues fetches a single page of issues
+func (c *RESTClient) issues(ctx context.Context, u string) (*schema.IssuesResponse, error) {
+	resp, err := c.get(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var page schema.IssuesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("can't decode issues: %v", err)
+	}
+	return &page, nil
+}
+
+// get sends the request, waiting and retrying if it's rate limited
+func (c *RESTClient) get(ctx context.Context, u string) (*http.Response, error) {
+	for waits := 0; ; waits++ {
+		resp, err := client.Get(ctx, c, u, http.Header{
+			"Authorization": {"token " + c.token},
+			"Accept":        {"application/vnd.api+json"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %q: %v", u, err)
+		}
+		if resp.StatusCode == http.StatusOK {
+			return resp, nil
+		}
+
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusTooManyRequests || waits == maxRateLimitWaits {
+			return nil, fmt.Errorf("failed to get %q: %v", u, &client.Err{Code: resp.StatusCode, Status: resp.Status, Body: string(body)})
+		}
+
+		wait := client.RetryAfter(resp.Header, defaultRetryAfter)
+		logging.Infof("rate limited by snyk, retrying in %v", wait)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFetchOrgIssues(t *testing.T) {
+	var limited bool
+	var updatedAfter string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token secret" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Path != "/rest/orgs/org-1/issues" || r.URL.Query().Get("version") != RESTVersion {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.URL.Query().Get("starting_after") {
+		case "":
+			updatedAfter = r.URL.Query().Get("updated_after")
+			fmt.Fprint(w, `{"data": [{"id": "1", "attributes": {"key": "SNYK-JS-A-1"}}], "links": {"next": "/orgs/org-1/issues?version=`+RESTVersion+`&starting_after=p2"}}`)
+		case "p2":
+			if !limited {
+				limited = true
+				w.Header().Set("Retry-After", "0")
+				http.Error(w, "slow down", http.StatusTooManyRequests)
+				return
+			}
+			fmt.Fprint(w, `{"data": [{"id": "2", "attributes": {"key": "SNYK-JS-B-2"}}], "links": {"next": "/rest/orgs/org-1/issues?version=`+RESTVersion+`&starting_after=p3"}}`)
+		case "p3":
+			fmt.Fprint(w, `{"data": [{"id": "3", "attributes": {"key": "SNYK-JS-C-3"}}], "links": {}}`)
+		}
+	}))
+	defer ts.Close()
+
+	c := NewRESTClient(http.DefaultClient, ts.URL+"/rest/", "secret", "org-1")
+	since := time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC)
+	issues, err := c.FetchOrgIssues(context.Background(), since.Unix())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for issue := range issues {
+		ids = append(ids, issue.ID())
+	}
+	if want := []string{"SNYK-JS-A-1", "SNYK-JS-B-2", "SNYK-JS-C-3"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("expected %v, got %v", want, ids)
+	}
+	if !limited {
+		t.Fatal("rate limited request wasn't retried")
+	}
+	if updatedAfter != "2022-03-01T00:00:00Z" {
+		t.Fatalf("wrong updated_after %q", updatedAfter)
+	}
+
+	c = NewRESTClient(http.DefaultClient, ts.URL+"/rest", "wrong", "org-1")
+	if _, err := c.FetchOrgIssues(context.Background(), 0); err == nil {
+		t.Fatal("expected an error with a wrong token")
+	}
+}
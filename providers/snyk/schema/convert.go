@@ -15,9 +15,11 @@
 package schema
 
 import (
-	"log"
+	"fmt"
+	"strings"
 
 	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/wfn"
 )
 
@@ -113,22 +115,27 @@ func (advisory *Advisory) newReferences() *nvd.CVEJSON40References {
 }
 
 func (advisory *Advisory) newConfigurations() *nvd.NVDCVEFeedJSON10DefConfigurations {
+	return newConfigurations(advisory.SnykID, advisory.Package, advisory.VulnerableVersions)
+}
+
+// newConfigurations creates a configuration matching the given version ranges of the package
+func newConfigurations(id, pkg string, vulnerableVersions []string) *nvd.NVDCVEFeedJSON10DefConfigurations {
 	nodes := []*nvd.NVDCVEFeedJSON10DefNode{
 		&nvd.NVDCVEFeedJSON10DefNode{Operator: "OR"},
 	}
 	var err error
 	var product string
-	if product, err = wfn.WFNize(advisory.Package); err != nil {
-		log.Printf("can't wfnize %q\n", advisory.Package)
-		product = advisory.Package
+	if product, err = wfn.WFNize(pkg); err != nil {
+		logging.Recordf("can't wfnize %q", pkg)
+		product = pkg
 	}
 	cpe := wfn.Attributes{Part: "a", Product: product}
 	cpe22URI := cpe.BindToURI()
 	cpe23URI := cpe.BindToFmtString()
-	for _, versions := range advisory.VulnerableVersions {
+	for _, versions := range vulnerableVersions {
 		vRanges, err := parseVersionRange(versions)
 		if err != nil {
-			log.Printf("could not generate configuration for item %s, vulnerable ver %q: %v", advisory.SnykID, versions, err)
+			logging.Recordf("could not generate configuration for item %s, vulnerable ver %q: %v", id, versions, err)
 			continue
 		}
 		for _, vRange := range vRanges {
@@ -153,3 +160,134 @@ func (advisory *Advisory) newConfigurations() *nvd.NVDCVEFeedJSON10DefConfigurat
 		Nodes: nodes,
 	}
 }
+
+// Convert converts a REST API issue into NVD CVE item
+func (issue *Issue) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	attrs := issue.Attributes
+	if attrs == nil {
+		return nil, fmt.Errorf("issue %s has no attributes", issue.IssueID)
+	}
+	description := attrs.Description
+	if description == "" {
+		description = attrs.Title
+	}
+
+	nvdItem := nvd.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &nvd.CVEJSON40{
+			CVEDataMeta: &nvd.CVEJSON40CVEDataMeta{
+				ID:       issue.ID(),
+				ASSIGNER: "snyk.io",
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: cveDataVersion,
+			Description: &nvd.CVEJSON40Description{
+				DescriptionData: []*nvd.CVEJSON40LangString{
+					{
+						Lang:  "en",
+						Value: description,
+					},
+				},
+			},
+			Problemtype: issue.newProblemType(),
+			References:  issue.newReferences(),
+		},
+		Configurations:   issue.newConfigurations(),
+		Impact:           issue.newImpact(),
+		LastModifiedDate: snykTimeToNVD(attrs.UpdatedAt),
+		PublishedDate:    snykTimeToNVD(attrs.CreatedAt),
+	}
+
+	return &nvdItem, nil
+}
+
+// ID returns the Snyk key of the issue (e.g. SNYK-JS-LODASH-567746), or its resource id if the key isn't set
+func (issue *Issue) ID() string {
+	if issue.Attributes != nil && issue.Attributes.Key != "" {
+		return issue.Attributes.Key
+	}
+	return issue.IssueID
+}
+
+func (issue *Issue) newProblemType() *nvd.CVEJSON40Problemtype {
+	var descs []*nvd.CVEJSON40LangString
+	for _, p := range issue.Attributes.Problems {
+		if p.Source == "CWE" {
+			descs = append(descs, &nvd.CVEJSON40LangString{Lang: "en", Value: p.ID})
+		}
+	}
+	if len(descs) == 0 {
+		return nil
+	}
+	return &nvd.CVEJSON40Problemtype{
+		ProblemtypeData: []*nvd.CVEJSON40ProblemtypeProblemtypeData{
+			{Description: descs},
+		},
+	}
+}
+
+func (issue *Issue) newReferences() *nvd.CVEJSON40References {
+	var refs []*nvd.CVEJSON40Reference
+	for _, p := range issue.Attributes.Problems {
+		if p.Source == "CWE" {
+			continue
+		}
+		refs = append(refs, &nvd.CVEJSON40Reference{Name: p.ID, URL: p.URL})
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	return &nvd.CVEJSON40References{ReferenceData: refs}
+}
+
+// newImpact uses the CVSS v3 score assessed by Snyk, or by any other source if Snyk didn't provide one
+func (issue *Issue) newImpact() *nvd.NVDCVEFeedJSON10DefImpact {
+	var sev *Severity
+	for _, s := range issue.Attributes.Severities {
+		if s.Score == nil || !strings.HasPrefix(s.Vector, "CVSS:3") {
+			continue
+		}
+		if sev == nil || s.Source == "Snyk" {
+			sev = s
+		}
+	}
+	if sev == nil {
+		return nil
+	}
+	return &nvd.NVDCVEFeedJSON10DefImpact{
+		BaseMetricV3: &nvd.NVDCVEFeedJSON10DefImpactBaseMetricV3{
+			CVSSV3: &nvd.CVSSV30{
+				VectorString: sev.Vector,
+				BaseScore:    *sev.Score,
+			},
+		},
+	}
+}
+
+// newConfigurations matches the vulnerable version ranges if the issue has them, or the exact versions
+// of the dependencies the issue was found in otherwise
+func (issue *Issue) newConfigurations() *nvd.NVDCVEFeedJSON10DefConfigurations {
+	var pkg string
+	var ranges, versions []string
+	for _, c := range issue.Attributes.Coordinates {
+		ranges = append(ranges, c.Representation...)
+		for _, r := range c.Representations {
+			if r.Dependency == nil {
+				continue
+			}
+			if pkg == "" {
+				pkg = r.Dependency.PackageName
+			}
+			if r.Dependency.PackageName == pkg && r.Dependency.PackageVersion != "" {
+				versions = append(versions, "="+r.Dependency.PackageVersion)
+			}
+		}
+	}
+	if pkg == "" {
+		return nil
+	}
+	if len(ranges) == 0 {
+		ranges = versions
+	}
+	return newConfigurations(issue.ID(), pkg, ranges)
+}
@@ -0,0 +1,120 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// IssuesResponse is a page of issues returned by the Snyk REST API, formatted as JSON:API document
+type IssuesResponse struct {
+	Data  []*Issue `json:"data"`
+	Links *Links   `json:"links,omitempty"`
+}
+
+// Links contains pagination links of a JSON:API document
+type Links struct {
+	Next string `json:"next,omitempty"`
+}
+
+// Issue is a Snyk REST API issue resource
+type Issue struct {
+	IssueID    string           `json:"id"`
+	Type       string           `json:"type"`
+	Attributes *IssueAttributes `json:"attributes"`
+}
+
+// IssueAttributes describes an issue
+type IssueAttributes struct {
+	Key                    string        `json:"key"`
+	Title                  string        `json:"title"`
+	Type                   string        `json:"type"`
+	Description            string        `json:"description,omitempty"`
+	CreatedAt              string        `json:"created_at"`
+	UpdatedAt              string        `json:"updated_at"`
+	EffectiveSeverityLevel string        `json:"effective_severity_level"`
+	Problems               []*Problem    `json:"problems,omitempty"`
+	Coordinates            []*Coordinate `json:"coordinates,omitempty"`
+	Severities             []*Severity   `json:"severities,omitempty"`
+}
+
+// Problem is an identifier of the issue in some source, e.g. CVE or CWE
+type Problem struct {
+	ID     string `json:"id"`
+	Source string `json:"source"`
+	URL    string `json:"url,omitempty"`
+}
+
+// Coordinate describes where the issue is located and how to remediate it
+type Coordinate struct {
+	Remedies []*Remedy `json:"remedies,omitempty"`
+	// Representation contains vulnerable version ranges, it's set by the package issues endpoints
+	Representation []string `json:"representation,omitempty"`
+	// Representations contains dependencies the issue was found in, it's set by the org issues endpoints
+	Representations []*Representation `json:"representations,omitempty"`
+}
+
+// Remedy describes how to fix the issue
+type Remedy struct {
+	Type        string         `json:"type"`
+	Description string         `json:"description,omitempty"`
+	Details     *RemedyDetails `json:"details,omitempty"`
+}
+
+// RemedyDetails contains the package version fixing the issue
+type RemedyDetails struct {
+	UpgradePackage string `json:"upgrade_package,omitempty"`
+}
+
+// Representation is a location of the issue
+type Representation struct {
+	Dependency *Dependency `json:"dependency,omitempty"`
+}
+
+// Dependency is a package in a specific version
+type Dependency struct {
+	PackageName    string `json:"package_name"`
+	PackageVersion string `json:"package_version"`
+}
+
+// Severity is a severity of the issue assessed by some source
+type Severity struct {
+	Source string   `json:"source"`
+	Level  string   `json:"level"`
+	Score  *float64 `json:"score,omitempty"`
+	Vector string   `json:"vector,omitempty"`
+}
+
+// Language returns the ecosystem of the issue, as used by the legacy feed, e.g. js or python
+// it's taken from the issue key, e.g. SNYK-JS-LODASH-567746
+func (issue *Issue) Language() string {
+	if issue.Attributes == nil {
+		return ""
+	}
+	parts := strings.SplitN(issue.Attributes.Key, "-", 3)
+	if len(parts) < 3 || parts[0] != "SNYK" {
+		return ""
+	}
+	return strings.ToLower(parts[1])
+}
+
+// IsIssue returns true if the JSON object looks like an Issue rather than a legacy Advisory
+func IsIssue(data json.RawMessage) bool {
+	var probe struct {
+		Attributes json.RawMessage `json:"attributes"`
+	}
+	return json.Unmarshal(data, &probe) == nil && len(probe.Attributes) != 0
+}
@@ -0,0 +1,109 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+const testIssue = `{
+	"id": "4a18d42f-0706-4ad0-b127-24078731fbed",
+	"type": "issue",
+	"attributes": {
+		"key": "SNYK-JS-LODASH-567746",
+		"title": "Prototype Pollution",
+		"type": "package_vulnerability",
+		"created_at": "2020-04-28T14:32:13.683154Z",
+		"updated_at": "2022-03-01T09:11:05Z",
+		"effective_severity_level": "high",
+		"problems": [
+			{"id": "CVE-2020-8203", "source": "NVD", "url": "https://nvd.nist.gov/vuln/detail/CVE-2020-8203"},
+			{"id": "CWE-400", "source": "CWE"}
+		],
+		"coordinates": [
+			{"representations": [{"dependency": {"package_name": "lodash", "package_version": "4.17.15"}}]},
+			{"representation": ["<4.17.16", ">=5.0.0 <5.0.1"]}
+		],
+		"severities": [
+			{"source": "NVD", "level": "high", "score": 7.4, "vector": "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:N/I:H/A:H"},
+			{"source": "Snyk", "level": "high", "score": 7.3, "vector": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:L/I:L/A:L"}
+		]
+	}
+}`
+
+func TestIssueConvert(t *testing.T) {
+	if !IsIssue(json.RawMessage(testIssue)) {
+		t.Fatal("issue not detected")
+	}
+	if IsIssue(json.RawMessage(`{"id": "SNYK-JS-LODASH-567746", "package": "lodash"}`)) {
+		t.Fatal("advisory detected as issue")
+	}
+
+	var issue Issue
+	if err := json.Unmarshal([]byte(testIssue), &issue); err != nil {
+		t.Fatal(err)
+	}
+	if lang := issue.Language(); lang != "js" {
+		t.Fatalf("wrong language %q", lang)
+	}
+
+	item, err := issue.Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := item.CVE.CVEDataMeta.ID; id != "SNYK-JS-LODASH-567746" {
+		t.Fatalf("wrong id %q", id)
+	}
+	if d := item.CVE.Description.DescriptionData[0].Value; d != "Prototype Pollution" {
+		t.Fatalf("description should fall back to the title, got %q", d)
+	}
+	if cwe := item.CVE.Problemtype.ProblemtypeData[0].Description[0].Value; cwe != "CWE-400" {
+		t.Fatalf("wrong cwe %q", cwe)
+	}
+	if refs := item.CVE.References.ReferenceData; len(refs) != 1 || refs[0].Name != "CVE-2020-8203" {
+		t.Fatalf("wrong references %+v", refs)
+	}
+	if score := item.Impact.BaseMetricV3.CVSSV3.BaseScore; score != 7.3 {
+		t.Fatalf("snyk's score should be preferred, got %v", score)
+	}
+	if item.PublishedDate != "2020-04-28T14:32Z" || item.LastModifiedDate != "2022-03-01T09:11Z" {
+		t.Fatalf("wrong dates %q, %q", item.PublishedDate, item.LastModifiedDate)
+	}
+
+	type bounds struct{ startIncl, endExcl string }
+	var got []bounds
+	for _, m := range item.Configurations.Nodes[0].CPEMatch {
+		if m.Cpe23Uri != "cpe:2.3:a:*:lodash:*:*:*:*:*:*:*:*" {
+			t.Fatalf("wrong cpe %q", m.Cpe23Uri)
+		}
+		got = append(got, bounds{m.VersionStartIncluding, m.VersionEndExcluding})
+	}
+	want := []bounds{{"", "4.17.16"}, {"5.0.0", "5.0.1"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("wrong version ranges:\nexpected: %+v\ngot: %+v", want, got)
+	}
+
+	// without ranges, versions of the dependencies are used
+	issue.Attributes.Coordinates = issue.Attributes.Coordinates[:1]
+	if item, err = issue.Convert(); err != nil {
+		t.Fatal(err)
+	}
+	m := item.Configurations.Nodes[0].CPEMatch
+	if len(m) != 1 || m[0].VersionStartIncluding != "4.17.15" || m[0].VersionEndIncluding != "4.17.15" {
+		t.Fatalf("expected a match of the dependency version, got %+v", m)
+	}
+}
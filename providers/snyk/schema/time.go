@@ -15,15 +15,16 @@
 package schema
 
 import (
-	"log"
 	"time"
 
 	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
 )
 
 var snykLayouts = []string{
 	"2006-01-02T15:04:05Z",
 	"2006-01-02T15:04:05.000000Z",
+	time.RFC3339Nano,
 }
 
 func snykTimeToNVD(s string) string {
@@ -37,6 +38,6 @@ func snykTimeToNVD(s string) string {
 		}
 	}
 
-	log.Printf("cannot parse snyk time: %v", err)
+	logging.Recordf("cannot parse snyk time: %v", err)
 	return s
 }
@@ -19,6 +19,8 @@ import (
 	"regexp"
 	"strings"
 	"unicode"
+
+	"github.com/facebookincubator/nvdtools/semver"
 )
 
 type versionRange struct {
@@ -95,39 +97,50 @@ func parseParenRanges(s string) (vr []versionRange, err error) {
 	return vr, nil
 }
 
+var (
+	cmpRe    = regexp.MustCompile(`(<=|>=|<|>|=|\^|~>|~)?\s*([^\s,<>=^~]+)`)
+	hyphenRe = regexp.MustCompile(`^(\S+)\s+-\s+(\S+)$`)
+)
+
 // parseCmpRange parses a sequence of intervals described as comparison operators (<=, <, =, >, >=);
 // ranges can be combined with || operators for boolean OR logic.
+// Semver shorthands used by Snyk are expanded into the bounds they stand for: caret (^1.2.3) and tilde
+// (~1.2.3, ~>1.2) ranges, x-ranges (1.2.x, *) and hyphen ranges (1.2.3 - 2.0.0).
+// A version without an operator means equality.
 func parseCmpRanges(s string) (vr []versionRange, err error) {
-	re := regexp.MustCompile(`([<>](:?=)?)\s*(\S+)`)
-	ss := strings.Split(s, "||")
-	for _, rs := range ss {
-		var r versionRange
+	for _, rs := range strings.Split(s, "||") {
+		rs = strings.TrimSpace(rs)
 		if rs == "" {
 			continue
 		}
-		rs = strings.TrimSpace(rs)
-		// first, check if it is just an equality check
-		if rs[0] == '=' {
-			r.maxVerIncl = strings.TrimSpace(rs[1:])
-			r.minVerIncl = r.maxVerIncl
+		var r versionRange
+		if m := hyphenRe.FindStringSubmatch(rs); m != nil {
+			r.minVerIncl, r.maxVerIncl = m[1], m[2]
 			vr = append(vr, r)
 			continue
 		}
-		// then process the range
-		matches := re.FindAllString(strings.TrimSpace(rs), -1)
-		for _, match := range matches {
-			switch match[0] {
-			case '<':
-				if match[1] == '=' {
-					r.maxVerIncl = strings.TrimSpace(match[2:])
+		for _, m := range cmpRe.FindAllStringSubmatch(rs, -1) {
+			op, ver := m[1], m[2]
+			switch op {
+			case "<":
+				r.maxVerExcl = ver
+			case "<=":
+				r.maxVerIncl = ver
+			case ">":
+				r.minVerExcl = ver
+			case ">=":
+				r.minVerIncl = ver
+			case "^":
+				r.minVerIncl, r.maxVerExcl = ver, caretUpperBound(ver)
+			case "~":
+				r.minVerIncl, r.maxVerExcl = ver, tildeUpperBound(ver)
+			case "~>":
+				r.minVerIncl, r.maxVerExcl = ver, pessimisticUpperBound(ver)
+			default:
+				if lower, upper, ok := xRange(ver); ok {
+					r.minVerIncl, r.maxVerExcl = lower, upper
 				} else {
-					r.maxVerExcl = strings.TrimSpace(match[1:])
-				}
-			case '>':
-				if match[1] == '=' {
-					r.minVerIncl = strings.TrimSpace(match[2:])
-				} else {
-					r.minVerExcl = strings.TrimSpace(match[1:])
+					r.minVerIncl, r.maxVerIncl = ver, ver
 				}
 			}
 		}
@@ -135,3 +148,66 @@ func parseCmpRanges(s string) (vr []versionRange, err error) {
 	}
 	return vr, nil
 }
+
+// caretUpperBound returns the exclusive upper bound of ^ver: changes which don't modify the
+// left-most non-zero part are allowed
+func caretUpperBound(ver string) string {
+	v, err := semver.Parse(ver)
+	if err != nil {
+		return ""
+	}
+	parts := []uint64{v.Major, v.Minor, v.Patch}[:v.Parts]
+	for i, p := range parts {
+		if p != 0 || i == len(parts)-1 {
+			return v.Bump(i).String()
+		}
+	}
+	return ""
+}
+
+// tildeUpperBound returns the exclusive upper bound of ~ver: patch level changes are allowed
+// if minor version is given, minor level changes otherwise
+func tildeUpperBound(ver string) string {
+	v, err := semver.Parse(ver)
+	if err != nil {
+		return ""
+	}
+	if v.Parts == 1 {
+		return v.Bump(0).String()
+	}
+	return v.Bump(1).String()
+}
+
+// pessimisticUpperBound returns the exclusive upper bound of ruby's ~>ver: only the last given
+// part is allowed to change
+func pessimisticUpperBound(ver string) string {
+	v, err := semver.Parse(ver)
+	if err != nil {
+		return ""
+	}
+	if v.Parts == 1 {
+		return v.Bump(0).String()
+	}
+	return v.Bump(v.Parts - 2).String()
+}
+
+// xRange returns bounds of versions like 1.x, 1.2.* or *; ok is false if the version isn't an x-range
+func xRange(ver string) (lower, upper string, ok bool) {
+	var parts []string
+	for _, p := range strings.Split(ver, ".") {
+		if p == "x" || p == "X" || p == "*" {
+			ok = true
+			break
+		}
+		parts = append(parts, p)
+	}
+	if !ok || len(parts) == 0 {
+		// * matches everything, which is a range without bounds
+		return "", "", ok
+	}
+	v, err := semver.Parse(strings.Join(parts, "."))
+	if err != nil || len(v.Prerelease) != 0 || v.Build != "" {
+		return "", "", false
+	}
+	return v.String(), v.Bump(v.Parts - 1).String(), true
+}
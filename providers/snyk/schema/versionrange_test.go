@@ -86,6 +86,18 @@ func TestParseCmpRanges(t *testing.T) {
 		{">=3.0.0  <3.0.1", []versionRange{{minVerIncl: "3.0.0", maxVerExcl: "3.0.1"}}},
 		{">3.0.0  <=3.0.1", []versionRange{{minVerExcl: "3.0.0", maxVerIncl: "3.0.1"}}},
 		{"=3.0.0-rc.1", []versionRange{{minVerIncl: "3.0.0-rc.1", maxVerIncl: "3.0.0-rc.1"}}},
+		{"1.2.3", []versionRange{{minVerIncl: "1.2.3", maxVerIncl: "1.2.3"}}},
+		{">=1.0, <2.0", []versionRange{{minVerIncl: "1.0", maxVerExcl: "2.0"}}},
+		{"^1.2.3", []versionRange{{minVerIncl: "1.2.3", maxVerExcl: "2.0.0"}}},
+		{"^0.2.3", []versionRange{{minVerIncl: "0.2.3", maxVerExcl: "0.3.0"}}},
+		{"^0.0.3", []versionRange{{minVerIncl: "0.0.3", maxVerExcl: "0.0.4"}}},
+		{"~1.2.3", []versionRange{{minVerIncl: "1.2.3", maxVerExcl: "1.3.0"}}},
+		{"~1", []versionRange{{minVerIncl: "1", maxVerExcl: "2"}}},
+		{"~>1.2", []versionRange{{minVerIncl: "1.2", maxVerExcl: "2.0"}}},
+		{"~> 1.2.3", []versionRange{{minVerIncl: "1.2.3", maxVerExcl: "1.3.0"}}},
+		{"1.2.x", []versionRange{{minVerIncl: "1.2", maxVerExcl: "1.3"}}},
+		{"*", []versionRange{{}}},
+		{"1.2.3 - 2.3.4", []versionRange{{minVerIncl: "1.2.3", maxVerIncl: "2.3.4"}}},
 		{
 			in: "< 1.12.4 || >= 2.0.0 <2.0.2",
 			out: []versionRange{
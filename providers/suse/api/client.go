@@ -0,0 +1,187 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+	"github.com/facebookincubator/nvdtools/providers/suse/schema"
+)
+
+const (
+	// DefaultWorkers is the default number of advisories fetched concurrently
+	DefaultWorkers = 10
+	// changes.csv lists all advisories with the time of the last change
+	changesPath = "/changes.csv"
+	// index.txt lists all advisories, it's used in directories without changes.csv
+	indexPath = "/index.txt"
+)
+
+// Client downloads SUSE CSAF or CVRF advisories
+type Client struct {
+	client.Client
+	baseURL string
+	workers int
+}
+
+// NewClient creates an object which is used to download SUSE CSAF advisories
+// base url should point to the CSAF directory, e.g. https://ftp.suse.com/pub/projects/security/csaf
+// or to the CVRF directory, e.g. https://ftp.suse.com/pub/projects/security/cvrf
+func NewClient(c client.Client, baseURL string) *Client {
+	return &Client{
+		Client:  c,
+		baseURL: baseURL,
+		workers: DefaultWorkers,
+	}
+}
+
+// SetWorkers sets the number of advisories which are fetched concurrently
+func (c *Client) SetWorkers(workers int) {
+	if workers > 0 {
+		c.workers = workers
+	}
+}
+
+// FetchAllAdvisories will fetch all advisories changed since the given time
+func (c *Client) FetchAllAdvisories(ctx context.Context, since int64) (<-chan runner.Convertible, error) {
+	paths, err := c.listAdvisories(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(chan string)
+	go func() {
+		defer close(ids)
+		for _, path := range paths {
+			ids <- path
+		}
+	}()
+
+	output := make(chan runner.Convertible)
+	wg := sync.WaitGroup{}
+	for i := 0; i < c.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range ids {
+				logging.Infof("fetching %s", path)
+				adv, err := c.fetchAdvisory(ctx, path)
+				if err != nil {
+					logging.Errorf("error while fetching %s: %v", path, err)
+					continue
+				}
+				output <- adv
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(output)
+	}()
+
+	return output, nil
+}
+
+// listAdvisories returns paths of advisories changed since the given time
+// all advisories from index.txt are returned if the directory doesn't have changes.csv
+func (c *Client) listAdvisories(ctx context.Context, since int64) ([]string, error) {
+	resp, err := client.Get(ctx, c, c.baseURL+changesPath, http.Header{})
+	if err == nil && resp.StatusCode == http.StatusOK {
+		defer resp.Body.Close()
+		return parseChanges(resp.Body, since)
+	}
+	if err == nil {
+		resp.Body.Close()
+	}
+	logging.Infof("can't fetch list of changed advisories, fetching all advisories from %s", indexPath)
+
+	resp, err = client.Get(ctx, c, c.baseURL+indexPath, http.Header{})
+	if err != nil {
+		return nil, fmt.Errorf("can't fetch list of advisories: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("can't fetch list of advisories: %s", resp.Status)
+	}
+	return parseIndex(resp.Body)
+}
+
+func (c *Client) fetchAdvisory(ctx context.Context, path string) (*schema.Advisory, error) {
+	resp, err := client.Get(ctx, c, c.baseURL+"/"+path, http.Header{})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	adv, err := schema.ReadAdvisory(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("can't decode advisory: %v", err)
+	}
+	return adv, nil
+}
+
+// parseChanges parses lines in the following format
+// "suse-su-2023_0001-1.json","2023-01-02T12:00:00Z"
+func parseChanges(r io.Reader, since int64) ([]string, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 2
+
+	var paths []string
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("can't read changes: %v", err)
+		}
+		changed, err := time.Parse(time.RFC3339, record[1])
+		if err != nil {
+			return nil, fmt.Errorf("can't parse time of change for %q: %v", record[0], err)
+		}
+		if changed.Unix() < since {
+			continue
+		}
+		paths = append(paths, record[0])
+	}
+
+	return paths, nil
+}
+
+// parseIndex parses lines with advisory paths, e.g. cvrf-suse-su-2023_0001-1.xml
+func parseIndex(r io.Reader) ([]string, error) {
+	var paths []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if path := strings.TrimSpace(scanner.Text()); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("can't read index: %v", err)
+	}
+	return paths, nil
+}
@@ -0,0 +1,89 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package suse
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/providers/suse/schema"
+	"github.com/facebookincubator/nvdtools/rpm"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// Feed is a collection of advisories, keyed by the advisory id
+type Feed map[string]*schema.Advisory
+
+// LoadFeed loads a feed downloaded by suse2nvd
+func LoadFeed(path string) (Feed, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open file %q: %v", path, err)
+	}
+	defer f.Close()
+	return loadFeed(f)
+}
+
+func loadFeed(r io.Reader) (Feed, error) {
+	var feed Feed
+	if err := json.NewDecoder(r).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("can't decode feed: %v", err)
+	}
+	return feed, nil
+}
+
+// Checker returns a checker which knows whether a package on some SUSE distribution has been fixed
+func (feed Feed) Checker() (rpm.Checker, error) {
+	return NewPackageFeed(feed)
+}
+
+// DistroCPE returns the CPE of the distribution as used in SUSE advisories
+// id and version are ID and VERSION_ID from /etc/os-release, e.g. sles and 15.4 or opensuse-leap and 15.4
+func DistroCPE(id, version string) (*wfn.Attributes, error) {
+	b := wfn.NewAttributesBuilder().Part("o")
+	switch id {
+	case "sles", "sled", "sles_sap":
+		// service packs are stored as update, e.g. cpe:/o:suse:sles:15:sp4
+		parts := strings.SplitN(version, ".", 2)
+		b.Vendor("suse").Product(id).Version(parts[0])
+		if len(parts) == 2 && parts[1] != "0" {
+			b.Update("sp" + parts[1])
+		}
+	case "opensuse-leap":
+		b.Vendor("opensuse").Product("leap").Version(version)
+	case "opensuse-tumbleweed":
+		b.Vendor("opensuse").Product("tumbleweed")
+	default:
+		return nil, fmt.Errorf("unknown distribution %q", id)
+	}
+	return b.Build()
+}
+
+func parseDistro(cpe string) (*wfn.Attributes, error) {
+	d, err := wfn.Parse(cpe)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse distro cpe %q: %v", cpe, err)
+	}
+	d.Part = wfn.Any
+	// packages on SLE are shipped in modules, e.g. cpe:/o:suse:sle-module-basesystem:15:sp4
+	// modules are shared between all SLE products of the same version
+	if strings.HasPrefix(wfn.StripSlashes(d.Product), "sle-module-") {
+		d.Product = wfn.Any
+	}
+	return d, nil
+}
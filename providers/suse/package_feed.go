@@ -0,0 +1,79 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package suse
+
+import (
+	"fmt"
+
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/pkgfeed"
+	"github.com/facebookincubator/nvdtools/providers/suse/schema"
+	"github.com/facebookincubator/nvdtools/rpm"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// PackageFeed indexes the feed by package names
+// it can be used to list CVEs which are fixed for some installed package
+type PackageFeed struct {
+	feed *pkgfeed.Feed
+}
+
+// NewPackageFeed creates a new package feed from the given feed
+func NewPackageFeed(feed Feed) (*PackageFeed, error) {
+	pf, err := pkgfeed.New(func(add func(interface{}, pkgfeed.Fix)) error {
+		for id, adv := range feed {
+			for _, fp := range adv.FixedPackages() {
+				if fp.CPE == "" {
+					continue
+				}
+				distro, err := parseDistro(fp.CPE)
+				if err != nil {
+					return fmt.Errorf("can't index %s: %v", id, err)
+				}
+				pkg, err := schema.ParsePackage(fp.Package)
+				if err != nil {
+					logging.Recordf("can't index package for %s: %v", id, err)
+					continue
+				}
+				add(pkg.Name, &pkgfeed.RPMFix{ID: fp.CVE, Distro: distro, Label: pkg.Label})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &PackageFeed{feed: pf}, nil
+}
+
+// LoadPackageFeed loads the feed from the given path and indexes it by packages
+func LoadPackageFeed(path string) (*PackageFeed, error) {
+	feed, err := LoadFeed(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewPackageFeed(feed)
+}
+
+// ListFixedCVEs returns sorted list of CVEs which are fixed for the given package on the given distro
+// distro can be created with DistroCPE
+func (pf *PackageFeed) ListFixedCVEs(distro *wfn.Attributes, pkg *rpm.Package) []string {
+	return pf.feed.ListFixedCVEs(pkgfeed.RPMPackage{Package: pkg, Distro: distro}, pkg.Name)
+}
+
+// Check is part of the rpm.Checker interface
+func (pf *PackageFeed) Check(pkg *rpm.Package, distro *wfn.Attributes, cve string) bool {
+	return pf.feed.Check(pkgfeed.RPMPackage{Package: pkg, Distro: distro}, cve, pkg.Name)
+}
@@ -0,0 +1,317 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package suse
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/providers/suse/schema"
+	"github.com/facebookincubator/nvdtools/rpm"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestPackageFeedListFixedCVEs(t *testing.T) {
+	feed, err := loadFeed(strings.NewReader(testFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pf, err := NewPackageFeed(feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sles154, err := DistroCPE("sles", "15.4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sles153, err := DistroCPE("sles", "15.3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	leap154, err := DistroCPE("opensuse-leap", "15.4")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tc := range []struct {
+		distro *wfn.Attributes
+		pkg    string
+		expect []string
+	}{
+		{sles154, "libopenssl1_1-1.1.1l-150400.7.28.1.x86_64", []string{"CVE-2023-0286", "CVE-2023-0464"}},
+		{sles154, "libopenssl1_1-1.1.1l-150400.7.30.1.x86_64", []string{"CVE-2023-0286", "CVE-2023-0464"}},
+		{sles154, "libopenssl1_1-1.1.1l-150400.7.25.1.x86_64", []string{}},
+		{sles153, "libopenssl1_1-1.1.1l-150400.7.28.1.x86_64", []string{}},
+		{leap154, "libopenssl1_1-1.1.1l-150400.7.28.1.aarch64", []string{"CVE-2023-0286", "CVE-2023-0464"}},
+		{nil, "openssl-1_1-1.1.1l-150400.7.28.1.x86_64", []string{"CVE-2023-0286", "CVE-2023-0464"}},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			pkg, err := rpm.Parse(tc.pkg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := pf.ListFixedCVEs(tc.distro, pkg); !reflect.DeepEqual(got, tc.expect) {
+				t.Fatalf("expecting %v, got %v", tc.expect, got)
+			}
+		})
+	}
+
+	fixed, err := rpm.Check(pf, "libopenssl1_1-1.1.1l-150400.7.28.1.x86_64", "cpe:/o:suse:sles:15:sp4", "CVE-2023-0286")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fixed {
+		t.Fatal("package should be fixed")
+	}
+}
+
+func TestConvert(t *testing.T) {
+	feed, err := loadFeed(strings.NewReader(testFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	item, err := feed["SUSE-SU-2023:0598-1"].Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.CVE.CVEDataMeta.ID != "SUSE-SU-2023:0598-1" {
+		t.Fatalf("wrong id %q", item.CVE.CVEDataMeta.ID)
+	}
+	if score := item.Impact.BaseMetricV3.CVSSV3.BaseScore; score != 7.4 {
+		t.Fatalf("wrong base score %.1f", score)
+	}
+	// 3 packages on 2 distributions
+	if n := len(item.Configurations.Nodes); n != 6 {
+		t.Fatalf("expecting 6 nodes, got %d", n)
+	}
+}
+
+func TestCVRF(t *testing.T) {
+	adv, err := schema.ReadAdvisory(strings.NewReader(testCVRF))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := adv.ID(); id != "SUSE-SU-2023:0598-1" {
+		t.Fatalf("wrong id %q", id)
+	}
+
+	var got []string
+	for _, fp := range adv.FixedPackages() {
+		got = append(got, fmt.Sprintf("%s %s %s", fp.CVE, fp.CPE, fp.Package))
+	}
+	expect := []string{
+		"CVE-2023-0286 cpe:/o:opensuse:leap:15.4 libopenssl1_1-1.1.1l-150400.7.28.1",
+		"CVE-2023-0286 cpe:/o:suse:sle-module-basesystem:15:sp4 libopenssl1_1-1.1.1l-150400.7.28.1",
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Fatalf("expecting %v, got %v", expect, got)
+	}
+
+	item, err := adv.Convert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc := item.CVE.Description.DescriptionData[0].Value; desc != "This update for openssl-1_1 fixes the following issues" {
+		t.Fatalf("wrong description %q", desc)
+	}
+	if score := item.Impact.BaseMetricV3.CVSSV3.BaseScore; score != 7.4 {
+		t.Fatalf("wrong base score %.1f", score)
+	}
+
+	pf, err := NewPackageFeed(Feed{adv.ID(): adv})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixed, err := rpm.Check(pf, "libopenssl1_1-1.1.1l-150400.7.28.1.x86_64", "cpe:/o:suse:sles:15:sp4", "CVE-2023-0286")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fixed {
+		t.Fatal("package should be fixed")
+	}
+}
+
+func TestDistroCPE(t *testing.T) {
+	for i, tc := range []struct {
+		id, version string
+		expect      string
+	}{
+		{"sles", "15.4", "cpe:/o:suse:sles:15:sp4"},
+		{"sles", "15", "cpe:/o:suse:sles:15"},
+		{"sled", "12.5", "cpe:/o:suse:sled:12:sp5"},
+		{"opensuse-leap", "15.5", "cpe:/o:opensuse:leap:15.5"},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			attrs, err := DistroCPE(tc.id, tc.version)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := attrs.BindToURI(); got != tc.expect {
+				t.Fatalf("expecting %s, got %s", tc.expect, got)
+			}
+		})
+	}
+	if _, err := DistroCPE("ubuntu", "22.04"); err == nil {
+		t.Fatal("expecting an error for unknown distribution")
+	}
+}
+
+var testFeed = `{
+  "SUSE-SU-2023:0598-1": {
+    "document": {
+      "category": "csaf_security_advisory",
+      "title": "Security update for openssl-1_1",
+      "notes": [{"category": "description", "text": "This update for openssl-1_1 fixes the following issues", "title": "Description of the patch"}],
+      "references": [{"category": "self", "summary": "URL of this CSAF notice", "url": "https://ftp.suse.com/pub/projects/security/csaf/suse-su-2023_0598-1.json"}],
+      "tracking": {"id": "SUSE-SU-2023:0598-1", "initial_release_date": "2023-03-01T12:00:00Z", "current_release_date": "2023-03-01T12:00:00Z"}
+    },
+    "product_tree": {
+      "branches": [{"category": "vendor", "name": "SUSE", "branches": [
+        {"category": "architecture", "name": "x86_64", "branches": [
+          {"category": "product_version", "name": "libopenssl1_1-1.1.1l-150400.7.28.1.x86_64", "product": {"name": "libopenssl1_1-1.1.1l-150400.7.28.1.x86_64", "product_id": "libopenssl1_1-1.1.1l-150400.7.28.1.x86_64"}},
+          {"category": "product_version", "name": "openssl-1_1-1.1.1l-150400.7.28.1.x86_64", "product": {"name": "openssl-1_1-1.1.1l-150400.7.28.1.x86_64", "product_id": "openssl-1_1-1.1.1l-150400.7.28.1.x86_64"}}
+        ]},
+        {"category": "architecture", "name": "aarch64", "branches": [
+          {"category": "product_version", "name": "libopenssl1_1-1.1.1l-150400.7.28.1.aarch64", "product": {"name": "libopenssl1_1-1.1.1l-150400.7.28.1.aarch64", "product_id": "libopenssl1_1-1.1.1l-150400.7.28.1.aarch64"}}
+        ]},
+        {"category": "product_family", "name": "SUSE Linux Enterprise", "branches": [
+          {"category": "product_name", "name": "SUSE Linux Enterprise Module for Basesystem 15 SP4", "product": {
+            "name": "SUSE Linux Enterprise Module for Basesystem 15 SP4", "product_id": "SUSE Linux Enterprise Module for Basesystem 15 SP4",
+            "product_identification_helper": {"cpe": "cpe:/o:suse:sle-module-basesystem:15:sp4"}}},
+          {"category": "product_name", "name": "openSUSE Leap 15.4", "product": {
+            "name": "openSUSE Leap 15.4", "product_id": "openSUSE Leap 15.4",
+            "product_identification_helper": {"cpe": "cpe:/o:opensuse:leap:15.4"}}}
+        ]}
+      ]}],
+      "relationships": [
+        {"category": "default_component_of", "product_reference": "libopenssl1_1-1.1.1l-150400.7.28.1.x86_64", "relates_to_product_reference": "SUSE Linux Enterprise Module for Basesystem 15 SP4",
+         "full_product_name": {"name": "libopenssl1_1 as component of SLE Basesystem 15 SP4", "product_id": "SUSE Linux Enterprise Module for Basesystem 15 SP4:libopenssl1_1-1.1.1l-150400.7.28.1.x86_64"}},
+        {"category": "default_component_of", "product_reference": "openssl-1_1-1.1.1l-150400.7.28.1.x86_64", "relates_to_product_reference": "SUSE Linux Enterprise Module for Basesystem 15 SP4",
+         "full_product_name": {"name": "openssl-1_1 as component of SLE Basesystem 15 SP4", "product_id": "SUSE Linux Enterprise Module for Basesystem 15 SP4:openssl-1_1-1.1.1l-150400.7.28.1.x86_64"}},
+        {"category": "default_component_of", "product_reference": "libopenssl1_1-1.1.1l-150400.7.28.1.aarch64", "relates_to_product_reference": "SUSE Linux Enterprise Module for Basesystem 15 SP4",
+         "full_product_name": {"name": "libopenssl1_1 as component of SLE Basesystem 15 SP4", "product_id": "SUSE Linux Enterprise Module for Basesystem 15 SP4:libopenssl1_1-1.1.1l-150400.7.28.1.aarch64"}},
+        {"category": "default_component_of", "product_reference": "libopenssl1_1-1.1.1l-150400.7.28.1.x86_64", "relates_to_product_reference": "openSUSE Leap 15.4",
+         "full_product_name": {"name": "libopenssl1_1 as component of openSUSE Leap 15.4", "product_id": "openSUSE Leap 15.4:libopenssl1_1-1.1.1l-150400.7.28.1.x86_64"}},
+        {"category": "default_component_of", "product_reference": "openssl-1_1-1.1.1l-150400.7.28.1.x86_64", "relates_to_product_reference": "openSUSE Leap 15.4",
+         "full_product_name": {"name": "openssl-1_1 as component of openSUSE Leap 15.4", "product_id": "openSUSE Leap 15.4:openssl-1_1-1.1.1l-150400.7.28.1.x86_64"}},
+        {"category": "default_component_of", "product_reference": "libopenssl1_1-1.1.1l-150400.7.28.1.aarch64", "relates_to_product_reference": "openSUSE Leap 15.4",
+         "full_product_name": {"name": "libopenssl1_1 as component of openSUSE Leap 15.4", "product_id": "openSUSE Leap 15.4:libopenssl1_1-1.1.1l-150400.7.28.1.aarch64"}}
+      ]
+    },
+    "vulnerabilities": [
+      {
+        "cve": "CVE-2023-0286",
+        "notes": [{"category": "general", "text": "X.400 address type confusion", "title": "CVE description"}],
+        "product_status": {"recommended": [
+          "SUSE Linux Enterprise Module for Basesystem 15 SP4:libopenssl1_1-1.1.1l-150400.7.28.1.x86_64",
+          "SUSE Linux Enterprise Module for Basesystem 15 SP4:openssl-1_1-1.1.1l-150400.7.28.1.x86_64",
+          "SUSE Linux Enterprise Module for Basesystem 15 SP4:libopenssl1_1-1.1.1l-150400.7.28.1.aarch64",
+          "openSUSE Leap 15.4:libopenssl1_1-1.1.1l-150400.7.28.1.x86_64",
+          "openSUSE Leap 15.4:openssl-1_1-1.1.1l-150400.7.28.1.x86_64",
+          "openSUSE Leap 15.4:libopenssl1_1-1.1.1l-150400.7.28.1.aarch64"
+        ]},
+        "scores": [{"cvss_v3": {"baseScore": 7.4, "baseSeverity": "HIGH", "vectorString": "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:N/A:H"}}]
+      },
+      {
+        "cve": "CVE-2023-0464",
+        "product_status": {"recommended": [
+          "SUSE Linux Enterprise Module for Basesystem 15 SP4:libopenssl1_1-1.1.1l-150400.7.28.1.x86_64",
+          "SUSE Linux Enterprise Module for Basesystem 15 SP4:openssl-1_1-1.1.1l-150400.7.28.1.x86_64",
+          "openSUSE Leap 15.4:libopenssl1_1-1.1.1l-150400.7.28.1.aarch64"
+        ]},
+        "scores": [{"cvss_v3": {"baseScore": 5.9, "baseSeverity": "MEDIUM", "vectorString": "CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:N/I:N/A:H"}}]
+      }
+    ]
+  }
+}`
+
+var testCVRF = `<?xml version="1.0" encoding="UTF-8"?>
+<cvrfdoc xmlns="http://www.icasi.org/CVRF/schema/cvrf/1.1" xmlns:cvrf="http://www.icasi.org/CVRF/schema/cvrf/1.1">
+  <DocumentTitle xml:lang="en">Security update for openssl-1_1</DocumentTitle>
+  <DocumentType>SUSE Patch</DocumentType>
+  <DocumentTracking>
+    <Identification>
+      <ID>SUSE-SU-2023:0598-1</ID>
+    </Identification>
+    <Status>Final</Status>
+    <Version>1</Version>
+    <RevisionHistory>
+      <Revision>
+        <Number>1</Number>
+        <Date>2023-03-01T12:00:00Z</Date>
+        <Description>current</Description>
+      </Revision>
+    </RevisionHistory>
+    <InitialReleaseDate>2023-03-01T12:00:00Z</InitialReleaseDate>
+    <CurrentReleaseDate>2023-03-01T12:00:00Z</CurrentReleaseDate>
+  </DocumentTracking>
+  <DocumentNotes>
+    <Note Title="Topic" Type="Summary" Ordinal="1" xml:lang="en">Security update for openssl-1_1</Note>
+    <Note Title="Details" Type="General" Ordinal="2" xml:lang="en">This update for openssl-1_1 fixes the following issues</Note>
+  </DocumentNotes>
+  <DocumentReferences>
+    <Reference Type="Self">
+      <URL>https://www.suse.com/support/update/announcement/2023/suse-su-20230598-1/</URL>
+      <Description>Link for SUSE-SU-2023:0598-1</Description>
+    </Reference>
+  </DocumentReferences>
+  <ProductTree xmlns="http://www.icasi.org/CVRF/schema/prod/1.1">
+    <Branch Type="Product Version" Name="libopenssl1_1-1.1.1l-150400.7.28.1">
+      <FullProductName ProductID="libopenssl1_1-1.1.1l-150400.7.28.1">libopenssl1_1-1.1.1l-150400.7.28.1</FullProductName>
+    </Branch>
+    <Branch Type="Product Family" Name="SUSE Linux Enterprise Module for Basesystem 15 SP4">
+      <Branch Type="Product Name" Name="SUSE Linux Enterprise Module for Basesystem 15 SP4">
+        <FullProductName ProductID="SUSE Linux Enterprise Module for Basesystem 15 SP4" CPE="cpe:/o:suse:sle-module-basesystem:15:sp4">SUSE Linux Enterprise Module for Basesystem 15 SP4</FullProductName>
+      </Branch>
+    </Branch>
+    <Branch Type="Product Family" Name="openSUSE Leap 15.4">
+      <Branch Type="Product Name" Name="openSUSE Leap 15.4">
+        <FullProductName ProductID="openSUSE Leap 15.4" CPE="cpe:/o:opensuse:leap:15.4">openSUSE Leap 15.4</FullProductName>
+      </Branch>
+    </Branch>
+    <Relationship ProductReference="libopenssl1_1-1.1.1l-150400.7.28.1" RelationType="Default Component Of" RelatesToProductReference="SUSE Linux Enterprise Module for Basesystem 15 SP4">
+      <FullProductName ProductID="SUSE Linux Enterprise Module for Basesystem 15 SP4:libopenssl1_1-1.1.1l-150400.7.28.1">libopenssl1_1-1.1.1l-150400.7.28.1 as a component of SUSE Linux Enterprise Module for Basesystem 15 SP4</FullProductName>
+    </Relationship>
+    <Relationship ProductReference="libopenssl1_1-1.1.1l-150400.7.28.1" RelationType="Default Component Of" RelatesToProductReference="openSUSE Leap 15.4">
+      <FullProductName ProductID="openSUSE Leap 15.4:libopenssl1_1-1.1.1l-150400.7.28.1">libopenssl1_1-1.1.1l-150400.7.28.1 as a component of openSUSE Leap 15.4</FullProductName>
+    </Relationship>
+  </ProductTree>
+  <Vulnerability xmlns="http://www.icasi.org/CVRF/schema/vuln/1.1" Ordinal="1">
+    <Notes>
+      <Note Title="Vulnerability Description" Type="General" Ordinal="1" xml:lang="en">X.400 address type confusion</Note>
+    </Notes>
+    <CVE>CVE-2023-0286</CVE>
+    <ProductStatuses>
+      <Status Type="Fixed">
+        <ProductID>SUSE Linux Enterprise Module for Basesystem 15 SP4:libopenssl1_1-1.1.1l-150400.7.28.1</ProductID>
+        <ProductID>openSUSE Leap 15.4:libopenssl1_1-1.1.1l-150400.7.28.1</ProductID>
+      </Status>
+    </ProductStatuses>
+    <Threats>
+      <Threat Type="Impact">
+        <Description>important</Description>
+      </Threat>
+    </Threats>
+    <CVSSScoreSets>
+      <ScoreSetV3>
+        <BaseScoreV3>7.4</BaseScoreV3>
+        <VectorV3>CVSS:3.1/AV:N/AC:H/PR:N/UI:N/S:U/C:H/I:N/A:H</VectorV3>
+      </ScoreSetV3>
+    </CVSSScoreSets>
+  </Vulnerability>
+</cvrfdoc>`
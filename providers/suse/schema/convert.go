@@ -0,0 +1,164 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"time"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/rpm"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const (
+	cveDataVersion = "4.0"
+)
+
+// ID is a part of the runner.Convertible interface
+func (adv *Advisory) ID() string {
+	return adv.Document.Document.Tracking.ID
+}
+
+// Convert is a part of the runner.Convertible interface
+func (adv *Advisory) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	item := nvd.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &nvd.CVEJSON40{
+			CVEDataMeta: &nvd.CVEJSON40CVEDataMeta{
+				ID:       adv.ID(),
+				ASSIGNER: "suse.com",
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: cveDataVersion,
+			Description: &nvd.CVEJSON40Description{
+				DescriptionData: []*nvd.CVEJSON40LangString{
+					{
+						Lang:  "en",
+						Value: adv.description(),
+					},
+				},
+			},
+			References: adv.newReferences(),
+		},
+		Configurations:   adv.newConfigurations(),
+		Impact:           adv.newImpact(),
+		LastModifiedDate: convertTime(adv.Document.Document.Tracking.CurrentReleaseDate),
+		PublishedDate:    convertTime(adv.Document.Document.Tracking.InitialReleaseDate),
+	}
+
+	return &item, nil
+}
+
+func (adv *Advisory) description() string {
+	for _, note := range adv.Document.Document.Notes {
+		if note.Category == "description" {
+			return note.Text
+		}
+	}
+	return adv.Document.Document.Title
+}
+
+func (adv *Advisory) newReferences() *nvd.CVEJSON40References {
+	refs := &nvd.CVEJSON40References{}
+	for _, vuln := range adv.Vulnerabilities {
+		refs.ReferenceData = append(refs.ReferenceData, &nvd.CVEJSON40Reference{
+			Name: vuln.CVE,
+		})
+	}
+	for _, ref := range adv.Document.Document.References {
+		refs.ReferenceData = append(refs.ReferenceData, &nvd.CVEJSON40Reference{
+			Name: ref.Summary,
+			URL:  ref.URL,
+		})
+	}
+	return refs
+}
+
+// newImpact uses the highest score of all vulnerabilities
+func (adv *Advisory) newImpact() *nvd.NVDCVEFeedJSON10DefImpact {
+	var cvss *nvd.CVSSV30
+	for _, vuln := range adv.Vulnerabilities {
+		for _, score := range vuln.Scores {
+			if score.CVSSV3 == nil || (cvss != nil && cvss.BaseScore >= score.CVSSV3.BaseScore) {
+				continue
+			}
+			cvss = score.CVSSV3
+		}
+	}
+	if cvss == nil {
+		return nil
+	}
+	return &nvd.NVDCVEFeedJSON10DefImpact{
+		BaseMetricV3: &nvd.NVDCVEFeedJSON10DefImpactBaseMetricV3{CVSSV3: cvss},
+	}
+}
+
+// newConfigurations creates a node for each fixed package on each distribution, the same way redhat does
+func (adv *Advisory) newConfigurations() *nvd.NVDCVEFeedJSON10DefConfigurations {
+	var nodes []*nvd.NVDCVEFeedJSON10DefNode
+	seen := make(map[[2]string]bool)
+	for _, fp := range adv.FixedPackages() {
+		key := [2]string{fp.CPE, fp.Package}
+		if fp.CPE == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		pkgAttrs, err := package2wfn(fp.Package)
+		if err != nil {
+			logging.Recordf("%s: can't create wfn from package: %v", adv.ID(), err)
+			continue
+		}
+
+		nodes = append(nodes, &nvd.NVDCVEFeedJSON10DefNode{
+			Operator: "AND",
+			CPEMatch: []*nvd.NVDCVEFeedJSON10DefCPEMatch{
+				{
+					Cpe22Uri:   fp.CPE,
+					Vulnerable: false,
+				},
+				{
+					Cpe22Uri:   pkgAttrs.BindToURI(),
+					Cpe23Uri:   pkgAttrs.BindToFmtString(),
+					Vulnerable: false,
+				},
+			},
+		})
+	}
+
+	return &nvd.NVDCVEFeedJSON10DefConfigurations{
+		CVEDataVersion: cveDataVersion,
+		Nodes:          nodes,
+	}
+}
+
+func package2wfn(pkg string) (*wfn.Attributes, error) {
+	attrs := wfn.NewAttributesWithAny()
+	err := rpm.ToWFN(attrs, withArch(pkg))
+	return attrs, err
+}
+
+func convertTime(suseTime string) string {
+	if suseTime == "" {
+		return ""
+	}
+	t, err := time.Parse(time.RFC3339, suseTime)
+	if err != nil {
+		logging.Recordf("unable to parse time: %v", err)
+		return suseTime
+	}
+	return t.UTC().Format(nvd.TimeLayout)
+}
@@ -0,0 +1,302 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	csafschema "github.com/facebookincubator/nvdtools/providers/csaf/schema"
+)
+
+// based on the CVRF 1.2 advisories published by SUSE, only the parts which are needed for conversion
+// https://ftp.suse.com/pub/projects/security/cvrf/
+
+// CVRF is the root of a CVRF document, e.g. cvrf-suse-su-2023_0001-1.xml
+type CVRF struct {
+	XMLName          xml.Name `xml:"cvrfdoc"`
+	DocumentTitle    string   `xml:"DocumentTitle"`
+	DocumentTracking struct {
+		ID                 string          `xml:"Identification>ID"`
+		Status             string          `xml:"Status"`
+		Version            string          `xml:"Version"`
+		RevisionHistory    []*CVRFRevision `xml:"RevisionHistory>Revision"`
+		InitialReleaseDate string          `xml:"InitialReleaseDate"`
+		CurrentReleaseDate string          `xml:"CurrentReleaseDate"`
+	} `xml:"DocumentTracking"`
+	DocumentNotes      []*CVRFNote      `xml:"DocumentNotes>Note"`
+	DocumentReferences []*CVRFReference `xml:"DocumentReferences>Reference"`
+	ProductTree        struct {
+		Branches         []*CVRFBranch          `xml:"Branch"`
+		FullProductNames []*CVRFFullProductName `xml:"FullProductName"`
+		Relationships    []*CVRFRelationship    `xml:"Relationship"`
+		ProductGroups    []*CVRFGroup           `xml:"ProductGroups>Group"`
+	} `xml:"ProductTree"`
+	Vulnerabilities []*CVRFVulnerability `xml:"Vulnerability"`
+}
+
+type CVRFRevision struct {
+	Number      string `xml:"Number"`
+	Date        string `xml:"Date"`
+	Description string `xml:"Description"`
+}
+
+type CVRFNote struct {
+	Title string `xml:"Title,attr"`
+	Type  string `xml:"Type,attr"`
+	Text  string `xml:",chardata"`
+}
+
+type CVRFReference struct {
+	Type        string `xml:"Type,attr"`
+	URL         string `xml:"URL"`
+	Description string `xml:"Description"`
+}
+
+type CVRFBranch struct {
+	Type            string               `xml:"Type,attr"`
+	Name            string               `xml:"Name,attr"`
+	FullProductName *CVRFFullProductName `xml:"FullProductName"`
+	Branches        []*CVRFBranch        `xml:"Branch"`
+}
+
+type CVRFFullProductName struct {
+	ProductID string `xml:"ProductID,attr"`
+	CPE       string `xml:"CPE,attr"`
+	Name      string `xml:",chardata"`
+}
+
+type CVRFRelationship struct {
+	ProductReference          string              `xml:"ProductReference,attr"`
+	RelationType              string              `xml:"RelationType,attr"`
+	RelatesToProductReference string              `xml:"RelatesToProductReference,attr"`
+	FullProductName           CVRFFullProductName `xml:"FullProductName"`
+}
+
+type CVRFGroup struct {
+	GroupID     string   `xml:"GroupID,attr"`
+	Description string   `xml:"Description"`
+	ProductIDs  []string `xml:"ProductID"`
+}
+
+type CVRFVulnerability struct {
+	Title           string           `xml:"Title"`
+	Notes           []*CVRFNote      `xml:"Notes>Note"`
+	CVE             string           `xml:"CVE"`
+	ProductStatuses []*CVRFStatus    `xml:"ProductStatuses>Status"`
+	Threats         []*CVRFThreat    `xml:"Threats>Threat"`
+	ScoreSetsV2     []*CVRFScoreSet  `xml:"CVSSScoreSets>ScoreSetV2"`
+	ScoreSetsV3     []*CVRFScoreSet  `xml:"CVSSScoreSets>ScoreSetV3"`
+	References      []*CVRFReference `xml:"References>Reference"`
+}
+
+type CVRFStatus struct {
+	Type       string   `xml:"Type,attr"`
+	ProductIDs []string `xml:"ProductID"`
+}
+
+type CVRFThreat struct {
+	Type        string   `xml:"Type,attr"`
+	Description string   `xml:"Description"`
+	ProductIDs  []string `xml:"ProductID"`
+}
+
+// CVRFScoreSet is either a ScoreSetV2 or a ScoreSetV3
+type CVRFScoreSet struct {
+	BaseScoreV2 float64  `xml:"BaseScoreV2"`
+	VectorV2    string   `xml:"VectorV2"`
+	BaseScoreV3 float64  `xml:"BaseScoreV3"`
+	VectorV3    string   `xml:"VectorV3"`
+	ProductIDs  []string `xml:"ProductID"`
+}
+
+// decodeCVRF decodes a CVRF document and converts it to CSAF
+func decodeCVRF(data []byte) (*Advisory, error) {
+	var doc CVRF
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("can't decode cvrf document: %v", err)
+	}
+	if doc.DocumentTracking.ID == "" {
+		return nil, fmt.Errorf("cvrf document doesn't have a tracking id")
+	}
+	return doc.CSAF(), nil
+}
+
+// CSAF converts the CVRF document to a CSAF security advisory, so both can be handled the same way
+func (doc *CVRF) CSAF() *Advisory {
+	var adv Advisory
+	meta := &adv.Document.Document
+	meta.Category = csafschema.CategorySecurityAdvisory
+	meta.CSAFVersion = "2.0"
+	meta.Title = strings.TrimSpace(doc.DocumentTitle)
+	meta.Publisher = csafschema.Publisher{Category: "vendor", Name: "SUSE", Namespace: "https://www.suse.com/"}
+	meta.Notes = convertNotes(doc.DocumentNotes)
+	meta.References = convertReferences(doc.DocumentReferences)
+
+	tracking := &meta.Tracking
+	tracking.ID = strings.TrimSpace(doc.DocumentTracking.ID)
+	tracking.Status = cvrfCategory(doc.DocumentTracking.Status)
+	tracking.Version = doc.DocumentTracking.Version
+	tracking.InitialReleaseDate = doc.DocumentTracking.InitialReleaseDate
+	tracking.CurrentReleaseDate = doc.DocumentTracking.CurrentReleaseDate
+	for _, rev := range doc.DocumentTracking.RevisionHistory {
+		tracking.RevisionHistory = append(tracking.RevisionHistory, &csafschema.Revision{
+			Date:    rev.Date,
+			Number:  rev.Number,
+			Summary: rev.Description,
+		})
+	}
+
+	pt := &csafschema.ProductTree{Branches: convertBranches(doc.ProductTree.Branches)}
+	for _, fpn := range doc.ProductTree.FullProductNames {
+		pt.FullProductNames = append(pt.FullProductNames, fpn.csaf())
+	}
+	for _, rel := range doc.ProductTree.Relationships {
+		pt.Relationships = append(pt.Relationships, &csafschema.Relationship{
+			Category:                  cvrfCategory(rel.RelationType),
+			FullProductName:           *rel.FullProductName.csaf(),
+			ProductReference:          rel.ProductReference,
+			RelatesToProductReference: rel.RelatesToProductReference,
+		})
+	}
+	for _, g := range doc.ProductTree.ProductGroups {
+		pt.ProductGroups = append(pt.ProductGroups, &csafschema.ProductGroup{
+			GroupID:    g.GroupID,
+			ProductIDs: g.ProductIDs,
+			Summary:    g.Description,
+		})
+	}
+	adv.ProductTree = pt
+
+	for _, vuln := range doc.Vulnerabilities {
+		adv.Vulnerabilities = append(adv.Vulnerabilities, vuln.csaf())
+	}
+	return &adv
+}
+
+func (vuln *CVRFVulnerability) csaf() *csafschema.Vulnerability {
+	v := csafschema.Vulnerability{
+		CVE:           strings.TrimSpace(vuln.CVE),
+		Title:         vuln.Title,
+		Notes:         convertNotes(vuln.Notes),
+		ProductStatus: &csafschema.ProductStatus{},
+		References:    convertReferences(vuln.References),
+	}
+	ps := v.ProductStatus
+	for _, status := range vuln.ProductStatuses {
+		switch cvrfCategory(status.Type) {
+		case "first_affected":
+			ps.FirstAffected = append(ps.FirstAffected, status.ProductIDs...)
+		case "first_fixed":
+			ps.FirstFixed = append(ps.FirstFixed, status.ProductIDs...)
+		case "fixed":
+			ps.Fixed = append(ps.Fixed, status.ProductIDs...)
+		case "known_affected":
+			ps.KnownAffected = append(ps.KnownAffected, status.ProductIDs...)
+		case "known_not_affected":
+			ps.KnownNotAffected = append(ps.KnownNotAffected, status.ProductIDs...)
+		case "recommended":
+			ps.Recommended = append(ps.Recommended, status.ProductIDs...)
+		}
+	}
+	for _, threat := range vuln.Threats {
+		v.Threats = append(v.Threats, &csafschema.Threat{
+			Category:   cvrfCategory(threat.Type),
+			Details:    threat.Description,
+			ProductIDs: threat.ProductIDs,
+		})
+	}
+	for _, set := range vuln.ScoreSetsV2 {
+		v.Scores = append(v.Scores, &csafschema.Score{
+			CVSSV2:   &nvd.CVSSV20{BaseScore: set.BaseScoreV2, VectorString: set.VectorV2, Version: "2.0"},
+			Products: set.ProductIDs,
+		})
+	}
+	for _, set := range vuln.ScoreSetsV3 {
+		version := "3.0"
+		if strings.HasPrefix(set.VectorV3, "CVSS:3.1/") {
+			version = "3.1"
+		}
+		v.Scores = append(v.Scores, &csafschema.Score{
+			CVSSV3:   &nvd.CVSSV30{BaseScore: set.BaseScoreV3, VectorString: set.VectorV3, Version: version},
+			Products: set.ProductIDs,
+		})
+	}
+	return &v
+}
+
+func (fpn *CVRFFullProductName) csaf() *csafschema.FullProductName {
+	p := csafschema.FullProductName{
+		Name:      strings.TrimSpace(fpn.Name),
+		ProductID: fpn.ProductID,
+	}
+	if fpn.CPE != "" {
+		p.ProductIdentificationHelper = &csafschema.ProductIdentificationHelper{CPE: fpn.CPE}
+	}
+	return &p
+}
+
+func convertBranches(branches []*CVRFBranch) []*csafschema.Branch {
+	var bs []*csafschema.Branch
+	for _, b := range branches {
+		branch := csafschema.Branch{
+			Category: cvrfCategory(b.Type),
+			Name:     b.Name,
+			Branches: convertBranches(b.Branches),
+		}
+		if b.FullProductName != nil {
+			branch.Product = b.FullProductName.csaf()
+		}
+		bs = append(bs, &branch)
+	}
+	return bs
+}
+
+// convertNotes converts note types to categories
+// SUSE stores the description of the patch as a general note titled Details
+func convertNotes(notes []*CVRFNote) []*csafschema.Note {
+	var ns []*csafschema.Note
+	for _, note := range notes {
+		category := cvrfCategory(note.Type)
+		if category == "general" && note.Title == "Details" {
+			category = "description"
+		}
+		ns = append(ns, &csafschema.Note{
+			Category: category,
+			Text:     strings.TrimSpace(note.Text),
+			Title:    note.Title,
+		})
+	}
+	return ns
+}
+
+func convertReferences(refs []*CVRFReference) []*csafschema.Reference {
+	var rs []*csafschema.Reference
+	for _, ref := range refs {
+		rs = append(rs, &csafschema.Reference{
+			Category: cvrfCategory(ref.Type),
+			Summary:  strings.TrimSpace(ref.Description),
+			URL:      strings.TrimSpace(ref.URL),
+		})
+	}
+	return rs
+}
+
+// cvrfCategory converts CVRF enumerations to CSAF categories, e.g. Default Component Of to default_component_of
+func cvrfCategory(typ string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(typ)), " ", "_")
+}
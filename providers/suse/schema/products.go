@@ -0,0 +1,107 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	csafschema "github.com/facebookincubator/nvdtools/providers/csaf/schema"
+	"github.com/facebookincubator/nvdtools/rpm"
+)
+
+// archs which can be found at the end of package names in SUSE product trees
+var archs = map[string]bool{
+	"aarch64": true,
+	"armv7hl": true,
+	"i586":    true,
+	"i686":    true,
+	"noarch":  true,
+	"nosrc":   true,
+	"ppc64le": true,
+	"s390x":   true,
+	"src":     true,
+	"x86_64":  true,
+}
+
+// FixedPackage is a package which was fixed for some CVE on some distribution
+type FixedPackage struct {
+	CVE string
+	// package NEVRA, arch is optional
+	Package string
+	// name and CPE of the distribution (or SLE module) the package was fixed on
+	ProductName string
+	CPE         string
+}
+
+// FixedPackages returns all packages fixed by the advisory, sorted by CVE, distribution and package
+func (adv *Advisory) FixedPackages() []*FixedPackage {
+	tree := csafschema.NewTree(adv.ProductTree)
+
+	var fps []*FixedPackage
+	for _, vuln := range adv.Vulnerabilities {
+		if vuln.ProductStatus == nil {
+			continue
+		}
+		ids := append(append([]string{}, vuln.ProductStatus.Fixed...), vuln.ProductStatus.Recommended...)
+		seen := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			p := tree.Product(id)
+			if p == nil || p.Relationship == "" || seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			fp := FixedPackage{CVE: vuln.CVE, Package: p.Component.ID}
+			if p.Component.Name != "" {
+				fp.Package = p.Component.Name
+			}
+			if tree.Product(p.Platform.ID) != nil {
+				fp.ProductName, fp.CPE = p.Platform.Name, p.Platform.CPE
+			}
+			fps = append(fps, &fp)
+		}
+	}
+
+	sort.Slice(fps, func(i, j int) bool {
+		if fps[i].CVE != fps[j].CVE {
+			return fps[i].CVE < fps[j].CVE
+		}
+		if fps[i].CPE != fps[j].CPE {
+			return fps[i].CPE < fps[j].CPE
+		}
+		return fps[i].Package < fps[j].Package
+	})
+	return fps
+}
+
+// ParsePackage parses the package name as found in SUSE product trees
+// names are in name-version-release[.arch] format, e.g. libopenssl1_1-1.1.1l-150400.7.28.1.x86_64
+func ParsePackage(name string) (*rpm.Package, error) {
+	p, err := rpm.Parse(withArch(name))
+	if err != nil {
+		return nil, fmt.Errorf("can't parse package %q: %v", name, err)
+	}
+	return p, nil
+}
+
+// withArch adds .src to package names without arch so they can be parsed correctly
+func withArch(name string) string {
+	if i := strings.LastIndexByte(name, '.'); i < 0 || !archs[name[i+1:]] {
+		return name + ".src"
+	}
+	return name
+}
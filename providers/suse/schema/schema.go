@@ -0,0 +1,48 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+
+	"github.com/facebookincubator/nvdtools/providers/csaf"
+	csafschema "github.com/facebookincubator/nvdtools/providers/csaf/schema"
+)
+
+// Advisory is a CSAF document with csaf_security_advisory category, e.g. SUSE-SU-2023:0001-1
+// https://ftp.suse.com/pub/projects/security/csaf/
+// CVRF advisories are converted to the same structure, see CVRF.CSAF
+type Advisory struct {
+	csafschema.Document
+}
+
+// ReadAdvisory reads a CSAF advisory from r
+// CVRF advisories, which are XML documents, are converted to CSAF
+func ReadAdvisory(r io.Reader) (*Advisory, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		return decodeCVRF(data)
+	}
+	doc, err := csaf.ReadDocument(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	return &Advisory{Document: *doc}, nil
+}
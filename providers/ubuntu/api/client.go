@@ -0,0 +1,68 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+	"github.com/facebookincubator/nvdtools/providers/lib/runner"
+	"github.com/facebookincubator/nvdtools/providers/ubuntu/schema"
+)
+
+const databasePath = "/usn-db/database.json"
+
+// Client downloads ubuntu security notices
+type Client struct {
+	client.Client
+	baseURL string
+}
+
+// NewClient creates an object which is used to download ubuntu security notices
+func NewClient(c client.Client, baseURL string) *Client {
+	return &Client{
+		Client:  c,
+		baseURL: baseURL,
+	}
+}
+
+// FetchAllNotices downloads the notices database and returns all notices published since the given time
+func (c *Client) FetchAllNotices(ctx context.Context, since int64) (<-chan runner.Convertible, error) {
+	resp, err := client.Get(ctx, c, c.baseURL+databasePath, http.Header{})
+	if err != nil {
+		return nil, fmt.Errorf("can't download notices: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var usns map[string]*schema.USN
+	if err := json.NewDecoder(resp.Body).Decode(&usns); err != nil {
+		return nil, fmt.Errorf("can't decode notices: %v", err)
+	}
+
+	output := make(chan runner.Convertible)
+	go func() {
+		defer close(output)
+		for _, usn := range usns {
+			if int64(usn.Timestamp) >= since {
+				output <- usn
+			}
+		}
+	}()
+
+	return output, nil
+}
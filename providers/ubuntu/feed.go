@@ -0,0 +1,89 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ubuntu
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/facebookincubator/nvdtools/deb"
+	"github.com/facebookincubator/nvdtools/providers/ubuntu/schema"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// Releases maps ubuntu release codenames to their versions
+var Releases = map[string]string{
+	"trusty":   "14.04",
+	"xenial":   "16.04",
+	"bionic":   "18.04",
+	"focal":    "20.04",
+	"jammy":    "22.04",
+	"kinetic":  "22.10",
+	"lunar":    "23.04",
+	"mantic":   "23.10",
+	"noble":    "24.04",
+	"oracular": "24.10",
+	"plucky":   "25.04",
+}
+
+// Feed is a collection of ubuntu security notices, keyed by the notice id
+type Feed map[string]*schema.USN
+
+// LoadFeed loads a feed downloaded by ubuntu2nvd or the usn database itself
+func LoadFeed(path string) (Feed, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't open file %q: %v", path, err)
+	}
+	defer f.Close()
+	return loadFeed(f)
+}
+
+func loadFeed(r io.Reader) (Feed, error) {
+	var feed Feed
+	if err := json.NewDecoder(r).Decode(&feed); err != nil {
+		return nil, fmt.Errorf("can't decode feed: %v", err)
+	}
+	return feed, nil
+}
+
+// Checker returns a checker which knows whether a package on some ubuntu release has been fixed
+// distro should be ubuntu cpe, e.g. cpe:/o:canonical:ubuntu_linux:20.04
+func (feed Feed) Checker() (deb.Checker, error) {
+	return NewPackageFeed(feed)
+}
+
+// ReleaseName returns the codename of the ubuntu release, which can be given either as a codename or a version
+func ReleaseName(release string) (string, bool) {
+	if _, ok := Releases[release]; ok {
+		return release, true
+	}
+	for name, version := range Releases {
+		if version == release {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// distroRelease returns the codename of the release from the ubuntu cpe
+func distroRelease(distro *wfn.Attributes) (string, bool) {
+	if distro == nil || distro.Product != "ubuntu_linux" {
+		return "", false
+	}
+	return ReleaseName(wfn.StripSlashes(distro.Version))
+}
@@ -0,0 +1,101 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ubuntu
+
+import (
+	"github.com/facebookincubator/nvdtools/deb"
+	"github.com/facebookincubator/nvdtools/logging"
+	"github.com/facebookincubator/nvdtools/providers/lib/pkgfeed"
+	"github.com/facebookincubator/nvdtools/providers/ubuntu/schema"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// packageKey identifies a package on some ubuntu release
+type packageKey struct {
+	release string
+	name    string
+}
+
+// PackageFeed indexes the feed by release and package
+// it can be used to list CVEs which are fixed for some installed package
+type PackageFeed struct {
+	feed *pkgfeed.Feed
+}
+
+// NewPackageFeed creates a new package feed from the given feed
+// both source and binary package names are indexed
+func NewPackageFeed(feed Feed) (*PackageFeed, error) {
+	pf, err := pkgfeed.New(func(add func(interface{}, pkgfeed.Fix)) error {
+		for _, usn := range feed {
+			cves := usn.CVEIDs()
+			for release, r := range usn.Releases {
+				for _, pkgs := range []map[string]*schema.Fix{r.Sources, r.Binaries, r.AllBinaries} {
+					addFixes(add, usn.ID(), release, pkgs, cves)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &PackageFeed{feed: pf}, nil
+}
+
+// LoadPackageFeed loads the feed from the given path and indexes it by packages
+func LoadPackageFeed(path string) (*PackageFeed, error) {
+	feed, err := LoadFeed(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewPackageFeed(feed)
+}
+
+func addFixes(add func(interface{}, pkgfeed.Fix), usn, release string, pkgs map[string]*schema.Fix, cves []string) {
+	for name, fix := range pkgs {
+		if fix == nil || fix.Version == "" {
+			continue
+		}
+		v, err := deb.ParseVersion(fix.Version)
+		if err != nil {
+			logging.Recordf("can't parse version %q of %s for %s: %v", fix.Version, name, usn, err)
+			continue
+		}
+		key := packageKey{release: release, name: name}
+		for _, cve := range cves {
+			add(key, &pkgfeed.DebFix{ID: cve, Version: v})
+		}
+	}
+}
+
+// ListFixedCVEs returns sorted list of CVEs which are fixed for the given package on the given release
+// release can be either a codename (e.g. focal) or a version (e.g. 20.04)
+func (pf *PackageFeed) ListFixedCVEs(release string, pkg *deb.Package) []string {
+	name, ok := ReleaseName(release)
+	if !ok {
+		return []string{}
+	}
+	return pf.feed.ListFixedCVEs(pkg, packageKey{release: name, name: pkg.Name})
+}
+
+// Check is part of the deb.Checker interface
+// distro should be ubuntu cpe, e.g. cpe:/o:canonical:ubuntu_linux:20.04
+func (pf *PackageFeed) Check(pkg *deb.Package, distro *wfn.Attributes, cve string) bool {
+	release, ok := distroRelease(distro)
+	if !ok {
+		return false
+	}
+	return pf.feed.Check(pkg, cve, packageKey{release: release, name: pkg.Name})
+}
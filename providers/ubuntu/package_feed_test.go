@@ -0,0 +1,142 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ubuntu
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/deb"
+)
+
+func TestPackageFeedListFixedCVEs(t *testing.T) {
+	feed, err := loadFeed(strings.NewReader(testFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pf, err := NewPackageFeed(feed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, tc := range []struct {
+		release string
+		pkg     string
+		expect  []string
+	}{
+		{"focal", "openssl_1.1.1f-1ubuntu2.17", []string{"CVE-2023-0286", "CVE-2023-0464"}},
+		{"20.04", "openssl_1.1.1f-1ubuntu2.17_amd64", []string{"CVE-2023-0286", "CVE-2023-0464"}},
+		{"focal", "openssl_1.1.1f-1ubuntu2.16", []string{"CVE-2023-0286"}},
+		{"focal", "openssl_1.1.1f-1ubuntu2.15", []string{}},
+		// binary package names are indexed as well
+		{"focal", "libssl1.1_1.1.1f-1ubuntu2.17_amd64", []string{"CVE-2023-0286", "CVE-2023-0464"}},
+		// versions differ per release
+		{"jammy", "openssl_3.0.2-0ubuntu1.8", []string{"CVE-2023-0286"}},
+		{"jammy", "openssl_1.1.1f-1ubuntu2.17", []string{}},
+		{"bionic", "openssl_1.1.1f-1ubuntu2.17", []string{}},
+		{"unknown", "openssl_1.1.1f-1ubuntu2.17", []string{}},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			pkg, err := deb.Parse(tc.pkg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := pf.ListFixedCVEs(tc.release, pkg); !reflect.DeepEqual(got, tc.expect) {
+				t.Fatalf("expecting %v, got %v", tc.expect, got)
+			}
+		})
+	}
+}
+
+func TestChecker(t *testing.T) {
+	feed, err := loadFeed(strings.NewReader(testFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	chk, err := feed.Checker()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	focal := "cpe:/o:canonical:ubuntu_linux:20.04"
+	jammy := "cpe:/o:canonical:ubuntu_linux:22.04"
+
+	for i, tc := range []struct {
+		pkg    string
+		distro string
+		cve    string
+		fixed  bool
+	}{
+		{"openssl_1.1.1f-1ubuntu2.17", focal, "CVE-2023-0464", true},
+		{"openssl_1.1.1f-1ubuntu2.16", focal, "CVE-2023-0464", false},
+		{"openssl_1.1.1f-1ubuntu2.16+esm1", focal, "CVE-2023-0286", true},
+		{"openssl_3.0.2-0ubuntu1.8", jammy, "CVE-2023-0286", true},
+		{"openssl_3.0.2-0ubuntu1.7", jammy, "CVE-2023-0286", false},
+		{"openssl_3.0.2-0ubuntu1.8", "cpe:/o:debian:debian_linux:11", "CVE-2023-0286", false},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			fixed, err := deb.Check(chk, tc.pkg, tc.distro, tc.cve)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fixed != tc.fixed {
+				t.Fatalf("expecting %s to be fixed: %v, got %v", tc.pkg, tc.fixed, fixed)
+			}
+		})
+	}
+}
+
+func TestReleaseName(t *testing.T) {
+	for _, release := range []string{"focal", "20.04"} {
+		if name, ok := ReleaseName(release); !ok || name != "focal" {
+			t.Fatalf("expecting focal for %q, got %q", release, name)
+		}
+	}
+	if _, ok := ReleaseName("20.05"); ok {
+		t.Fatal("unknown release shouldn't be found")
+	}
+}
+
+var testFeed = `{
+  "USN-5844-1": {
+    "id": "5844-1",
+    "title": "OpenSSL vulnerabilities",
+    "timestamp": 1675789437.5,
+    "cves": ["CVE-2023-0286", "https://launchpad.net/bugs/2006011"],
+    "releases": {
+      "focal": {
+        "sources": {"openssl": {"version": "1.1.1f-1ubuntu2.16", "description": "Secure Socket Layer (SSL) cryptographic library and tools"}},
+        "binaries": {"libssl1.1": {"version": "1.1.1f-1ubuntu2.16"}}
+      },
+      "jammy": {
+        "sources": {"openssl": {"version": "3.0.2-0ubuntu1.8"}}
+      }
+    }
+  },
+  "USN-6039-1": {
+    "id": "6039-1",
+    "title": "OpenSSL vulnerabilities",
+    "timestamp": 1682439861,
+    "cves": ["CVE-2023-0464"],
+    "releases": {
+      "focal": {
+        "sources": {"openssl": {"version": "1.1.1f-1ubuntu2.17"}},
+        "allbinaries": {"libssl1.1": {"version": "1.1.1f-1ubuntu2.17", "source": "openssl", "pocket": "security"}}
+      }
+    }
+  }
+}`
@@ -0,0 +1,155 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	nvd "github.com/facebookincubator/nvdtools/cvefeed/nvd/schema"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+const (
+	cveDataVersion = "4.0"
+	noticeURL      = "https://ubuntu.com/security/notices/"
+)
+
+// ID is a part of the runner.Convertible interface
+func (usn *USN) ID() string {
+	return "USN-" + usn.USNID
+}
+
+// Convert is a part of the runner.Convertible interface
+func (usn *USN) Convert() (*nvd.NVDCVEFeedJSON10DefCVEItem, error) {
+	configurations, err := usn.newConfigurations()
+	if err != nil {
+		return nil, fmt.Errorf("can't create configurations: %v", err)
+	}
+
+	item := nvd.NVDCVEFeedJSON10DefCVEItem{
+		CVE: &nvd.CVEJSON40{
+			CVEDataMeta: &nvd.CVEJSON40CVEDataMeta{
+				ID:       usn.ID(),
+				ASSIGNER: "ubuntu.com",
+			},
+			DataFormat:  "MITRE",
+			DataType:    "CVE",
+			DataVersion: cveDataVersion,
+			Description: &nvd.CVEJSON40Description{
+				DescriptionData: []*nvd.CVEJSON40LangString{
+					{
+						Lang:  "en",
+						Value: usn.Description,
+					},
+				},
+			},
+			References: usn.newReferences(),
+		},
+		Configurations:   configurations,
+		LastModifiedDate: usn.published(),
+		PublishedDate:    usn.published(),
+	}
+
+	return &item, nil
+}
+
+// CVEIDs returns CVEs fixed by the notice, other references (e.g. launchpad bugs) are skipped
+func (usn *USN) CVEIDs() []string {
+	var cves []string
+	for _, cve := range usn.CVEs {
+		if strings.HasPrefix(cve, "CVE-") {
+			cves = append(cves, cve)
+		}
+	}
+	return cves
+}
+
+func (usn *USN) published() string {
+	sec := int64(usn.Timestamp)
+	return time.Unix(sec, 0).UTC().Format(nvd.TimeLayout)
+}
+
+func (usn *USN) newReferences() *nvd.CVEJSON40References {
+	refs := &nvd.CVEJSON40References{
+		ReferenceData: []*nvd.CVEJSON40Reference{
+			{
+				Name: usn.Title,
+				URL:  noticeURL + usn.ID(),
+			},
+		},
+	}
+	for _, cve := range usn.CVEs {
+		ref := nvd.CVEJSON40Reference{Name: cve}
+		if !strings.HasPrefix(cve, "CVE-") {
+			ref.URL = cve
+		}
+		refs.ReferenceData = append(refs.ReferenceData, &ref)
+	}
+	return refs
+}
+
+func (usn *USN) newConfigurations() (*nvd.NVDCVEFeedJSON10DefConfigurations, error) {
+	// sort releases and packages to get a stable output
+	releases := make([]string, 0, len(usn.Releases))
+	for release := range usn.Releases {
+		releases = append(releases, release)
+	}
+	sort.Strings(releases)
+
+	node := &nvd.NVDCVEFeedJSON10DefNode{Operator: "OR"}
+	for _, release := range releases {
+		sources := usn.Releases[release].Sources
+		names := make([]string, 0, len(sources))
+		for name := range sources {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			attrs, err := PackageToCPE(release, name)
+			if err != nil {
+				return nil, err
+			}
+			cpe := attrs.BindToFmtString()
+			node.CPEMatch = append(node.CPEMatch, &nvd.NVDCVEFeedJSON10DefCPEMatch{
+				Cpe23Uri:            cpe,
+				VersionEndExcluding: sources[name].Version,
+				Vulnerable:          true,
+			})
+		}
+	}
+
+	return &nvd.NVDCVEFeedJSON10DefConfigurations{
+		CVEDataVersion: cveDataVersion,
+		Nodes:          []*nvd.NVDCVEFeedJSON10DefNode{node},
+	}, nil
+}
+
+// PackageToCPE creates a CPE for the source package on the given ubuntu release
+// release codename is stored as target software, e.g. ubuntu_focal
+func PackageToCPE(release, name string) (*wfn.Attributes, error) {
+	attrs := wfn.Attributes{Part: "a"}
+	var err error
+	if attrs.Product, err = wfn.WFNize(name); err != nil {
+		return nil, fmt.Errorf("can't wfnize package name %q: %v", name, err)
+	}
+	if attrs.TargetSW, err = wfn.WFNize("ubuntu_" + release); err != nil {
+		return nil, fmt.Errorf("can't wfnize release %q: %v", release, err)
+	}
+	return &attrs, nil
+}
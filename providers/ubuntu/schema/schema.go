@@ -0,0 +1,46 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+// based on the Ubuntu Security Notices database
+// https://usn.ubuntu.com/usn-db/database.json
+
+// USN is a single ubuntu security notice
+type USN struct {
+	USNID       string              `json:"id"`
+	Title       string              `json:"title"`
+	Summary     string              `json:"summary"`
+	ISummary    string              `json:"isummary,omitempty"`
+	Description string              `json:"description"`
+	Action      string              `json:"action,omitempty"`
+	Timestamp   float64             `json:"timestamp"`
+	CVEs        []string            `json:"cves"`
+	Releases    map[string]*Release `json:"releases"`
+}
+
+// Release lists packages fixed on a single ubuntu release, keyed by package name
+type Release struct {
+	Sources     map[string]*Fix `json:"sources"`
+	Binaries    map[string]*Fix `json:"binaries,omitempty"`
+	AllBinaries map[string]*Fix `json:"allbinaries,omitempty"`
+}
+
+// Fix is the version in which a package was fixed
+type Fix struct {
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+	Source      string `json:"source,omitempty"`
+	Pocket      string `json:"pocket,omitempty"`
+}
@@ -16,12 +16,13 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"path/filepath"
 
+	"github.com/facebookincubator/nvdtools/logging"
 	"github.com/facebookincubator/nvdtools/providers/vfeed/schema"
 )
 
@@ -40,8 +41,8 @@ func NewClient(path string) *Client {
 }
 
 // FetchAllVulnerabilities will return all vfeed items. The "since" parameter is
-// ignored.
-func (c *Client) FetchAllVulnerabilities(since int64) (<-chan *schema.Item, error) {
+// ignored. It stops reading items once the context is done.
+func (c *Client) FetchAllVulnerabilities(ctx context.Context, since int64) (<-chan *schema.Item, error) {
 	items := make(chan *schema.Item)
 
 	matches, err := filepath.Glob(c.path + suffixPattern)
@@ -56,11 +57,15 @@ func (c *Client) FetchAllVulnerabilities(since int64) (<-chan *schema.Item, erro
 
 			item, err := unmarshalFile(match)
 			if err != nil {
-				log.Printf("Failed to unmarshal %s: %v", match, err)
+				logging.Recordf("Failed to unmarshal %s: %v", match, err)
 				return
 			}
 
-			items <- item
+			select {
+			case items <- item:
+			case <-ctx.Done():
+				return
+			}
 		}
 	}()
 
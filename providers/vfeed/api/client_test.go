@@ -15,6 +15,7 @@
 package api
 
 import (
+	"context"
 	"reflect"
 	"testing"
 )
@@ -24,7 +25,7 @@ const testDir = "testdata"
 func TestClient(t *testing.T) {
 	client := NewClient(testDir)
 
-	items, err := client.FetchAllVulnerabilities(0)
+	items, err := client.FetchAllVulnerabilities(context.Background(), 0)
 	if err != nil {
 		t.Fatalf("Fetch vulnerabilities failed: %v", err)
 	}
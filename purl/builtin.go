@@ -0,0 +1,70 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package purl
+
+// builtin translations of common packages whose NVD vendor and product can't be derived from the package url
+var builtin = map[string]string{
+	// pypi
+	"pkg:pypi/cryptography": "cpe:/a:cryptography_project:cryptography",
+	"pkg:pypi/django":       "cpe:/a:djangoproject:django",
+	"pkg:pypi/flask":        "cpe:/a:palletsprojects:flask",
+	"pkg:pypi/jinja2":       "cpe:/a:palletsprojects:jinja",
+	"pkg:pypi/lxml":         "cpe:/a:lxml:lxml",
+	"pkg:pypi/numpy":        "cpe:/a:numpy:numpy",
+	"pkg:pypi/pillow":       "cpe:/a:python:pillow",
+	"pkg:pypi/pyyaml":       "cpe:/a:pyyaml:pyyaml",
+	"pkg:pypi/requests":     "cpe:/a:python:requests",
+	"pkg:pypi/urllib3":      "cpe:/a:python:urllib3",
+	"pkg:pypi/werkzeug":     "cpe:/a:palletsprojects:werkzeug",
+
+	// npm
+	"pkg:npm/axios":      "cpe:/a:axios:axios",
+	"pkg:npm/express":    "cpe:/a:openjsf:express",
+	"pkg:npm/handlebars": "cpe:/a:handlebarsjs:handlebars",
+	"pkg:npm/jquery":     "cpe:/a:jquery:jquery",
+	"pkg:npm/lodash":     "cpe:/a:lodash:lodash",
+	"pkg:npm/minimist":   "cpe:/a:minimist_project:minimist",
+	"pkg:npm/moment":     "cpe:/a:momentjs:moment",
+
+	// maven
+	"pkg:maven/com.fasterxml.jackson.core/jackson-databind": "cpe:/a:fasterxml:jackson-databind",
+	"pkg:maven/com.google.guava/guava":                      "cpe:/a:google:guava",
+	"pkg:maven/commons-collections/commons-collections":     "cpe:/a:apache:commons_collections",
+	"pkg:maven/org.apache.commons/commons-text":             "cpe:/a:apache:commons_text",
+	"pkg:maven/org.apache.logging.log4j/log4j-core":         "cpe:/a:apache:log4j",
+	"pkg:maven/org.apache.struts/struts2-core":              "cpe:/a:apache:struts",
+	"pkg:maven/org.springframework/spring-core":             "cpe:/a:vmware:spring_framework",
+	"pkg:maven/org.yaml/snakeyaml":                          "cpe:/a:snakeyaml_project:snakeyaml",
+
+	// gem, composer, golang, cargo, nuget
+	"pkg:gem/nokogiri":                    "cpe:/a:nokogiri:nokogiri",
+	"pkg:gem/rack":                        "cpe:/a:rack_project:rack",
+	"pkg:gem/rails":                       "cpe:/a:rubyonrails:rails",
+	"pkg:composer/laravel/framework":      "cpe:/a:laravel:framework",
+	"pkg:golang/github.com/gin-gonic/gin": "cpe:/a:gin-gonic:gin",
+	"pkg:cargo/openssl":                   "cpe:/a:rust-openssl_project:rust-openssl",
+	"pkg:nuget/newtonsoft.json":           "cpe:/a:newtonsoft:json.net",
+
+	// generic, also used for os packages
+	"pkg:generic/bash":    "cpe:/a:gnu:bash",
+	"pkg:generic/curl":    "cpe:/a:haxx:curl",
+	"pkg:generic/glibc":   "cpe:/a:gnu:glibc",
+	"pkg:generic/httpd":   "cpe:/a:apache:http_server",
+	"pkg:generic/libxml2": "cpe:/a:xmlsoft:libxml2",
+	"pkg:generic/nginx":   "cpe:/a:f5:nginx",
+	"pkg:generic/openssl": "cpe:/a:openssl:openssl",
+	"pkg:generic/sqlite":  "cpe:/a:sqlite:sqlite",
+	"pkg:generic/zlib":    "cpe:/a:zlib:zlib",
+}
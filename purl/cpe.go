@@ -0,0 +1,185 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package purl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// Mapping maps package urls to CPEs, using known translations for packages which have them
+// and deriving CPEs from the package urls of all other packages
+type Mapping struct {
+	mu    sync.RWMutex
+	known map[string]*wfn.Attributes
+}
+
+// NewMapping creates a mapping without any known translations
+func NewMapping() *Mapping {
+	return &Mapping{known: make(map[string]*wfn.Attributes)}
+}
+
+// DefaultMapping creates a mapping with the built-in translations of common packages, more can be added to it
+func DefaultMapping() *Mapping {
+	m := NewMapping()
+	for p, cpe := range builtin {
+		if err := m.Add(p, cpe); err != nil {
+			panic(fmt.Sprintf("invalid built-in mapping %s: %v", p, err))
+		}
+	}
+	return m
+}
+
+// Add adds a translation of the package to the CPE, version of both is ignored
+// e.g. pkg:pypi/django to cpe:/a:djangoproject:django
+func (m *Mapping) Add(purl, cpe string) error {
+	p, err := Parse(purl)
+	if err != nil {
+		return err
+	}
+	attrs, err := wfn.Parse(cpe)
+	if err != nil {
+		return fmt.Errorf("can't parse cpe %q: %v", cpe, err)
+	}
+	attrs.Version = wfn.Any
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.known[p.key()] = attrs
+	return nil
+}
+
+// Load adds translations from r, every line contains a package url and a CPE separated by spaces
+// empty lines and lines starting with # are skipped
+func (m *Mapping) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return fmt.Errorf("line %d: expecting a package url and a cpe, got %q", n, line)
+		}
+		if err := m.Add(fields[0], fields[1]); err != nil {
+			return fmt.Errorf("line %d: %v", n, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// LoadFile adds translations from the file, see Load for its format
+func (m *Mapping) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := m.Load(f); err != nil {
+		return fmt.Errorf("can't load %s: %v", path, err)
+	}
+	return nil
+}
+
+// ToCPE returns CPE attributes of the package
+// known packages are looked up by type, namespace and name, then by type and name,
+// and operating system packages (deb, rpm, apk) as generic ones as well, e.g. pkg:deb/debian/curl as pkg:generic/curl;
+// CPEs of other packages are derived from their names, with the owner as the vendor for repositories (e.g. github);
+// the target software is set to the language of the ecosystem (e.g. python for pypi) unless the known CPE has it
+func (m *Mapping) ToCPE(p *PackageURL) (*wfn.Attributes, error) {
+	attrs, err := m.lookup(p)
+	if err != nil {
+		return nil, err
+	}
+	if attrs.Version, err = wfn.WFNize(p.Version); err != nil {
+		return nil, fmt.Errorf("can't wfnize version %q: %v", p.Version, err)
+	}
+	if ts, ok := targetSW[p.Type]; ok && attrs.TargetSW == wfn.Any {
+		if attrs.TargetSW, err = wfn.WFNize(ts); err != nil {
+			return nil, fmt.Errorf("can't wfnize target software %q: %v", ts, err)
+		}
+	}
+	return attrs, nil
+}
+
+// ParseToCPE parses the package url and returns its CPE attributes
+func (m *Mapping) ParseToCPE(purl string) (*wfn.Attributes, error) {
+	p, err := Parse(purl)
+	if err != nil {
+		return nil, err
+	}
+	return m.ToCPE(p)
+}
+
+// lookup returns a copy of known attributes of the package, or the ones derived from it
+func (m *Mapping) lookup(p *PackageURL) (*wfn.Attributes, error) {
+	keys := []string{p.key(), p.Type + "/" + p.Name}
+	if osPackages[p.Type] {
+		keys = append(keys, "generic/"+p.Name)
+	}
+	m.mu.RLock()
+	for _, key := range keys {
+		if attrs, ok := m.known[key]; ok {
+			m.mu.RUnlock()
+			a := *attrs
+			return &a, nil
+		}
+	}
+	m.mu.RUnlock()
+
+	attrs := wfn.Attributes{Part: "a"}
+	var err error
+	if attrs.Product, err = wfn.WFNize(strings.ToLower(p.Name)); err != nil {
+		return nil, fmt.Errorf("can't wfnize name %q: %v", p.Name, err)
+	}
+	if repositories[p.Type] && p.Namespace != "" {
+		if attrs.Vendor, err = wfn.WFNize(strings.ToLower(p.Namespace)); err != nil {
+			return nil, fmt.Errorf("can't wfnize namespace %q: %v", p.Namespace, err)
+		}
+	}
+	return &attrs, nil
+}
+
+// types of packages built by operating system distributions
+var osPackages = map[string]bool{
+	"alpm": true,
+	"apk":  true,
+	"deb":  true,
+	"rpm":  true,
+}
+
+// types of packages whose namespace is the owner of the repository
+var repositories = map[string]bool{
+	"bitbucket": true,
+	"github":    true,
+	"gitlab":    true,
+}
+
+// target software as used by NVD for packages of language ecosystems
+var targetSW = map[string]string{
+	"cargo":    "rust",
+	"composer": "php",
+	"gem":      "ruby",
+	"golang":   "go",
+	"npm":      "node.js",
+	"nuget":    ".net",
+	"pypi":     "python",
+}
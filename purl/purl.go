@@ -0,0 +1,164 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package purl parses package urls and maps them to CPEs.
+// https://github.com/package-url/purl-spec/blob/master/PURL-SPECIFICATION.rst
+package purl
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// PackageURL is a parsed package url
+// pkg:type/namespace/name@version?qualifiers#subpath
+type PackageURL struct {
+	Type       string
+	Namespace  string
+	Name       string
+	Version    string
+	Qualifiers map[string]string
+	Subpath    string
+}
+
+// Parse parses the package url, e.g. pkg:npm/%40angular/core@16.0.0 or pkg:rpm/fedora/curl@7.50.3-1.fc25?arch=i386
+// namespace and name are normalized as required by their type, e.g. pypi names are lower case with dashes
+func Parse(s string) (*PackageURL, error) {
+	if !strings.HasPrefix(s, "pkg:") {
+		return nil, fmt.Errorf("%q isn't a package url", s)
+	}
+	rest := strings.TrimLeft(strings.TrimPrefix(s, "pkg:"), "/")
+
+	var p PackageURL
+	var err error
+	if i := strings.IndexByte(rest, '#'); i >= 0 {
+		if p.Subpath, err = url.PathUnescape(strings.Trim(rest[i+1:], "/")); err != nil {
+			return nil, fmt.Errorf("invalid subpath in %q: %v", s, err)
+		}
+		rest = rest[:i]
+	}
+	if i := strings.IndexByte(rest, '?'); i >= 0 {
+		p.Qualifiers = make(map[string]string)
+		for _, kv := range strings.Split(rest[i+1:], "&") {
+			parts := strings.SplitN(kv, "=", 2)
+			if len(parts) != 2 || parts[1] == "" {
+				continue
+			}
+			if p.Qualifiers[strings.ToLower(parts[0])], err = url.PathUnescape(parts[1]); err != nil {
+				return nil, fmt.Errorf("invalid qualifier %q in %q: %v", kv, s, err)
+			}
+		}
+		rest = rest[:i]
+	}
+	rest = strings.TrimRight(rest, "/")
+	if i := strings.LastIndexByte(rest, '@'); i >= 0 && i > strings.LastIndexByte(rest, '/') {
+		if p.Version, err = url.PathUnescape(rest[i+1:]); err != nil {
+			return nil, fmt.Errorf("invalid version in %q: %v", s, err)
+		}
+		rest = rest[:i]
+	}
+
+	parts := strings.Split(rest, "/")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("package url %q doesn't have a type and a name", s)
+	}
+	p.Type = strings.ToLower(parts[0])
+	if p.Name, err = url.PathUnescape(parts[len(parts)-1]); err != nil {
+		return nil, fmt.Errorf("invalid name in %q: %v", s, err)
+	}
+	var namespace []string
+	for _, segment := range parts[1 : len(parts)-1] {
+		if segment == "" {
+			continue
+		}
+		ns, err := url.PathUnescape(segment)
+		if err != nil {
+			return nil, fmt.Errorf("invalid namespace in %q: %v", s, err)
+		}
+		namespace = append(namespace, ns)
+	}
+	p.Namespace = strings.Join(namespace, "/")
+	if p.Name == "" {
+		return nil, fmt.Errorf("package url %q doesn't have a name", s)
+	}
+	p.normalize()
+	return &p, nil
+}
+
+// normalize applies type specific rules, only for the types which have them
+func (p *PackageURL) normalize() {
+	switch p.Type {
+	case "alpm", "apk", "bitbucket", "composer", "deb", "github", "gitlab", "hex", "npm":
+		p.Namespace = strings.ToLower(p.Namespace)
+		p.Name = strings.ToLower(p.Name)
+	case "pypi":
+		p.Name = strings.Replace(strings.ToLower(p.Name), "_", "-", -1)
+	}
+}
+
+// String returns the canonical form of the package url
+func (p *PackageURL) String() string {
+	var b strings.Builder
+	b.WriteString("pkg:")
+	b.WriteString(p.Type)
+	b.WriteByte('/')
+	if p.Namespace != "" {
+		for _, segment := range strings.Split(p.Namespace, "/") {
+			b.WriteString(escape(segment))
+			b.WriteByte('/')
+		}
+	}
+	b.WriteString(escape(p.Name))
+	if p.Version != "" {
+		b.WriteByte('@')
+		b.WriteString(escape(p.Version))
+	}
+	if len(p.Qualifiers) != 0 {
+		keys := make([]string, 0, len(p.Qualifiers))
+		for k := range p.Qualifiers {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for i, k := range keys {
+			if i == 0 {
+				b.WriteByte('?')
+			} else {
+				b.WriteByte('&')
+			}
+			b.WriteString(k)
+			b.WriteByte('=')
+			b.WriteString(escape(p.Qualifiers[k]))
+		}
+	}
+	if p.Subpath != "" {
+		b.WriteByte('#')
+		b.WriteString(p.Subpath)
+	}
+	return b.String()
+}
+
+// key identifies the package regardless of its version
+func (p *PackageURL) key() string {
+	if p.Namespace == "" {
+		return p.Type + "/" + p.Name
+	}
+	return p.Type + "/" + p.Namespace + "/" + p.Name
+}
+
+// escape percent-encodes the segment, @ as well since it separates the version
+func escape(s string) string {
+	return strings.Replace(url.PathEscape(s), "@", "%40", -1)
+}
@@ -0,0 +1,94 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package purl
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for i, tc := range []struct {
+		purl      string
+		fail      bool
+		typ       string
+		namespace string
+		name      string
+		version   string
+		canonical string
+	}{
+		{purl: "pkg:npm/%40angular/core@16.0.0", typ: "npm", namespace: "@angular", name: "core", version: "16.0.0", canonical: "pkg:npm/%40angular/core@16.0.0"},
+		{purl: "pkg:pypi/Django_Rest@3.2.1", typ: "pypi", name: "django-rest", version: "3.2.1", canonical: "pkg:pypi/django-rest@3.2.1"},
+		{purl: "pkg:rpm/fedora/curl@7.50.3-1.fc25?arch=i386&distro=fedora-25", typ: "rpm", namespace: "fedora", name: "curl", version: "7.50.3-1.fc25", canonical: "pkg:rpm/fedora/curl@7.50.3-1.fc25?arch=i386&distro=fedora-25"},
+		{purl: "pkg:golang/github.com/gin-gonic/gin@v1.9.0#binding", typ: "golang", namespace: "github.com/gin-gonic", name: "gin", version: "v1.9.0", canonical: "pkg:golang/github.com/gin-gonic/gin@v1.9.0#binding"},
+		{purl: "pkg:maven/org.apache.logging.log4j/log4j-core", typ: "maven", namespace: "org.apache.logging.log4j", name: "log4j-core", canonical: "pkg:maven/org.apache.logging.log4j/log4j-core"},
+		{purl: "pkg:GitHub/Package-URL/purl-spec@244fd47e07d1004", typ: "github", namespace: "package-url", name: "purl-spec", version: "244fd47e07d1004", canonical: "pkg:github/package-url/purl-spec@244fd47e07d1004"},
+		{purl: "npm/lodash@4.17.20", fail: true},
+		{purl: "pkg:npm", fail: true},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			p, err := Parse(tc.purl)
+			if tc.fail {
+				if err == nil {
+					t.Fatalf("expecting %q not to be parsed", tc.purl)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Type != tc.typ || p.Namespace != tc.namespace || p.Name != tc.name || p.Version != tc.version {
+				t.Fatalf("wrong package url %+v", p)
+			}
+			if s := p.String(); s != tc.canonical {
+				t.Fatalf("expecting canonical form %q, got %q", tc.canonical, s)
+			}
+		})
+	}
+}
+
+func TestMapping(t *testing.T) {
+	m := DefaultMapping()
+	if err := m.Load(strings.NewReader("# local packages\npkg:pypi/internal-tool cpe:/a:example:internal_tool\n")); err != nil {
+		t.Fatal(err)
+	}
+	for i, tc := range []struct {
+		purl string
+		cpe  string
+	}{
+		{"pkg:pypi/Django@3.2.1", "cpe:/a:djangoproject:django:3.2.1::~~~python~~"},
+		{"pkg:pypi/internal_tool@1.0", "cpe:/a:example:internal_tool:1.0::~~~python~~"},
+		{"pkg:npm/lodash@4.17.20", "cpe:/a:lodash:lodash:4.17.20::~~~node.js~~"},
+		{"pkg:deb/debian/openssl@1.1.1n-0+deb11u4?arch=amd64", "cpe:/a:openssl:openssl:1.1.1n-0%2bdeb11u4"},
+		{"pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1", "cpe:/a:apache:log4j:2.14.1"},
+		{"pkg:github/madler/zlib@1.2.11", "cpe:/a:madler:zlib:1.2.11"},
+		{"pkg:npm/left-pad@1.3.0", "cpe:/a::left-pad:1.3.0::~~~node.js~~"},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			attrs, err := m.ParseToCPE(tc.purl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cpe := attrs.BindToURI(); cpe != tc.cpe {
+				t.Fatalf("expecting %s to be mapped to %s, got %s", tc.purl, tc.cpe, cpe)
+			}
+		})
+	}
+
+	if err := m.Load(strings.NewReader("pkg:pypi/broken")); err == nil {
+		t.Fatal("expecting an error for a line without a cpe")
+	}
+}
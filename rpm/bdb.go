@@ -0,0 +1,125 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpm
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// Berkeley DB hash database, as used by rpm before 4.16
+// https://github.com/berkeleydb/libdb/blob/master/src/dbinc/db_page.h
+const (
+	bdbHashMagic = 0x061561
+
+	bdbPageHeaderSize = 26
+
+	bdbPageHashUnsorted = 2
+	bdbPageOverflow     = 7
+	bdbPageHash         = 13
+
+	// hash item pointing to overflow pages: type, 3 unused bytes, page number and length of the data
+	bdbHashOffPage     = 3
+	bdbHashOffPageSize = 12
+)
+
+// bdbBlobs returns all values stored in the Berkeley DB hash database
+// rpm stores headers as values, keyed by package number; headers are always
+// larger than a page, so only values stored on overflow pages are returned
+func bdbBlobs(data []byte) ([][]byte, error) {
+	if len(data) < 72 {
+		return nil, fmt.Errorf("not a berkeley db: file is too short")
+	}
+	var order binary.ByteOrder
+	switch {
+	case binary.LittleEndian.Uint32(data[12:]) == bdbHashMagic:
+		order = binary.LittleEndian
+	case binary.BigEndian.Uint32(data[12:]) == bdbHashMagic:
+		order = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("not a berkeley db hash database")
+	}
+	if data[24] != 0 {
+		return nil, fmt.Errorf("encrypted berkeley db isn't supported")
+	}
+	pageSize := order.Uint32(data[20:])
+	lastPage := order.Uint32(data[32:])
+	if pageSize < 512 || pageSize > 64<<10 {
+		return nil, fmt.Errorf("invalid berkeley db page size %d", pageSize)
+	}
+
+	page := func(n uint32) ([]byte, error) {
+		start := uint64(n) * uint64(pageSize)
+		if start+uint64(pageSize) > uint64(len(data)) {
+			return nil, fmt.Errorf("page %d is out of file", n)
+		}
+		return data[start : start+uint64(pageSize)], nil
+	}
+
+	var blobs [][]byte
+	for n := uint32(1); n <= lastPage; n++ {
+		p, err := page(n)
+		if err != nil {
+			return nil, err
+		}
+		if typ := p[25]; typ != bdbPageHash && typ != bdbPageHashUnsorted {
+			continue
+		}
+		entries := uint32(order.Uint16(p[20:]))
+		if bdbPageHeaderSize+entries*2 > pageSize {
+			return nil, fmt.Errorf("invalid number of entries on page %d", n)
+		}
+		// entries are key and value pairs
+		for i := uint32(1); i < entries; i += 2 {
+			offset := uint32(order.Uint16(p[bdbPageHeaderSize+i*2:]))
+			if offset+bdbHashOffPageSize > pageSize || p[offset] != bdbHashOffPage {
+				continue
+			}
+			next := order.Uint32(p[offset+4:])
+			length := order.Uint32(p[offset+8:])
+
+			// follow the chain of overflow pages
+			blob := make([]byte, 0, length)
+			for visited := uint32(0); next != 0; visited++ {
+				if visited > lastPage {
+					return nil, fmt.Errorf("loop in overflow pages of page %d", n)
+				}
+				pgno := next
+				op, err := page(pgno)
+				if err != nil {
+					return nil, err
+				}
+				if op[25] != bdbPageOverflow {
+					return nil, fmt.Errorf("page %d isn't an overflow page", pgno)
+				}
+				next = order.Uint32(op[16:])
+				end := pageSize
+				if next == 0 {
+					// the last page stores the number of bytes used in place of free area offset
+					end = bdbPageHeaderSize + uint32(order.Uint16(op[22:]))
+					if end > pageSize {
+						return nil, fmt.Errorf("invalid length of overflow page %d", pgno)
+					}
+				}
+				blob = append(blob, op[bdbPageHeaderSize:end]...)
+			}
+			if uint32(len(blob)) > length {
+				blob = blob[:length]
+			}
+			blobs = append(blobs, blob)
+		}
+	}
+	return blobs, nil
+}
@@ -16,6 +16,7 @@ package rpm
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/facebookincubator/nvdtools/wfn"
 )
@@ -26,6 +27,43 @@ type Checker interface {
 	Check(pkg *Package, distro *wfn.Attributes, cve string) bool
 }
 
+// Result is the result of checking whether some package has been fixed for a CVE
+type Result struct {
+	// Fixed is the same as returned by Check
+	Fixed bool
+	// FixedIn is the first version of the package with the fix, in [epoch:]version-release format
+	// it's set even when the package isn't fixed, so it can be reported as the version to upgrade to
+	// it's empty if it's not known, e.g. when the package was never affected
+	FixedIn string
+	// Advisory is the id of the advisory which fixed the CVE, e.g. RHSA-2020:1234
+	Advisory string
+}
+
+// ResultChecker is a Checker which also knows in which version the package was fixed
+type ResultChecker interface {
+	Checker
+	// CheckResult should return whether a given package on distribution is fixed for some CVE and what fixes it
+	CheckResult(pkg *Package, distro *wfn.Attributes, cve string) Result
+}
+
+// AsResultChecker returns the given checker if it's a ResultChecker
+// otherwise it wraps it, and only Fixed is set in results
+func AsResultChecker(chk Checker) ResultChecker {
+	if rc, ok := chk.(ResultChecker); ok {
+		return rc
+	}
+	return boolChecker{chk}
+}
+
+type boolChecker struct {
+	Checker
+}
+
+// CheckResult is part of the ResultChecker interface
+func (c boolChecker) CheckResult(pkg *Package, distro *wfn.Attributes, cve string) Result {
+	return Result{Fixed: c.Check(pkg, distro, cve)}
+}
+
 // CheckAny returns a Checker which will return true if any of the underlying checkers returns true
 func CheckAny(chks ...Checker) Checker {
 	return anyChecker(chks)
@@ -43,6 +81,23 @@ func (c anyChecker) Check(pkg *Package, distro *wfn.Attributes, cve string) bool
 	return false
 }
 
+// CheckResult is part of the ResultChecker interface
+// if some checker says the package is fixed, its result is returned
+// otherwise the result with the lowest fixed version is returned, it's the closest upgrade
+func (c anyChecker) CheckResult(pkg *Package, distro *wfn.Attributes, cve string) Result {
+	var res Result
+	for _, chk := range c {
+		r := AsResultChecker(chk).CheckResult(pkg, distro, cve)
+		if r.Fixed {
+			return r
+		}
+		if fixedInCompare(r.FixedIn, res.FixedIn) < 0 {
+			res = r
+		}
+	}
+	return res
+}
+
 // CheckAll returns a Checker which will return true if all of the underlying checkers returns true
 func CheckAll(chks ...Checker) Checker {
 	return allChecker(chks)
@@ -63,6 +118,41 @@ func (c allChecker) Check(pkg *Package, distro *wfn.Attributes, cve string) bool
 	return true
 }
 
+// CheckResult is part of the ResultChecker interface
+// the result with the highest fixed version is returned, since all of the fixes are needed
+func (c allChecker) CheckResult(pkg *Package, distro *wfn.Attributes, cve string) Result {
+	res := Result{Fixed: len(c) > 0}
+	var fixed Result
+	for _, chk := range c {
+		r := AsResultChecker(chk).CheckResult(pkg, distro, cve)
+		res.Fixed = res.Fixed && r.Fixed
+		if r.FixedIn != "" && (fixed.FixedIn == "" || fixedInCompare(r.FixedIn, fixed.FixedIn) > 0) {
+			fixed = r
+		}
+	}
+	res.FixedIn, res.Advisory = fixed.FixedIn, fixed.Advisory
+	return res
+}
+
+// fixedInCompare compares two fixed versions, empty version is greater than any other
+// versions which can't be parsed are compared as strings
+func fixedInCompare(v1, v2 string) int {
+	switch {
+	case v1 == v2:
+		return 0
+	case v1 == "":
+		return 1
+	case v2 == "":
+		return -1
+	}
+	l1, err1 := ParseLabel(v1)
+	l2, err2 := ParseLabel(v2)
+	if err1 != nil || err2 != nil {
+		return strings.Compare(v1, v2)
+	}
+	return LabelCompare(l1, l2)
+}
+
 // Check will parse package and distro and call given checker to return
 func Check(chk Checker, pkg, distro, cve string) (bool, error) {
 	p, err := Parse(pkg)
@@ -78,6 +168,22 @@ func Check(chk Checker, pkg, distro, cve string) (bool, error) {
 	return chk.Check(p, d, cve), nil
 }
 
+// CheckResult will parse package and distro and return the structured result of the given checker
+func CheckResult(chk Checker, pkg, distro, cve string) (*Result, error) {
+	p, err := Parse(pkg)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse package %q: %v", pkg, err)
+	}
+
+	d, err := wfn.Parse(distro)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse distro cpe %q: %v", distro, err)
+	}
+
+	res := AsResultChecker(chk).CheckResult(p, d, cve)
+	return &res, nil
+}
+
 // FilterPackages will return those packages which haven't been fixed already on the given distro and for a given cve
 // if some package can't be parsed as an rpm package, it will not be checked and will be included in the output list
 func FilterFixedPackages(chk Checker, pkgs []string, distro, cve string) ([]string, error) {
@@ -15,6 +15,7 @@
 package rpm
 
 import (
+	"fmt"
 	"reflect"
 	"testing"
 
@@ -73,6 +74,82 @@ func TestCheckAnyAndAll(t *testing.T) {
 	}
 }
 
+func TestCheckResult(t *testing.T) {
+	distro := "cpe:/o:vendor:product:version"
+
+	res, err := CheckResult(nameChecker("foo"), "foo-v1-rel.arch.rpm", distro, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, &Result{Fixed: true}) {
+		t.Fatalf("plain checkers should only set fixed, got %+v", res)
+	}
+
+	res, err = CheckResult(versionChecker{"1:1.2-3", "ADV-1"}, "foo-1:1.1-5.arch.rpm", distro, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(res, &Result{FixedIn: "1:1.2-3", Advisory: "ADV-1"}) {
+		t.Fatalf("unexpected result %+v", res)
+	}
+
+	if _, err := CheckResult(nameChecker("foo"), "malformed", distro, ""); err == nil {
+		t.Fatal("expecting an error for a malformed package")
+	}
+}
+
+func TestCheckAnyAndAllResult(t *testing.T) {
+	pkg, err := Parse("foo-1.5-1.arch.rpm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1 := versionChecker{"1.1-1", "ADV-1"}
+	v2 := versionChecker{"1.2-1", "ADV-2"}
+	v3 := versionChecker{"1.10-1", "ADV-3"}
+	v4 := versionChecker{"1.20-1", "ADV-4"}
+
+	for i, tc := range []struct {
+		chk    Checker
+		expect Result
+	}{
+		// the first fixed result
+		{CheckAny(v4, v2, v1), Result{Fixed: true, FixedIn: "1.2-1", Advisory: "ADV-2"}},
+		// the closest upgrade
+		{CheckAny(v4, constChecker(false), v3), Result{FixedIn: "1.10-1", Advisory: "ADV-3"}},
+		{CheckAny(constChecker(false)), Result{}},
+		{CheckAny(constChecker(true), v3), Result{Fixed: true}},
+		// all fixes are needed
+		{CheckAll(v1, v2), Result{Fixed: true, FixedIn: "1.2-1", Advisory: "ADV-2"}},
+		{CheckAll(v1, v4, v3), Result{FixedIn: "1.20-1", Advisory: "ADV-4"}},
+		{CheckAll(v1, constChecker(true)), Result{Fixed: true, FixedIn: "1.1-1", Advisory: "ADV-1"}},
+		{CheckAll(), Result{}},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			if got := AsResultChecker(tc.chk).CheckResult(pkg, nil, ""); got != tc.expect {
+				t.Fatalf("expecting %+v, got %+v", tc.expect, got)
+			}
+		})
+	}
+}
+
+// versionChecker is fixed in the given version by the given advisory
+type versionChecker struct {
+	fixedIn, advisory string
+}
+
+func (c versionChecker) Check(pkg *Package, distro *wfn.Attributes, cve string) bool {
+	return c.CheckResult(pkg, distro, cve).Fixed
+}
+
+func (c versionChecker) CheckResult(pkg *Package, _ *wfn.Attributes, _ string) Result {
+	l, _ := ParseLabel(c.fixedIn)
+	return Result{
+		Fixed:    LabelCompare(pkg.Label, l) >= 0,
+		FixedIn:  c.fixedIn,
+		Advisory: c.advisory,
+	}
+}
+
 // Check method returns true if package name is the one specified
 type nameChecker string
 
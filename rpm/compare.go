@@ -17,8 +17,40 @@ package rpm
 import (
 	"strings"
 	"unicode"
+
+	"github.com/facebookincubator/nvdtools/wfn"
 )
 
+func init() {
+	wfn.RegisterVersionScheme("rpm", labelVersionCompare)
+}
+
+// labelVersionCompare compares versions in [epoch:]version[-release] format,
+// versions which can't be parsed as labels are compared as they are
+func labelVersionCompare(v1, v2 string) int {
+	l1, err1 := ParseLabel(v1)
+	l2, err2 := ParseLabel(v2)
+	if err1 != nil || err2 != nil {
+		return VersionCompare(v1, v2)
+	}
+	return LabelCompare(l1, l2)
+}
+
+// Compare compares two packages and returns -1, 0 or 1 if p1 is older, same or newer than p2
+// packages are ordered by name first, then by label (epoch, version and release) and finally by arch
+// comparing different packages doesn't make much sense, but it gives a total order
+func Compare(p1, p2 *Package) int {
+	if c := strings.Compare(p1.Name, p2.Name); c != 0 {
+		return c
+	}
+	if c := LabelCompare(p1.Label, p2.Label); c != 0 {
+		return c
+	}
+	return strings.Compare(p1.Arch, p2.Arch)
+}
+
+// LabelCompare compares two labels and returns -1, 0 or 1 if l1 is older, same or newer than l2
+// missing epoch is treated as 0
 func LabelCompare(l1, l2 Label) int {
 	// 1. Set each epoch value to 0 if it’s null/None.
 	if l1.Epoch == "" {
@@ -53,6 +85,8 @@ func LabelCompare(l1, l2 Label) int {
 	return 0
 }
 
+// VersionCompare compares two versions (or releases) using the rpmvercmp algorithm
+// it returns -1, 0 or 1 if v1 is older, same or newer than v2
 func VersionCompare(v1, v2 string) int {
 	// 1. If the strings are binary equal (a == b), they’re equal, return 0.
 	if v1 == v2 {
@@ -61,9 +95,9 @@ func VersionCompare(v1, v2 string) int {
 
 	// 2. Loop over the strings, left-to-right.
 	for {
-		// 1. Trim anything that’s not [A-Za-z0-9] or tilde (~) from the front of both strings.
-		v1 = strings.TrimLeftFunc(v1, isntAlnumOrTilde)
-		v2 = strings.TrimLeftFunc(v2, isntAlnumOrTilde)
+		// 1. Trim anything that’s not [A-Za-z0-9], tilde (~) or caret (^) from the front of both strings.
+		v1 = strings.TrimLeftFunc(v1, isntAlnumOrSeparator)
+		v2 = strings.TrimLeftFunc(v2, isntAlnumOrSeparator)
 
 		v1StartsWithTilde := len(v1) > 0 && v1[0] == '~'
 		v2StartsWithTilde := len(v2) > 0 && v2[0] == '~'
@@ -80,7 +114,28 @@ func VersionCompare(v1, v2 string) int {
 			return 1
 		}
 
-		// neither v1 nor v2 start with tilde
+		// caret is like tilde, except that it sorts after the end of the string (rpm >= 4.15)
+		// 1.0^git1 is newer than 1.0, but older than 1.0.1
+		v1StartsWithCaret := len(v1) > 0 && v1[0] == '^'
+		v2StartsWithCaret := len(v2) > 0 && v2[0] == '^'
+
+		if v1StartsWithCaret || v2StartsWithCaret {
+			switch {
+			case len(v1) == 0:
+				return -1
+			case len(v2) == 0:
+				return 1
+			case !v1StartsWithCaret:
+				return 1
+			case !v2StartsWithCaret:
+				return -1
+			}
+			// both start with a caret, discard it and move on
+			v1, v2 = v1[1:], v2[1:]
+			continue
+		}
+
+		// neither v1 nor v2 start with tilde or caret
 		// they start with a letter or digit, or empty
 
 		if len(v1) == 0 || len(v2) == 0 {
@@ -160,8 +215,8 @@ func compareLen(s1, s2 string) int {
 	}
 }
 
-func isntAlnumOrTilde(r rune) bool {
-	return !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '~')
+func isntAlnumOrSeparator(r rune) bool {
+	return !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '~' || r == '^')
 }
 
 func isZero(r rune) bool {
@@ -17,6 +17,8 @@ package rpm
 import (
 	"fmt"
 	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
 )
 
 func TestVersionCompare(t *testing.T) {
@@ -35,6 +37,21 @@ func TestVersionCompare(t *testing.T) {
 		{"0001", "2", -1},
 		{"1", "a", 1},
 		{"a", "1", -1},
+		// caret
+		{"1.0^", "1.0", 1},
+		{"1.0^git1", "1.0", 1},
+		{"1.0^git1", "1.0.1", -1},
+		{"1.0^git1", "1.0^git2", -1},
+		{"1.0^git1", "1.0~rc1", 1},
+		{"1.0~rc1^git1", "1.0~rc1", 1},
+		{"1.0^20160101", "1.0^20160102", -1},
+		{"1.0^1", "1.0^1", 0},
+		// separators
+		{"1.0", "1_0", 0},
+		{"1.0a", "1.0", 1},
+		{"2.0.1", "2.0.1a", -1},
+		{"5.5p1", "5.5p10", -1},
+		{"6.0.rc1", "6.0", 1},
 	}
 
 	for i, c := range cases {
@@ -46,8 +63,69 @@ func TestVersionCompare(t *testing.T) {
 	}
 }
 
+func TestLabelCompare(t *testing.T) {
+	cases := []struct {
+		l1, l2 Label
+		result int
+	}{
+		{Label{"", "1.0", "1"}, Label{"0", "1.0", "1"}, 0},
+		{Label{"1", "1.0", "1"}, Label{"", "2.0", "1"}, 1},
+		{Label{"", "1.0", "1.el7"}, Label{"", "1.0", "1.el7_9"}, -1},
+		{Label{"", "1.0", "2.el7"}, Label{"", "1.0", "10.el7"}, -1},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			if r := LabelCompare(c.l1, c.l2); r != c.result {
+				t.Errorf("compare(%v, %v) = %d, expecting %d", c.l1, c.l2, r, c.result)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	cases := []struct {
+		p1, p2 string
+		result int
+	}{
+		{"bash-4.2.46-34.el7.x86_64", "bash-4.2.46-34.el7.x86_64", 0},
+		{"bash-4.2.46-34.el7.x86_64", "bash-0:4.2.46-34.el7.x86_64", 0},
+		{"bash-4.2.46-34.el7.x86_64", "bash-4.2.46-35.el7.x86_64", -1},
+		{"bash-1:4.2.46-34.el7.x86_64", "bash-5.0-1.el8.x86_64", 1},
+		{"bash-4.2.46-34.el7.i686", "bash-4.2.46-34.el7.x86_64", -1},
+		{"zsh-1.0-1.x86_64", "bash-2.0-1.x86_64", 1},
+	}
+
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			p1, err := Parse(c.p1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p2, err := Parse(c.p2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r := Compare(p1, p2); r != c.result {
+				t.Errorf("compare(%q, %q) = %d, expecting %d", c.p1, c.p2, r, c.result)
+			}
+		})
+	}
+}
+
 func BenchmarkVersionCompare(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		VersionCompare("1a2b3c4d", "1a2b3c4de")
 	}
 }
+
+func TestVersionScheme(t *testing.T) {
+	// epoch wins over the version
+	r := wfn.VersionRange{StartIncluding: "1:0.5", EndExcluding: "1:2.0", Scheme: "rpm"}
+	if !r.Contains("1:1.0-1") {
+		t.Errorf("1:1.0-1 should be in %v", r)
+	}
+	if r.Contains("1.9-1") {
+		t.Errorf("1.9-1 shouldn't be in %v", r)
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpm
+
+import (
+	"regexp"
+	"strings"
+)
+
+// distTagRE matches dist tags in package releases, e.g. el8 in 9.el8_7 or module+el8.5.0+123+abc
+var distTagRE = regexp.MustCompile(`(?:^|[.+])(el|fc|amzn|mga|ol)(\d+)(?:[._+~]|$)`)
+
+// distros maps dist tag prefixes to distribution names, as used in target_sw of distribution feeds
+var distros = map[string]string{
+	"amzn": "amazon_linux",
+	"el":   "rhel",
+	"fc":   "fedora",
+	"mga":  "mageia",
+	"ol":   "oracle_linux",
+}
+
+// Dist is the distribution a package was built for, found in its release
+type Dist struct {
+	Tag     string // e.g. el8
+	Name    string // e.g. rhel
+	Version string // e.g. 8
+}
+
+// ParseDist returns the distribution the package with the given release was built for,
+// or nil if the release doesn't have a known dist tag
+func ParseDist(release string) *Dist {
+	m := distTagRE.FindStringSubmatch(release)
+	if m == nil {
+		return nil
+	}
+	return &Dist{
+		Tag:     m[1] + m[2],
+		Name:    distros[m[1]],
+		Version: m[2],
+	}
+}
+
+// TargetSW returns the distribution as CPE target_sw, e.g. rhel_8
+func (d *Dist) TargetSW() string {
+	return d.Name + "_" + d.Version
+}
+
+// archs maps RPM architectures to CPE target_hw names used by NVD
+var archs = map[string]string{
+	"x86_64":  "x64",
+	"amd64":   "x64",
+	"i386":    "x86",
+	"i486":    "x86",
+	"i586":    "x86",
+	"i686":    "x86",
+	"athlon":  "x86",
+	"aarch64": "arm64",
+	"armv7hl": "arm",
+	"armv7l":  "arm",
+	"armhfp":  "arm",
+}
+
+// ArchToTargetHW maps RPM architecture to CPE target_hw, e.g. x86_64 to x64 and aarch64 to arm64.
+// Architectures without a common CPE name are returned unchanged.
+func ArchToTargetHW(arch string) string {
+	if hw, ok := archs[strings.ToLower(arch)]; ok {
+		return hw
+	}
+	return arch
+}
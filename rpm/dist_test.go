@@ -0,0 +1,57 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpm
+
+import "testing"
+
+func TestParseDist(t *testing.T) {
+	cases := []struct {
+		release  string
+		targetSW string
+	}{
+		{"9.el8_7", "rhel_8"},
+		{"1.el9", "rhel_9"},
+		{"3.module+el8.5.0+12345+abcdef", "rhel_8"},
+		{"3.fc38", "fedora_38"},
+		{"1.amzn2.0.1", "amazon_linux_2"},
+		{"2.amzn2023", "amazon_linux_2023"},
+		{"1.mga9", "mageia_9"},
+		{"1", ""},
+		{"1.elixir2", ""},
+	}
+	for _, c := range cases {
+		var targetSW string
+		if dist := ParseDist(c.release); dist != nil {
+			targetSW = dist.TargetSW()
+		}
+		if targetSW != c.targetSW {
+			t.Errorf("%q: expected %q, got %q", c.release, c.targetSW, targetSW)
+		}
+	}
+}
+
+func TestArchToTargetHW(t *testing.T) {
+	for arch, hw := range map[string]string{
+		"x86_64":  "x64",
+		"i686":    "x86",
+		"aarch64": "arm64",
+		"armv7hl": "arm",
+		"ppc64le": "ppc64le",
+	} {
+		if have := ArchToTargetHW(arch); have != hw {
+			t.Errorf("%q: expected %q, got %q", arch, hw, have)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpm
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// ndb database, the native format of rpm used by SUSE
+// https://github.com/rpm-software-management/rpm/blob/master/lib/backend/ndb/rpmpkg.c
+const (
+	ndbMagic     = 'R' | 'p'<<8 | 'm'<<16 | 'P'<<24
+	ndbSlotMagic = 'S' | 'l'<<8 | 'o'<<16 | 't'<<24
+	ndbBlobMagic = 'B' | 'l'<<8 | 'b'<<16 | 'S'<<24
+
+	ndbHeaderSize     = 32
+	ndbSlotSize       = 16
+	ndbSlotsPerPage   = 4096 / ndbSlotSize
+	ndbBlockSize      = 16
+	ndbBlobHeaderSize = 16
+)
+
+// ndbBlobs returns all blobs stored in the ndb database
+// the header is followed by slots, each slot points to a blob: package index, block offset and block count
+func ndbBlobs(data []byte) ([][]byte, error) {
+	if len(data) < ndbHeaderSize || binary.LittleEndian.Uint32(data) != ndbMagic {
+		return nil, fmt.Errorf("not an ndb database")
+	}
+	slotPages := uint64(binary.LittleEndian.Uint32(data[12:]))
+	// header takes the place of the first two slots
+	slots := slotPages*ndbSlotsPerPage - 2
+	if slotPages == 0 || ndbHeaderSize+slots*ndbSlotSize > uint64(len(data)) {
+		return nil, fmt.Errorf("invalid number of ndb slot pages %d", slotPages)
+	}
+
+	var blobs [][]byte
+	for i := uint64(0); i < slots; i++ {
+		slot := data[ndbHeaderSize+i*ndbSlotSize:]
+		if binary.LittleEndian.Uint32(slot) != ndbSlotMagic {
+			return nil, fmt.Errorf("invalid ndb slot %d", i)
+		}
+		pkgIdx := binary.LittleEndian.Uint32(slot[4:])
+		if pkgIdx == 0 {
+			// empty slot
+			continue
+		}
+		start := uint64(binary.LittleEndian.Uint32(slot[8:])) * ndbBlockSize
+		if start+ndbBlobHeaderSize > uint64(len(data)) {
+			return nil, fmt.Errorf("ndb blob of package %d is out of file", pkgIdx)
+		}
+		blob := data[start:]
+		if binary.LittleEndian.Uint32(blob) != ndbBlobMagic || binary.LittleEndian.Uint32(blob[4:]) != pkgIdx {
+			return nil, fmt.Errorf("invalid ndb blob of package %d", pkgIdx)
+		}
+		length := uint64(binary.LittleEndian.Uint32(blob[12:]))
+		if ndbBlobHeaderSize+length > uint64(len(blob)) {
+			return nil, fmt.Errorf("ndb blob of package %d is out of file", pkgIdx)
+		}
+		blobs = append(blobs, blob[ndbBlobHeaderSize:ndbBlobHeaderSize+length])
+	}
+	return blobs, nil
+}
@@ -33,6 +33,34 @@ type Label struct {
 	Release string
 }
 
+// String returns the label in [epoch:]version-release format
+func (l Label) String() string {
+	s := l.Version
+	if l.Release != "" {
+		s += "-" + l.Release
+	}
+	if l.Epoch != "" {
+		s = l.Epoch + ":" + s
+	}
+	return s
+}
+
+// ParseLabel parses a label in [epoch:]version-release format
+func ParseLabel(s string) (Label, error) {
+	var l Label
+	if i := strings.IndexByte(s, ':'); i >= 0 {
+		l.Epoch, s = s[:i], s[i+1:]
+	}
+	if i := strings.LastIndexByte(s, '-'); i >= 0 {
+		s, l.Release = s[:i], s[i+1:]
+	}
+	if s == "" {
+		return Label{}, fmt.Errorf("can't find version in label")
+	}
+	l.Version = s
+	return l, nil
+}
+
 // FieldsFromRPMName returns name, version, release and architecture parsed from RPM package name
 // NEVRA: https://blog.jasonantman.com/2014/07/how-yum-and-rpm-compare-versions/
 func Parse(pkg string) (*Package, error) {
@@ -153,3 +153,30 @@ func BenchmarkParse(t *testing.B) {
 		Parse("NaMe-1.0-1.i386.rpm")
 	}
 }
+
+func TestLabelString(t *testing.T) {
+	for i, tc := range []struct {
+		label Label
+		str   string
+	}{
+		{Label{Version: "1.2"}, "1.2"},
+		{Label{Version: "1.2", Release: "3.el8"}, "1.2-3.el8"},
+		{Label{Epoch: "1", Version: "1.2", Release: "3.el8"}, "1:1.2-3.el8"},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			if got := tc.label.String(); got != tc.str {
+				t.Fatalf("expecting %q, got %q", tc.str, got)
+			}
+			l, err := ParseLabel(tc.str)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if l != tc.label {
+				t.Fatalf("expecting %+v, got %+v", tc.label, l)
+			}
+		})
+	}
+	if _, err := ParseLabel("1:"); err == nil {
+		t.Fatal("expecting an error for a label without version")
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+)
+
+// header tags and types, see rpmtag.h
+const (
+	tagName    = 1000
+	tagVersion = 1001
+	tagRelease = 1002
+	tagEpoch   = 1003
+	tagArch    = 1022
+
+	typeInt32       = 4
+	typeString      = 6
+	typeStringArray = 8
+	typeI18NString  = 9
+
+	// limits of header blobs, as rpm enforces them
+	maxHeaderEntries = 0xffff
+	maxHeaderData    = 256 << 20
+)
+
+// ParseDB returns packages installed in the rpm database, all the formats rpm uses are supported:
+// Berkeley DB (Packages), ndb (Packages.db) and sqlite (rpmdb.sqlite); the format is detected from the content
+// gpg-pubkey pseudo packages are skipped
+func ParseDB(data []byte) ([]*Package, error) {
+	var blobs [][]byte
+	var err error
+	switch {
+	case bytes.HasPrefix(data, []byte(sqliteMagic)):
+		blobs, err = sqliteBlobs(data, "Packages", 1)
+	case len(data) >= 4 && binary.LittleEndian.Uint32(data) == ndbMagic:
+		blobs, err = ndbBlobs(data)
+	default:
+		blobs, err = bdbBlobs(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pkgs := make([]*Package, 0, len(blobs))
+	for _, blob := range blobs {
+		pkg, err := parseHeader(blob)
+		if err != nil {
+			return nil, err
+		}
+		if pkg.Name == "gpg-pubkey" {
+			continue
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	return pkgs, nil
+}
+
+// parseHeader parses package from the header blob stored in rpm database
+// the blob is a header without the lead magic: number of index entries, size of data, index entries and data
+func parseHeader(blob []byte) (*Package, error) {
+	if len(blob) < 8 {
+		return nil, fmt.Errorf("header blob is too short: %d bytes", len(blob))
+	}
+	il := binary.BigEndian.Uint32(blob)
+	dl := binary.BigEndian.Uint32(blob[4:])
+	if il > maxHeaderEntries || dl > maxHeaderData || uint64(len(blob)) < 8+uint64(il)*16+uint64(dl) {
+		return nil, fmt.Errorf("invalid header blob: %d entries, %d bytes of data", il, dl)
+	}
+	index, data := blob[8:8+il*16], blob[8+il*16:8+il*16+dl]
+
+	var pkg Package
+	for i := uint32(0); i < il; i++ {
+		entry := index[i*16:]
+		tag := binary.BigEndian.Uint32(entry)
+		typ := binary.BigEndian.Uint32(entry[4:])
+		offset := binary.BigEndian.Uint32(entry[8:])
+		if offset >= dl {
+			continue
+		}
+
+		var dst *string
+		switch tag {
+		case tagName:
+			dst = &pkg.Name
+		case tagVersion:
+			dst = &pkg.Label.Version
+		case tagRelease:
+			dst = &pkg.Label.Release
+		case tagArch:
+			dst = &pkg.Arch
+		case tagEpoch:
+			if typ == typeInt32 && offset+4 <= dl {
+				pkg.Label.Epoch = strconv.FormatUint(uint64(binary.BigEndian.Uint32(data[offset:])), 10)
+			}
+			continue
+		default:
+			continue
+		}
+		switch typ {
+		case typeString, typeStringArray, typeI18NString:
+			// arrays hold NUL terminated strings one after another, the first one is used
+			s := data[offset:]
+			if j := bytes.IndexByte(s, 0); j >= 0 {
+				s = s[:j]
+			}
+			*dst = string(s)
+		}
+	}
+
+	if pkg.Name == "" {
+		return nil, fmt.Errorf("no name found in header")
+	}
+	if pkg.Arch == "noarch" {
+		pkg.Arch = ""
+	}
+	return &pkg, nil
+}
@@ -0,0 +1,196 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpm
+
+import (
+	"encoding/binary"
+	"os"
+	"reflect"
+	"testing"
+)
+
+// testHeader creates a header blob of the package, pad adds binary data to make it longer
+func testHeader(pkg Package, pad int) []byte {
+	var index, data []byte
+	add := func(tag, typ uint32, value []byte) {
+		for typ == typeInt32 && len(data)%4 != 0 {
+			data = append(data, 0)
+		}
+		entry := make([]byte, 16)
+		binary.BigEndian.PutUint32(entry, tag)
+		binary.BigEndian.PutUint32(entry[4:], typ)
+		binary.BigEndian.PutUint32(entry[8:], uint32(len(data)))
+		binary.BigEndian.PutUint32(entry[12:], 1)
+		index = append(index, entry...)
+		data = append(data, value...)
+	}
+	str := func(s string) []byte { return append([]byte(s), 0) }
+
+	add(tagName, typeString, str(pkg.Name))
+	if pkg.Epoch != "" {
+		add(tagEpoch, typeInt32, []byte{0, 0, 0, pkg.Epoch[0] - '0'})
+	}
+	add(tagVersion, typeString, str(pkg.Version))
+	add(tagRelease, typeString, str(pkg.Release))
+	add(tagArch, typeString, str(pkg.Arch))
+	add(1005, 7, make([]byte, pad))
+
+	blob := make([]byte, 8)
+	binary.BigEndian.PutUint32(blob, uint32(len(index)/16))
+	binary.BigEndian.PutUint32(blob[4:], uint32(len(data)))
+	return append(append(blob, index...), data...)
+}
+
+// testBDB creates a berkeley db hash database with the blobs stored on overflow pages
+func testBDB(blobs [][]byte) []byte {
+	const pageSize = 512
+	le := binary.LittleEndian
+	pages := [][]byte{make([]byte, pageSize), make([]byte, pageSize)}
+	meta, hash := pages[0], pages[1]
+	le.PutUint32(meta[12:], bdbHashMagic)
+	le.PutUint32(meta[20:], pageSize)
+
+	hash[25] = bdbPageHash
+	le.PutUint16(hash[20:], uint16(2*len(blobs)))
+	end := pageSize
+	for i, blob := range blobs {
+		// key, package number
+		end -= 5
+		hash[end] = 1
+		le.PutUint32(hash[end+1:], uint32(i+1))
+		le.PutUint16(hash[bdbPageHeaderSize+4*i:], uint16(end))
+
+		// value, on overflow pages
+		end -= bdbHashOffPageSize
+		hash[end] = bdbHashOffPage
+		le.PutUint32(hash[end+4:], uint32(len(pages)))
+		le.PutUint32(hash[end+8:], uint32(len(blob)))
+		le.PutUint16(hash[bdbPageHeaderSize+4*i+2:], uint16(end))
+		for len(blob) > 0 {
+			p := make([]byte, pageSize)
+			p[25] = bdbPageOverflow
+			n := copy(p[bdbPageHeaderSize:], blob)
+			if blob = blob[n:]; len(blob) > 0 {
+				le.PutUint32(p[16:], uint32(len(pages)+1))
+			} else {
+				le.PutUint16(p[22:], uint16(n))
+			}
+			pages = append(pages, p)
+		}
+	}
+	le.PutUint32(meta[32:], uint32(len(pages)-1))
+
+	var db []byte
+	for _, p := range pages {
+		db = append(db, p...)
+	}
+	return db
+}
+
+// testNDB creates an ndb database with one page of slots
+func testNDB(blobs [][]byte) []byte {
+	le := binary.LittleEndian
+	db := make([]byte, 4096)
+	le.PutUint32(db, ndbMagic)
+	le.PutUint32(db[12:], 1)
+	for i := 0; i < ndbSlotsPerPage-2; i++ {
+		le.PutUint32(db[ndbHeaderSize+i*ndbSlotSize:], ndbSlotMagic)
+	}
+	for i, blob := range blobs {
+		// leave the first slot empty
+		slot := db[ndbHeaderSize+(i+1)*ndbSlotSize:]
+		le.PutUint32(slot[4:], uint32(i+1))
+		le.PutUint32(slot[8:], uint32(len(db)/ndbBlockSize))
+
+		hdr := make([]byte, ndbBlobHeaderSize)
+		le.PutUint32(hdr, ndbBlobMagic)
+		le.PutUint32(hdr[4:], uint32(i+1))
+		le.PutUint32(hdr[12:], uint32(len(blob)))
+		db = append(append(db, hdr...), blob...)
+		for len(db)%ndbBlockSize != 0 {
+			db = append(db, 0)
+		}
+	}
+	return db
+}
+
+var testPackages = []*Package{
+	{Name: "bash", Label: Label{Version: "5.1.8", Release: "6.el9"}, Arch: "x86_64"},
+	{Name: "openssl", Label: Label{Epoch: "1", Version: "3.0.7", Release: "24.el9"}, Arch: "x86_64"},
+	{Name: "tzdata", Label: Label{Version: "2023c", Release: "1.el9"}},
+}
+
+func TestParseDB(t *testing.T) {
+	blobs := [][]byte{
+		testHeader(*testPackages[0], 1500),
+		testHeader(*testPackages[1], 0),
+		testHeader(*testPackages[2], 700),
+		testHeader(Package{Name: "gpg-pubkey", Label: Label{Version: "5a6340b3", Release: "6229229e"}}, 0),
+	}
+	for name, db := range map[string][]byte{
+		"bdb": testBDB(blobs),
+		"ndb": testNDB(blobs),
+	} {
+		t.Run(name, func(t *testing.T) {
+			pkgs, err := ParseDB(db)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(pkgs, testPackages) {
+				t.Fatalf("have: %+v\nwant: %+v", pkgs, testPackages)
+			}
+		})
+	}
+}
+
+func TestParseDBSQLite(t *testing.T) {
+	// 44 packages on 512 bytes pages, so there are interior and overflow pages
+	db, err := os.ReadFile("testdata/rpmdb.sqlite")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgs, err := ParseDB(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pkgs) != 43 {
+		t.Fatalf("expecting 43 packages, got %d", len(pkgs))
+	}
+	if !reflect.DeepEqual(pkgs[:3], testPackages) {
+		t.Fatalf("have: %+v\nwant: %+v", pkgs[:3], testPackages)
+	}
+	want := &Package{Name: "filler39", Label: Label{Version: "1.0", Release: "39.el9"}, Arch: "aarch64"}
+	if !reflect.DeepEqual(pkgs[42], want) {
+		t.Fatalf("have: %+v\nwant: %+v", pkgs[42], want)
+	}
+}
+
+func TestParseDBInvalid(t *testing.T) {
+	blob := testHeader(*testPackages[0], 1500)
+	for name, db := range map[string][]byte{
+		"empty":     nil,
+		"garbage":   make([]byte, 4096),
+		"truncated": testBDB([][]byte{blob})[:1024],
+		"ndb":       testNDB([][]byte{blob})[:4096],
+		"header":    testBDB([][]byte{blob[:100]}),
+		"sqlite":    []byte(sqliteMagic + "\x02\x00"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseDB(db); err == nil {
+				t.Fatal("expected invalid database to fail")
+			}
+		})
+	}
+}
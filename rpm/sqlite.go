@@ -0,0 +1,278 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rpm
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// sqlite database, as used by rpm since 4.16
+// only reading of table b-trees is implemented, which is enough to read all rows of a table
+// https://www.sqlite.org/fileformat.html
+const (
+	sqliteMagic = "SQLite format 3\x00"
+
+	sqlitePageInteriorTable = 0x05
+	sqlitePageLeafTable     = 0x0d
+
+	// b-trees aren't deeper than this, unless the file is corrupted
+	sqliteMaxDepth = 32
+)
+
+// sqliteDB is a read only sqlite database
+type sqliteDB struct {
+	data     []byte
+	pageSize uint32
+	usable   uint32
+}
+
+// sqliteBlobs returns values of the given column of all rows of the table
+func sqliteBlobs(data []byte, table string, column int) ([][]byte, error) {
+	if len(data) < 100 || string(data[:len(sqliteMagic)]) != sqliteMagic {
+		return nil, fmt.Errorf("not an sqlite database")
+	}
+	db := sqliteDB{data: data, pageSize: uint32(binary.BigEndian.Uint16(data[16:]))}
+	if db.pageSize == 1 {
+		db.pageSize = 65536
+	}
+	if db.pageSize < 512 || uint32(data[20]) >= db.pageSize-480 {
+		return nil, fmt.Errorf("invalid sqlite page size %d", db.pageSize)
+	}
+	db.usable = db.pageSize - uint32(data[20])
+
+	// schema table is stored on the first page: type, name, table name, root page and sql
+	var root uint32
+	err := db.walk(1, 0, func(payload []byte) error {
+		cols, err := sqliteRecord(payload)
+		if err != nil {
+			return err
+		}
+		if len(cols) > 3 && cols[0].text() == "table" && cols[1].text() == table {
+			root = uint32(cols[3].int())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if root == 0 {
+		return nil, fmt.Errorf("table %s not found", table)
+	}
+
+	var blobs [][]byte
+	err = db.walk(root, 0, func(payload []byte) error {
+		cols, err := sqliteRecord(payload)
+		if err != nil {
+			return err
+		}
+		if column < len(cols) {
+			blobs = append(blobs, cols[column].data)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return blobs, nil
+}
+
+func (db *sqliteDB) page(n uint32) ([]byte, error) {
+	start := (uint64(n) - 1) * uint64(db.pageSize)
+	if n == 0 || start+uint64(db.pageSize) > uint64(len(db.data)) {
+		return nil, fmt.Errorf("sqlite page %d is out of file", n)
+	}
+	return db.data[start : start+uint64(db.pageSize)], nil
+}
+
+// walk calls fn with payload of every row in the table b-tree rooted at the given page
+func (db *sqliteDB) walk(n uint32, depth int, fn func([]byte) error) error {
+	if depth > sqliteMaxDepth {
+		return fmt.Errorf("sqlite b-tree is too deep")
+	}
+	p, err := db.page(n)
+	if err != nil {
+		return err
+	}
+	hdr := uint32(0)
+	if n == 1 {
+		// page 1 starts with the database header
+		hdr = 100
+	}
+	cells := uint32(binary.BigEndian.Uint16(p[hdr+3:]))
+
+	switch p[hdr] {
+	case sqlitePageInteriorTable:
+		// cells are left child page number and key, the right-most child is in the header
+		if hdr+12+cells*2 > db.usable {
+			return fmt.Errorf("invalid number of cells on sqlite page %d", n)
+		}
+		for i := uint32(0); i < cells; i++ {
+			offset := uint32(binary.BigEndian.Uint16(p[hdr+12+i*2:]))
+			if offset+4 > db.usable {
+				return fmt.Errorf("invalid cell offset on sqlite page %d", n)
+			}
+			if err := db.walk(binary.BigEndian.Uint32(p[offset:]), depth+1, fn); err != nil {
+				return err
+			}
+		}
+		return db.walk(binary.BigEndian.Uint32(p[hdr+8:]), depth+1, fn)
+	case sqlitePageLeafTable:
+		// cells are payload size, row id and payload, which can spill to overflow pages
+		if hdr+8+cells*2 > db.usable {
+			return fmt.Errorf("invalid number of cells on sqlite page %d", n)
+		}
+		for i := uint32(0); i < cells; i++ {
+			offset := uint32(binary.BigEndian.Uint16(p[hdr+8+i*2:]))
+			if offset >= db.usable {
+				return fmt.Errorf("invalid cell offset on sqlite page %d", n)
+			}
+			size, k := sqliteVarint(p[offset:db.usable])
+			offset += uint32(k)
+			_, l := sqliteVarint(p[offset:db.usable])
+			offset += uint32(l)
+			if k == 0 || l == 0 {
+				return fmt.Errorf("invalid cell %d on sqlite page %d", i, n)
+			}
+			payload, err := db.payload(p[offset:db.usable], size)
+			if err != nil {
+				return fmt.Errorf("can't read cell %d on sqlite page %d: %v", i, n, err)
+			}
+			if err := fn(payload); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("sqlite page %d isn't a table b-tree page", n)
+	}
+}
+
+// payload returns the payload of the given size stored in the cell and overflow pages
+func (db *sqliteDB) payload(cell []byte, size uint64) ([]byte, error) {
+	u := uint64(db.usable)
+	local := size
+	if maxLocal := u - 35; size > maxLocal {
+		minLocal := (u-12)*32/255 - 23
+		local = minLocal + (size-minLocal)%(u-4)
+		if local > maxLocal {
+			local = minLocal
+		}
+	}
+	if local > uint64(len(cell)) || local < size && local+4 > uint64(len(cell)) {
+		return nil, fmt.Errorf("payload is out of page")
+	}
+
+	payload := make([]byte, 0, size)
+	payload = append(payload, cell[:local]...)
+	if local == size {
+		return payload, nil
+	}
+	next := binary.BigEndian.Uint32(cell[local:])
+	for uint64(len(payload)) < size {
+		if next == 0 {
+			return nil, fmt.Errorf("overflow pages end before the end of payload")
+		}
+		p, err := db.page(next)
+		if err != nil {
+			return nil, err
+		}
+		next = binary.BigEndian.Uint32(p)
+		chunk := p[4:db.usable]
+		if rest := size - uint64(len(payload)); rest < uint64(len(chunk)) {
+			chunk = chunk[:rest]
+		}
+		payload = append(payload, chunk...)
+	}
+	return payload, nil
+}
+
+// sqliteValue is a value of a column in the record
+type sqliteValue struct {
+	typ  uint64
+	data []byte
+}
+
+func (v sqliteValue) text() string {
+	if v.typ >= 13 && v.typ%2 == 1 {
+		return string(v.data)
+	}
+	return ""
+}
+
+func (v sqliteValue) int() int64 {
+	switch {
+	case v.typ >= 1 && v.typ <= 6:
+		// big endian two's complement integer
+		n := int64(int8(v.data[0]))
+		for _, b := range v.data[1:] {
+			n = n<<8 | int64(b)
+		}
+		return n
+	case v.typ == 9:
+		return 1
+	}
+	return 0
+}
+
+// sqliteRecord parses a record: header size, types of columns and values of columns
+func sqliteRecord(rec []byte) ([]sqliteValue, error) {
+	hdrSize, k := sqliteVarint(rec)
+	if k == 0 || hdrSize > uint64(len(rec)) {
+		return nil, fmt.Errorf("invalid sqlite record header")
+	}
+	var values []sqliteValue
+	body := hdrSize
+	for pos := uint64(k); pos < hdrSize; {
+		typ, k := sqliteVarint(rec[pos:hdrSize])
+		if k == 0 {
+			return nil, fmt.Errorf("invalid sqlite record header")
+		}
+		pos += uint64(k)
+
+		var size uint64
+		switch {
+		case typ <= 4:
+			size = typ
+		case typ == 5:
+			size = 6
+		case typ == 6 || typ == 7:
+			size = 8
+		case typ >= 12:
+			size = (typ - 12) / 2
+		}
+		if body+size > uint64(len(rec)) {
+			return nil, fmt.Errorf("sqlite record is too short")
+		}
+		values = append(values, sqliteValue{typ: typ, data: rec[body : body+size]})
+		body += size
+	}
+	return values, nil
+}
+
+// sqliteVarint decodes a big endian variable length integer, it returns 0 length if the input is too short
+func sqliteVarint(b []byte) (uint64, int) {
+	var v uint64
+	for i := 0; i < 9 && i < len(b); i++ {
+		if i == 8 {
+			return v<<8 | uint64(b[i]), 9
+		}
+		v = v<<7 | uint64(b[i]&0x7f)
+		if b[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	return 0, 0
+}
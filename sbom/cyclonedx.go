@@ -0,0 +1,107 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// https://cyclonedx.org/docs/1.5/json/
+// https://cyclonedx.org/docs/1.5/xml/
+
+type cycloneDXBOM struct {
+	BOMFormat string `json:"bomFormat"`
+	Metadata  *struct {
+		Component *cycloneDXComponent `json:"component" xml:"component"`
+	} `json:"metadata" xml:"metadata"`
+	Components []*cycloneDXComponent `json:"components" xml:"components>component"`
+}
+
+type cycloneDXComponent struct {
+	BOMRef     string                `json:"bom-ref" xml:"bom-ref,attr"`
+	Type       string                `json:"type" xml:"type,attr"`
+	Group      string                `json:"group" xml:"group"`
+	Name       string                `json:"name" xml:"name"`
+	Version    string                `json:"version" xml:"version"`
+	CPE        string                `json:"cpe" xml:"cpe"`
+	PURL       string                `json:"purl" xml:"purl"`
+	Components []*cycloneDXComponent `json:"components" xml:"components>component"`
+}
+
+// ReadCycloneDX reads all components, including the nested ones and the one the BOM describes,
+// from a CycloneDX BOM in either JSON or XML format
+func ReadCycloneDX(r io.Reader) ([]*Component, error) {
+	br := bufio.NewReader(r)
+	var bom cycloneDXBOM
+	if firstChar(br) == '<' {
+		if err := xml.NewDecoder(br).Decode(&bom); err != nil {
+			return nil, fmt.Errorf("can't decode cyclonedx xml: %v", err)
+		}
+	} else {
+		if err := json.NewDecoder(br).Decode(&bom); err != nil {
+			return nil, fmt.Errorf("can't decode cyclonedx json: %v", err)
+		}
+		if bom.BOMFormat != "CycloneDX" {
+			return nil, fmt.Errorf("unsupported bom format %q", bom.BOMFormat)
+		}
+	}
+
+	var components []*Component
+	var walk func([]*cycloneDXComponent)
+	walk = func(cs []*cycloneDXComponent) {
+		for _, c := range cs {
+			if c == nil {
+				continue
+			}
+			component := Component{
+				Ref:     c.BOMRef,
+				Type:    c.Type,
+				Group:   c.Group,
+				Name:    c.Name,
+				Version: c.Version,
+				PURL:    c.PURL,
+			}
+			if c.CPE != "" {
+				component.CPEs = []string{c.CPE}
+			}
+			components = append(components, &component)
+			walk(c.Components)
+		}
+	}
+	if bom.Metadata != nil {
+		walk([]*cycloneDXComponent{bom.Metadata.Component})
+	}
+	walk(bom.Components)
+	return components, nil
+}
+
+// firstChar returns the first character which isn't a space or the utf-8 byte order mark, without consuming it
+func firstChar(br *bufio.Reader) byte {
+	for i := 1; ; i++ {
+		b, err := br.Peek(i)
+		if err != nil {
+			return 0
+		}
+		switch c := b[i-1]; c {
+		case ' ', '\t', '\r', '\n', 0xef, 0xbb, 0xbf:
+		default:
+			return c
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const testCycloneDXJSON = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.5",
+  "version": 1,
+  "metadata": {"component": {"bom-ref": "app", "type": "application", "name": "example-app", "version": "1.0.0"}},
+  "components": [
+    {"bom-ref": "pkg:pypi/django@3.2.1", "type": "library", "name": "Django", "version": "3.2.1", "purl": "pkg:pypi/django@3.2.1",
+     "cpe": "cpe:2.3:a:djangoproject:django:3.2.1:*:*:*:*:*:*:*"},
+    {"bom-ref": "lodash", "type": "library", "name": "lodash", "purl": "pkg:npm/lodash@4.17.20",
+     "components": [{"bom-ref": "nested", "type": "library", "name": "Left Pad", "version": "1.3.0"}]}
+  ]
+}`
+
+const testCycloneDXXML = `<?xml version="1.0" encoding="UTF-8"?>
+<bom xmlns="http://cyclonedx.org/schema/bom/1.5" version="1">
+  <components>
+    <component type="library" bom-ref="openssl">
+      <name>openssl</name>
+      <version>1.1.1k</version>
+      <cpe>cpe:/a:openssl:openssl:1.1.1k</cpe>
+    </component>
+    <component type="operating-system" bom-ref="os">
+      <name>Alpine</name>
+      <version>3.18</version>
+    </component>
+  </components>
+</bom>`
+
+func TestReadCycloneDX(t *testing.T) {
+	for i, tc := range []struct {
+		bom    string
+		expect []string
+	}{
+		{
+			bom: testCycloneDXJSON,
+			expect: []string{
+				"app cpe:/a::example-app:1.0.0",
+				"pkg:pypi/django@3.2.1 cpe:/a:djangoproject:django:3.2.1",
+				"lodash cpe:/a:lodash:lodash:4.17.20::~~~node.js~~",
+				"nested cpe:/a::left_pad:1.3.0",
+			},
+		},
+		{
+			bom: testCycloneDXXML,
+			expect: []string{
+				"openssl cpe:/a:openssl:openssl:1.1.1k",
+				"os cpe:/o::alpine:3.18",
+			},
+		},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			components, err := ReadCycloneDX(strings.NewReader(tc.bom))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, c := range components {
+				attrs, err := c.Attributes()
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, c.Ref+" "+attrs[0].BindToURI())
+			}
+			if strings.Join(got, "\n") != strings.Join(tc.expect, "\n") {
+				t.Fatalf("got:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(tc.expect, "\n"))
+			}
+		})
+	}
+
+	if _, err := ReadCycloneDX(strings.NewReader(`{"spdxVersion": "SPDX-2.3"}`)); err == nil {
+		t.Fatal("expecting an error for non cyclonedx document")
+	}
+}
@@ -0,0 +1,115 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sbom reads software components from SBOM documents, so they can be matched against vulnerability feeds.
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/purl"
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// Component is a software component listed in an SBOM
+type Component struct {
+	// Ref identifies the component in the document: bom-ref in CycloneDX, SPDXID in SPDX
+	Ref     string
+	Type    string
+	Group   string
+	Name    string
+	Version string
+	CPEs    []string
+	PURL    string
+}
+
+// Read reads components from a CycloneDX or an SPDX document, the format is detected from the content
+func Read(r io.Reader) ([]*Component, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	switch firstChar(bufio.NewReader(bytes.NewReader(data))) {
+	case '<':
+		return ReadCycloneDX(bytes.NewReader(data))
+	case '{':
+		var doc struct {
+			BOMFormat string `json:"bomFormat"`
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("can't decode sbom: %v", err)
+		}
+		if doc.BOMFormat != "" {
+			return ReadCycloneDX(bytes.NewReader(data))
+		}
+		return ReadSPDX(bytes.NewReader(data))
+	default:
+		return ReadSPDX(bytes.NewReader(data))
+	}
+}
+
+// defaultMapping is used to map package urls of components to CPEs
+var defaultMapping = purl.DefaultMapping()
+
+// Attributes returns CPE attributes of the component to match vulnerabilities against
+// CPEs listed in the SBOM are used if there are any, otherwise the attributes are derived
+// from the package url using the default purl mapping or, if there isn't one, from name and version of the component
+func (c *Component) Attributes() ([]*wfn.Attributes, error) {
+	return c.AttributesWith(defaultMapping)
+}
+
+// AttributesWith returns CPE attributes of the component, using the given mapping for its package url
+func (c *Component) AttributesWith(m *purl.Mapping) ([]*wfn.Attributes, error) {
+	var attrs []*wfn.Attributes
+	for _, cpe := range c.CPEs {
+		a, err := wfn.Parse(cpe)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse cpe %q of component %q: %v", cpe, c.Name, err)
+		}
+		attrs = append(attrs, a)
+	}
+	if len(attrs) != 0 {
+		return attrs, nil
+	}
+
+	if c.PURL != "" {
+		a, err := m.ParseToCPE(c.PURL)
+		if err != nil {
+			return nil, fmt.Errorf("can't map purl of component %q: %v", c.Name, err)
+		}
+		return []*wfn.Attributes{a}, nil
+	}
+	if c.Name == "" {
+		return nil, fmt.Errorf("component %q doesn't have a name", c.Ref)
+	}
+
+	a := wfn.Attributes{Part: "a"}
+	var err error
+	if c.Type == "operating-system" {
+		a.Part = "o"
+	}
+	if a.Product, err = wfn.WFNize(strings.ToLower(c.Name)); err != nil {
+		return nil, fmt.Errorf("can't wfnize name %q: %v", c.Name, err)
+	}
+	if a.Version, err = wfn.WFNize(c.Version); err != nil {
+		return nil, fmt.Errorf("can't wfnize version %q: %v", c.Version, err)
+	}
+	return []*wfn.Attributes{&a}, nil
+}
@@ -0,0 +1,177 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// https://spdx.github.io/spdx-spec/v2.3/
+// https://spdx.github.io/spdx-spec/v3.0.1/
+
+type spdx2Document struct {
+	SPDXVersion string          `json:"spdxVersion"`
+	Packages    []*spdx2Package `json:"packages"`
+}
+
+type spdx2Package struct {
+	SPDXID       string `json:"SPDXID"`
+	Name         string `json:"name"`
+	VersionInfo  string `json:"versionInfo"`
+	ExternalRefs []struct {
+		ReferenceCategory string `json:"referenceCategory"`
+		ReferenceType     string `json:"referenceType"`
+		ReferenceLocator  string `json:"referenceLocator"`
+	} `json:"externalRefs"`
+}
+
+// spdx3Document is an SPDX 3 document serialized as JSON-LD, only packages of the graph are read
+type spdx3Document struct {
+	Context interface{}     `json:"@context"`
+	Graph   []*spdx3Element `json:"@graph"`
+}
+
+type spdx3Element struct {
+	Type               string `json:"type"`
+	SPDXID             string `json:"spdxId"`
+	Name               string `json:"name"`
+	PackageVersion     string `json:"software_packageVersion"`
+	PackageURL         string `json:"software_packageUrl"`
+	ExternalIdentifier []struct {
+		ExternalIdentifierType string `json:"externalIdentifierType"`
+		Identifier             string `json:"identifier"`
+	} `json:"externalIdentifier"`
+}
+
+// ReadSPDX reads all packages from an SPDX 2.3 document in JSON or tag-value format, or an SPDX 3 JSON-LD document
+func ReadSPDX(r io.Reader) ([]*Component, error) {
+	br := bufio.NewReader(r)
+	if firstChar(br) != '{' {
+		return readSPDXTagValue(br)
+	}
+
+	var doc struct {
+		spdx2Document
+		spdx3Document
+	}
+	if err := json.NewDecoder(br).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("can't decode spdx json: %v", err)
+	}
+
+	var components []*Component
+	switch {
+	case strings.HasPrefix(doc.SPDXVersion, "SPDX-2."):
+		for _, p := range doc.Packages {
+			c := Component{Ref: p.SPDXID, Name: p.Name, Version: p.VersionInfo}
+			for _, ref := range p.ExternalRefs {
+				c.addRef(ref.ReferenceType, ref.ReferenceLocator)
+			}
+			components = append(components, &c)
+		}
+	case doc.Context != nil:
+		for _, e := range doc.Graph {
+			if e.Type != "software_Package" {
+				continue
+			}
+			c := Component{Ref: e.SPDXID, Name: e.Name, Version: e.PackageVersion, PURL: e.PackageURL}
+			for _, id := range e.ExternalIdentifier {
+				switch id.ExternalIdentifierType {
+				case "cpe22", "cpe23":
+					c.CPEs = append(c.CPEs, id.Identifier)
+				case "packageUrl":
+					if c.PURL == "" {
+						c.PURL = id.Identifier
+					}
+				}
+			}
+			components = append(components, &c)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported spdx version %q", doc.SPDXVersion)
+	}
+	return components, nil
+}
+
+// readSPDXTagValue reads packages from an SPDX 2 document in tag-value format
+// every PackageName tag starts a new package, multi line <text> values are skipped
+func readSPDXTagValue(r io.Reader) ([]*Component, error) {
+	var components []*Component
+	var cur *Component
+	inText := false
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if inText {
+			inText = !strings.Contains(line, "</text>")
+			continue
+		}
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.IndexByte(line, ':')
+		if i < 0 {
+			return nil, fmt.Errorf("invalid spdx tag-value at line %d: %q", n, line)
+		}
+		tag, value := line[:i], strings.TrimSpace(line[i+1:])
+		if strings.HasPrefix(value, "<text>") && !strings.Contains(value, "</text>") {
+			inText = true
+			continue
+		}
+		switch {
+		case tag == "SPDXVersion" && !strings.HasPrefix(value, "SPDX-2."):
+			return nil, fmt.Errorf("unsupported spdx version %q", value)
+		case tag == "PackageName":
+			cur = &Component{Name: value}
+			components = append(components, cur)
+		case tag == "FileName" || tag == "SnippetSPDXID":
+			// packages are followed by files and snippets, which aren't read
+			cur = nil
+		case cur == nil:
+		case tag == "SPDXID":
+			cur.Ref = value
+		case tag == "PackageVersion":
+			cur.Version = value
+		case tag == "ExternalRef":
+			if fields := strings.Fields(value); len(fields) == 3 {
+				cur.addRef(fields[1], fields[2])
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("can't read spdx tag-value: %v", err)
+	}
+	if len(components) == 0 {
+		return nil, fmt.Errorf("spdx document doesn't contain any packages")
+	}
+	return components, nil
+}
+
+// addRef adds cpe and purl external references of SPDX 2 packages
+func (c *Component) addRef(typ, locator string) {
+	switch typ {
+	case "cpe22Type", "cpe23Type":
+		c.CPEs = append(c.CPEs, locator)
+	case "purl":
+		if c.PURL == "" {
+			c.PURL = locator
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const testSPDX23JSON = `{
+  "spdxVersion": "SPDX-2.3",
+  "SPDXID": "SPDXRef-DOCUMENT",
+  "name": "example",
+  "packages": [
+    {"SPDXID": "SPDXRef-Package-django", "name": "Django", "versionInfo": "3.2.1",
+     "externalRefs": [
+       {"referenceCategory": "SECURITY", "referenceType": "cpe23Type", "referenceLocator": "cpe:2.3:a:djangoproject:django:3.2.1:*:*:*:*:*:*:*"},
+       {"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:pypi/django@3.2.1"}
+     ]},
+    {"SPDXID": "SPDXRef-Package-requests", "name": "requests", "versionInfo": "2.25.0",
+     "externalRefs": [{"referenceCategory": "PACKAGE_MANAGER", "referenceType": "purl", "referenceLocator": "pkg:pypi/requests@2.25.1"}]}
+  ]
+}`
+
+const testSPDX30JSON = `{
+  "@context": "https://spdx.org/rdf/3.0.1/spdx-context.jsonld",
+  "@graph": [
+    {"type": "CreationInfo", "@id": "_:creationinfo", "specVersion": "3.0.1"},
+    {"type": "software_Package", "spdxId": "urn:example:openssl", "name": "openssl", "software_packageVersion": "3.0.7",
+     "externalIdentifier": [{"type": "ExternalIdentifier", "externalIdentifierType": "cpe23", "identifier": "cpe:2.3:a:openssl:openssl:3.0.7:*:*:*:*:*:*:*"}]},
+    {"type": "software_Package", "spdxId": "urn:example:zlib", "name": "zlib", "software_packageVersion": "1.2.13", "software_packageUrl": "pkg:generic/zlib@1.2.13"},
+    {"type": "software_File", "spdxId": "urn:example:file", "name": "README"}
+  ]
+}`
+
+const testSPDXTagValue = `SPDXVersion: SPDX-2.3
+DataLicense: CC0-1.0
+SPDXID: SPDXRef-DOCUMENT
+DocumentName: example
+DocumentComment: <text>multi line comment
+PackageName: not a package
+</text>
+
+## packages
+PackageName: curl
+SPDXID: SPDXRef-Package-curl
+PackageVersion: 7.55.0
+ExternalRef: SECURITY cpe22Type cpe:/a:haxx:curl:7.55.0
+PackageDescription: <text>command line tool</text>
+
+PackageName: glibc
+SPDXID: SPDXRef-Package-glibc
+PackageVersion: 2.28
+ExternalRef: PACKAGE-MANAGER purl pkg:rpm/centos/glibc@2.28?arch=x86_64
+
+FileName: ./bin/curl
+SPDXID: SPDXRef-File-curl
+`
+
+func TestReadSPDX(t *testing.T) {
+	for i, tc := range []struct {
+		doc    string
+		expect []string
+	}{
+		{
+			doc: testSPDX23JSON,
+			expect: []string{
+				"SPDXRef-Package-django cpe:/a:djangoproject:django:3.2.1",
+				"SPDXRef-Package-requests cpe:/a:python:requests:2.25.1::~~~python~~",
+			},
+		},
+		{
+			doc: testSPDX30JSON,
+			expect: []string{
+				"urn:example:openssl cpe:/a:openssl:openssl:3.0.7",
+				"urn:example:zlib cpe:/a:zlib:zlib:1.2.13",
+			},
+		},
+		{
+			doc: testSPDXTagValue,
+			expect: []string{
+				"SPDXRef-Package-curl cpe:/a:haxx:curl:7.55.0",
+				"SPDXRef-Package-glibc cpe:/a:gnu:glibc:2.28",
+			},
+		},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			// also make sure the format is detected
+			components, err := Read(strings.NewReader(tc.doc))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, c := range components {
+				attrs, err := c.Attributes()
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, c.Ref+" "+attrs[0].BindToURI())
+			}
+			if strings.Join(got, "\n") != strings.Join(tc.expect, "\n") {
+				t.Fatalf("got:\n%s\nexpected:\n%s", strings.Join(got, "\n"), strings.Join(tc.expect, "\n"))
+			}
+		})
+	}
+
+	if _, err := ReadSPDX(strings.NewReader(`{"spdxVersion": "SPDX-1.2"}`)); err == nil {
+		t.Fatal("expecting an error for unsupported spdx version")
+	}
+}
@@ -0,0 +1,228 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package semver parses and compares semantic versions.
+// Partial versions (1 or 1.2) and a leading v are accepted, missing parts are zero.
+// https://semver.org/spec/v2.0.0.html
+package semver
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func init() {
+	wfn.RegisterVersionScheme("semver", func(v1, v2 string) int {
+		c, err := CompareVersions(v1, v2)
+		if err != nil {
+			// not a semantic version, do the best we can
+			return wfn.SmartVerCmp(v1, v2)
+		}
+		return c
+	})
+}
+
+// Version is a semantic version
+type Version struct {
+	Major uint64
+	Minor uint64
+	Patch uint64
+	// Prerelease holds dot separated pre-release identifiers, e.g. [rc 1] for 1.0.0-rc.1
+	Prerelease []string
+	// Build is the build metadata, it's ignored when versions are compared
+	Build string
+	// Parts is the number of numeric parts the version was given with, from 1 to 3;
+	// zero means all three
+	Parts int
+}
+
+// Parse parses the version in [v]major[.minor[.patch]][-prerelease][+build] format
+func Parse(version string) (*Version, error) {
+	s := strings.TrimPrefix(version, "v")
+	var v Version
+
+	if i := strings.IndexByte(s, '+'); i >= 0 {
+		s, v.Build = s[:i], s[i+1:]
+		if err := checkIdentifiers(v.Build); err != nil {
+			return nil, fmt.Errorf("invalid build metadata in version %q: %v", version, err)
+		}
+	}
+	if i := strings.IndexByte(s, '-'); i >= 0 {
+		var pre string
+		s, pre = s[:i], s[i+1:]
+		if err := checkIdentifiers(pre); err != nil {
+			return nil, fmt.Errorf("invalid pre-release in version %q: %v", version, err)
+		}
+		v.Prerelease = strings.Split(pre, ".")
+	}
+
+	parts := strings.Split(s, ".")
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("too many parts in version %q", version)
+	}
+	nums := []*uint64{&v.Major, &v.Minor, &v.Patch}
+	for i, p := range parts {
+		if p == "" || strings.TrimLeftFunc(p, isDigit) != "" {
+			return nil, fmt.Errorf("part %q of version %q should be a number", p, version)
+		}
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("can't parse part %q of version %q: %v", p, version, err)
+		}
+		*nums[i] = n
+	}
+	v.Parts = len(parts)
+
+	return &v, nil
+}
+
+// checkIdentifiers checks dot separated identifiers of pre-release or build metadata
+func checkIdentifiers(s string) error {
+	for _, id := range strings.Split(s, ".") {
+		if id == "" {
+			return fmt.Errorf("empty identifier")
+		}
+		for _, c := range id {
+			if !isDigit(c) && !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && c != '-' {
+				return fmt.Errorf("illegal character %q in identifier %q", c, id)
+			}
+		}
+	}
+	return nil
+}
+
+// String returns the version with as many numeric parts as it was given with
+func (v Version) String() string {
+	nums := []uint64{v.Major, v.Minor, v.Patch}
+	parts := v.Parts
+	if parts <= 0 || parts > 3 {
+		parts = 3
+	}
+	ss := make([]string, parts)
+	for i := range ss {
+		ss[i] = strconv.FormatUint(nums[i], 10)
+	}
+	s := strings.Join(ss, ".")
+	if len(v.Prerelease) != 0 {
+		s += "-" + strings.Join(v.Prerelease, ".")
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Bump returns the version with the i-th numeric part (0 is major) incremented and the following parts zeroed,
+// without pre-release and build metadata, e.g. bumping 1.2.3-rc.1 at 1 gives 1.3.0
+func (v Version) Bump(i int) Version {
+	b := Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch, Parts: v.Parts}
+	switch i {
+	case 0:
+		b.Major, b.Minor, b.Patch = b.Major+1, 0, 0
+	case 1:
+		b.Minor, b.Patch = b.Minor+1, 0
+	default:
+		i = 2
+		b.Patch++
+	}
+	if b.Parts != 0 && b.Parts < i+1 {
+		b.Parts = i + 1
+	}
+	return b
+}
+
+// CompareVersions parses and compares two semantic versions
+func CompareVersions(v1, v2 string) (int, error) {
+	ver1, err := Parse(v1)
+	if err != nil {
+		return 0, err
+	}
+	ver2, err := Parse(v2)
+	if err != nil {
+		return 0, err
+	}
+	return Compare(*ver1, *ver2), nil
+}
+
+// Compare compares two versions by their precedence
+// it returns -1, 0 or 1 if v1 is older, same or newer than v2
+func Compare(v1, v2 Version) int {
+	if c := compareUint(v1.Major, v2.Major); c != 0 {
+		return c
+	}
+	if c := compareUint(v1.Minor, v2.Minor); c != 0 {
+		return c
+	}
+	if c := compareUint(v1.Patch, v2.Patch); c != 0 {
+		return c
+	}
+
+	// a pre-release has lower precedence than the release
+	switch {
+	case len(v1.Prerelease) == 0 && len(v2.Prerelease) == 0:
+		return 0
+	case len(v1.Prerelease) == 0:
+		return 1
+	case len(v2.Prerelease) == 0:
+		return -1
+	}
+
+	for i := 0; i < len(v1.Prerelease) && i < len(v2.Prerelease); i++ {
+		if c := compareIdentifier(v1.Prerelease[i], v2.Prerelease[i]); c != 0 {
+			return c
+		}
+	}
+	// larger set of identifiers has higher precedence if all of the preceding ones are equal
+	return compareUint(uint64(len(v1.Prerelease)), uint64(len(v2.Prerelease)))
+}
+
+// compareIdentifier compares pre-release identifiers: numeric ones numerically, others lexically
+// numeric identifiers have lower precedence than others
+func compareIdentifier(id1, id2 string) int {
+	num1 := strings.TrimLeftFunc(id1, isDigit) == ""
+	num2 := strings.TrimLeftFunc(id2, isDigit) == ""
+	switch {
+	case num1 && num2:
+		// compare by length first, so numbers of any size can be compared
+		id1, id2 = strings.TrimLeft(id1, "0"), strings.TrimLeft(id2, "0")
+		if c := compareUint(uint64(len(id1)), uint64(len(id2))); c != 0 {
+			return c
+		}
+		return strings.Compare(id1, id2)
+	case num1:
+		return -1
+	case num2:
+		return 1
+	default:
+		return strings.Compare(id1, id2)
+	}
+}
+
+func compareUint(n1, n2 uint64) int {
+	switch {
+	case n1 < n2:
+		return -1
+	case n1 > n2:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
@@ -0,0 +1,137 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package semver
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		in     string
+		expect string
+		fail   bool
+	}{
+		{in: "1.2.3", expect: "1.2.3"},
+		{in: "v1.2.3", expect: "1.2.3"},
+		{in: "1.2", expect: "1.2"},
+		{in: "1", expect: "1"},
+		{in: "1.0.0-rc.1+build.5", expect: "1.0.0-rc.1+build.5"},
+		{in: "0.0.0-20210101000000-abcdef012345", expect: "0.0.0-20210101000000-abcdef012345"},
+		{in: "1.2.3.4", fail: true},
+		{in: "1..3", fail: true},
+		{in: "1.x", fail: true},
+		{in: "1.0.0-", fail: true},
+		{in: "1.0.0-rc..1", fail: true},
+		{in: "1.0.0+b_1", fail: true},
+		{in: "", fail: true},
+	}
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			v, err := Parse(c.in)
+			if c.fail {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", v)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := v.String(); s != c.expect {
+				t.Fatalf("expected %q, got %q", c.expect, s)
+			}
+		})
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	cases := []struct {
+		v1, v2 string
+		result int
+	}{
+		{"1.0.0", "1.0.0", 0},
+		{"1.0", "1.0.0", 0},
+		{"v1.0.0", "1.0.0", 0},
+		{"1.0.0+build1", "1.0.0+build2", 0},
+		{"1.9.0", "1.10.0", -1},
+		{"2.0.0", "1.99.99", 1},
+		// examples from the spec: 1.0.0-alpha < 1.0.0-alpha.1 < 1.0.0-alpha.beta < 1.0.0-beta <
+		// 1.0.0-beta.2 < 1.0.0-beta.11 < 1.0.0-rc.1 < 1.0.0
+		{"1.0.0-alpha", "1.0.0-alpha.1", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-alpha.beta", "1.0.0-beta", -1},
+		{"1.0.0-beta", "1.0.0-beta.2", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-beta.11", "1.0.0-rc.1", -1},
+		{"1.0.0-rc.1", "1.0.0", -1},
+		{"1.0.0-99999999999999999999999", "1.0.0-100000000000000000000000", -1},
+	}
+	for _, c := range cases {
+		t.Run(fmt.Sprintf("%s vs %s", c.v1, c.v2), func(t *testing.T) {
+			r, err := CompareVersions(c.v1, c.v2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r != c.result {
+				t.Fatalf("expected %d, got %d", c.result, r)
+			}
+			if r, _ := CompareVersions(c.v2, c.v1); r != -c.result {
+				t.Fatalf("expected %d when reversed, got %d", -c.result, r)
+			}
+		})
+	}
+}
+
+func TestBump(t *testing.T) {
+	cases := []struct {
+		in     string
+		i      int
+		expect string
+	}{
+		{"1.2.3", 0, "2.0.0"},
+		{"1.2.3-rc.1", 1, "1.3.0"},
+		{"1.2.3+build", 2, "1.2.4"},
+		{"1", 0, "2"},
+		{"1.2", 0, "2.0"},
+		{"1", 1, "1.1"},
+	}
+	for _, c := range cases {
+		v, err := Parse(c.in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := v.Bump(c.i).String(); s != c.expect {
+			t.Errorf("bumping %q at %d: expected %q, got %q", c.in, c.i, c.expect, s)
+		}
+	}
+}
+
+func TestVersionScheme(t *testing.T) {
+	r := wfn.VersionRange{StartIncluding: "1.0.0", EndExcluding: "2.0.0", Scheme: "semver"}
+	if r.Contains("2.0.0-rc.1") == (wfn.VersionRange{StartIncluding: "1.0.0", EndExcluding: "2.0.0"}).Contains("2.0.0-rc.1") {
+		t.Error("semver and the default scheme should disagree about 2.0.0-rc.1")
+	}
+	if !r.Contains("2.0.0-rc.1") {
+		t.Errorf("2.0.0-rc.1 should be in %v", r)
+	}
+	// not a semantic version
+	if !r.Contains("1.5.0.1") {
+		t.Errorf("1.5.0.1 should be in %v", r)
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shutdown
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// File is an output file which is written to a temporary file next to it and renamed when it's committed,
+// so commands which are shut down or fail don't leave partial output behind.
+//
+//	f, err := shutdown.CreateFile(path)
+//	if err != nil {
+//		return err
+//	}
+//	defer f.Remove()
+//	... write to f, stop when ctx is done ...
+//	return f.Commit()
+type File struct {
+	*os.File
+	path      string
+	committed bool
+}
+
+// CreateFile creates a temporary file for the output at path
+func CreateFile(path string) (*File, error) {
+	tmp, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return nil, err
+	}
+	return &File{File: tmp, path: path}, nil
+}
+
+// Name returns the path of the output, rather than of the temporary file
+func (f *File) Name() string {
+	return f.path
+}
+
+// Commit closes the file and renames it to its path
+func (f *File) Commit() error {
+	if err := f.File.Chmod(0644); err != nil {
+		return err
+	}
+	if err := f.File.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.File.Name(), f.path); err != nil {
+		return err
+	}
+	f.committed = true
+	return nil
+}
+
+// Remove closes and removes the temporary file unless the file was committed, it's meant to be deferred
+func (f *File) Remove() {
+	if f.committed {
+		return
+	}
+	f.File.Close()
+	os.Remove(f.File.Name())
+}
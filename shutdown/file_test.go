@@ -0,0 +1,67 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shutdown
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shutdown")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	removed := filepath.Join(dir, "removed.json")
+	f, err := CreateFile(removed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString("{"); err != nil {
+		t.Fatal(err)
+	}
+	f.Remove()
+
+	committed := filepath.Join(dir, "committed.json")
+	f, err = CreateFile(committed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Remove()
+	if f.Name() != committed {
+		t.Fatalf("expecting name %q, got %q", committed, f.Name())
+	}
+	if _, err := f.WriteString("{}"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name() != "committed.json" {
+		t.Fatalf("expecting only committed.json, got %v", files)
+	}
+	if data, err := ioutil.ReadFile(committed); err != nil || string(data) != "{}" {
+		t.Fatalf("unexpected content %q: %v", data, err)
+	}
+}
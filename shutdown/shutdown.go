@@ -0,0 +1,73 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package shutdown lets commands stop gracefully when they're asked to: their context is cancelled
+// on SIGINT or SIGTERM, so downloads and long loops can stop and clean up instead of being killed.
+//
+//	ctx, stop := shutdown.Context(context.Background())
+//	defer stop()
+//	if err := run(ctx); err != nil {
+//		...
+//	}
+package shutdown
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/facebookincubator/nvdtools/logging"
+)
+
+// Signals are the signals which shut commands down
+var Signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// Context returns a copy of parent which is cancelled when the process receives one of Signals;
+// if it receives another one while shutting down, it exits at once, in case shutting down hangs.
+// stop cancels the context and restores the default handling of the signals, it should be called
+// as soon as the context isn't needed anymore.
+func Context(parent context.Context) (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancel(parent)
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, Signals...)
+
+	stopped := make(chan struct{})
+	var once sync.Once
+	stop = func() {
+		once.Do(func() {
+			signal.Stop(signals)
+			close(stopped)
+		})
+		cancel()
+	}
+
+	go func() {
+		select {
+		case sig := <-signals:
+			logging.Warningf("received %v, shutting down; send it again to exit at once", sig)
+			cancel()
+		case <-stopped:
+			return
+		}
+		select {
+		case sig := <-signals:
+			logging.Errorf("received %v again, exiting", sig)
+			os.Exit(1)
+		case <-stopped:
+		}
+	}()
+	return ctx, stop
+}
@@ -0,0 +1,55 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shutdown
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestContext(t *testing.T) {
+	ctx, stop := Context(context.Background())
+	defer stop()
+	if ctx.Err() != nil {
+		t.Fatal("context is done before the signal")
+	}
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("context isn't cancelled by the signal")
+	}
+	if ctx.Err() != context.Canceled {
+		t.Fatalf("expected context to be cancelled, got %v", ctx.Err())
+	}
+}
+
+func TestContextStop(t *testing.T) {
+	parent, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	ctx, stop := Context(parent)
+	<-ctx.Done()
+	if ctx.Err() != context.DeadlineExceeded {
+		t.Fatalf("expected deadline of parent to be exceeded, got %v", ctx.Err())
+	}
+	// stopping more than once is fine
+	stop()
+	stop()
+}
@@ -0,0 +1,204 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package stix converts vulnerabilities to STIX 2.1 vulnerability objects, which threat intelligence
+// platforms such as MISP and OpenCTI can import.
+package stix
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+// SpecVersion is the version of STIX objects
+const SpecVersion = "2.1"
+
+// TimeLayout is the layout of STIX timestamps, which are always in UTC
+const TimeLayout = "2006-01-02T15:04:05.000Z"
+
+// namespace is the UUID namespace of deterministic identifiers, the one STIX 2.1 defines for SCOs
+var namespace = [16]byte{0x00, 0xab, 0xed, 0xb4, 0xaa, 0x42, 0x46, 0x6c, 0x9c, 0x01, 0xfe, 0xd2, 0x33, 0x15, 0xa9, 0xb7}
+
+// Vulnerability is a STIX 2.1 vulnerability SDO
+type Vulnerability struct {
+	Type               string              `json:"type"`
+	SpecVersion        string              `json:"spec_version"`
+	ID                 string              `json:"id"`
+	Created            string              `json:"created"`
+	Modified           string              `json:"modified"`
+	Name               string              `json:"name"`
+	Description        string              `json:"description,omitempty"`
+	ExternalReferences []ExternalReference `json:"external_references,omitempty"`
+	// CVSS is the x_cvss custom property, with CVSS scores by version: v2, v3 and v4
+	CVSS map[string]CVSS `json:"x_cvss,omitempty"`
+}
+
+// ExternalReference is a STIX external reference
+type ExternalReference struct {
+	SourceName  string `json:"source_name"`
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url,omitempty"`
+	ExternalID  string `json:"external_id,omitempty"`
+}
+
+// CVSS is a CVSS score of a vulnerability
+type CVSS struct {
+	VectorString string  `json:"vector_string"`
+	BaseScore    float64 `json:"base_score"`
+}
+
+// FromVuln converts the vulnerability to a vulnerability SDO. Its identifier is derived from the ID of
+// the vulnerability, so converting a newer version of the same vulnerability results in an object with
+// the same identifier; now is used as the creation time if the vulnerability doesn't have any dates
+func FromVuln(v cvefeed.Vuln, lang string, now time.Time) *Vulnerability {
+	created, modified := v.Published(), v.LastModified()
+	if created.IsZero() {
+		created = modified
+	}
+	if created.IsZero() {
+		created = now
+	}
+	if modified.Before(created) {
+		modified = created
+	}
+
+	sdo := Vulnerability{
+		Type:        "vulnerability",
+		SpecVersion: SpecVersion,
+		ID:          "vulnerability--" + uuid5(v.ID()),
+		Created:     created.UTC().Format(TimeLayout),
+		Modified:    modified.UTC().Format(TimeLayout),
+		Name:        v.ID(),
+		Description: cvefeed.Description(v, lang),
+	}
+	for _, cve := range v.CVEs() {
+		sdo.ExternalReferences = append(sdo.ExternalReferences, ExternalReference{
+			SourceName: "cve",
+			ExternalID: cve,
+			URL:        "https://nvd.nist.gov/vuln/detail/" + cve,
+		})
+	}
+	for _, cwe := range v.CWEs() {
+		ref := ExternalReference{SourceName: "cwe", ExternalID: cwe}
+		if n := strings.TrimPrefix(cwe, "CWE-"); n != cwe {
+			ref.URL = "https://cwe.mitre.org/data/definitions/" + n + ".html"
+		}
+		sdo.ExternalReferences = append(sdo.ExternalReferences, ref)
+	}
+	for _, r := range v.References() {
+		source := r.Source
+		if source == "" {
+			source = "url"
+		}
+		ref := ExternalReference{SourceName: source, URL: r.URL}
+		if r.Name != "" && r.Name != r.URL {
+			ref.Description = r.Name
+		}
+		sdo.ExternalReferences = append(sdo.ExternalReferences, ref)
+	}
+
+	scores := map[string]CVSS{}
+	if vector := v.CVSSv2Vector(); vector != "" {
+		scores["v2"] = CVSS{vector, v.CVSSv2BaseScore()}
+	}
+	if vector := v.CVSSv3Vector(); vector != "" {
+		scores["v3"] = CVSS{vector, v.CVSSv3BaseScore()}
+	}
+	if vector := v.CVSSv4Vector(); vector != "" {
+		scores["v4"] = CVSS{vector, v.CVSSv4BaseScore()}
+	}
+	if len(scores) != 0 {
+		sdo.CVSS = scores
+	}
+	return &sdo
+}
+
+// BundleWriter writes objects to a STIX bundle one by one, so the bundle doesn't have to be kept in memory
+type BundleWriter struct {
+	w   io.Writer
+	id  string
+	n   int
+	err error
+}
+
+// NewBundleWriter starts a new bundle with a random identifier
+func NewBundleWriter(w io.Writer) *BundleWriter {
+	return &BundleWriter{w: w, id: "bundle--" + uuid4()}
+}
+
+// Write adds the object to the bundle
+func (bw *BundleWriter) Write(obj interface{}) error {
+	if bw.err != nil {
+		return bw.err
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	sep := ",\n"
+	if bw.n == 0 {
+		sep = fmt.Sprintf(`{"type":"bundle","id":%q,"objects":[`, bw.id) + "\n"
+	}
+	if _, bw.err = io.WriteString(bw.w, sep); bw.err == nil {
+		_, bw.err = bw.w.Write(data)
+	}
+	bw.n++
+	return bw.err
+}
+
+// Close ends the bundle, it doesn't close the underlying writer
+func (bw *BundleWriter) Close() error {
+	if bw.err != nil {
+		return bw.err
+	}
+	if bw.n == 0 {
+		// objects can't be an empty list
+		_, bw.err = fmt.Fprintf(bw.w, `{"type":"bundle","id":%q}`+"\n", bw.id)
+		return bw.err
+	}
+	_, bw.err = io.WriteString(bw.w, "\n]}\n")
+	return bw.err
+}
+
+// uuid5 returns the name based (version 5) uuid of the name
+func uuid5(name string) string {
+	h := sha1.New()
+	h.Write(namespace[:])
+	h.Write([]byte(name))
+	var b [16]byte
+	copy(b[:], h.Sum(nil))
+	b[6] = b[6]&0x0f | 0x50
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b)
+}
+
+// uuid4 returns a random (version 4) uuid
+func uuid4() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b)
+}
+
+func formatUUID(b [16]byte) string {
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,105 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stix
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/facebookincubator/nvdtools/cvefeed"
+)
+
+const testFeed = `{"CVE_Items": [{
+	"cve": {
+		"CVE_data_meta": {"ID": "CVE-2020-0001"},
+		"problemtype": {"problemtype_data": [{"description": [{"lang": "en", "value": "CWE-79"}, {"lang": "en", "value": "NVD-CWE-Other"}]}]},
+		"references": {"reference_data": [{"url": "https://example.com/a", "name": "advisory", "refsource": "MISC"}, {"url": "https://example.com/b", "name": "https://example.com/b"}]},
+		"description": {"description_data": [{"lang": "en", "value": "bad"}]}
+	},
+	"configurations": {"nodes": []},
+	"impact": {"baseMetricV3": {"cvssV3": {"vectorString": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", "baseScore": 9.8}}},
+	"publishedDate": "2020-01-02T03:04Z",
+	"lastModifiedDate": "2020-02-03T04:05Z"
+}]}`
+
+func testVuln(t *testing.T) cvefeed.Vuln {
+	vulns, err := cvefeed.ParseJSON(strings.NewReader(testFeed))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vulns[0]
+}
+
+func TestFromVuln(t *testing.T) {
+	sdo := FromVuln(testVuln(t), "en", time.Now())
+	expected := &Vulnerability{
+		Type:        "vulnerability",
+		SpecVersion: "2.1",
+		ID:          "vulnerability--37c0be85-6968-565d-8313-aa5952d5e98b",
+		Created:     "2020-01-02T03:04:00.000Z",
+		Modified:    "2020-02-03T04:05:00.000Z",
+		Name:        "CVE-2020-0001",
+		Description: "bad",
+		ExternalReferences: []ExternalReference{
+			{SourceName: "cve", ExternalID: "CVE-2020-0001", URL: "https://nvd.nist.gov/vuln/detail/CVE-2020-0001"},
+			{SourceName: "cwe", ExternalID: "CWE-79", URL: "https://cwe.mitre.org/data/definitions/79.html"},
+			{SourceName: "cwe", ExternalID: "NVD-CWE-Other"},
+			{SourceName: "MISC", URL: "https://example.com/a", Description: "advisory"},
+			{SourceName: "url", URL: "https://example.com/b"},
+		},
+		CVSS: map[string]CVSS{
+			"v3": {VectorString: "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H", BaseScore: 9.8},
+		},
+	}
+	if !reflect.DeepEqual(sdo, expected) {
+		t.Fatalf("expected\n%+v\ngot\n%+v", expected, sdo)
+	}
+}
+
+func TestBundleWriter(t *testing.T) {
+	var buf bytes.Buffer
+	bw := NewBundleWriter(&buf)
+	for i := 0; i < 2; i++ {
+		if err := bw.Write(FromVuln(testVuln(t), "en", time.Now())); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := bw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var bundle struct {
+		Type    string
+		ID      string
+		Objects []Vulnerability
+	}
+	if err := json.Unmarshal(buf.Bytes(), &bundle); err != nil {
+		t.Fatalf("can't decode bundle: %v\n%s", err, buf.String())
+	}
+	if bundle.Type != "bundle" || !strings.HasPrefix(bundle.ID, "bundle--") || len(bundle.Objects) != 2 {
+		t.Fatalf("unexpected bundle: %+v", bundle)
+	}
+
+	buf.Reset()
+	if err := NewBundleWriter(&buf).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "objects") || !json.Valid(buf.Bytes()) {
+		t.Fatalf("unexpected empty bundle: %s", buf.String())
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// azureVersion is the version of the Blob service API used
+const azureVersion = "2021-08-06"
+
+// azureStore is a container of Azure Blob Storage, authorized with a shared access signature
+type azureStore struct {
+	restClient
+	container, prefix string
+	endpoint          string
+	// sas is the shared access signature query, requests are anonymous if it's empty
+	sas url.Values
+}
+
+func newAzure(container, prefix string, params url.Values) (*azureStore, error) {
+	s := &azureStore{
+		container: container,
+		prefix:    prefix,
+		endpoint:  strings.TrimSuffix(params.Get("endpoint"), "/"),
+	}
+	if s.endpoint == "" {
+		account := setting(params, "account", "AZURE_STORAGE_ACCOUNT")
+		if account == "" {
+			return nil, fmt.Errorf("azure storage account of container %q isn't set", container)
+		}
+		s.endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", account)
+	}
+	sas, err := url.ParseQuery(strings.TrimPrefix(getenv("AZURE_STORAGE_SAS_TOKEN"), "?"))
+	if err != nil {
+		return nil, fmt.Errorf("can't parse azure shared access signature: %v", err)
+	}
+	s.sas = sas
+	return s, nil
+}
+
+// url returns the url of the blob, or of the container if the blob is empty
+func (s *azureStore) url(blob string, query url.Values) string {
+	u := s.endpoint + "/" + url.PathEscape(s.container)
+	if blob != "" {
+		u += "/" + awsEscape(blob, false)
+	}
+	q := url.Values{}
+	for k, vs := range s.sas {
+		q[k] = vs
+	}
+	for k, vs := range query {
+		q[k] = vs
+	}
+	if len(q) != 0 {
+		u += "?" + q.Encode()
+	}
+	return u
+}
+
+// azureListResult is the response of List Blobs
+type azureListResult struct {
+	Blobs []struct {
+		Name       string
+		Properties struct {
+			LastModified  string `xml:"Last-Modified"`
+			Etag          string
+			ContentLength int64 `xml:"Content-Length"`
+		}
+	} `xml:"Blobs>Blob"`
+	NextMarker string
+}
+
+// List implements the Store interface.
+func (s *azureStore) List(ctx context.Context) ([]ObjectInfo, error) {
+	query := url.Values{"restype": {"container"}, "comp": {"list"}, "delimiter": {"/"}}
+	if s.prefix != "" {
+		query.Set("prefix", s.prefix+"/")
+	}
+	var objects []ObjectInfo
+	for {
+		req, err := s.newRequest(ctx, http.MethodGet, s.url("", query), nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := s.do(req)
+		if err != nil {
+			return nil, fmt.Errorf("can't list azure container %q: %v", s.container, err)
+		}
+		var result azureListResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("can't decode list of azure container %q: %v", s.container, err)
+		}
+		for _, b := range result.Blobs {
+			name := strings.TrimPrefix(b.Name, query.Get("prefix"))
+			if name == "" {
+				continue
+			}
+			modTime, _ := time.Parse(time.RFC1123, b.Properties.LastModified)
+			objects = append(objects, ObjectInfo{Name: name, Size: b.Properties.ContentLength, ETag: b.Properties.Etag, ModTime: modTime})
+		}
+		if result.NextMarker == "" {
+			return objects, nil
+		}
+		query.Set("marker", result.NextMarker)
+	}
+}
+
+// Open implements the Store interface.
+func (s *azureStore) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, s.url(join(s.prefix, name), nil), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Put implements the Store interface.
+func (s *azureStore) Put(ctx context.Context, name string, r io.Reader, size int64, cond Precondition) error {
+	req, err := s.newRequest(ctx, http.MethodPut, s.url(join(s.prefix, name), nil), r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	setPrecondition(req.Header, cond)
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// newRequest returns a request to the Blob service API
+func (s *azureStore) newRequest(ctx context.Context, method, u string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("x-ms-version", azureVersion)
+	return req, nil
+}
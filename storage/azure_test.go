@@ -0,0 +1,115 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeAzure serves a container of the Blob service
+type fakeAzure struct {
+	*fakeBucket
+	container string
+	sig       string
+}
+
+func (f fakeAzure) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("sig") != f.sig || r.Header.Get("x-ms-version") == "" {
+		http.Error(w, "unauthorized", http.StatusForbidden)
+		return
+	}
+	blob := strings.TrimPrefix(r.URL.Path, "/"+f.container+"/")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Query().Get("comp") == "list":
+		f.list(w, r)
+	case r.Method == http.MethodGet:
+		data, _, ok := f.get(blob)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	case r.Method == http.MethodPut && r.Header.Get("x-ms-blob-type") == "BlockBlob":
+		data, _ := ioutil.ReadAll(r.Body)
+		ifMatch := r.Header.Get("If-Match")
+		if r.Header.Get("If-None-Match") == "*" {
+			ifMatch = "0"
+		}
+		if !f.put(blob, data, ifMatch) {
+			// this is what the Blob service returns for existing blobs
+			w.WriteHeader(http.StatusConflict)
+		}
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+// list lists two blobs per page
+func (f fakeAzure) list(w http.ResponseWriter, r *http.Request) {
+	keys := f.keys(r.URL.Query().Get("prefix"))
+	if marker := r.URL.Query().Get("marker"); marker != "" {
+		for len(keys) != 0 && keys[0] < marker {
+			keys = keys[1:]
+		}
+	}
+	type properties struct {
+		LastModified  string `xml:"Last-Modified"`
+		Etag          string
+		ContentLength int `xml:"Content-Length"`
+	}
+	type blob struct {
+		Name       string
+		Properties properties
+	}
+	var result struct {
+		XMLName    xml.Name `xml:"EnumerationResults"`
+		Blobs      []blob   `xml:"Blobs>Blob"`
+		NextMarker string
+	}
+	for i, key := range keys {
+		if i == 2 {
+			result.NextMarker = key
+			break
+		}
+		data, etag, _ := f.get(key)
+		result.Blobs = append(result.Blobs, blob{key, properties{time.Now().UTC().Format(time.RFC1123), etag, len(data)}})
+	}
+	xml.NewEncoder(w).Encode(result)
+}
+
+func TestAzureStore(t *testing.T) {
+	bucket := newFakeBucket()
+	ts := httptest.NewServer(fakeAzure{fakeBucket: bucket, container: "container", sig: "signature"})
+	defer ts.Close()
+
+	defer func(f func(string) string) { getenv = f }(getenv)
+	env := map[string]string{"AZURE_STORAGE_SAS_TOKEN": "?sv=2021-08-06&sig=signature"}
+	getenv = func(key string) string { return env[key] }
+
+	s, err := New("azblob://container/feeds?endpoint=" + ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, s)
+	if keys := bucket.keys("feeds/"); len(keys) != 3 {
+		t.Fatalf("expected 3 objects under the prefix, got %v", keys)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Dir is a store of files in a local directory.
+// Its preconditions are checked before writing, so unlike the ones of object stores they aren't atomic.
+type Dir string
+
+// List implements the Store interface, it returns the regular files in the directory.
+func (d Dir) List(ctx context.Context) ([]ObjectInfo, error) {
+	fis, err := ioutil.ReadDir(string(d))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	objects := make([]ObjectInfo, 0, len(fis))
+	for _, fi := range fis {
+		if !fi.Mode().IsRegular() {
+			continue
+		}
+		objects = append(objects, ObjectInfo{Name: fi.Name(), Size: fi.Size(), ETag: fileETag(fi), ModTime: fi.ModTime()})
+	}
+	return objects, nil
+}
+
+// Open implements the Store interface.
+func (d Dir) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(string(d), name))
+	if os.IsNotExist(err) {
+		return nil, ErrNotExist
+	}
+	return f, err
+}
+
+// Put implements the Store interface, the file is replaced atomically.
+func (d Dir) Put(ctx context.Context, name string, r io.Reader, size int64, cond Precondition) error {
+	filename := filepath.Join(string(d), name)
+	fi, err := os.Stat(filename)
+	switch {
+	case err != nil && !os.IsNotExist(err):
+		return err
+	case err == nil && cond.IfNotExist:
+		return ErrPrecondition
+	case cond.IfMatch != "" && (err != nil || fileETag(fi) != cond.IfMatch):
+		return ErrPrecondition
+	}
+
+	if err := os.MkdirAll(string(d), 0755); err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile(string(d), "."+name+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	n, err := io.Copy(tmp, r)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && n != size {
+		err = fmt.Errorf("wrote %d bytes instead of %d", n, size)
+	}
+	if err != nil {
+		return fmt.Errorf("can't write %q: %v", filename, err)
+	}
+	return os.Rename(tmp.Name(), filename)
+}
+
+// fileETag identifies version of a file by its modification time and size
+func fileETag(fi os.FileInfo) string {
+	return fmt.Sprintf("%x-%x", fi.ModTime().UnixNano(), fi.Size())
+}
@@ -0,0 +1,204 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	gcsEndpoint = "https://storage.googleapis.com"
+	// gcsTokenURL returns the access token of the default service account when running on Google Cloud
+	gcsTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+// gcsStore is a bucket of Google Cloud Storage, accessed with its JSON API.
+// Generations of objects are used as their ETags, since writes are conditional on them.
+type gcsStore struct {
+	restClient
+	bucket, prefix string
+	endpoint       string
+	// token is the static access token, tokenURL is used to get one if it isn't set
+	token    string
+	tokenURL string
+
+	mu      sync.Mutex
+	expires time.Time
+}
+
+func newGCS(bucket, prefix string, params url.Values) *gcsStore {
+	s := &gcsStore{
+		bucket:   bucket,
+		prefix:   prefix,
+		endpoint: gcsEndpoint,
+		token:    getenv("GOOGLE_OAUTH_ACCESS_TOKEN"),
+		tokenURL: gcsTokenURL,
+	}
+	if endpoint := setting(params, "endpoint", "STORAGE_EMULATOR_HOST"); endpoint != "" {
+		if !strings.Contains(endpoint, "://") {
+			endpoint = "http://" + endpoint
+		}
+		s.endpoint = strings.TrimSuffix(endpoint, "/")
+		// emulators don't need tokens
+		s.tokenURL = ""
+	}
+	s.auth = s.authorize
+	return s
+}
+
+// gcsObject is the object resource of the JSON API
+type gcsObject struct {
+	Name       string    `json:"name"`
+	Size       int64     `json:"size,string"`
+	Generation string    `json:"generation"`
+	Updated    time.Time `json:"updated"`
+}
+
+// List implements the Store interface.
+func (s *gcsStore) List(ctx context.Context) ([]ObjectInfo, error) {
+	query := url.Values{"delimiter": {"/"}}
+	if s.prefix != "" {
+		query.Set("prefix", s.prefix+"/")
+	}
+	var objects []ObjectInfo
+	for {
+		u := fmt.Sprintf("%s/storage/v1/b/%s/o?%s", s.endpoint, url.PathEscape(s.bucket), query.Encode())
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		var result struct {
+			Items         []gcsObject `json:"items"`
+			NextPageToken string      `json:"nextPageToken"`
+		}
+		if err := s.doJSON(req, &result); err != nil {
+			return nil, fmt.Errorf("can't list gcs bucket %q: %v", s.bucket, err)
+		}
+		for _, o := range result.Items {
+			name := strings.TrimPrefix(o.Name, query.Get("prefix"))
+			if name == "" {
+				continue
+			}
+			objects = append(objects, ObjectInfo{Name: name, Size: o.Size, ETag: o.Generation, ModTime: o.Updated})
+		}
+		if result.NextPageToken == "" {
+			return objects, nil
+		}
+		query.Set("pageToken", result.NextPageToken)
+	}
+}
+
+// Open implements the Store interface.
+func (s *gcsStore) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	u := fmt.Sprintf("%s/storage/v1/b/%s/o/%s?alt=media",
+		s.endpoint, url.PathEscape(s.bucket), url.PathEscape(join(s.prefix, name)))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Put implements the Store interface.
+func (s *gcsStore) Put(ctx context.Context, name string, r io.Reader, size int64, cond Precondition) error {
+	query := url.Values{"uploadType": {"media"}, "name": {join(s.prefix, name)}}
+	switch {
+	case cond.IfNotExist:
+		// generation 0 matches only objects which don't exist
+		query.Set("ifGenerationMatch", "0")
+	case cond.IfMatch != "":
+		if _, err := strconv.ParseInt(cond.IfMatch, 10, 64); err != nil {
+			return fmt.Errorf("gcs etag %q isn't a generation: %v", cond.IfMatch, err)
+		}
+		query.Set("ifGenerationMatch", cond.IfMatch)
+	}
+	u := fmt.Sprintf("%s/upload/storage/v1/b/%s/o?%s", s.endpoint, url.PathEscape(s.bucket), query.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "application/octet-stream")
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// doJSON sends the request and decodes the json response into v
+func (s *gcsStore) doJSON(req *http.Request, v interface{}) error {
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// authorize sets the access token on the request. Tokens of the metadata server are cached until they expire;
+// requests are anonymous if there's neither a token nor a metadata server, which works for public buckets.
+func (s *gcsStore) authorize(req *http.Request) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tokenURL != "" && (s.token == "" || time.Now().After(s.expires)) {
+		token, expires, err := s.fetchToken(req.Context())
+		if err != nil {
+			// not running on Google Cloud
+			s.tokenURL = ""
+		}
+		s.token, s.expires = token, expires
+	}
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+	return nil
+}
+
+// fetchToken gets an access token from the metadata server
+func (s *gcsStore) fetchToken(ctx context.Context) (string, time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.tokenURL, nil)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := restClient{client: s.client}.do(req)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	defer resp.Body.Close()
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", time.Time{}, fmt.Errorf("can't decode access token: %v", err)
+	}
+	// refresh a minute before it expires
+	return token.AccessToken, time.Now().Add(time.Duration(token.ExpiresIn-60) * time.Second), nil
+}
@@ -0,0 +1,105 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeGCS serves a bucket with the JSON API
+type fakeGCS struct {
+	*fakeBucket
+	bucket string
+}
+
+func (f fakeGCS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer token" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	objects := "/storage/v1/b/" + f.bucket + "/o"
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == objects:
+		var result struct {
+			Items []gcsObject `json:"items"`
+		}
+		for _, key := range f.keys(r.URL.Query().Get("prefix")) {
+			data, etag, _ := f.get(key)
+			result.Items = append(result.Items, gcsObject{Name: key, Size: int64(len(data)), Generation: etag, Updated: time.Now()})
+		}
+		json.NewEncoder(w).Encode(result)
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, objects+"/") && r.URL.Query().Get("alt") == "media":
+		data, _, ok := f.get(strings.TrimPrefix(r.URL.Path, objects+"/"))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	case r.Method == http.MethodPost && r.URL.Path == "/upload"+objects:
+		data, _ := ioutil.ReadAll(r.Body)
+		if !f.put(r.URL.Query().Get("name"), data, r.URL.Query().Get("ifGenerationMatch")) {
+			w.WriteHeader(http.StatusPreconditionFailed)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestGCSStore(t *testing.T) {
+	bucket := newFakeBucket()
+	ts := httptest.NewServer(fakeGCS{fakeBucket: bucket, bucket: "bucket"})
+	defer ts.Close()
+
+	defer func(f func(string) string) { getenv = f }(getenv)
+	env := map[string]string{"GOOGLE_OAUTH_ACCESS_TOKEN": "token"}
+	getenv = func(key string) string { return env[key] }
+
+	s, err := New("gs://bucket/feeds/json?endpoint=" + ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, s)
+	if keys := bucket.keys("feeds/json/"); len(keys) != 3 {
+		t.Fatalf("expected 3 objects under the prefix, got %v", keys)
+	}
+}
+
+func TestGCSMetadataToken(t *testing.T) {
+	var requests int
+	metadata := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			http.Error(w, "missing header", http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"access_token": "token", "expires_in": 3600}`))
+	}))
+	defer metadata.Close()
+	ts := httptest.NewServer(fakeGCS{fakeBucket: newFakeBucket(), bucket: "bucket"})
+	defer ts.Close()
+
+	s := &gcsStore{bucket: "bucket", endpoint: ts.URL, tokenURL: metadata.URL}
+	s.auth = s.authorize
+	testStore(t, s)
+	if requests != 1 {
+		t.Fatalf("expected the token to be fetched once, it was fetched %d times", requests)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Snapshot records the objects copied from a store to a local directory by Pull,
+// so Push writes back only the files which changed since.
+type Snapshot map[string]snapshotEntry
+
+type snapshotEntry struct {
+	etag string
+	hash [sha256.Size]byte
+}
+
+// Pull copies all objects of the store to the local directory, replacing files with the same names.
+func Pull(ctx context.Context, s Store, dir string) (Snapshot, error) {
+	objects, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	snap := make(Snapshot, len(objects))
+	for _, o := range objects {
+		r, err := s.Open(ctx, o.Name)
+		if err != nil {
+			return nil, fmt.Errorf("can't open %q: %v", o.Name, err)
+		}
+		h := sha256.New()
+		err = Dir(dir).Put(ctx, o.Name, io.TeeReader(r, h), o.Size, Precondition{})
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("can't pull %q: %v", o.Name, err)
+		}
+		entry := snapshotEntry{etag: o.ETag}
+		copy(entry.hash[:], h.Sum(nil))
+		snap[o.Name] = entry
+	}
+	return snap, nil
+}
+
+// Push writes the files of the local directory which were added or changed since Pull to the store.
+// Writes are conditional: an object fails with ErrPrecondition if it was changed in the store since the snapshot
+// was taken, or created if it wasn't there then. Backup files (*.bak) are skipped.
+func Push(ctx context.Context, s Store, dir string, snap Snapshot) error {
+	fis, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, fi := range fis {
+		if !fi.Mode().IsRegular() || strings.HasSuffix(fi.Name(), ".bak") {
+			continue
+		}
+		if err := pushFile(ctx, s, dir, fi.Name(), snap); err != nil {
+			return fmt.Errorf("can't push %q: %v", fi.Name(), err)
+		}
+	}
+	return nil
+}
+
+// pushFile writes the file to the store if it changed since the snapshot
+func pushFile(ctx context.Context, s Store, dir, name string, snap Snapshot) error {
+	f, err := os.Open(filepath.Join(dir, name))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	cond := Precondition{IfNotExist: true}
+	if entry, ok := snap[name]; ok {
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+		if bytes.Equal(entry.hash[:], h.Sum(nil)) {
+			return nil
+		}
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		cond = Precondition{IfMatch: entry.etag}
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	return s.Put(ctx, name, f, fi.Size(), cond)
+}
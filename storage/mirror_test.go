@@ -0,0 +1,90 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPullPush(t *testing.T) {
+	ctx := context.Background()
+	tmp, err := ioutil.TempDir("", "storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	remote, local := Dir(filepath.Join(tmp, "remote")), filepath.Join(tmp, "local")
+
+	write := func(filename, data string) {
+		if err := ioutil.WriteFile(filename, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(string(remote), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write(filepath.Join(string(remote), "unchanged.json"), "unchanged")
+	write(filepath.Join(string(remote), "changed.json"), "old")
+
+	snap, err := Pull(ctx, remote, local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snap) != 2 {
+		t.Fatalf("expected 2 pulled objects, got %d", len(snap))
+	}
+	data, err := ioutil.ReadFile(filepath.Join(local, "changed.json"))
+	if err != nil || string(data) != "old" {
+		t.Fatalf("expected pulled file, got %q, %v", data, err)
+	}
+
+	write(filepath.Join(local, "changed.json"), "new")
+	write(filepath.Join(local, "added.json"), "added")
+	write(filepath.Join(local, "added.json.bak"), "backup")
+	unchanged, err := os.Stat(filepath.Join(string(remote), "unchanged.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Push(ctx, remote, local, snap); err != nil {
+		t.Fatal(err)
+	}
+
+	objects, err := remote.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 3 {
+		t.Fatalf("expected 3 objects, got %+v", objects)
+	}
+	for _, o := range objects {
+		if o.Name == "unchanged.json" && o.ETag != fileETag(unchanged) {
+			t.Fatal("unchanged file was pushed")
+		}
+	}
+	data, err = ioutil.ReadFile(filepath.Join(string(remote), "changed.json"))
+	if err != nil || string(data) != "new" {
+		t.Fatalf("expected pushed file, got %q, %v", data, err)
+	}
+
+	// the object changed in the store since the snapshot
+	write(filepath.Join(local, "changed.json"), "newer")
+	if err := Push(ctx, remote, local, snap); err == nil {
+		t.Fatal("expected push of a concurrently changed object to fail")
+	}
+}
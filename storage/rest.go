@@ -0,0 +1,63 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/facebookincubator/nvdtools/providers/lib/client"
+)
+
+// restClient sends requests to REST APIs of object stores
+type restClient struct {
+	client *http.Client
+	// auth authorizes requests, if it's set
+	auth func(req *http.Request) error
+}
+
+// do sends the request and maps error responses to errors: 404 to ErrNotExist, and 412 and 409 to ErrPrecondition
+// (409 is returned by some stores if an object which shouldn't exist does); the response body needs to be closed
+func (c restClient) do(req *http.Request) (*http.Response, error) {
+	if c.auth != nil {
+		if err := c.auth(req); err != nil {
+			return nil, err
+		}
+	}
+	var hc client.Client = client.Default()
+	if c.client != nil {
+		hc = c.client
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return nil, ErrNotExist
+	case http.StatusPreconditionFailed, http.StatusConflict:
+		return nil, ErrPrecondition
+	}
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4*1024))
+	return nil, fmt.Errorf("unexpected http response from %s %q (%q): %q",
+		req.Method, req.URL.Redacted(), resp.Status, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,268 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// s3Store is a bucket of Amazon S3 or an S3 compatible store
+type s3Store struct {
+	restClient
+	bucket, prefix string
+	region         string
+	// endpoint of an S3 compatible store, buckets are addressed in its path; virtual hosts of AWS are used if it's empty
+	endpoint                           string
+	accessKey, secretKey, sessionToken string
+	// now returns the time requests are signed at
+	now func() time.Time
+}
+
+func newS3(bucket, prefix string, params url.Values) *s3Store {
+	s := &s3Store{
+		bucket:       bucket,
+		prefix:       prefix,
+		region:       setting(params, "region", "AWS_REGION", "AWS_DEFAULT_REGION"),
+		endpoint:     strings.TrimSuffix(setting(params, "endpoint", "AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"), "/"),
+		accessKey:    getenv("AWS_ACCESS_KEY_ID"),
+		secretKey:    getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken: getenv("AWS_SESSION_TOKEN"),
+		now:          time.Now,
+	}
+	if s.region == "" {
+		s.region = "us-east-1"
+	}
+	s.auth = s.sign
+	return s
+}
+
+// url returns the url of the object with the key, or of the bucket if the key is empty
+func (s *s3Store) url(key string, query url.Values) string {
+	var u string
+	if s.endpoint != "" {
+		u = s.endpoint + "/" + s.bucket + "/" + awsEscape(key, false)
+	} else {
+		u = fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.bucket, s.region, awsEscape(key, false))
+	}
+	if len(query) != 0 {
+		u += "?" + query.Encode()
+	}
+	return u
+}
+
+// s3ListResult is the response of ListObjectsV2
+type s3ListResult struct {
+	IsTruncated           bool
+	NextContinuationToken string
+	Contents              []struct {
+		Key          string
+		LastModified time.Time
+		ETag         string
+		Size         int64
+	}
+}
+
+// List implements the Store interface.
+func (s *s3Store) List(ctx context.Context) ([]ObjectInfo, error) {
+	query := url.Values{"list-type": {"2"}}
+	if s.prefix != "" {
+		query.Set("prefix", s.prefix+"/")
+	}
+	var objects []ObjectInfo
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url("", query), nil)
+		if err != nil {
+			return nil, err
+		}
+		var result s3ListResult
+		if err := s.doXML(req, &result); err != nil {
+			return nil, fmt.Errorf("can't list s3 bucket %q: %v", s.bucket, err)
+		}
+		for _, c := range result.Contents {
+			name := strings.TrimPrefix(c.Key, query.Get("prefix"))
+			if name == "" || strings.Contains(name, "/") {
+				continue
+			}
+			objects = append(objects, ObjectInfo{Name: name, Size: c.Size, ETag: c.ETag, ModTime: c.LastModified})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+// Open implements the Store interface.
+func (s *s3Store) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url(join(s.prefix, name), nil), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Put implements the Store interface.
+func (s *s3Store) Put(ctx context.Context, name string, r io.Reader, size int64, cond Precondition) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.url(join(s.prefix, name), nil), r)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	setPrecondition(req.Header, cond)
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// doXML sends the request and decodes the xml response into v
+func (s *s3Store) doXML(req *http.Request, v interface{}) error {
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return xml.NewDecoder(resp.Body).Decode(v)
+}
+
+// sign signs the request with AWS signature version 4, it doesn't if credentials aren't set.
+// The payload isn't signed, unless its hash is set in the X-Amz-Content-Sha256 header already.
+func (s *s3Store) sign(req *http.Request) error {
+	if s.accessKey == "" {
+		return nil
+	}
+	amzDate := s.now().UTC().Format("20060102T150405Z")
+	req.Header.Set("X-Amz-Date", amzDate)
+	if req.Header.Get("X-Amz-Content-Sha256") == "" {
+		req.Header.Set("X-Amz-Content-Sha256", "UNSIGNED-PAYLOAD")
+	}
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for k, vs := range req.Header {
+		trimmed := make([]string, len(vs))
+		for i, v := range vs {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		headers[strings.ToLower(k)] = strings.Join(trimmed, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	query := req.URL.Query()
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var params []string
+	for _, k := range keys {
+		vs := query[k]
+		sort.Strings(vs)
+		for _, v := range vs {
+			params = append(params, awsEscape(k, true)+"="+awsEscape(v, true))
+		}
+	}
+
+	canonicalPath := req.URL.EscapedPath()
+	if canonicalPath == "" {
+		canonicalPath = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalPath,
+		strings.Join(params, "&"),
+		canonicalHeaders.String(),
+		signedHeaders,
+		req.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+
+	scope := strings.Join([]string{amzDate[:8], s.region, "s3", "aws4_request"}, "/")
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := []byte("AWS4" + s.secretKey)
+	for _, part := range strings.Split(scope, "/") {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, signedHeaders, signature))
+	return nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// awsEscape escapes all but the unreserved characters, as AWS signatures require;
+// slashes are escaped only if escapeSlash is true
+func awsEscape(s string, escapeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/' && !escapeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// setPrecondition sets the conditional request headers for the precondition
+func setPrecondition(h http.Header, cond Precondition) {
+	if cond.IfMatch != "" {
+		h.Set("If-Match", cond.IfMatch)
+	}
+	if cond.IfNotExist {
+		h.Set("If-None-Match", "*")
+	}
+}
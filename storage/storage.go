@@ -0,0 +1,161 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package storage provides access to local directories and object store buckets (Amazon S3,
+// Google Cloud Storage and Azure Blob Storage) as flat stores of named objects, so feeds can be
+// synchronized to and loaded from either of them.
+//
+// Stores are addressed by locations: a local directory path, or an url of a bucket and an optional
+// prefix of the objects in it:
+//
+//	s3://bucket/prefix?region=us-west-2
+//	gs://bucket/prefix
+//	azblob://container/prefix?account=myaccount
+//
+// Object stores are accessed with their REST APIs, with credentials taken from the environment:
+//
+//   - S3: AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN, requests are anonymous without them;
+//     AWS_REGION (or region url parameter) and AWS_ENDPOINT_URL (or endpoint url parameter) for S3 compatible stores
+//   - GCS: GOOGLE_OAUTH_ACCESS_TOKEN, or the token of the service account from the metadata server
+//     when running on Google Cloud; STORAGE_EMULATOR_HOST (or endpoint url parameter)
+//   - Azure: AZURE_STORAGE_ACCOUNT (or account url parameter) and AZURE_STORAGE_SAS_TOKEN
+//     (a shared access signature); endpoint url parameter
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// getenv returns value of the environment variable, it's replaced in tests
+var getenv = os.Getenv
+
+// ObjectInfo describes an object in a store.
+type ObjectInfo struct {
+	// Name of the object, relative to the prefix of the store
+	Name string
+	Size int64
+	// ETag identifies the version of the object, it changes whenever the object is written
+	ETag    string
+	ModTime time.Time
+}
+
+// Precondition of a write: it fails with ErrPrecondition if it isn't met.
+// Conditional writes keep concurrent writers from overwriting each other's objects.
+type Precondition struct {
+	// IfMatch is the ETag the object needs to have, it isn't checked if it's empty
+	IfMatch string
+	// IfNotExist requires the object not to exist yet
+	IfNotExist bool
+}
+
+// Errors returned by stores.
+var (
+	ErrNotExist     = errors.New("storage: object doesn't exist")
+	ErrPrecondition = errors.New("storage: precondition failed")
+)
+
+// Store is a flat namespace of named objects.
+type Store interface {
+	// List returns all objects in the store.
+	List(ctx context.Context) ([]ObjectInfo, error)
+	// Open returns contents of the object, ErrNotExist if there's no such object.
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+	// Put writes size bytes of r to the object if the precondition is met, replacing its contents.
+	Put(ctx context.Context, name string, r io.Reader, size int64, cond Precondition) error
+}
+
+// IsURL returns true if location is an url of an object store bucket, as opposed to a local path.
+func IsURL(location string) bool {
+	for _, scheme := range []string{"s3", "gs", "azblob"} {
+		if strings.HasPrefix(location, scheme+"://") {
+			return true
+		}
+	}
+	return false
+}
+
+// New returns the store at location, see the package documentation for the supported ones.
+func New(location string) (Store, error) {
+	if !IsURL(location) {
+		return Dir(location), nil
+	}
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse store url: %v", err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("bucket of store %q isn't set", location)
+	}
+	prefix := strings.Trim(u.Path, "/")
+	switch u.Scheme {
+	case "s3":
+		return newS3(u.Host, prefix, u.Query()), nil
+	case "gs":
+		return newGCS(u.Host, prefix, u.Query()), nil
+	default:
+		s, err := newAzure(u.Host, prefix, u.Query())
+		if err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+}
+
+// Open opens a single object or file at location, e.g. s3://bucket/feeds/nvdcve-1.1-2020.json.gz.
+func Open(ctx context.Context, location string) (io.ReadCloser, error) {
+	if !IsURL(location) {
+		return Dir(filepath.Dir(location)).Open(ctx, filepath.Base(location))
+	}
+	u, err := url.Parse(location)
+	if err != nil {
+		return nil, fmt.Errorf("can't parse object url: %v", err)
+	}
+	name := path.Base(u.Path)
+	u.Path = path.Dir(u.Path)
+	s, err := New(u.String())
+	if err != nil {
+		return nil, err
+	}
+	return s.Open(ctx, name)
+}
+
+// join joins the prefix of a store and the name of an object
+func join(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "/" + name
+}
+
+// setting returns the url parameter, or the first environment variable which is set
+func setting(params url.Values, param string, envs ...string) string {
+	if v := params.Get(param); v != "" {
+		return v
+	}
+	for _, env := range envs {
+		if v := getenv(env); v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,209 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeBucket keeps objects of fake object store servers
+type fakeBucket struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	etags   map[string]string
+	version int
+}
+
+func newFakeBucket() *fakeBucket {
+	return &fakeBucket{objects: map[string][]byte{}, etags: map[string]string{}}
+}
+
+// keys returns sorted keys of objects with the prefix
+func (b *fakeBucket) keys(prefix string) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var keys []string
+	for k := range b.objects {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (b *fakeBucket) get(key string) ([]byte, string, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	data, ok := b.objects[key]
+	return data, b.etags[key], ok
+}
+
+// put writes the object if the etag matches, "*" matches any existing object and "0" a missing one;
+// it returns false if the precondition failed
+func (b *fakeBucket) put(key string, data []byte, ifMatch string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	etag, ok := b.etags[key]
+	switch {
+	case ifMatch == "0" && ok, ifMatch == "*" && !ok, ifMatch != "" && ifMatch != "*" && ifMatch != "0" && ifMatch != etag:
+		return false
+	}
+	b.version++
+	b.objects[key] = data
+	b.etags[key] = strconv.Itoa(b.version)
+	return true
+}
+
+// testStore exercises the store, which needs to be empty
+func testStore(t *testing.T, s Store) {
+	ctx := context.Background()
+	put := func(name, data string, cond Precondition) error {
+		return s.Put(ctx, name, strings.NewReader(data), int64(len(data)), cond)
+	}
+	read := func(name string) (string, error) {
+		r, err := s.Open(ctx, name)
+		if err != nil {
+			return "", err
+		}
+		defer r.Close()
+		data, err := ioutil.ReadAll(r)
+		return string(data), err
+	}
+
+	if _, err := read("missing"); err != ErrNotExist {
+		t.Fatalf("opening a missing object: expected ErrNotExist, got %v", err)
+	}
+	for i, name := range []string{"a.json", "b.json", "c.json"} {
+		if err := put(name, fmt.Sprintf("data %d", i), Precondition{IfNotExist: true}); err != nil {
+			t.Fatalf("can't put %q: %v", name, err)
+		}
+	}
+	if err := put("a.json", "new", Precondition{IfNotExist: true}); err != ErrPrecondition {
+		t.Fatalf("creating an existing object: expected ErrPrecondition, got %v", err)
+	}
+
+	objects, err := s.List(ctx)
+	if err != nil {
+		t.Fatalf("can't list: %v", err)
+	}
+	if len(objects) != 3 {
+		t.Fatalf("expected 3 objects, got %+v", objects)
+	}
+	etags := map[string]string{}
+	for _, o := range objects {
+		if o.Size != 6 || o.ETag == "" {
+			t.Fatalf("unexpected object %+v", o)
+		}
+		etags[o.Name] = o.ETag
+	}
+
+	if err := put("b.json", "new", Precondition{IfMatch: etags["b.json"]}); err != nil {
+		t.Fatalf("can't update: %v", err)
+	}
+	if err := put("b.json", "newer", Precondition{IfMatch: etags["b.json"]}); err != ErrPrecondition {
+		t.Fatalf("updating a changed object: expected ErrPrecondition, got %v", err)
+	}
+	if data, err := read("b.json"); err != nil || data != "new" {
+		t.Fatalf("expected \"new\", got %q, %v", data, err)
+	}
+	if err := put("c.json", "any", Precondition{}); err != nil {
+		t.Fatalf("can't overwrite: %v", err)
+	}
+}
+
+func TestDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	testStore(t, Dir(dir))
+}
+
+// describe returns the settings of the store
+func describe(s Store) string {
+	switch s := s.(type) {
+	case *s3Store:
+		return fmt.Sprintf("s3 %s %q %s", s.bucket, s.prefix, s.region)
+	case *gcsStore:
+		return fmt.Sprintf("gs %s %q %s", s.bucket, s.prefix, s.endpoint)
+	case *azureStore:
+		return fmt.Sprintf("azblob %s %q %s", s.container, s.prefix, s.endpoint)
+	}
+	return fmt.Sprintf("%T %v", s, s)
+}
+
+func TestNew(t *testing.T) {
+	defer func(f func(string) string) { getenv = f }(getenv)
+	getenv = func(string) string { return "" }
+
+	for i, tc := range []struct {
+		location string
+		store    string
+	}{
+		{"/feeds/json", "storage.Dir /feeds/json"},
+		{"s3://bucket/feeds/json?region=eu-west-1", `s3 bucket "feeds/json" eu-west-1`},
+		{"s3://bucket", `s3 bucket "" us-east-1`},
+		{"gs://bucket/feeds/", `gs bucket "feeds" https://storage.googleapis.com`},
+		{"gs://bucket?endpoint=localhost:9023", `gs bucket "" http://localhost:9023`},
+		{"azblob://container/feeds?account=acc", `azblob container "feeds" https://acc.blob.core.windows.net`},
+		{"azblob://container/feeds", ""},
+		{"s3:///feeds", ""},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			s, err := New(tc.location)
+			if tc.store == "" {
+				if err == nil {
+					t.Fatalf("expected an error, got %s", describe(s))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := describe(s); got != tc.store {
+				t.Fatalf("expected %s, got %s", tc.store, got)
+			}
+		})
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(dir+"/feed.json", []byte("feed"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Open(context.Background(), dir+"/feed.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	if data, _ := ioutil.ReadAll(r); string(data) != "feed" {
+		t.Fatalf("unexpected contents %q", data)
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"fmt"
+)
+
+// AttributesBuilder constructs Attributes from plain values, e.g.
+//
+//	attrs, err := wfn.NewAttributesBuilder().Part("a").Vendor("openssl").Product("openssl").Version("1.1.1k").Build()
+//
+// Values are taken literally: spaces become underscores and punctuation, including * and ?, is quoted,
+// so they are never treated as wildcards. Any and NA logical values are passed through as is.
+// Values aren't lower-cased; it is recommended to strings.ToLower() them, as NVD does.
+// The first invalid value is reported by Build.
+type AttributesBuilder struct {
+	attrs Attributes
+	err   error
+}
+
+// NewAttributesBuilder returns a builder of Attributes with all fields initialized to Any logical value
+func NewAttributesBuilder() *AttributesBuilder {
+	return &AttributesBuilder{}
+}
+
+// Part sets the part, which must be one of KnownParts or Any
+func (b *AttributesBuilder) Part(v string) *AttributesBuilder {
+	if err := validatePart(v); err != nil {
+		b.fail("part", v, err)
+		return b
+	}
+	b.attrs.Part = v
+	return b
+}
+
+// Vendor sets the vendor
+func (b *AttributesBuilder) Vendor(v string) *AttributesBuilder {
+	return b.set("vendor", &b.attrs.Vendor, v)
+}
+
+// Product sets the product
+func (b *AttributesBuilder) Product(v string) *AttributesBuilder {
+	return b.set("product", &b.attrs.Product, v)
+}
+
+// Version sets the version
+func (b *AttributesBuilder) Version(v string) *AttributesBuilder {
+	return b.set("version", &b.attrs.Version, v)
+}
+
+// Update sets the update
+func (b *AttributesBuilder) Update(v string) *AttributesBuilder {
+	return b.set("update", &b.attrs.Update, v)
+}
+
+// Edition sets the edition
+func (b *AttributesBuilder) Edition(v string) *AttributesBuilder {
+	return b.set("edition", &b.attrs.Edition, v)
+}
+
+// SWEdition sets the software edition
+func (b *AttributesBuilder) SWEdition(v string) *AttributesBuilder {
+	return b.set("sw_edition", &b.attrs.SWEdition, v)
+}
+
+// TargetSW sets the target software
+func (b *AttributesBuilder) TargetSW(v string) *AttributesBuilder {
+	return b.set("target_sw", &b.attrs.TargetSW, v)
+}
+
+// TargetHW sets the target hardware
+func (b *AttributesBuilder) TargetHW(v string) *AttributesBuilder {
+	return b.set("target_hw", &b.attrs.TargetHW, v)
+}
+
+// Other sets the other attribute
+func (b *AttributesBuilder) Other(v string) *AttributesBuilder {
+	return b.set("other", &b.attrs.Other, v)
+}
+
+// Language sets the language
+func (b *AttributesBuilder) Language(v string) *AttributesBuilder {
+	return b.set("language", &b.attrs.Language, v)
+}
+
+// Build returns the constructed attributes or the first error encountered
+func (b *AttributesBuilder) Build() (*Attributes, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	attrs := b.attrs
+	return &attrs, nil
+}
+
+func (b *AttributesBuilder) set(name string, field *string, v string) *AttributesBuilder {
+	quoted, err := quoteValue(v)
+	if err != nil {
+		b.fail(name, v, err)
+		return b
+	}
+	*field = quoted
+	return b
+}
+
+func (b *AttributesBuilder) fail(name, v string, err error) {
+	if b.err == nil {
+		b.err = fmt.Errorf("wfn: invalid %s %q: %v", name, v, err)
+	}
+}
+
+// quoteValue converts a literal value into WFN attribute-value
+func quoteValue(s string) (string, error) {
+	if s == Any || s == NA {
+		return s, nil
+	}
+	buf := make([]byte, 0, len(s)*2)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case isAlnum(c) || c == '_':
+			buf = append(buf, c)
+		case c == ' ':
+			buf = append(buf, '_')
+		case c > ' ' && c <= '~':
+			buf = append(buf, '\\', c)
+		default:
+			return "", fmt.Errorf("illegal character %q at %d", c, i)
+		}
+	}
+	return string(buf), nil
+}
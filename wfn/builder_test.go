@@ -0,0 +1,74 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAttributesBuilder(t *testing.T) {
+	attrs, err := NewAttributesBuilder().
+		Part("a").
+		Vendor("red hat").
+		Product("openssl*").
+		Version("1.0.2k").
+		Update("el7_9?").
+		TargetSW(NA).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := &Attributes{
+		Part:     "a",
+		Vendor:   "red_hat",
+		Product:  `openssl\*`,
+		Version:  `1\.0\.2k`,
+		Update:   `el7_9\?`,
+		TargetSW: NA,
+	}
+	if !reflect.DeepEqual(attrs, expected) {
+		t.Fatalf("expected %v, got %v", expected, attrs)
+	}
+	if fsb := attrs.BindToFmtString(); fsb != `cpe:2.3:a:red_hat:openssl\*:1.0.2k:el7_9\?:*:*:*:-:*:*` {
+		t.Errorf("unexpected formatted string %q", fsb)
+	}
+	// must survive a round trip through the strict parser
+	if _, err := UnbindFmtStringStrict(attrs.BindToFmtString()); err != nil {
+		t.Errorf("strict parser rejected built attributes: %v", err)
+	}
+}
+
+func TestAttributesBuilderErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		b    *AttributesBuilder
+	}{
+		{"unknown part", NewAttributesBuilder().Part("x")},
+		{"non-ascii", NewAttributesBuilder().Vendor("ünicode")},
+		{"control character", NewAttributesBuilder().Product("a\tb")},
+		{"first error wins", NewAttributesBuilder().Version("1\n").Part("a").Update("ü")},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if attrs, err := c.b.Build(); err == nil {
+				t.Fatalf("expected an error, got %v", attrs)
+			}
+		})
+	}
+	if _, err := NewAttributesBuilder().Version("1\n").Update("ü").Build(); err == nil || err.Error() != `wfn: invalid version "1\n": illegal character '\n' at 1` {
+		t.Errorf("unexpected error %v", err)
+	}
+}
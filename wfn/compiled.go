@@ -0,0 +1,166 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+// Pattern is an attribute value compiled for repeated matching.
+// Values without wildcards are compared as plain strings; values with
+// unescaped * and ? are split into segments once, so that matching a target
+// doesn't need to re-interpret the pattern.
+type Pattern struct {
+	src string
+	// segments is nil for values without wildcards
+	segments []segment
+}
+
+// segment is a part of a pattern between two unescaped *
+type segment struct {
+	s string
+	// wild[i] is true if s[i] is an unescaped ? and matches any byte
+	wild []bool
+}
+
+// CompilePattern compiles attribute value v
+func CompilePattern(v string) Pattern {
+	p := Pattern{src: v}
+	if v == Any || v == NA || !HasWildcard(v) {
+		return p
+	}
+	var (
+		buf     []byte
+		wild    []bool
+		escaped bool
+	)
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if !escaped && c == '*' {
+			p.segments = append(p.segments, segment{string(buf), wild})
+			buf, wild = nil, nil
+			continue
+		}
+		buf = append(buf, c)
+		wild = append(wild, !escaped && c == '?')
+		escaped = c == '\\' && !escaped
+	}
+	p.segments = append(p.segments, segment{string(buf), wild})
+	return p
+}
+
+// Match returns true if target value tgt matches the pattern.
+// It's equivalent to matching uncompiled attribute values.
+func (p Pattern) Match(tgt string) bool {
+	switch {
+	case p.src == Any || tgt == Any || p.src == tgt:
+		return true
+	case p.segments == nil || tgt == NA || HasWildcard(tgt):
+		return false
+	}
+
+	first, last := p.segments[0], p.segments[len(p.segments)-1]
+	if len(p.segments) == 1 {
+		return len(tgt) == len(first.s) && first.matchAt(tgt, 0)
+	}
+	if len(tgt) < len(first.s)+len(last.s) {
+		return false
+	}
+	if !first.matchAt(tgt, 0) || !last.matchAt(tgt, len(tgt)-len(last.s)) {
+		return false
+	}
+	// leftmost match of every segment in between is enough, since the * after it
+	// can absorb whatever follows
+	pos, end := len(first.s), len(tgt)-len(last.s)
+	for _, seg := range p.segments[1 : len(p.segments)-1] {
+		found := false
+		for ; pos+len(seg.s) <= end; pos++ {
+			if seg.matchAt(tgt, pos) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+		pos += len(seg.s)
+	}
+	return true
+}
+
+// String returns the original attribute value
+func (p Pattern) String() string {
+	return p.src
+}
+
+// matchAt returns true if segment matches t at position pos
+func (seg segment) matchAt(t string, pos int) bool {
+	if pos+len(seg.s) > len(t) {
+		return false
+	}
+	for i := 0; i < len(seg.s); i++ {
+		if !seg.wild[i] && seg.s[i] != t[pos+i] {
+			return false
+		}
+	}
+	return true
+}
+
+// CompiledAttributes holds Attributes together with their compiled patterns.
+// It's meant to be created once for attributes which are matched against
+// many others, e.g. the ones loaded from a feed.
+type CompiledAttributes struct {
+	*Attributes
+	part, vendor, product, version, update, edition Pattern
+	swEdition, targetSW, targetHW, other, language  Pattern
+}
+
+// Compile compiles all values of the given attributes
+func Compile(a *Attributes) *CompiledAttributes {
+	if a == nil {
+		return nil
+	}
+	return &CompiledAttributes{
+		Attributes: a,
+		part:       CompilePattern(a.Part),
+		vendor:     CompilePattern(a.Vendor),
+		product:    CompilePattern(a.Product),
+		version:    CompilePattern(a.Version),
+		update:     CompilePattern(a.Update),
+		edition:    CompilePattern(a.Edition),
+		swEdition:  CompilePattern(a.SWEdition),
+		targetSW:   CompilePattern(a.TargetSW),
+		targetHW:   CompilePattern(a.TargetHW),
+		other:      CompilePattern(a.Other),
+		language:   CompilePattern(a.Language),
+	}
+}
+
+// MatchOnlyVersion checks whether version matches
+func (ca *CompiledAttributes) MatchOnlyVersion(attr *Attributes) bool {
+	if ca == nil || attr == nil {
+		return ca == nil && attr == nil
+	}
+	return ca.version.Match(attr.Version)
+}
+
+// MatchWithoutVersion checks whether everything else besides the version matches
+func (ca *CompiledAttributes) MatchWithoutVersion(attr *Attributes) bool {
+	if ca == nil || attr == nil {
+		return ca == nil && attr == nil
+	}
+	return ca.product.Match(attr.Product) &&
+		ca.vendor.Match(attr.Vendor) && ca.part.Match(attr.Part) &&
+		ca.update.Match(attr.Update) && ca.edition.Match(attr.Edition) &&
+		ca.language.Match(attr.Language) && ca.swEdition.Match(attr.SWEdition) &&
+		ca.targetHW.Match(attr.TargetHW) && ca.targetSW.Match(attr.TargetSW) &&
+		ca.other.Match(attr.Other)
+}
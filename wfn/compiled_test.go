@@ -0,0 +1,117 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"testing"
+)
+
+// strs returns all strings up to length n made of the given symbols
+func strs(symbols []string, n int) []string {
+	all := []string{""}
+	prev := []string{""}
+	for i := 0; i < n; i++ {
+		var next []string
+		for _, p := range prev {
+			for _, s := range symbols {
+				next = append(next, p+s)
+			}
+		}
+		all = append(all, next...)
+		prev = next
+	}
+	return all
+}
+
+func TestCompiledPatternMatchesLikeMatchAttr(t *testing.T) {
+	srcs := append(strs([]string{"a", "b", "*", "?", `\?`, `\*`}, 4), NA)
+	tgts := append(strs([]string{"a", "b", `\?`, `\*`}, 4), NA, "*", "a?")
+	for _, src := range srcs {
+		p := CompilePattern(src)
+		for _, tgt := range tgts {
+			if got, want := p.Match(tgt), matchAttr(src, tgt); got != want {
+				t.Errorf("CompilePattern(%q).Match(%q) = %t, matchAttr returned %t", src, tgt, got, want)
+			}
+		}
+	}
+}
+
+func TestCompiledAttributes(t *testing.T) {
+	src, err := UnbindFmtString(`cpe:2.3:a:microsoft:*internet_ex??????:8.0.*:sp?:*:*:*:*:*:*`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca := Compile(src)
+	cases := []struct {
+		tgt                   string
+		withoutVersion, onlyV bool
+	}{
+		{`cpe:2.3:a:microsoft:internet_explorer:8.0.6001:sp3:*:*:*:*:*:*`, true, true},
+		{`cpe:2.3:a:microsoft:internet_explorer:8.1.6001:sp3:*:*:*:*:*:*`, true, false},
+		{`cpe:2.3:a:microsoft:internet_explorer:8.0.6001:sp30:*:*:*:*:*:*`, false, true},
+		{`cpe:2.3:o:microsoft:internet_explorer:8.0.6001:sp3:*:*:*:*:*:*`, false, true},
+		{`cpe:2.3:a:microsoft:edge:8.0.6001:sp3:*:*:*:*:*:*`, false, true},
+	}
+	for _, c := range cases {
+		tgt, err := UnbindFmtString(c.tgt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := ca.MatchWithoutVersion(tgt); got != c.withoutVersion || got != src.MatchWithoutVersion(tgt) {
+			t.Errorf("MatchWithoutVersion(%q) = %t, expected %t", c.tgt, got, c.withoutVersion)
+		}
+		if got := ca.MatchOnlyVersion(tgt); got != c.onlyV || got != src.MatchOnlyVersion(tgt) {
+			t.Errorf("MatchOnlyVersion(%q) = %t, expected %t", c.tgt, got, c.onlyV)
+		}
+	}
+	if Compile(nil) != nil {
+		t.Error("Compile(nil) should return nil")
+	}
+}
+
+func BenchmarkCompiledMatch(b *testing.B) {
+	src := `cpe:2.3:a:microsoft:*internet_ex??????:8.0.*:sp?:*:*:*:*:*:*`
+	tgt := `cpe:2.3:a:microsoft:internet_explorer:8.1.6001:sp3:*:*:*:*:*:*`
+	srcAttr, err := UnbindFmtString(src)
+	if err != nil {
+		b.Fatalf("failed to unbind WFN from FSB %q: %v", src, err)
+	}
+	tgtAttr, err := UnbindFmtString(tgt)
+	if err != nil {
+		b.Fatalf("failed to unbind WFN from FSB %q: %v", tgt, err)
+	}
+	ca := Compile(srcAttr)
+	for i := 0; i < b.N; i++ {
+		ca.MatchWithoutVersion(tgtAttr)
+		ca.MatchOnlyVersion(tgtAttr)
+	}
+}
+
+func BenchmarkUncompiledMatch(b *testing.B) {
+	src := `cpe:2.3:a:microsoft:*internet_ex??????:8.0.*:sp?:*:*:*:*:*:*`
+	tgt := `cpe:2.3:a:microsoft:internet_explorer:8.1.6001:sp3:*:*:*:*:*:*`
+	srcAttr, err := UnbindFmtString(src)
+	if err != nil {
+		b.Fatalf("failed to unbind WFN from FSB %q: %v", src, err)
+	}
+	tgtAttr, err := UnbindFmtString(tgt)
+	if err != nil {
+		b.Fatalf("failed to unbind WFN from FSB %q: %v", tgt, err)
+	}
+	for i := 0; i < b.N; i++ {
+		srcAttr.MatchWithoutVersion(tgtAttr)
+		srcAttr.MatchOnlyVersion(tgtAttr)
+	}
+}
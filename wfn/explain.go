@@ -0,0 +1,142 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"sort"
+	"strings"
+)
+
+// Explanation tells why a Matcher matched some attributes; it mirrors the tree of matchers,
+// keeping only the branches which matched
+type Explanation struct {
+	// Operator is AND, OR or NOT for matchers combining other matchers, empty for CPE match criteria
+	Operator string `json:"operator,omitempty"`
+	// CPE is the formatted string of the CPE match criterion
+	CPE string `json:"cpe,omitempty"`
+	// VersionRange is the version range of the criterion, if it has one
+	VersionRange string `json:"version_range,omitempty"`
+	// Context is set if the branch matched the context of vulnerable attributes, e.g. the platform they run on
+	Context bool `json:"context,omitempty"`
+	// Matched are formatted strings of the attributes matched by this branch
+	Matched  []string       `json:"matched"`
+	Children []*Explanation `json:"children,omitempty"`
+}
+
+// Explainer is implemented by Matchers which can explain their matches
+type Explainer interface {
+	// Explain returns why some of attrs matched, nil if none did
+	Explain(attrs []*Attributes, requireVersion bool) *Explanation
+}
+
+// Explain returns why the matcher matched some of attrs;
+// it returns nil if none matched or the matcher doesn't implement Explainer
+func Explain(m Matcher, attrs []*Attributes, requireVersion bool) *Explanation {
+	if e, ok := m.(Explainer); ok {
+		return e.Explain(attrs, requireVersion)
+	}
+	return nil
+}
+
+// String returns the explanation in one line, e.g.
+// AND(OR(cpe:2.3:a:vendor:product:*:*:*:*:*:*:*:* >=1.0 <1.2); cpe:2.3:o:vendor:os:*:*:*:*:*:*:*:*)
+func (e *Explanation) String() string {
+	if e == nil {
+		return ""
+	}
+	if e.Operator == "" {
+		if e.VersionRange != "" {
+			return e.CPE + " " + e.VersionRange
+		}
+		return e.CPE
+	}
+	children := make([]string, len(e.Children))
+	for i, c := range e.Children {
+		children[i] = c.String()
+	}
+	return e.Operator + "(" + strings.Join(children, "; ") + ")"
+}
+
+// newExplanation returns an explanation of the matches, nil if there are none
+func newExplanation(op string, matches []*Attributes) *Explanation {
+	if len(matches) == 0 {
+		return nil
+	}
+	e := Explanation{Operator: op, Matched: make([]string, len(matches))}
+	for i, m := range matches {
+		e.Matched[i] = m.BindToFmtString()
+	}
+	sort.Strings(e.Matched)
+	return &e
+}
+
+// Explain is part of the Explainer interface
+func (mm *multiMatcher) Explain(attrs []*Attributes, requireVersion bool) *Explanation {
+	op := "OR"
+	if mm.allMatch {
+		op = "AND"
+	}
+	e := newExplanation(op, mm.Match(attrs, requireVersion))
+	if e == nil {
+		return nil
+	}
+	for _, m := range mm.matchers {
+		if _, ok := m.(*negatedMatcher); ok && !mm.allMatch {
+			// negated matchers don't take part in MatchAny
+			continue
+		}
+		if c := Explain(m, attrs, requireVersion); c != nil {
+			e.Children = append(e.Children, c)
+		}
+	}
+	return e
+}
+
+// Explain is part of the Explainer interface
+// the negated matcher didn't match, so there's nothing more to tell
+func (nm notMatcher) Explain(attrs []*Attributes, requireVersion bool) *Explanation {
+	return newExplanation("NOT", nm.Match(attrs, requireVersion))
+}
+
+// Explain is part of the Explainer interface
+// the negated matcher holds if its matcher didn't match any attribute, so it didn't match anything itself
+func (nm *negatedMatcher) Explain(attrs []*Attributes, requireVersion bool) *Explanation {
+	if !nm.holds(attrs, requireVersion) {
+		return nil
+	}
+	return &Explanation{Operator: "NOT", Matched: []string{}}
+}
+
+// Explain is part of the Explainer interface
+func (pm *platformMatcher) Explain(attrs []*Attributes, requireVersion bool) *Explanation {
+	e := Explain(pm.Matcher, attrs, requireVersion)
+	if e != nil {
+		e.Context = true
+	}
+	return e
+}
+
+// Explain is part of the Explainer interface
+func (rm *rangeMatcher) Explain(attrs []*Attributes, requireVersion bool) *Explanation {
+	e := newExplanation("", rm.Match(attrs, requireVersion))
+	if e == nil {
+		return nil
+	}
+	e.CPE = rm.Attributes.BindToFmtString()
+	if rm.versionRange.HasBounds() {
+		e.VersionRange = rm.versionRange.String()
+	}
+	return e
+}
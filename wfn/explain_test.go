@@ -0,0 +1,100 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	lib := VersionRange{StartIncluding: "1.0", EndExcluding: "1.2"}.Matcher(&Attributes{Part: "a", Vendor: "vendor", Product: "lib"})
+	os := VersionRange{}.Matcher(&Attributes{Part: "o", Vendor: "vendor", Product: "os"})
+	other := VersionRange{}.Matcher(&Attributes{Part: "a", Vendor: "vendor", Product: "other"})
+	m := MatchAll(MatchAny(lib, other), os, DontMatch(VersionRange{}.Matcher(&Attributes{Part: "h"})))
+
+	attrs := []*Attributes{
+		{Part: "a", Vendor: "vendor", Product: "lib", Version: `1\.1`},
+		{Part: "o", Vendor: "vendor", Product: "os"},
+	}
+	e := Explain(m, attrs, false)
+	expect := &Explanation{
+		Operator: "AND",
+		Matched:  []string{"cpe:2.3:a:vendor:lib:1.1:*:*:*:*:*:*:*", "cpe:2.3:o:vendor:os:*:*:*:*:*:*:*:*"},
+		Children: []*Explanation{
+			{
+				Operator: "OR",
+				Matched:  []string{"cpe:2.3:a:vendor:lib:1.1:*:*:*:*:*:*:*"},
+				Children: []*Explanation{
+					{
+						CPE:          "cpe:2.3:a:vendor:lib:*:*:*:*:*:*:*:*",
+						VersionRange: ">=1.0 <1.2",
+						Matched:      []string{"cpe:2.3:a:vendor:lib:1.1:*:*:*:*:*:*:*"},
+					},
+				},
+			},
+			{
+				CPE:     "cpe:2.3:o:vendor:os:*:*:*:*:*:*:*:*",
+				Matched: []string{"cpe:2.3:o:vendor:os:*:*:*:*:*:*:*:*"},
+			},
+			{
+				Operator: "NOT",
+				Matched:  []string{"cpe:2.3:a:vendor:lib:1.1:*:*:*:*:*:*:*", "cpe:2.3:o:vendor:os:*:*:*:*:*:*:*:*"},
+			},
+		},
+	}
+	if !reflect.DeepEqual(e, expect) {
+		t.Fatalf("expected %+v, got %+v", expect, e)
+	}
+	if s := e.String(); s != "AND(OR(cpe:2.3:a:vendor:lib:*:*:*:*:*:*:*:* >=1.0 <1.2); cpe:2.3:o:vendor:os:*:*:*:*:*:*:*:*; NOT())" {
+		t.Errorf("unexpected string %q", s)
+	}
+
+	if e := Explain(m, attrs[:1], false); e != nil {
+		t.Errorf("expected no explanation without a match, got %v", e)
+	}
+	if e := Explain(opaqueMatcher{m}, attrs, false); e != nil {
+		t.Errorf("expected no explanation of a matcher which can't explain, got %v", e)
+	}
+}
+
+func TestExplainContext(t *testing.T) {
+	app := VersionRange{}.Matcher(&Attributes{Part: "a", Vendor: "vendor", Product: "app"})
+	os := Platform(VersionRange{}.Matcher(&Attributes{Part: "o", Vendor: "vendor", Product: "os"}))
+	bad := Negate(VersionRange{}.Matcher(&Attributes{Part: "o", Vendor: "vendor", Product: "bad_os"}))
+	attrs := []*Attributes{
+		{Part: "a", Vendor: "vendor", Product: "app"},
+		{Part: "o", Vendor: "vendor", Product: "os"},
+	}
+
+	e := Explain(MatchAll(app, os, bad), attrs, false)
+	if s := e.String(); s != "AND(cpe:2.3:a:vendor:app:*:*:*:*:*:*:*:*; cpe:2.3:o:vendor:os:*:*:*:*:*:*:*:*; NOT())" {
+		t.Fatalf("unexpected explanation %q", s)
+	}
+	if e.Children[0].Context || !e.Children[1].Context {
+		t.Errorf("expected only the os to be matched as context, got %v and %v", e.Children[0].Context, e.Children[1].Context)
+	}
+	if s := Explain(MatchAny(app, bad), attrs, false).String(); s != "OR(cpe:2.3:a:vendor:app:*:*:*:*:*:*:*:*)" {
+		t.Errorf("unexpected explanation %q", s)
+	}
+	if e := Explain(MatchAll(app, os, bad), append(attrs, &Attributes{Part: "o", Vendor: "vendor", Product: "bad_os"}), false); e != nil {
+		t.Errorf("expected no explanation, got %v", e)
+	}
+}
+
+// opaqueMatcher hides the Explainer implementation of the wrapped matcher
+type opaqueMatcher struct {
+	Matcher
+}
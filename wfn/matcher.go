@@ -23,6 +23,44 @@ type Matcher interface {
 	Config() []*Attributes
 }
 
+// ContextMatcher is implemented by Matchers which tell apart attributes matched as vulnerable
+// from the ones matched as their context, e.g. the platform a vulnerable application runs on
+type ContextMatcher interface {
+	// MatchContext returns attributes which match it, split into the vulnerable ones and their context;
+	// an attribute matched as both is vulnerable
+	MatchContext(attrs []*Attributes, requireVersion bool) (vulnerable, context []*Attributes)
+}
+
+// MatchContext returns attributes which match the matcher, split into the vulnerable ones and their context;
+// all of them are vulnerable if the matcher doesn't implement ContextMatcher
+func MatchContext(m Matcher, attrs []*Attributes, requireVersion bool) (vulnerable, context []*Attributes) {
+	if cm, ok := m.(ContextMatcher); ok {
+		return cm.MatchContext(attrs, requireVersion)
+	}
+	return m.Match(attrs, requireVersion), nil
+}
+
+// PlatformMatcher is implemented by Matchers which match running_on conditions, the operating systems matched
+// as the context of vulnerable attributes, against the platforms only, rather than all attributes
+type PlatformMatcher interface {
+	// MatchPlatform returns attributes and platforms which match it; versions of platforms are compared,
+	// but running_on conditions which match all versions match them even if the version is required
+	MatchPlatform(attrs, platforms []*Attributes, requireVersion bool) (matches []*Attributes)
+}
+
+// MatchPlatform returns attributes and platforms (e.g. the operating system the attributes are installed on)
+// which match the matcher, so running_on conditions match only the platforms, see PlatformMatcher;
+// all of them are matched as Match does if the matcher doesn't implement PlatformMatcher
+func MatchPlatform(m Matcher, attrs, platforms []*Attributes, requireVersion bool) []*Attributes {
+	if pm, ok := m.(PlatformMatcher); ok {
+		return pm.MatchPlatform(attrs, platforms, requireVersion)
+	}
+	if len(platforms) != 0 {
+		attrs = append(attrs[:len(attrs):len(attrs)], platforms...)
+	}
+	return m.Match(attrs, requireVersion)
+}
+
 // Attrs is part of the Matcher interface
 func (a *Attributes) Config() []*Attributes {
 	return []*Attributes{a}
@@ -64,6 +102,28 @@ func DontMatch(m Matcher) Matcher {
 	return notMatcher{m}
 }
 
+// Negate returns a Matcher of a negated configuration node, which holds if m doesn't match any of the attributes;
+// it doesn't match attributes itself, so it only constrains the MatchAll matchers it's part of
+// (e.g. an application is vulnerable unless it runs on some platform) and is ignored by MatchAny ones;
+// on its own it matches all attributes if m doesn't match any of them
+func Negate(m Matcher) Matcher {
+	return &negatedMatcher{m}
+}
+
+// Platform returns a Matcher which matches what m matches, but as the context of vulnerable attributes
+// rather than vulnerable ones, e.g. the platform a vulnerable application runs on, see MatchContext;
+// if m matches only operating systems, it's a running_on condition, see MatchPlatform
+func Platform(m Matcher) Matcher {
+	pm := platformMatcher{Matcher: m, os: true}
+	for _, attrs := range m.Config() {
+		if attrs == nil || attrs.Part != "o" {
+			pm.os = false
+			break
+		}
+	}
+	return &pm
+}
+
 type multiMatcher struct {
 	matchers []Matcher
 	// if true, match will only return something if all matchers matched at least something
@@ -72,25 +132,69 @@ type multiMatcher struct {
 
 // Match is part of the Matcher interface
 func (mm *multiMatcher) Match(attrs []*Attributes, requireVersion bool) []*Attributes {
-	matched := make(map[*Attributes]bool)
+	vulnerable, context := mm.MatchContext(attrs, requireVersion)
+	return append(vulnerable, context...)
+}
+
+// MatchContext is part of the ContextMatcher interface
+func (mm *multiMatcher) MatchContext(attrs []*Attributes, requireVersion bool) (vulnerable, context []*Attributes) {
+	return mm.combine(
+		func(m Matcher) ([]*Attributes, []*Attributes) {
+			return MatchContext(m, attrs, requireVersion)
+		},
+		func(nm *negatedMatcher) bool {
+			return nm.holds(attrs, requireVersion)
+		},
+	)
+}
+
+// MatchPlatform is part of the PlatformMatcher interface
+func (mm *multiMatcher) MatchPlatform(attrs, platforms []*Attributes, requireVersion bool) []*Attributes {
+	vulnerable, context := mm.combine(
+		func(m Matcher) ([]*Attributes, []*Attributes) {
+			return MatchPlatform(m, attrs, platforms, requireVersion), nil
+		},
+		func(nm *negatedMatcher) bool {
+			return len(MatchPlatform(nm.Matcher, attrs, platforms, requireVersion)) == 0
+		},
+	)
+	return append(vulnerable, context...)
+}
+
+// combine returns matches of the matchers, as AND or OR of them, split into vulnerable and context ones;
+// match returns matches of a matcher and holds returns whether a negated matcher holds
+func (mm *multiMatcher) combine(match func(Matcher) (vulnerable, context []*Attributes), holds func(*negatedMatcher) bool) (vulnerable, context []*Attributes) {
+	matched := make(map[*Attributes]bool) // attribute -> whether it's vulnerable
 	for _, matcher := range mm.matchers {
-		matches := matcher.Match(attrs, requireVersion)
-		if mm.allMatch && len(matches) == 0 {
+		if nm, ok := matcher.(*negatedMatcher); ok {
+			if mm.allMatch && !holds(nm) {
+				return nil, nil
+			}
+			continue
+		}
+		v, c := match(matcher)
+		if mm.allMatch && len(v) == 0 && len(c) == 0 {
 			// all matchers need to match at least one attr
-			return nil
+			return nil, nil
 		}
-		for _, m := range matches {
+		for _, m := range v {
 			matched[m] = true
 		}
+		for _, m := range c {
+			if _, ok := matched[m]; !ok {
+				matched[m] = false
+			}
+		}
 	}
 
-	matches := make([]*Attributes, len(matched))
-	i := 0
-	for m := range matched {
-		matches[i] = m
-		i++
+	for m, vuln := range matched {
+		if vuln {
+			vulnerable = append(vulnerable, m)
+		} else {
+			context = append(context, m)
+		}
 	}
-	return matches
+	return vulnerable, context
 }
 
 // Attrs is part of the Matcher interface
@@ -120,3 +224,47 @@ func (nm notMatcher) Match(attrs []*Attributes, requireVersion bool) (matches []
 	}
 	return matches
 }
+
+type negatedMatcher struct {
+	Matcher
+}
+
+// holds returns true if the negated matcher doesn't match any of the attributes
+func (nm *negatedMatcher) holds(attrs []*Attributes, requireVersion bool) bool {
+	return len(nm.Matcher.Match(attrs, requireVersion)) == 0
+}
+
+// Match is part of the Matcher interface
+func (nm *negatedMatcher) Match(attrs []*Attributes, requireVersion bool) []*Attributes {
+	if nm.holds(attrs, requireVersion) {
+		return attrs
+	}
+	return nil
+}
+
+// MatchPlatform is part of the PlatformMatcher interface
+func (nm *negatedMatcher) MatchPlatform(attrs, platforms []*Attributes, requireVersion bool) []*Attributes {
+	if len(MatchPlatform(nm.Matcher, attrs, platforms, requireVersion)) == 0 {
+		return append(attrs[:len(attrs):len(attrs)], platforms...)
+	}
+	return nil
+}
+
+type platformMatcher struct {
+	Matcher
+	// os is set for running_on conditions, which match only operating systems
+	os bool
+}
+
+// MatchPlatform is part of the PlatformMatcher interface
+func (pm *platformMatcher) MatchPlatform(attrs, platforms []*Attributes, requireVersion bool) []*Attributes {
+	if pm.os {
+		return pm.Matcher.Match(platforms, false)
+	}
+	return MatchPlatform(pm.Matcher, attrs, platforms, requireVersion)
+}
+
+// MatchContext is part of the ContextMatcher interface
+func (pm *platformMatcher) MatchContext(attrs []*Attributes, requireVersion bool) (vulnerable, context []*Attributes) {
+	return nil, pm.Matcher.Match(attrs, requireVersion)
+}
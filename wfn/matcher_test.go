@@ -0,0 +1,120 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestMatchContext(t *testing.T) {
+	app := &Attributes{Part: "a", Vendor: "vendor", Product: "app", Version: `1\.0`}
+	os := &Attributes{Part: "o", Vendor: "vendor", Product: "os"}
+	bad := &Attributes{Part: "o", Vendor: "vendor", Product: "bad_os"}
+	other := &Attributes{Part: "a", Vendor: "other", Product: "app"}
+
+	matcher := func(attrs *Attributes) Matcher {
+		return VersionRange{}.Matcher(&Attributes{Part: attrs.Part, Vendor: attrs.Vendor, Product: attrs.Product})
+	}
+	// app is vulnerable when it runs on os, unless bad_os is installed as well
+	runningOn := MatchAll(MatchAny(matcher(app)), MatchAny(Platform(matcher(os))), Negate(MatchAny(matcher(bad))))
+
+	names := func(attrs []*Attributes) []string {
+		var names []string
+		for _, a := range attrs {
+			names = append(names, a.Product)
+		}
+		sort.Strings(names)
+		return names
+	}
+
+	for i, tc := range []struct {
+		m                   Matcher
+		attrs               []*Attributes
+		vulnerable, context []string
+	}{
+		{m: runningOn, attrs: []*Attributes{app, os}, vulnerable: []string{"app"}, context: []string{"os"}},
+		{m: runningOn, attrs: []*Attributes{app, os, other}, vulnerable: []string{"app"}, context: []string{"os"}},
+		{m: runningOn, attrs: []*Attributes{app, os, bad}},
+		{m: runningOn, attrs: []*Attributes{app}},
+		{m: runningOn, attrs: []*Attributes{os}},
+		// negated matchers don't match anything in MatchAny
+		{m: MatchAny(matcher(app), Negate(matcher(bad))), attrs: []*Attributes{app, bad}, vulnerable: []string{"app"}},
+		{m: MatchAny(matcher(app), Negate(matcher(bad))), attrs: []*Attributes{os}},
+		{m: MatchAll(Negate(matcher(bad))), attrs: []*Attributes{app}},
+		// on their own, they match everything if the negated matcher doesn't match anything
+		{m: Negate(matcher(bad)), attrs: []*Attributes{app, os}, vulnerable: []string{"app", "os"}},
+		{m: Negate(matcher(bad)), attrs: []*Attributes{app, bad}},
+		// attributes matched as vulnerable and as context are vulnerable
+		{m: MatchAny(Platform(matcher(app)), matcher(app)), attrs: []*Attributes{app}, vulnerable: []string{"app"}},
+		{m: Platform(MatchAny(matcher(app), matcher(os))), attrs: []*Attributes{app, os}, context: []string{"app", "os"}},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			vulnerable, context := MatchContext(tc.m, tc.attrs, false)
+			if got := names(vulnerable); !reflect.DeepEqual(got, tc.vulnerable) {
+				t.Errorf("expected vulnerable %v, got %v", tc.vulnerable, got)
+			}
+			if got := names(context); !reflect.DeepEqual(got, tc.context) {
+				t.Errorf("expected context %v, got %v", tc.context, got)
+			}
+			var expect []string
+			expect = append(append(expect, tc.vulnerable...), tc.context...)
+			sort.Strings(expect)
+			if got := names(tc.m.Match(tc.attrs, false)); !reflect.DeepEqual(got, expect) {
+				t.Errorf("expected matches %v, got %v", expect, got)
+			}
+		})
+	}
+}
+
+func TestMatchPlatform(t *testing.T) {
+	app := &Attributes{Part: "a", Vendor: "vendor", Product: "app", Version: `1\.0`}
+	runtime := &Attributes{Part: "a", Vendor: "vendor", Product: "runtime", Version: `2\.0`}
+	os := &Attributes{Part: "o", Vendor: "vendor", Product: "os", Version: `10`}
+
+	matcher := func(attrs *Attributes) Matcher {
+		return VersionRange{}.Matcher(&Attributes{Part: attrs.Part, Vendor: attrs.Vendor, Product: attrs.Product})
+	}
+	vulnerable := VersionRange{EndExcluding: "2.0"}.Matcher(&Attributes{Part: "a", Vendor: "vendor", Product: "app"})
+	runningOn := MatchAll(vulnerable, Platform(matcher(os)))
+	runningWith := MatchAll(vulnerable, Platform(matcher(runtime)))
+	notOn := MatchAll(vulnerable, Negate(Platform(matcher(os))))
+
+	for i, tc := range []struct {
+		m                Matcher
+		attrs, platforms []*Attributes
+		requireVersion   bool
+		expect           int
+	}{
+		{m: runningOn, attrs: []*Attributes{app}, platforms: []*Attributes{os}, expect: 2},
+		{m: runningOn, attrs: []*Attributes{app}, platforms: []*Attributes{os}, requireVersion: true, expect: 2},
+		{m: runningOn, attrs: []*Attributes{app, os}},
+		{m: runningOn, attrs: []*Attributes{app}},
+		{m: runningWith, attrs: []*Attributes{app, runtime}, platforms: []*Attributes{os}, expect: 2},
+		{m: runningWith, attrs: []*Attributes{app, runtime}, requireVersion: true},
+		{m: notOn, attrs: []*Attributes{app, os}, expect: 1},
+		{m: notOn, attrs: []*Attributes{app}, platforms: []*Attributes{os}},
+		// vulnerable operating systems are matched as platforms as well
+		{m: matcher(os), platforms: []*Attributes{os}, expect: 1},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			if got := MatchPlatform(tc.m, tc.attrs, tc.platforms, tc.requireVersion); len(got) != tc.expect {
+				t.Fatalf("expected %d matches, got %v", tc.expect, got)
+			}
+		})
+	}
+}
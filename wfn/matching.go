@@ -16,6 +16,7 @@ package wfn
 
 import (
 	"fmt"
+	"strings"
 )
 
 // Possible values of Relation type
@@ -24,6 +25,9 @@ const (
 	Subset
 	Equal
 	Superset
+	// Undefined is the relation of attribute values which can't be compared,
+	// e.g. when target contains wildcards
+	Undefined
 )
 
 // HasWildcard returns true if attribute has a wildcard symbol in it
@@ -45,7 +49,7 @@ func HasWildcard(s string) bool {
 	return false
 }
 
-// Relation describes four possible set relations of wfns attribute-value
+// Relation describes possible set relations of wfns attribute-value
 type Relation int
 
 // String return human readable representation of Relation value
@@ -59,6 +63,8 @@ func (r Relation) String() string {
 		return "EQUAL"
 	case Superset:
 		return "SUPERSET"
+	case Undefined:
+		return "UNDEFINED"
 	default:
 		return fmt.Sprintf("Undefined value %d", r)
 	}
@@ -199,18 +205,50 @@ func (c Comparison) IsSuperset() bool {
 	}
 }
 
-// Relation returns relation between matched CPE names
+// Relation returns relation between matched CPE names.
+// Names are disjoint if any pair of attributes is disjoint; otherwise the relation is undefined
+// if any pair of attributes is undefined or attributes relate as both subset and superset.
 func (c Comparison) Relation() Relation {
-	if c.IsSubset() {
+	switch {
+	case c.IsDisjoint():
+		return Disjoint
+	case c.isUndefined():
+		return Undefined
+	case c.IsEqual():
+		return Equal
+	case c.IsSubset():
 		return Subset
+	case c.IsSuperset():
+		return Superset
+	default:
+		return Undefined
 	}
-	if c.IsEqual() {
-		return Equal
+}
+
+func (c Comparison) isUndefined() bool {
+	for _, r := range []Relation{
+		c.Part, c.Vendor, c.Product, c.Version, c.Update, c.Edition,
+		c.Language, c.SWEdition, c.TargetSW, c.TargetHW, c.Other,
+	} {
+		if r == Undefined {
+			return true
+		}
 	}
-	if c.IsSuperset() {
-		return Superset
+	return false
+}
+
+// Relate returns the set relation between source and target CPE names
+// as per Name Matching Specification v.2.3 (NISTIR 7696).
+// Undefined is returned if names can't be compared, e.g. when target has wildcards.
+func Relate(src, tgt *Attributes) Relation {
+	if src == nil || tgt == nil {
+		return Undefined
+	}
+	c, err := Compare(src, tgt)
+	if err != nil {
+		return Undefined
 	}
-	return Disjoint
+	return c.Relation()
 }
 
 // Compare performs comparison of each attribute-value (A-V) of the wfns
@@ -222,10 +260,12 @@ func (c Comparison) Relation() Relation {
 // | Attribute Relation Set                     |  Name Comparison Relation          |
 // +--------------------------------------------+------------------------------------+
 // | any attribute relation is !=               | CPE name relation is DISJOINT (!=) |
+// | any attribute relation is undefined        | CPE name relation is UNDEFINED     |
 // | all attribute relations are ==             | CPE name relation is EQAL (==)     |
 // | all attribute relations are Subset or ==   | CPE name relation is Subset        |
 // | all attribute relations are Superset or == | CPE name relation is Superset      |
 // +--------------------------------------------+------------------------------------+
+// Comparison stops at the first attribute which can't be compared and returns an error.
 func Compare(src, tgt *Attributes) (Comparison, error) {
 	var result Comparison
 	var err error
@@ -312,10 +352,10 @@ func Match(src, tgt *Attributes) bool {
 // | m1 + w     | m2 + w     | undefined    |
 // +----------------------------------------+
 func CompareAttr(src, tgt string) (Relation, error) {
-	if src != NA && src != Any && HasWildcard(tgt) {
-		return Disjoint, fmt.Errorf("target attribute value cannot contain wildcard")
+	if HasWildcard(tgt) {
+		return Undefined, fmt.Errorf("target attribute value cannot contain wildcard")
 	}
-	if src == tgt {
+	if strings.EqualFold(src, tgt) {
 		return Equal, nil
 	}
 	if src == Any {
@@ -327,7 +367,7 @@ func CompareAttr(src, tgt string) (Relation, error) {
 	if src == NA || tgt == NA {
 		return Disjoint, nil
 	}
-	return matchStr(src, tgt), nil
+	return matchStr(strings.ToLower(src), strings.ToLower(tgt)), nil
 }
 
 // matchAttr returns true if relation between src and tgt is one of Equal, Subset or Superset.
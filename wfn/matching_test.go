@@ -109,6 +109,80 @@ func TestCompare(t *testing.T) {
 	}
 }
 
+func TestCompareAttr(t *testing.T) {
+	cases := []struct {
+		src, tgt string
+		fail     bool
+		expect   Relation
+	}{
+		{Any, Any, false, Equal},
+		{Any, NA, false, Superset},
+		{Any, "foo", false, Superset},
+		{Any, "f*", true, Undefined},
+		{NA, Any, false, Subset},
+		{NA, NA, false, Equal},
+		{NA, "foo", false, Disjoint},
+		{NA, "f?o", true, Undefined},
+		{"foo", "foo", false, Equal},
+		{"foo", "FOO", false, Equal},
+		{"foo", "bar", false, Disjoint},
+		{"foo", "f*", true, Undefined},
+		{"foo", NA, false, Disjoint},
+		{"foo", Any, false, Subset},
+		{"f*", "foo", false, Superset},
+		{"F?O", "foo", false, Superset},
+		{"b*", "foo", false, Disjoint},
+		{"f*", Any, false, Subset},
+		{"f*", NA, false, Disjoint},
+		{"f*", "f?o", true, Undefined},
+	}
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			r, err := CompareAttr(c.src, c.tgt)
+			if c.fail && err == nil {
+				t.Fatalf("comparison of %q to %q was expected to fail", c.src, c.tgt)
+			}
+			if !c.fail && err != nil {
+				t.Fatalf("comparison of %q to %q was expected to succeed, but failed: %v", c.src, c.tgt, err)
+			}
+			if r != c.expect {
+				t.Fatalf("CompareAttr(%q, %q) returned %v, %v was expected", c.src, c.tgt, r, c.expect)
+			}
+		})
+	}
+}
+
+func TestRelate(t *testing.T) {
+	cases := []struct {
+		src, tgt string
+		expect   Relation
+	}{
+		{"cpe:2.3:a:microsoft:internet_explorer:8.0.6001:sp3:*:*:*:*:*:*", "cpe:2.3:a:microsoft:internet_explorer:8.0.6001:sp3:*:*:*:*:*:*", Equal},
+		{"cpe:2.3:a:microsoft:internet_explorer:*:*:*:*:*:*:*:*", "cpe:2.3:a:microsoft:internet_explorer:8.0.6001:sp3:*:*:*:*:*:*", Superset},
+		{"cpe:2.3:a:microsoft:internet_explorer:8.0.6001:sp3:*:*:*:*:*:*", "cpe:2.3:a:microsoft:internet_explorer:*:*:*:*:*:*:*:*", Subset},
+		{"cpe:2.3:a:microsoft:internet_explorer:8.0.6001:*:*:*:*:*:*:*", "cpe:2.3:a:microsoft:internet_explorer:*:sp3:*:*:*:*:*:*", Undefined},
+		{"cpe:2.3:a:microsoft:internet_explorer:8.0.6001:-:*:*:*:*:*:*", "cpe:2.3:a:microsoft:internet_explorer:8.0.6001:sp3:*:*:*:*:*:*", Disjoint},
+		{"cpe:2.3:a:microsoft:internet_explorer:*:-:*:*:*:*:*:*", "cpe:2.3:a:microsoft:internet_explorer:8.0.6001:*:*:*:*:*:*:*", Undefined},
+		{"cpe:2.3:a:microsoft:internet_explorer:*:*:*:*:*:*:*:*", "cpe:2.3:a:microsoft:internet_explorer:8.*:*:*:*:*:*:*:*", Undefined},
+		{"cpe:2.3:a:Microsoft:Internet_Explorer:*:*:*:*:*:*:*:*", "cpe:2.3:a:microsoft:internet_explorer:-:*:*:*:*:*:*:*", Superset},
+	}
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			src, err := UnbindFmtString(c.src)
+			if err != nil {
+				t.Fatalf("failed to unbind WFN from FSB %q: %v", c.src, err)
+			}
+			tgt, err := UnbindFmtString(c.tgt)
+			if err != nil {
+				t.Fatalf("failed to unbind WFN from FSB %q: %v", c.tgt, err)
+			}
+			if r := Relate(src, tgt); r != c.expect {
+				t.Fatalf("Relate(%q, %q) returned %v, %v was expected", c.src, c.tgt, r, c.expect)
+			}
+		})
+	}
+}
+
 func BenchmarkCompare(b *testing.B) {
 	src := `cpe:2.3:a:microsoft:*internet_ex??????:8.0.*:sp?:*:*:*:*:*:*`
 	tgt := `cpe:2.3:a:microsoft:internet_explorer:8.1.6001:sp3:*:*:*:*:*:*`
@@ -12,7 +12,7 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-package nvd
+package wfn
 
 import (
 	"fmt"
@@ -46,7 +46,7 @@ func TestSmartVerCmp(t *testing.T) {
 	}
 	for _, c := range cases {
 		t.Run(fmt.Sprintf("%q vs %q", c.v1, c.v2), func(t *testing.T) {
-			if ret := smartVerCmp(c.v1, c.v2); ret != c.ret {
+			if ret := SmartVerCmp(c.v1, c.v2); ret != c.ret {
 				t.Fatalf("expected %d, got %d", c.ret, ret)
 			}
 		})
@@ -67,7 +67,7 @@ func BenchmarkSmartVerCmp(b *testing.B) {
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		for _, c := range cases {
-			smartVerCmp(c.v1, c.v2)
+			SmartVerCmp(c.v1, c.v2)
 		}
 	}
 }
@@ -0,0 +1,173 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"fmt"
+	"strings"
+)
+
+var strictParsers = map[string]func(s string) (*Attributes, error){
+	uriPrefix: UnbindURIStrict,
+	fsbPrefix: UnbindFmtStringStrict,
+}
+
+// ParseStrict parses Attributes from URI or formatted string binding.
+// Unlike Parse, it rejects malformed bindings instead of silently accepting them.
+func ParseStrict(s string) (*Attributes, error) {
+	for prefix, parserFunc := range strictParsers {
+		if strings.HasPrefix(s, prefix) {
+			return parserFunc(s)
+		}
+	}
+	return nil, fmt.Errorf("wfn: unsupported format %q", s)
+}
+
+// UnbindURIStrict loads WFN from URI.
+// It fails if URI has more than 7 components, packed edition doesn't have exactly 5 components,
+// or if any of the components contains illegal characters or bad percent-encoding.
+func UnbindURIStrict(s string) (*Attributes, error) {
+	if !strings.HasPrefix(s, uriPrefix) {
+		return nil, fmt.Errorf("unbind uri: bad prefix in URI %q", s)
+	}
+	components := strings.Split(s[len(uriPrefix):], ":")
+	if len(components) > 7 {
+		return nil, fmt.Errorf("unbind uri: too many components in URI %q: %d", s, len(components))
+	}
+	for i, c := range components {
+		if i == 5 && strings.HasPrefix(c, "~") {
+			packed := strings.Split(c[1:], "~")
+			if len(packed) != 5 {
+				return nil, fmt.Errorf("unbind uri: packed edition must have 5 components, got %d in %q", len(packed), s)
+			}
+			for _, p := range packed {
+				if err := validateURIComponent(p); err != nil {
+					return nil, fmt.Errorf("unbind uri: %q: %v", s, err)
+				}
+			}
+			continue
+		}
+		if err := validateURIComponent(c); err != nil {
+			return nil, fmt.Errorf("unbind uri: %q: %v", s, err)
+		}
+	}
+	attr, err := UnbindURI(s)
+	if err != nil {
+		return nil, err
+	}
+	if err = validatePart(attr.Part); err != nil {
+		return nil, fmt.Errorf("unbind uri: %q: %v", s, err)
+	}
+	return attr, nil
+}
+
+// UnbindFmtStringStrict loads WFN from formatted string.
+// It fails if formatted string doesn't have exactly 13 components,
+// or if any of the components contains illegal or unquoted characters.
+func UnbindFmtStringStrict(s string) (*Attributes, error) {
+	if !strings.HasPrefix(s, fsbPrefix) {
+		return nil, fmt.Errorf("bad prefix in FSB %q", s)
+	}
+	components := splitFmtString(s[len(fsbPrefix):])
+	if n := len(components) + 2; n != 13 {
+		return nil, fmt.Errorf("unbind formatted string: %q must have 13 components, got %d", s, n)
+	}
+	for _, c := range components {
+		if err := validateFmtStringComponent(c); err != nil {
+			return nil, fmt.Errorf("unbind formatted string: %q: %v", s, err)
+		}
+	}
+	attr, err := UnbindFmtString(s)
+	if err != nil {
+		return nil, err
+	}
+	if err = validatePart(attr.Part); err != nil {
+		return nil, fmt.Errorf("unbind formatted string: %q: %v", s, err)
+	}
+	return attr, nil
+}
+
+// splitFmtString splits formatted string by unquoted colons
+func splitFmtString(s string) []string {
+	var components []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ':':
+			components = append(components, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(components, s[start:])
+}
+
+func validateURIComponent(s string) error {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case isAlnum(c) || c == '_' || c == '-' || c == '.':
+		case c == '%':
+			if i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+				return fmt.Errorf("bad percent-encoding at %d in %q", i, s)
+			}
+			i += 2
+		default:
+			return fmt.Errorf("illegal character %q at %d in %q", c, i, s)
+		}
+	}
+	return nil
+}
+
+func validateFmtStringComponent(s string) error {
+	if s == "" {
+		return fmt.Errorf("empty component")
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case isAlnum(c) || c == '_' || c == '-' || c == '.' || c == '*' || c == '?':
+		case c == '\\':
+			if i+1 == len(s) {
+				return fmt.Errorf("dangling escape character in %q", s)
+			}
+			if q := s[i+1]; q <= ' ' || q > '~' || isAlnum(q) || q == '_' {
+				return fmt.Errorf("illegal quoted character %q at %d in %q", q, i+1, s)
+			}
+			i++
+		default:
+			return fmt.Errorf("illegal unquoted character %q at %d in %q", c, i, s)
+		}
+	}
+	return nil
+}
+
+func validatePart(part string) error {
+	if part != Any {
+		if _, ok := KnownParts[part]; !ok {
+			return fmt.Errorf("unknown part %q", part)
+		}
+	}
+	return nil
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
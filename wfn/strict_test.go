@@ -0,0 +1,60 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseStrict(t *testing.T) {
+	cases := []struct {
+		in   string
+		fail bool
+	}{
+		{"cpe:/a:microsoft:internet_explorer:8.0.6001:beta", false},
+		{"cpe:/a:foo%5cbar:big%24money:2010", false},
+		{"cpe:/a:hp:insight_diagnostics:7.4.0.1570::~~online~win2003~x64~", false},
+		{"cpe:/a:foo%5cbar:big%24money:2010:::en:extra", true},
+		{"cpe:/a:foo%5zbar", true},
+		{"cpe:/a:foo%5", true},
+		{"cpe:/a:foo!bar", true},
+		{"cpe:/a:hp:insight_diagnostics:7.4.0.1570::~~online~win2003", true},
+		{"cpe:/a:hp:insight_diagnostics:7.4.0.1570::sp1~online", true},
+		{"cpe:/x:microsoft", true},
+		{"cpe:2.3:a:microsoft:internet_explorer:8.0.6001:beta:*:*:*:*:*:*", false},
+		{`cpe:2.3:a:foo\\bar:big\$money:2010:*:*:*:special:ipod_touch:80gb:*`, false},
+		{`cpe:2.3:a:foo\:bar:big\$money:2010:*:*:*:special:ipod_touch:80gb:*`, false},
+		{"cpe:2.3:a:microsoft:internet_explorer:8.0.6001:beta:*:*:*:*:*", true},
+		{"cpe:2.3:a:microsoft:internet_explorer:8.0.6001:beta:*:*:*:*:*:*:*", true},
+		{"cpe:2.3:a:microsoft:internet_explorer:8.0.6001::*:*:*:*:*:*", true},
+		{"cpe:2.3:a:foo$bar:big:2010:*:*:*:*:*:*:*", true},
+		{`cpe:2.3:a:foo\bar:big:2010:*:*:*:*:*:*:*`, true},
+		{"cpe:2.3:a:foo bar:big:2010:*:*:*:*:*:*:*", true},
+		{"cpe:2.3:x:foo:bar:2010:*:*:*:*:*:*:*", true},
+		{"cpe:2.3:a:foo:bar:20*10:*:*:*:*:*:*:*", true},
+	}
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			_, err := ParseStrict(c.in)
+			if c.fail && err == nil {
+				t.Fatalf("parsing of %q was expected to fail", c.in)
+			}
+			if !c.fail && err != nil {
+				t.Fatalf("parsing of %q was expected to succeed, but failed: %v", c.in, err)
+			}
+		})
+	}
+}
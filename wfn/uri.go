@@ -36,19 +36,12 @@ func (a Attributes) BindToURI() string {
 			parts = append(parts, bindValueURI(v))
 			continue
 		}
+		// extended attributes are packed into edition, unless all of them are ANY
 		edParts := make([]string, 5)
-		allNAs := true
 		for i, v2 := range []string{a.Edition, a.SWEdition, a.TargetSW, a.TargetHW, a.Other} {
 			edParts[i] = bindValueURI(v2)
-			if edParts[i] != "-" {
-				allNAs = false
-			}
-		}
-		if allNAs {
-			parts = append(parts, "-")
-		} else {
-			parts = append(parts, pack(edParts))
 		}
+		parts = append(parts, pack(edParts))
 	}
 	// empty elements at the end of the URI should be omitted
 	for i := len(parts) - 1; i >= 0; i-- {
@@ -99,7 +92,6 @@ func UnbindURI(s string) (*Attributes, error) {
 					attr.TargetHW, i, err = unbindValueURIAtTill(s, i, '~')
 				case 4:
 					attr.Other, i, err = unbindValueURIAtTill(s, i, ':')
-				default:
 					break edition23
 				}
 			}
@@ -188,8 +180,8 @@ loop:
 			}
 			if code == 0x1 || code == 0x2 {
 				if !(i == at || i == len(s)-3 || s[i+3] == till || // at the beginning or at the end of the string
-					(!embedded && i > 2 && s[i-3:i] == codeStr || // not embedded and preceded by the same symbol
-						(embedded && i+6 < len(s) && s[i+3:i+6] == codeStr))) { // embedded and followed by the same symbol
+					(code == 0x1 && !embedded && i-3 >= at && s[i-3:i] == codeStr || // not embedded and preceded by the same symbol
+						(code == 0x1 && embedded && i+6 <= len(s) && s[i+3:i+6] == codeStr))) { // embedded and followed by the same symbol
 					return "", i, fmt.Errorf("unbind URI attribute: %%%02d is embedded into string %q", code, s)
 				}
 				switch code {
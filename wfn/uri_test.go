@@ -94,6 +94,8 @@ func TestBindToURI(t *testing.T) {
 		"cpe:/a:microsoft:internet_explorer:8.%02:sp%01",
 		"cpe:/a:microsoft:internet_explorer:8.%02:sp%01:limited",
 		"cpe:/a:hp:insight_diagnostics:7.4.0.1570::~~online~win2003~x64~",
+		"cpe:/a:foo%7ebar:big%3amoney:2010:-:~-~-~-~-~-:-",
+		"cpe:/a:%01%01vendor:%02prod:1%01%01",
 	}
 	for n, c := range cases {
 		c := c
@@ -108,3 +110,57 @@ func TestBindToURI(t *testing.T) {
 		})
 	}
 }
+
+func TestBindingRoundTrip(t *testing.T) {
+	cases := []struct {
+		URI string
+		FSB string
+	}{
+		{
+			URI: "cpe:/a:microsoft:internet_explorer:8.0.6001:beta",
+			FSB: "cpe:2.3:a:microsoft:internet_explorer:8.0.6001:beta:*:*:*:*:*:*",
+		},
+		{
+			URI: "cpe:/a:hp:insight_diagnostics:7.4.0.1570:-:~~online~win2003~x64~",
+			FSB: "cpe:2.3:a:hp:insight_diagnostics:7.4.0.1570:-:*:*:online:win2003:x64:*",
+		},
+		{
+			URI: "cpe:/a:foo%5cbar:big%24money:2010::~~special~ipod_touch~80gb~",
+			FSB: `cpe:2.3:a:foo\\bar:big\$money:2010:*:*:*:special:ipod_touch:80gb:*`,
+		},
+		{
+			URI: "cpe:/a:foo%7ebar:big%3amoney:2010:-:~-~-~-~-~-:-",
+			FSB: `cpe:2.3:a:foo\~bar:big\:money:2010:-:-:-:-:-:-:-`,
+		},
+		{
+			URI: "cpe:/a:microsoft:internet_explorer:8.%02:sp%01",
+			FSB: "cpe:2.3:a:microsoft:internet_explorer:8.*:sp?:*:*:*:*:*:*",
+		},
+		{
+			URI: "cpe:/a:vendor:product:%2a1.0%3f:::en-us",
+			FSB: `cpe:2.3:a:vendor:product:\*1.0\?:*:*:en-us:*:*:*:*`,
+		},
+		{
+			URI: "cpe:/a:%01%01vendor:%02prod:1%01%01",
+			FSB: "cpe:2.3:a:??vendor:*prod:1??:*:*:*:*:*:*:*",
+		},
+	}
+	for n, c := range cases {
+		t.Run(fmt.Sprintf("case#%d", n), func(t *testing.T) {
+			fsb, err := URIToFmtString(c.URI)
+			if err != nil {
+				t.Fatalf("failed to convert URI %q: %v", c.URI, err)
+			}
+			if fsb != c.FSB {
+				t.Fatalf("expected %s\ngot %s", c.FSB, fsb)
+			}
+			uri, err := FmtStringToURI(fsb)
+			if err != nil {
+				t.Fatalf("failed to convert formatted string %q: %v", fsb, err)
+			}
+			if uri != c.URI {
+				t.Fatalf("expected %s\ngot %s", c.URI, uri)
+			}
+		})
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// VersionComparer compares two versions; it returns -1, 0 or 1 if v1 is older, same or newer than v2
+type VersionComparer func(v1, v2 string) int
+
+var (
+	versionSchemesMu sync.RWMutex
+	versionSchemes   = map[string]VersionComparer{}
+	// partSchemes are schemes of ranges without one, by part of the attributes they're attached to
+	partSchemes = map[string]string{}
+)
+
+// RegisterVersionScheme makes a version comparison scheme available to VersionRange under the given name.
+// Packages which know how to compare versions of some ecosystem register their schemes in init(),
+// e.g. importing rpm package makes "rpm" scheme available.
+func RegisterVersionScheme(name string, cmp VersionComparer) {
+	versionSchemesMu.Lock()
+	defer versionSchemesMu.Unlock()
+	versionSchemes[name] = cmp
+}
+
+// SetPartVersionScheme sets the scheme used by ranges without one, which are attached to attributes of the given part
+// by VersionRange.Matcher, e.g. SetPartVersionScheme("a", "semver") compares versions of applications as semantic versions
+func SetPartVersionScheme(part, scheme string) {
+	versionSchemesMu.Lock()
+	defer versionSchemesMu.Unlock()
+	partSchemes[part] = scheme
+}
+
+func partVersionScheme(part string) string {
+	versionSchemesMu.RLock()
+	defer versionSchemesMu.RUnlock()
+	return partSchemes[part]
+}
+
+// versionComparer returns the comparer registered for the scheme, SmartVerCmp is used for unknown schemes
+func versionComparer(scheme string) VersionComparer {
+	versionSchemesMu.RLock()
+	defer versionSchemesMu.RUnlock()
+	if cmp, ok := versionSchemes[scheme]; ok {
+		return cmp
+	}
+	return SmartVerCmp
+}
+
+// VersionRange is a range of versions, as in NVD CPE matches, empty bounds are unlimited.
+// Versions are plain strings, not WFN attribute-values (i.e. they're not escaped).
+type VersionRange struct {
+	StartIncluding string
+	StartExcluding string
+	EndIncluding   string
+	EndExcluding   string
+	// Scheme is the name of the registered version scheme used to compare versions in the range;
+	// versions of empty or unknown scheme are compared by SmartVerCmp
+	Scheme string
+}
+
+// ParseVersionRange parses a range consisting of comparators separated by spaces or commas,
+// e.g. ">=1.2.0 <1.3.5" or ">= 1.2, < 1.5"; a version without an operator, or with "=", means that exact version.
+// "*" or an empty string is a range without bounds.
+func ParseVersionRange(s, scheme string) (VersionRange, error) {
+	r := VersionRange{Scheme: scheme}
+	fields := strings.FieldsFunc(s, func(c rune) bool { return c == ' ' || c == ',' || c == '\t' })
+	for i := 0; i < len(fields); i++ {
+		op, ver := splitOperator(fields[i])
+		if ver == "" && op != "" && i+1 < len(fields) {
+			// operator separated from the version by space
+			i++
+			ver = fields[i]
+		}
+		if ver == "*" && op == "" {
+			continue
+		}
+		if ver == "" || strings.ContainsAny(ver, "<>=") {
+			return VersionRange{}, fmt.Errorf("wfn: malformed version range %q", s)
+		}
+		switch op {
+		case ">=":
+			r.StartIncluding = ver
+		case ">":
+			r.StartExcluding = ver
+		case "<=":
+			r.EndIncluding = ver
+		case "<":
+			r.EndExcluding = ver
+		case "=", "==", "":
+			r.StartIncluding, r.EndIncluding = ver, ver
+		default:
+			return VersionRange{}, fmt.Errorf("wfn: unknown operator %q in version range %q", op, s)
+		}
+	}
+	return r, nil
+}
+
+func splitOperator(s string) (op, ver string) {
+	i := strings.IndexFunc(s, func(c rune) bool { return !strings.ContainsRune("<>=", c) })
+	if i < 0 {
+		return s, ""
+	}
+	return s[:i], s[i:]
+}
+
+// HasBounds returns true if the range limits versions in any way
+func (r VersionRange) HasBounds() bool {
+	return r.StartIncluding != "" || r.StartExcluding != "" || r.EndIncluding != "" || r.EndExcluding != ""
+}
+
+// Contains returns true if the version is within the range
+func (r VersionRange) Contains(version string) bool {
+	cmp := versionComparer(r.Scheme)
+	if r.StartIncluding != "" && cmp(version, r.StartIncluding) < 0 {
+		return false
+	}
+	if r.StartExcluding != "" && cmp(version, r.StartExcluding) <= 0 {
+		return false
+	}
+	if r.EndIncluding != "" && cmp(version, r.EndIncluding) > 0 {
+		return false
+	}
+	if r.EndExcluding != "" && cmp(version, r.EndExcluding) >= 0 {
+		return false
+	}
+	return true
+}
+
+// String returns the range in the format accepted by ParseVersionRange
+func (r VersionRange) String() string {
+	if r.StartIncluding != "" && r.StartIncluding == r.EndIncluding && r.StartExcluding == "" && r.EndExcluding == "" {
+		return "=" + r.StartIncluding
+	}
+	var parts []string
+	for _, b := range []struct{ op, ver string }{
+		{">=", r.StartIncluding},
+		{">", r.StartExcluding},
+		{"<=", r.EndIncluding},
+		{"<", r.EndExcluding},
+	} {
+		if b.ver != "" {
+			parts = append(parts, b.op+b.ver)
+		}
+	}
+	if len(parts) == 0 {
+		return "*"
+	}
+	return strings.Join(parts, " ")
+}
+
+// Matcher returns a Matcher of attributes which match attrs and whose version is within the range.
+// If the range has no bounds, the version of attrs is matched as usual; otherwise the version of
+// attrs is matched first and the range is checked only if it didn't match.
+func (r VersionRange) Matcher(attrs *Attributes) Matcher {
+	return &rangeMatcher{Compile(attrs), r}
+}
+
+type rangeMatcher struct {
+	*CompiledAttributes
+	versionRange VersionRange
+}
+
+// Match is part of the Matcher interface
+func (rm *rangeMatcher) Match(attrs []*Attributes, requireVersion bool) (matches []*Attributes) {
+	for _, attr := range attrs {
+		if rm.match(attr, requireVersion) {
+			matches = append(matches, attr)
+		}
+	}
+	return matches
+}
+
+func (rm *rangeMatcher) match(attr *Attributes, requireVersion bool) bool {
+	if rm == nil || rm.CompiledAttributes == nil {
+		return false
+	}
+	hasBounds := rm.versionRange.HasBounds()
+
+	// if we require version, then we need either version ranges or version not to be *
+	if requireVersion && !hasBounds && rm.Version == Any {
+		return false
+	}
+
+	// check whether everything except for version matches
+	if !rm.MatchWithoutVersion(attr) {
+		return false
+	}
+
+	if rm.Version == Any {
+		if !hasBounds {
+			// if version is any and there are no bounds, then it matches any
+			return !requireVersion
+		} // otherwise we try to match the range below
+	} else if rm.MatchOnlyVersion(attr) {
+		return true // version matched
+	}
+
+	if !hasBounds {
+		return false
+	}
+	r := rm.versionRange
+	if r.Scheme == "" {
+		part := rm.Part
+		if part == Any {
+			part = attr.Part
+		}
+		r.Scheme = partVersionScheme(part)
+	}
+	return r.Contains(StripSlashes(attr.Version))
+}
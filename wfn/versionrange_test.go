@@ -0,0 +1,144 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wfn
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseVersionRange(t *testing.T) {
+	cases := []struct {
+		in     string
+		expect VersionRange
+		str    string
+		fail   bool
+	}{
+		{in: ">=1.2.0 <1.3.5", expect: VersionRange{StartIncluding: "1.2.0", EndExcluding: "1.3.5"}, str: ">=1.2.0 <1.3.5"},
+		{in: ">= 1.2, < 1.5", expect: VersionRange{StartIncluding: "1.2", EndExcluding: "1.5"}, str: ">=1.2 <1.5"},
+		{in: ">1.0,<=2.0", expect: VersionRange{StartExcluding: "1.0", EndIncluding: "2.0"}, str: ">1.0 <=2.0"},
+		{in: "1.4", expect: VersionRange{StartIncluding: "1.4", EndIncluding: "1.4"}, str: "=1.4"},
+		{in: "= 1.4", expect: VersionRange{StartIncluding: "1.4", EndIncluding: "1.4"}, str: "=1.4"},
+		{in: "*", expect: VersionRange{}, str: "*"},
+		{in: "", expect: VersionRange{}, str: "*"},
+		{in: ">=", fail: true},
+		{in: "=>1.0", fail: true},
+		{in: "<>1.0", fail: true},
+	}
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			r, err := ParseVersionRange(c.in, "")
+			if c.fail {
+				if err == nil {
+					t.Fatalf("expected an error, got %+v", r)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r != c.expect {
+				t.Fatalf("expected %+v, got %+v", c.expect, r)
+			}
+			if s := r.String(); s != c.str {
+				t.Fatalf("expected %q, got %q", c.str, s)
+			}
+		})
+	}
+}
+
+func TestVersionRangeContains(t *testing.T) {
+	r, err := ParseVersionRange(">=1.2.0 <1.10", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for v, expect := range map[string]bool{
+		"1.1.9":  false,
+		"1.2.0":  true,
+		"1.9.99": true,
+		"1.10":   false,
+		"2.0":    false,
+	} {
+		if got := r.Contains(v); got != expect {
+			t.Errorf("%v.Contains(%q) = %t, expected %t", r, v, got, expect)
+		}
+	}
+
+	// lexical comparison is enough to tell the difference from SmartVerCmp
+	RegisterVersionScheme("test-lexical", func(v1, v2 string) int {
+		switch {
+		case v1 < v2:
+			return -1
+		case v1 > v2:
+			return 1
+		}
+		return 0
+	})
+	r.Scheme = "test-lexical"
+	if r.Contains("1.9.99") {
+		t.Errorf("1.9.99 shouldn't be in %v with lexical comparison", r)
+	}
+	r.Scheme = "unknown"
+	if !r.Contains("1.9.99") {
+		t.Errorf("1.9.99 should be in %v when scheme is unknown", r)
+	}
+}
+
+func TestVersionRangeMatcher(t *testing.T) {
+	attrs := &Attributes{Part: "a", Vendor: "openssl", Product: "openssl"}
+	r := VersionRange{StartIncluding: "1.1.0", EndExcluding: "1.1.1"}
+
+	cases := []struct {
+		version        string
+		requireVersion bool
+		expect         bool
+	}{
+		{`1\.1\.0k`, false, true},
+		{`1\.1\.1`, false, false},
+		{`1\.0\.2`, true, false},
+		{`1\.1\.0`, true, true},
+	}
+	m := r.Matcher(attrs)
+	for _, c := range cases {
+		tgt := &Attributes{Part: "a", Vendor: "openssl", Product: "openssl", Version: c.version}
+		if got := len(m.Match([]*Attributes{tgt}, c.requireVersion)) == 1; got != c.expect {
+			t.Errorf("match of version %q (require version %t): expected %t, got %t", c.version, c.requireVersion, c.expect, got)
+		}
+	}
+
+	// no bounds: version of the attributes is used
+	m = VersionRange{}.Matcher(attrs)
+	if len(m.Match([]*Attributes{{Part: "a", Vendor: "openssl", Product: "openssl", Version: "3"}}, true)) != 0 {
+		t.Error("any version shouldn't match when version is required")
+	}
+	if len(m.Match([]*Attributes{{Part: "a", Vendor: "openssl", Product: "openssl", Version: "3"}}, false)) != 1 {
+		t.Error("any version should match")
+	}
+	if len(m.Match([]*Attributes{{Part: "a", Vendor: "openssl", Product: "libressl"}}, false)) != 0 {
+		t.Error("different product shouldn't match")
+	}
+
+	// scheme of the part is used for ranges without one; 1.1.09 is above 1.1.1 for SmartVerCmp
+	if len(r.Matcher(attrs).Match([]*Attributes{{Part: "a", Vendor: "openssl", Product: "openssl", Version: `1\.1\.09`}}, false)) != 0 {
+		t.Error("1.1.09 shouldn't match by default")
+	}
+	RegisterVersionScheme("test-lexical", func(v1, v2 string) int { return strings.Compare(v1, v2) })
+	SetPartVersionScheme("a", "test-lexical")
+	defer SetPartVersionScheme("a", "")
+	tgt := &Attributes{Part: "a", Vendor: "openssl", Product: "openssl", Version: `1\.1\.09`}
+	if len(r.Matcher(attrs).Match([]*Attributes{tgt}, false)) != 1 {
+		t.Error("1.1.09 should match with lexical comparison")
+	}
+}
@@ -53,6 +53,26 @@ func Parse(s string) (*Attributes, error) {
 	return nil, fmt.Errorf("wfn: unsupported format %q", s)
 }
 
+// URIToFmtString converts CPE 2.2 URI binding to CPE 2.3 formatted string binding.
+func URIToFmtString(uri string) (string, error) {
+	attr, err := UnbindURI(uri)
+	if err != nil {
+		return "", err
+	}
+	return attr.BindToFmtString(), nil
+}
+
+// FmtStringToURI converts CPE 2.3 formatted string binding to CPE 2.2 URI binding.
+// Extended attributes (sw_edition, target_sw, target_hw and other) are packed into edition,
+// so the conversion is lossless.
+func FmtStringToURI(fsb string) (string, error) {
+	attr, err := UnbindFmtString(fsb)
+	if err != nil {
+		return "", err
+	}
+	return attr.BindToURI(), nil
+}
+
 // Attributes defines the WFN Data Model Attributes.
 type Attributes struct {
 	Part      string
@@ -129,6 +149,58 @@ func WFNize(s string) (string, error) {
 	return s, err
 }
 
+// Canonicalize returns a copy of the wfn with all attribute values converted to lower case
+// and escaped the same way, so that wfns created by different providers for the same CPE compare equal.
+// Punctuation characters are quoted, while unnecessarily quoted letters, digits and underscores aren't.
+// Unquoted wildcards are left as is.
+func (a Attributes) Canonicalize() *Attributes {
+	return &Attributes{
+		Part:      canonicalValue(a.Part),
+		Vendor:    canonicalValue(a.Vendor),
+		Product:   canonicalValue(a.Product),
+		Version:   canonicalValue(a.Version),
+		Update:    canonicalValue(a.Update),
+		Edition:   canonicalValue(a.Edition),
+		SWEdition: canonicalValue(a.SWEdition),
+		TargetSW:  canonicalValue(a.TargetSW),
+		TargetHW:  canonicalValue(a.TargetHW),
+		Other:     canonicalValue(a.Other),
+		Language:  canonicalValue(a.Language),
+	}
+}
+
+func canonicalValue(s string) string {
+	if s == Any || s == NA {
+		return s
+	}
+	s = strings.ToLower(s)
+	buf := make([]byte, 0, len(s)*2)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\\' {
+			if i++; i == len(s) {
+				break // drop dangling escape character
+			}
+			c = s[i]
+			if isAlnum(c) || c == '_' {
+				buf = append(buf, c)
+			} else {
+				buf = append(buf, '\\', c)
+			}
+			continue
+		}
+		switch {
+		case isAlnum(c) || c == '_' || c == '*' || c == '?':
+			buf = append(buf, c)
+		case c == ' ':
+			buf = append(buf, '_')
+		default:
+			buf = append(buf, '\\', c)
+		}
+	}
+	return string(buf)
+}
+
 // String returns a string representation of the wfn
 func (a Attributes) String() string {
 	parts := make([]string, 0, 11)
@@ -14,7 +14,10 @@
 
 package wfn
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+)
 
 func TestWFNize(t *testing.T) {
 	cases := []struct {
@@ -48,3 +51,34 @@ func BenchmarkWFNize(t *testing.B) {
 		WFNize("1.8.14.6001")
 	}
 }
+
+func TestCanonicalize(t *testing.T) {
+	cases := []struct {
+		in, expected string
+	}{
+		{"cpe:/a:Microsoft:Internet_Explorer:8.0.6001", "cpe:/a:microsoft:internet_explorer:8.0.6001"},
+		{"cpe:2.3:a:FOO\\\\Bar:big\\$money:2010:*:*:*:*:*:*:*", "cpe:/a:foo%5cbar:big%24money:2010"},
+		{"cpe:2.3:o:linux:linux_kernel:4.*:-:*:*:*:*:*:*", "cpe:/o:linux:linux_kernel:4.%02:-"},
+	}
+	for i, c := range cases {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			attrs, err := Parse(c.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if uri := attrs.Canonicalize().BindToURI(); uri != c.expected {
+				t.Fatalf("canonical form of %q is %q, %q was expected", c.in, uri, c.expected)
+			}
+		})
+	}
+
+	// names created by hand and parsed from bindings compare equal
+	attrs := &Attributes{Part: "a", Vendor: "Foo", Product: "bar\\_baz", Version: "1.0"}
+	parsed, err := Parse("cpe:/a:foo:bar_baz:1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, b := *attrs.Canonicalize(), *parsed.Canonicalize(); a != b {
+		t.Fatalf("expecting %v and %v to be equal", a, b)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windows
+
+// builtinVendors are NVD vendors of common publishers whose names differ from them
+var builtinVendors = map[string]string{
+	"Adobe Systems Incorporated":      "adobe",
+	"Apache Software Foundation":      "apache",
+	"Cisco Systems, Inc.":             "cisco",
+	"Citrix Systems, Inc.":            "citrix",
+	"Dominik Reichl":                  "keepass",
+	"Hewlett-Packard Company":         "hp",
+	"Igor Pavlov":                     "7-zip",
+	"Martin Prikryl":                  "winscp",
+	"Node.js Foundation":              "nodejs",
+	"Notepad++ Team":                  "notepad-plus-plus",
+	"Python Software Foundation":      "python",
+	"Simon Tatham":                    "putty",
+	"Slack Technologies Inc.":         "slack",
+	"TeamViewer Germany GmbH":         "teamviewer",
+	"The Document Foundation":         "libreoffice",
+	"The Git Development Community":   "git-scm",
+	"Tim Kosse":                       "filezilla-project",
+	"win.rar GmbH":                    "rarlab",
+	"Zoom Video Communications, Inc.": "zoom",
+}
+
+// builtinProducts are CPEs of common products whose NVD vendor and product can't be derived from their display names;
+// editions of Microsoft Office are named as in MSRC security updates
+var builtinProducts = map[string]string{
+	"7-Zip":                 "cpe:/a:7-zip:7-zip",
+	"FileZilla":             "cpe:/a:filezilla-project:filezilla_client",
+	"FileZilla Server":      "cpe:/a:filezilla-project:filezilla_server",
+	"KeePass Password Safe": "cpe:/a:keepass:keepass",
+	"LibreOffice":           "cpe:/a:libreoffice:libreoffice",
+	"Microsoft Edge":        "cpe:/a:microsoft:edge_chromium",
+	"Node.js":               "cpe:/a:nodejs:node.js",
+	"Notepad++":             "cpe:/a:notepad-plus-plus:notepad%2b%2b",
+	"PuTTY":                 "cpe:/a:putty:putty",
+	"VLC media player":      "cpe:/a:videolan:vlc_media_player",
+	"WinRAR":                "cpe:/a:rarlab:winrar",
+	"WinSCP":                "cpe:/a:winscp:winscp",
+	"Wireshark":             "cpe:/a:wireshark:wireshark",
+
+	"Microsoft Office Professional Plus 2013":      "cpe:/a:microsoft:office_2013",
+	"Microsoft Office Standard 2013":               "cpe:/a:microsoft:office_2013",
+	"Microsoft Office Professional Plus 2016":      "cpe:/a:microsoft:office_2016",
+	"Microsoft Office Standard 2016":               "cpe:/a:microsoft:office_2016",
+	"Microsoft Office Professional Plus 2019":      "cpe:/a:microsoft:office_2019",
+	"Microsoft Office Standard 2019":               "cpe:/a:microsoft:office_2019",
+	"Microsoft Office LTSC Professional Plus 2021": "cpe:/a:microsoft:office_ltsc_2021",
+	"Microsoft Office LTSC Standard 2021":          "cpe:/a:microsoft:office_ltsc_2021",
+	"Microsoft Office LTSC Professional Plus 2024": "cpe:/a:microsoft:office_ltsc_2024",
+	"Microsoft Office LTSC Standard 2024":          "cpe:/a:microsoft:office_ltsc_2024",
+}
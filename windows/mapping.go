@@ -0,0 +1,299 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windows
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/facebookincubator/nvdtools/wfn"
+)
+
+// Mapping maps installed software to CPEs, using known vendors of publishers and known CPEs of products
+// whose NVD names can't be derived from their display names, and deriving CPEs of all other products
+type Mapping struct {
+	mu       sync.RWMutex
+	vendors  map[string]string
+	products map[string]*wfn.Attributes
+}
+
+// NewMapping creates a mapping without any known translations
+func NewMapping() *Mapping {
+	return &Mapping{
+		vendors:  make(map[string]string),
+		products: make(map[string]*wfn.Attributes),
+	}
+}
+
+// DefaultMapping creates a mapping with the built-in translations of common publishers and products,
+// more can be added to it
+func DefaultMapping() *Mapping {
+	m := NewMapping()
+	for publisher, vendor := range builtinVendors {
+		if err := m.AddVendor(publisher, vendor); err != nil {
+			panic(fmt.Sprintf("invalid built-in vendor %s: %v", publisher, err))
+		}
+	}
+	for name, cpe := range builtinProducts {
+		if err := m.AddProduct(name, cpe); err != nil {
+			panic(fmt.Sprintf("invalid built-in product %s: %v", name, err))
+		}
+	}
+	return m
+}
+
+// AddVendor adds a translation of the publisher to the NVD vendor, e.g. Igor Pavlov to 7-zip;
+// publishers are compared without case and legal suffixes, such as Inc. or Corporation
+func (m *Mapping) AddVendor(publisher, vendor string) error {
+	key := strings.Join(publisherWords(publisher), " ")
+	if key == "" {
+		return fmt.Errorf("empty publisher")
+	}
+	v, err := wfn.WFNize(strings.ToLower(vendor))
+	if err != nil {
+		return fmt.Errorf("can't wfnize vendor %q: %v", vendor, err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.vendors[key] = v
+	return nil
+}
+
+// AddProduct adds a translation of products whose display names start with name to the CPE,
+// e.g. VLC media player to cpe:/a:videolan:vlc_media_player; version of the CPE is ignored.
+// Names are compared by words without case, versions, architectures and languages, the longest known name wins.
+func (m *Mapping) AddProduct(name, cpe string) error {
+	key := strings.Join(nameWords(name, ""), " ")
+	if key == "" {
+		return fmt.Errorf("empty product name")
+	}
+	attrs, err := wfn.Parse(cpe)
+	if err != nil {
+		return fmt.Errorf("can't parse cpe %q: %v", cpe, err)
+	}
+	attrs.Version = wfn.Any
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.products[key] = attrs
+	return nil
+}
+
+// Load adds translations from r, every line is either "vendor", the NVD vendor and the publisher
+// or "product", the CPE and the display name, separated by spaces, e.g.
+//
+//	vendor 7-zip Igor Pavlov
+//	product cpe:/a:videolan:vlc_media_player VLC media player
+//
+// empty lines and lines starting with # are skipped
+func (m *Mapping) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			return fmt.Errorf("line %d: expecting a kind, a vendor or cpe and a name, got %q", n, line)
+		}
+		name := strings.Join(fields[2:], " ")
+		var err error
+		switch fields[0] {
+		case "vendor":
+			err = m.AddVendor(name, fields[1])
+		case "product":
+			err = m.AddProduct(name, fields[1])
+		default:
+			err = fmt.Errorf("unknown kind %q, expecting vendor or product", fields[0])
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %v", n, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// LoadFile adds translations from the file, see Load for its format
+func (m *Mapping) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := m.Load(f); err != nil {
+		return fmt.Errorf("can't load %s: %v", path, err)
+	}
+	return nil
+}
+
+// ToCPE returns CPE attributes of the installed software
+// vendor and product of known products are used as they are, otherwise the vendor is the known vendor
+// of the publisher, or the publisher without legal suffixes, and the product is the display name without
+// version, architecture, language and the vendor, e.g. Mozilla Firefox 118.0.1 (x64 en-US) by Mozilla
+// is cpe:/a:mozilla:firefox:118.0.1::~~~~x64~; the architecture becomes the target hardware
+func (m *Mapping) ToCPE(s *Software) (*wfn.Attributes, error) {
+	words := nameWords(s.Name, s.Version)
+	if len(words) == 0 {
+		return nil, fmt.Errorf("no product name found in %q", s.Name)
+	}
+	attrs, err := m.lookup(words, publisherWords(s.Publisher))
+	if err != nil {
+		return nil, err
+	}
+	if attrs.Version, err = wfn.WFNize(s.Version); err != nil {
+		return nil, fmt.Errorf("can't wfnize version %q: %v", s.Version, err)
+	}
+	arch := archOf(s.Name)
+	if arch == "" {
+		arch = s.Arch
+	}
+	if attrs.TargetHW, err = wfn.WFNize(arch); err != nil {
+		return nil, fmt.Errorf("can't wfnize architecture %q: %v", arch, err)
+	}
+	return attrs, nil
+}
+
+// lookup returns a copy of the attributes of the longest known product name the name starts with,
+// or the ones derived from the name and publisher
+func (m *Mapping) lookup(words, publisher []string) (*wfn.Attributes, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for i := len(words); i > 0; i-- {
+		if attrs, ok := m.products[strings.Join(words[:i], " ")]; ok {
+			a := *attrs
+			return &a, nil
+		}
+	}
+
+	attrs := wfn.Attributes{Part: "a"}
+	vendor, ok := m.vendors[strings.Join(publisher, " ")]
+	if !ok {
+		var err error
+		if vendor, err = wfn.WFNize(strings.Join(publisher, "_")); err != nil {
+			return nil, fmt.Errorf("can't wfnize publisher %q: %v", strings.Join(publisher, " "), err)
+		}
+	}
+	attrs.Vendor = vendor
+	// display names usually start with the publisher, e.g. Mozilla Firefox
+	if len(words) > 1 && len(publisher) != 0 && (words[0] == publisher[0] || words[0] == vendor) {
+		words = words[1:]
+	}
+	var err error
+	if attrs.Product, err = wfn.WFNize(strings.Join(words, "_")); err != nil {
+		return nil, fmt.Errorf("can't wfnize product name %q: %v", strings.Join(words, " "), err)
+	}
+	return &attrs, nil
+}
+
+var (
+	trademarkRegex = regexp.MustCompile(`(?i)\((r|tm|c)\)|[®™©]`)
+	bracketsRegex  = regexp.MustCompile(`\([^)]*\)|\[[^\]]*\]`)
+	languageRegex  = regexp.MustCompile(`^[a-z]{2}-[a-z]{2,4}$`)
+	versionRegex   = regexp.MustCompile(`^v?\d+(\.\d+)+\S*$`)
+	archRegex      = regexp.MustCompile(`(?i)(^|[^a-z0-9])(x64|x86_64|amd64|x86|i386|arm64|aarch64|64-bit|32-bit)($|[^a-z0-9])`)
+)
+
+// architectures in display names mapped to target hardware
+var architectures = map[string]string{
+	"x64":     "x64",
+	"x86_64":  "x64",
+	"amd64":   "x64",
+	"64-bit":  "x64",
+	"x86":     "x86",
+	"i386":    "x86",
+	"32-bit":  "x86",
+	"arm64":   "arm64",
+	"aarch64": "arm64",
+}
+
+// words which end display names without being part of product names
+var trailingWords = map[string]bool{
+	"-":       true,
+	"release": true,
+	"version": true,
+}
+
+// archOf returns the target hardware of the architecture found in the display name, if any
+func archOf(name string) string {
+	if m := archRegex.FindStringSubmatch(name); m != nil {
+		return architectures[strings.ToLower(m[2])]
+	}
+	return ""
+}
+
+// nameWords returns lowercase words of the display name without trademark signs, parts in brackets
+// and trailing versions, architectures, languages and separators, e.g. mozilla firefox in Mozilla Firefox 118.0.1 (x64 en-US)
+func nameWords(name, version string) []string {
+	name = strings.ToLower(name)
+	name = trademarkRegex.ReplaceAllString(name, "")
+	name = bracketsRegex.ReplaceAllString(name, " ")
+	words := strings.Fields(name)
+	version = strings.ToLower(version)
+	for len(words) > 1 {
+		w := strings.TrimRight(words[len(words)-1], ",")
+		if w != "" && !trailingWords[w] && w != version && !versionRegex.MatchString(w) && !languageRegex.MatchString(w) && architectures[w] == "" {
+			break
+		}
+		words = words[:len(words)-1]
+	}
+	return words
+}
+
+// legal suffixes of publisher names
+var legalSuffixes = map[string]bool{
+	"ab":           true,
+	"ag":           true,
+	"b.v":          true,
+	"bv":           true,
+	"co":           true,
+	"company":      true,
+	"corp":         true,
+	"corporation":  true,
+	"gmbh":         true,
+	"inc":          true,
+	"incorporated": true,
+	"limited":      true,
+	"llc":          true,
+	"ltd":          true,
+	"oy":           true,
+	"plc":          true,
+	"pty":          true,
+	"s.a":          true,
+	"s.r.l":        true,
+	"s.r.o":        true,
+	"sa":           true,
+	"srl":          true,
+}
+
+// publisherWords returns lowercase words of the publisher without trademark signs and legal suffixes,
+// e.g. adobe systems in Adobe Systems Incorporated
+func publisherWords(publisher string) []string {
+	publisher = strings.ToLower(publisher)
+	publisher = trademarkRegex.ReplaceAllString(publisher, "")
+	publisher = strings.Replace(publisher, ",", " ", -1)
+	words := strings.Fields(publisher)
+	if len(words) > 1 && words[0] == "the" {
+		words = words[1:]
+	}
+	for len(words) > 1 && legalSuffixes[strings.TrimRight(words[len(words)-1], ".")] {
+		words = words[:len(words)-1]
+	}
+	return words
+}
@@ -0,0 +1,86 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windows
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestToCPE(t *testing.T) {
+	m := DefaultMapping()
+	cases := []struct {
+		sw  Software
+		cpe string
+	}{
+		{Software{Name: "Mozilla Firefox 118.0.1 (x64 en-US)", Version: "118.0.1", Publisher: "Mozilla"}, "cpe:/a:mozilla:firefox:118.0.1::~~~~x64~"},
+		{Software{Name: "Google Chrome", Version: "117.0.5938.132", Publisher: "Google LLC"}, "cpe:/a:google:chrome:117.0.5938.132"},
+		{Software{Name: "7-Zip 23.01 (x64)", Version: "23.01", Publisher: "Igor Pavlov"}, "cpe:/a:7-zip:7-zip:23.01::~~~~x64~"},
+		{Software{Name: "Notepad++ (32-bit x86)", Version: "8.5.7", Publisher: "Notepad++ Team"}, "cpe:/a:notepad-plus-plus:notepad%2b%2b:8.5.7::~~~~x86~"},
+		{Software{Name: "Python 3.11.4 (64-bit)", Version: "3.11.4150.0", Publisher: "Python Software Foundation"}, "cpe:/a:python:python:3.11.4150.0::~~~~x64~"},
+		{Software{Name: "PuTTY release 0.79 (64-bit)", Version: "0.79.0.0", Publisher: "Simon Tatham"}, "cpe:/a:putty:putty:0.79.0.0::~~~~x64~"},
+		{Software{Name: "Oracle VM VirtualBox 7.0.10", Version: "7.0.10", Publisher: "Oracle Corporation"}, "cpe:/a:oracle:vm_virtualbox:7.0.10"},
+		{Software{Name: "Cisco AnyConnect Secure Mobility Client ", Version: "4.10.07061", Publisher: "Cisco Systems, Inc."}, "cpe:/a:cisco:anyconnect_secure_mobility_client:4.10.07061"},
+		{Software{Name: "Adobe Acrobat Reader DC", Version: "23.006.20320", Publisher: "Adobe Systems Incorporated"}, "cpe:/a:adobe:acrobat_reader_dc:23.006.20320"},
+		{Software{Name: "Microsoft Office Professional Plus 2019 - en-us", Version: "16.0.10827.20138", Publisher: "Microsoft Corporation", Arch: "x86"}, "cpe:/a:microsoft:office_2019:16.0.10827.20138::~~~~x86~"},
+		{Software{Name: "Microsoft 365 Apps for enterprise - en-us", Version: "16.0.16731.20234", Publisher: "Microsoft Corporation"}, "cpe:/a:microsoft:365_apps_for_enterprise:16.0.16731.20234"},
+		{Software{Name: "Intel(R) Management Engine Components", Version: "2319.4.4.0", Publisher: "Intel Corporation"}, "cpe:/a:intel:management_engine_components:2319.4.4.0"},
+		{Software{Name: "Git", Version: "2.42.0"}, "cpe:/a::git:2.42.0"},
+	}
+	for _, c := range cases {
+		t.Run(c.sw.Name, func(t *testing.T) {
+			attrs, err := m.ToCPE(&c.sw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cpe := attrs.BindToURI(); cpe != c.cpe {
+				t.Fatalf("got %s, expected %s", cpe, c.cpe)
+			}
+		})
+	}
+}
+
+func TestMappingLoad(t *testing.T) {
+	m := DefaultMapping()
+	mapping := `
+# translations of in-house software
+vendor acme Acme Widgets, Inc.
+product cpe:/a:acme:rocket_skates Road Runner Catcher
+`
+	if err := m.Load(strings.NewReader(mapping)); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		sw  Software
+		cpe string
+	}{
+		{Software{Name: "Acme Anvil 2.0", Version: "2.0", Publisher: "Acme Widgets Inc"}, "cpe:/a:acme:anvil:2.0"},
+		{Software{Name: "Road Runner Catcher Pro 1.2", Version: "1.2", Publisher: "Wile E. Coyote"}, "cpe:/a:acme:rocket_skates:1.2"},
+	} {
+		attrs, err := m.ToCPE(&c.sw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if cpe := attrs.BindToURI(); cpe != c.cpe {
+			t.Fatalf("got %s, expected %s", cpe, c.cpe)
+		}
+	}
+
+	for _, bad := range []string{"vendor acme", "publisher acme Acme", "product acme Acme"} {
+		if err := NewMapping().Load(strings.NewReader(bad)); err == nil {
+			t.Fatalf("expecting an error loading %q", bad)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package windows reads inventories of software installed on Windows hosts and maps them to CPE names.
+package windows
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"unicode/utf16"
+)
+
+// Software is a product installed on a Windows host
+type Software struct {
+	Name      string
+	Version   string
+	Publisher string
+	// Arch is the architecture the product is built for, x86 for 32-bit products installed on 64-bit Windows
+	// (registered under WOW6432Node), empty if it's not known
+	Arch string
+}
+
+// columns of the exports, by lowercase name: registry Uninstall keys use Display* names,
+// Get-Package uses Name and Version and wmic product uses Name, Vendor and Version
+var (
+	nameColumns      = []string{"displayname", "name"}
+	versionColumns   = []string{"displayversion", "version"}
+	publisherColumns = []string{"publisher", "vendor"}
+	pathColumns      = []string{"pspath", "psparentpath"}
+)
+
+// ParseCSV reads installed software from a CSV export, e.g. of registry Uninstall keys
+//
+//	Get-ItemProperty HKLM:\Software\Microsoft\Windows\CurrentVersion\Uninstall\*,
+//		HKLM:\Software\WOW6432Node\Microsoft\Windows\CurrentVersion\Uninstall\* |
+//		Select-Object DisplayName, DisplayVersion, Publisher, PSPath | Export-Csv software.csv
+//
+// or of Get-Package or wmic product get Name,Vendor,Version /format:csv.
+// Columns are found by their names in the header, the name and version ones are required.
+// UTF-16 exports (e.g. by wmic) are decoded and the #TYPE line written by Export-Csv is skipped;
+// records without name, updates of other products (with ParentKeyName) and system components are skipped
+// as well as duplicates, which are common when both registry views are exported.
+func ParseCSV(r io.Reader) ([]*Software, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	cr := csv.NewReader(bytes.NewReader(decode(data)))
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	var header map[string]int
+	var sw []*Software
+	seen := make(map[Software]bool)
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if blank(rec) || (header == nil && strings.HasPrefix(rec[0], "#TYPE")) {
+			continue
+		}
+		if header == nil {
+			header = make(map[string]int, len(rec))
+			for i, name := range rec {
+				header[strings.ToLower(strings.TrimSpace(name))] = i
+			}
+			_, hasName := find(header, nameColumns)
+			_, hasVersion := find(header, versionColumns)
+			if !hasName || !hasVersion {
+				return nil, fmt.Errorf("can't find name and version columns in header %q", strings.Join(rec, ","))
+			}
+			continue
+		}
+
+		get := func(names []string) string {
+			if i, ok := find(header, names); ok && i < len(rec) {
+				return strings.TrimSpace(rec[i])
+			}
+			return ""
+		}
+		if get([]string{"systemcomponent"}) == "1" || get([]string{"parentkeyname"}) != "" {
+			continue
+		}
+		s := Software{
+			Name:      get(nameColumns),
+			Version:   get(versionColumns),
+			Publisher: get(publisherColumns),
+		}
+		if s.Name == "" {
+			continue
+		}
+		if strings.Contains(strings.ToLower(get(pathColumns)), `\wow6432node\`) {
+			s.Arch = "x86"
+		}
+		if seen[s] {
+			continue
+		}
+		seen[s] = true
+		sw = append(sw, &s)
+	}
+	if header == nil {
+		return nil, fmt.Errorf("no header found")
+	}
+	return sw, nil
+}
+
+// find returns the index of the first of the columns found in the header
+func find(header map[string]int, names []string) (int, bool) {
+	for _, name := range names {
+		if i, ok := header[name]; ok {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func blank(rec []string) bool {
+	for _, f := range rec {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// decode returns the data as UTF-8, decoding UTF-16 with byte order mark and dropping the UTF-8 one
+func decode(data []byte) []byte {
+	var bigEndian bool
+	switch {
+	case bytes.HasPrefix(data, []byte{0xef, 0xbb, 0xbf}):
+		return data[3:]
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+	case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		bigEndian = true
+	default:
+		return data
+	}
+	data = data[2:]
+	u := make([]uint16, len(data)/2)
+	for i := range u {
+		if bigEndian {
+			u[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+		} else {
+			u[i] = uint16(data[2*i+1])<<8 | uint16(data[2*i])
+		}
+	}
+	return []byte(string(utf16.Decode(u)))
+}
@@ -0,0 +1,111 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package windows
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func TestParseCSV(t *testing.T) {
+	cases := []struct {
+		name  string
+		in    string
+		sw    []Software
+		isErr bool
+	}{
+		{
+			name: "registry",
+			in: "#TYPE Selected.System.Management.Automation.PSCustomObject\n" +
+				`"DisplayName","DisplayVersion","Publisher","PSPath"` + "\n" +
+				`"7-Zip 23.01 (x64)","23.01","Igor Pavlov","Microsoft.PowerShell.Core\Registry::HKEY_LOCAL_MACHINE\Software\Microsoft\Windows\CurrentVersion\Uninstall\7-Zip"` + "\n" +
+				`"Notepad++ (32-bit x86)","8.5.7","Notepad++ Team","Microsoft.PowerShell.Core\Registry::HKEY_LOCAL_MACHINE\Software\WOW6432Node\Microsoft\Windows\CurrentVersion\Uninstall\Notepad++"` + "\n" +
+				`"7-Zip 23.01 (x64)","23.01","Igor Pavlov","Microsoft.PowerShell.Core\Registry::HKEY_LOCAL_MACHINE\Software\Microsoft\Windows\CurrentVersion\Uninstall\7-Zip"` + "\n" +
+				`"","","",""` + "\n",
+			sw: []Software{
+				{Name: "7-Zip 23.01 (x64)", Version: "23.01", Publisher: "Igor Pavlov"},
+				{Name: "Notepad++ (32-bit x86)", Version: "8.5.7", Publisher: "Notepad++ Team", Arch: "x86"},
+			},
+		},
+		{
+			name: "updates and system components",
+			in: "DisplayName,DisplayVersion,Publisher,ParentKeyName,SystemComponent\n" +
+				"Microsoft Office Professional Plus 2019 - en-us,16.0.10827.20138,Microsoft Corporation,,\n" +
+				"Security Update for Microsoft Office,16.0.1,Microsoft Corporation,Office16.PROPLUS,\n" +
+				"Microsoft Office 64-bit Components 2019,16.0.10827.20138,Microsoft Corporation,,1\n",
+			sw: []Software{
+				{Name: "Microsoft Office Professional Plus 2019 - en-us", Version: "16.0.10827.20138", Publisher: "Microsoft Corporation"},
+			},
+		},
+		{
+			name: "wmic",
+			in:   "\r\r\nNode,Name,Vendor,Version\r\r\nHOST,VLC media player,VideoLAN,3.0.18\r\r\n",
+			sw: []Software{
+				{Name: "VLC media player", Version: "3.0.18", Publisher: "VideoLAN"},
+			},
+		},
+		{
+			name: "get-package",
+			in:   `"Name","Version","ProviderName"` + "\n" + `"Git","2.42.0","Programs"` + "\n",
+			sw: []Software{
+				{Name: "Git", Version: "2.42.0"},
+			},
+		},
+		{name: "no version", in: "Name,Vendor\nGit,Git\n", isErr: true},
+		{name: "empty", in: "", isErr: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sw, err := ParseCSV(strings.NewReader(c.in))
+			if c.isErr {
+				if err == nil {
+					t.Fatalf("expecting an error, got %v", sw)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]Software, len(sw))
+			for i, s := range sw {
+				got[i] = *s
+			}
+			if !reflect.DeepEqual(got, c.sw) {
+				t.Fatalf("got %+v, expected %+v", got, c.sw)
+			}
+		})
+	}
+}
+
+func TestParseCSVUTF16(t *testing.T) {
+	// wmic writes UTF-16 little endian with byte order mark
+	in := "\r\nNode,Name,Vendor,Version\r\nHOST,Wireshark 4.0.8 x64,The Wireshark developer community,4.0.8\r\n"
+	var buf bytes.Buffer
+	buf.Write([]byte{0xff, 0xfe})
+	for _, u := range utf16.Encode([]rune(in)) {
+		buf.Write([]byte{byte(u), byte(u >> 8)})
+	}
+	sw, err := ParseCSV(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := Software{Name: "Wireshark 4.0.8 x64", Version: "4.0.8", Publisher: "The Wireshark developer community"}
+	if len(sw) != 1 || *sw[0] != expect {
+		t.Fatalf("got %+v, expected %+v", sw, expect)
+	}
+}
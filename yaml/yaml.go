@@ -0,0 +1,520 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package yaml parses the subset of YAML which config and rule files of nvdtools are written in
+package yaml
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a line of a yaml document, without indentation and comments
+type yamlLine struct {
+	num    int
+	indent int
+	text   string
+}
+
+// Unmarshal parses the YAML document and decodes it into v as encoding/json does,
+// so v is decoded according to its json struct tags
+func Unmarshal(data []byte, v interface{}) error {
+	doc, err := Parse(string(data))
+	if err != nil {
+		return err
+	}
+	if data, err = json.Marshal(doc); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Parse parses the subset of YAML config files are written in: block mappings and sequences,
+// flow sequences of scalars, plain and quoted scalars, which can span several lines, literal (|)
+// and folded (>) block scalars and comments. Scalars are returned as strings,
+// mappings as map[string]interface{} and sequences as []interface{}.
+func Parse(doc string) (interface{}, error) {
+	raw := strings.Split(strings.Replace(doc, "\r\n", "\n", -1), "\n")
+	var lines []yamlLine
+	for i, line := range raw {
+		line = stripComment(line)
+		text := strings.TrimLeft(line, " ")
+		if text == "" || text == "---" || text == "..." {
+			continue
+		}
+		lines = append(lines, yamlLine{num: i + 1, indent: len(line) - len(text), text: strings.TrimRight(text, " \t")})
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	p := yamlParser{raw: raw, lines: lines}
+	v, err := p.block(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(lines) {
+		return nil, lineError(lines[p.pos], "unexpected indentation")
+	}
+	return v, nil
+}
+
+// lineError returns the error of the line, lines indented with tabs get a more helpful one
+func lineError(line yamlLine, format string, args ...interface{}) error {
+	if strings.HasPrefix(line.text, "\t") {
+		return fmt.Errorf("line %d: tabs can't be used for indentation", line.num)
+	}
+	return fmt.Errorf("line %d: "+format, append([]interface{}{line.num}, args...)...)
+}
+
+type yamlParser struct {
+	// lines of the document as they are, block and multi-line scalars are parsed from them
+	raw   []string
+	lines []yamlLine
+	pos   int
+}
+
+// skipTo skips the lines up to the given line number, which were parsed as a part of a scalar
+func (p *yamlParser) skipTo(num int) {
+	for p.pos < len(p.lines) && p.lines[p.pos].num <= num {
+		p.pos++
+	}
+}
+
+// block parses the mapping or sequence starting at the current line, with the given indentation
+func (p *yamlParser) block(indent int) (interface{}, error) {
+	if isSequenceItem(p.lines[p.pos].text) {
+		return p.sequence(indent)
+	}
+	return p.mapping(indent)
+}
+
+func (p *yamlParser) sequence(indent int) ([]interface{}, error) {
+	var seq []interface{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSequenceItem(p.lines[p.pos].text) {
+		line := p.lines[p.pos]
+		item := strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " ")
+		switch {
+		case item == "":
+			p.pos++
+			v, err := p.nested(indent)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, v)
+		case isMappingEntry(item):
+			// the mapping starts on the line of the item, at the indentation of its first key
+			p.lines[p.pos] = yamlLine{num: line.num, indent: line.indent + len(line.text) - len(item), text: item}
+			v, err := p.mapping(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, v)
+		default:
+			p.pos++
+			v, err := p.scalar(item, line.num, indent)
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, v)
+		}
+	}
+	return seq, nil
+}
+
+func (p *yamlParser) mapping(indent int) (map[string]interface{}, error) {
+	m := make(map[string]interface{})
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent {
+		line := p.lines[p.pos]
+		if !isMappingEntry(line.text) || strings.HasPrefix(line.text, "\t") {
+			return nil, lineError(line, "expecting a key, got %q", line.text)
+		}
+		sep := strings.Index(line.text, ":")
+		key, err := parseScalar(strings.TrimSpace(line.text[:sep]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line.num, err)
+		}
+		k := key.(string)
+		if _, ok := m[k]; ok {
+			return nil, fmt.Errorf("line %d: duplicate key %q", line.num, k)
+		}
+		p.pos++
+		if rest := strings.TrimSpace(line.text[sep+1:]); rest != "" {
+			if m[k], err = p.scalar(rest, line.num, indent); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// sequences can be at the indentation of their key
+		if p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isSequenceItem(p.lines[p.pos].text) {
+			if m[k], err = p.sequence(indent); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if m[k], err = p.nested(indent); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// nested parses the block more indented than indent at the current line, it returns nil if there's none
+func (p *yamlParser) nested(indent int) (interface{}, error) {
+	if p.pos >= len(p.lines) || p.lines[p.pos].indent <= indent {
+		return nil, nil
+	}
+	return p.block(p.lines[p.pos].indent)
+}
+
+// scalar parses the scalar which starts with text at line num, the lines which follow it
+// and are more indented than indent can continue it
+func (p *yamlParser) scalar(text string, num, indent int) (interface{}, error) {
+	var v interface{}
+	var err error
+	switch {
+	case strings.HasPrefix(text, "|"), strings.HasPrefix(text, ">"):
+		v, err = p.blockScalar(text, num, indent)
+	case (text[0] == '"' || text[0] == '\'') && closingQuote(text) < 0:
+		v, err = p.quoted(text, num)
+	default:
+		v, err = parseScalar(p.plain(text, num, indent))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %v", num, err)
+	}
+	return v, nil
+}
+
+// plain joins the plain scalar which starts with text at line num with the lines continuing it
+func (p *yamlParser) plain(text string, num, indent int) string {
+	lines := []string{text}
+	end := num
+	for i := num; i < len(p.raw); i++ {
+		line := stripComment(p.raw[i])
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			if strings.TrimSpace(p.raw[i]) != "" {
+				// comments end plain scalars
+				break
+			}
+			lines = append(lines, "")
+			continue
+		}
+		if indentation(line) <= indent || isMappingEntry(trimmed) {
+			break
+		}
+		lines = append(lines, trimmed)
+		end = i + 1
+	}
+	p.skipTo(end)
+	return fold(lines[:end-num+1])
+}
+
+// quoted parses the quoted scalar which starts with text at line num and ends in one of the following lines
+func (p *yamlParser) quoted(text string, num int) (string, error) {
+	lines := []string{text}
+	for i := num; i < len(p.raw); i++ {
+		line := p.raw[i]
+		lines = append(lines, line)
+		// the quote is prepended, so end is the index right after the closing quote in line
+		end := closingQuote(text[:1] + line)
+		if end < 0 {
+			continue
+		}
+		if rest := strings.TrimSpace(stripComment(line[end:])); rest != "" {
+			return "", fmt.Errorf("unexpected %q after quoted scalar", rest)
+		}
+		lines[0], lines[len(lines)-1] = text[1:], line[:end-1]
+		p.skipTo(i + 1)
+		if text[0] == '\'' {
+			return strings.Replace(foldQuoted(lines, false), "''", "'", -1), nil
+		}
+		return unquote(foldQuoted(lines, true))
+	}
+	return "", fmt.Errorf("unterminated quoted scalar %s", text)
+}
+
+// blockScalar parses the literal (|) or folded (>) block scalar with the given header at line num,
+// its content are the following lines which are more indented than indent
+func (p *yamlParser) blockScalar(header string, num, indent int) (string, error) {
+	var chomp rune
+	var contentIndent int
+	for _, c := range header[1:] {
+		switch {
+		case (c == '-' || c == '+') && chomp == 0:
+			chomp = c
+		case c >= '1' && c <= '9' && contentIndent == 0:
+			contentIndent = indent + int(c-'0')
+		default:
+			return "", fmt.Errorf("bad block scalar header %q", header)
+		}
+	}
+
+	var lines []string
+	end := num
+	for i := num; i < len(p.raw); i++ {
+		line := p.raw[i]
+		if strings.TrimLeft(line, " ") == "" {
+			lines = append(lines, "")
+			continue
+		}
+		n := indentation(line)
+		if contentIndent == 0 {
+			if n <= indent {
+				break
+			}
+			contentIndent = n
+		}
+		if n < contentIndent {
+			break
+		}
+		lines = append(lines, line[contentIndent:])
+		end = i + 1
+	}
+	p.skipTo(end)
+	// empty lines after the content are only kept with the + chomping indicator
+	trailing := len(lines) - (end - num)
+	lines = lines[:end-num]
+
+	var s string
+	if header[0] == '>' {
+		s = foldBlock(lines)
+	} else {
+		s = strings.Join(lines, "\n")
+	}
+	switch {
+	case chomp == '+':
+		return s + strings.Repeat("\n", trailing+1), nil
+	case chomp == '-' || s == "":
+		return s, nil
+	}
+	return s + "\n", nil
+}
+
+// parseScalar parses a plain or quoted scalar, or a flow sequence of them
+func parseScalar(s string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(s, "["):
+		if !strings.HasSuffix(s, "]") {
+			return nil, fmt.Errorf("unterminated flow sequence %q", s)
+		}
+		seq := []interface{}{}
+		for _, item := range splitFlow(s[1 : len(s)-1]) {
+			v, err := parseScalar(strings.TrimSpace(item))
+			if err != nil {
+				return nil, err
+			}
+			seq = append(seq, v)
+		}
+		return seq, nil
+	case s == "{}":
+		return map[string]interface{}{}, nil
+	case strings.HasPrefix(s, `"`):
+		if closingQuote(s) != len(s)-1 {
+			return nil, fmt.Errorf("bad double quoted scalar %s", s)
+		}
+		return unquote(s[1 : len(s)-1])
+	case strings.HasPrefix(s, "'"):
+		if closingQuote(s) != len(s)-1 {
+			return nil, fmt.Errorf("bad single quoted scalar %s", s)
+		}
+		return strings.Replace(s[1:len(s)-1], "''", "'", -1), nil
+	case strings.HasPrefix(s, "{"), strings.HasPrefix(s, "&"), strings.HasPrefix(s, "*"), strings.HasPrefix(s, "|"), strings.HasPrefix(s, ">"):
+		return nil, fmt.Errorf("unsupported yaml value %q", s)
+	}
+	return s, nil
+}
+
+// closingQuote returns the index of the quote closing the quoted scalar s, or -1 if it isn't closed;
+// double quoted scalars escape characters with backslashes, single quoted ones double the quotes
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[0] == '"' && s[i] == '\\':
+			i++
+		case s[i] == s[0] && s[0] == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == s[0]:
+			return i
+		}
+	}
+	return -1
+}
+
+// unquote replaces escape sequences of a double quoted scalar,
+// YAML has a few which Go doesn't, they're replaced first
+func unquote(s string) (string, error) {
+	r := strings.NewReplacer(`\\`, `\\`, `\/`, `/`, `\ `, ` `, `\e`, `\x1b`, `\_`, "\u00a0", `\N`, "\u0085", "\n", `\n`, "\t", `\t`)
+	v, err := strconv.Unquote(`"` + r.Replace(s) + `"`)
+	if err != nil {
+		return "", fmt.Errorf("bad double quoted scalar %q", s)
+	}
+	return v, nil
+}
+
+// fold joins the lines of a multi-line plain scalar with spaces, empty lines become line breaks
+func fold(lines []string) string {
+	var sb strings.Builder
+	newline := true
+	for _, line := range lines {
+		switch {
+		case line == "":
+			sb.WriteByte('\n')
+			newline = true
+		case newline:
+			sb.WriteString(line)
+			newline = false
+		default:
+			sb.WriteByte(' ')
+			sb.WriteString(line)
+		}
+	}
+	return sb.String()
+}
+
+// foldQuoted joins the lines of a multi-line quoted scalar, without the quotes, as fold does;
+// lines of double quoted scalars which end with a backslash are joined without a space
+func foldQuoted(lines []string, double bool) string {
+	for i := range lines {
+		if i > 0 {
+			lines[i] = strings.TrimLeft(lines[i], " \t")
+		}
+		if i < len(lines)-1 {
+			lines[i] = strings.TrimRight(lines[i], " \t")
+		}
+	}
+	var sb strings.Builder
+	var breaks int
+	for i, line := range lines {
+		if i > 0 && i < len(lines)-1 && line == "" {
+			breaks++
+			continue
+		}
+		if i > 0 {
+			prev := sb.String()
+			switch {
+			case breaks > 0:
+				sb.WriteString(strings.Repeat("\n", breaks))
+			case double && escapedBreak(prev):
+				// the backslash escapes the line break
+				sb.Reset()
+				sb.WriteString(prev[:len(prev)-1])
+			default:
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteString(line)
+		breaks = 0
+	}
+	return sb.String()
+}
+
+// escapedBreak returns true if s ends with an odd number of backslashes
+func escapedBreak(s string) bool {
+	n := len(s) - len(strings.TrimRight(s, "\\"))
+	return n%2 == 1
+}
+
+// foldBlock joins the lines of a folded block scalar: line breaks between lines of text become spaces,
+// except around empty lines and more indented lines, which keep them
+func foldBlock(lines []string) string {
+	var sb strings.Builder
+	prev := -1
+	for i, line := range lines {
+		if line == "" {
+			continue
+		}
+		if prev < 0 {
+			sb.WriteString(strings.Repeat("\n", i))
+		} else {
+			empty := i - prev - 1
+			switch {
+			case moreIndented(lines[prev]) || moreIndented(line):
+				sb.WriteString(strings.Repeat("\n", empty+1))
+			case empty > 0:
+				sb.WriteString(strings.Repeat("\n", empty))
+			default:
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteString(line)
+		prev = i
+	}
+	return sb.String()
+}
+
+func moreIndented(line string) bool {
+	return strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+}
+
+func indentation(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// splitFlow splits items of a flow sequence at commas outside of quotes
+func splitFlow(s string) []string {
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	var items []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ',':
+			items = append(items, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, s[start:])
+}
+
+// stripComment removes the comment from the line, if there's one outside of quotes
+func stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return line[:i]
+		}
+	}
+	return line
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// isMappingEntry returns true if the text is a key followed by a colon
+func isMappingEntry(text string) bool {
+	if strings.HasPrefix(text, `"`) || strings.HasPrefix(text, "'") || strings.HasPrefix(text, "[") {
+		return false
+	}
+	sep := strings.Index(text, ":")
+	return sep > 0 && (sep == len(text)-1 || text[sep+1] == ' ')
+}
@@ -0,0 +1,149 @@
+// Copyright (c) Facebook, Inc. and its affiliates.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yaml
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for i, tc := range []struct {
+		doc  string
+		want interface{}
+		fail bool
+	}{
+		{doc: "", want: nil},
+		{doc: "a: b\nc: 'd # e'  # comment\n", want: map[string]interface{}{"a": "b", "c": "d # e"}},
+		{doc: "list: [a, \"b, c\", 'd']", want: map[string]interface{}{"list": []interface{}{"a", "b, c", "d"}}},
+		{
+			doc: `
+# providers
+output_dir: /feeds
+providers:
+  - name: snyk
+    env:
+      SNYK_ID: "id"
+    args:
+      - -language
+      - python
+  - name: kev
+top:
+- x
+- y
+empty:
+`,
+			want: map[string]interface{}{
+				"output_dir": "/feeds",
+				"providers": []interface{}{
+					map[string]interface{}{
+						"name": "snyk",
+						"env":  map[string]interface{}{"SNYK_ID": "id"},
+						"args": []interface{}{"-language", "python"},
+					},
+					map[string]interface{}{"name": "kev"},
+				},
+				"top":   []interface{}{"x", "y"},
+				"empty": nil,
+			},
+		},
+		{doc: "-\n  a: b\n- c", want: []interface{}{map[string]interface{}{"a": "b"}, "c"}},
+		{doc: "url: http://example.com:8080/x", want: map[string]interface{}{"url": "http://example.com:8080/x"}},
+		{
+			doc: `
+literal: |
+  line 1
+    indented # not a comment
+
+  line 3
+folded: >-
+  folded
+  text
+
+  next
+    more indented
+keep: |+
+  kept
+
+strip: |-
+  stripped
+indicator: |2
+    two spaces
+seq:
+  - |
+    item
+  - b
+`,
+			want: map[string]interface{}{
+				"literal":   "line 1\n  indented # not a comment\n\nline 3\n",
+				"folded":    "folded text\nnext\n  more indented",
+				"keep":      "kept\n\n",
+				"strip":     "stripped",
+				"indicator": "  two spaces\n",
+				"seq":       []interface{}{"item\n", "b"},
+			},
+		},
+		{
+			doc: `description: "a double quoted
+  scalar\twith \/escapes\n
+
+  and an escaped line br\
+  eak"
+plain: a plain
+  scalar # comment
+title: 'it''s
+  quoted'
+list:
+- multi
+  line
+- x
+`,
+			want: map[string]interface{}{
+				"description": "a double quoted scalar\twith /escapes\n\nand an escaped line break",
+				"plain":       "a plain scalar",
+				"title":       "it's quoted",
+				"list":        []interface{}{"multi line", "x"},
+			},
+		},
+		{doc: "a: b\na: c", fail: true},
+		{doc: "a: b\n  c: d", fail: true},
+		{doc: "a:\n  b: c\n d: e", fail: true},
+		{doc: "a: \"b", fail: true},
+		{doc: "a: [b", fail: true},
+		{doc: "a: \"b\n  c\n", fail: true},
+		{doc: "a: \"b\n  c\" d\n", fail: true},
+		{doc: "a: |x\n  b\n", fail: true},
+		{doc: "a: b\n\tc: d", fail: true},
+		{doc: "a: &anchor b", fail: true},
+		{doc: "just a string", fail: true},
+	} {
+		t.Run(fmt.Sprintf("case-%d", i+1), func(t *testing.T) {
+			got, err := Parse(tc.doc)
+			if tc.fail {
+				if err == nil {
+					t.Fatalf("expected an error, got %#v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("unexpected result:\nwant: %#v\nhave: %#v", tc.want, got)
+			}
+		})
+	}
+}